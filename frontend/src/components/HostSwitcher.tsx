@@ -0,0 +1,90 @@
+import { Server } from 'lucide-react'
+import { useQuery } from '@tanstack/react-query'
+import { Button } from '@/components/ui/button'
+import {
+  DropdownMenu,
+  DropdownMenuContent,
+  DropdownMenuLabel,
+  DropdownMenuRadioGroup,
+  DropdownMenuRadioItem,
+  DropdownMenuSeparator,
+  DropdownMenuTrigger,
+} from '@/components/ui/dropdown-menu'
+import { TooltipHelper } from '@/components/TooltipHelper'
+import { useTmuxApi } from '@/hooks/useTmuxApi'
+import { getActiveHost, setActiveHost } from '@/lib/activeHost'
+import type { FederatedHost } from '@/types'
+
+const LOCAL_HOST = ''
+
+// HostSwitcher picks which federated host the tmux and ops views talk to.
+// It stays hidden until this central has an agent or SSH host.
+export default function HostSwitcher() {
+  const api = useTmuxApi()
+  const activeHost = getActiveHost()
+  const hostsQuery = useQuery({
+    queryKey: ['hosts'],
+    queryFn: () => api<{ hosts: Array<FederatedHost> }>('/api/hosts'),
+    refetchInterval: 30_000,
+  })
+  const hosts = hostsQuery.data?.hosts ?? []
+
+  if (hosts.length === 0 && activeHost === LOCAL_HOST) {
+    return null
+  }
+
+  const selectHost = (host: string) => {
+    if (host === activeHost) {
+      return
+    }
+    setActiveHost(host)
+    // Cached queries and the event stream belong to the previous host.
+    window.location.reload()
+  }
+
+  const label = activeHost === LOCAL_HOST ? 'Host: local' : `Host: ${activeHost}`
+
+  return (
+    <DropdownMenu>
+      <TooltipHelper content={label} side="right">
+        <DropdownMenuTrigger asChild>
+          <Button
+            variant="ghost"
+            size="icon-lg"
+            className={
+              activeHost === LOCAL_HOST
+                ? 'w-full text-secondary-foreground hover:text-foreground'
+                : 'w-full text-primary-text-bright'
+            }
+            aria-label={label}
+          >
+            <Server className="size-4" />
+          </Button>
+        </DropdownMenuTrigger>
+      </TooltipHelper>
+      <DropdownMenuContent side="right" align="end" className="w-56">
+        <DropdownMenuLabel>Hosts</DropdownMenuLabel>
+        <DropdownMenuSeparator />
+        <DropdownMenuRadioGroup value={activeHost} onValueChange={selectHost}>
+          <DropdownMenuRadioItem value={LOCAL_HOST}>Local</DropdownMenuRadioItem>
+          {hosts.map((host) => (
+            <DropdownMenuRadioItem key={host.name} value={host.name}>
+              {host.name}
+              {host.transport === 'ssh' && (
+                <span className="ml-auto text-xs text-muted-foreground">
+                  ssh
+                </span>
+              )}
+            </DropdownMenuRadioItem>
+          ))}
+          {activeHost !== LOCAL_HOST &&
+            !hosts.some((host) => host.name === activeHost) && (
+              <DropdownMenuRadioItem value={activeHost} disabled>
+                {activeHost} (offline)
+              </DropdownMenuRadioItem>
+            )}
+        </DropdownMenuRadioGroup>
+      </DropdownMenuContent>
+    </DropdownMenu>
+  )
+}
@@ -0,0 +1,153 @@
+import { useCallback, useEffect, useMemo, useRef, useState } from 'react'
+import { Terminal } from '@xterm/xterm'
+import { Pause, Play, RotateCcw } from 'lucide-react'
+import type { Asciicast } from '@/lib/asciicast'
+import { Button } from '@/components/ui/button'
+import { compressIdle, formatPlaybackTime, parseResize } from '@/lib/asciicast'
+import { THEME_STORAGE_KEY, getTerminalTheme } from '@/lib/terminalThemes'
+
+const PLAYBACK_SPEEDS = [0.5, 1, 2, 4, 8] as const
+
+type RecordingPlayerProps = {
+  cast: Asciicast
+}
+
+// RecordingPlayer replays the output of a recording into a read-only
+// terminal. Typed input is part of the file but is not shown: its echo is
+// already in the output.
+export function RecordingPlayer({ cast }: RecordingPlayerProps) {
+  const hostRef = useRef<HTMLDivElement | null>(null)
+  const terminalRef = useRef<Terminal | null>(null)
+  const events = useMemo(
+    () => compressIdle(cast.events.filter((event) => event.code !== 'i')),
+    [cast],
+  )
+  const duration = events.length > 0 ? events[events.length - 1].time : 0
+
+  const [playing, setPlaying] = useState(true)
+  const [speed, setSpeed] = useState<number>(1)
+  const [position, setPosition] = useState(0)
+  // Bumped by restart so the playback effect starts over even when it was
+  // already playing.
+  const [run, setRun] = useState(0)
+  // Playback state shared with the timer: the next event to write and the
+  // recording time reached so far.
+  const nextEventRef = useRef(0)
+  const positionRef = useRef(0)
+
+  useEffect(() => {
+    const host = hostRef.current
+    if (!host) return
+    const themeId = window.localStorage.getItem(THEME_STORAGE_KEY) ?? 'sentinel'
+    const terminal = new Terminal({
+      cols: cast.header.width,
+      rows: cast.header.height,
+      disableStdin: true,
+      cursorBlink: false,
+      fontFamily: 'JetBrains Mono Variable, JetBrains Mono, SF Mono, monospace',
+      fontSize: 13,
+      scrollback: 0,
+      theme: getTerminalTheme(themeId).colors,
+    })
+    terminal.open(host)
+    terminalRef.current = terminal
+    nextEventRef.current = 0
+    positionRef.current = 0
+    setPosition(0)
+    setPlaying(true)
+    return () => {
+      terminalRef.current = null
+      terminal.dispose()
+    }
+  }, [cast])
+
+  useEffect(() => {
+    if (!playing) return
+    const terminal = terminalRef.current
+    if (!terminal) return
+
+    let timer: ReturnType<typeof setTimeout> | null = null
+    const startedAt = performance.now() - (positionRef.current * 1000) / speed
+    const step = () => {
+      const now = ((performance.now() - startedAt) * speed) / 1000
+      let index = nextEventRef.current
+      while (index < events.length && events[index].time <= now) {
+        const event = events[index]
+        if (event.code === 'o') {
+          terminal.write(event.data)
+        } else if (event.code === 'r') {
+          const size = parseResize(event.data)
+          if (size) terminal.resize(size.cols, size.rows)
+        }
+        index++
+      }
+      nextEventRef.current = index
+      positionRef.current = Math.min(now, duration)
+      setPosition(positionRef.current)
+      if (index >= events.length) {
+        setPlaying(false)
+        return
+      }
+      timer = setTimeout(step, ((events[index].time - now) * 1000) / speed)
+    }
+    step()
+    return () => {
+      if (timer != null) clearTimeout(timer)
+    }
+  }, [playing, speed, events, duration, run])
+
+  const restart = useCallback(() => {
+    terminalRef.current?.reset()
+    terminalRef.current?.resize(cast.header.width, cast.header.height)
+    nextEventRef.current = 0
+    positionRef.current = 0
+    setPosition(0)
+    setPlaying(true)
+    setRun((prev) => prev + 1)
+  }, [cast])
+
+  const finished = !playing && nextEventRef.current >= events.length
+
+  return (
+    <div className="flex min-h-0 flex-col gap-2">
+      <div className="min-h-0 overflow-auto rounded-md border border-border-subtle bg-surface-sunken p-1">
+        <div ref={hostRef} />
+      </div>
+      <div className="flex items-center gap-2 text-[12px] text-secondary-foreground">
+        <Button
+          variant="outline"
+          size="sm"
+          className="h-7 cursor-pointer"
+          onClick={() => (finished ? restart() : setPlaying((prev) => !prev))}
+          aria-label={playing ? 'Pause' : 'Play'}
+        >
+          {playing ? <Pause className="h-3.5 w-3.5" /> : <Play className="h-3.5 w-3.5" />}
+        </Button>
+        <Button
+          variant="outline"
+          size="sm"
+          className="h-7 cursor-pointer"
+          onClick={restart}
+          aria-label="Restart"
+        >
+          <RotateCcw className="h-3.5 w-3.5" />
+        </Button>
+        <select
+          value={speed}
+          onChange={(e) => setSpeed(Number(e.target.value))}
+          className="h-7 rounded-md border border-border-subtle bg-surface-overlay px-1.5 text-[11px]"
+          aria-label="Playback speed"
+        >
+          {PLAYBACK_SPEEDS.map((value) => (
+            <option key={value} value={value}>
+              {value}x
+            </option>
+          ))}
+        </select>
+        <span className="tabular-nums">
+          {formatPlaybackTime(position)} / {formatPlaybackTime(duration)}
+        </span>
+      </div>
+    </div>
+  )
+}
@@ -23,6 +23,10 @@ vi.mock('@/components/settings/SettingsDialog', () => ({
   default: () => null,
 }))
 
+vi.mock('@/components/HostSwitcher', () => ({
+  default: () => null,
+}))
+
 vi.mock('@/contexts/ViewportContext', () => ({
   useViewport: () => ({
     compactLayout: false,
@@ -1,6 +1,7 @@
 import { ChevronsLeft, ChevronsRight, Settings } from 'lucide-react'
 import { Link, useRouterState } from '@tanstack/react-router'
 import { Button } from '@/components/ui/button'
+import HostSwitcher from '@/components/HostSwitcher'
 import { TooltipHelper } from '@/components/TooltipHelper'
 import { useLayoutContext } from '@/contexts/LayoutContext'
 import { useViewport } from '@/contexts/ViewportContext'
@@ -57,6 +58,7 @@ export default function SideRail({
       </nav>
       <div className="flex-1" />
       <hr className="w-full border-t border-border-subtle" />
+      <HostSwitcher />
       <TooltipHelper content="Settings" side="right">
         <Button
           variant="ghost"
@@ -35,6 +35,11 @@ function scheduleDescription(schedule: OpsSchedule): string {
       return schedule.cronExpr
     }
   }
+  if (schedule.scheduleType === 'interval' && schedule.interval) {
+    return schedule.jitter
+      ? `Every ${schedule.interval} (+ up to ${schedule.jitter} jitter)`
+      : `Every ${schedule.interval}`
+  }
   if (schedule.scheduleType === 'once' && schedule.runAt) {
     return `Once at ${new Intl.DateTimeFormat('en-US', { dateStyle: 'medium', timeStyle: 'short', timeZone: schedule.timezone || undefined }).format(new Date(schedule.runAt))}`
   }
@@ -77,6 +77,7 @@ export type InspectorPanePatch = {
   paneId?: string
   title?: string
   active?: boolean
+  zoomed?: boolean
   tty?: string
   currentPath?: string
   startCommand?: string
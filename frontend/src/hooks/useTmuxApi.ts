@@ -1,4 +1,5 @@
 import { useCallback } from 'react'
+import { getActiveHost, hostApiPath } from '@/lib/activeHost'
 
 export function useTmuxApi() {
   return useCallback(async <T>(path: string, init?: RequestInit): Promise<T> => {
@@ -10,7 +11,7 @@ export function useTmuxApi() {
       Object.assign(headers, init.headers as Record<string, string>)
     }
 
-    const response = await fetch(path, {
+    const response = await fetch(hostApiPath(path, getActiveHost()), {
       ...init,
       credentials: 'same-origin',
       headers,
@@ -0,0 +1,48 @@
+import { afterEach, beforeEach, describe, expect, it, vi } from 'vitest'
+
+import { getActiveHost, hostApiPath, setActiveHost } from './activeHost'
+
+describe('hostApiPath', () => {
+  it('leaves paths alone for the local host', () => {
+    expect(hostApiPath('/api/tmux/sessions', '')).toBe('/api/tmux/sessions')
+  })
+
+  it('routes tmux and ops calls through the host relay', () => {
+    expect(hostApiPath('/api/tmux/sessions', 'web-01')).toBe(
+      '/api/hosts/web-01/tmux/sessions',
+    )
+    expect(hostApiPath('/api/ops/services?x=1', 'web-01')).toBe(
+      '/api/hosts/web-01/ops/services?x=1',
+    )
+  })
+
+  it('keeps streams and other APIs local', () => {
+    expect(
+      hostApiPath('/api/ops/services/nginx/logs/stream', 'web-01'),
+    ).toBe('/api/ops/services/nginx/logs/stream')
+    expect(hostApiPath('/api/meta', 'web-01')).toBe('/api/meta')
+  })
+})
+
+describe('active host storage', () => {
+  beforeEach(() => {
+    const store = new Map<string, string>()
+    vi.stubGlobal('localStorage', {
+      getItem: (key: string) => store.get(key) ?? null,
+      setItem: (key: string, value: string) => store.set(key, value),
+      removeItem: (key: string) => store.delete(key),
+    })
+  })
+
+  afterEach(() => {
+    vi.unstubAllGlobals()
+  })
+
+  it('round-trips the selected host', () => {
+    expect(getActiveHost()).toBe('')
+    setActiveHost(' db-01 ')
+    expect(getActiveHost()).toBe('db-01')
+    setActiveHost('')
+    expect(getActiveHost()).toBe('')
+  })
+})
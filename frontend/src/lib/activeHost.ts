@@ -0,0 +1,40 @@
+// Federated host selection. The selected host is stored per browser; an
+// empty value means the local Sentinel instance.
+export const ACTIVE_HOST_STORAGE_KEY = 'sentinel_active_host'
+
+export function getActiveHost(): string {
+  try {
+    return (localStorage.getItem(ACTIVE_HOST_STORAGE_KEY) ?? '').trim()
+  } catch {
+    return ''
+  }
+}
+
+export function setActiveHost(host: string): void {
+  const value = host.trim()
+  try {
+    if (value === '') {
+      localStorage.removeItem(ACTIVE_HOST_STORAGE_KEY)
+    } else {
+      localStorage.setItem(ACTIVE_HOST_STORAGE_KEY, value)
+    }
+  } catch {
+    // Storage can be unavailable in private browsing; selection is lost.
+  }
+}
+
+// hostApiPath routes tmux and ops API calls through the central's host
+// relay. Streams and every other API stay on the local instance.
+export function hostApiPath(path: string, host: string): string {
+  if (host === '') {
+    return path
+  }
+  const [pathname] = path.split('?', 1)
+  if (pathname.endsWith('/stream')) {
+    return path
+  }
+  if (!path.startsWith('/api/tmux/') && !path.startsWith('/api/ops/')) {
+    return path
+  }
+  return `/api/hosts/${encodeURIComponent(host)}/${path.slice('/api/'.length)}`
+}
@@ -0,0 +1,62 @@
+import { describe, expect, it } from 'vitest'
+
+import { compressIdle, formatPlaybackTime, parseAsciicast, parseResize } from './asciicast'
+
+describe('parseAsciicast', () => {
+  it('reads the header and events', () => {
+    const cast = parseAsciicast(
+      [
+        '{"version":2,"width":100,"height":30,"timestamp":1700000000,"title":"dev"}',
+        '[0.5,"o","hello"]',
+        '[1.25,"i","ls\\r"]',
+        '[2,"r","120x40"]',
+        '',
+      ].join('\n'),
+    )
+    expect(cast.header).toEqual({ width: 100, height: 30, timestamp: 1700000000, title: 'dev' })
+    expect(cast.events).toEqual([
+      { time: 0.5, code: 'o', data: 'hello' },
+      { time: 1.25, code: 'i', data: 'ls\r' },
+      { time: 2, code: 'r', data: '120x40' },
+    ])
+  })
+
+  it('skips a truncated last line', () => {
+    const cast = parseAsciicast('{"version":2,"width":80,"height":24}\n[0.1,"o","a"]\n[0.2,"o","b')
+    expect(cast.events).toHaveLength(1)
+  })
+
+  it('rejects other formats', () => {
+    expect(() => parseAsciicast('')).toThrow()
+    expect(() => parseAsciicast('{"version":1}')).toThrow()
+  })
+})
+
+describe('compressIdle', () => {
+  it('caps long pauses', () => {
+    const events = compressIdle(
+      [
+        { time: 1, code: 'o', data: 'a' },
+        { time: 61, code: 'o', data: 'b' },
+        { time: 61.5, code: 'o', data: 'c' },
+      ],
+      2,
+    )
+    expect(events.map((event) => event.time)).toEqual([1, 3, 3.5])
+  })
+})
+
+describe('parseResize', () => {
+  it('parses COLSxROWS', () => {
+    expect(parseResize('120x40')).toEqual({ cols: 120, rows: 40 })
+    expect(parseResize('0x40')).toBeNull()
+    expect(parseResize('wide')).toBeNull()
+  })
+})
+
+describe('formatPlaybackTime', () => {
+  it('formats minutes and seconds', () => {
+    expect(formatPlaybackTime(0)).toBe('0:00')
+    expect(formatPlaybackTime(75.9)).toBe('1:15')
+  })
+})
@@ -0,0 +1,93 @@
+// Parsing and timing for asciicast v2 recordings, the format written by
+// terminal recording (see internal/recording).
+
+export type AsciicastHeader = {
+  width: number
+  height: number
+  timestamp: number
+  title: string
+}
+
+export type AsciicastEvent = {
+  time: number
+  code: string
+  data: string
+}
+
+export type Asciicast = {
+  header: AsciicastHeader
+  events: Array<AsciicastEvent>
+}
+
+// IDLE_LIMIT_SECONDS caps the pauses replayed between two events, so a
+// terminal left open overnight does not play back as a blank hour.
+export const IDLE_LIMIT_SECONDS = 2
+
+export function parseAsciicast(text: string): Asciicast {
+  const lines = text.split('\n').filter((line) => line.trim() !== '')
+  if (lines.length === 0) {
+    throw new Error('empty recording')
+  }
+  const raw = JSON.parse(lines[0]) as Record<string, unknown>
+  if (raw.version !== 2) {
+    throw new Error('not an asciicast v2 recording')
+  }
+  const header: AsciicastHeader = {
+    width: typeof raw.width === 'number' ? raw.width : 80,
+    height: typeof raw.height === 'number' ? raw.height : 24,
+    timestamp: typeof raw.timestamp === 'number' ? raw.timestamp : 0,
+    title: typeof raw.title === 'string' ? raw.title : '',
+  }
+
+  const events: Array<AsciicastEvent> = []
+  for (const line of lines.slice(1)) {
+    let event: unknown
+    try {
+      event = JSON.parse(line)
+    } catch {
+      // The last line of a recording still being written may be cut short.
+      continue
+    }
+    if (
+      Array.isArray(event) &&
+      typeof event[0] === 'number' &&
+      typeof event[1] === 'string' &&
+      typeof event[2] === 'string'
+    ) {
+      events.push({ time: event[0], code: event[1], data: event[2] })
+    }
+  }
+  return { header, events }
+}
+
+// compressIdle returns the events retimed so no pause between two of them
+// is longer than limit seconds.
+export function compressIdle(
+  events: Array<AsciicastEvent>,
+  limit = IDLE_LIMIT_SECONDS,
+): Array<AsciicastEvent> {
+  let previous = 0
+  let shifted = 0
+  return events.map((event) => {
+    shifted += Math.min(Math.max(event.time - previous, 0), limit)
+    previous = event.time
+    return { ...event, time: shifted }
+  })
+}
+
+// parseResize reads the "COLSxROWS" payload of a resize event.
+export function parseResize(data: string): { cols: number; rows: number } | null {
+  const match = /^(\d+)x(\d+)$/.exec(data)
+  if (!match) {
+    return null
+  }
+  const cols = Number(match[1])
+  const rows = Number(match[2])
+  return cols > 0 && rows > 0 ? { cols, rows } : null
+}
+
+export function formatPlaybackTime(seconds: number): string {
+  const total = Math.max(0, Math.floor(seconds))
+  const minutes = Math.floor(total / 60)
+  return `${minutes}:${String(total % 60).padStart(2, '0')}`
+}
@@ -12,6 +12,7 @@ import { Route as rootRouteImport } from './routes/__root'
 import { Route as IndexRouteImport } from './routes/index'
 import { Route as MetricsRouteImport } from './routes/metrics'
 import { Route as OpsRouteImport } from './routes/ops'
+import { Route as RecordingsRouteImport } from './routes/recordings'
 import { Route as RunbooksRouteImport } from './routes/runbooks'
 import { Route as ServicesRouteImport } from './routes/services'
 import { Route as TmuxRouteImport } from './routes/tmux'
@@ -31,6 +32,11 @@ const OpsRoute = OpsRouteImport.update({
   path: '/ops',
   getParentRoute: () => rootRouteImport,
 } as any)
+const RecordingsRoute = RecordingsRouteImport.update({
+  id: '/recordings',
+  path: '/recordings',
+  getParentRoute: () => rootRouteImport,
+} as any)
 const RunbooksRoute = RunbooksRouteImport.update({
   id: '/runbooks',
   path: '/runbooks',
@@ -51,6 +57,7 @@ export interface FileRoutesByFullPath {
   '/': typeof IndexRoute
   '/metrics': typeof MetricsRoute
   '/ops': typeof OpsRoute
+  '/recordings': typeof RecordingsRoute
   '/runbooks': typeof RunbooksRoute
   '/services': typeof ServicesRoute
   '/tmux': typeof TmuxRoute
@@ -59,6 +66,7 @@ export interface FileRoutesByTo {
   '/': typeof IndexRoute
   '/metrics': typeof MetricsRoute
   '/ops': typeof OpsRoute
+  '/recordings': typeof RecordingsRoute
   '/runbooks': typeof RunbooksRoute
   '/services': typeof ServicesRoute
   '/tmux': typeof TmuxRoute
@@ -68,23 +76,46 @@ export interface FileRoutesById {
   '/': typeof IndexRoute
   '/metrics': typeof MetricsRoute
   '/ops': typeof OpsRoute
+  '/recordings': typeof RecordingsRoute
   '/runbooks': typeof RunbooksRoute
   '/services': typeof ServicesRoute
   '/tmux': typeof TmuxRoute
 }
 export interface FileRouteTypes {
   fileRoutesByFullPath: FileRoutesByFullPath
-  fullPaths: '/' | '/metrics' | '/ops' | '/runbooks' | '/services' | '/tmux'
+  fullPaths:
+    | '/'
+    | '/metrics'
+    | '/ops'
+    | '/recordings'
+    | '/runbooks'
+    | '/services'
+    | '/tmux'
   fileRoutesByTo: FileRoutesByTo
-  to: '/' | '/metrics' | '/ops' | '/runbooks' | '/services' | '/tmux'
+  to:
+    | '/'
+    | '/metrics'
+    | '/ops'
+    | '/recordings'
+    | '/runbooks'
+    | '/services'
+    | '/tmux'
   id:
-    '__root__' | '/' | '/metrics' | '/ops' | '/runbooks' | '/services' | '/tmux'
+    | '__root__'
+    | '/'
+    | '/metrics'
+    | '/ops'
+    | '/recordings'
+    | '/runbooks'
+    | '/services'
+    | '/tmux'
   fileRoutesById: FileRoutesById
 }
 export interface RootRouteChildren {
   IndexRoute: typeof IndexRoute
   MetricsRoute: typeof MetricsRoute
   OpsRoute: typeof OpsRoute
+  RecordingsRoute: typeof RecordingsRoute
   RunbooksRoute: typeof RunbooksRoute
   ServicesRoute: typeof ServicesRoute
   TmuxRoute: typeof TmuxRoute
@@ -113,6 +144,13 @@ declare module '@tanstack/react-router' {
       preLoaderRoute: typeof OpsRouteImport
       parentRoute: typeof rootRouteImport
     }
+    '/recordings': {
+      id: '/recordings'
+      path: '/recordings'
+      fullPath: '/recordings'
+      preLoaderRoute: typeof RecordingsRouteImport
+      parentRoute: typeof rootRouteImport
+    }
     '/runbooks': {
       id: '/runbooks'
       path: '/runbooks'
@@ -141,6 +179,7 @@ const rootRouteChildren: RootRouteChildren = {
   IndexRoute: IndexRoute,
   MetricsRoute: MetricsRoute,
   OpsRoute: OpsRoute,
+  RecordingsRoute: RecordingsRoute,
   RunbooksRoute: RunbooksRoute,
   ServicesRoute: ServicesRoute,
   TmuxRoute: TmuxRoute,
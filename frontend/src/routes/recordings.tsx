@@ -0,0 +1,172 @@
+import { useCallback, useEffect, useState } from 'react'
+import { createFileRoute } from '@tanstack/react-router'
+import { Download, Trash2 } from 'lucide-react'
+import type { TerminalRecording, TerminalRecordingsResponse } from '@/types'
+import type { Asciicast } from '@/lib/asciicast'
+import AppSectionTitle from '@/components/layout/AppSectionTitle'
+import AppShell from '@/components/layout/AppShell'
+import { RecordingPlayer } from '@/components/RecordingPlayer'
+import { Button } from '@/components/ui/button'
+import { EmptyState } from '@/components/ui/empty-state'
+import { useMetaContext } from '@/contexts/MetaContext'
+import { useDateFormat } from '@/hooks/useDateFormat'
+import { useTmuxApi } from '@/hooks/useTmuxApi'
+import { getActiveHost, hostApiPath } from '@/lib/activeHost'
+import { parseAsciicast } from '@/lib/asciicast'
+import { formatBytes, toErrorMessage } from '@/lib/opsUtils'
+import { cn } from '@/lib/utils'
+
+function recordingPath(id: string): string {
+  return `/api/tmux/recordings/${encodeURIComponent(id)}`
+}
+
+function RecordingsPage() {
+  const { hostname } = useMetaContext()
+  const api = useTmuxApi()
+  const { formatDateTime } = useDateFormat()
+
+  const [recordings, setRecordings] = useState<Array<TerminalRecording>>([])
+  const [loading, setLoading] = useState(true)
+  const [error, setError] = useState('')
+  const [selectedId, setSelectedId] = useState<string | null>(null)
+  const [cast, setCast] = useState<Asciicast | null>(null)
+  const [castError, setCastError] = useState('')
+
+  const refresh = useCallback(async () => {
+    try {
+      const data = await api<TerminalRecordingsResponse>('/api/tmux/recordings')
+      setRecordings(data.recordings)
+      setError('')
+    } catch (err) {
+      setError(toErrorMessage(err, 'failed to load recordings'))
+    } finally {
+      setLoading(false)
+    }
+  }, [api])
+
+  useEffect(() => {
+    void refresh()
+  }, [refresh])
+
+  // The download endpoint serves the raw cast file rather than the usual
+  // {data} envelope, so it is fetched directly.
+  useEffect(() => {
+    if (selectedId == null) {
+      setCast(null)
+      return
+    }
+    let cancelled = false
+    setCast(null)
+    setCastError('')
+    fetch(hostApiPath(recordingPath(selectedId), getActiveHost()), {
+      credentials: 'same-origin',
+    })
+      .then(async (response) => {
+        if (!response.ok) throw new Error(`HTTP ${response.status}`)
+        return parseAsciicast(await response.text())
+      })
+      .then((parsed) => {
+        if (!cancelled) setCast(parsed)
+      })
+      .catch((err: unknown) => {
+        if (!cancelled) setCastError(toErrorMessage(err, 'failed to load recording'))
+      })
+    return () => {
+      cancelled = true
+    }
+  }, [selectedId])
+
+  const deleteRecording = useCallback(
+    async (id: string) => {
+      try {
+        await api(recordingPath(id), { method: 'DELETE' })
+        if (selectedId === id) setSelectedId(null)
+        await refresh()
+      } catch (err) {
+        setError(toErrorMessage(err, 'failed to delete recording'))
+      }
+    },
+    [api, refresh, selectedId],
+  )
+
+  return (
+    <AppShell>
+      <main className="grid h-full min-h-0 min-w-0 grid-cols-1 grid-rows-[40px_1fr_28px] bg-background">
+        <header className="flex min-w-0 items-center justify-between gap-2 border-b border-border bg-card px-2.5">
+          <div className="flex min-w-0 items-center gap-2">
+            <AppSectionTitle hostname={hostname} section="recordings" />
+          </div>
+        </header>
+
+        <div className="grid min-h-0 grid-cols-1 gap-3 overflow-y-auto p-3 md:grid-cols-[minmax(240px,320px)_1fr] md:overflow-hidden">
+          <div className="flex min-h-0 flex-col gap-1.5 md:overflow-y-auto">
+            {error !== '' && <p className="text-[12px] text-destructive">{error}</p>}
+            {!loading && recordings.length === 0 && error === '' && (
+              <EmptyState variant="inline" className="text-[12px]">
+                No recordings. Enable [recording] in the config to record terminals.
+              </EmptyState>
+            )}
+            {recordings.map((recording) => (
+              <div
+                key={recording.id}
+                className={cn(
+                  'flex items-center gap-2 rounded-md border border-border-subtle px-2 py-1.5',
+                  selectedId === recording.id ? 'bg-surface-overlay' : 'bg-surface-elevated',
+                )}
+              >
+                <button
+                  type="button"
+                  className="min-w-0 flex-1 cursor-pointer text-left"
+                  onClick={() => setSelectedId(recording.id)}
+                >
+                  <span className="block truncate text-[12px] font-medium">{recording.session}</span>
+                  <span className="block truncate text-[11px] text-muted-foreground">
+                    {formatDateTime(recording.startedAt)} · {formatBytes(recording.size)}
+                  </span>
+                </button>
+                <Button variant="ghost" size="icon" className="h-7 w-7" asChild>
+                  <a
+                    href={hostApiPath(recordingPath(recording.id), getActiveHost())}
+                    download={`${recording.id}.cast`}
+                    aria-label="Download recording"
+                  >
+                    <Download className="h-3.5 w-3.5" />
+                  </a>
+                </Button>
+                <Button
+                  variant="ghost"
+                  size="icon"
+                  className="h-7 w-7 cursor-pointer"
+                  onClick={() => void deleteRecording(recording.id)}
+                  aria-label="Delete recording"
+                >
+                  <Trash2 className="h-3.5 w-3.5" />
+                </Button>
+              </div>
+            ))}
+          </div>
+
+          <div className="min-h-0 min-w-0 md:overflow-auto">
+            {castError !== '' ? (
+              <EmptyState className="text-[12px]">{castError}</EmptyState>
+            ) : cast != null ? (
+              <RecordingPlayer cast={cast} />
+            ) : (
+              <EmptyState className="min-h-[30vh] text-[12px]">
+                {selectedId == null ? 'Select a recording to play it' : 'Loading…'}
+              </EmptyState>
+            )}
+          </div>
+        </div>
+
+        <footer className="flex items-center justify-between gap-2 overflow-hidden border-t border-border bg-card px-2.5 text-[12px] text-secondary-foreground">
+          <span className="min-w-0 flex-1 truncate">{recordings.length} recordings</span>
+        </footer>
+      </main>
+    </AppShell>
+  )
+}
+
+export const Route = createFileRoute('/recordings')({
+  component: RecordingsPage,
+})
@@ -10,10 +10,24 @@ export type Session = {
   hash: string
   lastContent: string
   icon: string
+  tags?: Array<string>
+  group?: string
+  notes?: string
   user?: string
   unreadWindows?: number
   unreadPanes?: number
   rev?: number
+  usage?: ProcessUsage
+}
+
+export type ProcessUsage = {
+  processes: number
+  cpuPercent: number
+  rssBytes: number
+}
+
+export type SessionUsage = ProcessUsage & {
+  session: string
 }
 
 export type SessionPreset = {
@@ -92,6 +106,46 @@ export type LaunchTmuxLauncherResponse = {
   managedWindowId: string
 }
 
+export type WatchRuleSeverity = 'info' | 'warning' | 'critical'
+
+export type WatchRule = {
+  id: string
+  name: string
+  pattern: string
+  severity: WatchRuleSeverity
+  session?: string
+  paneId?: string
+  enabled: boolean
+  createdAt: string
+  updatedAt: string
+}
+
+export type WatchRulesResponse = {
+  rules: Array<WatchRule>
+}
+
+export type TmuxWatchMatch = {
+  globalRev: number
+  action: 'matched'
+  rule: WatchRule
+  session: string
+  paneId: string
+  line: string
+  count: number
+}
+
+export type TmuxPaneExit = {
+  globalRev: number
+  action: 'exited' | 'oom-killed'
+  session: string
+  paneId: string
+  command: string
+  status?: number
+  signal?: number
+  pid?: number
+  process?: string
+}
+
 export type ConnectionState = 'connected' | 'connecting' | 'disconnected' | 'error'
 
 export type SessionsResponse = {
@@ -124,6 +178,8 @@ export type PaneInfo = {
   paneId: string
   title: string
   active: boolean
+  zoomed?: boolean
+  pid?: number
   tty: string
   currentPath?: string
   startCommand?: string
@@ -150,12 +206,24 @@ export type StorageResourceStat = {
   approxBytes: number
 }
 
+export type StorageDatabaseStats = {
+  journalMode: string
+  synchronous: string
+  busyTimeoutMs: number
+  cacheSize: number
+  openConnections: number
+  inUse: number
+  waitCount: number
+  waitDurationMs: number
+}
+
 export type StorageStatsResponse = {
   databaseBytes: number
   walBytes: number
   shmBytes: number
   totalBytes: number
   resources: Array<StorageResourceStat>
+  database: StorageDatabaseStats
   collectedAt: string
 }
 
@@ -182,6 +250,30 @@ export type OpsServiceStatus = {
   activeState: string
   lastRunState?: string
   updatedAt: string
+  health?: OpsServiceHealth
+  hooks?: Array<OpsServiceHookResult>
+}
+
+export type OpsServiceHookResult = {
+  hook: 'preStop' | 'postStart'
+  command: string
+  ok: boolean
+  detail?: string
+  durationMs: number
+}
+
+export type OpsServiceHealthCheckResult = {
+  type: 'http' | 'tcp' | 'command'
+  target: string
+  ok: boolean
+  detail?: string
+  durationMs: number
+}
+
+export type OpsServiceHealth = {
+  healthy: boolean
+  checkedAt: string
+  checks: Array<OpsServiceHealthCheckResult>
 }
 
 export type OpsServiceInspect = {
@@ -209,9 +301,29 @@ export type OpsOverview = {
     active: number
     failed: number
   }
+  packages?: OpsPackageUpdates
+  uptime?: OpsUptimeSummary
   updatedAt: string
 }
 
+export type OpsPendingPackage = {
+  name: string
+  version: string
+  security: boolean
+}
+
+export type OpsPackageUpdates = {
+  manager: '' | 'apt' | 'dnf' | 'yum'
+  pending: number
+  security: number
+  packages: Array<OpsPendingPackage>
+  rebootRequired: boolean
+  rebootPackages?: Array<string>
+  checkedAt?: string
+  checking: boolean
+  error?: string
+}
+
 export type OpsOverviewResponse = {
   overview: OpsOverview
 }
@@ -220,6 +332,44 @@ export type OpsServicesResponse = {
   services: Array<OpsServiceStatus>
 }
 
+export type OpsListeningPort = {
+  protocol: string
+  address: string
+  port: number
+  pid?: number
+  process?: string
+  service?: string
+  unit?: string
+  session?: string
+  paneId?: string
+}
+
+export type OpsPortsResponse = {
+  ports: Array<OpsListeningPort>
+}
+
+export type OpsLoginSession = {
+  user: string
+  tty: string
+  host?: string
+  loginAt?: string
+}
+
+export type OpsAuthEvent = {
+  at: string
+  user: string
+  host: string
+  port?: number
+  method?: string
+  result: 'accepted' | 'failed' | 'invalid-user'
+}
+
+export type OpsLoginsResponse = {
+  sessions: Array<OpsLoginSession>
+  events: Array<OpsAuthEvent>
+  checkedAt: string
+}
+
 export type OpsRunbookStepType = 'run' | 'script' | 'approval'
 
 export type OpsRunbookStep = {
@@ -232,6 +382,12 @@ export type OpsRunbookStep = {
   timeout?: number
   retries?: number
   retryDelay?: number
+  retryBackoff?: number
+  retryMaxDelay?: number
+  retryOnExitCodes?: Array<number>
+  cwd?: string
+  runAsUser?: string
+  shell?: string
 }
 
 export type RunbookParameterType = 'string' | 'number' | 'boolean' | 'select'
@@ -253,6 +409,7 @@ export type OpsRunbook = {
   webhookURL?: string
   parameters?: Array<RunbookParameter>
   steps: Array<OpsRunbookStep>
+  managedBy?: string
   createdAt: string
   updatedAt: string
 }
@@ -264,6 +421,8 @@ export type OpsRunbookStepResult = {
   output: string
   error: string
   durationMs: number
+  retries?: number
+  exitCode?: number
 }
 
 export type OpsRunbookRun = {
@@ -277,11 +436,27 @@ export type OpsRunbookRun = {
   error: string
   stepResults: Array<OpsRunbookStepResult>
   parametersUsed?: Record<string, string>
+  hosts?: string
   createdAt: string
   startedAt?: string
   finishedAt?: string
 }
 
+export type OpsWebhook = {
+  id: string
+  name: string
+  provider: 'generic' | 'github' | 'gitlab'
+  runbookId: string
+  parameters: Record<string, string>
+  repository: string
+  branch: string
+  events: Array<'push' | 'tag' | 'release'>
+  enabled: boolean
+  createdAt: string
+  updatedAt: string
+  lastTriggeredAt: string
+}
+
 export type OpsSchedule = {
   id: string
   runbookId: string
@@ -290,10 +465,15 @@ export type OpsSchedule = {
   cronExpr: string
   timezone: string
   runAt: string
+  interval?: string
+  jitter?: string
+  concurrencyPolicy?: 'forbid' | 'allow' | 'replace'
+  hosts: string
   enabled: boolean
   lastRunAt: string
   lastRunStatus: string
   nextRunAt: string
+  managedBy?: string
   createdAt: string
   updatedAt: string
 }
@@ -351,9 +531,16 @@ export type OpsHostMetrics = {
   diskInodesUsed: number
   diskInodesTotal: number
   diskInodesPercent: number
+  diskMounts?: OpsDiskMount[]
   netRxBytes: number
   netTxBytes: number
   netInterfaces: number
+  netRxBytesPerSec: number
+  netTxBytesPerSec: number
+  netRxErrors: number
+  netTxErrors: number
+  netSaturationPercent: number
+  networkInterfaces?: OpsNetworkInterface[]
   processCount: number
   threadCount: number
   hostUptimeSec: number
@@ -374,6 +561,69 @@ export type OpsMetricsResponse = {
   metrics: OpsHostMetrics
 }
 
+export type OpsNetworkInterface = {
+  name: string
+  rxBytes: number
+  txBytes: number
+  rxBytesPerSec: number
+  txBytesPerSec: number
+  rxErrors: number
+  txErrors: number
+  rxDropped: number
+  txDropped: number
+  speedMbps?: number
+  saturationPercent: number
+}
+
+export type OpsUPSStatus = {
+  source: 'nut' | 'apcupsd'
+  name?: string
+  model?: string
+  state: 'online' | 'on-battery' | 'low-battery' | 'unknown'
+  raw: string
+  onBattery: boolean
+  lowBattery: boolean
+  chargePercent: number
+  runtimeSec: number
+  loadPercent: number
+  checkedAt: string
+}
+
+export type OpsUPSAction = 'power-lost' | 'power-restored' | 'shutdown'
+
+export type OpsDiskMount = {
+  mountpoint: string
+  device: string
+  fsType: string
+  usedBytes: number
+  totalBytes: number
+  freeBytes: number
+  percent: number
+  inodesUsed: number
+  inodesTotal: number
+  inodesPercent: number
+}
+
+export type OpsDirectoryUsage = {
+  path: string
+  sizeBytes: number
+}
+
+export type OpsDiskScan = {
+  root: string
+  sizeBytes: number
+  directories: OpsDirectoryUsage[]
+  skipped?: number
+  error?: string
+}
+
+export type OpsDiskResponse = {
+  mounts: OpsDiskMount[]
+  roots: OpsDiskScan[]
+  scannedAt?: string
+  scanning: boolean
+}
+
 export type OpsCustomServiceWrite = {
   name: string
   displayName: string
@@ -406,6 +656,10 @@ export type OpsBrowsedService = {
   scope: string
   tracked: boolean
   trackedName?: string
+  nextElapse?: string
+  lastTrigger?: string
+  listen?: string[]
+  activates?: string[]
 }
 
 export type OpsBrowseServicesResponse = {
@@ -423,6 +677,14 @@ export type OpsUnitLogsResponse = {
   output: string
 }
 
+export type FederatedHost = {
+  name: string
+  version: string
+  transport: 'agent' | 'ssh'
+  remoteAddr: string
+  connectedAt: string
+}
+
 export type WebhookSettings = {
   url: string
   events: Array<string>
@@ -433,6 +695,147 @@ export type WebhookTestResponse = {
   message: string
 }
 
+export type OpsCertificateStatus = {
+  target: string
+  kind: 'file' | 'endpoint'
+  state: 'valid' | 'expiring' | 'expired' | 'error'
+  subject?: string
+  issuer?: string
+  dnsNames?: Array<string>
+  notBefore?: string
+  notAfter?: string
+  daysLeft: number
+  error?: string
+}
+
+export type OpsCertificatesResponse = {
+  certificates: Array<OpsCertificateStatus>
+  warnDays: number
+  checkedAt?: string
+}
+
+export type OpsUptimeStatus = 'pending' | 'up' | 'down'
+
+export type OpsUptimeCheck = {
+  id: string
+  name: string
+  type: 'http' | 'tcp' | 'icmp'
+  target: string
+  intervalSeconds: number
+  timeoutSeconds: number
+  expectStatus: number
+  expectBody: string
+  enabled: boolean
+  createdAt: string
+  updatedAt: string
+  status: OpsUptimeStatus
+  detail?: string
+  latencyMs: number
+  lastCheckedAt: string
+  lastChangeAt: string
+}
+
+export type OpsUptimeSummary = {
+  total: number
+  up: number
+  down: number
+  pending: number
+}
+
+export type OpsUptimeChecksResponse = {
+  checks: Array<OpsUptimeCheck>
+}
+
+export type OpsHeartbeatStatus = 'new' | 'up' | 'missed'
+
+export type OpsHeartbeat = {
+  id: string
+  name: string
+  intervalSeconds: number
+  graceSeconds: number
+  enabled: boolean
+  createdAt: string
+  updatedAt: string
+  status: OpsHeartbeatStatus
+  lastPingAt: string
+  lastChangeAt: string
+}
+
+export type OpsHeartbeatsResponse = {
+  heartbeats: Array<OpsHeartbeat>
+}
+
+export type OpsBackupTool = 'restic' | 'borg'
+
+export type OpsBackupStatus = 'new' | 'running' | 'succeeded' | 'failed'
+
+export type OpsBackupStats = {
+  snapshotId?: string
+  files: number
+  filesNew: number
+  filesChanged: number
+  bytesProcessed: number
+  bytesAdded: number
+  durationSeconds: number
+}
+
+export type OpsBackup = {
+  id: string
+  name: string
+  tool: OpsBackupTool
+  repository: string
+  sources: Array<string>
+  command: string
+  schedule: string
+  timezone: string
+  maxAgeHours: number
+  timeoutMinutes: number
+  enabled: boolean
+  createdAt: string
+  updatedAt: string
+  status: OpsBackupStatus
+  stale: boolean
+  lastJobId: string
+  lastRunAt: string
+  lastFinishedAt: string
+  lastSuccessAt: string
+  lastError?: string
+  stats: OpsBackupStats
+}
+
+export type OpsBackupsResponse = {
+  backups: Array<OpsBackup>
+}
+
+export type OpsSecret = {
+  name: string
+  createdAt: string
+  updatedAt: string
+}
+
+export type OpsSecretsResponse = {
+  secrets: Array<OpsSecret>
+}
+
+export type OpsLibrarySync = {
+  syncedAt: string
+  revision?: string
+  files: number
+  created: number
+  updated: number
+  deleted: number
+  errors: Array<{ file: string; error: string }>
+  gitError?: string
+  error?: string
+}
+
+export type OpsLibraryStatus = {
+  dir: string
+  gitUrl?: string
+  gitBranch?: string
+  last: OpsLibrarySync | null
+}
+
 export type OpsWsMessage =
   | { type: 'ops.overview.updated'; payload: { overview: OpsOverview } }
   | {
@@ -442,3 +845,61 @@ export type OpsWsMessage =
   | { type: 'ops.metrics.updated'; payload: { metrics: OpsHostMetrics } }
   | { type: 'ops.job.updated'; payload: { job: OpsRunbookRun } }
   | { type: 'ops.schedule.updated'; payload: Record<string, unknown> }
+  | { type: 'ops.hosts.updated'; payload: Record<string, unknown> }
+  | {
+      type: 'ops.ups.updated'
+      payload: { action: OpsUPSAction; ups: OpsUPSStatus }
+    }
+  | {
+      type: 'ops.logins.updated'
+      payload: {
+        action: 'alert'
+        reasons: Array<'root-login' | 'new-address'>
+        event: OpsAuthEvent
+      }
+    }
+  | {
+      type: 'ops.certificates.updated'
+      payload: {
+        action: 'expiring' | 'expired'
+        certificate: OpsCertificateStatus
+      }
+    }
+  | {
+      type: 'ops.uptime.updated'
+      payload: {
+        action: OpsUptimeStatus
+        previous: OpsUptimeStatus
+        check: OpsUptimeCheck
+      }
+    }
+  | {
+      type: 'ops.heartbeats.updated'
+      payload: {
+        action: 'up' | 'missed'
+        previous?: OpsHeartbeatStatus
+        heartbeat: OpsHeartbeat
+      }
+    }
+  | {
+      type: 'ops.backups.updated'
+      payload: {
+        action: 'started' | 'succeeded' | 'failed' | 'stale'
+        backup: OpsBackup
+      }
+    }
+  | { type: 'ops.library.updated'; payload: { library: OpsLibrarySync } }
+
+export type TerminalRecording = {
+  id: string
+  session: string
+  width: number
+  height: number
+  startedAt: string
+  updatedAt: string
+  size: number
+}
+
+export type TerminalRecordingsResponse = {
+  recordings: Array<TerminalRecording>
+}
@@ -17,8 +17,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/opus-domini/sentinel/internal/backup"
 	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/jobqueue"
+	"github.com/opus-domini/sentinel/internal/library"
+	"github.com/opus-domini/sentinel/internal/logging"
+	"github.com/opus-domini/sentinel/internal/proc"
 	"github.com/opus-domini/sentinel/internal/runbook"
+	"github.com/opus-domini/sentinel/internal/secrets"
 	"github.com/opus-domini/sentinel/internal/security"
 	opsplane "github.com/opus-domini/sentinel/internal/services"
 	"github.com/opus-domini/sentinel/internal/store"
@@ -49,7 +55,20 @@ type tmuxService interface {
 	KillWindow(ctx context.Context, session string, index int) error
 	KillPane(ctx context.Context, paneID string) error
 	SplitPane(ctx context.Context, paneID, direction string) (string, error)
+	MoveWindow(ctx context.Context, session string, index int, targetSession string) error
+	SwapPane(ctx context.Context, paneID, targetPaneID string) error
+	ZoomPane(ctx context.Context, paneID string) error
+	PanePID(ctx context.Context, paneID string) (int, error)
+	RotateWindow(ctx context.Context, session string, index int, direction string) error
 	SendKeys(ctx context.Context, paneID, keys string, enter bool) error
+	CapturePaneRange(ctx context.Context, paneID string, opts tmux.CaptureRangeOptions) (tmux.PaneCapture, error)
+	ListBuffers(ctx context.Context) ([]tmux.Buffer, error)
+	ShowBuffer(ctx context.Context, name string) (string, error)
+	SetBuffer(ctx context.Context, name, content string) error
+	PasteBuffer(ctx context.Context, name, paneID string) error
+	ShowEnvironment(ctx context.Context, session string) ([]tmux.EnvVar, error)
+	SetEnvironment(ctx context.Context, session, name, value string) error
+	UnsetEnvironment(ctx context.Context, session, name string) error
 }
 
 type opsControlPlane interface {
@@ -57,13 +76,22 @@ type opsControlPlane interface {
 	ListServices(ctx context.Context) ([]opsplane.ServiceStatus, error)
 	Act(ctx context.Context, name, action string) (opsplane.ServiceStatus, error)
 	Inspect(ctx context.Context, name string) (opsplane.ServiceInspect, error)
-	Logs(ctx context.Context, name string, lines int) (string, error)
+	Logs(ctx context.Context, name string, query opsplane.LogQuery) (string, error)
 	Metrics(ctx context.Context) opsplane.HostMetrics
+	DiskUsage(ctx context.Context, refresh bool) opsplane.DiskUsage
+	UPS(ctx context.Context) (opsplane.UPSStatus, error)
+	Logins(ctx context.Context, since time.Time) (opsplane.Logins, error)
+	PackageUpdates(refresh bool) (opsplane.PackageUpdates, error)
+	Certificates(ctx context.Context, refresh bool) opsplane.Certificates
 	DiscoverServices(ctx context.Context) ([]opsplane.AvailableService, error)
 	BrowseServices(ctx context.Context) ([]opsplane.BrowsedService, error)
 	ActByUnit(ctx context.Context, unit, scope, manager, action string) error
 	InspectByUnit(ctx context.Context, unit, scope, manager string) (opsplane.ServiceInspect, error)
-	LogsByUnit(ctx context.Context, unit, scope, manager string, lines int) (string, error)
+	LogsByUnit(ctx context.Context, unit, scope, manager string, query opsplane.LogQuery) (string, error)
+	StreamLogs(ctx context.Context, name, priority string) (io.ReadCloser, error)
+	StreamLogsByUnit(ctx context.Context, unit, scope, manager, priority string) (io.ReadCloser, error)
+	ListeningPorts(ctx context.Context) ([]opsplane.ListeningPort, error)
+	StartUpdate(ctx context.Context) (opsplane.ServiceStatus, error)
 }
 
 type mcpSettings interface {
@@ -85,6 +113,11 @@ type sessionMetaRepo interface {
 	SetIcon(ctx context.Context, name, icon string) error
 }
 
+type sessionAnnotationRepo interface {
+	SetSessionTags(ctx context.Context, name, group string, tags []string) error
+	SetSessionNotes(ctx context.Context, name, notes string) error
+}
+
 type sessionOrderRepo interface {
 	MoveSessionToFront(ctx context.Context, name string) error
 	ReorderSessions(ctx context.Context, names []string) error
@@ -110,21 +143,33 @@ type watchtowerMarkRepo interface {
 type presenceRepo interface {
 	UpsertWatchtowerPresence(ctx context.Context, row store.WatchtowerPresenceWrite) error
 	ListWatchtowerJournalSince(ctx context.Context, sinceRev int64, limit int) ([]store.WatchtowerJournal, error)
+	ListWatchtowerActivityBuckets(ctx context.Context, since time.Time) ([]store.WatchtowerActivityBucket, error)
 	GetWatchtowerRuntimeValue(ctx context.Context, key string) (string, error)
 }
 
 type opsJobRepo interface {
-	CreateOpsRunbookRun(ctx context.Context, runbookID string, at time.Time) (store.OpsRunbookRun, error)
+	CreateOpsRunbookRunForHosts(ctx context.Context, runbookID string, at time.Time, params map[string]string, hosts string) (store.OpsRunbookRun, error)
 	DeleteOpsRunbookRun(ctx context.Context, runID string) error
 }
 
 type opsScheduleRepo interface {
 	ListOpsSchedules(ctx context.Context) ([]store.OpsSchedule, error)
+	GetOpsSchedule(ctx context.Context, id string) (store.OpsSchedule, error)
 	InsertOpsSchedule(ctx context.Context, w store.OpsScheduleWrite) (store.OpsSchedule, error)
 	UpdateOpsSchedule(ctx context.Context, w store.OpsScheduleWrite) (store.OpsSchedule, error)
 	DeleteOpsSchedule(ctx context.Context, id string) error
+}
+
+type opsScheduleRunRepo interface {
 	UpdateScheduleAfterRun(ctx context.Context, id, lastRunAt, lastRunStatus, nextRunAt string, enabled bool) error
 	UpdateScheduleLastRun(ctx context.Context, id, lastRunAt, lastRunStatus string) error
+	ListScheduleRuns(ctx context.Context, scheduleID string, limit int) ([]store.OpsScheduleRun, error)
+}
+
+type opsHostRepo interface {
+	ListOpsHosts(ctx context.Context) ([]store.OpsHost, error)
+	SetOpsHostLabels(ctx context.Context, name string, labels map[string]string) (store.OpsHost, error)
+	DeleteOpsHost(ctx context.Context, name string) error
 }
 
 type customServicesRepo interface {
@@ -135,6 +180,13 @@ type customServicesRepo interface {
 type storageRepo interface {
 	GetStorageStats(ctx context.Context) (store.StorageStats, error)
 	FlushStorageResource(ctx context.Context, resource string) ([]store.StorageFlushResult, error)
+	Backup(ctx context.Context, dir string, keep int, now time.Time) (store.BackupInfo, error)
+	Maintain(ctx context.Context, progress func(store.MaintenanceStep)) (store.MaintenanceReport, error)
+	Ping(ctx context.Context) error
+}
+
+type metricsHistoryRepo interface {
+	QueryMetricsHistory(ctx context.Context, from, to time.Time, step time.Duration) (store.MetricsHistory, error)
 }
 
 type sessionDirectoryRepo interface {
@@ -192,17 +244,67 @@ type sessionUserRepo interface {
 	RenameSessionUser(ctx context.Context, oldName, newName string) error
 }
 
+type opsWebhookRepo interface {
+	ListOpsWebhooks(ctx context.Context) ([]store.OpsWebhook, error)
+	CreateOpsWebhook(ctx context.Context, w store.OpsWebhookWrite) (store.OpsWebhook, string, error)
+	UpdateOpsWebhook(ctx context.Context, w store.OpsWebhookWrite) (store.OpsWebhook, error)
+	DeleteOpsWebhook(ctx context.Context, id string) error
+}
+
+type opsWebhookDeliveryRepo interface {
+	GetOpsWebhook(ctx context.Context, id string) (store.OpsWebhook, error)
+	MarkOpsWebhookTriggered(ctx context.Context, id string, at time.Time) error
+}
+
+type opsUptimeRepo interface {
+	ListOpsUptimeChecks(ctx context.Context) ([]store.OpsUptimeCheck, error)
+	CreateOpsUptimeCheck(ctx context.Context, w store.OpsUptimeCheckWrite) (store.OpsUptimeCheck, error)
+	UpdateOpsUptimeCheck(ctx context.Context, w store.OpsUptimeCheckWrite) (store.OpsUptimeCheck, error)
+	DeleteOpsUptimeCheck(ctx context.Context, id string) error
+}
+
+type opsHeartbeatRepo interface {
+	ListOpsHeartbeats(ctx context.Context) ([]store.OpsHeartbeat, error)
+	CreateOpsHeartbeat(ctx context.Context, w store.OpsHeartbeatWrite) (store.OpsHeartbeat, error)
+	UpdateOpsHeartbeat(ctx context.Context, w store.OpsHeartbeatWrite) (store.OpsHeartbeat, error)
+	DeleteOpsHeartbeat(ctx context.Context, id string) error
+	PingOpsHeartbeat(ctx context.Context, id string, at time.Time) (store.OpsHeartbeat, string, error)
+}
+
+type opsBackupRepo interface {
+	ListOpsBackups(ctx context.Context) ([]store.OpsBackup, error)
+	CreateOpsBackup(ctx context.Context, w store.OpsBackupWrite) (store.OpsBackup, error)
+	UpdateOpsBackup(ctx context.Context, w store.OpsBackupWrite) (store.OpsBackup, error)
+}
+
+type watchRuleRepo interface {
+	ListWatchRules(ctx context.Context) ([]store.WatchRule, error)
+	CreateWatchRule(ctx context.Context, w store.WatchRuleWrite) (store.WatchRule, error)
+	UpdateWatchRule(ctx context.Context, w store.WatchRuleWrite) (store.WatchRule, error)
+	DeleteWatchRule(ctx context.Context, id string) error
+}
+
+type apiKeyRepo interface {
+	ListAPIKeys(ctx context.Context) ([]store.APIKey, error)
+	CreateAPIKey(ctx context.Context, w store.APIKeyWrite) (store.APIKey, string, error)
+	DeleteAPIKey(ctx context.Context, id string) error
+}
+
 type handlerRepo interface {
 	runbook.Repo
 	sessionMetaRepo
+	sessionAnnotationRepo
 	sessionOrderRepo
 	watchtowerReadRepo
 	watchtowerMarkRepo
 	presenceRepo
 	opsJobRepo
 	opsScheduleRepo
+	opsScheduleRunRepo
+	opsHostRepo
 	customServicesRepo
 	storageRepo
+	metricsHistoryRepo
 	sessionDirectoryRepo
 	sessionPresetRepo
 	sessionLauncherRepo
@@ -210,6 +312,13 @@ type handlerRepo interface {
 	tmuxLauncherWriteRepo
 	managedTmuxWindowRepo
 	sessionUserRepo
+	apiKeyRepo
+	opsWebhookRepo
+	opsWebhookDeliveryRepo
+	opsUptimeRepo
+	opsHeartbeatRepo
+	opsBackupRepo
+	watchRuleRepo
 }
 
 // Compile-time check: *store.Store satisfies handlerRepo.
@@ -242,6 +351,50 @@ type Handler struct {
 	runCancel context.CancelFunc
 	wg        sync.WaitGroup
 	runbooks  *runbook.Manager
+	backups   *backup.Service
+
+	// secrets is nil unless the master key could be loaded.
+	secrets *secrets.Vault
+
+	// library is nil unless a library directory or repository is set.
+	library *library.Service
+
+	// paneLog is nil unless watchtower pane logging is enabled.
+	paneLog paneLogSearcher
+
+	// recordings is nil unless terminal recording is enabled.
+	recordings recordingStore
+
+	// procs inspects and signals processes running inside panes.
+	procs processController
+
+	// backupDir and backupKeep configure on-demand database backups.
+	backupDir  string
+	backupKeep int
+
+	// limiter is nil when rate limiting is disabled.
+	limiter *rateLimiter
+
+	// updateDataDir, updateChannel and updatePublicKey configure the update
+	// endpoints; updateCheck defaults to updater.Check.
+	updateDataDir   string
+	updateChannel   string
+	updatePublicKey string
+	updateCheck     updateChecker
+
+	// hosts is nil unless federation is enabled.
+	hosts hostRelay
+
+	// files is nil unless file roots are configured.
+	files          filesBrowser
+	maxUploadBytes int64
+
+	// watchtowerTick is the watchtower collect interval checked by
+	// GET /readyz; zero while watchtower is disabled.
+	watchtowerTick time.Duration
+
+	// opsDeltas stamps service and metric changes for GET /api/ops/delta.
+	opsDeltas opsDeltaTracker
 }
 
 const (
@@ -253,6 +406,7 @@ const (
 	stateFailed                  = "failed"
 	scheduleTypeCron             = "cron"
 	scheduleTypeOnce             = "once"
+	scheduleTypeInterval         = "interval"
 	stepTypeApproval             = "approval"
 	defaultTimezoneUTC           = "UTC"
 )
@@ -278,11 +432,8 @@ func Register(
 	timezone string,
 	locale string,
 	mcpSettings mcpSettings,
-	runbookMaxConcurrent int,
+	jobs *jobqueue.Queue,
 ) *Handler {
-	if runbookMaxConcurrent <= 0 {
-		runbookMaxConcurrent = 5
-	}
 	runCtx, runCancel := context.WithCancel(context.Background())
 	h := &Handler{
 		guard:            guard,
@@ -296,16 +447,24 @@ func Register(
 		locale:           locale,
 		mcpSettings:      mcpSettings,
 		userSwitchMethod: tmux.UserSwitchMethod,
+		procs:            proc.System{},
 		runCtx:           runCtx,
 		runCancel:        runCancel,
 	}
-	h.runbooks = runbook.NewManager(st, h.emitEvent, runbookMaxConcurrent)
+	if jobs != nil {
+		h.runbooks = runbook.NewManagerWithQueue(st, h.emitEvent, jobs)
+	} else {
+		h.runbooks = runbook.NewManager(st, h.emitEvent, 5)
+	}
+	h.runbooks.SetUserPolicy(guard.ValidateTargetUser)
+	h.backups = backup.New(st, h.runbooks)
 	h.registerMetaRoutes(mux)
 	h.registerTmuxRoutes(mux)
 	h.registerServicesRoutes(mux)
 	h.registerRunbooksRoutes(mux)
 	h.registerMetricsRoutes(mux)
 	h.registerSettingsRoutes(mux)
+	h.registerDebugRoutes(mux)
 	h.populateSessionUsersFromPresets(context.Background())
 	return h
 }
@@ -340,6 +499,14 @@ func (h *Handler) RunbookManager() *runbook.Manager {
 	return h.runbooks
 }
 
+// Backups returns the service running restic and borg backups.
+func (h *Handler) Backups() *backup.Service {
+	if h == nil {
+		return nil
+	}
+	return h.backups
+}
+
 func (h *Handler) emit(eventType string, payload map[string]any) {
 	if h == nil || h.events == nil {
 		return
@@ -347,7 +514,7 @@ func (h *Handler) emit(eventType string, payload map[string]any) {
 	h.events.Publish(events.NewEvent(eventType, payload))
 }
 
-func (h *Handler) meta(w http.ResponseWriter, _ *http.Request) {
+func (h *Handler) meta(w http.ResponseWriter, r *http.Request) {
 	defaultCwd := defaultSessionCWD()
 	version := strings.TrimSpace(h.version)
 	if version == "" {
@@ -377,6 +544,9 @@ func (h *Handler) meta(w http.ResponseWriter, _ *http.Request) {
 	data["canSwitchUser"] = len(h.guard.SystemUsers()) > 0
 	data["allowedUsers"] = h.guard.AllowedUsers()
 	data["userSwitchMethod"] = strings.TrimSpace(h.userSwitchMethod)
+	if id, ok := security.IdentityFromContext(r.Context()); ok {
+		data[keyIdentity] = id
+	}
 
 	writeData(w, http.StatusOK, data)
 }
@@ -405,13 +575,14 @@ func (h *Handler) setAuthToken(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "token is required", nil)
 		return
 	}
-	if !h.guard.TokenMatches(token) {
+	id, err := h.guard.AuthenticateToken(r.Context(), token)
+	if err != nil {
 		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "missing or invalid token", nil)
 		return
 	}
 
-	h.guard.SetAuthCookie(w, r)
-	writeData(w, http.StatusOK, map[string]any{keyAuthenticated: true})
+	h.guard.SetAuthCookieToken(w, r, token)
+	writeData(w, http.StatusOK, map[string]any{keyAuthenticated: true, keyIdentity: id})
 }
 
 func (h *Handler) clearAuthToken(w http.ResponseWriter, r *http.Request) {
@@ -596,31 +767,55 @@ func (h *Handler) originFailure(err error) (string, string, map[string]any) {
 }
 
 func (h *Handler) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return h.wrapRole(security.RoleViewer, next)
+}
+
+func (h *Handler) wrapRole(required security.Role, next http.HandlerFunc) http.HandlerFunc {
 	return h.wrapOrigin(func(w http.ResponseWriter, r *http.Request) {
-		if err := h.guard.RequireAuth(r); err != nil {
+		if !h.checkClientRateLimit(w, r) {
+			return
+		}
+		id, err := h.guard.Authorize(r, required)
+		switch {
+		case errors.Is(err, security.ErrForbidden):
+			writeError(w, http.StatusForbidden, "FORBIDDEN", "role does not allow this action", map[string]any{
+				"role":         id.Role,
+				"requiredRole": required,
+			})
+			return
+		case err != nil:
 			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "missing or invalid token", nil)
 			return
 		}
-		next(w, r)
+		logging.SetActor(r.Context(), id.Name)
+		if !h.checkIdentityRateLimit(w, r, id) {
+			return
+		}
+		next(w, r.WithContext(security.WithIdentity(r.Context(), id)))
 	})
 }
 
 type enrichedSession struct {
-	Name          string `json:"name"`
-	Windows       int    `json:"windows"`
-	Panes         int    `json:"panes"`
-	Attached      int    `json:"attached"`
-	CreatedAt     string `json:"createdAt"`
-	ActivityAt    string `json:"activityAt"`
-	Command       string `json:"command"`
-	Hash          string `json:"hash"`
-	LastContent   string `json:"lastContent"`
-	Icon          string `json:"icon"`
-	User          string `json:"user,omitempty"`
-	SortOrder     int    `json:"sortOrder"`
-	UnreadWindows int    `json:"unreadWindows"`
-	UnreadPanes   int    `json:"unreadPanes"`
-	Rev           int64  `json:"rev"`
+	Name          string   `json:"name"`
+	Windows       int      `json:"windows"`
+	Panes         int      `json:"panes"`
+	Attached      int      `json:"attached"`
+	CreatedAt     string   `json:"createdAt"`
+	ActivityAt    string   `json:"activityAt"`
+	Command       string   `json:"command"`
+	Hash          string   `json:"hash"`
+	LastContent   string   `json:"lastContent"`
+	Icon          string   `json:"icon"`
+	Tags          []string `json:"tags,omitempty"`
+	Group         string   `json:"group,omitempty"`
+	Notes         string   `json:"notes,omitempty"`
+	User          string   `json:"user,omitempty"`
+	SortOrder     int      `json:"sortOrder"`
+	UnreadWindows int      `json:"unreadWindows"`
+	UnreadPanes   int      `json:"unreadPanes"`
+	Rev           int64    `json:"rev"`
+	// Usage is only filled when the list is requested with usage=true.
+	Usage *proc.Usage `json:"usage,omitempty"`
 }
 
 type enrichedWindow struct {
@@ -649,6 +844,7 @@ type enrichedPane struct {
 	PaneID         string `json:"paneId"`
 	Title          string `json:"title"`
 	Active         bool   `json:"active"`
+	Zoomed         bool   `json:"zoomed"`
 	TTY            string `json:"tty"`
 	CurrentPath    string `json:"currentPath,omitempty"`
 	StartCommand   string `json:"startCommand,omitempty"`
@@ -703,6 +899,8 @@ func writeTmuxError(w http.ResponseWriter, err error) {
 		writeError(w, http.StatusConflict, string(tmux.ErrKindSessionExists), "tmux session already exists", nil)
 	case tmux.IsKind(err, tmux.ErrKindServerNotRunning):
 		writeError(w, http.StatusServiceUnavailable, string(tmux.ErrKindServerNotRunning), "tmux server not running", nil)
+	case tmux.IsKind(err, tmux.ErrKindBufferNotFound):
+		writeError(w, http.StatusNotFound, string(tmux.ErrKindBufferNotFound), "tmux buffer not found", nil)
 	default:
 		writeError(w, http.StatusInternalServerError, string(tmux.ErrKindCommandFailed), "tmux command failed", nil)
 	}
@@ -25,7 +25,16 @@ func TestContractRoutesAreMountedByFeature(t *testing.T) {
 	mux := newContractMux(t)
 	routes := []contractRoute{
 		{name: "meta", method: http.MethodGet, path: "/api/meta"},
+		// An unknown type answers at once instead of opening the stream.
+		{name: "events-stream", method: http.MethodGet, path: "/api/events/stream?types=unknown"},
+		{name: "healthz", method: http.MethodGet, path: "/healthz"},
+		{name: "readyz", method: http.MethodGet, path: "/readyz"},
 		{name: "dirs", method: http.MethodGet, path: "/api/fs/dirs?prefix=/tmp"},
+		{name: "files", method: http.MethodGet, path: "/api/fs/files?path=/tmp"},
+		{name: "files-download", method: http.MethodGet, path: "/api/fs/files/download?path=/tmp/a.log"},
+		{name: "files-upload", method: http.MethodPost, path: "/api/fs/files/upload?dir=/tmp&name=a.log", body: "hello"},
+		{name: "files-rename", method: http.MethodPost, path: "/api/fs/files/rename", body: `{"path":"/tmp/a.log","name":"b.log"}`},
+		{name: "files-delete", method: http.MethodDelete, path: "/api/fs/files?path=/tmp/a.log"},
 
 		{name: "tmux-sessions", method: http.MethodGet, path: "/api/tmux/sessions"},
 		{name: "tmux-create", method: http.MethodPost, path: "/api/tmux/sessions", body: `{"name":"dev","cwd":"/tmp"}`},
@@ -47,17 +56,34 @@ func TestContractRoutesAreMountedByFeature(t *testing.T) {
 		{name: "tmux-launcher-reorder", method: http.MethodPatch, path: "/api/tmux/launchers/order", body: `{"ids":["l1","l2"]}`},
 		{name: "tmux-launcher-update", method: http.MethodPatch, path: "/api/tmux/launchers/launcher-1", body: `{"name":"Codex","icon":"code","command":"codex","cwdMode":"session","windowName":"codex"}`},
 		{name: "tmux-launcher-delete", method: http.MethodDelete, path: "/api/tmux/launchers/launcher-1"},
+		{name: "tmux-watch-rules", method: http.MethodGet, path: "/api/tmux/watch-rules"},
+		{name: "tmux-watch-rule-create", method: http.MethodPost, path: "/api/tmux/watch-rules", body: `{"name":"panic","pattern":"^panic:"}`},
+		{name: "tmux-watch-rule-update", method: http.MethodPut, path: "/api/tmux/watch-rules/noop", body: `{"name":"panic","pattern":"^panic:"}`},
+		{name: "tmux-watch-rule-delete", method: http.MethodDelete, path: "/api/tmux/watch-rules/noop"},
 		{name: "tmux-launcher-launch", method: http.MethodPost, path: "/api/tmux/sessions/dev/launchers/launcher-1/launch"},
 		{name: "tmux-rename", method: http.MethodPatch, path: "/api/tmux/sessions/dev", body: `{"newName":"dev2"}`},
 		{name: "tmux-delete", method: http.MethodDelete, path: "/api/tmux/sessions/dev"},
+		{name: "tmux-sessions-usage", method: http.MethodGet, path: "/api/tmux/sessions/usage"},
 		{name: "tmux-windows", method: http.MethodGet, path: "/api/tmux/sessions/dev/windows"},
 		{name: "tmux-panes", method: http.MethodGet, path: "/api/tmux/sessions/dev/panes"},
 		{name: "tmux-activity-delta", method: http.MethodGet, path: "/api/tmux/activity/delta"},
 		{name: "tmux-activity-stats", method: http.MethodGet, path: "/api/tmux/activity/stats"},
+		{name: "tmux-activity-heatmap", method: http.MethodGet, path: "/api/tmux/activity/heatmap?days=7"},
+		{name: "tmux-recordings", method: http.MethodGet, path: "/api/tmux/recordings"},
+		{name: "tmux-recording-download", method: http.MethodGet, path: "/api/tmux/recordings/20260301T120000Z-dev-abc123"},
+		{name: "tmux-recording-delete", method: http.MethodDelete, path: "/api/tmux/recordings/20260301T120000Z-dev-abc123"},
+		{name: "tmux-environment", method: http.MethodGet, path: "/api/tmux/sessions/dev/environment"},
+		{name: "tmux-environment-set", method: http.MethodPut, path: "/api/tmux/sessions/dev/environment/API_KEY", body: `{"value":"new"}`},
+		{name: "tmux-environment-unset", method: http.MethodDelete, path: "/api/tmux/sessions/dev/environment/API_KEY"},
+		{name: "tmux-buffers", method: http.MethodGet, path: "/api/tmux/buffers"},
+		{name: "tmux-buffer-get", method: http.MethodGet, path: "/api/tmux/buffers/clip"},
+		{name: "tmux-buffer-set", method: http.MethodPut, path: "/api/tmux/buffers/clip", body: `{"content":"hello"}`},
+		{name: "tmux-buffer-paste", method: http.MethodPost, path: "/api/tmux/buffers/clip/paste", body: `{"session":"dev","paneId":"%1"}`},
 		{name: "tmux-mark-seen", method: http.MethodPost, path: "/api/tmux/sessions/dev/seen", body: `{"scope":"session"}`},
 
 		{name: "ops-overview", method: http.MethodGet, path: "/api/ops/overview"},
 		{name: "ops-services", method: http.MethodGet, path: "/api/ops/services"},
+		{name: "ops-delta", method: http.MethodGet, path: "/api/ops/delta?since=0"},
 		{name: "ops-service-status", method: http.MethodGet, path: "/api/ops/services/sentinel/status"},
 		{name: "ops-service-action", method: http.MethodPost, path: "/api/ops/services/sentinel/action", body: `{"action":"restart"}`},
 		{name: "ops-services-browse", method: http.MethodGet, path: "/api/ops/services/browse"},
@@ -79,9 +105,28 @@ func TestContractRoutesAreMountedByFeature(t *testing.T) {
 		{name: "runs-reject", method: http.MethodPost, path: "/api/ops/runs/noop/reject"},
 		{name: "schedules-list", method: http.MethodGet, path: "/api/ops/schedules"},
 		{name: "schedules-create", method: http.MethodPost, path: "/api/ops/schedules", body: `{"runbookID":"noop","scheduleType":"once","timezone":"UTC","runAt":"2030-01-01T00:00:00Z","enabled":true}`},
+		{name: "schedules-preview", method: http.MethodPost, path: "/api/ops/schedules/preview", body: `{"cronExpr":"0 9 * * 1-5","timezone":"UTC"}`},
+		{name: "schedules-history", method: http.MethodGet, path: "/api/ops/schedules/noop/history"},
 		{name: "schedules-update", method: http.MethodPut, path: "/api/ops/schedules/noop", body: `{"runbookID":"noop","scheduleType":"once","timezone":"UTC","runAt":"2030-01-01T00:00:00Z","enabled":true}`},
 		{name: "schedules-delete", method: http.MethodDelete, path: "/api/ops/schedules/noop"},
 		{name: "schedules-trigger", method: http.MethodPost, path: "/api/ops/schedules/noop/trigger"},
+		{name: "webhooks-list", method: http.MethodGet, path: "/api/ops/webhooks"},
+		{name: "webhooks-create", method: http.MethodPost, path: "/api/ops/webhooks", body: `{"name":"ci","runbookId":"noop","enabled":true}`},
+		{name: "webhooks-update", method: http.MethodPut, path: "/api/ops/webhooks/noop", body: `{"name":"ci","runbookId":"noop","enabled":true}`},
+		{name: "webhooks-delete", method: http.MethodDelete, path: "/api/ops/webhooks/noop"},
+		{name: "webhooks-receive", method: http.MethodPost, path: "/api/hooks/noop", body: `{}`},
+		{name: "heartbeats-list", method: http.MethodGet, path: "/api/ops/heartbeats"},
+		{name: "heartbeats-create", method: http.MethodPost, path: "/api/ops/heartbeats", body: `{"name":"backup","intervalSeconds":3600}`},
+		{name: "heartbeats-update", method: http.MethodPut, path: "/api/ops/heartbeats/noop", body: `{"name":"backup","intervalSeconds":3600}`},
+		{name: "heartbeats-delete", method: http.MethodDelete, path: "/api/ops/heartbeats/noop"},
+		{name: "heartbeats-ping", method: http.MethodPost, path: "/api/hooks/heartbeat/noop"},
+		{name: "backups-list", method: http.MethodGet, path: "/api/ops/backups"},
+		{name: "backups-create", method: http.MethodPost, path: "/api/ops/backups", body: `{"name":"home","tool":"restic","repository":"/srv/restic","sources":["/home"]}`},
+		{name: "backups-update", method: http.MethodPut, path: "/api/ops/backups/noop", body: `{"name":"home","tool":"restic","repository":"/srv/restic","sources":["/home"]}`},
+		{name: "backups-delete", method: http.MethodDelete, path: "/api/ops/backups/noop"},
+		{name: "backups-run", method: http.MethodPost, path: "/api/ops/backups/noop/run"},
+		{name: "library-status", method: http.MethodGet, path: "/api/ops/library"},
+		{name: "library-sync", method: http.MethodPost, path: "/api/ops/library/sync"},
 
 		{name: "config-get", method: http.MethodGet, path: "/api/ops/config"},
 		{name: "config-patch", method: http.MethodPatch, path: "/api/ops/config", body: `{"logLevel":"info"}`},
@@ -89,6 +134,17 @@ func TestContractRoutesAreMountedByFeature(t *testing.T) {
 		{name: "settings-locale", method: http.MethodPatch, path: "/api/ops/settings/locale", body: `{"locale":"en-US"}`},
 		{name: "storage-stats", method: http.MethodGet, path: "/api/ops/storage/stats"},
 		{name: "storage-flush", method: http.MethodPost, path: "/api/ops/storage/flush", body: `{"resource":"activity-journal"}`},
+		{name: "secrets-list", method: http.MethodGet, path: "/api/ops/secrets"},
+		{name: "secrets-create", method: http.MethodPost, path: "/api/ops/secrets", body: `{"name":"token","value":"x"}`},
+		{name: "secrets-update", method: http.MethodPut, path: "/api/ops/secrets/noop", body: `{"value":"x"}`},
+		{name: "secrets-delete", method: http.MethodDelete, path: "/api/ops/secrets/noop"},
+
+		{name: "debug-runtime", method: http.MethodGet, path: "/api/debug/runtime"},
+		{name: "debug-goroutines", method: http.MethodGet, path: "/api/debug/goroutines"},
+		{name: "debug-gc", method: http.MethodPost, path: "/api/debug/gc"},
+		{name: "debug-vars", method: http.MethodGet, path: "/api/debug/vars"},
+		{name: "debug-pprof-index", method: http.MethodGet, path: "/api/debug/pprof/"},
+		{name: "debug-pprof-heap", method: http.MethodGet, path: "/api/debug/pprof/heap?debug=1"},
 	}
 
 	for _, tc := range routes {
@@ -130,7 +186,7 @@ func newContractMux(t *testing.T) *http.ServeMux {
 		"UTC",
 		"",
 		nil,
-		5,
+		nil,
 	)
 	return mux
 }
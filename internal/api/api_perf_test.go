@@ -61,7 +61,7 @@ func newPerfMux(tb testing.TB) *http.ServeMux {
 		"UTC",
 		"",
 		nil,
-		5,
+		nil,
 	)
 	return mux
 }
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -17,6 +18,7 @@ import (
 	"time"
 
 	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/jobqueue"
 	"github.com/opus-domini/sentinel/internal/runbook"
 	"github.com/opus-domini/sentinel/internal/security"
 	opsplane "github.com/opus-domini/sentinel/internal/services"
@@ -47,7 +49,20 @@ type mockTmux struct {
 	killWindowFn             func(ctx context.Context, session string, index int) error
 	killPaneFn               func(ctx context.Context, paneID string) error
 	splitPaneFn              func(ctx context.Context, paneID, direction string) (string, error)
+	moveWindowFn             func(ctx context.Context, session string, index int, targetSession string) error
+	swapPaneFn               func(ctx context.Context, paneID, targetPaneID string) error
+	zoomPaneFn               func(ctx context.Context, paneID string) error
+	panePIDFn                func(ctx context.Context, paneID string) (int, error)
+	rotateWindowFn           func(ctx context.Context, session string, index int, direction string) error
 	sendKeysFn               func(ctx context.Context, paneID, keys string, enter bool) error
+	capturePaneRangeFn       func(ctx context.Context, paneID string, opts tmux.CaptureRangeOptions) (tmux.PaneCapture, error)
+	listBuffersFn            func(ctx context.Context) ([]tmux.Buffer, error)
+	showBufferFn             func(ctx context.Context, name string) (string, error)
+	setBufferFn              func(ctx context.Context, name, content string) error
+	pasteBufferFn            func(ctx context.Context, name, paneID string) error
+	showEnvironmentFn        func(ctx context.Context, session string) ([]tmux.EnvVar, error)
+	setEnvironmentFn         func(ctx context.Context, session, name, value string) error
+	unsetEnvironmentFn       func(ctx context.Context, session, name string) error
 }
 
 func (m *mockTmux) ListSessions(ctx context.Context) ([]tmux.Session, error) {
@@ -169,6 +184,41 @@ func (m *mockTmux) KillPane(ctx context.Context, paneID string) error {
 	return nil
 }
 
+func (m *mockTmux) MoveWindow(ctx context.Context, session string, index int, targetSession string) error {
+	if m.moveWindowFn != nil {
+		return m.moveWindowFn(ctx, session, index, targetSession)
+	}
+	return nil
+}
+
+func (m *mockTmux) SwapPane(ctx context.Context, paneID, targetPaneID string) error {
+	if m.swapPaneFn != nil {
+		return m.swapPaneFn(ctx, paneID, targetPaneID)
+	}
+	return nil
+}
+
+func (m *mockTmux) ZoomPane(ctx context.Context, paneID string) error {
+	if m.zoomPaneFn != nil {
+		return m.zoomPaneFn(ctx, paneID)
+	}
+	return nil
+}
+
+func (m *mockTmux) PanePID(ctx context.Context, paneID string) (int, error) {
+	if m.panePIDFn != nil {
+		return m.panePIDFn(ctx, paneID)
+	}
+	return 0, nil
+}
+
+func (m *mockTmux) RotateWindow(ctx context.Context, session string, index int, direction string) error {
+	if m.rotateWindowFn != nil {
+		return m.rotateWindowFn(ctx, session, index, direction)
+	}
+	return nil
+}
+
 func (m *mockTmux) SplitPane(ctx context.Context, paneID, direction string) (string, error) {
 	if m.splitPaneFn != nil {
 		return m.splitPaneFn(ctx, paneID, direction)
@@ -183,18 +233,83 @@ func (m *mockTmux) SendKeys(ctx context.Context, paneID, keys string, enter bool
 	return nil
 }
 
+func (m *mockTmux) CapturePaneRange(ctx context.Context, paneID string, opts tmux.CaptureRangeOptions) (tmux.PaneCapture, error) {
+	if m.capturePaneRangeFn != nil {
+		return m.capturePaneRangeFn(ctx, paneID, opts)
+	}
+	return tmux.PaneCapture{PaneID: paneID}, nil
+}
+
+func (m *mockTmux) ListBuffers(ctx context.Context) ([]tmux.Buffer, error) {
+	if m.listBuffersFn != nil {
+		return m.listBuffersFn(ctx)
+	}
+	return []tmux.Buffer{}, nil
+}
+
+func (m *mockTmux) ShowBuffer(ctx context.Context, name string) (string, error) {
+	if m.showBufferFn != nil {
+		return m.showBufferFn(ctx, name)
+	}
+	return "", nil
+}
+
+func (m *mockTmux) SetBuffer(ctx context.Context, name, content string) error {
+	if m.setBufferFn != nil {
+		return m.setBufferFn(ctx, name, content)
+	}
+	return nil
+}
+
+func (m *mockTmux) PasteBuffer(ctx context.Context, name, paneID string) error {
+	if m.pasteBufferFn != nil {
+		return m.pasteBufferFn(ctx, name, paneID)
+	}
+	return nil
+}
+
+func (m *mockTmux) ShowEnvironment(ctx context.Context, session string) ([]tmux.EnvVar, error) {
+	if m.showEnvironmentFn != nil {
+		return m.showEnvironmentFn(ctx, session)
+	}
+	return []tmux.EnvVar{}, nil
+}
+
+func (m *mockTmux) SetEnvironment(ctx context.Context, session, name, value string) error {
+	if m.setEnvironmentFn != nil {
+		return m.setEnvironmentFn(ctx, session, name, value)
+	}
+	return nil
+}
+
+func (m *mockTmux) UnsetEnvironment(ctx context.Context, session, name string) error {
+	if m.unsetEnvironmentFn != nil {
+		return m.unsetEnvironmentFn(ctx, session, name)
+	}
+	return nil
+}
+
 type mockOpsControlPlane struct {
 	overviewFn      func(ctx context.Context) (opsplane.Overview, error)
 	listServicesFn  func(ctx context.Context) ([]opsplane.ServiceStatus, error)
 	actFn           func(ctx context.Context, name, action string) (opsplane.ServiceStatus, error)
 	inspectFn       func(ctx context.Context, name string) (opsplane.ServiceInspect, error)
-	logsFn          func(ctx context.Context, name string, lines int) (string, error)
+	logsFn          func(ctx context.Context, name string, query opsplane.LogQuery) (string, error)
 	metricsFn       func(ctx context.Context) opsplane.HostMetrics
 	discoverFn      func(ctx context.Context) ([]opsplane.AvailableService, error)
 	browseFn        func(ctx context.Context) ([]opsplane.BrowsedService, error)
 	actByUnitFn     func(ctx context.Context, unit, scope, manager, action string) error
 	inspectByUnitFn func(ctx context.Context, unit, scope, manager string) (opsplane.ServiceInspect, error)
-	logsByUnitFn    func(ctx context.Context, unit, scope, manager string, lines int) (string, error)
+	logsByUnitFn    func(ctx context.Context, unit, scope, manager string, query opsplane.LogQuery) (string, error)
+	portsFn         func(ctx context.Context) ([]opsplane.ListeningPort, error)
+	diskFn          func(ctx context.Context, refresh bool) opsplane.DiskUsage
+	upsFn           func(ctx context.Context) (opsplane.UPSStatus, error)
+	loginsFn        func(ctx context.Context, since time.Time) (opsplane.Logins, error)
+	packagesFn      func(refresh bool) (opsplane.PackageUpdates, error)
+	certificatesFn  func(ctx context.Context, refresh bool) opsplane.Certificates
+	streamLogsFn    func(ctx context.Context, name, priority string) (io.ReadCloser, error)
+	streamUnitFn    func(ctx context.Context, unit, scope, manager, priority string) (io.ReadCloser, error)
+	startUpdateFn   func(ctx context.Context) (opsplane.ServiceStatus, error)
 }
 
 func (m *mockOpsControlPlane) Overview(ctx context.Context) (opsplane.Overview, error) {
@@ -225,9 +340,9 @@ func (m *mockOpsControlPlane) Inspect(ctx context.Context, name string) (opsplan
 	return opsplane.ServiceInspect{}, nil
 }
 
-func (m *mockOpsControlPlane) Logs(ctx context.Context, name string, lines int) (string, error) {
+func (m *mockOpsControlPlane) Logs(ctx context.Context, name string, query opsplane.LogQuery) (string, error) {
 	if m.logsFn != nil {
-		return m.logsFn(ctx, name, lines)
+		return m.logsFn(ctx, name, query)
 	}
 	return "", nil
 }
@@ -239,6 +354,69 @@ func (m *mockOpsControlPlane) Metrics(ctx context.Context) opsplane.HostMetrics
 	return opsplane.HostMetrics{}
 }
 
+func (m *mockOpsControlPlane) DiskUsage(ctx context.Context, refresh bool) opsplane.DiskUsage {
+	if m.diskFn != nil {
+		return m.diskFn(ctx, refresh)
+	}
+	return opsplane.DiskUsage{}
+}
+
+func (m *mockOpsControlPlane) UPS(ctx context.Context) (opsplane.UPSStatus, error) {
+	if m.upsFn != nil {
+		return m.upsFn(ctx)
+	}
+	return opsplane.UPSStatus{}, opsplane.ErrUPSNotConfigured
+}
+
+func (m *mockOpsControlPlane) Logins(ctx context.Context, since time.Time) (opsplane.Logins, error) {
+	if m.loginsFn != nil {
+		return m.loginsFn(ctx, since)
+	}
+	return opsplane.Logins{}, nil
+}
+
+func (m *mockOpsControlPlane) PackageUpdates(refresh bool) (opsplane.PackageUpdates, error) {
+	if m.packagesFn != nil {
+		return m.packagesFn(refresh)
+	}
+	return opsplane.PackageUpdates{}, opsplane.ErrPackagesUnsupported
+}
+
+func (m *mockOpsControlPlane) Certificates(ctx context.Context, refresh bool) opsplane.Certificates {
+	if m.certificatesFn != nil {
+		return m.certificatesFn(ctx, refresh)
+	}
+	return opsplane.Certificates{Certificates: []opsplane.CertificateStatus{}}
+}
+
+func (m *mockOpsControlPlane) StreamLogs(ctx context.Context, name, priority string) (io.ReadCloser, error) {
+	if m.streamLogsFn != nil {
+		return m.streamLogsFn(ctx, name, priority)
+	}
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (m *mockOpsControlPlane) StreamLogsByUnit(ctx context.Context, unit, scope, manager, priority string) (io.ReadCloser, error) {
+	if m.streamUnitFn != nil {
+		return m.streamUnitFn(ctx, unit, scope, manager, priority)
+	}
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (m *mockOpsControlPlane) ListeningPorts(ctx context.Context) ([]opsplane.ListeningPort, error) {
+	if m.portsFn != nil {
+		return m.portsFn(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockOpsControlPlane) StartUpdate(ctx context.Context) (opsplane.ServiceStatus, error) {
+	if m.startUpdateFn != nil {
+		return m.startUpdateFn(ctx)
+	}
+	return opsplane.ServiceStatus{}, nil
+}
+
 func (m *mockOpsControlPlane) DiscoverServices(ctx context.Context) ([]opsplane.AvailableService, error) {
 	if m.discoverFn != nil {
 		return m.discoverFn(ctx)
@@ -267,9 +445,9 @@ func (m *mockOpsControlPlane) InspectByUnit(ctx context.Context, unit, scope, ma
 	return opsplane.ServiceInspect{}, nil
 }
 
-func (m *mockOpsControlPlane) LogsByUnit(ctx context.Context, unit, scope, manager string, lines int) (string, error) {
+func (m *mockOpsControlPlane) LogsByUnit(ctx context.Context, unit, scope, manager string, query opsplane.LogQuery) (string, error) {
 	if m.logsByUnitFn != nil {
-		return m.logsByUnitFn(ctx, unit, scope, manager, lines)
+		return m.logsByUnitFn(ctx, unit, scope, manager, query)
 	}
 	return "", nil
 }
@@ -338,7 +516,7 @@ func TestRegisterRoutesThroughMux(t *testing.T) {
 		"America/Sao_Paulo",
 		"pt-BR",
 		nil,
-		1,
+		jobqueue.New(1, 0),
 	)
 	t.Cleanup(func() {
 		h.Shutdown(context.Background())
@@ -3256,6 +3434,160 @@ func TestSplitPaneHandler(t *testing.T) {
 	})
 }
 
+func TestMoveWindowHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		var moved string
+		tm := &mockTmux{
+			moveWindowFn: func(_ context.Context, session string, index int, targetSession string) error {
+				moved = fmt.Sprintf("%s:%d->%s", session, index, targetSession)
+				return nil
+			},
+		}
+		h, _ := newTestHandler(t, tm)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/move-window", strings.NewReader(`{"index":2,"targetSession":"ops"}`))
+		r.SetPathValue("session", "dev")
+		h.moveWindow(w, r)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want 204", w.Code)
+		}
+		if moved != "dev:2->ops" {
+			t.Fatalf("moved = %q, want dev:2->ops", moved)
+		}
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		t.Parallel()
+
+		for _, body := range []string{
+			`{"index":-1,"targetSession":"ops"}`,
+			`{"index":0,"targetSession":"bad name"}`,
+			`{"index":0,"targetSession":"dev"}`,
+		} {
+			h, _ := newTestHandler(t, &mockTmux{})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/move-window", strings.NewReader(body))
+			r.SetPathValue("session", "dev")
+			h.moveWindow(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("body %s: status = %d, want 400", body, w.Code)
+			}
+		}
+	})
+
+	t.Run("missing target session", func(t *testing.T) {
+		t.Parallel()
+
+		tm := &mockTmux{
+			moveWindowFn: func(context.Context, string, int, string) error {
+				return &tmux.Error{Kind: tmux.ErrKindSessionNotFound}
+			},
+		}
+		h, _ := newTestHandler(t, tm)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/move-window", strings.NewReader(`{"index":0,"targetSession":"ghost"}`))
+		r.SetPathValue("session", "dev")
+		h.moveWindow(w, r)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", w.Code)
+		}
+	})
+}
+
+func TestSwapPaneHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		var swapped string
+		tm := &mockTmux{
+			listPanesFn: func(_ context.Context, _ string) ([]tmux.Pane, error) {
+				return []tmux.Pane{{Session: "dev", PaneID: "%1"}, {Session: "dev", PaneID: "%2"}}, nil
+			},
+			swapPaneFn: func(_ context.Context, paneID, targetPaneID string) error {
+				swapped = paneID + "<->" + targetPaneID
+				return nil
+			},
+		}
+		h, _ := newTestHandler(t, tm)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/swap-pane", strings.NewReader(`{"paneId":"%1","targetPaneId":"%2"}`))
+		r.SetPathValue("session", "dev")
+		h.swapPane(w, r)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want 204", w.Code)
+		}
+		if swapped != "%1<->%2" {
+			t.Fatalf("swapped = %q, want %%1<->%%2", swapped)
+		}
+	})
+
+	t.Run("same pane", func(t *testing.T) {
+		t.Parallel()
+
+		h, _ := newTestHandler(t, &mockTmux{})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/swap-pane", strings.NewReader(`{"paneId":"%1","targetPaneId":"%1"}`))
+		r.SetPathValue("session", "dev")
+		h.swapPane(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+}
+
+func TestRotateWindowHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		var rotated string
+		tm := &mockTmux{
+			rotateWindowFn: func(_ context.Context, session string, index int, direction string) error {
+				rotated = fmt.Sprintf("%s:%d %s", session, index, direction)
+				return nil
+			},
+		}
+		h, _ := newTestHandler(t, tm)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/rotate-window", strings.NewReader(`{"index":1,"direction":"Down"}`))
+		r.SetPathValue("session", "dev")
+		h.rotateWindow(w, r)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want 204", w.Code)
+		}
+		if rotated != "dev:1 down" {
+			t.Fatalf("rotated = %q, want %q", rotated, "dev:1 down")
+		}
+	})
+
+	t.Run("invalid direction", func(t *testing.T) {
+		t.Parallel()
+
+		h, _ := newTestHandler(t, &mockTmux{})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/rotate-window", strings.NewReader(`{"index":1,"direction":"left"}`))
+		r.SetPathValue("session", "dev")
+		h.rotateWindow(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+}
+
 func TestPaneScopedHandlersRejectPaneOutsideSession(t *testing.T) {
 	t.Parallel()
 
@@ -3303,6 +3635,16 @@ func TestPaneScopedHandlersRejectPaneOutsideSession(t *testing.T) {
 				return w
 			},
 		},
+		{
+			name: "swap-pane",
+			run: func(h *Handler) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/swap-pane", strings.NewReader(`{"paneId":"%1","targetPaneId":"%9"}`))
+				r.SetPathValue("session", "dev")
+				h.swapPane(w, r)
+				return w
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -4035,6 +4377,83 @@ func TestRegisterOpsServiceValidation(t *testing.T) {
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("status = %d, want 400", w.Code)
 	}
+
+	// Malformed health check.
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/api/ops/services", strings.NewReader(`{
+		"name":"myapp",
+		"unit":"myapp.service",
+		"healthChecks":[{"type":"tcp","address":"no-port"}]
+	}`))
+	h.registerOpsService(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("health check status = %d, want 400", w.Code)
+	}
+
+	// Empty restart hook command.
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/api/ops/services", strings.NewReader(`{
+		"name":"myapp",
+		"unit":"myapp.service",
+		"restartHooks":{"preStop":{"command":""}}
+	}`))
+	h.registerOpsService(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("restart hook status = %d, want 400", w.Code)
+	}
+}
+
+func TestRegisterOpsServiceWithRestartHooks(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/ops/services", strings.NewReader(`{
+		"name":"nginx",
+		"unit":"nginx.service",
+		"scope":"system",
+		"restartHooks":{
+			"preStop":{"command":"lbctl drain web1","timeoutSeconds":60},
+			"postStart":{"command":"lbctl enable web1"}
+		}
+	}`))
+	h.registerOpsService(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register status = %d, want 201; body = %s", w.Code, w.Body.String())
+	}
+	custom, err := st.ListCustomServices(r.Context())
+	if err != nil {
+		t.Fatalf("ListCustomServices: %v", err)
+	}
+	if len(custom) != 1 || custom[0].RestartHooks == nil || custom[0].RestartHooks.PreStop.TimeoutSeconds != 60 || custom[0].RestartHooks.PostStart.Command != "lbctl enable web1" {
+		t.Fatalf("custom services = %+v, want nginx with both restart hooks", custom)
+	}
+}
+
+func TestRegisterOpsServiceWithHealthChecks(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/ops/services", strings.NewReader(`{
+		"name":"api",
+		"unit":"api.service",
+		"healthChecks":[
+			{"type":"http","url":"http://127.0.0.1:8080/healthz","expectStatus":200},
+			{"type":"command","command":"pg_isready -q","timeoutSeconds":3}
+		]
+	}`))
+	h.registerOpsService(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register status = %d, want 201; body = %s", w.Code, w.Body.String())
+	}
+	custom, err := st.ListCustomServices(r.Context())
+	if err != nil {
+		t.Fatalf("ListCustomServices: %v", err)
+	}
+	if len(custom) != 1 || len(custom[0].HealthChecks) != 2 || custom[0].HealthChecks[1].Command != "pg_isready -q" {
+		t.Fatalf("custom services = %+v, want api with 2 health checks", custom)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -4046,12 +4465,12 @@ func TestOpsServiceLogsHandler(t *testing.T) {
 
 	h, _ := newTestHandler(t, nil)
 	h.ops = &mockOpsControlPlane{
-		logsFn: func(_ context.Context, name string, lines int) (string, error) {
+		logsFn: func(_ context.Context, name string, query opsplane.LogQuery) (string, error) {
 			if name != "sentinel" {
 				t.Fatalf("service = %q, want sentinel", name)
 			}
-			if lines != 50 {
-				t.Fatalf("lines = %d, want 50", lines)
+			if query.Lines != 50 {
+				t.Fatalf("lines = %d, want 50", query.Lines)
 			}
 			return "line1\nline2\nline3", nil
 		},
@@ -4071,12 +4490,62 @@ func TestOpsServiceLogsHandler(t *testing.T) {
 	}
 }
 
+func TestOpsServiceLogsQueryFilters(t *testing.T) {
+	t.Parallel()
+
+	var got opsplane.LogQuery
+	h, _ := newTestHandler(t, nil)
+	h.ops = &mockOpsControlPlane{
+		logsFn: func(_ context.Context, _ string, query opsplane.LogQuery) (string, error) {
+			got = query
+			return "", nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/ops/services/sentinel/logs?since=2026-10-15T09:00:00Z&until=1792056600&priority=err&grep=timeout", nil)
+	r.SetPathValue("service", "sentinel")
+	h.opsServiceLogs(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	wantSince := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	if got.Lines != 100 || !got.Since.Equal(wantSince) || !got.Until.Equal(wantSince.Add(30*time.Minute)) ||
+		got.Priority != "err" || got.Grep != "timeout" {
+		t.Fatalf("query = %+v", got)
+	}
+}
+
+func TestOpsServiceLogsInvalidQuery(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.ops = &mockOpsControlPlane{
+		logsFn: func(context.Context, string, opsplane.LogQuery) (string, error) {
+			return "", fmt.Errorf("%w: since must not be after until", opsplane.ErrInvalidLogQuery)
+		},
+	}
+
+	for _, target := range []string{
+		"/api/ops/services/sentinel/logs?since=yesterday",
+		"/api/ops/services/sentinel/logs?since=1792056600&until=1792054800",
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.SetPathValue("service", "sentinel")
+		h.opsServiceLogs(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", target, w.Code)
+		}
+	}
+}
+
 func TestOpsServiceLogsNotFound(t *testing.T) {
 	t.Parallel()
 
 	h, _ := newTestHandler(t, nil)
 	h.ops = &mockOpsControlPlane{
-		logsFn: func(context.Context, string, int) (string, error) {
+		logsFn: func(context.Context, string, opsplane.LogQuery) (string, error) {
 			return "", opsplane.ErrServiceNotFound
 		},
 	}
@@ -4124,6 +4593,89 @@ func TestOpsMetricsHandler(t *testing.T) {
 	}
 }
 
+func TestOpsDiskHandler(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	var gotRefresh []bool
+	h.ops = &mockOpsControlPlane{
+		diskFn: func(_ context.Context, refresh bool) opsplane.DiskUsage {
+			gotRefresh = append(gotRefresh, refresh)
+			return opsplane.DiskUsage{
+				Mounts: []opsplane.DiskMount{{Mountpoint: "/", FSType: "ext4", Percent: 91}},
+				Roots: []opsplane.DiskScan{{
+					Root:        "/",
+					Directories: []opsplane.DirectoryUsage{{Path: "/var", SizeBytes: 4096}},
+				}},
+				Scanning: refresh,
+			}
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.opsDisk(w, httptest.NewRequest(http.MethodGet, "/api/ops/disk", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	mounts, _ := data["mounts"].([]any)
+	roots, _ := data["roots"].([]any)
+	if len(mounts) != 1 || len(roots) != 1 || data["scanning"] != false {
+		t.Fatalf("data = %v, want one mount and one root", data)
+	}
+
+	w = httptest.NewRecorder()
+	h.opsDisk(w, httptest.NewRequest(http.MethodGet, "/api/ops/disk?refresh=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh status = %d, want 200", w.Code)
+	}
+	if len(gotRefresh) != 2 || gotRefresh[0] || !gotRefresh[1] {
+		t.Fatalf("refresh flags = %v, want [false true]", gotRefresh)
+	}
+
+	w = httptest.NewRecorder()
+	h.opsDisk(w, httptest.NewRequest(http.MethodGet, "/api/ops/disk?refresh=maybe", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid refresh status = %d, want 400", w.Code)
+	}
+}
+
+func TestOpsUPSHandler(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	mock := &mockOpsControlPlane{}
+	h.ops = mock
+
+	w := httptest.NewRecorder()
+	h.opsUPS(w, httptest.NewRequest(http.MethodGet, "/api/ops/ups", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unconfigured status = %d, want 404", w.Code)
+	}
+
+	mock.upsFn = func(context.Context) (opsplane.UPSStatus, error) {
+		return opsplane.UPSStatus{Source: opsplane.UPSSourceNUT, State: opsplane.UPSStateOnBattery, OnBattery: true, RuntimeSec: 600}, nil
+	}
+	w = httptest.NewRecorder()
+	h.opsUPS(w, httptest.NewRequest(http.MethodGet, "/api/ops/ups", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	if data["state"] != opsplane.UPSStateOnBattery || data["runtimeSec"] != float64(600) {
+		t.Fatalf("data = %v, want an on-battery UPS with 600s left", data)
+	}
+
+	mock.upsFn = func(context.Context) (opsplane.UPSStatus, error) {
+		return opsplane.UPSStatus{}, errors.New("upsc failed: Error: Driver not connected")
+	}
+	w = httptest.NewRecorder()
+	h.opsUPS(w, httptest.NewRequest(http.MethodGet, "/api/ops/ups", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("failing status = %d, want 502", w.Code)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Browse + unit-based handler tests
 // ---------------------------------------------------------------------------
@@ -4251,12 +4803,12 @@ func TestOpsUnitLogsHandler(t *testing.T) {
 
 	h, _ := newTestHandler(t, nil)
 	h.ops = &mockOpsControlPlane{
-		logsByUnitFn: func(_ context.Context, unit, _, _ string, lines int) (string, error) {
+		logsByUnitFn: func(_ context.Context, unit, _, _ string, query opsplane.LogQuery) (string, error) {
 			if unit != testNginxUnit {
 				t.Fatalf("unit = %q, want %s", unit, testNginxUnit)
 			}
-			if lines != 50 {
-				t.Fatalf("lines = %d, want 50", lines)
+			if query.Lines != 50 {
+				t.Fatalf("lines = %d, want 50", query.Lines)
 			}
 			return "log line 1\nlog line 2", nil
 		},
@@ -4374,6 +4926,69 @@ func TestOpsPatchConfigValidation(t *testing.T) {
 	}
 }
 
+func TestOpsPatchConfigRejectsInvalidContent(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.configPath = filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(h.configPath, []byte("[server]\nport = 4040\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPatch, "/api/ops/config", strings.NewReader(`{"content":"[server]\nwat = true\n"}`))
+	h.patchOpsConfig(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body = %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "INVALID_CONFIG") || !strings.Contains(w.Body.String(), "unknown key: server.wat") {
+		t.Fatalf("body = %s, want INVALID_CONFIG with issue", w.Body.String())
+	}
+	got, _ := os.ReadFile(h.configPath)
+	if string(got) != "[server]\nport = 4040\n" {
+		t.Fatalf("config file = %q, want unchanged", got)
+	}
+}
+
+func TestOpsValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.configPath = filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(h.configPath, []byte("[server]\nport = 4040\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/ops/config/validate", strings.NewReader(`{"content":"[server]\nport = 5050\n"}`))
+	h.validateOpsConfig(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	changes, _ := data["changes"].([]any)
+	if data["valid"] != true || len(changes) != 1 {
+		t.Fatalf("data = %v, want one valid change", data)
+	}
+	change, _ := changes[0].(map[string]any)
+	if change["key"] != "server.port" || change["from"] != float64(4040) || change["to"] != float64(5050) {
+		t.Fatalf("change = %v, want server.port 4040 -> 5050", change)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/api/ops/config/validate", strings.NewReader(`{"content":"[watchtower]\ntick_interval = \"soon\"\n"}`))
+	h.validateOpsConfig(w, r)
+	data, _ = jsonBody(t, w)["data"].(map[string]any)
+	issues, _ := data["issues"].([]any)
+	if w.Code != http.StatusOK || data["valid"] != false || len(issues) != 1 {
+		t.Fatalf("status = %d, data = %v, want one issue", w.Code, data)
+	}
+	got, _ := os.ReadFile(h.configPath)
+	if string(got) != "[server]\nport = 4040\n" {
+		t.Fatalf("config file = %q, want unchanged", got)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Runbook CRUD handler tests
 // ---------------------------------------------------------------------------
@@ -4900,6 +5515,9 @@ func TestCreateScheduleHandler(t *testing.T) {
 		if sched["name"] != "my-cron" {
 			t.Fatalf("name = %v, want my-cron", sched["name"])
 		}
+		if sched["concurrencyPolicy"] != store.ScheduleConcurrencyForbid {
+			t.Fatalf("concurrencyPolicy = %v, want default forbid", sched["concurrencyPolicy"])
+		}
 	})
 
 	t.Run("once schedule", func(t *testing.T) {
@@ -4938,6 +5556,7 @@ func TestCreateScheduleHandler(t *testing.T) {
 			{"missing runbookId", `{"name":"x","scheduleType":"cron","cronExpr":"0 * * * *"}`},
 			{"missing name", `{"runbookId":"x","scheduleType":"cron","cronExpr":"0 * * * *"}`},
 			{"invalid scheduleType", `{"runbookId":"x","name":"x","scheduleType":"bad"}`},
+			{"invalid concurrencyPolicy", `{"runbookId":"x","name":"x","scheduleType":"cron","cronExpr":"0 * * * *","concurrencyPolicy":"queue"}`},
 			{"invalid json", `{not-json}`},
 		}
 		for _, tt := range tests {
@@ -4995,7 +5614,7 @@ func TestUpdateScheduleHandler(t *testing.T) {
 			t.Fatalf("InsertOpsSchedule: %v", err)
 		}
 
-		body := fmt.Sprintf(`{"runbookId":"%s","name":"updated","scheduleType":"cron","cronExpr":"30 * * * *","timezone":"UTC","enabled":true}`, rb.ID)
+		body := fmt.Sprintf(`{"runbookId":"%s","name":"updated","scheduleType":"cron","cronExpr":"30 * * * *","timezone":"UTC","concurrencyPolicy":"replace","enabled":true}`, rb.ID)
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodPut, "/api/ops/schedules/"+sched.ID, strings.NewReader(body))
 		r.SetPathValue("schedule", sched.ID)
@@ -5010,6 +5629,9 @@ func TestUpdateScheduleHandler(t *testing.T) {
 		if updated["name"] != "updated" {
 			t.Fatalf("name = %v, want updated", updated["name"])
 		}
+		if updated["concurrencyPolicy"] != store.ScheduleConcurrencyReplace {
+			t.Fatalf("concurrencyPolicy = %v, want replace", updated["concurrencyPolicy"])
+		}
 	})
 
 	t.Run("not found", func(t *testing.T) {
@@ -5149,7 +5771,7 @@ func TestValidateScheduleRequest(t *testing.T) {
 		t.Parallel()
 
 		st := newTestStore(t)
-		_, err := validateScheduleRequest(ctx, st, "nonexistent", "cron", "0 * * * *", "UTC", "")
+		_, err := validateScheduleRequest(ctx, st, "nonexistent", scheduleSpec{ScheduleType: "cron", CronExpr: "0 * * * *", Timezone: "UTC"})
 		if err == nil || !strings.Contains(err.Error(), "runbook not found") {
 			t.Fatalf("err = %v, want runbook not found", err)
 		}
@@ -5163,7 +5785,7 @@ func TestValidateScheduleRequest(t *testing.T) {
 			Name:  "val-cron-rb",
 			Steps: []store.OpsRunbookStep{{Type: "run", Title: "echo", Command: "echo ok"}},
 		})
-		_, err := validateScheduleRequest(ctx, st, rb.ID, "cron", "bad-cron", "UTC", "")
+		_, err := validateScheduleRequest(ctx, st, rb.ID, scheduleSpec{ScheduleType: "cron", CronExpr: "bad-cron", Timezone: "UTC"})
 		if err == nil || !strings.Contains(err.Error(), "invalid cron") {
 			t.Fatalf("err = %v, want invalid cron", err)
 		}
@@ -5177,7 +5799,7 @@ func TestValidateScheduleRequest(t *testing.T) {
 			Name:  "val-tz-rb",
 			Steps: []store.OpsRunbookStep{{Type: "run", Title: "echo", Command: "echo ok"}},
 		})
-		_, err := validateScheduleRequest(ctx, st, rb.ID, "cron", "0 * * * *", "Invalid/Zone", "")
+		_, err := validateScheduleRequest(ctx, st, rb.ID, scheduleSpec{ScheduleType: "cron", CronExpr: "0 * * * *", Timezone: "Invalid/Zone"})
 		if err == nil || !strings.Contains(err.Error(), "invalid timezone") {
 			t.Fatalf("err = %v, want invalid timezone", err)
 		}
@@ -5191,7 +5813,7 @@ func TestValidateScheduleRequest(t *testing.T) {
 			Name:  "val-once-rb",
 			Steps: []store.OpsRunbookStep{{Type: "run", Title: "echo", Command: "echo ok"}},
 		})
-		_, err := validateScheduleRequest(ctx, st, rb.ID, "once", "", "", "not-rfc3339")
+		_, err := validateScheduleRequest(ctx, st, rb.ID, scheduleSpec{ScheduleType: "once", RunAt: "not-rfc3339"})
 		if err == nil || !strings.Contains(err.Error(), "runAt must be a valid RFC3339") {
 			t.Fatalf("err = %v, want runAt must be RFC3339", err)
 		}
@@ -5206,7 +5828,7 @@ func TestValidateScheduleRequest(t *testing.T) {
 			Steps: []store.OpsRunbookStep{{Type: "run", Title: "echo", Command: "echo ok"}},
 		})
 		past := time.Now().UTC().Add(-1 * time.Hour).Format(time.RFC3339)
-		_, err := validateScheduleRequest(ctx, st, rb.ID, "once", "", "", past)
+		_, err := validateScheduleRequest(ctx, st, rb.ID, scheduleSpec{ScheduleType: "once", RunAt: past})
 		if err == nil || !strings.Contains(err.Error(), "runAt must be in the future") {
 			t.Fatalf("err = %v, want runAt must be in the future", err)
 		}
@@ -5220,7 +5842,7 @@ func TestValidateScheduleRequest(t *testing.T) {
 			Name:  "val-ok-rb",
 			Steps: []store.OpsRunbookStep{{Type: "run", Title: "echo", Command: "echo ok"}},
 		})
-		next, err := validateScheduleRequest(ctx, st, rb.ID, "cron", "0 * * * *", "UTC", "")
+		next, err := validateScheduleRequest(ctx, st, rb.ID, scheduleSpec{ScheduleType: "cron", CronExpr: "0 * * * *", Timezone: "UTC"})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -5233,6 +5855,42 @@ func TestValidateScheduleRequest(t *testing.T) {
 		}
 	})
 
+	t.Run("invalid interval", func(t *testing.T) {
+		t.Parallel()
+
+		st := newTestStore(t)
+		rb, _ := st.InsertOpsRunbook(ctx, store.OpsRunbookWrite{
+			Name:  "val-interval-bad-rb",
+			Steps: []store.OpsRunbookStep{{Type: "run", Title: "echo", Command: "echo ok"}},
+		})
+		_, err := validateScheduleRequest(ctx, st, rb.ID, scheduleSpec{ScheduleType: "interval", Interval: "10s"})
+		if err == nil || !strings.Contains(err.Error(), "invalid interval") {
+			t.Fatalf("err = %v, want invalid interval", err)
+		}
+	})
+
+	t.Run("valid interval returns nextRunAt", func(t *testing.T) {
+		t.Parallel()
+
+		st := newTestStore(t)
+		rb, _ := st.InsertOpsRunbook(ctx, store.OpsRunbookWrite{
+			Name:  "val-interval-ok-rb",
+			Steps: []store.OpsRunbookStep{{Type: "run", Title: "echo", Command: "echo ok"}},
+		})
+		before := time.Now().UTC().Truncate(time.Second)
+		next, err := validateScheduleRequest(ctx, st, rb.ID, scheduleSpec{ScheduleType: "interval", Interval: "15m", Jitter: "1m"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		parsed, err := time.Parse(time.RFC3339, next)
+		if err != nil {
+			t.Fatalf("nextRunAt is not RFC3339: %q", next)
+		}
+		if parsed.Before(before.Add(15*time.Minute)) || parsed.After(before.Add(17*time.Minute)) {
+			t.Fatalf("nextRunAt = %s, want about 15m from now", next)
+		}
+	})
+
 	t.Run("valid once returns nextRunAt", func(t *testing.T) {
 		t.Parallel()
 
@@ -5242,7 +5900,7 @@ func TestValidateScheduleRequest(t *testing.T) {
 			Steps: []store.OpsRunbookStep{{Type: "run", Title: "echo", Command: "echo ok"}},
 		})
 		future := time.Now().UTC().Add(2 * time.Hour).Format(time.RFC3339)
-		next, err := validateScheduleRequest(ctx, st, rb.ID, "once", "", "", future)
+		next, err := validateScheduleRequest(ctx, st, rb.ID, scheduleSpec{ScheduleType: "once", RunAt: future})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -5252,6 +5910,115 @@ func TestValidateScheduleRequest(t *testing.T) {
 	})
 }
 
+func TestScheduleHistoryHandler(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	ctx := context.Background()
+	sched, err := st.InsertOpsSchedule(ctx, store.OpsScheduleWrite{
+		RunbookID: "rb-1", Name: "history", ScheduleType: "cron",
+		CronExpr: "0 * * * *", Timezone: "UTC", Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("InsertOpsSchedule: %v", err)
+	}
+	fired := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	runID, _ := st.InsertScheduleRun(ctx, sched.ID, "job-1", fired, store.ScheduleRunRunning)
+	_ = st.FinishScheduleRun(ctx, runID, fired.Add(2*time.Second), "failed")
+	_, _ = st.InsertScheduleRun(ctx, sched.ID, "job-2", fired.Add(time.Hour), store.ScheduleRunRunning)
+
+	history := func(id, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/ops/schedules/"+id+"/history"+query, nil)
+		r.SetPathValue("schedule", id)
+		h.scheduleHistory(w, r)
+		return w
+	}
+
+	w := history(sched.ID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; body=%s", w.Code, w.Body.String())
+	}
+	runs := jsonBody(t, w)["data"].(map[string]any)["runs"].([]any)
+	if len(runs) != 2 {
+		t.Fatalf("runs = %+v, want 2", runs)
+	}
+	if got := runs[0].(map[string]any); got["jobId"] != "job-2" || got["outcome"] != store.ScheduleRunRunning {
+		t.Fatalf("newest run = %+v", got)
+	}
+	if got := runs[1].(map[string]any); got["outcome"] != "failed" || got["durationMs"] != float64(2000) {
+		t.Fatalf("finished run = %+v", got)
+	}
+
+	if w := history(sched.ID, "?limit=1"); len(jsonBody(t, w)["data"].(map[string]any)["runs"].([]any)) != 1 {
+		t.Fatalf("limit=1 body = %s", w.Body.String())
+	}
+	if w := history(sched.ID, "?limit=0"); w.Code != http.StatusBadRequest {
+		t.Fatalf("limit=0 status = %d, want 400", w.Code)
+	}
+	if w := history("missing", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("missing schedule status = %d, want 404", w.Code)
+	}
+}
+
+func TestPreviewScheduleHandler(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	preview := func(body string) map[string]any {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.previewSchedule(w, httptest.NewRequest(http.MethodPost, "/api/ops/schedules/preview", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body=%s", w.Code, w.Body.String())
+		}
+		return jsonBody(t, w)["data"].(map[string]any)
+	}
+
+	data := preview(`{"cronExpr":"30 9 * * 1-5","timezone":"America/Sao_Paulo"}`)
+	next := data["next"].([]any)
+	if data["valid"] != true || len(next) != schedulePreviewCount {
+		t.Fatalf("data = %+v, want 10 runs", data)
+	}
+	var prev time.Time
+	for _, raw := range next {
+		at, err := time.Parse(time.RFC3339, raw.(string))
+		if err != nil {
+			t.Fatalf("parse %v: %v", raw, err)
+		}
+		if _, offset := at.Zone(); offset != -3*3600 || at.Hour() != 9 || at.Minute() != 30 {
+			t.Fatalf("run %v is not 09:30 in Sao Paulo", raw)
+		}
+		if wd := at.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			t.Fatalf("run %v falls on a weekend", raw)
+		}
+		if !at.After(prev) {
+			t.Fatalf("run %v is not after %v", at, prev)
+		}
+		prev = at
+	}
+
+	if data := preview(`{"cronExpr":"0 0 30 2 *"}`); data["valid"] != true || len(data["next"].([]any)) != 0 {
+		t.Fatalf("never-matching data = %+v, want valid with no runs", data)
+	}
+	for _, body := range []string{
+		`{"cronExpr":"61 * * * *"}`,
+		`{"cronExpr":"@every 1m"}`,
+		`{"cronExpr":"0 * * * *","timezone":"Mars/Base"}`,
+	} {
+		data := preview(body)
+		if data["valid"] != false || data["error"] == "" {
+			t.Fatalf("preview(%s) = %+v, want invalid with error", body, data)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.previewSchedule(w, httptest.NewRequest(http.MethodPost, "/api/ops/schedules/preview", strings.NewReader(`{"cron":"x"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown field status = %d, want 400", w.Code)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Ops handler tests – deleteOpsAlert, discoverOpsServices
 // ---------------------------------------------------------------------------
@@ -5559,8 +6326,8 @@ func TestOpsUnitLogsHandlerValidation(t *testing.T) {
 
 		h, _ := newTestHandler(t, nil)
 		h.ops = &mockOpsControlPlane{
-			logsByUnitFn: func(_ context.Context, _, _, _ string, lines int) (string, error) {
-				return fmt.Sprintf("lines=%d", lines), nil
+			logsByUnitFn: func(_ context.Context, _, _, _ string, query opsplane.LogQuery) (string, error) {
+				return fmt.Sprintf("lines=%d", query.Lines), nil
 			},
 		}
 
@@ -5578,7 +6345,7 @@ func TestOpsUnitLogsHandlerValidation(t *testing.T) {
 
 		h, _ := newTestHandler(t, nil)
 		h.ops = &mockOpsControlPlane{
-			logsByUnitFn: func(_ context.Context, _, _, _ string, _ int) (string, error) {
+			logsByUnitFn: func(_ context.Context, _, _, _ string, _ opsplane.LogQuery) (string, error) {
 				return "", fmt.Errorf("logs fail")
 			},
 		}
@@ -5815,8 +6582,8 @@ func TestOpsServiceLogsCustomLines(t *testing.T) {
 	var gotLines int
 	h, _ := newTestHandler(t, nil)
 	h.ops = &mockOpsControlPlane{
-		logsFn: func(_ context.Context, _ string, lines int) (string, error) {
-			gotLines = lines
+		logsFn: func(_ context.Context, _ string, query opsplane.LogQuery) (string, error) {
+			gotLines = query.Lines
 			return "log output", nil
 		},
 	}
@@ -6218,6 +6985,86 @@ func TestRunOpsRunbookSemaphoreFull(t *testing.T) {
 	h.runbooks.WaitIdle()
 }
 
+func TestRunOpsRunbookQueuesByPriority(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	h.events = events.NewHub()
+	jobs := jobqueue.New(1, 5)
+	h.runbooks.Shutdown(context.Background())
+	h.runbooks = runbook.NewManagerWithQueue(st, h.emitEvent, jobs)
+	t.Cleanup(func() { jobs.Shutdown(context.Background()) })
+	ctx := context.Background()
+
+	rb, err := st.InsertOpsRunbook(ctx, store.OpsRunbookWrite{
+		Name:  "queued-rb",
+		Steps: []store.OpsRunbookStep{{Type: "run", Title: "wait", Command: "sleep 5"}},
+	})
+	if err != nil {
+		t.Fatalf("InsertOpsRunbook: %v", err)
+	}
+
+	run := func(body string) (int, string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/ops/runbooks/"+rb.ID+"/run", strings.NewReader(body))
+		r.SetPathValue("runbook", rb.ID)
+		h.runOpsRunbook(w, r)
+		if w.Code != http.StatusAccepted {
+			return w.Code, ""
+		}
+		job, _ := jsonBody(t, w)["data"].(map[string]any)["job"].(map[string]any)
+		id, _ := job["id"].(string)
+		return w.Code, id
+	}
+
+	if code, _ := run(`{"priority":"urgent"}`); code != http.StatusBadRequest {
+		t.Fatalf("invalid priority: status = %d, want 400", code)
+	}
+	var ids []string
+	for _, body := range []string{`{}`, `{"priority":"low"}`, `{"priority":"high"}`} {
+		code, id := run(body)
+		if code != http.StatusAccepted {
+			t.Fatalf("run %s: status = %d, want 202", body, code)
+		}
+		ids = append(ids, id)
+	}
+
+	// The high priority run waits ahead of the low priority one.
+	for i, want := range []float64{0, 2, 1} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/ops/jobs/"+ids[i], nil)
+		r.SetPathValue("job", ids[i])
+		h.opsJob(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("opsJob: status = %d, want 200; body=%s", w.Code, w.Body.String())
+		}
+		data, _ := jsonBody(t, w)["data"].(map[string]any)
+		if got, _ := data["queuePosition"].(float64); got != want {
+			t.Fatalf("queuePosition of run %d = %v, want %v", i, got, want)
+		}
+	}
+
+	// Waiting runs cancel at once; the running one may still be starting.
+	for _, id := range ids[1:] {
+		if _, err := h.runbooks.Cancel(ctx, id); err != nil {
+			t.Fatalf("Cancel(%s): %v", id, err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := h.runbooks.Cancel(ctx, ids[0])
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Cancel(%s): %v", ids[0], err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	h.runbooks.WaitIdle()
+}
+
 func TestRunOpsRunbookSemaphoreReleasedAfterCompletion(t *testing.T) {
 	t.Parallel()
 
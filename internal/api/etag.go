@@ -0,0 +1,49 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// writeDataETag is writeData for read endpoints that dashboards poll. The
+// response carries an ETag hashed from its body, and a request whose
+// If-None-Match already names it gets 304 Not Modified without one.
+// Cache-Control: no-cache makes browsers revalidate on every poll instead
+// of reusing a stale copy.
+func writeDataETag(w http.ResponseWriter, r *http.Request, data any) {
+	body, err := json.Marshal(map[string]any{"data": data})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "json encode error", "err", err)
+		writeData(w, http.StatusOK, data)
+		return
+	}
+	body = append(body, '\n')
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// etagMatches reports whether an If-None-Match header names etag. Weak
+// validators match too, as RFC 9110 requires for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
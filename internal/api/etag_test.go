@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+func TestWriteDataETag(t *testing.T) {
+	t.Parallel()
+
+	serve := func(data any, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/tmux/sessions", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		writeDataETag(w, r, data)
+		return w
+	}
+
+	first := serve(map[string]string{"key": "value"}, "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("first response = %d, headers %v", first.Code, first.Header())
+	}
+	plain := httptest.NewRecorder()
+	writeData(plain, http.StatusOK, map[string]string{"key": "value"})
+	if first.Body.String() != plain.Body.String() {
+		t.Fatalf("body = %q, want writeData's %q", first.Body.String(), plain.Body.String())
+	}
+
+	for _, header := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		w := serve(map[string]string{"key": "value"}, header)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %s: status = %d, body %q", header, w.Code, w.Body.String())
+		}
+	}
+
+	changed := serve(map[string]string{"key": "changed"}, etag)
+	if changed.Code != http.StatusOK || changed.Header().Get("ETag") == etag {
+		t.Fatalf("changed data: status = %d, ETag %q", changed.Code, changed.Header().Get("ETag"))
+	}
+}
+
+func TestOpsServicesNotModified(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.ops = &mockOpsControlPlane{
+		listServicesFn: func(context.Context) ([]opsplane.ServiceStatus, error) {
+			return []opsplane.ServiceStatus{{Name: "web", ActiveState: "active"}}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.opsServices(w, httptest.NewRequest(http.MethodGet, "/api/ops/services", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag %q", w.Code, etag)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/ops/services", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.opsServices(w, r)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("revalidation status = %d, body %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+type createAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+func (h *Handler) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	keys, err := h.repo.ListAPIKeys(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to list api keys", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+func (h *Handler) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	if !h.guard.TokenRequired() {
+		writeError(w, http.StatusConflict, "TOKEN_NOT_CONFIGURED", "api keys require server.token to be configured", nil)
+		return
+	}
+
+	var req createAPIKeyRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "name is required", nil)
+		return
+	}
+	role, ok := security.ParseRole(req.Role)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "role must be viewer, operator, or admin", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	key, token, err := h.repo.CreateAPIKey(ctx, store.APIKeyWrite{Name: name, Role: string(role)})
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			writeError(w, http.StatusConflict, "API_KEY_EXISTS", "api key already exists", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to create api key", nil)
+		return
+	}
+	// The plaintext token is only returned here; the store keeps a digest.
+	writeData(w, http.StatusCreated, map[string]any{"key": key, "token": token})
+}
+
+func (h *Handler) deleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID := strings.TrimSpace(r.PathValue("key"))
+	if keyID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "api key id is required", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.repo.DeleteAPIKey(ctx, keyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "API_KEY_NOT_FOUND", "api key not found", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to delete api key", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{keyRemoved: keyID})
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func newRoleTestMux(t *testing.T) (*http.ServeMux, *store.Store) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	guard := security.New("secret", nil, security.CookieSecureAuto)
+	st := newTestStore(t)
+	guard.SetKeyResolver(func(ctx context.Context, token string) (security.Identity, bool) {
+		key, err := st.AuthenticateAPIKey(ctx, token)
+		if err != nil {
+			return security.Identity{}, false
+		}
+		role, ok := security.ParseRole(key.Role)
+		return security.Identity{Name: key.Name, Role: role}, ok
+	})
+	h := Register(mux, guard, st, &mockOpsControlPlane{}, events.NewHub(), "test", "", "", "", nil, nil)
+	t.Cleanup(func() { h.Shutdown(context.Background()) })
+	return mux, st
+}
+
+func serveWithBearer(mux *http.ServeMux, method, target, token, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, "http://localhost:4040"+target, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	mux.ServeHTTP(w, r)
+	return w
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	t.Parallel()
+
+	mux, _ := newRoleTestMux(t)
+
+	created := serveWithBearer(mux, http.MethodPost, "/api/auth/keys", "secret", `{"name":"dashboard","role":"viewer"}`)
+	if created.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want 201; body=%s", created.Code, created.Body.String())
+	}
+	var createBody struct {
+		Data struct {
+			Key   store.APIKey `json:"key"`
+			Token string       `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(created.Body.Bytes(), &createBody); err != nil {
+		t.Fatalf("decode create response: %v", err)
+	}
+	if createBody.Data.Token == "" || createBody.Data.Key.Role != "viewer" {
+		t.Fatalf("unexpected create response: %+v", createBody.Data)
+	}
+
+	duplicate := serveWithBearer(mux, http.MethodPost, "/api/auth/keys", "secret", `{"name":"Dashboard","role":"admin"}`)
+	if duplicate.Code != http.StatusConflict {
+		t.Fatalf("duplicate status = %d, want 409", duplicate.Code)
+	}
+	badRole := serveWithBearer(mux, http.MethodPost, "/api/auth/keys", "secret", `{"name":"ci","role":"root"}`)
+	if badRole.Code != http.StatusBadRequest {
+		t.Fatalf("bad role status = %d, want 400", badRole.Code)
+	}
+
+	meta := serveWithBearer(mux, http.MethodGet, "/api/meta", createBody.Data.Token, "")
+	if meta.Code != http.StatusOK {
+		t.Fatalf("GET /api/meta with viewer key status = %d, want 200", meta.Code)
+	}
+	var metaBody struct {
+		Data struct {
+			Identity security.Identity `json:"identity"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(meta.Body.Bytes(), &metaBody); err != nil {
+		t.Fatalf("decode meta response: %v", err)
+	}
+	if metaBody.Data.Identity != (security.Identity{Name: "dashboard", Role: security.RoleViewer}) {
+		t.Fatalf("meta identity = %+v", metaBody.Data.Identity)
+	}
+
+	list := serveWithBearer(mux, http.MethodGet, "/api/auth/keys", "secret", "")
+	if list.Code != http.StatusOK || strings.Contains(list.Body.String(), createBody.Data.Token) {
+		t.Fatalf("list status = %d, body must not leak token; body=%s", list.Code, list.Body.String())
+	}
+
+	removed := serveWithBearer(mux, http.MethodDelete, "/api/auth/keys/"+createBody.Data.Key.ID, "secret", "")
+	if removed.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, want 200", removed.Code)
+	}
+	if again := serveWithBearer(mux, http.MethodDelete, "/api/auth/keys/"+createBody.Data.Key.ID, "secret", ""); again.Code != http.StatusNotFound {
+		t.Fatalf("second delete status = %d, want 404", again.Code)
+	}
+	if revoked := serveWithBearer(mux, http.MethodGet, "/api/meta", createBody.Data.Token, ""); revoked.Code != http.StatusUnauthorized {
+		t.Fatalf("revoked key status = %d, want 401", revoked.Code)
+	}
+}
+
+func TestRouteRolesAreEnforced(t *testing.T) {
+	t.Parallel()
+
+	mux, st := newRoleTestMux(t)
+	ctx := context.Background()
+	_, viewerToken, err := st.CreateAPIKey(ctx, store.APIKeyWrite{Name: "viewer", Role: "viewer"})
+	if err != nil {
+		t.Fatalf("CreateAPIKey(viewer) error = %v", err)
+	}
+	_, operatorToken, err := st.CreateAPIKey(ctx, store.APIKeyWrite{Name: "operator", Role: "operator"})
+	if err != nil {
+		t.Fatalf("CreateAPIKey(operator) error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		token  string
+		body   string
+		want   int
+	}{
+		{name: "viewer reads storage stats", method: http.MethodGet, target: "/api/ops/storage/stats", token: viewerToken, want: http.StatusOK},
+		{name: "viewer cannot create session", method: http.MethodPost, target: "/api/tmux/sessions", token: viewerToken, body: `{}`, want: http.StatusForbidden},
+		{name: "viewer may update presence", method: http.MethodPut, target: "/api/tmux/presence", token: viewerToken, body: `{}`, want: http.StatusBadRequest},
+		{name: "operator cannot flush storage", method: http.MethodPost, target: "/api/ops/storage/flush", token: operatorToken, body: `{}`, want: http.StatusForbidden},
+		{name: "operator cannot manage keys", method: http.MethodGet, target: "/api/auth/keys", token: operatorToken, want: http.StatusForbidden},
+		{name: "owner token manages keys", method: http.MethodGet, target: "/api/auth/keys", token: "secret", want: http.StatusOK},
+		{name: "missing credentials", method: http.MethodGet, target: "/api/ops/storage/stats", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := serveWithBearer(mux, tt.method, tt.target, tt.token, tt.body)
+			if w.Code != tt.want {
+				t.Fatalf("%s %s status = %d, want %d; body=%s", tt.method, tt.target, w.Code, tt.want, w.Body.String())
+			}
+			if tt.want == http.StatusForbidden && !strings.Contains(w.Body.String(), "FORBIDDEN") {
+				t.Fatalf("forbidden body = %s, want FORBIDDEN code", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateAPIKeyRequiresServerToken(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/keys", strings.NewReader(`{"name":"ci","role":"viewer"}`))
+	h.createAPIKey(w, r)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", w.Code)
+	}
+}
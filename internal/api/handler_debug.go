@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"time"
+)
+
+// processStart approximates when the daemon started, for uptime.
+var processStart = time.Now()
+
+type runtimeStats struct {
+	GoVersion    string  `json:"goVersion"`
+	NumCPU       int     `json:"numCpu"`
+	GOMAXPROCS   int     `json:"gomaxprocs"`
+	Goroutines   int     `json:"goroutines"`
+	UptimeSec    int64   `json:"uptimeSec"`
+	HeapAlloc    uint64  `json:"heapAllocBytes"`
+	HeapInuse    uint64  `json:"heapInuseBytes"`
+	HeapIdle     uint64  `json:"heapIdleBytes"`
+	HeapReleased uint64  `json:"heapReleasedBytes"`
+	HeapObjects  uint64  `json:"heapObjects"`
+	Sys          uint64  `json:"sysBytes"`
+	TotalAlloc   uint64  `json:"totalAllocBytes"`
+	NumGC        uint32  `json:"numGc"`
+	LastGC       string  `json:"lastGc,omitempty"`
+	PauseTotalMs float64 `json:"pauseTotalMs"`
+}
+
+func readRuntimeStats() runtimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	stats := runtimeStats{
+		GoVersion:    runtime.Version(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		Goroutines:   runtime.NumGoroutine(),
+		UptimeSec:    int64(time.Since(processStart).Seconds()),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapInuse:    mem.HeapInuse,
+		HeapIdle:     mem.HeapIdle,
+		HeapReleased: mem.HeapReleased,
+		HeapObjects:  mem.HeapObjects,
+		Sys:          mem.Sys,
+		TotalAlloc:   mem.TotalAlloc,
+		NumGC:        mem.NumGC,
+		PauseTotalMs: float64(mem.PauseTotalNs) / float64(time.Millisecond),
+	}
+	if mem.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(mem.LastGC)).UTC().Format(time.RFC3339)
+	}
+	return stats
+}
+
+func (h *Handler) debugRuntime(w http.ResponseWriter, _ *http.Request) {
+	writeData(w, http.StatusOK, readRuntimeStats())
+}
+
+// debugGC forces a collection and returns memory to the OS, reporting the
+// heap before and after.
+func (h *Handler) debugGC(w http.ResponseWriter, _ *http.Request) {
+	before := readRuntimeStats()
+	start := time.Now()
+	debug.FreeOSMemory()
+	writeData(w, http.StatusOK, map[string]any{
+		"before":     before,
+		"after":      readRuntimeStats(),
+		"durationMs": time.Since(start).Milliseconds(),
+	})
+}
+
+// debugGoroutines writes the stack of every goroutine as plain text, the
+// format of a crash dump.
+func (h *Handler) debugGoroutines(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="goroutines.txt"`)
+	_ = rpprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// debugProfile serves a named runtime profile such as heap or goroutine.
+// net/http/pprof.Index only resolves names under /debug/pprof/, so the
+// profile is looked up here.
+func debugProfile(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("profile")
+	if rpprof.Lookup(name) == nil {
+		writeError(w, http.StatusNotFound, "PROFILE_NOT_FOUND", "unknown profile", nil)
+		return
+	}
+	pprof.Handler(name).ServeHTTP(w, r)
+}
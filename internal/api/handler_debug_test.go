@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func TestDebugRoutesRequireAdmin(t *testing.T) {
+	t.Parallel()
+
+	mux, st := newRoleTestMux(t)
+	_, operatorToken, err := st.CreateAPIKey(context.Background(), store.APIKeyWrite{Name: "operator", Role: "operator"})
+	if err != nil {
+		t.Fatalf("CreateAPIKey(operator) error = %v", err)
+	}
+	for _, target := range []string{"/api/debug/runtime", "/api/debug/goroutines", "/api/debug/vars", "/api/debug/pprof/", "/api/debug/pprof/heap"} {
+		if w := serveWithBearer(mux, http.MethodGet, target, operatorToken, ""); w.Code != http.StatusForbidden {
+			t.Errorf("operator GET %s status = %d, want 403", target, w.Code)
+		}
+	}
+	if w := serveWithBearer(mux, http.MethodPost, "/api/debug/gc", operatorToken, ""); w.Code != http.StatusForbidden {
+		t.Errorf("operator POST /api/debug/gc status = %d, want 403", w.Code)
+	}
+}
+
+func TestDebugEndpoints(t *testing.T) {
+	t.Parallel()
+
+	mux, _ := newRoleTestMux(t)
+
+	w := serveWithBearer(mux, http.MethodGet, "/api/debug/runtime", "secret", "")
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	if w.Code != http.StatusOK || data["goroutines"].(float64) < 1 || data["heapAllocBytes"].(float64) <= 0 {
+		t.Fatalf("runtime status = %d, data = %v", w.Code, data)
+	}
+
+	w = serveWithBearer(mux, http.MethodPost, "/api/debug/gc", "secret", "")
+	data, _ = jsonBody(t, w)["data"].(map[string]any)
+	before, _ := data["before"].(map[string]any)
+	after, _ := data["after"].(map[string]any)
+	if w.Code != http.StatusOK || after["numGc"].(float64) <= before["numGc"].(float64) {
+		t.Fatalf("gc status = %d, data = %v", w.Code, data)
+	}
+
+	for _, tc := range []struct {
+		target string
+		want   string
+	}{
+		{target: "/api/debug/goroutines", want: "goroutine "},
+		{target: "/api/debug/vars", want: `"memstats"`},
+		{target: "/api/debug/pprof/", want: "Types of profiles available"},
+		{target: "/api/debug/pprof/heap?debug=1", want: "heap profile"},
+	} {
+		w := serveWithBearer(mux, http.MethodGet, tc.target, "secret", "")
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tc.want) {
+			t.Errorf("GET %s status = %d, body lacks %q", tc.target, w.Code, tc.want)
+		}
+	}
+
+	if w := serveWithBearer(mux, http.MethodGet, "/api/debug/pprof/nope", "secret", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown profile status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+)
+
+// eventsStreamHeartbeat keeps idle event streams alive through proxies.
+const eventsStreamHeartbeat = 20 * time.Second
+
+// streamEvents relays the event hub as server-sent events, for clients
+// behind proxies that break the /ws/events upgrade. Each event is the same
+// JSON object /ws/events sends, named after its type and carrying its
+// eventId as the SSE id, so a reconnecting EventSource resumes from the
+// Last-Event-ID header; a "since" query parameter does the same for other
+// clients. "types", comma-separated or repeated, limits the stream to
+// those event types. events.ready always comes first.
+func (h *Handler) streamEvents(w http.ResponseWriter, r *http.Request) {
+	if h.events == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "events are unavailable", nil)
+		return
+	}
+	types, err := parseEventTypes(r.URL.Query()["types"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	rawSince := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if rawSince == "" {
+		rawSince = strings.TrimSpace(r.URL.Query().Get("since"))
+	}
+	var since int64
+	if rawSince != "" {
+		since, err = strconv.ParseInt(rawSince, 10, 64)
+		if err != nil || since < 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "since must be a non-negative event id", nil)
+			return
+		}
+	}
+
+	eventsCh, replay, complete, unsubscribe := h.events.SubscribeSince(64, since)
+	defer unsubscribe()
+
+	rc := http.NewResponseController(w)
+	// The stream stays open past the server write timeout.
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	ready := events.NewEvent(events.TypeReady, map[string]any{
+		"message":        "subscribed",
+		"latestEventId":  h.events.LatestEventID(),
+		"replayed":       len(replay),
+		"replayComplete": complete,
+	})
+	if err := writeSSEEvent(w, rc, ready); err != nil {
+		return
+	}
+	wanted := func(evt events.Event) bool {
+		return len(types) == 0 || slices.Contains(types, evt.Type)
+	}
+	for _, evt := range replay {
+		if !wanted(evt) {
+			continue
+		}
+		if err := writeSSEEvent(w, rc, evt); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(eventsStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case evt, ok := <-eventsCh:
+			if !ok {
+				return
+			}
+			if !wanted(evt) {
+				continue
+			}
+			if err := writeSSEEvent(w, rc, evt); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseEventTypes reads the "types" filter. Unknown types are rejected so
+// that a typo does not silently yield an empty stream.
+func parseEventTypes(values []string) ([]string, error) {
+	known := events.Types()
+	var types []string
+	for _, value := range values {
+		for _, eventType := range strings.Split(value, ",") {
+			eventType = strings.TrimSpace(eventType)
+			if eventType == "" {
+				continue
+			}
+			if !slices.Contains(known, eventType) {
+				return nil, fmt.Errorf("unknown event type %q", eventType)
+			}
+			types = append(types, eventType)
+		}
+	}
+	return types, nil
+}
+
+// writeSSEEvent writes a hub event. Live-only events such as ops.job.log
+// have no eventId and so no SSE id, which leaves the client's resume point
+// unchanged.
+func writeSSEEvent(w io.Writer, rc *http.ResponseController, evt events.Event) error {
+	raw, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	if evt.EventID > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", evt.EventID); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, raw); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/events"
+)
+
+func TestStreamEvents(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.events = events.NewHub()
+	h.emit(events.TypeOpsServices, map[string]any{"n": 1})
+	h.emit(events.TypeOpsMetrics, map[string]any{"n": 2})
+	h.emit(events.TypeOpsServices, map[string]any{"n": 3})
+
+	srv := httptest.NewServer(http.HandlerFunc(h.streamEvents))
+	t.Cleanup(srv.Close)
+
+	// Resume after event 1, keeping service events only.
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/events/stream?types=ops.services.updated,ops.job.log", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	ready := readSSEEvent(t, reader)
+	if !strings.HasPrefix(ready, "event: events.ready\n") || !strings.Contains(ready, `"replayed":2`) || !strings.Contains(ready, `"replayComplete":true`) {
+		t.Fatalf("ready event = %q", ready)
+	}
+	if got := readSSEEvent(t, reader); !strings.HasPrefix(got, "id: 3\nevent: ops.services.updated\n") {
+		t.Fatalf("replayed event = %q, want event 3 only", got)
+	}
+
+	h.emit(events.TypeOpsMetrics, map[string]any{"n": 4})
+	h.emit(events.TypeOpsJobLog, map[string]any{"chunk": "hello"})
+	if got := readSSEEvent(t, reader); !strings.HasPrefix(got, "event: ops.job.log\n") || !strings.Contains(got, `"chunk":"hello"`) {
+		t.Fatalf("live event = %q, want the job log without an id", got)
+	}
+}
+
+func TestStreamEventsRejectsBadQuery(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.events = events.NewHub()
+	for _, query := range []string{"types=ops.nope", "since=-1", "since=abc"} {
+		w := httptest.NewRecorder()
+		h.streamEvents(w, httptest.NewRequest(http.MethodGet, "/api/events/stream?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+// readSSEEvent reads one event, up to its blank line, skipping comments.
+func readSSEEvent(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+	var b strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v (got %q)", err, b.String())
+		}
+		if line == "\n" {
+			if b.Len() > 0 {
+				return b.String()
+			}
+			continue
+		}
+		if !strings.HasPrefix(line, ":") {
+			b.WriteString(line)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/opus-domini/sentinel/internal/files"
+	"github.com/opus-domini/sentinel/internal/security"
+)
+
+// filesBrowser reads and writes files under the configured roots.
+type filesBrowser interface {
+	Roots() []string
+	List(path string) ([]files.Entry, error)
+	Open(path string) (*os.File, files.Entry, error)
+	Write(dir, name string, r io.Reader, overwrite bool) (files.Entry, error)
+	Rename(path, newName string) (files.Entry, error)
+	Remove(path string) error
+}
+
+// SetFiles enables the file endpoints. Uploads larger than maxUploadBytes
+// are rejected.
+func (h *Handler) SetFiles(browser filesBrowser, maxUploadBytes int64) {
+	if h == nil {
+		return
+	}
+	h.files = browser
+	h.maxUploadBytes = maxUploadBytes
+}
+
+func (h *Handler) filesEnabled(w http.ResponseWriter) bool {
+	if h.files == nil {
+		writeError(w, http.StatusNotFound, "FILES_DISABLED", "file access is disabled", nil)
+		return false
+	}
+	return true
+}
+
+func writeFilesError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "upload exceeds the size limit", nil)
+	case errors.Is(err, files.ErrOutsideRoots):
+		writeError(w, http.StatusForbidden, "PATH_NOT_ALLOWED", "path is outside the allowed directories", nil)
+	case errors.Is(err, files.ErrRoot):
+		writeError(w, http.StatusForbidden, "PATH_NOT_ALLOWED", "root directories cannot be changed", nil)
+	case errors.Is(err, files.ErrInvalidName):
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid file name", nil)
+	case errors.Is(err, files.ErrIsDir), errors.Is(err, files.ErrNotDir):
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+	case errors.Is(err, files.ErrExists):
+		writeError(w, http.StatusConflict, "FILE_EXISTS", "file already exists", nil)
+	case errors.Is(err, fs.ErrNotExist):
+		writeError(w, http.StatusNotFound, "FILE_NOT_FOUND", "file not found", nil)
+	case errors.Is(err, fs.ErrPermission):
+		writeError(w, http.StatusForbidden, "PERMISSION_DENIED", "permission denied", nil)
+	default:
+		writeError(w, http.StatusInternalServerError, "FILES_ERROR", "file operation failed", nil)
+	}
+}
+
+// listFiles lists a directory under the roots. Without a path it returns
+// the roots themselves, so the UI has a place to start when the pane's
+// working directory is outside them.
+func (h *Handler) listFiles(w http.ResponseWriter, r *http.Request) {
+	if !h.filesEnabled(w) {
+		return
+	}
+	path := strings.TrimSpace(r.URL.Query().Get("path"))
+	if path == "" {
+		writeData(w, http.StatusOK, map[string]any{"roots": h.files.Roots()})
+		return
+	}
+	entries, err := h.files.List(path)
+	if err != nil {
+		writeFilesError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"path": path, "entries": entries})
+}
+
+func (h *Handler) downloadFile(w http.ResponseWriter, r *http.Request) {
+	if !h.filesEnabled(w) {
+		return
+	}
+	f, entry, err := h.files.Open(r.URL.Query().Get("path"))
+	if err != nil {
+		writeFilesError(w, err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": entry.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, entry.Name, entry.ModifiedAt, f)
+}
+
+// uploadFile stores the raw request body as dir/name.
+func (h *Handler) uploadFile(w http.ResponseWriter, r *http.Request) {
+	if !h.filesEnabled(w) {
+		return
+	}
+	query := r.URL.Query()
+	overwrite := query.Get("overwrite") == "true"
+	// Replacing a file destroys its contents, like rename and delete.
+	if overwrite {
+		if id, _ := security.IdentityFromContext(r.Context()); !id.Role.Allows(security.RoleAdmin) {
+			writeError(w, http.StatusForbidden, "FORBIDDEN", "role does not allow this action", map[string]any{
+				"role":         id.Role,
+				"requiredRole": security.RoleAdmin,
+			})
+			return
+		}
+	}
+	if r.ContentLength > h.maxUploadBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "upload exceeds the size limit", nil)
+		return
+	}
+	body := http.MaxBytesReader(w, r.Body, h.maxUploadBytes)
+	defer func() { _ = body.Close() }()
+
+	entry, err := h.files.Write(query.Get("dir"), strings.TrimSpace(query.Get("name")), body, overwrite)
+	if err != nil {
+		writeFilesError(w, err)
+		return
+	}
+	writeData(w, http.StatusCreated, map[string]any{"file": entry})
+}
+
+func (h *Handler) renameFile(w http.ResponseWriter, r *http.Request) {
+	if !h.filesEnabled(w) {
+		return
+	}
+	var req struct {
+		Path string `json:"path"`
+		Name string `json:"name"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	entry, err := h.files.Rename(req.Path, strings.TrimSpace(req.Name))
+	if err != nil {
+		writeFilesError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"file": entry})
+}
+
+func (h *Handler) deleteFile(w http.ResponseWriter, r *http.Request) {
+	if !h.filesEnabled(w) {
+		return
+	}
+	if err := h.files.Remove(r.URL.Query().Get("path")); err != nil {
+		writeFilesError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
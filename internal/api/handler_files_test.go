@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/files"
+	"github.com/opus-domini/sentinel/internal/security"
+)
+
+func TestFilesDisabled(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, &mockTmux{})
+	w := httptest.NewRecorder()
+	h.listFiles(w, httptest.NewRequest(http.MethodGet, "/api/fs/files", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+	if code := jsonBody(t, w)["error"].(map[string]any)["code"]; code != "FILES_DISABLED" {
+		t.Fatalf("code = %v, want FILES_DISABLED", code)
+	}
+}
+
+func TestFilesUploadListDownloadDelete(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	h, _ := newTestHandler(t, &mockTmux{})
+	h.SetFiles(files.New([]string{root}), 16)
+
+	upload := func(name, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		target := "/api/fs/files/upload?dir=" + url.QueryEscape(root) + "&name=" + url.QueryEscape(name)
+		h.uploadFile(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+		return w
+	}
+	if w := upload("build.log", "ok\n"); w.Code != http.StatusCreated {
+		t.Fatalf("upload status = %d; body=%s", w.Code, w.Body.String())
+	}
+	if w := upload("build.log", "again"); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate upload status = %d, want 409", w.Code)
+	}
+	overwrite := func(role security.Role) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		target := "/api/fs/files/upload?overwrite=true&dir=" + url.QueryEscape(root) + "&name=build.log"
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader("ok\n"))
+		h.uploadFile(w, r.WithContext(security.WithIdentity(r.Context(), security.Identity{Name: "test", Role: role})))
+		return w
+	}
+	if w := overwrite(security.RoleOperator); w.Code != http.StatusForbidden {
+		t.Fatalf("operator overwrite status = %d, want 403", w.Code)
+	}
+	if w := overwrite(security.RoleAdmin); w.Code != http.StatusCreated {
+		t.Fatalf("admin overwrite status = %d; body=%s", w.Code, w.Body.String())
+	}
+	if w := upload("big.bin", strings.Repeat("x", 17)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("large upload status = %d, want 413", w.Code)
+	}
+	if _, err := os.Stat(filepath.Join(root, "big.bin")); !os.IsNotExist(err) {
+		t.Fatalf("big.bin stat error = %v, want not exist", err)
+	}
+
+	w := httptest.NewRecorder()
+	h.listFiles(w, httptest.NewRequest(http.MethodGet, "/api/fs/files?path="+url.QueryEscape(root), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d; body=%s", w.Code, w.Body.String())
+	}
+	entries := jsonBody(t, w)["data"].(map[string]any)["entries"].([]any)
+	if len(entries) != 1 || entries[0].(map[string]any)["size"] != float64(3) {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	path := url.QueryEscape(filepath.Join(root, "build.log"))
+	w = httptest.NewRecorder()
+	h.downloadFile(w, httptest.NewRequest(http.MethodGet, "/api/fs/files/download?path="+path, nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok\n" {
+		t.Fatalf("download = %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename=build.log` {
+		t.Fatalf("Content-Disposition = %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	h.deleteFile(w, httptest.NewRequest(http.MethodDelete, "/api/fs/files?path="+path, nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d; body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestFilesErrors(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	h, _ := newTestHandler(t, &mockTmux{})
+	h.SetFiles(files.New([]string{root}), 1024)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		target  string
+		body    string
+		want    int
+	}{
+		{name: "outside roots", handler: h.listFiles, method: http.MethodGet, target: "/api/fs/files?path=/etc", want: http.StatusForbidden},
+		{name: "missing file", handler: h.downloadFile, method: http.MethodGet, target: "/api/fs/files/download?path=" + url.QueryEscape(root+"/nope"), want: http.StatusNotFound},
+		{name: "download directory", handler: h.downloadFile, method: http.MethodGet, target: "/api/fs/files/download?path=" + url.QueryEscape(root), want: http.StatusBadRequest},
+		{name: "delete root", handler: h.deleteFile, method: http.MethodDelete, target: "/api/fs/files?path=" + url.QueryEscape(root), want: http.StatusForbidden},
+		{name: "rename to path", handler: h.renameFile, method: http.MethodPost, target: "/api/fs/files/rename", body: `{"path":"` + root + `/a","name":"../b"}`, want: http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tc.handler(w, httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body)))
+			if w.Code != tc.want {
+				t.Fatalf("status = %d, want %d; body=%s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
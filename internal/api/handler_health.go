@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// Component states reported by GET /readyz.
+const (
+	componentOK       = "ok"
+	componentFail     = "fail"
+	componentDisabled = "disabled"
+)
+
+// watchtowerStaleTicks is how many collect intervals may pass without a
+// collection before readiness fails, and minWatchtowerStaleAfter the floor
+// for short intervals.
+const (
+	watchtowerStaleTicks    = 5
+	minWatchtowerStaleAfter = 30 * time.Second
+)
+
+type componentStatus struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SetWatchtowerTick makes GET /readyz check that watchtower collects
+// regularly at the given interval. Zero leaves the check disabled.
+func (h *Handler) SetWatchtowerTick(interval time.Duration) {
+	if h == nil {
+		return
+	}
+	h.watchtowerTick = interval
+}
+
+// healthz reports that the process is up. It is public so load balancers
+// and uptime monitors need no token.
+func (h *Handler) healthz(w http.ResponseWriter, _ *http.Request) {
+	writeData(w, http.StatusOK, map[string]any{"status": componentOK})
+}
+
+// readyz checks the dependencies requests rely on and answers 503 when one
+// of them fails. Details stay short since the route is public.
+func (h *Handler) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	components := map[string]componentStatus{
+		"store":      h.storeReadiness(ctx),
+		"tmux":       tmuxReadiness(),
+		"watchtower": h.watchtowerReadiness(ctx, time.Now()),
+	}
+	status, code := "ready", http.StatusOK
+	for _, component := range components {
+		if component.Status == componentFail {
+			status, code = "not_ready", http.StatusServiceUnavailable
+			break
+		}
+	}
+	writeData(w, code, map[string]any{"status": status, "components": components})
+}
+
+func (h *Handler) storeReadiness(ctx context.Context) componentStatus {
+	if h.repo == nil {
+		return componentStatus{Status: componentFail, Detail: "store is unavailable"}
+	}
+	if err := h.repo.Ping(ctx); err != nil {
+		return componentStatus{Status: componentFail, Detail: "database is unreachable"}
+	}
+	return componentStatus{Status: componentOK}
+}
+
+func tmuxReadiness() componentStatus {
+	// Windows runs only the ops half of Sentinel.
+	if runtime.GOOS == "windows" {
+		return componentStatus{Status: componentDisabled}
+	}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return componentStatus{Status: componentFail, Detail: "tmux binary not found"}
+	}
+	return componentStatus{Status: componentOK}
+}
+
+func (h *Handler) watchtowerReadiness(ctx context.Context, now time.Time) componentStatus {
+	if h.watchtowerTick <= 0 {
+		return componentStatus{Status: componentDisabled}
+	}
+	if h.repo == nil {
+		return componentStatus{Status: componentFail, Detail: "store is unavailable"}
+	}
+	raw, err := h.repo.GetWatchtowerRuntimeValue(ctx, "last_collect_at")
+	if err != nil {
+		return componentStatus{Status: componentFail, Detail: "collection state is unreadable"}
+	}
+	last, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return componentStatus{Status: componentFail, Detail: "no collection yet"}
+	}
+	staleAfter := max(watchtowerStaleTicks*h.watchtowerTick, minWatchtowerStaleAfter)
+	if age := now.Sub(last); age > staleAfter {
+		return componentStatus{Status: componentFail, Detail: "last collection " + age.Truncate(time.Second).String() + " ago"}
+	}
+	return componentStatus{Status: componentOK}
+}
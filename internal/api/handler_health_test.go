@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestHealthEndpointsNeedNoToken(t *testing.T) {
+	t.Parallel()
+
+	mux, _ := newRoleTestMux(t)
+	if w := serveWithBearer(mux, http.MethodGet, "/healthz", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("healthz status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+
+	w := serveWithBearer(mux, http.MethodGet, "/readyz", "", "")
+	wantCode, wantStatus := http.StatusOK, "ready"
+	if _, err := exec.LookPath("tmux"); err != nil {
+		wantCode, wantStatus = http.StatusServiceUnavailable, "not_ready"
+	}
+	if w.Code != wantCode {
+		t.Fatalf("readyz status = %d, want %d; body=%s", w.Code, wantCode, w.Body.String())
+	}
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	components, _ := data["components"].(map[string]any)
+	store, _ := components["store"].(map[string]any)
+	watchtower, _ := components["watchtower"].(map[string]any)
+	if data["status"] != wantStatus || store["status"] != componentOK || watchtower["status"] != componentDisabled {
+		t.Fatalf("readyz data = %v", data)
+	}
+}
+
+func TestWatchtowerReadiness(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	h.SetWatchtowerTick(time.Second)
+
+	if got := h.watchtowerReadiness(ctx, now); got.Status != componentFail {
+		t.Fatalf("before first collection = %+v, want fail", got)
+	}
+	for _, tc := range []struct {
+		age  time.Duration
+		want string
+	}{
+		{age: 2 * time.Second, want: componentOK},
+		{age: 29 * time.Second, want: componentOK},
+		{age: time.Minute, want: componentFail},
+	} {
+		if err := st.SetWatchtowerRuntimeValues(ctx, map[string]string{
+			"last_collect_at": now.Add(-tc.age).Format(time.RFC3339),
+		}); err != nil {
+			t.Fatalf("SetWatchtowerRuntimeValues: %v", err)
+		}
+		if got := h.watchtowerReadiness(ctx, now); got.Status != tc.want {
+			t.Fatalf("collected %s ago = %+v, want %s", tc.age, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/federation"
+	"github.com/opus-domini/sentinel/internal/security"
+)
+
+// hostRelayTimeout bounds a relayed call, including the agent's handler.
+const hostRelayTimeout = 30 * time.Second
+
+// hostRelay lists federated hosts and relays API calls to them.
+type hostRelay interface {
+	Hosts() []federation.Host
+	Forward(ctx context.Context, host string, req federation.Request) (federation.Response, error)
+}
+
+// hostRoutes are the routes NewHostHandler serves: tmux sessions, windows
+// and panes, and service inspection and control. Routes that rely on the
+// central's store (presets, launchers, annotations, tracked services) or on
+// local files and processes are left out.
+var hostRoutes = map[string]bool{
+	"GET /api/tmux/sessions":                                   true,
+	"POST /api/tmux/sessions":                                  true,
+	"PATCH /api/tmux/sessions/{session}":                       true,
+	"DELETE /api/tmux/sessions/{session}":                      true,
+	"POST /api/tmux/sessions/{session}/rename-window":          true,
+	"POST /api/tmux/sessions/{session}/rename-pane":            true,
+	"POST /api/tmux/sessions/{session}/select-window":          true,
+	"POST /api/tmux/sessions/{session}/select-pane":            true,
+	"POST /api/tmux/sessions/{session}/new-window":             true,
+	"POST /api/tmux/sessions/{session}/kill-window":            true,
+	"POST /api/tmux/sessions/{session}/kill-pane":              true,
+	"POST /api/tmux/sessions/{session}/split-pane":             true,
+	"GET /api/tmux/sessions/{session}/windows":                 true,
+	"GET /api/tmux/sessions/{session}/panes":                   true,
+	"GET /api/tmux/sessions/{session}/panes/{pane}/capture":    true,
+	"POST /api/tmux/sessions/{session}/panes/{pane}/send-keys": true,
+	"POST /api/tmux/sessions/{session}/panes/{pane}/zoom":      true,
+	"GET /api/ops/overview":                                    true,
+	"GET /api/ops/services":                                    true,
+	"GET /api/ops/services/browse":                             true,
+	"GET /api/ops/services/discover":                           true,
+	"GET /api/ops/services/{service}/status":                   true,
+	"POST /api/ops/services/{service}/action":                  true,
+	"GET /api/ops/services/{service}/logs":                     true,
+	"POST /api/ops/services/unit/action":                       true,
+	"GET /api/ops/services/unit/status":                        true,
+	"GET /api/ops/services/unit/logs":                          true,
+}
+
+// NewHostHandler returns the API of a host this daemon drives itself, such
+// as an SSH host, backed by tmuxSvc and ops. It is served through
+// federation, which supplies the caller's identity; the route roles are the
+// same as on the local API. Requests without a relayed identity are refused.
+func NewHostHandler(tmuxSvc tmuxService, ops opsControlPlane) http.Handler {
+	h := &Handler{
+		// The token only keeps requests without a relayed identity out.
+		guard: security.New(rand.Text(), nil, security.CookieSecureAuto),
+		tmux:  tmuxSvc,
+		ops:   ops,
+	}
+	var routes []routeBinding
+	for _, route := range append(h.tmuxRoutes(), h.servicesRoutes()...) {
+		if hostRoutes[route.pattern] {
+			routes = append(routes, route)
+		}
+	}
+	mux := http.NewServeMux()
+	h.registerRoutes(mux, routes)
+	return mux
+}
+
+// SetFederation enables the host endpoints backed by relay and lets runbook
+// service steps reach the hosts.
+func (h *Handler) SetFederation(relay hostRelay) {
+	if h == nil {
+		return
+	}
+	h.hosts = relay
+	h.runbooks.SetHostTargets(h.hostTargets())
+}
+
+func (h *Handler) listHosts(w http.ResponseWriter, _ *http.Request) {
+	hosts := []federation.Host{}
+	if h.hosts != nil {
+		hosts = h.hosts.Hosts()
+	}
+	writeData(w, http.StatusOK, map[string]any{"hosts": hosts})
+}
+
+// proxyHost relays /api/hosts/{host}/{path...} to /api/{path...} on the
+// agent. The caller only needs to be authenticated here: the agent checks
+// the route's own role against the forwarded identity.
+func (h *Handler) proxyHost(w http.ResponseWriter, r *http.Request) {
+	if h.hosts == nil {
+		writeError(w, http.StatusNotFound, "HOST_NOT_FOUND", "federation is not enabled", nil)
+		return
+	}
+	host := strings.TrimSpace(r.PathValue("host"))
+	path := "/api/" + r.PathValue("path")
+	if !federation.Relayable(path) {
+		writeError(w, http.StatusNotFound, "HOST_ROUTE_UNSUPPORTED", "only /api/tmux and /api/ops calls are relayed to hosts", nil)
+		return
+	}
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+	defer func() { _ = r.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "failed to read request body", nil)
+		return
+	}
+	header := http.Header{}
+	for _, name := range []string{"Accept", "Content-Type"} {
+		if value := r.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	id, _ := security.IdentityFromContext(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), hostRelayTimeout)
+	defer cancel()
+	resp, err := h.hosts.Forward(ctx, host, federation.Request{
+		Method:   r.Method,
+		Path:     path,
+		Header:   header,
+		Body:     body,
+		Identity: id,
+	})
+	switch {
+	case errors.Is(err, federation.ErrHostNotFound):
+		writeError(w, http.StatusNotFound, "HOST_NOT_FOUND", "host is not connected", map[string]any{"host": host})
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, "HOST_TIMEOUT", "host did not respond in time", map[string]any{"host": host})
+		return
+	case err != nil:
+		slog.WarnContext(r.Context(), "host relay failed", "host", host, "path", path, "err", err)
+		writeError(w, http.StatusBadGateway, "HOST_UNREACHABLE", "host dropped the connection", map[string]any{"host": host})
+		return
+	}
+	for _, name := range []string{"Content-Type", "Retry-After"} {
+		if value := resp.Header.Get(name); value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write(resp.Body)
+}
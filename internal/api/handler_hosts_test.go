@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/federation"
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+type fakeHostRelay struct {
+	hosts     []federation.Host
+	forwardFn func(ctx context.Context, host string, req federation.Request) (federation.Response, error)
+}
+
+func (f *fakeHostRelay) Hosts() []federation.Host { return f.hosts }
+
+func (f *fakeHostRelay) Forward(ctx context.Context, host string, req federation.Request) (federation.Response, error) {
+	return f.forwardFn(ctx, host, req)
+}
+
+func hostProxyRequest(method, host, path, body string) *http.Request {
+	r := httptest.NewRequest(method, "/api/hosts/"+host+"/"+path, strings.NewReader(body))
+	r.SetPathValue("host", host)
+	rest, _, _ := strings.Cut(path, "?")
+	r.SetPathValue("path", rest)
+	return r.WithContext(security.WithIdentity(r.Context(), security.Identity{Name: "ci", Role: security.RoleOperator}))
+}
+
+func TestListHosts(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	w := httptest.NewRecorder()
+	h.listHosts(w, httptest.NewRequest(http.MethodGet, "/api/hosts", nil))
+	if hosts := jsonBody(t, w)["data"].(map[string]any)["hosts"].([]any); len(hosts) != 0 {
+		t.Fatalf("hosts without federation = %v, want empty", hosts)
+	}
+
+	h.SetFederation(&fakeHostRelay{hosts: []federation.Host{{Name: "web-01", Version: "1.2.3", ConnectedAt: time.Now()}}})
+	w = httptest.NewRecorder()
+	h.listHosts(w, httptest.NewRequest(http.MethodGet, "/api/hosts", nil))
+	hosts := jsonBody(t, w)["data"].(map[string]any)["hosts"].([]any)
+	if len(hosts) != 1 || hosts[0].(map[string]any)["name"] != "web-01" {
+		t.Fatalf("hosts = %v", hosts)
+	}
+}
+
+func TestProxyHostRelaysCall(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.SetFederation(&fakeHostRelay{
+		forwardFn: func(_ context.Context, host string, req federation.Request) (federation.Response, error) {
+			if host != "web-01" || req.Method != http.MethodPost || req.Path != "/api/ops/services/nginx/action?dry=1" {
+				t.Errorf("forward %s %s %s", host, req.Method, req.Path)
+			}
+			if string(req.Body) != `{"action":"restart"}` || req.Identity.Name != "ci" {
+				t.Errorf("body = %s, identity = %+v", req.Body, req.Identity)
+			}
+			return federation.Response{
+				Status: http.StatusAccepted,
+				Header: http.Header{"Content-Type": {"application/json"}, "Set-Cookie": {"x=1"}},
+				Body:   []byte(`{"data":{"ok":true}}`),
+			}, nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.proxyHost(w, hostProxyRequest(http.MethodPost, "web-01", "ops/services/nginx/action?dry=1", `{"action":"restart"}`))
+	if w.Code != http.StatusAccepted || w.Body.String() != `{"data":{"ok":true}}` {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Set-Cookie") != "" {
+		t.Fatal("proxy copied Set-Cookie from the host response")
+	}
+}
+
+func TestProxyHostErrors(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	w := httptest.NewRecorder()
+	h.proxyHost(w, hostProxyRequest(http.MethodGet, "web-01", "tmux/sessions", ""))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("disabled federation status = %d, want 404", w.Code)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"unrelayable path", "auth/keys", nil, http.StatusNotFound, "HOST_ROUTE_UNSUPPORTED"},
+		{"stream path", "ops/services/nginx/logs/stream", nil, http.StatusNotFound, "HOST_ROUTE_UNSUPPORTED"},
+		{"unknown host", "tmux/sessions", federation.ErrHostNotFound, http.StatusNotFound, "HOST_NOT_FOUND"},
+		{"timeout", "tmux/sessions", context.DeadlineExceeded, http.StatusGatewayTimeout, "HOST_TIMEOUT"},
+		{"dropped", "tmux/sessions", federation.ErrHostDisconnected, http.StatusBadGateway, "HOST_UNREACHABLE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.SetFederation(&fakeHostRelay{
+				forwardFn: func(context.Context, string, federation.Request) (federation.Response, error) {
+					return federation.Response{}, tt.err
+				},
+			})
+			w := httptest.NewRecorder()
+			h.proxyHost(w, hostProxyRequest(http.MethodGet, "web-01", tt.path, ""))
+			body, _ := io.ReadAll(w.Body)
+			if w.Code != tt.wantStatus || !strings.Contains(string(body), tt.wantCode) {
+				t.Fatalf("status = %d, body = %s; want %d %s", w.Code, body, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestHostHandlerServesHostRoutesWithoutStore(t *testing.T) {
+	t.Parallel()
+
+	tm := &mockTmux{
+		listSessionsFn: func(context.Context) ([]tmux.Session, error) {
+			return []tmux.Session{{Name: "dev", Windows: 1}}, nil
+		},
+		listPanesFn: func(context.Context, string) ([]tmux.Pane, error) {
+			return []tmux.Pane{{Session: "dev", PaneID: "%1", Active: true}}, nil
+		},
+	}
+	handler := NewHostHandler(tm, &mockOpsControlPlane{})
+	unit := "unit=nginx.service&scope=system&manager=systemd"
+	requests := []struct{ method, path, body string }{
+		{http.MethodGet, "/api/tmux/sessions", ""},
+		{http.MethodPost, "/api/tmux/sessions", `{"name":"dev","cwd":"/tmp"}`},
+		{http.MethodPatch, "/api/tmux/sessions/dev", `{"newName":"web"}`},
+		{http.MethodDelete, "/api/tmux/sessions/dev", ""},
+		{http.MethodPost, "/api/tmux/sessions/dev/rename-window", `{"index":0,"name":"logs"}`},
+		{http.MethodPost, "/api/tmux/sessions/dev/rename-pane", `{"paneId":"%1","title":"logs"}`},
+		{http.MethodPost, "/api/tmux/sessions/dev/select-window", `{"index":0}`},
+		{http.MethodPost, "/api/tmux/sessions/dev/select-pane", `{"paneId":"%1"}`},
+		{http.MethodPost, "/api/tmux/sessions/dev/new-window", `{}`},
+		{http.MethodPost, "/api/tmux/sessions/dev/kill-window", `{"index":0}`},
+		{http.MethodPost, "/api/tmux/sessions/dev/kill-pane", `{"paneId":"%1"}`},
+		{http.MethodPost, "/api/tmux/sessions/dev/split-pane", `{"paneId":"%1","direction":"vertical"}`},
+		{http.MethodGet, "/api/tmux/sessions/dev/windows", ""},
+		{http.MethodGet, "/api/tmux/sessions/dev/panes", ""},
+		{http.MethodGet, "/api/tmux/sessions/dev/panes/%251/capture", ""},
+		{http.MethodPost, "/api/tmux/sessions/dev/panes/%251/send-keys", `{"keys":"ls","enter":true}`},
+		{http.MethodPost, "/api/tmux/sessions/dev/panes/%251/zoom", ""},
+		{http.MethodGet, "/api/ops/overview", ""},
+		{http.MethodGet, "/api/ops/services", ""},
+		{http.MethodGet, "/api/ops/services/browse", ""},
+		{http.MethodGet, "/api/ops/services/discover", ""},
+		{http.MethodGet, "/api/ops/services/nginx/status", ""},
+		{http.MethodPost, "/api/ops/services/nginx/action", `{"action":"restart"}`},
+		{http.MethodGet, "/api/ops/services/nginx/logs", ""},
+		{http.MethodPost, "/api/ops/services/unit/action", `{"unit":"nginx.service","scope":"system","manager":"systemd","action":"restart"}`},
+		{http.MethodGet, "/api/ops/services/unit/status?" + unit, ""},
+		{http.MethodGet, "/api/ops/services/unit/logs?" + unit, ""},
+	}
+	if len(requests) != len(hostRoutes) {
+		t.Fatalf("%d requests for %d host routes", len(requests), len(hostRoutes))
+	}
+	admin := security.Identity{Name: "ci", Role: security.RoleAdmin}
+	for _, tc := range requests {
+		r := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		r = r.WithContext(security.WithForwardedIdentity(r.Context(), admin))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code >= 400 {
+			t.Errorf("%s %s = %d %s", tc.method, tc.path, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tmux/sessions", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("request without relayed identity = %d, want 401", w.Code)
+	}
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/tmux/session-presets", nil)
+	handler.ServeHTTP(w, r.WithContext(security.WithForwardedIdentity(r.Context(), admin)))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("store-backed route = %d, want 404", w.Code)
+	}
+}
@@ -324,7 +324,7 @@ func TestRegisterAddsRoutesAndShutsDown(t *testing.T) {
 	mux := http.NewServeMux()
 	guard := security.New("", nil, security.CookieSecureAuto)
 	st := newTestStore(t)
-	h := Register(mux, guard, st, &mockOpsControlPlane{}, events.NewHub(), "v1", "", "UTC", "", nil, 2)
+	h := Register(mux, guard, st, &mockOpsControlPlane{}, events.NewHub(), "v1", "", "UTC", "", nil, nil)
 	if h == nil {
 		t.Fatal("Register returned nil handler")
 	}
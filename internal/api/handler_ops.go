@@ -17,7 +17,7 @@ import (
 )
 
 var (
-	validManagers = []string{"systemd", "launchd"}
+	validManagers = []string{"systemd", "launchd", "docker", "windows", "rcd"}
 	validScopes   = []string{"user", "system", ""}
 )
 
@@ -52,7 +52,7 @@ func (h *Handler) opsServices(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "OPS_UNAVAILABLE", "failed to load ops services", nil)
 		return
 	}
-	writeData(w, http.StatusOK, map[string]any{
+	writeDataETag(w, r, map[string]any{
 		keyServices: services,
 	})
 }
@@ -89,18 +89,32 @@ func (h *Handler) opsServiceAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	// A restart may run a pre-stop and a post-start hook around it.
+	timeout := 8 * time.Second
+	if req.Action == opsplane.ActionRestart {
+		timeout += 2 * opsplane.MaxHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
 	defer cancel()
 
 	serviceStatus, err := h.ops.Act(ctx, serviceName, req.Action)
+	logServiceHooks(r.Context(), serviceName, serviceStatus.Hooks)
 	if err != nil {
 		switch {
+		case errors.Is(err, opsplane.ErrHookFailed):
+			h.emit(events.TypeOpsServices, map[string]any{
+				keyGlobalRev: time.Now().UTC().UnixMilli(),
+				keyService:   serviceName,
+				keyAction:    "hook-failed",
+				keyHooks:     serviceStatus.Hooks,
+			})
+			writeError(w, http.StatusConflict, "OPS_HOOK_FAILED", err.Error(), map[string]any{keyHooks: serviceStatus.Hooks})
 		case errors.Is(err, opsplane.ErrServiceNotFound):
 			writeError(w, http.StatusNotFound, "OPS_SERVICE_NOT_FOUND", "service not found", nil)
 		case errors.Is(err, opsplane.ErrInvalidAction):
 			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid action", nil)
 		default:
-			slog.Warn("ops service action failed", keyService, serviceName, keyAction, req.Action, "err", err)
+			slog.WarnContext(r.Context(), "ops service action failed", keyService, serviceName, keyAction, req.Action, "err", err)
 			writeError(w, http.StatusInternalServerError, "OPS_ACTION_FAILED", "service action failed", nil)
 		}
 		return
@@ -119,12 +133,16 @@ func (h *Handler) opsServiceAction(w http.ResponseWriter, r *http.Request) {
 
 	now := time.Now().UTC()
 	globalRev := now.UnixMilli()
-	h.emit(events.TypeOpsServices, map[string]any{
+	payload := map[string]any{
 		keyGlobalRev: globalRev,
 		keyService:   serviceStatus.Name,
 		keyAction:    req.Action,
 		keyServices:  services,
-	})
+	}
+	if len(serviceStatus.Hooks) > 0 {
+		payload[keyHooks] = serviceStatus.Hooks
+	}
+	h.emit(events.TypeOpsServices, payload)
 	h.emit(events.TypeOpsOverview, map[string]any{
 		keyGlobalRev: globalRev,
 		keyOverview:  overview,
@@ -139,6 +157,18 @@ func (h *Handler) opsServiceAction(w http.ResponseWriter, r *http.Request) {
 	writeData(w, http.StatusOK, response)
 }
 
+// logServiceHooks records the restart hooks run for a service action.
+func logServiceHooks(ctx context.Context, service string, hooks []opsplane.HookResult) {
+	for _, hook := range hooks {
+		level := slog.LevelInfo
+		if !hook.OK {
+			level = slog.LevelWarn
+		}
+		slog.Log(ctx, level, "ops service hook", keyService, service, "hook", hook.Hook,
+			"ok", hook.OK, "detail", hook.Detail, "durationMs", hook.DurationMs)
+	}
+}
+
 func (h *Handler) opsServiceStatus(w http.ResponseWriter, r *http.Request) {
 	if h.ops == nil {
 		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
@@ -160,7 +190,7 @@ func (h *Handler) opsServiceStatus(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, "OPS_SERVICE_NOT_FOUND", "service not found", nil)
 			return
 		}
-		slog.Warn("ops service inspect failed", keyService, serviceName, "err", err)
+		slog.WarnContext(r.Context(), "ops service inspect failed", keyService, serviceName, "err", err)
 		writeError(w, http.StatusInternalServerError, "OPS_ACTION_FAILED", "failed to inspect service", nil)
 		return
 	}
@@ -172,11 +202,13 @@ func (h *Handler) opsServiceStatus(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) registerOpsService(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name        string `json:"name"`
-		DisplayName string `json:"displayName"`
-		Manager     string `json:"manager"`
-		Unit        string `json:"unit"`
-		Scope       string `json:"scope"`
+		Name         string              `json:"name"`
+		DisplayName  string              `json:"displayName"`
+		Manager      string              `json:"manager"`
+		Unit         string              `json:"unit"`
+		Scope        string              `json:"scope"`
+		HealthChecks []store.HealthCheck `json:"healthChecks"`
+		RestartHooks *store.RestartHooks `json:"restartHooks"`
 	}
 	if err := decodeJSON(r, &req); err != nil {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
@@ -194,21 +226,31 @@ func (h *Handler) registerOpsService(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "unit is invalid", nil)
 		return
 	}
+	if err := opsplane.ValidateHealthChecks(req.HealthChecks); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if err := opsplane.ValidateRestartHooks(req.RestartHooks); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
 	if _, err := h.repo.InsertCustomService(ctx, store.CustomServiceWrite{
-		Name:        req.Name,
-		DisplayName: req.DisplayName,
-		Manager:     req.Manager,
-		Unit:        req.Unit,
-		Scope:       req.Scope,
+		Name:         req.Name,
+		DisplayName:  req.DisplayName,
+		Manager:      req.Manager,
+		Unit:         req.Unit,
+		Scope:        req.Scope,
+		HealthChecks: req.HealthChecks,
+		RestartHooks: req.RestartHooks,
 	}); err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint") {
 			writeError(w, http.StatusConflict, "OPS_SERVICE_EXISTS", "service already registered", nil)
 		} else {
-			slog.Warn("register ops service failed", keyName, req.Name, "err", err)
+			slog.WarnContext(r.Context(), "register ops service failed", keyName, req.Name, "err", err)
 			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to register service", nil)
 		}
 		return
@@ -287,30 +329,33 @@ func (h *Handler) opsServiceLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	lines := 100
-	if raw := strings.TrimSpace(r.URL.Query().Get("lines")); raw != "" {
-		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
-			lines = parsed
-		}
+	query, err := parseOpsLogQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
 	defer cancel()
 
-	output, err := h.ops.Logs(ctx, serviceName, lines)
+	output, err := h.ops.Logs(ctx, serviceName, query)
 	if err != nil {
 		if errors.Is(err, opsplane.ErrServiceNotFound) {
 			writeError(w, http.StatusNotFound, "OPS_SERVICE_NOT_FOUND", "service not found", nil)
 			return
 		}
-		slog.Warn("ops service logs failed", keyService, serviceName, "err", err)
+		if isLogQueryError(err) {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+			return
+		}
+		slog.WarnContext(r.Context(), "ops service logs failed", keyService, serviceName, "err", err)
 		writeError(w, http.StatusInternalServerError, "OPS_LOGS_FAILED", "failed to fetch service logs", nil)
 		return
 	}
 
 	writeData(w, http.StatusOK, map[string]any{
 		keyService: serviceName,
-		"lines":    lines,
+		"lines":    query.Lines,
 		"output":   output,
 	})
 }
@@ -325,7 +370,7 @@ func (h *Handler) discoverOpsServices(w http.ResponseWriter, r *http.Request) {
 
 	available, err := h.ops.DiscoverServices(ctx)
 	if err != nil {
-		slog.Warn("ops discover services failed", "err", err)
+		slog.WarnContext(r.Context(), "ops discover services failed", "err", err)
 		writeError(w, http.StatusInternalServerError, "OPS_DISCOVER_FAILED", "failed to discover services", nil)
 		return
 	}
@@ -347,7 +392,7 @@ func (h *Handler) browseOpsServices(w http.ResponseWriter, r *http.Request) {
 
 	services, err := h.ops.BrowseServices(ctx)
 	if err != nil {
-		slog.Warn("ops browse services failed", "err", err)
+		slog.WarnContext(r.Context(), "ops browse services failed", "err", err)
 		writeError(w, http.StatusInternalServerError, "OPS_BROWSE_FAILED", "failed to browse services", nil)
 		return
 	}
@@ -390,7 +435,7 @@ func (h *Handler) opsUnitAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if !slices.Contains(validManagers, req.Manager) {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "manager must be systemd or launchd", nil)
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "manager must be systemd, launchd, docker, windows, or rcd", nil)
 		return
 	}
 	if !slices.Contains(validScopes, req.Scope) {
@@ -405,7 +450,7 @@ func (h *Handler) opsUnitAction(w http.ResponseWriter, r *http.Request) {
 		if errors.Is(err, opsplane.ErrInvalidAction) {
 			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid action", nil)
 		} else {
-			slog.Warn("ops unit action failed", "unit", req.Unit, keyAction, req.Action, "err", err)
+			slog.WarnContext(r.Context(), "ops unit action failed", "unit", req.Unit, keyAction, req.Action, "err", err)
 			writeError(w, http.StatusInternalServerError, "OPS_ACTION_FAILED", "unit action failed", nil)
 		}
 		return
@@ -449,7 +494,7 @@ func (h *Handler) opsUnitStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if !slices.Contains(validManagers, manager) {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "manager must be systemd or launchd", nil)
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "manager must be systemd, launchd, docker, windows, or rcd", nil)
 		return
 	}
 	if !slices.Contains(validScopes, scope) {
@@ -462,7 +507,7 @@ func (h *Handler) opsUnitStatus(w http.ResponseWriter, r *http.Request) {
 
 	status, err := h.ops.InspectByUnit(ctx, unit, scope, manager)
 	if err != nil {
-		slog.Warn("ops unit inspect failed", "unit", unit, "err", err)
+		slog.WarnContext(r.Context(), "ops unit inspect failed", "unit", unit, "err", err)
 		writeError(w, http.StatusInternalServerError, "OPS_ACTION_FAILED", "failed to inspect unit", nil)
 		return
 	}
@@ -487,7 +532,7 @@ func (h *Handler) opsUnitLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if !slices.Contains(validManagers, manager) {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "manager must be systemd or launchd", nil)
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "manager must be systemd, launchd, docker, windows, or rcd", nil)
 		return
 	}
 	if !slices.Contains(validScopes, scope) {
@@ -495,30 +540,67 @@ func (h *Handler) opsUnitLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	lines := 100
-	if raw := strings.TrimSpace(r.URL.Query().Get("lines")); raw != "" {
-		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
-			lines = parsed
-		}
+	query, err := parseOpsLogQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
 	defer cancel()
 
-	output, err := h.ops.LogsByUnit(ctx, unit, scope, manager, lines)
+	output, err := h.ops.LogsByUnit(ctx, unit, scope, manager, query)
 	if err != nil {
-		slog.Warn("ops unit logs failed", "unit", unit, "err", err)
+		if isLogQueryError(err) {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+			return
+		}
+		slog.WarnContext(r.Context(), "ops unit logs failed", "unit", unit, "err", err)
 		writeError(w, http.StatusInternalServerError, "OPS_LOGS_FAILED", "failed to fetch unit logs", nil)
 		return
 	}
 
 	writeData(w, http.StatusOK, map[string]any{
 		"unit":   unit,
-		"lines":  lines,
+		"lines":  query.Lines,
 		"output": output,
 	})
 }
 
+// parseOpsLogQuery reads the lines, since, until, priority and grep query
+// parameters. Times accept RFC3339 or unix seconds; an invalid line count
+// falls back to the default.
+func parseOpsLogQuery(r *http.Request) (opsplane.LogQuery, error) {
+	values := r.URL.Query()
+	query := opsplane.LogQuery{
+		Lines:    100,
+		Priority: strings.TrimSpace(values.Get("priority")),
+		Grep:     strings.TrimSpace(values.Get("grep")),
+	}
+	if raw := strings.TrimSpace(values.Get("lines")); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			query.Lines = parsed
+		}
+	}
+	since, err := parseMetricsHistoryTime(values.Get("since"), time.Time{})
+	if err != nil {
+		return query, errors.New("since must be RFC3339 or unix seconds")
+	}
+	until, err := parseMetricsHistoryTime(values.Get("until"), time.Time{})
+	if err != nil {
+		return query, errors.New("until must be RFC3339 or unix seconds")
+	}
+	query.Since, query.Until = since, until
+	return query, nil
+}
+
+func isLogQueryError(err error) bool {
+	return errors.Is(err, opsplane.ErrInvalidLogQuery) ||
+		errors.Is(err, opsplane.ErrInvalidLogPriority) ||
+		errors.Is(err, opsplane.ErrPriorityUnsupported) ||
+		errors.Is(err, opsplane.ErrInvalidUnit)
+}
+
 func (h *Handler) opsMetrics(w http.ResponseWriter, r *http.Request) {
 	if h.ops == nil {
 		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
@@ -532,3 +614,117 @@ func (h *Handler) opsMetrics(w http.ResponseWriter, r *http.Request) {
 		"metrics": metrics,
 	})
 }
+
+func (h *Handler) opsDisk(w http.ResponseWriter, r *http.Request) {
+	if h.ops == nil {
+		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
+		return
+	}
+	refresh := false
+	if raw := strings.TrimSpace(r.URL.Query().Get("refresh")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "refresh must be a boolean", nil)
+			return
+		}
+		refresh = parsed
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	writeData(w, http.StatusOK, h.ops.DiskUsage(ctx, refresh))
+}
+
+func (h *Handler) opsUPS(w http.ResponseWriter, r *http.Request) {
+	if h.ops == nil {
+		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	status, err := h.ops.UPS(ctx)
+	switch {
+	case errors.Is(err, opsplane.ErrUPSNotConfigured):
+		writeError(w, http.StatusNotFound, "UPS_NOT_CONFIGURED", err.Error(), nil)
+	case err != nil:
+		writeError(w, http.StatusBadGateway, "UPS_UNAVAILABLE", err.Error(), nil)
+	default:
+		writeData(w, http.StatusOK, status)
+	}
+}
+
+const (
+	defaultMetricsHistoryRange  = time.Hour
+	defaultMetricsHistoryPoints = 300
+)
+
+func (h *Handler) opsMetricsHistory(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	query := r.URL.Query()
+	to, err := parseMetricsHistoryTime(query.Get("to"), time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "to must be RFC3339 or unix seconds", nil)
+		return
+	}
+	from, err := parseMetricsHistoryTime(query.Get("from"), to.Add(-defaultMetricsHistoryRange))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "from must be RFC3339 or unix seconds", nil)
+		return
+	}
+	if !to.After(from) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "from must be before to", nil)
+		return
+	}
+	step := to.Sub(from) / defaultMetricsHistoryPoints
+	if raw := strings.TrimSpace(query.Get("step")); raw != "" {
+		step, err = parseMetricsHistoryStep(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "step must be a positive duration such as 30s, 5m, or 1h", nil)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	history, err := h.repo.QueryMetricsHistory(ctx, from, to, step)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to load metrics history", nil)
+		return
+	}
+	writeData(w, http.StatusOK, history)
+}
+
+// parseMetricsHistoryTime accepts RFC3339 timestamps or unix seconds.
+func parseMetricsHistoryTime(raw string, fallback time.Time) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fallback, nil
+	}
+	if secs, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+// parseMetricsHistoryStep accepts Go durations or a bare number of seconds.
+func parseMetricsHistoryStep(raw string) (time.Duration, error) {
+	if secs, err := strconv.Atoi(raw); err == nil {
+		raw += "s"
+		if secs <= 0 {
+			return 0, errors.New("step must be positive")
+		}
+	}
+	step, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, err
+	}
+	if step <= 0 {
+		return 0, errors.New("step must be positive")
+	}
+	return step, nil
+}
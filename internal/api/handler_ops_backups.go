@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/backup"
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/runbook"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+type backupRequest struct {
+	Name       string   `json:"name"`
+	Tool       string   `json:"tool"`
+	Repository string   `json:"repository"`
+	Sources    []string `json:"sources"`
+	// Command replaces the tool's default command template.
+	Command        string `json:"command"`
+	Schedule       string `json:"schedule"`
+	Timezone       string `json:"timezone"`
+	MaxAgeHours    int    `json:"maxAgeHours"`
+	TimeoutMinutes int    `json:"timeoutMinutes"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+func (h *Handler) listBackups(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	backups, err := h.repo.ListOpsBackups(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to list backups", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"backups": backups})
+}
+
+func (h *Handler) createBackup(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	write, ok := h.decodeBackupRequest(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	item, err := h.repo.CreateOpsBackup(ctx, write)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			writeError(w, http.StatusConflict, "BACKUP_EXISTS", "backup already exists", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to create backup", nil)
+		return
+	}
+	writeData(w, http.StatusCreated, map[string]any{"backup": item})
+}
+
+func (h *Handler) updateBackup(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	write, ok := h.decodeBackupRequest(w, r)
+	if !ok {
+		return
+	}
+	write.ID = strings.TrimSpace(r.PathValue("backup"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	item, err := h.repo.UpdateOpsBackup(ctx, write)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "BACKUP_NOT_FOUND", "backup not found", nil)
+		case isUniqueConstraintError(err):
+			writeError(w, http.StatusConflict, "BACKUP_EXISTS", "backup already exists", nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to update backup", nil)
+		}
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"backup": item})
+}
+
+func (h *Handler) deleteBackup(w http.ResponseWriter, r *http.Request) {
+	if h.backups == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	id := strings.TrimSpace(r.PathValue("backup"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.backups.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "BACKUP_NOT_FOUND", "backup not found", nil)
+		case errors.Is(err, store.ErrOpsRunbookActive):
+			writeError(w, http.StatusConflict, "BACKUP_RUNNING", "backup is running", nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to delete backup", nil)
+		}
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{keyRemoved: id})
+}
+
+// runBackup starts a backup outside its schedule. The outcome is recorded
+// by the backup ticker once the run finishes.
+func (h *Handler) runBackup(w http.ResponseWriter, r *http.Request) {
+	if h.backups == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 6*time.Second)
+	defer cancel()
+
+	item, err := h.backups.Run(ctx, strings.TrimSpace(r.PathValue("backup")))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "BACKUP_NOT_FOUND", "backup not found", nil)
+		case errors.Is(err, backup.ErrRunning):
+			writeError(w, http.StatusConflict, "BACKUP_RUNNING", "backup is running", nil)
+		case errors.Is(err, runbook.ErrTooManyExecutions):
+			writeError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", err.Error(), nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "BACKUP_FAILED", err.Error(), nil)
+		}
+		return
+	}
+	h.emit(events.TypeOpsBackups, map[string]any{
+		keyGlobalRev: time.Now().UTC().UnixMilli(),
+		keyAction:    backup.ActionStarted,
+		"backup":     item,
+	})
+	writeData(w, http.StatusAccepted, map[string]any{"backup": item})
+}
+
+func (h *Handler) decodeBackupRequest(w http.ResponseWriter, r *http.Request) (store.OpsBackupWrite, bool) {
+	var req backupRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return store.OpsBackupWrite{}, false
+	}
+	write := store.OpsBackupWrite{
+		Name:           strings.TrimSpace(req.Name),
+		Tool:           strings.ToLower(strings.TrimSpace(req.Tool)),
+		Repository:     strings.TrimSpace(req.Repository),
+		Sources:        make([]string, 0, len(req.Sources)),
+		Command:        strings.TrimSpace(req.Command),
+		Schedule:       strings.TrimSpace(req.Schedule),
+		Timezone:       strings.TrimSpace(req.Timezone),
+		MaxAgeHours:    req.MaxAgeHours,
+		TimeoutMinutes: req.TimeoutMinutes,
+		Enabled:        req.Enabled == nil || *req.Enabled,
+	}
+	for _, source := range req.Sources {
+		write.Sources = append(write.Sources, strings.TrimSpace(source))
+	}
+	if write.Schedule == "" {
+		write.Schedule = backup.DefaultSchedule
+	}
+	if write.Timezone == "" {
+		write.Timezone = h.timezone
+	}
+	if write.Timezone == "" {
+		write.Timezone = "UTC"
+	}
+	if write.MaxAgeHours == 0 {
+		write.MaxAgeHours = backup.DefaultMaxAgeHours
+	}
+	if write.TimeoutMinutes == 0 {
+		write.TimeoutMinutes = backup.DefaultTimeoutMinutes
+	}
+	if err := backup.Validate(write); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return store.OpsBackupWrite{}, false
+	}
+	return write, true
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestBackups(t *testing.T) {
+	t.Parallel()
+
+	mux, _ := newRoleTestMux(t)
+
+	w := serveWithBearer(mux, http.MethodPost, "/api/ops/backups", "secret",
+		`{"name":"home","tool":"tar","repository":"/srv/backups","sources":["/home"]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("create with an unknown tool: status = %d, want 400; body=%s", w.Code, w.Body.String())
+	}
+
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/backups", "secret",
+		`{"name":"home","tool":"Restic","repository":"/srv/restic","sources":[" /home "]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want 201; body=%s", w.Code, w.Body.String())
+	}
+	item, _ := jsonBody(t, w)["data"].(map[string]any)["backup"].(map[string]any)
+	id, _ := item["id"].(string)
+	if id == "" || item["tool"] != "restic" || item["schedule"] != "0 3 * * *" || item["timezone"] != "UTC" ||
+		item["maxAgeHours"] != float64(48) || item["timeoutMinutes"] != float64(360) || item["status"] != "new" {
+		t.Fatalf("created backup = %v, want a new restic backup with default settings", item)
+	}
+
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/backups", "secret",
+		`{"name":"home","tool":"borg","repository":"/srv/borg","sources":["/home"]}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("duplicate create: status = %d, want 409", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodPut, "/api/ops/backups/"+id, "secret",
+		`{"name":"home","tool":"restic","repository":"/srv/restic","command":"true","schedule":"30 2 * * 0","enabled":false}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	item, _ = jsonBody(t, w)["data"].(map[string]any)["backup"].(map[string]any)
+	if item["schedule"] != "30 2 * * 0" || item["command"] != "true" || item["enabled"] != false {
+		t.Fatalf("updated backup = %v", item)
+	}
+	w = serveWithBearer(mux, http.MethodPut, "/api/ops/backups/missing", "secret",
+		`{"name":"other","tool":"restic","repository":"/srv/restic","sources":["/etc"]}`)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("update missing: status = %d, want 404", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/backups/"+id+"/run", "secret", "")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("run: status = %d, want 202; body=%s", w.Code, w.Body.String())
+	}
+	item, _ = jsonBody(t, w)["data"].(map[string]any)["backup"].(map[string]any)
+	if item["status"] != "running" || item["lastJobId"] == "" {
+		t.Fatalf("started backup = %v", item)
+	}
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/backups/"+id+"/run", "secret", "")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("second run: status = %d, want 409", w.Code)
+	}
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/backups/missing/run", "secret", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("run missing: status = %d, want 404", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodGet, "/api/ops/backups", "secret", "")
+	list, _ := jsonBody(t, w)["data"].(map[string]any)["backups"].([]any)
+	if w.Code != http.StatusOK || len(list) != 1 {
+		t.Fatalf("list: status = %d, backups = %v", w.Code, list)
+	}
+
+	w = serveWithBearer(mux, http.MethodDelete, "/api/ops/backups/missing", "secret", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("delete missing: status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (h *Handler) opsCertificates(w http.ResponseWriter, r *http.Request) {
+	if h.ops == nil {
+		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
+		return
+	}
+	refresh := false
+	if raw := strings.TrimSpace(r.URL.Query().Get("refresh")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "refresh must be a boolean", nil)
+			return
+		}
+		refresh = parsed
+	}
+	// A refresh dials every watched endpoint in turn.
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	writeData(w, http.StatusOK, h.ops.Certificates(ctx, refresh))
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+func TestOpsCertificates(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	var gotRefresh []bool
+	h.ops = &mockOpsControlPlane{
+		certificatesFn: func(_ context.Context, refresh bool) opsplane.Certificates {
+			gotRefresh = append(gotRefresh, refresh)
+			return opsplane.Certificates{
+				Certificates: []opsplane.CertificateStatus{{
+					Target:   "example.com",
+					Kind:     opsplane.CertificateKindEndpoint,
+					State:    opsplane.CertificateStateExpiring,
+					NotAfter: "2026-03-01T00:00:00Z",
+					DaysLeft: 13,
+				}},
+				WarnDays:  30,
+				CheckedAt: "2026-02-15T12:00:00Z",
+			}
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.opsCertificates(w, httptest.NewRequest(http.MethodGet, "/api/ops/certificates", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	certs, _ := data["certificates"].([]any)
+	if data["warnDays"] != float64(30) || len(certs) != 1 {
+		t.Fatalf("data = %v, want one certificate", data)
+	}
+	if cert, _ := certs[0].(map[string]any); cert["state"] != opsplane.CertificateStateExpiring || cert["daysLeft"] != float64(13) {
+		t.Fatalf("certificate = %v, want an expiring certificate", cert)
+	}
+
+	w = httptest.NewRecorder()
+	h.opsCertificates(w, httptest.NewRequest(http.MethodGet, "/api/ops/certificates?refresh=1", nil))
+	if w.Code != http.StatusOK || len(gotRefresh) != 2 || gotRefresh[0] || !gotRefresh[1] {
+		t.Fatalf("refresh status = %d, flags = %v, want 200 and [false true]", w.Code, gotRefresh)
+	}
+
+	w = httptest.NewRecorder()
+	h.opsCertificates(w, httptest.NewRequest(http.MethodGet, "/api/ops/certificates?refresh=maybe", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid refresh status = %d, want 400", w.Code)
+	}
+}
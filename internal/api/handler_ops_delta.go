@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+// opsMetricsSummary is the part of the host metrics the ops delta reports.
+// Values are rounded to one decimal so sampling noise is not a change.
+type opsMetricsSummary struct {
+	CPUPercent  float64 `json:"cpuPercent"`
+	MemPercent  float64 `json:"memPercent"`
+	SwapPercent float64 `json:"swapPercent"`
+	DiskPercent float64 `json:"diskPercent"`
+	LoadAvg1    float64 `json:"loadAvg1"`
+}
+
+func summarizeMetrics(m opsplane.HostMetrics) opsMetricsSummary {
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+	return opsMetricsSummary{
+		CPUPercent:  round(m.CPUPercent),
+		MemPercent:  round(m.MemPercent),
+		SwapPercent: round(m.SwapPercent),
+		DiskPercent: round(m.DiskPercent),
+		LoadAvg1:    round(m.LoadAvg1),
+	}
+}
+
+type trackedService struct {
+	rev    int64
+	status opsplane.ServiceStatus
+}
+
+// opsDeltaTracker gives ops state revisions. Service states are computed
+// live rather than journaled, so each observation is compared with the
+// previous one and whatever changed is stamped with a new revision.
+// Revisions are unix milliseconds, kept strictly increasing, so they stay
+// ahead of those handed out before a restart.
+type opsDeltaTracker struct {
+	mu sync.Mutex
+	// base is the revision of the first observation. A client whose
+	// revision predates it, or is ahead of the latest one, may have missed
+	// removals and gets everything.
+	base       int64
+	rev        int64
+	services   map[string]trackedService
+	removed    map[string]int64
+	metrics    opsMetricsSummary
+	metricsRev int64
+}
+
+// opsDelta is the state that changed after a revision.
+type opsDelta struct {
+	rev      int64
+	full     bool
+	services []opsplane.ServiceStatus
+	removed  []string
+	metrics  *opsMetricsSummary
+}
+
+func (t *opsDeltaTracker) nextRev(now time.Time) int64 {
+	t.rev = max(t.rev+1, now.UnixMilli())
+	return t.rev
+}
+
+// observe records the current services and metrics and returns what
+// changed after since.
+func (t *opsDeltaTracker) observe(now time.Time, since int64, services []opsplane.ServiceStatus, metrics opsMetricsSummary) opsDelta {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var rev int64
+	stamp := func() int64 {
+		if rev == 0 {
+			rev = t.nextRev(now)
+		}
+		return rev
+	}
+	if t.services == nil {
+		t.services = make(map[string]trackedService, len(services))
+		t.removed = make(map[string]int64)
+		t.base = stamp()
+	}
+
+	seen := make(map[string]struct{}, len(services))
+	for _, svc := range services {
+		seen[svc.Name] = struct{}{}
+		prev, ok := t.services[svc.Name]
+		if ok && sameServiceState(prev.status, svc) {
+			prev.status.UpdatedAt = svc.UpdatedAt
+			prev.status.Health = svc.Health
+			t.services[svc.Name] = prev
+			continue
+		}
+		t.services[svc.Name] = trackedService{rev: stamp(), status: svc}
+		delete(t.removed, svc.Name)
+	}
+	for name := range t.services {
+		if _, ok := seen[name]; !ok {
+			delete(t.services, name)
+			t.removed[name] = stamp()
+		}
+	}
+	if t.metricsRev == 0 || metrics != t.metrics {
+		t.metrics, t.metricsRev = metrics, stamp()
+	}
+
+	delta := opsDelta{rev: t.rev, full: since < t.base || since > t.rev, services: []opsplane.ServiceStatus{}, removed: []string{}}
+	for _, tracked := range t.services {
+		if delta.full || tracked.rev > since {
+			delta.services = append(delta.services, tracked.status)
+		}
+	}
+	if !delta.full {
+		for name, removedAt := range t.removed {
+			if removedAt > since {
+				delta.removed = append(delta.removed, name)
+			}
+		}
+	}
+	if delta.full || t.metricsRev > since {
+		summary := t.metrics
+		delta.metrics = &summary
+	}
+	slices.SortFunc(delta.services, func(a, b opsplane.ServiceStatus) int { return strings.Compare(a.Name, b.Name) })
+	slices.Sort(delta.removed)
+	return delta
+}
+
+// sameServiceState compares two observations of a service, ignoring
+// UpdatedAt, which is the time of the check rather than of a change.
+// Health counts by its outcome only, since check timings differ on every
+// run.
+func sameServiceState(a, b opsplane.ServiceStatus) bool {
+	if (a.Health == nil) != (b.Health == nil) || (a.Health != nil && a.Health.Healthy != b.Health.Healthy) {
+		return false
+	}
+	return a.Name == b.Name &&
+		a.DisplayName == b.DisplayName &&
+		a.Manager == b.Manager &&
+		a.Scope == b.Scope &&
+		a.Unit == b.Unit &&
+		a.Exists == b.Exists &&
+		a.EnabledState == b.EnabledState &&
+		a.ActiveState == b.ActiveState &&
+		a.LastRunState == b.LastRunState
+}
+
+func (h *Handler) opsDelta(w http.ResponseWriter, r *http.Request) {
+	if h.ops == nil {
+		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
+		return
+	}
+	since, err := parseOpsDeltaSince(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	services, err := h.ops.ListServices(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "OPS_UNAVAILABLE", "failed to load ops services", nil)
+		return
+	}
+	delta := h.opsDeltas.observe(time.Now(), since, services, summarizeMetrics(h.ops.Metrics(ctx)))
+
+	response := map[string]any{
+		"since":      since,
+		keyGlobalRev: delta.rev,
+		"full":       delta.full,
+		keyServices:  delta.services,
+		"removed":    delta.removed,
+	}
+	if delta.metrics != nil {
+		response["metrics"] = delta.metrics
+	}
+	writeData(w, http.StatusOK, response)
+}
+
+func parseOpsDeltaSince(r *http.Request) (int64, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("since"))
+	if raw == "" {
+		return 0, nil
+	}
+	since, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || since < 0 {
+		return 0, errors.New("since must be >= 0")
+	}
+	return since, nil
+}
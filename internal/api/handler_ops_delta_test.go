@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+func TestOpsDeltaTrackerReportsChangesSinceRevision(t *testing.T) {
+	t.Parallel()
+
+	var tracker opsDeltaTracker
+	now := time.UnixMilli(1_000_000)
+	web := opsplane.ServiceStatus{Name: "web", ActiveState: "active", UpdatedAt: "t1"}
+	db := opsplane.ServiceStatus{Name: "db", ActiveState: "active", UpdatedAt: "t1"}
+	metrics := opsMetricsSummary{CPUPercent: 12.5}
+
+	first := tracker.observe(now, 0, []opsplane.ServiceStatus{web, db}, metrics)
+	if !first.full || len(first.services) != 2 || first.metrics == nil {
+		t.Fatalf("first delta = %+v, want the full state", first)
+	}
+
+	// Only UpdatedAt and health check timings moved: nothing changed.
+	web.UpdatedAt, db.UpdatedAt = "t2", "t2"
+	idle := tracker.observe(now, first.rev, []opsplane.ServiceStatus{web, db}, metrics)
+	if idle.full || len(idle.services) != 0 {
+		t.Fatalf("idle delta = %+v, want no changes", idle)
+	}
+	db.Health = &opsplane.ServiceHealth{Healthy: true, CheckedAt: "t2"}
+	health := tracker.observe(now, first.rev, []opsplane.ServiceStatus{web, db}, metrics)
+	if len(health.services) != 1 {
+		t.Fatalf("first health delta = %+v, want db", health)
+	}
+	db.Health = &opsplane.ServiceHealth{Healthy: true, CheckedAt: "t3"}
+	idle = tracker.observe(now, health.rev, []opsplane.ServiceStatus{web, db}, metrics)
+	if idle.full || len(idle.services) != 0 || len(idle.removed) != 0 || idle.metrics != nil || idle.rev != health.rev {
+		t.Fatalf("idle delta = %+v, want no changes", idle)
+	}
+
+	web.ActiveState = "failed"
+	changed := tracker.observe(now, first.rev, []opsplane.ServiceStatus{web}, opsMetricsSummary{CPUPercent: 80})
+	if changed.full || changed.rev <= first.rev {
+		t.Fatalf("changed delta = %+v", changed)
+	}
+	if len(changed.services) != 1 || changed.services[0].ActiveState != "failed" {
+		t.Fatalf("changed services = %+v", changed.services)
+	}
+	if len(changed.removed) != 1 || changed.removed[0] != "db" {
+		t.Fatalf("removed = %v, want [db]", changed.removed)
+	}
+	if changed.metrics == nil || changed.metrics.CPUPercent != 80 {
+		t.Fatalf("metrics = %+v", changed.metrics)
+	}
+
+	// A revision from before the first observation, or from the future,
+	// gets the full state again.
+	for _, since := range []int64{first.rev - 1, changed.rev + 1} {
+		if stale := tracker.observe(now, since, []opsplane.ServiceStatus{web}, opsMetricsSummary{CPUPercent: 80}); !stale.full || len(stale.services) != 1 {
+			t.Fatalf("since %d: delta = %+v, want the full state", since, stale)
+		}
+	}
+}
+
+func TestOpsDeltaHandler(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	state := "active"
+	h.ops = &mockOpsControlPlane{
+		listServicesFn: func(context.Context) ([]opsplane.ServiceStatus, error) {
+			return []opsplane.ServiceStatus{{Name: "web", ActiveState: state}}, nil
+		},
+		metricsFn: func(context.Context) opsplane.HostMetrics {
+			return opsplane.HostMetrics{CPUPercent: 10.04, MemPercent: 50}
+		},
+	}
+
+	get := func(query string) map[string]any {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.opsDelta(w, httptest.NewRequest(http.MethodGet, "/api/ops/delta"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d, body %s", query, w.Code, w.Body.String())
+		}
+		data, _ := jsonBody(t, w)["data"].(map[string]any)
+		return data
+	}
+
+	first := get("")
+	if first["full"] != true || len(first["services"].([]any)) != 1 {
+		t.Fatalf("first = %v", first)
+	}
+	if metrics, _ := first["metrics"].(map[string]any); metrics["cpuPercent"] != 10.0 {
+		t.Fatalf("metrics = %v", first["metrics"])
+	}
+	rev := strconv.FormatInt(int64(first[keyGlobalRev].(float64)), 10)
+
+	if idle := get("?since=" + rev); len(idle["services"].([]any)) != 0 || idle["metrics"] != nil {
+		t.Fatalf("idle = %v", idle)
+	}
+	state = "failed"
+	changed := get("?since=" + rev)
+	services := changed["services"].([]any)
+	if len(services) != 1 || services[0].(map[string]any)["activeState"] != "failed" {
+		t.Fatalf("changed = %v", changed)
+	}
+
+	w := httptest.NewRecorder()
+	h.opsDelta(w, httptest.NewRequest(http.MethodGet, "/api/ops/delta?since=-1", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("negative since: status = %d, want 400", w.Code)
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -83,7 +84,7 @@ func TestOpsServiceLogsErrorPaths(t *testing.T) {
 		t.Parallel()
 		h, _ := newTestHandler(t, nil)
 		h.ops = &mockOpsControlPlane{
-			logsFn: func(context.Context, string, int) (string, error) {
+			logsFn: func(context.Context, string, opsplane.LogQuery) (string, error) {
 				return "", opsplane.ErrServiceNotFound
 			},
 		}
@@ -100,7 +101,7 @@ func TestOpsServiceLogsErrorPaths(t *testing.T) {
 		t.Parallel()
 		h, _ := newTestHandler(t, nil)
 		h.ops = &mockOpsControlPlane{
-			logsFn: func(context.Context, string, int) (string, error) {
+			logsFn: func(context.Context, string, opsplane.LogQuery) (string, error) {
 				return "", errors.New("boom")
 			},
 		}
@@ -173,6 +174,35 @@ func TestOpsServiceActionErrorPaths(t *testing.T) {
 			t.Fatalf("status = %d, want 500", w.Code)
 		}
 	})
+
+	t.Run("returns 409 when the pre-stop hook fails", func(t *testing.T) {
+		t.Parallel()
+		h, _ := newTestHandler(t, nil)
+		h.ops = &mockOpsControlPlane{
+			actFn: func(context.Context, string, string) (opsplane.ServiceStatus, error) {
+				return opsplane.ServiceStatus{
+					Name:  "api",
+					Hooks: []opsplane.HookResult{{Hook: opsplane.HookPreStop, Command: "drain", Detail: "exit 1"}},
+				}, fmt.Errorf("%w: preStop: exit 1", opsplane.ErrHookFailed)
+			},
+		}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/ops/services/api/action", strings.NewReader(`{"action":"restart"}`))
+		r.SetPathValue("service", "api")
+		h.opsServiceAction(w, r)
+		if w.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want 409", w.Code)
+		}
+		body := jsonBody(t, w)
+		if got := errCode(body); got != "OPS_HOOK_FAILED" {
+			t.Fatalf("error code = %q, want OPS_HOOK_FAILED", got)
+		}
+		errObj, _ := body["error"].(map[string]any)
+		details, _ := errObj["details"].(map[string]any)
+		if hooks, _ := details["hooks"].([]any); len(hooks) != 1 {
+			t.Fatalf("details = %v, want the failed hook", details)
+		}
+	})
 }
 
 func TestRegisterOpsServiceErrorPaths(t *testing.T) {
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+type heartbeatRequest struct {
+	Name            string `json:"name"`
+	IntervalSeconds int    `json:"intervalSeconds"`
+	GraceSeconds    int    `json:"graceSeconds"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+func (h *Handler) listHeartbeats(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	heartbeats, err := h.repo.ListOpsHeartbeats(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to list heartbeats", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"heartbeats": heartbeats})
+}
+
+func (h *Handler) createHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	write, ok := decodeHeartbeatRequest(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	heartbeat, err := h.repo.CreateOpsHeartbeat(ctx, write)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			writeError(w, http.StatusConflict, "HEARTBEAT_EXISTS", "heartbeat already exists", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to create heartbeat", nil)
+		return
+	}
+	writeData(w, http.StatusCreated, map[string]any{"heartbeat": heartbeat})
+}
+
+func (h *Handler) updateHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	write, ok := decodeHeartbeatRequest(w, r)
+	if !ok {
+		return
+	}
+	write.ID = strings.TrimSpace(r.PathValue("heartbeat"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	heartbeat, err := h.repo.UpdateOpsHeartbeat(ctx, write)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "HEARTBEAT_NOT_FOUND", "heartbeat not found", nil)
+		case isUniqueConstraintError(err):
+			writeError(w, http.StatusConflict, "HEARTBEAT_EXISTS", "heartbeat already exists", nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to update heartbeat", nil)
+		}
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"heartbeat": heartbeat})
+}
+
+func (h *Handler) deleteHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	id := strings.TrimSpace(r.PathValue("heartbeat"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.repo.DeleteOpsHeartbeat(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "HEARTBEAT_NOT_FOUND", "heartbeat not found", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to delete heartbeat", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{keyRemoved: id})
+}
+
+// pingHeartbeat records a check-in from an external job. The random
+// heartbeat id is the only credential, so unknown ids get a plain 404.
+func (h *Handler) pingHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	heartbeat, previous, err := h.repo.PingOpsHeartbeat(ctx, r.PathValue("heartbeat"), time.Now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "HEARTBEAT_NOT_FOUND", "heartbeat not found", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to record heartbeat", nil)
+		return
+	}
+	if previous != heartbeat.Status {
+		if previous == store.HeartbeatStatusMissed {
+			slog.Info("heartbeat recovered", "heartbeat", heartbeat.Name)
+		}
+		h.emit(events.TypeOpsHeartbeats, map[string]any{
+			keyGlobalRev: time.Now().UTC().UnixMilli(),
+			keyAction:    store.HeartbeatStatusUp,
+			"previous":   previous,
+			"heartbeat":  heartbeat,
+		})
+	}
+	writeData(w, http.StatusOK, map[string]any{"heartbeat": heartbeat})
+}
+
+func decodeHeartbeatRequest(w http.ResponseWriter, r *http.Request) (store.OpsHeartbeatWrite, bool) {
+	var req heartbeatRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return store.OpsHeartbeatWrite{}, false
+	}
+	write := store.OpsHeartbeatWrite{
+		Name:            strings.TrimSpace(req.Name),
+		IntervalSeconds: req.IntervalSeconds,
+		GraceSeconds:    req.GraceSeconds,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+	}
+	if err := opsplane.ValidateHeartbeat(write); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return store.OpsHeartbeatWrite{}, false
+	}
+	return write, true
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHeartbeats(t *testing.T) {
+	t.Parallel()
+
+	mux, _ := newRoleTestMux(t)
+
+	w := serveWithBearer(mux, http.MethodPost, "/api/ops/heartbeats", "secret",
+		`{"name":"backup","intervalSeconds":10}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("create with a short interval: status = %d, want 400; body=%s", w.Code, w.Body.String())
+	}
+
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/heartbeats", "secret",
+		`{"name":"backup","intervalSeconds":86400,"graceSeconds":600}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want 201; body=%s", w.Code, w.Body.String())
+	}
+	heartbeat, _ := jsonBody(t, w)["data"].(map[string]any)["heartbeat"].(map[string]any)
+	id, _ := heartbeat["id"].(string)
+	if len(id) != 32 || heartbeat["enabled"] != true || heartbeat["status"] != "new" {
+		t.Fatalf("created heartbeat = %v, want an enabled new heartbeat with a random id", heartbeat)
+	}
+
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/heartbeats", "secret",
+		`{"name":"backup","intervalSeconds":3600}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("duplicate create: status = %d, want 409", w.Code)
+	}
+
+	// Pings need no token: the id is the credential.
+	w = serveWithBearer(mux, http.MethodPost, "/api/hooks/heartbeat/"+id, "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("ping: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	heartbeat, _ = jsonBody(t, w)["data"].(map[string]any)["heartbeat"].(map[string]any)
+	if heartbeat["status"] != "up" {
+		t.Fatalf("pinged heartbeat = %v, want up", heartbeat)
+	}
+	w = serveWithBearer(mux, http.MethodPost, "/api/hooks/heartbeat/missing", "", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("ping unknown: status = %d, want 404", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodPut, "/api/ops/heartbeats/"+id, "secret",
+		`{"name":"nightly backup","intervalSeconds":3600,"enabled":false}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	heartbeat, _ = jsonBody(t, w)["data"].(map[string]any)["heartbeat"].(map[string]any)
+	if heartbeat["name"] != "nightly backup" || heartbeat["enabled"] != false || heartbeat["status"] != "up" {
+		t.Fatalf("updated heartbeat = %v", heartbeat)
+	}
+	w = serveWithBearer(mux, http.MethodPut, "/api/ops/heartbeats/missing", "secret",
+		`{"name":"other","intervalSeconds":3600}`)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("update missing: status = %d, want 404", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodGet, "/api/ops/heartbeats", "secret", "")
+	heartbeats, _ := jsonBody(t, w)["data"].(map[string]any)["heartbeats"].([]any)
+	if w.Code != http.StatusOK || len(heartbeats) != 1 {
+		t.Fatalf("list: status = %d, heartbeats = %v", w.Code, heartbeats)
+	}
+
+	w = serveWithBearer(mux, http.MethodDelete, "/api/ops/heartbeats/"+id, "secret", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	w = serveWithBearer(mux, http.MethodPost, "/api/hooks/heartbeat/"+id, "", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("ping deleted: status = %d, want 404", w.Code)
+	}
+}
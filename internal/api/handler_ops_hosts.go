@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/inventory"
+	"github.com/opus-domini/sentinel/internal/runbook"
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+func (h *Handler) inventory() *inventory.Inventory {
+	var repo inventory.Repo
+	if h.repo != nil {
+		repo = h.repo
+	}
+	var relay inventory.Relay
+	if h.hosts != nil {
+		relay = h.hosts
+	}
+	return inventory.New(repo, relay)
+}
+
+// hostTargets returns what service steps run through, or nil when
+// federation is disabled so those steps fail with a clear error.
+func (h *Handler) hostTargets() runbook.HostTargets {
+	if h.hosts == nil {
+		return nil
+	}
+	return h.inventory()
+}
+
+// validateHostSelector accepts an empty selector, which leaves service steps
+// on their own.
+func validateHostSelector(raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	_, err := inventory.ParseSelector(raw)
+	return err
+}
+
+// listOpsHosts returns the host inventory with health, optionally narrowed
+// by a label selector.
+func (h *Handler) listOpsHosts(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	var sel *inventory.Selector
+	if raw := strings.TrimSpace(r.URL.Query().Get("selector")); raw != "" {
+		parsed, err := inventory.ParseSelector(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+			return
+		}
+		sel = &parsed
+	}
+
+	inv := h.inventory()
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	hosts, err := inv.Hosts(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to load hosts", nil)
+		return
+	}
+	if sel != nil {
+		matched := hosts[:0]
+		for _, host := range hosts {
+			if sel.Matches(host.Labels) {
+				matched = append(matched, host)
+			}
+		}
+		hosts = matched
+	}
+	id, _ := security.IdentityFromContext(r.Context())
+	hosts = inv.WithHealth(ctx, id, hosts)
+
+	writeData(w, http.StatusOK, map[string]any{
+		"hosts":   hosts,
+		"summary": inventory.Rollup(hosts),
+	})
+}
+
+// setOpsHostLabels replaces the labels of a host. The host need not be
+// connected, so labels can be prepared before an agent first joins.
+func (h *Handler) setOpsHostLabels(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	var req struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !validate.HostName(req.Name) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "name must be a valid host name", nil)
+		return
+	}
+	if err := inventory.ValidateLabels(req.Labels); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	host, err := h.repo.SetOpsHostLabels(ctx, req.Name, req.Labels)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to save host labels", nil)
+		return
+	}
+	h.emit(events.TypeOpsHosts, map[string]any{
+		keyAction: "labeled",
+		"host":    host.Name,
+	})
+	writeData(w, http.StatusOK, map[string]any{"host": host})
+}
+
+// deleteOpsHost forgets the labels of a host. A connected host stays in
+// the inventory without labels.
+func (h *Handler) deleteOpsHost(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	name := strings.TrimSpace(r.PathValue("host"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	if err := h.repo.DeleteOpsHost(ctx, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "HOST_NOT_FOUND", "host has no labels", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to delete host labels", nil)
+		return
+	}
+	h.emit(events.TypeOpsHosts, map[string]any{
+		keyAction: keyDeleted,
+		"host":    name,
+	})
+	writeData(w, http.StatusOK, map[string]any{keyRemoved: name})
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/federation"
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func TestOpsHostsInventory(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.SetFederation(&fakeHostRelay{
+		hosts: []federation.Host{{Name: "web-01", Transport: federation.TransportAgent, ConnectedAt: time.Now()}},
+		forwardFn: func(_ context.Context, host string, req federation.Request) (federation.Response, error) {
+			if host != "web-01" || req.Path != "/api/ops/overview" || req.Identity.Name != "ci" {
+				t.Errorf("forward %s %s as %+v", host, req.Path, req.Identity)
+			}
+			return federation.Response{Status: http.StatusOK, Body: []byte(`{"data":{"overview":{"services":{"total":2,"active":1,"failed":1}}}}`)}, nil
+		},
+	})
+
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{`{"name":"web-01","labels":{"env":"prod","role":"web"}}`, http.StatusOK},
+		{`{"name":"db-01","labels":{"env":"prod","role":"db"}}`, http.StatusOK},
+		{`{"name":"db 01","labels":{}}`, http.StatusBadRequest},
+		{`{"name":"db-02","labels":{"role":"db,web"}}`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		h.setOpsHostLabels(w, httptest.NewRequest(http.MethodPost, "/api/ops/hosts", strings.NewReader(tc.body)))
+		if w.Code != tc.want {
+			t.Fatalf("POST %s = %d %s, want %d", tc.body, w.Code, w.Body.String(), tc.want)
+		}
+	}
+
+	list := func(query string) map[string]any {
+		r := httptest.NewRequest(http.MethodGet, "/api/ops/hosts"+query, nil)
+		r = r.WithContext(security.WithIdentity(r.Context(), security.Identity{Name: "ci", Role: security.RoleViewer}))
+		w := httptest.NewRecorder()
+		h.listOpsHosts(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d %s", query, w.Code, w.Body.String())
+		}
+		return jsonBody(t, w)["data"].(map[string]any)
+	}
+
+	data := list("")
+	hosts := data["hosts"].([]any)
+	if len(hosts) != 2 {
+		t.Fatalf("hosts = %v", hosts)
+	}
+	db, web := hosts[0].(map[string]any), hosts[1].(map[string]any)
+	if db["name"] != "db-01" || db["status"] != "offline" || db["health"] != "offline" {
+		t.Fatalf("db-01 = %v", db)
+	}
+	if web["status"] != "online" || web["health"] != "degraded" || web["labels"].(map[string]any)["role"] != "web" {
+		t.Fatalf("web-01 = %v", web)
+	}
+	summary := data["summary"].(map[string]any)
+	if summary["total"] != float64(2) || summary["online"] != float64(1) || summary["degraded"] != float64(1) {
+		t.Fatalf("summary = %v", summary)
+	}
+
+	if hosts := list("?selector=role%3Ddb")["hosts"].([]any); len(hosts) != 1 || hosts[0].(map[string]any)["name"] != "db-01" {
+		t.Fatalf("selected hosts = %v", hosts)
+	}
+	w := httptest.NewRecorder()
+	h.listOpsHosts(w, httptest.NewRequest(http.MethodGet, "/api/ops/hosts?selector=%3Dweb", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid selector = %d, want 400", w.Code)
+	}
+
+	for _, want := range []int{http.StatusOK, http.StatusNotFound} {
+		r := httptest.NewRequest(http.MethodDelete, "/api/ops/hosts/db-01", nil)
+		r.SetPathValue("host", "db-01")
+		w := httptest.NewRecorder()
+		h.deleteOpsHost(w, r)
+		if w.Code != want {
+			t.Fatalf("DELETE db-01 = %d, want %d", w.Code, want)
+		}
+	}
+}
+
+func TestRunOpsRunbookTargetsHosts(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	ctx := context.Background()
+	for name, role := range map[string]string{"web-01": "web", "web-02": "web", "db-01": "db"} {
+		if _, err := st.SetOpsHostLabels(ctx, name, map[string]string{"role": role}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rb, err := st.InsertOpsRunbook(ctx, store.OpsRunbookWrite{
+		Name:    "restart nginx",
+		Steps:   []store.OpsRunbookStep{{Type: "service", Title: "restart", Hosts: "role=db", Unit: "nginx.service", Action: "restart"}},
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu      sync.Mutex
+		targets []string
+	)
+	h.SetFederation(&fakeHostRelay{
+		hosts: []federation.Host{{Name: "web-01"}, {Name: "web-02"}, {Name: "db-01"}},
+		forwardFn: func(_ context.Context, host string, req federation.Request) (federation.Response, error) {
+			if req.Path != "/api/ops/services/unit/action" || !strings.Contains(string(req.Body), `"unit":"nginx.service"`) {
+				t.Errorf("forward %s %s %s", host, req.Path, req.Body)
+			}
+			mu.Lock()
+			targets = append(targets, host)
+			mu.Unlock()
+			return federation.Response{Status: http.StatusOK, Body: []byte(`{"data":{}}`)}, nil
+		},
+	})
+
+	run := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/ops/runbooks/"+rb.ID+"/run", strings.NewReader(body))
+		r.SetPathValue(keyRunbook, rb.ID)
+		w := httptest.NewRecorder()
+		h.runOpsRunbook(w, r)
+		return w
+	}
+	if w := run(`{"hosts":"role=web app"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid hosts = %d %s, want 400", w.Code, w.Body.String())
+	}
+	w := run(`{"hosts":"role=web"}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("run = %d %s", w.Code, w.Body.String())
+	}
+	h.runbooks.WaitIdle()
+
+	jobID := jsonBody(t, w)["data"].(map[string]any)["job"].(map[string]any)["id"].(string)
+	job, err := st.GetOpsRunbookRun(ctx, jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != stateSucceeded || job.Hosts != "role=web" {
+		t.Fatalf("job = %s hosts %q, error %q", job.Status, job.Hosts, job.Error)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(targets) != 2 || targets[0] != "web-01" || targets[1] != "web-02" {
+		t.Fatalf("service action ran on %v, want the role=web hosts", targets)
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/library"
+)
+
+// SetLibrary enables the library endpoints backed by lib.
+func (h *Handler) SetLibrary(lib *library.Service) {
+	if h == nil {
+		return
+	}
+	h.library = lib
+}
+
+func (h *Handler) libraryEnabled(w http.ResponseWriter) bool {
+	if h.library == nil {
+		writeError(w, http.StatusServiceUnavailable, "LIBRARY_DISABLED", library.ErrDisabled.Error(), nil)
+		return false
+	}
+	return true
+}
+
+// libraryStatus returns the library configuration and its last sync.
+func (h *Handler) libraryStatus(w http.ResponseWriter, _ *http.Request) {
+	if !h.libraryEnabled(w) {
+		return
+	}
+	status, err := h.library.Status()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "LIBRARY_ERROR", err.Error(), nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"library": status})
+}
+
+// syncLibrary pulls and applies the library now instead of waiting for the
+// next sync.
+func (h *Handler) syncLibrary(w http.ResponseWriter, r *http.Request) {
+	if !h.libraryEnabled(w) {
+		return
+	}
+	result, err := h.library.Sync(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "LIBRARY_SYNC_FAILED", err.Error(), nil)
+		return
+	}
+	h.emit(events.TypeOpsLibrary, map[string]any{
+		keyGlobalRev: time.Now().UTC().UnixMilli(),
+		"library":    result,
+	})
+	writeData(w, http.StatusOK, map[string]any{"library": result})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/library"
+	"github.com/opus-domini/sentinel/internal/security"
+)
+
+func TestLibraryManagesRunbooks(t *testing.T) {
+	t.Parallel()
+
+	disabled, _ := newRoleTestMux(t)
+	if w := serveWithBearer(disabled, http.MethodGet, "/api/ops/library", "secret", ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status without a library: status = %d, want 503", w.Code)
+	}
+
+	mux := http.NewServeMux()
+	st := newTestStore(t)
+	h := Register(mux, security.New("secret", nil, security.CookieSecureAuto), st, &mockOpsControlPlane{}, events.NewHub(), "test", "", "", "", nil, nil)
+	t.Cleanup(func() { h.Shutdown(context.Background()) })
+	dir := t.TempDir()
+	content := "name: Deploy\nsteps: [{type: run, title: Pull, command: git pull}]\nschedules: [{name: nightly, cronExpr: '0 2 * * *'}]\n"
+	if err := os.WriteFile(filepath.Join(dir, "deploy.yaml"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	h.SetLibrary(library.New(st, library.Options{Dir: dir}))
+
+	w := serveWithBearer(mux, http.MethodPost, "/api/ops/library/sync", "secret", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"created":2`) {
+		t.Fatalf("sync: status = %d, want 200 with 2 created; body=%s", w.Code, w.Body.String())
+	}
+	w = serveWithBearer(mux, http.MethodGet, "/api/ops/library", "secret", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"created":2`) {
+		t.Fatalf("status: status = %d; body=%s", w.Code, w.Body.String())
+	}
+
+	id := library.RunbookID("deploy.yaml")
+	w = serveWithBearer(mux, http.MethodPut, "/api/ops/runbooks/"+id, "secret", `{"name":"Deploy","steps":[{"type":"run","title":"Pull","command":"true"}]}`)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "RUNBOOK_MANAGED") {
+		t.Fatalf("update managed runbook: status = %d, want 409; body=%s", w.Code, w.Body.String())
+	}
+	w = serveWithBearer(mux, http.MethodDelete, "/api/ops/runbooks/"+id, "secret", "")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("delete managed runbook: status = %d, want 409", w.Code)
+	}
+	schedules, err := st.ListOpsSchedules(context.Background())
+	if err != nil || len(schedules) != 1 {
+		t.Fatalf("schedules = %+v, %v", schedules, err)
+	}
+	w = serveWithBearer(mux, http.MethodDelete, "/api/ops/schedules/"+schedules[0].ID, "secret", "")
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "SCHEDULE_MANAGED") {
+		t.Fatalf("delete managed schedule: status = %d, want 409; body=%s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+// defaultLoginsRange is how far back GET /api/ops/logins reads SSH
+// authentication attempts without a since parameter.
+const defaultLoginsRange = 24 * time.Hour
+
+func (h *Handler) opsLogins(w http.ResponseWriter, r *http.Request) {
+	if h.ops == nil {
+		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
+		return
+	}
+	since, err := parseMetricsHistoryTime(r.URL.Query().Get("since"), time.Now().Add(-defaultLoginsRange))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "since must be RFC3339 or unix seconds", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	logins, err := h.ops.Logins(ctx, since)
+	if err != nil {
+		if errors.Is(err, opsplane.ErrLoginsUnsupported) {
+			writeError(w, http.StatusNotImplemented, "LOGINS_UNSUPPORTED", err.Error(), nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "OPS_UNAVAILABLE", "failed to read logins", nil)
+		return
+	}
+	writeData(w, http.StatusOK, logins)
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+func TestOpsLogins(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	var gotSince time.Time
+	h.ops = &mockOpsControlPlane{
+		loginsFn: func(_ context.Context, since time.Time) (opsplane.Logins, error) {
+			gotSince = since
+			return opsplane.Logins{
+				Sessions: []opsplane.LoginSession{{User: "alice", TTY: "pts/0", Host: "10.0.0.5"}},
+				Events:   []opsplane.AuthEvent{{User: "root", Host: "203.0.113.9", Result: opsplane.AuthResultFailed}},
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.opsLogins(w, httptest.NewRequest(http.MethodGet, "/api/ops/logins?since=1771113600", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	if !gotSince.Equal(time.Unix(1771113600, 0)) {
+		t.Fatalf("since = %v, want 1771113600", gotSince)
+	}
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	sessions, _ := data["sessions"].([]any)
+	loginEvents, _ := data["events"].([]any)
+	if len(sessions) != 1 || len(loginEvents) != 1 {
+		t.Fatalf("data = %v, want one session and one event", data)
+	}
+
+	w = httptest.NewRecorder()
+	h.opsLogins(w, httptest.NewRequest(http.MethodGet, "/api/ops/logins?since=yesterday", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid since status = %d, want 400", w.Code)
+	}
+}
+
+func TestOpsLoginsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.ops = &mockOpsControlPlane{
+		loginsFn: func(context.Context, time.Time) (opsplane.Logins, error) {
+			return opsplane.Logins{}, opsplane.ErrLoginsUnsupported
+		},
+	}
+	w := httptest.NewRecorder()
+	h.opsLogins(w, httptest.NewRequest(http.MethodGet, "/api/ops/logins", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("status = %d, want 501", w.Code)
+	}
+}
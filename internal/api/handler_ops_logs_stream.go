@@ -0,0 +1,141 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+// opsLogStreamHeartbeat keeps idle service log streams alive through proxies.
+const opsLogStreamHeartbeat = 15 * time.Second
+
+type logStreamOpener func(ctx context.Context) (io.ReadCloser, error)
+
+// streamOpsServiceLogs tails a tracked service's logs as server-sent events.
+func (h *Handler) streamOpsServiceLogs(w http.ResponseWriter, r *http.Request) {
+	if h.ops == nil {
+		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
+		return
+	}
+	serviceName := strings.TrimSpace(r.PathValue(keyService))
+	if serviceName == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "service name is required", nil)
+		return
+	}
+	priority := strings.TrimSpace(r.URL.Query().Get("priority"))
+	h.streamOpsLogs(w, r, func(ctx context.Context) (io.ReadCloser, error) {
+		return h.ops.StreamLogs(ctx, serviceName, priority)
+	})
+}
+
+// streamOpsUnitLogs tails an untracked unit's logs as server-sent events.
+func (h *Handler) streamOpsUnitLogs(w http.ResponseWriter, r *http.Request) {
+	if h.ops == nil {
+		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
+		return
+	}
+	unit := strings.TrimSpace(r.URL.Query().Get("unit"))
+	scope := strings.TrimSpace(r.URL.Query().Get(keyScope))
+	manager := strings.TrimSpace(r.URL.Query().Get("manager"))
+	priority := strings.TrimSpace(r.URL.Query().Get("priority"))
+
+	if unit == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "unit is required", nil)
+		return
+	}
+	if !slices.Contains(validManagers, manager) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "manager must be systemd, launchd, docker, windows, or rcd", nil)
+		return
+	}
+	if !slices.Contains(validScopes, scope) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "scope must be user or system", nil)
+		return
+	}
+	h.streamOpsLogs(w, r, func(ctx context.Context) (io.ReadCloser, error) {
+		return h.ops.StreamLogsByUnit(ctx, unit, scope, manager, priority)
+	})
+}
+
+// streamOpsLogs sends each log line as a "log" event and a final "done"
+// event when the log source exits. The source is stopped when the client
+// disconnects.
+func (h *Handler) streamOpsLogs(w http.ResponseWriter, r *http.Request, open logStreamOpener) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	stream, err := open(ctx)
+	if err != nil {
+		writeLogStreamError(w, err)
+		return
+	}
+	defer func() { _ = stream.Close() }()
+
+	rc := http.NewResponseController(w)
+	// The stream lives until the client leaves, past the server write timeout.
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	lines := make(chan string, 64)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 64*1024), 64*1024)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(opsLogStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case line, ok := <-lines:
+			if !ok {
+				_ = writeSSE(w, rc, "done", map[string]any{})
+				return
+			}
+			if err := writeSSE(w, rc, "log", map[string]any{"line": line}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeLogStreamError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, opsplane.ErrServiceNotFound):
+		writeError(w, http.StatusNotFound, "OPS_SERVICE_NOT_FOUND", "service not found", nil)
+	case errors.Is(err, opsplane.ErrInvalidUnit),
+		errors.Is(err, opsplane.ErrInvalidLogPriority),
+		errors.Is(err, opsplane.ErrPriorityUnsupported):
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+	case errors.Is(err, opsplane.ErrStreamingUnsupported):
+		writeError(w, http.StatusNotImplemented, "STREAMING_UNSUPPORTED", err.Error(), nil)
+	default:
+		slog.Warn("ops log stream failed", "err", err)
+		writeError(w, http.StatusInternalServerError, "OPS_LOGS_FAILED", "failed to start log stream", nil)
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+func TestStreamOpsServiceLogs(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	var gotName, gotPriority string
+	h.ops = &mockOpsControlPlane{
+		streamLogsFn: func(_ context.Context, name, priority string) (io.ReadCloser, error) {
+			gotName, gotPriority = name, priority
+			return io.NopCloser(strings.NewReader("first line\nsecond line\n")), nil
+		},
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/ops/services/nginx/logs/stream?priority=warning", nil)
+	r.SetPathValue(keyService, "nginx")
+	w := httptest.NewRecorder()
+	h.streamOpsServiceLogs(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	if gotName != "nginx" || gotPriority != "warning" {
+		t.Fatalf("StreamLogs(%q, %q), want nginx, warning", gotName, gotPriority)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	want := "event: log\ndata: {\"line\":\"first line\"}\n\n" +
+		"event: log\ndata: {\"line\":\"second line\"}\n\n" +
+		"event: done\ndata: {}\n\n"
+	if w.Body.String() != want {
+		t.Fatalf("body = %q, want %q", w.Body.String(), want)
+	}
+}
+
+func TestStreamOpsUnitLogsErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		query    string
+		err      error
+		wantCode int
+	}{
+		{name: "missing unit", query: "manager=systemd&scope=system", wantCode: http.StatusBadRequest},
+		{name: "bad manager", query: "unit=web&manager=runit&scope=system", wantCode: http.StatusBadRequest},
+		{name: "docker priority", query: "unit=web&manager=docker&scope=system&priority=err", err: opsplane.ErrPriorityUnsupported, wantCode: http.StatusBadRequest},
+		{name: "launchd", query: "unit=com.example&manager=launchd&scope=user", err: opsplane.ErrStreamingUnsupported, wantCode: http.StatusNotImplemented},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h, _ := newTestHandler(t, nil)
+			h.ops = &mockOpsControlPlane{
+				streamUnitFn: func(context.Context, string, string, string, string) (io.ReadCloser, error) {
+					return nil, tt.err
+				},
+			}
+			w := httptest.NewRecorder()
+			h.streamOpsUnitLogs(w, httptest.NewRequest(http.MethodGet, "/api/ops/services/unit/logs/stream?"+tt.query, nil))
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body = %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
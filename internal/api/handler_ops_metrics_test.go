@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func TestOpsMetricsHistory(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	ctx := context.Background()
+	base := time.Date(2026, 6, 2, 12, 0, 0, 0, time.UTC)
+	for i, cpu := range []float64{10, 20, 60} {
+		if err := st.RecordMetricsSample(ctx, base.Add(time.Duration(i)*30*time.Second), store.MetricsSample{CPUPercent: cpu}); err != nil {
+			t.Fatalf("RecordMetricsSample: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantCode   int
+		wantPoints int
+		wantStep   float64
+	}{
+		{name: "minute buckets", query: "?from=2026-06-02T12:00:00Z&to=2026-06-02T12:05:00Z&step=1m", wantCode: http.StatusOK, wantPoints: 2, wantStep: 60},
+		{name: "unix seconds and numeric step", query: "?from=1780401600&to=1780401900&step=30", wantCode: http.StatusOK, wantPoints: 3, wantStep: 30},
+		{name: "default step", query: "?from=2026-06-02T12:00:00Z&to=2026-06-02T12:05:00Z", wantCode: http.StatusOK, wantPoints: 3, wantStep: 1},
+		{name: "bad from", query: "?from=yesterday", wantCode: http.StatusBadRequest},
+		{name: "bad step", query: "?step=-5m", wantCode: http.StatusBadRequest},
+		{name: "inverted range", query: "?from=2026-06-02T13:00:00Z&to=2026-06-02T12:00:00Z", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			h.opsMetricsHistory(w, httptest.NewRequest(http.MethodGet, "/api/ops/metrics/history"+tt.query, nil))
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			data := jsonBody(t, w)["data"].(map[string]any)
+			points := data["points"].([]any)
+			if len(points) != tt.wantPoints || data["stepSeconds"] != tt.wantStep {
+				t.Fatalf("points = %d, step = %v; want %d, %v", len(points), data["stepSeconds"], tt.wantPoints, tt.wantStep)
+			}
+		})
+	}
+}
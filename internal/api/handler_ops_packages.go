@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+func (h *Handler) opsPackages(w http.ResponseWriter, r *http.Request) {
+	if h.ops == nil {
+		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
+		return
+	}
+	refresh := false
+	if raw := strings.TrimSpace(r.URL.Query().Get("refresh")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "refresh must be a boolean", nil)
+			return
+		}
+		refresh = parsed
+	}
+
+	updates, err := h.ops.PackageUpdates(refresh)
+	if err != nil {
+		if errors.Is(err, opsplane.ErrPackagesUnsupported) {
+			writeError(w, http.StatusNotImplemented, "PACKAGES_UNSUPPORTED", err.Error(), nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "OPS_UNAVAILABLE", "failed to check package updates", nil)
+		return
+	}
+	writeData(w, http.StatusOK, updates)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+func TestOpsPackages(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	var gotRefresh []bool
+	h.ops = &mockOpsControlPlane{
+		packagesFn: func(refresh bool) (opsplane.PackageUpdates, error) {
+			gotRefresh = append(gotRefresh, refresh)
+			return opsplane.PackageUpdates{
+				Manager:        "apt",
+				Pending:        1,
+				Security:       1,
+				Packages:       []opsplane.PendingPackage{{Name: "openssl", Version: "3.0.2-0ubuntu1.15", Security: true}},
+				RebootRequired: true,
+				Checking:       refresh,
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.opsPackages(w, httptest.NewRequest(http.MethodGet, "/api/ops/packages", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", w.Code, w.Body.String())
+	}
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	if data["security"] != float64(1) || data["rebootRequired"] != true || data["checking"] != false {
+		t.Fatalf("data = %v, want one security update and a pending reboot", data)
+	}
+
+	w = httptest.NewRecorder()
+	h.opsPackages(w, httptest.NewRequest(http.MethodGet, "/api/ops/packages?refresh=true", nil))
+	if w.Code != http.StatusOK || len(gotRefresh) != 2 || gotRefresh[0] || !gotRefresh[1] {
+		t.Fatalf("refresh status = %d, flags = %v, want 200 and [false true]", w.Code, gotRefresh)
+	}
+
+	w = httptest.NewRecorder()
+	h.opsPackages(w, httptest.NewRequest(http.MethodGet, "/api/ops/packages?refresh=maybe", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid refresh status = %d, want 400", w.Code)
+	}
+}
+
+func TestOpsPackagesUnsupported(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.ops = &mockOpsControlPlane{}
+	w := httptest.NewRecorder()
+	h.opsPackages(w, httptest.NewRequest(http.MethodGet, "/api/ops/packages", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("status = %d, want 501", w.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+)
+
+func (h *Handler) opsPorts(w http.ResponseWriter, r *http.Request) {
+	if h.ops == nil {
+		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	ports, err := h.ops.ListeningPorts(ctx)
+	if err != nil {
+		if errors.Is(err, opsplane.ErrPortsUnsupported) {
+			writeError(w, http.StatusNotImplemented, "PORTS_UNSUPPORTED", err.Error(), nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "OPS_UNAVAILABLE", "failed to scan listening ports", nil)
+		return
+	}
+	h.attachPortPanes(ctx, ports)
+	writeData(w, http.StatusOK, map[string]any{
+		"ports": ports,
+	})
+}
+
+// attachPortPanes marks ports owned by a process running inside a tmux pane
+// of the default server. Panes are matched best-effort: tmux or process
+// listing failures leave the ports unannotated.
+func (h *Handler) attachPortPanes(ctx context.Context, ports []opsplane.ListeningPort) {
+	if h.procs == nil || len(ports) == 0 {
+		return
+	}
+	sessions, err := h.tmux.ListSessions(ctx)
+	if err != nil || len(sessions) == 0 {
+		return
+	}
+	table, err := h.procs.Table(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "process table failed", "err", err)
+		return
+	}
+	for _, sess := range sessions {
+		panes, err := h.tmux.ListPanes(ctx, sess.Name)
+		if err != nil {
+			continue
+		}
+		for _, pane := range panes {
+			if pane.PID <= 0 {
+				continue
+			}
+			for i := range ports {
+				pid := ports[i].PID
+				if ports[i].PaneID != "" || pid <= 0 {
+					continue
+				}
+				if pid == pane.PID || table.IsDescendant(pane.PID, pid) {
+					ports[i].Session = pane.Session
+					ports[i].PaneID = pane.PaneID
+				}
+			}
+		}
+	}
+}
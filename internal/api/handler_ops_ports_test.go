@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestOpsPortsAttachesPanes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	h, _ := newTestHandler(t, &mockTmux{
+		listSessionsFn: func(context.Context) ([]tmux.Session, error) {
+			return []tmux.Session{{Name: "dev", CreatedAt: now, ActivityAt: now}}, nil
+		},
+		listPanesFn: func(_ context.Context, session string) ([]tmux.Pane, error) {
+			return []tmux.Pane{{Session: session, PaneID: "%3", PID: 100}}, nil
+		},
+	})
+	h.procs = &mockProcs{parents: map[int]int{200: 100, 300: 200, 900: 1}}
+	h.ops = &mockOpsControlPlane{
+		portsFn: func(context.Context) ([]opsplane.ListeningPort, error) {
+			return []opsplane.ListeningPort{
+				{Protocol: "tcp", Address: "127.0.0.1", Port: 5173, PID: 300, Process: "node"},
+				{Protocol: "tcp", Address: "0.0.0.0", Port: 22, PID: 900, Process: "sshd", Service: "ssh", Unit: "ssh.service"},
+				{Protocol: "udp", Address: "0.0.0.0", Port: 68},
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.opsPorts(w, httptest.NewRequest(http.MethodGet, "/api/ops/ports", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			Ports []opsplane.ListeningPort `json:"ports"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	ports := resp.Data.Ports
+	if len(ports) != 3 {
+		t.Fatalf("ports = %+v, want 3", ports)
+	}
+	if ports[0].Session != "dev" || ports[0].PaneID != "%3" {
+		t.Fatalf("pane listener = %+v, want dev %%3", ports[0])
+	}
+	if ports[1].PaneID != "" || ports[1].Service != "ssh" || ports[2].PaneID != "" {
+		t.Fatalf("non-pane listeners = %+v, %+v", ports[1], ports[2])
+	}
+}
+
+func TestOpsPortsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.ops = &mockOpsControlPlane{
+		portsFn: func(context.Context) ([]opsplane.ListeningPort, error) {
+			return nil, opsplane.ErrPortsUnsupported
+		},
+	}
+	w := httptest.NewRecorder()
+	h.opsPorts(w, httptest.NewRequest(http.MethodGet, "/api/ops/ports", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("status = %d, want 501", w.Code)
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/secrets"
+)
+
+type secretRequest struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SetSecrets enables the secrets endpoints backed by vault and lets runbook
+// runs resolve secret references through it.
+func (h *Handler) SetSecrets(vault *secrets.Vault) {
+	if h == nil {
+		return
+	}
+	h.secrets = vault
+	h.runbooks.SetSecrets(vault.Lookup)
+}
+
+func (h *Handler) secretsEnabled(w http.ResponseWriter) bool {
+	if h.secrets == nil {
+		writeError(w, http.StatusServiceUnavailable, "SECRETS_UNAVAILABLE", secrets.ErrUnavailable.Error(), nil)
+		return false
+	}
+	return true
+}
+
+// listSecrets returns secret names and timestamps; values never leave the
+// vault through the API.
+func (h *Handler) listSecrets(w http.ResponseWriter, r *http.Request) {
+	if !h.secretsEnabled(w) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	items, err := h.secrets.List(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to list secrets", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"secrets": items})
+}
+
+func (h *Handler) createSecret(w http.ResponseWriter, r *http.Request) {
+	if !h.secretsEnabled(w) {
+		return
+	}
+	var req secretRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	item, err := h.secrets.Create(ctx, strings.TrimSpace(req.Name), req.Value)
+	if err != nil {
+		switch {
+		case errors.Is(err, secrets.ErrInvalidSecret):
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		case isUniqueConstraintError(err):
+			writeError(w, http.StatusConflict, "SECRET_EXISTS", "secret already exists", nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to create secret", nil)
+		}
+		return
+	}
+	writeData(w, http.StatusCreated, map[string]any{"secret": item})
+}
+
+func (h *Handler) updateSecret(w http.ResponseWriter, r *http.Request) {
+	if !h.secretsEnabled(w) {
+		return
+	}
+	var req secretRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	item, err := h.secrets.Update(ctx, strings.TrimSpace(r.PathValue("secret")), req.Value)
+	if err != nil {
+		switch {
+		case errors.Is(err, secrets.ErrInvalidSecret):
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "SECRET_NOT_FOUND", "secret not found", nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to update secret", nil)
+		}
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"secret": item})
+}
+
+func (h *Handler) deleteSecret(w http.ResponseWriter, r *http.Request) {
+	if !h.secretsEnabled(w) {
+		return
+	}
+	name := strings.TrimSpace(r.PathValue("secret"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.secrets.Delete(ctx, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "SECRET_NOT_FOUND", "secret not found", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to delete secret", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{keyRemoved: name})
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/secrets"
+	"github.com/opus-domini/sentinel/internal/security"
+)
+
+func TestSecrets(t *testing.T) {
+	t.Parallel()
+
+	unavailable, _ := newRoleTestMux(t)
+	if w := serveWithBearer(unavailable, http.MethodGet, "/api/ops/secrets", "secret", ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("list without a vault: status = %d, want 503", w.Code)
+	}
+
+	mux := http.NewServeMux()
+	st := newTestStore(t)
+	h := Register(mux, security.New("secret", nil, security.CookieSecureAuto), st, &mockOpsControlPlane{}, events.NewHub(), "test", "", "", "", nil, nil)
+	t.Cleanup(func() { h.Shutdown(context.Background()) })
+	vault, err := secrets.New(st, []byte(strings.Repeat("k", secrets.KeySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.SetSecrets(vault)
+
+	w := serveWithBearer(mux, http.MethodPost, "/api/ops/secrets", "secret", `{"name":"slack webhook","value":"x"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("create with a bad name: status = %d, want 400", w.Code)
+	}
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/secrets", "secret", `{"name":"slack_webhook","value":"https://hooks.example/abc"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want 201; body=%s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "hooks.example") {
+		t.Fatalf("create response leaks the value: %s", w.Body.String())
+	}
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/secrets", "secret", `{"name":"slack_webhook","value":"y"}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("duplicate create: status = %d, want 409", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodPut, "/api/ops/secrets/slack_webhook", "secret", `{"value":"https://hooks.example/rotated"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	if got, err := vault.Lookup(context.Background(), "slack_webhook"); err != nil || got != "https://hooks.example/rotated" {
+		t.Fatalf("Lookup() after update = %q, %v", got, err)
+	}
+	w = serveWithBearer(mux, http.MethodPut, "/api/ops/secrets/missing", "secret", `{"value":"x"}`)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("update missing: status = %d, want 404", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodGet, "/api/ops/secrets", "secret", "")
+	list, _ := jsonBody(t, w)["data"].(map[string]any)["secrets"].([]any)
+	if w.Code != http.StatusOK || len(list) != 1 || strings.Contains(w.Body.String(), "rotated") {
+		t.Fatalf("list: status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	if w := serveWithBearer(mux, http.MethodDelete, "/api/ops/secrets/slack_webhook", "secret", ""); w.Code != http.StatusOK {
+		t.Fatalf("delete: status = %d, want 200", w.Code)
+	}
+	if w := serveWithBearer(mux, http.MethodDelete, "/api/ops/secrets/slack_webhook", "secret", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("second delete: status = %d, want 404", w.Code)
+	}
+}
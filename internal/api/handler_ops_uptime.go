@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+type uptimeCheckRequest struct {
+	Name            string `json:"name"`
+	Type            string `json:"type"`
+	Target          string `json:"target"`
+	IntervalSeconds int    `json:"intervalSeconds"`
+	TimeoutSeconds  int    `json:"timeoutSeconds"`
+	ExpectStatus    int    `json:"expectStatus"`
+	ExpectBody      string `json:"expectBody"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+func (h *Handler) listUptimeChecks(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	checks, err := h.repo.ListOpsUptimeChecks(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to list uptime checks", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"checks": checks})
+}
+
+func (h *Handler) createUptimeCheck(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	write, ok := decodeUptimeCheckRequest(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	check, err := h.repo.CreateOpsUptimeCheck(ctx, write)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			writeError(w, http.StatusConflict, "UPTIME_CHECK_EXISTS", "uptime check already exists", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to create uptime check", nil)
+		return
+	}
+	writeData(w, http.StatusCreated, map[string]any{"check": check})
+}
+
+func (h *Handler) updateUptimeCheck(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	write, ok := decodeUptimeCheckRequest(w, r)
+	if !ok {
+		return
+	}
+	write.ID = strings.TrimSpace(r.PathValue("check"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	check, err := h.repo.UpdateOpsUptimeCheck(ctx, write)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "UPTIME_CHECK_NOT_FOUND", "uptime check not found", nil)
+		case isUniqueConstraintError(err):
+			writeError(w, http.StatusConflict, "UPTIME_CHECK_EXISTS", "uptime check already exists", nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to update uptime check", nil)
+		}
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"check": check})
+}
+
+func (h *Handler) deleteUptimeCheck(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	id := strings.TrimSpace(r.PathValue("check"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.repo.DeleteOpsUptimeCheck(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "UPTIME_CHECK_NOT_FOUND", "uptime check not found", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to delete uptime check", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{keyRemoved: id})
+}
+
+func decodeUptimeCheckRequest(w http.ResponseWriter, r *http.Request) (store.OpsUptimeCheckWrite, bool) {
+	var req uptimeCheckRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return store.OpsUptimeCheckWrite{}, false
+	}
+	write := store.OpsUptimeCheckWrite{
+		Name:            strings.TrimSpace(req.Name),
+		Type:            strings.ToLower(strings.TrimSpace(req.Type)),
+		Target:          strings.TrimSpace(req.Target),
+		IntervalSeconds: req.IntervalSeconds,
+		TimeoutSeconds:  req.TimeoutSeconds,
+		ExpectStatus:    req.ExpectStatus,
+		ExpectBody:      req.ExpectBody,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+	}
+	if write.IntervalSeconds == 0 {
+		write.IntervalSeconds = opsplane.DefaultUptimeInterval
+	}
+	if write.TimeoutSeconds == 0 {
+		write.TimeoutSeconds = min(opsplane.DefaultUptimeTimeout, write.IntervalSeconds)
+	}
+	if err := opsplane.ValidateUptimeCheck(write); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return store.OpsUptimeCheckWrite{}, false
+	}
+	return write, true
+}
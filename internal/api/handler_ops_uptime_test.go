@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUptimeChecks(t *testing.T) {
+	t.Parallel()
+
+	mux, _ := newRoleTestMux(t)
+
+	w := serveWithBearer(mux, http.MethodPost, "/api/ops/uptime", "secret",
+		`{"name":"router","type":"icmp","target":"-f"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("create with an option as target: status = %d, want 400; body=%s", w.Code, w.Body.String())
+	}
+
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/uptime", "secret",
+		`{"name":"web","type":"HTTP","target":"https://example.com/health","expectBody":"ok"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want 201; body=%s", w.Code, w.Body.String())
+	}
+	check, _ := jsonBody(t, w)["data"].(map[string]any)["check"].(map[string]any)
+	checkID, _ := check["id"].(string)
+	if checkID == "" || check["type"] != "http" || check["intervalSeconds"] != float64(60) ||
+		check["timeoutSeconds"] != float64(10) || check["enabled"] != true || check["status"] != "pending" {
+		t.Fatalf("created check = %v, want an enabled pending http check with default timings", check)
+	}
+
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/uptime", "secret",
+		`{"name":"web","type":"tcp","target":"db:5432"}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("duplicate create: status = %d, want 409", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodPut, "/api/ops/uptime/"+checkID, "secret",
+		`{"name":"web","type":"tcp","target":"example.com:443","intervalSeconds":30,"enabled":false}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	check, _ = jsonBody(t, w)["data"].(map[string]any)["check"].(map[string]any)
+	if check["type"] != "tcp" || check["intervalSeconds"] != float64(30) || check["enabled"] != false {
+		t.Fatalf("updated check = %v", check)
+	}
+	w = serveWithBearer(mux, http.MethodPut, "/api/ops/uptime/missing", "secret",
+		`{"name":"other","type":"tcp","target":"example.com:443"}`)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("update missing: status = %d, want 404", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodGet, "/api/ops/uptime", "secret", "")
+	checks, _ := jsonBody(t, w)["data"].(map[string]any)["checks"].([]any)
+	if w.Code != http.StatusOK || len(checks) != 1 {
+		t.Fatalf("list: status = %d, checks = %v", w.Code, checks)
+	}
+
+	w = serveWithBearer(mux, http.MethodDelete, "/api/ops/uptime/"+checkID, "secret", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	w = serveWithBearer(mux, http.MethodDelete, "/api/ops/uptime/"+checkID, "secret", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("second delete: status = %d, want 404", w.Code)
+	}
+}
@@ -1,9 +1,11 @@
 package api
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -89,6 +91,11 @@ func (h *Handler) activityDelta(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
 		return
 	}
+	filter, err := parseSessionTagFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
@@ -104,6 +111,10 @@ func (h *Handler) activityDelta(w http.ResponseWriter, r *http.Request) {
 		changes = changes[:limit]
 	}
 
+	if filter.active() {
+		changes = filterJournalBySessionMeta(changes, h.loadSessionMetaMap(ctx), filter)
+	}
+
 	globalRev := readWatchtowerGlobalRev(ctx, h.repo)
 	sessionNames := extractChangedSessionNames(changes)
 	sessionPatches, inspectorPatches := h.collectSessionsPatches(ctx, sessionNames)
@@ -147,6 +158,19 @@ func parseActivityDeltaParams(r *http.Request) (int64, int, error) {
 	return since, limit, nil
 }
 
+// filterJournalBySessionMeta keeps the journal entries of sessions matching
+// the tag filter. Entries without a session are dropped.
+func filterJournalBySessionMeta(changes []store.WatchtowerJournal, stored map[string]store.SessionMeta, filter sessionTagFilter) []store.WatchtowerJournal {
+	filtered := make([]store.WatchtowerJournal, 0, len(changes))
+	for _, change := range changes {
+		meta, ok := stored[strings.TrimSpace(change.Session)]
+		if ok && filter.matches(meta) {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
+
 func extractChangedSessionNames(changes []store.WatchtowerJournal) []string {
 	sessionSet := make(map[string]struct{}, len(changes))
 	for _, change := range changes {
@@ -194,6 +218,7 @@ func (h *Handler) activityStats(w http.ResponseWriter, r *http.Request) {
 		"last_collect_duration_ms",
 		"last_collect_sessions",
 		"last_collect_changed_sessions",
+		"last_collect_skipped_sessions",
 		"last_collect_error",
 	}
 
@@ -227,7 +252,77 @@ func (h *Handler) activityStats(w http.ResponseWriter, r *http.Request) {
 		"lastCollectDurationMs": parseInt("last_collect_duration_ms"),
 		"lastCollectSessions":   parseInt("last_collect_sessions"),
 		"lastCollectChanged":    parseInt("last_collect_changed_sessions"),
+		"lastCollectSkipped":    parseInt("last_collect_skipped_sessions"),
 		"lastCollectError":      runtime["last_collect_error"],
 		"runtime":               runtime,
 	})
 }
+
+const (
+	defaultHeatmapDays = 7
+	maxHeatmapDays     = 90
+)
+
+type heatmapHour struct {
+	Hour    time.Time `json:"hour"`
+	Changes int64     `json:"changes"`
+}
+
+type heatmapSession struct {
+	Session string        `json:"session"`
+	Total   int64         `json:"total"`
+	Hours   []heatmapHour `json:"hours"`
+}
+
+// activityHeatmap reports how many watchtower collections found each session
+// changed, per UTC hour over the last days. Busiest sessions come first.
+func (h *Handler) activityHeatmap(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+
+	days := defaultHeatmapDays
+	if raw := strings.TrimSpace(r.URL.Query().Get("days")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "days must be > 0", nil)
+			return
+		}
+		days = min(parsed, maxHeatmapDays)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	since := time.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour).Truncate(time.Hour)
+	buckets, err := h.repo.ListWatchtowerActivityBuckets(ctx, since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to read activity heatmap", nil)
+		return
+	}
+
+	writeData(w, http.StatusOK, map[string]any{
+		"days":     days,
+		"since":    since.Format(time.RFC3339),
+		"sessions": groupHeatmapBuckets(buckets),
+	})
+}
+
+// groupHeatmapBuckets folds the store buckets, ordered by session, into one
+// row per session sorted by total changes.
+func groupHeatmapBuckets(buckets []store.WatchtowerActivityBucket) []heatmapSession {
+	sessions := make([]heatmapSession, 0)
+	for _, bucket := range buckets {
+		if n := len(sessions); n == 0 || sessions[n-1].Session != bucket.Session {
+			sessions = append(sessions, heatmapSession{Session: bucket.Session, Hours: []heatmapHour{}})
+		}
+		last := &sessions[len(sessions)-1]
+		last.Total += bucket.Changes
+		last.Hours = append(last.Hours, heatmapHour{Hour: bucket.Hour, Changes: bucket.Changes})
+	}
+	slices.SortStableFunc(sessions, func(a, b heatmapSession) int {
+		return cmp.Compare(b.Total, a.Total)
+	})
+	return sessions
+}
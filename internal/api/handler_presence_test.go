@@ -184,6 +184,46 @@ func TestActivityStatsSuccessParsesRuntime(t *testing.T) {
 	}
 }
 
+func TestActivityHeatmapGroupsSessionsByTotal(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Hour)
+	for i, row := range []store.WatchtowerJournalWrite{
+		{EntityType: "session", Session: "dev", ChangedAt: now.Add(-2 * time.Hour)},
+		{EntityType: "session", Session: "ops", ChangedAt: now.Add(-2 * time.Hour)},
+		{EntityType: "session", Session: "ops", ChangedAt: now},
+		{EntityType: "session", Session: "ops", ChangedAt: now.Add(-10 * 24 * time.Hour)},
+	} {
+		row.GlobalRev = int64(i + 1)
+		if _, err := st.InsertWatchtowerJournal(ctx, row); err != nil {
+			t.Fatalf("InsertWatchtowerJournal(%d): %v", i, err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.activityHeatmap(w, httptest.NewRequest(http.MethodGet, "/api/tmux/activity/heatmap?days=7", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("activityHeatmap status = %d, want %d; body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	data := jsonBody(t, w)["data"].(map[string]any)
+	sessions := data["sessions"].([]any)
+	if data["days"] != float64(7) || len(sessions) != 2 {
+		t.Fatalf("unexpected heatmap: %+v", data)
+	}
+	first := sessions[0].(map[string]any)
+	if first["session"] != "ops" || first["total"] != float64(2) || len(first["hours"].([]any)) != 2 {
+		t.Fatalf("first session = %+v, want ops with 2 changes in 2 hours", first)
+	}
+
+	w = httptest.NewRecorder()
+	h.activityHeatmap(w, httptest.NewRequest(http.MethodGet, "/api/tmux/activity/heatmap?days=0", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("activityHeatmap(days=0) status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
 func seedActivityDeltaSession(t *testing.T, st *store.Store, session, paneID string, now time.Time, rev int64) {
 	t.Helper()
 	ctx := context.Background()
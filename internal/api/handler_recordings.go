@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/opus-domini/sentinel/internal/recording"
+)
+
+// recordingStore lists and serves terminal recordings.
+type recordingStore interface {
+	List() ([]recording.Info, error)
+	Stat(id string) (recording.Info, error)
+	Open(id string) (*os.File, error)
+	Delete(id string) error
+}
+
+// SetRecordings enables the recording endpoints backed by the given store.
+func (h *Handler) SetRecordings(store recordingStore) {
+	if h == nil {
+		return
+	}
+	h.recordings = store
+}
+
+func (h *Handler) recordingsEnabled(w http.ResponseWriter) bool {
+	if h.recordings == nil {
+		writeError(w, http.StatusNotFound, "RECORDING_DISABLED", "terminal recording is disabled", nil)
+		return false
+	}
+	return true
+}
+
+func writeRecordingError(w http.ResponseWriter, err error) {
+	if errors.Is(err, recording.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "RECORDING_NOT_FOUND", "recording not found", nil)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, "RECORDING_ERROR", "failed to read recordings", nil)
+}
+
+func (h *Handler) listRecordings(w http.ResponseWriter, _ *http.Request) {
+	if !h.recordingsEnabled(w) {
+		return
+	}
+	infos, err := h.recordings.List()
+	if err != nil {
+		writeRecordingError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"recordings": infos})
+}
+
+// downloadRecording serves the asciicast file. Recordings still being
+// written are served up to their current end.
+func (h *Handler) downloadRecording(w http.ResponseWriter, r *http.Request) {
+	if !h.recordingsEnabled(w) {
+		return
+	}
+	id := strings.TrimSpace(r.PathValue("recording"))
+	info, err := h.recordings.Stat(id)
+	if err != nil {
+		writeRecordingError(w, err)
+		return
+	}
+	f, err := h.recordings.Open(id)
+	if err != nil {
+		writeRecordingError(w, err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	name := info.ID + ".cast"
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, name, info.UpdatedAt, f)
+}
+
+func (h *Handler) deleteRecording(w http.ResponseWriter, r *http.Request) {
+	if !h.recordingsEnabled(w) {
+		return
+	}
+	if err := h.recordings.Delete(strings.TrimSpace(r.PathValue("recording"))); err != nil {
+		writeRecordingError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
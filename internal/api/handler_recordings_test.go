@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/recording"
+)
+
+func TestRecordingEndpoints(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	w := httptest.NewRecorder()
+	h.listRecordings(w, httptest.NewRequest(http.MethodGet, "/api/tmux/recordings", nil))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "RECORDING_DISABLED") {
+		t.Fatalf("disabled: status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	store := recording.New(t.TempDir(), recording.Options{})
+	rec, err := store.Start("dev", 80, 24)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	rec.Output([]byte("hello"))
+	_ = rec.Close()
+	h.SetRecordings(store)
+
+	w = httptest.NewRecorder()
+	h.listRecordings(w, httptest.NewRequest(http.MethodGet, "/api/tmux/recordings", nil))
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	list, _ := data["recordings"].([]any)
+	if w.Code != http.StatusOK || len(list) != 1 || list[0].(map[string]any)["id"] != rec.ID() {
+		t.Fatalf("list: status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	download := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/tmux/recordings/"+id, nil)
+		r.SetPathValue("recording", id)
+		h.downloadRecording(w, r)
+		return w
+	}
+	w = download(rec.ID())
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-asciicast" ||
+		!strings.HasPrefix(w.Body.String(), `{"version":2`) || !strings.Contains(w.Body.String(), `"o","hello"`) {
+		t.Fatalf("download: status = %d, headers %v, body %s", w.Code, w.Header(), w.Body.String())
+	}
+	if w := download("../sentinel.db"); w.Code != http.StatusNotFound {
+		t.Fatalf("traversal download: status = %d, want 404", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodDelete, "/api/tmux/recordings/"+rec.ID(), nil)
+	r.SetPathValue("recording", rec.ID())
+	h.deleteRecording(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: status = %d, body %s", w.Code, w.Body.String())
+	}
+	if w := download(rec.ID()); w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "RECORDING_NOT_FOUND") {
+		t.Fatalf("download after delete: status = %d, body %s", w.Code, w.Body.String())
+	}
+}
@@ -3,11 +3,16 @@ package api
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/jobqueue"
 	"github.com/opus-domini/sentinel/internal/runbook"
 	"github.com/opus-domini/sentinel/internal/store"
 )
@@ -44,6 +49,11 @@ func (h *Handler) opsRunbooks(w http.ResponseWriter, r *http.Request) {
 
 type runOpsRunbookRequest struct {
 	Parameters map[string]string `json:"parameters"`
+	// Hosts is a label selector replacing the selector of every service step.
+	Hosts string `json:"hosts"`
+	// Priority orders the run among those waiting for a worker: low,
+	// normal (default) or high.
+	Priority string `json:"priority"`
 }
 
 func (h *Handler) runOpsRunbook(w http.ResponseWriter, r *http.Request) {
@@ -57,20 +67,24 @@ func (h *Handler) runOpsRunbook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse optional parameters from request body.
-	var reqParams map[string]string
+	// Parse optional parameters and host selector from request body.
+	var req runOpsRunbookRequest
 	if r.Body != nil && r.ContentLength != 0 {
-		var req runOpsRunbookRequest
 		if err := decodeJSON(r, &req); err != nil {
 			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
 			return
 		}
-		reqParams = req.Parameters
+	}
+
+	priority, err := jobqueue.ParsePriority(req.Priority)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 6*time.Second)
 	defer cancel()
-	job, err := h.runbooks.Start(ctx, runbookID, reqParams, "runbook")
+	job, err := h.runbooks.StartWithPriority(ctx, runbookID, req.Parameters, req.Hosts, "runbook", priority)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -79,6 +93,8 @@ func (h *Handler) runOpsRunbook(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", err.Error(), nil)
 		case errors.Is(err, runbook.ErrInvalidParameters):
 			writeError(w, http.StatusBadRequest, "INVALID_PARAMETERS", err.Error(), nil)
+		case errors.Is(err, runbook.ErrInvalidHostSelector):
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
 		default:
 			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to run runbook", nil)
 		}
@@ -91,6 +107,67 @@ func (h *Handler) runOpsRunbook(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// dryRunOpsRunbook renders a runbook's execution plan with the request
+// parameters without running anything.
+func (h *Handler) dryRunOpsRunbook(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil || h.runbooks == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	runbookID := strings.TrimSpace(r.PathValue(keyRunbook))
+	if runbookID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "runbook is required", nil)
+		return
+	}
+
+	var req runOpsRunbookRequest
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 6*time.Second)
+	defer cancel()
+	plan, err := h.runbooks.DryRunOnHosts(ctx, runbookID, req.Parameters, req.Hosts, h.tmuxSessionExists(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "OPS_RUNBOOK_NOT_FOUND", "runbook not found", nil)
+		case errors.Is(err, runbook.ErrInvalidParameters):
+			writeError(w, http.StatusBadRequest, "INVALID_PARAMETERS", err.Error(), nil)
+		case errors.Is(err, runbook.ErrInvalidHostSelector):
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to plan runbook", nil)
+		}
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"plan": plan})
+}
+
+// tmuxSessionExists snapshots the live tmux sessions for dry-run checks. It
+// returns nil when tmux cannot be listed so session checks are skipped
+// rather than reported as missing.
+func (h *Handler) tmuxSessionExists(ctx context.Context) runbook.SessionExists {
+	if h.tmux == nil {
+		return nil
+	}
+	sessions, err := h.tmux.ListSessions(ctx)
+	if err != nil {
+		return nil
+	}
+	names := make(map[string]struct{}, len(sessions))
+	for _, sess := range sessions {
+		names[sess.Name] = struct{}{}
+	}
+	return func(name string) bool {
+		_, ok := names[name]
+		return ok
+	}
+}
+
 func (h *Handler) emitEvent(eventType string, payload map[string]any) {
 	h.emit(eventType, payload)
 }
@@ -118,7 +195,151 @@ func (h *Handler) opsJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	writeData(w, http.StatusOK, map[string]any{
-		keyJob: job,
+		keyJob:          job,
+		"queuePosition": h.runbooks.QueuePosition(job.ID),
+	})
+}
+
+// jobLogHeartbeat keeps idle job log streams alive through proxies and
+// re-checks the job, in case its final event was dropped for a slow client.
+const jobLogHeartbeat = 15 * time.Second
+
+// streamOpsJobLogs streams a job's live step output as server-sent events:
+// a "job" snapshot first, then "log" chunks, and "done" with the finished
+// job before the stream closes.
+func (h *Handler) streamOpsJobLogs(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil || h.runbooks == nil || h.events == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	jobID := strings.TrimSpace(r.PathValue(keyJob))
+	if jobID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "job id is required", nil)
+		return
+	}
+
+	// Subscribe before loading the snapshot so no chunk falls in between.
+	eventsCh, unsubscribe := h.events.Subscribe(256)
+	defer unsubscribe()
+
+	job, err := h.loadOpsJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "OPS_JOB_NOT_FOUND", "job not found", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to load job", nil)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The stream lives as long as the job, past the server write timeout.
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeSSE(w, rc, "job", map[string]any{keyJob: job}); err != nil {
+		return
+	}
+	if runbook.IsTerminalStatus(job.Status) {
+		_ = writeSSE(w, rc, "done", map[string]any{keyJob: job})
+		return
+	}
+
+	heartbeat := time.NewTicker(jobLogHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if current, err := h.loadOpsJob(r.Context(), jobID); err == nil && runbook.IsTerminalStatus(current.Status) {
+				_ = writeSSE(w, rc, "done", map[string]any{keyJob: current})
+				return
+			}
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case evt, ok := <-eventsCh:
+			if !ok {
+				return
+			}
+			switch evt.Type {
+			case events.TypeOpsJobLog:
+				if evt.Payload[keyJobID] != jobID {
+					continue
+				}
+				if err := writeSSE(w, rc, "log", evt.Payload); err != nil {
+					return
+				}
+			case events.TypeOpsJob:
+				updated, ok := evt.Payload[keyJob].(store.OpsRunbookRun)
+				if !ok || updated.ID != jobID || !runbook.IsTerminalStatus(updated.Status) {
+					continue
+				}
+				_ = writeSSE(w, rc, "done", map[string]any{keyJob: updated})
+				return
+			}
+		}
+	}
+}
+
+func (h *Handler) loadOpsJob(ctx context.Context, jobID string) (store.OpsRunbookRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	return h.runbooks.GetRun(ctx, jobID)
+}
+
+func writeSSE(w io.Writer, rc *http.ResponseController, event string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, raw); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// cancelOpsJob stops a job. An executing job is signaled and answers 202;
+// its final "canceled" state arrives as an ops.job.updated event. A job
+// waiting for approval is canceled at once.
+func (h *Handler) cancelOpsJob(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil || h.runbooks == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	jobID := strings.TrimSpace(r.PathValue(keyJob))
+	if jobID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "job id is required", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	job, err := h.runbooks.Cancel(ctx, jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "OPS_JOB_NOT_FOUND", "job not found", nil)
+		case errors.Is(err, runbook.ErrInvalidRunState):
+			writeError(w, http.StatusConflict, "INVALID_STATE", err.Error(), nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to cancel job", nil)
+		}
+		return
+	}
+	status := http.StatusAccepted
+	if runbook.IsTerminalStatus(job.Status) {
+		status = http.StatusOK
+	}
+	writeData(w, status, map[string]any{
+		keyJob:       job,
+		keyGlobalRev: time.Now().UTC().UnixMilli(),
 	})
 }
 
@@ -204,6 +425,8 @@ func (h *Handler) updateOpsRunbook(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, "OPS_RUNBOOK_NOT_FOUND", "runbook not found", nil)
 		case errors.Is(err, runbook.ErrInvalidDefinition):
 			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		case errors.Is(err, runbook.ErrManagedByFile):
+			writeError(w, http.StatusConflict, "RUNBOOK_MANAGED", err.Error(), nil)
 		default:
 			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to update runbook", nil)
 		}
@@ -240,6 +463,8 @@ func (h *Handler) deleteOpsRunbook(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, "OPS_RUNBOOK_NOT_FOUND", "runbook not found", nil)
 		case errors.Is(err, store.ErrOpsRunbookActive):
 			writeError(w, http.StatusConflict, "RUNBOOK_ACTIVE", err.Error(), nil)
+		case errors.Is(err, runbook.ErrManagedByFile):
+			writeError(w, http.StatusConflict, "RUNBOOK_MANAGED", err.Error(), nil)
 		default:
 			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to delete runbook", nil)
 		}
@@ -249,6 +474,7 @@ func (h *Handler) deleteOpsRunbook(w http.ResponseWriter, r *http.Request) {
 	writeData(w, http.StatusOK, map[string]any{
 		keyRemoved:         deleted.ID,
 		"deletedSchedules": deleted.DeletedSchedules,
+		"deletedWebhooks":  deleted.DeletedWebhooks,
 	})
 }
 
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/runbook"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestDryRunOpsRunbook(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, &mockTmux{
+		listSessionsFn: func(context.Context) ([]tmux.Session, error) {
+			return []tmux.Session{{Name: "dev"}}, nil
+		},
+	})
+	rb, err := st.InsertOpsRunbook(context.Background(), store.OpsRunbookWrite{
+		Name: "dry-run-rb",
+		Steps: []store.OpsRunbookStep{
+			{Type: "run", Title: "echo", Command: "echo {{ENV}}"},
+			{Type: "tmux.send", Title: "dev", Target: "dev:1", Keys: "make"},
+			{Type: "tmux.send", Title: "gone", Target: "gone", Keys: "make"},
+		},
+		Parameters: []store.RunbookParameter{{Name: "ENV", Type: "string", Required: true}},
+		Enabled:    true,
+	})
+	if err != nil {
+		t.Fatalf("InsertOpsRunbook: %v", err)
+	}
+
+	dryRun := func(id, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/ops/runbooks/"+id+"/dry-run", strings.NewReader(body))
+		r.SetPathValue("runbook", id)
+		h.dryRunOpsRunbook(w, r)
+		return w
+	}
+
+	w := dryRun(rb.ID, `{"parameters":{"ENV":"prod"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("dry-run status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			Plan runbook.Plan `json:"plan"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	plan := resp.Data.Plan
+	if plan.Ready || len(plan.Steps) != 3 {
+		t.Fatalf("plan = %+v, want 3 steps and not ready", plan)
+	}
+	if plan.Steps[0].Command != "echo 'prod'" {
+		t.Fatalf("rendered command = %q", plan.Steps[0].Command)
+	}
+	if len(plan.Steps[1].Problems) != 0 || len(plan.Steps[2].Problems) != 1 {
+		t.Fatalf("session problems = %v, %v", plan.Steps[1].Problems, plan.Steps[2].Problems)
+	}
+
+	jobs, err := st.ListOpsRunbookRuns(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("ListOpsRunbookRuns: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("dry run created %d jobs", len(jobs))
+	}
+
+	if w := dryRun(rb.ID, `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing parameter status = %d, want 400", w.Code)
+	}
+	if w := dryRun("missing", `{}`); w.Code != http.StatusNotFound {
+		t.Fatalf("missing runbook status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func TestStreamOpsJobLogs(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	h.events = events.NewHub()
+	ctx := context.Background()
+	rb, err := st.InsertOpsRunbook(ctx, store.OpsRunbookWrite{
+		Name:  "stream-rb",
+		Steps: []store.OpsRunbookStep{{Type: "run", Title: "echo", Command: "echo ok"}},
+	})
+	if err != nil {
+		t.Fatalf("InsertOpsRunbook: %v", err)
+	}
+	job, err := st.CreateOpsRunbookRun(ctx, rb.ID, time.Now().UTC())
+	if err != nil {
+		t.Fatalf("CreateOpsRunbookRun: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.SetPathValue("job", strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/ops/jobs/"), "/logs/stream"))
+		h.streamOpsJobLogs(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/api/ops/jobs/" + job.ID + "/logs/stream")
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	first, err := reader.ReadString('\n')
+	if err != nil || first != "event: job\n" {
+		t.Fatalf("first line = %q, %v; want job snapshot", first, err)
+	}
+
+	h.emit(events.TypeOpsJobLog, map[string]any{keyJobID: "other-job", "stream": "stdout", "chunk": "not mine"})
+	h.emit(events.TypeOpsJobLog, map[string]any{keyJobID: job.ID, "stream": "stdout", "chunk": "hello"})
+	h.emit(events.TypeOpsJob, map[string]any{keyJob: store.OpsRunbookRun{ID: job.ID, Status: stateSucceeded}})
+
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	body := string(rest)
+	if !strings.Contains(body, "event: log\ndata: ") || !strings.Contains(body, `"chunk":"hello"`) {
+		t.Fatalf("stream missing log chunk: %s", body)
+	}
+	if strings.Contains(body, "not mine") {
+		t.Fatalf("stream leaked another job's output: %s", body)
+	}
+	if !strings.Contains(body, "event: done\n") {
+		t.Fatalf("stream missing done event: %s", body)
+	}
+}
+
+func TestStreamOpsJobLogsFinishedJob(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	h.events = events.NewHub()
+	ctx := context.Background()
+	rb, err := st.InsertOpsRunbook(ctx, store.OpsRunbookWrite{Name: "done-rb"})
+	if err != nil {
+		t.Fatalf("InsertOpsRunbook: %v", err)
+	}
+	job, err := st.CreateOpsRunbookRun(ctx, rb.ID, time.Now().UTC())
+	if err != nil {
+		t.Fatalf("CreateOpsRunbookRun: %v", err)
+	}
+	if _, err := st.UpdateOpsRunbookRun(ctx, store.OpsRunbookRunUpdate{
+		RunID: job.ID, Status: stateFailed, Error: "boom", FinishedAt: time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		t.Fatalf("UpdateOpsRunbookRun: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/ops/jobs/"+job.ID+"/logs/stream", nil)
+	r.SetPathValue("job", job.ID)
+	h.streamOpsJobLogs(w, r)
+
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "event: job\n") || !strings.Contains(body, "event: done\n") {
+		t.Fatalf("finished job stream = %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/api/ops/jobs/missing/logs/stream", nil)
+	r.SetPathValue("job", "missing")
+	h.streamOpsJobLogs(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("missing job status = %d, want 404", w.Code)
+	}
+}
+
+func TestCancelOpsJob(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	h.events = events.NewHub()
+	run := createWaitingApprovalRun(t, st)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/ops/jobs/"+run.ID+"/cancel", nil)
+	r.SetPathValue("job", run.ID)
+	h.cancelOpsJob(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("cancel status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	updated, err := st.GetOpsRunbookRun(context.Background(), run.ID)
+	if err != nil {
+		t.Fatalf("GetOpsRunbookRun: %v", err)
+	}
+	if updated.Status != "canceled" {
+		t.Fatalf("status = %q, want canceled", updated.Status)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/api/ops/jobs/"+run.ID+"/cancel", nil)
+	r.SetPathValue("job", run.ID)
+	h.cancelOpsJob(w, r)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("second cancel status = %d, want 409", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/api/ops/jobs/missing/cancel", nil)
+	r.SetPathValue("job", "missing")
+	h.cancelOpsJob(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("missing job status = %d, want 404", w.Code)
+	}
+}
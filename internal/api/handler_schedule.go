@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -41,13 +43,12 @@ func (h *Handler) createSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		RunbookID    string `json:"runbookId"`
-		Name         string `json:"name"`
-		ScheduleType string `json:"scheduleType"`
-		CronExpr     string `json:"cronExpr"`
-		Timezone     string `json:"timezone"`
-		RunAt        string `json:"runAt"`
-		Enabled      bool   `json:"enabled"`
+		RunbookID string `json:"runbookId"`
+		Name      string `json:"name"`
+		scheduleSpec
+		ConcurrencyPolicy string `json:"concurrencyPolicy"`
+		Hosts             string `json:"hosts"`
+		Enabled           bool   `json:"enabled"`
 	}
 	if err := decodeJSON(r, &req); err != nil {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
@@ -62,15 +63,23 @@ func (h *Handler) createSchedule(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "name is required", nil)
 		return
 	}
-	if req.ScheduleType != scheduleTypeCron && req.ScheduleType != scheduleTypeOnce {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "scheduleType must be \"cron\" or \"once\"", nil)
+	if !isScheduleType(req.ScheduleType) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", errScheduleType.Error(), nil)
+		return
+	}
+	if !isConcurrencyPolicy(req.ConcurrencyPolicy) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", errConcurrencyPolicy.Error(), nil)
+		return
+	}
+	if err := validateHostSelector(req.Hosts); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	nextRunAt, err := validateScheduleRequest(ctx, h.repo, req.RunbookID, req.ScheduleType, req.CronExpr, req.Timezone, req.RunAt)
+	nextRunAt, err := validateScheduleRequest(ctx, h.repo, req.RunbookID, req.scheduleSpec)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
 		return
@@ -86,6 +95,10 @@ func (h *Handler) createSchedule(w http.ResponseWriter, r *http.Request) {
 		CronExpr:     req.CronExpr,
 		Timezone:     req.Timezone,
 		RunAt:        req.RunAt,
+		Interval:     req.Interval,
+		Jitter:       req.Jitter,
+		Concurrency:  req.ConcurrencyPolicy,
+		Hosts:        strings.TrimSpace(req.Hosts),
 		Enabled:      req.Enabled,
 		NextRunAt:    nextRunAt,
 	})
@@ -116,13 +129,12 @@ func (h *Handler) updateSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		RunbookID    string `json:"runbookId"`
-		Name         string `json:"name"`
-		ScheduleType string `json:"scheduleType"`
-		CronExpr     string `json:"cronExpr"`
-		Timezone     string `json:"timezone"`
-		RunAt        string `json:"runAt"`
-		Enabled      bool   `json:"enabled"`
+		RunbookID string `json:"runbookId"`
+		Name      string `json:"name"`
+		scheduleSpec
+		ConcurrencyPolicy string `json:"concurrencyPolicy"`
+		Hosts             string `json:"hosts"`
+		Enabled           bool   `json:"enabled"`
 	}
 	if err := decodeJSON(r, &req); err != nil {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
@@ -137,19 +149,30 @@ func (h *Handler) updateSchedule(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "name is required", nil)
 		return
 	}
-	if req.ScheduleType != scheduleTypeCron && req.ScheduleType != scheduleTypeOnce {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "scheduleType must be \"cron\" or \"once\"", nil)
+	if !isScheduleType(req.ScheduleType) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", errScheduleType.Error(), nil)
+		return
+	}
+	if !isConcurrencyPolicy(req.ConcurrencyPolicy) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", errConcurrencyPolicy.Error(), nil)
+		return
+	}
+	if err := validateHostSelector(req.Hosts); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	nextRunAt, err := validateScheduleRequest(ctx, h.repo, req.RunbookID, req.ScheduleType, req.CronExpr, req.Timezone, req.RunAt)
+	nextRunAt, err := validateScheduleRequest(ctx, h.repo, req.RunbookID, req.scheduleSpec)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
 		return
 	}
+	if !h.scheduleUnmanaged(ctx, w, scheduleID) {
+		return
+	}
 	if req.Timezone == "" && req.ScheduleType == scheduleTypeCron {
 		req.Timezone = defaultTimezoneUTC
 	}
@@ -162,6 +185,10 @@ func (h *Handler) updateSchedule(w http.ResponseWriter, r *http.Request) {
 		CronExpr:     req.CronExpr,
 		Timezone:     req.Timezone,
 		RunAt:        req.RunAt,
+		Interval:     req.Interval,
+		Jitter:       req.Jitter,
+		Concurrency:  req.ConcurrencyPolicy,
+		Hosts:        strings.TrimSpace(req.Hosts),
 		Enabled:      req.Enabled,
 		NextRunAt:    nextRunAt,
 	})
@@ -198,6 +225,9 @@ func (h *Handler) deleteSchedule(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
+	if !h.scheduleUnmanaged(ctx, w, scheduleID) {
+		return
+	}
 	if err := h.repo.DeleteOpsSchedule(ctx, scheduleID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "SCHEDULE_NOT_FOUND", "schedule not found", nil)
@@ -217,6 +247,24 @@ func (h *Handler) deleteSchedule(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// scheduleUnmanaged writes an error and returns false unless the schedule
+// exists and is not owned by a runbook library file.
+func (h *Handler) scheduleUnmanaged(ctx context.Context, w http.ResponseWriter, scheduleID string) bool {
+	schedule, err := h.repo.GetOpsSchedule(ctx, scheduleID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "SCHEDULE_NOT_FOUND", "schedule not found", nil)
+		return false
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to load schedule", nil)
+		return false
+	case schedule.ManagedBy != "":
+		writeError(w, http.StatusConflict, "SCHEDULE_MANAGED", "schedule is managed by a library file "+schedule.ManagedBy, nil)
+		return false
+	}
+	return true
+}
+
 func (h *Handler) triggerSchedule(w http.ResponseWriter, r *http.Request) {
 	if h.repo == nil {
 		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
@@ -250,7 +298,7 @@ func (h *Handler) triggerSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	now := time.Now().UTC()
-	job, err := h.repo.CreateOpsRunbookRun(ctx, sched.RunbookID, now)
+	job, err := h.repo.CreateOpsRunbookRunForHosts(ctx, sched.RunbookID, now, nil, sched.Hosts)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "OPS_RUNBOOK_NOT_FOUND", "runbook not found", nil)
@@ -275,17 +323,27 @@ func (h *Handler) triggerSchedule(w http.ResponseWriter, r *http.Request) {
 		}
 		cronSched, cronErr := validate.ParseCron(sched.CronExpr)
 		if cronErr != nil {
-			slog.Warn("trigger schedule: invalid cron, disabling", keySchedule, scheduleID, "err", cronErr)
+			slog.WarnContext(r.Context(), "trigger schedule: invalid cron, disabling", keySchedule, scheduleID, "err", cronErr)
 			finalNextRunAt = ""
 			finalEnabled = false
 		} else {
 			finalNextRunAt = cronSched.Next(time.Now().In(loc)).UTC().Format(time.RFC3339)
 			finalEnabled = true
 		}
+	case scheduleTypeInterval:
+		intervalSched, intervalErr := validate.ParseInterval(sched.Interval, sched.Jitter)
+		if intervalErr != nil {
+			slog.WarnContext(r.Context(), "trigger schedule: invalid interval, disabling", keySchedule, scheduleID, "err", intervalErr)
+			finalNextRunAt = ""
+			finalEnabled = false
+		} else {
+			finalNextRunAt = intervalSched.Next(now).Format(time.RFC3339)
+			finalEnabled = true
+		}
 	}
 
 	if err := h.repo.UpdateScheduleAfterRun(ctx, scheduleID, now.Format(time.RFC3339), stateRunning, finalNextRunAt, finalEnabled); err != nil {
-		slog.Warn("trigger schedule: update after run failed", keySchedule, scheduleID, "err", err)
+		slog.WarnContext(r.Context(), "trigger schedule: update after run failed", keySchedule, scheduleID, "err", err)
 	}
 
 	h.wg.Add(1)
@@ -295,12 +353,15 @@ func (h *Handler) triggerSchedule(w http.ResponseWriter, r *http.Request) {
 			Job:         job,
 			Source:      keySchedule,
 			StepTimeout: 30 * time.Second,
+			Hosts:       h.hostTargets(),
+			Secrets:     h.secrets.Lookup,
+			UserPolicy:  h.guard.ValidateTargetUser,
 			OnFinish: func(ctx context.Context, status string) {
 				finished := time.Now().UTC()
 				// Update only last_run_*; next_run_at/enabled were set at dispatch
 				// and may have been edited during the run.
 				if err := h.repo.UpdateScheduleLastRun(ctx, scheduleID, finished.Format(time.RFC3339), status); err != nil {
-					slog.Warn("trigger schedule: update after completion", keySchedule, scheduleID, "err", err)
+					slog.WarnContext(ctx, "trigger schedule: update after completion", keySchedule, scheduleID, "err", err)
 				}
 				h.emit(events.TypeScheduleUpdated, map[string]any{
 					keyAction:   "run_completed",
@@ -328,24 +389,161 @@ func (h *Handler) triggerSchedule(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// validateScheduleRequest checks runbook existence, parses cron/once fields,
-// and returns the computed nextRunAt. It returns a user-facing error message
-// on any validation failure.
+const (
+	defaultScheduleHistoryLimit = 50
+	maxScheduleHistoryLimit     = 500
+)
+
+// scheduleHistory lists the scheduler-triggered runs of a schedule, newest
+// first.
+func (h *Handler) scheduleHistory(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	scheduleID := strings.TrimSpace(r.PathValue(keySchedule))
+	if scheduleID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "schedule id is required", nil)
+		return
+	}
+	limit := defaultScheduleHistoryLimit
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxScheduleHistoryLimit {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "limit must be between 1 and 500", nil)
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	schedules, err := h.repo.ListOpsSchedules(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to load schedules", nil)
+		return
+	}
+	if !slices.ContainsFunc(schedules, func(s store.OpsSchedule) bool { return s.ID == scheduleID }) {
+		writeError(w, http.StatusNotFound, "SCHEDULE_NOT_FOUND", "schedule not found", nil)
+		return
+	}
+	runs, err := h.repo.ListScheduleRuns(ctx, scheduleID, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to load schedule history", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"runs": runs})
+}
+
+// schedulePreviewCount is how many upcoming runs a cron preview lists.
+const schedulePreviewCount = 10
+
+// previewSchedule lists the next runs of a cron expression. An invalid
+// expression or timezone is reported in the body rather than as a 400, so
+// the UI can validate while the user types.
+func (h *Handler) previewSchedule(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CronExpr string `json:"cronExpr"`
+		Timezone string `json:"timezone"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+
+	invalid := func(msg string) {
+		writeData(w, http.StatusOK, map[string]any{"valid": false, "error": msg, "next": []string{}})
+	}
+	tz := strings.TrimSpace(req.Timezone)
+	if tz == "" {
+		tz = defaultTimezoneUTC
+	}
+	if err := validate.Timezone(tz); err != nil {
+		invalid("invalid timezone")
+		return
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		invalid("invalid timezone")
+		return
+	}
+	if err := validate.CronExpression(req.CronExpr); err != nil {
+		invalid(err.Error())
+		return
+	}
+	sched, err := validate.ParseCron(req.CronExpr)
+	if err != nil {
+		invalid(err.Error())
+		return
+	}
+
+	next := make([]string, 0, schedulePreviewCount)
+	at := time.Now().In(loc)
+	for range schedulePreviewCount {
+		at = sched.Next(at)
+		if at.IsZero() {
+			// The expression never matches, e.g. "0 0 30 2 *".
+			break
+		}
+		next = append(next, at.Format(time.RFC3339))
+	}
+	writeData(w, http.StatusOK, map[string]any{"valid": true, "timezone": tz, "next": next})
+}
+
+// scheduleSpec holds the timing fields shared by schedule create and update
+// requests.
+type scheduleSpec struct {
+	ScheduleType string `json:"scheduleType"`
+	CronExpr     string `json:"cronExpr"`
+	Timezone     string `json:"timezone"`
+	RunAt        string `json:"runAt"`
+	Interval     string `json:"interval"`
+	Jitter       string `json:"jitter"`
+}
+
+var errScheduleType = errors.New(`scheduleType must be "cron", "once" or "interval"`)
+
+func isScheduleType(raw string) bool {
+	switch raw {
+	case scheduleTypeCron, scheduleTypeOnce, scheduleTypeInterval:
+		return true
+	default:
+		return false
+	}
+}
+
+var errConcurrencyPolicy = errors.New(`concurrencyPolicy must be "forbid", "allow" or "replace"`)
+
+// isConcurrencyPolicy reports whether raw is a schedule concurrency policy.
+// Empty selects the store default (forbid).
+func isConcurrencyPolicy(raw string) bool {
+	switch raw {
+	case "", store.ScheduleConcurrencyForbid, store.ScheduleConcurrencyAllow, store.ScheduleConcurrencyReplace:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateScheduleRequest checks runbook existence, parses cron/once/interval
+// fields, and returns the computed nextRunAt. It returns a user-facing error
+// message on any validation failure.
 type runbookLookup interface {
 	GetOpsRunbook(ctx context.Context, id string) (store.OpsRunbook, error)
 }
 
-func validateScheduleRequest(ctx context.Context, repo runbookLookup, runbookID, scheduleType, cronExpr, timezone, runAt string) (string, error) {
+func validateScheduleRequest(ctx context.Context, repo runbookLookup, runbookID string, spec scheduleSpec) (string, error) {
 	if _, err := repo.GetOpsRunbook(ctx, runbookID); err != nil {
 		return "", fmt.Errorf("runbook not found")
 	}
 
-	switch scheduleType {
+	switch spec.ScheduleType {
 	case scheduleTypeCron:
-		if err := validate.CronExpression(cronExpr); err != nil {
+		if err := validate.CronExpression(spec.CronExpr); err != nil {
 			return "", fmt.Errorf("invalid cron expression")
 		}
-		tz := timezone
+		tz := spec.Timezone
 		if tz == "" {
 			tz = defaultTimezoneUTC
 		}
@@ -356,13 +554,13 @@ func validateScheduleRequest(ctx context.Context, repo runbookLookup, runbookID,
 		if locErr != nil {
 			return "", fmt.Errorf("invalid timezone")
 		}
-		sched, cronErr := validate.ParseCron(cronExpr)
+		sched, cronErr := validate.ParseCron(spec.CronExpr)
 		if cronErr != nil {
 			return "", fmt.Errorf("invalid cron expression")
 		}
 		return sched.Next(time.Now().In(loc)).UTC().Format(time.RFC3339), nil
 	case scheduleTypeOnce:
-		parsed, parseErr := time.Parse(time.RFC3339, runAt)
+		parsed, parseErr := time.Parse(time.RFC3339, spec.RunAt)
 		if parseErr != nil {
 			return "", fmt.Errorf("runAt must be a valid RFC3339 timestamp")
 		}
@@ -370,7 +568,13 @@ func validateScheduleRequest(ctx context.Context, repo runbookLookup, runbookID,
 			return "", fmt.Errorf("runAt must be in the future")
 		}
 		return parsed.UTC().Format(time.RFC3339), nil
+	case scheduleTypeInterval:
+		sched, err := validate.ParseInterval(spec.Interval, spec.Jitter)
+		if err != nil {
+			return "", err
+		}
+		return sched.Next(time.Now().UTC()).Format(time.RFC3339), nil
 	default:
-		return "", fmt.Errorf("scheduleType must be \"cron\" or \"once\"")
+		return "", errScheduleType
 	}
 }
@@ -79,6 +79,12 @@ func (h *Handler) patchOpsConfig(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "content is required", nil)
 		return
 	}
+	if _, issues := config.ValidateContent(req.Content); len(issues) > 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_CONFIG", "config is invalid", map[string]any{
+			"issues": issues,
+		})
+		return
+	}
 	h.configMu.Lock()
 	err := os.WriteFile(h.configPath, []byte(req.Content), 0o600)
 	h.configMu.Unlock()
@@ -93,6 +99,51 @@ func (h *Handler) patchOpsConfig(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// validateOpsConfig checks proposed config content without writing it and
+// lists the settings it would change relative to the current config file.
+func (h *Handler) validateOpsConfig(w http.ResponseWriter, r *http.Request) {
+	if h.configPath == "" {
+		writeError(w, http.StatusServiceUnavailable, "CONFIG_UNAVAILABLE", "config path not set", nil)
+		return
+	}
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if req.Content == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "content is required", nil)
+		return
+	}
+
+	h.configMu.Lock()
+	current, err := os.ReadFile(h.configPath)
+	h.configMu.Unlock()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		writeError(w, http.StatusInternalServerError, "CONFIG_READ_FAILED", "failed to read config file", nil)
+		return
+	}
+	// Both sides are resolved the same way, so deployment defaults and
+	// environment overrides do not show up as changes.
+	currentCfg, _ := config.ValidateContent(string(current))
+	proposedCfg, issues := config.ValidateContent(req.Content)
+	if issues == nil {
+		issues = []string{}
+	}
+	changes := []config.Change{}
+	if len(issues) == 0 {
+		changes = config.Diff(currentCfg, proposedCfg)
+	}
+
+	writeData(w, http.StatusOK, map[string]any{
+		"valid":   len(issues) == 0,
+		"issues":  issues,
+		"changes": changes,
+	})
+}
+
 func (h *Handler) storageStats(w http.ResponseWriter, r *http.Request) {
 	if h.repo == nil {
 		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
@@ -147,6 +198,74 @@ func (h *Handler) flushStorage(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// SetBackupOptions configures where on-demand database backups are written
+// and how many are kept.
+func (h *Handler) SetBackupOptions(dir string, keep int) {
+	if h == nil {
+		return
+	}
+	h.backupDir = strings.TrimSpace(dir)
+	h.backupKeep = keep
+}
+
+func (h *Handler) backupStorage(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	if h.backupDir == "" {
+		writeError(w, http.StatusServiceUnavailable, "BACKUP_UNAVAILABLE", "backup directory is not configured", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	backup, err := h.repo.Backup(ctx, h.backupDir, h.backupKeep, time.Now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "BACKUP_FAILED", "failed to back up database", nil)
+		return
+	}
+	writeData(w, http.StatusCreated, map[string]any{"backup": backup})
+}
+
+// maintainStorage checks the database and releases unused space. It
+// answers once every step has run, with a report of each.
+func (h *Handler) maintainStorage(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	report, err := h.repo.Maintain(ctx, nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "MAINTENANCE_FAILED", "failed to maintain database", map[string]any{
+			"steps": report.Steps,
+		})
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"report": report})
+}
+
+func (h *Handler) listStorageBackups(w http.ResponseWriter, _ *http.Request) {
+	if h.backupDir == "" {
+		writeError(w, http.StatusServiceUnavailable, "BACKUP_UNAVAILABLE", "backup directory is not configured", nil)
+		return
+	}
+	backups, err := store.ListBackups(h.backupDir)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "BACKUP_FAILED", "failed to list backups", nil)
+		return
+	}
+	if backups == nil {
+		backups = []store.BackupInfo{}
+	}
+	writeData(w, http.StatusOK, map[string]any{"backups": backups})
+}
+
 func (h *Handler) patchTimezone(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Timezone string `json:"timezone"`
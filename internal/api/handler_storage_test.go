@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -71,3 +73,62 @@ func TestStorageStatsAndFlushActivityJournal(t *testing.T) {
 		t.Fatalf("remaining journal rows = %d, want 0", len(remaining))
 	}
 }
+
+func TestBackupStorage(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+
+	w := httptest.NewRecorder()
+	h.backupStorage(w, httptest.NewRequest(http.MethodPost, "/api/ops/storage/backup", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("backupStorage without dir status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	dir := filepath.Join(t.TempDir(), "backups")
+	h.SetBackupOptions(dir, 3)
+
+	w = httptest.NewRecorder()
+	h.backupStorage(w, httptest.NewRequest(http.MethodPost, "/api/ops/storage/backup", nil))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("backupStorage status = %d, want %d; body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	backup := jsonBody(t, w)["data"].(map[string]any)["backup"].(map[string]any)
+	name, _ := backup["name"].(string)
+	if !strings.HasPrefix(name, "sentinel-") {
+		t.Fatalf("backup name = %q", name)
+	}
+	if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+		t.Fatalf("stat backup: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	h.listStorageBackups(w, httptest.NewRequest(http.MethodGet, "/api/ops/storage/backups", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("listStorageBackups status = %d; body=%s", w.Code, w.Body.String())
+	}
+	backups := jsonBody(t, w)["data"].(map[string]any)["backups"].([]any)
+	if len(backups) != 1 || backups[0].(map[string]any)["name"] != name {
+		t.Fatalf("backups = %+v, want [%s]", backups, name)
+	}
+}
+
+func TestMaintainStorage(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+
+	w := httptest.NewRecorder()
+	h.maintainStorage(w, httptest.NewRequest(http.MethodPost, "/api/ops/storage/maintain", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("maintainStorage status = %d; body=%s", w.Code, w.Body.String())
+	}
+	report := jsonBody(t, w)["data"].(map[string]any)["report"].(map[string]any)
+	if report["integrityOk"] != true {
+		t.Fatalf("report = %+v, want integrityOk", report)
+	}
+	steps := report["steps"].([]any)
+	if len(steps) != 4 || steps[0].(map[string]any)["name"] != "integrity_check" || steps[2].(map[string]any)["name"] != "vacuum" {
+		t.Fatalf("steps = %+v", steps)
+	}
+}
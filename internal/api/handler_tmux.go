@@ -66,16 +66,18 @@ func (h *Handler) createSession(w http.ResponseWriter, r *http.Request) {
 	}
 	h.registerSessionUser(finalName, req.User)
 	if req.User != "" {
-		slog.Warn("multi-user session created",
+		slog.WarnContext(r.Context(), "multi-user session created",
 			keyAction, actionSessionCreate,
 			"target_user", req.User,
 			keySession, finalName,
-			"source_ip", r.RemoteAddr,
+			"source_ip", h.guard.ClientIP(r),
 		)
 	}
 	h.persistSessionLaunchMetadataBestEffort(ctx, finalName, req.Cwd, req.Icon)
-	if err := h.repo.MoveSessionToFront(ctx, finalName); err != nil {
-		slog.Warn("failed to move session to front", keySession, finalName, "err", err)
+	if h.repo != nil {
+		if err := h.repo.MoveSessionToFront(ctx, finalName); err != nil {
+			slog.WarnContext(r.Context(), "failed to move session to front", keySession, finalName, "err", err)
+		}
 	}
 	payload := map[string]any{
 		keySession: finalName,
@@ -221,7 +223,7 @@ func (h *Handler) populateSessionUsersFromPresets(ctx context.Context) {
 	// Also load pinned session presets, which may have user overrides.
 	presets, err := h.repo.ListSessionPresets(ctx)
 	if err != nil {
-		slog.Warn("failed to load pinned session presets for user registry", "err", err)
+		slog.WarnContext(ctx, "failed to load pinned session presets for user registry", "err", err)
 		return
 	}
 	for _, preset := range presets {
@@ -267,8 +269,10 @@ func (h *Handler) renameSession(w http.ResponseWriter, r *http.Request) {
 			_ = h.repo.RenameSessionUser(context.Background(), session, req.NewName)
 		}
 	}
-	if err := h.repo.Rename(ctx, session, req.NewName); err != nil {
-		slog.Warn("store.Rename failed", "from", session, "to", req.NewName, "err", err)
+	if h.repo != nil {
+		if err := h.repo.Rename(ctx, session, req.NewName); err != nil {
+			slog.WarnContext(r.Context(), "store.Rename failed", "from", session, "to", req.NewName, "err", err)
+		}
 	}
 	h.renameSessionPresetBestEffort(ctx, session, req.NewName)
 	h.emit(events.TypeTmuxSessions, map[string]any{
@@ -323,19 +327,28 @@ func (h *Handler) deleteSession(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
+	if err := h.killSession(ctx, session); err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	h.emit(events.TypeTmuxSessions, map[string]any{keySession: session, keyAction: "delete"})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// killSession kills a tmux session and forgets its user and preset. A
+// session that is already gone is not an error.
+func (h *Handler) killSession(ctx context.Context, session string) error {
 	if err := h.tmuxForSession(ctx, session).KillSession(ctx, session); err != nil &&
 		!tmux.IsKind(err, tmux.ErrKindSessionNotFound) &&
 		!tmux.IsKind(err, tmux.ErrKindServerNotRunning) {
-		writeTmuxError(w, err)
-		return
+		return err
 	}
 	h.sessionUsers.Delete(session)
 	if h.repo != nil {
 		_ = h.repo.DeleteSessionUser(context.Background(), session)
 		_ = h.repo.DeleteSessionPreset(context.Background(), session)
 	}
-	h.emit(events.TypeTmuxSessions, map[string]any{keySession: session, keyAction: "delete"})
-	w.WriteHeader(http.StatusNoContent)
+	return nil
 }
 
 func (h *Handler) frequentDirectories(w http.ResponseWriter, r *http.Request) {
@@ -354,7 +367,7 @@ func (h *Handler) frequentDirectories(w http.ResponseWriter, r *http.Request) {
 
 	dirs, err := h.repo.ListFrequentDirectories(ctx, limit)
 	if err != nil {
-		slog.Warn("failed to list frequent directories", "err", err)
+		slog.WarnContext(r.Context(), "failed to list frequent directories", "err", err)
 		writeData(w, http.StatusOK, map[string]any{keyDirs: []string{}})
 		return
 	}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/tmux"
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+// maxBufferBytes bounds the content set into a paste buffer. tmux receives
+// it as a single command argument.
+const maxBufferBytes = 64 * 1024
+
+// bufferServer returns the tmux service whose paste buffers a request
+// addresses. Buffers belong to a tmux server, not a session; the optional
+// session query parameter selects the server of a multi-user session.
+func (h *Handler) bufferServer(ctx context.Context, r *http.Request) (tmuxService, bool) {
+	session := strings.TrimSpace(r.URL.Query().Get(keySession))
+	if session == "" {
+		return h.tmux, true
+	}
+	if !validate.SessionName(session) {
+		return nil, false
+	}
+	return h.tmuxForSession(ctx, session), true
+}
+
+func bufferNameFromPath(r *http.Request) (string, bool) {
+	name := strings.TrimSpace(r.PathValue("buffer"))
+	return name, validate.BufferName(name)
+}
+
+func (h *Handler) listBuffers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	svc, ok := h.bufferServer(ctx, r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+	buffers, err := svc.ListBuffers(ctx)
+	if err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"buffers": buffers})
+}
+
+func (h *Handler) getBuffer(w http.ResponseWriter, r *http.Request) {
+	name, ok := bufferNameFromPath(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid buffer name", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	svc, ok := h.bufferServer(ctx, r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+	content, err := svc.ShowBuffer(ctx, name)
+	if err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{
+		"name":    name,
+		"content": content,
+	})
+}
+
+// setBuffer creates or replaces a paste buffer, e.g. with text copied in the
+// browser, so native tmux clients can paste it.
+func (h *Handler) setBuffer(w http.ResponseWriter, r *http.Request) {
+	name, ok := bufferNameFromPath(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid buffer name", nil)
+		return
+	}
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if req.Content == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "content is required", nil)
+		return
+	}
+	if len(req.Content) > maxBufferBytes {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "content is too large", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	svc, ok := h.bufferServer(ctx, r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+	if err := svc.SetBuffer(ctx, name, req.Content); err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pasteBuffer pastes a buffer into a pane of the session's tmux server.
+func (h *Handler) pasteBuffer(w http.ResponseWriter, r *http.Request) {
+	name, ok := bufferNameFromPath(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid buffer name", nil)
+		return
+	}
+	var req struct {
+		Session string `json:"session"`
+		PaneID  string `json:"paneId"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	session := strings.TrimSpace(req.Session)
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+	paneID := strings.TrimSpace(req.PaneID)
+	if !strings.HasPrefix(paneID, "%") {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "paneId must start with %", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.ensureSessionPane(ctx, session, paneID); err != nil {
+		if tmux.IsKind(err, tmux.ErrKindSessionNotFound) {
+			writeTmuxError(w, err)
+			return
+		}
+		writeError(w, http.StatusNotFound, "PANE_NOT_FOUND", "pane does not belong to session", nil)
+		return
+	}
+	if err := h.tmuxForSession(ctx, session).PasteBuffer(ctx, name, paneID); err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestListAndGetBuffers(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 6, 2, 12, 0, 0, 0, time.UTC)
+	h, _ := newTestHandler(t, &mockTmux{
+		listBuffersFn: func(context.Context) ([]tmux.Buffer, error) {
+			return []tmux.Buffer{{Name: "buffer0001", Size: 5, CreatedAt: created, Sample: "hello"}}, nil
+		},
+		showBufferFn: func(_ context.Context, name string) (string, error) {
+			if name != "buffer0001" {
+				return "", &tmux.Error{Kind: tmux.ErrKindBufferNotFound}
+			}
+			return "hello\n", nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.listBuffers(w, httptest.NewRequest(http.MethodGet, "/api/tmux/buffers", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("listBuffers status = %d; body=%s", w.Code, w.Body.String())
+	}
+	buffers := jsonBody(t, w)["data"].(map[string]any)["buffers"].([]any)
+	if len(buffers) != 1 || buffers[0].(map[string]any)["name"] != "buffer0001" {
+		t.Fatalf("buffers = %+v", buffers)
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/tmux/buffers/buffer0001", nil)
+	r.SetPathValue("buffer", "buffer0001")
+	h.getBuffer(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("getBuffer status = %d; body=%s", w.Code, w.Body.String())
+	}
+	if got := jsonBody(t, w)["data"].(map[string]any)["content"]; got != "hello\n" {
+		t.Fatalf("content = %q, want hello", got)
+	}
+
+	for _, tc := range []struct {
+		buffer, query string
+		want          int
+	}{
+		{buffer: "missing", want: http.StatusNotFound},
+		{buffer: "-b", want: http.StatusBadRequest},
+		{buffer: "buffer0001", query: "?session=bad%20name", want: http.StatusBadRequest},
+	} {
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodGet, "/api/tmux/buffers/x"+tc.query, nil)
+		r.SetPathValue("buffer", tc.buffer)
+		h.getBuffer(w, r)
+		if w.Code != tc.want {
+			t.Fatalf("getBuffer(%q%s) status = %d, want %d", tc.buffer, tc.query, w.Code, tc.want)
+		}
+	}
+}
+
+func TestSetBuffer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "multi-line content", body: `{"content":"line 1\nline 2"}`, wantCode: http.StatusNoContent},
+		{name: "empty content", body: `{"content":""}`, wantCode: http.StatusBadRequest},
+		{name: "too large", body: `{"content":"` + strings.Repeat("a", maxBufferBytes+1) + `"}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotName, gotContent string
+			h, _ := newTestHandler(t, &mockTmux{
+				setBufferFn: func(_ context.Context, name, content string) error {
+					gotName, gotContent = name, content
+					return nil
+				},
+			})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/api/tmux/buffers/clip", strings.NewReader(tt.body))
+			r.SetPathValue("buffer", "clip")
+			h.setBuffer(w, r)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode == http.StatusNoContent && (gotName != "clip" || gotContent != "line 1\nline 2") {
+				t.Fatalf("SetBuffer(%q, %q)", gotName, gotContent)
+			}
+		})
+	}
+}
+
+func TestPasteBuffer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "pane in session", body: `{"session":"dev","paneId":"%3"}`, wantCode: http.StatusNoContent},
+		{name: "pane outside session", body: `{"session":"dev","paneId":"%9"}`, wantCode: http.StatusNotFound},
+		{name: "invalid pane", body: `{"session":"dev","paneId":"3"}`, wantCode: http.StatusBadRequest},
+		{name: "invalid session", body: `{"session":"-x","paneId":"%3"}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var pasted []string
+			h, _ := newTestHandler(t, &mockTmux{
+				listPanesFn: func(context.Context, string) ([]tmux.Pane, error) {
+					return []tmux.Pane{{Session: "dev", PaneID: "%3"}}, nil
+				},
+				pasteBufferFn: func(_ context.Context, name, paneID string) error {
+					pasted = append(pasted, name, paneID)
+					return nil
+				},
+			})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/tmux/buffers/clip/paste", strings.NewReader(tt.body))
+			r.SetPathValue("buffer", "clip")
+			h.pasteBuffer(w, r)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+			wantPasted := 0
+			if tt.wantCode == http.StatusNoContent {
+				wantPasted = 2
+			}
+			if len(pasted) != wantPasted || (wantPasted > 0 && (pasted[0] != "clip" || pasted[1] != "%3")) {
+				t.Fatalf("PasteBuffer calls = %q", pasted)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+const (
+	bulkActionKill     = "kill"
+	bulkActionMarkSeen = "mark-seen"
+
+	// maxBulkSessionOperations bounds one bulk request so it fits the
+	// request timeout.
+	maxBulkSessionOperations = 100
+)
+
+type bulkSessionOperation struct {
+	Action  string `json:"action"`
+	Session string `json:"session"`
+}
+
+type bulkSessionResult struct {
+	Action  string `json:"action"`
+	Session string `json:"session"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+}
+
+// bulkSessions applies kill and mark-seen operations to many sessions in one
+// request. The whole batch is validated before anything runs; each operation
+// then reports its own result, and a single tmux.sessions event announces the
+// change.
+func (h *Handler) bulkSessions(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Operations []bulkSessionOperation `json:"operations"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	ops, needsAdmin, err := normalizeBulkSessionOperations(req.Operations)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if needsAdmin {
+		if id, _ := security.IdentityFromContext(r.Context()); !id.Role.Allows(security.RoleAdmin) {
+			writeError(w, http.StatusForbidden, "FORBIDDEN", "role does not allow this action", map[string]any{
+				"role":         id.Role,
+				"requiredRole": security.RoleAdmin,
+			})
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	results := make([]bulkSessionResult, 0, len(ops))
+	sessions := make([]string, 0, len(ops))
+	for _, op := range ops {
+		result := bulkSessionResult{Action: op.Action, Session: op.Session, OK: true}
+		if err := h.applyBulkSessionOperation(ctx, op); err != nil {
+			result.OK = false
+			result.Error = err.Error()
+		} else {
+			sessions = append(sessions, op.Session)
+		}
+		results = append(results, result)
+	}
+	if len(sessions) > 0 {
+		h.emit(events.TypeTmuxSessions, map[string]any{
+			keyAction:  "bulk",
+			"sessions": sessions,
+		})
+	}
+	writeData(w, http.StatusOK, map[string]any{"results": results})
+}
+
+func normalizeBulkSessionOperations(raw []bulkSessionOperation) ([]bulkSessionOperation, bool, error) {
+	if len(raw) == 0 {
+		return nil, false, errors.New("operations is required")
+	}
+	if len(raw) > maxBulkSessionOperations {
+		return nil, false, fmt.Errorf("at most %d operations are allowed", maxBulkSessionOperations)
+	}
+	ops := make([]bulkSessionOperation, 0, len(raw))
+	needsAdmin := false
+	for index, op := range raw {
+		op.Action = strings.TrimSpace(strings.ToLower(op.Action))
+		op.Session = strings.TrimSpace(op.Session)
+		switch op.Action {
+		case bulkActionKill:
+			needsAdmin = true
+		case bulkActionMarkSeen:
+		default:
+			return nil, false, fmt.Errorf("operation %d: action must be kill or mark-seen", index)
+		}
+		if !validate.SessionName(op.Session) {
+			return nil, false, fmt.Errorf("operation %d: invalid session name", index)
+		}
+		ops = append(ops, op)
+	}
+	return ops, needsAdmin, nil
+}
+
+func (h *Handler) applyBulkSessionOperation(ctx context.Context, op bulkSessionOperation) error {
+	switch op.Action {
+	case bulkActionKill:
+		return h.killSession(ctx, op.Session)
+	default:
+		if h.repo == nil {
+			return errors.New("store is unavailable")
+		}
+		_, err := h.repo.MarkWatchtowerSessionSeen(ctx, op.Session)
+		return err
+	}
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func bulkSessionsRequest(t *testing.T, h *Handler, role security.Role, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/bulk", strings.NewReader(body))
+	r = r.WithContext(security.WithIdentity(r.Context(), security.Identity{Name: "test", Role: role}))
+	h.bulkSessions(w, r)
+	return w
+}
+
+func TestBulkSessions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		killed []string
+	)
+	h, st := newTestHandler(t, &mockTmux{
+		killSessionFn: func(_ context.Context, session string) error {
+			if session == "broken" {
+				return &tmux.Error{Kind: tmux.ErrKindCommandFailed, Msg: "boom"}
+			}
+			mu.Lock()
+			killed = append(killed, session)
+			mu.Unlock()
+			return nil
+		},
+	})
+	hub := events.NewHub()
+	eventsCh, unsubscribe := hub.Subscribe(8)
+	defer unsubscribe()
+	h.events = hub
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	if err := st.UpsertWatchtowerSession(ctx, store.WatchtowerSessionWrite{SessionName: "dev", UpdatedAt: now}); err != nil {
+		t.Fatalf("UpsertWatchtowerSession: %v", err)
+	}
+	if err := st.UpsertWatchtowerPane(ctx, store.WatchtowerPaneWrite{
+		PaneID: "%1", SessionName: "dev", Revision: 3, SeenRevision: 1, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("UpsertWatchtowerPane: %v", err)
+	}
+
+	w := bulkSessionsRequest(t, h, security.RoleAdmin, `{"operations":[
+		{"action":"kill","session":"stale-1"},
+		{"action":"KILL","session":"broken"},
+		{"action":"mark-seen","session":"dev"}
+	]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			Results []bulkSessionResult `json:"results"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	results := resp.Data.Results
+	if len(results) != 3 || !results[0].OK || results[1].OK || results[1].Error == "" || !results[2].OK {
+		t.Fatalf("results = %+v", results)
+	}
+	if !slices.Equal(killed, []string{"stale-1"}) {
+		t.Fatalf("killed = %v, want [stale-1]", killed)
+	}
+	panes, err := st.ListWatchtowerPanes(ctx, "dev")
+	if err != nil {
+		t.Fatalf("ListWatchtowerPanes: %v", err)
+	}
+	if len(panes) != 1 || panes[0].SeenRevision != 3 {
+		t.Fatalf("panes = %+v, want seen revision 3", panes)
+	}
+
+	select {
+	case event := <-eventsCh:
+		if event.Type != events.TypeTmuxSessions || event.Payload[keyAction] != "bulk" {
+			t.Fatalf("event = %+v, want bulk tmux.sessions event", event)
+		}
+		sessions, _ := event.Payload["sessions"].([]string)
+		if !slices.Equal(sessions, []string{"stale-1", "dev"}) {
+			t.Fatalf("event sessions = %v", event.Payload["sessions"])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for bulk event")
+	}
+	select {
+	case event := <-eventsCh:
+		t.Fatalf("unexpected extra event %+v", event)
+	default:
+	}
+}
+
+func TestBulkSessionsValidatesWholeBatch(t *testing.T) {
+	t.Parallel()
+
+	killCalls := 0
+	h, _ := newTestHandler(t, &mockTmux{
+		killSessionFn: func(context.Context, string) error {
+			killCalls++
+			return nil
+		},
+	})
+
+	tests := []struct {
+		name string
+		role security.Role
+		body string
+		want int
+	}{
+		{name: "empty", role: security.RoleAdmin, body: `{"operations":[]}`, want: http.StatusBadRequest},
+		{name: "unknown action", role: security.RoleAdmin, body: `{"operations":[{"action":"kill","session":"a"},{"action":"archive","session":"b"}]}`, want: http.StatusBadRequest},
+		{name: "invalid session", role: security.RoleAdmin, body: `{"operations":[{"action":"kill","session":"a"},{"action":"kill","session":"bad name"}]}`, want: http.StatusBadRequest},
+		{name: "kill needs admin", role: security.RoleOperator, body: `{"operations":[{"action":"mark-seen","session":"a"},{"action":"kill","session":"b"}]}`, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if w := bulkSessionsRequest(t, h, tt.role, tt.body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d; body=%s", tt.name, w.Code, tt.want, w.Body.String())
+		}
+	}
+	if killCalls != 0 {
+		t.Fatalf("kill called %d times for rejected batches", killCalls)
+	}
+
+	if w := bulkSessionsRequest(t, h, security.RoleOperator, `{"operations":[{"action":"mark-seen","session":"a"}]}`); w.Code != http.StatusOK {
+		t.Fatalf("operator mark-seen status = %d, want 200", w.Code)
+	}
+}
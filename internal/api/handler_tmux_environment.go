@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+// maxEnvValueBytes bounds a session environment value.
+const maxEnvValueBytes = 32 * 1024
+
+// protectedEnvNames are variables the API refuses to change: tmux relies on
+// the first two, and the loader variables would inject code into every new
+// process of the session.
+var protectedEnvNames = map[string]bool{
+	"TMUX":      true,
+	"TMUX_PANE": true,
+	"BASH_ENV":  true,
+	"ENV":       true,
+}
+
+var protectedEnvPrefixes = []string{"LD_", "DYLD_"}
+
+// sensitiveEnvMarkers flag variables whose values are never returned.
+var sensitiveEnvMarkers = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "PASSWD", "CREDENTIAL", "PRIVATE"}
+
+func envNameProtected(name string) bool {
+	upper := strings.ToUpper(name)
+	if protectedEnvNames[upper] {
+		return true
+	}
+	for _, prefix := range protectedEnvPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func envNameSensitive(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range sensitiveEnvMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+type sessionEnvVar struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Removed   bool   `json:"removed"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// sessionEnvironment lists a session's tmux environment. Values of
+// sensitive-looking names are blanked so the list can be shown on screen.
+func (h *Handler) sessionEnvironment(w http.ResponseWriter, r *http.Request) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	vars, err := h.tmuxForSession(ctx, session).ShowEnvironment(ctx, session)
+	if err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	out := make([]sessionEnvVar, 0, len(vars))
+	for _, v := range vars {
+		entry := sessionEnvVar{Name: v.Name, Value: v.Value, Removed: v.Removed, Sensitive: envNameSensitive(v.Name)}
+		if entry.Sensitive {
+			entry.Value = ""
+		}
+		out = append(out, entry)
+	}
+	writeData(w, http.StatusOK, map[string]any{
+		keySession:  session,
+		"variables": out,
+	})
+}
+
+// sessionEnvTarget validates the session and variable name of an
+// environment mutation, writing the error response when they are rejected.
+func sessionEnvTarget(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return "", "", false
+	}
+	name := strings.TrimSpace(r.PathValue("name"))
+	if !validate.EnvName(name) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid variable name", nil)
+		return "", "", false
+	}
+	if envNameProtected(name) {
+		writeError(w, http.StatusForbidden, "ENV_PROTECTED", "variable cannot be changed through the API", nil)
+		return "", "", false
+	}
+	return session, name, true
+}
+
+// setSessionEnvironment sets a variable in the session environment. Only
+// processes started afterwards, e.g. new windows and panes, see it.
+func (h *Handler) setSessionEnvironment(w http.ResponseWriter, r *http.Request) {
+	session, name, ok := sessionEnvTarget(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Value string `json:"value"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if len(req.Value) > maxEnvValueBytes {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "value is too large", nil)
+		return
+	}
+	if strings.ContainsRune(req.Value, 0) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "value must not contain NUL", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.tmuxForSession(ctx, session).SetEnvironment(ctx, session, name, req.Value); err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unsetSessionEnvironment removes a variable from the session environment,
+// so new processes inherit the global value again.
+func (h *Handler) unsetSessionEnvironment(w http.ResponseWriter, r *http.Request) {
+	session, name, ok := sessionEnvTarget(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.tmuxForSession(ctx, session).UnsetEnvironment(ctx, session, name); err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestSessionEnvironmentMasksSensitiveValues(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, &mockTmux{
+		showEnvironmentFn: func(_ context.Context, session string) ([]tmux.EnvVar, error) {
+			if session != "dev" {
+				return nil, &tmux.Error{Kind: tmux.ErrKindSessionNotFound}
+			}
+			return []tmux.EnvVar{
+				{Name: "EDITOR", Value: "vim"},
+				{Name: "OPENAI_API_KEY", Value: "sk-live"},
+				{Name: "DISPLAY", Removed: true},
+			}, nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/tmux/sessions/dev/environment", nil)
+	r.SetPathValue("session", "dev")
+	h.sessionEnvironment(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; body=%s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "sk-live") {
+		t.Fatalf("sensitive value leaked: %s", w.Body.String())
+	}
+	vars := jsonBody(t, w)["data"].(map[string]any)["variables"].([]any)
+	if len(vars) != 3 {
+		t.Fatalf("variables = %+v", vars)
+	}
+	editor, key, display := vars[0].(map[string]any), vars[1].(map[string]any), vars[2].(map[string]any)
+	if editor["value"] != "vim" || editor["sensitive"] != false {
+		t.Fatalf("EDITOR = %+v", editor)
+	}
+	if key["value"] != "" || key["sensitive"] != true {
+		t.Fatalf("OPENAI_API_KEY = %+v, want masked", key)
+	}
+	if display["removed"] != true {
+		t.Fatalf("DISPLAY = %+v, want removed", display)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/api/tmux/sessions/ops/environment", nil)
+	r.SetPathValue("session", "ops")
+	h.sessionEnvironment(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("missing session status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestSetAndUnsetSessionEnvironment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		method   string
+		variable string
+		body     string
+		wantCode int
+		wantCall string
+	}{
+		{name: "set", method: http.MethodPut, variable: "API_KEY", body: `{"value":"rotated"}`, wantCode: http.StatusNoContent, wantCall: "set dev API_KEY=rotated"},
+		{name: "set empty value", method: http.MethodPut, variable: "FLAG", body: `{"value":""}`, wantCode: http.StatusNoContent, wantCall: "set dev FLAG="},
+		{name: "unset", method: http.MethodDelete, variable: "API_KEY", wantCode: http.StatusNoContent, wantCall: "unset dev API_KEY"},
+		{name: "invalid name", method: http.MethodPut, variable: "A-B", body: `{"value":"x"}`, wantCode: http.StatusBadRequest},
+		{name: "protected tmux", method: http.MethodDelete, variable: "TMUX", wantCode: http.StatusForbidden},
+		{name: "protected loader", method: http.MethodPut, variable: "LD_PRELOAD", body: `{"value":"/tmp/x.so"}`, wantCode: http.StatusForbidden},
+		{name: "too large", method: http.MethodPut, variable: "BIG", body: `{"value":"` + strings.Repeat("a", maxEnvValueBytes+1) + `"}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var call string
+			h, _ := newTestHandler(t, &mockTmux{
+				setEnvironmentFn: func(_ context.Context, session, name, value string) error {
+					call = "set " + session + " " + name + "=" + value
+					return nil
+				},
+				unsetEnvironmentFn: func(_ context.Context, session, name string) error {
+					call = "unset " + session + " " + name
+					return nil
+				},
+			})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, "/api/tmux/sessions/dev/environment/"+tt.variable, strings.NewReader(tt.body))
+			r.SetPathValue("session", "dev")
+			r.SetPathValue("name", tt.variable)
+			if tt.method == http.MethodPut {
+				h.setSessionEnvironment(w, r)
+			} else {
+				h.unsetSessionEnvironment(w, r)
+			}
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if call != tt.wantCall {
+				t.Fatalf("call = %q, want %q", call, tt.wantCall)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+// maxSessionNotesBytes bounds the markdown notes stored on one session.
+const maxSessionNotesBytes = 8 << 10
+
+func (h *Handler) setSessionNotes(w http.ResponseWriter, r *http.Request) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+
+	var req struct {
+		Notes string `json:"notes"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	notes := strings.TrimSpace(req.Notes)
+	if len(notes) > maxSessionNotesBytes {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST",
+			fmt.Sprintf("notes must be at most %d bytes", maxSessionNotesBytes), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.repo.SetSessionNotes(ctx, session, notes); err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to set notes", nil)
+		return
+	}
+	h.emit(events.TypeTmuxSessions, map[string]any{
+		keySession: session,
+		keyAction:  "notes",
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
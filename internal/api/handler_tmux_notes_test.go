@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestSetSessionNotes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tm := &mockTmux{
+		listSessionsFn: func(context.Context) ([]tmux.Session, error) {
+			return []tmux.Session{{Name: "dev", Windows: 1, CreatedAt: now, ActivityAt: now}}, nil
+		},
+	}
+	h, _ := newTestHandler(t, tm)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPut, "/api/tmux/sessions/dev/notes",
+		strings.NewReader(`{"notes":"Runs the data migration, **do not kill**.\n"}`))
+	r.SetPathValue("session", "dev")
+	h.setSessionNotes(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204; body=%s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.listSessions(w, httptest.NewRequest(http.MethodGet, "/api/tmux/sessions", nil))
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	sessions, _ := data["sessions"].([]any)
+	if len(sessions) != 1 {
+		t.Fatalf("sessions len = %d, want 1", len(sessions))
+	}
+	if got := sessions[0].(map[string]any)["notes"]; got != "Runs the data migration, **do not kill**." {
+		t.Fatalf("notes = %v", got)
+	}
+
+	for _, body := range []string{
+		`{"notes":"` + strings.Repeat("a", maxSessionNotesBytes+1) + `"}`,
+		`{`,
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/api/tmux/sessions/dev/notes", strings.NewReader(body))
+		r.SetPathValue("session", "dev")
+		h.setSessionNotes(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400", w.Code)
+		}
+	}
+}
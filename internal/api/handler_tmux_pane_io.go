@@ -0,0 +1,352 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/panelog"
+	"github.com/opus-domini/sentinel/internal/proc"
+	"github.com/opus-domini/sentinel/internal/tmux"
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+const (
+	// maxSendKeysBytes bounds a single send-keys payload. Larger inputs
+	// belong in a script or a terminal attachment.
+	maxSendKeysBytes = 16 * 1024
+
+	defaultCaptureLines = 200
+	maxCaptureLines     = 5000
+
+	defaultPaneHistoryLimit = 200
+)
+
+// paneLogSearcher searches archived pane output.
+type paneLogSearcher interface {
+	Search(session, paneID, query string, limit int) (panelog.SearchResult, error)
+}
+
+// processController inspects and signals processes running inside panes.
+type processController interface {
+	Table(ctx context.Context) (proc.Table, error)
+	Processes(ctx context.Context) ([]proc.Process, error)
+	Signal(pid int, sig syscall.Signal) error
+}
+
+// SetPaneLog enables the pane history endpoint backed by the given archive.
+func (h *Handler) SetPaneLog(archive paneLogSearcher) {
+	if h == nil {
+		return
+	}
+	h.paneLog = archive
+}
+
+// paneIDFromPath accepts a pane ID from the URL with or without the leading
+// "%", since a literal "%" must be escaped as "%25" in paths.
+func paneIDFromPath(r *http.Request) (string, bool) {
+	raw := strings.TrimSpace(r.PathValue("pane"))
+	if raw == "" {
+		return "", false
+	}
+	if !strings.HasPrefix(raw, "%") {
+		raw = "%" + raw
+	}
+	for _, ch := range raw[1:] {
+		if ch < '0' || ch > '9' {
+			return "", false
+		}
+	}
+	return raw, len(raw) > 1
+}
+
+func (h *Handler) sendPaneKeys(w http.ResponseWriter, r *http.Request) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+	paneID, ok := paneIDFromPath(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid pane id", nil)
+		return
+	}
+
+	var req struct {
+		Keys  string `json:"keys"`
+		Enter bool   `json:"enter"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if strings.TrimSpace(req.Keys) == "" && !req.Enter {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "keys or enter is required", nil)
+		return
+	}
+	if len(req.Keys) > maxSendKeysBytes {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "keys payload is too large", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.ensureSessionPane(ctx, session, paneID); err != nil {
+		if tmux.IsKind(err, tmux.ErrKindSessionNotFound) {
+			writeTmuxError(w, err)
+			return
+		}
+		writeError(w, http.StatusNotFound, "PANE_NOT_FOUND", "pane does not belong to session", nil)
+		return
+	}
+	if err := h.tmuxForSession(ctx, session).SendKeys(ctx, paneID, req.Keys, req.Enter); err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// zoomPane toggles a pane's zoom and reports the resulting state.
+func (h *Handler) zoomPane(w http.ResponseWriter, r *http.Request) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+	paneID, ok := paneIDFromPath(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid pane id", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.ensureSessionPane(ctx, session, paneID); err != nil {
+		if tmux.IsKind(err, tmux.ErrKindSessionNotFound) {
+			writeTmuxError(w, err)
+			return
+		}
+		writeError(w, http.StatusNotFound, "PANE_NOT_FOUND", "pane does not belong to session", nil)
+		return
+	}
+	svc := h.tmuxForSession(ctx, session)
+	if err := svc.ZoomPane(ctx, paneID); err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	panes, err := svc.ListPanes(ctx, session)
+	if err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	zoomed := false
+	for _, pane := range panes {
+		if pane.PaneID == paneID {
+			zoomed = pane.Zoomed
+			break
+		}
+	}
+	h.emit(events.TypeTmuxInspector, map[string]any{
+		keySession: session,
+		keyAction:  "zoom-pane",
+		keyPaneID:  paneID,
+		"zoomed":   zoomed,
+	})
+	writeData(w, http.StatusOK, map[string]any{
+		keyPaneID: paneID,
+		"zoomed":  zoomed,
+	})
+}
+
+// signalPane sends a signal to a process running inside a pane without
+// touching the pane itself. The target must descend from the pane process,
+// so the pane shell and unrelated processes cannot be signalled.
+func (h *Handler) signalPane(w http.ResponseWriter, r *http.Request) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+	paneID, ok := paneIDFromPath(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid pane id", nil)
+		return
+	}
+	var req struct {
+		Signal string `json:"signal"`
+		PID    int    `json:"pid"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	sig, err := proc.ParseSignal(req.Signal)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	if req.PID <= 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "pid must be > 0", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.ensureSessionPane(ctx, session, paneID); err != nil {
+		if tmux.IsKind(err, tmux.ErrKindSessionNotFound) {
+			writeTmuxError(w, err)
+			return
+		}
+		writeError(w, http.StatusNotFound, "PANE_NOT_FOUND", "pane does not belong to session", nil)
+		return
+	}
+	panePID, err := h.tmuxForSession(ctx, session).PanePID(ctx, paneID)
+	if err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	table, err := h.procs.Table(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "PROCESS_LIST_FAILED", "failed to list processes", nil)
+		return
+	}
+	if !table.IsDescendant(panePID, req.PID) {
+		writeError(w, http.StatusForbidden, "PROCESS_NOT_IN_PANE", "pid is not a descendant of the pane process", nil)
+		return
+	}
+	if err := h.procs.Signal(req.PID, sig); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			writeError(w, http.StatusNotFound, "PROCESS_NOT_FOUND", "process has exited", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "SIGNAL_FAILED", err.Error(), nil)
+		return
+	}
+
+	signalName := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(req.Signal)), "SIG")
+	h.emit(events.TypeTmuxInspector, map[string]any{
+		keySession: session,
+		keyAction:  "signal-pane",
+		keyPaneID:  paneID,
+		"pid":      req.PID,
+		"signal":   signalName,
+	})
+	writeData(w, http.StatusOK, map[string]any{
+		keyPaneID: paneID,
+		"pid":     req.PID,
+		"signal":  signalName,
+	})
+}
+
+func (h *Handler) capturePaneRange(w http.ResponseWriter, r *http.Request) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+	paneID, ok := paneIDFromPath(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid pane id", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	opts := tmux.CaptureRangeOptions{
+		Start:    -defaultCaptureLines,
+		End:      math.MaxInt32,
+		MaxLines: maxCaptureLines,
+	}
+	if raw := strings.TrimSpace(query.Get("start")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "start must be an integer", nil)
+			return
+		}
+		opts.Start = parsed
+	}
+	if raw := strings.TrimSpace(query.Get("end")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "end must be an integer", nil)
+			return
+		}
+		opts.End = parsed
+	}
+	if opts.Start > opts.End {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "start must not be after end", nil)
+		return
+	}
+	if raw := strings.TrimSpace(query.Get("escapes")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "escapes must be a boolean", nil)
+			return
+		}
+		opts.Escapes = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.ensureSessionPane(ctx, session, paneID); err != nil {
+		if tmux.IsKind(err, tmux.ErrKindSessionNotFound) {
+			writeTmuxError(w, err)
+			return
+		}
+		writeError(w, http.StatusNotFound, "PANE_NOT_FOUND", "pane does not belong to session", nil)
+		return
+	}
+	capture, err := h.tmuxForSession(ctx, session).CapturePaneRange(ctx, paneID, opts)
+	if err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"capture": capture})
+}
+
+// paneHistory searches the archived output of a pane. Closed panes stay
+// searchable until their logs expire, so the pane is not checked against
+// the live session.
+func (h *Handler) paneHistory(w http.ResponseWriter, r *http.Request) {
+	if h.paneLog == nil {
+		writeError(w, http.StatusNotFound, "PANE_LOG_DISABLED", "pane logging is disabled", nil)
+		return
+	}
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+	paneID, ok := paneIDFromPath(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid pane id", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := defaultPaneHistoryLimit
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > panelog.MaxSearchResults {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "limit must be between 1 and 1000", nil)
+			return
+		}
+		limit = parsed
+	}
+
+	result, err := h.paneLog.Search(session, paneID, query.Get("q"), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to search pane history", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"history": result})
+}
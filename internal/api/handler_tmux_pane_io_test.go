@@ -0,0 +1,342 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/panelog"
+	"github.com/opus-domini/sentinel/internal/proc"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestSendPaneKeys(t *testing.T) {
+	t.Parallel()
+
+	panes := func(_ context.Context, _ string) ([]tmux.Pane, error) {
+		return []tmux.Pane{{Session: "dev", PaneID: "%3"}}, nil
+	}
+
+	tests := []struct {
+		name      string
+		pane      string
+		body      string
+		wantCode  int
+		wantKeys  string
+		wantEnter bool
+	}{
+		{name: "keys with enter", pane: "%3", body: `{"keys":"ls -la","enter":true}`, wantCode: http.StatusNoContent, wantKeys: "ls -la", wantEnter: true},
+		{name: "pane without percent", pane: "3", body: `{"keys":"q"}`, wantCode: http.StatusNoContent, wantKeys: "q"},
+		{name: "enter only", pane: "%3", body: `{"enter":true}`, wantCode: http.StatusNoContent, wantEnter: true},
+		{name: "empty input", pane: "%3", body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "invalid pane", pane: "%abc", body: `{"keys":"ls"}`, wantCode: http.StatusBadRequest},
+		{name: "pane outside session", pane: "%9", body: `{"keys":"ls"}`, wantCode: http.StatusNotFound},
+		{name: "too large", pane: "%3", body: `{"keys":"` + strings.Repeat("a", maxSendKeysBytes+1) + `"}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				sent     bool
+				gotPane  string
+				gotKeys  string
+				gotEnter bool
+			)
+			h, _ := newTestHandler(t, &mockTmux{
+				listPanesFn: panes,
+				sendKeysFn: func(_ context.Context, paneID, keys string, enter bool) error {
+					sent, gotPane, gotKeys, gotEnter = true, paneID, keys, enter
+					return nil
+				},
+			})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/panes/x/send-keys", strings.NewReader(tt.body))
+			r.SetPathValue("session", "dev")
+			r.SetPathValue("pane", tt.pane)
+			h.sendPaneKeys(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusNoContent {
+				if sent {
+					t.Fatal("SendKeys should not be called on error")
+				}
+				return
+			}
+			if gotPane != "%3" || gotKeys != tt.wantKeys || gotEnter != tt.wantEnter {
+				t.Fatalf("SendKeys(%q, %q, %v), want (%%3, %q, %v)", gotPane, gotKeys, gotEnter, tt.wantKeys, tt.wantEnter)
+			}
+		})
+	}
+}
+
+func TestZoomPane(t *testing.T) {
+	t.Parallel()
+
+	zoomed := false
+	var toggled string
+	h, _ := newTestHandler(t, &mockTmux{
+		listPanesFn: func(_ context.Context, _ string) ([]tmux.Pane, error) {
+			return []tmux.Pane{
+				{Session: "dev", PaneID: "%3", Active: true, Zoomed: zoomed},
+				{Session: "dev", PaneID: "%4"},
+			}, nil
+		},
+		zoomPaneFn: func(_ context.Context, paneID string) error {
+			toggled = paneID
+			zoomed = !zoomed
+			return nil
+		},
+	})
+
+	zoom := func(pane string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/panes/x/zoom", nil)
+		r.SetPathValue("session", "dev")
+		r.SetPathValue("pane", pane)
+		h.zoomPane(w, r)
+		return w
+	}
+
+	for _, want := range []bool{true, false} {
+		w := zoom("3")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body=%s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Data struct {
+				PaneID string `json:"paneId"`
+				Zoomed bool   `json:"zoomed"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if toggled != "%3" || resp.Data.PaneID != "%3" || resp.Data.Zoomed != want {
+			t.Fatalf("toggled=%q resp=%+v, want %%3 zoomed=%v", toggled, resp.Data, want)
+		}
+	}
+
+	if w := zoom("%9"); w.Code != http.StatusNotFound {
+		t.Fatalf("pane outside session status = %d, want 404", w.Code)
+	}
+	if w := zoom("%x"); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid pane status = %d, want 400", w.Code)
+	}
+}
+
+type mockProcs struct {
+	parents  map[int]int
+	procs    []proc.Process
+	signaled []int
+	sig      syscall.Signal
+	err      error
+}
+
+func (m *mockProcs) Table(context.Context) (proc.Table, error) {
+	return proc.Table(m.parents), nil
+}
+
+func (m *mockProcs) Processes(context.Context) ([]proc.Process, error) {
+	return m.procs, nil
+}
+
+func (m *mockProcs) Signal(pid int, sig syscall.Signal) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.signaled = append(m.signaled, pid)
+	m.sig = sig
+	return nil
+}
+
+func TestSignalPane(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, &mockTmux{
+		listPanesFn: func(_ context.Context, _ string) ([]tmux.Pane, error) {
+			return []tmux.Pane{{Session: "dev", PaneID: "%3"}}, nil
+		},
+		panePIDFn: func(_ context.Context, _ string) (int, error) { return 100, nil },
+	})
+	procs := &mockProcs{parents: map[int]int{200: 100, 300: 200, 400: 1}}
+	h.procs = procs
+
+	signal := func(pane, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/dev/panes/x/signal", strings.NewReader(body))
+		r.SetPathValue("session", "dev")
+		r.SetPathValue("pane", pane)
+		h.signalPane(w, r)
+		return w
+	}
+
+	w := signal("3", `{"signal":"sigint","pid":300}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	if len(procs.signaled) != 1 || procs.signaled[0] != 300 || procs.sig != syscall.SIGINT {
+		t.Fatalf("signaled = %v with %v, want [300] SIGINT", procs.signaled, procs.sig)
+	}
+	if data := jsonBody(t, w)["data"].(map[string]any); data["signal"] != "INT" || data["pid"] != float64(300) {
+		t.Fatalf("data = %+v", data)
+	}
+
+	cases := []struct {
+		name, pane, body string
+		want             int
+	}{
+		{"pane shell itself", "3", `{"signal":"TERM","pid":100}`, http.StatusForbidden},
+		{"unrelated process", "3", `{"signal":"TERM","pid":400}`, http.StatusForbidden},
+		{"unknown signal", "3", `{"signal":"SEGV","pid":300}`, http.StatusBadRequest},
+		{"missing pid", "3", `{"signal":"TERM"}`, http.StatusBadRequest},
+		{"pane outside session", "9", `{"signal":"TERM","pid":300}`, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		if w := signal(tc.pane, tc.body); w.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d; body=%s", tc.name, w.Code, tc.want, w.Body.String())
+		}
+	}
+	if len(procs.signaled) != 1 {
+		t.Fatalf("rejected requests signaled processes: %v", procs.signaled)
+	}
+
+	procs.err = syscall.ESRCH
+	if w := signal("3", `{"signal":"TERM","pid":300}`); w.Code != http.StatusNotFound {
+		t.Fatalf("exited process status = %d, want 404", w.Code)
+	}
+}
+
+func TestCapturePaneRange(t *testing.T) {
+	t.Parallel()
+
+	panes := func(_ context.Context, _ string) ([]tmux.Pane, error) {
+		return []tmux.Pane{{Session: "dev", PaneID: "%3"}}, nil
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		pane     string
+		wantCode int
+		wantOpts tmux.CaptureRangeOptions
+	}{
+		{name: "defaults", pane: "%3", wantCode: http.StatusOK, wantOpts: tmux.CaptureRangeOptions{Start: -defaultCaptureLines, End: math.MaxInt32, MaxLines: maxCaptureLines}},
+		{name: "explicit range with escapes", pane: "3", query: "?start=-500&end=-101&escapes=true", wantCode: http.StatusOK, wantOpts: tmux.CaptureRangeOptions{Start: -500, End: -101, Escapes: true, MaxLines: maxCaptureLines}},
+		{name: "bad start", pane: "%3", query: "?start=abc", wantCode: http.StatusBadRequest},
+		{name: "inverted range", pane: "%3", query: "?start=5&end=1", wantCode: http.StatusBadRequest},
+		{name: "bad escapes", pane: "%3", query: "?escapes=maybe", wantCode: http.StatusBadRequest},
+		{name: "unknown pane", pane: "%7", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotOpts tmux.CaptureRangeOptions
+			h, _ := newTestHandler(t, &mockTmux{
+				listPanesFn: panes,
+				capturePaneRangeFn: func(_ context.Context, paneID string, opts tmux.CaptureRangeOptions) (tmux.PaneCapture, error) {
+					gotOpts = opts
+					return tmux.PaneCapture{PaneID: paneID, Content: "hello", Start: -10, End: 23}, nil
+				},
+			})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/tmux/sessions/dev/panes/x/capture"+tt.query, nil)
+			r.SetPathValue("session", "dev")
+			r.SetPathValue("pane", tt.pane)
+			h.capturePaneRange(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if gotOpts != tt.wantOpts {
+				t.Fatalf("opts = %+v, want %+v", gotOpts, tt.wantOpts)
+			}
+			var body struct {
+				Data struct {
+					Capture tmux.PaneCapture `json:"capture"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if body.Data.Capture.PaneID != "%3" || body.Data.Capture.Content != "hello" {
+				t.Fatalf("capture = %+v", body.Data.Capture)
+			}
+		})
+	}
+}
+
+func TestPaneHistory(t *testing.T) {
+	t.Parallel()
+
+	archive := panelog.New(t.TempDir(), panelog.Options{})
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := archive.Append("dev", "%3", at, "make build\nerror: missing dep\nok"); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		pane      string
+		disabled  bool
+		wantCode  int
+		wantLines []string
+	}{
+		{name: "search", pane: "%3", query: "?q=ERROR", wantCode: http.StatusOK, wantLines: []string{"error: missing dep"}},
+		{name: "all lines with limit", pane: "3", query: "?limit=2", wantCode: http.StatusOK, wantLines: []string{"error: missing dep", "ok"}},
+		{name: "unknown pane", pane: "%9", wantCode: http.StatusOK},
+		{name: "bad limit", pane: "%3", query: "?limit=0", wantCode: http.StatusBadRequest},
+		{name: "invalid pane", pane: "%abc", wantCode: http.StatusBadRequest},
+		{name: "disabled", pane: "%3", disabled: true, wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h, _ := newTestHandler(t, &mockTmux{})
+			if !tt.disabled {
+				h.SetPaneLog(archive)
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/tmux/sessions/dev/panes/x/history"+tt.query, nil)
+			r.SetPathValue("session", "dev")
+			r.SetPathValue("pane", tt.pane)
+			h.paneHistory(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var body struct {
+				Data struct {
+					History panelog.SearchResult `json:"history"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			got := make([]string, 0, len(body.Data.History.Lines))
+			for _, line := range body.Data.History.Lines {
+				got = append(got, line.Text)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.wantLines, "|") {
+				t.Fatalf("lines = %q, want %q", got, tt.wantLines)
+			}
+		})
+	}
+}
@@ -147,17 +147,17 @@ func (h *Handler) launchSessionPreset(w http.ResponseWriter, r *http.Request) {
 		h.registerSessionUser(preset.Name, preset.User)
 	}
 	if preset.User != "" {
-		slog.Warn("multi-user session created",
+		slog.WarnContext(r.Context(), "multi-user session created",
 			keyAction, "session.preset.launch",
 			"target_user", preset.User,
 			keySession, preset.Name,
-			"source_ip", r.RemoteAddr,
+			"source_ip", h.guard.ClientIP(r),
 		)
 	}
 
 	h.persistSessionLaunchMetadataBestEffort(ctx, preset.Name, preset.Cwd, preset.Icon)
 	if err := h.repo.MarkSessionPresetLaunched(ctx, preset.Name); err != nil {
-		slog.Warn("failed to mark session preset launched", "preset", preset.Name, "err", err)
+		slog.WarnContext(r.Context(), "failed to mark session preset launched", "preset", preset.Name, "err", err)
 	}
 	h.emit(events.TypeTmuxSessions, map[string]any{
 		keySession: preset.Name,
@@ -190,7 +190,7 @@ func (h *Handler) renameSessionPresetBestEffort(ctx context.Context, oldName, ne
 	preset, err := h.findSessionPreset(ctx, oldName)
 	if err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
-			slog.Warn("failed to load session preset during rename", "preset", oldName, "err", err)
+			slog.WarnContext(ctx, "failed to load session preset during rename", "preset", oldName, "err", err)
 		}
 		return
 	}
@@ -201,7 +201,7 @@ func (h *Handler) renameSessionPresetBestEffort(ctx context.Context, oldName, ne
 		Icon: preset.Icon,
 		User: preset.User,
 	}); err != nil {
-		slog.Warn("failed to rename session preset", "from", oldName, "to", newName, "err", err)
+		slog.WarnContext(ctx, "failed to rename session preset", "from", oldName, "to", newName, "err", err)
 	}
 }
 
@@ -211,12 +211,12 @@ func (h *Handler) persistSessionLaunchMetadataBestEffort(ctx context.Context, se
 	}
 	if cwd != "" {
 		if err := h.repo.RecordSessionDirectory(ctx, cwd); err != nil {
-			slog.Warn("failed to record session directory", "cwd", cwd, "err", err)
+			slog.WarnContext(ctx, "failed to record session directory", "cwd", cwd, "err", err)
 		}
 	}
 	if icon != "" {
 		if err := h.repo.SetIcon(ctx, sessionName, icon); err != nil {
-			slog.Warn("failed to persist session icon", keySession, sessionName, "icon", icon, "err", err)
+			slog.WarnContext(ctx, "failed to persist session icon", keySession, sessionName, "icon", icon, "err", err)
 		}
 	}
 }
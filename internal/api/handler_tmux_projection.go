@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -104,6 +105,7 @@ func projectedPanesToEnriched(panes []store.WatchtowerPane) []enrichedPane {
 			PaneID:         row.PaneID,
 			Title:          row.Title,
 			Active:         row.Active,
+			Zoomed:         row.Zoomed,
 			TTY:            row.TTY,
 			CurrentPath:    row.CurrentPath,
 			StartCommand:   row.StartCommand,
@@ -125,12 +127,12 @@ func (h *Handler) listProjectedWindows(ctx context.Context, session string) ([]s
 
 	windows, err := h.repo.ListWatchtowerWindows(ctx, session)
 	if err != nil {
-		slog.Warn("store.ListWatchtowerWindows failed", keySession, session, "err", err)
+		slog.WarnContext(ctx, "store.ListWatchtowerWindows failed", keySession, session, "err", err)
 		return nil, nil, false
 	}
 	panes, err := h.repo.ListWatchtowerPanes(ctx, session)
 	if err != nil {
-		slog.Warn("store.ListWatchtowerPanes failed", keySession, session, "err", err)
+		slog.WarnContext(ctx, "store.ListWatchtowerPanes failed", keySession, session, "err", err)
 		return nil, nil, false
 	}
 	if len(windows) == 0 {
@@ -146,7 +148,7 @@ func (h *Handler) listProjectedPanes(ctx context.Context, session string) ([]sto
 
 	panes, err := h.repo.ListWatchtowerPanes(ctx, session)
 	if err != nil {
-		slog.Warn("store.ListWatchtowerPanes failed", keySession, session, "err", err)
+		slog.WarnContext(ctx, "store.ListWatchtowerPanes failed", keySession, session, "err", err)
 		return nil, false
 	}
 	if len(panes) == 0 {
@@ -180,21 +182,39 @@ func (h *Handler) ensureSessionPane(ctx context.Context, session, paneID string)
 }
 
 func (h *Handler) listSessions(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseSessionTagFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	// Usage is opt-in: it costs a process listing and changes on every
+	// poll, which would defeat ETag revalidation.
+	withUsage := false
+	if raw := strings.TrimSpace(r.URL.Query().Get("usage")); raw != "" {
+		withUsage, err = strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "usage must be a boolean", nil)
+			return
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
 	stored := h.loadSessionMetaMap(ctx)
-	if sessions, ok := h.listSessionsFromProjection(ctx, stored); ok {
-		writeData(w, http.StatusOK, map[string]any{"sessions": sessions})
-		return
+	sessions, ok := h.listSessionsFromProjection(ctx, stored)
+	if !ok {
+		sessions, err = h.listSessionsFromTmux(ctx, stored)
+		if err != nil {
+			writeTmuxError(w, err)
+			return
+		}
 	}
-
-	sessions, err := h.listSessionsFromTmux(ctx, stored)
-	if err != nil {
-		writeTmuxError(w, err)
-		return
+	sessions = filter.apply(sessions)
+	if withUsage {
+		h.attachSessionUsage(ctx, sessions)
 	}
-	writeData(w, http.StatusOK, map[string]any{"sessions": sessions})
+	writeDataETag(w, r, map[string]any{"sessions": sessions})
 }
 
 func (h *Handler) loadSessionMetaMap(ctx context.Context) map[string]store.SessionMeta {
@@ -203,7 +223,7 @@ func (h *Handler) loadSessionMetaMap(ctx context.Context) map[string]store.Sessi
 	}
 	meta, err := h.repo.GetAll(ctx)
 	if err != nil {
-		slog.Warn("store.GetAll failed", "err", err)
+		slog.WarnContext(ctx, "store.GetAll failed", "err", err)
 		return map[string]store.SessionMeta{}
 	}
 	return meta
@@ -215,7 +235,7 @@ func (h *Handler) listSessionsFromProjection(ctx context.Context, stored map[str
 	}
 	projected, err := h.repo.ListWatchtowerSessions(ctx)
 	if err != nil {
-		slog.Warn("store.ListWatchtowerSessions failed", "err", err)
+		slog.WarnContext(ctx, "store.ListWatchtowerSessions failed", "err", err)
 		return nil, false
 	}
 	if len(projected) == 0 {
@@ -236,7 +256,7 @@ func (h *Handler) listSessionsFromProjection(ctx context.Context, stored map[str
 	// truth instead of waiting for the next watchtower collection.
 	liveSessions, listErr := h.tmux.ListSessions(ctx)
 	if listErr != nil {
-		slog.Warn("tmux session overlay failed", "err", listErr)
+		slog.WarnContext(ctx, "tmux session overlay failed", "err", listErr)
 	} else {
 		snapshots := h.loadActivePaneSnapshots(ctx)
 		for _, sess := range liveSessions {
@@ -255,7 +275,7 @@ func (h *Handler) listSessionsFromProjection(ctx context.Context, stored map[str
 		svc := tmux.Service{User: user}
 		userSessions, listErr := svc.ListSessions(ctx)
 		if listErr != nil {
-			slog.Warn("multi-user session list failed", "user", user, "err", listErr)
+			slog.WarnContext(ctx, "multi-user session list failed", "user", user, "err", listErr)
 			continue
 		}
 		userSnapshots, _ := svc.ListActivePaneCommands(ctx)
@@ -298,6 +318,9 @@ func (h *Handler) projectedSessionToEnriched(ctx context.Context, row store.Watc
 		Hash:          hash,
 		LastContent:   lastContent,
 		Icon:          meta.Icon,
+		Tags:          meta.Tags,
+		Group:         meta.Group,
+		Notes:         meta.Notes,
 		User:          h.SessionUser(row.SessionName),
 		SortOrder:     meta.SortOrder,
 		UnreadWindows: row.UnreadWindows,
@@ -338,7 +361,7 @@ func (h *Handler) listSessionsFromTmux(ctx context.Context, stored map[string]st
 		svc := tmux.Service{User: user}
 		userSessions, listErr := svc.ListSessions(ctx)
 		if listErr != nil {
-			slog.Warn("multi-user session list failed", "user", user, "err", listErr)
+			slog.WarnContext(ctx, "multi-user session list failed", "user", user, "err", listErr)
 			continue
 		}
 		userSnapshots, _ := svc.ListActivePaneCommands(ctx)
@@ -363,7 +386,7 @@ func (h *Handler) listSessionsFromTmux(ctx context.Context, stored map[string]st
 func (h *Handler) loadActivePaneSnapshots(ctx context.Context) map[string]tmux.PaneSnapshot {
 	snapshots, err := h.tmux.ListActivePaneCommands(ctx)
 	if err != nil {
-		slog.Warn("list-pane-commands failed", "err", err)
+		slog.WarnContext(ctx, "list-pane-commands failed", "err", err)
 		return map[string]tmux.PaneSnapshot{}
 	}
 	return snapshots
@@ -388,6 +411,9 @@ func (h *Handler) tmuxSessionToEnriched(ctx context.Context, sess tmux.Session,
 		Hash:          hash,
 		LastContent:   lastContent,
 		Icon:          meta.Icon,
+		Tags:          meta.Tags,
+		Group:         meta.Group,
+		Notes:         meta.Notes,
 		User:          h.SessionUser(sess.Name),
 		SortOrder:     meta.SortOrder,
 		UnreadWindows: 0,
@@ -442,7 +468,7 @@ func (h *Handler) upsertSessionMetaBestEffort(ctx context.Context, sessionName,
 		return
 	}
 	if err := h.repo.UpsertSession(ctx, sessionName, hash, lastContent); err != nil {
-		slog.Warn("store.UpsertSession failed", keySession, sessionName, "err", err)
+		slog.WarnContext(ctx, "store.UpsertSession failed", keySession, sessionName, "err", err)
 	}
 }
 
@@ -451,6 +477,6 @@ func (h *Handler) purgeStoredSessionsBestEffort(ctx context.Context, activeNames
 		return
 	}
 	if err := h.repo.Purge(ctx, activeNames); err != nil {
-		slog.Warn("store.Purge failed", "err", err)
+		slog.WarnContext(ctx, "store.Purge failed", "err", err)
 	}
 }
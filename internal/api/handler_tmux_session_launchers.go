@@ -165,12 +165,12 @@ func (h *Handler) launchSessionLauncher(w http.ResponseWriter, r *http.Request)
 	}
 	h.registerSessionUser(sessionName, launcher.User)
 	if launcher.User != "" {
-		slog.Warn("multi-user session created",
+		slog.WarnContext(r.Context(), "multi-user session created",
 			keyAction, "session.launcher.launch",
 			"target_user", launcher.User,
 			keySession, sessionName,
 			keyLauncher, launcher.ID,
-			"source_ip", r.RemoteAddr,
+			"source_ip", h.guard.ClientIP(r),
 		)
 	}
 
@@ -180,7 +180,7 @@ func (h *Handler) launchSessionLauncher(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	if err := h.repo.MoveSessionToFront(ctx, sessionName); err != nil {
-		slog.Warn("failed to move session to front", keySession, sessionName, "err", err)
+		slog.WarnContext(r.Context(), "failed to move session to front", keySession, sessionName, "err", err)
 	}
 	h.emit(events.TypeTmuxSessions, map[string]any{
 		keySession:  sessionName,
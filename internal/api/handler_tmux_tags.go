@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+// maxSessionTags bounds the tags stored on one session.
+const maxSessionTags = 16
+
+func (h *Handler) setSessionTags(w http.ResponseWriter, r *http.Request) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+
+	var req struct {
+		Tags  []string `json:"tags"`
+		Group string   `json:"group"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	tags, err := normalizeSessionTags(req.Tags)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	group := strings.TrimSpace(req.Group)
+	if group != "" && !validate.SessionGroup(group) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid group name", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.repo.SetSessionTags(ctx, session, group, tags); err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to set tags", nil)
+		return
+	}
+	h.emit(events.TypeTmuxSessions, map[string]any{
+		keySession: session,
+		keyAction:  "tags",
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// normalizeSessionTags lowercases, validates and de-duplicates tags while
+// keeping their order.
+func normalizeSessionTags(raw []string) ([]string, error) {
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !validate.SessionTag(tag) {
+			return nil, fmt.Errorf("tag %q must match ^[a-z0-9][a-z0-9._-]{0,31}$", tag)
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxSessionTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", maxSessionTags)
+	}
+	return tags, nil
+}
+
+// sessionTagFilter selects sessions by the repeatable ?tag= and the ?group=
+// query parameters. A session matches when it carries every requested tag
+// and, if set, belongs to the group.
+type sessionTagFilter struct {
+	tags  []string
+	group string
+}
+
+func parseSessionTagFilter(r *http.Request) (sessionTagFilter, error) {
+	query := r.URL.Query()
+	tags, err := normalizeSessionTags(query["tag"])
+	if err != nil {
+		return sessionTagFilter{}, err
+	}
+	return sessionTagFilter{tags: tags, group: strings.TrimSpace(query.Get("group"))}, nil
+}
+
+func (f sessionTagFilter) active() bool {
+	return len(f.tags) > 0 || f.group != ""
+}
+
+func (f sessionTagFilter) matches(meta store.SessionMeta) bool {
+	if f.group != "" && meta.Group != f.group {
+		return false
+	}
+	for _, tag := range f.tags {
+		if !slices.Contains(meta.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f sessionTagFilter) apply(sessions []enrichedSession) []enrichedSession {
+	if !f.active() {
+		return sessions
+	}
+	filtered := make([]enrichedSession, 0, len(sessions))
+	for _, sess := range sessions {
+		if f.matches(store.SessionMeta{Tags: sess.Tags, Group: sess.Group}) {
+			filtered = append(filtered, sess)
+		}
+	}
+	return filtered
+}
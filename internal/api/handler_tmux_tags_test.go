@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestSetSessionTags(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, &mockTmux{})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPatch, "/api/tmux/sessions/dev/tags",
+		strings.NewReader(`{"tags":[" Work ","acme","work"],"group":"clients"}`))
+	r.SetPathValue("session", "dev")
+	h.setSessionTags(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204; body=%s", w.Code, w.Body.String())
+	}
+
+	meta, err := st.GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	got := meta["dev"]
+	if got.Group != "clients" || strings.Join(got.Tags, ",") != "work,acme" {
+		t.Fatalf("meta = %+v, want group clients and tags work,acme", got)
+	}
+
+	for _, body := range []string{
+		`{"tags":["bad tag"]}`,
+		`{"tags":["ok"],"group":"-bad"}`,
+		`{"tags":["a","b","c","d","e","f","g","h","i","j","k","l","m","n","o","p","q"]}`,
+		`{`,
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/api/tmux/sessions/dev/tags", strings.NewReader(body))
+		r.SetPathValue("session", "dev")
+		h.setSessionTags(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("body %s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestListSessionsFiltersByTag(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tm := &mockTmux{
+		listSessionsFn: func(context.Context) ([]tmux.Session, error) {
+			return []tmux.Session{
+				{Name: "acme", Windows: 1, CreatedAt: now, ActivityAt: now},
+				{Name: "blog", Windows: 1, CreatedAt: now, ActivityAt: now},
+				{Name: "dots", Windows: 1, CreatedAt: now, ActivityAt: now},
+			}, nil
+		},
+	}
+	h, st := newTestHandler(t, tm)
+	ctx := context.Background()
+	if err := st.SetSessionTags(ctx, "acme", "clients", []string{"work", "billable"}); err != nil {
+		t.Fatalf("SetSessionTags(acme): %v", err)
+	}
+	if err := st.SetSessionTags(ctx, "blog", "", []string{"personal"}); err != nil {
+		t.Fatalf("SetSessionTags(blog): %v", err)
+	}
+
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"", "acme,blog,dots"},
+		{"?tag=work", "acme"},
+		{"?tag=WORK&tag=billable", "acme"},
+		{"?tag=work&tag=personal", ""},
+		{"?group=clients", "acme"},
+		{"?tag=personal", "blog"},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		h.listSessions(w, httptest.NewRequest(http.MethodGet, "/api/tmux/sessions"+tc.query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: status = %d, want 200; body=%s", tc.query, w.Code, w.Body.String())
+		}
+		data, _ := jsonBody(t, w)["data"].(map[string]any)
+		sessions, _ := data["sessions"].([]any)
+		names := make([]string, 0, len(sessions))
+		for _, raw := range sessions {
+			names = append(names, raw.(map[string]any)["name"].(string))
+		}
+		if got := strings.Join(names, ","); got != tc.want {
+			t.Fatalf("%q: sessions = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.listSessions(w, httptest.NewRequest(http.MethodGet, "/api/tmux/sessions?tag=bad%20tag", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid tag status = %d, want 400", w.Code)
+	}
+}
+
+func TestActivityDeltaFiltersByTag(t *testing.T) {
+	t.Parallel()
+
+	h, st := newTestHandler(t, nil)
+	ctx := context.Background()
+	now := time.Date(2026, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	seedActivityDeltaSession(t, st, "dev", "%1", now, 11)
+	seedActivityDeltaSession(t, st, "prod", "%2", now.Add(time.Minute), 12)
+	if err := st.SetSessionTags(ctx, "prod", "", []string{"work"}); err != nil {
+		t.Fatalf("SetSessionTags: %v", err)
+	}
+	for _, row := range []store.WatchtowerJournalWrite{
+		{GlobalRev: 10, EntityType: "session", Session: "dev", ChangeKind: "updated", ChangedAt: now},
+		{GlobalRev: 11, EntityType: "session", Session: "prod", ChangeKind: "updated", ChangedAt: now.Add(time.Second)},
+	} {
+		if _, err := st.InsertWatchtowerJournal(ctx, row); err != nil {
+			t.Fatalf("InsertWatchtowerJournal(%d): %v", row.GlobalRev, err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.activityDelta(w, httptest.NewRequest(http.MethodGet, "/api/activity/delta?since=9&tag=work", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("activityDelta status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	data := jsonBody(t, w)["data"].(map[string]any)
+	if changes := data["changes"].([]any); len(changes) != 1 {
+		t.Fatalf("len(changes) = %d, want 1", len(changes))
+	}
+	assertPatchSessions(t, data["sessionPatches"], []string{"prod"})
+	assertInspectorPatchSessions(t, data["inspectorPatches"], []string{"prod"})
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/proc"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+// sessionUsage is the summed usage of every process running in the panes
+// of one session.
+type sessionUsage struct {
+	Session string `json:"session"`
+	proc.Usage
+}
+
+// sessionsUsage ranks live sessions by the CPU and memory of the processes
+// in their panes, busiest first.
+func (h *Handler) sessionsUsage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	names, err := h.liveSessionNames(ctx)
+	if err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	usage, err := h.collectSessionUsage(ctx, names)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "PROCESS_LIST_FAILED", "failed to list processes", nil)
+		return
+	}
+
+	result := make([]sessionUsage, 0, len(usage))
+	for _, name := range names {
+		if u, ok := usage[name]; ok {
+			result = append(result, sessionUsage{Session: name, Usage: u})
+		}
+	}
+	slices.SortStableFunc(result, func(a, b sessionUsage) int {
+		if c := cmp.Compare(b.CPUPercent, a.CPUPercent); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.RSSBytes, a.RSSBytes)
+	})
+	writeData(w, http.StatusOK, map[string]any{"usage": result})
+}
+
+// attachSessionUsage fills the usage of the listed sessions. It is
+// best-effort: a failed process listing leaves usage unset.
+func (h *Handler) attachSessionUsage(ctx context.Context, sessions []enrichedSession) {
+	names := make([]string, len(sessions))
+	for i := range sessions {
+		names[i] = sessions[i].Name
+	}
+	usage, err := h.collectSessionUsage(ctx, names)
+	if err != nil {
+		slog.WarnContext(ctx, "session usage failed", "err", err)
+		return
+	}
+	for i := range sessions {
+		if u, ok := usage[sessions[i].Name]; ok {
+			sessions[i].Usage = &u
+		}
+	}
+}
+
+// collectSessionUsage sums, per session, the processes descending from its
+// pane processes. Sessions whose panes cannot be listed are left out.
+func (h *Handler) collectSessionUsage(ctx context.Context, names []string) (map[string]proc.Usage, error) {
+	procs, err := h.procs.Processes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]proc.Usage, len(names))
+	for _, name := range names {
+		panes, err := h.tmuxForSession(ctx, name).ListPanes(ctx, name)
+		if err != nil {
+			continue
+		}
+		roots := make([]int, 0, len(panes))
+		for _, pane := range panes {
+			roots = append(roots, pane.PID)
+		}
+		usage[name] = proc.TreeUsage(procs, roots...)
+	}
+	return usage, nil
+}
+
+// liveSessionNames lists the sessions of the default tmux server and of
+// every known multi-user server.
+func (h *Handler) liveSessionNames(ctx context.Context) ([]string, error) {
+	sessions, err := h.tmux.ListSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(sessions))
+	names := make([]string, 0, len(sessions))
+	for _, sess := range sessions {
+		seen[sess.Name] = struct{}{}
+		names = append(names, sess.Name)
+	}
+	for _, user := range h.knownSessionUsers() {
+		userSessions, err := tmux.Service{User: user}.ListSessions(ctx)
+		if err != nil {
+			continue
+		}
+		for _, sess := range userSessions {
+			if _, ok := seen[sess.Name]; ok {
+				continue
+			}
+			seen[sess.Name] = struct{}{}
+			names = append(names, sess.Name)
+			h.registerSessionUser(sess.Name, user)
+		}
+	}
+	return names, nil
+}
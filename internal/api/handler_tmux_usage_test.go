@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/proc"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestSessionsUsage(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	h, _ := newTestHandler(t, &mockTmux{
+		listSessionsFn: func(context.Context) ([]tmux.Session, error) {
+			return []tmux.Session{
+				{Name: "idle", Windows: 1, CreatedAt: now, ActivityAt: now},
+				{Name: "build", Windows: 1, CreatedAt: now, ActivityAt: now},
+			}, nil
+		},
+		listPanesFn: func(_ context.Context, session string) ([]tmux.Pane, error) {
+			if session == "build" {
+				return []tmux.Pane{{Session: "build", PaneID: "%1", PID: 100}, {Session: "build", PaneID: "%2", PID: 300}}, nil
+			}
+			return []tmux.Pane{{Session: "idle", PaneID: "%3", PID: 500}}, nil
+		},
+	})
+	h.procs = &mockProcs{procs: []proc.Process{
+		{PID: 100, PPID: 1, CPUPercent: 1, RSSBytes: 1 << 20},
+		{PID: 200, PPID: 100, CPUPercent: 80, RSSBytes: 64 << 20},
+		{PID: 300, PPID: 1, CPUPercent: 4, RSSBytes: 2 << 20},
+		{PID: 500, PPID: 1, CPUPercent: 0.5, RSSBytes: 3 << 20},
+		{PID: 900, PPID: 1, CPUPercent: 99, RSSBytes: 1 << 30},
+	}}
+
+	w := httptest.NewRecorder()
+	h.sessionsUsage(w, httptest.NewRequest(http.MethodGet, "/api/tmux/sessions/usage", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	usage, _ := data["usage"].([]any)
+	if len(usage) != 2 {
+		t.Fatalf("usage = %v, want 2 sessions", usage)
+	}
+	first, _ := usage[0].(map[string]any)
+	if first["session"] != "build" || first["processes"] != float64(3) || first["cpuPercent"] != float64(85) || first["rssBytes"] != float64(67<<20) {
+		t.Fatalf("busiest session = %v, want build with 3 processes, 85%% CPU, 67 MiB", first)
+	}
+
+	w = httptest.NewRecorder()
+	h.listSessions(w, httptest.NewRequest(http.MethodGet, "/api/tmux/sessions?usage=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	data, _ = jsonBody(t, w)["data"].(map[string]any)
+	sessions, _ := data["sessions"].([]any)
+	for _, raw := range sessions {
+		session, _ := raw.(map[string]any)
+		if _, ok := session["usage"].(map[string]any); !ok {
+			t.Fatalf("session %v has no usage", session["name"])
+		}
+	}
+
+	w = httptest.NewRecorder()
+	h.listSessions(w, httptest.NewRequest(http.MethodGet, "/api/tmux/sessions", nil))
+	data, _ = jsonBody(t, w)["data"].(map[string]any)
+	sessions, _ = data["sessions"].([]any)
+	if session, _ := sessions[0].(map[string]any); session["usage"] != nil {
+		t.Fatalf("usage listed without usage=true: %v", session)
+	}
+
+	w = httptest.NewRecorder()
+	h.listSessions(w, httptest.NewRequest(http.MethodGet, "/api/tmux/sessions?usage=maybe", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("usage=maybe status = %d, want 400", w.Code)
+	}
+}
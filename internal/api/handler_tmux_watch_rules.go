@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+// maxWatchPatternLen bounds a watch rule pattern.
+const maxWatchPatternLen = 512
+
+type watchRuleRequest struct {
+	Name     string `json:"name"`
+	Pattern  string `json:"pattern"`
+	Severity string `json:"severity"`
+	Session  string `json:"session"`
+	PaneID   string `json:"paneId"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+func (h *Handler) listWatchRules(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	rules, err := h.repo.ListWatchRules(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to list watch rules", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"rules": rules})
+}
+
+func (h *Handler) createWatchRule(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	write, ok := decodeWatchRuleRequest(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	rule, err := h.repo.CreateWatchRule(ctx, write)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			writeError(w, http.StatusConflict, "WATCH_RULE_EXISTS", "watch rule already exists", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to create watch rule", nil)
+		return
+	}
+	writeData(w, http.StatusCreated, map[string]any{"rule": rule})
+}
+
+func (h *Handler) updateWatchRule(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	write, ok := decodeWatchRuleRequest(w, r)
+	if !ok {
+		return
+	}
+	write.ID = strings.TrimSpace(r.PathValue("rule"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	rule, err := h.repo.UpdateWatchRule(ctx, write)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "WATCH_RULE_NOT_FOUND", "watch rule not found", nil)
+		case isUniqueConstraintError(err):
+			writeError(w, http.StatusConflict, "WATCH_RULE_EXISTS", "watch rule already exists", nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to update watch rule", nil)
+		}
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"rule": rule})
+}
+
+func (h *Handler) deleteWatchRule(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	id := strings.TrimSpace(r.PathValue("rule"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.repo.DeleteWatchRule(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "WATCH_RULE_NOT_FOUND", "watch rule not found", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to delete watch rule", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{keyRemoved: id})
+}
+
+func decodeWatchRuleRequest(w http.ResponseWriter, r *http.Request) (store.WatchRuleWrite, bool) {
+	var req watchRuleRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return store.WatchRuleWrite{}, false
+	}
+	write := store.WatchRuleWrite{
+		Name:     strings.TrimSpace(req.Name),
+		Pattern:  req.Pattern,
+		Severity: strings.TrimSpace(req.Severity),
+		Session:  strings.TrimSpace(req.Session),
+		PaneID:   strings.TrimSpace(req.PaneID),
+		Enabled:  req.Enabled == nil || *req.Enabled,
+	}
+	if msg := validateWatchRule(write); msg != "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", msg, nil)
+		return store.WatchRuleWrite{}, false
+	}
+	return write, true
+}
+
+func validateWatchRule(write store.WatchRuleWrite) string {
+	switch {
+	case write.Name == "":
+		return "name is required"
+	case strings.TrimSpace(write.Pattern) == "":
+		return "pattern is required"
+	case len(write.Pattern) > maxWatchPatternLen:
+		return "pattern is too long"
+	}
+	if _, err := regexp.Compile(write.Pattern); err != nil {
+		return "pattern is not a valid regular expression"
+	}
+	switch write.Severity {
+	case "", store.WatchSeverityInfo, store.WatchSeverityWarning, store.WatchSeverityCritical:
+	default:
+		return "severity must be info, warning or critical"
+	}
+	if write.Session != "" && !validate.SessionName(write.Session) {
+		return "session is invalid"
+	}
+	if write.PaneID != "" && !strings.HasPrefix(write.PaneID, "%") {
+		return "paneId must be a tmux pane id"
+	}
+	return ""
+}
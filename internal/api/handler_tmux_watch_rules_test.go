@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestWatchRules(t *testing.T) {
+	t.Parallel()
+
+	mux, _ := newRoleTestMux(t)
+
+	for _, body := range []string{
+		`{"name":"panic","pattern":"("}`,
+		`{"name":"panic","pattern":"panic","severity":"fatal"}`,
+		`{"name":"panic","pattern":"panic","paneId":"1"}`,
+		`{"name":"","pattern":"panic"}`,
+	} {
+		w := serveWithBearer(mux, http.MethodPost, "/api/tmux/watch-rules", "secret", body)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("create %s: status = %d, want 400; body=%s", body, w.Code, w.Body.String())
+		}
+	}
+
+	w := serveWithBearer(mux, http.MethodPost, "/api/tmux/watch-rules", "secret",
+		`{"name":"panic","pattern":"^panic:","session":"dev"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want 201; body=%s", w.Code, w.Body.String())
+	}
+	rule, _ := jsonBody(t, w)["data"].(map[string]any)["rule"].(map[string]any)
+	id, _ := rule["id"].(string)
+	if id == "" || rule["enabled"] != true || rule["severity"] != "warning" || rule["session"] != "dev" {
+		t.Fatalf("created rule = %v, want an enabled warning rule for dev", rule)
+	}
+
+	w = serveWithBearer(mux, http.MethodPost, "/api/tmux/watch-rules", "secret", `{"name":"panic","pattern":"x"}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("duplicate create: status = %d, want 409", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodPut, "/api/tmux/watch-rules/"+id, "secret",
+		`{"name":"oom","pattern":"Out of memory","severity":"critical","enabled":false}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	rule, _ = jsonBody(t, w)["data"].(map[string]any)["rule"].(map[string]any)
+	if rule["name"] != "oom" || rule["severity"] != "critical" || rule["enabled"] != false || rule["session"] != nil {
+		t.Fatalf("updated rule = %v", rule)
+	}
+	w = serveWithBearer(mux, http.MethodPut, "/api/tmux/watch-rules/missing", "secret", `{"name":"x","pattern":"x"}`)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("update missing: status = %d, want 404", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodGet, "/api/tmux/watch-rules", "secret", "")
+	rules, _ := jsonBody(t, w)["data"].(map[string]any)["rules"].([]any)
+	if w.Code != http.StatusOK || len(rules) != 1 {
+		t.Fatalf("list: status = %d, rules = %v", w.Code, rules)
+	}
+
+	w = serveWithBearer(mux, http.MethodDelete, "/api/tmux/watch-rules/"+id, "secret", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	w = serveWithBearer(mux, http.MethodDelete, "/api/tmux/watch-rules/"+id, "secret", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("delete again: status = %d, want 404", w.Code)
+	}
+}
@@ -32,9 +32,9 @@ func (h *Handler) listWindows(w http.ResponseWriter, r *http.Request) {
 		if ok {
 			managedRows, managedErr := h.listManagedTmuxWindows(ctx, session)
 			if managedErr != nil {
-				slog.Warn("store.ListManagedTmuxWindowsBySession failed", keySession, session, "err", managedErr)
+				slog.WarnContext(r.Context(), "store.ListManagedTmuxWindowsBySession failed", keySession, session, "err", managedErr)
 			}
-			writeData(w, http.StatusOK, map[string]any{
+			writeDataETag(w, r, map[string]any{
 				"windows": projectedWindowsToEnriched(projectedWindows, projectedPanes, managedWindowsByRuntime(managedRows)),
 			})
 			return
@@ -45,7 +45,7 @@ func (h *Handler) listWindows(w http.ResponseWriter, r *http.Request) {
 
 	managedRows, managedErr := h.reconcileManagedTmuxWindows(ctx, session, windows)
 	if managedErr != nil {
-		slog.Warn("failed to reconcile managed tmux windows", keySession, session, "err", managedErr)
+		slog.WarnContext(r.Context(), "failed to reconcile managed tmux windows", keySession, session, "err", managedErr)
 		managedRows = nil
 	}
 	managedByRuntime := managedWindowsByRuntime(managedRows)
@@ -92,7 +92,7 @@ func (h *Handler) listWindows(w http.ResponseWriter, r *http.Request) {
 			ActivityAt:      activityAt,
 		})
 	}
-	writeData(w, http.StatusOK, map[string]any{"windows": resp})
+	writeDataETag(w, r, map[string]any{"windows": resp})
 }
 
 func (h *Handler) listPanes(w http.ResponseWriter, r *http.Request) {
@@ -148,6 +148,7 @@ func (h *Handler) listPanes(w http.ResponseWriter, r *http.Request) {
 			PaneID:         row.PaneID,
 			Title:          row.Title,
 			Active:         row.Active,
+			Zoomed:         row.Zoomed,
 			TTY:            row.TTY,
 			CurrentPath:    row.CurrentPath,
 			StartCommand:   row.StartCommand,
@@ -425,10 +426,10 @@ func (h *Handler) renameWindow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if managedWindow, ok, err := h.managedTmuxWindowForIndex(ctx, session, req.Index); err != nil {
-		slog.Warn("failed to load managed tmux window after rename", keySession, session, keyIndex, req.Index, "err", err)
+		slog.WarnContext(r.Context(), "failed to load managed tmux window after rename", keySession, session, keyIndex, req.Index, "err", err)
 	} else if ok {
 		if err := h.repo.UpdateManagedTmuxWindowName(ctx, managedWindow.ID, req.Name); err != nil {
-			slog.Warn("failed to persist managed tmux window name", keySession, session, keyIndex, req.Index, "managedWindowId", managedWindow.ID, "err", err)
+			slog.WarnContext(r.Context(), "failed to persist managed tmux window name", keySession, session, keyIndex, req.Index, "managedWindowId", managedWindow.ID, "err", err)
 		}
 	}
 	h.emit(events.TypeTmuxInspector, map[string]any{
@@ -565,26 +566,26 @@ func (h *Handler) newWindow(w http.ResponseWriter, r *http.Request) {
 		windowNameSequence = 1
 	}
 	if windows, listErr := svc.ListWindows(ctx, session); listErr != nil {
-		slog.Warn("failed to resolve window count for default name", keySession, session, keyIndex, createdWindow.Index, "err", listErr)
+		slog.WarnContext(r.Context(), "failed to resolve window count for default name", keySession, session, keyIndex, createdWindow.Index, "err", listErr)
 	} else if next := nextWindowNameSequence(windows); next > windowNameSequence {
 		windowNameSequence = next
 	}
 	if h.repo != nil {
 		allocatedSequence, allocErr := h.repo.AllocateNextWindowSequence(ctx, session, windowNameSequence)
 		if allocErr != nil {
-			slog.Warn("failed to allocate default window sequence", keySession, session, "min", windowNameSequence, "err", allocErr)
+			slog.WarnContext(r.Context(), "failed to allocate default window sequence", keySession, session, "min", windowNameSequence, "err", allocErr)
 		} else {
 			windowNameSequence = allocatedSequence
 		}
 	}
 	windowName := defaultWindowName(windowNameSequence)
 	if err := svc.RenameWindow(ctx, session, createdWindow.Index, windowName); err != nil {
-		slog.Warn("failed to apply default window name", keySession, session, keyIndex, createdWindow.Index, keyName, windowName, "err", err)
+		slog.WarnContext(r.Context(), "failed to apply default window name", keySession, session, keyIndex, createdWindow.Index, keyName, windowName, "err", err)
 	}
 	if createdWindow.PaneID != "" {
 		paneTitle := defaultPaneTitle(createdWindow.PaneID)
 		if err := svc.RenamePane(ctx, createdWindow.PaneID, paneTitle); err != nil {
-			slog.Warn("failed to apply default pane title", keySession, session, keyPaneID, createdWindow.PaneID, "title", paneTitle, "err", err)
+			slog.WarnContext(r.Context(), "failed to apply default pane title", keySession, session, keyPaneID, createdWindow.PaneID, "title", paneTitle, "err", err)
 		}
 	}
 	inspectorPayload := map[string]any{
@@ -628,7 +629,7 @@ func (h *Handler) killWindow(w http.ResponseWriter, r *http.Request) {
 
 	managedWindow, hasManagedWindow, managedErr := h.managedTmuxWindowForIndex(ctx, session, req.Index)
 	if managedErr != nil {
-		slog.Warn("failed to resolve managed tmux window before delete", keySession, session, keyIndex, req.Index, "err", managedErr)
+		slog.WarnContext(r.Context(), "failed to resolve managed tmux window before delete", keySession, session, keyIndex, req.Index, "err", managedErr)
 	}
 
 	if err := h.tmuxForSession(ctx, session).KillWindow(ctx, session, req.Index); err != nil {
@@ -637,7 +638,7 @@ func (h *Handler) killWindow(w http.ResponseWriter, r *http.Request) {
 	}
 	if hasManagedWindow {
 		if err := h.repo.DeleteManagedTmuxWindow(ctx, managedWindow.ID); err != nil {
-			slog.Warn("failed to delete managed tmux window", keySession, session, keyIndex, req.Index, "managedWindowId", managedWindow.ID, "err", err)
+			slog.WarnContext(r.Context(), "failed to delete managed tmux window", keySession, session, keyIndex, req.Index, "managedWindowId", managedWindow.ID, "err", err)
 		}
 	}
 	h.emit(events.TypeTmuxInspector, map[string]any{
@@ -741,7 +742,7 @@ func (h *Handler) splitPane(w http.ResponseWriter, r *http.Request) {
 	if createdPaneID != "" {
 		paneTitle := defaultPaneTitle(createdPaneID)
 		if err := svc.RenamePane(ctx, createdPaneID, paneTitle); err != nil {
-			slog.Warn("failed to apply default pane title", keySession, session, keyPaneID, createdPaneID, "title", paneTitle, "err", err)
+			slog.WarnContext(r.Context(), "failed to apply default pane title", keySession, session, keyPaneID, createdPaneID, "title", paneTitle, "err", err)
 		}
 	}
 	inspectorPayload := map[string]any{
@@ -761,3 +762,144 @@ func (h *Handler) splitPane(w http.ResponseWriter, r *http.Request) {
 	h.emit(events.TypeTmuxSessions, sessionsPayload)
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *Handler) moveWindow(w http.ResponseWriter, r *http.Request) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+
+	var req struct {
+		Index         int    `json:"index"`
+		TargetSession string `json:"targetSession"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	req.TargetSession = strings.TrimSpace(req.TargetSession)
+	if req.Index < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "index must be >= 0", nil)
+		return
+	}
+	if !validate.SessionName(req.TargetSession) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid target session name", nil)
+		return
+	}
+	if req.TargetSession == session {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "target session must differ from session", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.tmuxForSession(ctx, session).MoveWindow(ctx, session, req.Index, req.TargetSession); err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	for _, name := range []string{session, req.TargetSession} {
+		h.emit(events.TypeTmuxInspector, map[string]any{
+			keySession:      name,
+			keyAction:       "move-window",
+			keyIndex:        req.Index,
+			"targetSession": req.TargetSession,
+		})
+		h.emit(events.TypeTmuxSessions, map[string]any{keySession: name, keyAction: actionWindowCount})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) swapPane(w http.ResponseWriter, r *http.Request) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+
+	var req struct {
+		PaneID       string `json:"paneId"`
+		TargetPaneID string `json:"targetPaneId"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	req.PaneID = strings.TrimSpace(req.PaneID)
+	req.TargetPaneID = strings.TrimSpace(req.TargetPaneID)
+	if !strings.HasPrefix(req.PaneID, "%") || !strings.HasPrefix(req.TargetPaneID, "%") {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "paneId and targetPaneId must start with %", nil)
+		return
+	}
+	if req.PaneID == req.TargetPaneID {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "targetPaneId must differ from paneId", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	svc := h.tmuxForSession(ctx, session)
+	panes, err := svc.ListPanes(ctx, session)
+	if err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	if !paneBelongsToSession(panes, req.PaneID) || !paneBelongsToSession(panes, req.TargetPaneID) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "paneId and targetPaneId must belong to session", nil)
+		return
+	}
+	if err := svc.SwapPane(ctx, req.PaneID, req.TargetPaneID); err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	h.emit(events.TypeTmuxInspector, map[string]any{
+		keySession:     session,
+		keyAction:      "swap-pane",
+		keyPaneID:      req.PaneID,
+		"targetPaneId": req.TargetPaneID,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) rotateWindow(w http.ResponseWriter, r *http.Request) {
+	session := strings.TrimSpace(r.PathValue(keySession))
+	if !validate.SessionName(session) {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid session name", nil)
+		return
+	}
+
+	var req struct {
+		Index     int    `json:"index"`
+		Direction string `json:"direction"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return
+	}
+	req.Direction = strings.TrimSpace(strings.ToLower(req.Direction))
+	if req.Index < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "index must be >= 0", nil)
+		return
+	}
+	if req.Direction != "up" && req.Direction != "down" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "direction must be up or down", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.tmuxForSession(ctx, session).RotateWindow(ctx, session, req.Index, req.Direction); err != nil {
+		writeTmuxError(w, err)
+		return
+	}
+	h.emit(events.TypeTmuxInspector, map[string]any{
+		keySession:  session,
+		keyAction:   "rotate-window",
+		keyIndex:    req.Index,
+		"direction": req.Direction,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/updater"
+)
+
+type updateChecker func(ctx context.Context, opts updater.CheckOptions) (updater.CheckResult, error)
+
+// updateCheckResponse is updater.CheckResult with JSON field names.
+type updateCheckResponse struct {
+	CurrentVersion string `json:"currentVersion"`
+	LatestVersion  string `json:"latestVersion"`
+	Channel        string `json:"channel"`
+	UpToDate       bool   `json:"upToDate"`
+	ReleaseURL     string `json:"releaseUrl"`
+	AssetName      string `json:"assetName"`
+	ExpectedSHA256 string `json:"expectedSha256"`
+	// SignatureVerified reports that the checksum comes from a checksums
+	// file signed with updates.public_key.
+	SignatureVerified bool      `json:"signatureVerified"`
+	CheckedAt         time.Time `json:"checkedAt"`
+}
+
+// SetUpdateOptions configures the data dir holding updater state, the
+// release channel checked by the update endpoints and the key releases must
+// be signed with, if any.
+func (h *Handler) SetUpdateOptions(dataDir, channel, publicKey string) {
+	if h == nil {
+		return
+	}
+	h.updateDataDir = strings.TrimSpace(dataDir)
+	h.updateChannel = strings.TrimSpace(channel)
+	h.updatePublicKey = strings.TrimSpace(publicKey)
+}
+
+func (h *Handler) checkUpdate(w http.ResponseWriter, r *http.Request) {
+	check := h.updateCheck
+	if check == nil {
+		check = updater.Check
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := check(ctx, updater.CheckOptions{
+		CurrentVersion: h.version,
+		Channel:        h.updateChannel,
+		DataDir:        h.updateDataDir,
+		PublicKey:      h.updatePublicKey,
+	})
+	if err != nil {
+		slog.WarnContext(r.Context(), "update check failed", "err", err)
+		writeError(w, http.StatusBadGateway, "UPDATE_CHECK_FAILED", err.Error(), nil)
+		return
+	}
+	data := map[string]any{
+		"check": updateCheckResponse{
+			CurrentVersion:    result.CurrentVersion,
+			LatestVersion:     result.LatestVersion,
+			Channel:           result.Channel,
+			UpToDate:          result.UpToDate,
+			ReleaseURL:        result.ReleaseURL,
+			AssetName:         result.AssetName,
+			ExpectedSHA256:    result.ExpectedSHA256,
+			SignatureVerified: result.SignatureVerified,
+			CheckedAt:         result.CheckedAt,
+		},
+	}
+	if h.updateDataDir != "" {
+		if state, err := updater.Status(h.updateDataDir); err == nil {
+			data["state"] = state
+		}
+	}
+	writeData(w, http.StatusOK, data)
+}
+
+// applyUpdate starts the autoupdate service, which downloads, verifies and
+// installs the release, restarts Sentinel and rolls back on a failed health
+// check. Progress is reported by GET /api/ops/update/check's state.
+func (h *Handler) applyUpdate(w http.ResponseWriter, r *http.Request) {
+	if h.ops == nil {
+		writeError(w, http.StatusServiceUnavailable, "OPS_UNAVAILABLE", "ops control plane unavailable", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	service, err := h.ops.StartUpdate(ctx)
+	if err != nil {
+		if errors.Is(err, opsplane.ErrUpdaterNotInstalled) {
+			writeError(w, http.StatusConflict, "UPDATER_NOT_INSTALLED",
+				"autoupdate service is not installed; run `sentinel service autoupdate install`", nil)
+			return
+		}
+		slog.WarnContext(r.Context(), "update apply failed", "err", err)
+		writeError(w, http.StatusInternalServerError, "UPDATE_APPLY_FAILED", "failed to start the autoupdate service", nil)
+		return
+	}
+	writeData(w, http.StatusAccepted, map[string]any{keyService: service})
+}
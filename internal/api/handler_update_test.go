@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	opsplane "github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/updater"
+)
+
+func TestCheckUpdate(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	h.version = "1.2.0"
+	h.SetUpdateOptions(t.TempDir(), updater.ChannelPrerelease, "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=")
+	h.updateCheck = func(_ context.Context, opts updater.CheckOptions) (updater.CheckResult, error) {
+		if opts.CurrentVersion != "1.2.0" || opts.Channel != updater.ChannelPrerelease || opts.PublicKey == "" {
+			t.Errorf("check options = %+v", opts)
+		}
+		return updater.CheckResult{
+			CurrentVersion: "1.2.0",
+			LatestVersion:  "1.3.0-rc.1",
+			Channel:        updater.ChannelPrerelease,
+			CheckedAt:      time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	h.checkUpdate(w, httptest.NewRequest(http.MethodGet, "/api/ops/update/check", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	data := jsonBody(t, w)["data"].(map[string]any)
+	check := data["check"].(map[string]any)
+	if check["latestVersion"] != "1.3.0-rc.1" || check["upToDate"] != false || check["channel"] != "prerelease" {
+		t.Fatalf("check = %+v", check)
+	}
+	if _, ok := data["state"]; !ok {
+		t.Fatalf("data missing state: %+v", data)
+	}
+
+	h.updateCheck = func(context.Context, updater.CheckOptions) (updater.CheckResult, error) {
+		return updater.CheckResult{}, errors.New("unexpected response 503")
+	}
+	w = httptest.NewRecorder()
+	h.checkUpdate(w, httptest.NewRequest(http.MethodGet, "/api/ops/update/check", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("failed check status = %d, want 502", w.Code)
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newTestHandler(t, nil)
+	started := false
+	h.ops = &mockOpsControlPlane{
+		startUpdateFn: func(context.Context) (opsplane.ServiceStatus, error) {
+			started = true
+			return opsplane.ServiceStatus{Name: opsplane.ServiceNameUpdater}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.applyUpdate(w, httptest.NewRequest(http.MethodPost, "/api/ops/update/apply", nil))
+	if w.Code != http.StatusAccepted || !started {
+		t.Fatalf("status = %d started = %t, want 202 and started; body=%s", w.Code, started, w.Body.String())
+	}
+
+	h.ops = &mockOpsControlPlane{
+		startUpdateFn: func(context.Context) (opsplane.ServiceStatus, error) {
+			return opsplane.ServiceStatus{}, opsplane.ErrUpdaterNotInstalled
+		},
+	}
+	w = httptest.NewRecorder()
+	h.applyUpdate(w, httptest.NewRequest(http.MethodPost, "/api/ops/update/apply", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", w.Code)
+	}
+	if code := jsonBody(t, w)["error"].(map[string]any)["code"]; code != "UPDATER_NOT_INSTALLED" {
+		t.Fatalf("error code = %v", code)
+	}
+}
@@ -0,0 +1,313 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/jobqueue"
+	"github.com/opus-domini/sentinel/internal/runbook"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+const (
+	// webhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of
+	// the timestamp, a dot and the request body, keyed with the webhook
+	// secret.
+	webhookSignatureHeader = "X-Sentinel-Signature"
+	// webhookTimestampHeader carries the Unix time, in seconds, at which the
+	// request was signed.
+	webhookTimestampHeader = "X-Sentinel-Timestamp"
+	// webhookMaxSkew is how far a signed timestamp may be from the server
+	// clock, which bounds how long a captured request can be replayed.
+	webhookMaxSkew      = 5 * time.Minute
+	webhookMaxBodyBytes = 1 << 20
+	webhookRunSource    = "webhook"
+)
+
+type webhookRequest struct {
+	Name       string            `json:"name"`
+	Provider   string            `json:"provider"`
+	RunbookID  string            `json:"runbookId"`
+	Parameters map[string]string `json:"parameters"`
+	Repository string            `json:"repository"`
+	Branch     string            `json:"branch"`
+	Events     []string          `json:"events"`
+	Enabled    bool              `json:"enabled"`
+}
+
+func (h *Handler) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	hooks, err := h.repo.ListOpsWebhooks(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to list webhooks", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"webhooks": hooks})
+}
+
+func (h *Handler) createWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	write, ok := h.decodeWebhookRequest(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	if !h.webhookRunbookExists(ctx, w, write.RunbookID) {
+		return
+	}
+
+	hook, secret, err := h.repo.CreateOpsWebhook(ctx, write)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			writeError(w, http.StatusConflict, "WEBHOOK_EXISTS", "webhook already exists", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to create webhook", nil)
+		return
+	}
+	// The secret is only returned here; callers sign requests with it.
+	writeData(w, http.StatusCreated, map[string]any{"webhook": hook, "secret": secret})
+}
+
+func (h *Handler) updateWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	write, ok := h.decodeWebhookRequest(w, r)
+	if !ok {
+		return
+	}
+	write.ID = strings.TrimSpace(r.PathValue("webhook"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	if !h.webhookRunbookExists(ctx, w, write.RunbookID) {
+		return
+	}
+
+	hook, err := h.repo.UpdateOpsWebhook(ctx, write)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "WEBHOOK_NOT_FOUND", "webhook not found", nil)
+		case isUniqueConstraintError(err):
+			writeError(w, http.StatusConflict, "WEBHOOK_EXISTS", "webhook already exists", nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to update webhook", nil)
+		}
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{"webhook": hook})
+}
+
+func (h *Handler) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	id := strings.TrimSpace(r.PathValue("webhook"))
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.repo.DeleteOpsWebhook(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "WEBHOOK_NOT_FOUND", "webhook not found", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to delete webhook", nil)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{keyRemoved: id})
+}
+
+// receiveWebhook starts the runbook bound to a webhook. It is a public
+// route: the signature over the timestamp and body stands in for a token,
+// and stale timestamps are rejected so a captured request cannot be
+// replayed later. A JSON body may carry "parameters" that override the
+// webhook's own. GitHub and GitLab webhooks are handed to
+// receiveGitWebhook instead.
+func (h *Handler) receiveWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil || h.runbooks == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "store is unavailable", nil)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "INVALID_REQUEST", "webhook body is too large", nil)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 6*time.Second)
+	defer cancel()
+
+	hook, err := h.repo.GetOpsWebhook(ctx, r.PathValue("hook"))
+	if err != nil || !hook.Enabled {
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to load webhook", nil)
+			return
+		}
+		writeError(w, http.StatusNotFound, "WEBHOOK_NOT_FOUND", "webhook not found", nil)
+		return
+	}
+	if hook.Provider != store.WebhookProviderGeneric {
+		h.receiveGitWebhook(ctx, w, r, hook, body)
+		return
+	}
+	timestamp := strings.TrimSpace(r.Header.Get(webhookTimestampHeader))
+	if !freshWebhookTimestamp(timestamp, time.Now()) {
+		writeError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "webhook timestamp is missing or too old", nil)
+		return
+	}
+	signed := append([]byte(timestamp+"."), body...)
+	if !validWebhookSignature(hook.Secret, signed, r.Header.Get(webhookSignatureHeader)) {
+		writeError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "webhook signature does not match", nil)
+		return
+	}
+
+	var payload struct {
+		Parameters map[string]string `json:"parameters"`
+	}
+	if strings.TrimSpace(string(body)) != "" {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid json body", nil)
+			return
+		}
+	}
+	params := maps.Clone(hook.Parameters)
+	if params == nil {
+		params = map[string]string{}
+	}
+	maps.Copy(params, payload.Parameters)
+	h.startWebhookRun(ctx, w, hook, params)
+}
+
+// startWebhookRun runs the webhook's runbook and answers 202 with the job.
+func (h *Handler) startWebhookRun(ctx context.Context, w http.ResponseWriter, hook store.OpsWebhook, params map[string]string) {
+	job, err := h.runbooks.StartWithPriority(ctx, hook.RunbookID, params, "", webhookRunSource, jobqueue.PriorityNormal)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "OPS_RUNBOOK_NOT_FOUND", "runbook not found", nil)
+		case errors.Is(err, runbook.ErrTooManyExecutions):
+			writeError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", err.Error(), nil)
+		case errors.Is(err, runbook.ErrInvalidParameters):
+			writeError(w, http.StatusBadRequest, "INVALID_PARAMETERS", err.Error(), nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to run runbook", nil)
+		}
+		return
+	}
+	if err := h.repo.MarkOpsWebhookTriggered(ctx, hook.ID, time.Now()); err != nil {
+		slog.WarnContext(ctx, "webhook trigger time not recorded", "webhook", hook.ID, "err", err)
+	}
+	writeData(w, http.StatusAccepted, map[string]any{keyJob: job})
+}
+
+func (h *Handler) decodeWebhookRequest(w http.ResponseWriter, r *http.Request) (store.OpsWebhookWrite, bool) {
+	var req webhookRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+		return store.OpsWebhookWrite{}, false
+	}
+	write := store.OpsWebhookWrite{
+		Name:       strings.TrimSpace(req.Name),
+		Provider:   strings.ToLower(strings.TrimSpace(req.Provider)),
+		RunbookID:  strings.TrimSpace(req.RunbookID),
+		Parameters: req.Parameters,
+		Repository: strings.TrimSpace(req.Repository),
+		Branch:     strings.TrimPrefix(strings.TrimSpace(req.Branch), "refs/heads/"),
+		Enabled:    req.Enabled,
+	}
+	if write.Provider == "" {
+		write.Provider = store.WebhookProviderGeneric
+	}
+	for _, event := range req.Events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if !slices.Contains(gitEventKinds, event) {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "events must be push, tag or release", nil)
+			return store.OpsWebhookWrite{}, false
+		}
+		if !slices.Contains(write.Events, event) {
+			write.Events = append(write.Events, event)
+		}
+	}
+	switch {
+	case write.Name == "":
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "name is required", nil)
+		return store.OpsWebhookWrite{}, false
+	case write.RunbookID == "":
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "runbookId is required", nil)
+		return store.OpsWebhookWrite{}, false
+	case write.Provider != store.WebhookProviderGeneric &&
+		write.Provider != store.WebhookProviderGitHub &&
+		write.Provider != store.WebhookProviderGitLab:
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "provider must be generic, github or gitlab", nil)
+		return store.OpsWebhookWrite{}, false
+	case write.Provider == store.WebhookProviderGeneric &&
+		(write.Repository != "" || write.Branch != "" || len(write.Events) > 0):
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "repository, branch and events apply to github and gitlab webhooks", nil)
+		return store.OpsWebhookWrite{}, false
+	}
+	return write, true
+}
+
+func (h *Handler) webhookRunbookExists(ctx context.Context, w http.ResponseWriter, runbookID string) bool {
+	if _, err := h.repo.GetOpsRunbook(ctx, runbookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "OPS_RUNBOOK_NOT_FOUND", "runbook not found", nil)
+			return false
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to load runbook", nil)
+		return false
+	}
+	return true
+}
+
+// freshWebhookTimestamp reports whether raw is a Unix time within
+// webhookMaxSkew of now.
+func freshWebhookTimestamp(raw string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	return skew <= webhookMaxSkew && skew >= -webhookMaxSkew
+}
+
+// validWebhookSignature checks a "sha256=<hex>" HMAC of body in constant
+// time.
+func validWebhookSignature(secret string, body []byte, header string) bool {
+	digest, ok := strings.CutPrefix(strings.TrimSpace(header), "sha256=")
+	if !ok || secret == "" {
+		return false
+	}
+	got, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
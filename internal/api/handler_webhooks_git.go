@@ -0,0 +1,248 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+const (
+	githubEventHeader     = "X-GitHub-Event"
+	githubSignatureHeader = "X-Hub-Signature-256"
+	gitlabEventHeader     = "X-Gitlab-Event"
+	gitlabTokenHeader     = "X-Gitlab-Token"
+)
+
+// Git event kinds a GitHub or GitLab webhook can accept.
+const (
+	gitEventPush    = "push"
+	gitEventTag     = "tag"
+	gitEventRelease = "release"
+)
+
+var gitEventKinds = []string{gitEventPush, gitEventTag, gitEventRelease}
+
+// gitEvent is the part of a GitHub or GitLab event that selects and
+// parameterizes a run.
+type gitEvent struct {
+	Kind       string
+	Repository string
+	Ref        string
+	Branch     string
+	Tag        string
+	CommitSHA  string
+}
+
+// params returns the run parameters describing the event. Only the ones the
+// runbook declares are passed on, since undeclared parameters are rejected.
+func (e gitEvent) params() map[string]string {
+	return map[string]string{
+		"EVENT":      e.Kind,
+		"REPOSITORY": e.Repository,
+		"REF":        e.Ref,
+		"BRANCH":     e.Branch,
+		"TAG":        e.Tag,
+		"COMMIT_SHA": e.CommitSHA,
+	}
+}
+
+// receiveGitWebhook verifies a GitHub or GitLab delivery and runs the
+// webhook's runbook when the event matches its filters. Events that are
+// verified but not acted on, such as GitHub's ping, answer 200 with the
+// reason so the provider records a successful delivery.
+func (h *Handler) receiveGitWebhook(ctx context.Context, w http.ResponseWriter, r *http.Request, hook store.OpsWebhook, body []byte) {
+	var (
+		event   gitEvent
+		ignored string
+		err     error
+	)
+	switch hook.Provider {
+	case store.WebhookProviderGitHub:
+		if !validWebhookSignature(hook.Secret, body, r.Header.Get(githubSignatureHeader)) {
+			writeError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "webhook signature does not match", nil)
+			return
+		}
+		event, ignored, err = parseGitHubEvent(r.Header.Get(githubEventHeader), body)
+	case store.WebhookProviderGitLab:
+		token := r.Header.Get(gitlabTokenHeader)
+		if hook.Secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(hook.Secret)) != 1 {
+			writeError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "webhook token does not match", nil)
+			return
+		}
+		event, ignored, err = parseGitLabEvent(r.Header.Get(gitlabEventHeader), body)
+	default:
+		writeError(w, http.StatusNotFound, "WEBHOOK_NOT_FOUND", "webhook not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid json body", nil)
+		return
+	}
+	if ignored == "" {
+		ignored = gitEventSkipReason(hook, event)
+	}
+	if ignored != "" {
+		writeData(w, http.StatusOK, map[string]any{"ignored": ignored})
+		return
+	}
+
+	rb, err := h.repo.GetOpsRunbook(ctx, hook.RunbookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "OPS_RUNBOOK_NOT_FOUND", "runbook not found", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "STORE_ERROR", "failed to load runbook", nil)
+		return
+	}
+	params := maps.Clone(hook.Parameters)
+	if params == nil {
+		params = map[string]string{}
+	}
+	eventParams := event.params()
+	for _, def := range rb.Parameters {
+		if value := eventParams[def.Name]; value != "" {
+			params[def.Name] = value
+		}
+	}
+	h.startWebhookRun(ctx, w, hook, params)
+}
+
+// gitEventSkipReason returns why hook does not act on event, or "" when it
+// does. Hooks without events accept pushes only; the branch filter applies
+// to pushes.
+func gitEventSkipReason(hook store.OpsWebhook, event gitEvent) string {
+	events := hook.Events
+	if len(events) == 0 {
+		events = []string{gitEventPush}
+	}
+	switch {
+	case !slices.Contains(events, event.Kind):
+		return event.Kind + " events are not enabled"
+	case hook.Repository != "" && !strings.EqualFold(hook.Repository, event.Repository):
+		return "repository " + event.Repository + " does not match"
+	case event.Kind == gitEventPush && hook.Branch != "" && hook.Branch != event.Branch:
+		return "branch " + event.Branch + " does not match"
+	}
+	return ""
+}
+
+// refEvent fills the kind, branch and tag of a push to ref.
+func refEvent(ref string) gitEvent {
+	if tag, ok := strings.CutPrefix(ref, "refs/tags/"); ok {
+		return gitEvent{Kind: gitEventTag, Ref: ref, Tag: tag}
+	}
+	return gitEvent{Kind: gitEventPush, Ref: ref, Branch: strings.TrimPrefix(ref, "refs/heads/")}
+}
+
+// parseGitHubEvent reads push and published release events. Other events
+// return the reason they are ignored.
+func parseGitHubEvent(name string, body []byte) (gitEvent, string, error) {
+	switch name {
+	case "push":
+		var payload struct {
+			Ref        string `json:"ref"`
+			After      string `json:"after"`
+			Deleted    bool   `json:"deleted"`
+			Repository struct {
+				FullName string `json:"full_name"`
+			} `json:"repository"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return gitEvent{}, "", err
+		}
+		if payload.Deleted {
+			return gitEvent{}, "ref deleted", nil
+		}
+		event := refEvent(payload.Ref)
+		event.Repository = payload.Repository.FullName
+		event.CommitSHA = payload.After
+		return event, "", nil
+	case "release":
+		var payload struct {
+			Action  string `json:"action"`
+			Release struct {
+				TagName string `json:"tag_name"`
+			} `json:"release"`
+			Repository struct {
+				FullName string `json:"full_name"`
+			} `json:"repository"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return gitEvent{}, "", err
+		}
+		if payload.Action != "published" {
+			return gitEvent{}, "release " + payload.Action, nil
+		}
+		return gitEvent{
+			Kind:       gitEventRelease,
+			Repository: payload.Repository.FullName,
+			Ref:        "refs/tags/" + payload.Release.TagName,
+			Tag:        payload.Release.TagName,
+		}, "", nil
+	case "ping":
+		return gitEvent{}, "ping", nil
+	default:
+		return gitEvent{}, "unsupported event " + name, nil
+	}
+}
+
+// parseGitLabEvent reads push, tag push and created release events. Other
+// events return the reason they are ignored.
+func parseGitLabEvent(name string, body []byte) (gitEvent, string, error) {
+	switch name {
+	case "Push Hook", "Tag Push Hook":
+		var payload struct {
+			Ref         string `json:"ref"`
+			CheckoutSHA string `json:"checkout_sha"`
+			Project     struct {
+				PathWithNamespace string `json:"path_with_namespace"`
+			} `json:"project"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return gitEvent{}, "", err
+		}
+		// GitLab sends no checkout SHA when the ref is deleted.
+		if payload.CheckoutSHA == "" {
+			return gitEvent{}, "ref deleted", nil
+		}
+		event := refEvent(payload.Ref)
+		event.Repository = payload.Project.PathWithNamespace
+		event.CommitSHA = payload.CheckoutSHA
+		return event, "", nil
+	case "Release Hook":
+		var payload struct {
+			Action  string `json:"action"`
+			Tag     string `json:"tag"`
+			Project struct {
+				PathWithNamespace string `json:"path_with_namespace"`
+			} `json:"project"`
+			Commit struct {
+				ID string `json:"id"`
+			} `json:"commit"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return gitEvent{}, "", err
+		}
+		if payload.Action != "create" {
+			return gitEvent{}, "release " + payload.Action, nil
+		}
+		return gitEvent{
+			Kind:       gitEventRelease,
+			Repository: payload.Project.PathWithNamespace,
+			Ref:        "refs/tags/" + payload.Tag,
+			Tag:        payload.Tag,
+			CommitSHA:  payload.Commit.ID,
+		}, "", nil
+	default:
+		return gitEvent{}, "unsupported event " + name, nil
+	}
+}
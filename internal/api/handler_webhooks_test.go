@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func signWebhook(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postWebhook posts body signed with secret at the given time, as a
+// generic webhook caller does. An empty secret sends no signature.
+func postWebhook(mux *http.ServeMux, id, secret string, at time.Time, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "http://localhost:4040/api/hooks/"+id, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		timestamp := strconv.FormatInt(at.Unix(), 10)
+		r.Header.Set(webhookTimestampHeader, timestamp)
+		r.Header.Set(webhookSignatureHeader, signWebhook(secret, timestamp+"."+body))
+	}
+	mux.ServeHTTP(w, r)
+	return w
+}
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+
+	mux, st := newRoleTestMux(t)
+	ctx := context.Background()
+	rb, err := st.InsertOpsRunbook(ctx, store.OpsRunbookWrite{
+		Name:       "deploy",
+		Steps:      []store.OpsRunbookStep{{Type: "run", Title: "deploy", Command: "echo {{ENV}} {{REF}}"}},
+		Parameters: []store.RunbookParameter{{Name: "ENV", Type: "string"}, {Name: "REF", Type: "string"}},
+		Enabled:    true,
+	})
+	if err != nil {
+		t.Fatalf("InsertOpsRunbook: %v", err)
+	}
+
+	w := serveWithBearer(mux, http.MethodPost, "/api/ops/webhooks", "secret",
+		`{"name":"ci","runbookId":"missing","enabled":true}`)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("create with unknown runbook: status = %d, want 404; body=%s", w.Code, w.Body.String())
+	}
+
+	w = serveWithBearer(mux, http.MethodPost, "/api/ops/webhooks", "secret",
+		`{"name":"ci","runbookId":"`+rb.ID+`","parameters":{"ENV":"staging","REF":"main"},"enabled":true}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want 201; body=%s", w.Code, w.Body.String())
+	}
+	data, _ := jsonBody(t, w)["data"].(map[string]any)
+	secret, _ := data["secret"].(string)
+	hook, _ := data["webhook"].(map[string]any)
+	hookID, _ := hook["id"].(string)
+	if secret == "" || hookID == "" {
+		t.Fatalf("create response = %v", data)
+	}
+
+	w = serveWithBearer(mux, http.MethodGet, "/api/ops/webhooks", "secret", "")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), secret) {
+		t.Fatalf("list: status = %d, body must not include the secret; body=%s", w.Code, w.Body.String())
+	}
+
+	body := `{"parameters":{"REF":"abc123"}}`
+	now := time.Now()
+	if w := postWebhook(mux, hookID, "", now, body); w.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned: status = %d, want 401", w.Code)
+	}
+	if w := postWebhook(mux, hookID, "wrong", now, body); w.Code != http.StatusUnauthorized {
+		t.Fatalf("bad signature: status = %d, want 401", w.Code)
+	}
+	// A captured request stops working once its timestamp is stale.
+	if w := postWebhook(mux, hookID, secret, now.Add(-10*time.Minute), body); w.Code != http.StatusUnauthorized {
+		t.Fatalf("stale timestamp: status = %d, want 401", w.Code)
+	}
+	if w := postWebhook(mux, "missing", secret, now, body); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown hook: status = %d, want 404", w.Code)
+	}
+
+	// No bearer token: the signature authenticates the call.
+	w = postWebhook(mux, hookID, secret, now, body)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("signed: status = %d, want 202; body=%s", w.Code, w.Body.String())
+	}
+	job, _ := jsonBody(t, w)["data"].(map[string]any)["job"].(map[string]any)
+	params, _ := job["parametersUsed"].(map[string]any)
+	if params["ENV"] != "staging" || params["REF"] != "abc123" {
+		t.Fatalf("parametersUsed = %v, want webhook ENV and payload REF", params)
+	}
+
+	w = serveWithBearer(mux, http.MethodPut, "/api/ops/webhooks/"+hookID, "secret",
+		`{"name":"ci","runbookId":"`+rb.ID+`","enabled":false}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	if w := postWebhook(mux, hookID, secret, now, body); w.Code != http.StatusNotFound {
+		t.Fatalf("disabled hook: status = %d, want 404", w.Code)
+	}
+
+	w = serveWithBearer(mux, http.MethodDelete, "/api/ops/webhooks/"+hookID, "secret", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	w = serveWithBearer(mux, http.MethodDelete, "/api/ops/webhooks/"+hookID, "secret", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("second delete: status = %d, want 404", w.Code)
+	}
+}
+
+func postGitWebhook(mux *http.ServeMux, id string, headers map[string]string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "http://localhost:4040/api/hooks/"+id, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		r.Header.Set(key, value)
+	}
+	mux.ServeHTTP(w, r)
+	return w
+}
+
+func TestGitWebhooks(t *testing.T) {
+	t.Parallel()
+
+	mux, st := newRoleTestMux(t)
+	rb, err := st.InsertOpsRunbook(context.Background(), store.OpsRunbookWrite{
+		Name:  "deploy",
+		Steps: []store.OpsRunbookStep{{Type: "run", Title: "deploy", Command: "echo {{ENV}} {{COMMIT_SHA}}"}},
+		Parameters: []store.RunbookParameter{
+			{Name: "ENV", Type: "string"}, {Name: "COMMIT_SHA", Type: "string"}, {Name: "TAG", Type: "string"},
+		},
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("InsertOpsRunbook: %v", err)
+	}
+
+	create := func(body string) (string, string) {
+		t.Helper()
+		w := serveWithBearer(mux, http.MethodPost, "/api/ops/webhooks", "secret", body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create: status = %d, want 201; body=%s", w.Code, w.Body.String())
+		}
+		data, _ := jsonBody(t, w)["data"].(map[string]any)
+		hook, _ := data["webhook"].(map[string]any)
+		id, _ := hook["id"].(string)
+		secret, _ := data["secret"].(string)
+		return id, secret
+	}
+
+	w := serveWithBearer(mux, http.MethodPost, "/api/ops/webhooks", "secret",
+		`{"name":"bad","runbookId":"`+rb.ID+`","branch":"main","enabled":true}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("generic with branch: status = %d, want 400", w.Code)
+	}
+
+	githubID, githubSecret := create(`{"name":"gh","provider":"github","runbookId":"` + rb.ID +
+		`","parameters":{"ENV":"prod"},"repository":"acme/app","branch":"main","enabled":true}`)
+	github := func(event, body, secret string) *httptest.ResponseRecorder {
+		return postGitWebhook(mux, githubID, map[string]string{
+			githubEventHeader:     event,
+			githubSignatureHeader: signWebhook(secret, body),
+		}, body)
+	}
+
+	push := `{"ref":"refs/heads/main","after":"abc123","repository":{"full_name":"acme/app"}}`
+	if w := github("push", push, "wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("bad signature: status = %d, want 401", w.Code)
+	}
+	if w := github("ping", `{"zen":"hi"}`, githubSecret); w.Code != http.StatusOK {
+		t.Fatalf("ping: status = %d, want 200; body=%s", w.Code, w.Body.String())
+	}
+	other := `{"ref":"refs/heads/dev","after":"def456","repository":{"full_name":"acme/app"}}`
+	if w := github("push", other, githubSecret); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "ignored") {
+		t.Fatalf("other branch: status = %d, want 200 ignored; body=%s", w.Code, w.Body.String())
+	}
+	w = github("push", push, githubSecret)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("push: status = %d, want 202; body=%s", w.Code, w.Body.String())
+	}
+	job, _ := jsonBody(t, w)["data"].(map[string]any)["job"].(map[string]any)
+	params, _ := job["parametersUsed"].(map[string]any)
+	if params["ENV"] != "prod" || params["COMMIT_SHA"] != "abc123" {
+		t.Fatalf("parametersUsed = %v, want webhook ENV and event COMMIT_SHA", params)
+	}
+
+	gitlabID, gitlabToken := create(`{"name":"gl","provider":"gitlab","runbookId":"` + rb.ID +
+		`","events":["release"],"enabled":true}`)
+	release := `{"action":"create","tag":"v1.2.0","project":{"path_with_namespace":"acme/app"},"commit":{"id":"fff999"}}`
+	gitlab := func(event, token string) *httptest.ResponseRecorder {
+		return postGitWebhook(mux, gitlabID, map[string]string{gitlabEventHeader: event, gitlabTokenHeader: token}, release)
+	}
+	if w := gitlab("Release Hook", "wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("bad token: status = %d, want 401", w.Code)
+	}
+	if w := gitlab("Pipeline Hook", gitlabToken); w.Code != http.StatusOK {
+		t.Fatalf("unsupported event: status = %d, want 200", w.Code)
+	}
+	w = gitlab("Release Hook", gitlabToken)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("release: status = %d, want 202; body=%s", w.Code, w.Body.String())
+	}
+	job, _ = jsonBody(t, w)["data"].(map[string]any)["job"].(map[string]any)
+	params, _ = job["parametersUsed"].(map[string]any)
+	if params["TAG"] != "v1.2.0" || params["COMMIT_SHA"] != "fff999" {
+		t.Fatalf("parametersUsed = %v, want event TAG and COMMIT_SHA", params)
+	}
+}
@@ -13,6 +13,8 @@ const (
 	keyEvent         = "event"
 	keyEvents        = "events"
 	keyGlobalRev     = "globalRev"
+	keyHooks         = "hooks"
+	keyIdentity      = "identity"
 	keyIndex         = "index"
 	keyJob           = "job"
 	keyJobID         = "jobId"
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
+
+// rateLimitPruneInterval is how often idle, full buckets are dropped.
+const rateLimitPruneInterval = time.Minute
+
+// rateLimiter keeps token buckets per caller. Each bucket holds up to a
+// minute's worth of requests and refills continuously, so short bursts pass
+// while a sustained flood is throttled to the per-minute rate.
+type rateLimiter struct {
+	mu        sync.Mutex
+	nowFn     func() time.Time
+	read      float64
+	mutate    float64
+	buckets   map[string]*rateBucket
+	lastPrune time.Time
+}
+
+type rateBucket struct {
+	tokens   float64
+	capacity float64
+	updated  time.Time
+}
+
+func newRateLimiter(readPerMinute, mutatePerMinute int) *rateLimiter {
+	return &rateLimiter{
+		nowFn:   time.Now,
+		read:    float64(readPerMinute),
+		mutate:  float64(mutatePerMinute),
+		buckets: make(map[string]*rateBucket),
+	}
+}
+
+// SetRateLimits enables per-token and per-IP request limits. Non-positive
+// limits disable rate limiting.
+func (h *Handler) SetRateLimits(readPerMinute, mutatePerMinute int) {
+	if h == nil {
+		return
+	}
+	if readPerMinute <= 0 || mutatePerMinute <= 0 {
+		h.limiter = nil
+		return
+	}
+	h.limiter = newRateLimiter(readPerMinute, mutatePerMinute)
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// allow takes one request from every bucket in keys, or from none of them.
+// When any bucket is empty it reports how long until all can serve.
+func (l *rateLimiter) allow(read bool, keys ...string) (bool, time.Duration) {
+	capacity, class := l.mutate, "mutate:"
+	if read {
+		capacity, class = l.read, "read:"
+	}
+	rate := capacity / time.Minute.Seconds()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.nowFn()
+	l.pruneLocked(now)
+
+	buckets := make([]*rateBucket, 0, len(keys))
+	var wait time.Duration
+	for _, key := range keys {
+		b := l.buckets[class+key]
+		if b == nil {
+			b = &rateBucket{tokens: capacity, capacity: capacity, updated: now}
+			l.buckets[class+key] = b
+		}
+		b.refill(now, rate)
+		if b.tokens < 1 {
+			need := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+			wait = max(wait, need)
+		}
+		buckets = append(buckets, b)
+	}
+	if wait > 0 {
+		return false, wait
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return true, 0
+}
+
+func (b *rateBucket) refill(now time.Time, rate float64) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*rate)
+		b.updated = now
+	}
+}
+
+// pruneLocked drops buckets that have refilled completely; recreating them
+// on the next request is equivalent.
+func (l *rateLimiter) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		rate := b.capacity / time.Minute.Seconds()
+		if b.tokens+now.Sub(b.updated).Seconds()*rate >= b.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// checkRateLimit charges the request to the bucket of key and writes 429
+// with Retry-After when it is exhausted.
+func (h *Handler) checkRateLimit(w http.ResponseWriter, r *http.Request, key string) bool {
+	if h.limiter == nil {
+		return true
+	}
+	ok, wait := h.limiter.allow(isReadMethod(r.Method), key)
+	if ok {
+		return true
+	}
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeError(w, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests", map[string]any{
+		"retryAfter": retryAfter,
+	})
+	return false
+}
+
+// checkClientRateLimit charges the request to its client IP bucket. It runs
+// before authentication, so floods of bad credentials are throttled too.
+func (h *Handler) checkClientRateLimit(w http.ResponseWriter, r *http.Request) bool {
+	if h.limiter == nil {
+		return true
+	}
+	return h.checkRateLimit(w, r, "ip:"+h.guard.ClientIP(r))
+}
+
+// checkIdentityRateLimit charges an authenticated request to the bucket of
+// its identity.
+func (h *Handler) checkIdentityRateLimit(w http.ResponseWriter, r *http.Request, id security.Identity) bool {
+	return h.checkRateLimit(w, r, "id:"+id.Name)
+}
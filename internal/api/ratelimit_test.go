@@ -0,0 +1,130 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
+
+func TestRateLimiterBucketsRefill(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	l := newRateLimiter(60, 2)
+	l.nowFn = func() time.Time { return now }
+
+	for i := range 2 {
+		if ok, _ := l.allow(false, "id:owner"); !ok {
+			t.Fatalf("mutation %d denied within burst", i+1)
+		}
+	}
+	ok, wait := l.allow(false, "id:owner")
+	if ok || wait != 30*time.Second {
+		t.Fatalf("third mutation = %v, wait %s; want denied for 30s", ok, wait)
+	}
+	if ok, _ := l.allow(true, "id:owner"); !ok {
+		t.Fatal("read denied; reads and mutations must use separate buckets")
+	}
+	if ok, _ := l.allow(false, "id:other"); !ok {
+		t.Fatal("other identity denied; buckets must be per key")
+	}
+
+	now = now.Add(30 * time.Second)
+	if ok, _ := l.allow(false, "id:owner"); !ok {
+		t.Fatal("mutation denied after refill")
+	}
+}
+
+func TestRateLimiterChargesAllKeysOrNone(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	l := newRateLimiter(1, 1)
+	l.nowFn = func() time.Time { return now }
+
+	if ok, _ := l.allow(true, "id:a", "ip:1"); !ok {
+		t.Fatal("first request denied")
+	}
+	// ip:1 is exhausted, so id:b must not be charged.
+	if ok, _ := l.allow(true, "id:b", "ip:1"); ok {
+		t.Fatal("request from exhausted ip allowed")
+	}
+	if ok, _ := l.allow(true, "id:b", "ip:2"); !ok {
+		t.Fatal("id:b was charged by a denied request")
+	}
+}
+
+func TestWrapRateLimited(t *testing.T) {
+	t.Parallel()
+
+	h := &Handler{guard: security.New("", nil, security.CookieSecureAuto)}
+	h.SetRateLimits(1, 1)
+	wrapped := h.wrap(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	call := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/tmux/sessions", nil)
+		r.Host = "localhost:4040"
+		wrapped(w, r)
+		return w
+	}
+	if w := call(); w.Code != http.StatusOK {
+		t.Fatalf("first status = %d, want 200", w.Code)
+	}
+	w := call()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "60" {
+		t.Fatalf("Retry-After = %q, want 60", got)
+	}
+	body := jsonBody(t, w)
+	if errBody, _ := body["error"].(map[string]any); errBody["code"] != "RATE_LIMITED" {
+		t.Fatalf("body = %v, want RATE_LIMITED", body)
+	}
+
+	h.SetRateLimits(0, 0)
+	if w := call(); w.Code != http.StatusOK {
+		t.Fatalf("status after disabling = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimitChargesClientBeforeAuth(t *testing.T) {
+	t.Parallel()
+
+	h := &Handler{guard: security.New("secret", nil, security.CookieSecureAuto)}
+	h.SetRateLimits(2, 2)
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+	h.registerRoutes(mux, []routeBinding{{pattern: "GET /api/tmux/sessions", handler: ok}})
+	h.registerPublicRoutes(mux, []routeBinding{{pattern: "GET /healthz", handler: ok}})
+
+	call := func(path, token string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Host = "localhost:4040"
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		mux.ServeHTTP(w, r)
+		return w.Code
+	}
+	// Bad tokens spend the client's budget, so guessing is throttled and
+	// the valid token is turned away from the same address as well.
+	for _, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		if got := call("/api/tmux/sessions", "guess"); got != want {
+			t.Fatalf("bad token status = %d, want %d", got, want)
+		}
+	}
+	if got := call("/api/tmux/sessions", "secret"); got != http.StatusTooManyRequests {
+		t.Fatalf("valid token status = %d, want 429", got)
+	}
+	if got := call("/healthz", ""); got != http.StatusTooManyRequests {
+		t.Fatalf("public route status = %d, want 429", got)
+	}
+}
@@ -1,20 +1,50 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
 
 type routeBinding struct {
 	pattern string
 	handler http.HandlerFunc
+	// role overrides the minimum role derived from the HTTP method.
+	role security.Role
+}
+
+// requiredRole returns the minimum role for the route: reads need viewer,
+// mutations need operator unless the binding asks for more (or less).
+func (b routeBinding) requiredRole() security.Role {
+	if b.role != "" {
+		return b.role
+	}
+	method, _, _ := strings.Cut(b.pattern, " ")
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return security.RoleViewer
+	default:
+		return security.RoleOperator
+	}
 }
 
 func (h *Handler) registerRoutes(mux *http.ServeMux, routes []routeBinding) {
 	for _, route := range routes {
-		mux.HandleFunc(route.pattern, h.wrap(route.handler))
+		mux.HandleFunc(route.pattern, h.wrapRole(route.requiredRole(), route.handler))
 	}
 }
 
+// registerPublicRoutes mounts routes that authenticate on their own, or not
+// at all. They are still rate limited per client IP.
 func (h *Handler) registerPublicRoutes(mux *http.ServeMux, routes []routeBinding) {
 	for _, route := range routes {
-		mux.HandleFunc(route.pattern, h.wrapOrigin(route.handler))
+		handler := route.handler
+		mux.HandleFunc(route.pattern, h.wrapOrigin(func(w http.ResponseWriter, r *http.Request) {
+			if !h.checkClientRateLimit(w, r) {
+				return
+			}
+			handler(w, r)
+		}))
 	}
 }
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
+
+// registerDebugRoutes mounts runtime diagnostics. Profiles expose memory
+// contents and command lines, so every route needs an admin.
+func (h *Handler) registerDebugRoutes(mux *http.ServeMux) {
+	h.registerRoutes(mux, []routeBinding{
+		{pattern: "GET /api/debug/runtime", handler: h.debugRuntime, role: security.RoleAdmin},
+		{pattern: "GET /api/debug/goroutines", handler: h.debugGoroutines, role: security.RoleAdmin},
+		{pattern: "POST /api/debug/gc", handler: h.debugGC, role: security.RoleAdmin},
+		{pattern: "GET /api/debug/vars", handler: expvar.Handler().ServeHTTP, role: security.RoleAdmin},
+		{pattern: "GET /api/debug/pprof/{$}", handler: pprof.Index, role: security.RoleAdmin},
+		{pattern: "GET /api/debug/pprof/cmdline", handler: pprof.Cmdline, role: security.RoleAdmin},
+		{pattern: "GET /api/debug/pprof/profile", handler: pprof.Profile, role: security.RoleAdmin},
+		{pattern: "GET /api/debug/pprof/symbol", handler: pprof.Symbol, role: security.RoleAdmin},
+		{pattern: "POST /api/debug/pprof/symbol", handler: pprof.Symbol, role: security.RoleAdmin},
+		{pattern: "GET /api/debug/pprof/trace", handler: pprof.Trace, role: security.RoleAdmin},
+		{pattern: "GET /api/debug/pprof/{profile}", handler: debugProfile, role: security.RoleAdmin},
+	})
+}
@@ -1,16 +1,42 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
 
 func (h *Handler) registerMetaRoutes(mux *http.ServeMux) {
 	h.registerPublicRoutes(mux, []routeBinding{
 		{pattern: "PUT /api/auth/token", handler: h.setAuthToken},
 		{pattern: "DELETE /api/auth/token", handler: h.clearAuthToken},
+		{pattern: "GET /healthz", handler: h.healthz},
+		{pattern: "GET /readyz", handler: h.readyz},
 	})
 
 	h.registerRoutes(mux, []routeBinding{
-		{pattern: "POST /api/connection/check", handler: h.connectionCheck},
+		{pattern: "POST /api/connection/check", handler: h.connectionCheck, role: security.RoleViewer},
 		{pattern: "GET /api/meta", handler: h.meta},
+		{pattern: "GET /api/events/stream", handler: h.streamEvents},
 		{pattern: "GET /api/fs/dirs", handler: h.listDirectories},
+		{pattern: "GET /api/fs/files", handler: h.listFiles},
+		{pattern: "GET /api/fs/files/download", handler: h.downloadFile},
+		{pattern: "POST /api/fs/files/upload", handler: h.uploadFile},
+		{pattern: "POST /api/fs/files/rename", handler: h.renameFile, role: security.RoleAdmin},
+		{pattern: "DELETE /api/fs/files", handler: h.deleteFile, role: security.RoleAdmin},
+		{pattern: "GET /api/auth/keys", handler: h.listAPIKeys, role: security.RoleAdmin},
+		{pattern: "POST /api/auth/keys", handler: h.createAPIKey, role: security.RoleAdmin},
+		{pattern: "DELETE /api/auth/keys/{key}", handler: h.deleteAPIKey, role: security.RoleAdmin},
+		{pattern: "GET /api/hosts", handler: h.listHosts},
+		{pattern: "GET /api/ops/hosts", handler: h.listOpsHosts},
+		{pattern: "POST /api/ops/hosts", handler: h.setOpsHostLabels, role: security.RoleAdmin},
+		{pattern: "DELETE /api/ops/hosts/{host}", handler: h.deleteOpsHost, role: security.RoleAdmin},
 	})
+
+	// The agent enforces each relayed route's own role.
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		h.registerRoutes(mux, []routeBinding{
+			{pattern: method + " /api/hosts/{host}/{path...}", handler: h.proxyHost, role: security.RoleViewer},
+		})
+	}
 }
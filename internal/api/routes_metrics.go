@@ -5,5 +5,8 @@ import "net/http"
 func (h *Handler) registerMetricsRoutes(mux *http.ServeMux) {
 	h.registerRoutes(mux, []routeBinding{
 		{pattern: "GET /api/ops/metrics", handler: h.opsMetrics},
+		{pattern: "GET /api/ops/metrics/history", handler: h.opsMetricsHistory},
+		{pattern: "GET /api/ops/disk", handler: h.opsDisk},
+		{pattern: "GET /api/ops/ups", handler: h.opsUPS},
 	})
 }
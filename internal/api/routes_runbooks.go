@@ -1,22 +1,53 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
 
 func (h *Handler) registerRunbooksRoutes(mux *http.ServeMux) {
+	// Webhook callers authenticate with the webhook's signature and
+	// heartbeat pings with the heartbeat's random id.
+	h.registerPublicRoutes(mux, []routeBinding{
+		{pattern: "POST /api/hooks/{hook}", handler: h.receiveWebhook},
+		{pattern: "POST /api/hooks/heartbeat/{heartbeat}", handler: h.pingHeartbeat},
+	})
+
 	h.registerRoutes(mux, []routeBinding{
 		{pattern: "GET /api/ops/runbooks", handler: h.opsRunbooks},
-		{pattern: "POST /api/ops/runbooks", handler: h.createOpsRunbook},
-		{pattern: "PUT /api/ops/runbooks/{runbook}", handler: h.updateOpsRunbook},
-		{pattern: "DELETE /api/ops/runbooks/{runbook}", handler: h.deleteOpsRunbook},
+		{pattern: "POST /api/ops/runbooks", handler: h.createOpsRunbook, role: security.RoleAdmin},
+		{pattern: "PUT /api/ops/runbooks/{runbook}", handler: h.updateOpsRunbook, role: security.RoleAdmin},
+		{pattern: "DELETE /api/ops/runbooks/{runbook}", handler: h.deleteOpsRunbook, role: security.RoleAdmin},
 		{pattern: "POST /api/ops/runbooks/{runbook}/run", handler: h.runOpsRunbook},
+		{pattern: "POST /api/ops/runbooks/{runbook}/dry-run", handler: h.dryRunOpsRunbook},
 		{pattern: "GET /api/ops/jobs/{job}", handler: h.opsJob},
-		{pattern: "DELETE /api/ops/jobs/{job}", handler: h.deleteOpsJob},
+		{pattern: "GET /api/ops/jobs/{job}/logs/stream", handler: h.streamOpsJobLogs},
+		{pattern: "POST /api/ops/jobs/{job}/cancel", handler: h.cancelOpsJob},
+		{pattern: "DELETE /api/ops/jobs/{job}", handler: h.deleteOpsJob, role: security.RoleAdmin},
 		{pattern: "POST /api/ops/runs/{runId}/approve", handler: h.approveOpsRunbookRun},
 		{pattern: "POST /api/ops/runs/{runId}/reject", handler: h.rejectOpsRunbookRun},
 		{pattern: "GET /api/ops/schedules", handler: h.listSchedules},
-		{pattern: "POST /api/ops/schedules", handler: h.createSchedule},
-		{pattern: "PUT /api/ops/schedules/{schedule}", handler: h.updateSchedule},
-		{pattern: "DELETE /api/ops/schedules/{schedule}", handler: h.deleteSchedule},
+		{pattern: "POST /api/ops/schedules", handler: h.createSchedule, role: security.RoleAdmin},
+		{pattern: "POST /api/ops/schedules/preview", handler: h.previewSchedule, role: security.RoleViewer},
+		{pattern: "PUT /api/ops/schedules/{schedule}", handler: h.updateSchedule, role: security.RoleAdmin},
+		{pattern: "DELETE /api/ops/schedules/{schedule}", handler: h.deleteSchedule, role: security.RoleAdmin},
 		{pattern: "POST /api/ops/schedules/{schedule}/trigger", handler: h.triggerSchedule},
+		{pattern: "GET /api/ops/schedules/{schedule}/history", handler: h.scheduleHistory},
+		{pattern: "GET /api/ops/webhooks", handler: h.listWebhooks},
+		{pattern: "POST /api/ops/webhooks", handler: h.createWebhook, role: security.RoleAdmin},
+		{pattern: "PUT /api/ops/webhooks/{webhook}", handler: h.updateWebhook, role: security.RoleAdmin},
+		{pattern: "DELETE /api/ops/webhooks/{webhook}", handler: h.deleteWebhook, role: security.RoleAdmin},
+		{pattern: "GET /api/ops/heartbeats", handler: h.listHeartbeats},
+		{pattern: "POST /api/ops/heartbeats", handler: h.createHeartbeat, role: security.RoleAdmin},
+		{pattern: "PUT /api/ops/heartbeats/{heartbeat}", handler: h.updateHeartbeat, role: security.RoleAdmin},
+		{pattern: "DELETE /api/ops/heartbeats/{heartbeat}", handler: h.deleteHeartbeat, role: security.RoleAdmin},
+		{pattern: "GET /api/ops/backups", handler: h.listBackups},
+		{pattern: "POST /api/ops/backups", handler: h.createBackup, role: security.RoleAdmin},
+		{pattern: "PUT /api/ops/backups/{backup}", handler: h.updateBackup, role: security.RoleAdmin},
+		{pattern: "DELETE /api/ops/backups/{backup}", handler: h.deleteBackup, role: security.RoleAdmin},
+		{pattern: "POST /api/ops/backups/{backup}/run", handler: h.runBackup},
+		{pattern: "GET /api/ops/library", handler: h.libraryStatus},
+		{pattern: "POST /api/ops/library/sync", handler: h.syncLibrary, role: security.RoleAdmin},
 	})
 }
@@ -1,20 +1,39 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
 
 func (h *Handler) registerServicesRoutes(mux *http.ServeMux) {
-	h.registerRoutes(mux, []routeBinding{
+	h.registerRoutes(mux, h.servicesRoutes())
+}
+
+func (h *Handler) servicesRoutes() []routeBinding {
+	return []routeBinding{
 		{pattern: "GET /api/ops/overview", handler: h.opsOverview},
 		{pattern: "GET /api/ops/services", handler: h.opsServices},
-		{pattern: "POST /api/ops/services", handler: h.registerOpsService},
-		{pattern: "DELETE /api/ops/services/{service}", handler: h.unregisterOpsService},
+		{pattern: "GET /api/ops/delta", handler: h.opsDelta},
+		{pattern: "GET /api/ops/ports", handler: h.opsPorts},
+		{pattern: "GET /api/ops/logins", handler: h.opsLogins},
+		{pattern: "GET /api/ops/packages", handler: h.opsPackages},
+		{pattern: "GET /api/ops/certificates", handler: h.opsCertificates},
+		{pattern: "GET /api/ops/uptime", handler: h.listUptimeChecks},
+		{pattern: "POST /api/ops/uptime", handler: h.createUptimeCheck, role: security.RoleAdmin},
+		{pattern: "PUT /api/ops/uptime/{check}", handler: h.updateUptimeCheck, role: security.RoleAdmin},
+		{pattern: "DELETE /api/ops/uptime/{check}", handler: h.deleteUptimeCheck, role: security.RoleAdmin},
+		{pattern: "POST /api/ops/services", handler: h.registerOpsService, role: security.RoleAdmin},
+		{pattern: "DELETE /api/ops/services/{service}", handler: h.unregisterOpsService, role: security.RoleAdmin},
 		{pattern: "GET /api/ops/services/browse", handler: h.browseOpsServices},
 		{pattern: "GET /api/ops/services/discover", handler: h.discoverOpsServices},
 		{pattern: "GET /api/ops/services/{service}/status", handler: h.opsServiceStatus},
-		{pattern: "POST /api/ops/services/{service}/action", handler: h.opsServiceAction},
+		{pattern: "POST /api/ops/services/{service}/action", handler: h.opsServiceAction, role: security.RoleAdmin},
 		{pattern: "GET /api/ops/services/{service}/logs", handler: h.opsServiceLogs},
-		{pattern: "POST /api/ops/services/unit/action", handler: h.opsUnitAction},
+		{pattern: "GET /api/ops/services/{service}/logs/stream", handler: h.streamOpsServiceLogs},
+		{pattern: "POST /api/ops/services/unit/action", handler: h.opsUnitAction, role: security.RoleAdmin},
 		{pattern: "GET /api/ops/services/unit/status", handler: h.opsUnitStatus},
 		{pattern: "GET /api/ops/services/unit/logs", handler: h.opsUnitLogs},
-	})
+		{pattern: "GET /api/ops/services/unit/logs/stream", handler: h.streamOpsUnitLogs},
+	}
 }
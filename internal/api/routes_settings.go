@@ -1,16 +1,30 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
 
 func (h *Handler) registerSettingsRoutes(mux *http.ServeMux) {
 	h.registerRoutes(mux, []routeBinding{
-		{pattern: "GET /api/ops/config", handler: h.opsConfig},
-		{pattern: "PATCH /api/ops/config", handler: h.patchOpsConfig},
-		{pattern: "PATCH /api/ops/settings/timezone", handler: h.patchTimezone},
-		{pattern: "PATCH /api/ops/settings/locale", handler: h.patchLocale},
-		{pattern: "GET /api/ops/settings/mcp", handler: h.getMCPSettings},
-		{pattern: "PATCH /api/ops/settings/mcp", handler: h.patchMCPSettings},
+		{pattern: "GET /api/ops/config", handler: h.opsConfig, role: security.RoleAdmin},
+		{pattern: "PATCH /api/ops/config", handler: h.patchOpsConfig, role: security.RoleAdmin},
+		{pattern: "POST /api/ops/config/validate", handler: h.validateOpsConfig, role: security.RoleAdmin},
+		{pattern: "PATCH /api/ops/settings/timezone", handler: h.patchTimezone, role: security.RoleAdmin},
+		{pattern: "PATCH /api/ops/settings/locale", handler: h.patchLocale, role: security.RoleAdmin},
+		{pattern: "GET /api/ops/settings/mcp", handler: h.getMCPSettings, role: security.RoleAdmin},
+		{pattern: "PATCH /api/ops/settings/mcp", handler: h.patchMCPSettings, role: security.RoleAdmin},
 		{pattern: "GET /api/ops/storage/stats", handler: h.storageStats},
-		{pattern: "POST /api/ops/storage/flush", handler: h.flushStorage},
+		{pattern: "POST /api/ops/storage/flush", handler: h.flushStorage, role: security.RoleAdmin},
+		{pattern: "GET /api/ops/storage/backups", handler: h.listStorageBackups, role: security.RoleAdmin},
+		{pattern: "POST /api/ops/storage/backup", handler: h.backupStorage, role: security.RoleAdmin},
+		{pattern: "POST /api/ops/storage/maintain", handler: h.maintainStorage, role: security.RoleAdmin},
+		{pattern: "GET /api/ops/secrets", handler: h.listSecrets, role: security.RoleAdmin},
+		{pattern: "POST /api/ops/secrets", handler: h.createSecret, role: security.RoleAdmin},
+		{pattern: "PUT /api/ops/secrets/{secret}", handler: h.updateSecret, role: security.RoleAdmin},
+		{pattern: "DELETE /api/ops/secrets/{secret}", handler: h.deleteSecret, role: security.RoleAdmin},
+		{pattern: "GET /api/ops/update/check", handler: h.checkUpdate, role: security.RoleAdmin},
+		{pattern: "POST /api/ops/update/apply", handler: h.applyUpdate, role: security.RoleAdmin},
 	})
 }
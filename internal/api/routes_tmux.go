@@ -1,12 +1,22 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
 
 func (h *Handler) registerTmuxRoutes(mux *http.ServeMux) {
-	h.registerRoutes(mux, []routeBinding{
+	h.registerRoutes(mux, h.tmuxRoutes())
+}
+
+func (h *Handler) tmuxRoutes() []routeBinding {
+	return []routeBinding{
 		{pattern: "GET /api/tmux/sessions", handler: h.listSessions},
 		{pattern: "POST /api/tmux/sessions", handler: h.createSession},
 		{pattern: "PATCH /api/tmux/sessions/order", handler: h.reorderSessions},
+		{pattern: "POST /api/tmux/sessions/bulk", handler: h.bulkSessions},
+		{pattern: "GET /api/tmux/sessions/usage", handler: h.sessionsUsage},
 		{pattern: "GET /api/tmux/session-presets", handler: h.listSessionPresets},
 		{pattern: "POST /api/tmux/session-presets", handler: h.createSessionPreset},
 		{pattern: "PATCH /api/tmux/session-presets/order", handler: h.reorderSessionPresets},
@@ -26,23 +36,49 @@ func (h *Handler) registerTmuxRoutes(mux *http.ServeMux) {
 		{pattern: "DELETE /api/tmux/launchers/{launcher}", handler: h.deleteTmuxLauncher},
 		{pattern: "POST /api/tmux/sessions/{session}/launchers/{launcher}/launch", handler: h.launchTmuxLauncher},
 		{pattern: "PATCH /api/tmux/sessions/{session}", handler: h.renameSession},
-		{pattern: "DELETE /api/tmux/sessions/{session}", handler: h.deleteSession},
+		{pattern: "DELETE /api/tmux/sessions/{session}", handler: h.deleteSession, role: security.RoleAdmin},
 		{pattern: "PATCH /api/tmux/sessions/{session}/icon", handler: h.setSessionIcon},
+		{pattern: "PATCH /api/tmux/sessions/{session}/tags", handler: h.setSessionTags},
+		{pattern: "PUT /api/tmux/sessions/{session}/notes", handler: h.setSessionNotes},
+		{pattern: "GET /api/tmux/sessions/{session}/environment", handler: h.sessionEnvironment},
+		{pattern: "PUT /api/tmux/sessions/{session}/environment/{name}", handler: h.setSessionEnvironment},
+		{pattern: "DELETE /api/tmux/sessions/{session}/environment/{name}", handler: h.unsetSessionEnvironment},
 		{pattern: "POST /api/tmux/sessions/{session}/rename-window", handler: h.renameWindow},
 		{pattern: "POST /api/tmux/sessions/{session}/rename-pane", handler: h.renamePane},
 		{pattern: "POST /api/tmux/sessions/{session}/select-window", handler: h.selectWindow},
 		{pattern: "POST /api/tmux/sessions/{session}/select-pane", handler: h.selectPane},
 		{pattern: "POST /api/tmux/sessions/{session}/new-window", handler: h.newWindow},
 		{pattern: "PATCH /api/tmux/sessions/{session}/windows/order", handler: h.reorderWindows},
-		{pattern: "POST /api/tmux/sessions/{session}/kill-window", handler: h.killWindow},
-		{pattern: "POST /api/tmux/sessions/{session}/kill-pane", handler: h.killPane},
+		{pattern: "POST /api/tmux/sessions/{session}/kill-window", handler: h.killWindow, role: security.RoleAdmin},
+		{pattern: "POST /api/tmux/sessions/{session}/kill-pane", handler: h.killPane, role: security.RoleAdmin},
 		{pattern: "POST /api/tmux/sessions/{session}/split-pane", handler: h.splitPane},
+		{pattern: "POST /api/tmux/sessions/{session}/move-window", handler: h.moveWindow},
+		{pattern: "POST /api/tmux/sessions/{session}/swap-pane", handler: h.swapPane},
+		{pattern: "POST /api/tmux/sessions/{session}/rotate-window", handler: h.rotateWindow},
 		{pattern: "GET /api/tmux/sessions/{session}/windows", handler: h.listWindows},
 		{pattern: "GET /api/tmux/sessions/{session}/panes", handler: h.listPanes},
-		{pattern: "POST /api/tmux/sessions/{session}/seen", handler: h.markSessionSeen},
-		{pattern: "PUT /api/tmux/presence", handler: h.setTmuxPresence},
+		{pattern: "GET /api/tmux/sessions/{session}/panes/{pane}/capture", handler: h.capturePaneRange},
+		{pattern: "GET /api/tmux/sessions/{session}/panes/{pane}/history", handler: h.paneHistory},
+		{pattern: "POST /api/tmux/sessions/{session}/panes/{pane}/send-keys", handler: h.sendPaneKeys},
+		{pattern: "POST /api/tmux/sessions/{session}/panes/{pane}/zoom", handler: h.zoomPane},
+		{pattern: "POST /api/tmux/sessions/{session}/panes/{pane}/signal", handler: h.signalPane, role: security.RoleAdmin},
+		{pattern: "POST /api/tmux/sessions/{session}/seen", handler: h.markSessionSeen, role: security.RoleViewer},
+		{pattern: "PUT /api/tmux/presence", handler: h.setTmuxPresence, role: security.RoleViewer},
 		{pattern: "GET /api/tmux/frequent-dirs", handler: h.frequentDirectories},
+		{pattern: "GET /api/tmux/buffers", handler: h.listBuffers},
+		{pattern: "GET /api/tmux/buffers/{buffer}", handler: h.getBuffer},
+		{pattern: "PUT /api/tmux/buffers/{buffer}", handler: h.setBuffer},
+		{pattern: "POST /api/tmux/buffers/{buffer}/paste", handler: h.pasteBuffer},
 		{pattern: "GET /api/tmux/activity/delta", handler: h.activityDelta},
 		{pattern: "GET /api/tmux/activity/stats", handler: h.activityStats},
-	})
+		{pattern: "GET /api/tmux/activity/heatmap", handler: h.activityHeatmap},
+		{pattern: "GET /api/tmux/watch-rules", handler: h.listWatchRules},
+		{pattern: "POST /api/tmux/watch-rules", handler: h.createWatchRule, role: security.RoleAdmin},
+		{pattern: "PUT /api/tmux/watch-rules/{rule}", handler: h.updateWatchRule, role: security.RoleAdmin},
+		{pattern: "DELETE /api/tmux/watch-rules/{rule}", handler: h.deleteWatchRule, role: security.RoleAdmin},
+		// Recordings hold terminal input, so reading them needs admin.
+		{pattern: "GET /api/tmux/recordings", handler: h.listRecordings, role: security.RoleAdmin},
+		{pattern: "GET /api/tmux/recordings/{recording}", handler: h.downloadRecording, role: security.RoleAdmin},
+		{pattern: "DELETE /api/tmux/recordings/{recording}", handler: h.deleteRecording, role: security.RoleAdmin},
+	}
 }
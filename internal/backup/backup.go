@@ -0,0 +1,374 @@
+// Package backup runs restic and borg backups through managed runbooks on
+// their cron schedules, records the stats of each run and flags backups
+// that failed or have not succeeded for too long.
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/runbook"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+// Backup tools.
+const (
+	ToolRestic = "restic"
+	ToolBorg   = "borg"
+)
+
+// Change actions.
+const (
+	ActionStarted   = "started"
+	ActionSucceeded = store.BackupStatusSucceeded
+	ActionFailed    = store.BackupStatusFailed
+	ActionStale     = "stale"
+)
+
+const (
+	// TickInterval is how often due, finished and stale backups are looked
+	// for.
+	TickInterval = 30 * time.Second
+
+	DefaultSchedule       = "0 3 * * *"
+	DefaultMaxAgeHours    = 48
+	DefaultTimeoutMinutes = 360
+
+	maxAgeHoursLimit    = 366 * 24
+	timeoutMinutesLimit = 24 * 60
+
+	runSource       = "backup"
+	runbookIDPrefix = "backup-"
+
+	// runStatusSucceeded is the status of a runbook run that succeeded.
+	runStatusSucceeded = "succeeded"
+)
+
+// Default command templates. {{REPOSITORY}}, {{SOURCES}} and {{NAME}} are
+// replaced with the shell-quoted repository, sources and backup name.
+var defaultCommands = map[string]string{
+	ToolRestic: "restic -r {{REPOSITORY}} backup --json {{SOURCES}}",
+	ToolBorg:   "borg create --json {{REPOSITORY}}::{hostname}-{now} {{SOURCES}}",
+}
+
+var (
+	// ErrInvalidBackup is returned for a malformed backup.
+	ErrInvalidBackup = errors.New("invalid backup")
+	// ErrRunning is returned when a backup is started while it runs.
+	ErrRunning = errors.New("backup is already running")
+)
+
+// Repo stores backups and their managed runbooks and reads the runs.
+type Repo interface {
+	ListOpsBackups(ctx context.Context) ([]store.OpsBackup, error)
+	GetOpsBackup(ctx context.Context, id string) (store.OpsBackup, error)
+	DeleteOpsBackup(ctx context.Context, id string) error
+	MarkOpsBackupStarted(ctx context.Context, id, jobID string, at time.Time) error
+	RecordOpsBackupResult(ctx context.Context, id string, r store.OpsBackupResult) (bool, error)
+	SetOpsBackupStale(ctx context.Context, id string, stale bool) (bool, error)
+	InsertOpsRunbook(ctx context.Context, w store.OpsRunbookWrite) (store.OpsRunbook, error)
+	UpdateOpsRunbook(ctx context.Context, w store.OpsRunbookWrite) (store.OpsRunbook, error)
+	DeleteOpsRunbook(ctx context.Context, id, expectedName string) (store.OpsRunbookDeleteResult, error)
+	GetOpsRunbookRun(ctx context.Context, id string) (store.OpsRunbookRun, error)
+}
+
+// Runner starts runbook runs; *runbook.Manager implements it.
+type Runner interface {
+	StartWithTimeout(ctx context.Context, runbookID string, params map[string]string, source string, runTimeout time.Duration) (store.OpsRunbookRun, error)
+}
+
+// Change is a backup that started, finished or went stale.
+type Change struct {
+	Action string
+	Backup store.OpsBackup
+}
+
+// Service schedules backups and tracks their runs.
+type Service struct {
+	repo  Repo
+	runs  Runner
+	nowFn func() time.Time
+}
+
+// New returns a Service storing backups in repo and running them on runs.
+func New(repo Repo, runs Runner) *Service {
+	return &Service{repo: repo, runs: runs, nowFn: time.Now}
+}
+
+// RunbookID returns the id of the runbook a backup runs through.
+func RunbookID(backupID string) string {
+	return runbookIDPrefix + backupID
+}
+
+// Validate rejects backups that cannot be run.
+func Validate(w store.OpsBackupWrite) error {
+	switch {
+	case strings.TrimSpace(w.Name) == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidBackup)
+	case defaultCommands[w.Tool] == "":
+		return fmt.Errorf("%w: tool must be %s or %s", ErrInvalidBackup, ToolRestic, ToolBorg)
+	case strings.TrimSpace(w.Repository) == "":
+		return fmt.Errorf("%w: repository is required", ErrInvalidBackup)
+	case w.MaxAgeHours < 1 || w.MaxAgeHours > maxAgeHoursLimit:
+		return fmt.Errorf("%w: maxAgeHours must be between 1 and %d", ErrInvalidBackup, maxAgeHoursLimit)
+	case w.TimeoutMinutes < 1 || w.TimeoutMinutes > timeoutMinutesLimit:
+		return fmt.Errorf("%w: timeoutMinutes must be between 1 and %d", ErrInvalidBackup, timeoutMinutesLimit)
+	}
+	if w.Command == "" && len(w.Sources) == 0 {
+		return fmt.Errorf("%w: at least one source is required", ErrInvalidBackup)
+	}
+	for _, source := range w.Sources {
+		if strings.TrimSpace(source) == "" {
+			return fmt.Errorf("%w: sources must not be empty", ErrInvalidBackup)
+		}
+	}
+	if err := validate.CronExpression(w.Schedule); err != nil {
+		return fmt.Errorf("%w: schedule: %w", ErrInvalidBackup, err)
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidBackup, w.Timezone)
+	}
+	return nil
+}
+
+// Command renders the shell command a backup runs: its own template, or
+// the tool's default, with the placeholders replaced.
+func Command(b store.OpsBackup) string {
+	tmpl := b.Command
+	if tmpl == "" {
+		tmpl = defaultCommands[b.Tool]
+	}
+	sources := make([]string, len(b.Sources))
+	for i, source := range b.Sources {
+		sources[i] = runbook.ShellEscape(source)
+	}
+	return strings.NewReplacer(
+		"{{REPOSITORY}}", runbook.ShellEscape(b.Repository),
+		"{{SOURCES}}", strings.Join(sources, " "),
+		"{{NAME}}", runbook.ShellEscape(b.Name),
+	).Replace(tmpl)
+}
+
+// Run starts a backup now, outside its schedule.
+func (s *Service) Run(ctx context.Context, id string) (store.OpsBackup, error) {
+	b, err := s.repo.GetOpsBackup(ctx, id)
+	if err != nil {
+		return store.OpsBackup{}, err
+	}
+	if b.Status == store.BackupStatusRunning {
+		return store.OpsBackup{}, ErrRunning
+	}
+	change, err := s.start(ctx, b, s.nowFn().UTC())
+	return change.Backup, err
+}
+
+// Delete removes a backup and its managed runbook. A running backup is
+// refused with store.ErrOpsRunbookActive.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	if _, err := s.repo.DeleteOpsRunbook(ctx, RunbookID(strings.TrimSpace(id)), ""); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return s.repo.DeleteOpsBackup(ctx, id)
+}
+
+// Check records the outcome of finished runs, starts the enabled backups
+// whose schedule is due and flags the ones without a recent success. It
+// returns what changed.
+func (s *Service) Check(ctx context.Context) ([]Change, error) {
+	list, err := s.repo.ListOpsBackups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.nowFn().UTC()
+	var changes []Change
+	for _, b := range list {
+		if ctx.Err() != nil {
+			return changes, ctx.Err()
+		}
+		if b.Status == store.BackupStatusRunning {
+			change, ok, err := s.reconcile(ctx, b)
+			if err != nil {
+				return changes, err
+			}
+			if ok {
+				changes = append(changes, change)
+				b = change.Backup
+			}
+		}
+		if !b.Enabled {
+			continue
+		}
+		if b.Status != store.BackupStatusRunning && due(b, now) {
+			change, err := s.start(ctx, b, now)
+			if err != nil && change.Action == "" {
+				return changes, err
+			}
+			changes = append(changes, change)
+			b = change.Backup
+		}
+		stale := now.Sub(staleSince(b)) > time.Duration(b.MaxAgeHours)*time.Hour
+		changed, err := s.repo.SetOpsBackupStale(ctx, b.ID, stale)
+		if err != nil {
+			return changes, err
+		}
+		if changed && stale {
+			b.Stale = true
+			changes = append(changes, Change{Action: ActionStale, Backup: b})
+		}
+	}
+	return changes, nil
+}
+
+// start runs a backup through its managed runbook. A run that cannot
+// start is recorded as failed and returned as a failed change along with
+// the error.
+func (s *Service) start(ctx context.Context, b store.OpsBackup, now time.Time) (Change, error) {
+	job, err := s.startRun(ctx, b)
+	if err != nil {
+		if markErr := s.repo.MarkOpsBackupStarted(ctx, b.ID, "", now); markErr != nil {
+			return Change{}, markErr
+		}
+		if _, recErr := s.repo.RecordOpsBackupResult(ctx, b.ID, store.OpsBackupResult{
+			Status: store.BackupStatusFailed, Error: err.Error(), FinishedAt: now,
+		}); recErr != nil {
+			return Change{}, recErr
+		}
+		b.Status, b.LastJobID, b.LastRunAt, b.LastFinishedAt, b.LastError = store.BackupStatusFailed, "", now, now, err.Error()
+		return Change{Action: ActionFailed, Backup: b}, err
+	}
+	if err := s.repo.MarkOpsBackupStarted(ctx, b.ID, job.ID, now); err != nil {
+		return Change{}, err
+	}
+	b.Status, b.LastJobID, b.LastRunAt = store.BackupStatusRunning, job.ID, now
+	return Change{Action: ActionStarted, Backup: b}, nil
+}
+
+func (s *Service) startRun(ctx context.Context, b store.OpsBackup) (store.OpsRunbookRun, error) {
+	write := store.OpsRunbookWrite{
+		ID:          RunbookID(b.ID),
+		Name:        "Backup: " + b.Name,
+		Description: "Managed by the backup " + b.Name + "; edit the backup instead.",
+		Steps: []store.OpsRunbookStep{{
+			Type:    "run",
+			Title:   b.Tool + " backup",
+			Command: Command(b),
+			Timeout: b.TimeoutMinutes * 60,
+		}},
+		Enabled: true,
+	}
+	if err := runbook.ValidateDefinition(write); err != nil {
+		return store.OpsRunbookRun{}, err
+	}
+	if _, err := s.repo.UpdateOpsRunbook(ctx, write); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return store.OpsRunbookRun{}, err
+		}
+		if _, err := s.repo.InsertOpsRunbook(ctx, write); err != nil {
+			return store.OpsRunbookRun{}, err
+		}
+	}
+	// The step timeout bounds the tool; the run gets a minute on top to
+	// record the outcome.
+	timeout := time.Duration(b.TimeoutMinutes)*time.Minute + time.Minute
+	return s.runs.StartWithTimeout(ctx, write.ID, nil, runSource, timeout)
+}
+
+// reconcile records the outcome of a backup's run once it finished.
+func (s *Service) reconcile(ctx context.Context, b store.OpsBackup) (Change, bool, error) {
+	result := store.OpsBackupResult{JobID: b.LastJobID, FinishedAt: s.nowFn().UTC()}
+	job, err := s.repo.GetOpsRunbookRun(ctx, b.LastJobID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		result.Status, result.Error = store.BackupStatusFailed, "backup run not found"
+	case err != nil:
+		return Change{}, false, err
+	case !runbook.IsTerminalStatus(job.Status):
+		return Change{}, false, nil
+	default:
+		if finished, err := time.Parse(time.RFC3339, job.FinishedAt); err == nil {
+			result.FinishedAt = finished
+		}
+		output := runOutput(job)
+		if job.Status == runStatusSucceeded {
+			result.Status = store.BackupStatusSucceeded
+			result.Stats = ParseStats(b.Tool, output)
+		} else {
+			result.Status, result.Error = store.BackupStatusFailed, runError(job)
+		}
+	}
+	ok, err := s.repo.RecordOpsBackupResult(ctx, b.ID, result)
+	if err != nil || !ok {
+		return Change{}, false, err
+	}
+	b.Status, b.LastFinishedAt, b.LastError = result.Status, result.FinishedAt, result.Error
+	if result.Status == store.BackupStatusSucceeded {
+		b.LastSuccessAt, b.Stats, b.Stale = result.FinishedAt, result.Stats, false
+	}
+	return Change{Action: result.Status, Backup: b}, true, nil
+}
+
+// due reports whether a cron occurrence of the backup's schedule passed
+// since its last run, or since it was created.
+func due(b store.OpsBackup, now time.Time) bool {
+	sched, err := validate.ParseCron(b.Schedule)
+	if err != nil {
+		return false
+	}
+	loc, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	since := b.LastRunAt
+	if since.IsZero() {
+		since = b.CreatedAt
+	}
+	return !sched.Next(since.In(loc)).After(now)
+}
+
+func staleSince(b store.OpsBackup) time.Time {
+	if b.LastSuccessAt.IsZero() {
+		return b.CreatedAt
+	}
+	return b.LastSuccessAt
+}
+
+func runOutput(job store.OpsRunbookRun) string {
+	var out strings.Builder
+	for _, step := range job.StepResults {
+		out.WriteString(step.Output)
+	}
+	return out.String()
+}
+
+// runError describes why a run failed, preferring the tool's own last
+// line of output over the generic exit status.
+func runError(job store.OpsRunbookRun) string {
+	for i := len(job.StepResults) - 1; i >= 0; i-- {
+		step := job.StepResults[i]
+		if step.Error == "" {
+			continue
+		}
+		if line := lastLine(step.Output); line != "" {
+			return step.Error + ": " + line
+		}
+		return step.Error
+	}
+	if job.Error != "" {
+		return job.Error
+	}
+	return "backup run " + job.Status
+}
+
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	line := strings.TrimSpace(lines[len(lines)-1])
+	if len(line) > 300 {
+		line = line[:300]
+	}
+	return line
+}
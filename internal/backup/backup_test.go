@@ -0,0 +1,165 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/runbook"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func TestValidateAndCommand(t *testing.T) {
+	t.Parallel()
+
+	valid := store.OpsBackupWrite{
+		Name: "home", Tool: ToolRestic, Repository: "/srv/restic", Sources: []string{"/home"},
+		Schedule: DefaultSchedule, Timezone: "UTC", MaxAgeHours: DefaultMaxAgeHours, TimeoutMinutes: DefaultTimeoutMinutes,
+	}
+	if err := Validate(valid); err != nil {
+		t.Fatalf("Validate(valid) = %v", err)
+	}
+	for name, mutate := range map[string]func(*store.OpsBackupWrite){
+		"tool":     func(w *store.OpsBackupWrite) { w.Tool = "tar" },
+		"sources":  func(w *store.OpsBackupWrite) { w.Sources = nil },
+		"schedule": func(w *store.OpsBackupWrite) { w.Schedule = "every day" },
+		"timezone": func(w *store.OpsBackupWrite) { w.Timezone = "Mars/Base" },
+		"max age":  func(w *store.OpsBackupWrite) { w.MaxAgeHours = 0 },
+	} {
+		w := valid
+		mutate(&w)
+		if err := Validate(w); !errors.Is(err, ErrInvalidBackup) {
+			t.Errorf("Validate(bad %s) = %v, want ErrInvalidBackup", name, err)
+		}
+	}
+
+	got := Command(store.OpsBackup{Tool: ToolBorg, Repository: "ssh://nas/backups", Sources: []string{"/srv/my files", "/etc"}})
+	want := `borg create --json 'ssh://nas/backups'::{hostname}-{now} '/srv/my files' '/etc'`
+	if got != want {
+		t.Fatalf("Command(borg) = %q, want %q", got, want)
+	}
+}
+
+func TestParseStats(t *testing.T) {
+	t.Parallel()
+
+	restic := `{"message_type":"status","percent_done":0.5}
+{"message_type":"summary","files_new":3,"files_changed":2,"files_unmodified":10,"data_added":4096,"total_bytes_processed":1048576,"total_duration":12.5,"snapshot_id":"1a2b3c4d5e6f"}
+`
+	if got := ParseStats(ToolRestic, restic); got.SnapshotID != "1a2b3c4d5e6f" || got.Files != 15 || got.FilesNew != 3 ||
+		got.BytesAdded != 4096 || got.BytesProcessed != 1048576 || got.DurationSeconds != 12.5 {
+		t.Fatalf("ParseStats(restic json) = %+v", got)
+	}
+	if got := ParseStats(ToolRestic, "Files: 1 new\nsnapshot 9f8e7d6c saved\n"); got.SnapshotID != "9f8e7d6c" {
+		t.Fatalf("ParseStats(restic text) = %+v", got)
+	}
+
+	borg := `Warning: {not json
+{
+  "archive": {"name": "host-2026-02-15T03:00:00", "duration": 4.2,
+    "stats": {"original_size": 2000, "compressed_size": 1500, "deduplicated_size": 300, "nfiles": 42}},
+  "repository": {"location": "/srv/borg"}
+}
+`
+	if got := ParseStats(ToolBorg, borg); got.SnapshotID != "host-2026-02-15T03:00:00" || got.Files != 42 ||
+		got.BytesProcessed != 2000 || got.BytesAdded != 300 || got.DurationSeconds != 4.2 {
+		t.Fatalf("ParseStats(borg) = %+v", got)
+	}
+}
+
+func TestServiceRunsAndTracksBackups(t *testing.T) {
+	t.Parallel()
+
+	st, err := store.New(filepath.Join(t.TempDir(), "sentinel.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	runs := runbook.NewManager(st, nil, 1)
+	t.Cleanup(func() { runs.Shutdown(context.Background()) })
+	ctx := context.Background()
+
+	summary := `{"message_type":"summary","files_new":1,"data_added":512,"snapshot_id":"cafe1234"}`
+	created, err := st.CreateOpsBackup(ctx, store.OpsBackupWrite{
+		Name: "home", Tool: ToolRestic, Repository: "/srv/restic",
+		Command:  "echo " + runbook.ShellEscape(summary),
+		Schedule: "0 3 * * *", Timezone: "UTC", MaxAgeHours: 24, TimeoutMinutes: 5, Enabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := New(st, runs)
+	now := created.CreatedAt.Add(2 * time.Hour)
+	svc.nowFn = func() time.Time { return now }
+
+	// Not due before the next 03:00 after creation.
+	if next := nextRun(t, created); now.Before(next) {
+		changes, err := svc.Check(ctx)
+		if err != nil || len(changes) != 0 {
+			t.Fatalf("Check() before due = %+v, %v", changes, err)
+		}
+	}
+
+	now = created.CreatedAt.Add(25 * time.Hour)
+	changes, err := svc.Check(ctx)
+	if err != nil || len(changes) != 2 || changes[0].Action != ActionStarted || changes[1].Action != ActionStale {
+		t.Fatalf("Check() when due = %+v, %v; want started and stale", changes, err)
+	}
+	runs.WaitIdle()
+
+	// The run finished on the real clock.
+	now = time.Now()
+	changes, err = svc.Check(ctx)
+	if err != nil || len(changes) != 1 || changes[0].Action != ActionSucceeded {
+		t.Fatalf("Check() after the run = %+v, %v; want succeeded", changes, err)
+	}
+	got := changes[0].Backup
+	if got.Stale || got.Stats.SnapshotID != "cafe1234" || got.Stats.BytesAdded != 512 {
+		t.Fatalf("succeeded backup = %+v", got)
+	}
+	rb, err := st.GetOpsRunbook(ctx, RunbookID(created.ID))
+	if err != nil || len(rb.Steps) != 1 || rb.Steps[0].Timeout != 300 {
+		t.Fatalf("managed runbook = %+v, %v", rb, err)
+	}
+
+	// A manual run of a failing command is reported with its output.
+	if _, err := st.UpdateOpsBackup(ctx, store.OpsBackupWrite{
+		ID: created.ID, Name: "home", Tool: ToolRestic, Repository: "/srv/restic",
+		Command:  "echo 'Fatal: repository is locked'; exit 1",
+		Schedule: "0 3 * * *", Timezone: "UTC", MaxAgeHours: 24, TimeoutMinutes: 5, Enabled: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Run(ctx, created.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if _, err := svc.Run(ctx, created.ID); !errors.Is(err, ErrRunning) {
+		t.Fatalf("second Run() error = %v, want ErrRunning", err)
+	}
+	runs.WaitIdle()
+	changes, err = svc.Check(ctx)
+	if err != nil || len(changes) != 1 || changes[0].Action != ActionFailed {
+		t.Fatalf("Check() after the failed run = %+v, %v; want failed", changes, err)
+	}
+	if got := changes[0].Backup; got.LastError == "" || got.Stats.SnapshotID != "cafe1234" {
+		t.Fatalf("failed backup = %+v, want the error and the last success kept", got)
+	}
+
+	if err := svc.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := st.GetOpsRunbook(ctx, RunbookID(created.ID)); err == nil {
+		t.Fatal("managed runbook survived Delete()")
+	}
+}
+
+func nextRun(t *testing.T, b store.OpsBackup) time.Time {
+	t.Helper()
+	next := time.Date(b.CreatedAt.Year(), b.CreatedAt.Month(), b.CreatedAt.Day(), 3, 0, 0, 0, time.UTC)
+	if !next.After(b.CreatedAt) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
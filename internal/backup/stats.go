@@ -0,0 +1,103 @@
+package backup
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+// resticSnapshotPattern matches the line plain restic output ends with.
+var resticSnapshotPattern = regexp.MustCompile(`snapshot ([0-9a-f]{8,64}) saved`)
+
+// resticSummary is the last message of `restic backup --json`.
+type resticSummary struct {
+	MessageType         string  `json:"message_type"`
+	FilesNew            int64   `json:"files_new"`
+	FilesChanged        int64   `json:"files_changed"`
+	FilesUnmodified     int64   `json:"files_unmodified"`
+	DataAdded           int64   `json:"data_added"`
+	TotalBytesProcessed int64   `json:"total_bytes_processed"`
+	TotalDuration       float64 `json:"total_duration"`
+	SnapshotID          string  `json:"snapshot_id"`
+}
+
+// borgCreate is the document `borg create --json` prints.
+type borgCreate struct {
+	Archive struct {
+		Name     string  `json:"name"`
+		ID       string  `json:"id"`
+		Duration float64 `json:"duration"`
+		Stats    struct {
+			OriginalSize     int64 `json:"original_size"`
+			DeduplicatedSize int64 `json:"deduplicated_size"`
+			NFiles           int64 `json:"nfiles"`
+		} `json:"stats"`
+	} `json:"archive"`
+}
+
+// ParseStats reads the figures a backup tool reported in its output. Output
+// it does not recognise, such as that of a custom command without --json,
+// yields what could be found, possibly nothing.
+func ParseStats(tool, output string) store.OpsBackupStats {
+	switch tool {
+	case ToolRestic:
+		return parseRestic(output)
+	case ToolBorg:
+		return parseBorg(output)
+	}
+	return store.OpsBackupStats{}
+}
+
+func parseRestic(output string) store.OpsBackupStats {
+	var stats store.OpsBackupStats
+	for line := range strings.Lines(output) {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var msg resticSummary
+		if json.Unmarshal([]byte(line), &msg) != nil || msg.MessageType != "summary" {
+			continue
+		}
+		stats = store.OpsBackupStats{
+			SnapshotID:      msg.SnapshotID,
+			Files:           msg.FilesNew + msg.FilesChanged + msg.FilesUnmodified,
+			FilesNew:        msg.FilesNew,
+			FilesChanged:    msg.FilesChanged,
+			BytesProcessed:  msg.TotalBytesProcessed,
+			BytesAdded:      msg.DataAdded,
+			DurationSeconds: msg.TotalDuration,
+		}
+	}
+	if stats.SnapshotID == "" {
+		if m := resticSnapshotPattern.FindStringSubmatch(output); m != nil {
+			stats.SnapshotID = m[1]
+		}
+	}
+	return stats
+}
+
+// parseBorg decodes the JSON document borg prints, which may be preceded
+// by warnings on stderr.
+func parseBorg(output string) store.OpsBackupStats {
+	for i := strings.Index(output, "{"); i >= 0; {
+		var doc borgCreate
+		if json.NewDecoder(strings.NewReader(output[i:])).Decode(&doc) == nil && doc.Archive.Name != "" {
+			return store.OpsBackupStats{
+				SnapshotID:      doc.Archive.Name,
+				Files:           doc.Archive.Stats.NFiles,
+				BytesProcessed:  doc.Archive.Stats.OriginalSize,
+				BytesAdded:      doc.Archive.Stats.DeduplicatedSize,
+				DurationSeconds: doc.Archive.Duration,
+			}
+		}
+		next := strings.Index(output[i+1:], "\n{")
+		if next < 0 {
+			break
+		}
+		i += next + 2
+	}
+	return store.OpsBackupStats{}
+}
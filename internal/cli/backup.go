@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/humanize"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/spf13/cobra"
+)
+
+func newBackupCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Write a consistent snapshot of the Sentinel database",
+		Long: "Write a consistent snapshot of sentinel.db into the configured\n" +
+			"storage.backup_dir, keeping the newest storage.backup_keep snapshots.\n" +
+			"Safe to run while the daemon is up.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runBackup(cmd.Context(), app)
+		},
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List database snapshots, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runBackupList(app)
+		},
+	})
+	return cmd
+}
+
+func runBackup(ctx context.Context, app *App) error {
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return failf("backup failed: %w", err)
+	}
+	st, dbPath, err := openDBStore(cfg)
+	if err != nil {
+		return failf("backup failed: %w", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	backup, err := st.Backup(ctx, cfg.Storage.BackupDir, cfg.Storage.BackupKeep, time.Now())
+	if err != nil {
+		return failf("backup failed: %w", err)
+	}
+	reportHeader(app.Stdout, "backup", "created")
+	printRows(app.Stdout, []outputRow{
+		{Key: dbOutputKeyDatabase, Value: dbPath},
+		{Key: "backup", Value: backup.Name},
+		{Key: "path", Value: backup.Path},
+		{Key: "size", Value: humanize.Bytes(backup.SizeBytes)},
+		{Key: "keep", Value: fmt.Sprint(cfg.Storage.BackupKeep)},
+	})
+	return nil
+}
+
+func runBackupList(app *App) error {
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return failf("backup list failed: %w", err)
+	}
+	backups, err := store.ListBackups(cfg.Storage.BackupDir)
+	if err != nil {
+		return failf("backup list failed: %w", err)
+	}
+	rows := []outputRow{{Key: "dir", Value: cfg.Storage.BackupDir}}
+	for _, backup := range backups {
+		rows = append(rows, outputRow{
+			Key:   backup.Name,
+			Value: humanize.Bytes(backup.SizeBytes),
+		})
+	}
+	if len(backups) == 0 {
+		rows = append(rows, outputRow{Key: "backups", Value: "none"})
+	}
+	reportHeader(app.Stdout, "backup", "list")
+	printRows(app.Stdout, rows)
+	return nil
+}
+
+func newRestoreCmd(app *App) *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "restore <backup>",
+		Short: "Restore the Sentinel database from a snapshot on next start",
+		Long: "Stage a snapshot from storage.backup_dir to replace sentinel.db. The\n" +
+			"daemon applies it the next time it starts, before opening the\n" +
+			"database; restart the service to complete the restore.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if !yes {
+				return failf("refusing to restore storage without --yes")
+			}
+			return runRestore(app, args[0])
+		},
+	}
+	cmd.Flags().BoolVar(&yes, "yes", false, "confirm replacing the current database")
+	return cmd
+}
+
+func runRestore(app *App, name string) error {
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return failf("restore failed: %w", err)
+	}
+	backup, err := store.StageRestore(cfg.Storage.BackupDir, name)
+	if err != nil {
+		if errors.Is(err, store.ErrBackupNotFound) || errors.Is(err, store.ErrInvalidBackupName) {
+			return failf("restore failed: %w: %s (see `sentinel backup list`)", err, name)
+		}
+		return failf("restore failed: %w", err)
+	}
+	reportHeader(app.Stdout, "restore", "staged")
+	printRows(app.Stdout, []outputRow{
+		{Key: dbOutputKeyDatabase, Value: cfg.Storage.Path},
+		{Key: "backup", Value: backup.Path},
+		{Key: cmdStatus, Value: "applies on next daemon start"},
+	})
+	return nil
+}
@@ -931,6 +931,7 @@ func TestDoctorHelpAndArgs(t *testing.T) {
 
 // TestDoctorStatusError covers the doctor command when service status fails.
 func TestDoctorStatusError(t *testing.T) {
+	stubDoctorProbes(t)
 	origLoad := loadConfigFn
 	origStatus := serviceStatusFn
 	t.Cleanup(func() {
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -26,11 +27,34 @@ const (
 	testScopeSystem     = "system"
 )
 
-func stubDoctorLookPath(t *testing.T) {
+func stubDoctorProbes(t *testing.T) {
 	t.Helper()
-	original := doctorLookPath
+	origLookPath := doctorLookPath
+	origCommand := doctorCommandOutput
+	origListen := doctorListen
+	origIntegrity := doctorCheckIntegrity
+	origNow := doctorNow
+	t.Cleanup(func() {
+		doctorLookPath = origLookPath
+		doctorCommandOutput = origCommand
+		doctorListen = origListen
+		doctorCheckIntegrity = origIntegrity
+		doctorNow = origNow
+	})
 	doctorLookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
-	t.Cleanup(func() { doctorLookPath = original })
+	doctorCommandOutput = func(name string, _ ...string) (string, error) {
+		switch filepath.Base(name) {
+		case "tmux":
+			return "tmux 3.4", nil
+		case "timedatectl":
+			return "yes", nil
+		default:
+			return "", nil
+		}
+	}
+	doctorListen = func(network, _ string) (net.Listener, error) { return net.Listen(network, "127.0.0.1:0") }
+	doctorCheckIntegrity = func(context.Context, string) ([]string, error) { return nil, nil }
+	doctorNow = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
 }
 
 func stubUserServiceInstallContext(t *testing.T) {
@@ -611,6 +635,62 @@ func TestRunCLIDBResetForceRejectsResource(t *testing.T) {
 	}
 }
 
+func TestRunCLIBackupListAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SENTINEL_DATA_DIR", dir)
+	backupDir := filepath.Join(dir, "backups")
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	if code := Run([]string{"backup"}, &out, &errOut); code != 0 {
+		t.Fatalf("backup exit code = %d, want 0 (stderr: %s)", code, errOut.String())
+	}
+	backups, err := store.ListBackups(backupDir)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("backups = %v, %v; want one", backups, err)
+	}
+	name := backups[0].Name
+	for _, fragment := range []string{"backup: " + name, "path: " + backups[0].Path} {
+		if !strings.Contains(out.String(), fragment) {
+			t.Fatalf("stdout missing %q: %s", fragment, out.String())
+		}
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := Run([]string{"backup", "list"}, &out, &errOut); code != 0 {
+		t.Fatalf("backup list exit code = %d, want 0 (stderr: %s)", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), name+":") {
+		t.Fatalf("backup list missing %q: %s", name, out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := Run([]string{"restore", name}, &out, &errOut); code != 1 {
+		t.Fatalf("restore without --yes exit code = %d, want 1", code)
+	}
+	if code := Run([]string{"restore", "--yes", "sentinel-19700101T000000Z.db"}, &out, &errOut); code != 1 {
+		t.Fatalf("restore unknown exit code = %d, want 1", code)
+	}
+	if !strings.Contains(errOut.String(), "backup not found") {
+		t.Fatalf("unexpected stderr: %s", errOut.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := Run([]string{"restore", "--yes", name}, &out, &errOut); code != 0 {
+		t.Fatalf("restore exit code = %d, want 0 (stderr: %s)", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "applies on next daemon start") {
+		t.Fatalf("unexpected stdout: %s", out.String())
+	}
+	restored, err := store.ApplyPendingRestore(filepath.Join(dir, "sentinel.db"), backupDir)
+	if err != nil || restored != name {
+		t.Fatalf("ApplyPendingRestore = %q, %v; want %q", restored, err, name)
+	}
+}
+
 func TestRunCLIServiceInstallParsesFlags(t *testing.T) {
 	stubUserServiceInstallContext(t)
 	origInstall := installUserSvcFn
@@ -803,7 +883,7 @@ func TestRunCLIServiceStatusSystemUnitLabel(t *testing.T) {
 }
 
 func TestRunCLIDoctor(t *testing.T) {
-	stubDoctorLookPath(t)
+	stubDoctorProbes(t)
 	home := t.TempDir()
 	t.Setenv("HOME", home)
 	origLoad := loadConfigFn
@@ -852,6 +932,10 @@ func TestRunCLIDoctor(t *testing.T) {
 		"data dir: /tmp/.sentinel",
 		"token required: true",
 		"user unit file: /tmp/sentinel.service",
+		"tmux version: tmux 3.4",
+		"database: integrity ok",
+		"listen port: held by the running service",
+		"remote exposure: false",
 	} {
 		if !strings.Contains(text, fragment) {
 			t.Fatalf("output missing %q:\n%s", fragment, text)
@@ -859,8 +943,47 @@ func TestRunCLIDoctor(t *testing.T) {
 	}
 }
 
+func TestDoctorProbesReportProblems(t *testing.T) {
+	stubDoctorProbes(t)
+	doctorCommandOutput = func(string, ...string) (string, error) { return "tmux 2.9a", nil }
+	doctorCheckIntegrity = func(context.Context, string) ([]string, error) {
+		return []string{"row 3 missing from index idx_sessions"}, nil
+	}
+	doctorListen = func(string, string) (net.Listener, error) {
+		return nil, errors.New("address already in use")
+	}
+	doctorNow = func() time.Time { return time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	cfg := testCLIConfig("/tmp/.sentinel", "")
+	cfg.Server.Host = "0.0.0.0"
+
+	var issues []string
+	for _, inspect := range []func() ([]outputRow, []string){
+		func() ([]outputRow, []string) { return inspectDoctorTmuxVersion("/usr/bin/tmux") },
+		func() ([]outputRow, []string) { return inspectDoctorDatabase(cfg.Storage.Path) },
+		func() ([]outputRow, []string) { return inspectDoctorPort(cfg.Address(), false) },
+		func() ([]outputRow, []string) { return inspectDoctorSecurity(cfg) },
+		inspectDoctorClock,
+	} {
+		_, found := inspect()
+		issues = append(issues, found...)
+	}
+	text := strings.Join(issues, "\n")
+	for _, fragment := range []string{
+		"tmux 2.9a is older than tmux 3.2",
+		"row 3 missing from index idx_sessions",
+		"cannot listen on 0.0.0.0:4040: address already in use",
+		"0.0.0.0:4040 is reachable beyond loopback without a token",
+		"system clock reads 2000-01-01T00:00:00Z",
+	} {
+		if !strings.Contains(text, fragment) {
+			t.Fatalf("issues missing %q:\n%s", fragment, text)
+		}
+	}
+}
+
 func TestRunCLIDoctorReportsFailedAutoUpdate(t *testing.T) {
-	stubDoctorLookPath(t)
+	stubDoctorProbes(t)
 	origLoad := loadConfigFn
 	origStatus := serviceStatusFn
 	origDeployments := installedDeploymentsFn
@@ -915,7 +1038,7 @@ func TestRunCLIDoctorReportsFailedAutoUpdate(t *testing.T) {
 }
 
 func TestRunCLIDoctorFailsWithExactConfigDiagnosis(t *testing.T) {
-	stubDoctorLookPath(t)
+	stubDoctorProbes(t)
 	origLoad := loadConfigFn
 	origLoadPath := loadConfigPathFn
 	origStatus := serviceStatusFn
@@ -984,7 +1107,7 @@ func TestRunCLIDoctorFailsWithExactConfigDiagnosis(t *testing.T) {
 }
 
 func TestRunCLIDoctorSystemUnitLabel(t *testing.T) {
-	stubDoctorLookPath(t)
+	stubDoctorProbes(t)
 	origLoad := loadConfigFn
 	origStatus := serviceStatusFn
 	origDeployments := installedDeploymentsFn
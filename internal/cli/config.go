@@ -306,6 +306,8 @@ type configShowOutput struct {
 	MCP          config.MCPConfig       `json:"mcp"`
 	Runbooks     config.RunbooksConfig  `json:"runbooks"`
 	MultiUser    configShowMultiUser    `json:"multi_user"`
+	Updates      config.UpdatesConfig   `json:"updates"`
+	Federation   configShowFederation   `json:"federation"`
 	SystemUsers  []string               `json:"system_users"`
 }
 
@@ -342,6 +344,15 @@ type configShowHealthReport struct {
 	Schedule   string `json:"schedule"`
 }
 
+// configShowFederation mirrors config.FederationConfig with the token
+// redacted.
+type configShowFederation struct {
+	Token      string                 `json:"token"`
+	CentralURL string                 `json:"central_url"`
+	Name       string                 `json:"name"`
+	SSHHosts   []config.SSHHostConfig `json:"ssh_hosts"`
+}
+
 func newConfigShowOutput(cfg config.Config) configShowOutput {
 	return configShowOutput{
 		Version: cfg.Version,
@@ -369,6 +380,13 @@ func newConfigShowOutput(cfg config.Config) configShowOutput {
 			AllowRootTarget:  cfg.MultiUser.AllowRootTarget,
 			UserSwitchMethod: cfg.MultiUser.UserSwitchMethod,
 		},
+		Updates: cfg.Updates,
+		Federation: configShowFederation{
+			Token:      redactConfigSecret(cfg.Federation.Token),
+			CentralURL: cfg.Federation.CentralURL,
+			Name:       cfg.Federation.Name,
+			SSHHosts:   append([]config.SSHHostConfig{}, cfg.Federation.SSHHosts...),
+		},
 		SystemUsers: nonNilStrings(cfg.SystemUsers),
 		Watchtower: configShowWatchtower{
 			Enabled:        cfg.Watchtower.Enabled,
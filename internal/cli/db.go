@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/opus-domini/sentinel/internal/config"
 	"github.com/opus-domini/sentinel/internal/humanize"
@@ -12,7 +13,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var storeNewFn = store.New
+var storeOpenFn = store.Open
 
 const dbOutputKeyDatabase = "database"
 
@@ -106,6 +107,9 @@ func runDBStatus(ctx context.Context, app *App) error {
 		{Key: "wal size", Value: humanize.Bytes(stats.WALBytes)},
 		{Key: "shm size", Value: humanize.Bytes(stats.SHMBytes)},
 		{Key: "total size", Value: humanize.Bytes(stats.TotalBytes)},
+		{Key: "journal mode", Value: stats.Database.JournalMode},
+		{Key: "synchronous", Value: stats.Database.Synchronous},
+		{Key: "busy timeout", Value: humanize.Duration(time.Duration(stats.Database.BusyTimeoutMs) * time.Millisecond)},
 	}
 	for _, stat := range stats.Resources {
 		rows = append(rows, outputRow{
@@ -138,7 +142,7 @@ func newDBResetCmd(app *App) *cobra.Command {
 	}
 	cmd.Flags().BoolVar(&yes, "yes", false, "confirm flushing local runtime storage")
 	cmd.Flags().BoolVar(&force, "force", false, "delete and recreate the SQLite database")
-	cmd.Flags().StringVar(&resource, "resource", store.StorageResourceAll, "resource to flush: activity-journal, ops-jobs, or all")
+	cmd.Flags().StringVar(&resource, "resource", store.StorageResourceAll, "resource to flush: activity-journal, ops-jobs, metrics-history, or all")
 	return cmd
 }
 
@@ -194,7 +198,7 @@ func runDBResetForce(app *App) error {
 	if err != nil {
 		return failf("db reset failed: %w", err)
 	}
-	st, err := storeNewFn(dbPath)
+	st, err := storeOpenFn(dbPath, cfg.Storage.StoreOptions())
 	if err != nil {
 		return failf("db reset failed: %w", err)
 	}
@@ -230,7 +234,7 @@ func removeDBFiles(dbPath string) ([]string, error) {
 
 func openDBStore(cfg config.Config) (*store.Store, string, error) {
 	dbPath := cfg.Storage.Path
-	st, err := storeNewFn(dbPath)
+	st, err := storeOpenFn(dbPath, cfg.Storage.StoreOptions())
 	if err != nil {
 		return nil, dbPath, err
 	}
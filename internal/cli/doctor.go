@@ -97,6 +97,9 @@ func runDoctor(app *App) error {
 	}
 	if tmuxErr == nil {
 		rows = append(rows, outputRow{Key: "tmux", Value: tmuxPath})
+		versionRows, versionIssues := inspectDoctorTmuxVersion(tmuxPath)
+		rows = append(rows, versionRows...)
+		issues = append(issues, versionIssues...)
 	} else {
 		rows = append(rows, outputRow{Key: "tmux", Value: "not found"})
 		issues = append(issues, "tmux binary was not found in PATH")
@@ -107,6 +110,21 @@ func runDoctor(app *App) error {
 		rows = append(rows, outputRow{Key: managerLabel, Value: "not found"})
 		issues = append(issues, managerLabel+" was not found in PATH")
 	}
+	serviceActive := false
+	for _, s := range report {
+		serviceActive = serviceActive || s.ActiveState == stateActive
+	}
+	for _, inspect := range []func() ([]outputRow, []string){
+		func() ([]outputRow, []string) { return inspectDoctorDatabase(cfg.Storage.Path) },
+		inspectDoctorJournal,
+		func() ([]outputRow, []string) { return inspectDoctorPort(cfg.Address(), serviceActive) },
+		func() ([]outputRow, []string) { return inspectDoctorSecurity(cfg) },
+		inspectDoctorClock,
+	} {
+		checkRows, checkIssues := inspect()
+		rows = append(rows, checkRows...)
+		issues = append(issues, checkIssues...)
+	}
 	switch {
 	case statusErr != nil:
 		rows = append(rows, outputRow{Key: "service status", Value: fmt.Sprintf("unavailable (%v)", statusErr)})
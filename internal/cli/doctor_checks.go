@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/config"
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+const (
+	doctorProbeTimeout = 5 * time.Second
+	// doctorMinTmuxMajor/Minor is the oldest tmux with the clipboard and
+	// extended-keys behavior the browser terminal relies on.
+	doctorMinTmuxMajor = 3
+	doctorMinTmuxMinor = 2
+	// doctorClockFloorYear catches hosts booted without a real-time clock.
+	doctorClockFloorYear = 2025
+)
+
+// Test indirections for the doctor probes.
+var (
+	doctorCommandOutput  = runDoctorCommand
+	doctorListen         = net.Listen
+	doctorCheckIntegrity = store.CheckIntegrity
+	doctorNow            = time.Now
+)
+
+var tmuxVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+func runDoctorCommand(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), doctorProbeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// inspectDoctorTmuxVersion reports the tmux version and flags releases older
+// than the supported minimum. Development builds ("tmux master") pass.
+func inspectDoctorTmuxVersion(tmuxPath string) ([]outputRow, []string) {
+	out, err := doctorCommandOutput(tmuxPath, "-V")
+	if err != nil {
+		return []outputRow{{Key: "tmux version", Value: "unknown"}},
+			[]string{fmt.Sprintf("tmux -V failed: %v", err)}
+	}
+	rows := []outputRow{{Key: "tmux version", Value: out}}
+	match := tmuxVersionPattern.FindStringSubmatch(out)
+	if match == nil {
+		return rows, nil
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	if major < doctorMinTmuxMajor || (major == doctorMinTmuxMajor && minor < doctorMinTmuxMinor) {
+		return rows, []string{fmt.Sprintf(
+			"%s is older than tmux %d.%d; upgrade tmux for clipboard and key passthrough in the browser terminal",
+			out, doctorMinTmuxMajor, doctorMinTmuxMinor,
+		)}
+	}
+	return rows, nil
+}
+
+// inspectDoctorDatabase runs a read-only integrity check on the configured
+// database. A database that was never created is not a problem.
+func inspectDoctorDatabase(dbPath string) ([]outputRow, []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), doctorProbeTimeout)
+	defer cancel()
+	problems, err := doctorCheckIntegrity(ctx, dbPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return []outputRow{{Key: dbOutputKeyDatabase, Value: "not created yet"}}, nil
+	case err != nil:
+		return []outputRow{{Key: dbOutputKeyDatabase, Value: "unreadable"}},
+			[]string{fmt.Sprintf("database %s could not be checked: %v", dbPath, err)}
+	case len(problems) > 0:
+		return []outputRow{{Key: dbOutputKeyDatabase, Value: "corrupt"}},
+			[]string{fmt.Sprintf(
+				"database %s failed its integrity check (%s); restore a backup with `sentinel restore`",
+				dbPath, strings.Join(problems, "; "),
+			)}
+	default:
+		return []outputRow{{Key: dbOutputKeyDatabase, Value: "integrity ok"}}, nil
+	}
+}
+
+// inspectDoctorJournal checks that journald is readable on Linux, which the
+// Services page needs for unit logs. journalctl exits zero when it can only
+// read the caller's own journal, so its permission hint is matched instead.
+func inspectDoctorJournal() ([]outputRow, []string) {
+	if runtime.GOOS != "linux" {
+		return nil, nil
+	}
+	if _, err := doctorLookPath("journalctl"); err != nil {
+		return []outputRow{{Key: "journald", Value: "not found"}},
+			[]string{"journalctl was not found in PATH; service logs are unavailable"}
+	}
+	out, err := doctorCommandOutput("journalctl", "-n", "1", "--no-pager", "-q")
+	lower := strings.ToLower(out)
+	switch {
+	case err != nil:
+		return []outputRow{{Key: "journald", Value: "unreadable"}},
+			[]string{fmt.Sprintf("journalctl failed: %v", err)}
+	case strings.Contains(lower, "insufficient permissions"), strings.Contains(lower, "not seeing messages"):
+		return []outputRow{{Key: "journald", Value: "own journal only"}},
+			[]string{"journald only shows this user's journal; add the user to the systemd-journal group to read system service logs"}
+	default:
+		return []outputRow{{Key: "journald", Value: "readable"}}, nil
+	}
+}
+
+// inspectDoctorPort checks that the listen address can be bound. The check
+// is skipped while the Sentinel service runs, since it holds the port.
+func inspectDoctorPort(addr string, serviceActive bool) ([]outputRow, []string) {
+	if serviceActive {
+		return []outputRow{{Key: "listen port", Value: "held by the running service"}}, nil
+	}
+	ln, err := doctorListen("tcp", addr)
+	if err != nil {
+		return []outputRow{{Key: "listen port", Value: "unavailable"}},
+			[]string{fmt.Sprintf("cannot listen on %s: %v; stop the other process or change server.port", addr, err)}
+	}
+	_ = ln.Close()
+	return []outputRow{{Key: "listen port", Value: "available"}}, nil
+}
+
+// inspectDoctorSecurity flags remote exposure without a token and cookie
+// settings the daemon would refuse or only accept through an override.
+func inspectDoctorSecurity(cfg config.Config) ([]outputRow, []string) {
+	exposed := security.ExposesBeyondLoopback(cfg.Address())
+	rows := []outputRow{
+		{Key: "remote exposure", Value: strconv.FormatBool(exposed)},
+		{Key: "cookie secure", Value: cfg.Server.CookieSecure},
+	}
+	if !exposed {
+		return rows, nil
+	}
+	var issues []string
+	if cfg.Server.Token == "" {
+		issues = append(issues, fmt.Sprintf(
+			"%s is reachable beyond loopback without a token; set server.token or bind server.host to 127.0.0.1",
+			cfg.Address(),
+		))
+	} else if cfg.Server.CookieSecure == config.CookieSecureNever {
+		if cfg.Server.AllowInsecureCookie {
+			issues = append(issues, "auth cookies are sent without the Secure flag on a remote listener (allow_insecure_cookie); set server.cookie_secure to auto or always")
+		} else {
+			issues = append(issues, "server.cookie_secure=never with remote exposure and a token stops the daemon at startup; set it to auto or always")
+		}
+	}
+	return rows, issues
+}
+
+// inspectDoctorClock reports the host clock and, on systemd hosts, whether it
+// is NTP-synchronized. Schedules, metrics history and TLS certificate
+// checks all depend on a sane clock.
+func inspectDoctorClock() ([]outputRow, []string) {
+	now := doctorNow()
+	rows := []outputRow{{Key: "clock", Value: now.UTC().Format(time.RFC3339)}}
+	var issues []string
+	if now.Year() < doctorClockFloorYear {
+		issues = append(issues, fmt.Sprintf("system clock reads %s; set the time or enable NTP", now.UTC().Format(time.RFC3339)))
+	}
+	if runtime.GOOS != "linux" {
+		return rows, issues
+	}
+	if _, err := doctorLookPath("timedatectl"); err != nil {
+		return rows, issues
+	}
+	out, err := doctorCommandOutput("timedatectl", "show", "--property=NTPSynchronized", "--value")
+	if err != nil {
+		return rows, issues
+	}
+	synced := strings.TrimSpace(out) == "yes"
+	rows = append(rows, outputRow{Key: "clock synchronized", Value: strconv.FormatBool(synced)})
+	if !synced {
+		issues = append(issues, "system clock is not NTP-synchronized; enable systemd-timesyncd or chrony")
+	}
+	return rows, issues
+}
@@ -6,6 +6,7 @@ import "github.com/spf13/cobra"
 const (
 	groupSetup   = "setup"
 	groupService = "service"
+	groupClient  = "client"
 	groupExtra   = "additional"
 )
 
@@ -50,6 +51,7 @@ func applyHelpStyle(root *cobra.Command) {
 	root.AddGroup(
 		&cobra.Group{ID: groupSetup, Title: "SETUP COMMANDS"},
 		&cobra.Group{ID: groupService, Title: "SERVICE COMMANDS"},
+		&cobra.Group{ID: groupClient, Title: "CLIENT COMMANDS"},
 		&cobra.Group{ID: groupExtra, Title: "ADDITIONAL COMMANDS"},
 	)
 	root.SetHelpCommandGroupID(groupExtra)
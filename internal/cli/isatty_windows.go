@@ -0,0 +1,10 @@
+//go:build windows
+
+package cli
+
+import "golang.org/x/sys/windows"
+
+func isTerminal(fd uintptr) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(fd), &mode) == nil
+}
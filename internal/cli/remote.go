@@ -0,0 +1,356 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// Test indirections for the API client commands.
+var (
+	newAPIClientFn    = newAPIClient
+	jobPollIntervalFn = func() time.Duration { return time.Second }
+)
+
+// daemonAddress resolves the local daemon's base URL and token from the
+// effective config. Wildcard listen hosts are reached through loopback.
+func daemonAddress() (string, string, error) {
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return "", "", err
+	}
+	host := strings.TrimSpace(cfg.Server.Host)
+	switch host {
+	case "", "0.0.0.0", "::", "[::]":
+		host = "127.0.0.1"
+	}
+	baseURL := "http://" + net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(cfg.Server.Port))
+	return baseURL, cfg.Server.Token, nil
+}
+
+// newAPIClient returns a client for the local daemon.
+func newAPIClient() (*client.Client, error) {
+	baseURL, token, err := daemonAddress()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(baseURL, client.WithToken(token))
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// withClient runs fn with a client for the local daemon, prefixing failures
+// with label.
+func withClient(ctx context.Context, label string, fn func(context.Context, *client.Client) error) error {
+	c, err := newAPIClientFn()
+	if err != nil {
+		return failf("%s failed: %w", label, err)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := fn(ctx, c); err != nil {
+		var ee exitError
+		if errors.As(err, &ee) {
+			return err
+		}
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return failf("%s failed: sentinel daemon unreachable: %w", label, err)
+		}
+		return failf("%s failed: %w", label, err)
+	}
+	return nil
+}
+
+func newSessionsCmd(app *App) *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "List tmux sessions through the running daemon",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+	ls := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List tmux sessions",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runSessionsList(cmd.Context(), app, asJSON)
+		},
+	}
+	ls.Flags().BoolVar(&asJSON, "json", false, "print the API response as JSON")
+	cmd.AddCommand(ls)
+	return cmd
+}
+
+func runSessionsList(ctx context.Context, app *App, asJSON bool) error {
+	return withClient(ctx, "sessions ls", func(ctx context.Context, c *client.Client) error {
+		sessions, err := c.ListSessions(ctx, client.SessionFilter{})
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			return printJSON(app.Stdout, sessions)
+		}
+		if len(sessions) == 0 {
+			empty(app.Stdout, "no tmux sessions")
+			return nil
+		}
+		rows := make([]outputRow, 0, len(sessions))
+		for _, s := range sessions {
+			value := fmt.Sprintf("%d windows, %d panes", s.Windows, s.Panes)
+			if s.Attached > 0 {
+				value += ", attached"
+			}
+			rows = append(rows, outputRow{Key: s.Name, Value: value})
+		}
+		printRows(app.Stdout, rows)
+		return nil
+	})
+}
+
+func newSvcCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "svc",
+		Short: "Inspect and control tracked services through the running daemon",
+		Long: "Inspect and control the services tracked on the Services page. To\n" +
+			"manage Sentinel's own daemon service use `sentinel service`.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	var asJSON bool
+	ls := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List tracked services and their state",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runSvcList(cmd.Context(), app, asJSON)
+		},
+	}
+	ls.Flags().BoolVar(&asJSON, "json", false, "print the API response as JSON")
+	cmd.AddCommand(ls)
+
+	for _, action := range []string{client.ActionStart, client.ActionStop, client.ActionRestart} {
+		cmd.AddCommand(&cobra.Command{
+			Use:   action + " <service>",
+			Short: strings.ToUpper(action[:1]) + action[1:] + " a tracked service",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runSvcAction(cmd.Context(), app, args[0], action)
+			},
+		})
+	}
+
+	var query client.LogOptions
+	logs := &cobra.Command{
+		Use:   "logs <service>",
+		Short: "Print recent logs of a tracked service",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSvcLogs(cmd.Context(), app, args[0], query)
+		},
+	}
+	logs.Flags().IntVarP(&query.Lines, "lines", "n", 100, "number of newest lines")
+	logs.Flags().StringVar(&query.Since, "since", "", "window start (RFC3339 or unix seconds)")
+	logs.Flags().StringVar(&query.Until, "until", "", "window end (RFC3339 or unix seconds)")
+	logs.Flags().StringVar(&query.Priority, "priority", "", "minimum journald priority (e.g. err, warning)")
+	logs.Flags().StringVar(&query.Grep, "grep", "", "only lines matching this regular expression")
+	cmd.AddCommand(logs)
+	return cmd
+}
+
+func runSvcList(ctx context.Context, app *App, asJSON bool) error {
+	return withClient(ctx, "svc ls", func(ctx context.Context, c *client.Client) error {
+		services, err := c.ListServices(ctx)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			return printJSON(app.Stdout, services)
+		}
+		if len(services) == 0 {
+			empty(app.Stdout, "no tracked services")
+			return nil
+		}
+		rows := make([]outputRow, 0, len(services))
+		for _, svc := range services {
+			rows = append(rows, outputRow{Key: svc.Name, Value: svc.ActiveState})
+		}
+		printRows(app.Stdout, rows)
+		return nil
+	})
+}
+
+func runSvcAction(ctx context.Context, app *App, name, action string) error {
+	return withClient(ctx, "svc "+action, func(ctx context.Context, c *client.Client) error {
+		svc, err := c.ServiceAction(ctx, name, action)
+		if err != nil {
+			return err
+		}
+		done(app.Stdout, action, name)
+		printRows(app.Stdout, []outputRow{
+			{Key: "unit", Value: svc.Unit},
+			{Key: stateActive, Value: svc.ActiveState},
+		})
+		return nil
+	})
+}
+
+func runSvcLogs(ctx context.Context, app *App, name string, opts client.LogOptions) error {
+	return withClient(ctx, "svc logs", func(ctx context.Context, c *client.Client) error {
+		output, err := c.ServiceLogs(ctx, name, opts)
+		if err != nil {
+			return err
+		}
+		writef(app.Stdout, "%s", output)
+		if output != "" && !strings.HasSuffix(output, "\n") {
+			writeln(app.Stdout)
+		}
+		return nil
+	})
+}
+
+func newRunbookCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "runbook",
+		Short: "List and run runbooks through the running daemon",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	var asJSON bool
+	ls := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List runbooks",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runRunbookList(cmd.Context(), app, asJSON)
+		},
+	}
+	ls.Flags().BoolVar(&asJSON, "json", false, "print the API response as JSON")
+	cmd.AddCommand(ls)
+
+	var params []string
+	var wait bool
+	run := &cobra.Command{
+		Use:   "run <runbook>",
+		Short: "Start a runbook by name or ID",
+		Long: "Start a runbook by name or ID. Pass parameters with repeated\n" +
+			"--param key=value. With --wait, poll the job until it finishes or\n" +
+			"stops at an approval step, and exit non-zero unless it succeeded.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			values, err := parseRunbookParams(params)
+			if err != nil {
+				return failf("runbook run failed: %w", err)
+			}
+			return runRunbookRun(cmd.Context(), app, args[0], values, wait)
+		},
+	}
+	run.Flags().StringArrayVarP(&params, "param", "p", nil, "runbook parameter as key=value (repeatable)")
+	run.Flags().BoolVar(&wait, "wait", false, "wait for the job to finish")
+	cmd.AddCommand(run)
+	return cmd
+}
+
+func parseRunbookParams(raw []string) (map[string]string, error) {
+	values := make(map[string]string, len(raw))
+	for _, pair := range raw {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --param %q: want key=value", pair)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+func runRunbookList(ctx context.Context, app *App, asJSON bool) error {
+	return withClient(ctx, "runbook ls", func(ctx context.Context, c *client.Client) error {
+		runbooks, _, err := c.ListRunbooks(ctx)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			return printJSON(app.Stdout, runbooks)
+		}
+		if len(runbooks) == 0 {
+			empty(app.Stdout, "no runbooks")
+			return nil
+		}
+		rows := make([]outputRow, 0, len(runbooks))
+		for _, rb := range runbooks {
+			rows = append(rows, outputRow{Key: rb.Name, Value: rb.ID})
+		}
+		printRows(app.Stdout, rows)
+		return nil
+	})
+}
+
+func runRunbookRun(ctx context.Context, app *App, ref string, params map[string]string, wait bool) error {
+	return withClient(ctx, "runbook run", func(ctx context.Context, c *client.Client) error {
+		runbooks, _, err := c.ListRunbooks(ctx)
+		if err != nil {
+			return err
+		}
+		id := ""
+		for _, rb := range runbooks {
+			if rb.ID == ref || strings.EqualFold(rb.Name, ref) {
+				id = rb.ID
+				break
+			}
+		}
+		if id == "" {
+			return fmt.Errorf("runbook not found: %s (see `sentinel runbook ls`)", ref)
+		}
+
+		job, err := c.RunRunbook(ctx, id, client.RunOptions{Parameters: params})
+		if err != nil {
+			return err
+		}
+		if wait {
+			if job, err = c.WaitJob(ctx, job.ID, jobPollIntervalFn()); err != nil {
+				return err
+			}
+		}
+		reportHeader(app.Stdout, "runbook", job.RunbookName)
+		rows := []outputRow{
+			{Key: "job", Value: job.ID},
+			{Key: cmdStatus, Value: job.Status},
+		}
+		if job.Error != "" {
+			rows = append(rows, outputRow{Key: "error", Value: job.Error})
+		}
+		printRows(app.Stdout, rows)
+		if wait && job.Status != client.JobSucceeded {
+			return exitError{code: 1}
+		}
+		return nil
+	})
+}
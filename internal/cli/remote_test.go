@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/pkg/client"
+)
+
+// stubAPI points the client commands at handler and returns the test server.
+func stubAPI(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	prevClient, prevPoll := newAPIClientFn, jobPollIntervalFn
+	newAPIClientFn = func() (*client.Client, error) {
+		return client.New(srv.URL, client.WithToken("secret"), client.WithHTTPClient(srv.Client()))
+	}
+	jobPollIntervalFn = func() time.Duration { return time.Millisecond }
+	t.Cleanup(func() {
+		newAPIClientFn, jobPollIntervalFn = prevClient, prevPoll
+	})
+	return srv
+}
+
+func writeAPIData(t *testing.T, w http.ResponseWriter, status int, data any) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]any{"data": data}); err != nil {
+		t.Errorf("encode response: %v", err)
+	}
+}
+
+func TestDaemonAddressUsesConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SENTINEL_DATA_DIR", dir)
+	t.Setenv("SENTINEL_CONFIG", "")
+	raw := "[server]\nhost = \"0.0.0.0\"\nport = 4141\ntoken = \"abc\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.toml"), []byte(raw), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	baseURL, token, err := daemonAddress()
+	if err != nil {
+		t.Fatalf("daemonAddress: %v", err)
+	}
+	if baseURL != "http://127.0.0.1:4141" {
+		t.Fatalf("baseURL = %q, want http://127.0.0.1:4141", baseURL)
+	}
+	if token != "abc" {
+		t.Fatalf("token = %q, want abc", token)
+	}
+}
+
+func TestRunSessionsList(t *testing.T) {
+	stubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want Bearer secret", got)
+		}
+		if r.URL.Path != "/api/tmux/sessions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		writeAPIData(t, w, http.StatusOK, map[string]any{
+			"sessions": []map[string]any{{"name": "dev", "windows": 2, "panes": 3, "attached": 1}},
+		})
+	})
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"sessions", "ls"}, &out, &errOut); code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "dev") || !strings.Contains(out.String(), "2 windows, 3 panes, attached") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRunSvcRestart(t *testing.T) {
+	stubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/ops/services/nginx/action" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["action"] != "restart" {
+			t.Errorf("body = %v (%v), want action=restart", body, err)
+		}
+		writeAPIData(t, w, http.StatusOK, map[string]any{
+			"service": map[string]any{"name": "nginx", "unit": "nginx.service", "activeState": "active"},
+		})
+	})
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"svc", "restart", "nginx"}, &out, &errOut); code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "restart nginx") || !strings.Contains(out.String(), "nginx.service") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRunSvcLogsPassesFilters(t *testing.T) {
+	stubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("lines") != "20" || q.Get("grep") != "timeout" || q.Has("since") {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		writeAPIData(t, w, http.StatusOK, map[string]any{"output": "upstream timeout"})
+	})
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"svc", "logs", "nginx", "-n", "20", "--grep", "timeout"}, &out, &errOut); code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, errOut.String())
+	}
+	if out.String() != "upstream timeout\n" {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestRunSvcReportsAPIError(t *testing.T) {
+	stubAPI(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"OPS_SERVICE_NOT_FOUND","message":"service not found"}}`))
+	})
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"svc", "stop", "missing"}, &out, &errOut); code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(errOut.String(), "OPS_SERVICE_NOT_FOUND: service not found") {
+		t.Fatalf("stderr = %s", errOut.String())
+	}
+}
+
+func TestRunRunbookRunByNameWaits(t *testing.T) {
+	var polls atomic.Int32
+	stubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/ops/runbooks":
+			writeAPIData(t, w, http.StatusOK, map[string]any{
+				"runbooks": []map[string]any{{"id": "rb-1", "name": "deploy"}},
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/ops/runbooks/rb-1/run":
+			var body struct {
+				Parameters map[string]string `json:"parameters"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Parameters["env"] != "prod" {
+				t.Errorf("parameters = %v (%v), want env=prod", body.Parameters, err)
+			}
+			writeAPIData(t, w, http.StatusAccepted, map[string]any{
+				"job": map[string]any{"id": "job-1", "runbookName": "deploy", "status": "queued"},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/ops/jobs/job-1":
+			status := "running"
+			if polls.Add(1) > 1 {
+				status = "failed"
+			}
+			writeAPIData(t, w, http.StatusOK, map[string]any{
+				"job": map[string]any{"id": "job-1", "runbookName": "deploy", "status": status, "error": "step 2 exited 1"},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	var out, errOut bytes.Buffer
+	code := Run([]string{"runbook", "run", "deploy", "--param", "env=prod", "--wait"}, &out, &errOut)
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1 for a failed job", code)
+	}
+	if polls.Load() != 2 {
+		t.Fatalf("polls = %d, want 2", polls.Load())
+	}
+	if !strings.Contains(out.String(), "job-1") || !strings.Contains(out.String(), "step 2 exited 1") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRunSessionsReportsUnreachableDaemon(t *testing.T) {
+	srv := stubAPI(t, func(http.ResponseWriter, *http.Request) {})
+	srv.Close()
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"sessions", "ls"}, &out, &errOut); code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(errOut.String(), "sentinel daemon unreachable") {
+		t.Fatalf("stderr = %s", errOut.String())
+	}
+}
+
+func TestRunRunbookRunRejectsBadParam(t *testing.T) {
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"runbook", "run", "deploy", "--param", "env"}, &out, &errOut); code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(errOut.String(), "want key=value") {
+		t.Fatalf("stderr = %s", errOut.String())
+	}
+}
@@ -40,6 +40,8 @@ func newRootCmd(app *App) *cobra.Command {
 	addGrouped(root, groupSetup,
 		newConfigCmd(app),
 		newDBCmd(app),
+		newBackupCmd(app),
+		newRestoreCmd(app),
 		newDoctorCmd(app),
 	)
 	addGrouped(root, groupService,
@@ -47,6 +49,11 @@ func newRootCmd(app *App) *cobra.Command {
 		newServiceCmd(app),
 		newUpdateCmd(app),
 	)
+	addGrouped(root, groupClient,
+		newSessionsCmd(app),
+		newSvcCmd(app),
+		newRunbookCmd(app),
+	)
 	addGrouped(root, groupExtra,
 		newCompletionCmd(app),
 		newVersionCmd(app),
@@ -41,6 +41,7 @@ func newUpdateCheckCmd(app *App) *cobra.Command {
 	var (
 		repo       string
 		apiBase    string
+		channel    string
 		targetOS   string
 		targetArch string
 		scope      string
@@ -58,9 +59,11 @@ func newUpdateCheckCmd(app *App) *cobra.Command {
 				CurrentVersion: currentVersionFn(),
 				Repo:           strings.TrimSpace(repo),
 				APIBaseURL:     strings.TrimSpace(apiBase),
+				Channel:        updateChannel(channel, ctx.cfg),
 				OS:             strings.TrimSpace(targetOS),
 				Arch:           strings.TrimSpace(targetArch),
 				DataDir:        ctx.cfg.DataDir(),
+				PublicKey:      ctx.cfg.Updates.PublicKey,
 			})
 			if err != nil {
 				return failf("update check failed: %w", err)
@@ -68,16 +71,19 @@ func newUpdateCheckCmd(app *App) *cobra.Command {
 			printRows(app.Stdout, []outputRow{
 				{Key: "current version", Value: humanize.ValueOrDash(result.CurrentVersion)},
 				{Key: "latest version", Value: humanize.ValueOrDash(result.LatestVersion)},
+				{Key: "channel", Value: humanize.ValueOrDash(result.Channel)},
 				{Key: "up to date", Value: fmt.Sprintf("%t", result.UpToDate)},
 				{Key: "release", Value: humanize.ValueOrDash(result.ReleaseURL)},
 				{Key: "asset", Value: humanize.ValueOrDash(result.AssetName)},
 				{Key: "sha256", Value: humanize.ValueOrDash(result.ExpectedSHA256)},
+				{Key: "signature verified", Value: fmt.Sprintf("%t", result.SignatureVerified)},
 			})
 			return nil
 		},
 	}
 	cmd.Flags().StringVar(&repo, "repo", defaultUpdaterRepo, "GitHub repository in owner/name format")
 	cmd.Flags().StringVar(&apiBase, "api", "", "GitHub API base URL override")
+	cmd.Flags().StringVar(&channel, "channel", "", "release channel: stable or prerelease (default: updates.channel)")
 	cmd.Flags().StringVar(&targetOS, "os", runtime.GOOS, "target operating system")
 	cmd.Flags().StringVar(&targetArch, "arch", runtime.GOARCH, "target CPU architecture")
 	cmd.Flags().StringVar(&scope, "scope", optionAuto, "target deployment: auto|user|system")
@@ -88,6 +94,7 @@ func newUpdateApplyCmd(app *App) *cobra.Command {
 	var (
 		repo            string
 		apiBase         string
+		channel         string
 		targetOS        string
 		targetArch      string
 		execPath        string
@@ -137,6 +144,7 @@ func newUpdateApplyCmd(app *App) *cobra.Command {
 				CurrentVersion:  currentVersionFn(),
 				Repo:            strings.TrimSpace(repo),
 				APIBaseURL:      strings.TrimSpace(apiBase),
+				Channel:         updateChannel(channel, updateContext.cfg),
 				OS:              strings.TrimSpace(targetOS),
 				Arch:            strings.TrimSpace(targetArch),
 				DataDir:         updateContext.cfg.DataDir(),
@@ -147,6 +155,7 @@ func newUpdateApplyCmd(app *App) *cobra.Command {
 				SkipRestart:     !restart,
 				ServiceUnit:     strings.TrimSpace(serviceUnit),
 				SystemdScope:    restartScope,
+				PublicKey:       updateContext.cfg.Updates.PublicKey,
 			})
 			if err != nil {
 				return failf("update apply failed: %w", err)
@@ -171,6 +180,7 @@ func newUpdateApplyCmd(app *App) *cobra.Command {
 	}
 	cmd.Flags().StringVar(&repo, "repo", defaultUpdaterRepo, "GitHub repository in owner/name format")
 	cmd.Flags().StringVar(&apiBase, "api", "", "GitHub API base URL override")
+	cmd.Flags().StringVar(&channel, "channel", "", "release channel: stable or prerelease (default: updates.channel)")
 	cmd.Flags().StringVar(&targetOS, "os", runtime.GOOS, "target operating system")
 	cmd.Flags().StringVar(&targetArch, "arch", runtime.GOARCH, "target CPU architecture")
 	cmd.Flags().StringVar(&execPath, "exec", "", "path to the sentinel binary to replace (default: current executable)")
@@ -182,6 +192,15 @@ func newUpdateApplyCmd(app *App) *cobra.Command {
 	return cmd
 }
 
+// updateChannel returns the --channel flag, falling back to the configured
+// updates.channel.
+func updateChannel(flag string, cfg config.Config) string {
+	if channel := strings.TrimSpace(flag); channel != "" {
+		return channel
+	}
+	return cfg.Updates.Channel
+}
+
 func normalizeUpdateApplyScope(raw string) (string, error) {
 	scope := strings.ToLower(strings.TrimSpace(raw))
 	if scope == "" {
@@ -11,12 +11,19 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/BurntSushi/toml"
+	"github.com/opus-domini/sentinel/internal/events"
 	"github.com/opus-domini/sentinel/internal/humanize"
+	"github.com/opus-domini/sentinel/internal/mqtt"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/tracing"
+	"github.com/opus-domini/sentinel/internal/updater"
 	"github.com/opus-domini/sentinel/internal/userswitch"
 	"github.com/opus-domini/sentinel/internal/validate"
 )
@@ -45,13 +52,27 @@ const ManagedDefaultLogPathEnv = "SENTINEL_DEFAULT_LOG_PATH"
 type Config struct {
 	Version      int                `toml:"version" json:"version"`
 	Server       ServerConfig       `toml:"server" json:"server"`
+	RateLimit    RateLimitConfig    `toml:"rate_limit" json:"rate_limit"`
 	Storage      StorageConfig      `toml:"storage" json:"storage"`
 	Log          LogConfig          `toml:"log" json:"log"`
 	HealthReport HealthReportConfig `toml:"health_report" json:"health_report"`
+	MQTT         MQTTConfig         `toml:"mqtt" json:"mqtt"`
+	Tracing      TracingConfig      `toml:"tracing" json:"tracing"`
 	Watchtower   WatchtowerConfig   `toml:"watchtower" json:"watchtower"`
 	MCP          MCPConfig          `toml:"mcp" json:"mcp"`
 	Runbooks     RunbooksConfig     `toml:"runbooks" json:"runbooks"`
+	Secrets      SecretsConfig      `toml:"secrets" json:"secrets"`
+	Library      LibraryConfig      `toml:"library" json:"library"`
+	Metrics      MetricsConfig      `toml:"metrics" json:"metrics"`
+	UPS          UPSConfig          `toml:"ups" json:"ups"`
+	Logins       LoginsConfig       `toml:"logins" json:"logins"`
+	Certificates CertificatesConfig `toml:"certificates" json:"certificates"`
+	StatusPage   StatusPageConfig   `toml:"status_page" json:"status_page"`
+	Files        FilesConfig        `toml:"files" json:"files"`
+	Recording    RecordingConfig    `toml:"recording" json:"recording"`
 	MultiUser    MultiUserConfig    `toml:"multi_user" json:"multi_user"`
+	Updates      UpdatesConfig      `toml:"updates" json:"updates"`
+	Federation   FederationConfig   `toml:"federation" json:"federation"`
 	SystemUsers  []string           `toml:"-" json:"system_users"`
 }
 
@@ -68,15 +89,56 @@ type ServerConfig struct {
 	Locale              string   `toml:"locale" json:"locale"`
 }
 
-// StorageConfig controls the SQLite database location.
+// RateLimitConfig caps API requests per token and per client IP. Reads
+// (GET and HEAD) and mutations are limited separately.
+type RateLimitConfig struct {
+	Enabled         bool `toml:"enabled" json:"enabled"`
+	ReadPerMinute   int  `toml:"read_per_minute" json:"read_per_minute"`
+	MutatePerMinute int  `toml:"mutate_per_minute" json:"mutate_per_minute"`
+}
+
+// StorageConfig controls the SQLite database location, its connection
+// tuning and its backups.
 type StorageConfig struct {
 	Path string `toml:"path" json:"path"`
+
+	// Connection pragmas; see https://sqlite.org/pragma.html.
+	JournalMode string        `toml:"journal_mode" json:"journal_mode"`
+	Synchronous string        `toml:"synchronous" json:"synchronous"`
+	BusyTimeout time.Duration `toml:"busy_timeout" json:"busy_timeout"`
+	CacheSize   int           `toml:"cache_size" json:"cache_size"`
+
+	// BackupDir holds database snapshots; empty means <data dir>/backups.
+	BackupDir      string `toml:"backup_dir" json:"backup_dir"`
+	BackupKeep     int    `toml:"backup_keep" json:"backup_keep"`
+	BackupSchedule string `toml:"backup_schedule" json:"backup_schedule"`
+
+	// MaintenanceSchedule runs integrity_check, ANALYZE and an incremental
+	// VACUUM at each cron occurrence; empty disables it.
+	MaintenanceSchedule string `toml:"maintenance_schedule" json:"maintenance_schedule"`
+}
+
+// StoreOptions returns the connection settings for store.Open.
+func (c StorageConfig) StoreOptions() store.Options {
+	return store.Options{
+		JournalMode: c.JournalMode,
+		Synchronous: c.Synchronous,
+		BusyTimeout: c.BusyTimeout,
+		CacheSize:   c.CacheSize,
+	}
 }
 
 // LogConfig controls daemon logging.
 type LogConfig struct {
 	Level string `toml:"level" json:"level"`
-	Path  string `toml:"path" json:"path"`
+	// Format is "text" or "json". JSON suits shipping logs to Loki or
+	// another aggregator.
+	Format string `toml:"format" json:"format"`
+	Path   string `toml:"path" json:"path"`
+	// MaxSizeMB rotates the log file once it reaches this size; 0 never
+	// rotates. MaxBackups is how many rotated files are kept.
+	MaxSizeMB  int `toml:"max_size_mb" json:"max_size_mb"`
+	MaxBackups int `toml:"max_backups" json:"max_backups"`
 }
 
 // HealthReportConfig controls scheduled health report delivery.
@@ -85,6 +147,41 @@ type HealthReportConfig struct {
 	Schedule   string `toml:"schedule" json:"schedule"`
 }
 
+// MQTTConfig controls the MQTT bridge, which publishes the listed event
+// types to a broker. It is disabled while Broker is empty.
+type MQTTConfig struct {
+	Broker      string   `toml:"broker" json:"broker"`
+	ClientID    string   `toml:"client_id" json:"client_id"`
+	Username    string   `toml:"username" json:"username"`
+	Password    string   `toml:"password" json:"password,omitempty"`
+	TopicPrefix string   `toml:"topic_prefix" json:"topic_prefix"`
+	QoS         int      `toml:"qos" json:"qos"`
+	Retain      bool     `toml:"retain" json:"retain"`
+	Events      []string `toml:"events" json:"events"`
+}
+
+// TracingConfig controls OpenTelemetry tracing. Spans are exported over
+// OTLP/HTTP while Endpoint is set.
+type TracingConfig struct {
+	Endpoint    string `toml:"endpoint" json:"endpoint"`
+	ServiceName string `toml:"service_name" json:"service_name"`
+	// SampleRatio is the share of new traces recorded, from 0 to 1.
+	SampleRatio float64 `toml:"sample_ratio" json:"sample_ratio"`
+	// Headers are "Name=value" pairs sent with each export, e.g. the
+	// credentials of a hosted backend.
+	Headers []string `toml:"headers" json:"headers,omitempty"`
+}
+
+// HeaderMap returns Headers keyed by name.
+func (c TracingConfig) HeaderMap() map[string]string {
+	headers := make(map[string]string, len(c.Headers))
+	for _, header := range c.Headers {
+		name, value, _ := strings.Cut(header, "=")
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
 // WatchtowerConfig represents watchtower config data.
 type WatchtowerConfig struct {
 	Enabled        bool          `toml:"enabled" json:"enabled"`
@@ -92,6 +189,21 @@ type WatchtowerConfig struct {
 	CaptureLines   int           `toml:"capture_lines" json:"capture_lines"`
 	CaptureTimeout time.Duration `toml:"capture_timeout" json:"capture_timeout"`
 	JournalRows    int           `toml:"journal_rows" json:"journal_rows"`
+
+	// PaneLog archives captured pane output under <data dir>/pane-logs.
+	PaneLog          bool          `toml:"pane_log" json:"pane_log"`
+	PaneLogMaxMB     int           `toml:"pane_log_max_mb" json:"pane_log_max_mb"`
+	PaneLogRetention time.Duration `toml:"pane_log_retention" json:"pane_log_retention"`
+
+	// Adaptive collects sessions unchanged for IdleTicks ticks less often,
+	// up to every MaxInterval.
+	Adaptive    bool          `toml:"adaptive" json:"adaptive"`
+	IdleTicks   int           `toml:"idle_ticks" json:"idle_ticks"`
+	MaxInterval time.Duration `toml:"max_interval" json:"max_interval"`
+
+	// ControlMode runs watchtower's tmux commands over one tmux control-mode
+	// connection and collects as soon as tmux reports a layout change.
+	ControlMode bool `toml:"control_mode" json:"control_mode"`
 }
 
 // MCPConfig controls the HTTP Model Context Protocol endpoint.
@@ -99,9 +211,107 @@ type MCPConfig struct {
 	Enabled bool `toml:"enabled" json:"enabled"`
 }
 
-// RunbooksConfig controls runbook execution behavior.
+// RunbooksConfig controls runbook execution behavior. Manual and scheduled
+// runs share one job queue: MaxConcurrent of them run at once and up to
+// MaxQueued more wait for a worker. On shutdown, running and waiting runs
+// get DrainTimeout to finish before they are canceled.
 type RunbooksConfig struct {
-	MaxConcurrent int `toml:"max_concurrent" json:"max_concurrent"`
+	MaxConcurrent int           `toml:"max_concurrent" json:"max_concurrent"`
+	MaxQueued     int           `toml:"max_queued" json:"max_queued"`
+	DrainTimeout  time.Duration `toml:"drain_timeout" json:"drain_timeout"`
+}
+
+// SecretsConfig controls where the master key of the secrets vault comes
+// from. The key is read from KeyFile, which is created on first start, or
+// from the OS keyring when Keyring is set.
+type SecretsConfig struct {
+	// KeyFile holds the hex-encoded key; empty means <data dir>/secrets.key.
+	KeyFile string `toml:"key_file" json:"key_file"`
+	Keyring bool   `toml:"keyring" json:"keyring"`
+}
+
+// LibraryConfig loads runbooks and their schedules from the YAML files in
+// Dir every SyncInterval. With GitURL set, Dir is a clone of that
+// repository that each sync pulls first. The library is off when both Dir
+// and GitURL are empty.
+type LibraryConfig struct {
+	// Dir defaults to <data dir>/library when GitURL is set.
+	Dir          string        `toml:"dir" json:"dir"`
+	GitURL       string        `toml:"git_url" json:"git_url"`
+	GitBranch    string        `toml:"git_branch" json:"git_branch"`
+	SyncInterval time.Duration `toml:"sync_interval" json:"sync_interval"`
+}
+
+// MetricsConfig controls persisted host metrics history and disk scans.
+type MetricsConfig struct {
+	History          bool          `toml:"history" json:"history"`
+	HistoryRetention time.Duration `toml:"history_retention" json:"history_retention"`
+
+	// DiskScanRoots are the directories ranked by GET /api/ops/disk.
+	DiskScanRoots []string `toml:"disk_scan_roots" json:"disk_scan_roots"`
+}
+
+// UPSConfig controls UPS power monitoring through NUT or apcupsd. It is
+// disabled while Source is empty.
+type UPSConfig struct {
+	// Source is "nut" or "apcupsd".
+	Source string `toml:"source" json:"source"`
+	// Name is the NUT UPS (e.g. ups@localhost) or the apcupsd host:port;
+	// empty reads the local apcupsd.
+	Name string `toml:"name" json:"name"`
+	// ShutdownRunbook is started once per outage when the UPS runs on
+	// battery with at most ShutdownRuntime left or reports a low battery.
+	ShutdownRunbook string        `toml:"shutdown_runbook" json:"shutdown_runbook"`
+	ShutdownRuntime time.Duration `toml:"shutdown_runtime" json:"shutdown_runtime"`
+}
+
+// LoginsConfig selects which successful SSH logins raise an alert.
+type LoginsConfig struct {
+	// AlertRoot alerts on every login as root.
+	AlertRoot bool `toml:"alert_root" json:"alert_root"`
+	// AlertNewAddress alerts when a user logs in from an address not seen
+	// for them in the journal before.
+	AlertNewAddress bool `toml:"alert_new_address" json:"alert_new_address"`
+}
+
+// CertificatesConfig lists the certificate files and TLS endpoints whose
+// expiry is watched. It is disabled while both lists are empty.
+type CertificatesConfig struct {
+	// Paths are PEM or DER certificate files.
+	Paths []string `toml:"paths" json:"paths"`
+	// Endpoints are TLS servers as host or host:port (port 443 by default).
+	Endpoints []string `toml:"endpoints" json:"endpoints"`
+	// WarnDays is how many days before expiry an alert is raised.
+	WarnDays      int           `toml:"warn_days" json:"warn_days"`
+	CheckInterval time.Duration `toml:"check_interval" json:"check_interval"`
+}
+
+// StatusPageConfig controls the read-only /status page summarizing uptime
+// checks and tracked services.
+type StatusPageConfig struct {
+	Enabled bool   `toml:"enabled" json:"enabled"`
+	Title   string `toml:"title" json:"title"`
+	// Public serves /status on the main listener without authentication.
+	Public bool `toml:"public" json:"public"`
+	// Listen is an extra host:port serving only the status page, without
+	// authentication; empty disables it.
+	Listen      string `toml:"listen" json:"listen"`
+	HistoryDays int    `toml:"history_days" json:"history_days"`
+}
+
+// FilesConfig controls the file browser API. It is disabled while Roots is
+// empty.
+type FilesConfig struct {
+	// Roots are the directories the file API may list, read and write.
+	Roots       []string `toml:"roots" json:"roots"`
+	MaxUploadMB int      `toml:"max_upload_mb" json:"max_upload_mb"`
+}
+
+// RecordingConfig controls asciicast recordings of terminals attached
+// through /ws/tmux, stored under <data dir>/recordings.
+type RecordingConfig struct {
+	Enabled   bool          `toml:"enabled" json:"enabled"`
+	Retention time.Duration `toml:"retention" json:"retention"`
 }
 
 // MultiUserConfig represents multi user config data.
@@ -111,6 +321,41 @@ type MultiUserConfig struct {
 	UserSwitchMethod string   `toml:"user_switch_method" json:"user_switch_method"`
 }
 
+// UpdatesConfig controls which releases the updater follows: "stable" or
+// "prerelease". A PublicKey makes the updater require releases whose
+// checksums file is signed with the matching ed25519 key.
+type UpdatesConfig struct {
+	Channel   string `toml:"channel" json:"channel"`
+	PublicKey string `toml:"public_key" json:"public_key"`
+}
+
+// FederationConfig links Sentinel instances. A central with a token accepts
+// agents; an instance with a central URL connects to it as an agent.
+type FederationConfig struct {
+	// Token is the shared secret agents present to the central.
+	Token      string `toml:"token" json:"token,omitempty"`
+	CentralURL string `toml:"central_url" json:"central_url"`
+	// Name identifies this agent on the central; empty means the hostname.
+	Name string `toml:"name" json:"name"`
+
+	// SSHHosts are driven from this instance over SSH, without an agent.
+	SSHHosts []SSHHostConfig `toml:"ssh_hosts" json:"ssh_hosts"`
+}
+
+// SSHHostConfig describes a host reached over SSH with key authentication.
+type SSHHostConfig struct {
+	Name    string `toml:"name" json:"name"`
+	Address string `toml:"address" json:"address"`
+	// Port and User default to the ssh client's settings when empty.
+	Port int    `toml:"port" json:"port"`
+	User string `toml:"user" json:"user"`
+	// IdentityFile is the private key; empty uses the ssh client defaults.
+	IdentityFile string `toml:"identity_file" json:"identity_file"`
+	// KnownHostsFile replaces ~/.ssh/known_hosts; host keys are always
+	// checked strictly.
+	KnownHostsFile string `toml:"known_hosts_file" json:"known_hosts_file"`
+}
+
 var (
 	osUserHomeDir = os.UserHomeDir
 	osCurrentUser = user.Current
@@ -155,19 +400,69 @@ func DefaultForDeployment(dataRoot, logPath string) Config {
 			CookieSecure: CookieSecureAuto,
 			Timezone:     time.Now().Location().String(),
 		},
-		Storage: StorageConfig{Path: filepath.Join(dataRoot, "sentinel.db")},
-		Log:     LogConfig{Level: DefaultLogLevel, Path: logPath},
+		RateLimit: RateLimitConfig{
+			Enabled:         true,
+			ReadPerMinute:   600,
+			MutatePerMinute: 120,
+		},
+		Storage: StorageConfig{
+			Path:        filepath.Join(dataRoot, "sentinel.db"),
+			JournalMode: "wal",
+			Synchronous: "normal",
+			BusyTimeout: 5 * time.Second,
+			BackupDir:   filepath.Join(dataRoot, "backups"),
+			BackupKeep:  7,
+		},
+		Log: LogConfig{Level: DefaultLogLevel, Format: "text", Path: logPath, MaxBackups: 5},
+		MQTT: MQTTConfig{
+			ClientID:    "sentinel",
+			TopicPrefix: "sentinel",
+			Events:      []string{events.TypeOpsServices, events.TypeOpsJob, events.TypeTmuxActivity},
+		},
+		Tracing: TracingConfig{
+			ServiceName: "sentinel",
+			SampleRatio: 1,
+		},
 		Watchtower: WatchtowerConfig{
 			Enabled:        true,
 			TickInterval:   1 * time.Second,
 			CaptureLines:   80,
 			CaptureTimeout: 150 * time.Millisecond,
 			JournalRows:    5000,
+
+			PaneLogMaxMB:     8,
+			PaneLogRetention: 7 * 24 * time.Hour,
+
+			IdleTicks:   10,
+			MaxInterval: 10 * time.Second,
+		},
+		Runbooks: RunbooksConfig{
+			MaxConcurrent: 5,
+			MaxQueued:     100,
+			DrainTimeout:  30 * time.Second,
+		},
+		Secrets: SecretsConfig{KeyFile: filepath.Join(dataRoot, "secrets.key")},
+		Library: LibraryConfig{SyncInterval: 5 * time.Minute},
+		Metrics: MetricsConfig{
+			History:          true,
+			HistoryRetention: 90 * 24 * time.Hour,
+			DiskScanRoots:    []string{"/"},
 		},
-		Runbooks: RunbooksConfig{MaxConcurrent: 5},
+		UPS: UPSConfig{ShutdownRuntime: 5 * time.Minute},
+		Certificates: CertificatesConfig{
+			WarnDays:      30,
+			CheckInterval: 6 * time.Hour,
+		},
+		StatusPage: StatusPageConfig{
+			Title:       "Status",
+			HistoryDays: 30,
+		},
+		Files:     FilesConfig{MaxUploadMB: 64},
+		Recording: RecordingConfig{Retention: 30 * 24 * time.Hour},
 		MultiUser: MultiUserConfig{
 			UserSwitchMethod: defaultUserSwitchMethod(),
 		},
+		Updates: UpdatesConfig{Channel: "stable"},
 	}
 }
 
@@ -252,10 +547,7 @@ func loadExistingWithDefaults(path string, applyEnvironment bool, defaults Confi
 	if err != nil {
 		return cfg, path, fmt.Errorf("decode config: %w", err)
 	}
-	var issues []string
-	for _, key := range meta.Undecoded() {
-		issues = append(issues, "unknown key: "+strings.Join(key, "."))
-	}
+	issues := undecodedIssues(meta)
 	if err := cfg.Resolve(); err != nil {
 		issues = append(issues, err.Error())
 	}
@@ -394,6 +686,14 @@ func (c *Config) Resolve() error {
 	if c == nil {
 		return nil
 	}
+	if issues := c.resolveIssues(); len(issues) > 0 {
+		return errors.New(strings.Join(issues, "; "))
+	}
+	return nil
+}
+
+// resolveIssues is Resolve returning each validation failure separately.
+func (c *Config) resolveIssues() []string {
 	defaults := Default()
 	if c.Version == 0 {
 		c.Version = defaults.Version
@@ -412,19 +712,113 @@ func (c *Config) Resolve() error {
 	if c.Server.Timezone == "" {
 		c.Server.Timezone = defaults.Server.Timezone
 	}
+	if c.RateLimit.ReadPerMinute == 0 {
+		c.RateLimit.ReadPerMinute = defaults.RateLimit.ReadPerMinute
+	}
+	if c.RateLimit.MutatePerMinute == 0 {
+		c.RateLimit.MutatePerMinute = defaults.RateLimit.MutatePerMinute
+	}
 	if strings.TrimSpace(c.Storage.Path) == "" {
 		c.Storage.Path = defaults.Storage.Path
 	}
+	c.Storage.JournalMode = strings.ToLower(strings.TrimSpace(c.Storage.JournalMode))
+	if c.Storage.JournalMode == "" {
+		c.Storage.JournalMode = defaults.Storage.JournalMode
+	}
+	c.Storage.Synchronous = strings.ToLower(strings.TrimSpace(c.Storage.Synchronous))
+	if c.Storage.Synchronous == "" {
+		c.Storage.Synchronous = defaults.Storage.Synchronous
+	}
+	if c.Storage.BusyTimeout == 0 {
+		c.Storage.BusyTimeout = defaults.Storage.BusyTimeout
+	}
+	if c.Storage.BackupKeep == 0 {
+		c.Storage.BackupKeep = defaults.Storage.BackupKeep
+	}
+	c.Storage.BackupSchedule = strings.TrimSpace(c.Storage.BackupSchedule)
+	c.Storage.MaintenanceSchedule = strings.TrimSpace(c.Storage.MaintenanceSchedule)
 	if strings.TrimSpace(c.Log.Level) == "" {
 		c.Log.Level = defaults.Log.Level
 	}
 	c.Log.Level = strings.ToLower(strings.TrimSpace(c.Log.Level))
+	if c.Log.Format = strings.ToLower(strings.TrimSpace(c.Log.Format)); c.Log.Format == "" {
+		c.Log.Format = defaults.Log.Format
+	}
 	if strings.TrimSpace(c.Log.Path) == "" {
 		c.Log.Path = defaults.Log.Path
 	}
+	if c.Log.MaxBackups == 0 {
+		c.Log.MaxBackups = defaults.Log.MaxBackups
+	}
+	c.MQTT.Broker = strings.TrimSpace(c.MQTT.Broker)
+	if c.MQTT.ClientID = strings.TrimSpace(c.MQTT.ClientID); c.MQTT.ClientID == "" {
+		c.MQTT.ClientID = defaults.MQTT.ClientID
+	}
+	c.MQTT.TopicPrefix = strings.Trim(strings.TrimSpace(c.MQTT.TopicPrefix), "/")
+	c.MQTT.Events = cleanStrings(c.MQTT.Events)
+	if len(c.MQTT.Events) == 0 {
+		c.MQTT.Events = defaults.MQTT.Events
+	}
+	c.Tracing.Endpoint = strings.TrimSpace(c.Tracing.Endpoint)
+	if c.Tracing.ServiceName = strings.TrimSpace(c.Tracing.ServiceName); c.Tracing.ServiceName == "" {
+		c.Tracing.ServiceName = defaults.Tracing.ServiceName
+	}
+	c.Tracing.Headers = cleanStrings(c.Tracing.Headers)
 	if c.Runbooks.MaxConcurrent == 0 {
 		c.Runbooks.MaxConcurrent = defaults.Runbooks.MaxConcurrent
 	}
+	if c.Runbooks.MaxQueued == 0 {
+		c.Runbooks.MaxQueued = defaults.Runbooks.MaxQueued
+	}
+	if c.Runbooks.DrainTimeout == 0 {
+		c.Runbooks.DrainTimeout = defaults.Runbooks.DrainTimeout
+	}
+	c.Secrets.KeyFile = strings.TrimSpace(c.Secrets.KeyFile)
+	if c.Secrets.KeyFile == "" {
+		c.Secrets.KeyFile = filepath.Join(c.DataDir(), "secrets.key")
+	}
+	c.Library.Dir = strings.TrimSpace(c.Library.Dir)
+	c.Library.GitURL = strings.TrimSpace(c.Library.GitURL)
+	c.Library.GitBranch = strings.TrimSpace(c.Library.GitBranch)
+	if c.Library.Dir == "" && c.Library.GitURL != "" {
+		c.Library.Dir = filepath.Join(c.DataDir(), "library")
+	}
+	if c.Library.SyncInterval == 0 {
+		c.Library.SyncInterval = defaults.Library.SyncInterval
+	}
+	if c.Metrics.HistoryRetention == 0 {
+		c.Metrics.HistoryRetention = defaults.Metrics.HistoryRetention
+	}
+	c.Metrics.DiskScanRoots = cleanStrings(c.Metrics.DiskScanRoots)
+	if len(c.Metrics.DiskScanRoots) == 0 {
+		c.Metrics.DiskScanRoots = defaults.Metrics.DiskScanRoots
+	}
+	c.UPS.Source = strings.ToLower(strings.TrimSpace(c.UPS.Source))
+	c.UPS.Name = strings.TrimSpace(c.UPS.Name)
+	c.UPS.ShutdownRunbook = strings.TrimSpace(c.UPS.ShutdownRunbook)
+	if c.UPS.ShutdownRuntime == 0 {
+		c.UPS.ShutdownRuntime = defaults.UPS.ShutdownRuntime
+	}
+	c.Certificates.Paths = cleanStrings(c.Certificates.Paths)
+	c.Certificates.Endpoints = cleanStrings(c.Certificates.Endpoints)
+	if c.Certificates.WarnDays == 0 {
+		c.Certificates.WarnDays = defaults.Certificates.WarnDays
+	}
+	if c.Certificates.CheckInterval == 0 {
+		c.Certificates.CheckInterval = defaults.Certificates.CheckInterval
+	}
+	c.StatusPage.Title = strings.TrimSpace(c.StatusPage.Title)
+	if c.StatusPage.Title == "" {
+		c.StatusPage.Title = defaults.StatusPage.Title
+	}
+	c.StatusPage.Listen = strings.TrimSpace(c.StatusPage.Listen)
+	if c.StatusPage.HistoryDays == 0 {
+		c.StatusPage.HistoryDays = defaults.StatusPage.HistoryDays
+	}
+	c.Files.Roots = cleanStrings(c.Files.Roots)
+	if c.Files.MaxUploadMB == 0 {
+		c.Files.MaxUploadMB = defaults.Files.MaxUploadMB
+	}
 	if c.Watchtower.TickInterval == 0 {
 		c.Watchtower.TickInterval = defaults.Watchtower.TickInterval
 	}
@@ -437,25 +831,72 @@ func (c *Config) Resolve() error {
 	if c.Watchtower.JournalRows == 0 {
 		c.Watchtower.JournalRows = defaults.Watchtower.JournalRows
 	}
+	if c.Watchtower.PaneLogMaxMB == 0 {
+		c.Watchtower.PaneLogMaxMB = defaults.Watchtower.PaneLogMaxMB
+	}
+	if c.Watchtower.PaneLogRetention == 0 {
+		c.Watchtower.PaneLogRetention = defaults.Watchtower.PaneLogRetention
+	}
+	if c.Watchtower.IdleTicks == 0 {
+		c.Watchtower.IdleTicks = defaults.Watchtower.IdleTicks
+	}
+	if c.Watchtower.MaxInterval == 0 {
+		c.Watchtower.MaxInterval = defaults.Watchtower.MaxInterval
+	}
+	if c.Recording.Retention == 0 {
+		c.Recording.Retention = defaults.Recording.Retention
+	}
 	c.MultiUser.AllowedUsers = cleanStrings(c.MultiUser.AllowedUsers)
 	if strings.TrimSpace(c.MultiUser.UserSwitchMethod) == "" {
 		c.MultiUser.UserSwitchMethod = defaults.MultiUser.UserSwitchMethod
 	}
 	c.MultiUser.UserSwitchMethod = userswitch.NormalizeMethod(c.MultiUser.UserSwitchMethod, defaults.MultiUser.UserSwitchMethod)
+	c.Updates.Channel = strings.ToLower(strings.TrimSpace(c.Updates.Channel))
+	if c.Updates.Channel == "" {
+		c.Updates.Channel = defaults.Updates.Channel
+	}
+	c.Updates.PublicKey = strings.TrimSpace(c.Updates.PublicKey)
+	c.Federation.Token = strings.TrimSpace(c.Federation.Token)
+	c.Federation.CentralURL = strings.TrimSpace(c.Federation.CentralURL)
+	c.Federation.Name = strings.TrimSpace(c.Federation.Name)
+	for i := range c.Federation.SSHHosts {
+		host := &c.Federation.SSHHosts[i]
+		host.Name = strings.TrimSpace(host.Name)
+		host.Address = strings.TrimSpace(host.Address)
+		host.User = strings.TrimSpace(host.User)
+		for _, path := range []*string{&host.IdentityFile, &host.KnownHostsFile} {
+			if strings.TrimSpace(*path) == "" {
+				*path = ""
+				continue
+			}
+			expanded, err := ExpandPath(*path)
+			if err != nil {
+				return []string{err.Error()}
+			}
+			*path = expanded
+		}
+	}
 
 	var err error
 	c.Storage.Path, err = ExpandPath(c.Storage.Path)
 	if err != nil {
-		return err
+		return []string{err.Error()}
+	}
+	if strings.TrimSpace(c.Storage.BackupDir) == "" {
+		c.Storage.BackupDir = filepath.Join(filepath.Dir(c.Storage.Path), "backups")
+	}
+	c.Storage.BackupDir, err = ExpandPath(c.Storage.BackupDir)
+	if err != nil {
+		return []string{err.Error()}
 	}
 	c.Log.Path, err = ExpandPath(c.Log.Path)
 	if err != nil {
-		return err
+		return []string{err.Error()}
 	}
-	return validateConfig(*c)
+	return configIssues(*c)
 }
 
-func validateConfig(cfg Config) error {
+func configIssues(cfg Config) []string {
 	var issues []string
 	if cfg.Server.Port < 1 || cfg.Server.Port > 65535 {
 		issues = append(issues, "server.port must be between 1 and 65535")
@@ -483,17 +924,116 @@ func validateConfig(cfg Config) error {
 			}
 		}
 	}
+	if cfg.RateLimit.ReadPerMinute < 0 {
+		issues = append(issues, "rate_limit.read_per_minute must be a positive integer")
+	}
+	if cfg.RateLimit.MutatePerMinute < 0 {
+		issues = append(issues, "rate_limit.mutate_per_minute must be a positive integer")
+	}
 	switch cfg.Log.Level {
 	case "debug", "info", "warn", "error":
 	default:
 		issues = append(issues, `log.level must be one of "debug", "info", "warn", or "error"`)
 	}
+	if cfg.Log.Format != "text" && cfg.Log.Format != "json" {
+		issues = append(issues, `log.format must be "text" or "json"`)
+	}
+	if cfg.Log.MaxSizeMB < 0 {
+		issues = append(issues, "log.max_size_mb must be zero or a positive integer")
+	}
+	if cfg.Log.MaxBackups < 0 {
+		issues = append(issues, "log.max_backups must be a positive integer")
+	}
 	if err := validate.Timezone(cfg.Server.Timezone); err != nil {
 		issues = append(issues, "server.timezone "+err.Error())
 	}
 	if cfg.Runbooks.MaxConcurrent <= 0 {
 		issues = append(issues, "runbooks.max_concurrent must be a positive integer")
 	}
+	if cfg.Runbooks.MaxQueued <= 0 {
+		issues = append(issues, "runbooks.max_queued must be a positive integer")
+	}
+	if cfg.Runbooks.DrainTimeout <= 0 {
+		issues = append(issues, "runbooks.drain_timeout must be positive")
+	}
+	if !cfg.Secrets.Keyring && !filepath.IsAbs(cfg.Secrets.KeyFile) {
+		issues = append(issues, "secrets.key_file must be an absolute path")
+	}
+	if cfg.Library.Dir != "" && !filepath.IsAbs(cfg.Library.Dir) {
+		issues = append(issues, "library.dir must be an absolute path")
+	}
+	if cfg.Library.SyncInterval < 10*time.Second {
+		issues = append(issues, "library.sync_interval must be at least 10s")
+	}
+	if strings.HasPrefix(cfg.Library.GitURL, "-") || strings.ContainsAny(cfg.Library.GitURL, " \t\r\n") {
+		issues = append(issues, "library.git_url must be a repository URL or path")
+	}
+	if strings.HasPrefix(cfg.Library.GitBranch, "-") || strings.ContainsAny(cfg.Library.GitBranch, " \t\r\n") {
+		issues = append(issues, "library.git_branch must be a branch name")
+	}
+	if cfg.Metrics.HistoryRetention < 24*time.Hour {
+		issues = append(issues, "metrics.history_retention must be at least 24h")
+	}
+	for _, root := range cfg.Metrics.DiskScanRoots {
+		if !filepath.IsAbs(root) {
+			issues = append(issues, fmt.Sprintf("metrics.disk_scan_roots entry %q must be an absolute path", root))
+		}
+	}
+	switch cfg.UPS.Source {
+	case "", "apcupsd":
+	case "nut":
+		if cfg.UPS.Name == "" {
+			issues = append(issues, `ups.name is required when ups.source is "nut"`)
+		}
+	default:
+		issues = append(issues, `ups.source must be "nut" or "apcupsd"`)
+	}
+	if strings.HasPrefix(cfg.UPS.Name, "-") || strings.ContainsFunc(cfg.UPS.Name, unicode.IsSpace) {
+		issues = append(issues, "ups.name must not start with - or contain whitespace")
+	}
+	if cfg.UPS.ShutdownRunbook != "" && cfg.UPS.Source == "" {
+		issues = append(issues, "ups.shutdown_runbook requires ups.source")
+	}
+	if cfg.UPS.ShutdownRuntime <= 0 {
+		issues = append(issues, "ups.shutdown_runtime must be positive")
+	}
+	for _, path := range cfg.Certificates.Paths {
+		if !filepath.IsAbs(path) {
+			issues = append(issues, fmt.Sprintf("certificates.paths entry %q must be an absolute path", path))
+		}
+	}
+	for _, endpoint := range cfg.Certificates.Endpoints {
+		if !validCertificateEndpoint(endpoint) {
+			issues = append(issues, fmt.Sprintf("certificates.endpoints entry %q must be host or host:port", endpoint))
+		}
+	}
+	if cfg.Certificates.WarnDays < 1 {
+		issues = append(issues, "certificates.warn_days must be a positive integer")
+	}
+	if cfg.Certificates.CheckInterval < time.Minute {
+		issues = append(issues, "certificates.check_interval must be at least 1m")
+	}
+	if cfg.StatusPage.Listen != "" {
+		if err := validateListenAddress(cfg.StatusPage.Listen); err != nil {
+			issues = append(issues, "status_page.listen must be a valid listen address: "+err.Error())
+		} else if cfg.StatusPage.Listen == cfg.Address() {
+			issues = append(issues, "status_page.listen must differ from the server address")
+		}
+	}
+	if cfg.StatusPage.HistoryDays < 1 || cfg.StatusPage.HistoryDays > 90 {
+		issues = append(issues, "status_page.history_days must be between 1 and 90")
+	}
+	for _, root := range cfg.Files.Roots {
+		if !filepath.IsAbs(root) {
+			issues = append(issues, fmt.Sprintf("files.roots entry %q must be an absolute path", root))
+		}
+	}
+	if cfg.Files.MaxUploadMB < 0 {
+		issues = append(issues, "files.max_upload_mb must be positive")
+	}
+	if cfg.Recording.Retention <= 0 {
+		issues = append(issues, "recording.retention must be a positive duration")
+	}
 	if cfg.Watchtower.TickInterval <= 0 {
 		issues = append(issues, "watchtower.tick_interval must be a positive duration")
 	}
@@ -506,6 +1046,18 @@ func validateConfig(cfg Config) error {
 	if cfg.Watchtower.JournalRows <= 0 {
 		issues = append(issues, "watchtower.journal_rows must be a positive integer")
 	}
+	if cfg.Watchtower.PaneLogMaxMB <= 0 {
+		issues = append(issues, "watchtower.pane_log_max_mb must be a positive integer")
+	}
+	if cfg.Watchtower.PaneLogRetention <= 0 {
+		issues = append(issues, "watchtower.pane_log_retention must be a positive duration")
+	}
+	if cfg.Watchtower.IdleTicks <= 0 {
+		issues = append(issues, "watchtower.idle_ticks must be a positive integer")
+	}
+	if cfg.Watchtower.MaxInterval < cfg.Watchtower.TickInterval {
+		issues = append(issues, "watchtower.max_interval must not be shorter than watchtower.tick_interval")
+	}
 	if cfg.MCP.Enabled && strings.TrimSpace(cfg.Server.Token) == "" {
 		issues = append(issues, "mcp.enabled requires server.token")
 	}
@@ -514,10 +1066,103 @@ func validateConfig(cfg Config) error {
 			issues = append(issues, "health_report.schedule "+err.Error())
 		}
 	}
-	if len(issues) > 0 {
-		return errors.New(strings.Join(issues, "; "))
+	if cfg.MQTT.Broker != "" {
+		if _, _, err := mqtt.BrokerAddress(cfg.MQTT.Broker); err != nil {
+			issues = append(issues, "mqtt.broker must be an mqtt:// or mqtts:// URL with a host")
+		}
 	}
-	return nil
+	if cfg.MQTT.QoS < 0 || cfg.MQTT.QoS > 1 {
+		issues = append(issues, "mqtt.qos must be 0 or 1")
+	}
+	if strings.ContainsAny(cfg.MQTT.TopicPrefix, "+#") {
+		issues = append(issues, "mqtt.topic_prefix must not contain the wildcards + or #")
+	}
+	for _, eventType := range cfg.MQTT.Events {
+		if !slices.Contains(events.Types(), eventType) {
+			issues = append(issues, fmt.Sprintf("mqtt.events entry %q is not a known event type", eventType))
+		}
+	}
+	if cfg.Tracing.Endpoint != "" {
+		if _, err := tracing.TracesURL(cfg.Tracing.Endpoint); err != nil {
+			issues = append(issues, "tracing.endpoint must be an http:// or https:// URL with a host")
+		}
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		issues = append(issues, "tracing.sample_ratio must be between 0 and 1")
+	}
+	for _, header := range cfg.Tracing.Headers {
+		if name, _, ok := strings.Cut(header, "="); !ok || strings.TrimSpace(name) == "" {
+			issues = append(issues, fmt.Sprintf("tracing.headers entry %q must be Name=value", header))
+		}
+	}
+	switch cfg.Updates.Channel {
+	case "stable", "prerelease":
+	default:
+		issues = append(issues, `updates.channel must be "stable" or "prerelease"`)
+	}
+	if cfg.Updates.PublicKey != "" {
+		if _, err := updater.ParsePublicKey(cfg.Updates.PublicKey); err != nil {
+			issues = append(issues, "updates.public_key must be a base64-encoded ed25519 public key")
+		}
+	}
+	if cfg.Federation.CentralURL != "" {
+		if parsed, err := url.Parse(cfg.Federation.CentralURL); err != nil || parsed.Host == "" ||
+			(parsed.Scheme != "ws" && parsed.Scheme != "wss") {
+			issues = append(issues, "federation.central_url must be a ws:// or wss:// URL")
+		}
+		if cfg.Federation.Token == "" {
+			issues = append(issues, "federation.central_url requires federation.token")
+		}
+	}
+	if cfg.Federation.Name != "" && !validate.HostName(cfg.Federation.Name) {
+		issues = append(issues, "federation.name must be 1-63 letters, digits, dots, underscores or hyphens")
+	}
+	seenSSHHosts := make(map[string]bool, len(cfg.Federation.SSHHosts))
+	for i, host := range cfg.Federation.SSHHosts {
+		field := fmt.Sprintf("federation.ssh_hosts[%d]", i)
+		if !validate.HostName(host.Name) {
+			issues = append(issues, field+".name must be 1-63 letters, digits, dots, underscores or hyphens")
+		} else if seenSSHHosts[host.Name] {
+			issues = append(issues, fmt.Sprintf("%s.name %q is used by another SSH host", field, host.Name))
+		}
+		seenSSHHosts[host.Name] = true
+		if !validate.SSHAddress(host.Address) {
+			issues = append(issues, field+".address must be a hostname or IP address")
+		}
+		if host.Port < 0 || host.Port > 65535 {
+			issues = append(issues, field+".port must be between 1 and 65535")
+		}
+		if host.User != "" && !validate.LoginName(host.User) {
+			issues = append(issues, field+".user must be a valid login name")
+		}
+	}
+	switch cfg.Storage.JournalMode {
+	case "wal", "delete", "truncate", "persist":
+	default:
+		issues = append(issues, `storage.journal_mode must be one of "wal", "delete", "truncate", or "persist"`)
+	}
+	switch cfg.Storage.Synchronous {
+	case "off", "normal", "full", "extra":
+	default:
+		issues = append(issues, `storage.synchronous must be one of "off", "normal", "full", or "extra"`)
+	}
+	if cfg.Storage.BusyTimeout <= 0 {
+		issues = append(issues, "storage.busy_timeout must be a positive duration")
+	}
+	if cfg.Storage.BackupKeep <= 0 {
+		issues = append(issues, "storage.backup_keep must be a positive integer")
+	}
+	if cfg.Storage.BackupSchedule != "" {
+		if err := validate.CronExpression(cfg.Storage.BackupSchedule); err != nil {
+			issues = append(issues, "storage.backup_schedule "+err.Error())
+		}
+	}
+	if cfg.Storage.MaintenanceSchedule != "" {
+		if err := validate.CronExpression(cfg.Storage.MaintenanceSchedule); err != nil {
+			issues = append(issues, "storage.maintenance_schedule "+err.Error())
+		}
+	}
+	return issues
 }
 
 func validateAllowedOrigin(origin string) error {
@@ -543,13 +1188,27 @@ func applyEnv(cfg *Config) {
 		return
 	}
 	applyServerEnv(cfg)
+	applyRateLimitEnv(cfg)
 	applyStorageEnv(cfg)
 	applyLogEnv(cfg)
 	applyHealthReportEnv(cfg)
+	applyMQTTEnv(cfg)
+	applyTracingEnv(cfg)
 	applyWatchtowerEnv(cfg)
 	applyMCPEnv(cfg)
 	applyRunbooksEnv(cfg)
+	applySecretsEnv(cfg)
+	applyLibraryEnv(cfg)
+	applyMetricsEnv(cfg)
+	applyUPSEnv(cfg)
+	applyLoginsEnv(cfg)
+	applyCertificatesEnv(cfg)
+	applyStatusPageEnv(cfg)
+	applyFilesEnv(cfg)
+	applyRecordingEnv(cfg)
 	applyMultiUserEnv(cfg)
+	applyUpdatesEnv(cfg)
+	applyFederationEnv(cfg)
 }
 
 func applyServerEnv(cfg *Config) {
@@ -589,19 +1248,80 @@ func applyServerEnv(cfg *Config) {
 	}
 }
 
+func applyRateLimitEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_RATE_LIMIT_ENABLED")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.RateLimit.Enabled = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_RATE_LIMIT_READ_PER_MINUTE")); v != "" {
+		if parsed, ok := parsePositiveInt(v); ok {
+			cfg.RateLimit.ReadPerMinute = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_RATE_LIMIT_MUTATE_PER_MINUTE")); v != "" {
+		if parsed, ok := parsePositiveInt(v); ok {
+			cfg.RateLimit.MutatePerMinute = parsed
+		}
+	}
+}
+
 func applyStorageEnv(cfg *Config) {
 	if v := strings.TrimSpace(os.Getenv("SENTINEL_STORAGE_PATH")); v != "" {
 		cfg.Storage.Path = v
 	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STORAGE_JOURNAL_MODE")); v != "" {
+		cfg.Storage.JournalMode = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STORAGE_SYNCHRONOUS")); v != "" {
+		cfg.Storage.Synchronous = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STORAGE_BUSY_TIMEOUT")); v != "" {
+		if parsed, ok := parseDuration(v); ok {
+			cfg.Storage.BusyTimeout = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STORAGE_CACHE_SIZE")); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			cfg.Storage.CacheSize = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STORAGE_BACKUP_DIR")); v != "" {
+		cfg.Storage.BackupDir = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STORAGE_BACKUP_KEEP")); v != "" {
+		if parsed, ok := parsePositiveInt(v); ok {
+			cfg.Storage.BackupKeep = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STORAGE_BACKUP_SCHEDULE")); v != "" {
+		cfg.Storage.BackupSchedule = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STORAGE_MAINTENANCE_SCHEDULE")); v != "" {
+		cfg.Storage.MaintenanceSchedule = v
+	}
 }
 
 func applyLogEnv(cfg *Config) {
 	if v := strings.TrimSpace(os.Getenv("SENTINEL_LOG_LEVEL")); v != "" {
 		cfg.Log.Level = v
 	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_LOG_FORMAT")); v != "" {
+		cfg.Log.Format = v
+	}
 	if v := strings.TrimSpace(os.Getenv("SENTINEL_LOG_PATH")); v != "" {
 		cfg.Log.Path = v
 	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_LOG_MAX_SIZE_MB")); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+			cfg.Log.MaxSizeMB = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_LOG_MAX_BACKUPS")); v != "" {
+		if parsed, ok := parsePositiveInt(v); ok {
+			cfg.Log.MaxBackups = parsed
+		}
+	}
 }
 
 func applyHealthReportEnv(cfg *Config) {
@@ -613,6 +1333,54 @@ func applyHealthReportEnv(cfg *Config) {
 	}
 }
 
+func applyMQTTEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_MQTT_BROKER")); v != "" {
+		cfg.MQTT.Broker = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_MQTT_CLIENT_ID")); v != "" {
+		cfg.MQTT.ClientID = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_MQTT_USERNAME")); v != "" {
+		cfg.MQTT.Username = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_MQTT_PASSWORD")); v != "" {
+		cfg.MQTT.Password = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_MQTT_TOPIC_PREFIX")); v != "" {
+		cfg.MQTT.TopicPrefix = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_MQTT_QOS")); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			cfg.MQTT.QoS = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_MQTT_RETAIN")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.MQTT.Retain = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_MQTT_EVENTS")); v != "" {
+		cfg.MQTT.Events = splitCSV(v)
+	}
+}
+
+func applyTracingEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_TRACING_ENDPOINT")); v != "" {
+		cfg.Tracing.Endpoint = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_TRACING_SERVICE_NAME")); v != "" {
+		cfg.Tracing.ServiceName = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_TRACING_SAMPLE_RATIO")); v != "" {
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.Tracing.SampleRatio = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_TRACING_HEADERS")); v != "" {
+		cfg.Tracing.Headers = splitCSV(v)
+	}
+}
+
 func applyWatchtowerEnv(cfg *Config) {
 	if v := strings.TrimSpace(os.Getenv("SENTINEL_WATCHTOWER_ENABLED")); v != "" {
 		if parsed, ok := parseBool(v); ok {
@@ -639,6 +1407,41 @@ func applyWatchtowerEnv(cfg *Config) {
 			cfg.Watchtower.JournalRows = parsed
 		}
 	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_WATCHTOWER_PANE_LOG")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.Watchtower.PaneLog = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_WATCHTOWER_PANE_LOG_MAX_MB")); v != "" {
+		if parsed, ok := parsePositiveInt(v); ok {
+			cfg.Watchtower.PaneLogMaxMB = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_WATCHTOWER_PANE_LOG_RETENTION")); v != "" {
+		if parsed, ok := parseDuration(v); ok {
+			cfg.Watchtower.PaneLogRetention = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_WATCHTOWER_ADAPTIVE")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.Watchtower.Adaptive = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_WATCHTOWER_IDLE_TICKS")); v != "" {
+		if parsed, ok := parsePositiveInt(v); ok {
+			cfg.Watchtower.IdleTicks = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_WATCHTOWER_MAX_INTERVAL")); v != "" {
+		if parsed, ok := parseDuration(v); ok {
+			cfg.Watchtower.MaxInterval = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_WATCHTOWER_CONTROL_MODE")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.Watchtower.ControlMode = parsed
+		}
+	}
 }
 
 func applyMCPEnv(cfg *Config) {
@@ -655,6 +1458,157 @@ func applyRunbooksEnv(cfg *Config) {
 			cfg.Runbooks.MaxConcurrent = parsed
 		}
 	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_RUNBOOK_MAX_QUEUED")); v != "" {
+		if parsed, ok := parsePositiveInt(v); ok {
+			cfg.Runbooks.MaxQueued = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_RUNBOOK_DRAIN_TIMEOUT")); v != "" {
+		if parsed, ok := parseDuration(v); ok {
+			cfg.Runbooks.DrainTimeout = parsed
+		}
+	}
+}
+
+func applySecretsEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_SECRETS_KEY_FILE")); v != "" {
+		cfg.Secrets.KeyFile = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_SECRETS_KEYRING")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.Secrets.Keyring = parsed
+		}
+	}
+}
+
+func applyLibraryEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_LIBRARY_DIR")); v != "" {
+		cfg.Library.Dir = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_LIBRARY_GIT_URL")); v != "" {
+		cfg.Library.GitURL = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_LIBRARY_GIT_BRANCH")); v != "" {
+		cfg.Library.GitBranch = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_LIBRARY_SYNC_INTERVAL")); v != "" {
+		if parsed, ok := parseDuration(v); ok {
+			cfg.Library.SyncInterval = parsed
+		}
+	}
+}
+
+func applyMetricsEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_METRICS_HISTORY")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.Metrics.History = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_METRICS_HISTORY_RETENTION")); v != "" {
+		if parsed, ok := parseDuration(v); ok {
+			cfg.Metrics.HistoryRetention = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_METRICS_DISK_SCAN_ROOTS")); v != "" {
+		cfg.Metrics.DiskScanRoots = splitCSV(v)
+	}
+}
+
+func applyUPSEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_UPS_SOURCE")); v != "" {
+		cfg.UPS.Source = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_UPS_NAME")); v != "" {
+		cfg.UPS.Name = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_UPS_SHUTDOWN_RUNBOOK")); v != "" {
+		cfg.UPS.ShutdownRunbook = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_UPS_SHUTDOWN_RUNTIME")); v != "" {
+		if parsed, ok := parseDuration(v); ok {
+			cfg.UPS.ShutdownRuntime = parsed
+		}
+	}
+}
+
+func applyLoginsEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_LOGINS_ALERT_ROOT")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.Logins.AlertRoot = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_LOGINS_ALERT_NEW_ADDRESS")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.Logins.AlertNewAddress = parsed
+		}
+	}
+}
+
+func applyCertificatesEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_CERTIFICATES_PATHS")); v != "" {
+		cfg.Certificates.Paths = splitCSV(v)
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_CERTIFICATES_ENDPOINTS")); v != "" {
+		cfg.Certificates.Endpoints = splitCSV(v)
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_CERTIFICATES_WARN_DAYS")); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			cfg.Certificates.WarnDays = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_CERTIFICATES_CHECK_INTERVAL")); v != "" {
+		if parsed, ok := parseDuration(v); ok {
+			cfg.Certificates.CheckInterval = parsed
+		}
+	}
+}
+
+func applyStatusPageEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STATUS_PAGE_ENABLED")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.StatusPage.Enabled = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STATUS_PAGE_TITLE")); v != "" {
+		cfg.StatusPage.Title = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STATUS_PAGE_PUBLIC")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.StatusPage.Public = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STATUS_PAGE_LISTEN")); v != "" {
+		cfg.StatusPage.Listen = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_STATUS_PAGE_HISTORY_DAYS")); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			cfg.StatusPage.HistoryDays = parsed
+		}
+	}
+}
+
+func applyFilesEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_FILES_ROOTS")); v != "" {
+		cfg.Files.Roots = splitCSV(v)
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_FILES_MAX_UPLOAD_MB")); v != "" {
+		if parsed, ok := parsePositiveInt(v); ok {
+			cfg.Files.MaxUploadMB = parsed
+		}
+	}
+}
+
+func applyRecordingEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_RECORDING_ENABLED")); v != "" {
+		if parsed, ok := parseBool(v); ok {
+			cfg.Recording.Enabled = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_RECORDING_RETENTION")); v != "" {
+		if parsed, ok := parseDuration(v); ok {
+			cfg.Recording.Retention = parsed
+		}
+	}
 }
 
 func applyMultiUserEnv(cfg *Config) {
@@ -671,6 +1625,27 @@ func applyMultiUserEnv(cfg *Config) {
 	}
 }
 
+func applyUpdatesEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_UPDATES_CHANNEL")); v != "" {
+		cfg.Updates.Channel = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_UPDATES_PUBLIC_KEY")); v != "" {
+		cfg.Updates.PublicKey = v
+	}
+}
+
+func applyFederationEnv(cfg *Config) {
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_FEDERATION_TOKEN")); v != "" {
+		cfg.Federation.Token = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_FEDERATION_CENTRAL_URL")); v != "" {
+		cfg.Federation.CentralURL = v
+	}
+	if v := strings.TrimSpace(os.Getenv("SENTINEL_FEDERATION_NAME")); v != "" {
+		cfg.Federation.Name = v
+	}
+}
+
 func defaultConfigTOML(cfg Config) []byte {
 	var b strings.Builder
 	writeConfigLine(&b, "# Sentinel configuration")
@@ -692,7 +1667,7 @@ func defaultConfigTOML(cfg Config) []byte {
 	writeConfigLine(&b, "  token = %q", cfg.Server.Token)
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_SERVER_ALLOWED_ORIGINS")
 	writeConfigLine(&b, "  allowed_origins = [%s]", quoteStringList(cfg.Server.AllowedOrigins))
-	writeConfigLine(&b, "  # Additional non-loopback proxy IPs/CIDRs allowed to set X-Forwarded-Proto.")
+	writeConfigLine(&b, "  # Additional non-loopback proxy IPs/CIDRs allowed to set X-Forwarded-Proto/For.")
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_SERVER_TRUSTED_PROXIES")
 	writeConfigLine(&b, "  trusted_proxies = [%s]", quoteStringList(cfg.Server.TrustedProxies))
 	writeConfigLine(&b, "  # Cookie Secure flag: auto, always, or never.")
@@ -707,17 +1682,63 @@ func defaultConfigTOML(cfg Config) []byte {
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_SERVER_LOCALE")
 	writeConfigLine(&b, "  locale = %q", cfg.Server.Locale)
 	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Per-token and per-IP API request limits. Excess requests get 429.")
+	writeConfigLine(&b, "[rate_limit]")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_RATE_LIMIT_ENABLED")
+	writeConfigLine(&b, "  enabled = %t", cfg.RateLimit.Enabled)
+	writeConfigLine(&b, "  # GET requests per minute.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_RATE_LIMIT_READ_PER_MINUTE")
+	writeConfigLine(&b, "  read_per_minute = %d", cfg.RateLimit.ReadPerMinute)
+	writeConfigLine(&b, "  # POST, PUT, PATCH and DELETE requests per minute.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_RATE_LIMIT_MUTATE_PER_MINUTE")
+	writeConfigLine(&b, "  mutate_per_minute = %d", cfg.RateLimit.MutatePerMinute)
+	writeConfigLine(&b, "")
 	writeConfigLine(&b, "# Local SQLite database.")
 	writeConfigLine(&b, "[storage]")
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_STORAGE_PATH")
 	writeConfigLine(&b, "  path = %q", cfg.Storage.Path)
+	writeConfigLine(&b, "  # Journal mode: wal, delete, truncate or persist. WAL lets reads run")
+	writeConfigLine(&b, "  # alongside writes.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STORAGE_JOURNAL_MODE")
+	writeConfigLine(&b, "  journal_mode = %q", cfg.Storage.JournalMode)
+	writeConfigLine(&b, "  # Sync level: off, normal, full or extra.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STORAGE_SYNCHRONOUS")
+	writeConfigLine(&b, "  synchronous = %q", cfg.Storage.Synchronous)
+	writeConfigLine(&b, "  # How long a query waits for a lock held by another process.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STORAGE_BUSY_TIMEOUT")
+	writeConfigLine(&b, "  busy_timeout = %q", humanize.Duration(cfg.Storage.BusyTimeout))
+	writeConfigLine(&b, "  # Page cache: pages when positive, KiB when negative, 0 for SQLite's default.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STORAGE_CACHE_SIZE")
+	writeConfigLine(&b, "  cache_size = %d", cfg.Storage.CacheSize)
+	writeConfigLine(&b, "  # Directory for database snapshots.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STORAGE_BACKUP_DIR")
+	writeConfigLine(&b, "  backup_dir = %q", cfg.Storage.BackupDir)
+	writeConfigLine(&b, "  # Number of most recent snapshots to keep.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STORAGE_BACKUP_KEEP")
+	writeConfigLine(&b, "  backup_keep = %d", cfg.Storage.BackupKeep)
+	writeConfigLine(&b, "  # Cron expression for automatic backups (e.g. \"0 3 * * *\"). Empty disables.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STORAGE_BACKUP_SCHEDULE")
+	writeConfigLine(&b, "  backup_schedule = %q", cfg.Storage.BackupSchedule)
+	writeConfigLine(&b, "  # Cron expression for integrity check, ANALYZE and incremental VACUUM")
+	writeConfigLine(&b, "  # (e.g. \"30 3 * * *\"). Empty disables.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STORAGE_MAINTENANCE_SCHEDULE")
+	writeConfigLine(&b, "  maintenance_schedule = %q", cfg.Storage.MaintenanceSchedule)
 	writeConfigLine(&b, "")
 	writeConfigLine(&b, "# Daemon logging.")
 	writeConfigLine(&b, "[log]")
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_LOG_LEVEL")
 	writeConfigLine(&b, "  level = %q", cfg.Log.Level)
+	writeConfigLine(&b, "  # \"text\" or \"json\" (one object per line, for Loki and similar).")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_LOG_FORMAT")
+	writeConfigLine(&b, "  format = %q", cfg.Log.Format)
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_LOG_PATH")
 	writeConfigLine(&b, "  path = %q", cfg.Log.Path)
+	writeConfigLine(&b, "  # Rotate the log file at this size in MB. 0 disables rotation.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_LOG_MAX_SIZE_MB")
+	writeConfigLine(&b, "  max_size_mb = %d", cfg.Log.MaxSizeMB)
+	writeConfigLine(&b, "  # Rotated files to keep (sentinel.log.1 is the newest).")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_LOG_MAX_BACKUPS")
+	writeConfigLine(&b, "  max_backups = %d", cfg.Log.MaxBackups)
 	writeConfigLine(&b, "")
 	writeConfigLine(&b, "# Scheduled health report delivery.")
 	writeConfigLine(&b, "[health_report]")
@@ -726,6 +1747,43 @@ func defaultConfigTOML(cfg Config) []byte {
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_HEALTH_REPORT_SCHEDULE")
 	writeConfigLine(&b, "  schedule = %q", cfg.HealthReport.Schedule)
 	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# MQTT bridge publishing events to a broker. Disabled while broker is empty.")
+	writeConfigLine(&b, "[mqtt]")
+	writeConfigLine(&b, "  # e.g. mqtt://homeassistant.local:1883 or mqtts://broker.example:8883")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_MQTT_BROKER")
+	writeConfigLine(&b, "  broker = %q", cfg.MQTT.Broker)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_MQTT_CLIENT_ID")
+	writeConfigLine(&b, "  client_id = %q", cfg.MQTT.ClientID)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_MQTT_USERNAME")
+	writeConfigLine(&b, "  username = %q", cfg.MQTT.Username)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_MQTT_PASSWORD")
+	writeConfigLine(&b, "  password = %q", cfg.MQTT.Password)
+	writeConfigLine(&b, "  # Events go to <topic_prefix>/<event type with dots as slashes>.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_MQTT_TOPIC_PREFIX")
+	writeConfigLine(&b, "  topic_prefix = %q", cfg.MQTT.TopicPrefix)
+	writeConfigLine(&b, "  # 0 (at most once) or 1 (at least once).")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_MQTT_QOS")
+	writeConfigLine(&b, "  qos = %d", cfg.MQTT.QoS)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_MQTT_RETAIN")
+	writeConfigLine(&b, "  retain = %t", cfg.MQTT.Retain)
+	writeConfigLine(&b, "  # Event types to publish.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_MQTT_EVENTS")
+	writeConfigLine(&b, "  events = [%s]", quoteStringList(cfg.MQTT.Events))
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# OpenTelemetry tracing over OTLP/HTTP. Disabled while endpoint is empty.")
+	writeConfigLine(&b, "[tracing]")
+	writeConfigLine(&b, "  # Collector base URL, e.g. http://localhost:4318 (spans go to /v1/traces).")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_TRACING_ENDPOINT")
+	writeConfigLine(&b, "  endpoint = %q", cfg.Tracing.Endpoint)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_TRACING_SERVICE_NAME")
+	writeConfigLine(&b, "  service_name = %q", cfg.Tracing.ServiceName)
+	writeConfigLine(&b, "  # Share of new traces recorded, 0 to 1.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_TRACING_SAMPLE_RATIO")
+	writeConfigLine(&b, "  sample_ratio = %s", strconv.FormatFloat(cfg.Tracing.SampleRatio, 'f', -1, 64))
+	writeConfigLine(&b, "  # \"Name=value\" headers sent with each export.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_TRACING_HEADERS (comma-separated)")
+	writeConfigLine(&b, "  headers = [%s]", quoteStringList(cfg.Tracing.Headers))
+	writeConfigLine(&b, "")
 	writeConfigLine(&b, "# Background activity projection and unread journal.")
 	writeConfigLine(&b, "[watchtower]")
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_WATCHTOWER_ENABLED")
@@ -738,6 +1796,23 @@ func defaultConfigTOML(cfg Config) []byte {
 	writeConfigLine(&b, "  capture_timeout = %q", humanize.Duration(cfg.Watchtower.CaptureTimeout))
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_WATCHTOWER_JOURNAL_ROWS")
 	writeConfigLine(&b, "  journal_rows = %d", cfg.Watchtower.JournalRows)
+	writeConfigLine(&b, "  # Archive pane output to compressed logs under <data dir>/pane-logs.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_WATCHTOWER_PANE_LOG")
+	writeConfigLine(&b, "  pane_log = %t", cfg.Watchtower.PaneLog)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_WATCHTOWER_PANE_LOG_MAX_MB")
+	writeConfigLine(&b, "  pane_log_max_mb = %d", cfg.Watchtower.PaneLogMaxMB)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_WATCHTOWER_PANE_LOG_RETENTION")
+	writeConfigLine(&b, "  pane_log_retention = %q", humanize.Duration(cfg.Watchtower.PaneLogRetention))
+	writeConfigLine(&b, "  # Collect sessions idle for idle_ticks ticks less often, up to max_interval.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_WATCHTOWER_ADAPTIVE")
+	writeConfigLine(&b, "  adaptive = %t", cfg.Watchtower.Adaptive)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_WATCHTOWER_IDLE_TICKS")
+	writeConfigLine(&b, "  idle_ticks = %d", cfg.Watchtower.IdleTicks)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_WATCHTOWER_MAX_INTERVAL")
+	writeConfigLine(&b, "  max_interval = %q", humanize.Duration(cfg.Watchtower.MaxInterval))
+	writeConfigLine(&b, "  # Use one tmux control-mode connection and collect on tmux change notifications.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_WATCHTOWER_CONTROL_MODE")
+	writeConfigLine(&b, "  control_mode = %t", cfg.Watchtower.ControlMode)
 	writeConfigLine(&b, "")
 	writeConfigLine(&b, "# Model Context Protocol endpoint at /mcp.")
 	writeConfigLine(&b, "[mcp]")
@@ -745,10 +1820,120 @@ func defaultConfigTOML(cfg Config) []byte {
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_MCP_ENABLED")
 	writeConfigLine(&b, "  enabled = %t", cfg.MCP.Enabled)
 	writeConfigLine(&b, "")
-	writeConfigLine(&b, "# Manual runbook execution.")
+	writeConfigLine(&b, "# Runbook execution, shared by manual and scheduled runs.")
 	writeConfigLine(&b, "[runbooks]")
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_RUNBOOK_MAX_CONCURRENT")
 	writeConfigLine(&b, "  max_concurrent = %d", cfg.Runbooks.MaxConcurrent)
+	writeConfigLine(&b, "  # Runs waiting for a worker before new runs are rejected.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_RUNBOOK_MAX_QUEUED")
+	writeConfigLine(&b, "  max_queued = %d", cfg.Runbooks.MaxQueued)
+	writeConfigLine(&b, "  # How long shutdown waits for runs before canceling them.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_RUNBOOK_DRAIN_TIMEOUT")
+	writeConfigLine(&b, "  drain_timeout = %q", humanize.Duration(cfg.Runbooks.DrainTimeout))
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Master key of the secrets vault referenced as {{secret \"name\"}}.")
+	writeConfigLine(&b, "[secrets]")
+	writeConfigLine(&b, "  # Hex-encoded key, created with mode 0600 on first start.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_SECRETS_KEY_FILE")
+	writeConfigLine(&b, "  key_file = %q", cfg.Secrets.KeyFile)
+	writeConfigLine(&b, "  # Keep the key in the OS keyring (secret-tool or macOS Keychain) instead.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_SECRETS_KEYRING")
+	writeConfigLine(&b, "  keyring = %t", cfg.Secrets.Keyring)
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Runbooks and schedules loaded from YAML files, optionally kept in Git.")
+	writeConfigLine(&b, "[library]")
+	writeConfigLine(&b, "  # Directory of *.yaml files; empty disables the library unless git_url is set.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_LIBRARY_DIR")
+	writeConfigLine(&b, "  dir = %q", cfg.Library.Dir)
+	writeConfigLine(&b, "  # Repository cloned into dir and pulled before each sync.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_LIBRARY_GIT_URL")
+	writeConfigLine(&b, "  git_url = %q", cfg.Library.GitURL)
+	writeConfigLine(&b, "  # Branch to follow; empty follows the remote default branch.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_LIBRARY_GIT_BRANCH")
+	writeConfigLine(&b, "  git_branch = %q", cfg.Library.GitBranch)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_LIBRARY_SYNC_INTERVAL")
+	writeConfigLine(&b, "  sync_interval = %q", humanize.Duration(cfg.Library.SyncInterval))
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Persisted host metrics for historical charts.")
+	writeConfigLine(&b, "[metrics]")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_METRICS_HISTORY")
+	writeConfigLine(&b, "  history = %t", cfg.Metrics.History)
+	writeConfigLine(&b, "  # How long hourly rollups are kept (minimum 24h).")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_METRICS_HISTORY_RETENTION")
+	writeConfigLine(&b, "  history_retention = %q", humanize.Duration(cfg.Metrics.HistoryRetention))
+	writeConfigLine(&b, "  # Directories ranked by size in the disk usage breakdown.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_METRICS_DISK_SCAN_ROOTS")
+	writeConfigLine(&b, "  disk_scan_roots = [%s]", quoteStringList(cfg.Metrics.DiskScanRoots))
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# UPS power monitoring. Disabled while source is empty.")
+	writeConfigLine(&b, "[ups]")
+	writeConfigLine(&b, "  # nut (reads upsc) or apcupsd (reads apcaccess).")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_UPS_SOURCE")
+	writeConfigLine(&b, "  source = %q", cfg.UPS.Source)
+	writeConfigLine(&b, "  # NUT UPS name (e.g. \"ups@localhost\") or apcupsd host:port.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_UPS_NAME")
+	writeConfigLine(&b, "  name = %q", cfg.UPS.Name)
+	writeConfigLine(&b, "  # Runbook ID started once per outage when the battery runs low.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_UPS_SHUTDOWN_RUNBOOK")
+	writeConfigLine(&b, "  shutdown_runbook = %q", cfg.UPS.ShutdownRunbook)
+	writeConfigLine(&b, "  # Battery runtime left at which the shutdown runbook starts.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_UPS_SHUTDOWN_RUNTIME")
+	writeConfigLine(&b, "  shutdown_runtime = %q", humanize.Duration(cfg.UPS.ShutdownRuntime))
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Alerts on successful SSH logins, read from the sshd journal.")
+	writeConfigLine(&b, "[logins]")
+	writeConfigLine(&b, "  # Alert on every login as root.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_LOGINS_ALERT_ROOT")
+	writeConfigLine(&b, "  alert_root = %t", cfg.Logins.AlertRoot)
+	writeConfigLine(&b, "  # Alert when a user logs in from an address not seen for them before.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_LOGINS_ALERT_NEW_ADDRESS")
+	writeConfigLine(&b, "  alert_new_address = %t", cfg.Logins.AlertNewAddress)
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Certificate expiry watcher. Disabled while paths and endpoints are empty.")
+	writeConfigLine(&b, "[certificates]")
+	writeConfigLine(&b, "  # Absolute paths of PEM or DER certificate files.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_CERTIFICATES_PATHS")
+	writeConfigLine(&b, "  paths = [%s]", quoteStringList(cfg.Certificates.Paths))
+	writeConfigLine(&b, "  # TLS endpoints as host or host:port (port 443 by default).")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_CERTIFICATES_ENDPOINTS")
+	writeConfigLine(&b, "  endpoints = [%s]", quoteStringList(cfg.Certificates.Endpoints))
+	writeConfigLine(&b, "  # Alert this many days before a certificate expires.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_CERTIFICATES_WARN_DAYS")
+	writeConfigLine(&b, "  warn_days = %d", cfg.Certificates.WarnDays)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_CERTIFICATES_CHECK_INTERVAL")
+	writeConfigLine(&b, "  check_interval = %q", humanize.Duration(cfg.Certificates.CheckInterval))
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Read-only /status page of uptime checks and tracked services.")
+	writeConfigLine(&b, "[status_page]")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STATUS_PAGE_ENABLED")
+	writeConfigLine(&b, "  enabled = %t", cfg.StatusPage.Enabled)
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STATUS_PAGE_TITLE")
+	writeConfigLine(&b, "  title = %q", cfg.StatusPage.Title)
+	writeConfigLine(&b, "  # Serve /status on the main listener without authentication.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STATUS_PAGE_PUBLIC")
+	writeConfigLine(&b, "  public = %t", cfg.StatusPage.Public)
+	writeConfigLine(&b, "  # Extra host:port serving only the status page, without authentication.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STATUS_PAGE_LISTEN")
+	writeConfigLine(&b, "  listen = %q", cfg.StatusPage.Listen)
+	writeConfigLine(&b, "  # Days of history bars shown, up to 90.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_STATUS_PAGE_HISTORY_DAYS")
+	writeConfigLine(&b, "  history_days = %d", cfg.StatusPage.HistoryDays)
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# File browser API. Disabled while roots is empty.")
+	writeConfigLine(&b, "[files]")
+	writeConfigLine(&b, "  # Absolute directories the file API may list, download from and upload to.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_FILES_ROOTS")
+	writeConfigLine(&b, "  roots = [%s]", quoteStringList(cfg.Files.Roots))
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_FILES_MAX_UPLOAD_MB")
+	writeConfigLine(&b, "  max_upload_mb = %d", cfg.Files.MaxUploadMB)
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Asciicast recordings of attached terminals under <data dir>/recordings.")
+	writeConfigLine(&b, "[recording]")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_RECORDING_ENABLED")
+	writeConfigLine(&b, "  enabled = %t", cfg.Recording.Enabled)
+	writeConfigLine(&b, "  # How long recordings are kept after they end.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_RECORDING_RETENTION")
+	writeConfigLine(&b, "  retention = %q", humanize.Duration(cfg.Recording.Retention))
 	writeConfigLine(&b, "")
 	writeConfigLine(&b, "# OS-user session targeting.")
 	writeConfigLine(&b, "[multi_user]")
@@ -758,6 +1943,48 @@ func defaultConfigTOML(cfg Config) []byte {
 	writeConfigLine(&b, "  allow_root_target = %t", cfg.MultiUser.AllowRootTarget)
 	writeConfigLine(&b, "  # Environment variable: SENTINEL_USER_SWITCH_METHOD")
 	writeConfigLine(&b, "  user_switch_method = %q", cfg.MultiUser.UserSwitchMethod)
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Release channel followed by `sentinel update` and the autoupdate timer.")
+	writeConfigLine(&b, "[updates]")
+	writeConfigLine(&b, "  # stable or prerelease.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_UPDATES_CHANNEL")
+	writeConfigLine(&b, "  channel = %q", cfg.Updates.Channel)
+	writeConfigLine(&b, "  # Base64 ed25519 key; when set, releases must ship a checksums file")
+	writeConfigLine(&b, "  # signed with it (<checksums>.sig).")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_UPDATES_PUBLIC_KEY")
+	writeConfigLine(&b, "  public_key = %q", cfg.Updates.PublicKey)
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Multi-host federation. A central with a token accepts agents on /ws/agent;")
+	writeConfigLine(&b, "# set central_url to connect this instance to a central as an agent.")
+	writeConfigLine(&b, "[federation]")
+	writeConfigLine(&b, "  # Shared secret between the central and its agents.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_FEDERATION_TOKEN")
+	writeConfigLine(&b, "  token = %q", cfg.Federation.Token)
+	writeConfigLine(&b, "  # e.g. wss://central.example.com/ws/agent")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_FEDERATION_CENTRAL_URL")
+	writeConfigLine(&b, "  central_url = %q", cfg.Federation.CentralURL)
+	writeConfigLine(&b, "  # Name shown on the central; empty uses the hostname.")
+	writeConfigLine(&b, "  # Environment variable: SENTINEL_FEDERATION_NAME")
+	writeConfigLine(&b, "  name = %q", cfg.Federation.Name)
+	writeConfigLine(&b, "")
+	writeConfigLine(&b, "# Hosts driven from this instance over SSH, without an agent. Repeat the")
+	writeConfigLine(&b, "# table for each host; the host key must already be in known_hosts.")
+	writeConfigLine(&b, "# [[federation.ssh_hosts]]")
+	writeConfigLine(&b, "#   name = \"db-01\"")
+	writeConfigLine(&b, "#   address = \"db-01.internal\"")
+	writeConfigLine(&b, "#   port = 22")
+	writeConfigLine(&b, "#   user = \"deploy\"")
+	writeConfigLine(&b, "#   identity_file = \"~/.ssh/id_ed25519\"")
+	writeConfigLine(&b, "#   known_hosts_file = \"\"")
+	for _, host := range cfg.Federation.SSHHosts {
+		writeConfigLine(&b, "[[federation.ssh_hosts]]")
+		writeConfigLine(&b, "  name = %q", host.Name)
+		writeConfigLine(&b, "  address = %q", host.Address)
+		writeConfigLine(&b, "  port = %d", host.Port)
+		writeConfigLine(&b, "  user = %q", host.User)
+		writeConfigLine(&b, "  identity_file = %q", host.IdentityFile)
+		writeConfigLine(&b, "  known_hosts_file = %q", host.KnownHostsFile)
+	}
 	return []byte(b.String())
 }
 
@@ -820,6 +2047,20 @@ func validateListenAddress(addr string) error {
 	return nil
 }
 
+// validCertificateEndpoint reports whether endpoint is a host or host:port
+// without a URL scheme or path.
+func validCertificateEndpoint(endpoint string) bool {
+	if endpoint == "" || strings.ContainsAny(endpoint, "/@") || strings.ContainsFunc(endpoint, unicode.IsSpace) {
+		return false
+	}
+	if host, port, err := net.SplitHostPort(endpoint); err == nil {
+		value, err := strconv.Atoi(port)
+		return host != "" && err == nil && value >= 1 && value <= 65535
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(endpoint, "["), "]")
+	return !strings.Contains(host, ":") || net.ParseIP(host) != nil
+}
+
 func normalizeCookieSecure(value, fallback string) string {
 	switch strings.ToLower(strings.TrimSpace(value)) {
 	case CookieSecureAuto, CookieSecureAlways, CookieSecureNever:
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"reflect"
 	"slices"
 	"strings"
 	"testing"
@@ -43,6 +44,41 @@ func TestDefaultForDataDirDoesNotDependOnCallerHome(t *testing.T) {
 	}
 }
 
+func TestResolveDefaultsBackupDirNextToStorage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfg := Default()
+	cfg.Storage.Path = filepath.Join(dir, "custom", "sentinel.db")
+	cfg.Storage.BackupDir = ""
+	if err := cfg.Resolve(); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got, want := cfg.Storage.BackupDir, filepath.Join(dir, "custom", "backups"); got != want {
+		t.Fatalf("Storage.BackupDir = %q, want %q", got, want)
+	}
+
+	cfg.Storage.BackupSchedule = "not a cron"
+	if err := cfg.Resolve(); err == nil || !strings.Contains(err.Error(), "storage.backup_schedule") {
+		t.Fatalf("Resolve() error = %v, want backup_schedule issue", err)
+	}
+	cfg.Storage.BackupSchedule = ""
+	cfg.Storage.MaintenanceSchedule = "nightly"
+	if err := cfg.Resolve(); err == nil || !strings.Contains(err.Error(), "storage.maintenance_schedule") {
+		t.Fatalf("Resolve() error = %v, want maintenance_schedule issue", err)
+	}
+	cfg.Storage.MaintenanceSchedule = ""
+	cfg.Storage.JournalMode = "memory"
+	if err := cfg.Resolve(); err == nil || !strings.Contains(err.Error(), "storage.journal_mode") {
+		t.Fatalf("Resolve() error = %v, want journal_mode issue", err)
+	}
+	cfg.Storage.JournalMode = "wal"
+	cfg.Storage.Synchronous = "sometimes"
+	if err := cfg.Resolve(); err == nil || !strings.Contains(err.Error(), "storage.synchronous") {
+		t.Fatalf("Resolve() error = %v, want synchronous issue", err)
+	}
+}
+
 func TestDefaultForDeploymentUsesSeparateLogPath(t *testing.T) {
 	t.Parallel()
 
@@ -244,12 +280,23 @@ path = "` + dbPath + `"
 
 [log]
 level = "debug"
+format = "JSON"
 path = "` + logPath + `"
+max_size_mb = 50
 
 [health_report]
 webhook_url = "https://example.com/report"
 schedule = "@daily"
 
+[mqtt]
+broker = "mqtt://homeassistant.local"
+qos = 1
+events = ["ops.services.updated"]
+
+[tracing]
+endpoint = "http://tempo.lan:4318"
+sample_ratio = 0.25
+
 [watchtower]
 enabled = false
 tick_interval = "5s"
@@ -259,6 +306,8 @@ journal_rows = 10000
 
 [runbooks]
 max_concurrent = 8
+max_queued = 20
+drain_timeout = "1m"
 
 [mcp]
 enabled = true
@@ -267,6 +316,12 @@ enabled = true
 allowed_users = ["deploy"]
 allow_root_target = true
 user_switch_method = "sudo"
+
+[[federation.ssh_hosts]]
+name = "db-01"
+address = " db-01.internal "
+user = "deploy"
+identity_file = "` + filepath.Join(dir, "id_ed25519") + `"
 `
 	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
 		t.Fatal(err)
@@ -293,14 +348,21 @@ user_switch_method = "sudo"
 	if cfg.Storage.Path != dbPath || cfg.Log.Path != logPath {
 		t.Fatalf("paths = storage:%q log:%q", cfg.Storage.Path, cfg.Log.Path)
 	}
-	if cfg.Log.Level != "debug" {
-		t.Fatalf("Log.Level = %q", cfg.Log.Level)
+	if cfg.Log.Level != "debug" || cfg.Log.Format != "json" || cfg.Log.MaxSizeMB != 50 || cfg.Log.MaxBackups != 5 {
+		t.Fatalf("Log = %+v", cfg.Log)
 	}
 	if cfg.Watchtower.TickInterval != 5*time.Second || cfg.Watchtower.CaptureTimeout != 500*time.Millisecond {
 		t.Fatalf("Watchtower = %+v", cfg.Watchtower)
 	}
-	if cfg.Runbooks.MaxConcurrent != 8 {
-		t.Fatalf("Runbooks.MaxConcurrent = %d", cfg.Runbooks.MaxConcurrent)
+	if cfg.Runbooks.MaxConcurrent != 8 || cfg.Runbooks.MaxQueued != 20 || cfg.Runbooks.DrainTimeout != time.Minute {
+		t.Fatalf("Runbooks = %+v", cfg.Runbooks)
+	}
+	if cfg.MQTT.Broker != "mqtt://homeassistant.local" || cfg.MQTT.QoS != 1 || cfg.MQTT.ClientID != "sentinel" ||
+		!slices.Equal(cfg.MQTT.Events, []string{"ops.services.updated"}) {
+		t.Fatalf("MQTT = %+v", cfg.MQTT)
+	}
+	if cfg.Tracing.Endpoint != "http://tempo.lan:4318" || cfg.Tracing.SampleRatio != 0.25 || cfg.Tracing.ServiceName != "sentinel" {
+		t.Fatalf("Tracing = %+v", cfg.Tracing)
 	}
 	if !cfg.MCP.Enabled {
 		t.Fatal("MCP.Enabled = false, want true")
@@ -308,6 +370,10 @@ user_switch_method = "sudo"
 	if cfg.MultiUser.UserSwitchMethod != "sudo" {
 		t.Fatalf("UserSwitchMethod = %q", cfg.MultiUser.UserSwitchMethod)
 	}
+	want := []SSHHostConfig{{Name: "db-01", Address: "db-01.internal", User: "deploy", IdentityFile: filepath.Join(dir, "id_ed25519")}}
+	if !slices.Equal(cfg.Federation.SSHHosts, want) {
+		t.Fatalf("SSHHosts = %+v, want %+v", cfg.Federation.SSHHosts, want)
+	}
 }
 
 func TestLoadEnvOverridesFile(t *testing.T) {
@@ -358,17 +424,84 @@ func TestApplyEnvOverridesAllRuntimeSections(t *testing.T) {
 	t.Setenv("SENTINEL_SERVER_LOCALE", "pt-BR")
 	t.Setenv("SENTINEL_LOG_LEVEL", "debug")
 	t.Setenv("SENTINEL_LOG_PATH", "/tmp/sentinel-test.log")
+	t.Setenv("SENTINEL_LOG_FORMAT", "json")
+	t.Setenv("SENTINEL_LOG_MAX_SIZE_MB", "20")
+	t.Setenv("SENTINEL_LOG_MAX_BACKUPS", "3")
 	t.Setenv("SENTINEL_HEALTH_REPORT_WEBHOOK_URL", "https://hooks.example/sentinel")
 	t.Setenv("SENTINEL_HEALTH_REPORT_SCHEDULE", "0 * * * *")
+	t.Setenv("SENTINEL_MQTT_BROKER", "mqtts://broker.example")
+	t.Setenv("SENTINEL_MQTT_CLIENT_ID", "sentinel-web")
+	t.Setenv("SENTINEL_MQTT_USERNAME", "ha")
+	t.Setenv("SENTINEL_MQTT_PASSWORD", "pw")
+	t.Setenv("SENTINEL_MQTT_TOPIC_PREFIX", "home/sentinel")
+	t.Setenv("SENTINEL_MQTT_QOS", "1")
+	t.Setenv("SENTINEL_MQTT_RETAIN", "true")
+	t.Setenv("SENTINEL_MQTT_EVENTS", "ops.job.updated, tmux.activity.updated")
+	t.Setenv("SENTINEL_TRACING_ENDPOINT", "https://otlp.example")
+	t.Setenv("SENTINEL_TRACING_SERVICE_NAME", "sentinel-edge")
+	t.Setenv("SENTINEL_TRACING_SAMPLE_RATIO", "0.5")
+	t.Setenv("SENTINEL_TRACING_HEADERS", "Authorization=Basic abc, X-Scope-OrgID=home")
+	t.Setenv("SENTINEL_RATE_LIMIT_ENABLED", "false")
+	t.Setenv("SENTINEL_RATE_LIMIT_READ_PER_MINUTE", "300")
+	t.Setenv("SENTINEL_RATE_LIMIT_MUTATE_PER_MINUTE", "30")
+	t.Setenv("SENTINEL_STORAGE_BACKUP_DIR", "/tmp/sentinel-backups")
+	t.Setenv("SENTINEL_STORAGE_BACKUP_KEEP", "3")
+	t.Setenv("SENTINEL_STORAGE_BACKUP_SCHEDULE", "0 3 * * *")
+	t.Setenv("SENTINEL_STORAGE_MAINTENANCE_SCHEDULE", "30 3 * * *")
+	t.Setenv("SENTINEL_STORAGE_JOURNAL_MODE", "delete")
+	t.Setenv("SENTINEL_STORAGE_SYNCHRONOUS", "full")
+	t.Setenv("SENTINEL_STORAGE_BUSY_TIMEOUT", "10s")
+	t.Setenv("SENTINEL_STORAGE_CACHE_SIZE", "-8192")
 	t.Setenv("SENTINEL_WATCHTOWER_ENABLED", "true")
 	t.Setenv("SENTINEL_WATCHTOWER_TICK_INTERVAL", "3s")
 	t.Setenv("SENTINEL_WATCHTOWER_CAPTURE_LINES", "120")
 	t.Setenv("SENTINEL_WATCHTOWER_CAPTURE_TIMEOUT", "750ms")
 	t.Setenv("SENTINEL_WATCHTOWER_JOURNAL_ROWS", "240")
+	t.Setenv("SENTINEL_WATCHTOWER_PANE_LOG", "true")
+	t.Setenv("SENTINEL_WATCHTOWER_PANE_LOG_MAX_MB", "16")
+	t.Setenv("SENTINEL_WATCHTOWER_PANE_LOG_RETENTION", "72h")
+	t.Setenv("SENTINEL_WATCHTOWER_ADAPTIVE", "true")
+	t.Setenv("SENTINEL_WATCHTOWER_IDLE_TICKS", "5")
+	t.Setenv("SENTINEL_WATCHTOWER_MAX_INTERVAL", "30s")
+	t.Setenv("SENTINEL_WATCHTOWER_CONTROL_MODE", "true")
 	t.Setenv("SENTINEL_RUNBOOK_MAX_CONCURRENT", "7")
+	t.Setenv("SENTINEL_RUNBOOK_MAX_QUEUED", "12")
+	t.Setenv("SENTINEL_RUNBOOK_DRAIN_TIMEOUT", "45s")
+	t.Setenv("SENTINEL_METRICS_HISTORY", "false")
+	t.Setenv("SENTINEL_UPS_SOURCE", "nut")
+	t.Setenv("SENTINEL_UPS_NAME", "ups@localhost")
+	t.Setenv("SENTINEL_UPS_SHUTDOWN_RUNBOOK", "rb-shutdown")
+	t.Setenv("SENTINEL_UPS_SHUTDOWN_RUNTIME", "10m")
+	t.Setenv("SENTINEL_LOGINS_ALERT_ROOT", "true")
+	t.Setenv("SENTINEL_LOGINS_ALERT_NEW_ADDRESS", "true")
+	t.Setenv("SENTINEL_CERTIFICATES_PATHS", "/etc/ssl/web.pem, /etc/ssl/mail.pem")
+	t.Setenv("SENTINEL_CERTIFICATES_ENDPOINTS", "example.com, mail.example.com:465")
+	t.Setenv("SENTINEL_CERTIFICATES_WARN_DAYS", "21")
+	t.Setenv("SENTINEL_CERTIFICATES_CHECK_INTERVAL", "1h")
+	t.Setenv("SENTINEL_SECRETS_KEY_FILE", "/etc/sentinel/secrets.key")
+	t.Setenv("SENTINEL_SECRETS_KEYRING", "true")
+	t.Setenv("SENTINEL_LIBRARY_GIT_URL", "https://git.example.com/ops/runbooks.git")
+	t.Setenv("SENTINEL_LIBRARY_GIT_BRANCH", "main")
+	t.Setenv("SENTINEL_LIBRARY_SYNC_INTERVAL", "2m")
+	t.Setenv("SENTINEL_STATUS_PAGE_ENABLED", "true")
+	t.Setenv("SENTINEL_STATUS_PAGE_TITLE", "Homelab")
+	t.Setenv("SENTINEL_STATUS_PAGE_PUBLIC", "true")
+	t.Setenv("SENTINEL_STATUS_PAGE_LISTEN", "0.0.0.0:4041")
+	t.Setenv("SENTINEL_STATUS_PAGE_HISTORY_DAYS", "14")
+	t.Setenv("SENTINEL_METRICS_HISTORY_RETENTION", "168h")
+	t.Setenv("SENTINEL_METRICS_DISK_SCAN_ROOTS", "/var, /home")
+	t.Setenv("SENTINEL_FILES_ROOTS", "/srv/logs, /home/dev")
+	t.Setenv("SENTINEL_FILES_MAX_UPLOAD_MB", "16")
+	t.Setenv("SENTINEL_RECORDING_ENABLED", "true")
+	t.Setenv("SENTINEL_RECORDING_RETENTION", "48h")
 	t.Setenv("SENTINEL_ALLOWED_USERS", "alice, bob")
 	t.Setenv("SENTINEL_ALLOW_ROOT_TARGET", "true")
 	t.Setenv("SENTINEL_USER_SWITCH_METHOD", "sudo")
+	t.Setenv("SENTINEL_UPDATES_CHANNEL", "prerelease")
+	t.Setenv("SENTINEL_UPDATES_PUBLIC_KEY", "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=")
+	t.Setenv("SENTINEL_FEDERATION_TOKEN", "fleet-secret")
+	t.Setenv("SENTINEL_FEDERATION_CENTRAL_URL", "wss://central.example/ws/agent")
+	t.Setenv("SENTINEL_FEDERATION_NAME", "web-01")
 
 	cfg := Default()
 	applyEnv(&cfg)
@@ -385,17 +518,88 @@ func TestApplyEnvOverridesAllRuntimeSections(t *testing.T) {
 	if cfg.Server.Timezone != "America/Sao_Paulo" || cfg.Server.Locale != "pt-BR" {
 		t.Fatalf("server locale settings = timezone:%q locale:%q", cfg.Server.Timezone, cfg.Server.Locale)
 	}
-	if cfg.Log.Level != "debug" || cfg.Log.Path != "/tmp/sentinel-test.log" {
+	if cfg.RateLimit.Enabled || cfg.RateLimit.ReadPerMinute != 300 || cfg.RateLimit.MutatePerMinute != 30 {
+		t.Fatalf("rate limit settings = %+v", cfg.RateLimit)
+	}
+	if cfg.Log.Level != "debug" || cfg.Log.Path != "/tmp/sentinel-test.log" || cfg.Log.Format != "json" || cfg.Log.MaxSizeMB != 20 || cfg.Log.MaxBackups != 3 {
 		t.Fatalf("log settings = %+v", cfg.Log)
 	}
 	if cfg.HealthReport.WebhookURL != "https://hooks.example/sentinel" || cfg.HealthReport.Schedule != "0 * * * *" {
 		t.Fatalf("health report settings = %+v", cfg.HealthReport)
 	}
+	if cfg.MQTT.Broker != "mqtts://broker.example" || cfg.MQTT.ClientID != "sentinel-web" || cfg.MQTT.Username != "ha" ||
+		cfg.MQTT.Password != "pw" || cfg.MQTT.TopicPrefix != "home/sentinel" || cfg.MQTT.QoS != 1 || !cfg.MQTT.Retain {
+		t.Fatalf("mqtt settings = %+v", cfg.MQTT)
+	}
+	if got, want := cfg.MQTT.Events, []string{"ops.job.updated", "tmux.activity.updated"}; !slices.Equal(got, want) {
+		t.Fatalf("MQTT.Events = %v, want %v", got, want)
+	}
+	if cfg.Tracing.Endpoint != "https://otlp.example" || cfg.Tracing.ServiceName != "sentinel-edge" || cfg.Tracing.SampleRatio != 0.5 {
+		t.Fatalf("tracing settings = %+v", cfg.Tracing)
+	}
+	if got := cfg.Tracing.HeaderMap(); got["Authorization"] != "Basic abc" || got["X-Scope-OrgID"] != "home" {
+		t.Fatalf("Tracing.HeaderMap() = %v", got)
+	}
+	if cfg.Storage.BackupDir != "/tmp/sentinel-backups" || cfg.Storage.BackupKeep != 3 || cfg.Storage.BackupSchedule != "0 3 * * *" || cfg.Storage.MaintenanceSchedule != "30 3 * * *" {
+		t.Fatalf("storage backup settings = %+v", cfg.Storage)
+	}
+	if cfg.Storage.JournalMode != "delete" || cfg.Storage.Synchronous != "full" || cfg.Storage.BusyTimeout != 10*time.Second || cfg.Storage.CacheSize != -8192 {
+		t.Fatalf("storage pragma settings = %+v", cfg.Storage)
+	}
 	if !cfg.Watchtower.Enabled || cfg.Watchtower.TickInterval != 3*time.Second || cfg.Watchtower.CaptureLines != 120 || cfg.Watchtower.CaptureTimeout != 750*time.Millisecond || cfg.Watchtower.JournalRows != 240 {
 		t.Fatalf("watchtower settings = %+v", cfg.Watchtower)
 	}
-	if cfg.Runbooks.MaxConcurrent != 7 {
-		t.Fatalf("Runbooks.MaxConcurrent = %d, want 7", cfg.Runbooks.MaxConcurrent)
+	if !cfg.Watchtower.PaneLog || cfg.Watchtower.PaneLogMaxMB != 16 || cfg.Watchtower.PaneLogRetention != 72*time.Hour {
+		t.Fatalf("pane log settings = %+v", cfg.Watchtower)
+	}
+	if !cfg.Watchtower.Adaptive || cfg.Watchtower.IdleTicks != 5 || cfg.Watchtower.MaxInterval != 30*time.Second {
+		t.Fatalf("adaptive watchtower settings = %+v", cfg.Watchtower)
+	}
+	if !cfg.Watchtower.ControlMode {
+		t.Fatalf("Watchtower.ControlMode = false, want true")
+	}
+	if cfg.Updates.Channel != "prerelease" {
+		t.Fatalf("Updates.Channel = %q, want prerelease", cfg.Updates.Channel)
+	}
+	if cfg.Updates.PublicKey != "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=" {
+		t.Fatalf("Updates.PublicKey = %q", cfg.Updates.PublicKey)
+	}
+	if cfg.Federation.Token != "fleet-secret" || cfg.Federation.CentralURL != "wss://central.example/ws/agent" || cfg.Federation.Name != "web-01" {
+		t.Fatalf("federation settings = %+v", cfg.Federation)
+	}
+	if cfg.Runbooks.MaxConcurrent != 7 || cfg.Runbooks.MaxQueued != 12 || cfg.Runbooks.DrainTimeout != 45*time.Second {
+		t.Fatalf("Runbooks = %+v", cfg.Runbooks)
+	}
+	if cfg.Metrics.History || cfg.Metrics.HistoryRetention != 168*time.Hour {
+		t.Fatalf("metrics settings = %+v", cfg.Metrics)
+	}
+	if got, want := cfg.Metrics.DiskScanRoots, []string{"/var", "/home"}; !slices.Equal(got, want) {
+		t.Fatalf("DiskScanRoots = %v, want %v", got, want)
+	}
+	if cfg.UPS.Source != "nut" || cfg.UPS.Name != "ups@localhost" || cfg.UPS.ShutdownRunbook != "rb-shutdown" || cfg.UPS.ShutdownRuntime != 10*time.Minute {
+		t.Fatalf("ups settings = %+v", cfg.UPS)
+	}
+	if !cfg.Logins.AlertRoot || !cfg.Logins.AlertNewAddress {
+		t.Fatalf("logins settings = %+v", cfg.Logins)
+	}
+	if got := cfg.Certificates; !slices.Equal(got.Paths, []string{"/etc/ssl/web.pem", "/etc/ssl/mail.pem"}) ||
+		!slices.Equal(got.Endpoints, []string{"example.com", "mail.example.com:465"}) || got.WarnDays != 21 || got.CheckInterval != time.Hour {
+		t.Fatalf("certificates settings = %+v", got)
+	}
+	if got := cfg.Secrets; got.KeyFile != "/etc/sentinel/secrets.key" || !got.Keyring {
+		t.Fatalf("secrets settings = %+v", got)
+	}
+	if got := cfg.Library; got.GitURL != "https://git.example.com/ops/runbooks.git" || got.GitBranch != "main" || got.SyncInterval != 2*time.Minute {
+		t.Fatalf("library settings = %+v", got)
+	}
+	if got := cfg.StatusPage; !got.Enabled || got.Title != "Homelab" || !got.Public || got.Listen != "0.0.0.0:4041" || got.HistoryDays != 14 {
+		t.Fatalf("status page settings = %+v", got)
+	}
+	if got, want := cfg.Files.Roots, []string{"/srv/logs", "/home/dev"}; !slices.Equal(got, want) || cfg.Files.MaxUploadMB != 16 {
+		t.Fatalf("files settings = %+v, want roots %v and 16 MB uploads", cfg.Files, want)
+	}
+	if !cfg.Recording.Enabled || cfg.Recording.Retention != 48*time.Hour {
+		t.Fatalf("recording settings = %+v", cfg.Recording)
 	}
 	if got, want := cfg.MultiUser.AllowedUsers, []string{"alice", "bob"}; !slices.Equal(got, want) {
 		t.Fatalf("AllowedUsers = %v, want %v", got, want)
@@ -493,9 +697,39 @@ schedule = "@daily"
 		{name: "legacy listen rejected", content: "[server]\nlisten = \"127.0.0.1:4040\"\n", wantErr: "unknown key: server.listen"},
 		{name: "invalid port", content: "[server]\nport = 999999\n", wantErr: "server.port"},
 		{name: "invalid log level", content: "[log]\nlevel = \"verbose\"\n", wantErr: "log.level"},
+		{name: "invalid log format", content: "[log]\nformat = \"xml\"\n", wantErr: "log.format"},
 		{name: "invalid schedule", content: "[health_report]\nschedule = \"not cron\"\n", wantErr: "health_report.schedule"},
 		{name: "origin with path", content: "[server]\nallowed_origins = [\"https://example.com/path\"]\n", wantErr: "must not contain credentials, a path"},
 		{name: "invalid trusted proxy", content: "[server]\ntrusted_proxies = [\"localhost\"]\n", wantErr: "must be an IP address or CIDR"},
+		{name: "negative rate limit", content: "[rate_limit]\nread_per_minute = -1\n", wantErr: "rate_limit.read_per_minute"},
+		{name: "mqtt broker over http", content: "[mqtt]\nbroker = \"http://broker.lan\"\n", wantErr: "mqtt.broker"},
+		{name: "mqtt qos 2", content: "[mqtt]\nqos = 2\n", wantErr: "mqtt.qos"},
+		{name: "unknown mqtt event", content: "[mqtt]\nevents = [\"alerts\"]\n", wantErr: "mqtt.events"},
+		{name: "tracing endpoint scheme", content: "[tracing]\nendpoint = \"grpc://collector:4317\"\n", wantErr: "tracing.endpoint"},
+		{name: "tracing sample ratio", content: "[tracing]\nsample_ratio = 2.0\n", wantErr: "tracing.sample_ratio"},
+		{name: "tracing header", content: "[tracing]\nheaders = [\"Authorization\"]\n", wantErr: "tracing.headers"},
+		{name: "unknown update channel", content: "[updates]\nchannel = \"nightly\"\n", wantErr: "updates.channel"},
+		{name: "short update public key", content: "[updates]\npublic_key = \"c2hvcnQ=\"\n", wantErr: "updates.public_key"},
+		{name: "federation central without token", content: "[federation]\ncentral_url = \"wss://central.example/ws/agent\"\n", wantErr: "federation.central_url requires federation.token"},
+		{name: "federation central over https", content: "[federation]\ntoken = \"s\"\ncentral_url = \"https://central.example\"\n", wantErr: "ws:// or wss://"},
+		{name: "invalid federation name", content: "[federation]\nname = \"web 01\"\n", wantErr: "federation.name"},
+		{name: "ssh host address option", content: "[[federation.ssh_hosts]]\nname = \"db-01\"\naddress = \"-oProxyCommand=x\"\n", wantErr: "federation.ssh_hosts[0].address"},
+		{name: "duplicate ssh host", content: "[[federation.ssh_hosts]]\nname = \"db\"\naddress = \"a\"\n[[federation.ssh_hosts]]\nname = \"db\"\naddress = \"b\"\n", wantErr: "used by another SSH host"},
+		{name: "relative disk scan root", content: "[metrics]\ndisk_scan_roots = [\"var\"]\n", wantErr: "must be an absolute path"},
+		{name: "unknown ups source", content: "[ups]\nsource = \"upower\"\n", wantErr: "ups.source"},
+		{name: "nut without ups name", content: "[ups]\nsource = \"nut\"\n", wantErr: "ups.name is required"},
+		{name: "ups name option", content: "[ups]\nsource = \"apcupsd\"\nname = \"-h\"\n", wantErr: "ups.name must not start with -"},
+		{name: "relative certificate path", content: "[certificates]\npaths = [\"certs/web.pem\"]\n", wantErr: "certificates.paths entry"},
+		{name: "certificate endpoint url", content: "[certificates]\nendpoints = [\"https://example.com\"]\n", wantErr: "certificates.endpoints entry"},
+		{name: "certificate endpoint port", content: "[certificates]\nendpoints = [\"example.com:0\"]\n", wantErr: "certificates.endpoints entry"},
+		{name: "certificate warn days", content: "[certificates]\nwarn_days = -1\n", wantErr: "certificates.warn_days"},
+		{name: "secrets key file", content: "[secrets]\nkey_file = \"secrets.key\"\n", wantErr: "secrets.key_file"},
+		{name: "relative library dir", content: "[library]\ndir = \"library\"\n", wantErr: "library.dir"},
+		{name: "library sync interval", content: "[library]\nsync_interval = \"1s\"\n", wantErr: "library.sync_interval"},
+		{name: "library git url option", content: "[library]\ngit_url = \"--upload-pack=x\"\n", wantErr: "library.git_url"},
+		{name: "status page listen", content: "[status_page]\nlisten = \"4041\"\n", wantErr: "status_page.listen"},
+		{name: "status page listen clash", content: "[status_page]\nlisten = \"127.0.0.1:4040\"\n", wantErr: "status_page.listen must differ"},
+		{name: "status page history days", content: "[status_page]\nhistory_days = 365\n", wantErr: "status_page.history_days"},
 		{name: "https origin supports implicit loopback proxy", content: "[server]\nallowed_origins = [\"https://example.com\"]\n"},
 		{name: "https origin with trusted proxy", content: "[server]\nallowed_origins = [\"https://example.com\"]\ntrusted_proxies = [\"127.0.0.1\"]\n"},
 		{name: "unknown key", content: "[server]\nwat = true\n", wantErr: "unknown key: server.wat"},
@@ -521,6 +755,50 @@ schedule = "@daily"
 	}
 }
 
+func TestValidateContentReportsEveryIssue(t *testing.T) {
+	clearConfigEnv(t)
+
+	_, issues := ValidateContent("[server]\nport = 999999\nwat = true\n[log]\nlevel = \"loud\"\n")
+	want := []string{"unknown key: server.wat", "server.port", "log.level"}
+	if len(issues) < len(want) {
+		t.Fatalf("issues = %q, want at least %d", issues, len(want))
+	}
+	for _, fragment := range want {
+		if !slices.ContainsFunc(issues, func(issue string) bool { return strings.Contains(issue, fragment) }) {
+			t.Fatalf("issues = %q, want one containing %q", issues, fragment)
+		}
+	}
+
+	if _, issues := ValidateContent("[watchtower]\ntick_interval = \"soon\"\n"); len(issues) != 1 || !strings.Contains(issues[0], "decode config") {
+		t.Fatalf("bad duration issues = %q, want decode error", issues)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	clearConfigEnv(t)
+
+	from, _ := ValidateContent("[server]\ntoken = \"old\"\n")
+	to, issues := ValidateContent("[server]\nport = 5050\ntoken = \"new\"\nallowed_origins = [\"https://a.example\"]\n[mqtt]\npassword = \"pw\"\n[tracing]\nheaders = [\"Authorization=Bearer t\"]\n[watchtower]\ntick_interval = \"2s\"\n")
+	if len(issues) > 0 {
+		t.Fatalf("issues = %q", issues)
+	}
+	changes := Diff(from, to)
+	want := []Change{
+		{Key: "server.port", From: 4040, To: 5050},
+		{Key: "server.token", From: redactedValue, To: redactedValue},
+		{Key: "server.allowed_origins", From: []string{}, To: []string{"https://a.example"}},
+		{Key: "mqtt.password", From: "", To: redactedValue},
+		{Key: "tracing.headers", From: "", To: redactedValue},
+		{Key: "watchtower.tick_interval", From: "1s", To: "2s"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("Diff() = %#v, want %#v", changes, want)
+	}
+	if got := Diff(from, from); len(got) != 0 {
+		t.Fatalf("Diff(same) = %#v, want none", got)
+	}
+}
+
 func TestValidateFileMissing(t *testing.T) {
 	err := ValidateFile(filepath.Join(t.TempDir(), "config.toml"))
 	if err == nil || !strings.Contains(err.Error(), "config file not found") {
@@ -667,22 +945,90 @@ func clearConfigEnv(t *testing.T) {
 		"SENTINEL_SERVER_ALLOW_INSECURE_COOKIE",
 		"SENTINEL_SERVER_TIMEZONE",
 		"SENTINEL_SERVER_LOCALE",
+		"SENTINEL_RATE_LIMIT_ENABLED",
+		"SENTINEL_RATE_LIMIT_READ_PER_MINUTE",
+		"SENTINEL_RATE_LIMIT_MUTATE_PER_MINUTE",
 		"SENTINEL_STORAGE_PATH",
+		"SENTINEL_STORAGE_BACKUP_DIR",
+		"SENTINEL_STORAGE_BACKUP_KEEP",
+		"SENTINEL_STORAGE_BACKUP_SCHEDULE",
+		"SENTINEL_STORAGE_MAINTENANCE_SCHEDULE",
+		"SENTINEL_STORAGE_JOURNAL_MODE",
+		"SENTINEL_STORAGE_SYNCHRONOUS",
+		"SENTINEL_STORAGE_BUSY_TIMEOUT",
+		"SENTINEL_STORAGE_CACHE_SIZE",
 		"SENTINEL_LOG_LEVEL",
 		"SENTINEL_LOG_PATH",
+		"SENTINEL_LOG_FORMAT",
+		"SENTINEL_LOG_MAX_SIZE_MB",
+		"SENTINEL_LOG_MAX_BACKUPS",
 		ManagedDefaultLogPathEnv,
 		"SENTINEL_HEALTH_REPORT_WEBHOOK_URL",
 		"SENTINEL_HEALTH_REPORT_SCHEDULE",
+		"SENTINEL_MQTT_BROKER",
+		"SENTINEL_MQTT_CLIENT_ID",
+		"SENTINEL_MQTT_USERNAME",
+		"SENTINEL_MQTT_PASSWORD",
+		"SENTINEL_MQTT_TOPIC_PREFIX",
+		"SENTINEL_MQTT_QOS",
+		"SENTINEL_MQTT_RETAIN",
+		"SENTINEL_MQTT_EVENTS",
+		"SENTINEL_TRACING_ENDPOINT",
+		"SENTINEL_TRACING_SERVICE_NAME",
+		"SENTINEL_TRACING_SAMPLE_RATIO",
+		"SENTINEL_TRACING_HEADERS",
 		"SENTINEL_WATCHTOWER_ENABLED",
 		"SENTINEL_WATCHTOWER_TICK_INTERVAL",
 		"SENTINEL_WATCHTOWER_CAPTURE_LINES",
 		"SENTINEL_WATCHTOWER_CAPTURE_TIMEOUT",
 		"SENTINEL_WATCHTOWER_JOURNAL_ROWS",
+		"SENTINEL_WATCHTOWER_PANE_LOG",
+		"SENTINEL_WATCHTOWER_PANE_LOG_MAX_MB",
+		"SENTINEL_WATCHTOWER_PANE_LOG_RETENTION",
+		"SENTINEL_WATCHTOWER_ADAPTIVE",
+		"SENTINEL_WATCHTOWER_IDLE_TICKS",
+		"SENTINEL_WATCHTOWER_MAX_INTERVAL",
+		"SENTINEL_WATCHTOWER_CONTROL_MODE",
 		"SENTINEL_RUNBOOK_MAX_CONCURRENT",
+		"SENTINEL_RUNBOOK_MAX_QUEUED",
+		"SENTINEL_RUNBOOK_DRAIN_TIMEOUT",
+		"SENTINEL_METRICS_HISTORY",
+		"SENTINEL_METRICS_HISTORY_RETENTION",
+		"SENTINEL_METRICS_DISK_SCAN_ROOTS",
+		"SENTINEL_UPS_SOURCE",
+		"SENTINEL_UPS_NAME",
+		"SENTINEL_UPS_SHUTDOWN_RUNBOOK",
+		"SENTINEL_UPS_SHUTDOWN_RUNTIME",
+		"SENTINEL_LOGINS_ALERT_ROOT",
+		"SENTINEL_LOGINS_ALERT_NEW_ADDRESS",
+		"SENTINEL_CERTIFICATES_PATHS",
+		"SENTINEL_CERTIFICATES_ENDPOINTS",
+		"SENTINEL_CERTIFICATES_WARN_DAYS",
+		"SENTINEL_CERTIFICATES_CHECK_INTERVAL",
+		"SENTINEL_SECRETS_KEY_FILE",
+		"SENTINEL_SECRETS_KEYRING",
+		"SENTINEL_LIBRARY_DIR",
+		"SENTINEL_LIBRARY_GIT_URL",
+		"SENTINEL_LIBRARY_GIT_BRANCH",
+		"SENTINEL_LIBRARY_SYNC_INTERVAL",
+		"SENTINEL_STATUS_PAGE_ENABLED",
+		"SENTINEL_STATUS_PAGE_TITLE",
+		"SENTINEL_STATUS_PAGE_PUBLIC",
+		"SENTINEL_STATUS_PAGE_LISTEN",
+		"SENTINEL_STATUS_PAGE_HISTORY_DAYS",
+		"SENTINEL_FILES_ROOTS",
+		"SENTINEL_FILES_MAX_UPLOAD_MB",
+		"SENTINEL_RECORDING_ENABLED",
+		"SENTINEL_RECORDING_RETENTION",
 		"SENTINEL_MCP_ENABLED",
 		"SENTINEL_ALLOWED_USERS",
 		"SENTINEL_ALLOW_ROOT_TARGET",
 		"SENTINEL_USER_SWITCH_METHOD",
+		"SENTINEL_UPDATES_CHANNEL",
+		"SENTINEL_UPDATES_PUBLIC_KEY",
+		"SENTINEL_FEDERATION_TOKEN",
+		"SENTINEL_FEDERATION_CENTRAL_URL",
+		"SENTINEL_FEDERATION_NAME",
 	} {
 		t.Setenv(key, "")
 	}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/opus-domini/sentinel/internal/humanize"
+)
+
+// redactedValue replaces secrets in config diffs.
+const redactedValue = "[REDACTED]"
+
+// Change is one setting that differs between two configs, keyed by its
+// dotted TOML path.
+type Change struct {
+	Key  string `json:"key"`
+	From any    `json:"from"`
+	To   any    `json:"to"`
+}
+
+// ValidateContent checks proposed config file content the way a config file
+// is loaded, without environment overrides. It returns the resolved config
+// and every problem found: TOML syntax, unknown keys, and invalid values.
+func ValidateContent(content string) (Config, []string) {
+	cfg := Default()
+	meta, err := toml.Decode(content, &cfg)
+	if err != nil {
+		return cfg, []string{"decode config: " + err.Error()}
+	}
+	issues := undecodedIssues(meta)
+	issues = append(issues, cfg.resolveIssues()...)
+	return cfg, issues
+}
+
+func undecodedIssues(meta toml.MetaData) []string {
+	var issues []string
+	for _, key := range meta.Undecoded() {
+		issues = append(issues, "unknown key: "+strings.Join(key, "."))
+	}
+	return issues
+}
+
+// Diff lists the settings that differ between from and to, in file order.
+// The server token, the MQTT password and the tracing headers are redacted.
+func Diff(from, to Config) []Change {
+	before := flattenConfig(reflect.ValueOf(from), "")
+	after := flattenConfig(reflect.ValueOf(to), "")
+	changes := []Change{}
+	for i, setting := range before {
+		if reflect.DeepEqual(setting.value, after[i].value) {
+			continue
+		}
+		change := Change{Key: setting.key, From: setting.value, To: after[i].value}
+		switch setting.key {
+		case "server.token":
+			change.From, change.To = redactSecret(from.Server.Token), redactSecret(to.Server.Token)
+		case "mqtt.password":
+			change.From, change.To = redactSecret(from.MQTT.Password), redactSecret(to.MQTT.Password)
+		case "tracing.headers":
+			change.From = redactSecret(strings.Join(from.Tracing.Headers, ","))
+			change.To = redactSecret(strings.Join(to.Tracing.Headers, ","))
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+type flatSetting struct {
+	key   string
+	value any
+}
+
+// flattenConfig walks the toml-tagged fields of a config struct. Both sides
+// of a diff share the same type, so the settings line up by index.
+func flattenConfig(v reflect.Value, prefix string) []flatSetting {
+	var out []flatSetting
+	t := v.Type()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			out = append(out, flattenConfig(field, key+".")...)
+			continue
+		}
+		out = append(out, flatSetting{key: key, value: settingValue(field)})
+	}
+	return out
+}
+
+func settingValue(v reflect.Value) any {
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return humanize.Duration(value)
+	case []string:
+		if value == nil {
+			return []string{}
+		}
+		return value
+	case string, bool, int:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func redactSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redactedValue
+}
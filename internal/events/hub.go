@@ -9,6 +9,10 @@ import (
 // PresenceExpiry is the TTL for client presence heartbeats.
 const PresenceExpiry = 30 * time.Second
 
+// ReplaySize is the number of recent events the hub retains so reconnecting
+// subscribers can catch up on what they missed.
+const ReplaySize = 512
+
 const (
 	// TypeReady announces that the events stream is connected.
 	TypeReady = "events.ready"
@@ -18,18 +22,59 @@ const (
 	TypeTmuxInspector = "tmux.inspector.updated"
 	// TypeTmuxActivity announces that tmux activity stats changed.
 	TypeTmuxActivity = "tmux.activity.updated"
+	// TypeTmuxWatch announces that a watch rule matched new pane output.
+	TypeTmuxWatch = "tmux.watch.matched"
+	// TypeTmuxPaneExit announces that a pane's process exited non-zero or
+	// a process of a pane was OOM-killed.
+	TypeTmuxPaneExit = "tmux.pane.exited"
 	// TypeOpsOverview announces that the ops overview changed.
 	TypeOpsOverview = "ops.overview.updated"
 	// TypeOpsServices announces that ops service state changed.
 	TypeOpsServices = "ops.services.updated"
 	// TypeOpsJob announces that an ops job changed.
 	TypeOpsJob = "ops.job.updated"
+	// TypeOpsJobLog carries a chunk of live output from a running ops job
+	// step. It is transient: delivered to current subscribers only.
+	TypeOpsJobLog = "ops.job.log"
 	// TypeOpsMetrics announces that ops metrics changed.
 	TypeOpsMetrics = "ops.metrics.updated"
 	// TypeScheduleUpdated announces that scheduler state changed.
 	TypeScheduleUpdated = "ops.schedule.updated"
+	// TypeOpsHosts announces that host labels changed.
+	TypeOpsHosts = "ops.hosts.updated"
+	// TypeOpsUPS announces a UPS power transition: power lost, power
+	// restored or a shutdown runbook started.
+	TypeOpsUPS = "ops.ups.updated"
+	// TypeOpsLogins announces an alert on a successful SSH login.
+	TypeOpsLogins = "ops.logins.updated"
+	// TypeOpsCertificates announces that a watched certificate is about to
+	// expire or has expired.
+	TypeOpsCertificates = "ops.certificates.updated"
+	// TypeOpsUptime announces that an uptime check went up or down.
+	TypeOpsUptime = "ops.uptime.updated"
+	// TypeOpsHeartbeats announces that a heartbeat check-in was missed or
+	// received again.
+	TypeOpsHeartbeats = "ops.heartbeats.updated"
+	// TypeOpsBackups announces that a backup started, finished or went
+	// stale.
+	TypeOpsBackups = "ops.backups.updated"
+	// TypeOpsLibrary announces a library sync that changed runbooks or
+	// schedules, or was requested through the API.
+	TypeOpsLibrary = "ops.library.updated"
 )
 
+// Types returns the event types published on the hub, except TypeReady,
+// which only greets stream subscribers.
+func Types() []string {
+	return []string{
+		TypeTmuxSessions, TypeTmuxInspector, TypeTmuxActivity, TypeTmuxWatch, TypeTmuxPaneExit,
+		TypeOpsOverview, TypeOpsServices, TypeOpsJob, TypeOpsJobLog,
+		TypeOpsMetrics, TypeScheduleUpdated, TypeOpsHosts, TypeOpsUPS,
+		TypeOpsLogins, TypeOpsCertificates, TypeOpsUptime, TypeOpsHeartbeats,
+		TypeOpsBackups, TypeOpsLibrary,
+	}
+}
+
 // Event represents event data.
 type Event struct {
 	EventID   int64          `json:"eventId"`
@@ -53,21 +98,39 @@ type Hub struct {
 	nextSubID   int64
 	nextEventID int64
 	subscribers map[int64]chan Event
+
+	// history is a ring buffer of the most recent events, oldest at
+	// historyHead once the buffer has wrapped.
+	history     []Event
+	historyHead int
 }
 
 // NewHub creates hub.
 func NewHub() *Hub {
 	return &Hub{
 		subscribers: make(map[int64]chan Event),
+		history:     make([]Event, 0, ReplaySize),
 	}
 }
 
 // Subscribe subscribes to value.
 func (h *Hub) Subscribe(buffer int) (<-chan Event, func()) {
+	ch, _, _, unsubscribe := h.SubscribeSince(buffer, 0)
+	return ch, unsubscribe
+}
+
+// SubscribeSince subscribes and returns the retained events with an ID
+// greater than since, oldest first. Replay and registration happen under
+// the same lock, so the replayed events and the channel neither overlap nor
+// leave a gap. complete is false when events after since were already
+// evicted or since is ahead of the hub (for example after a server
+// restart); callers should then fall back to a full resync. A since of zero
+// replays nothing and is always complete.
+func (h *Hub) SubscribeSince(buffer int, since int64) (<-chan Event, []Event, bool, func()) {
 	if h == nil {
 		ch := make(chan Event)
 		close(ch)
-		return ch, func() {}
+		return ch, nil, since <= 0, func() {}
 	}
 	if buffer <= 0 {
 		buffer = 16
@@ -78,6 +141,7 @@ func (h *Hub) Subscribe(buffer int) (<-chan Event, func()) {
 	h.nextSubID++
 	id := h.nextSubID
 	h.subscribers[id] = ch
+	replay, complete := h.replayLocked(since)
 	h.mu.Unlock()
 
 	unsubscribe := func() {
@@ -91,7 +155,64 @@ func (h *Hub) Subscribe(buffer int) (<-chan Event, func()) {
 		}
 		h.mu.Unlock()
 	}
-	return ch, unsubscribe
+	return ch, replay, complete, unsubscribe
+}
+
+// LatestEventID returns the ID of the most recently published event.
+func (h *Hub) LatestEventID() int64 {
+	if h == nil {
+		return 0
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.nextEventID
+}
+
+func (h *Hub) replayLocked(since int64) ([]Event, bool) {
+	if since <= 0 {
+		return nil, true
+	}
+	if since > h.nextEventID {
+		return nil, false
+	}
+
+	ordered := make([]Event, 0, len(h.history))
+	ordered = append(ordered, h.history[h.historyHead:]...)
+	ordered = append(ordered, h.history[:h.historyHead]...)
+
+	start := len(ordered)
+	for i, evt := range ordered {
+		if evt.EventID > since {
+			start = i
+			break
+		}
+	}
+	replay := ordered[start:]
+	complete := since == h.nextEventID || (len(replay) > 0 && replay[0].EventID == since+1)
+	if len(replay) == 0 {
+		return nil, complete
+	}
+	return replay, complete
+}
+
+func (h *Hub) recordLocked(event Event) {
+	if len(h.history) < cap(h.history) {
+		h.history = append(h.history, event)
+		return
+	}
+	if len(h.history) == 0 {
+		return
+	}
+	h.history[h.historyHead] = event
+	h.historyHead = (h.historyHead + 1) % len(h.history)
+}
+
+// isTransient reports whether events of eventType are too high-volume to
+// sequence and retain for replay. Transient events carry no event ID, so
+// they never open a gap in a subscriber's sequence, and reconnecting
+// subscribers recover their state from the regular events instead.
+func isTransient(eventType string) bool {
+	return eventType == TypeOpsJobLog
 }
 
 // Publish publishes value.
@@ -102,13 +223,17 @@ func (h *Hub) Publish(event Event) {
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	if event.EventID <= 0 {
+	transient := isTransient(event.Type)
+	if event.EventID <= 0 && !transient {
 		h.nextEventID++
 		event.EventID = h.nextEventID
 	}
 	if event.Timestamp == "" {
 		event.Timestamp = time.Now().UTC().Format(time.RFC3339)
 	}
+	if !transient {
+		h.recordLocked(event)
+	}
 	// Deliver while still holding the lock that unsubscribe uses to close
 	// channels. This makes send and close mutually exclusive, so a subscriber
 	// channel can never be closed mid-send and the non-blocking send below
@@ -114,3 +114,97 @@ func TestHubConcurrentPublishUnsubscribe(t *testing.T) {
 		t.Fatal("hub stopped delivering after concurrent churn")
 	}
 }
+
+func TestSubscribeSinceReplaysMissedEvents(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	for range 5 {
+		hub.Publish(NewEvent(TypeOpsJob, nil))
+	}
+
+	ch, replay, complete, unsubscribe := hub.SubscribeSince(4, 3)
+	t.Cleanup(unsubscribe)
+
+	if !complete {
+		t.Fatal("complete = false, want true")
+	}
+	if len(replay) != 2 || replay[0].EventID != 4 || replay[1].EventID != 5 {
+		t.Fatalf("replay = %+v, want events 4 and 5", replay)
+	}
+
+	hub.Publish(NewEvent(TypeOpsJob, nil))
+	if evt := <-ch; evt.EventID != 6 {
+		t.Fatalf("live eventId = %d, want 6", evt.EventID)
+	}
+}
+
+func TestTransientEventsAreLiveOnly(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	hub.Publish(NewEvent(TypeOpsJob, nil))
+	ch, unsubscribe := hub.Subscribe(4)
+	t.Cleanup(unsubscribe)
+
+	hub.Publish(NewEvent(TypeOpsJobLog, map[string]any{"chunk": "hello"}))
+	if evt := <-ch; evt.Type != TypeOpsJobLog || evt.EventID != 0 {
+		t.Fatalf("live transient event = %+v, want %s without event ID", evt, TypeOpsJobLog)
+	}
+	if got := hub.LatestEventID(); got != 1 {
+		t.Fatalf("LatestEventID = %d, want 1", got)
+	}
+
+	_, replay, complete, unsubscribeReplay := hub.SubscribeSince(4, 1)
+	t.Cleanup(unsubscribeReplay)
+	if !complete || len(replay) != 0 {
+		t.Fatalf("replay = %+v complete=%v, want no transient events and complete", replay, complete)
+	}
+}
+
+func TestSubscribeSinceReportsEvictedHistory(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	for range ReplaySize + 10 {
+		hub.Publish(NewEvent(TypeOpsMetrics, nil))
+	}
+
+	_, replay, complete, unsubscribe := hub.SubscribeSince(1, 5)
+	t.Cleanup(unsubscribe)
+	if complete {
+		t.Fatal("complete = true, want false after eviction")
+	}
+	if len(replay) != ReplaySize || replay[0].EventID != 11 || replay[len(replay)-1].EventID != ReplaySize+10 {
+		t.Fatalf("replay len = %d, first = %d", len(replay), replay[0].EventID)
+	}
+}
+
+func TestSubscribeSinceCursorEdgeCases(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub()
+	hub.Publish(NewEvent(TypeOpsJob, nil))
+	hub.Publish(NewEvent(TypeOpsJob, nil))
+
+	tests := []struct {
+		name         string
+		since        int64
+		wantReplay   int
+		wantComplete bool
+	}{
+		{name: "no cursor", since: 0, wantReplay: 0, wantComplete: true},
+		{name: "up to date", since: 2, wantReplay: 0, wantComplete: true},
+		{name: "ahead of hub", since: 9, wantReplay: 0, wantComplete: false},
+	}
+	for _, tt := range tests {
+		_, replay, complete, unsubscribe := hub.SubscribeSince(1, tt.since)
+		unsubscribe()
+		if len(replay) != tt.wantReplay || complete != tt.wantComplete {
+			t.Fatalf("%s: replay = %d, complete = %v; want %d, %v", tt.name, len(replay), complete, tt.wantReplay, tt.wantComplete)
+		}
+	}
+	if got := hub.LatestEventID(); got != 2 {
+		t.Fatalf("LatestEventID() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,191 @@
+package federation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/ws"
+)
+
+const (
+	agentDialTimeout = 15 * time.Second
+	agentMinBackoff  = time.Second
+	agentMaxBackoff  = time.Minute
+	// agentMaxInFlight bounds concurrently served relayed requests.
+	agentMaxInFlight = 16
+)
+
+// AgentOptions configures an Agent.
+type AgentOptions struct {
+	// CentralURL is the central's agent endpoint, e.g.
+	// wss://central.example.com/ws/agent.
+	CentralURL string
+	Token      string
+	Name       string
+	Version    string
+	// Handler serves relayed requests, normally the daemon's API mux.
+	Handler http.Handler
+}
+
+// Agent keeps an outbound connection to a central Sentinel and serves the
+// requests it relays.
+type Agent struct {
+	opts AgentOptions
+	dial func(ctx context.Context, rawURL string, header http.Header) (*ws.Conn, error)
+}
+
+// NewAgent returns an agent for opts.
+func NewAgent(opts AgentOptions) *Agent {
+	return &Agent{opts: opts, dial: ws.Dial}
+}
+
+// Run connects to the central and reconnects with backoff until ctx ends.
+func (a *Agent) Run(ctx context.Context) {
+	backoff := agentMinBackoff
+	for {
+		connected, err := a.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = agentMinBackoff
+		}
+		slog.Warn("federation central connection lost", "central", a.opts.CentralURL, "err", err, "retry_in", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, agentMaxBackoff)
+	}
+}
+
+// session runs one connection. connected reports whether the handshake
+// succeeded, which resets the reconnect backoff.
+func (a *Agent) session(ctx context.Context) (connected bool, err error) {
+	dialCtx, cancel := context.WithTimeout(ctx, agentDialTimeout)
+	conn, err := a.dial(dialCtx, a.opts.CentralURL, http.Header{
+		"Authorization": {"Bearer " + a.opts.Token},
+	})
+	cancel()
+	if err != nil {
+		return false, err
+	}
+	conn.SetReadLimit(readLimit)
+	if err := writeFrame(conn, frame{Type: frameHello, Name: a.opts.Name, Version: a.opts.Version}); err != nil {
+		_ = conn.Close()
+		return false, err
+	}
+	slog.Info("federation connected to central", "central", a.opts.CentralURL, "host", a.opts.Name)
+
+	done := make(chan struct{})
+	defer close(done)
+	go pingLoop(conn, done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.WriteClose(ws.CloseGoingAway, "agent stopping")
+		case <-done:
+		}
+	}()
+
+	slots := make(chan struct{}, agentMaxInFlight)
+	for {
+		_, payload, err := conn.ReadMessage()
+		if err != nil {
+			_ = conn.Close()
+			return true, err
+		}
+		var req frame
+		if err := json.Unmarshal(payload, &req); err != nil || req.Type != frameRequest {
+			continue
+		}
+		slots <- struct{}{}
+		go func() {
+			defer func() { <-slots }()
+			if err := writeFrame(conn, serveRelayed(ctx, a.opts.Handler, req)); err != nil && !errors.Is(err, ws.ErrClosed) {
+				slog.Warn("federation response failed", "path", req.Path, "err", err)
+			}
+		}()
+	}
+}
+
+// serveRelayed runs a relayed request against handler as the identity the
+// central authenticated.
+func serveRelayed(ctx context.Context, handler http.Handler, req frame) frame {
+	if path, _, _ := strings.Cut(req.Path, "?"); !Relayable(path) {
+		return errorFrame(req.ID, http.StatusForbidden, "HOST_ROUTE_UNSUPPORTED", "path is not relayed to hosts")
+	}
+	ctx = security.WithForwardedIdentity(ctx, security.Identity{Name: req.User, Role: req.Role})
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.Path, bytes.NewReader(req.Body))
+	if err != nil {
+		return errorFrame(req.ID, http.StatusBadRequest, "INVALID_REQUEST", "invalid relayed request")
+	}
+	if req.Header != nil {
+		httpReq.Header = req.Header
+	}
+	httpReq.RemoteAddr = "127.0.0.1:0"
+
+	rec := &responseRecorder{header: http.Header{}}
+	handler.ServeHTTP(rec, httpReq)
+	if rec.overflow {
+		return errorFrame(req.ID, http.StatusBadGateway, "HOST_RESPONSE_TOO_LARGE", "host response exceeds the federation size limit")
+	}
+	return frame{
+		Type:   frameResponse,
+		ID:     req.ID,
+		Status: rec.statusCode(),
+		Header: rec.header,
+		Body:   rec.body.Bytes(),
+	}
+}
+
+func errorFrame(id string, status int, code, message string) frame {
+	body, _ := json.Marshal(map[string]any{"error": map[string]string{"code": code, "message": message}})
+	return frame{
+		Type:   frameResponse,
+		ID:     id,
+		Status: status,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   body,
+	}
+}
+
+// responseRecorder buffers a handler's response up to MaxResponseBytes.
+type responseRecorder struct {
+	header   http.Header
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (r *responseRecorder) Header() http.Header { return r.header }
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	if r.body.Len()+len(p) > MaxResponseBytes {
+		r.overflow = true
+		return 0, errors.New("federation response too large")
+	}
+	return r.body.Write(p)
+}
+
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
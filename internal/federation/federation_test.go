@@ -0,0 +1,261 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/ws"
+)
+
+// startFederation runs a hub behind a test server and an agent serving
+// handler, and waits for the agent to register.
+func startFederation(t *testing.T, handler http.Handler) (*Hub, context.CancelFunc) {
+	t.Helper()
+	hub := NewHub("fleet-secret")
+	mux := http.NewServeMux()
+	mux.Handle("GET "+AgentPath, hub)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	agent := NewAgent(AgentOptions{
+		CentralURL: "ws" + strings.TrimPrefix(srv.URL, "http") + AgentPath,
+		Token:      "fleet-secret",
+		Name:       "web-01",
+		Version:    "1.2.3",
+		Handler:    handler,
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		agent.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	waitFor(t, func() bool { return len(hub.Hosts()) == 1 })
+	return hub, cancel
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestForwardRelaysRequestAsIdentity(t *testing.T) {
+	t.Parallel()
+
+	hub, _ := startFederation(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		guard := security.New("agent-token", nil, security.CookieSecureAuto)
+		id, err := guard.Authorize(r, security.RoleOperator)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Identity", id.Name)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+	}))
+
+	hosts := hub.Hosts()
+	if hosts[0].Name != "web-01" || hosts[0].Version != "1.2.3" || hosts[0].Transport != TransportAgent {
+		t.Fatalf("Hosts() = %+v", hosts)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	resp, err := hub.Forward(ctx, "web-01", Request{
+		Method:   http.MethodPost,
+		Path:     "/api/ops/services/nginx/action?x=1",
+		Body:     []byte(`{"action":"restart"}`),
+		Identity: security.Identity{Name: "ci", Role: security.RoleOperator},
+	})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if resp.Status != http.StatusCreated || resp.Header.Get("X-Identity") != "ci" {
+		t.Fatalf("Forward() = %d %v", resp.Status, resp.Header)
+	}
+	if want := `POST /api/ops/services/nginx/action?x=1 {"action":"restart"}`; string(resp.Body) != want {
+		t.Fatalf("body = %q, want %q", resp.Body, want)
+	}
+
+	resp, err = hub.Forward(ctx, "web-01", Request{
+		Method:   http.MethodPost,
+		Path:     "/api/ops/services/nginx/action",
+		Identity: security.Identity{Name: "dash", Role: security.RoleViewer},
+	})
+	if err != nil || resp.Status != http.StatusForbidden {
+		t.Fatalf("viewer Forward() = %d, %v; want 403", resp.Status, err)
+	}
+}
+
+func TestForwardRejectsUnrelayablePaths(t *testing.T) {
+	t.Parallel()
+
+	hub, _ := startFederation(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("handler reached for an unrelayable path")
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	resp, err := hub.Forward(ctx, "web-01", Request{
+		Method:   http.MethodGet,
+		Path:     "/api/config",
+		Identity: security.Identity{Name: "owner", Role: security.RoleAdmin},
+	})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if resp.Status != http.StatusForbidden || !strings.Contains(string(resp.Body), "HOST_ROUTE_UNSUPPORTED") {
+		t.Fatalf("Forward() = %d %s", resp.Status, resp.Body)
+	}
+}
+
+func TestForwardUnknownAndDisconnectedHosts(t *testing.T) {
+	t.Parallel()
+
+	hub, stopAgent := startFederation(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	ctx := context.Background()
+	if _, err := hub.Forward(ctx, "db-01", Request{Path: "/api/ops/overview"}); !errors.Is(err, ErrHostNotFound) {
+		t.Fatalf("Forward(unknown) error = %v, want ErrHostNotFound", err)
+	}
+
+	stopAgent()
+	waitFor(t, func() bool { return len(hub.Hosts()) == 0 })
+	if _, err := hub.Forward(ctx, "web-01", Request{Path: "/api/ops/overview"}); !errors.Is(err, ErrHostNotFound) {
+		t.Fatalf("Forward(after disconnect) error = %v, want ErrHostNotFound", err)
+	}
+}
+
+func TestHubRejectsBadTokenAndName(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub("fleet-secret")
+	srv := httptest.NewServer(hub)
+	t.Cleanup(srv.Close)
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := ws.Dial(ctx, wsURL, http.Header{"Authorization": {"Bearer wrong"}})
+	var hsErr *ws.HandshakeError
+	if !errors.As(err, &hsErr) || hsErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Dial(bad token) error = %v, want 401", err)
+	}
+
+	conn, err := ws.Dial(ctx, wsURL, http.Header{"Authorization": {"Bearer fleet-secret"}})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if err := writeFrame(conn, frame{Type: frameHello, Name: "../etc"}); err != nil {
+		t.Fatalf("write hello: %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Fatal("ReadMessage() succeeded, want the hub to close the connection")
+	}
+	if hosts := hub.Hosts(); len(hosts) != 0 {
+		t.Fatalf("Hosts() = %+v, want none", hosts)
+	}
+
+	disabled := httptest.NewServer(NewHub(""))
+	t.Cleanup(disabled.Close)
+	resp, err := http.Get(disabled.URL)
+	if err != nil {
+		t.Fatalf("GET disabled hub: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("disabled hub status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestDirectHost(t *testing.T) {
+	t.Parallel()
+
+	hub := NewHub("fleet-secret")
+	hub.AddDirect(Host{Name: "db-01", Transport: TransportSSH, RemoteAddr: "db-01.internal"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := security.New("", nil, security.CookieSecureAuto).Authorize(r, security.RoleViewer)
+		if err != nil || id.Name != "ci" {
+			t.Errorf("direct handler identity = %+v, %v", id, err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, r.URL.RequestURI())
+	}))
+
+	hosts := hub.Hosts()
+	if len(hosts) != 1 || hosts[0].Transport != TransportSSH || hosts[0].ConnectedAt.IsZero() {
+		t.Fatalf("Hosts() = %+v", hosts)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	resp, err := hub.Forward(ctx, "db-01", Request{
+		Method:   http.MethodGet,
+		Path:     "/api/tmux/sessions?x=1",
+		Identity: security.Identity{Name: "ci", Role: security.RoleViewer},
+	})
+	if err != nil || resp.Status != http.StatusAccepted || string(resp.Body) != "/api/tmux/sessions?x=1" {
+		t.Fatalf("Forward() = %d %q, %v", resp.Status, resp.Body, err)
+	}
+	resp, _ = hub.Forward(ctx, "db-01", Request{Method: http.MethodGet, Path: "/api/config"})
+	if resp.Status != http.StatusForbidden {
+		t.Fatalf("Forward(unrelayable) status = %d, want 403", resp.Status)
+	}
+
+	srv := httptest.NewServer(hub)
+	t.Cleanup(srv.Close)
+	conn, err := ws.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), http.Header{"Authorization": {"Bearer fleet-secret"}})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if err := writeFrame(conn, frame{Type: frameHello, Name: "db-01"}); err != nil {
+		t.Fatalf("write hello: %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Fatal("ReadMessage() succeeded, want an agent claiming a direct host's name to be closed")
+	}
+	if hosts := hub.Hosts(); len(hosts) != 1 || hosts[0].Transport != TransportSSH {
+		t.Fatalf("Hosts() = %+v, want only the direct host", hosts)
+	}
+}
+
+func TestRelayable(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]bool{
+		"/api/tmux/sessions":                  true,
+		"/api/ops/services":                   true,
+		"/api/ops/services/nginx/logs/stream": false,
+		"/api/ops/../config":                  false,
+		"/api/config":                         false,
+		"/api/auth/token":                     false,
+		"/ws/tmux":                            false,
+	}
+	for path, want := range tests {
+		if got := Relayable(path); got != want {
+			t.Errorf("Relayable(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package federation
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/validate"
+	"github.com/opus-domini/sentinel/internal/ws"
+)
+
+var (
+	// ErrHostNotFound is returned when no agent is connected under a name.
+	ErrHostNotFound = errors.New("host not connected")
+	// ErrHostDisconnected is returned when an agent drops mid-request.
+	ErrHostDisconnected = errors.New("host disconnected")
+)
+
+// Host transports.
+const (
+	// TransportAgent hosts run an agent connected to the central.
+	TransportAgent = "agent"
+	// TransportSSH hosts are driven by the central over SSH.
+	TransportSSH = "ssh"
+)
+
+// Host describes a connected agent or a directly served host.
+type Host struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Transport   string    `json:"transport"`
+	RemoteAddr  string    `json:"remoteAddr"`
+	ConnectedAt time.Time `json:"connectedAt"`
+}
+
+// Request is an API call relayed to an agent on behalf of identity.
+type Request struct {
+	Method   string
+	Path     string
+	Header   http.Header
+	Body     []byte
+	Identity security.Identity
+}
+
+// Response is an agent's answer to a relayed Request.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Hub is the central side of federation: it accepts agent connections and
+// relays requests to them. A Hub without a token rejects every agent but
+// still serves direct hosts.
+type Hub struct {
+	token  string
+	nextID atomic.Uint64
+
+	mu     sync.Mutex
+	agents map[string]*agentConn
+	direct map[string]directHost
+}
+
+// directHost is served in-process by a handler rather than by an agent.
+type directHost struct {
+	host    Host
+	handler http.Handler
+}
+
+type agentConn struct {
+	host Host
+	conn *ws.Conn
+	done chan struct{}
+
+	mu      sync.Mutex
+	pending map[string]chan frame
+}
+
+// NewHub returns a hub that admits agents presenting token.
+func NewHub(token string) *Hub {
+	return &Hub{token: token, agents: make(map[string]*agentConn), direct: make(map[string]directHost)}
+}
+
+// AddDirect serves host in-process with handler, which sees relayed
+// requests exactly as an agent's API would. Agents cannot claim the name of
+// a direct host.
+func (h *Hub) AddDirect(host Host, handler http.Handler) {
+	if host.ConnectedAt.IsZero() {
+		host.ConnectedAt = time.Now().UTC()
+	}
+	h.mu.Lock()
+	h.direct[host.Name] = directHost{host: host, handler: handler}
+	h.mu.Unlock()
+}
+
+// ServeHTTP upgrades an agent connection and serves it until it drops. An
+// agent reconnecting under the same name replaces the previous connection.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	presented := security.BearerToken(r.Header.Get("Authorization"))
+	if subtle.ConstantTimeCompare([]byte(presented), []byte(h.token)) != 1 {
+		slog.Warn("federation agent rejected", "remote", r.RemoteAddr, "reason", "invalid token")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	conn, err := ws.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn.SetReadLimit(readLimit)
+
+	hello, err := readHello(conn)
+	if err != nil {
+		slog.Warn("federation agent rejected", "remote", r.RemoteAddr, "err", err)
+		_ = conn.WriteClose(ws.CloseProtocol, err.Error())
+		return
+	}
+	agent := &agentConn{
+		host: Host{
+			Name:        hello.Name,
+			Version:     hello.Version,
+			Transport:   TransportAgent,
+			RemoteAddr:  r.RemoteAddr,
+			ConnectedAt: time.Now().UTC(),
+		},
+		conn:    conn,
+		done:    make(chan struct{}),
+		pending: make(map[string]chan frame),
+	}
+	if !h.attach(agent) {
+		slog.Warn("federation agent rejected", "remote", r.RemoteAddr, "host", hello.Name, "reason", "name is a direct host")
+		_ = conn.WriteClose(ws.CloseProtocol, "host name is reserved")
+		return
+	}
+	slog.Info("federation agent connected", "host", hello.Name, "version", hello.Version, "remote", r.RemoteAddr)
+
+	go pingLoop(conn, agent.done)
+	agent.readLoop()
+	h.detach(agent)
+	slog.Info("federation agent disconnected", "host", hello.Name)
+}
+
+func readHello(conn *ws.Conn) (frame, error) {
+	_, payload, err := conn.ReadMessage()
+	if err != nil {
+		return frame{}, err
+	}
+	var hello frame
+	if err := json.Unmarshal(payload, &hello); err != nil || hello.Type != frameHello {
+		return frame{}, errors.New("expected hello frame")
+	}
+	if !validate.HostName(hello.Name) {
+		return frame{}, errors.New("invalid host name")
+	}
+	return hello, nil
+}
+
+// attach registers agent, replacing an agent of the same name. It fails
+// when the name belongs to a direct host.
+func (h *Hub) attach(agent *agentConn) bool {
+	h.mu.Lock()
+	if _, taken := h.direct[agent.host.Name]; taken {
+		h.mu.Unlock()
+		return false
+	}
+	previous := h.agents[agent.host.Name]
+	h.agents[agent.host.Name] = agent
+	h.mu.Unlock()
+	if previous != nil {
+		_ = previous.conn.WriteClose(ws.CloseGoingAway, "replaced by a new connection")
+	}
+	return true
+}
+
+func (h *Hub) detach(agent *agentConn) {
+	h.mu.Lock()
+	if h.agents[agent.host.Name] == agent {
+		delete(h.agents, agent.host.Name)
+	}
+	h.mu.Unlock()
+}
+
+// Hosts lists the connected agents and direct hosts sorted by name.
+func (h *Hub) Hosts() []Host {
+	if h == nil {
+		return []Host{}
+	}
+	h.mu.Lock()
+	hosts := make([]Host, 0, len(h.agents)+len(h.direct))
+	for _, agent := range h.agents {
+		hosts = append(hosts, agent.host)
+	}
+	for _, direct := range h.direct {
+		hosts = append(hosts, direct.host)
+	}
+	h.mu.Unlock()
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+	return hosts
+}
+
+// Forward relays req to the named host and waits for its response or for
+// ctx to end.
+func (h *Hub) Forward(ctx context.Context, host string, req Request) (Response, error) {
+	if h == nil {
+		return Response{}, ErrHostNotFound
+	}
+	h.mu.Lock()
+	agent := h.agents[host]
+	direct, isDirect := h.direct[host]
+	h.mu.Unlock()
+	if isDirect {
+		resp := serveRelayed(ctx, direct.handler, frame{
+			Method: req.Method,
+			Path:   req.Path,
+			Header: req.Header,
+			Body:   req.Body,
+			User:   req.Identity.Name,
+			Role:   req.Identity.Role,
+		})
+		return Response{Status: resp.Status, Header: resp.Header, Body: resp.Body}, nil
+	}
+	if agent == nil {
+		return Response{}, ErrHostNotFound
+	}
+
+	id := strconv.FormatUint(h.nextID.Add(1), 10)
+	reply := make(chan frame, 1)
+	agent.mu.Lock()
+	agent.pending[id] = reply
+	agent.mu.Unlock()
+	defer func() {
+		agent.mu.Lock()
+		delete(agent.pending, id)
+		agent.mu.Unlock()
+	}()
+
+	err := writeFrame(agent.conn, frame{
+		Type:   frameRequest,
+		ID:     id,
+		Method: req.Method,
+		Path:   req.Path,
+		Header: req.Header,
+		Body:   req.Body,
+		User:   req.Identity.Name,
+		Role:   req.Identity.Role,
+	})
+	if err != nil {
+		return Response{}, ErrHostDisconnected
+	}
+	select {
+	case resp := <-reply:
+		return Response{Status: resp.Status, Header: resp.Header, Body: resp.Body}, nil
+	case <-agent.done:
+		return Response{}, ErrHostDisconnected
+	case <-ctx.Done():
+		return Response{}, ctx.Err()
+	}
+}
+
+func (a *agentConn) readLoop() {
+	defer close(a.done)
+	defer func() { _ = a.conn.Close() }()
+	for {
+		_, payload, err := a.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var resp frame
+		if err := json.Unmarshal(payload, &resp); err != nil || resp.Type != frameResponse {
+			slog.Warn("federation agent sent an invalid frame", "host", a.host.Name)
+			continue
+		}
+		a.mu.Lock()
+		reply := a.pending[resp.ID]
+		a.mu.Unlock()
+		if reply != nil {
+			select {
+			case reply <- resp:
+			default:
+			}
+		}
+	}
+}
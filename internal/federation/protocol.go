@@ -0,0 +1,83 @@
+// Package federation links remote Sentinel agents to a central instance.
+// Agents dial the central over an outbound WebSocket, so hosts behind NAT or
+// a firewall need no inbound port, and serve the API requests it relays.
+package federation
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/ws"
+)
+
+// AgentPath is the central endpoint agents connect to.
+const AgentPath = "/ws/agent"
+
+// MaxResponseBytes bounds a relayed response body. Larger responses are
+// replaced by an error on the agent.
+const MaxResponseBytes = 8 << 20
+
+const (
+	frameHello    = "hello"
+	frameRequest  = "request"
+	frameResponse = "response"
+
+	// readLimit leaves room for the base64 and JSON overhead of a maximal
+	// response body.
+	readLimit    = MaxResponseBytes*3/2 + 64*1024
+	pingInterval = 20 * time.Second
+)
+
+// frame is the JSON message exchanged over the agent connection. Hello
+// frames come first from the agent; request frames flow from the central and
+// each is answered by a response frame with the same ID.
+type frame struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id,omitempty"`
+	Name    string        `json:"name,omitempty"`
+	Version string        `json:"version,omitempty"`
+	Method  string        `json:"method,omitempty"`
+	Path    string        `json:"path,omitempty"`
+	Header  http.Header   `json:"header,omitempty"`
+	Body    []byte        `json:"body,omitempty"`
+	User    string        `json:"user,omitempty"`
+	Role    security.Role `json:"role,omitempty"`
+	Status  int           `json:"status,omitempty"`
+}
+
+// Relayable reports whether path may be relayed to an agent. Only the tmux
+// and ops APIs are federated; auth, settings and streams stay local.
+func Relayable(path string) bool {
+	if strings.HasSuffix(path, "/stream") || strings.Contains(path, "..") {
+		return false
+	}
+	return strings.HasPrefix(path, "/api/tmux/") || strings.HasPrefix(path, "/api/ops/")
+}
+
+func writeFrame(conn *ws.Conn, f frame) error {
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return conn.WriteText(raw)
+}
+
+// pingLoop keeps the connection's read deadline fresh on the peer until done
+// is closed or a ping fails.
+func pingLoop(conn *ws.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := conn.WritePing(nil); err != nil {
+				return
+			}
+		}
+	}
+}
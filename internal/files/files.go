@@ -0,0 +1,308 @@
+// Package files lists, reads and writes files confined to a set of root
+// directories. Every operation goes through an os.Root, so symlinks cannot
+// lead outside the roots.
+package files
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrOutsideRoots is returned for paths outside every root.
+	ErrOutsideRoots = errors.New("path is outside the allowed directories")
+	// ErrInvalidName is returned for a file name that is empty, "." or
+	// "..", or contains a path separator.
+	ErrInvalidName = errors.New("invalid file name")
+	// ErrExists is returned when a write or rename would replace a file.
+	ErrExists = errors.New("file already exists")
+	// ErrIsDir is returned when a file operation targets a directory.
+	ErrIsDir = errors.New("path is a directory")
+	// ErrNotDir is returned when a directory operation targets a file.
+	ErrNotDir = errors.New("path is not a directory")
+	// ErrRoot is returned when an operation would rename or remove a root.
+	ErrRoot = errors.New("root directories cannot be changed")
+)
+
+const uploadPrefix = ".sentinel-upload-"
+
+// Entry describes one file or directory.
+type Entry struct {
+	Name       string    `json:"name"`
+	Path       string    `json:"path"`
+	Dir        bool      `json:"dir"`
+	Symlink    bool      `json:"symlink"`
+	Size       int64     `json:"size"`
+	Mode       string    `json:"mode"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+// Browser serves the files under its roots.
+type Browser struct {
+	roots []string
+}
+
+// New returns a browser for the given absolute roots. A root given through
+// a symlink is also matched by its resolved path, which is what tmux
+// reports as a pane's working directory.
+func New(roots []string) *Browser {
+	b := &Browser{}
+	seen := make(map[string]bool)
+	add := func(root string) {
+		if root != "" && filepath.IsAbs(root) && !seen[root] {
+			seen[root] = true
+			b.roots = append(b.roots, root)
+		}
+	}
+	for _, root := range roots {
+		root = filepath.Clean(strings.TrimSpace(root))
+		add(root)
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			add(resolved)
+		}
+	}
+	return b
+}
+
+// Roots returns the directories the browser serves.
+func (b *Browser) Roots() []string {
+	return append([]string(nil), b.roots...)
+}
+
+// List returns the entries of a directory, directories first.
+func (b *Browser) List(path string) ([]Entry, error) {
+	root, dir, rel, err := b.open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = root.Close() }()
+
+	f, err := root.Open(rel)
+	if err != nil {
+		return nil, rootError(err)
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, ErrNotDir
+	}
+	dirEntries, err := f.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		entry := newEntry(filepath.Join(dir, de.Name()), info)
+		if entry.Symlink {
+			// Follow the link only while it stays inside the root.
+			if target, err := root.Stat(filepath.Join(rel, de.Name())); err == nil {
+				entry.Dir = target.IsDir()
+				entry.Size = target.Size()
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Dir != entries[j].Dir {
+			return entries[i].Dir
+		}
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+	return entries, nil
+}
+
+// Open opens a regular file for reading. The caller closes it.
+func (b *Browser) Open(path string) (*os.File, Entry, error) {
+	root, abs, rel, err := b.open(path)
+	if err != nil {
+		return nil, Entry{}, err
+	}
+	defer func() { _ = root.Close() }()
+
+	f, err := root.Open(rel)
+	if err != nil {
+		return nil, Entry{}, rootError(err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, Entry{}, err
+	}
+	if info.IsDir() {
+		_ = f.Close()
+		return nil, Entry{}, ErrIsDir
+	}
+	return f, newEntry(abs, info), nil
+}
+
+// Write stores r as dir/name. The content goes to a temporary file first,
+// so a failed upload never leaves a partial file behind. An existing file
+// is replaced only when overwrite is set.
+func (b *Browser) Write(dir, name string, r io.Reader, overwrite bool) (Entry, error) {
+	if !validName(name) {
+		return Entry{}, ErrInvalidName
+	}
+	root, absDir, relDir, err := b.open(dir)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer func() { _ = root.Close() }()
+
+	if info, err := root.Stat(relDir); err != nil {
+		return Entry{}, rootError(err)
+	} else if !info.IsDir() {
+		return Entry{}, ErrNotDir
+	}
+	target := filepath.Join(relDir, name)
+	if info, err := root.Lstat(target); err == nil {
+		if info.IsDir() {
+			return Entry{}, ErrIsDir
+		}
+		if !overwrite {
+			return Entry{}, ErrExists
+		}
+	}
+
+	tmp := filepath.Join(relDir, uploadPrefix+randomSuffix())
+	f, err := root.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return Entry{}, rootError(err)
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = root.Rename(tmp, target)
+	}
+	if err != nil {
+		_ = root.Remove(tmp)
+		return Entry{}, rootError(err)
+	}
+	info, err := root.Lstat(target)
+	if err != nil {
+		return Entry{}, err
+	}
+	return newEntry(filepath.Join(absDir, name), info), nil
+}
+
+// Rename gives a file or directory a new name in the same directory.
+func (b *Browser) Rename(path, newName string) (Entry, error) {
+	if !validName(newName) {
+		return Entry{}, ErrInvalidName
+	}
+	root, abs, rel, err := b.open(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer func() { _ = root.Close() }()
+	if rel == "." {
+		return Entry{}, ErrRoot
+	}
+
+	target := filepath.Join(filepath.Dir(rel), newName)
+	if _, err := root.Lstat(target); err == nil {
+		return Entry{}, ErrExists
+	}
+	if err := root.Rename(rel, target); err != nil {
+		return Entry{}, rootError(err)
+	}
+	info, err := root.Lstat(target)
+	if err != nil {
+		return Entry{}, err
+	}
+	return newEntry(filepath.Join(filepath.Dir(abs), newName), info), nil
+}
+
+// Remove deletes a file or an empty directory.
+func (b *Browser) Remove(path string) error {
+	root, _, rel, err := b.open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = root.Close() }()
+	if rel == "." {
+		return ErrRoot
+	}
+	return rootError(root.Remove(rel))
+}
+
+// open resolves path against the innermost root containing it and returns
+// that root opened, the cleaned absolute path and the path within the root.
+func (b *Browser) open(path string) (*os.Root, string, string, error) {
+	path = strings.TrimSpace(path)
+	if !filepath.IsAbs(path) {
+		return nil, "", "", ErrOutsideRoots
+	}
+	path = filepath.Clean(path)
+
+	best := ""
+	for _, root := range b.roots {
+		if path != root && !strings.HasPrefix(path, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator)) {
+			continue
+		}
+		if len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return nil, "", "", ErrOutsideRoots
+	}
+	rel, err := filepath.Rel(best, path)
+	if err != nil {
+		return nil, "", "", ErrOutsideRoots
+	}
+	root, err := os.OpenRoot(best)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return root, path, rel, nil
+}
+
+// rootError reports a symlink leading out of the root as ErrOutsideRoots.
+// os.Root does not export the error it returns for that case.
+func rootError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) && strings.Contains(pathErr.Err.Error(), "escapes") {
+		return ErrOutsideRoots
+	}
+	return err
+}
+
+func newEntry(path string, info fs.FileInfo) Entry {
+	return Entry{
+		Name:       info.Name(),
+		Path:       path,
+		Dir:        info.IsDir(),
+		Symlink:    info.Mode()&fs.ModeSymlink != 0,
+		Size:       info.Size(),
+		Mode:       info.Mode().String(),
+		ModifiedAt: info.ModTime().UTC(),
+	}
+}
+
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`+"\x00")
+}
+
+func randomSuffix() string {
+	var buf [8]byte
+	_, _ = rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
@@ -0,0 +1,131 @@
+package files
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile(%s) error = %v", path, err)
+	}
+}
+
+func TestListSortsDirectoriesFirst(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "b.log"), "hello")
+	writeFile(t, filepath.Join(root, "A.txt"), "x")
+	if err := os.Mkdir(filepath.Join(root, "zdir"), 0o755); err != nil {
+		t.Fatalf("Mkdir error = %v", err)
+	}
+
+	entries, err := New([]string{root}).List(root)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	if got := strings.Join(names, ","); got != "zdir,A.txt,b.log" {
+		t.Fatalf("names = %s, want zdir,A.txt,b.log", got)
+	}
+	if entries[2].Size != 5 || entries[2].Path != filepath.Join(root, "b.log") {
+		t.Fatalf("b.log entry = %+v", entries[2])
+	}
+}
+
+func TestPathsOutsideRootsAreRejected(t *testing.T) {
+	t.Parallel()
+
+	base := t.TempDir()
+	root := filepath.Join(base, "work")
+	outside := filepath.Join(base, "secret")
+	for _, dir := range []string{root, outside} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatalf("Mkdir error = %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(outside, "key"), "s3cr3t")
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatalf("Symlink error = %v", err)
+	}
+	browser := New([]string{root})
+
+	for _, path := range []string{
+		outside,
+		filepath.Join(root, "..", "secret", "key"),
+		"relative/path",
+		base + "/work-other",
+	} {
+		if _, err := browser.List(path); !errors.Is(err, ErrOutsideRoots) {
+			t.Fatalf("List(%q) error = %v, want ErrOutsideRoots", path, err)
+		}
+	}
+	if _, _, err := browser.Open(filepath.Join(root, "link", "key")); !errors.Is(err, ErrOutsideRoots) {
+		t.Fatalf("Open(through symlink) error = %v, want ErrOutsideRoots", err)
+	}
+}
+
+func TestWriteRenameRemove(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	browser := New([]string{root})
+
+	entry, err := browser.Write(root, "out.log", strings.NewReader("line\n"), false)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if entry.Size != 5 {
+		t.Fatalf("entry.Size = %d, want 5", entry.Size)
+	}
+	if _, err := browser.Write(root, "out.log", strings.NewReader("again"), false); !errors.Is(err, ErrExists) {
+		t.Fatalf("Write(existing) error = %v, want ErrExists", err)
+	}
+	if _, err := browser.Write(root, "out.log", strings.NewReader("again"), true); err != nil {
+		t.Fatalf("Write(overwrite) error = %v", err)
+	}
+	if _, err := browser.Write(root, "../escape", strings.NewReader("x"), false); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("Write(../escape) error = %v, want ErrInvalidName", err)
+	}
+
+	f, _, err := browser.Open(filepath.Join(root, "out.log"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	_ = f.Close()
+	if string(data) != "again" {
+		t.Fatalf("content = %q, want again", data)
+	}
+
+	renamed, err := browser.Rename(filepath.Join(root, "out.log"), "old.log")
+	if err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if renamed.Path != filepath.Join(root, "old.log") {
+		t.Fatalf("renamed.Path = %s", renamed.Path)
+	}
+	if err := browser.Remove(renamed.Path); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := browser.Remove(root); !errors.Is(err, ErrRoot) {
+		t.Fatalf("Remove(root) error = %v, want ErrRoot", err)
+	}
+
+	entries, err := browser.List(root)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("entries = %+v, want none (no leftover temp files)", entries)
+	}
+}
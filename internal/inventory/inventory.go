@@ -0,0 +1,272 @@
+// Package inventory combines the federated hosts that are connected with
+// the labels stored for them, rolls up their health, and runs service
+// actions on the hosts a label selector picks.
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/federation"
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+// Host statuses.
+const (
+	StatusOnline  = "online"
+	StatusOffline = "offline"
+)
+
+// Host health states.
+const (
+	HealthHealthy  = "healthy"
+	HealthDegraded = "degraded"
+	HealthUnknown  = "unknown"
+	HealthOffline  = "offline"
+)
+
+const (
+	healthTimeout = 5 * time.Second
+	actionTimeout = 30 * time.Second
+)
+
+// ErrNoRelay is returned when federation is not enabled.
+var ErrNoRelay = errors.New("federation is not enabled")
+
+// Repo reads the stored host labels.
+type Repo interface {
+	ListOpsHosts(ctx context.Context) ([]store.OpsHost, error)
+}
+
+// Relay lists the connected hosts and relays API calls to them.
+type Relay interface {
+	Hosts() []federation.Host
+	Forward(ctx context.Context, host string, req federation.Request) (federation.Response, error)
+}
+
+// Host is one inventory entry: a connected host, a labelled host that is
+// offline, or both.
+type Host struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels"`
+	Status      string            `json:"status"`
+	Transport   string            `json:"transport,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	ConnectedAt *time.Time        `json:"connectedAt,omitempty"`
+	Health      string            `json:"health,omitempty"`
+	Services    *services.Summary `json:"services,omitempty"`
+	HealthError string            `json:"healthError,omitempty"`
+}
+
+// Summary counts hosts by status and health.
+type Summary struct {
+	Total    int `json:"total"`
+	Online   int `json:"online"`
+	Offline  int `json:"offline"`
+	Healthy  int `json:"healthy"`
+	Degraded int `json:"degraded"`
+	Unknown  int `json:"unknown"`
+}
+
+// ServiceAction is a unit action run on a host.
+type ServiceAction struct {
+	Unit    string `json:"unit"`
+	Scope   string `json:"scope"`
+	Manager string `json:"manager"`
+	Action  string `json:"action"`
+}
+
+// Inventory reads hosts from repo and relay. Either may be nil: without a
+// relay every labelled host is offline, without a repo hosts have no labels.
+type Inventory struct {
+	repo  Repo
+	relay Relay
+}
+
+// New returns an Inventory over repo and relay.
+func New(repo Repo, relay Relay) *Inventory {
+	return &Inventory{repo: repo, relay: relay}
+}
+
+// Hosts returns every connected or labelled host ordered by name, without
+// health.
+func (inv *Inventory) Hosts(ctx context.Context) ([]Host, error) {
+	byName := map[string]*Host{}
+	if inv.repo != nil {
+		stored, err := inv.repo.ListOpsHosts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range stored {
+			byName[item.Name] = &Host{Name: item.Name, Labels: item.Labels, Status: StatusOffline}
+		}
+	}
+	if inv.relay != nil {
+		for _, connected := range inv.relay.Hosts() {
+			host, ok := byName[connected.Name]
+			if !ok {
+				host = &Host{Name: connected.Name, Labels: map[string]string{}}
+				byName[connected.Name] = host
+			}
+			connectedAt := connected.ConnectedAt
+			host.Status = StatusOnline
+			host.Transport = connected.Transport
+			host.Version = connected.Version
+			host.ConnectedAt = &connectedAt
+		}
+	}
+
+	hosts := make([]Host, 0, len(byName))
+	for _, host := range byName {
+		hosts = append(hosts, *host)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+	return hosts, nil
+}
+
+// MatchHosts returns the names of the online hosts selector picks.
+func (inv *Inventory) MatchHosts(ctx context.Context, selector string) ([]string, error) {
+	sel, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := inv.Hosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, host := range hosts {
+		if host.Status == StatusOnline && sel.Matches(host.Labels) {
+			names = append(names, host.Name)
+		}
+	}
+	return names, nil
+}
+
+// WithHealth fills in the health of hosts from each online host's service
+// overview, fetched in parallel on behalf of id. A host with failed services
+// is degraded; one that does not answer is unknown.
+func (inv *Inventory) WithHealth(ctx context.Context, id security.Identity, hosts []Host) []Host {
+	var wg sync.WaitGroup
+	for i := range hosts {
+		host := &hosts[i]
+		if host.Status != StatusOnline || inv.relay == nil {
+			host.Health = HealthOffline
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			summary, err := inv.serviceSummary(ctx, id, host.Name)
+			switch {
+			case err != nil:
+				host.Health = HealthUnknown
+				host.HealthError = err.Error()
+			case summary.Failed > 0:
+				host.Health = HealthDegraded
+				host.Services = &summary
+			default:
+				host.Health = HealthHealthy
+				host.Services = &summary
+			}
+		}()
+	}
+	wg.Wait()
+	return hosts
+}
+
+// Rollup counts hosts by status and health.
+func Rollup(hosts []Host) Summary {
+	summary := Summary{Total: len(hosts)}
+	for _, host := range hosts {
+		if host.Status == StatusOnline {
+			summary.Online++
+		} else {
+			summary.Offline++
+		}
+		switch host.Health {
+		case HealthHealthy:
+			summary.Healthy++
+		case HealthDegraded:
+			summary.Degraded++
+		case HealthUnknown:
+			summary.Unknown++
+		}
+	}
+	return summary
+}
+
+// ServiceAction runs action on host as the runbook engine. Running a
+// runbook takes no admin role, but defining its steps does, so the action
+// is relayed with admin rights.
+func (inv *Inventory) ServiceAction(ctx context.Context, host string, action ServiceAction) error {
+	body, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, actionTimeout)
+	defer cancel()
+	_, err = inv.call(ctx, host, federation.Request{
+		Method:   http.MethodPost,
+		Path:     "/api/ops/services/unit/action",
+		Header:   http.Header{"Content-Type": {"application/json"}},
+		Body:     body,
+		Identity: security.Identity{Name: "runbook", Role: security.RoleAdmin},
+	})
+	return err
+}
+
+func (inv *Inventory) serviceSummary(ctx context.Context, id security.Identity, host string) (services.Summary, error) {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+	body, err := inv.call(ctx, host, federation.Request{
+		Method:   http.MethodGet,
+		Path:     "/api/ops/overview",
+		Identity: id,
+	})
+	if err != nil {
+		return services.Summary{}, err
+	}
+	var payload struct {
+		Data struct {
+			Overview services.Overview `json:"overview"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return services.Summary{}, fmt.Errorf("decode overview: %w", err)
+	}
+	return payload.Data.Overview.Services, nil
+}
+
+// call relays req to host and returns the body of a 2xx response. Other
+// statuses become errors carrying the API error message.
+func (inv *Inventory) call(ctx context.Context, host string, req federation.Request) ([]byte, error) {
+	if inv.relay == nil {
+		return nil, ErrNoRelay
+	}
+	resp, err := inv.relay.Forward(ctx, host, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status >= 200 && resp.Status <= 299 {
+		return resp.Body, nil
+	}
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(resp.Body, &payload) == nil && strings.TrimSpace(payload.Error.Message) != "" {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.Status, payload.Error.Message)
+	}
+	return nil, fmt.Errorf("HTTP %d", resp.Status)
+}
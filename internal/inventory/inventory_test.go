@@ -0,0 +1,166 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/federation"
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+type fakeRepo []store.OpsHost
+
+func (f fakeRepo) ListOpsHosts(context.Context) ([]store.OpsHost, error) { return f, nil }
+
+type fakeRelay struct {
+	hosts   []federation.Host
+	forward func(host string, req federation.Request) federation.Response
+
+	mu       sync.Mutex
+	requests []federation.Request
+}
+
+func (f *fakeRelay) Hosts() []federation.Host { return f.hosts }
+
+func (f *fakeRelay) Forward(_ context.Context, host string, req federation.Request) (federation.Response, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+	if !slices.ContainsFunc(f.hosts, func(h federation.Host) bool { return h.Name == host }) {
+		return federation.Response{}, federation.ErrHostNotFound
+	}
+	return f.forward(host, req), nil
+}
+
+func overviewResponse(active, failed int) federation.Response {
+	body := fmt.Sprintf(`{"data":{"overview":{"services":{"total":%d,"active":%d,"failed":%d}}}}`, active+failed, active, failed)
+	return federation.Response{Status: http.StatusOK, Body: []byte(body)}
+}
+
+func testInventory() (*Inventory, *fakeRelay) {
+	repo := fakeRepo{
+		{Name: "db-01", Labels: map[string]string{"env": "prod", "role": "db"}},
+		{Name: "web-01", Labels: map[string]string{"env": "prod", "role": "web"}},
+		{Name: "web-02", Labels: map[string]string{"env": "prod", "role": "web"}},
+	}
+	relay := &fakeRelay{
+		hosts: []federation.Host{
+			{Name: "web-01", Transport: federation.TransportAgent, Version: "1.4.0", ConnectedAt: time.Now()},
+			{Name: "db-01", Transport: federation.TransportSSH, ConnectedAt: time.Now()},
+			{Name: "cache-01", Transport: federation.TransportAgent, ConnectedAt: time.Now()},
+		},
+		forward: func(host string, _ federation.Request) federation.Response {
+			switch host {
+			case "web-01":
+				return overviewResponse(4, 0)
+			case "db-01":
+				return overviewResponse(3, 1)
+			default:
+				return federation.Response{Status: http.StatusServiceUnavailable, Body: []byte(`{"error":{"code":"OPS_UNAVAILABLE","message":"ops control plane unavailable"}}`)}
+			}
+		},
+	}
+	return New(repo, relay), relay
+}
+
+func TestHostsMergesLabelsAndConnections(t *testing.T) {
+	t.Parallel()
+
+	inv, _ := testInventory()
+	hosts, err := inv.Hosts(context.Background())
+	if err != nil {
+		t.Fatalf("Hosts() error = %v", err)
+	}
+	var got []string
+	for _, host := range hosts {
+		got = append(got, host.Name+":"+host.Status+":"+host.Labels["role"])
+	}
+	want := []string{"cache-01:online:", "db-01:online:db", "web-01:online:web", "web-02:offline:web"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("hosts = %q, want %q", got, want)
+	}
+	if hosts[1].Transport != federation.TransportSSH || hosts[3].ConnectedAt != nil {
+		t.Fatalf("hosts = %+v", hosts)
+	}
+
+	matched, err := inv.MatchHosts(context.Background(), "role=web")
+	if err != nil || !slices.Equal(matched, []string{"web-01"}) {
+		t.Fatalf("MatchHosts(role=web) = %v, %v; want only the online web host", matched, err)
+	}
+	if _, err := inv.MatchHosts(context.Background(), ""); err == nil {
+		t.Fatal("MatchHosts(empty) succeeded")
+	}
+}
+
+func TestWithHealthRollsUp(t *testing.T) {
+	t.Parallel()
+
+	inv, relay := testInventory()
+	hosts, _ := inv.Hosts(context.Background())
+	viewer := security.Identity{Name: "ci", Role: security.RoleViewer}
+	hosts = inv.WithHealth(context.Background(), viewer, hosts)
+
+	health := map[string]string{}
+	for _, host := range hosts {
+		health[host.Name] = host.Health
+	}
+	if health["web-01"] != HealthHealthy || health["db-01"] != HealthDegraded || health["cache-01"] != HealthUnknown || health["web-02"] != HealthOffline {
+		t.Fatalf("health = %v", health)
+	}
+	if !strings.Contains(hosts[0].HealthError, "ops control plane unavailable") {
+		t.Fatalf("cache-01 health error = %q", hosts[0].HealthError)
+	}
+	if hosts[1].Services == nil || hosts[1].Services.Failed != 1 {
+		t.Fatalf("db-01 services = %+v", hosts[1].Services)
+	}
+	for _, req := range relay.requests {
+		if req.Path != "/api/ops/overview" || req.Identity != viewer {
+			t.Fatalf("health request = %+v", req)
+		}
+	}
+
+	want := Summary{Total: 4, Online: 3, Offline: 1, Healthy: 1, Degraded: 1, Unknown: 1}
+	if got := Rollup(hosts); got != want {
+		t.Fatalf("Rollup() = %+v, want %+v", got, want)
+	}
+}
+
+func TestServiceActionRelaysUnitAction(t *testing.T) {
+	t.Parallel()
+
+	inv, relay := testInventory()
+	relay.forward = func(host string, req federation.Request) federation.Response {
+		if host == "db-01" {
+			return federation.Response{Status: http.StatusInternalServerError, Body: []byte(`{"error":{"code":"OPS_ACTION_FAILED","message":"unit action failed"}}`)}
+		}
+		return federation.Response{Status: http.StatusOK, Body: []byte(`{"data":{}}`)}
+	}
+
+	action := ServiceAction{Unit: "nginx.service", Scope: "system", Manager: "systemd", Action: "restart"}
+	if err := inv.ServiceAction(context.Background(), "web-01", action); err != nil {
+		t.Fatalf("ServiceAction() error = %v", err)
+	}
+	req := relay.requests[0]
+	if req.Method != http.MethodPost || req.Path != "/api/ops/services/unit/action" || req.Identity.Role != security.RoleAdmin {
+		t.Fatalf("request = %+v", req)
+	}
+	var body ServiceAction
+	if err := json.Unmarshal(req.Body, &body); err != nil || body != action {
+		t.Fatalf("body = %s", req.Body)
+	}
+
+	if err := inv.ServiceAction(context.Background(), "db-01", action); err == nil || err.Error() != "HTTP 500: unit action failed" {
+		t.Fatalf("ServiceAction(failing) error = %v", err)
+	}
+	if err := New(nil, nil).ServiceAction(context.Background(), "web-01", action); err != ErrNoRelay {
+		t.Fatalf("ServiceAction(no relay) error = %v, want ErrNoRelay", err)
+	}
+}
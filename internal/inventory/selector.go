@@ -0,0 +1,101 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const maxLabels = 32
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]{0,62}$`)
+	labelValuePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{0,63}$`)
+)
+
+// ValidateLabels checks label keys and values. Keys start with a letter or
+// digit; values may be empty. Neither may contain spaces, commas or "=", so
+// every label can be written in a selector.
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > maxLabels {
+		return fmt.Errorf("a host takes at most %d labels", maxLabels)
+	}
+	for key, value := range labels {
+		if !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("label key %q is invalid", key)
+		}
+		if !labelValuePattern.MatchString(value) {
+			return fmt.Errorf("label %q value %q is invalid", key, value)
+		}
+	}
+	return nil
+}
+
+// Selector picks hosts by label. Its text form is a comma-separated list of
+// requirements that must all hold: "key=value", "key!=value", or a bare
+// "key" for hosts that have the label at all; "*" selects every host.
+type Selector struct {
+	all          bool
+	requirements []requirement
+}
+
+type requirement struct {
+	key   string
+	value string
+	op    string // "=", "!=" or "" (exists)
+}
+
+// ParseSelector parses the text form of a Selector.
+func ParseSelector(raw string) (Selector, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Selector{}, errors.New("host selector is empty")
+	}
+	if raw == "*" {
+		return Selector{all: true}, nil
+	}
+	var sel Selector
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		req := requirement{key: part}
+		if key, value, ok := strings.Cut(part, "!="); ok {
+			req = requirement{key: strings.TrimSpace(key), value: strings.TrimSpace(value), op: "!="}
+		} else if key, value, ok := strings.Cut(part, "="); ok {
+			req = requirement{key: strings.TrimSpace(key), value: strings.TrimSpace(value), op: "="}
+		}
+		if !labelKeyPattern.MatchString(req.key) {
+			return Selector{}, fmt.Errorf("host selector %q: label key %q is invalid", raw, req.key)
+		}
+		if !labelValuePattern.MatchString(req.value) {
+			return Selector{}, fmt.Errorf("host selector %q: label value %q is invalid", raw, req.value)
+		}
+		sel.requirements = append(sel.requirements, req)
+	}
+	return sel, nil
+}
+
+// Matches reports whether labels satisfy every requirement.
+func (s Selector) Matches(labels map[string]string) bool {
+	if s.all {
+		return true
+	}
+	for _, req := range s.requirements {
+		value, ok := labels[req.key]
+		switch req.op {
+		case "=":
+			if !ok || value != req.value {
+				return false
+			}
+		case "!=":
+			if ok && value == req.value {
+				return false
+			}
+		default:
+			if !ok {
+				return false
+			}
+		}
+	}
+	return len(s.requirements) > 0
+}
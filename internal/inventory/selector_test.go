@@ -0,0 +1,62 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectorMatches(t *testing.T) {
+	t.Parallel()
+
+	web := map[string]string{"env": "prod", "role": "web"}
+	db := map[string]string{"env": "prod", "role": "db", "backup": ""}
+	tests := []struct {
+		selector string
+		web, db  bool
+	}{
+		{"role=web", true, false},
+		{"env=prod", true, true},
+		{" env = prod , role != web ", false, true},
+		{"backup", false, true},
+		{"role=web,env=staging", false, false},
+		{"zone!=eu", true, true},
+		{"*", true, true},
+	}
+	for _, tt := range tests {
+		sel, err := ParseSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("ParseSelector(%q) error = %v", tt.selector, err)
+		}
+		if got := sel.Matches(web); got != tt.web {
+			t.Errorf("%q matches web = %v, want %v", tt.selector, got, tt.web)
+		}
+		if got := sel.Matches(db); got != tt.db {
+			t.Errorf("%q matches db = %v, want %v", tt.selector, got, tt.db)
+		}
+	}
+
+	for _, raw := range []string{"", " ", "role=web,", "=web", "role=web app", "role=a=b"} {
+		if _, err := ParseSelector(raw); err == nil {
+			t.Errorf("ParseSelector(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateLabels(map[string]string{"env": "prod", "team/owner": "sre", "canary": ""}); err != nil {
+		t.Fatalf("ValidateLabels(valid) error = %v", err)
+	}
+	for _, labels := range []map[string]string{
+		{"": "x"},
+		{"-env": "prod"},
+		{"env": "prod,web"},
+		{"env": "a=b"},
+		{"role": strings.Repeat("x", 64)},
+	} {
+		if err := ValidateLabels(labels); err == nil {
+			t.Errorf("ValidateLabels(%v) succeeded", labels)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Package jobqueue runs background jobs on a bounded pool of workers. Jobs
+// beyond the concurrency limit wait in priority order, first in first out
+// within a priority, until a worker frees up.
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Priority orders waiting jobs; higher runs first.
+type Priority int
+
+// Job priorities.
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+// String returns the name used for p in the API.
+func (p Priority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "low"
+	case p > PriorityNormal:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// ParsePriority parses "low", "normal" or "high". Empty is normal.
+func ParsePriority(raw string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "normal":
+		return PriorityNormal, nil
+	case "low":
+		return PriorityLow, nil
+	case "high":
+		return PriorityHigh, nil
+	default:
+		return PriorityNormal, fmt.Errorf("priority must be low, normal or high, got %q", raw)
+	}
+}
+
+var (
+	// ErrFull is returned when every worker is busy and the queue holds as
+	// many waiting jobs as it allows.
+	ErrFull = errors.New("job queue is full")
+	// ErrClosed is returned once Shutdown has begun.
+	ErrClosed = errors.New("job queue is shut down")
+)
+
+// Job is a unit of work. Run must return promptly once ctx is cancelled.
+// A job cancelled while still waiting is run with its cancelled context,
+// so it can record that it never started.
+type Job struct {
+	ID       string
+	Priority Priority
+	Run      func(ctx context.Context)
+}
+
+// Queue runs jobs with at most MaxConcurrent at a time.
+type Queue struct {
+	maxConcurrent int
+	maxQueued     int
+
+	// ctx is cancelled when Shutdown gives up waiting; every job context
+	// derives from it.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	running  int
+	reserved int
+	pending  []*entry
+	closed   bool
+
+	// wg counts jobs from Reserve until Run returns, including the ones
+	// still waiting.
+	wg sync.WaitGroup
+}
+
+type entry struct {
+	job    Job
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New returns a queue running up to maxConcurrent jobs (at least 1). Up to
+// maxQueued more jobs wait for a worker; 0 rejects a job when every worker
+// is busy and a negative value lets the queue grow without bound.
+func New(maxConcurrent, maxQueued int) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		maxConcurrent: max(maxConcurrent, 1),
+		maxQueued:     maxQueued,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Reservation holds room in the queue for one job. Callers reserve before
+// doing work that only makes sense if the job can run, such as persisting
+// a run record, then Submit or Release it.
+type Reservation struct {
+	q    *Queue
+	once sync.Once
+}
+
+// Reserve claims room for one job.
+func (q *Queue) Reserve() (*Reservation, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, ErrClosed
+	}
+	if q.maxQueued >= 0 && q.running+len(q.pending)+q.reserved >= q.maxConcurrent+q.maxQueued {
+		return nil, ErrFull
+	}
+	q.reserved++
+	q.wg.Add(1)
+	return &Reservation{q: q}, nil
+}
+
+// Release gives the room back without running a job. Only the first call
+// to Release or Submit on a reservation has an effect.
+func (r *Reservation) Release() {
+	r.once.Do(func() {
+		r.q.mu.Lock()
+		r.q.reserved--
+		r.q.mu.Unlock()
+		r.q.wg.Done()
+	})
+}
+
+// Submit starts job on a free worker or queues it. The job's context is
+// cancelled when ctx is, or when Shutdown stops waiting for it.
+func (r *Reservation) Submit(ctx context.Context, job Job) {
+	r.once.Do(func() { r.q.submit(ctx, job) })
+}
+
+// Submit reserves room for job and submits it.
+func (q *Queue) Submit(ctx context.Context, job Job) error {
+	r, err := q.Reserve()
+	if err != nil {
+		return err
+	}
+	r.Submit(ctx, job)
+	return nil
+}
+
+func (q *Queue) submit(ctx context.Context, job Job) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(q.ctx, cancel)
+	e := &entry{job: job, ctx: jobCtx, cancel: func() { stop(); cancel() }}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.reserved--
+	if q.running < q.maxConcurrent {
+		q.running++
+		q.start(e, true)
+		return
+	}
+	i := len(q.pending)
+	for i > 0 && q.pending[i-1].job.Priority < job.Priority {
+		i--
+	}
+	q.pending = append(q.pending, nil)
+	copy(q.pending[i+1:], q.pending[i:])
+	q.pending[i] = e
+	context.AfterFunc(jobCtx, func() { q.abandon(e) })
+}
+
+// start runs e on a new worker goroutine. Jobs holding a worker slot hand
+// it to the next waiting job when they finish.
+func (q *Queue) start(e *entry, slot bool) {
+	go func() {
+		defer q.wg.Done()
+		defer e.cancel()
+		if slot {
+			defer q.next()
+		}
+		e.job.Run(e.ctx)
+	}()
+}
+
+// next frees a worker slot and starts the first waiting job on it.
+func (q *Queue) next() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		q.running--
+		return
+	}
+	e := q.pending[0]
+	q.pending = q.pending[1:]
+	q.start(e, true)
+}
+
+// abandon takes a cancelled job out of the queue and runs it without a
+// worker slot, so it can finish as cancelled without waiting its turn.
+func (q *Queue) abandon(e *entry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, pending := range q.pending {
+		if pending == e {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			q.start(e, false)
+			return
+		}
+	}
+}
+
+// Cancel cancels a waiting job, which then runs at once with its cancelled
+// context. It reports whether a job with that ID was waiting.
+func (q *Queue) Cancel(id string) bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	var found *entry
+	for _, e := range q.pending {
+		if e.job.ID == id {
+			found = e
+			break
+		}
+	}
+	q.mu.Unlock()
+	if found == nil {
+		return false
+	}
+	found.cancel()
+	return true
+}
+
+// Position returns the 1-based place of a waiting job, or 0 when no job
+// with that ID is waiting.
+func (q *Queue) Position(id string) int {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, e := range q.pending {
+		if e.job.ID == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Shutdown stops accepting jobs and waits for the running and waiting ones
+// to finish. When ctx ends first, the remaining jobs are cancelled and
+// Shutdown waits for them to return.
+func (q *Queue) Shutdown(ctx context.Context) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+	}
+	q.cancel()
+}
@@ -0,0 +1,208 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blocker is a job body that runs until released.
+type blocker struct {
+	started chan string
+	release chan struct{}
+}
+
+func newBlocker() *blocker {
+	return &blocker{started: make(chan string, 16), release: make(chan struct{})}
+}
+
+func (b *blocker) job(id string, priority Priority) Job {
+	return Job{ID: id, Priority: priority, Run: func(ctx context.Context) {
+		b.started <- id
+		select {
+		case <-b.release:
+		case <-ctx.Done():
+		}
+	}}
+}
+
+func waitStarted(t *testing.T, b *blocker) string {
+	t.Helper()
+	select {
+	case id := <-b.started:
+		return id
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a job to start")
+		return ""
+	}
+}
+
+func TestQueueRunsByPriority(t *testing.T) {
+	t.Parallel()
+
+	q := New(1, -1)
+	b := newBlocker()
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(id string, priority Priority) Job {
+		return Job{ID: id, Priority: priority, Run: func(context.Context) {
+			mu.Lock()
+			order = append(order, id)
+			mu.Unlock()
+		}}
+	}
+
+	ctx := context.Background()
+	if err := q.Submit(ctx, b.job("busy", PriorityNormal)); err != nil {
+		t.Fatalf("Submit(busy) error = %v", err)
+	}
+	waitStarted(t, b)
+	for _, job := range []Job{
+		record("low", PriorityLow),
+		record("normal-1", PriorityNormal),
+		record("high", PriorityHigh),
+		record("normal-2", PriorityNormal),
+	} {
+		if err := q.Submit(ctx, job); err != nil {
+			t.Fatalf("Submit(%s) error = %v", job.ID, err)
+		}
+	}
+	if got := q.Position("high"); got != 1 {
+		t.Fatalf("Position(high) = %d, want 1", got)
+	}
+	if got := q.Position("low"); got != 4 {
+		t.Fatalf("Position(low) = %d, want 4", got)
+	}
+	if got := q.Position("busy"); got != 0 {
+		t.Fatalf("Position(busy) = %d, want 0 for a running job", got)
+	}
+
+	close(b.release)
+	q.Shutdown(ctx)
+	want := []string{"high", "normal-1", "normal-2", "low"}
+	if !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
+
+func TestQueueLimits(t *testing.T) {
+	t.Parallel()
+
+	q := New(1, 1)
+	b := newBlocker()
+	ctx := context.Background()
+	if err := q.Submit(ctx, b.job("a", PriorityNormal)); err != nil {
+		t.Fatalf("Submit(a) error = %v", err)
+	}
+	waitStarted(t, b)
+	if err := q.Submit(ctx, b.job("b", PriorityNormal)); err != nil {
+		t.Fatalf("Submit(b) error = %v", err)
+	}
+	if err := q.Submit(ctx, b.job("c", PriorityNormal)); !errors.Is(err, ErrFull) {
+		t.Fatalf("Submit(c) error = %v, want ErrFull", err)
+	}
+
+	r, err := New(1, 0).Reserve()
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	r.Release()
+
+	close(b.release)
+	q.Shutdown(ctx)
+	if err := q.Submit(ctx, b.job("d", PriorityNormal)); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Submit after Shutdown error = %v, want ErrClosed", err)
+	}
+}
+
+func TestQueueCancelledWaitingJobRunsImmediately(t *testing.T) {
+	t.Parallel()
+
+	q := New(1, -1)
+	b := newBlocker()
+	if err := q.Submit(context.Background(), b.job("busy", PriorityNormal)); err != nil {
+		t.Fatalf("Submit(busy) error = %v", err)
+	}
+	waitStarted(t, b)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan error, 1)
+	if err := q.Submit(ctx, Job{ID: "waiting", Run: func(ctx context.Context) { ran <- ctx.Err() }}); err != nil {
+		t.Fatalf("Submit(waiting) error = %v", err)
+	}
+	cancel()
+	select {
+	case err := <-ran:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("job ctx error = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("cancelled job did not run while the worker was busy")
+	}
+	if got := q.Position("waiting"); got != 0 {
+		t.Fatalf("Position(waiting) = %d, want 0", got)
+	}
+	close(b.release)
+	q.Shutdown(context.Background())
+}
+
+func TestQueueShutdownDrainsThenCancels(t *testing.T) {
+	t.Parallel()
+
+	q := New(1, -1)
+	quick := make(chan struct{})
+	if err := q.Submit(context.Background(), Job{ID: "quick", Run: func(context.Context) {
+		time.Sleep(20 * time.Millisecond)
+		close(quick)
+	}}); err != nil {
+		t.Fatalf("Submit(quick) error = %v", err)
+	}
+	stuck := make(chan error, 1)
+	if err := q.Submit(context.Background(), Job{ID: "stuck", Run: func(ctx context.Context) {
+		<-ctx.Done()
+		stuck <- ctx.Err()
+	}}); err != nil {
+		t.Fatalf("Submit(stuck) error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	q.Shutdown(ctx)
+
+	select {
+	case <-quick:
+	default:
+		t.Fatal("Shutdown returned before the quick job finished")
+	}
+	select {
+	case err := <-stuck:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("stuck job ctx error = %v, want context.Canceled", err)
+		}
+	default:
+		t.Fatal("Shutdown returned before the stuck job was cancelled")
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	t.Parallel()
+
+	for raw, want := range map[string]Priority{"": PriorityNormal, "LOW": PriorityLow, "high": PriorityHigh} {
+		got, err := ParsePriority(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParsePriority(%q) = %v, %v; want %v", raw, got, err, want)
+		}
+		if raw != "" && got.String() != strings.ToLower(raw) {
+			t.Fatalf("String() = %q, want %q", got.String(), strings.ToLower(raw))
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Fatal("ParsePriority(urgent) error = nil")
+	}
+}
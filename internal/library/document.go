@@ -0,0 +1,192 @@
+package library
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/opus-domini/sentinel/internal/inventory"
+	"github.com/opus-domini/sentinel/internal/runbook"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+// Schedule types a library file may declare.
+const (
+	scheduleTypeCron     = "cron"
+	scheduleTypeInterval = "interval"
+)
+
+// document is one library file: a runbook and the schedules that run it.
+// Keys match the JSON of the runbook and schedule APIs.
+type document struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Enabled     *bool                    `json:"enabled"`
+	WebhookURL  string                   `json:"webhookURL"`
+	Parameters  []store.RunbookParameter `json:"parameters"`
+	Steps       []store.OpsRunbookStep   `json:"steps"`
+	Schedules   []scheduleDocument       `json:"schedules"`
+}
+
+type scheduleDocument struct {
+	Name              string `json:"name"`
+	ScheduleType      string `json:"scheduleType"`
+	CronExpr          string `json:"cronExpr"`
+	Timezone          string `json:"timezone"`
+	Interval          string `json:"interval"`
+	Jitter            string `json:"jitter"`
+	ConcurrencyPolicy string `json:"concurrencyPolicy"`
+	Hosts             string `json:"hosts"`
+	Enabled           *bool  `json:"enabled"`
+}
+
+// definition is a parsed library file as the runbook and schedules it
+// stores.
+type definition struct {
+	file      string
+	runbook   store.OpsRunbookWrite
+	schedules []store.OpsScheduleWrite
+}
+
+// RunbookID returns the id of the runbook loaded from file, a path relative
+// to the library directory with forward slashes.
+func RunbookID(file string) string {
+	return hashID(file)
+}
+
+func scheduleID(file, name string) string {
+	return hashID(file + "\x00" + name)
+}
+
+func hashID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return idPrefix + hex.EncodeToString(sum[:])[:32]
+}
+
+// parseDefinition decodes and validates the YAML of file. Unknown keys are
+// rejected so that a typo does not silently drop a setting.
+func parseDefinition(file string, data []byte) (definition, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var raw any
+	if err := decoder.Decode(&raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			return definition{}, errors.New("file is empty")
+		}
+		return definition{}, err
+	}
+	var extra any
+	if err := decoder.Decode(&extra); !errors.Is(err, io.EOF) {
+		return definition{}, errors.New("file must hold a single YAML document")
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return definition{}, err
+	}
+	var doc document
+	jsonDecoder := json.NewDecoder(bytes.NewReader(encoded))
+	jsonDecoder.DisallowUnknownFields()
+	if err := jsonDecoder.Decode(&doc); err != nil {
+		return definition{}, err
+	}
+
+	def := definition{
+		file: file,
+		runbook: store.OpsRunbookWrite{
+			ID:          RunbookID(file),
+			Name:        strings.TrimSpace(doc.Name),
+			Description: strings.TrimSpace(doc.Description),
+			Steps:       doc.Steps,
+			Parameters:  doc.Parameters,
+			Enabled:     doc.Enabled == nil || *doc.Enabled,
+			WebhookURL:  strings.TrimSpace(doc.WebhookURL),
+			ManagedBy:   file,
+		},
+	}
+	if err := runbook.ValidateDefinition(def.runbook); err != nil {
+		return definition{}, err
+	}
+
+	names := make(map[string]struct{}, len(doc.Schedules))
+	for i, sched := range doc.Schedules {
+		write, err := scheduleWrite(file, def.runbook.ID, sched)
+		if err != nil {
+			return definition{}, fmt.Errorf("schedules[%d]: %w", i, err)
+		}
+		if _, dup := names[write.Name]; dup {
+			return definition{}, fmt.Errorf("schedules[%d]: name %q is used by another schedule", i, write.Name)
+		}
+		names[write.Name] = struct{}{}
+		def.schedules = append(def.schedules, write)
+	}
+	return def, nil
+}
+
+// scheduleWrite validates a schedule of a library file. The type may be
+// left out when only one of cronExpr and interval is set. One-off schedules
+// are not supported, since they would run again whenever they are reloaded.
+func scheduleWrite(file, runbookID string, doc scheduleDocument) (store.OpsScheduleWrite, error) {
+	write := store.OpsScheduleWrite{
+		RunbookID:    runbookID,
+		Name:         strings.TrimSpace(doc.Name),
+		ScheduleType: strings.TrimSpace(doc.ScheduleType),
+		CronExpr:     strings.TrimSpace(doc.CronExpr),
+		Timezone:     strings.TrimSpace(doc.Timezone),
+		Interval:     strings.TrimSpace(doc.Interval),
+		Jitter:       strings.TrimSpace(doc.Jitter),
+		Concurrency:  strings.TrimSpace(doc.ConcurrencyPolicy),
+		Hosts:        strings.TrimSpace(doc.Hosts),
+		Enabled:      doc.Enabled == nil || *doc.Enabled,
+		ManagedBy:    file,
+	}
+	if write.Name == "" {
+		return write, errors.New("name is required")
+	}
+	write.ID = scheduleID(file, write.Name)
+	if write.ScheduleType == "" {
+		switch {
+		case write.CronExpr != "" && write.Interval == "":
+			write.ScheduleType = scheduleTypeCron
+		case write.Interval != "" && write.CronExpr == "":
+			write.ScheduleType = scheduleTypeInterval
+		}
+	}
+	switch write.ScheduleType {
+	case scheduleTypeCron:
+		if err := validate.CronExpression(write.CronExpr); err != nil {
+			return write, err
+		}
+		if write.Timezone == "" {
+			write.Timezone = defaultTimezone
+		}
+		if err := validate.Timezone(write.Timezone); err != nil {
+			return write, err
+		}
+	case scheduleTypeInterval:
+		if _, err := validate.ParseInterval(write.Interval, write.Jitter); err != nil {
+			return write, err
+		}
+	default:
+		return write, errors.New(`scheduleType must be "cron" or "interval"`)
+	}
+	switch write.Concurrency {
+	case "":
+		write.Concurrency = store.ScheduleConcurrencyForbid
+	case store.ScheduleConcurrencyForbid, store.ScheduleConcurrencyAllow, store.ScheduleConcurrencyReplace:
+	default:
+		return write, errors.New(`concurrencyPolicy must be "forbid", "allow" or "replace"`)
+	}
+	if write.Hosts != "" {
+		if _, err := inventory.ParseSelector(write.Hosts); err != nil {
+			return write, err
+		}
+	}
+	return write, nil
+}
@@ -0,0 +1,107 @@
+package library
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitTimeout bounds each git command of a sync.
+const gitTimeout = 2 * time.Minute
+
+// pull clones gitURL into dir, or fetches branch, the remote default when
+// empty, into an existing clone and checks it out. It returns the commit
+// now checked out. Local changes to the checkout are discarded.
+func pull(ctx context.Context, dir, gitURL, branch string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(dir), 0o750); err != nil {
+			return "", err
+		}
+		args := []string{"clone", "--quiet", "--depth", "1"}
+		if branch != "" {
+			args = append(args, "--branch", branch)
+		}
+		if _, err := git(ctx, "", append(args, "--", gitURL, dir)...); err != nil {
+			return "", err
+		}
+		return checkoutRevision(ctx, dir)
+	}
+
+	ref := "HEAD"
+	if branch != "" {
+		ref = branch
+	}
+	if _, err := git(ctx, dir, "remote", "set-url", "origin", gitURL); err != nil {
+		return "", err
+	}
+	if _, err := git(ctx, dir, "fetch", "--quiet", "--depth", "1", "origin", ref); err != nil {
+		return "", err
+	}
+	if _, err := git(ctx, dir, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	return checkoutRevision(ctx, dir)
+}
+
+// checkoutRevision returns the commit checked out in dir.
+func checkoutRevision(ctx context.Context, dir string) (string, error) {
+	out, err := git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// git runs a git command in dir without prompting for credentials and
+// returns its output. Errors carry git's message with any credentials in
+// URLs removed.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+	subcommand := args[0]
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", subcommand, redactText(msg))
+	}
+	return stdout.String(), nil
+}
+
+// redactURL removes the credentials from a repository URL.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	u.User = nil
+	return u.String()
+}
+
+// redactText removes the credentials from the URLs in git's messages.
+func redactText(text string) string {
+	fields := strings.Fields(text)
+	for _, field := range fields {
+		trimmed := strings.Trim(field, `'".,:`)
+		if redacted := redactURL(trimmed); redacted != trimmed {
+			text = strings.ReplaceAll(text, trimmed, redacted)
+		}
+	}
+	return text
+}
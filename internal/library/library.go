@@ -0,0 +1,410 @@
+// Package library loads runbooks and their schedules from a directory of
+// YAML files, optionally a Git checkout, and keeps the store in step with
+// it: runbooks of new files are created, edited files update theirs and
+// removed files delete theirs. Runbooks and schedules loaded this way are
+// read-only in the API and UI.
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/runbook"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/validate"
+)
+
+const (
+	// idPrefix starts the ids of library runbooks and schedules.
+	idPrefix        = "lib-"
+	defaultTimezone = "UTC"
+	// maxFileSize bounds a library file.
+	maxFileSize = 1 << 20
+)
+
+// ErrDisabled is returned by a nil Service.
+var ErrDisabled = errors.New("library is not configured")
+
+// Repo stores the runbooks and schedules loaded from the library.
+type Repo interface {
+	ListOpsRunbooks(ctx context.Context) ([]store.OpsRunbook, error)
+	InsertOpsRunbook(ctx context.Context, w store.OpsRunbookWrite) (store.OpsRunbook, error)
+	UpdateOpsRunbook(ctx context.Context, w store.OpsRunbookWrite) (store.OpsRunbook, error)
+	DeleteOpsRunbook(ctx context.Context, id, expectedName string) (store.OpsRunbookDeleteResult, error)
+	ListOpsSchedules(ctx context.Context) ([]store.OpsSchedule, error)
+	InsertOpsSchedule(ctx context.Context, w store.OpsScheduleWrite) (store.OpsSchedule, error)
+	UpdateOpsSchedule(ctx context.Context, w store.OpsScheduleWrite) (store.OpsSchedule, error)
+	DeleteOpsSchedule(ctx context.Context, id string) error
+}
+
+// Options configure a Service.
+type Options struct {
+	// Dir holds the library files, or the Git checkout when GitURL is set.
+	Dir       string
+	GitURL    string
+	GitBranch string
+	// UserPolicy decides which users the steps of library runbooks may run
+	// as. A file breaking it fails to load, so a push cannot bring in a
+	// step running as a user the API would refuse.
+	UserPolicy runbook.UserPolicy
+}
+
+// FileError is a library file that could not be loaded or applied.
+type FileError struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// Result describes one sync of the library.
+type Result struct {
+	SyncedAt string `json:"syncedAt"`
+	// Revision is the commit of the Git checkout that was loaded.
+	Revision string      `json:"revision,omitempty"`
+	Files    int         `json:"files"`
+	Created  int         `json:"created"`
+	Updated  int         `json:"updated"`
+	Deleted  int         `json:"deleted"`
+	Errors   []FileError `json:"errors"`
+	// GitError is set when the checkout could not be updated and the files
+	// already on disk were loaded instead.
+	GitError string `json:"gitError,omitempty"`
+	// Error is set when the library could not be loaded at all; nothing was
+	// changed.
+	Error string `json:"error,omitempty"`
+}
+
+// Changed reports whether the sync created, updated or deleted anything.
+func (r Result) Changed() bool {
+	return r.Created+r.Updated+r.Deleted > 0
+}
+
+// Status is the library configuration and the result of its last sync.
+type Status struct {
+	Dir       string  `json:"dir"`
+	GitURL    string  `json:"gitUrl,omitempty"`
+	GitBranch string  `json:"gitBranch,omitempty"`
+	Last      *Result `json:"last"`
+}
+
+// Service syncs the library into the store.
+type Service struct {
+	repo  Repo
+	opts  Options
+	nowFn func() time.Time
+
+	// syncMu serialises syncs; mu guards last.
+	syncMu sync.Mutex
+	mu     sync.Mutex
+	last   *Result
+}
+
+// New returns a Service loading the library described by opts into repo.
+func New(repo Repo, opts Options) *Service {
+	return &Service{repo: repo, opts: opts, nowFn: time.Now}
+}
+
+// Status returns the configuration and the last sync result.
+func (s *Service) Status() (Status, error) {
+	if s == nil {
+		return Status{}, ErrDisabled
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := Status{Dir: s.opts.Dir, GitURL: redactURL(s.opts.GitURL), GitBranch: s.opts.GitBranch}
+	if s.last != nil {
+		last := *s.last
+		status.Last = &last
+	}
+	return status, nil
+}
+
+// Sync pulls the Git checkout when configured, loads every *.yaml and *.yml
+// file under the library directory and applies them to the store. A file
+// that fails to load is reported and leaves its runbook as it was. The
+// returned error means the library could not be read and nothing changed.
+func (s *Service) Sync(ctx context.Context) (Result, error) {
+	if s == nil {
+		return Result{}, ErrDisabled
+	}
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	result := Result{SyncedAt: s.nowFn().UTC().Format(time.RFC3339), Errors: []FileError{}}
+	err := s.sync(ctx, &result)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	s.mu.Lock()
+	s.last = &result
+	s.mu.Unlock()
+	return result, err
+}
+
+func (s *Service) sync(ctx context.Context, result *Result) error {
+	if s.opts.GitURL != "" {
+		revision, err := pull(ctx, s.opts.Dir, s.opts.GitURL, s.opts.GitBranch)
+		if err != nil {
+			result.GitError = err.Error()
+			if revision, err = checkoutRevision(ctx, s.opts.Dir); err != nil {
+				return errors.New("library checkout is unavailable: " + result.GitError)
+			}
+		}
+		result.Revision = revision
+	}
+
+	defs, failed, err := load(s.opts.Dir)
+	if err != nil {
+		return err
+	}
+	defs, failed = checkUsers(defs, failed, s.opts.UserPolicy)
+	result.Files = len(defs) + len(failed)
+	result.Errors = append(result.Errors, failed...)
+	return s.apply(ctx, defs, failed, result)
+}
+
+// load parses the library files under dir. Hidden directories, such as
+// .git, are skipped.
+func load(dir string) ([]definition, []FileError, error) {
+	var (
+		defs   []definition
+		failed []FileError
+	)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		file := filepath.ToSlash(rel)
+		def, err := readDefinition(path, file)
+		if err != nil {
+			failed = append(failed, FileError{File: file, Error: err.Error()})
+			return nil
+		}
+		defs = append(defs, def)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("read library: %w", err)
+	}
+	return defs, failed, nil
+}
+
+// checkUsers moves the definitions whose steps run as a user policy
+// refuses to the failed files.
+func checkUsers(defs []definition, failed []FileError, policy runbook.UserPolicy) ([]definition, []FileError) {
+	kept := defs[:0]
+	for _, def := range defs {
+		if err := runbook.CheckUsers(def.runbook.Steps, policy); err != nil {
+			failed = append(failed, FileError{File: def.file, Error: err.Error()})
+			continue
+		}
+		kept = append(kept, def)
+	}
+	return kept, failed
+}
+
+func readDefinition(path, file string) (definition, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return definition{}, err
+	}
+	if info.Size() > maxFileSize {
+		return definition{}, fmt.Errorf("file is larger than %d bytes", maxFileSize)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // library files are read from the configured directory.
+	if err != nil {
+		return definition{}, err
+	}
+	return parseDefinition(file, data)
+}
+
+// apply creates, updates and deletes the library runbooks and schedules in
+// the store. Runbooks of files that failed to load are kept.
+func (s *Service) apply(ctx context.Context, defs []definition, failed []FileError, result *Result) error {
+	runbooks, err := s.repo.ListOpsRunbooks(ctx)
+	if err != nil {
+		return err
+	}
+	schedules, err := s.repo.ListOpsSchedules(ctx)
+	if err != nil {
+		return err
+	}
+	existingRunbooks := make(map[string]store.OpsRunbook, len(runbooks))
+	for _, rb := range runbooks {
+		existingRunbooks[rb.ID] = rb
+	}
+	existingSchedules := make(map[string][]store.OpsSchedule)
+	for _, sched := range schedules {
+		if sched.ManagedBy != "" {
+			existingSchedules[sched.ManagedBy] = append(existingSchedules[sched.ManagedBy], sched)
+		}
+	}
+
+	keep := make(map[string]struct{}, len(defs)+len(failed))
+	for _, fe := range failed {
+		keep[fe.File] = struct{}{}
+	}
+	for _, def := range defs {
+		keep[def.file] = struct{}{}
+		if err := s.applyDefinition(ctx, def, existingRunbooks, existingSchedules[def.file], result); err != nil {
+			result.Errors = append(result.Errors, FileError{File: def.file, Error: err.Error()})
+		}
+	}
+
+	for _, rb := range runbooks {
+		if rb.ManagedBy == "" {
+			continue
+		}
+		if _, ok := keep[rb.ManagedBy]; ok {
+			continue
+		}
+		if _, err := s.repo.DeleteOpsRunbook(ctx, rb.ID, ""); err != nil {
+			if errors.Is(err, store.ErrOpsRunbookActive) {
+				err = errors.New("runbook is running; it is removed on a later sync")
+			}
+			result.Errors = append(result.Errors, FileError{File: rb.ManagedBy, Error: err.Error()})
+			continue
+		}
+		result.Deleted++
+	}
+	return nil
+}
+
+// applyDefinition stores the runbook and schedules of one file. existing
+// holds the schedules the file loaded before.
+func (s *Service) applyDefinition(ctx context.Context, def definition, runbooks map[string]store.OpsRunbook, existing []store.OpsSchedule, result *Result) error {
+	current, ok := runbooks[def.runbook.ID]
+	switch {
+	case !ok:
+		if _, err := s.repo.InsertOpsRunbook(ctx, def.runbook); err != nil {
+			return err
+		}
+		result.Created++
+	case current.ManagedBy != def.file:
+		return fmt.Errorf("runbook id %s is used by %q", def.runbook.ID, current.ManagedBy)
+	case !sameRunbook(current, def.runbook):
+		if _, err := s.repo.UpdateOpsRunbook(ctx, def.runbook); err != nil {
+			return err
+		}
+		result.Updated++
+	}
+
+	byID := make(map[string]store.OpsSchedule, len(existing))
+	for _, sched := range existing {
+		byID[sched.ID] = sched
+	}
+	now := s.nowFn()
+	for _, write := range def.schedules {
+		current, ok := byID[write.ID]
+		delete(byID, write.ID)
+		if ok && sameSchedule(current, write) {
+			continue
+		}
+		if ok && sameTiming(current, write) && current.NextRunAt != "" {
+			write.NextRunAt = current.NextRunAt
+		} else {
+			next, err := nextRunAt(write, now)
+			if err != nil {
+				return fmt.Errorf("schedule %q: %w", write.Name, err)
+			}
+			write.NextRunAt = next
+		}
+		if !ok {
+			if _, err := s.repo.InsertOpsSchedule(ctx, write); err != nil {
+				return fmt.Errorf("schedule %q: %w", write.Name, err)
+			}
+			result.Created++
+			continue
+		}
+		if _, err := s.repo.UpdateOpsSchedule(ctx, write); err != nil {
+			return fmt.Errorf("schedule %q: %w", write.Name, err)
+		}
+		result.Updated++
+	}
+	for _, sched := range byID {
+		if err := s.repo.DeleteOpsSchedule(ctx, sched.ID); err != nil {
+			return fmt.Errorf("schedule %q: %w", sched.Name, err)
+		}
+		result.Deleted++
+	}
+	return nil
+}
+
+func sameRunbook(current store.OpsRunbook, write store.OpsRunbookWrite) bool {
+	if current.Name != write.Name || current.Description != write.Description ||
+		current.Enabled != write.Enabled || current.WebhookURL != write.WebhookURL {
+		return false
+	}
+	return sameJSON(current.Steps, write.Steps) && sameJSON(current.Parameters, write.Parameters)
+}
+
+// sameJSON compares values as they are stored, so that nil and empty
+// slices are equal.
+func sameJSON[T any](a, b []T) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return slices.Equal(left, right)
+}
+
+func sameSchedule(current store.OpsSchedule, write store.OpsScheduleWrite) bool {
+	return sameTiming(current, write) && current.Name == write.Name &&
+		current.RunbookID == write.RunbookID && current.Concurrency == write.Concurrency &&
+		current.Hosts == write.Hosts && current.Enabled == write.Enabled
+}
+
+// sameTiming reports whether the next run of a schedule still holds.
+func sameTiming(current store.OpsSchedule, write store.OpsScheduleWrite) bool {
+	return current.ScheduleType == write.ScheduleType && current.CronExpr == write.CronExpr &&
+		current.Timezone == write.Timezone && current.Interval == write.Interval &&
+		current.Jitter == write.Jitter && current.Enabled == write.Enabled
+}
+
+func nextRunAt(write store.OpsScheduleWrite, now time.Time) (string, error) {
+	if write.ScheduleType == scheduleTypeInterval {
+		sched, err := validate.ParseInterval(write.Interval, write.Jitter)
+		if err != nil {
+			return "", err
+		}
+		return sched.Next(now.UTC()).Format(time.RFC3339), nil
+	}
+	loc, err := time.LoadLocation(write.Timezone)
+	if err != nil {
+		return "", err
+	}
+	sched, err := validate.ParseCron(write.CronExpr)
+	if err != nil {
+		return "", err
+	}
+	return sched.Next(now.In(loc)).UTC().Format(time.RFC3339), nil
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// File is a log file that rotates once it reaches a size limit. Rotated
+// files are renamed path.1, path.2, … with path.1 the most recent, and
+// the oldest beyond the backup limit are removed.
+type File struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenFile opens path for appending, creating its directory. A maxBytes of
+// zero disables rotation.
+func OpenFile(path string, maxBytes int64, maxBackups int) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create log dir: %w", err)
+	}
+	f := &File{path: path, maxBytes: maxBytes, maxBackups: max(maxBackups, 1)}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p, rotating first when p would take the file past the
+// limit. A single record is never split across files.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxBytes > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // configured daemon log path.
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	f.file = nil
+	_ = os.Remove(f.backup(f.maxBackups))
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(f.backup(i), f.backup(i+1))
+	}
+	if err := os.Rename(f.path, f.backup(1)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	return f.open()
+}
+
+func (f *File) backup(n int) string {
+	return f.path + "." + strconv.Itoa(n)
+}
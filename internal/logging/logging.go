@@ -0,0 +1,120 @@
+// Package logging configures Sentinel's slog output: text or JSON records,
+// an optional size-rotated log file, and request attributes carried by the
+// context so every line logged while serving a request can be correlated.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
+
+// Record formats accepted by NewHandler.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// requestInfo is shared by every context derived from a request, so an
+// actor recorded by an inner handler also reaches the access log line.
+type requestInfo struct {
+	id    string
+	actor atomic.Pointer[string]
+}
+
+type requestContextKey struct{}
+
+// WithRequestID returns a context carrying id. Records logged with it, or
+// with any context derived from it, include a request_id attribute.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, &requestInfo{id: id})
+}
+
+// RequestID returns the ID stored by WithRequestID, or "".
+func RequestID(ctx context.Context) string {
+	if info := requestFromContext(ctx); info != nil {
+		return info.id
+	}
+	return ""
+}
+
+// SetActor records who made the request carried by ctx. It has no effect
+// on a context without a request ID.
+func SetActor(ctx context.Context, actor string) {
+	if info := requestFromContext(ctx); info != nil && actor != "" {
+		info.actor.Store(&actor)
+	}
+}
+
+// Actor returns the request's actor: the one recorded by SetActor, else the
+// authenticated identity in ctx, else "".
+func Actor(ctx context.Context) string {
+	if info := requestFromContext(ctx); info != nil {
+		if actor := info.actor.Load(); actor != nil {
+			return *actor
+		}
+	}
+	if id, ok := security.IdentityFromContext(ctx); ok {
+		return id.Name
+	}
+	return ""
+}
+
+func requestFromContext(ctx context.Context) *requestInfo {
+	if ctx == nil {
+		return nil
+	}
+	info, _ := ctx.Value(requestContextKey{}).(*requestInfo)
+	return info
+}
+
+// ParseLevel maps a configured level name to a slog level. Unknown names
+// mean info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// NewHandler returns a handler writing text or JSON records to w. Records
+// logged with a request context gain request_id and actor attributes.
+func NewHandler(w io.Writer, format string, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == FormatJSON {
+		return contextHandler{slog.NewJSONHandler(w, opts)}
+	}
+	return contextHandler{slog.NewTextHandler(w, opts)}
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if actor := Actor(ctx); actor != "" {
+		record.AddAttrs(slog.String("actor", actor))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
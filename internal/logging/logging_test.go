@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/security"
+)
+
+func TestJSONHandlerAddsRequestAttributes(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, FormatJSON, slog.LevelInfo))
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	inner := security.WithIdentity(ctx, security.Identity{Name: "deploy-bot", Role: security.RoleOperator})
+	logger.InfoContext(inner, "handled", "code", 200)
+	SetActor(inner, "deploy-bot")
+	logger.InfoContext(ctx, "request")
+	logger.DebugContext(ctx, "dropped")
+	logger.Info("background")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []map[string]any{
+		{"msg": "handled", "request_id": "req-1", "actor": "deploy-bot", "code": float64(200)},
+		{"msg": "request", "request_id": "req-1", "actor": "deploy-bot"},
+		{"msg": "background", "request_id": nil, "actor": nil},
+	} {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(lines[i]), &record); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i, err)
+		}
+		for key, value := range want {
+			if record[key] != value {
+				t.Errorf("line %d %s = %v, want %v", i, key, record[key], value)
+			}
+		}
+	}
+}
+
+func TestTextHandlerAddsRequestID(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, FormatText, slog.LevelInfo)).With("component", "api")
+	logger.InfoContext(WithRequestID(context.Background(), "abc"), "hello")
+
+	if out := buf.String(); !strings.Contains(out, "component=api") || !strings.Contains(out, "request_id=abc") {
+		t.Fatalf("output = %q", out)
+	}
+}
+
+func TestFileRotates(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "logs", "sentinel.log")
+	f, err := OpenFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	for name, want := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(name), got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 exists, want only 2 backups", filepath.Base(path))
+	}
+}
@@ -49,7 +49,7 @@ type runbookGetOutput struct {
 type runbookCreateInput struct {
 	Name        string                   `json:"name" jsonschema:"runbook name"`
 	Description string                   `json:"description,omitempty" jsonschema:"purpose and operational context"`
-	Steps       []store.OpsRunbookStep   `json:"steps" jsonschema:"ordered run, script, or approval steps"`
+	Steps       []store.OpsRunbookStep   `json:"steps" jsonschema:"ordered run, script, approval, http, tmux.send, tmux.exec, wait, or service steps"`
 	Parameters  []store.RunbookParameter `json:"parameters,omitempty" jsonschema:"typed parameters accepted by this runbook"`
 	Enabled     *bool                    `json:"enabled,omitempty" jsonschema:"whether the runbook can be executed; defaults to true"`
 	WebhookURL  string                   `json:"webhookURL,omitempty" jsonschema:"optional HTTP or HTTPS completion webhook"`
@@ -70,6 +70,7 @@ type runbookDeleteOutput struct {
 	RunbookID        string `json:"runbookId"`
 	Name             string `json:"name"`
 	DeletedSchedules int64  `json:"deletedSchedules"`
+	DeletedWebhooks  int64  `json:"deletedWebhooks"`
 }
 
 type runbookRunInput struct {
@@ -244,6 +245,7 @@ func (t *tools) deleteRunbook(ctx context.Context, _ *mcp.CallToolRequest, input
 		RunbookID:        deleted.ID,
 		Name:             deleted.Name,
 		DeletedSchedules: deleted.DeletedSchedules,
+		DeletedWebhooks:  deleted.DeletedWebhooks,
 	}, nil
 }
 
@@ -426,6 +428,8 @@ func runbookToolError(action string, err error) error {
 		return errors.New("delete runbook: confirmName does not exactly match the persisted runbook name")
 	case errors.Is(err, store.ErrOpsRunbookActive):
 		return errors.New("delete runbook: runbook has a queued, running, or waiting-for-approval execution")
+	case errors.Is(err, runbook.ErrManagedByFile):
+		return fmt.Errorf("%s: %w; change its library file instead", action, err)
 	default:
 		return toolError(action, err)
 	}
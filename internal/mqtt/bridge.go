@@ -0,0 +1,116 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+)
+
+const (
+	bridgeBuffer         = 256
+	bridgePublishTimeout = 10 * time.Second
+	bridgeMinBackoff     = time.Second
+	bridgeMaxBackoff     = time.Minute
+)
+
+// BridgeOptions configure which events a bridge publishes and how.
+type BridgeOptions struct {
+	Client Options
+	// TopicPrefix is prepended to the event type with dots turned into
+	// slashes: ops.services.updated goes to <prefix>/ops/services/updated.
+	TopicPrefix string
+	QoS         byte
+	Retain      bool
+	// Events are the event types to publish.
+	Events []string
+}
+
+// Bridge forwards hub events to a broker, reconnecting with backoff when
+// the connection drops. Events published while disconnected are dropped
+// once the subscription buffer fills.
+type Bridge struct {
+	hub  *events.Hub
+	opts BridgeOptions
+}
+
+// NewBridge returns a bridge from hub to the broker in opts.
+func NewBridge(hub *events.Hub, opts BridgeOptions) *Bridge {
+	opts.TopicPrefix = strings.Trim(opts.TopicPrefix, "/")
+	return &Bridge{hub: hub, opts: opts}
+}
+
+// Topic returns the topic an event type is published to.
+func (b *Bridge) Topic(eventType string) string {
+	topic := strings.ReplaceAll(eventType, ".", "/")
+	if b.opts.TopicPrefix == "" {
+		return topic
+	}
+	return b.opts.TopicPrefix + "/" + topic
+}
+
+// Run publishes events until ctx is cancelled.
+func (b *Bridge) Run(ctx context.Context) {
+	ch, unsubscribe := b.hub.Subscribe(bridgeBuffer)
+	defer unsubscribe()
+
+	backoff := bridgeMinBackoff
+	for ctx.Err() == nil {
+		dialCtx, cancel := context.WithTimeout(ctx, bridgePublishTimeout)
+		client, err := Dial(dialCtx, b.opts.Client)
+		cancel()
+		if err != nil {
+			slog.Warn("mqtt connect failed", "broker", b.opts.Client.Broker, "retry", backoff, "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, bridgeMaxBackoff)
+			continue
+		}
+		backoff = bridgeMinBackoff
+		slog.Info("mqtt connected", "broker", b.opts.Client.Broker)
+
+		err = b.forward(ctx, client, ch)
+		_ = client.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("mqtt connection lost", "broker", b.opts.Client.Broker, "err", err)
+	}
+}
+
+// forward publishes events on client until the connection or ctx ends.
+func (b *Bridge) forward(ctx context.Context, client *Client, ch <-chan events.Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-client.Done():
+			return client.Err()
+		case event, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if !slices.Contains(b.opts.Events, event.Type) {
+				continue
+			}
+			payload, err := json.Marshal(event)
+			if err != nil {
+				slog.Warn("mqtt event not encoded", "type", event.Type, "err", err)
+				continue
+			}
+			pubCtx, cancel := context.WithTimeout(ctx, bridgePublishTimeout)
+			err = client.Publish(pubCtx, b.Topic(event.Type), payload, b.opts.QoS, b.opts.Retain)
+			cancel()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
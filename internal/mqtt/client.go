@@ -0,0 +1,396 @@
+// Package mqtt publishes Sentinel events to an MQTT broker. The client
+// implements the part of MQTT 3.1.1 a publisher needs: connect with
+// optional credentials and TLS, publish at QoS 0 or 1, and keep-alive.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Control packet types, already shifted into the high nibble.
+const (
+	packetConnect    = 0x10
+	packetConnack    = 0x20
+	packetPublish    = 0x30
+	packetPuback     = 0x40
+	packetPingreq    = 0xC0
+	packetPingresp   = 0xD0
+	packetDisconnect = 0xE0
+)
+
+// maxRemainingLength is the largest packet body MQTT can frame.
+const maxRemainingLength = 268_435_455
+
+var (
+	// ErrClosed is returned by Publish once the connection is gone.
+	ErrClosed = errors.New("mqtt connection closed")
+	// ErrInvalidBroker is returned for a broker URL that is not
+	// mqtt://, mqtts://, tcp://, ssl:// or tls:// with a host.
+	ErrInvalidBroker = errors.New("mqtt broker must be an mqtt:// or mqtts:// URL with a host")
+)
+
+// Options configure a connection.
+type Options struct {
+	// Broker is the broker URL. mqtt:// and tcp:// connect in plain text
+	// (default port 1883); mqtts://, ssl:// and tls:// use TLS (8883).
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// KeepAlive is the longest the connection stays silent; 0 means 60s.
+	KeepAlive time.Duration
+	// TLSConfig overrides the TLS settings of a TLS broker.
+	TLSConfig *tls.Config
+}
+
+// BrokerAddress parses a broker URL into a dial address and whether it
+// uses TLS.
+func BrokerAddress(broker string) (string, bool, error) {
+	parsed, err := url.Parse(broker)
+	if err != nil || parsed.Hostname() == "" {
+		return "", false, ErrInvalidBroker
+	}
+	var (
+		useTLS bool
+		port   = "1883"
+	)
+	switch parsed.Scheme {
+	case "mqtt", "tcp":
+	case "mqtts", "ssl", "tls":
+		useTLS, port = true, "8883"
+	default:
+		return "", false, ErrInvalidBroker
+	}
+	if parsed.Port() != "" {
+		port = parsed.Port()
+	}
+	return net.JoinHostPort(parsed.Hostname(), port), useTLS, nil
+}
+
+// Client is a connection to a broker. It is safe for concurrent use.
+type Client struct {
+	conn      net.Conn
+	keepAlive time.Duration
+
+	writeMu sync.Mutex
+
+	mu     sync.Mutex
+	nextID uint16
+	acks   map[uint16]chan struct{}
+	err    error
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Dial connects and waits for the broker to accept the session.
+func Dial(ctx context.Context, opts Options) (*Client, error) {
+	addr, useTLS, err := BrokerAddress(opts.Broker)
+	if err != nil {
+		return nil, err
+	}
+	keepAlive := opts.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = 60 * time.Second
+	}
+
+	var conn net.Conn
+	if useTLS {
+		cfg := opts.TLSConfig
+		if cfg == nil {
+			host, _, _ := net.SplitHostPort(addr)
+			cfg = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+		}
+		conn, err = (&tls.Dialer{Config: cfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dial mqtt broker: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	r := bufio.NewReader(conn)
+	if _, err := conn.Write(connectPacket(opts, keepAlive)); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("send mqtt connect: %w", err)
+	}
+	header, body, err := readPacket(r)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("read mqtt connack: %w", err)
+	}
+	if header&0xF0 != packetConnack || len(body) != 2 {
+		_ = conn.Close()
+		return nil, errors.New("mqtt broker did not acknowledge the connection")
+	}
+	if code := body[1]; code != 0 {
+		_ = conn.Close()
+		return nil, fmt.Errorf("mqtt broker refused the connection: %s", connackReason(code))
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	c := &Client{
+		conn:      conn,
+		keepAlive: keepAlive,
+		acks:      make(map[uint16]chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go c.readLoop(r)
+	go c.pingLoop()
+	return c, nil
+}
+
+// Done is closed when the connection ends.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the connection ended, or nil while it is up.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Publish sends payload to topic. At QoS 1 it waits for the broker's
+// acknowledgement until ctx ends.
+func (c *Client) Publish(ctx context.Context, topic string, payload []byte, qos byte, retain bool) error {
+	if qos > 1 {
+		return fmt.Errorf("mqtt qos %d is not supported", qos)
+	}
+	var (
+		id  uint16
+		ack chan struct{}
+	)
+	if qos == 1 {
+		c.mu.Lock()
+		if c.err != nil {
+			c.mu.Unlock()
+			return ErrClosed
+		}
+		for {
+			c.nextID++
+			if c.nextID != 0 && c.acks[c.nextID] == nil {
+				break
+			}
+		}
+		id, ack = c.nextID, make(chan struct{})
+		c.acks[id] = ack
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			delete(c.acks, id)
+			c.mu.Unlock()
+		}()
+	}
+
+	packet, err := publishPacket(topic, payload, qos, retain, id)
+	if err != nil {
+		return err
+	}
+	if err := c.write(packet); err != nil {
+		return err
+	}
+	if qos == 0 {
+		return nil
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-c.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close disconnects from the broker.
+func (c *Client) Close() error {
+	_ = c.write([]byte{packetDisconnect, 0})
+	c.shutdown(ErrClosed)
+	return nil
+}
+
+func (c *Client) write(packet []byte) error {
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.keepAlive))
+	if _, err := c.conn.Write(packet); err != nil {
+		c.shutdown(err)
+		return ErrClosed
+	}
+	return nil
+}
+
+func (c *Client) shutdown(err error) {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		_ = c.conn.Close()
+		close(c.done)
+	})
+}
+
+// readLoop handles acknowledgements. A broker that stays silent for one
+// and a half keep-alive periods, despite the pings, is treated as gone.
+func (c *Client) readLoop(r *bufio.Reader) {
+	for {
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		header, body, err := readPacket(r)
+		if err != nil {
+			c.shutdown(err)
+			return
+		}
+		if header&0xF0 == packetPuback && len(body) == 2 {
+			id := binary.BigEndian.Uint16(body)
+			c.mu.Lock()
+			if ack := c.acks[id]; ack != nil {
+				close(ack)
+				delete(c.acks, id)
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+func (c *Client) pingLoop() {
+	ticker := time.NewTicker(c.keepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if c.write([]byte{packetPingreq, 0}) != nil {
+				return
+			}
+		}
+	}
+}
+
+func connectPacket(opts Options, keepAlive time.Duration) []byte {
+	flags := byte(0x02) // clean session
+	if opts.Username != "" {
+		flags |= 0x80
+		if opts.Password != "" {
+			flags |= 0x40
+		}
+	}
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(min(keepAlive/time.Second, 0xFFFF)))
+	body = appendString(body, opts.ClientID)
+	if flags&0x80 != 0 {
+		body = appendString(body, opts.Username)
+	}
+	if flags&0x40 != 0 {
+		body = appendString(body, opts.Password)
+	}
+	return frame(packetConnect, body)
+}
+
+func publishPacket(topic string, payload []byte, qos byte, retain bool, id uint16) ([]byte, error) {
+	if topic == "" || len(topic) > 0xFFFF {
+		return nil, errors.New("mqtt topic must be 1-65535 bytes")
+	}
+	header := byte(packetPublish) | qos<<1
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(make([]byte, 0, len(topic)+len(payload)+4), topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	body = append(body, payload...)
+	if len(body) > maxRemainingLength {
+		return nil, errors.New("mqtt message is too large")
+	}
+	return frame(header, body), nil
+}
+
+func frame(header byte, body []byte) []byte {
+	packet := make([]byte, 0, len(body)+5)
+	packet = append(packet, header)
+	for n := len(body); ; {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if n == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readPacket reads one control packet and returns its first header byte
+// and body.
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7F) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, nil, errors.New("malformed mqtt packet length")
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func connackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "client identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad user name or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("return code %d", code)
+	}
+}
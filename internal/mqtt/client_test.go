@@ -0,0 +1,218 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+)
+
+type message struct {
+	topic   string
+	payload []byte
+	qos     byte
+	retain  bool
+}
+
+// fakeBroker accepts connections, answers CONNECT with returnCode and
+// records PUBLISH packets, acknowledging the QoS 1 ones.
+type fakeBroker struct {
+	ln         net.Listener
+	returnCode byte
+	connects   chan []byte
+	messages   chan message
+}
+
+func newFakeBroker(t *testing.T, returnCode byte) *fakeBroker {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	b := &fakeBroker{
+		ln:         ln,
+		returnCode: returnCode,
+		connects:   make(chan []byte, 4),
+		messages:   make(chan message, 16),
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) url() string {
+	return "mqtt://" + b.ln.Addr().String()
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch header & 0xF0 {
+		case packetConnect:
+			b.connects <- body
+			_, _ = conn.Write([]byte{packetConnack, 2, 0, b.returnCode})
+		case packetPublish:
+			qos := (header >> 1) & 0x03
+			n := int(binary.BigEndian.Uint16(body))
+			msg := message{topic: string(body[2 : 2+n]), qos: qos, retain: header&0x01 != 0}
+			rest := body[2+n:]
+			if qos > 0 {
+				_, _ = conn.Write([]byte{packetPuback, 2, rest[0], rest[1]})
+				rest = rest[2:]
+			}
+			msg.payload = rest
+			b.messages <- msg
+		case packetPingreq:
+			_, _ = conn.Write([]byte{packetPingresp, 0})
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) next(t *testing.T) message {
+	t.Helper()
+	select {
+	case msg := <-b.messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message reached the broker")
+		return message{}
+	}
+}
+
+func TestBrokerAddress(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		broker string
+		addr   string
+		tls    bool
+		ok     bool
+	}{
+		{"mqtt://broker.lan", "broker.lan:1883", false, true},
+		{"tcp://10.0.0.5:1884", "10.0.0.5:1884", false, true},
+		{"mqtts://broker.lan", "broker.lan:8883", true, true},
+		{"http://broker.lan", "", false, false},
+		{"mqtt://", "", false, false},
+	}
+	for _, tc := range cases {
+		addr, useTLS, err := BrokerAddress(tc.broker)
+		if (err == nil) != tc.ok || addr != tc.addr || useTLS != tc.tls {
+			t.Errorf("BrokerAddress(%q) = %q, %t, %v", tc.broker, addr, useTLS, err)
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+
+	broker := newFakeBroker(t, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := Dial(ctx, Options{Broker: broker.url(), ClientID: "sentinel", Username: "ha", Password: "pw"})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	connect := <-broker.connects
+	if !strings.Contains(string(connect), "sentinel") || !strings.Contains(string(connect), "ha") || connect[7]&0xC0 != 0xC0 {
+		t.Fatalf("CONNECT body = %q, want client ID and credentials", connect)
+	}
+
+	if err := client.Publish(ctx, "sentinel/test", []byte("at-least-once"), 1, true); err != nil {
+		t.Fatalf("Publish qos 1: %v", err)
+	}
+	msg := broker.next(t)
+	if msg.topic != "sentinel/test" || string(msg.payload) != "at-least-once" || msg.qos != 1 || !msg.retain {
+		t.Fatalf("message = %+v", msg)
+	}
+
+	if err := client.Publish(ctx, "sentinel/test", []byte("at-most-once"), 0, false); err != nil {
+		t.Fatalf("Publish qos 0: %v", err)
+	}
+	if msg := broker.next(t); string(msg.payload) != "at-most-once" || msg.qos != 0 {
+		t.Fatalf("message = %+v", msg)
+	}
+
+	if err := client.Publish(ctx, "sentinel/test", nil, 2, false); err == nil {
+		t.Fatal("Publish qos 2 succeeded, want an error")
+	}
+}
+
+func TestDialRefused(t *testing.T) {
+	t.Parallel()
+
+	broker := newFakeBroker(t, 5)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := Dial(ctx, Options{Broker: broker.url(), ClientID: "sentinel"})
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Fatalf("Dial error = %v, want not authorized", err)
+	}
+}
+
+func TestBridgeForwardsSelectedEvents(t *testing.T) {
+	t.Parallel()
+
+	broker := newFakeBroker(t, 0)
+	hub := events.NewHub()
+	bridge := NewBridge(hub, BridgeOptions{
+		Client:      Options{Broker: broker.url(), ClientID: "sentinel"},
+		TopicPrefix: "home/sentinel/",
+		QoS:         1,
+		Events:      []string{events.TypeOpsServices},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bridge.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	<-broker.connects
+
+	hub.Publish(events.NewEvent(events.TypeOpsJob, map[string]any{"id": "skipped"}))
+	hub.Publish(events.NewEvent(events.TypeOpsServices, map[string]any{"service": "nginx"}))
+
+	msg := broker.next(t)
+	if msg.topic != "home/sentinel/ops/services/updated" {
+		t.Fatalf("topic = %q", msg.topic)
+	}
+	var event events.Event
+	if err := json.Unmarshal(msg.payload, &event); err != nil {
+		t.Fatalf("payload %q: %v", msg.payload, err)
+	}
+	if event.Type != events.TypeOpsServices || event.Payload["service"] != "nginx" {
+		t.Fatalf("event = %+v", event)
+	}
+	select {
+	case extra := <-broker.messages:
+		t.Fatalf("unexpected message %+v", extra)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -14,11 +14,18 @@ import (
 // A nil *Notifier is safe to call (all methods are no-ops).
 type Notifier struct {
 	url    string
+	label  string // names the webhook in logs
 	client fastshot.ClientHttpMethods
 }
 
 // New creates a Notifier. If url is empty the notifier is disabled.
 func New(url string) *Notifier {
+	return NewLabeled(url, url)
+}
+
+// NewLabeled creates a Notifier that names the webhook label in logs, for
+// URLs resolved from secrets. If url is empty the notifier is disabled.
+func NewLabeled(url, label string) *Notifier {
 	if url == "" {
 		return nil
 	}
@@ -27,6 +34,7 @@ func New(url string) *Notifier {
 		Build()
 	return &Notifier{
 		url:    url,
+		label:  label,
 		client: client,
 	}
 }
@@ -63,6 +71,6 @@ func (n *Notifier) SendJSON(ctx context.Context, payload any) error {
 	if resp.Status().IsError() {
 		return fmt.Errorf("webhook rejected: status %d", resp.Status().Code())
 	}
-	slog.Info("webhook delivered", "url", n.url, "status", resp.Status().Code())
+	slog.Info("webhook delivered", "url", n.label, "status", resp.Status().Code())
 	return nil
 }
@@ -0,0 +1,325 @@
+// Package panelog archives captured tmux pane output to compressed per-pane
+// log files and searches them.
+package panelog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxBytes is the compressed size at which a pane log rotates.
+	DefaultMaxBytes = 8 * 1024 * 1024
+	// DefaultRetention is how long pane logs are kept after their last write.
+	DefaultRetention = 7 * 24 * time.Hour
+
+	// MaxSearchResults caps the number of lines returned by Search.
+	MaxSearchResults = 1000
+
+	logSuffix     = ".log.gz"
+	rotatedSuffix = ".1.log.gz"
+	pruneInterval = 10 * time.Minute
+	maxLineBytes  = 64 * 1024
+)
+
+// ErrInvalidPane is returned when a session or pane identifier cannot be
+// mapped to a log file.
+var ErrInvalidPane = errors.New("invalid session or pane")
+
+// Options configures an Archive.
+type Options struct {
+	MaxBytes  int64
+	Retention time.Duration
+}
+
+// Line is one archived output line.
+type Line struct {
+	At   time.Time `json:"at"`
+	Text string    `json:"text"`
+}
+
+// SearchResult holds the newest matching lines, oldest first.
+type SearchResult struct {
+	Lines     []Line `json:"lines"`
+	Truncated bool   `json:"truncated"`
+}
+
+// Archive appends pane output to gzip files under a directory. Each append
+// writes a separate gzip member, so files stay valid while they grow.
+type Archive struct {
+	dir     string
+	options Options
+
+	mu        sync.Mutex
+	previous  map[string][]string // last captured lines per log path, for overlap detection
+	lastPrune time.Time
+}
+
+// New creates an archive rooted at dir.
+func New(dir string, options Options) *Archive {
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = DefaultMaxBytes
+	}
+	if options.Retention <= 0 {
+		options.Retention = DefaultRetention
+	}
+	return &Archive{
+		dir:      dir,
+		options:  options,
+		previous: make(map[string][]string),
+	}
+}
+
+// Append records the lines of captured that were not part of the previous
+// capture of the same pane. Capture is a sliding window over the pane, so
+// the longest suffix of the previous capture that prefixes this one is
+// treated as already archived.
+func (a *Archive) Append(session, paneID string, at time.Time, captured string) error {
+	path, err := a.logPath(session, paneID)
+	if err != nil {
+		return err
+	}
+	lines := splitCaptured(captured)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	fresh := lines[overlap(a.previous[path], lines):]
+	a.previous[path] = lines
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create pane log dir: %w", err)
+	}
+	if err := a.rotateIfNeeded(path); err != nil {
+		return err
+	}
+	if err := appendMember(path, at, fresh); err != nil {
+		return err
+	}
+
+	if now := time.Now(); now.Sub(a.lastPrune) >= pruneInterval {
+		a.lastPrune = now
+		a.pruneLocked(now)
+	}
+	return nil
+}
+
+// Search scans the archive of one pane and returns up to limit of the
+// newest lines containing query (case-insensitive). An empty query matches
+// every line.
+func (a *Archive) Search(session, paneID, query string, limit int) (SearchResult, error) {
+	path, err := a.logPath(session, paneID)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	if limit <= 0 || limit > MaxSearchResults {
+		limit = MaxSearchResults
+	}
+	needle := strings.ToLower(strings.TrimSpace(query))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var (
+		ring  = make([]Line, 0, limit)
+		total int
+	)
+	for _, file := range []string{rotatedPath(path), path} {
+		err := scanLog(file, func(line Line) {
+			if needle != "" && !strings.Contains(strings.ToLower(line.Text), needle) {
+				return
+			}
+			total++
+			if len(ring) == limit {
+				copy(ring, ring[1:])
+				ring = ring[:limit-1]
+			}
+			ring = append(ring, line)
+		})
+		if err != nil {
+			return SearchResult{}, err
+		}
+	}
+	return SearchResult{Lines: ring, Truncated: total > len(ring)}, nil
+}
+
+// Prune removes pane logs that have not been written within the retention
+// window.
+func (a *Archive) Prune(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pruneLocked(now)
+}
+
+func (a *Archive) pruneLocked(now time.Time) {
+	cutoff := now.Add(-a.options.Retention)
+	_ = filepath.WalkDir(a.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, logSuffix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("pane log prune failed", "path", path, "err", err)
+		}
+		return nil
+	})
+}
+
+func (a *Archive) rotateIfNeeded(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if info.Size() < a.options.MaxBytes {
+		return nil
+	}
+	if err := os.Rename(path, rotatedPath(path)); err != nil {
+		return fmt.Errorf("rotate pane log: %w", err)
+	}
+	return nil
+}
+
+// logPath maps a session and pane to <dir>/<session>/<pane>.log.gz.
+func (a *Archive) logPath(session, paneID string) (string, error) {
+	session = sanitizeSegment(session)
+	pane := sanitizeSegment(strings.TrimPrefix(strings.TrimSpace(paneID), "%"))
+	if a == nil || a.dir == "" || session == "" || pane == "" {
+		return "", ErrInvalidPane
+	}
+	return filepath.Join(a.dir, session, pane+logSuffix), nil
+}
+
+func rotatedPath(path string) string {
+	return strings.TrimSuffix(path, logSuffix) + rotatedSuffix
+}
+
+func sanitizeSegment(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "." || raw == ".." {
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, raw)
+}
+
+func appendMember(path string, at time.Time, lines []string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600) //nolint:gosec // path is built from sanitized segments under the archive dir.
+	if err != nil {
+		return fmt.Errorf("open pane log: %w", err)
+	}
+	zw := gzip.NewWriter(file)
+	stamp := at.UTC().Format(time.RFC3339)
+	for _, line := range lines {
+		if _, err := fmt.Fprintf(zw, "%s\t%s\n", stamp, line); err != nil {
+			_ = zw.Close()
+			_ = file.Close()
+			return fmt.Errorf("write pane log: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write pane log: %w", err)
+	}
+	return file.Close()
+}
+
+func scanLog(path string, fn func(Line)) error {
+	file, err := os.Open(path) //nolint:gosec // path is built from sanitized segments under the archive dir.
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("read pane log: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineBytes)
+	for scanner.Scan() {
+		stamp, text, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, stamp)
+		if err != nil {
+			continue
+		}
+		fn(Line{At: at, Text: text})
+	}
+	// A member cut short by a crash mid-write ends the readable log.
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("read pane log: %w", err)
+	}
+	return nil
+}
+
+func splitCaptured(captured string) []string {
+	captured = strings.TrimRight(captured, "\n")
+	if strings.TrimSpace(captured) == "" {
+		return nil
+	}
+	lines := strings.Split(captured, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \r")
+	}
+	// Blank rows below the prompt are screen padding, not output.
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// overlap returns the length of the longest suffix of prev that is also a
+// prefix of next.
+func overlap(prev, next []string) int {
+	best := min(len(prev), len(next))
+	for n := best; n > 0; n-- {
+		if equalLines(prev[len(prev)-n:], next[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+func equalLines(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package panelog
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func lineTexts(lines []Line) []string {
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		out = append(out, line.Text)
+	}
+	return out
+}
+
+func TestAppendArchivesOnlyNewLines(t *testing.T) {
+	t.Parallel()
+
+	archive := New(t.TempDir(), Options{})
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	captures := []string{
+		"$ make build\ncompiling\n\n",
+		"$ make build\ncompiling\nlinking\n$ ",
+		"compiling\nlinking\n$ ",
+		"$ make test\nFAIL pkg/x\n",
+	}
+	for i, captured := range captures {
+		if err := archive.Append("dev", "%3", at.Add(time.Duration(i)*time.Second), captured); err != nil {
+			t.Fatalf("Append(%d) error = %v", i, err)
+		}
+	}
+
+	result, err := archive.Search("dev", "%3", "", 0)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	want := []string{"$ make build", "compiling", "linking", "$", "$ make test", "FAIL pkg/x"}
+	if got := lineTexts(result.Lines); !slices.Equal(got, want) {
+		t.Fatalf("archived lines = %q, want %q", got, want)
+	}
+	if !result.Lines[2].At.Equal(at.Add(time.Second)) {
+		t.Fatalf("linking at = %s, want %s", result.Lines[2].At, at.Add(time.Second))
+	}
+}
+
+func TestSearchFiltersAndLimits(t *testing.T) {
+	t.Parallel()
+
+	archive := New(t.TempDir(), Options{})
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := archive.Append("dev", "3", at, "error: one\nok\nERROR: two\nerror: three"); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	result, err := archive.Search("dev", "%3", "Error", 2)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got := lineTexts(result.Lines); !slices.Equal(got, []string{"ERROR: two", "error: three"}) || !result.Truncated {
+		t.Fatalf("result = %q truncated=%v", got, result.Truncated)
+	}
+
+	empty, err := archive.Search("dev", "%9", "error", 10)
+	if err != nil || len(empty.Lines) != 0 {
+		t.Fatalf("Search(unknown pane) = %+v, %v", empty, err)
+	}
+	if _, err := archive.Search("..", "%3", "", 10); err == nil {
+		t.Fatal("Search(..) error = nil, want ErrInvalidPane")
+	}
+}
+
+func TestAppendRotatesAndPrunes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	archive := New(dir, Options{MaxBytes: 1, Retention: time.Hour})
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, captured := range []string{"first", "second", "third"} {
+		if err := archive.Append("dev", "%1", at.Add(time.Duration(i)*time.Second), captured); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	// Only the current and one rotated file are kept.
+	result, err := archive.Search("dev", "%1", "", 0)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got := lineTexts(result.Lines); !slices.Equal(got, []string{"second", "third"}) {
+		t.Fatalf("lines after rotation = %q", got)
+	}
+
+	current := filepath.Join(dir, "dev", "1"+logSuffix)
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(current, old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+	archive.Prune(time.Now())
+	if _, err := os.Stat(current); !os.IsNotExist(err) {
+		t.Fatalf("stale log still present: %v", err)
+	}
+	if _, err := os.Stat(rotatedPath(current)); err != nil {
+		t.Fatalf("fresh rotated log removed: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// oomKillLimit caps the kernel journal lines read per query.
+const oomKillLimit = 1000
+
+// OOMKill is a process the kernel killed for lack of memory.
+type OOMKill struct {
+	At      time.Time
+	PID     int
+	Process string
+}
+
+// OOMKills reads the processes the kernel OOM killer killed since the given
+// time, oldest first. They come from the kernel messages in the systemd
+// journal, so other platforms report none.
+func (System) OOMKills(ctx context.Context, since time.Time) ([]OOMKill, error) {
+	if runtime.GOOS != "linux" {
+		return nil, nil
+	}
+	out, err := exec.CommandContext(ctx, "journalctl", "-k",
+		"--no-pager",
+		"-n", strconv.Itoa(oomKillLimit),
+		"--output=short-iso",
+		fmt.Sprintf("--since=@%d", since.Unix()),
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("journalctl failed: %w", err)
+	}
+	var kills []OOMKill
+	for _, line := range strings.Split(string(out), "\n") {
+		if kill, ok := parseOOMKillLine(line); ok {
+			kills = append(kills, kill)
+		}
+	}
+	return kills, nil
+}
+
+// parseOOMKillLine reads a short-iso kernel line such as
+// "2026-10-17T09:12:03+00:00 web-01 kernel: Out of memory: Killed process
+// 1234 (python3) total-vm:...". The memory cgroup variant reads the same
+// from "Killed process" on; other lines are skipped.
+func parseOOMKillLine(line string) (OOMKill, bool) {
+	stamp, _, _ := strings.Cut(line, " ")
+	at, err := time.Parse(time.RFC3339, stamp)
+	if err != nil {
+		// journalctl before systemd 250 omits the colon in the offset.
+		if at, err = time.Parse("2006-01-02T15:04:05-0700", stamp); err != nil {
+			return OOMKill{}, false
+		}
+	}
+	_, rest, ok := strings.Cut(line, "Killed process ")
+	if !ok {
+		return OOMKill{}, false
+	}
+	rawPID, rest, _ := strings.Cut(rest, " ")
+	pid, err := strconv.Atoi(rawPID)
+	if err != nil || pid <= 0 || !strings.HasPrefix(rest, "(") {
+		return OOMKill{}, false
+	}
+	// Process names may hold spaces but not a closing parenthesis.
+	name, _, ok := strings.Cut(rest[1:], ")")
+	if !ok {
+		return OOMKill{}, false
+	}
+	return OOMKill{At: at.UTC(), PID: pid, Process: name}, true
+}
@@ -0,0 +1,158 @@
+// Package proc inspects and signals local processes.
+package proc
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ParseSignal resolves a signal name such as "INT" or "SIGTERM".
+func ParseSignal(name string) (syscall.Signal, error) {
+	key := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+	sig, ok := signals[key]
+	if !ok {
+		return 0, fmt.Errorf("unsupported signal %q", name)
+	}
+	return sig, nil
+}
+
+// System inspects and signals processes of the local host.
+type System struct{}
+
+// Table maps each process ID to its parent process ID.
+type Table map[int]int
+
+// Table snapshots the parent of every process on the host.
+func (System) Table(ctx context.Context) (Table, error) {
+	out, err := exec.CommandContext(ctx, "ps", "-A", "-o", "pid=", "-o", "ppid=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+	return parseProcessTable(string(out)), nil
+}
+
+// Process is one process and its resource usage as reported by ps.
+type Process struct {
+	PID  int
+	PPID int
+	// CPUPercent is ps's %cpu: CPU time over the process lifetime on
+	// Linux, a decaying recent average on macOS.
+	CPUPercent float64
+	RSSBytes   int64
+}
+
+// Usage sums the resource usage of a group of processes.
+type Usage struct {
+	Processes  int     `json:"processes"`
+	CPUPercent float64 `json:"cpuPercent"`
+	RSSBytes   int64   `json:"rssBytes"`
+}
+
+// Processes snapshots every process on the host with its CPU and memory.
+func (System) Processes(ctx context.Context) ([]Process, error) {
+	out, err := exec.CommandContext(ctx, "ps", "-A", "-o", "pid=", "-o", "ppid=", "-o", "pcpu=", "-o", "rss=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+	return parseProcessList(string(out)), nil
+}
+
+// parseProcessTable maps each pid to its parent from "pid ppid" lines.
+func parseProcessTable(out string) Table {
+	parents := make(Table)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		pid, pidErr := strconv.Atoi(fields[0])
+		ppid, ppidErr := strconv.Atoi(fields[1])
+		if pidErr != nil || ppidErr != nil {
+			continue
+		}
+		parents[pid] = ppid
+	}
+	return parents
+}
+
+// parseProcessList reads "pid ppid pcpu rss" lines, with rss in KiB.
+func parseProcessList(out string) []Process {
+	procs := make([]Process, 0, strings.Count(out, "\n"))
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		pid, pidErr := strconv.Atoi(fields[0])
+		ppid, ppidErr := strconv.Atoi(fields[1])
+		cpu, cpuErr := strconv.ParseFloat(strings.ReplaceAll(fields[2], ",", "."), 64)
+		rss, rssErr := strconv.ParseInt(fields[3], 10, 64)
+		if pidErr != nil || ppidErr != nil || cpuErr != nil || rssErr != nil {
+			continue
+		}
+		procs = append(procs, Process{PID: pid, PPID: ppid, CPUPercent: cpu, RSSBytes: rss * 1024})
+	}
+	return procs
+}
+
+// TreeUsage sums the usage of the given root processes and all of their
+// descendants. Roots missing from procs are skipped, but their surviving
+// children still count.
+func TreeUsage(procs []Process, roots ...int) Usage {
+	byPID := make(map[int]Process, len(procs))
+	children := make(map[int][]int, len(procs))
+	for _, p := range procs {
+		byPID[p.PID] = p
+		children[p.PPID] = append(children[p.PPID], p.PID)
+	}
+
+	var usage Usage
+	seen := make(map[int]bool, len(roots))
+	queue := make([]int, 0, len(roots))
+	for _, root := range roots {
+		if root > 0 && !seen[root] {
+			seen[root] = true
+			queue = append(queue, root)
+		}
+	}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		if p, ok := byPID[pid]; ok {
+			usage.Processes++
+			usage.CPUPercent += p.CPUPercent
+			usage.RSSBytes += p.RSSBytes
+		}
+		for _, child := range children[pid] {
+			if !seen[child] {
+				seen[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return usage
+}
+
+// IsDescendant reports whether pid is a child, grandchild, etc. of ancestor.
+// A process is not its own descendant.
+func (t Table) IsDescendant(ancestor, pid int) bool {
+	if ancestor <= 0 || pid <= 0 || ancestor == pid {
+		return false
+	}
+	// Bound the walk so a malformed table with a cycle terminates.
+	for range len(t) {
+		parent, ok := t[pid]
+		if !ok || parent <= 0 {
+			return false
+		}
+		if parent == ancestor {
+			return true
+		}
+		pid = parent
+	}
+	return false
+}
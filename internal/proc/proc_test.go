@@ -0,0 +1,132 @@
+//go:build !windows
+
+package proc
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParseSignal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    syscall.Signal
+		wantErr bool
+	}{
+		{"INT", syscall.SIGINT, false},
+		{"sigterm", syscall.SIGTERM, false},
+		{" KILL ", syscall.SIGKILL, false},
+		{"SIGUSR1", syscall.SIGUSR1, false},
+		{"", 0, true},
+		{"SEGV", 0, true},
+		{"9", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSignal(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSignal(%q) = %v, %v; want %v, err=%v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestIsDescendant(t *testing.T) {
+	t.Parallel()
+
+	parents := parseProcessTable("  1     0\n 100     1\n 200   100\n 300   200\n 400     1\n bad line\n 500   600\n 600   500\n")
+	tests := []struct {
+		ancestor, pid int
+		want          bool
+	}{
+		{100, 200, true},
+		{100, 300, true},
+		{100, 100, false},
+		{100, 400, false},
+		{200, 100, false},
+		{100, 999, false},
+		{100, 500, false},
+	}
+	for _, tt := range tests {
+		if got := parents.IsDescendant(tt.ancestor, tt.pid); got != tt.want {
+			t.Errorf("IsDescendant(%d, %d) = %v, want %v", tt.ancestor, tt.pid, got, tt.want)
+		}
+	}
+}
+
+func TestTreeUsage(t *testing.T) {
+	t.Parallel()
+
+	procs := parseProcessList("  1     0  0.0  1000\n 100     1  1.5  2048\n 200   100 10.0  4096\n 300   200  0,5  1024\n 400     1 50.0 99999\n 500   999  2.0  1024\n bad\n")
+	if len(procs) != 6 {
+		t.Fatalf("parsed %d processes, want 6", len(procs))
+	}
+
+	got := TreeUsage(procs, 100, 200)
+	want := Usage{Processes: 3, CPUPercent: 12, RSSBytes: (2048 + 4096 + 1024) * 1024}
+	if got != want {
+		t.Fatalf("TreeUsage(100, 200) = %+v, want %+v", got, want)
+	}
+	// A root that already exited still accounts for its children.
+	if got := TreeUsage(procs, 999); got.Processes != 1 || got.RSSBytes != 1024*1024 {
+		t.Fatalf("TreeUsage(999) = %+v, want the orphaned child only", got)
+	}
+	if got := TreeUsage(procs); got != (Usage{}) {
+		t.Fatalf("TreeUsage() = %+v, want zero", got)
+	}
+}
+
+func TestSystemTableLiveChild(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("ps"); err != nil {
+		t.Skip("ps not available")
+	}
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("start sleep: %v", err)
+	}
+	defer func() { _ = cmd.Process.Kill(); _ = cmd.Wait() }()
+
+	table, err := System{}.Table(context.Background())
+	if err != nil {
+		t.Fatalf("Table: %v", err)
+	}
+	if !table.IsDescendant(os.Getpid(), cmd.Process.Pid) {
+		t.Fatalf("child %d not reported as descendant of %d", cmd.Process.Pid, os.Getpid())
+	}
+}
+
+func TestParseOOMKillLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line string
+		want OOMKill
+		ok   bool
+	}{
+		{
+			line: "2026-10-17T09:12:03+00:00 web-01 kernel: Out of memory: Killed process 1234 (python3) total-vm:812kB, anon-rss:400kB, UID:1000 pgtables:64kB oom_score_adj:0",
+			want: OOMKill{At: time.Date(2026, 10, 17, 9, 12, 3, 0, time.UTC), PID: 1234, Process: "python3"},
+			ok:   true,
+		},
+		{
+			line: "2026-10-17T11:12:03+0200 web-01 kernel: Memory cgroup out of memory: Killed process 77 (Web Content) total-vm:1kB",
+			want: OOMKill{At: time.Date(2026, 10, 17, 9, 12, 3, 0, time.UTC), PID: 77, Process: "Web Content"},
+			ok:   true,
+		},
+		{line: "2026-10-17T09:12:03+00:00 web-01 kernel: oom-kill:constraint=CONSTRAINT_NONE,task=python3,pid=1234,uid=1000"},
+		{line: "2026-10-17T09:12:03+00:00 web-01 kernel: Killed process x (python3)"},
+		{line: "-- No entries --"},
+	}
+	for _, tt := range tests {
+		got, ok := parseOOMKillLine(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseOOMKillLine(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package proc
+
+import "syscall"
+
+// signals lists the signals that may be sent by name.
+var signals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"STOP": syscall.SIGSTOP,
+	"CONT": syscall.SIGCONT,
+}
+
+// Signal sends sig to pid.
+func (System) Signal(pid int, sig syscall.Signal) error {
+	return syscall.Kill(pid, sig)
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package proc
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// signals lists the signals that may be sent by name. Windows can only
+// terminate a process, so KILL and TERM both do that.
+var signals = map[string]syscall.Signal{
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
+
+// Signal terminates pid; sig must be KILL or TERM.
+func (System) Signal(pid int, sig syscall.Signal) error {
+	if sig != syscall.SIGKILL && sig != syscall.SIGTERM {
+		return fmt.Errorf("signal %v is not supported on windows", sig)
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
@@ -0,0 +1,370 @@
+// Package recording writes attached terminals to asciicast v2 files and
+// lists them for download and playback.
+package recording
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultRetention is how long recordings are kept after their last
+	// write.
+	DefaultRetention = 30 * 24 * time.Hour
+
+	fileSuffix    = ".cast"
+	pruneInterval = 10 * time.Minute
+)
+
+// ErrNotFound is returned for an unknown or malformed recording ID.
+var ErrNotFound = errors.New("recording not found")
+
+// Options configures a Store.
+type Options struct {
+	Retention time.Duration
+}
+
+// Info describes one recording.
+type Info struct {
+	ID        string    `json:"id"`
+	Session   string    `json:"session"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	StartedAt time.Time `json:"startedAt"`
+	// UpdatedAt is the time of the last write, which is when the
+	// recording ended once the terminal is closed.
+	UpdatedAt time.Time `json:"updatedAt"`
+	Size      int64     `json:"size"`
+}
+
+// header is the first line of an asciicast v2 file.
+type header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Store keeps recordings as <dir>/<id>.cast, where the ID is the start
+// time, the session and a random suffix.
+type Store struct {
+	dir     string
+	options Options
+	now     func() time.Time
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// New creates a store rooted at dir.
+func New(dir string, options Options) *Store {
+	if options.Retention <= 0 {
+		options.Retention = DefaultRetention
+	}
+	return &Store{dir: dir, options: options, now: time.Now}
+}
+
+// Start creates a recording of a terminal attached to session with the
+// given size. The caller feeds it with Output, Input and Resize and closes
+// it when the terminal detaches.
+func (s *Store) Start(session string, cols, rows int) (*Recorder, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create recording dir: %w", err)
+	}
+	s.pruneIfDue()
+
+	started := s.now()
+	var suffix [3]byte
+	_, _ = rand.Read(suffix[:])
+	id := fmt.Sprintf("%s-%s-%s", started.UTC().Format("20060102T150405Z"), sanitizeSegment(session), hex.EncodeToString(suffix[:]))
+	file, err := os.OpenFile(s.path(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) //nolint:gosec // id is built from sanitized segments.
+	if err != nil {
+		return nil, fmt.Errorf("create recording: %w", err)
+	}
+
+	line, err := json.Marshal(header{
+		Version:   2,
+		Width:     cols,
+		Height:    rows,
+		Timestamp: started.Unix(),
+		Title:     session,
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	if err == nil {
+		_, err = file.Write(append(line, '\n'))
+	}
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, fmt.Errorf("write recording header: %w", err)
+	}
+	return &Recorder{id: id, file: file, started: started, now: s.now}, nil
+}
+
+// List returns the recordings, newest first.
+func (s *Store) List() ([]Info, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Info{}, nil
+		}
+		return nil, err
+	}
+	infos := make([]Info, 0, len(entries))
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), fileSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := s.Stat(id)
+		if err != nil {
+			continue // removed meanwhile, or not an asciicast file
+		}
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b Info) int { return b.StartedAt.Compare(a.StartedAt) })
+	return infos, nil
+}
+
+// Stat describes one recording.
+func (s *Store) Stat(id string) (Info, error) {
+	file, err := s.Open(id)
+	if err != nil {
+		return Info{}, err
+	}
+	defer func() { _ = file.Close() }()
+	return readInfo(id, file)
+}
+
+// Open opens a recording for reading.
+func (s *Store) Open(id string) (*os.File, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	file, err := os.Open(s.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// Delete removes a recording.
+func (s *Store) Delete(id string) error {
+	if !validID(id) {
+		return ErrNotFound
+	}
+	if err := os.Remove(s.path(id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Prune removes recordings that have not been written within the
+// retention window.
+func (s *Store) Prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked(now)
+}
+
+func (s *Store) pruneIfDue() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now := s.now(); now.Sub(s.lastPrune) >= pruneInterval {
+		s.lastPrune = now
+		s.pruneLocked(now)
+	}
+}
+
+func (s *Store) pruneLocked(now time.Time) {
+	cutoff := now.Add(-s.options.Retention)
+	_ = filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, fileSuffix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("recording prune failed", "path", path, "err", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+fileSuffix)
+}
+
+func readInfo(id string, file *os.File) (Info, error) {
+	stat, err := file.Stat()
+	if err != nil {
+		return Info{}, err
+	}
+	reader := bufio.NewReaderSize(file, 4096)
+	line, err := reader.ReadSlice('\n')
+	if err != nil && len(line) == 0 {
+		return Info{}, fmt.Errorf("read recording header: %w", err)
+	}
+	var head header
+	if err := json.Unmarshal(line, &head); err != nil || head.Version != 2 {
+		return Info{}, errors.New("not an asciicast v2 recording")
+	}
+	return Info{
+		ID:        id,
+		Session:   head.Title,
+		Width:     head.Width,
+		Height:    head.Height,
+		StartedAt: time.Unix(head.Timestamp, 0).UTC(),
+		UpdatedAt: stat.ModTime().UTC(),
+		Size:      stat.Size(),
+	}, nil
+}
+
+// Recorder appends the events of one terminal to its recording. It is safe
+// for concurrent use, so the output and input loops can share it, and a nil
+// Recorder records nothing.
+type Recorder struct {
+	id      string
+	started time.Time
+	now     func() time.Time
+
+	mu      sync.Mutex
+	file    *os.File
+	pending []byte // a UTF-8 sequence split across two output reads
+	err     error
+}
+
+// ID returns the recording ID.
+func (r *Recorder) ID() string {
+	return r.id
+}
+
+// Output records terminal output.
+func (r *Recorder) Output(data []byte) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	buf := append(r.pending, data...)
+	cut := completeUTF8(buf)
+	r.pending = append([]byte(nil), buf[cut:]...)
+	if cut > 0 {
+		r.writeLocked("o", string(buf[:cut]))
+	}
+}
+
+// Input records what was typed into the terminal.
+func (r *Recorder) Input(data []byte) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeLocked("i", string(data))
+}
+
+// Resize records a terminal size change.
+func (r *Recorder) Resize(cols, rows int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeLocked("r", strconv.Itoa(cols)+"x"+strconv.Itoa(rows))
+}
+
+// Close ends the recording.
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	if len(r.pending) > 0 {
+		r.writeLocked("o", string(r.pending))
+		r.pending = nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return errors.Join(r.err, err)
+}
+
+// writeLocked appends one [time, code, data] event. A failed write stops
+// the recording rather than the terminal; Close reports it.
+func (r *Recorder) writeLocked(code, data string) {
+	if r.file == nil || r.err != nil {
+		return
+	}
+	elapsed := r.now().Sub(r.started).Seconds()
+	line, err := json.Marshal([]any{json.Number(strconv.FormatFloat(elapsed, 'f', 6, 64)), code, data})
+	if err == nil {
+		_, err = r.file.Write(append(line, '\n'))
+	}
+	if err != nil {
+		r.err = fmt.Errorf("write recording: %w", err)
+		slog.Warn("recording write failed", "recording", r.id, "err", err)
+	}
+}
+
+// completeUTF8 returns the length of the prefix of buf that does not end
+// inside a multi-byte UTF-8 sequence.
+func completeUTF8(buf []byte) int {
+	for back := 1; back <= utf8.UTFMax && back <= len(buf); back++ {
+		start := len(buf) - back
+		if !utf8.RuneStart(buf[start]) {
+			continue
+		}
+		if utf8.FullRune(buf[start:]) {
+			return len(buf)
+		}
+		return start
+	}
+	return len(buf)
+}
+
+func validID(id string) bool {
+	return id != "" && id[0] != '.' && sanitizeSegment(id) == id
+}
+
+func sanitizeSegment(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "session"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, raw)
+}
@@ -0,0 +1,111 @@
+package recording
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorderWritesAsciicast(t *testing.T) {
+	t.Parallel()
+
+	store := New(t.TempDir(), Options{})
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := started
+	store.now = func() time.Time { return clock }
+
+	rec, err := store.Start("dev", 120, 40)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	clock = started.Add(1500 * time.Millisecond)
+	rec.Output([]byte("h\xc3")) // "hé" split inside the é
+	rec.Output([]byte("\xa9\r\n"))
+	rec.Input([]byte("ls\r"))
+	rec.Resize(100, 30)
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	file, err := store.Open(rec.ID())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	raw, _ := io.ReadAll(file)
+	_ = file.Close()
+	want := `{"version":2,"width":120,"height":40,"timestamp":1772366400,"title":"dev","env":{"TERM":"xterm-256color"}}
+[1.500000,"o","h"]
+[1.500000,"o","é\r\n"]
+[1.500000,"i","ls\r"]
+[1.500000,"r","100x30"]
+`
+	if string(raw) != want {
+		t.Fatalf("recording =\n%s\nwant\n%s", raw, want)
+	}
+
+	infos, err := store.List()
+	if err != nil || len(infos) != 1 {
+		t.Fatalf("List() = %v, %v", infos, err)
+	}
+	info := infos[0]
+	if info.ID != rec.ID() || info.Session != "dev" || info.Width != 120 || info.Height != 40 ||
+		!info.StartedAt.Equal(started) || info.Size != int64(len(want)) {
+		t.Fatalf("info = %+v", info)
+	}
+	if !strings.HasPrefix(info.ID, "20260301T120000Z-dev-") {
+		t.Fatalf("id = %q", info.ID)
+	}
+}
+
+func TestStoreRejectsUnknownIDs(t *testing.T) {
+	t.Parallel()
+
+	store := New(t.TempDir(), Options{})
+	for _, id := range []string{"", "../secret", ".hidden", "a/b", "missing"} {
+		if _, err := store.Open(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Open(%q) error = %v, want ErrNotFound", id, err)
+		}
+		if err := store.Delete(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Delete(%q) error = %v, want ErrNotFound", id, err)
+		}
+	}
+	if infos, err := store.List(); err != nil || len(infos) != 0 {
+		t.Fatalf("List() on a missing dir = %v, %v", infos, err)
+	}
+}
+
+func TestStorePrunesAndDeletes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := New(dir, Options{Retention: time.Hour})
+	old, err := store.Start("old", 80, 24)
+	if err != nil {
+		t.Fatalf("Start(old) error = %v", err)
+	}
+	_ = old.Close()
+	fresh, err := store.Start("fresh", 80, 24)
+	if err != nil {
+		t.Fatalf("Start(fresh) error = %v", err)
+	}
+	_ = fresh.Close()
+	stale := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, old.ID()+fileSuffix), stale, stale); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	store.Prune(time.Now())
+	if _, err := store.Stat(old.ID()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("old recording after prune: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(fresh.ID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if infos, _ := store.List(); len(infos) != 0 {
+		t.Fatalf("List() after delete = %v", infos)
+	}
+}
@@ -0,0 +1,62 @@
+package runbook
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrRunCanceled is the cancellation cause of a run stopped by an operator.
+var ErrRunCanceled = errors.New("canceled by operator")
+
+// errCanceledQueued is the error of a run canceled before a worker picked
+// it up.
+var errCanceledQueued = errors.New("canceled while queued")
+
+// activeRuns tracks the runs executing in this process, whichever caller
+// (manual, scheduler, schedule trigger) started them, so any of them can be
+// canceled by ID.
+var activeRuns = struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelCauseFunc
+}{cancels: make(map[string]context.CancelCauseFunc)}
+
+// trackRun registers a run as executing. The returned context is canceled
+// with ErrRunCanceled by cancelActiveRun; done unregisters the run.
+func trackRun(ctx context.Context, runID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	activeRuns.mu.Lock()
+	activeRuns.cancels[runID] = cancel
+	activeRuns.mu.Unlock()
+	return ctx, func() {
+		activeRuns.mu.Lock()
+		delete(activeRuns.cancels, runID)
+		activeRuns.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancelActiveRun signals an executing run to stop. It returns false when
+// the run is not executing in this process.
+func cancelActiveRun(runID string) bool {
+	activeRuns.mu.Lock()
+	cancel, ok := activeRuns.cancels[runID]
+	activeRuns.mu.Unlock()
+	if ok {
+		cancel(ErrRunCanceled)
+	}
+	return ok
+}
+
+// finalStatus maps how execution ended to the run's terminal status and
+// error. A step failure caused by an operator cancel reports canceled.
+func finalStatus(ctx context.Context, errMsg string) (string, string) {
+	switch {
+	case errMsg == "":
+		return runnerStatusSucceeded, ""
+	case errors.Is(context.Cause(ctx), ErrRunCanceled):
+		return runnerStatusCanceled, ErrRunCanceled.Error()
+	default:
+		return runnerStatusFailed, errMsg
+	}
+}
@@ -0,0 +1,145 @@
+package runbook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// defaultShell runs the commands of steps without a shell.
+const defaultShell = "sh"
+
+var (
+	// shellPattern accepts a program name or path without arguments.
+	shellPattern = regexp.MustCompile(`^[A-Za-z0-9_./+-]+$`)
+	// userPattern accepts portable POSIX user names, and the trailing $ of
+	// Samba machine accounts.
+	userPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,31}\$?$`)
+	// posixShells parse like sh, so their commands get syntax warnings.
+	posixShells = []string{"sh", "ash", "bash", "dash", "ksh", "mksh", "zsh"}
+)
+
+// UserPolicy checks that steps may run as user, returning nil when they may.
+// Sentinel uses the [multi_user] policy of security.Guard.ValidateTargetUser.
+type UserPolicy func(user string) error
+
+// errNoUserPolicy rejects runAsUser when no UserPolicy is set.
+var errNoUserPolicy = errors.New("no target user policy is configured")
+
+// checkUser applies policy to a runAsUser. A nil policy allows no user.
+func checkUser(policy UserPolicy, name string) error {
+	if policy == nil {
+		return errNoUserPolicy
+	}
+	return policy(name)
+}
+
+// commandSpec is a process started by a run, script or wait step.
+type commandSpec struct {
+	name string
+	args []string
+	dir  string
+	// account is the user the process switches to with setuid.
+	account *user.User
+}
+
+// stepTarget is where and as whom a step runs its shell.
+type stepTarget struct {
+	shell string
+	dir   string
+	// account is set when the step runs as a user other than Sentinel's.
+	account *user.User
+}
+
+// stepShell returns the shell a step runs its command with.
+func stepShell(step Step) string {
+	if shell := strings.TrimSpace(step.Shell); shell != "" {
+		return shell
+	}
+	return defaultShell
+}
+
+// isPOSIXShell reports whether shell parses commands like sh.
+func isPOSIXShell(shell string) bool {
+	return slices.Contains(posixShells, path.Base(shell))
+}
+
+// SetUserPolicy sets the policy checked before a step runs as another user.
+// Without one, steps with runAsUser fail.
+func (e *Executor) SetUserPolicy(policy UserPolicy) {
+	e.userPolicy = policy
+}
+
+// resolveTarget checks the working directory and user of a step when it
+// starts, after parameters have been substituted into the directory.
+func (e *Executor) resolveTarget(step Step) (stepTarget, error) {
+	target := stepTarget{shell: stepShell(step)}
+	if cwd := strings.TrimSpace(step.Cwd); cwd != "" {
+		dir := substituteRawParams(cwd, e.params)
+		if !filepath.IsAbs(dir) {
+			return target, fmt.Errorf("cwd %q is not an absolute path", dir)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return target, fmt.Errorf("cwd: %w", err)
+		}
+		if !info.IsDir() {
+			return target, fmt.Errorf("cwd %q is not a directory", dir)
+		}
+		target.dir = dir
+	}
+
+	name := strings.TrimSpace(step.RunAsUser)
+	if name == "" {
+		return target, nil
+	}
+	if runtime.GOOS == "windows" {
+		return target, errors.New("runAsUser is not supported on Windows")
+	}
+	// Checked again here, since the policy may have changed since the
+	// runbook was saved.
+	if err := checkUser(e.userPolicy, name); err != nil {
+		return target, fmt.Errorf("runAsUser %q is not allowed: %w", name, err)
+	}
+	account, err := user.Lookup(name)
+	if err != nil {
+		return target, fmt.Errorf("runAsUser: %w", err)
+	}
+	if current, err := user.Current(); err == nil && current.Uid == account.Uid {
+		return target, nil
+	}
+	target.account = account
+	return target, nil
+}
+
+// command returns the process running the target's shell with args. A
+// Sentinel running as root switches user itself; otherwise the command goes
+// through sudo, which must allow it without a password.
+func (t stepTarget) command(args ...string) commandSpec {
+	spec := commandSpec{name: t.shell, args: args, dir: t.dir}
+	switch {
+	case t.account == nil:
+	case canSetUser():
+		spec.account = t.account
+	default:
+		spec.args = append([]string{"-n", "-u", t.account.Username, "--", t.shell}, args...)
+		spec.name = "sudo"
+	}
+	return spec
+}
+
+// userEnv points the variables naming the current user at account.
+func userEnv(account *user.User) []string {
+	return append(os.Environ(),
+		"HOME="+account.HomeDir,
+		"USER="+account.Username,
+		"LOGNAME="+account.Username,
+	)
+}
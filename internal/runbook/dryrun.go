@@ -0,0 +1,242 @@
+package runbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+
+	"github.com/opus-domini/sentinel/internal/inventory"
+)
+
+// placeholderPattern matches {{NAME}} placeholders left after substitution.
+var placeholderPattern = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+// Plan is the execution plan of a dry run: each step rendered exactly as it
+// would execute with the resolved parameters.
+type Plan struct {
+	RunbookID   string            `json:"runbookId"`
+	RunbookName string            `json:"runbookName"`
+	Parameters  map[string]string `json:"parameters"`
+	Steps       []PlannedStep     `json:"steps"`
+	// Ready is false when any step reports a problem.
+	Ready         bool           `json:"ready"`
+	ShellWarnings []ShellWarning `json:"shellWarnings,omitempty"`
+}
+
+// PlannedStep is one rendered step of a Plan. Problems lists what would make
+// the step fail or misbehave if the runbook ran now.
+type PlannedStep struct {
+	Index           int      `json:"index"`
+	Type            string   `json:"type"`
+	Title           string   `json:"title"`
+	Command         string   `json:"command,omitempty"`
+	Script          string   `json:"script,omitempty"`
+	Description     string   `json:"description,omitempty"`
+	Method          string   `json:"method,omitempty"`
+	URL             string   `json:"url,omitempty"`
+	Body            string   `json:"body,omitempty"`
+	Target          string   `json:"target,omitempty"`
+	Keys            string   `json:"keys,omitempty"`
+	Cwd             string   `json:"cwd,omitempty"`
+	RunAsUser       string   `json:"runAsUser,omitempty"`
+	Shell           string   `json:"shell,omitempty"`
+	Enter           bool     `json:"enter,omitempty"`
+	Marker          string   `json:"marker,omitempty"`
+	Hosts           string   `json:"hosts,omitempty"`
+	Unit            string   `json:"unit,omitempty"`
+	Action          string   `json:"action,omitempty"`
+	TargetHosts     []string `json:"targetHosts,omitempty"`
+	TimeoutSeconds  int      `json:"timeoutSeconds"`
+	Retries         int      `json:"retries,omitempty"`
+	ContinueOnError bool     `json:"continueOnError,omitempty"`
+	Problems        []string `json:"problems,omitempty"`
+}
+
+// SessionExists reports whether a tmux session is running.
+type SessionExists func(session string) bool
+
+// DryRun resolves parameters like Start and renders the runbook's steps
+// without executing anything. tmux.send targets are checked against
+// sessionExists; a nil sessionExists skips that check.
+func (m *Manager) DryRun(ctx context.Context, runbookID string, params map[string]string, sessionExists SessionExists) (Plan, error) {
+	return m.DryRunOnHosts(ctx, runbookID, params, "", sessionExists)
+}
+
+// DryRunOnHosts is DryRun for a run started with StartOnHosts. Service
+// steps list the connected hosts their selector matches.
+func (m *Manager) DryRunOnHosts(ctx context.Context, runbookID string, params map[string]string, hosts string, sessionExists SessionExists) (Plan, error) {
+	if m == nil || m.repo == nil {
+		return Plan{}, errors.New("runbook manager is unavailable")
+	}
+	hosts = strings.TrimSpace(hosts)
+	if hosts != "" {
+		if _, err := inventory.ParseSelector(hosts); err != nil {
+			return Plan{}, fmt.Errorf("%w: %w", ErrInvalidHostSelector, err)
+		}
+	}
+	rb, err := m.repo.GetOpsRunbook(ctx, runbookID)
+	if err != nil {
+		return Plan{}, err
+	}
+	if err := ValidateInputParams(rb.Parameters, params); err != nil {
+		return Plan{}, fmt.Errorf("%w: %w", ErrInvalidParameters, err)
+	}
+	resolved := ResolveParams(rb.Parameters, params)
+	if err := ValidateParams(rb.Parameters, resolved); err != nil {
+		return Plan{}, fmt.Errorf("%w: %w", ErrInvalidParameters, err)
+	}
+
+	plan := Plan{
+		RunbookID:     rb.ID,
+		RunbookName:   rb.Name,
+		Parameters:    resolved,
+		Steps:         make([]PlannedStep, 0, len(rb.Steps)),
+		Ready:         true,
+		ShellWarnings: ShellWarnings(rb.Steps),
+	}
+	for index, step := range stepsFromStore(rb.Steps) {
+		planned := planStep(index, step, resolved, sessionExists)
+		if step.Type == stepTypeService {
+			m.planServiceTargets(ctx, &planned, hosts)
+		}
+		if planned.RunAsUser != "" {
+			if err := checkUser(m.userPolicy, planned.RunAsUser); err != nil {
+				planned.Problems = append(planned.Problems, fmt.Sprintf("runAsUser %q is not allowed: %v", planned.RunAsUser, err))
+			}
+		}
+		if len(planned.Problems) > 0 {
+			plan.Ready = false
+		}
+		plan.Steps = append(plan.Steps, planned)
+	}
+	return plan, nil
+}
+
+// planStep renders a step with the same substitutions the executor applies.
+func planStep(index int, step Step, params map[string]string, sessionExists SessionExists) PlannedStep {
+	planned := PlannedStep{
+		Index:           index,
+		Type:            step.Type,
+		Title:           step.Title,
+		Description:     step.Description,
+		TimeoutSeconds:  int(effectiveStepTimeout(step, defaultStepTimeout).Seconds()),
+		Retries:         step.Retries,
+		ContinueOnError: step.ContinueOnError,
+	}
+	var rendered []string
+	switch step.Type {
+	case stepTypeRun, stepTypeWait:
+		planned.Command = SubstituteParams(step.Command, params)
+		planTarget(&planned, step, params)
+		rendered = append(rendered, planned.Command, planned.Cwd)
+	case stepTypeScript:
+		planned.Script = SubstituteParams(step.Script, params)
+		planTarget(&planned, step, params)
+		rendered = append(rendered, planned.Script, planned.Cwd)
+	case stepTypeHTTP:
+		planned.Method = httpStepMethod(step.Method)
+		planned.URL = substituteRawParams(strings.TrimSpace(step.URL), params)
+		planned.Body = substituteRawParams(step.Body, params)
+		rendered = append(rendered, planned.URL, planned.Body)
+		if parsed, err := url.Parse(planned.URL); !placeholderPattern.MatchString(planned.URL) && (err != nil || parsed.Host == "") {
+			planned.Problems = append(planned.Problems, "rendered url is invalid")
+		}
+	case stepTypeTmuxSend:
+		planned.Target = strings.TrimSpace(step.Target)
+		planned.Keys = SubstituteParams(step.Keys, params)
+		planned.Enter = step.Enter
+		rendered = append(rendered, planned.Keys)
+		if session := targetSession(planned.Target); session != "" && sessionExists != nil && !sessionExists(session) {
+			planned.Problems = append(planned.Problems, fmt.Sprintf("tmux session %q does not exist", session))
+		}
+	case stepTypeTmuxExec:
+		planned.Target = tmuxExecTarget(step)
+		planned.Command = SubstituteParams(step.Command, params)
+		planned.Marker = step.Marker
+		rendered = append(rendered, planned.Command)
+	case stepTypeService:
+		action := serviceStepAction(step, params)
+		planned.Hosts = strings.TrimSpace(substituteRawParams(step.Hosts, params))
+		planned.Unit = action.Unit
+		planned.Action = action.Action
+		rendered = append(rendered, planned.Hosts, planned.Unit)
+	}
+	for _, text := range rendered {
+		for _, placeholder := range placeholderPattern.FindAllString(text, -1) {
+			planned.Problems = append(planned.Problems, fmt.Sprintf("unresolved placeholder %s", placeholder))
+		}
+	}
+	return planned
+}
+
+// planTarget renders the cwd, user and shell of a run, script or wait step
+// and reports a working directory or user that does not exist.
+func planTarget(planned *PlannedStep, step Step, params map[string]string) {
+	planned.Shell = strings.TrimSpace(step.Shell)
+	planned.RunAsUser = strings.TrimSpace(step.RunAsUser)
+	planned.Cwd = substituteRawParams(strings.TrimSpace(step.Cwd), params)
+	if planned.Cwd != "" && !placeholderPattern.MatchString(planned.Cwd) {
+		if info, err := os.Stat(planned.Cwd); err != nil || !info.IsDir() {
+			planned.Problems = append(planned.Problems, fmt.Sprintf("cwd %q is not a directory", planned.Cwd))
+		}
+	}
+	if planned.RunAsUser != "" {
+		if _, err := user.Lookup(planned.RunAsUser); err != nil {
+			planned.Problems = append(planned.Problems, fmt.Sprintf("user %q does not exist", planned.RunAsUser))
+		}
+	}
+}
+
+// targetSession extracts the session name from a tmux target such as
+// "dev", "dev:1" or "dev:1.0". Pane and window IDs ("%3", "@2") carry no
+// session name and yield "".
+func targetSession(target string) string {
+	target = strings.TrimPrefix(target, "=")
+	if strings.HasPrefix(target, "%") || strings.HasPrefix(target, "@") {
+		return ""
+	}
+	session, _, _ := strings.Cut(target, ":")
+	return session
+}
+
+// tmuxExecTarget names the window a tmux.exec step runs in. The session
+// need not exist: the step creates it.
+func tmuxExecTarget(step Step) string {
+	target := strings.TrimSpace(step.Session)
+	if window := strings.TrimSpace(step.Window); window != "" {
+		target += ":" + window
+	}
+	return target
+}
+
+// planServiceTargets resolves the hosts a service step would act on, with
+// the run's selector replacing the step's when set.
+func (m *Manager) planServiceTargets(ctx context.Context, planned *PlannedStep, runHosts string) {
+	if runHosts != "" {
+		planned.Hosts = runHosts
+	}
+	switch {
+	case planned.Hosts == "":
+		planned.Problems = append(planned.Problems, "no host selector: set hosts on the step or the run")
+		return
+	case placeholderPattern.MatchString(planned.Hosts):
+		return
+	case m.hosts == nil:
+		planned.Problems = append(planned.Problems, "host targeting is unavailable: federation is not enabled")
+		return
+	}
+	targets, err := m.hosts.MatchHosts(ctx, planned.Hosts)
+	if err != nil {
+		planned.Problems = append(planned.Problems, err.Error())
+		return
+	}
+	if len(targets) == 0 {
+		planned.Problems = append(planned.Problems, fmt.Sprintf("no connected hosts match %q", planned.Hosts))
+	}
+	planned.TargetHosts = targets
+}
@@ -0,0 +1,180 @@
+package runbook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func TestManagerDryRunRendersPlanWithoutExecuting(t *testing.T) {
+	t.Parallel()
+	st, err := store.New(filepath.Join(t.TempDir(), "sentinel.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+
+	manager := NewManager(st, nil, 1)
+	t.Cleanup(func() { manager.Shutdown(context.Background()) })
+
+	marker := filepath.Join(t.TempDir(), "ran")
+	rb, _, err := manager.Create(context.Background(), store.OpsRunbookWrite{
+		Name: "deploy",
+		Steps: []store.OpsRunbookStep{
+			{Type: "run", Title: "deploy", Command: "touch " + marker + " && echo {{ENV}} {{MISSING}}"},
+			{Type: "http", Title: "notify", Method: "POST", URL: "https://{{HOST}}/hook", Body: `{"env":"{{ENV}}"}`},
+			{Type: "tmux.send", Title: "attach", Target: "dev:1", Keys: "make {{ENV}}"},
+			{Type: "tmux.send", Title: "pane", Target: "%3", Keys: "ls"},
+			{Type: "tmux.exec", Title: "build", Session: "dev", Window: "build", Command: "make {{ENV}}", Marker: "^ok$"},
+			{Type: "script", Title: "migrate", Script: "make migrate", Cwd: "/srv/sentinel-missing/{{ENV}}", Shell: "bash"},
+		},
+		Parameters: []store.RunbookParameter{
+			{Name: "ENV", Type: "string", Default: "staging"},
+			{Name: "HOST", Type: "string", Required: true},
+		},
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.DryRun(context.Background(), rb.ID, nil, nil); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("DryRun(missing required) error = %v, want ErrInvalidParameters", err)
+	}
+	if _, err := manager.DryRun(context.Background(), "missing", nil, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("DryRun(missing runbook) error = %v, want sql.ErrNoRows", err)
+	}
+
+	sessions := map[string]bool{"ops": true}
+	plan, err := manager.DryRun(context.Background(), rb.ID, map[string]string{"HOST": "example.com"}, func(name string) bool {
+		return sessions[name]
+	})
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+	if plan.Ready {
+		t.Fatal("plan.Ready = true, want false")
+	}
+	if plan.Parameters["ENV"] != "staging" || plan.Parameters["HOST"] != "example.com" {
+		t.Fatalf("plan.Parameters = %v", plan.Parameters)
+	}
+	if len(plan.Steps) != 6 {
+		t.Fatalf("len(plan.Steps) = %d, want 6", len(plan.Steps))
+	}
+
+	deploy := plan.Steps[0]
+	if !strings.Contains(deploy.Command, "echo 'staging' {{MISSING}}") {
+		t.Fatalf("deploy.Command = %q", deploy.Command)
+	}
+	if len(deploy.Problems) != 1 || !strings.Contains(deploy.Problems[0], "{{MISSING}}") {
+		t.Fatalf("deploy.Problems = %v", deploy.Problems)
+	}
+	notify := plan.Steps[1]
+	if notify.URL != "https://example.com/hook" || notify.Body != `{"env":"staging"}` || len(notify.Problems) != 0 {
+		t.Fatalf("notify = %+v", notify)
+	}
+	attach := plan.Steps[2]
+	if attach.Keys != "make 'staging'" {
+		t.Fatalf("attach.Keys = %q", attach.Keys)
+	}
+	if len(attach.Problems) != 1 || !strings.Contains(attach.Problems[0], `"dev"`) {
+		t.Fatalf("attach.Problems = %v", attach.Problems)
+	}
+	if pane := plan.Steps[3]; len(pane.Problems) != 0 {
+		t.Fatalf("pane.Problems = %v", pane.Problems)
+	}
+	// tmux.exec creates its session, so a missing one is no problem.
+	if build := plan.Steps[4]; build.Target != "dev:build" || build.Command != "make 'staging'" || build.Marker != "^ok$" || len(build.Problems) != 0 {
+		t.Fatalf("build = %+v", build)
+	}
+	if migrate := plan.Steps[5]; migrate.Cwd != "/srv/sentinel-missing/staging" || migrate.Shell != "bash" ||
+		len(migrate.Problems) != 1 || !strings.Contains(migrate.Problems[0], "not a directory") {
+		t.Fatalf("migrate = %+v", migrate)
+	}
+
+	runs, err := manager.ListRuns(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("dry run created %d runs", len(runs))
+	}
+	if matches, _ := filepath.Glob(marker); len(matches) != 0 {
+		t.Fatal("dry run executed a step")
+	}
+}
+
+func TestManagerDryRunPlansServiceTargets(t *testing.T) {
+	t.Parallel()
+	st, err := store.New(filepath.Join(t.TempDir(), "sentinel.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+
+	manager := NewManager(st, nil, 1)
+	t.Cleanup(func() { manager.Shutdown(context.Background()) })
+	rb, _, err := manager.Create(context.Background(), store.OpsRunbookWrite{
+		Name: "restart web",
+		Steps: []store.OpsRunbookStep{
+			{Type: "service", Title: "restart", Hosts: "role=web", Unit: "nginx.service", Action: "restart"},
+			{Type: "service", Title: "reload", Unit: "api", Action: "restart"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := manager.DryRun(context.Background(), rb.ID, nil, nil)
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+	if problems := plan.Steps[0].Problems; len(problems) != 1 || !strings.Contains(problems[0], "federation is not enabled") {
+		t.Fatalf("problems without targets = %v", problems)
+	}
+	if problems := plan.Steps[1].Problems; len(problems) != 1 || !strings.Contains(problems[0], "no host selector") {
+		t.Fatalf("problems without selector = %v", problems)
+	}
+
+	manager.SetHostTargets(&fakeHostTargets{labels: map[string]string{"web-01": "web", "db-01": "db"}})
+	plan, err = manager.DryRunOnHosts(context.Background(), rb.ID, nil, "role=db", nil)
+	if err != nil {
+		t.Fatalf("DryRunOnHosts: %v", err)
+	}
+	if !plan.Ready {
+		t.Fatalf("plan not ready: %+v", plan.Steps)
+	}
+	for _, step := range plan.Steps {
+		if step.Hosts != "role=db" || len(step.TargetHosts) != 1 || step.TargetHosts[0] != "db-01" {
+			t.Fatalf("step %d hosts = %q -> %v, want role=db -> [db-01]", step.Index, step.Hosts, step.TargetHosts)
+		}
+	}
+	if _, err := manager.DryRunOnHosts(context.Background(), rb.ID, nil, "role=", nil); err != nil {
+		t.Fatalf("DryRunOnHosts(empty value) error = %v", err)
+	}
+	if _, err := manager.DryRunOnHosts(context.Background(), rb.ID, nil, "=web", nil); !errors.Is(err, ErrInvalidHostSelector) {
+		t.Fatalf("DryRunOnHosts(invalid) error = %v, want ErrInvalidHostSelector", err)
+	}
+}
+
+func TestTargetSession(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"dev":     "dev",
+		"dev:1":   "dev",
+		"dev:1.0": "dev",
+		"=dev:1":  "dev",
+		"%3":      "",
+		"@2":      "",
+	}
+	for target, want := range tests {
+		if got := targetSession(target); got != want {
+			t.Errorf("targetSession(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
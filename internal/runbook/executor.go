@@ -2,25 +2,30 @@
 package runbook
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
 	"os"
-	"os/exec"
+	"slices"
+	"sync"
 	"time"
+
+	"github.com/opus-domini/sentinel/internal/secrets"
+	"github.com/opus-domini/sentinel/internal/tracing"
 )
 
 // StepResult holds the outcome of a single executed step.
 type StepResult struct {
 	StepIndex     int
 	Title         string
-	Type          string // "run", "script", "approval"
+	Type          string // "run", "script", "approval", "http", "tmux.send", "tmux.exec", "wait", "service"
 	Output        string
 	Error         string
 	Duration      time.Duration
 	NeedsApproval bool // true when an approval step pauses execution
 	Retries       int  // number of retries attempted
+	ExitCode      int  // exit status of a failed run or script command
 }
 
 // BeforeStepFunc is called before each step begins execution.
@@ -31,19 +36,44 @@ type BeforeStepFunc func(stepIndex int, step Step)
 type ProgressFunc func(completedSteps int, currentStep string, result StepResult)
 
 // CommandRunner executes an external command and returns its combined output.
+// A custom runner receives the command line of run, script and wait steps,
+// but not their working directory or a user switched to without sudo.
 type CommandRunner func(ctx context.Context, name string, args ...string) (string, error)
 
 // Step describes a single runbook step to execute.
 type Step struct {
-	Type            string `json:"type"`
-	Title           string `json:"title"`
-	Command         string `json:"command,omitempty"`
-	Script          string `json:"script,omitempty"`
-	Description     string `json:"description,omitempty"`
-	ContinueOnError bool   `json:"continueOnError,omitempty"`
-	Timeout         int    `json:"timeout,omitempty"`
-	Retries         int    `json:"retries,omitempty"`
-	RetryDelay      int    `json:"retryDelay,omitempty"`
+	Type             string  `json:"type"`
+	Title            string  `json:"title"`
+	Command          string  `json:"command,omitempty"`
+	Script           string  `json:"script,omitempty"`
+	Description      string  `json:"description,omitempty"`
+	ContinueOnError  bool    `json:"continueOnError,omitempty"`
+	Timeout          int     `json:"timeout,omitempty"`
+	Retries          int     `json:"retries,omitempty"`
+	RetryDelay       int     `json:"retryDelay,omitempty"`
+	RetryBackoff     float64 `json:"retryBackoff,omitempty"`
+	RetryMaxDelay    int     `json:"retryMaxDelay,omitempty"`
+	RetryOnExitCodes []int   `json:"retryOnExitCodes,omitempty"`
+	Cwd              string  `json:"cwd,omitempty"`
+	RunAsUser        string  `json:"runAsUser,omitempty"`
+	Shell            string  `json:"shell,omitempty"`
+	URL              string  `json:"url,omitempty"`
+	Method           string  `json:"method,omitempty"`
+	Body             string  `json:"body,omitempty"`
+	ExpectStatus     int     `json:"expectStatus,omitempty"`
+	Target           string  `json:"target,omitempty"`
+	Keys             string  `json:"keys,omitempty"`
+	Enter            bool    `json:"enter,omitempty"`
+	Session          string  `json:"session,omitempty"`
+	Window           string  `json:"window,omitempty"`
+	Marker           string  `json:"marker,omitempty"`
+	Duration         int     `json:"duration,omitempty"`
+	Interval         int     `json:"interval,omitempty"`
+	Hosts            string  `json:"hosts,omitempty"`
+	Unit             string  `json:"unit,omitempty"`
+	Action           string  `json:"action,omitempty"`
+	Scope            string  `json:"scope,omitempty"`
+	Manager          string  `json:"manager,omitempty"`
 }
 
 // ExecuteResult holds the outcome of an Execute call, including whether
@@ -60,12 +90,36 @@ type Executor struct {
 	runner      CommandRunner
 	stepTimeout time.Duration
 	params      map[string]string // substituted into commands before execution
+
+	// output streams live step output; it only applies with the default
+	// runner, since a CommandRunner returns output when it finishes.
+	output        OutputFunc
+	defaultRunner bool
+
+	// hosts runs service steps; hostSelector, when set, replaces the
+	// selector of every service step.
+	hosts        HostTargets
+	hostSelector string
+
+	// secrets resolves secret references; revealed holds the values
+	// resolved so far, masked in output.
+	secrets    secrets.LookupFunc
+	revealedMu sync.Mutex
+	revealed   []string
+
+	// userPolicy decides which users steps may run as.
+	userPolicy UserPolicy
 }
 
 const (
 	stepTypeRun      = "run"
 	stepTypeScript   = "script"
 	stepTypeApproval = "approval"
+	stepTypeHTTP     = "http"
+	stepTypeTmuxSend = "tmux.send"
+	stepTypeTmuxExec = "tmux.exec"
+	stepTypeWait     = "wait"
+	stepTypeService  = "service"
 
 	defaultStepTimeout = 30 * time.Second
 	defaultRetryDelay  = 2 * time.Second
@@ -76,7 +130,8 @@ const (
 // The optional params map is used to substitute {{PARAM}} placeholders in
 // step commands before execution.
 func NewExecutor(runner CommandRunner, stepTimeout time.Duration, params ...map[string]string) *Executor {
-	if runner == nil {
+	isDefault := runner == nil
+	if isDefault {
 		runner = defaultRunner
 	}
 	if stepTimeout == 0 {
@@ -87,12 +142,26 @@ func NewExecutor(runner CommandRunner, stepTimeout time.Duration, params ...map[
 		p = params[0]
 	}
 	return &Executor{
-		runner:      runner,
-		stepTimeout: stepTimeout,
-		params:      p,
+		runner:        runner,
+		stepTimeout:   stepTimeout,
+		params:        p,
+		defaultRunner: isDefault,
 	}
 }
 
+// SetOutput streams the output of run and script steps to fn while they
+// execute. The complete output is still reported in each StepResult.
+func (e *Executor) SetOutput(fn OutputFunc) {
+	e.output = fn
+}
+
+// SetHosts lets service steps run on federated hosts through targets. A
+// non-empty selector overrides the selector of each service step.
+func (e *Executor) SetHosts(targets HostTargets, selector string) {
+	e.hosts = targets
+	e.hostSelector = selector
+}
+
 // Execute runs steps sequentially. It stops on the first command/script
 // failure (unless ContinueOnError is set) and returns partial results
 // together with an error. When an approval step is encountered, execution
@@ -122,10 +191,7 @@ func (e *Executor) ExecuteFrom(ctx context.Context, steps []Step, startFrom int,
 			beforeStep(i, step)
 		}
 
-		timeout := e.stepTimeout
-		if step.Timeout > 0 {
-			timeout = time.Duration(step.Timeout) * time.Second
-		}
+		timeout := effectiveStepTimeout(step, e.stepTimeout)
 
 		start := time.Now()
 		// Each attempt gets its own timeout (applied inside); retry delays run
@@ -179,27 +245,44 @@ func (r ExecuteResult) Err() error {
 	return nil
 }
 
-func (e *Executor) executeStepWithRetries(ctx context.Context, timeout time.Duration, index int, step Step) StepResult {
+func (e *Executor) executeStepWithRetries(ctx context.Context, timeout time.Duration, index int, step Step) (result StepResult) {
+	ctx, span := tracing.StartChild(ctx, "runbook step", tracing.KindInternal,
+		tracing.Int("runbook.step.index", index),
+		tracing.String("runbook.step.title", step.Title),
+		tracing.String("runbook.step.type", step.Type),
+	)
+	defer func() {
+		span.SetAttributes(tracing.Int("runbook.step.retries", result.Retries))
+		if result.Error != "" {
+			span.SetError(result.Error)
+		}
+		span.End()
+	}()
+
+	step, err := e.resolveSecrets(ctx, step)
+	if err != nil {
+		return StepResult{StepIndex: index, Title: step.Title, Type: step.Type, Error: err.Error()}
+	}
+
 	attempt := func() StepResult {
 		stepCtx, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
-		return e.executeStep(stepCtx, index, step)
+		res := e.executeStep(stepCtx, index, step)
+		res.Output = e.redact(res.Output)
+		res.Error = e.redact(res.Error)
+		return res
 	}
 
-	result := attempt()
+	result = attempt()
 
 	retries := step.Retries
-	if retries <= 0 || result.Error == "" || step.Type == stepTypeApproval {
+	if retries <= 0 || step.Type == stepTypeApproval {
 		return result
 	}
 
-	delay := defaultRetryDelay
-	if step.RetryDelay > 0 {
-		delay = time.Duration(step.RetryDelay) * time.Second
-	}
-
-	for n := 1; n <= retries; n++ {
-		slog.Info("retrying step", "step", index, "title", step.Title, "attempt", n, "maxRetries", retries)
+	for n := 1; n <= retries && shouldRetry(step, result); n++ {
+		delay := retryDelay(step, n)
+		slog.Info("retrying step", "step", index, "title", step.Title, "attempt", n, "maxRetries", retries, "delay", delay)
 
 		select {
 		case <-ctx.Done():
@@ -210,15 +293,46 @@ func (e *Executor) executeStepWithRetries(ctx context.Context, timeout time.Dura
 
 		result = attempt()
 		result.Retries = n
-
-		if result.Error == "" {
-			return result
-		}
 	}
 
 	return result
 }
 
+// shouldRetry reports whether a step result calls for another attempt: the
+// step failed and, when the step lists retry exit codes, its command exited
+// with one of them.
+func shouldRetry(step Step, result StepResult) bool {
+	if result.Error == "" {
+		return false
+	}
+	return len(step.RetryOnExitCodes) == 0 || slices.Contains(step.RetryOnExitCodes, result.ExitCode)
+}
+
+// retryDelay returns the wait before retry n (1-based): RetryDelay seconds
+// (default 2s) multiplied by RetryBackoff for every earlier retry, capped at
+// RetryMaxDelay seconds when set.
+func retryDelay(step Step, n int) time.Duration {
+	delay := defaultRetryDelay
+	if step.RetryDelay > 0 {
+		delay = time.Duration(step.RetryDelay) * time.Second
+	}
+	var maxDelay time.Duration
+	if step.RetryMaxDelay > 0 {
+		maxDelay = time.Duration(step.RetryMaxDelay) * time.Second
+	}
+	if step.RetryBackoff > 1 {
+		scaled := float64(delay) * math.Pow(step.RetryBackoff, float64(n-1))
+		if scaled > float64(math.MaxInt64) {
+			scaled = float64(math.MaxInt64)
+		}
+		delay = time.Duration(scaled)
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
 func (e *Executor) executeStep(ctx context.Context, index int, step Step) StepResult {
 	result := StepResult{
 		StepIndex: index,
@@ -228,29 +342,78 @@ func (e *Executor) executeStep(ctx context.Context, index int, step Step) StepRe
 
 	switch step.Type {
 	case stepTypeRun:
-		cmd := SubstituteParams(step.Command, e.params)
-		output, err := e.runner(ctx, "sh", "-c", cmd)
+		output, err := e.executeRun(ctx, index, step)
 		result.Output = output
 		if err != nil {
 			result.Error = err.Error()
+			result.ExitCode = exitCode(err)
 		}
 	case stepTypeScript:
-		output, err := e.executeScript(ctx, step)
+		output, err := e.executeScript(ctx, index, step)
 		result.Output = output
 		if err != nil {
 			result.Error = err.Error()
+			result.ExitCode = exitCode(err)
 		}
 	case stepTypeApproval:
 		result.Output = step.Description
 		result.NeedsApproval = true
+	case stepTypeHTTP:
+		output, err := e.executeHTTP(ctx, step)
+		result.Output = output
+		if err != nil {
+			result.Error = err.Error()
+		}
+	case stepTypeTmuxSend:
+		output, err := e.executeTmuxSend(ctx, step)
+		result.Output = output
+		if err != nil {
+			result.Error = err.Error()
+		}
+	case stepTypeTmuxExec:
+		output, err := e.executeTmuxExec(ctx, step)
+		result.Output = output
+		if err != nil {
+			result.Error = err.Error()
+		}
+	case stepTypeWait:
+		output, err := e.executeWait(ctx, step)
+		result.Output = output
+		if err != nil {
+			result.Error = err.Error()
+		}
+	case stepTypeService:
+		output, err := e.executeService(ctx, step)
+		result.Output = output
+		if err != nil {
+			result.Error = err.Error()
+		}
 	default:
 		result.Error = fmt.Sprintf("unknown step type: %q", step.Type)
 	}
 
 	return result
 }
-func (e *Executor) executeScript(ctx context.Context, step Step) (string, error) {
+func (e *Executor) executeRun(ctx context.Context, index int, step Step) (string, error) {
+	target, err := e.resolveTarget(step)
+	if err != nil {
+		return "", err
+	}
+	cmd := SubstituteParams(step.Command, e.params)
+	return e.runCommand(ctx, index, target.command("-c", cmd))
+}
+
+func (e *Executor) executeScript(ctx context.Context, index int, step Step) (string, error) {
+	target, err := e.resolveTarget(step)
+	if err != nil {
+		return "", err
+	}
 	script := SubstituteParams(step.Script, e.params)
+	// Another user cannot read Sentinel's private temp file, so the script
+	// is passed inline.
+	if target.account != nil {
+		return e.runCommand(ctx, index, target.command("-c", script))
+	}
 
 	tmpFile, err := os.CreateTemp("", "sentinel-step-*.sh")
 	if err != nil {
@@ -269,14 +432,38 @@ func (e *Executor) executeScript(ctx context.Context, step Step) (string, error)
 		return "", fmt.Errorf("chmod temp script: %w", err)
 	}
 
-	return e.runner(ctx, "sh", tmpFile.Name())
+	return e.runCommand(ctx, index, target.command(tmpFile.Name()))
+}
+
+// runCommand executes a step command, streaming its output when an
+// OutputFunc is set.
+func (e *Executor) runCommand(ctx context.Context, index int, spec commandSpec) (string, error) {
+	if e.output == nil || !e.defaultRunner {
+		return e.runQuiet(ctx, spec)
+	}
+	// The two streams flush on their own timers; serialize the callbacks.
+	var mu sync.Mutex
+	emit := func(stream, chunk string) {
+		mu.Lock()
+		defer mu.Unlock()
+		e.output(index, stream, e.redact(chunk))
+	}
+	stdout := newChunkWriter(func(chunk string) { emit(StreamStdout, chunk) })
+	stderr := newChunkWriter(func(chunk string) { emit(StreamStderr, chunk) })
+	output, err := execCommand(ctx, stdout, stderr, spec)
+	stdout.Flush()
+	stderr.Flush()
+	return output, err
+}
+
+// runQuiet executes a step command without streaming its output.
+func (e *Executor) runQuiet(ctx context.Context, spec commandSpec) (string, error) {
+	if !e.defaultRunner {
+		return e.runner(ctx, spec.name, spec.args...)
+	}
+	return execCommand(ctx, nil, nil, spec)
 }
 
 func defaultRunner(ctx context.Context, name string, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
-	var buf bytes.Buffer
-	cmd.Stdout = &buf
-	cmd.Stderr = &buf
-	err := cmd.Run()
-	return buf.String(), err
+	return execCommand(ctx, nil, nil, commandSpec{name: name, args: args})
 }
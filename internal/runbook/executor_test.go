@@ -2,10 +2,19 @@ package runbook
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	osexec "os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/store"
 )
 
 // mockCall records a single invocation of the mock runner.
@@ -46,6 +55,12 @@ func (m *mockRunner) callCount() int {
 	return len(m.calls)
 }
 
+func (m *mockRunner) getCalls() []mockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mockCall(nil), m.calls...)
+}
+
 func TestExecuteAllStepTypes(t *testing.T) {
 	t.Parallel()
 
@@ -842,6 +857,66 @@ func TestRetryExhausted(t *testing.T) {
 	}
 }
 
+func TestRetryDelayBackoff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		step Step
+		want []time.Duration
+	}{
+		{name: "default", step: Step{}, want: []time.Duration{2 * time.Second, 2 * time.Second}},
+		{name: "fixed", step: Step{RetryDelay: 3}, want: []time.Duration{3 * time.Second, 3 * time.Second}},
+		{
+			name: "exponential",
+			step: Step{RetryDelay: 1, RetryBackoff: 2},
+			want: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+		},
+		{
+			name: "capped",
+			step: Step{RetryDelay: 5, RetryBackoff: 3, RetryMaxDelay: 30},
+			want: []time.Duration{5 * time.Second, 15 * time.Second, 30 * time.Second, 30 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		for i, want := range tt.want {
+			if got := retryDelay(tt.step, i+1); got != want {
+				t.Errorf("%s: retryDelay(%d) = %v, want %v", tt.name, i+1, got, want)
+			}
+		}
+	}
+}
+
+func TestRetryOnExitCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		command      string
+		wantRetries  int
+		wantExitCode int
+	}{
+		{name: "listed code", command: "exit 75", wantRetries: 2, wantExitCode: 75},
+		{name: "other code", command: "exit 1", wantRetries: 0, wantExitCode: 1},
+	}
+	for _, tt := range tests {
+		steps := []Step{{
+			Type: "run", Title: tt.name, Command: tt.command,
+			Retries: 2, RetryDelay: 1, RetryMaxDelay: 1, RetryOnExitCodes: []int{75},
+		}}
+		results, err := NewExecutor(nil, time.Minute).Execute(context.Background(), steps, nil, nil)
+		if err == nil {
+			t.Fatalf("%s: expected error", tt.name)
+		}
+		if results[0].Retries != tt.wantRetries {
+			t.Errorf("%s: retries = %d, want %d", tt.name, results[0].Retries, tt.wantRetries)
+		}
+		if results[0].ExitCode != tt.wantExitCode {
+			t.Errorf("%s: exit code = %d, want %d", tt.name, results[0].ExitCode, tt.wantExitCode)
+		}
+	}
+}
+
 func TestRetryWithDelay(t *testing.T) {
 	t.Parallel()
 
@@ -876,3 +951,100 @@ func TestRetryWithDelay(t *testing.T) {
 		t.Errorf("execution took %v, expected at least ~1s delay for retry", elapsed)
 	}
 }
+
+func TestStepCwdShellAndUser(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []Step{
+		{Type: "run", Title: "pwd", Command: "pwd", Cwd: "{{DIR}}"},
+		{Type: "script", Title: "script pwd", Script: "pwd", Cwd: dir},
+		{Type: "wait", Title: "marker", Command: "test -d .", Cwd: dir},
+		{Type: "run", Title: "missing", Command: "pwd", Cwd: filepath.Join(dir, "missing")},
+	}
+	results, err := NewExecutor(nil, time.Minute, map[string]string{"DIR": dir}).Execute(context.Background(), steps, nil, nil)
+	if err == nil || len(results) != 4 {
+		t.Fatalf("Execute() = %d results, %v; want 4 and the missing cwd error", len(results), err)
+	}
+	for _, index := range []int{0, 1} {
+		if got := strings.TrimSpace(results[index].Output); got != dir {
+			t.Errorf("step %d output = %q, want %q", index, got, dir)
+		}
+	}
+	if results[2].Error != "" {
+		t.Errorf("wait step error = %q", results[2].Error)
+	}
+	if !strings.Contains(results[3].Error, "cwd") {
+		t.Errorf("missing cwd error = %q", results[3].Error)
+	}
+
+	if _, err := osexec.LookPath("bash"); err == nil {
+		steps := []Step{{Type: "run", Title: "bash", Command: `echo "${BASH_VERSION:+bash}"`, Shell: "bash"}}
+		results, err := NewExecutor(nil, time.Minute).Execute(context.Background(), steps, nil, nil)
+		if err != nil || strings.TrimSpace(results[0].Output) != "bash" {
+			t.Errorf("bash step = %+v, %v", results, err)
+		}
+	}
+
+	// Switching user without sudo needs root and an unprivileged account.
+	if !canSetUser() {
+		return
+	}
+	if _, err := user.Lookup("nobody"); err != nil {
+		return
+	}
+	steps = []Step{
+		{Type: "run", Title: "whoami", Command: "id -un", RunAsUser: "nobody", Cwd: "/"},
+		{Type: "script", Title: "script whoami", Script: "id -un", RunAsUser: "nobody", Cwd: "/"},
+	}
+	executor := NewExecutor(nil, time.Minute)
+	executor.SetUserPolicy(func(string) error { return nil })
+	results, err = executor.Execute(context.Background(), steps, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute(runAsUser) error = %v", err)
+	}
+	for _, result := range results {
+		if got := strings.TrimSpace(result.Output); got != "nobody" {
+			t.Errorf("%s output = %q, want nobody", result.Title, got)
+		}
+	}
+}
+
+func TestStepUserPolicy(t *testing.T) {
+	t.Parallel()
+
+	guard := security.NewWithOptions("", nil, security.CookieSecureAuto, security.MultiUserConfig{
+		SystemUsers: []string{"root", "deploy"},
+	}, nil)
+	for name, tc := range map[string]struct {
+		user   string
+		policy UserPolicy
+		want   error
+	}{
+		"root":       {user: "root", policy: guard.ValidateTargetUser, want: security.ErrRootNotAllowed},
+		"disallowed": {user: "mallory", policy: guard.ValidateTargetUser, want: security.ErrUserNotSystemUser},
+		"no policy":  {user: "deploy", want: errNoUserPolicy},
+	} {
+		marker := filepath.Join(t.TempDir(), "ran")
+		steps := []Step{{Type: "run", Title: "touch", Command: "touch " + marker, RunAsUser: tc.user}}
+		executor := NewExecutor(nil, time.Minute)
+		executor.SetUserPolicy(tc.policy)
+		results, err := executor.Execute(context.Background(), steps, nil, nil)
+		if err == nil || len(results) != 1 || !strings.Contains(results[0].Error, "not allowed") {
+			t.Errorf("%s: Execute() = %+v, %v; want the step refused", name, results, err)
+		}
+		if _, statErr := os.Stat(marker); statErr == nil {
+			t.Errorf("%s: step ran despite the policy", name)
+		}
+		if err := CheckUsers([]store.OpsRunbookStep{{Type: "run", Title: "t", Command: "true", RunAsUser: tc.user}}, tc.policy); !errors.Is(err, tc.want) {
+			t.Errorf("%s: CheckUsers() error = %v, want %v", name, err, tc.want)
+		}
+	}
+	if err := CheckUsers([]store.OpsRunbookStep{{Type: "run", Title: "t", Command: "true", RunAsUser: "deploy"}}, guard.ValidateTargetUser); err != nil {
+		t.Errorf("CheckUsers(deploy) error = %v", err)
+	}
+}
@@ -4,16 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/opus-domini/sentinel/internal/inventory"
+	"github.com/opus-domini/sentinel/internal/jobqueue"
+	"github.com/opus-domini/sentinel/internal/secrets"
 	"github.com/opus-domini/sentinel/internal/store"
 )
 
 const defaultMaxConcurrentRuns = 5
 
-// ErrTooManyExecutions is returned when the shared manual-execution limit is
-// full across HTTP and MCP callers.
+// ErrTooManyExecutions is returned when the job queue cannot take another
+// run: every worker is busy and the queue is full, or it is shutting down.
 var ErrTooManyExecutions = errors.New("too many concurrent runbook executions")
 
 // ErrInvalidDefinition is returned when a runbook definition is not valid.
@@ -23,6 +27,14 @@ var ErrInvalidDefinition = errors.New("invalid runbook definition")
 // satisfy the runbook definition.
 var ErrInvalidParameters = errors.New("invalid runbook parameters")
 
+// ErrInvalidHostSelector is returned when a run targets hosts with a
+// selector that does not parse.
+var ErrInvalidHostSelector = errors.New("invalid host selector")
+
+// ErrManagedByFile is returned when a change targets a runbook owned by a
+// runbook library file; only a library sync changes it.
+var ErrManagedByFile = errors.New("runbook is managed by a library file")
+
 // ErrInvalidRunState is returned when an approval transition is not valid for
 // the current persisted run state.
 var ErrInvalidRunState = errors.New("invalid runbook run state")
@@ -34,7 +46,7 @@ type ManagerRepo interface {
 	ListOpsRunbookRuns(ctx context.Context, limit int) ([]store.OpsRunbookRun, error)
 	InsertOpsRunbook(ctx context.Context, write store.OpsRunbookWrite) (store.OpsRunbook, error)
 	UpdateOpsRunbook(ctx context.Context, write store.OpsRunbookWrite) (store.OpsRunbook, error)
-	CreateOpsRunbookRunWithParams(ctx context.Context, runbookID string, at time.Time, params map[string]string) (store.OpsRunbookRun, error)
+	CreateOpsRunbookRunForHosts(ctx context.Context, runbookID string, at time.Time, params map[string]string, hosts string) (store.OpsRunbookRun, error)
 	DeleteOpsRunbook(ctx context.Context, id, expectedName string) (store.OpsRunbookDeleteResult, error)
 }
 
@@ -44,23 +56,80 @@ type Manager struct {
 	emit   EmitFunc
 	ctx    context.Context
 	cancel context.CancelFunc
-	sem    chan struct{}
 	wg     sync.WaitGroup
+	hosts  HostTargets
+
+	// secrets resolves secret references in the runs the manager starts.
+	secrets secrets.LookupFunc
+
+	// userPolicy decides which users the steps of its runbooks may run as.
+	userPolicy UserPolicy
+
+	// queue runs the executions; ownQueue is set when the manager created
+	// it and so shuts it down.
+	queue    *jobqueue.Queue
+	ownQueue bool
 }
 
-// NewManager creates a shared runbook manager.
+// NewManager creates a shared runbook manager running up to maxConcurrent
+// executions. With its own queue, further runs are rejected rather than
+// queued.
 func NewManager(repo ManagerRepo, emit EmitFunc, maxConcurrent int) *Manager {
 	if maxConcurrent <= 0 {
 		maxConcurrent = defaultMaxConcurrentRuns
 	}
+	m := NewManagerWithQueue(repo, emit, jobqueue.New(maxConcurrent, 0))
+	m.ownQueue = true
+	return m
+}
+
+// NewManagerWithQueue creates a shared runbook manager whose executions run
+// on jobs. The caller shuts the queue down.
+func NewManagerWithQueue(repo ManagerRepo, emit EmitFunc, jobs *jobqueue.Queue) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Manager{
 		repo:   repo,
 		emit:   emit,
 		ctx:    ctx,
 		cancel: cancel,
-		sem:    make(chan struct{}, maxConcurrent),
+		queue:  jobs,
+	}
+}
+
+// QueuePosition returns the 1-based place of a run waiting for a worker,
+// or 0 when it is not waiting.
+func (m *Manager) QueuePosition(runID string) int {
+	if m == nil {
+		return 0
+	}
+	return m.queue.Position(runID)
+}
+
+// SetHostTargets lets service steps of the runs the manager starts reach
+// federated hosts.
+func (m *Manager) SetHostTargets(targets HostTargets) {
+	if m == nil {
+		return
+	}
+	m.hosts = targets
+}
+
+// SetSecrets lets the runs the manager starts resolve {{secret "name"}}
+// references through lookup.
+func (m *Manager) SetSecrets(lookup secrets.LookupFunc) {
+	if m == nil {
+		return
+	}
+	m.secrets = lookup
+}
+
+// SetUserPolicy sets the policy a runAsUser must pass when a runbook is
+// saved, dry-run or run. Without one, runbooks with runAsUser are rejected.
+func (m *Manager) SetUserPolicy(policy UserPolicy) {
+	if m == nil {
+		return
 	}
+	m.userPolicy = policy
 }
 
 // List returns every persisted runbook.
@@ -87,6 +156,9 @@ func (m *Manager) Create(ctx context.Context, write store.OpsRunbookWrite) (stor
 	if err := ValidateDefinition(write); err != nil {
 		return store.OpsRunbook{}, nil, fmt.Errorf("%w: %w", ErrInvalidDefinition, err)
 	}
+	if err := CheckUsers(write.Steps, m.userPolicy); err != nil {
+		return store.OpsRunbook{}, nil, fmt.Errorf("%w: %w", ErrInvalidDefinition, err)
+	}
 	created, err := m.repo.InsertOpsRunbook(ctx, write)
 	if err != nil {
 		return store.OpsRunbook{}, nil, err
@@ -103,6 +175,12 @@ func (m *Manager) Update(ctx context.Context, write store.OpsRunbookWrite) (stor
 	if err := ValidateDefinition(write); err != nil {
 		return store.OpsRunbook{}, nil, fmt.Errorf("%w: %w", ErrInvalidDefinition, err)
 	}
+	if err := CheckUsers(write.Steps, m.userPolicy); err != nil {
+		return store.OpsRunbook{}, nil, fmt.Errorf("%w: %w", ErrInvalidDefinition, err)
+	}
+	if err := m.checkUnmanaged(ctx, write.ID); err != nil {
+		return store.OpsRunbook{}, nil, err
+	}
 	updated, err := m.repo.UpdateOpsRunbook(ctx, write)
 	if err != nil {
 		return store.OpsRunbook{}, nil, err
@@ -116,9 +194,25 @@ func (m *Manager) Delete(ctx context.Context, id, expectedName string) (store.Op
 	if m == nil || m.repo == nil {
 		return store.OpsRunbookDeleteResult{}, errors.New("runbook manager is unavailable")
 	}
+	if err := m.checkUnmanaged(ctx, id); err != nil {
+		return store.OpsRunbookDeleteResult{}, err
+	}
 	return m.repo.DeleteOpsRunbook(ctx, id, expectedName)
 }
 
+// checkUnmanaged returns ErrManagedByFile for a runbook owned by a library
+// file.
+func (m *Manager) checkUnmanaged(ctx context.Context, id string) error {
+	existing, err := m.repo.GetOpsRunbook(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing.ManagedBy != "" {
+		return fmt.Errorf("%w %s", ErrManagedByFile, existing.ManagedBy)
+	}
+	return nil
+}
+
 // ListRuns returns recent runbook executions.
 func (m *Manager) ListRuns(ctx context.Context, limit int) ([]store.OpsRunbookRun, error) {
 	if m == nil || m.repo == nil {
@@ -137,18 +231,42 @@ func (m *Manager) GetRun(ctx context.Context, id string) (store.OpsRunbookRun, e
 
 // Start validates parameters, persists a run, and launches it asynchronously.
 func (m *Manager) Start(ctx context.Context, runbookID string, params map[string]string, source string) (store.OpsRunbookRun, error) {
+	return m.StartOnHosts(ctx, runbookID, params, "", source)
+}
+
+// StartOnHosts is Start for a run whose service steps target the hosts
+// matching the hosts label selector; empty keeps each step's own selector.
+func (m *Manager) StartOnHosts(ctx context.Context, runbookID string, params map[string]string, hosts, source string) (store.OpsRunbookRun, error) {
+	return m.StartWithPriority(ctx, runbookID, params, hosts, source, jobqueue.PriorityNormal)
+}
+
+// StartWithPriority is StartOnHosts for a run that waits for a worker at
+// the given priority.
+func (m *Manager) StartWithPriority(ctx context.Context, runbookID string, params map[string]string, hosts, source string, priority jobqueue.Priority) (store.OpsRunbookRun, error) {
+	return m.start(ctx, runbookID, params, hosts, source, priority, 0)
+}
+
+// StartWithTimeout is Start for a run allowed up to runTimeout in total
+// instead of the default five minutes, such as a backup.
+func (m *Manager) StartWithTimeout(ctx context.Context, runbookID string, params map[string]string, source string, runTimeout time.Duration) (store.OpsRunbookRun, error) {
+	return m.start(ctx, runbookID, params, "", source, jobqueue.PriorityNormal, runTimeout)
+}
+
+func (m *Manager) start(ctx context.Context, runbookID string, params map[string]string, hosts, source string, priority jobqueue.Priority, runTimeout time.Duration) (store.OpsRunbookRun, error) {
 	if m == nil || m.repo == nil {
 		return store.OpsRunbookRun{}, errors.New("runbook manager is unavailable")
 	}
-	if !m.acquire() {
-		return store.OpsRunbookRun{}, ErrTooManyExecutions
-	}
-	release := true
-	defer func() {
-		if release {
-			m.release()
+	hosts = strings.TrimSpace(hosts)
+	if hosts != "" {
+		if _, err := inventory.ParseSelector(hosts); err != nil {
+			return store.OpsRunbookRun{}, fmt.Errorf("%w: %w", ErrInvalidHostSelector, err)
 		}
-	}()
+	}
+	slot, err := m.reserve()
+	if err != nil {
+		return store.OpsRunbookRun{}, err
+	}
+	defer slot.Release()
 
 	rb, err := m.repo.GetOpsRunbook(ctx, runbookID)
 	if err != nil {
@@ -162,7 +280,7 @@ func (m *Manager) Start(ctx context.Context, runbookID string, params map[string
 		return store.OpsRunbookRun{}, fmt.Errorf("%w: %w", ErrInvalidParameters, err)
 	}
 	now := time.Now().UTC()
-	job, err := m.repo.CreateOpsRunbookRunWithParams(ctx, runbookID, now, resolved)
+	job, err := m.repo.CreateOpsRunbookRunForHosts(ctx, runbookID, now, resolved, hosts)
 	if err != nil {
 		return store.OpsRunbookRun{}, err
 	}
@@ -172,17 +290,24 @@ func (m *Manager) Start(ctx context.Context, runbookID string, params map[string
 		keyJob:       job,
 	})
 	m.wg.Add(1)
-	go func() {
+	slot.Submit(m.ctx, jobqueue.Job{ID: job.ID, Priority: priority, Run: func(ctx context.Context) {
 		defer m.wg.Done()
-		defer m.release()
-		Run(m.ctx, m.repo, m.emitEvent, RunParams{
+		params := RunParams{
 			Job:         job,
 			Source:      source,
 			StepTimeout: 30 * time.Second,
+			RunTimeout:  runTimeout,
 			Parameters:  resolved,
-		})
-	}()
-	release = false
+			Hosts:       m.hosts,
+			Secrets:     m.secrets,
+			UserPolicy:  m.userPolicy,
+		}
+		if ctx.Err() != nil {
+			CancelQueued(ctx, m.repo, m.emitEvent, params)
+			return
+		}
+		Run(ctx, m.repo, m.emitEvent, params)
+	}})
 	return job, nil
 }
 
@@ -202,15 +327,11 @@ func (m *Manager) Approve(ctx context.Context, runID, source string) (store.OpsR
 	if approvalStep < 0 {
 		return store.OpsRunbookRun{}, errors.New("could not find approval step in results")
 	}
-	if !m.acquire() {
-		return store.OpsRunbookRun{}, ErrTooManyExecutions
+	slot, err := m.reserve()
+	if err != nil {
+		return store.OpsRunbookRun{}, err
 	}
-	release := true
-	defer func() {
-		if release {
-			m.release()
-		}
-	}()
+	defer slot.Release()
 
 	now := time.Now().UTC()
 	running, err := m.repo.UpdateOpsRunbookRun(ctx, store.OpsRunbookRunUpdate{
@@ -232,18 +353,25 @@ func (m *Manager) Approve(ctx context.Context, runID, source string) (store.OpsR
 		keyGlobalRev: now.UnixMilli(),
 		keyJob:       running,
 	})
+	// A person is waiting on an approved run, so it goes ahead of new runs.
 	m.wg.Add(1)
-	go func() {
+	slot.Submit(m.ctx, jobqueue.Job{ID: running.ID, Priority: jobqueue.PriorityHigh, Run: func(ctx context.Context) {
 		defer m.wg.Done()
-		defer m.release()
-		ResumeRun(m.ctx, m.repo, m.emitEvent, RunParams{
+		params := RunParams{
 			Job:         running,
 			Source:      source,
 			StepTimeout: 30 * time.Second,
 			Parameters:  job.ParametersUsed,
-		}, approvalStep)
-	}()
-	release = false
+			Hosts:       m.hosts,
+			Secrets:     m.secrets,
+			UserPolicy:  m.userPolicy,
+		}
+		if ctx.Err() != nil {
+			CancelQueued(ctx, m.repo, m.emitEvent, params)
+			return
+		}
+		ResumeRun(ctx, m.repo, m.emitEvent, params, approvalStep)
+	}})
 	return running, nil
 }
 
@@ -282,6 +410,48 @@ func (m *Manager) Reject(ctx context.Context, runID string) (store.OpsRunbookRun
 	return updated, nil
 }
 
+// Cancel stops a run. An executing run has its current step's process group
+// terminated and then finishes as canceled, publishing the final job
+// through the usual events; the returned job is its state when signaled. A
+// run still waiting in the job queue finishes as canceled without starting,
+// and a run paused for approval is canceled immediately.
+func (m *Manager) Cancel(ctx context.Context, runID string) (store.OpsRunbookRun, error) {
+	if m == nil || m.repo == nil {
+		return store.OpsRunbookRun{}, errors.New("runbook manager is unavailable")
+	}
+	job, err := m.repo.GetOpsRunbookRun(ctx, runID)
+	if err != nil {
+		return store.OpsRunbookRun{}, err
+	}
+	if cancelActiveRun(job.ID) || m.queue.Cancel(job.ID) {
+		return job, nil
+	}
+	if job.Status != store.OpsRunbookStatusWaitingApproval {
+		return store.OpsRunbookRun{}, fmt.Errorf("%w: run status is %q, not executing", ErrInvalidRunState, job.Status)
+	}
+	now := time.Now().UTC()
+	updated, err := m.repo.UpdateOpsRunbookRun(ctx, store.OpsRunbookRunUpdate{
+		RunID:          job.ID,
+		Status:         runnerStatusCanceled,
+		CompletedSteps: job.CompletedSteps,
+		CurrentStep:    job.CurrentStep,
+		Error:          ErrRunCanceled.Error(),
+		FinishedAt:     now.Format(time.RFC3339),
+		FromStatus:     store.OpsRunbookStatusWaitingApproval,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrOpsRunbookRunConflict) {
+			return store.OpsRunbookRun{}, fmt.Errorf("%w: run is no longer waiting for approval", ErrInvalidRunState)
+		}
+		return store.OpsRunbookRun{}, err
+	}
+	m.emitEvent("ops.job.updated", map[string]any{
+		keyGlobalRev: now.UnixMilli(),
+		keyJob:       updated,
+	})
+	return updated, nil
+}
+
 func approvalStepIndex(job store.OpsRunbookRun) int {
 	index := -1
 	for _, result := range job.StepResults {
@@ -292,17 +462,13 @@ func approvalStepIndex(job store.OpsRunbookRun) int {
 	return index
 }
 
-func (m *Manager) acquire() bool {
-	select {
-	case m.sem <- struct{}{}:
-		return true
-	default:
-		return false
+// reserve claims room in the job queue for one execution.
+func (m *Manager) reserve() (*jobqueue.Reservation, error) {
+	slot, err := m.queue.Reserve()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTooManyExecutions, err)
 	}
-}
-
-func (m *Manager) release() {
-	<-m.sem
+	return slot, nil
 }
 
 func (m *Manager) emitEvent(eventType string, payload map[string]any) {
@@ -319,11 +485,16 @@ func (m *Manager) WaitIdle() {
 }
 
 // Shutdown cancels manager-owned executions and waits for them to finish.
+// Runs on a shared queue get the chance to finish when its owner drains the
+// queue before calling this.
 func (m *Manager) Shutdown(ctx context.Context) {
 	if m == nil {
 		return
 	}
 	m.cancel()
+	if m.ownQueue {
+		m.queue.Shutdown(ctx)
+	}
 	done := make(chan struct{})
 	go func() {
 		m.wg.Wait()
@@ -338,7 +509,7 @@ func (m *Manager) Shutdown(ctx context.Context) {
 // IsTerminalStatus reports whether a run no longer executes or waits for
 // approval.
 func IsTerminalStatus(status string) bool {
-	return status == runnerStatusSucceeded || status == runnerStatusFailed
+	return status == runnerStatusSucceeded || status == runnerStatusFailed || status == runnerStatusCanceled
 }
 
 // IsWaitingApproval reports whether a run is paused for human approval.
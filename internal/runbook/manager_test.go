@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/opus-domini/sentinel/internal/jobqueue"
 	"github.com/opus-domini/sentinel/internal/store"
 )
 
@@ -69,3 +73,234 @@ func TestManagerSharesValidationExecutionAndEventOrdering(t *testing.T) {
 		t.Fatalf("event statuses = %q", statuses)
 	}
 }
+
+func TestManagerCancelStopsExecutingRun(t *testing.T) {
+	t.Parallel()
+	st, err := store.New(filepath.Join(t.TempDir(), "sentinel.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	manager := NewManager(st, nil, 1)
+	t.Cleanup(func() { manager.Shutdown(context.Background()) })
+	ctx := context.Background()
+
+	rb, _, err := manager.Create(ctx, store.OpsRunbookWrite{
+		Name: "hung",
+		Steps: []store.OpsRunbookStep{
+			{Type: "run", Title: "hang", Command: "sleep 30 & wait"},
+			{Type: "run", Title: "never", Command: "true"},
+		},
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := manager.Start(ctx, rb.ID, nil, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	deadline := start.Add(5 * time.Second)
+	for {
+		current, err := manager.GetRun(ctx, run.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if current.Status == runnerStatusRunning && current.CurrentStep == "hang" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run never reached the hanging step: %+v", current)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, err := manager.Cancel(ctx, run.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	manager.WaitIdle()
+	if elapsed := time.Since(start); elapsed > stepKillGrace {
+		t.Fatalf("cancel took %v, want the step terminated before the kill grace", elapsed)
+	}
+
+	finished, err := manager.GetRun(ctx, run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finished.Status != runnerStatusCanceled || finished.Error != ErrRunCanceled.Error() {
+		t.Fatalf("finished run = %q (%q), want canceled", finished.Status, finished.Error)
+	}
+	if finished.CompletedSteps != 1 {
+		t.Fatalf("completed steps = %d, want only the canceled step", finished.CompletedSteps)
+	}
+	if _, err := manager.Cancel(ctx, run.ID); !errors.Is(err, ErrInvalidRunState) {
+		t.Fatalf("Cancel(finished) error = %v, want ErrInvalidRunState", err)
+	}
+}
+
+func TestManagerCancelWaitingApprovalRun(t *testing.T) {
+	t.Parallel()
+	st, err := store.New(filepath.Join(t.TempDir(), "sentinel.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	manager := NewManager(st, nil, 1)
+	t.Cleanup(func() { manager.Shutdown(context.Background()) })
+	ctx := context.Background()
+
+	rb, _, err := manager.Create(ctx, store.OpsRunbookWrite{
+		Name:    "gated",
+		Steps:   []store.OpsRunbookStep{{Type: "approval", Title: "Approve", Description: "ok?"}},
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := manager.Start(ctx, rb.ID, nil, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.WaitIdle()
+
+	canceled, err := manager.Cancel(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if canceled.Status != runnerStatusCanceled || canceled.FinishedAt == "" {
+		t.Fatalf("canceled run = %+v", canceled)
+	}
+	if !IsTerminalStatus(canceled.Status) {
+		t.Fatal("canceled must be a terminal status")
+	}
+}
+
+func TestManagerQueuesRunsOnSharedQueue(t *testing.T) {
+	t.Parallel()
+	st, err := store.New(filepath.Join(t.TempDir(), "sentinel.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	jobs := jobqueue.New(1, 1)
+	manager := NewManagerWithQueue(st, nil, jobs)
+	t.Cleanup(func() {
+		jobs.Shutdown(context.Background())
+		manager.Shutdown(context.Background())
+	})
+	ctx := context.Background()
+
+	rb, _, err := manager.Create(ctx, store.OpsRunbookWrite{
+		Name:    "queued",
+		Steps:   []store.OpsRunbookStep{{Type: "run", Title: "hang", Command: "sleep 30 & wait"}},
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := manager.Start(ctx, rb.ID, nil, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := manager.Start(ctx, rb.ID, nil, "test")
+	if err != nil {
+		t.Fatalf("second Start error = %v, want it queued", err)
+	}
+	if got := manager.QueuePosition(second.ID); got != 1 {
+		t.Fatalf("QueuePosition(second) = %d, want 1", got)
+	}
+	if _, err := manager.Start(ctx, rb.ID, nil, "test"); !errors.Is(err, ErrTooManyExecutions) {
+		t.Fatalf("third Start error = %v, want ErrTooManyExecutions", err)
+	}
+
+	// Canceling the waiting run finishes it without running any step.
+	if _, err := manager.Cancel(ctx, second.ID); err != nil {
+		t.Fatalf("Cancel(queued) error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		current, err := manager.GetRun(ctx, second.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if current.Status == runnerStatusCanceled {
+			if current.StartedAt != "" || current.Error != errCanceledQueued.Error() {
+				t.Fatalf("canceled queued run = %+v", current)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queued run was not canceled: %+v", current)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if _, err := manager.Cancel(ctx, first.ID); err != nil {
+		t.Fatalf("Cancel(first) error = %v", err)
+	}
+	manager.WaitIdle()
+}
+
+func TestManagerChecksRunAsUserPolicy(t *testing.T) {
+	t.Parallel()
+	st, err := store.New(filepath.Join(t.TempDir(), "sentinel.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	ctx := context.Background()
+
+	manager := NewManager(st, nil, 1)
+	t.Cleanup(func() { manager.Shutdown(ctx) })
+	manager.SetUserPolicy(func(name string) error {
+		if name == "root" {
+			return errors.New("root user is not allowed as a target")
+		}
+		return nil
+	})
+
+	write := store.OpsRunbookWrite{
+		Name:    "as root",
+		Steps:   []store.OpsRunbookStep{{Type: "run", Title: "id", Command: "id -un", RunAsUser: "root"}},
+		Enabled: true,
+	}
+	if _, _, err := manager.Create(ctx, write); !errors.Is(err, ErrInvalidDefinition) {
+		t.Fatalf("Create(root) error = %v, want ErrInvalidDefinition", err)
+	}
+	write.Steps[0].RunAsUser = "deploy"
+	rb, _, err := manager.Create(ctx, write)
+	if err != nil {
+		t.Fatalf("Create(deploy) error = %v", err)
+	}
+	write.ID = rb.ID
+	write.Steps[0].RunAsUser = "root"
+	if _, _, err := manager.Update(ctx, write); !errors.Is(err, ErrInvalidDefinition) {
+		t.Fatalf("Update(root) error = %v, want ErrInvalidDefinition", err)
+	}
+
+	// A policy that no longer allows the saved user is reported by dry runs
+	// and stops the run before the step starts.
+	manager.SetUserPolicy(func(string) error { return errors.New("user not in allowed users") })
+	plan, err := manager.DryRun(ctx, rb.ID, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Ready || len(plan.Steps) != 1 || !slices.ContainsFunc(plan.Steps[0].Problems, func(p string) bool {
+		return strings.Contains(p, `runAsUser "deploy" is not allowed`)
+	}) {
+		t.Fatalf("DryRun() = %+v, want the user reported", plan)
+	}
+	run, err := manager.Start(ctx, rb.ID, nil, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.WaitIdle()
+	finished, err := manager.GetRun(ctx, run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finished.Status != runnerStatusFailed || !strings.Contains(finished.Error, "not allowed") {
+		t.Fatalf("run = %s %q, want failed on the user policy", finished.Status, finished.Error)
+	}
+}
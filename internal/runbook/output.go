@@ -0,0 +1,140 @@
+package runbook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Output stream names passed to OutputFunc.
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+const (
+	// outputFlushInterval bounds how long streamed output is buffered
+	// before it is published, so chatty commands emit a few chunks per
+	// second instead of one event per write.
+	outputFlushInterval = 200 * time.Millisecond
+	// outputChunkBytes flushes early once a chunk grows this large.
+	outputChunkBytes = 4096
+	// stepKillGrace is how long a terminated step may shut down before its
+	// process group is killed.
+	stepKillGrace = 5 * time.Second
+)
+
+// OutputFunc receives live command output from run and script steps as it
+// is produced. Calls are serialized and complete before the step's
+// ProgressFunc call.
+type OutputFunc func(stepIndex int, stream, chunk string)
+
+// execCommand runs a command and returns its combined output. Non-nil
+// stdout and stderr additionally receive each stream as it is written.
+//
+// The command runs in its own process group. When ctx ends (step timeout,
+// cancel, shutdown) the whole group gets SIGTERM, then SIGKILL after
+// stepKillGrace, so children of the step shell do not outlive it.
+func execCommand(ctx context.Context, stdout, stderr io.Writer, spec commandSpec) (string, error) {
+	cmd := exec.CommandContext(ctx, spec.name, spec.args...)
+	cmd.Dir = spec.dir
+	setProcessGroup(cmd)
+	if spec.account != nil {
+		if err := setProcessUser(cmd, spec.account); err != nil {
+			return "", err
+		}
+	}
+	// Stop waiting for output held open by stray processes once the group
+	// has been killed.
+	cmd.WaitDelay = stepKillGrace + time.Second
+	combined := &syncBuffer{}
+	cmd.Stdout = teeWriter(combined, stdout)
+	cmd.Stderr = teeWriter(combined, stderr)
+	err := cmd.Run()
+	return combined.String(), err
+}
+
+// exitCode returns the status a failed command exited with, or 0 when it
+// did not run or was killed by a signal.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 0
+}
+
+func teeWriter(combined, stream io.Writer) io.Writer {
+	if stream == nil {
+		return combined
+	}
+	return io.MultiWriter(combined, stream)
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent stdout and stderr
+// copies exec starts when they use different writers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// chunkWriter batches writes into chunks, publishing at most every
+// outputFlushInterval or whenever outputChunkBytes accumulate.
+type chunkWriter struct {
+	mu    sync.Mutex
+	buf   []byte
+	timer *time.Timer
+	emit  func(chunk string)
+}
+
+func newChunkWriter(emit func(chunk string)) *chunkWriter {
+	return &chunkWriter{emit: emit}
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= outputChunkBytes {
+		w.flushLocked()
+	} else if w.timer == nil {
+		w.timer = time.AfterFunc(outputFlushInterval, w.Flush)
+	}
+	return len(p), nil
+}
+
+// Flush publishes any buffered output.
+func (w *chunkWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushLocked()
+}
+
+func (w *chunkWriter) flushLocked() {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.buf) == 0 {
+		return
+	}
+	chunk := string(w.buf)
+	w.buf = w.buf[:0]
+	w.emit(chunk)
+}
@@ -0,0 +1,84 @@
+package runbook
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExecutorStreamsStepOutput(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		chunks = map[string]string{}
+	)
+	exec := NewExecutor(nil, 5*time.Second)
+	exec.SetOutput(func(stepIndex int, stream, chunk string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stepIndex != 0 {
+			t.Errorf("stepIndex = %d, want 0", stepIndex)
+		}
+		chunks[stream] += chunk
+	})
+
+	results, err := exec.Execute(context.Background(), []Step{
+		{Type: "run", Title: "mixed", Command: "echo out; echo err >&2"},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if chunks[StreamStdout] != "out\n" || chunks[StreamStderr] != "err\n" {
+		t.Fatalf("streamed chunks = %q", chunks)
+	}
+	if out := results[0].Output; !strings.Contains(out, "out\n") || !strings.Contains(out, "err\n") {
+		t.Fatalf("combined output = %q", out)
+	}
+}
+
+func TestExecutorDoesNotStreamCustomRunner(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockRunner{results: []mockResult{{output: "done\n"}}}
+	exec := NewExecutor(mock.run, 5*time.Second)
+	exec.SetOutput(func(int, string, string) {
+		t.Error("custom runner output must not be streamed")
+	})
+	if _, err := exec.Execute(context.Background(), []Step{
+		{Type: "run", Title: "mock", Command: "true"},
+	}, nil, nil); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+}
+
+func TestChunkWriterBatchesUntilFlush(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		chunks []string
+	)
+	w := newChunkWriter(func(chunk string) {
+		mu.Lock()
+		chunks = append(chunks, chunk)
+		mu.Unlock()
+	})
+	_, _ = w.Write([]byte("a"))
+	_, _ = w.Write([]byte("b"))
+	w.Flush()
+	w.Flush()
+
+	_, _ = w.Write([]byte(strings.Repeat("x", outputChunkBytes)))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(chunks) != 2 || chunks[0] != "ab" || len(chunks[1]) != outputChunkBytes {
+		t.Fatalf("chunks = %q", chunks)
+	}
+}
@@ -0,0 +1,58 @@
+//go:build !windows
+
+package runbook
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup runs cmd in its own process group and makes its
+// cancellation terminate the whole group.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		time.AfterFunc(stepKillGrace, func() {
+			_ = syscall.Kill(-pgid, syscall.SIGKILL)
+		})
+		return syscall.Kill(-pgid, syscall.SIGTERM)
+	}
+}
+
+// canSetUser reports whether commands can switch user without sudo, which
+// takes root.
+func canSetUser() bool {
+	return os.Geteuid() == 0
+}
+
+// setProcessUser makes cmd run as account with its groups. It must follow
+// setProcessGroup.
+func setProcessUser(cmd *exec.Cmd, account *user.User) error {
+	uid, err := strconv.ParseUint(account.Uid, 10, 32)
+	if err != nil {
+		return fmt.Errorf("user %s has invalid uid %q", account.Username, account.Uid)
+	}
+	gid, err := strconv.ParseUint(account.Gid, 10, 32)
+	if err != nil {
+		return fmt.Errorf("user %s has invalid gid %q", account.Username, account.Gid)
+	}
+	credential := &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+	groupIDs, err := account.GroupIds()
+	if err != nil {
+		return fmt.Errorf("groups of user %s: %w", account.Username, err)
+	}
+	for _, raw := range groupIDs {
+		if group, err := strconv.ParseUint(raw, 10, 32); err == nil {
+			credential.Groups = append(credential.Groups, uint32(group))
+		}
+	}
+	cmd.SysProcAttr.Credential = credential
+	cmd.Env = userEnv(account)
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package runbook
+
+import (
+	"errors"
+	"os/exec"
+	"os/user"
+)
+
+// setProcessGroup keeps the default cancellation on Windows, which kills
+// the step process but not its children.
+func setProcessGroup(*exec.Cmd) {}
+
+// canSetUser reports false: steps cannot switch user on Windows.
+func canSetUser() bool {
+	return false
+}
+
+func setProcessUser(*exec.Cmd, *user.User) error {
+	return errors.New("runAsUser is not supported on Windows")
+}
@@ -9,7 +9,9 @@ import (
 
 	fastshot "github.com/opus-domini/fast-shot"
 
+	"github.com/opus-domini/sentinel/internal/secrets"
 	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/tracing"
 )
 
 // Repo defines the store operations consumed by the runbook runner.
@@ -41,6 +43,18 @@ type RunParams struct {
 	// step commands before execution.
 	Parameters map[string]string
 
+	// Hosts runs service steps on federated hosts. Without it service steps
+	// fail.
+	Hosts HostTargets
+
+	// Secrets resolves {{secret "name"}} references in steps and the
+	// runbook's webhook URL. Without it such references fail.
+	Secrets secrets.LookupFunc
+
+	// UserPolicy decides which users steps may run as. Without it steps
+	// with runAsUser fail.
+	UserPolicy UserPolicy
+
 	// OnFinish is called after the run is persisted with the final status.
 	OnFinish func(ctx context.Context, status string)
 }
@@ -48,6 +62,7 @@ type RunParams struct {
 const (
 	keyGlobalRev = "globalRev"
 	keyJob       = "job"
+	keyJobID     = "jobId"
 )
 
 const (
@@ -55,6 +70,7 @@ const (
 	runnerStatusSucceeded       = "succeeded"
 	runnerStatusFailed          = "failed"
 	runnerStatusWaitingApproval = "waiting_approval"
+	runnerStatusCanceled        = "canceled"
 )
 
 const defaultRunTimeout = 5 * time.Minute
@@ -71,6 +87,10 @@ func Run(ctx context.Context, repo Repo, emit EmitFunc, params RunParams) {
 	if runTimeout <= 0 {
 		runTimeout = defaultRunTimeout
 	}
+	ctx, untrack := trackRun(ctx, params.Job.ID)
+	defer untrack()
+	ctx, span := startRunSpan(ctx, params)
+	defer span.End()
 	ctx, cancel := context.WithTimeout(ctx, runTimeout)
 	defer cancel()
 
@@ -98,29 +118,20 @@ func Run(ctx context.Context, repo Repo, emit EmitFunc, params RunParams) {
 	if err != nil {
 		finCtx, finCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 		defer finCancel()
-		finishRun(finCtx, repo, emit, params, 0, "", err.Error(), "[]", "")
+		finishRun(finCtx, repo, emit, params, 0, "", runnerStatusFailed, err.Error(), "[]", "")
 		return
 	}
-	steps := make([]Step, len(rb.Steps))
-	for i, s := range rb.Steps {
-		steps[i] = Step{
-			Type:            s.Type,
-			Title:           s.Title,
-			Command:         s.Command,
-			Script:          s.Script,
-			Description:     s.Description,
-			ContinueOnError: s.ContinueOnError,
-			Timeout:         s.Timeout,
-			Retries:         s.Retries,
-			RetryDelay:      s.RetryDelay,
-		}
-	}
+	steps := stepsFromStore(rb.Steps)
 
 	stepTimeout := params.StepTimeout
 	if stepTimeout <= 0 {
 		stepTimeout = 30 * time.Second
 	}
 	executor := NewExecutor(nil, stepTimeout, params.Parameters)
+	executor.SetOutput(jobOutput(emit, job.ID))
+	executor.SetHosts(params.Hosts, job.Hosts)
+	executor.SetSecrets(params.Secrets)
+	executor.SetUserPolicy(params.UserPolicy)
 	var accumulated []store.OpsRunbookStepResult
 
 	// beforeStep writes a preliminary step result to the DB before execution.
@@ -163,6 +174,8 @@ func Run(ctx context.Context, repo Repo, emit EmitFunc, params RunParams) {
 			Output:     result.Output,
 			Error:      result.Error,
 			DurationMs: result.Duration.Milliseconds(),
+			Retries:    result.Retries,
+			ExitCode:   result.ExitCode,
 		}
 		stepResultsJSON, marshalErr := json.Marshal(accumulated)
 		if marshalErr != nil {
@@ -198,6 +211,7 @@ func Run(ctx context.Context, repo Repo, emit EmitFunc, params RunParams) {
 		if len(results) > 0 {
 			lastStep = results[len(results)-1].Title
 		}
+		span.SetAttributes(tracing.String("runbook.status", runnerStatusWaitingApproval))
 		if _, err := repo.UpdateOpsRunbookRun(ctx, store.OpsRunbookRunUpdate{
 			RunID:          job.ID,
 			Status:         runnerStatusWaitingApproval,
@@ -223,6 +237,7 @@ func Run(ctx context.Context, repo Repo, emit EmitFunc, params RunParams) {
 	if execErr := execResult.Err(); execErr != nil {
 		errMsg = execErr.Error()
 	}
+	status, errMsg := finalStatus(ctx, errMsg)
 	lastStep := ""
 	if len(results) > 0 {
 		lastStep = results[len(results)-1].Title
@@ -238,16 +253,90 @@ func Run(ctx context.Context, repo Repo, emit EmitFunc, params RunParams) {
 	// (trace IDs) while shedding the done channel.
 	finCtx, finCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 	defer finCancel()
-	finishRun(finCtx, repo, emit, params, len(results), lastStep, errMsg, string(stepResultsJSON), rb.WebhookURL)
+	finishRun(finCtx, repo, emit, params, len(results), lastStep, status, errMsg, string(stepResultsJSON), rb.WebhookURL)
 }
 
-func finishRun(ctx context.Context, repo Repo, emit EmitFunc, params RunParams, completed int, lastStep, errMsg, stepResultsJSON, webhookURL string) {
-	status := runnerStatusSucceeded
-	if errMsg != "" {
-		status = runnerStatusFailed
+// jobOutput publishes live step output of a run as ops.job.log events.
+func jobOutput(emit EmitFunc, jobID string) OutputFunc {
+	return func(stepIndex int, stream, chunk string) {
+		emit("ops.job.log", map[string]any{
+			keyGlobalRev: time.Now().UTC().UnixMilli(),
+			keyJobID:     jobID,
+			"stepIndex":  stepIndex,
+			"stream":     stream,
+			"chunk":      chunk,
+		})
+	}
+}
+
+func stepsFromStore(in []store.OpsRunbookStep) []Step {
+	steps := make([]Step, len(in))
+	for i, s := range in {
+		steps[i] = Step{
+			Type:             s.Type,
+			Title:            s.Title,
+			Command:          s.Command,
+			Script:           s.Script,
+			Description:      s.Description,
+			ContinueOnError:  s.ContinueOnError,
+			Timeout:          s.Timeout,
+			Retries:          s.Retries,
+			RetryDelay:       s.RetryDelay,
+			RetryBackoff:     s.RetryBackoff,
+			RetryMaxDelay:    s.RetryMaxDelay,
+			RetryOnExitCodes: s.RetryOnExitCodes,
+			Cwd:              s.Cwd,
+			RunAsUser:        s.RunAsUser,
+			Shell:            s.Shell,
+			URL:              s.URL,
+			Method:           s.Method,
+			Body:             s.Body,
+			ExpectStatus:     s.ExpectStatus,
+			Target:           s.Target,
+			Keys:             s.Keys,
+			Enter:            s.Enter,
+			Session:          s.Session,
+			Window:           s.Window,
+			Marker:           s.Marker,
+			Duration:         s.Duration,
+			Interval:         s.Interval,
+			Hosts:            s.Hosts,
+			Unit:             s.Unit,
+			Action:           s.Action,
+			Scope:            s.Scope,
+			Manager:          s.Manager,
+		}
 	}
+	return steps
+}
 
+// CancelQueued finishes a run canceled while it waited for a worker as
+// canceled, keeping the progress it made before an approval pause.
+func CancelQueued(ctx context.Context, repo Repo, emit EmitFunc, params RunParams) {
+	finCtx, finCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer finCancel()
+	job := params.Job
+	finishRun(finCtx, repo, emit, params, job.CompletedSteps, job.CurrentStep, runnerStatusCanceled, errCanceledQueued.Error(), "", "")
+}
+
+// startRunSpan starts the span covering a run or its resumption. Spans of
+// the steps, queries and tmux commands it issues nest under it.
+func startRunSpan(ctx context.Context, params RunParams) (context.Context, *tracing.Span) {
+	return tracing.Start(ctx, "runbook run", tracing.KindInternal,
+		tracing.String("runbook.id", params.Job.RunbookID),
+		tracing.String("runbook.name", params.Job.RunbookName),
+		tracing.String("runbook.run_id", params.Job.ID),
+		tracing.String("runbook.source", params.Source),
+	)
+}
+
+func finishRun(ctx context.Context, repo Repo, emit EmitFunc, params RunParams, completed int, lastStep, status, errMsg, stepResultsJSON, webhookURL string) {
 	finished := time.Now().UTC()
+	span := tracing.SpanFromContext(ctx)
+	span.SetAttributes(tracing.String("runbook.status", status))
+	if status != runnerStatusSucceeded {
+		span.SetError(errMsg)
+	}
 	if _, err := repo.UpdateOpsRunbookRun(ctx, store.OpsRunbookRunUpdate{
 		RunID:          params.Job.ID,
 		Status:         status,
@@ -271,7 +360,13 @@ func finishRun(ctx context.Context, repo Repo, emit EmitFunc, params RunParams,
 	})
 
 	if webhookURL != "" {
-		fireWebhook(ctx, webhookURL, buildWebhookPayload(params, updatedJob))
+		// Logs show the URL as written, never a resolved secret.
+		target, _, err := secrets.Expand(ctx, params.Secrets, webhookURL, nil)
+		if err != nil {
+			slog.Warn("webhook delivery skipped", "url", webhookURL, "error", err)
+		} else {
+			fireWebhook(ctx, target, webhookURL, buildWebhookPayload(params, updatedJob))
+		}
 	}
 
 	if params.OnFinish != nil {
@@ -346,8 +441,10 @@ func buildWebhookPayload(params RunParams, job store.OpsRunbookRun) webhookPaylo
 	}
 }
 
-func fireWebhook(ctx context.Context, webhookURL string, payload any) {
-	client := fastshot.NewClient(webhookURL).
+// fireWebhook posts payload to target. Logs name the webhook by webhookURL,
+// the URL as configured, so resolved secrets stay out of them.
+func fireWebhook(ctx context.Context, target, webhookURL string, payload any) {
+	client := fastshot.NewClient(target).
 		Config().SetTimeout(10 * time.Second).
 		Build()
 
@@ -380,6 +477,10 @@ func ResumeRun(ctx context.Context, repo Repo, emit EmitFunc, params RunParams,
 	if runTimeout <= 0 {
 		runTimeout = defaultRunTimeout
 	}
+	ctx, untrack := trackRun(ctx, params.Job.ID)
+	defer untrack()
+	ctx, span := startRunSpan(ctx, params)
+	defer span.End()
 	ctx, cancel := context.WithTimeout(ctx, runTimeout)
 	defer cancel()
 
@@ -407,29 +508,20 @@ func ResumeRun(ctx context.Context, repo Repo, emit EmitFunc, params RunParams,
 	if err != nil {
 		finCtx, finCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 		defer finCancel()
-		finishRun(finCtx, repo, emit, params, resumeFromStep+1, "", err.Error(), "[]", "")
+		finishRun(finCtx, repo, emit, params, resumeFromStep+1, "", runnerStatusFailed, err.Error(), "[]", "")
 		return
 	}
-	steps := make([]Step, len(rb.Steps))
-	for i, s := range rb.Steps {
-		steps[i] = Step{
-			Type:            s.Type,
-			Title:           s.Title,
-			Command:         s.Command,
-			Script:          s.Script,
-			Description:     s.Description,
-			ContinueOnError: s.ContinueOnError,
-			Timeout:         s.Timeout,
-			Retries:         s.Retries,
-			RetryDelay:      s.RetryDelay,
-		}
-	}
+	steps := stepsFromStore(rb.Steps)
 
 	stepTimeout := params.StepTimeout
 	if stepTimeout <= 0 {
 		stepTimeout = 30 * time.Second
 	}
 	executor := NewExecutor(nil, stepTimeout, params.Parameters)
+	executor.SetOutput(jobOutput(emit, job.ID))
+	executor.SetHosts(params.Hosts, job.Hosts)
+	executor.SetSecrets(params.Secrets)
+	executor.SetUserPolicy(params.UserPolicy)
 
 	// Recover previous step results from the run record. If this read fails,
 	// continuing would start from an empty set and overwrite the pre-approval
@@ -439,7 +531,7 @@ func ResumeRun(ctx context.Context, repo Repo, emit EmitFunc, params RunParams,
 	if err != nil {
 		finCtx, finCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 		defer finCancel()
-		finishRun(finCtx, repo, emit, params, resumeFromStep+1, "", fmt.Sprintf("resume failed: %v", err), "", "")
+		finishRun(finCtx, repo, emit, params, resumeFromStep+1, "", runnerStatusFailed, fmt.Sprintf("resume failed: %v", err), "", "")
 		return
 	}
 	accumulated := make([]store.OpsRunbookStepResult, len(existingRun.StepResults))
@@ -481,6 +573,8 @@ func ResumeRun(ctx context.Context, repo Repo, emit EmitFunc, params RunParams,
 			Output:     result.Output,
 			Error:      result.Error,
 			DurationMs: result.Duration.Milliseconds(),
+			Retries:    result.Retries,
+			ExitCode:   result.ExitCode,
 		}
 		stepResultsJSON, marshalErr := json.Marshal(accumulated)
 		if marshalErr != nil {
@@ -519,6 +613,7 @@ func ResumeRun(ctx context.Context, repo Repo, emit EmitFunc, params RunParams,
 		if len(results) > 0 {
 			lastStep = results[len(results)-1].Title
 		}
+		span.SetAttributes(tracing.String("runbook.status", runnerStatusWaitingApproval))
 		if _, err := repo.UpdateOpsRunbookRun(ctx, store.OpsRunbookRunUpdate{
 			RunID:          job.ID,
 			Status:         runnerStatusWaitingApproval,
@@ -544,6 +639,7 @@ func ResumeRun(ctx context.Context, repo Repo, emit EmitFunc, params RunParams,
 	if execErr := execResult.Err(); execErr != nil {
 		errMsg = execErr.Error()
 	}
+	status, errMsg := finalStatus(ctx, errMsg)
 	lastStep := ""
 	if len(results) > 0 {
 		lastStep = results[len(results)-1].Title
@@ -555,5 +651,5 @@ func ResumeRun(ctx context.Context, repo Repo, emit EmitFunc, params RunParams,
 
 	finCtx, finCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 	defer finCancel()
-	finishRun(finCtx, repo, emit, params, resumeFromStep+1+len(results), lastStep, errMsg, string(stepResultsJSON), rb.WebhookURL)
+	finishRun(finCtx, repo, emit, params, resumeFromStep+1+len(results), lastStep, status, errMsg, string(stepResultsJSON), rb.WebhookURL)
 }
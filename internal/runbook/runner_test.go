@@ -195,8 +195,12 @@ func TestRunSuccessfulEndToEnd(t *testing.T) {
 	}
 
 	var emittedTypes []string
-	emit := func(eventType string, _ map[string]any) {
+	var logged []map[string]any
+	emit := func(eventType string, payload map[string]any) {
 		emittedTypes = append(emittedTypes, eventType)
+		if eventType == "ops.job.log" {
+			logged = append(logged, payload)
+		}
 	}
 
 	var onFinishStatus string
@@ -223,6 +227,9 @@ func TestRunSuccessfulEndToEnd(t *testing.T) {
 	if onFinishStatus != runnerStatusSucceeded {
 		t.Errorf("OnFinish status = %q, want %q", onFinishStatus, runnerStatusSucceeded)
 	}
+	if len(logged) != 1 || logged[0][keyJobID] != "run-ok" || logged[0]["stream"] != StreamStdout || logged[0]["chunk"] != "hello\n" {
+		t.Errorf("ops.job.log payloads = %v, want one stdout chunk for run-ok", logged)
+	}
 }
 
 func TestRunGetRunbookError(t *testing.T) {
@@ -562,7 +569,7 @@ func TestFireWebhookDeliversPayload(t *testing.T) {
 		},
 	}
 
-	fireWebhook(context.Background(), server.URL, payload)
+	fireWebhook(context.Background(), server.URL, server.URL, payload)
 
 	if received.Event != "runbook.completed" {
 		t.Fatalf("received event = %q, want runbook.completed", received.Event)
@@ -589,7 +596,7 @@ func TestFireWebhookHandlesServerError(t *testing.T) {
 	defer server.Close()
 
 	// Should not panic; logs a warning instead.
-	fireWebhook(context.Background(), server.URL, map[string]string{"test": "true"})
+	fireWebhook(context.Background(), server.URL, server.URL, map[string]string{"test": "true"})
 
 	mu.Lock()
 	got := attempts
@@ -607,7 +614,7 @@ func TestFireWebhookHandlesInvalidURL(t *testing.T) {
 	defer cancel()
 
 	// Should not panic on an unreachable URL.
-	fireWebhook(ctx, "http://192.0.2.1:1/webhook", map[string]string{"test": "true"})
+	fireWebhook(ctx, "http://192.0.2.1:1/webhook", "http://192.0.2.1:1/webhook", map[string]string{"test": "true"})
 }
 
 func TestRunApprovalStepPauses(t *testing.T) {
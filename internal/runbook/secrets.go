@@ -0,0 +1,61 @@
+package runbook
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/opus-domini/sentinel/internal/secrets"
+)
+
+// SetSecrets resolves {{secret "name"}} references in step commands,
+// scripts, keys and HTTP requests through lookup when each step starts.
+// Resolved values are masked in the step output and errors.
+func (e *Executor) SetSecrets(lookup secrets.LookupFunc) {
+	e.secrets = lookup
+}
+
+// resolveSecrets returns step with its secret references replaced. Values
+// headed for a shell are escaped like parameters. References are resolved
+// before parameters are substituted, so a parameter value cannot smuggle
+// one in.
+func (e *Executor) resolveSecrets(ctx context.Context, step Step) (Step, error) {
+	for _, field := range []struct {
+		text  *string
+		quote func(string) string
+	}{
+		{&step.Command, ShellEscape},
+		{&step.Script, ShellEscape},
+		{&step.Keys, ShellEscape},
+		{&step.URL, nil},
+		{&step.Body, nil},
+	} {
+		expanded, values, err := secrets.Expand(ctx, e.secrets, *field.text, field.quote)
+		if err != nil {
+			return step, fmt.Errorf("resolve secret: %w", err)
+		}
+		*field.text = expanded
+		e.reveal(values)
+	}
+	return step, nil
+}
+
+func (e *Executor) reveal(values []string) {
+	if len(values) == 0 {
+		return
+	}
+	e.revealedMu.Lock()
+	defer e.revealedMu.Unlock()
+	for _, value := range values {
+		if !slices.Contains(e.revealed, value) {
+			e.revealed = append(e.revealed, value)
+		}
+	}
+}
+
+// redact masks the secret values resolved so far in text.
+func (e *Executor) redact(text string) string {
+	e.revealedMu.Lock()
+	defer e.revealedMu.Unlock()
+	return secrets.Redact(text, e.revealed)
+}
@@ -0,0 +1,64 @@
+package runbook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/secrets"
+)
+
+func TestExecutorResolvesAndRedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(_ context.Context, name string) (string, error) {
+		if name == "api_token" {
+			return "tok'en", nil
+		}
+		return "", secrets.ErrNotFound
+	}
+	mock := &mockRunner{results: []mockResult{{output: "sent with tok'en\n"}}}
+	exec := NewExecutor(mock.run, 0, map[string]string{"HOST": `{{secret "api_token"}}`})
+	exec.SetSecrets(lookup)
+
+	results, err := exec.Execute(context.Background(), []Step{
+		{Type: "run", Title: "notify", Command: `curl -H "Authorization: {{secret "api_token"}}" {{HOST}}`},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	calls := mock.getCalls()
+	want := `curl -H "Authorization: 'tok'\''en'" '{{secret "api_token"}}'`
+	if len(calls) != 1 || calls[0].Args[1] != want {
+		t.Fatalf("command = %v, want %q (a parameter must not resolve secrets)", calls, want)
+	}
+	if got := results[0].Output; strings.Contains(got, "tok'en") || !strings.Contains(got, "[secret]") {
+		t.Fatalf("output = %q, want the value redacted", got)
+	}
+
+	results, err = exec.Execute(context.Background(), []Step{
+		{Type: "run", Title: "missing", Command: `echo {{secret "other"}}`},
+	}, nil, nil)
+	if err == nil || !strings.Contains(results[0].Error, "secret not found") || mock.callCount() != 1 {
+		t.Fatalf("Execute(missing secret) = %+v, %v; want the step to fail without running", results, err)
+	}
+
+	unconfigured := NewExecutor(mock.run, 0)
+	results, _ = unconfigured.Execute(context.Background(), []Step{
+		{Type: "run", Title: "no vault", Command: `echo {{secret "api_token"}}`},
+	}, nil, nil)
+	if !strings.Contains(results[0].Error, secrets.ErrUnavailable.Error()) {
+		t.Fatalf("Execute() without secrets = %+v, want ErrUnavailable", results)
+	}
+}
+
+func TestValidateWebhookURLAcceptsSecretRefs(t *testing.T) {
+	t.Parallel()
+
+	if err := validateWebhookURL(`{{secret "slack_webhook"}}`); err != nil {
+		t.Fatalf("validateWebhookURL(secret) error = %v", err)
+	}
+	if err := validateWebhookURL("ftp://example.com"); err == nil {
+		t.Fatal("validateWebhookURL(ftp) accepted")
+	}
+}
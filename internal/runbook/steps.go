@@ -0,0 +1,362 @@
+package runbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/inventory"
+)
+
+const (
+	// maxHTTPStepOutput bounds how much of an http step response body is
+	// recorded in the step result.
+	maxHTTPStepOutput = 64 * 1024
+
+	// maxTmuxExecOutput bounds how much pane output a tmux.exec step
+	// records, keeping the most recent lines.
+	maxTmuxExecOutput = 64 * 1024
+
+	defaultWaitInterval   = 2 * time.Second
+	defaultMarkerInterval = time.Second
+)
+
+var httpStepClient = &http.Client{} // var enables test injection
+
+// Service step defaults and actions, matching the unit action API.
+const (
+	defaultServiceScope   = "system"
+	defaultServiceManager = "systemd"
+)
+
+var (
+	serviceStepActions  = []string{"start", "stop", "restart", "enable", "disable"}
+	serviceStepScopes   = []string{"user", "system"}
+	serviceStepManagers = []string{"systemd", "launchd", "docker", "windows", "rcd"}
+)
+
+// HostTargets resolves host label selectors and runs service actions on
+// federated hosts for service steps.
+type HostTargets interface {
+	MatchHosts(ctx context.Context, selector string) ([]string, error)
+	ServiceAction(ctx context.Context, host string, action inventory.ServiceAction) error
+}
+
+var httpStepMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// substituteRawParams replaces {{PARAM_NAME}} placeholders without shell
+// escaping, for step fields that are never passed to a shell.
+func substituteRawParams(text string, params map[string]string) string {
+	for name, value := range params {
+		text = strings.ReplaceAll(text, "{{"+name+"}}", value)
+	}
+	return text
+}
+
+func httpStepMethod(raw string) string {
+	method := strings.ToUpper(strings.TrimSpace(raw))
+	if method == "" {
+		return http.MethodGet
+	}
+	return method
+}
+
+func (e *Executor) executeHTTP(ctx context.Context, step Step) (string, error) {
+	target := substituteRawParams(strings.TrimSpace(step.URL), e.params)
+	var body io.Reader
+	if step.Body != "" {
+		body = strings.NewReader(substituteRawParams(step.Body, e.params))
+	}
+	req, err := http.NewRequestWithContext(ctx, httpStepMethod(step.Method), target, body)
+	if err != nil {
+		return "", fmt.Errorf("build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := httpStepClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	payload, _ := io.ReadAll(io.LimitReader(resp.Body, maxHTTPStepOutput))
+	output := fmt.Sprintf("HTTP %d\n%s", resp.StatusCode, payload)
+
+	if step.ExpectStatus > 0 {
+		if resp.StatusCode != step.ExpectStatus {
+			return output, fmt.Errorf("unexpected status %d, want %d", resp.StatusCode, step.ExpectStatus)
+		}
+	} else if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return output, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return output, nil
+}
+
+func (e *Executor) executeTmuxSend(ctx context.Context, step Step) (string, error) {
+	target := strings.TrimSpace(step.Target)
+	// Keys end up at a shell prompt, so values are escaped like commands.
+	keys := SubstituteParams(step.Keys, e.params)
+	if keys != "" {
+		if out, err := e.runner(ctx, "tmux", "send-keys", "-t", target, "-l", keys); err != nil {
+			return out, err
+		}
+	}
+	if step.Enter {
+		if out, err := e.runner(ctx, "tmux", "send-keys", "-t", target, "Enter"); err != nil {
+			return out, err
+		}
+	}
+	return fmt.Sprintf("sent %d bytes to %s", len(keys), target), nil
+}
+
+// executeTmuxExec sends the step's command to a tmux window, creating the
+// session and window when they do not exist. With a marker it then polls the
+// pane until the output written after the command line matches.
+func (e *Executor) executeTmuxExec(ctx context.Context, step Step) (string, error) {
+	target, err := e.ensureTmuxWindow(ctx, strings.TrimSpace(step.Session), strings.TrimSpace(step.Window))
+	if err != nil {
+		return "", err
+	}
+	var marker *regexp.Regexp
+	if step.Marker != "" {
+		if marker, err = compileMarker(step.Marker); err != nil {
+			return "", fmt.Errorf("invalid marker: %w", err)
+		}
+	}
+	var start int
+	if marker != nil {
+		if start, err = e.tmuxCursorLine(ctx, target); err != nil {
+			return "", err
+		}
+	}
+
+	cmd := SubstituteParams(step.Command, e.params)
+	if out, err := e.runner(ctx, "tmux", "send-keys", "-t", target, "-l", cmd); err != nil {
+		return out, err
+	}
+	if out, err := e.runner(ctx, "tmux", "send-keys", "-t", target, "Enter"); err != nil {
+		return out, err
+	}
+	if marker == nil {
+		return fmt.Sprintf("sent command to %s", target), nil
+	}
+
+	interval := defaultMarkerInterval
+	if step.Interval > 0 {
+		interval = time.Duration(step.Interval) * time.Second
+	}
+	var output string
+	for {
+		output, err = e.tmuxOutputSince(ctx, target, start)
+		if err == nil && marker.MatchString(output) {
+			return tailOutput(output, maxTmuxExecOutput), nil
+		}
+		select {
+		case <-ctx.Done():
+			return tailOutput(output, maxTmuxExecOutput), errors.Join(fmt.Errorf("marker %q not seen before timeout", step.Marker), ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// compileMarker compiles a tmux.exec marker in multi-line mode, so ^ and $
+// anchor at the lines of the pane output.
+func compileMarker(marker string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?m)" + marker)
+}
+
+// ensureTmuxWindow creates session and window when missing and returns the
+// pane target for them. Names are matched exactly; with no window the
+// session's current window is used.
+func (e *Executor) ensureTmuxWindow(ctx context.Context, session, window string) (string, error) {
+	target := "=" + session + ":"
+	if window != "" {
+		target += "=" + window
+	}
+	if _, err := e.runner(ctx, "tmux", "has-session", "-t", "="+session); err != nil {
+		args := []string{"new-session", "-d", "-s", session}
+		if window != "" {
+			args = append(args, "-n", window)
+		}
+		if out, err := e.runner(ctx, "tmux", args...); err != nil {
+			return "", fmt.Errorf("create session %s: %w: %s", session, err, strings.TrimSpace(out))
+		}
+		return target, nil
+	}
+	if window == "" {
+		return target, nil
+	}
+
+	names, err := e.runner(ctx, "tmux", "list-windows", "-t", "="+session, "-F", "#{window_name}")
+	if err != nil {
+		return "", fmt.Errorf("list windows of %s: %w", session, err)
+	}
+	if slices.Contains(strings.Split(strings.TrimSpace(names), "\n"), window) {
+		return target, nil
+	}
+	if out, err := e.runner(ctx, "tmux", "new-window", "-d", "-t", "="+session+":", "-n", window); err != nil {
+		return "", fmt.Errorf("create window %s:%s: %w: %s", session, window, err, strings.TrimSpace(out))
+	}
+	return target, nil
+}
+
+// tmuxCursorLine returns the absolute line of the pane cursor, counted from
+// the top of the scrollback, so later output can be found after scrolling.
+func (e *Executor) tmuxCursorLine(ctx context.Context, target string) (int, error) {
+	out, err := e.runner(ctx, "tmux", "display-message", "-p", "-t", target, "#{history_size} #{cursor_y}")
+	if err != nil {
+		return 0, fmt.Errorf("read cursor of %s: %w", target, err)
+	}
+	var history, cursor int
+	if _, err := fmt.Sscanf(strings.TrimSpace(out), "%d %d", &history, &cursor); err != nil {
+		return 0, fmt.Errorf("read cursor of %s: unexpected output %q", target, out)
+	}
+	return history + cursor, nil
+}
+
+// tmuxOutputSince captures the pane from absolute line start on, dropping
+// that first line, which holds the command as typed; wrapped lines are
+// joined so a long command cannot spill into the output.
+func (e *Executor) tmuxOutputSince(ctx context.Context, target string, start int) (string, error) {
+	out, err := e.runner(ctx, "tmux", "display-message", "-p", "-t", target, "#{history_size}")
+	if err != nil {
+		return "", err
+	}
+	history, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return "", fmt.Errorf("read history of %s: unexpected output %q", target, out)
+	}
+	captured, err := e.runner(ctx, "tmux", "capture-pane", "-p", "-J", "-t", target, "-S", strconv.Itoa(start-history))
+	if err != nil {
+		return "", err
+	}
+	_, output, _ := strings.Cut(captured, "\n")
+	return strings.TrimRight(output, "\n "), nil
+}
+
+// tailOutput keeps the last limit bytes of output.
+func tailOutput(output string, limit int) string {
+	if len(output) <= limit {
+		return output
+	}
+	return output[len(output)-limit:]
+}
+
+// executeService runs the step's action on every matching host in turn. A
+// host failing does not stop the others; the step fails if any host did.
+func (e *Executor) executeService(ctx context.Context, step Step) (string, error) {
+	if e.hosts == nil {
+		return "", errors.New("host targeting is unavailable: federation is not enabled")
+	}
+	selector := e.serviceSelector(step)
+	hosts, err := e.hosts.MatchHosts(ctx, selector)
+	if err != nil {
+		return "", err
+	}
+	if len(hosts) == 0 {
+		return "", fmt.Errorf("no connected hosts match %q", selector)
+	}
+
+	action := serviceStepAction(step, e.params)
+	var out strings.Builder
+	failed := 0
+	for _, host := range hosts {
+		if err := e.hosts.ServiceAction(ctx, host, action); err != nil {
+			failed++
+			fmt.Fprintf(&out, "%s: %s %s failed: %v\n", host, action.Action, action.Unit, err)
+			continue
+		}
+		fmt.Fprintf(&out, "%s: %s %s ok\n", host, action.Action, action.Unit)
+	}
+	if failed > 0 {
+		return out.String(), fmt.Errorf("%d of %d hosts failed", failed, len(hosts))
+	}
+	return out.String(), nil
+}
+
+// serviceSelector returns the selector a service step runs against: the
+// run's when set, otherwise the step's own with parameters substituted.
+func (e *Executor) serviceSelector(step Step) string {
+	if selector := strings.TrimSpace(e.hostSelector); selector != "" {
+		return selector
+	}
+	return strings.TrimSpace(substituteRawParams(step.Hosts, e.params))
+}
+
+func serviceStepAction(step Step, params map[string]string) inventory.ServiceAction {
+	action := inventory.ServiceAction{
+		Unit:    strings.TrimSpace(substituteRawParams(step.Unit, params)),
+		Scope:   strings.TrimSpace(step.Scope),
+		Manager: strings.TrimSpace(step.Manager),
+		Action:  strings.ToLower(strings.TrimSpace(step.Action)),
+	}
+	if action.Scope == "" {
+		action.Scope = defaultServiceScope
+	}
+	if action.Manager == "" {
+		action.Manager = defaultServiceManager
+	}
+	return action
+}
+
+func (e *Executor) executeWait(ctx context.Context, step Step) (string, error) {
+	if strings.TrimSpace(step.Command) == "" {
+		delay := time.Duration(step.Duration) * time.Second
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+			return fmt.Sprintf("waited %s", delay), nil
+		}
+	}
+
+	interval := defaultWaitInterval
+	if step.Interval > 0 {
+		interval = time.Duration(step.Interval) * time.Second
+	}
+	target, err := e.resolveTarget(step)
+	if err != nil {
+		return "", err
+	}
+	spec := target.command("-c", SubstituteParams(step.Command, e.params))
+	for attempt := 1; ; attempt++ {
+		output, err := e.runQuiet(ctx, spec)
+		if err == nil {
+			return fmt.Sprintf("condition met after %d attempt(s)\n%s", attempt, output), nil
+		}
+		select {
+		case <-ctx.Done():
+			return output, errors.Join(errors.New("condition not met before timeout"), ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// effectiveStepTimeout returns the per-attempt timeout for a step. Fixed
+// waits without an explicit timeout get their duration on top of the default
+// so a long sleep is not cut short.
+func effectiveStepTimeout(step Step, fallback time.Duration) time.Duration {
+	if step.Timeout > 0 {
+		return time.Duration(step.Timeout) * time.Second
+	}
+	if step.Type == stepTypeWait && strings.TrimSpace(step.Command) == "" && step.Duration > 0 {
+		return time.Duration(step.Duration)*time.Second + fallback
+	}
+	return fallback
+}
@@ -0,0 +1,346 @@
+package runbook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/inventory"
+)
+
+func TestExecuteHTTPStep(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/deploy":
+			if r.Method != http.MethodPost || string(body) != `{"env":"prod"}` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte("queued"))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	exec := NewExecutor(nil, 5*time.Second, map[string]string{"ENV": "prod"})
+	results, err := exec.Execute(context.Background(), []Step{{
+		Type:         stepTypeHTTP,
+		Title:        "deploy",
+		URL:          srv.URL + "/deploy",
+		Method:       "post",
+		Body:         `{"env":"{{ENV}}"}`,
+		ExpectStatus: http.StatusAccepted,
+	}}, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if results[0].Output != "HTTP 202\nqueued" {
+		t.Fatalf("output = %q", results[0].Output)
+	}
+
+	_, err = exec.Execute(context.Background(), []Step{{Type: stepTypeHTTP, Title: "health", URL: srv.URL + "/healthz"}}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "unexpected status 503") {
+		t.Fatalf("Execute() error = %v, want unexpected status 503", err)
+	}
+}
+
+func TestExecuteTmuxSendStep(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockRunner{}
+	exec := NewExecutor(mock.run, time.Second, map[string]string{"SVC": "api"})
+	_, err := exec.Execute(context.Background(), []Step{{
+		Type:   stepTypeTmuxSend,
+		Title:  "restart",
+		Target: "ops:1.0",
+		Keys:   "systemctl restart {{SVC}}",
+		Enter:  true,
+	}}, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := []mockCall{
+		{Name: "tmux", Args: []string{"send-keys", "-t", "ops:1.0", "-l", "systemctl restart 'api'"}},
+		{Name: "tmux", Args: []string{"send-keys", "-t", "ops:1.0", "Enter"}},
+	}
+	calls := mock.getCalls()
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %#v, want %#v", calls, want)
+	}
+	for i := range want {
+		if calls[i].Name != want[i].Name || !slices.Equal(calls[i].Args, want[i].Args) {
+			t.Fatalf("call %d = %#v, want %#v", i, calls[i], want[i])
+		}
+	}
+}
+
+// fakeTmux answers the tmux commands a tmux.exec step runs. Captures are
+// served in order, the last one repeating.
+type fakeTmux struct {
+	mu       sync.Mutex
+	sessions map[string][]string
+	history  string
+	captures []string
+	calls    []string
+}
+
+func (f *fakeTmux) run(_ context.Context, name string, args ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, name+" "+strings.Join(args, " "))
+	switch args[0] {
+	case "has-session":
+		if _, ok := f.sessions[strings.TrimPrefix(args[2], "=")]; !ok {
+			return "can't find session", errors.New("exit status 1")
+		}
+	case "list-windows":
+		return strings.Join(f.sessions[strings.TrimPrefix(args[2], "=")], "\n") + "\n", nil
+	case "display-message":
+		if strings.Contains(args[4], "cursor_y") {
+			return "10 3\n", nil
+		}
+		return f.history + "\n", nil
+	case "capture-pane":
+		out := f.captures[0]
+		if len(f.captures) > 1 {
+			f.captures = f.captures[1:]
+		}
+		return out, nil
+	}
+	return "", nil
+}
+
+func TestExecuteTmuxExecStep(t *testing.T) {
+	t.Parallel()
+
+	t.Run("creates missing session", func(t *testing.T) {
+		t.Parallel()
+
+		tmux := &fakeTmux{}
+		exec := NewExecutor(tmux.run, time.Second, map[string]string{"ENV": "prod"})
+		results, err := exec.Execute(context.Background(), []Step{{
+			Type: stepTypeTmuxExec, Title: "deploy", Session: "ops", Window: "deploy", Command: "make {{ENV}}",
+		}}, nil, nil)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		want := []string{
+			"tmux has-session -t =ops",
+			"tmux new-session -d -s ops -n deploy",
+			"tmux send-keys -t =ops:=deploy -l make 'prod'",
+			"tmux send-keys -t =ops:=deploy Enter",
+		}
+		if !slices.Equal(tmux.calls, want) {
+			t.Fatalf("calls = %q, want %q", tmux.calls, want)
+		}
+		if results[0].Output != "sent command to =ops:=deploy" {
+			t.Fatalf("output = %q", results[0].Output)
+		}
+	})
+
+	t.Run("waits for marker in a new window", func(t *testing.T) {
+		t.Parallel()
+
+		tmux := &fakeTmux{
+			sessions: map[string][]string{"ops": {"main"}},
+			history:  "12",
+			captures: []string{
+				"make\nbuilding\n",
+				"make && echo DONE\nbuilding\nDONE\n\n",
+			},
+		}
+		exec := NewExecutor(tmux.run, 5*time.Second)
+		results, err := exec.Execute(context.Background(), []Step{{
+			Type: stepTypeTmuxExec, Title: "build", Session: "ops", Window: "build",
+			Command: "make && echo DONE", Marker: "^DONE$", Interval: 1,
+		}}, nil, nil)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if !slices.Contains(tmux.calls, "tmux new-window -d -t =ops: -n build") {
+			t.Fatalf("calls = %q, want the window created", tmux.calls)
+		}
+		// The cursor sat on absolute line 13 and history grew to 12, so the
+		// capture starts one line into the visible pane.
+		if !slices.Contains(tmux.calls, "tmux capture-pane -p -J -t =ops:=build -S 1") {
+			t.Fatalf("calls = %q, want capture from line 1", tmux.calls)
+		}
+		if results[0].Output != "building\nDONE" {
+			t.Fatalf("output = %q", results[0].Output)
+		}
+	})
+
+	t.Run("marker in the command line does not count", func(t *testing.T) {
+		t.Parallel()
+
+		tmux := &fakeTmux{
+			sessions: map[string][]string{"ops": {"main"}},
+			history:  "10",
+			captures: []string{"echo DONE\n"},
+		}
+		exec := NewExecutor(tmux.run, 300*time.Millisecond)
+		_, err := exec.Execute(context.Background(), []Step{{
+			Type: stepTypeTmuxExec, Title: "build", Session: "ops", Command: "echo DONE", Marker: "DONE",
+		}}, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), `marker "DONE" not seen before timeout`) {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		for _, call := range tmux.calls {
+			if strings.HasPrefix(call, "tmux new-") {
+				t.Fatalf("calls = %q, want the session reused", tmux.calls)
+			}
+		}
+	})
+}
+
+func TestExecuteWaitStep(t *testing.T) {
+	t.Parallel()
+
+	t.Run("polls until condition succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		mock := &mockRunner{results: []mockResult{
+			{err: errors.New("not yet")},
+			{output: "ready"},
+		}}
+		exec := NewExecutor(mock.run, 5*time.Second)
+		results, err := exec.Execute(context.Background(), []Step{{
+			Type: stepTypeWait, Title: "ready", Command: "test -f /tmp/ready", Interval: 1,
+		}}, nil, nil)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if len(mock.getCalls()) != 2 || !strings.Contains(results[0].Output, "after 2 attempt(s)") {
+			t.Fatalf("calls = %d, output = %q", len(mock.getCalls()), results[0].Output)
+		}
+	})
+
+	t.Run("condition times out", func(t *testing.T) {
+		t.Parallel()
+
+		mock := &mockRunner{results: []mockResult{{err: errors.New("no")}, {err: errors.New("no")}}}
+		exec := NewExecutor(mock.run, 5*time.Second)
+		_, err := exec.Execute(context.Background(), []Step{{
+			Type: stepTypeWait, Title: "ready", Command: "false", Interval: 1, Timeout: 1,
+		}}, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "condition not met") {
+			t.Fatalf("Execute() error = %v, want condition not met", err)
+		}
+	})
+
+	t.Run("fixed duration honours cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		exec := NewExecutor(nil, time.Second)
+		results := exec.ExecuteFrom(ctx, []Step{{Type: stepTypeWait, Title: "pause", Duration: 60}}, 0, nil, nil)
+		if results.CtxErr == nil {
+			t.Fatal("expected context error for cancelled wait")
+		}
+	})
+}
+
+func TestEffectiveStepTimeout(t *testing.T) {
+	t.Parallel()
+
+	fallback := 30 * time.Second
+	tests := []struct {
+		step Step
+		want time.Duration
+	}{
+		{Step{Type: stepTypeRun}, fallback},
+		{Step{Type: stepTypeRun, Timeout: 5}, 5 * time.Second},
+		{Step{Type: stepTypeWait, Duration: 120}, 150 * time.Second},
+		{Step{Type: stepTypeWait, Duration: 120, Timeout: 10}, 10 * time.Second},
+		{Step{Type: stepTypeWait, Command: "true", Duration: 120}, fallback},
+	}
+	for _, tt := range tests {
+		if got := effectiveStepTimeout(tt.step, fallback); got != tt.want {
+			t.Errorf("effectiveStepTimeout(%+v) = %s, want %s", tt.step, got, tt.want)
+		}
+	}
+}
+
+type fakeHostTargets struct {
+	labels  map[string]string // host -> role
+	failing string
+	actions []string
+}
+
+func (f *fakeHostTargets) MatchHosts(_ context.Context, selector string) ([]string, error) {
+	sel, err := inventory.ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	var hosts []string
+	for host, role := range f.labels {
+		if sel.Matches(map[string]string{"role": role}) {
+			hosts = append(hosts, host)
+		}
+	}
+	slices.Sort(hosts)
+	return hosts, nil
+}
+
+func (f *fakeHostTargets) ServiceAction(_ context.Context, host string, action inventory.ServiceAction) error {
+	f.actions = append(f.actions, host+" "+action.Action+" "+action.Unit+" "+action.Scope+"/"+action.Manager)
+	if host == f.failing {
+		return errors.New("HTTP 500: unit action failed")
+	}
+	return nil
+}
+
+func TestExecuteServiceStep(t *testing.T) {
+	t.Parallel()
+
+	step := Step{Type: stepTypeService, Title: "restart", Hosts: "role={{ROLE}}", Unit: "nginx.service", Action: "Restart"}
+	targets := &fakeHostTargets{labels: map[string]string{"web-01": "web", "web-02": "web", "db-01": "db"}}
+	exec := NewExecutor(nil, time.Second, map[string]string{"ROLE": "web"})
+
+	if _, err := exec.Execute(context.Background(), []Step{step}, nil, nil); err == nil || !strings.Contains(err.Error(), "federation is not enabled") {
+		t.Fatalf("Execute(no targets) error = %v", err)
+	}
+
+	exec.SetHosts(targets, "")
+	results, err := exec.Execute(context.Background(), []Step{step}, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := []string{"web-01 restart nginx.service system/systemd", "web-02 restart nginx.service system/systemd"}
+	if !slices.Equal(targets.actions, want) {
+		t.Fatalf("actions = %q, want %q", targets.actions, want)
+	}
+	if results[0].Output != "web-01: restart nginx.service ok\nweb-02: restart nginx.service ok\n" {
+		t.Fatalf("output = %q", results[0].Output)
+	}
+
+	// The run's selector replaces the step's, and one failing host fails
+	// the step after the others ran.
+	targets.actions, targets.failing = nil, "db-01"
+	exec.SetHosts(targets, "role=db")
+	results, err = exec.Execute(context.Background(), []Step{step}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 hosts failed") {
+		t.Fatalf("Execute(failing host) error = %v", err)
+	}
+	if !strings.Contains(results[0].Output, "db-01: restart nginx.service failed: HTTP 500") {
+		t.Fatalf("output = %q", results[0].Output)
+	}
+
+	exec.SetHosts(targets, "role=cache")
+	if _, err := exec.Execute(context.Background(), []Step{step}, nil, nil); err == nil || !strings.Contains(err.Error(), `no connected hosts match "role=cache"`) {
+		t.Fatalf("Execute(no match) error = %v", err)
+	}
+}
@@ -3,10 +3,16 @@ package runbook
 import (
 	"fmt"
 	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/opus-domini/sentinel/internal/inventory"
+	"github.com/opus-domini/sentinel/internal/secrets"
 	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/validate"
 )
 
 const (
@@ -36,6 +42,21 @@ func ValidateDefinition(write store.OpsRunbookWrite) error {
 	return validateWebhookURL(write.WebhookURL)
 }
 
+// CheckUsers checks the runAsUser of every step against policy. A nil
+// policy allows no runAsUser.
+func CheckUsers(steps []store.OpsRunbookStep, policy UserPolicy) error {
+	for index, step := range steps {
+		name := strings.TrimSpace(step.RunAsUser)
+		if name == "" {
+			continue
+		}
+		if err := checkUser(policy, name); err != nil {
+			return fmt.Errorf("step %d: runAsUser %q is not allowed: %w", index, name, err)
+		}
+	}
+	return nil
+}
+
 func validateStep(index int, step store.OpsRunbookStep) error {
 	if strings.TrimSpace(step.Title) == "" {
 		return fmt.Errorf("step %d: title is required", index)
@@ -49,6 +70,12 @@ func validateStep(index int, step store.OpsRunbookStep) error {
 	if step.RetryDelay < 0 {
 		return fmt.Errorf("step %d: retryDelay must not be negative", index)
 	}
+	if err := validateRetryPolicy(index, step); err != nil {
+		return err
+	}
+	if err := validateCommandTarget(index, step); err != nil {
+		return err
+	}
 	switch step.Type {
 	case stepTypeRun:
 		if strings.TrimSpace(step.Command) == "" {
@@ -62,8 +89,137 @@ func validateStep(index int, step store.OpsRunbookStep) error {
 		if strings.TrimSpace(step.Description) == "" {
 			return fmt.Errorf("step %d: description is required for type approval", index)
 		}
+	case stepTypeHTTP:
+		return validateHTTPStep(index, step)
+	case stepTypeTmuxSend:
+		if strings.TrimSpace(step.Target) == "" {
+			return fmt.Errorf("step %d: target is required for type tmux.send", index)
+		}
+		if step.Keys == "" && !step.Enter {
+			return fmt.Errorf("step %d: keys or enter is required for type tmux.send", index)
+		}
+	case stepTypeTmuxExec:
+		return validateTmuxExecStep(index, step)
+	case stepTypeWait:
+		if step.Duration < 0 || step.Interval < 0 {
+			return fmt.Errorf("step %d: duration and interval must not be negative", index)
+		}
+		if step.Duration == 0 && strings.TrimSpace(step.Command) == "" {
+			return fmt.Errorf("step %d: duration or command is required for type wait", index)
+		}
+	case stepTypeService:
+		return validateServiceStep(index, step)
 	default:
-		return fmt.Errorf("step %d: type must be run, script, or approval", index)
+		return fmt.Errorf("step %d: type must be run, script, approval, http, tmux.send, tmux.exec, wait, or service", index)
+	}
+	return nil
+}
+
+func validateTmuxExecStep(index int, step store.OpsRunbookStep) error {
+	if !validate.SessionName(strings.TrimSpace(step.Session)) {
+		return fmt.Errorf("step %d: session must be a valid tmux session name for type tmux.exec", index)
+	}
+	if window := strings.TrimSpace(step.Window); window != "" && !validate.WindowName(window) {
+		return fmt.Errorf("step %d: window must be a valid tmux window name", index)
+	}
+	if strings.TrimSpace(step.Command) == "" {
+		return fmt.Errorf("step %d: command is required for type tmux.exec", index)
+	}
+	if step.Interval < 0 {
+		return fmt.Errorf("step %d: interval must not be negative", index)
+	}
+	if _, err := compileMarker(step.Marker); err != nil {
+		return fmt.Errorf("step %d: marker is not a valid regular expression: %w", index, err)
+	}
+	return nil
+}
+
+func validateServiceStep(index int, step store.OpsRunbookStep) error {
+	if strings.TrimSpace(step.Unit) == "" {
+		return fmt.Errorf("step %d: unit is required for type service", index)
+	}
+	if !slices.Contains(serviceStepActions, strings.ToLower(strings.TrimSpace(step.Action))) {
+		return fmt.Errorf("step %d: action must be one of %s", index, strings.Join(serviceStepActions, ", "))
+	}
+	if scope := strings.TrimSpace(step.Scope); scope != "" && !slices.Contains(serviceStepScopes, scope) {
+		return fmt.Errorf("step %d: scope must be user or system", index)
+	}
+	if manager := strings.TrimSpace(step.Manager); manager != "" && !slices.Contains(serviceStepManagers, manager) {
+		return fmt.Errorf("step %d: manager must be one of %s", index, strings.Join(serviceStepManagers, ", "))
+	}
+	// A placeholder selector is checked when the run renders it, and an
+	// empty one must come from the run or schedule.
+	if hosts := strings.TrimSpace(step.Hosts); hosts != "" && !strings.Contains(hosts, "{{") {
+		if _, err := inventory.ParseSelector(hosts); err != nil {
+			return fmt.Errorf("step %d: %w", index, err)
+		}
+	}
+	return nil
+}
+
+func validateRetryPolicy(index int, step store.OpsRunbookStep) error {
+	if step.RetryBackoff != 0 && (step.RetryBackoff < 1 || step.RetryBackoff > 10) {
+		return fmt.Errorf("step %d: retryBackoff must be between 1 and 10", index)
+	}
+	if step.RetryMaxDelay < 0 {
+		return fmt.Errorf("step %d: retryMaxDelay must not be negative", index)
+	}
+	if len(step.RetryOnExitCodes) > 0 && step.Type != stepTypeRun && step.Type != stepTypeScript {
+		return fmt.Errorf("step %d: retryOnExitCodes only applies to run and script steps", index)
+	}
+	for _, code := range step.RetryOnExitCodes {
+		if code < 1 || code > 255 {
+			return fmt.Errorf("step %d: retryOnExitCodes entries must be between 1 and 255", index)
+		}
+	}
+	return nil
+}
+
+// validateCommandTarget checks the cwd, runAsUser and shell of a step. A
+// placeholder in cwd is checked when the step starts.
+func validateCommandTarget(index int, step store.OpsRunbookStep) error {
+	cwd := strings.TrimSpace(step.Cwd)
+	runAsUser := strings.TrimSpace(step.RunAsUser)
+	shell := strings.TrimSpace(step.Shell)
+	if cwd == "" && runAsUser == "" && shell == "" {
+		return nil
+	}
+	if step.Type != stepTypeRun && step.Type != stepTypeScript && step.Type != stepTypeWait {
+		return fmt.Errorf("step %d: cwd, runAsUser and shell only apply to run, script and wait steps", index)
+	}
+	if cwd != "" && !strings.Contains(cwd, "{{") && !filepath.IsAbs(cwd) {
+		return fmt.Errorf("step %d: cwd must be an absolute path", index)
+	}
+	if runAsUser != "" && !userPattern.MatchString(runAsUser) {
+		return fmt.Errorf("step %d: runAsUser must be a valid user name", index)
+	}
+	if shell != "" && (!shellPattern.MatchString(shell) || strings.Contains(shell, "/") && !path.IsAbs(shell)) {
+		return fmt.Errorf("step %d: shell must be a program name or an absolute path", index)
+	}
+	return nil
+}
+
+func validateHTTPStep(index int, step store.OpsRunbookStep) error {
+	raw := strings.TrimSpace(step.URL)
+	if raw == "" {
+		return fmt.Errorf("step %d: url is required for type http", index)
+	}
+	// Placeholders may stand in for any part of the URL, so only the scheme
+	// is checked when parameters are used.
+	if !strings.Contains(raw, "{{") {
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Host == "" {
+			return fmt.Errorf("step %d: url is invalid", index)
+		}
+	}
+	if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+		return fmt.Errorf("step %d: url must use http or https", index)
+	}
+	if !slices.Contains(httpStepMethods, httpStepMethod(step.Method)) {
+		return fmt.Errorf("step %d: method must be one of %s", index, strings.Join(httpStepMethods, ", "))
+	}
+	if step.ExpectStatus != 0 && (step.ExpectStatus < 100 || step.ExpectStatus > 599) {
+		return fmt.Errorf("step %d: expectStatus must be between 100 and 599", index)
 	}
 	return nil
 }
@@ -140,7 +296,8 @@ func validateParameterValue(parameter store.RunbookParameter, value string) erro
 
 func validateWebhookURL(raw string) error {
 	raw = strings.TrimSpace(raw)
-	if raw == "" {
+	// A URL built from secrets is only known when the run finishes.
+	if raw == "" || secrets.HasRefs(raw) {
 		return nil
 	}
 	parsed, err := url.Parse(raw)
@@ -161,11 +318,20 @@ func validateWebhookURL(raw string) error {
 func ShellWarnings(steps []store.OpsRunbookStep) []ShellWarning {
 	inputs := make([]ShellCheckInput, 0, len(steps))
 	for index, step := range steps {
+		// Commands for another interpreter are not sh syntax.
+		if shell := strings.TrimSpace(step.Shell); shell != "" && !isPOSIXShell(shell) {
+			continue
+		}
 		switch step.Type {
 		case stepTypeRun:
 			inputs = append(inputs, ShellCheckInput{Step: index, Type: stepTypeRun, Source: step.Command})
 		case stepTypeScript:
 			inputs = append(inputs, ShellCheckInput{Step: index, Type: stepTypeScript, Source: step.Script})
+		case stepTypeWait:
+			// Wait conditions are shell commands, checked like run steps.
+			if strings.TrimSpace(step.Command) != "" {
+				inputs = append(inputs, ShellCheckInput{Step: index, Type: stepTypeRun, Source: step.Command})
+			}
 		}
 	}
 	return ValidateShellSyntaxFromStrings(inputs)
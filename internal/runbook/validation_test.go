@@ -21,6 +21,22 @@ func TestValidateDefinition(t *testing.T) {
 		t.Fatalf("ValidateDefinition(valid) error = %v", err)
 	}
 
+	extended := valid
+	extended.Steps = []store.OpsRunbookStep{
+		{Type: "http", Title: "health", URL: "https://{{ENV}}.example.test/healthz", Method: "get", ExpectStatus: 204},
+		{Type: "tmux.send", Title: "tail", Target: "ops:0.1", Keys: "tail -f log", Enter: true},
+		{Type: "tmux.exec", Title: "build", Session: "ops", Window: "build 1", Command: "make", Marker: "^(ok|done)$"},
+		{Type: "wait", Title: "settle", Duration: 5},
+		{Type: "wait", Title: "ready", Command: "test -f /tmp/ready", Interval: 1, Cwd: "/srv/{{ENV}}", Shell: "/bin/bash"},
+		{Type: "script", Title: "migrate", Script: "make migrate", Cwd: "/srv/app", RunAsUser: "deploy", Shell: "bash"},
+		{Type: "service", Title: "restart", Hosts: "role=web,env!=dev", Unit: "nginx.service", Action: "Restart"},
+		{Type: "service", Title: "stop", Hosts: "role={{ENV}}", Unit: "api", Action: "stop", Scope: "user", Manager: "docker"},
+		{Type: "service", Title: "start", Unit: "nginx.service", Action: "start"},
+	}
+	if err := ValidateDefinition(extended); err != nil {
+		t.Fatalf("ValidateDefinition(extended) error = %v", err)
+	}
+
 	tests := []struct {
 		name string
 		edit func(*store.OpsRunbookWrite)
@@ -31,6 +47,64 @@ func TestValidateDefinition(t *testing.T) {
 		{name: "duplicate parameter", edit: func(w *store.OpsRunbookWrite) { w.Parameters = append(w.Parameters, w.Parameters[0]) }, want: "duplicated"},
 		{name: "invalid default", edit: func(w *store.OpsRunbookWrite) { w.Parameters[0].Default = "unknown" }, want: "must be one of"},
 		{name: "invalid webhook", edit: func(w *store.OpsRunbookWrite) { w.WebhookURL = "file:///tmp/hook" }, want: "http or https"},
+		{name: "unknown step type", edit: func(w *store.OpsRunbookWrite) { w.Steps[0].Type = "ssh" }, want: "type must be"},
+		{name: "retry backoff", edit: func(w *store.OpsRunbookWrite) { w.Steps[0].RetryBackoff = 0.5 }, want: "retryBackoff"},
+		{name: "retry max delay", edit: func(w *store.OpsRunbookWrite) { w.Steps[0].RetryMaxDelay = -1 }, want: "retryMaxDelay"},
+		{name: "retry exit code", edit: func(w *store.OpsRunbookWrite) { w.Steps[0].RetryOnExitCodes = []int{0} }, want: "between 1 and 255"},
+		{name: "retry exit codes step type", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "wait", Title: "settle", Duration: 1, RetryOnExitCodes: []int{1}}
+		}, want: "only applies to run and script"},
+		{name: "cwd relative", edit: func(w *store.OpsRunbookWrite) { w.Steps[0].Cwd = "srv/app" }, want: "cwd must be an absolute path"},
+		{name: "run as user", edit: func(w *store.OpsRunbookWrite) { w.Steps[0].RunAsUser = "deploy;id" }, want: "runAsUser must be"},
+		{name: "shell arguments", edit: func(w *store.OpsRunbookWrite) { w.Steps[0].Shell = "bash -e" }, want: "shell must be"},
+		{name: "shell relative path", edit: func(w *store.OpsRunbookWrite) { w.Steps[0].Shell = "bin/bash" }, want: "shell must be"},
+		{name: "cwd step type", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "http", Title: "ping", URL: "https://example.test", Cwd: "/srv"}
+		}, want: "only apply to run, script and wait"},
+		{name: "http url", edit: func(w *store.OpsRunbookWrite) { w.Steps[0] = store.OpsRunbookStep{Type: "http", Title: "ping"} }, want: "url is required"},
+		{name: "http scheme", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "http", Title: "ping", URL: "ftp://example.test"}
+		}, want: "http or https"},
+		{name: "http method", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "http", Title: "ping", URL: "https://example.test", Method: "TRACE"}
+		}, want: "method must be"},
+		{name: "http status", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "http", Title: "ping", URL: "https://example.test", ExpectStatus: 42}
+		}, want: "expectStatus"},
+		{name: "tmux target", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "tmux.send", Title: "send", Keys: "ls"}
+		}, want: "target is required"},
+		{name: "tmux keys", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "tmux.send", Title: "send", Target: "dev"}
+		}, want: "keys or enter"},
+		{name: "tmux.exec session", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "tmux.exec", Title: "build", Session: "-d", Command: "make"}
+		}, want: "session must be"},
+		{name: "tmux.exec window", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "tmux.exec", Title: "build", Session: "ops", Window: "a:b", Command: "make"}
+		}, want: "window must be"},
+		{name: "tmux.exec command", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "tmux.exec", Title: "build", Session: "ops"}
+		}, want: "command is required"},
+		{name: "tmux.exec marker", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "tmux.exec", Title: "build", Session: "ops", Command: "make", Marker: "(done"}
+		}, want: "marker is not a valid"},
+		{name: "wait without condition", edit: func(w *store.OpsRunbookWrite) { w.Steps[0] = store.OpsRunbookStep{Type: "wait", Title: "pause"} }, want: "duration or command"},
+		{name: "wait negative", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "wait", Title: "pause", Duration: -1}
+		}, want: "must not be negative"},
+		{name: "service unit", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "service", Title: "restart", Hosts: "role=web", Action: "restart"}
+		}, want: "unit is required"},
+		{name: "service action", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "service", Title: "restart", Hosts: "role=web", Unit: "nginx", Action: "reload"}
+		}, want: "action must be one of"},
+		{name: "service manager", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "service", Title: "restart", Unit: "nginx", Action: "restart", Manager: "rc"}
+		}, want: "manager must be"},
+		{name: "service selector", edit: func(w *store.OpsRunbookWrite) {
+			w.Steps[0] = store.OpsRunbookStep{Type: "service", Title: "restart", Hosts: "role=web app", Unit: "nginx", Action: "restart"}
+		}, want: "host selector"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
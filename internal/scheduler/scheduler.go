@@ -10,13 +10,19 @@ import (
 	"time"
 
 	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/jobqueue"
 	"github.com/opus-domini/sentinel/internal/runbook"
+	"github.com/opus-domini/sentinel/internal/secrets"
 	"github.com/opus-domini/sentinel/internal/store"
 	"github.com/opus-domini/sentinel/internal/validate"
 )
 
 const keyJobID = "jobId"
 
+// statusSkipped is the last_run_status of a due run dropped because the
+// previous run was still in flight.
+const statusSkipped = "skipped"
+
 const (
 	defaultTickInterval  = 5 * time.Second
 	defaultMaxConcurrent = 5
@@ -27,16 +33,30 @@ const (
 type schedulerRepo interface {
 	ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]store.OpsSchedule, error)
 	CreateOpsRunbookRun(ctx context.Context, runbookID string, now time.Time) (store.OpsRunbookRun, error)
-	CreateOpsRunbookRunWithParams(ctx context.Context, runbookID string, now time.Time, params map[string]string) (store.OpsRunbookRun, error)
+	CreateOpsRunbookRunForHosts(ctx context.Context, runbookID string, now time.Time, params map[string]string, hosts string) (store.OpsRunbookRun, error)
 	UpdateScheduleAfterRun(ctx context.Context, scheduleID, lastRunAt, lastRunStatus, nextRunAt string, enabled bool) error
 	UpdateScheduleLastRun(ctx context.Context, scheduleID, lastRunAt, lastRunStatus string) error
+	InsertScheduleRun(ctx context.Context, scheduleID, jobID string, firedAt time.Time, outcome string) (int64, error)
+	FinishScheduleRun(ctx context.Context, id int64, finishedAt time.Time, outcome string) error
 }
 
 // Options configures the scheduler service.
 type Options struct {
-	TickInterval  time.Duration
+	TickInterval time.Duration
+	// MaxConcurrent bounds the scheduler's own job queue when Queue is nil.
 	MaxConcurrent int
-	EventHub      *events.Hub
+	// Queue runs the scheduled executions alongside manual runs; the
+	// caller shuts it down.
+	Queue    *jobqueue.Queue
+	EventHub *events.Hub
+	// Hosts runs service steps on federated hosts; nil when federation is
+	// disabled.
+	Hosts runbook.HostTargets
+	// Secrets resolves {{secret "name"}} references in scheduled runs.
+	Secrets secrets.LookupFunc
+	// UserPolicy decides which users the steps of scheduled runs may run
+	// as.
+	UserPolicy runbook.UserPolicy
 }
 
 // Service runs scheduled runbook executions on a tick loop.
@@ -53,19 +73,31 @@ type Service struct {
 	// Cancelled on Stop to signal in-flight runs.
 	runCtx    context.Context
 	runCancel context.CancelFunc
-	sem       chan struct{}
 	wg        sync.WaitGroup
 
-	// inFlight guards against overlapping runs of the same schedule: a schedule
-	// stays claimed for the lifetime of its run, so a tick that sees it still
-	// due (cron interval shorter than the run) skips it instead of double-firing.
+	// queue runs the executions; ownQueue is set when the scheduler
+	// created it. batch caps the due schedules dispatched per tick.
+	queue    *jobqueue.Queue
+	ownQueue bool
+	batch    int
+
+	// inFlight tracks the runs of each schedule for their lifetime, so a tick
+	// that sees a schedule due again (cron interval shorter than the run)
+	// applies its concurrency policy instead of blindly double-firing.
 	// stopping (under the same lock) makes wg.Add and Stop's wg.Wait mutually
 	// exclusive, so a tick cannot register a new run after Stop began waiting.
 	inFlightMu sync.Mutex
-	inFlight   map[string]struct{}
+	inFlight   map[string]map[*scheduleRun]struct{}
 	stopping   bool
 }
 
+// scheduleRun is one in-flight run of a schedule. Its context is cancelled
+// when a replace-policy run supersedes it.
+type scheduleRun struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
 // beginRun registers a run goroutine with the wait group unless the scheduler
 // is stopping. It must wrap the matching wg.Done in the spawned goroutine.
 func (s *Service) beginRun() bool {
@@ -88,33 +120,60 @@ func New(r schedulerRepo, rr runbook.Repo, opts Options) *Service {
 		maxConc = defaultMaxConcurrent
 	}
 	runCtx, runCancel := context.WithCancel(context.Background())
-	return &Service{
+	s := &Service{
 		repo:        r,
 		runbookRepo: rr,
 		opts:        opts,
-		sem:         make(chan struct{}, maxConc),
+		queue:       opts.Queue,
+		batch:       maxConc,
 		runCtx:      runCtx,
 		runCancel:   runCancel,
-		inFlight:    make(map[string]struct{}),
+		inFlight:    make(map[string]map[*scheduleRun]struct{}),
 	}
+	if s.queue == nil {
+		s.queue = jobqueue.New(maxConc, -1)
+		s.ownQueue = true
+	}
+	return s
 }
 
-// claimSchedule marks a schedule as running. It returns false when a run for
-// the same schedule is already in flight.
-func (s *Service) claimSchedule(id string) bool {
+// claimSchedule registers a new run for a schedule according to its
+// concurrency policy. It returns nil when the policy forbids overlap and a
+// run is already in flight; under the replace policy the in-flight runs are
+// cancelled first.
+func (s *Service) claimSchedule(id, policy string) *scheduleRun {
 	s.inFlightMu.Lock()
 	defer s.inFlightMu.Unlock()
-	if _, ok := s.inFlight[id]; ok {
-		return false
+	runs := s.inFlight[id]
+	if len(runs) > 0 {
+		switch policy {
+		case store.ScheduleConcurrencyAllow:
+		case store.ScheduleConcurrencyReplace:
+			for run := range runs {
+				run.cancel()
+			}
+		default:
+			return nil
+		}
 	}
-	s.inFlight[id] = struct{}{}
-	return true
+	if runs == nil {
+		runs = make(map[*scheduleRun]struct{})
+		s.inFlight[id] = runs
+	}
+	ctx, cancel := context.WithCancel(s.runCtx)
+	run := &scheduleRun{ctx: ctx, cancel: cancel}
+	runs[run] = struct{}{}
+	return run
 }
 
-// releaseSchedule clears the in-flight marker for a schedule.
-func (s *Service) releaseSchedule(id string) {
+// releaseSchedule clears the in-flight marker for a schedule run.
+func (s *Service) releaseSchedule(id string, run *scheduleRun) {
+	run.cancel()
 	s.inFlightMu.Lock()
-	delete(s.inFlight, id)
+	delete(s.inFlight[id], run)
+	if len(s.inFlight[id]) == 0 {
+		delete(s.inFlight, id)
+	}
 	s.inFlightMu.Unlock()
 }
 
@@ -164,6 +223,9 @@ func (s *Service) Stop(ctx context.Context) {
 		if s.runCancel != nil {
 			s.runCancel()
 		}
+		if s.ownQueue {
+			defer s.queue.Shutdown(ctx)
+		}
 		// Reject any further run registrations so wg.Add cannot race wg.Wait.
 		s.inFlightMu.Lock()
 		s.stopping = true
@@ -191,8 +253,7 @@ func (s *Service) Stop(ctx context.Context) {
 
 func (s *Service) tick(ctx context.Context) {
 	now := time.Now().UTC()
-	maxConc := cap(s.sem)
-	due, err := s.repo.ListDueSchedules(ctx, now, maxConc)
+	due, err := s.repo.ListDueSchedules(ctx, now, s.batch)
 	if err != nil {
 		slog.Warn("scheduler list due schedules failed", "err", err)
 		return
@@ -208,9 +269,11 @@ func (s *Service) tick(ctx context.Context) {
 }
 
 func (s *Service) executeDueSchedule(ctx context.Context, sched store.OpsSchedule, now time.Time) {
-	if !s.claimSchedule(sched.ID) {
+	run := s.claimSchedule(sched.ID, sched.Concurrency)
+	if run == nil {
 		// A previous run for this schedule is still in flight; skip to avoid
 		// overlapping runs of a non-idempotent runbook (restart/deploy/cleanup).
+		s.skipOverlappingRun(ctx, sched, now)
 		return
 	}
 
@@ -219,7 +282,7 @@ func (s *Service) executeDueSchedule(ctx context.Context, sched store.OpsSchedul
 	// raw placeholders before).
 	rb, rbErr := s.runbookRepo.GetOpsRunbook(ctx, sched.RunbookID)
 	if rbErr != nil {
-		s.releaseSchedule(sched.ID)
+		s.releaseSchedule(sched.ID, run)
 		if errors.Is(rbErr, sql.ErrNoRows) {
 			slog.Warn("scheduler auto-heal: disabling orphan schedule", "schedule", sched.ID, "runbook", sched.RunbookID)
 			if healErr := s.repo.UpdateScheduleAfterRun(ctx, sched.ID, "", "", "", false); healErr != nil {
@@ -234,30 +297,43 @@ func (s *Service) executeDueSchedule(ctx context.Context, sched store.OpsSchedul
 	if err := runbook.ValidateParams(rb.Parameters, params); err != nil {
 		// A required parameter has no default; running with placeholders would be
 		// worse than skipping. Surface it instead of executing.
-		s.releaseSchedule(sched.ID)
+		s.releaseSchedule(sched.ID, run)
 		slog.Warn("scheduler skipping run: unmet required parameters", "schedule", sched.ID, "runbook", sched.RunbookID, "err", err)
 		return
 	}
 
+	// Claim room in the job queue before touching the schedule. A full
+	// queue leaves the schedule due, so it is retried on the next tick.
+	slot, err := s.queue.Reserve()
+	if err != nil {
+		s.releaseSchedule(sched.ID, run)
+		if !errors.Is(err, jobqueue.ErrClosed) {
+			slog.Warn("scheduler job queue full, retrying next tick", "schedule", sched.ID, "err", err)
+		}
+		return
+	}
+	defer slot.Release()
+
 	// Advance next_run_at (and mark running) BEFORE creating the run so a crash
 	// between the two can't leave the schedule still 'due' and re-fire a
 	// duplicate run on restart. If the create below fails the schedule simply
 	// skips this cycle, which is safer than a double run.
 	nextRunAt, enabled := s.computeNextRun(sched)
 	if err := s.repo.UpdateScheduleAfterRun(ctx, sched.ID, now.Format(time.RFC3339), "running", nextRunAt, enabled); err != nil {
-		s.releaseSchedule(sched.ID)
+		s.releaseSchedule(sched.ID, run)
 		slog.Warn("scheduler advance schedule failed", "schedule", sched.ID, "err", err)
 		return
 	}
 
-	job, err := s.repo.CreateOpsRunbookRunWithParams(ctx, sched.RunbookID, now, params)
+	job, err := s.repo.CreateOpsRunbookRunForHosts(ctx, sched.RunbookID, now, params, sched.Hosts)
 	if err != nil {
-		s.releaseSchedule(sched.ID)
+		s.releaseSchedule(sched.ID, run)
 		slog.Warn("scheduler create run failed", "schedule", sched.ID, "runbook", sched.RunbookID, "err", err)
 		return
 	}
 
 	slog.Info("scheduler triggered run", "schedule", sched.ID, "runbook", sched.RunbookID, "job", job.ID)
+	historyID := s.recordRun(ctx, sched.ID, job.ID, now, store.ScheduleRunRunning)
 
 	s.publish(events.TypeScheduleUpdated, map[string]any{
 		"action":   "triggered",
@@ -266,29 +342,72 @@ func (s *Service) executeDueSchedule(ctx context.Context, sched store.OpsSchedul
 	})
 
 	if !s.beginRun() {
-		s.releaseSchedule(sched.ID)
+		s.releaseSchedule(sched.ID, run)
 		return
 	}
-	go func() {
+	// Scheduled runs give way to manual runs waiting for a worker. A run
+	// canceled while waiting finishes as canceled without starting.
+	slot.Submit(run.ctx, jobqueue.Job{ID: job.ID, Priority: jobqueue.PriorityLow, Run: func(ctx context.Context) {
 		defer s.wg.Done()
-		defer s.releaseSchedule(sched.ID)
-		// Acquire semaphore (backpressure).
-		select {
-		case s.sem <- struct{}{}:
-			defer func() { <-s.sem }()
-		case <-s.runCtx.Done():
-			return
-		}
-		s.executeRunbook(s.runCtx, job, sched.ID, params)
-	}()
+		defer s.releaseSchedule(sched.ID, run)
+		s.executeRunbook(ctx, job, sched.ID, historyID, params)
+	}})
+}
+
+// skipOverlappingRun records a due run dropped by the forbid policy: the
+// schedule advances as if it had run, so it is not retried on every tick
+// until the in-flight run finishes.
+func (s *Service) skipOverlappingRun(ctx context.Context, sched store.OpsSchedule, now time.Time) {
+	nextRunAt, enabled := s.computeNextRun(sched)
+	if err := s.repo.UpdateScheduleAfterRun(ctx, sched.ID, now.Format(time.RFC3339), statusSkipped, nextRunAt, enabled); err != nil {
+		slog.Warn("scheduler record skipped run failed", "schedule", sched.ID, "err", err)
+		return
+	}
+	slog.Info("scheduler skipped overlapping run", "schedule", sched.ID, "runbook", sched.RunbookID, "next_run_at", nextRunAt)
+	s.recordRun(ctx, sched.ID, "", now, statusSkipped)
+	s.publish(events.TypeScheduleUpdated, map[string]any{
+		"action":   "skipped",
+		"schedule": sched.ID,
+		"reason":   "overlap",
+	})
 }
 
-func (s *Service) executeRunbook(ctx context.Context, job store.OpsRunbookRun, scheduleID string, params map[string]string) {
-	runbook.Run(ctx, s.runbookRepo, s.emitEvent, runbook.RunParams{
+// recordRun adds a fired run to the schedule history and returns its ID,
+// or 0 when it could not be stored. History is best effort: a failed write
+// never blocks the run itself.
+func (s *Service) recordRun(ctx context.Context, scheduleID, jobID string, firedAt time.Time, outcome string) int64 {
+	id, err := s.repo.InsertScheduleRun(ctx, scheduleID, jobID, firedAt, outcome)
+	if err != nil {
+		slog.Warn("scheduler record run history failed", "schedule", scheduleID, "err", err)
+		return 0
+	}
+	return id
+}
+
+// finishRun stores the outcome of a run recorded by recordRun.
+func (s *Service) finishRun(ctx context.Context, historyID int64, outcome string) {
+	if historyID == 0 {
+		return
+	}
+	if err := s.repo.FinishScheduleRun(ctx, historyID, time.Now().UTC(), outcome); err != nil {
+		slog.Warn("scheduler finish run history failed", "run", historyID, "err", err)
+	}
+}
+
+func (s *Service) executeRunbook(ctx context.Context, job store.OpsRunbookRun, scheduleID string, historyID int64, params map[string]string) {
+	run := runbook.Run
+	if ctx.Err() != nil {
+		// Canceled while waiting for a worker.
+		run = runbook.CancelQueued
+	}
+	run(ctx, s.runbookRepo, s.emitEvent, runbook.RunParams{
 		Job:         job,
 		Source:      "scheduler",
 		StepTimeout: stepTimeout,
 		Parameters:  params,
+		Hosts:       s.opts.Hosts,
+		Secrets:     s.opts.Secrets,
+		UserPolicy:  s.opts.UserPolicy,
 		OnFinish: func(ctx context.Context, status string) {
 			finished := time.Now().UTC()
 			// Update only last_run_*; next_run_at/enabled were set at dispatch and
@@ -296,6 +415,7 @@ func (s *Service) executeRunbook(ctx context.Context, job store.OpsRunbookRun, s
 			if err := s.repo.UpdateScheduleLastRun(ctx, scheduleID, finished.Format(time.RFC3339), status); err != nil {
 				slog.Warn("scheduler: update schedule after run", "err", err)
 			}
+			s.finishRun(ctx, historyID, status)
 			s.publish(events.TypeScheduleUpdated, map[string]any{
 				"action":   "run_completed",
 				"schedule": scheduleID,
@@ -315,7 +435,26 @@ func (s *Service) computeNextRun(sched store.OpsSchedule) (string, bool) {
 		return "", false
 	}
 
-	// type="cron": compute next run time.
+	next, err := nextRun(sched, time.Now())
+	if err != nil {
+		slog.Warn("scheduler invalid schedule", "schedule", sched.ID, "type", sched.ScheduleType, "err", err)
+		return "", false
+	}
+	return next, true
+}
+
+// nextRun computes the next activation after now for a recurring schedule:
+// cron expressions are evaluated in the schedule timezone (UTC when
+// invalid), intervals are counted from now.
+func nextRun(sched store.OpsSchedule, now time.Time) (string, error) {
+	if sched.ScheduleType == "interval" {
+		interval, err := validate.ParseInterval(sched.Interval, sched.Jitter)
+		if err != nil {
+			return "", err
+		}
+		return interval.Next(now).UTC().Format(time.RFC3339), nil
+	}
+
 	loc, err := time.LoadLocation(sched.Timezone)
 	if err != nil {
 		slog.Warn("scheduler invalid timezone, using UTC", "schedule", sched.ID, "timezone", sched.Timezone)
@@ -323,17 +462,14 @@ func (s *Service) computeNextRun(sched store.OpsSchedule) (string, bool) {
 	}
 	cronSched, err := validate.ParseCron(sched.CronExpr)
 	if err != nil {
-		slog.Warn("scheduler invalid cron expression", "schedule", sched.ID, "expr", sched.CronExpr, "err", err)
-		return "", false
+		return "", err
 	}
-	nextRun := cronSched.Next(time.Now().In(loc)).UTC().Format(time.RFC3339)
-	return nextRun, true
+	return cronSched.Next(now.In(loc)).UTC().Format(time.RFC3339), nil
 }
 
 func (s *Service) catchUpMissedRuns(ctx context.Context) {
 	now := time.Now().UTC()
-	maxConc := cap(s.sem)
-	due, err := s.repo.ListDueSchedules(ctx, now, maxConc)
+	due, err := s.repo.ListDueSchedules(ctx, now, s.batch)
 	if err != nil {
 		slog.Warn("scheduler catch-up list failed", "err", err)
 		return
@@ -364,17 +500,12 @@ func (s *Service) recomputeNextRun(ctx context.Context, sched store.OpsSchedule)
 		return
 	}
 
-	loc, err := time.LoadLocation(sched.Timezone)
-	if err != nil {
-		loc = time.UTC
-	}
-	cronSched, err := validate.ParseCron(sched.CronExpr)
+	next, err := nextRun(sched, time.Now())
 	if err != nil {
 		slog.Warn("scheduler recompute failed", "schedule", sched.ID, "err", err)
 		return
 	}
-	nextRun := cronSched.Next(time.Now().In(loc)).UTC().Format(time.RFC3339)
-	if err := s.repo.UpdateScheduleAfterRun(ctx, sched.ID, sched.LastRunAt, sched.LastRunStatus, nextRun, true); err != nil {
+	if err := s.repo.UpdateScheduleAfterRun(ctx, sched.ID, sched.LastRunAt, sched.LastRunStatus, next, true); err != nil {
 		slog.Warn("scheduler: recompute next run", "schedule", sched.ID, "err", err)
 	}
 }
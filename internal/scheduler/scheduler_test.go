@@ -98,6 +98,42 @@ func TestComputeNextRun_OnceDisables(t *testing.T) {
 	}
 }
 
+func TestComputeNextRun_IntervalAdvances(t *testing.T) {
+	t.Parallel()
+	st := testStore(t)
+	svc := New(st, st, Options{})
+
+	sched := store.OpsSchedule{
+		ScheduleType: "interval",
+		Interval:     "15m",
+		Jitter:       "30s",
+	}
+
+	before := time.Now().UTC().Truncate(time.Second)
+	nextRun, enabled := svc.computeNextRun(sched)
+	if !enabled {
+		t.Fatal("expected enabled=true for interval schedule")
+	}
+	parsed, err := time.Parse(time.RFC3339, nextRun)
+	if err != nil {
+		t.Fatalf("nextRun is not valid RFC3339: %v", err)
+	}
+	if parsed.Before(before.Add(15*time.Minute)) || parsed.After(before.Add(16*time.Minute)) {
+		t.Fatalf("nextRun = %v, want 15m-15m30s from now", parsed)
+	}
+}
+
+func TestComputeNextRun_InvalidIntervalDisables(t *testing.T) {
+	t.Parallel()
+	st := testStore(t)
+	svc := New(st, st, Options{})
+
+	nextRun, enabled := svc.computeNextRun(store.OpsSchedule{ScheduleType: "interval", Interval: "soon"})
+	if enabled || nextRun != "" {
+		t.Fatalf("computeNextRun(invalid interval) = %q, %v; want disabled", nextRun, enabled)
+	}
+}
+
 func TestComputeNextRun_InvalidCron(t *testing.T) {
 	t.Parallel()
 	st := testStore(t)
@@ -170,7 +206,7 @@ func (r *failingScheduleUpdateRepo) CreateOpsRunbookRun(context.Context, string,
 	return store.OpsRunbookRun{}, nil
 }
 
-func (r *failingScheduleUpdateRepo) CreateOpsRunbookRunWithParams(context.Context, string, time.Time, map[string]string) (store.OpsRunbookRun, error) {
+func (r *failingScheduleUpdateRepo) CreateOpsRunbookRunForHosts(context.Context, string, time.Time, map[string]string, string) (store.OpsRunbookRun, error) {
 	return store.OpsRunbookRun{}, nil
 }
 
@@ -184,6 +220,14 @@ func (r *failingScheduleUpdateRepo) UpdateScheduleLastRun(context.Context, strin
 	return errors.New("update failed")
 }
 
+func (r *failingScheduleUpdateRepo) InsertScheduleRun(context.Context, string, string, time.Time, string) (int64, error) {
+	return 0, errors.New("insert failed")
+}
+
+func (r *failingScheduleUpdateRepo) FinishScheduleRun(context.Context, int64, time.Time, string) error {
+	return errors.New("update failed")
+}
+
 type schedulerRunbookRepo struct{}
 
 func (schedulerRunbookRepo) UpdateOpsRunbookRun(_ context.Context, update store.OpsRunbookRunUpdate) (store.OpsRunbookRun, error) {
@@ -211,7 +255,7 @@ func TestExecuteRunbookHandlesScheduleUpdateError(t *testing.T) {
 	svc.executeRunbook(context.Background(), store.OpsRunbookRun{
 		ID:        "job-1",
 		RunbookID: "runbook-1",
-	}, "schedule-1", nil)
+	}, "schedule-1", 0, nil)
 
 	if repo.updateCalls != 1 {
 		t.Fatalf("UpdateScheduleLastRun calls = %d, want 1", repo.updateCalls)
@@ -243,6 +287,7 @@ func TestTick_DueScheduleCreatesRun(t *testing.T) {
 		ScheduleType: "cron",
 		CronExpr:     "*/5 * * * *",
 		Timezone:     "UTC",
+		Hosts:        "role=web",
 		Enabled:      true,
 		NextRunAt:    past.Format(time.RFC3339),
 	})
@@ -263,17 +308,17 @@ func TestTick_DueScheduleCreatesRun(t *testing.T) {
 	if runs[0].RunbookID != rb.ID {
 		t.Fatalf("run runbook ID = %q, want %q", runs[0].RunbookID, rb.ID)
 	}
+	if runs[0].Hosts != "role=web" {
+		t.Fatalf("run hosts = %q, want the schedule's selector", runs[0].Hosts)
+	}
 
 	// Wait for the async goroutine to complete so the store can close cleanly.
 	time.Sleep(300 * time.Millisecond)
 }
 
-func TestTick_SkipsScheduleAlreadyInFlight(t *testing.T) {
-	t.Parallel()
-	st := testStore(t)
-	svc := New(st, st, Options{EventHub: events.NewHub()})
+func insertOverlapSchedule(t *testing.T, st *store.Store, policy string) store.OpsSchedule {
+	t.Helper()
 	ctx := context.Background()
-
 	rb, err := st.InsertOpsRunbook(ctx, store.OpsRunbookWrite{Name: "overlap-test", Enabled: true})
 	if err != nil {
 		t.Fatal(err)
@@ -285,16 +330,27 @@ func TestTick_SkipsScheduleAlreadyInFlight(t *testing.T) {
 		ScheduleType: "cron",
 		CronExpr:     "*/5 * * * *",
 		Timezone:     "UTC",
+		Concurrency:  policy,
 		Enabled:      true,
 		NextRunAt:    past.Format(time.RFC3339),
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
+	return sched
+}
+
+func TestTick_SkipsScheduleAlreadyInFlight(t *testing.T) {
+	t.Parallel()
+	st := testStore(t)
+	svc := New(st, st, Options{EventHub: events.NewHub()})
+	ctx := context.Background()
+	sched := insertOverlapSchedule(t, st, store.ScheduleConcurrencyForbid)
 
 	// Simulate a run still in flight for this schedule (e.g. cron interval
 	// shorter than the run). A tick must not create a second, overlapping run.
-	if !svc.claimSchedule(sched.ID) {
+	run := svc.claimSchedule(sched.ID, sched.Concurrency)
+	if run == nil {
 		t.Fatal("first claim should succeed")
 	}
 	svc.tick(ctx)
@@ -306,17 +362,86 @@ func TestTick_SkipsScheduleAlreadyInFlight(t *testing.T) {
 		t.Fatalf("expected no run while the schedule is in flight, got %d", len(runs))
 	}
 
-	// After the in-flight run finishes, the next tick may trigger again.
-	svc.releaseSchedule(sched.ID)
+	// The skip is recorded and the schedule advances instead of retrying on
+	// every tick.
+	schedules, err := st.ListOpsSchedules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedules[0].LastRunStatus != statusSkipped {
+		t.Fatalf("lastRunStatus = %q, want %q", schedules[0].LastRunStatus, statusSkipped)
+	}
+	if schedules[0].NextRunAt <= sched.NextRunAt {
+		t.Fatalf("nextRunAt = %q, want after %q", schedules[0].NextRunAt, sched.NextRunAt)
+	}
+	if run.ctx.Err() != nil {
+		t.Fatal("forbid policy must not cancel the in-flight run")
+	}
+	history, err := st.ListScheduleRuns(ctx, sched.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Outcome != statusSkipped || history[0].JobID != "" {
+		t.Fatalf("history = %+v, want one skipped run", history)
+	}
+
+	svc.releaseSchedule(sched.ID, run)
+	if next := svc.claimSchedule(sched.ID, sched.Concurrency); next == nil {
+		t.Fatal("claim after release should succeed")
+	}
+}
+
+func TestTick_AllowPolicyOverlapsInFlightRun(t *testing.T) {
+	t.Parallel()
+	st := testStore(t)
+	svc := New(st, st, Options{EventHub: events.NewHub()})
+	ctx := context.Background()
+	sched := insertOverlapSchedule(t, st, store.ScheduleConcurrencyAllow)
+
+	run := svc.claimSchedule(sched.ID, sched.Concurrency)
+	if run == nil {
+		t.Fatal("first claim should succeed")
+	}
+	svc.tick(ctx)
+	runs, err := st.ListOpsRunbookRuns(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected one overlapping run, got %d", len(runs))
+	}
+	if run.ctx.Err() != nil {
+		t.Fatal("allow policy must not cancel the in-flight run")
+	}
+
+	svc.releaseSchedule(sched.ID, run)
+	time.Sleep(300 * time.Millisecond)
+}
+
+func TestTick_ReplacePolicyCancelsInFlightRun(t *testing.T) {
+	t.Parallel()
+	st := testStore(t)
+	svc := New(st, st, Options{EventHub: events.NewHub()})
+	ctx := context.Background()
+	sched := insertOverlapSchedule(t, st, store.ScheduleConcurrencyReplace)
+
+	run := svc.claimSchedule(sched.ID, sched.Concurrency)
+	if run == nil {
+		t.Fatal("first claim should succeed")
+	}
 	svc.tick(ctx)
-	runs, err = st.ListOpsRunbookRuns(ctx, 10)
+	if run.ctx.Err() == nil {
+		t.Fatal("replace policy should cancel the in-flight run")
+	}
+	runs, err := st.ListOpsRunbookRuns(ctx, 10)
 	if err != nil {
 		t.Fatal(err)
 	}
 	if len(runs) != 1 {
-		t.Fatalf("expected exactly one run after release, got %d", len(runs))
+		t.Fatalf("expected the replacement run, got %d", len(runs))
 	}
 
+	svc.releaseSchedule(sched.ID, run)
 	time.Sleep(300 * time.Millisecond)
 }
 
@@ -624,6 +749,17 @@ func TestCronRecurrence_AfterRunCompletion(t *testing.T) {
 	if found.LastRunStatus == "running" {
 		t.Fatal("last_run_status should be terminal (not 'running') after completion")
 	}
+
+	history, err := st.ListScheduleRuns(ctx, sched.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("history = %+v, want one run", history)
+	}
+	if history[0].JobID == "" || history[0].Outcome != found.LastRunStatus || history[0].FinishedAt == "" {
+		t.Fatalf("history run = %+v, want finished with status %q", history[0], found.LastRunStatus)
+	}
 }
 
 func TestOnceSchedule_DisabledAfterRunCompletion(t *testing.T) {
@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Keyring entry holding the hex-encoded master key.
+const (
+	keyringService = "sentinel"
+	keyringAccount = "secrets-master-key"
+	keyringLabel   = "Sentinel secrets master key"
+)
+
+// LoadKeyFile reads the hex-encoded master key at path, creating the file
+// with a random key (mode 0600) when it does not exist yet.
+func LoadKeyFile(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path) //nolint:gosec // G304: path comes from the operator's config
+	if errors.Is(err, fs.ErrNotExist) {
+		return createKeyFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read master key: %w", err)
+	}
+	return decodeKey(raw)
+}
+
+func createKeyFile(path string) ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create master key directory: %w", err)
+	}
+	// O_EXCL: if another process won the race, use its key.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) //nolint:gosec // G304: path comes from the operator's config
+	if errors.Is(err, fs.ErrExist) {
+		return LoadKeyFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create master key: %w", err)
+	}
+	if _, err := f.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("write master key: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("write master key: %w", err)
+	}
+	return key, nil
+}
+
+// LoadKeyring reads the master key from the OS keyring, storing a random
+// one on first use. It uses secret-tool (libsecret) on Linux and the BSDs
+// and security(1) on macOS.
+func LoadKeyring(ctx context.Context) ([]byte, error) {
+	var lookup, store *exec.Cmd
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	encoded := hex.EncodeToString(key)
+	switch runtime.GOOS {
+	case "darwin":
+		lookup = exec.CommandContext(ctx, "security", "find-generic-password", "-s", keyringService, "-a", keyringAccount, "-w")
+		// A trailing -w makes security prompt for the password, which keeps the
+		// key out of the process list. It asks twice, to confirm.
+		store = exec.CommandContext(ctx, "security", "add-generic-password", "-s", keyringService, "-a", keyringAccount, "-l", keyringLabel, "-w")
+		store.Stdin = strings.NewReader(encoded + "\n" + encoded + "\n")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		lookup = exec.CommandContext(ctx, "secret-tool", "lookup", "service", keyringService, "account", keyringAccount)
+		store = exec.CommandContext(ctx, "secret-tool", "store", "--label", keyringLabel, "service", keyringService, "account", keyringAccount)
+		store.Stdin = strings.NewReader(encoded)
+	default:
+		return nil, fmt.Errorf("no OS keyring support on %s", runtime.GOOS)
+	}
+
+	if out, err := lookup.Output(); err == nil && len(bytes.TrimSpace(out)) > 0 {
+		return decodeKey(out)
+	} else if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("read master key from keyring: %w", err)
+	}
+	// Not found: store a fresh key.
+	if out, err := store.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("store master key in keyring: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return key, nil
+}
+
+func decodeKey(raw []byte) ([]byte, error) {
+	key, err := hex.DecodeString(string(bytes.TrimSpace(raw)))
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("master key must be %d hex-encoded bytes", KeySize)
+	}
+	return key, nil
+}
@@ -0,0 +1,71 @@
+package secrets
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// refPattern matches {{secret "name"}}, tolerating inner spaces.
+var refPattern = regexp.MustCompile(`\{\{\s*secret\s+"([^"]*)"\s*\}\}`)
+
+// redacted replaces secret values in output.
+const redacted = "[secret]"
+
+// HasRefs reports whether text references a secret.
+func HasRefs(text string) bool {
+	return strings.Contains(text, "{{") && refPattern.MatchString(text)
+}
+
+// Expand replaces every {{secret "name"}} in text with the secret's value,
+// passed through quote when it is non-nil (e.g. shell escaping). It also
+// returns the values it inserted so callers can redact them from output.
+// Text without references is returned as is, even when lookup is nil.
+func Expand(ctx context.Context, lookup LookupFunc, text string, quote func(string) string) (string, []string, error) {
+	matches := refPattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text, nil, nil
+	}
+	if lookup == nil {
+		return "", nil, ErrUnavailable
+	}
+	var (
+		b      strings.Builder
+		values []string
+		last   int
+	)
+	for _, m := range matches {
+		value, err := lookup(ctx, text[m[2]:m[3]])
+		if err != nil {
+			return "", nil, err
+		}
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+		if quote != nil {
+			value = quote(value)
+		}
+		b.WriteString(text[last:m[0]])
+		b.WriteString(value)
+		last = m[1]
+	}
+	b.WriteString(text[last:])
+	return b.String(), values, nil
+}
+
+// Redact masks every occurrence of values in text.
+func Redact(text string, values []string) string {
+	if text == "" || len(values) == 0 {
+		return text
+	}
+	// Longest first, so a value containing another is masked whole.
+	sorted := slices.Clone(values)
+	slices.SortFunc(sorted, func(a, b string) int { return len(b) - len(a) })
+	for _, value := range sorted {
+		if value != "" {
+			text = strings.ReplaceAll(text, value, redacted)
+		}
+	}
+	return text
+}
@@ -0,0 +1,167 @@
+// Package secrets keeps named credentials encrypted in the store and
+// resolves the {{secret "name"}} references that runbook steps and
+// notification settings use instead of plain tokens.
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+// KeySize is the length of the master key in bytes (AES-256).
+const KeySize = 32
+
+// maxValueBytes bounds a secret value; credentials are short.
+const maxValueBytes = 64 * 1024
+
+var (
+	// ErrUnavailable is returned when no vault is configured, typically
+	// because the master key could not be loaded.
+	ErrUnavailable = errors.New("secrets vault is unavailable")
+	// ErrInvalidSecret is returned for a malformed name or value.
+	ErrInvalidSecret = errors.New("invalid secret")
+	// ErrNotFound is returned when a referenced secret does not exist.
+	ErrNotFound = errors.New("secret not found")
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// LookupFunc returns the plaintext value of a named secret.
+type LookupFunc func(ctx context.Context, name string) (string, error)
+
+// Repo is the persistence the vault needs.
+type Repo interface {
+	ListOpsSecrets(ctx context.Context) ([]store.OpsSecret, error)
+	GetOpsSecret(ctx context.Context, name string) (store.OpsSecret, error)
+	CreateOpsSecret(ctx context.Context, name string, value []byte) (store.OpsSecret, error)
+	UpdateOpsSecret(ctx context.Context, name string, value []byte) (store.OpsSecret, error)
+	DeleteOpsSecret(ctx context.Context, name string) error
+}
+
+// Vault seals secret values with AES-256-GCM before they reach the store.
+// The secret name is bound to its value as additional data, so sealed
+// values cannot be swapped between rows. A nil *Vault is unavailable.
+type Vault struct {
+	repo Repo
+	aead cipher.AEAD
+}
+
+// New creates a vault over repo using a KeySize-byte master key.
+func New(repo Repo, key []byte) (*Vault, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{repo: repo, aead: aead}, nil
+}
+
+// ValidateName reports whether name can name a secret: letters, digits,
+// '_', '.' and '-', starting with a letter or digit, at most 64 long.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: name must be 1-64 letters, digits, '_', '.' or '-'", ErrInvalidSecret)
+	}
+	return nil
+}
+
+// List returns the secrets without their values.
+func (v *Vault) List(ctx context.Context) ([]store.OpsSecret, error) {
+	if v == nil {
+		return nil, ErrUnavailable
+	}
+	return v.repo.ListOpsSecrets(ctx)
+}
+
+// Create stores a new secret.
+func (v *Vault) Create(ctx context.Context, name, value string) (store.OpsSecret, error) {
+	sealed, err := v.sealValid(name, value)
+	if err != nil {
+		return store.OpsSecret{}, err
+	}
+	return v.repo.CreateOpsSecret(ctx, name, sealed)
+}
+
+// Update replaces the value of an existing secret.
+func (v *Vault) Update(ctx context.Context, name, value string) (store.OpsSecret, error) {
+	sealed, err := v.sealValid(name, value)
+	if err != nil {
+		return store.OpsSecret{}, err
+	}
+	return v.repo.UpdateOpsSecret(ctx, name, sealed)
+}
+
+// Delete removes a secret. References to it fail from then on.
+func (v *Vault) Delete(ctx context.Context, name string) error {
+	if v == nil {
+		return ErrUnavailable
+	}
+	return v.repo.DeleteOpsSecret(ctx, name)
+}
+
+// Lookup returns the plaintext value of a secret. It is a LookupFunc.
+func (v *Vault) Lookup(ctx context.Context, name string) (string, error) {
+	if v == nil {
+		return "", ErrUnavailable
+	}
+	item, err := v.repo.GetOpsSecret(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return v.open(item.Name, item.Value)
+}
+
+func (v *Vault) sealValid(name, value string) ([]byte, error) {
+	if v == nil {
+		return nil, ErrUnavailable
+	}
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%w: value is required", ErrInvalidSecret)
+	}
+	if len(value) > maxValueBytes {
+		return nil, fmt.Errorf("%w: value must be at most %d bytes", ErrInvalidSecret, maxValueBytes)
+	}
+	return v.seal(name, value)
+}
+
+// seal returns the nonce followed by the ciphertext.
+func (v *Vault) seal(name, value string) ([]byte, error) {
+	nonce := make([]byte, v.aead.NonceSize(), v.aead.NonceSize()+len(value)+v.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return v.aead.Seal(nonce, nonce, []byte(value), []byte(name)), nil
+}
+
+func (v *Vault) open(name string, sealed []byte) (string, error) {
+	size := v.aead.NonceSize()
+	if len(sealed) < size {
+		return "", fmt.Errorf("secret %q is corrupt", name)
+	}
+	plain, err := v.aead.Open(nil, sealed[:size], sealed[size:], []byte(name))
+	if err != nil {
+		// Most likely sealed under another master key.
+		return "", fmt.Errorf("secret %q cannot be decrypted with this master key", name)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,138 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func newTestVault(t *testing.T, key []byte) (*Vault, *store.Store) {
+	t.Helper()
+	st, err := store.New(filepath.Join(t.TempDir(), "sentinel.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	v, err := New(st, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v, st
+}
+
+func TestVaultSealsValues(t *testing.T) {
+	t.Parallel()
+
+	key := []byte(strings.Repeat("k", KeySize))
+	v, st := newTestVault(t, key)
+	ctx := context.Background()
+
+	if _, err := v.Create(ctx, "slack webhook", "x"); !errors.Is(err, ErrInvalidSecret) {
+		t.Fatalf("Create(bad name) error = %v, want ErrInvalidSecret", err)
+	}
+	if _, err := v.Create(ctx, "slack_webhook", ""); !errors.Is(err, ErrInvalidSecret) {
+		t.Fatalf("Create(empty value) error = %v, want ErrInvalidSecret", err)
+	}
+	if _, err := v.Create(ctx, "slack_webhook", "https://hooks.example/T0/B0/abc"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	raw, err := st.GetOpsSecret(ctx, "slack_webhook")
+	if err != nil || strings.Contains(string(raw.Value), "hooks.example") {
+		t.Fatalf("stored value = %q, %v; want ciphertext", raw.Value, err)
+	}
+	if got, err := v.Lookup(ctx, "slack_webhook"); err != nil || got != "https://hooks.example/T0/B0/abc" {
+		t.Fatalf("Lookup() = %q, %v", got, err)
+	}
+	if _, err := v.Update(ctx, "slack_webhook", "rotated"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, _ := v.Lookup(ctx, "slack_webhook"); got != "rotated" {
+		t.Fatalf("Lookup() after update = %q", got)
+	}
+	if _, err := v.Lookup(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Lookup(missing) error = %v, want ErrNotFound", err)
+	}
+
+	// A value moved to another name does not open.
+	if _, err := st.CreateOpsSecret(ctx, "copy", raw.Value); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Lookup(ctx, "copy"); err == nil {
+		t.Fatal("Lookup() opened a value sealed under another name")
+	}
+
+	other, err := New(st, []byte(strings.Repeat("o", KeySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Lookup(ctx, "slack_webhook"); err == nil {
+		t.Fatal("Lookup() with another master key succeeded")
+	}
+
+	var unavailable *Vault
+	if _, err := unavailable.Lookup(ctx, "slack_webhook"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("nil vault Lookup() error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestExpandAndRedact(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(_ context.Context, name string) (string, error) {
+		if name == "token" {
+			return "s3cr3t'x", nil
+		}
+		return "", ErrNotFound
+	}
+	quote := func(s string) string { return "<" + s + ">" }
+	ctx := context.Background()
+
+	got, values, err := Expand(ctx, lookup, `curl -H "X: {{secret "token"}}" {{ secret "token" }}`, quote)
+	if err != nil || got != `curl -H "X: <s3cr3t'x>" <s3cr3t'x>` || len(values) != 1 {
+		t.Fatalf("Expand() = %q, %v, %v", got, values, err)
+	}
+	if _, _, err := Expand(ctx, lookup, `{{secret "other"}}`, nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expand(unknown) error = %v, want ErrNotFound", err)
+	}
+	if got, _, err := Expand(ctx, nil, "echo {{NAME}}", nil); err != nil || got != "echo {{NAME}}" {
+		t.Fatalf("Expand(no refs) = %q, %v", got, err)
+	}
+	if _, _, err := Expand(ctx, nil, `{{secret "token"}}`, nil); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expand(nil lookup) error = %v, want ErrUnavailable", err)
+	}
+
+	if got := Redact("token=s3cr3t'x and s3cr3t", values); got != "token=[secret] and s3cr3t" {
+		t.Fatalf("Redact() = %q", got)
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "keys", "secrets.key")
+	key, err := LoadKeyFile(path)
+	if err != nil || len(key) != KeySize {
+		t.Fatalf("LoadKeyFile() created %d bytes, %v", len(key), err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("key file mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+	again, err := LoadKeyFile(path)
+	if err != nil || string(again) != string(key) {
+		t.Fatalf("LoadKeyFile() second read = %x, %v; want the created key", again, err)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.key")
+	if err := os.WriteFile(bad, []byte("not hex"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeyFile(bad); err == nil {
+		t.Fatal("LoadKeyFile() accepted a malformed key")
+	}
+}
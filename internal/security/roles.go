@@ -0,0 +1,175 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrForbidden is returned when an authenticated identity lacks the role
+// required for a request.
+var ErrForbidden = errors.New("forbidden")
+
+// Role is the permission level attached to an authenticated identity.
+type Role string
+
+const (
+	// RoleViewer may read state but not change it.
+	RoleViewer Role = "viewer"
+	// RoleOperator may drive sessions and run automation.
+	RoleOperator Role = "operator"
+	// RoleAdmin may perform destructive actions and change configuration.
+	RoleAdmin Role = "admin"
+)
+
+// OwnerIdentityName names the identity behind the configured server token.
+const OwnerIdentityName = "owner"
+
+var roleRanks = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// ParseRole normalizes a role name. The second result is false when the
+// value is not a known role.
+func ParseRole(raw string) (Role, bool) {
+	role := Role(strings.ToLower(strings.TrimSpace(raw)))
+	if _, ok := roleRanks[role]; !ok {
+		return "", false
+	}
+	return role, true
+}
+
+// Allows reports whether r grants at least the required role.
+func (r Role) Allows(required Role) bool {
+	have, ok := roleRanks[r]
+	if !ok {
+		return false
+	}
+	return have >= roleRanks[required]
+}
+
+// Identity describes who authenticated a request.
+type Identity struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// KeyResolver resolves a presented token that is not the server token, such
+// as a named API key. It returns false when the token is unknown.
+type KeyResolver func(ctx context.Context, token string) (Identity, bool)
+
+type identityContextKey struct{}
+
+// WithIdentity returns a context carrying the authenticated identity.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, id)
+}
+
+// IdentityFromContext returns the identity stored by WithIdentity.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	if ctx == nil {
+		return Identity{}, false
+	}
+	id, ok := ctx.Value(identityContextKey{}).(Identity)
+	return id, ok
+}
+
+type forwardedIdentityContextKey struct{}
+
+// WithForwardedIdentity returns a context for a request that another
+// Sentinel instance already authenticated, such as a federation central
+// proxying to an agent. Authenticate trusts it ahead of cookies and tokens;
+// only in-process callers can set it.
+func WithForwardedIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, forwardedIdentityContextKey{}, id)
+}
+
+// SetKeyResolver installs the lookup used for tokens other than the server
+// token. Passing nil disables additional keys.
+func (g *Guard) SetKeyResolver(resolver KeyResolver) {
+	if g == nil {
+		return
+	}
+	g.keyMu.Lock()
+	g.keyResolver = resolver
+	g.keyMu.Unlock()
+}
+
+// Authenticate resolves the identity behind a request from the auth cookie
+// or an Authorization Bearer header. Without a configured server token every
+// request is treated as the local admin. A nil guard fails closed.
+func (g *Guard) Authenticate(r *http.Request) (Identity, error) {
+	if g == nil || r == nil {
+		return Identity{}, ErrUnauthorized
+	}
+	if id, ok := r.Context().Value(forwardedIdentityContextKey{}).(Identity); ok {
+		if _, known := roleRanks[id.Role]; !known {
+			return Identity{}, ErrUnauthorized
+		}
+		return id, nil
+	}
+	if !g.TokenRequired() {
+		return Identity{Name: OwnerIdentityName, Role: RoleAdmin}, nil
+	}
+	if token := cookieToken(r); token != "" {
+		if id, err := g.AuthenticateToken(r.Context(), token); err == nil {
+			return id, nil
+		}
+	}
+	return g.AuthenticateToken(r.Context(), BearerToken(r.Header.Get("Authorization")))
+}
+
+// AuthenticateToken resolves the identity for a raw token value.
+func (g *Guard) AuthenticateToken(ctx context.Context, token string) (Identity, error) {
+	if g == nil {
+		return Identity{}, ErrUnauthorized
+	}
+	if !g.TokenRequired() {
+		return Identity{Name: OwnerIdentityName, Role: RoleAdmin}, nil
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return Identity{}, ErrUnauthorized
+	}
+	if g.TokenMatches(token) {
+		return Identity{Name: OwnerIdentityName, Role: RoleAdmin}, nil
+	}
+	g.keyMu.RLock()
+	resolver := g.keyResolver
+	g.keyMu.RUnlock()
+	if resolver == nil {
+		return Identity{}, ErrUnauthorized
+	}
+	id, ok := resolver(ctx, token)
+	if !ok {
+		return Identity{}, ErrUnauthorized
+	}
+	if _, known := roleRanks[id.Role]; !known {
+		return Identity{}, ErrUnauthorized
+	}
+	return id, nil
+}
+
+// Authorize authenticates r and checks that the identity holds required.
+func (g *Guard) Authorize(r *http.Request, required Role) (Identity, error) {
+	id, err := g.Authenticate(r)
+	if err != nil {
+		return Identity{}, err
+	}
+	if !id.Role.Allows(required) {
+		return id, ErrForbidden
+	}
+	return id, nil
+}
+
+// BearerToken extracts the token from an Authorization header value.
+func BearerToken(value string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,203 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		raw    string
+		want   Role
+		wantOK bool
+	}{
+		{"viewer", RoleViewer, true},
+		{" Operator ", RoleOperator, true},
+		{"ADMIN", RoleAdmin, true},
+		{"root", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRole(tt.raw)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseRole(%q) = (%q, %v), want (%q, %v)", tt.raw, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		have     Role
+		required Role
+		want     bool
+	}{
+		{RoleViewer, RoleViewer, true},
+		{RoleViewer, RoleOperator, false},
+		{RoleOperator, RoleViewer, true},
+		{RoleOperator, RoleAdmin, false},
+		{RoleAdmin, RoleOperator, true},
+		{Role("bogus"), RoleViewer, false},
+	}
+	for _, tt := range tests {
+		if got := tt.have.Allows(tt.required); got != tt.want {
+			t.Errorf("%q.Allows(%q) = %v, want %v", tt.have, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticateResolvesIdentities(t *testing.T) {
+	t.Parallel()
+
+	g := New("server-token", nil, CookieSecureAuto)
+	g.SetKeyResolver(func(_ context.Context, token string) (Identity, bool) {
+		switch token {
+		case "viewer-key":
+			return Identity{Name: "dashboard", Role: RoleViewer}, true
+		case "broken-key":
+			return Identity{Name: "broken", Role: Role("superuser")}, true
+		default:
+			return Identity{}, false
+		}
+	})
+
+	tests := []struct {
+		name    string
+		cookie  string
+		bearer  string
+		want    Identity
+		wantErr error
+	}{
+		{name: "server token cookie", cookie: "server-token", want: Identity{Name: OwnerIdentityName, Role: RoleAdmin}},
+		{name: "api key cookie", cookie: "viewer-key", want: Identity{Name: "dashboard", Role: RoleViewer}},
+		{name: "api key bearer", bearer: "viewer-key", want: Identity{Name: "dashboard", Role: RoleViewer}},
+		{name: "stale cookie falls back to bearer", cookie: "gone", bearer: "server-token", want: Identity{Name: OwnerIdentityName, Role: RoleAdmin}},
+		{name: "unknown key", bearer: "nope", wantErr: ErrUnauthorized},
+		{name: "unknown role", bearer: "broken-key", wantErr: ErrUnauthorized},
+		{name: "missing credentials", wantErr: ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: AuthCookieName, Value: encodeBase64URL(tt.cookie)})
+			}
+			if tt.bearer != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			got, err := g.Authenticate(r)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Authenticate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateWithoutTokenIsLocalAdmin(t *testing.T) {
+	t.Parallel()
+
+	g := New("", nil, CookieSecureAuto)
+	id, err := g.Authenticate(httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if id.Role != RoleAdmin {
+		t.Fatalf("role = %q, want admin", id.Role)
+	}
+}
+
+func TestAuthorizeEnforcesRole(t *testing.T) {
+	t.Parallel()
+
+	g := New("server-token", nil, CookieSecureAuto)
+	g.SetKeyResolver(func(_ context.Context, token string) (Identity, bool) {
+		if token == "ops-key" {
+			return Identity{Name: "ci", Role: RoleOperator}, true
+		}
+		return Identity{}, false
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "http://localhost/", nil)
+	r.Header.Set("Authorization", "Bearer ops-key")
+	if _, err := g.Authorize(r, RoleOperator); err != nil {
+		t.Fatalf("Authorize(operator) error = %v", err)
+	}
+	id, err := g.Authorize(r, RoleAdmin)
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Authorize(admin) error = %v, want ErrForbidden", err)
+	}
+	if id.Name != "ci" {
+		t.Fatalf("identity name = %q, want ci", id.Name)
+	}
+
+	var nilGuard *Guard
+	if _, err := nilGuard.Authorize(r, RoleViewer); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("nil guard Authorize() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestAuthenticateTrustsForwardedIdentity(t *testing.T) {
+	t.Parallel()
+
+	g := New("server-token", nil, CookieSecureAuto)
+	ctx := WithForwardedIdentity(context.Background(), Identity{Name: "ci", Role: RoleOperator})
+	r := httptest.NewRequest(http.MethodPost, "http://localhost/", nil).WithContext(ctx)
+	if _, err := g.Authorize(r, RoleOperator); err != nil {
+		t.Fatalf("Authorize(operator) error = %v", err)
+	}
+	if _, err := g.Authorize(r, RoleAdmin); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Authorize(admin) error = %v, want ErrForbidden", err)
+	}
+
+	bogus := WithForwardedIdentity(context.Background(), Identity{Name: "x", Role: Role("root")})
+	r = httptest.NewRequest(http.MethodGet, "http://localhost/", nil).WithContext(bogus)
+	if _, err := g.Authenticate(r); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Authenticate(unknown role) error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestIdentityContextRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := IdentityFromContext(context.Background()); ok {
+		t.Fatal("empty context should not carry an identity")
+	}
+	want := Identity{Name: "dashboard", Role: RoleViewer}
+	got, ok := IdentityFromContext(WithIdentity(context.Background(), want))
+	if !ok || got != want {
+		t.Fatalf("IdentityFromContext() = (%+v, %v), want (%+v, true)", got, ok, want)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	t.Parallel()
+
+	for raw, want := range map[string]string{
+		"Bearer abc":   "abc",
+		"bearer  xyz ": "xyz",
+		"Basic abc":    "",
+		"abc":          "",
+		"":             "",
+	} {
+		if got := BearerToken(raw); got != want {
+			t.Errorf("BearerToken(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
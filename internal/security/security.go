@@ -80,6 +80,8 @@ type Guard struct {
 	trustedProxies []trustedProxy
 	originLogMu    sync.Mutex
 	originLogAt    map[string]time.Time
+	keyMu          sync.RWMutex
+	keyResolver    KeyResolver
 }
 
 // OriginError describes why a request origin was rejected.
@@ -333,23 +335,27 @@ func (g *Guard) LogOriginDenial(r *http.Request, err error) {
 
 // RequireAuth requires auth. A nil guard fails closed (denies).
 func (g *Guard) RequireAuth(r *http.Request) error {
-	if g == nil {
-		return ErrUnauthorized
-	}
-	if !g.TokenMatches(cookieToken(r)) {
-		return ErrUnauthorized
-	}
-	return nil
+	_, err := g.Authenticate(r)
+	return err
 }
 
 // SetAuthCookie sets auth cookie.
 func (g *Guard) SetAuthCookie(w http.ResponseWriter, r *http.Request) {
+	if !g.TokenRequired() {
+		return
+	}
+	g.SetAuthCookieToken(w, r, g.token)
+}
+
+// SetAuthCookieToken sets the auth cookie to an already validated token,
+// such as a named API key.
+func (g *Guard) SetAuthCookieToken(w http.ResponseWriter, r *http.Request, token string) {
 	if !g.TokenRequired() {
 		return
 	}
 	http.SetCookie(w, &http.Cookie{
 		Name:     AuthCookieName,
-		Value:    encodeBase64URL(g.token),
+		Value:    encodeBase64URL(strings.TrimSpace(token)),
 		Path:     "/",
 		HttpOnly: true,
 		SameSite: http.SameSiteLaxMode,
@@ -414,6 +420,24 @@ func (g *Guard) trustsRemote(remoteAddr string) bool {
 	return false
 }
 
+// ClientIP returns the address of the client behind r. X-Forwarded-For is
+// honored only when the direct peer is a trusted proxy, and then only its
+// last hop, which that proxy appended.
+func (g *Guard) ClientIP(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	if g != nil && g.trustsRemote(r.RemoteAddr) {
+		if forwarded := strings.Join(r.Header.Values("X-Forwarded-For"), ","); strings.TrimSpace(forwarded) != "" {
+			hops := strings.Split(forwarded, ",")
+			if ip := net.ParseIP(strings.TrimSpace(hops[len(hops)-1])); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+	return remoteHost(r.RemoteAddr)
+}
+
 func remoteHost(remoteAddr string) string {
 	host, _, err := net.SplitHostPort(strings.TrimSpace(remoteAddr))
 	if err != nil {
@@ -180,6 +180,33 @@ func TestCheckOriginTrustsLoopbackHTTPSProxyByDefault(t *testing.T) {
 	}
 }
 
+func TestClientIP(t *testing.T) {
+	t.Parallel()
+
+	guard := NewWithOptions("", nil, CookieSecureAuto, MultiUserConfig{}, []string{"10.0.0.0/8"})
+	tests := []struct {
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"192.0.2.7:5000", "", "192.0.2.7"},
+		{"192.0.2.7:5000", "198.51.100.1", "192.0.2.7"},
+		{"127.0.0.1:5000", "203.0.113.9, 198.51.100.1", "198.51.100.1"},
+		{"10.1.2.3:5000", "198.51.100.1", "198.51.100.1"},
+		{"10.1.2.3:5000", "garbage", "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/meta", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		if got := guard.ClientIP(req); got != tt.want {
+			t.Errorf("ClientIP(%s, %q) = %q, want %q", tt.remoteAddr, tt.forwarded, got, tt.want)
+		}
+	}
+}
+
 func TestRequireAuth(t *testing.T) {
 	t.Parallel()
 
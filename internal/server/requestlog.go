@@ -2,7 +2,6 @@ package server
 
 import (
 	"bufio"
-	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
@@ -11,16 +10,20 @@ import (
 	"net/http"
 	"runtime/debug"
 	"time"
-)
 
-// requestIDKey is the context key for the request ID.
-type requestIDKey struct{}
+	"github.com/opus-domini/sentinel/internal/logging"
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/tracing"
+)
 
-func requestLog(next http.Handler) http.Handler {
+// requestLog assigns a request ID and logs each request. The ID travels in
+// the context, so handler logs and the request line share it along with the
+// actor the API records once it authenticates the caller. The client IP comes
+// from guard, which honors X-Forwarded-For only from trusted proxies.
+func requestLog(guard *security.Guard, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		rid := generateRequestID()
-		ctx := context.WithValue(r.Context(), requestIDKey{}, rid)
-		r = r.WithContext(ctx)
+		r = r.WithContext(logging.WithRequestID(r.Context(), rid))
 		w.Header().Set("X-Request-ID", rid)
 
 		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
@@ -35,7 +38,7 @@ func requestLog(next http.Handler) http.Handler {
 			if p == http.ErrAbortHandler { //nolint:errorlint // recover() value, sentinel identity compare matches net/http
 				panic(p)
 			}
-			slog.Error("request panic recovered", "method", r.Method, "path", r.URL.Path, "request_id", rid, "panic", p, "stack", string(debug.Stack()))
+			slog.ErrorContext(r.Context(), "request panic recovered", "method", r.Method, "path", r.URL.Path, "panic", p, "stack", string(debug.Stack()))
 			// Only emit a 500 if the response is still ours: after a Hijack
 			// (WebSocket) or a written header, the connection is no longer
 			// writable as an HTTP response.
@@ -44,7 +47,38 @@ func requestLog(next http.Handler) http.Handler {
 			}
 		}()
 		rec.ServeHTTP(next, r)
-		slog.Info("request", "method", r.Method, "path", r.URL.Path, "status", rec.status, "duration", time.Since(start).Truncate(time.Millisecond), "request_id", rid)
+		slog.InfoContext(r.Context(), "request", "method", r.Method, "path", r.URL.Path, "status", rec.status, "duration", time.Since(start).Truncate(time.Millisecond), "client_ip", guard.ClientIP(r))
+	})
+}
+
+// traceRequests records a server span per request, continuing the caller's
+// trace when the request carries a traceparent header. Spans are named
+// after the matched route so that requests to one endpoint group together.
+func traceRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !tracing.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := tracing.Extract(r.Context(), r.Header.Get("traceparent"))
+		ctx, span := tracing.Start(ctx, r.Method, tracing.KindServer,
+			tracing.String("http.request.method", r.Method),
+			tracing.String("url.path", r.URL.Path),
+		)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		rec.ServeHTTP(next, r)
+		// The mux records the matched pattern on the request it was given.
+		if r.Pattern != "" {
+			span.SetName(r.Pattern)
+			span.SetAttributes(tracing.String("http.route", r.Pattern))
+		}
+		span.SetAttributes(tracing.Int("http.response.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetError(http.StatusText(rec.status))
+		}
 	})
 }
 
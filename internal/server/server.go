@@ -5,7 +5,6 @@ package server
 import (
 	"context"
 	"errors"
-	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -19,15 +18,30 @@ import (
 	"github.com/opus-domini/sentinel/internal/api"
 	"github.com/opus-domini/sentinel/internal/config"
 	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/federation"
+	"github.com/opus-domini/sentinel/internal/files"
+	"github.com/opus-domini/sentinel/internal/inventory"
+	"github.com/opus-domini/sentinel/internal/jobqueue"
+	"github.com/opus-domini/sentinel/internal/library"
+	"github.com/opus-domini/sentinel/internal/logging"
 	"github.com/opus-domini/sentinel/internal/mcpserver"
+	"github.com/opus-domini/sentinel/internal/mqtt"
 	"github.com/opus-domini/sentinel/internal/notify"
+	"github.com/opus-domini/sentinel/internal/panelog"
+	"github.com/opus-domini/sentinel/internal/proc"
+	"github.com/opus-domini/sentinel/internal/recording"
 	"github.com/opus-domini/sentinel/internal/report"
+	"github.com/opus-domini/sentinel/internal/runbook"
 	"github.com/opus-domini/sentinel/internal/scheduler"
+	"github.com/opus-domini/sentinel/internal/secrets"
 	"github.com/opus-domini/sentinel/internal/security"
 	"github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/sshhost"
+	"github.com/opus-domini/sentinel/internal/statuspage"
 	"github.com/opus-domini/sentinel/internal/store"
 	"github.com/opus-domini/sentinel/internal/term"
 	"github.com/opus-domini/sentinel/internal/tmux"
+	"github.com/opus-domini/sentinel/internal/tracing"
 	"github.com/opus-domini/sentinel/internal/ui"
 	"github.com/opus-domini/sentinel/internal/watchtower"
 )
@@ -38,14 +52,14 @@ import (
 func Serve(version string) int {
 	cfg, configPath, err := config.Load()
 	if err != nil {
-		closeLogger, _ := initLogger(config.DefaultLogLevel, "")
+		closeLogger, _ := initLogger(config.LogConfig{Level: config.DefaultLogLevel})
 		defer closeLogger()
 		slog.Error("config load failed", "err", err)
 		return 1
 	}
-	closeLogger, err := initLogger(cfg.Log.Level, cfg.Log.Path)
+	closeLogger, err := initLogger(cfg.Log)
 	if err != nil {
-		closeFallback, _ := initLogger(config.DefaultLogLevel, "")
+		closeFallback, _ := initLogger(config.LogConfig{Level: config.DefaultLogLevel})
 		defer closeFallback()
 		slog.Error("logger init failed", "err", err)
 		return 1
@@ -85,9 +99,19 @@ func Serve(version string) int {
 			return 1
 		}
 	}
+	stopTracing := startTracing(cfg.Tracing, version)
+	defer stopTracing()
+
 	eventHub := events.NewHub()
 
-	st, err := store.New(cfg.Storage.Path)
+	if restored, err := store.ApplyPendingRestore(cfg.Storage.Path, cfg.Storage.BackupDir); err != nil {
+		slog.Error("database restore failed", "err", err)
+		return 1
+	} else if restored != "" {
+		slog.Info("database restored from backup", "backup", restored)
+	}
+
+	st, err := store.Open(cfg.Storage.Path, cfg.Storage.StoreOptions())
 	if err != nil {
 		slog.Error("store init failed", "err", err)
 		return 1
@@ -111,11 +135,55 @@ func Serve(version string) int {
 		slog.Info("restored pinned sessions", "count", restoredPinned)
 	}
 
+	// Without a master key the vault stays off: secret references fail, but
+	// everything else runs.
+	vault, err := openSecrets(cfg.Secrets, st)
+	if err != nil {
+		slog.Warn("secrets vault unavailable", "err", err)
+	}
+
+	guard.SetKeyResolver(func(ctx context.Context, token string) (security.Identity, bool) {
+		key, err := st.AuthenticateAPIKey(ctx, token)
+		if err != nil {
+			return security.Identity{}, false
+		}
+		role, ok := security.ParseRole(key.Role)
+		return security.Identity{Name: key.Name, Role: role}, ok
+	})
+
 	opsManager := services.NewManager(time.Now(), st)
+	opsManager.SetDiskScanRoots(cfg.Metrics.DiskScanRoots)
+	opsManager.SetUPS(cfg.UPS.Source, cfg.UPS.Name)
+	opsManager.SetCertificates(cfg.Certificates.Paths, cfg.Certificates.Endpoints, cfg.Certificates.WarnDays)
+	opsManager.SetUptimeChecks(st)
+	opsManager.SetHeartbeats(st)
 
 	mux := http.NewServeMux()
 	mcpState := mcpserver.NewState(cfg.MCP.Enabled, strings.TrimSpace(cfg.Server.Token) != "")
-	apiHandler := api.Register(mux, guard, st, opsManager, eventHub, version, configPath, cfg.Server.Timezone, cfg.Server.Locale, mcpState, cfg.Runbooks.MaxConcurrent)
+	// Manual and scheduled runbook runs share one job queue.
+	jobs := jobqueue.New(cfg.Runbooks.MaxConcurrent, cfg.Runbooks.MaxQueued)
+	apiHandler := api.Register(mux, guard, st, opsManager, eventHub, version, configPath, cfg.Server.Timezone, cfg.Server.Locale, mcpState, jobs)
+	apiHandler.SetBackupOptions(cfg.Storage.BackupDir, cfg.Storage.BackupKeep)
+	apiHandler.SetUpdateOptions(cfg.DataDir(), cfg.Updates.Channel, cfg.Updates.PublicKey)
+	if vault != nil {
+		apiHandler.SetSecrets(vault)
+	}
+	if cfg.RateLimit.Enabled {
+		apiHandler.SetRateLimits(cfg.RateLimit.ReadPerMinute, cfg.RateLimit.MutatePerMinute)
+	}
+	if len(cfg.Files.Roots) > 0 {
+		apiHandler.SetFiles(files.New(cfg.Files.Roots), int64(cfg.Files.MaxUploadMB)*1024*1024)
+	}
+	var lib *library.Service
+	if cfg.Library.Dir != "" {
+		lib = library.New(st, library.Options{
+			Dir:        cfg.Library.Dir,
+			GitURL:     cfg.Library.GitURL,
+			GitBranch:  cfg.Library.GitBranch,
+			UserPolicy: guard.ValidateTargetUser,
+		})
+		apiHandler.SetLibrary(lib)
+	}
 	mcpServer := mcpserver.New(mcpState, guard, mcpserver.Options{
 		Version:             version,
 		SessionUser:         apiHandler.SessionUser,
@@ -127,16 +195,77 @@ func Serve(version string) int {
 	mux.Handle("GET /mcp", mcpServer)
 	mux.Handle("DELETE /mcp", mcpServer)
 
-	if err := ui.Register(mux, guard, st, eventHub, opsManager, apiHandler.SessionUser); err != nil {
+	var (
+		sshClients  []*sshhost.Client
+		hostTargets runbook.HostTargets
+	)
+	if cfg.Federation.Token != "" || len(cfg.Federation.SSHHosts) > 0 {
+		hub := federation.NewHub(cfg.Federation.Token)
+		if cfg.Federation.Token != "" {
+			mux.Handle("GET "+federation.AgentPath, hub)
+			slog.Info("federation central enabled", "path", federation.AgentPath)
+		}
+		sshClients = addSSHHosts(hub, cfg.Federation.SSHHosts, filepath.Join(cfg.DataDir(), "ssh"))
+		apiHandler.SetFederation(hub)
+		hostTargets = inventory.New(st, hub)
+	}
+
+	var recordings *recording.Store
+	if cfg.Recording.Enabled {
+		recordings = recording.New(filepath.Join(cfg.DataDir(), "recordings"), recording.Options{
+			Retention: cfg.Recording.Retention,
+		})
+		apiHandler.SetRecordings(recordings)
+	}
+
+	if err := ui.Register(mux, guard, st, eventHub, opsManager, apiHandler.SessionUser, recordings); err != nil {
 		slog.Error("frontend init failed", "err", err)
 		return 1
 	}
 
-	watchtowerService := watchtower.New(st, tmux.Service{}, watchtower.Options{
+	var statusPage *statuspage.Page
+	if cfg.StatusPage.Enabled {
+		statusPage = statuspage.New(opsManager, st, statuspage.Options{
+			Title:       cfg.StatusPage.Title,
+			HistoryDays: cfg.StatusPage.HistoryDays,
+		})
+		var auth statuspage.Authenticator
+		if !cfg.StatusPage.Public {
+			auth = guard
+		}
+		statusPage.Register(mux, auth)
+		slog.Info("status page enabled", "public", cfg.StatusPage.Public, "listen", cfg.StatusPage.Listen)
+	}
+
+	var paneLog watchtower.PaneArchiver
+	if cfg.Watchtower.PaneLog {
+		archive := panelog.New(filepath.Join(cfg.DataDir(), "pane-logs"), panelog.Options{
+			MaxBytes:  int64(cfg.Watchtower.PaneLogMaxMB) * 1024 * 1024,
+			Retention: cfg.Watchtower.PaneLogRetention,
+		})
+		apiHandler.SetPaneLog(archive)
+		paneLog = archive
+	}
+
+	// With control mode, tmux layout notifications trigger a collection
+	// between ticks.
+	var (
+		watchtowerService *watchtower.Service
+		watchtowerControl *tmux.ControlClient
+	)
+	if cfg.Watchtower.ControlMode {
+		watchtowerControl = tmux.NewControlClient("", func(string) { watchtowerService.Trigger() })
+	}
+	watchtowerService = watchtower.New(st, tmux.Service{Control: watchtowerControl}, watchtower.Options{
 		TickInterval:   cfg.Watchtower.TickInterval,
 		CaptureLines:   cfg.Watchtower.CaptureLines,
 		CaptureTimeout: cfg.Watchtower.CaptureTimeout,
 		JournalRows:    cfg.Watchtower.JournalRows,
+		PaneLog:        paneLog,
+		Adaptive:       cfg.Watchtower.Adaptive,
+		IdleTicks:      cfg.Watchtower.IdleTicks,
+		MaxInterval:    cfg.Watchtower.MaxInterval,
+		Processes:      proc.System{},
 		Publish: func(eventType string, payload map[string]any) {
 			eventHub.Publish(events.NewEvent(eventType, payload))
 		},
@@ -160,45 +289,68 @@ func Serve(version string) int {
 	})
 	if cfg.Watchtower.Enabled {
 		watchtowerService.Start(context.Background())
+		apiHandler.SetWatchtowerTick(cfg.Watchtower.TickInterval)
 	}
 
 	schedulerService := scheduler.New(st, st, scheduler.Options{
 		TickInterval: 5 * time.Second,
+		Queue:        jobs,
 		EventHub:     eventHub,
+		Hosts:        hostTargets,
+		Secrets:      vault.Lookup,
+		UserPolicy:   guard.ValidateTargetUser,
 	})
 	schedulerService.Start(context.Background())
 
 	// Health report generator (optional: requires webhook URL + schedule).
 	var reportGen *report.Generator
 	if cfg.HealthReport.WebhookURL != "" {
-		reportNotifier := notify.New(cfg.HealthReport.WebhookURL)
-		reportGen = report.New(opsManager, reportNotifier)
-		if cfg.HealthReport.Schedule != "" {
-			if err := reportGen.StartSchedule(context.Background(), cfg.HealthReport.Schedule, cfg.Server.Timezone); err != nil {
-				slog.Warn("health report schedule failed to start", "error", err)
-			} else {
-				slog.Info("health report enabled", "url", cfg.HealthReport.WebhookURL, "schedule", cfg.HealthReport.Schedule)
-			}
+		if webhookURL, ok := resolveConfigSecrets(vault, "health_report.webhook_url", cfg.HealthReport.WebhookURL); ok {
+			reportGen = report.New(opsManager, notify.NewLabeled(webhookURL, cfg.HealthReport.WebhookURL))
+		}
+	}
+	if reportGen != nil && cfg.HealthReport.Schedule != "" {
+		if err := reportGen.StartSchedule(context.Background(), cfg.HealthReport.Schedule, cfg.Server.Timezone); err != nil {
+			slog.Warn("health report schedule failed to start", "error", err)
+		} else {
+			slog.Info("health report enabled", "url", cfg.HealthReport.WebhookURL, "schedule", cfg.HealthReport.Schedule)
 		}
 	}
 
-	metricsCtx, stopMetrics := context.WithCancel(context.Background())
-	metricsDone := startMetricsTicker(metricsCtx, opsManager, eventHub)
+	bg := startBackground(backgroundDeps{
+		cfg:        cfg,
+		version:    version,
+		mux:        mux,
+		store:      st,
+		ops:        opsManager,
+		hub:        eventHub,
+		backups:    apiHandler.Backups(),
+		runbooks:   apiHandler.RunbookManager(),
+		library:    lib,
+		statusPage: statusPage,
+		vault:      vault,
+	})
 
-	exitCode := run(version, cfg, mux)
+	exitCode := run(version, cfg, guard, mux)
 
 	// Shutdown in LIFO order: API handler first (drains in-flight requests),
-	// then tickers (wait for doneCh so no queries race with st.Close),
-	// then services, then store.
-	apiShutdownCtx, cancelAPI := context.WithTimeout(context.Background(), 5*time.Second)
-	apiHandler.Shutdown(apiShutdownCtx)
-	cancelAPI()
-	mcpShutdownCtx, cancelMCP := context.WithTimeout(context.Background(), 3*time.Second)
-	mcpServer.Shutdown(mcpShutdownCtx)
-	cancelMCP()
-
-	stopMetrics()
-	<-metricsDone
+	// then tickers (wait for them so no queries race with st.Close),
+	// then services, then store. Runbook runs get the drain timeout to finish
+	// before the API handler and the scheduler cancel the rest.
+	bg.shutdown(func() {
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.Runbooks.DrainTimeout)
+		jobs.Shutdown(drainCtx)
+		cancelDrain()
+		apiShutdownCtx, cancelAPI := context.WithTimeout(context.Background(), 5*time.Second)
+		apiHandler.Shutdown(apiShutdownCtx)
+		cancelAPI()
+		mcpShutdownCtx, cancelMCP := context.WithTimeout(context.Background(), 3*time.Second)
+		mcpServer.Shutdown(mcpShutdownCtx)
+		cancelMCP()
+	})
+	for _, client := range sshClients {
+		client.Close()
+	}
 
 	stopReportCtx, cancelReport := context.WithTimeout(context.Background(), 2*time.Second)
 	reportGen.Stop(stopReportCtx)
@@ -213,13 +365,202 @@ func Serve(version string) int {
 		watchtowerService.Stop(stopWatchtowerCtx)
 		cancelWatchtower()
 	}
+	if watchtowerControl != nil {
+		watchtowerControl.Close()
+	}
 	return exitCode
 }
 
-func run(version string, cfg config.Config, mux *http.ServeMux) int {
+// startFederationAgent connects to the configured central, serving relayed
+// calls from mux. The returned channel closes once the agent has stopped.
+func startFederationAgent(ctx context.Context, cfg config.FederationConfig, version string, mux http.Handler) <-chan struct{} {
+	done := make(chan struct{})
+	if cfg.CentralURL == "" {
+		close(done)
+		return done
+	}
+	name := cfg.Name
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	agent := federation.NewAgent(federation.AgentOptions{
+		CentralURL: cfg.CentralURL,
+		Token:      cfg.Token,
+		Name:       name,
+		Version:    version,
+		Handler:    mux,
+	})
+	go func() {
+		defer close(done)
+		agent.Run(ctx)
+	}()
+	slog.Info("federation agent enabled", "central", cfg.CentralURL, "host", name)
+	return done
+}
+
+// startTracing exports spans to the configured collector. The returned
+// function flushes the spans still queued and stops the exporter.
+func startTracing(cfg config.TracingConfig, version string) func() {
+	if cfg.Endpoint == "" {
+		return func() {}
+	}
+	tracer, err := tracing.NewTracer(tracing.Config{
+		Endpoint:    cfg.Endpoint,
+		ServiceName: cfg.ServiceName,
+		Version:     version,
+		SampleRatio: cfg.SampleRatio,
+		Headers:     cfg.HeaderMap(),
+	})
+	if err != nil {
+		slog.Warn("tracing disabled", "err", err)
+		return func() {}
+	}
+	tracing.SetDefault(tracer)
+	slog.Info("tracing enabled", "endpoint", cfg.Endpoint, "sample_ratio", cfg.SampleRatio)
+	return func() {
+		tracing.SetDefault(nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracer.Shutdown(ctx); err != nil {
+			slog.Warn("tracing flush incomplete", "err", err)
+		}
+	}
+}
+
+// openSecrets loads the master key from the keyring or key file and opens
+// the secrets vault over st.
+func openSecrets(cfg config.SecretsConfig, st *store.Store) (*secrets.Vault, error) {
+	var (
+		key []byte
+		err error
+	)
+	if cfg.Keyring {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		key, err = secrets.LoadKeyring(ctx)
+	} else {
+		key, err = secrets.LoadKeyFile(cfg.KeyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return secrets.New(st, key)
+}
+
+// resolveConfigSecrets expands {{secret "name"}} references in the value of
+// a config setting. When one cannot be resolved the setting's feature
+// should stay off, so it logs why and reports false.
+func resolveConfigSecrets(vault *secrets.Vault, setting, value string) (string, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resolved, _, err := secrets.Expand(ctx, vault.Lookup, value, nil)
+	if err != nil {
+		slog.Warn("config secret unavailable", "setting", setting, "err", err)
+		return "", false
+	}
+	return resolved, true
+}
+
+// startMQTTBridge publishes events to the configured broker. The returned
+// channel closes once the bridge has stopped.
+func startMQTTBridge(ctx context.Context, cfg config.MQTTConfig, hub *events.Hub) <-chan struct{} {
+	done := make(chan struct{})
+	if cfg.Broker == "" {
+		close(done)
+		return done
+	}
+	bridge := mqtt.NewBridge(hub, mqtt.BridgeOptions{
+		Client: mqtt.Options{
+			Broker:   cfg.Broker,
+			ClientID: cfg.ClientID,
+			Username: cfg.Username,
+			Password: cfg.Password,
+		},
+		TopicPrefix: cfg.TopicPrefix,
+		QoS:         byte(cfg.QoS),
+		Retain:      cfg.Retain,
+		Events:      cfg.Events,
+	})
+	go func() {
+		defer close(done)
+		bridge.Run(ctx)
+	}()
+	slog.Info("mqtt bridge enabled", "broker", cfg.Broker, "topic_prefix", cfg.TopicPrefix, "events", len(cfg.Events))
+	return done
+}
+
+// startStatusListener serves only the status page on addr, without
+// authentication. The returned channel closes once the listener has
+// stopped; it is closed right away when addr is empty or page is nil.
+func startStatusListener(ctx context.Context, addr string, page *statuspage.Page) <-chan struct{} {
+	done := make(chan struct{})
+	if addr == "" || page == nil {
+		close(done)
+		return done
+	}
+	mux := http.NewServeMux()
+	page.Register(mux, nil)
+	mux.Handle("GET /{$}", http.RedirectHandler("/status", http.StatusFound))
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	go func() {
+		defer close(done)
+		slog.Info("status page listener enabled", "listen", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("status page listener failed", "listen", addr, "err", err)
+		}
+	}()
+	return done
+}
+
+// addSSHHosts serves each configured SSH host through hub and returns the
+// clients to close on shutdown. Control sockets live in controlDir.
+func addSSHHosts(hub *federation.Hub, hosts []config.SSHHostConfig, controlDir string) []*sshhost.Client {
+	if len(hosts) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(controlDir, 0o700); err != nil {
+		slog.Warn("ssh hosts disabled", "dir", controlDir, "err", err)
+		return nil
+	}
+	clients := make([]*sshhost.Client, 0, len(hosts))
+	for _, host := range hosts {
+		client := sshhost.New(sshhost.Config{
+			Name:           host.Name,
+			Address:        host.Address,
+			Port:           host.Port,
+			User:           host.User,
+			IdentityFile:   host.IdentityFile,
+			KnownHostsFile: host.KnownHostsFile,
+		}, controlDir)
+		hub.AddDirect(federation.Host{
+			Name:       host.Name,
+			Transport:  federation.TransportSSH,
+			RemoteAddr: host.Address,
+		}, api.NewHostHandler(
+			tmux.Service{Command: client.Command},
+			services.NewRemoteManager(host.Name, host.User, client.Run),
+		))
+		clients = append(clients, client)
+		slog.Info("ssh host enabled", "host", host.Name, "address", host.Address)
+	}
+	return clients
+}
+
+func run(version string, cfg config.Config, guard *security.Guard, mux *http.ServeMux) int {
 	server := &http.Server{
 		Addr:         cfg.Address(),
-		Handler:      requestLog(mux),
+		Handler:      requestLog(guard, traceRequests(mux)),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
@@ -248,7 +589,7 @@ func run(version string, cfg config.Config, mux *http.ServeMux) int {
 	slog.Info("security", "token_required", cfg.Server.Token != "", "allowed_origins", len(cfg.Server.AllowedOrigins))
 
 	if cfg.Watchtower.Enabled {
-		slog.Info("watchtower enabled", "tick", cfg.Watchtower.TickInterval, "capture_lines", cfg.Watchtower.CaptureLines)
+		slog.Info("watchtower enabled", "tick", cfg.Watchtower.TickInterval, "capture_lines", cfg.Watchtower.CaptureLines, "pane_log", cfg.Watchtower.PaneLog)
 	} else {
 		slog.Info("watchtower disabled")
 	}
@@ -261,31 +602,17 @@ func run(version string, cfg config.Config, mux *http.ServeMux) int {
 	return 0
 }
 
-func initLogger(level, path string) (func(), error) {
-	var lv slog.Level
-	switch level {
-	case "debug":
-		lv = slog.LevelDebug
-	case "warn":
-		lv = slog.LevelWarn
-	case "error":
-		lv = slog.LevelError
-	default:
-		lv = slog.LevelInfo
-	}
+func initLogger(cfg config.LogConfig) (func(), error) {
 	writer := io.Writer(os.Stderr)
 	closeFn := func() {}
-	if strings.TrimSpace(path) != "" {
-		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
-			return closeFn, fmt.Errorf("create log dir: %w", err)
-		}
-		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // configured daemon log path.
+	if strings.TrimSpace(cfg.Path) != "" {
+		file, err := logging.OpenFile(cfg.Path, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
 		if err != nil {
-			return closeFn, fmt.Errorf("open log file: %w", err)
+			return closeFn, err
 		}
 		writer = io.MultiWriter(os.Stderr, file)
 		closeFn = func() { _ = file.Close() }
 	}
-	slog.SetDefault(slog.New(slog.NewTextHandler(writer, &slog.HandlerOptions{Level: lv})))
+	slog.SetDefault(slog.New(logging.NewHandler(writer, cfg.Format, logging.ParseLevel(cfg.Level))))
 	return closeFn, nil
 }
@@ -3,17 +3,27 @@ package server
 import (
 	"bufio"
 	"context"
+	"io"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/opus-domini/sentinel/internal/backup"
 	"github.com/opus-domini/sentinel/internal/config"
 	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/federation"
+	"github.com/opus-domini/sentinel/internal/logging"
 	"github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/statuspage"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/tracing"
 )
 
 func TestRequestLogSetsRequestIDAndCapturesStatus(t *testing.T) {
@@ -21,14 +31,14 @@ func TestRequestLogSetsRequestIDAndCapturesStatus(t *testing.T) {
 
 	var sawRequestID string
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		sawRequestID, _ = r.Context().Value(requestIDKey{}).(string)
+		sawRequestID = logging.RequestID(r.Context())
 		w.WriteHeader(http.StatusTeapot)
 		_, _ = w.Write([]byte("hi"))
 	})
 
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/probe", nil)
-	requestLog(next).ServeHTTP(rec, req)
+	requestLog(nil, next).ServeHTTP(rec, req)
 
 	if rec.Code != http.StatusTeapot {
 		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTeapot)
@@ -42,6 +52,41 @@ func TestRequestLogSetsRequestIDAndCapturesStatus(t *testing.T) {
 	}
 }
 
+func TestTraceRequestsNamesSpansByRoute(t *testing.T) {
+	bodies := make(chan string, 4)
+	collector := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer collector.Close()
+	tracer, err := tracing.NewTracer(tracing.Config{Endpoint: collector.URL, ServiceName: "sentinel", SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("NewTracer: %v", err)
+	}
+	tracing.SetDefault(tracer)
+	defer tracing.SetDefault(nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/items/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/items/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	traceRequests(mux).ServeHTTP(httptest.NewRecorder(), req)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracer.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	body := <-bodies
+	for _, want := range []string{`"name":"GET /api/items/{id}"`, `"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"`, `"intValue":"502"`, `"code":2`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("export %s lacks %s", body, want)
+		}
+	}
+}
+
 func TestGenerateRequestIDUniqueAndHex(t *testing.T) {
 	t.Parallel()
 
@@ -132,13 +177,31 @@ func TestStatusRecorderHijackUnsupported(t *testing.T) {
 }
 
 func TestInitLogger(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
 	for _, level := range []string{"debug", "warn", "error", "info", "unknown"} {
-		closeLogger, err := initLogger(level, "")
+		closeLogger, err := initLogger(config.LogConfig{Level: level})
 		if err != nil {
 			t.Fatalf("initLogger(%q) error = %v", level, err)
 		}
 		closeLogger()
 	}
+
+	path := filepath.Join(t.TempDir(), "logs", "sentinel.log")
+	closeLogger, err := initLogger(config.LogConfig{Level: "info", Format: "json", Path: path, MaxSizeMB: 1, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("initLogger(json) error = %v", err)
+	}
+	slog.Info("json probe")
+	closeLogger()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if !strings.Contains(string(data), `"msg":"json probe"`) {
+		t.Fatalf("log file = %q, want a JSON record", data)
+	}
 }
 
 func TestStartMetricsTickerStopsOnCancel(t *testing.T) {
@@ -147,7 +210,7 @@ func TestStartMetricsTickerStopsOnCancel(t *testing.T) {
 	hub := events.NewHub()
 	mgr := services.NewManager(time.Now(), nil)
 	ctx, cancel := context.WithCancel(context.Background())
-	done := startMetricsTicker(ctx, mgr, hub)
+	done := startMetricsTicker(ctx, mgr, hub, nil)
 	cancel()
 	select {
 	case <-done:
@@ -156,12 +219,217 @@ func TestStartMetricsTickerStopsOnCancel(t *testing.T) {
 	}
 }
 
+type fakeMetricsHistory struct{}
+
+func (f *fakeMetricsHistory) RecordMetricsSample(context.Context, time.Time, store.MetricsSample) error {
+	return nil
+}
+
+func (f *fakeMetricsHistory) RollupMetricsHistory(context.Context, time.Time) error { return nil }
+
+func (f *fakeMetricsHistory) PruneMetricsHistory(context.Context, time.Time, store.MetricsRetention) (int64, error) {
+	return 0, nil
+}
+
+type fakeStatusHistory struct{}
+
+func (f *fakeStatusHistory) RecordOpsStatusSample(context.Context, string, string, bool, time.Time) error {
+	return nil
+}
+
+func (f *fakeStatusHistory) PruneOpsStatusHistory(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+type fakeBackups struct{}
+
+func (fakeBackups) Check(context.Context) ([]backup.Change, error) { return nil, nil }
+
+func TestMetricsRetentionCapsFinerResolutions(t *testing.T) {
+	t.Parallel()
+
+	day := 24 * time.Hour
+	if got := metricsRetention(90 * day); got != (store.MetricsRetention{Raw: day, Minute: 14 * day, Hour: 90 * day}) {
+		t.Fatalf("metricsRetention(90d) = %+v", got)
+	}
+	if got := metricsRetention(2 * day); got != (store.MetricsRetention{Raw: day, Minute: 2 * day, Hour: 2 * day}) {
+		t.Fatalf("metricsRetention(2d) = %+v", got)
+	}
+}
+
+func TestUPSWatchTransitions(t *testing.T) {
+	t.Parallel()
+
+	online := services.UPSStatus{State: services.UPSStateOnline, RuntimeSec: 1800}
+	onBattery := services.UPSStatus{State: services.UPSStateOnBattery, OnBattery: true, RuntimeSec: 900}
+	draining := services.UPSStatus{State: services.UPSStateOnBattery, OnBattery: true, RuntimeSec: 240}
+	unknown := services.UPSStatus{State: "unknown", RuntimeSec: -1}
+
+	watch := &upsWatch{shutdownEnabled: true, threshold: 5 * time.Minute}
+	steps := []struct {
+		status services.UPSStatus
+		want   string
+	}{
+		{online, ""},
+		{onBattery, upsPowerLost},
+		{unknown, ""},
+		{draining, upsShutdown},
+		{draining, ""},
+		{online, upsPowerRestored},
+		{draining, upsPowerLost + "," + upsShutdown},
+	}
+	for i, step := range steps {
+		if got := strings.Join(watch.observe(step.status), ","); got != step.want {
+			t.Fatalf("step %d: observe(%s, %ds) = %q, want %q", i, step.status.State, step.status.RuntimeSec, got, step.want)
+		}
+	}
+
+	disabled := &upsWatch{threshold: 5 * time.Minute}
+	if got := disabled.observe(draining); len(got) != 1 || got[0] != upsPowerLost {
+		t.Fatalf("observe without a runbook = %v, want only %s", got, upsPowerLost)
+	}
+}
+
+func TestLoginWatchAlerts(t *testing.T) {
+	t.Parallel()
+
+	accepted := func(at, user, host string) services.AuthEvent {
+		return services.AuthEvent{At: at, User: user, Host: host, Result: services.AuthResultAccepted}
+	}
+	history := accepted("2026-02-01T08:00:00Z", "alice", "10.0.0.5")
+	again := accepted("2026-02-15T09:00:00Z", "alice", "10.0.0.5")
+	travel := accepted("2026-02-15T09:00:30Z", "alice", "198.51.100.7")
+	root := accepted("2026-02-15T09:01:00Z", "root", "10.0.0.5")
+	failed := services.AuthEvent{At: "2026-02-15T09:01:10Z", User: "bob", Host: "203.0.113.9", Result: services.AuthResultFailed}
+
+	watch := newLoginWatch(true, true)
+	watch.learn([]services.AuthEvent{history})
+
+	alerts := watch.observe([]services.AuthEvent{again, travel, failed})
+	if len(alerts) != 1 || alerts[0].event != travel || !slices.Equal(alerts[0].reasons, []string{loginAlertNewAddress}) {
+		t.Fatalf("first poll alerts = %+v, want a new address for alice", alerts)
+	}
+	// The next poll overlaps the last second of the previous one.
+	alerts = watch.observe([]services.AuthEvent{travel, root})
+	if len(alerts) != 1 || alerts[0].event != root || !slices.Equal(alerts[0].reasons, []string{loginAlertRoot, loginAlertNewAddress}) {
+		t.Fatalf("second poll alerts = %+v, want one alert for root", alerts)
+	}
+
+	rootOnly := newLoginWatch(true, false)
+	if alerts := rootOnly.observe([]services.AuthEvent{travel, failed}); len(alerts) != 0 {
+		t.Fatalf("root-only alerts = %+v, want none", alerts)
+	}
+}
+
+func TestCertificateWatchAlerts(t *testing.T) {
+	t.Parallel()
+
+	cert := func(target, state string) services.CertificateStatus {
+		return services.CertificateStatus{Target: target, Kind: services.CertificateKindFile, State: state}
+	}
+	targets := func(certs []services.CertificateStatus) string {
+		var out []string
+		for _, c := range certs {
+			out = append(out, c.Target+"="+c.State)
+		}
+		return strings.Join(out, ",")
+	}
+
+	watch := &certificateWatch{}
+	steps := []struct {
+		certs        []services.CertificateStatus
+		wantAlerts   string
+		wantFailures string
+	}{
+		{[]services.CertificateStatus{cert("web", services.CertificateStateValid), cert("mail", services.CertificateStateExpiring)}, "mail=expiring", ""},
+		{[]services.CertificateStatus{cert("web", services.CertificateStateValid), cert("mail", services.CertificateStateExpiring)}, "", ""},
+		{[]services.CertificateStatus{cert("web", services.CertificateStateError), cert("mail", services.CertificateStateExpired)}, "mail=expired", "web=error"},
+		{[]services.CertificateStatus{cert("web", services.CertificateStateExpiring), cert("mail", services.CertificateStateValid)}, "web=expiring", ""},
+		{[]services.CertificateStatus{cert("web", services.CertificateStateExpiring), cert("mail", services.CertificateStateExpiring)}, "mail=expiring", ""},
+	}
+	for i, step := range steps {
+		alerts, failures := watch.observe(step.certs)
+		if got := targets(alerts); got != step.wantAlerts {
+			t.Fatalf("step %d: alerts = %q, want %q", i, got, step.wantAlerts)
+		}
+		if got := targets(failures); got != step.wantFailures {
+			t.Fatalf("step %d: failures = %q, want %q", i, got, step.wantFailures)
+		}
+	}
+}
+
+func TestBackgroundShutdownOrder(t *testing.T) {
+	t.Parallel()
+
+	listenersCtx, stopListeners := context.WithCancel(context.Background())
+	tickersCtx, stopTickers := context.WithCancel(context.Background())
+	b := &background{
+		stopListeners: stopListeners,
+		listeners:     []<-chan struct{}{loopTicker(listenersCtx, time.Hour, func() {})},
+		stopTickers:   stopTickers,
+		tickers:       []<-chan struct{}{loopTicker(tickersCtx, time.Hour, func() {})},
+	}
+
+	drained := false
+	b.shutdown(func() {
+		drained = true
+		if listenersCtx.Err() == nil {
+			t.Error("listeners still running during drain")
+		}
+		if tickersCtx.Err() != nil {
+			t.Error("tickers stopped before drain")
+		}
+	})
+	if !drained || tickersCtx.Err() == nil {
+		t.Fatalf("drained = %v, tickers err = %v", drained, tickersCtx.Err())
+	}
+	for _, done := range append(b.listeners, b.tickers...) {
+		select {
+		case <-done:
+		default:
+			t.Fatal("shutdown returned before a goroutine stopped")
+		}
+	}
+}
+
 func TestStartStoreTickersStopOnCancel(t *testing.T) {
 	t.Parallel()
 
 	tickers := map[string]func(context.Context) <-chan struct{}{
 		"metrics": func(c context.Context) <-chan struct{} {
-			return startMetricsTicker(c, services.NewManager(time.Now(), nil), events.NewHub())
+			return startMetricsTicker(c, services.NewManager(time.Now(), nil), events.NewHub(), nil)
+		},
+		"uptime": func(c context.Context) <-chan struct{} {
+			return startUptimeTicker(c, services.NewManager(time.Now(), nil), events.NewHub())
+		},
+		"heartbeats": func(c context.Context) <-chan struct{} {
+			return startHeartbeatTicker(c, services.NewManager(time.Now(), nil), events.NewHub())
+		},
+		"backups": func(c context.Context) <-chan struct{} {
+			return startBackupsTicker(c, fakeBackups{}, events.NewHub())
+		},
+		"certificates": func(c context.Context) <-chan struct{} {
+			return startCertificateTicker(c, services.NewManager(time.Now(), nil), events.NewHub(), time.Hour)
+		},
+		"status-history": func(c context.Context) <-chan struct{} {
+			return startStatusHistoryTicker(c, services.NewManager(time.Now(), nil), &fakeStatusHistory{}, 30)
+		},
+		"metrics-history": func(c context.Context) <-chan struct{} {
+			return startMetricsHistoryTicker(c, &fakeMetricsHistory{}, 24*time.Hour)
+		},
+		"backup": func(c context.Context) <-chan struct{} {
+			done, err := startBackupSchedule(c, nil, "@hourly", "UTC", t.TempDir(), 1)
+			if err != nil {
+				t.Fatalf("startBackupSchedule: %v", err)
+			}
+			return done
+		},
+		"maintenance": func(c context.Context) <-chan struct{} {
+			done, err := startMaintenanceSchedule(c, nil, "@daily", "UTC")
+			if err != nil {
+				t.Fatalf("startMaintenanceSchedule: %v", err)
+			}
+			return done
 		},
 	}
 	for name, start := range tickers {
@@ -178,6 +446,133 @@ func TestStartStoreTickersStopOnCancel(t *testing.T) {
 	}
 }
 
+func TestStartStatusListener(t *testing.T) {
+	t.Parallel()
+
+	st, err := store.Open(filepath.Join(t.TempDir(), "sentinel.db"), store.Options{})
+	if err != nil {
+		t.Fatalf("store.Open: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	page := statuspage.New(services.NewManager(time.Now(), nil), st, statuspage.Options{Title: "Homelab"})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("reserve port: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := startStatusListener(ctx, addr, page)
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	var resp *http.Response
+	for range 50 {
+		if resp, err = client.Get("http://" + addr + "/status"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "Homelab") {
+		t.Fatalf("GET /status = %d\n%s", resp.StatusCode, body)
+	}
+	for path, want := range map[string]int{"/": http.StatusFound, "/api/ops/overview": http.StatusNotFound} {
+		resp, err := client.Get("http://" + addr + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("GET %s = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("status listener did not stop after cancel")
+	}
+
+	// Without an address nothing is served.
+	select {
+	case <-startStatusListener(context.Background(), "", page):
+	default:
+		t.Fatal("startStatusListener without an address did not return a closed channel")
+	}
+}
+
+func TestOpenSecretsResolvesConfigValues(t *testing.T) {
+	t.Parallel()
+
+	st, err := store.Open(filepath.Join(t.TempDir(), "sentinel.db"), store.Options{})
+	if err != nil {
+		t.Fatalf("store.Open: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+
+	keyFile := filepath.Join(t.TempDir(), "secrets.key")
+	vault, err := openSecrets(config.SecretsConfig{KeyFile: keyFile}, st)
+	if err != nil {
+		t.Fatalf("openSecrets() error = %v", err)
+	}
+	if _, err := vault.Create(context.Background(), "mqtt_password", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	// A restart reads the same key back.
+	if vault, err = openSecrets(config.SecretsConfig{KeyFile: keyFile}, st); err != nil {
+		t.Fatalf("second openSecrets() error = %v", err)
+	}
+
+	if got, ok := resolveConfigSecrets(vault, "mqtt.password", `{{secret "mqtt_password"}}`); !ok || got != "hunter2" {
+		t.Fatalf("resolveConfigSecrets() = %q, %v", got, ok)
+	}
+	if got, ok := resolveConfigSecrets(nil, "mqtt.password", "plain"); !ok || got != "plain" {
+		t.Fatalf("resolveConfigSecrets(plain) = %q, %v", got, ok)
+	}
+	if _, ok := resolveConfigSecrets(vault, "mqtt.password", `{{secret "missing"}}`); ok {
+		t.Fatal("resolveConfigSecrets() resolved a missing secret")
+	}
+}
+
+func TestStartCronSchedulesRejectInvalidCron(t *testing.T) {
+	t.Parallel()
+
+	if _, err := startBackupSchedule(context.Background(), nil, "every day", "UTC", t.TempDir(), 1); err == nil {
+		t.Fatal("startBackupSchedule accepted an invalid cron expression")
+	}
+	if _, err := startMaintenanceSchedule(context.Background(), nil, "nightly", "UTC"); err == nil {
+		t.Fatal("startMaintenanceSchedule accepted an invalid cron expression")
+	}
+}
+
+func TestAddSSHHosts(t *testing.T) {
+	t.Parallel()
+
+	hub := federation.NewHub("")
+	controlDir := filepath.Join(t.TempDir(), "ssh")
+	clients := addSSHHosts(hub, []config.SSHHostConfig{
+		{Name: "db-01", Address: "db-01.internal", User: "deploy"},
+		{Name: "db-02", Address: "10.0.0.12"},
+	}, controlDir)
+	if len(clients) != 2 {
+		t.Fatalf("addSSHHosts() returned %d clients, want 2", len(clients))
+	}
+	hosts := hub.Hosts()
+	if len(hosts) != 2 || hosts[0].Name != "db-01" || hosts[0].Transport != federation.TransportSSH || hosts[1].RemoteAddr != "10.0.0.12" {
+		t.Fatalf("Hosts() = %+v", hosts)
+	}
+	info, err := os.Stat(controlDir)
+	if err != nil || info.Mode().Perm() != 0o700 {
+		t.Fatalf("control dir = %v, %v; want mode 0700", info, err)
+	}
+}
+
 func TestLoopTickerRunsTickThenStops(t *testing.T) {
 	t.Parallel()
 
@@ -234,7 +629,7 @@ func TestRunFailsOnInvalidListenAddr(t *testing.T) {
 	cfg := config.Default()
 	cfg.Server.Host = "localhost"
 	cfg.Server.Port = 999999
-	if code := run("test-version", cfg, http.NewServeMux()); code != 1 {
+	if code := run("test-version", cfg, nil, http.NewServeMux()); code != 1 {
 		t.Fatalf("run() = %d, want 1 for an invalid listen address", code)
 	}
 }
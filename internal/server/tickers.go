@@ -2,12 +2,149 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"time"
 
+	"github.com/opus-domini/sentinel/internal/backup"
+	"github.com/opus-domini/sentinel/internal/config"
 	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/jobqueue"
+	"github.com/opus-domini/sentinel/internal/library"
+	"github.com/opus-domini/sentinel/internal/secrets"
 	"github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/statuspage"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/validate"
 )
 
+// metricsHistoryStore persists sampled host metrics.
+type metricsHistoryStore interface {
+	RecordMetricsSample(ctx context.Context, at time.Time, sample store.MetricsSample) error
+	RollupMetricsHistory(ctx context.Context, now time.Time) error
+	PruneMetricsHistory(ctx context.Context, now time.Time, retention store.MetricsRetention) (int64, error)
+}
+
+// backupStore snapshots the database.
+type backupStore interface {
+	Backup(ctx context.Context, dir string, keep int, now time.Time) (store.BackupInfo, error)
+}
+
+// maintenanceStore checks and compacts the database.
+type maintenanceStore interface {
+	Maintain(ctx context.Context, progress func(store.MaintenanceStep)) (store.MaintenanceReport, error)
+}
+
+// backgroundDeps are what the background tickers and listeners run on.
+// library and statusPage are nil when disabled.
+type backgroundDeps struct {
+	cfg        config.Config
+	version    string
+	mux        http.Handler
+	store      *store.Store
+	ops        *services.Manager
+	hub        *events.Hub
+	backups    backupChecker
+	runbooks   runbookStarter
+	library    *library.Service
+	statusPage *statuspage.Page
+	vault      *secrets.Vault
+}
+
+// background holds the goroutines Serve runs next to the HTTP server.
+// Listeners take work from outside the process: the federation agent, the
+// MQTT bridge and the status page listener. Tickers are the periodic and
+// scheduled jobs.
+type background struct {
+	stopListeners context.CancelFunc
+	listeners     []<-chan struct{}
+	stopTickers   context.CancelFunc
+	tickers       []<-chan struct{}
+}
+
+// startBackground starts the tickers and listeners the config enables.
+func startBackground(d backgroundDeps) *background {
+	cfg := d.cfg
+	listenersCtx, stopListeners := context.WithCancel(context.Background())
+	tickersCtx, stopTickers := context.WithCancel(context.Background())
+	b := &background{stopListeners: stopListeners, stopTickers: stopTickers}
+
+	var history metricsHistoryStore
+	if cfg.Metrics.History {
+		history = d.store
+		b.tickers = append(b.tickers, startMetricsHistoryTicker(tickersCtx, d.store, cfg.Metrics.HistoryRetention))
+	}
+	b.tickers = append(b.tickers,
+		startMetricsTicker(tickersCtx, d.ops, d.hub, history),
+		startServiceHealthTicker(tickersCtx, d.ops, d.hub),
+		startUptimeTicker(tickersCtx, d.ops, d.hub),
+		startHeartbeatTicker(tickersCtx, d.ops, d.hub),
+		startBackupsTicker(tickersCtx, d.backups, d.hub),
+	)
+	if d.library != nil {
+		b.tickers = append(b.tickers, startLibraryTicker(tickersCtx, d.library, d.hub, cfg.Library.SyncInterval))
+		slog.Info("runbook library enabled", "dir", cfg.Library.Dir, "git", cfg.Library.GitURL != "", "interval", cfg.Library.SyncInterval)
+	}
+	if cfg.UPS.Source != "" {
+		b.tickers = append(b.tickers, startUPSTicker(tickersCtx, d.ops, d.hub, d.runbooks, cfg.UPS.ShutdownRunbook, cfg.UPS.ShutdownRuntime))
+	}
+	if cfg.Logins.AlertRoot || cfg.Logins.AlertNewAddress {
+		b.tickers = append(b.tickers, startLoginAlertTicker(tickersCtx, d.ops, d.hub, cfg.Logins.AlertRoot, cfg.Logins.AlertNewAddress))
+	}
+	if len(cfg.Certificates.Paths) > 0 || len(cfg.Certificates.Endpoints) > 0 {
+		b.tickers = append(b.tickers, startCertificateTicker(tickersCtx, d.ops, d.hub, cfg.Certificates.CheckInterval))
+	}
+	if d.statusPage != nil {
+		b.tickers = append(b.tickers, startStatusHistoryTicker(tickersCtx, d.ops, d.store, cfg.StatusPage.HistoryDays))
+	}
+	if cfg.Storage.BackupSchedule != "" {
+		done, err := startBackupSchedule(tickersCtx, d.store, cfg.Storage.BackupSchedule, cfg.Server.Timezone, cfg.Storage.BackupDir, cfg.Storage.BackupKeep)
+		if err != nil {
+			slog.Warn("backup schedule failed to start", "err", err)
+		} else {
+			b.tickers = append(b.tickers, done)
+			slog.Info("scheduled backups enabled", "schedule", cfg.Storage.BackupSchedule, "dir", cfg.Storage.BackupDir, "keep", cfg.Storage.BackupKeep)
+		}
+	}
+	if cfg.Storage.MaintenanceSchedule != "" {
+		done, err := startMaintenanceSchedule(tickersCtx, d.store, cfg.Storage.MaintenanceSchedule, cfg.Server.Timezone)
+		if err != nil {
+			slog.Warn("maintenance schedule failed to start", "err", err)
+		} else {
+			b.tickers = append(b.tickers, done)
+			slog.Info("scheduled database maintenance enabled", "schedule", cfg.Storage.MaintenanceSchedule)
+		}
+	}
+
+	mqttConfig := cfg.MQTT
+	if password, ok := resolveConfigSecrets(d.vault, "mqtt.password", mqttConfig.Password); ok {
+		mqttConfig.Password = password
+	} else {
+		mqttConfig.Broker = ""
+	}
+	b.listeners = append(b.listeners,
+		startFederationAgent(listenersCtx, cfg.Federation, d.version, d.mux),
+		startMQTTBridge(listenersCtx, mqttConfig, d.hub),
+		startStatusListener(listenersCtx, cfg.StatusPage.Listen, d.statusPage),
+	)
+	return b
+}
+
+// shutdown stops the listeners, so no new work arrives, then calls drain
+// and stops the tickers. It returns once every goroutine has stopped.
+func (b *background) shutdown(drain func()) {
+	b.stopListeners()
+	for _, done := range b.listeners {
+		<-done
+	}
+	drain()
+	b.stopTickers()
+	for _, done := range b.tickers {
+		<-done
+	}
+}
+
 // loopTicker runs tick every interval until ctx is cancelled. The returned
 // channel closes once the loop has stopped, so shutdown can wait on it.
 func loopTicker(ctx context.Context, interval time.Duration, tick func()) <-chan struct{} {
@@ -28,18 +165,640 @@ func loopTicker(ctx context.Context, interval time.Duration, tick func()) <-chan
 	return done
 }
 
-func startMetricsTicker(ctx context.Context, mgr *services.Manager, hub *events.Hub) <-chan struct{} {
+// startMetricsTicker samples host metrics every 2s. A nil history skips
+// persistence.
+func startMetricsTicker(ctx context.Context, mgr *services.Manager, hub *events.Hub, history metricsHistoryStore) <-chan struct{} {
 	return loopTicker(ctx, 2*time.Second, func() {
-		publishMetrics(ctx, mgr, hub)
+		m := publishMetrics(ctx, mgr, hub)
+		if history == nil {
+			return
+		}
+		if err := history.RecordMetricsSample(ctx, time.Now(), metricsSample(m)); err != nil && ctx.Err() == nil {
+			slog.Warn("metrics history record failed", "err", err)
+		}
 	})
 }
 
 // publishMetrics samples host metrics and broadcasts them on the event hub.
-func publishMetrics(ctx context.Context, mgr *services.Manager, hub *events.Hub) {
+func publishMetrics(ctx context.Context, mgr *services.Manager, hub *events.Hub) services.HostMetrics {
 	collectCtx, cancel := context.WithTimeout(ctx, 1500*time.Millisecond)
 	m := mgr.Metrics(collectCtx)
 	cancel()
 	hub.Publish(events.NewEvent(events.TypeOpsMetrics, map[string]any{
 		"metrics": m,
 	}))
+	return m
+}
+
+// startServiceHealthTicker runs custom service health checks. A service
+// that turns unhealthy or recovers is logged and announced on the event
+// hub, which also reaches the MQTT bridge.
+func startServiceHealthTicker(ctx context.Context, mgr *services.Manager, hub *events.Hub) <-chan struct{} {
+	return loopTicker(ctx, services.HealthCheckInterval, func() {
+		changes, err := mgr.CheckHealth(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("service health checks failed", "err", err)
+			}
+			return
+		}
+		for _, change := range changes {
+			if change.Health.Healthy {
+				slog.Info("service healthy", "service", change.Name)
+			} else {
+				slog.Warn("service unhealthy", "service", change.Name, "checks", change.Health.Checks)
+			}
+			hub.Publish(events.NewEvent(events.TypeOpsServices, map[string]any{
+				"globalRev": time.Now().UTC().UnixMilli(),
+				"action":    "health",
+				"service":   change.Name,
+				"healthy":   change.Health.Healthy,
+				"health":    change.Health,
+			}))
+		}
+	})
+}
+
+// startUptimeTicker runs the uptime checks as they come due. A check that
+// goes down or comes back up is logged and announced on the event hub,
+// which also reaches the MQTT bridge.
+func startUptimeTicker(ctx context.Context, mgr *services.Manager, hub *events.Hub) <-chan struct{} {
+	return loopTicker(ctx, services.UptimeTickInterval, func() {
+		changes, err := mgr.CheckUptime(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("uptime checks failed", "err", err)
+			}
+			return
+		}
+		for _, change := range changes {
+			check := change.Check
+			switch {
+			case check.Status == store.UptimeStatusDown:
+				slog.Warn("uptime check down", "check", check.Name, "target", check.Target, "detail", check.Detail)
+			case change.Previous == store.UptimeStatusDown:
+				slog.Info("uptime check up", "check", check.Name, "target", check.Target)
+			}
+			hub.Publish(events.NewEvent(events.TypeOpsUptime, map[string]any{
+				"globalRev": time.Now().UTC().UnixMilli(),
+				"action":    check.Status,
+				"previous":  change.Previous,
+				"check":     check,
+			}))
+		}
+	})
+}
+
+// startHeartbeatTicker looks for heartbeats whose check-in is overdue. A
+// missed heartbeat is logged and announced on the event hub, which also
+// reaches the MQTT bridge; the ping handler announces the recovery.
+func startHeartbeatTicker(ctx context.Context, mgr *services.Manager, hub *events.Hub) <-chan struct{} {
+	return loopTicker(ctx, services.HeartbeatTickInterval, func() {
+		missed, err := mgr.CheckHeartbeats(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("heartbeat checks failed", "err", err)
+		}
+		for _, hb := range missed {
+			slog.Warn("heartbeat missed", "heartbeat", hb.Name, "last_ping", hb.LastPingAt, "interval_seconds", hb.IntervalSeconds)
+			hub.Publish(events.NewEvent(events.TypeOpsHeartbeats, map[string]any{
+				"globalRev": time.Now().UTC().UnixMilli(),
+				"action":    store.HeartbeatStatusMissed,
+				"heartbeat": hb,
+			}))
+		}
+	})
+}
+
+// backupChecker starts due restic and borg backups and reports their
+// outcome.
+type backupChecker interface {
+	Check(ctx context.Context) ([]backup.Change, error)
+}
+
+// startBackupsTicker runs the backup checks. Failed and stale backups are
+// logged as warnings and every change is announced on the event hub, which
+// also reaches the MQTT bridge.
+func startBackupsTicker(ctx context.Context, backups backupChecker, hub *events.Hub) <-chan struct{} {
+	return loopTicker(ctx, backup.TickInterval, func() {
+		changes, err := backups.Check(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("backup checks failed", "err", err)
+		}
+		for _, change := range changes {
+			b := change.Backup
+			switch change.Action {
+			case backup.ActionFailed:
+				slog.Warn("backup failed", "backup", b.Name, "job", b.LastJobID, "err", b.LastError)
+			case backup.ActionStale:
+				slog.Warn("backup stale", "backup", b.Name, "last_success", b.LastSuccessAt, "max_age_hours", b.MaxAgeHours)
+			case backup.ActionSucceeded:
+				slog.Info("backup succeeded", "backup", b.Name, "snapshot", b.Stats.SnapshotID, "bytes_added", b.Stats.BytesAdded)
+			}
+			hub.Publish(events.NewEvent(events.TypeOpsBackups, map[string]any{
+				"globalRev": time.Now().UTC().UnixMilli(),
+				"action":    change.Action,
+				"backup":    b,
+			}))
+		}
+	})
+}
+
+// librarySyncer loads the runbook library into the store.
+type librarySyncer interface {
+	Sync(ctx context.Context) (library.Result, error)
+}
+
+// startLibraryTicker syncs the runbook library at startup and then every
+// interval. Syncs that change runbooks or schedules are announced on the
+// event hub.
+func startLibraryTicker(ctx context.Context, lib librarySyncer, hub *events.Hub, interval time.Duration) <-chan struct{} {
+	tick := func() {
+		result, err := lib.Sync(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err != nil:
+			slog.Warn("library sync failed", "err", err)
+			return
+		case result.GitError != "":
+			slog.Warn("library git pull failed; loaded the existing checkout", "err", result.GitError)
+		}
+		for _, fe := range result.Errors {
+			slog.Warn("library file not applied", "file", fe.File, "err", fe.Error)
+		}
+		if !result.Changed() {
+			return
+		}
+		slog.Info("library synced", "revision", result.Revision, "created", result.Created, "updated", result.Updated, "deleted", result.Deleted)
+		hub.Publish(events.NewEvent(events.TypeOpsLibrary, map[string]any{
+			"globalRev": time.Now().UTC().UnixMilli(),
+			"library":   result,
+		}))
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tick()
+		<-loopTicker(ctx, interval, tick)
+	}()
+	return done
+}
+
+// statusHistoryInterval is how often tracked services are sampled for the
+// status page history.
+const statusHistoryInterval = time.Minute
+
+// serviceLister lists the tracked services.
+type serviceLister interface {
+	ListServices(ctx context.Context) ([]services.ServiceStatus, error)
+}
+
+// statusHistoryStore keeps the daily status page history.
+type statusHistoryStore interface {
+	RecordOpsStatusSample(ctx context.Context, kind, name string, up bool, at time.Time) error
+	PruneOpsStatusHistory(ctx context.Context, before time.Time) (int64, error)
+}
+
+// startStatusHistoryTicker samples the tracked services for the status page
+// history and drops the days older than the page shows. Uptime checks count
+// their own runs as they are recorded.
+func startStatusHistoryTicker(ctx context.Context, svc serviceLister, history statusHistoryStore, days int) <-chan struct{} {
+	return loopTicker(ctx, statusHistoryInterval, func() {
+		now := time.Now()
+		list, err := svc.ListServices(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("status history sample failed", "err", err)
+			}
+			return
+		}
+		for _, s := range list {
+			if err := history.RecordOpsStatusSample(ctx, store.StatusHistoryService, s.Name, statuspage.ServiceUp(s), now); err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("status history record failed", "service", s.Name, "err", err)
+				}
+				return
+			}
+		}
+		if _, err := history.PruneOpsStatusHistory(ctx, now.AddDate(0, 0, -days)); err != nil && ctx.Err() == nil {
+			slog.Warn("status history prune failed", "err", err)
+		}
+	})
+}
+
+const (
+	upsPollInterval = 10 * time.Second
+	upsRunSource    = "ups"
+
+	upsPowerLost     = "power-lost"
+	upsPowerRestored = "power-restored"
+	upsShutdown      = "shutdown"
+)
+
+// upsReader reads the UPS power state.
+type upsReader interface {
+	UPS(ctx context.Context) (services.UPSStatus, error)
+}
+
+// runbookStarter queues runbook runs.
+type runbookStarter interface {
+	StartWithPriority(ctx context.Context, runbookID string, params map[string]string, hosts, source string, priority jobqueue.Priority) (store.OpsRunbookRun, error)
+}
+
+// upsWatch turns successive UPS readings into power transitions. The
+// shutdown transition fires once per outage, when the UPS reports a low
+// battery or at most threshold of runtime left, and only if a shutdown
+// runbook is configured.
+type upsWatch struct {
+	shutdownEnabled bool
+	threshold       time.Duration
+
+	onBattery     bool
+	shutdownFired bool
+}
+
+func (w *upsWatch) observe(status services.UPSStatus) []string {
+	var actions []string
+	switch {
+	case status.OnBattery && !w.onBattery:
+		w.onBattery = true
+		actions = append(actions, upsPowerLost)
+	case !status.OnBattery && w.onBattery && status.State == services.UPSStateOnline:
+		w.onBattery = false
+		w.shutdownFired = false
+		actions = append(actions, upsPowerRestored)
+	}
+	if !w.onBattery || !w.shutdownEnabled || w.shutdownFired {
+		return actions
+	}
+	lowRuntime := status.RuntimeSec >= 0 && time.Duration(status.RuntimeSec)*time.Second <= w.threshold
+	if status.LowBattery || lowRuntime {
+		w.shutdownFired = true
+		actions = append(actions, upsShutdown)
+	}
+	return actions
+}
+
+// startUPSTicker polls the UPS. Losing and regaining mains power is logged
+// and announced on the event hub; when the battery runs low the configured
+// shutdown runbook is started at high priority.
+func startUPSTicker(ctx context.Context, ups upsReader, hub *events.Hub, runbooks runbookStarter, runbookID string, threshold time.Duration) <-chan struct{} {
+	watch := &upsWatch{shutdownEnabled: runbookID != "" && runbooks != nil, threshold: threshold}
+	failing := false
+	return loopTicker(ctx, upsPollInterval, func() {
+		readCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		status, err := ups.UPS(readCtx)
+		cancel()
+		if err != nil {
+			// Warn once per failure streak rather than every poll.
+			if !failing && ctx.Err() == nil {
+				slog.Warn("ups status read failed", "err", err)
+			}
+			failing = true
+			return
+		}
+		failing = false
+		for _, action := range watch.observe(status) {
+			payload := map[string]any{
+				"globalRev": time.Now().UTC().UnixMilli(),
+				"action":    action,
+				"ups":       status,
+			}
+			switch action {
+			case upsPowerLost:
+				slog.Warn("ups on battery", "ups", status.Name, "charge", status.ChargePercent, "runtime_sec", status.RuntimeSec)
+			case upsPowerRestored:
+				slog.Info("ups power restored", "ups", status.Name, "charge", status.ChargePercent)
+			case upsShutdown:
+				run, err := runbooks.StartWithPriority(ctx, runbookID, nil, "", upsRunSource, jobqueue.PriorityHigh)
+				if err != nil {
+					// Retry on the next poll.
+					watch.shutdownFired = false
+					slog.Error("ups shutdown runbook failed to start", "runbook", runbookID, "err", err)
+					continue
+				}
+				slog.Warn("ups battery low, shutdown runbook started", "runbook", runbookID, "job", run.ID, "runtime_sec", status.RuntimeSec)
+				payload["job"] = run
+			}
+			hub.Publish(events.NewEvent(events.TypeOpsUPS, payload))
+		}
+	})
+}
+
+const (
+	loginPollInterval = 30 * time.Second
+	// loginHistoryWindow is how far back the first poll reads the journal
+	// to learn the addresses each user logs in from.
+	loginHistoryWindow = 30 * 24 * time.Hour
+
+	loginAlertRoot       = "root-login"
+	loginAlertNewAddress = "new-address"
+)
+
+// authEventReader reads sshd authentication attempts.
+type authEventReader interface {
+	AuthEvents(ctx context.Context, since time.Time) ([]services.AuthEvent, error)
+}
+
+// loginWatch raises alerts on successful SSH logins. It remembers the
+// addresses each user logged in from and the events of the last poll,
+// which the next poll reads again because journalctl --since has second
+// resolution.
+type loginWatch struct {
+	alertRoot       bool
+	alertNewAddress bool
+
+	addresses map[string]map[string]bool
+	lastPoll  map[services.AuthEvent]bool
+}
+
+func newLoginWatch(alertRoot, alertNewAddress bool) *loginWatch {
+	return &loginWatch{
+		alertRoot:       alertRoot,
+		alertNewAddress: alertNewAddress,
+		addresses:       make(map[string]map[string]bool),
+		lastPoll:        make(map[services.AuthEvent]bool),
+	}
+}
+
+// learn records the addresses of accepted logins without alerting.
+func (w *loginWatch) learn(events []services.AuthEvent) {
+	for _, event := range events {
+		w.lastPoll[event] = true
+		w.seen(event)
+	}
+}
+
+// seen records the address of an accepted login and reports whether the
+// user had logged in from it before.
+func (w *loginWatch) seen(event services.AuthEvent) bool {
+	if event.Result != services.AuthResultAccepted {
+		return true
+	}
+	known := w.addresses[event.User]
+	if known == nil {
+		known = make(map[string]bool)
+		w.addresses[event.User] = known
+	}
+	if known[event.Host] {
+		return true
+	}
+	known[event.Host] = true
+	return false
+}
+
+// loginAlert is an accepted login with the reasons it raised an alert.
+type loginAlert struct {
+	event   services.AuthEvent
+	reasons []string
+}
+
+// observe returns the alerts raised by the accepted logins in events that
+// the last poll did not report.
+func (w *loginWatch) observe(events []services.AuthEvent) []loginAlert {
+	var alerts []loginAlert
+	current := make(map[services.AuthEvent]bool, len(events))
+	for _, event := range events {
+		current[event] = true
+		if w.lastPoll[event] {
+			continue
+		}
+		if event.Result != services.AuthResultAccepted {
+			continue
+		}
+		newAddress := !w.seen(event)
+		var reasons []string
+		if w.alertRoot && event.User == "root" {
+			reasons = append(reasons, loginAlertRoot)
+		}
+		if w.alertNewAddress && newAddress {
+			reasons = append(reasons, loginAlertNewAddress)
+		}
+		if len(reasons) > 0 {
+			alerts = append(alerts, loginAlert{event: event, reasons: reasons})
+		}
+	}
+	w.lastPoll = current
+	return alerts
+}
+
+// startLoginAlertTicker polls the sshd journal and logs and announces
+// logins matching the enabled alerts. The first poll only learns the
+// addresses seen in the last loginHistoryWindow.
+func startLoginAlertTicker(ctx context.Context, auth authEventReader, hub *events.Hub, alertRoot, alertNewAddress bool) <-chan struct{} {
+	watch := newLoginWatch(alertRoot, alertNewAddress)
+	var since time.Time
+	failing := false
+	return loopTicker(ctx, loginPollInterval, func() {
+		now := time.Now()
+		from := since
+		if from.IsZero() {
+			from = now.Add(-loginHistoryWindow)
+		}
+		readCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		authEvents, err := auth.AuthEvents(readCtx, from)
+		cancel()
+		if err != nil {
+			if !failing && ctx.Err() == nil {
+				slog.Warn("ssh auth events read failed", "err", err)
+			}
+			failing = true
+			return
+		}
+		failing = false
+		if since.IsZero() {
+			watch.learn(authEvents)
+			since = now
+			return
+		}
+		for _, alert := range watch.observe(authEvents) {
+			slog.Warn("ssh login alert", "user", alert.event.User, "host", alert.event.Host, "reasons", alert.reasons)
+			hub.Publish(events.NewEvent(events.TypeOpsLogins, map[string]any{
+				"globalRev": time.Now().UTC().UnixMilli(),
+				"action":    "alert",
+				"reasons":   alert.reasons,
+				"event":     alert.event,
+			}))
+		}
+		since = now
+	})
+}
+
+// certificateChecker checks the watched certificates.
+type certificateChecker interface {
+	Certificates(ctx context.Context, refresh bool) services.Certificates
+}
+
+// certificateWatch remembers the state of each watched certificate so an
+// alert is raised once when it turns expiring and once when it expires,
+// and again after a renewed certificate runs out.
+type certificateWatch struct {
+	states map[string]string
+}
+
+// observe returns the certificates whose state changed to expiring or
+// expired, and the ones that turned unreadable.
+func (w *certificateWatch) observe(certs []services.CertificateStatus) (alerts, failures []services.CertificateStatus) {
+	if w.states == nil {
+		w.states = make(map[string]string)
+	}
+	for _, cert := range certs {
+		key := cert.Kind + ":" + cert.Target
+		previous, known := w.states[key]
+		w.states[key] = cert.State
+		if known && previous == cert.State {
+			continue
+		}
+		switch cert.State {
+		case services.CertificateStateExpiring, services.CertificateStateExpired:
+			alerts = append(alerts, cert)
+		case services.CertificateStateError:
+			failures = append(failures, cert)
+		}
+	}
+	return alerts, failures
+}
+
+// startCertificateTicker checks the watched certificates at startup and
+// every interval. Certificates that turn expiring or expired are logged and
+// announced on the event hub.
+func startCertificateTicker(ctx context.Context, checker certificateChecker, hub *events.Hub, interval time.Duration) <-chan struct{} {
+	watch := &certificateWatch{}
+	tick := func() {
+		result := checker.Certificates(ctx, true)
+		if ctx.Err() != nil {
+			return
+		}
+		alerts, failures := watch.observe(result.Certificates)
+		for _, cert := range failures {
+			slog.Warn("certificate check failed", "target", cert.Target, "err", cert.Error)
+		}
+		for _, cert := range alerts {
+			slog.Warn("certificate expiry alert", "state", cert.State, "target", cert.Target, "not_after", cert.NotAfter, "days_left", cert.DaysLeft)
+			hub.Publish(events.NewEvent(events.TypeOpsCertificates, map[string]any{
+				"globalRev":   time.Now().UTC().UnixMilli(),
+				"action":      cert.State,
+				"certificate": cert,
+			}))
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tick()
+		<-loopTicker(ctx, interval, tick)
+	}()
+	return done
+}
+
+// startMetricsHistoryTicker rolls raw samples up into 1m and 1h buckets and
+// prunes each resolution past its retention once a minute.
+func startMetricsHistoryTicker(ctx context.Context, history metricsHistoryStore, retention time.Duration) <-chan struct{} {
+	keep := metricsRetention(retention)
+	return loopTicker(ctx, time.Minute, func() {
+		now := time.Now()
+		if err := history.RollupMetricsHistory(ctx, now); err != nil {
+			slog.Warn("metrics history rollup failed", "err", err)
+			return
+		}
+		if _, err := history.PruneMetricsHistory(ctx, now, keep); err != nil {
+			slog.Warn("metrics history prune failed", "err", err)
+		}
+	})
+}
+
+// metricsRetention derives per-resolution retention from the configured
+// hourly retention: raw samples are kept for a day and minute buckets for
+// two weeks, never longer than the hourly window.
+func metricsRetention(total time.Duration) store.MetricsRetention {
+	return store.MetricsRetention{
+		Raw:    min(24*time.Hour, total),
+		Minute: min(14*24*time.Hour, total),
+		Hour:   total,
+	}
+}
+
+func metricsSample(m services.HostMetrics) store.MetricsSample {
+	return store.MetricsSample{
+		CPUPercent:    m.CPUPercent,
+		LoadAvg1:      m.LoadAvg1,
+		MemPercent:    m.MemPercent,
+		MemUsedBytes:  m.MemUsedBytes,
+		SwapPercent:   m.SwapPercent,
+		DiskPercent:   m.DiskPercent,
+		DiskUsedBytes: m.DiskUsedBytes,
+		NetRxBytes:    m.NetRxBytes,
+		NetTxBytes:    m.NetTxBytes,
+		NetRxRate:     m.NetRxBytesPerSec,
+		NetTxRate:     m.NetTxBytesPerSec,
+		NetRxErrors:   m.NetRxErrors,
+		NetTxErrors:   m.NetTxErrors,
+		NetSaturation: m.NetSaturationPercent,
+	}
+}
+
+// startBackupSchedule snapshots the database at each cron occurrence of
+// schedule, evaluated in timezone (UTC when invalid). The returned channel
+// closes once the loop has stopped.
+func startBackupSchedule(ctx context.Context, st backupStore, schedule, timezone, dir string, keep int) (<-chan struct{}, error) {
+	return startCronLoop(ctx, "backup", schedule, timezone, func() {
+		backup, err := st.Backup(ctx, dir, keep, time.Now())
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("scheduled backup failed", "err", err)
+			}
+			return
+		}
+		slog.Info("scheduled backup written", "path", backup.Path, "size", backup.SizeBytes)
+	})
+}
+
+// startMaintenanceSchedule runs database maintenance at each cron
+// occurrence of schedule, evaluated in timezone (UTC when invalid).
+func startMaintenanceSchedule(ctx context.Context, st maintenanceStore, schedule, timezone string) (<-chan struct{}, error) {
+	return startCronLoop(ctx, "maintenance", schedule, timezone, func() {
+		report, err := st.Maintain(ctx, func(step store.MaintenanceStep) {
+			slog.Debug("database maintenance step", "step", step.Name, "durationMs", step.DurationMs, "detail", step.Detail)
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("scheduled database maintenance failed", "err", err)
+			}
+			return
+		}
+		if !report.IntegrityOK {
+			slog.Error("database integrity check failed", "problems", report.Problems)
+		}
+		slog.Info("scheduled database maintenance done", "bytesBefore", report.BytesBefore, "bytesAfter", report.BytesAfter)
+	})
+}
+
+// startCronLoop calls run at each cron occurrence of schedule until ctx is
+// cancelled. The returned channel closes once the loop has stopped.
+func startCronLoop(ctx context.Context, name, schedule, timezone string, run func()) (<-chan struct{}, error) {
+	sched, err := validate.ParseCron(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s schedule: %w", name, err)
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			timer := time.NewTimer(time.Until(sched.Next(time.Now().In(loc))))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			run()
+		}
+	}()
+	return done, nil
 }
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Certificate kinds.
+const (
+	CertificateKindFile     = "file"
+	CertificateKindEndpoint = "endpoint"
+)
+
+// Certificate states.
+const (
+	CertificateStateValid    = "valid"
+	CertificateStateExpiring = "expiring"
+	CertificateStateExpired  = "expired"
+	CertificateStateError    = "error"
+)
+
+const (
+	// DefaultCertificateWarnDays is how many days before expiry a
+	// certificate is reported as expiring.
+	DefaultCertificateWarnDays = 30
+
+	certificateDialTimeout = 10 * time.Second
+	defaultTLSPort         = "443"
+)
+
+// CertificateStatus is the expiry of a watched certificate file or TLS
+// endpoint. Files report their first certificate and endpoints the one the
+// server presents, which for both is normally the leaf.
+type CertificateStatus struct {
+	Target    string   `json:"target"`
+	Kind      string   `json:"kind"`
+	State     string   `json:"state"`
+	Subject   string   `json:"subject,omitempty"`
+	Issuer    string   `json:"issuer,omitempty"`
+	DNSNames  []string `json:"dnsNames,omitempty"`
+	NotBefore string   `json:"notBefore,omitempty"`
+	NotAfter  string   `json:"notAfter,omitempty"`
+	DaysLeft  int      `json:"daysLeft"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// Certificates lists the watched certificates from the last check.
+type Certificates struct {
+	Certificates []CertificateStatus `json:"certificates"`
+	WarnDays     int                 `json:"warnDays"`
+	CheckedAt    string              `json:"checkedAt,omitempty"`
+}
+
+// SetCertificates sets the certificate files and TLS endpoints (host or
+// host:port, port 443 by default) to watch. It is called once at startup.
+func (m *Manager) SetCertificates(paths, endpoints []string, warnDays int) {
+	if warnDays <= 0 {
+		warnDays = DefaultCertificateWarnDays
+	}
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+	m.certPaths = slices.Clone(paths)
+	m.certEndpoints = slices.Clone(endpoints)
+	m.certWarnDays = warnDays
+	m.certs = nil
+}
+
+// Certificates returns the last certificate check, checking every target
+// first when none ran yet or refresh is set.
+func (m *Manager) Certificates(ctx context.Context, refresh bool) Certificates {
+	m.metricsMu.Lock()
+	cached := m.certs
+	paths, endpoints, warnDays := m.certPaths, m.certEndpoints, m.certWarnDays
+	m.metricsMu.Unlock()
+	if cached != nil && !refresh {
+		return *cached
+	}
+	if warnDays <= 0 {
+		warnDays = DefaultCertificateWarnDays
+	}
+
+	now := m.nowFn()
+	out := Certificates{
+		Certificates: make([]CertificateStatus, 0, len(paths)+len(endpoints)),
+		WarnDays:     warnDays,
+		CheckedAt:    now.UTC().Format(time.RFC3339),
+	}
+	for _, path := range paths {
+		cert, err := readCertificateFile(path)
+		out.Certificates = append(out.Certificates, certificateStatus(path, CertificateKindFile, cert, err, now, warnDays))
+	}
+	for _, endpoint := range endpoints {
+		cert, err := dialCertificate(ctx, endpoint)
+		out.Certificates = append(out.Certificates, certificateStatus(endpoint, CertificateKindEndpoint, cert, err, now, warnDays))
+	}
+
+	m.metricsMu.Lock()
+	m.certs = &out
+	m.metricsMu.Unlock()
+	return out
+}
+
+func certificateStatus(target, kind string, cert *x509.Certificate, err error, now time.Time, warnDays int) CertificateStatus {
+	status := CertificateStatus{Target: target, Kind: kind}
+	if err != nil {
+		status.State = CertificateStateError
+		status.Error = err.Error()
+		return status
+	}
+	status.Subject = cert.Subject.String()
+	status.Issuer = cert.Issuer.String()
+	status.DNSNames = cert.DNSNames
+	status.NotBefore = cert.NotBefore.UTC().Format(time.RFC3339)
+	status.NotAfter = cert.NotAfter.UTC().Format(time.RFC3339)
+	left := cert.NotAfter.Sub(now)
+	status.DaysLeft = int(left / (24 * time.Hour))
+	switch {
+	case left <= 0:
+		status.State = CertificateStateExpired
+	case status.DaysLeft < warnDays:
+		status.State = CertificateStateExpiring
+	default:
+		status.State = CertificateStateValid
+	}
+	return status
+}
+
+// readCertificateFile parses the first certificate of a PEM file, or of a
+// DER file when it holds no PEM block.
+func readCertificateFile(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // paths come from the operator's config.
+	if err != nil {
+		return nil, err
+	}
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, errors.New("no certificate found")
+	}
+	return cert, nil
+}
+
+// dialCertificate completes a TLS handshake with endpoint and returns the
+// certificate it presents. The chain is not verified: a self-signed or
+// untrusted certificate still has an expiry worth watching.
+func dialCertificate(ctx context.Context, endpoint string) (*x509.Certificate, error) {
+	addr := CertificateEndpointAddr(endpoint)
+	host, _, _ := net.SplitHostPort(addr)
+	ctx, cancel := context.WithTimeout(ctx, certificateDialTimeout)
+	defer cancel()
+	dialer := &tls.Dialer{Config: &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true, //nolint:gosec // only the expiry is read.
+	}}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	peers := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(peers) == 0 {
+		return nil, fmt.Errorf("%s presented no certificate", addr)
+	}
+	return peers[0], nil
+}
+
+// CertificateEndpointAddr returns the host:port dialed for a watched
+// endpoint, adding port 443 when it has none.
+func CertificateEndpointAddr(endpoint string) string {
+	endpoint = strings.TrimSpace(endpoint)
+	if _, _, err := net.SplitHostPort(endpoint); err == nil {
+		return endpoint
+	}
+	return net.JoinHostPort(strings.Trim(endpoint, "[]"), defaultTLSPort)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestCertificate(t *testing.T, dir, name string, notAfter time.Time) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	path := filepath.Join(dir, name+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestCertificates(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	valid := writeTestCertificate(t, dir, "valid.example.com", now.AddDate(0, 6, 0))
+	expiring := writeTestCertificate(t, dir, "expiring.example.com", now.Add(10*24*time.Hour+time.Hour))
+	expired := writeTestCertificate(t, dir, "expired.example.com", now.Add(-time.Hour))
+	missing := filepath.Join(dir, "missing.pem")
+
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	// The check closes the connection right after the handshake.
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	endpoint := strings.TrimPrefix(srv.URL, "https://")
+
+	m := newTestManager("linux", nil)
+	m.SetCertificates([]string{valid, expiring, expired, missing}, []string{endpoint}, 14)
+	got := m.Certificates(context.Background(), false)
+	if got.WarnDays != 14 || got.CheckedAt != "2026-02-15T12:00:00Z" || len(got.Certificates) != 5 {
+		t.Fatalf("certificates = %+v", got)
+	}
+
+	want := []struct {
+		target, kind, state string
+		daysLeft            int
+	}{
+		{valid, CertificateKindFile, CertificateStateValid, 181},
+		{expiring, CertificateKindFile, CertificateStateExpiring, 10},
+		{expired, CertificateKindFile, CertificateStateExpired, 0},
+		{missing, CertificateKindFile, CertificateStateError, 0},
+	}
+	for i, w := range want {
+		c := got.Certificates[i]
+		if c.Target != w.target || c.Kind != w.kind || c.State != w.state || c.DaysLeft != w.daysLeft {
+			t.Fatalf("certificate %d = %+v, want %s %s with %d days left", i, c, w.target, w.state, w.daysLeft)
+		}
+	}
+	if c := got.Certificates[1]; c.Subject != "CN=expiring.example.com" || len(c.DNSNames) != 1 || c.NotAfter != "2026-02-25T13:00:00Z" {
+		t.Fatalf("expiring certificate = %+v", c)
+	}
+	if c := got.Certificates[3]; c.Error == "" {
+		t.Fatalf("missing certificate = %+v, want an error", c)
+	}
+	if c := got.Certificates[4]; c.Kind != CertificateKindEndpoint || c.State != CertificateStateValid || c.NotAfter == "" {
+		t.Fatalf("endpoint certificate = %+v", c)
+	}
+
+	// The cached check is served until a refresh.
+	if err := os.Remove(valid); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if cached := m.Certificates(context.Background(), false); cached.Certificates[0].State != CertificateStateValid {
+		t.Fatalf("cached certificate = %+v", cached.Certificates[0])
+	}
+	if refreshed := m.Certificates(context.Background(), true); refreshed.Certificates[0].State != CertificateStateError {
+		t.Fatalf("refreshed certificate = %+v", refreshed.Certificates[0])
+	}
+}
+
+func TestCertificateEndpointAddr(t *testing.T) {
+	t.Parallel()
+
+	for endpoint, want := range map[string]string{
+		"example.com":      "example.com:443",
+		"example.com:8443": "example.com:8443",
+		"[::1]":            "[::1]:443",
+		"[::1]:8443":       "[::1]:8443",
+	} {
+		if got := CertificateEndpointAddr(endpoint); got != want {
+			t.Errorf("CertificateEndpointAddr(%q) = %q, want %q", endpoint, got, want)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	diskScanTTL     = 15 * time.Minute
+	diskScanTimeout = 5 * time.Minute
+	// diskScanDepth is how many directory levels below a root are ranked.
+	diskScanDepth = 2
+	diskScanTopN  = 20
+)
+
+// DefaultDiskScanRoots are scanned when no roots are configured.
+var DefaultDiskScanRoots = []string{"/"}
+
+// DirectoryUsage is the allocated size of everything below a directory.
+type DirectoryUsage struct {
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"sizeBytes"`
+}
+
+// DiskScan holds the largest directories found under one scan root.
+type DiskScan struct {
+	Root        string           `json:"root"`
+	SizeBytes   int64            `json:"sizeBytes"`
+	Directories []DirectoryUsage `json:"directories"`
+	Skipped     int              `json:"skipped,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// DiskUsage combines per-mount usage with the latest directory scan. Scans
+// run in the background; Scanning reports whether one is in progress and
+// Roots holds the previous results until it completes.
+type DiskUsage struct {
+	Mounts    []DiskMount `json:"mounts"`
+	Roots     []DiskScan  `json:"roots"`
+	ScannedAt string      `json:"scannedAt,omitempty"`
+	Scanning  bool        `json:"scanning"`
+}
+
+// DiskUsage returns mount usage and the cached largest-directory scan,
+// starting a new scan when the cache is stale or refresh is set.
+func (m *Manager) DiskUsage(ctx context.Context, refresh bool) DiskUsage {
+	if m != nil && m.remote {
+		return DiskUsage{Mounts: []DiskMount{}, Roots: []DiskScan{}}
+	}
+	usage := m.diskScanner().snapshot(refresh)
+	usage.Mounts = m.Metrics(ctx).DiskMounts
+	if usage.Mounts == nil {
+		usage.Mounts = []DiskMount{}
+	}
+	return usage
+}
+
+// SetDiskScanRoots replaces the directories scanned for large children.
+// Cached results are dropped so the next request scans the new roots.
+func (m *Manager) SetDiskScanRoots(roots []string) {
+	m.diskScanner().setRoots(roots)
+}
+
+func (m *Manager) diskScanner() *diskScanner {
+	if m == nil {
+		return newDiskScanner(DefaultDiskScanRoots)
+	}
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+	if m.diskScan == nil {
+		m.diskScan = newDiskScanner(DefaultDiskScanRoots)
+	}
+	return m.diskScan
+}
+
+type diskScanner struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	nowFn  func() time.Time
+	scanFn func(context.Context, string) DiskScan
+
+	roots     []string
+	results   []DiskScan
+	scannedAt time.Time
+	scanning  bool
+}
+
+func newDiskScanner(roots []string) *diskScanner {
+	return &diskScanner{
+		nowFn:  time.Now,
+		scanFn: scanDiskRoot,
+		roots:  append([]string(nil), roots...),
+	}
+}
+
+func (s *diskScanner) setRoots(roots []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roots = append([]string(nil), roots...)
+	s.results = nil
+	s.scannedAt = time.Time{}
+}
+
+func (s *diskScanner) snapshot(refresh bool) DiskUsage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stale := s.scannedAt.IsZero() || s.nowFn().Sub(s.scannedAt) >= diskScanTTL
+	if (stale || refresh) && !s.scanning {
+		s.scanning = true
+		s.wg.Add(1)
+		go s.run(append([]string(nil), s.roots...))
+	}
+
+	usage := DiskUsage{
+		Roots:    append([]DiskScan{}, s.results...),
+		Scanning: s.scanning,
+	}
+	if !s.scannedAt.IsZero() {
+		usage.ScannedAt = s.scannedAt.UTC().Format(time.RFC3339)
+	}
+	return usage
+}
+
+func (s *diskScanner) run(roots []string) {
+	defer s.wg.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), diskScanTimeout)
+	defer cancel()
+
+	results := make([]DiskScan, 0, len(roots))
+	for _, root := range roots {
+		results = append(results, s.scanFn(ctx, root))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = results
+	s.scannedAt = s.nowFn()
+	s.scanning = false
+}
+
+// scanDiskRoot walks root without crossing filesystem boundaries and ranks
+// the directories up to diskScanDepth levels below it by allocated size.
+// Unreadable entries are counted in Skipped; hard links are counted once per
+// path.
+func scanDiskRoot(ctx context.Context, root string) DiskScan {
+	root = filepath.Clean(root)
+	scan := DiskScan{Root: root, Directories: []DirectoryUsage{}}
+	info, err := os.Stat(root)
+	if err != nil {
+		scan.Error = err.Error()
+		return scan
+	}
+	if !info.IsDir() {
+		scan.Error = "not a directory"
+		return scan
+	}
+	rootDev, _, sameDevice := fileUsage(info)
+
+	sizes := make(map[string]int64)
+	walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			scan.Skipped++
+			if d != nil && d.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			scan.Skipped++
+			return nil
+		}
+		dev, size, ok := fileUsage(info)
+		if d.IsDir() && path != root && sameDevice && ok && dev != rootDev {
+			return fs.SkipDir
+		}
+		scan.SizeBytes += size
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		parts := strings.Split(rel, string(filepath.Separator))
+		levels := len(parts)
+		if !d.IsDir() {
+			levels--
+		}
+		levels = min(levels, diskScanDepth)
+		for level := 1; level <= levels; level++ {
+			sizes[filepath.Join(root, filepath.Join(parts[:level]...))] += size
+		}
+		return nil
+	})
+	if walkErr != nil {
+		scan.Error = walkErr.Error()
+	}
+
+	for path, size := range sizes {
+		scan.Directories = append(scan.Directories, DirectoryUsage{Path: path, SizeBytes: size})
+	}
+	sort.Slice(scan.Directories, func(i, j int) bool {
+		if scan.Directories[i].SizeBytes != scan.Directories[j].SizeBytes {
+			return scan.Directories[i].SizeBytes > scan.Directories[j].SizeBytes
+		}
+		return scan.Directories[i].Path < scan.Directories[j].Path
+	})
+	if len(scan.Directories) > diskScanTopN {
+		scan.Directories = scan.Directories[:diskScanTopN]
+	}
+	return scan
+}
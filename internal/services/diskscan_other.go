@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd
+
+package services
+
+import "io/fs"
+
+func fileUsage(info fs.FileInfo) (dev uint64, size int64, ok bool) {
+	return 0, info.Size(), false
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScanDiskRootRanksDirectories(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeSizedFile(t, filepath.Join(root, "logs", "app", "big.log"), 256<<10)
+	writeSizedFile(t, filepath.Join(root, "logs", "small.log"), 4<<10)
+	writeSizedFile(t, filepath.Join(root, "cache", "deep", "er", "blob"), 64<<10)
+	writeSizedFile(t, filepath.Join(root, "top.txt"), 4<<10)
+
+	scan := scanDiskRoot(context.Background(), root)
+	if scan.Error != "" {
+		t.Fatalf("scan error = %q", scan.Error)
+	}
+	sizes := make(map[string]int64, len(scan.Directories))
+	for _, dir := range scan.Directories {
+		sizes[dir.Path] = dir.SizeBytes
+	}
+	if len(scan.Directories) == 0 || scan.Directories[0].Path != filepath.Join(root, "logs") {
+		t.Fatalf("directories = %+v, want logs ranked first", scan.Directories)
+	}
+	if sizes[filepath.Join(root, "logs", "app")] < 256<<10 {
+		t.Fatalf("logs/app size = %d, want at least 256KiB", sizes[filepath.Join(root, "logs", "app")])
+	}
+	if sizes[filepath.Join(root, "cache")] < sizes[filepath.Join(root, "cache", "deep")] {
+		t.Fatalf("cache smaller than its child: %v", sizes)
+	}
+	if _, ok := sizes[filepath.Join(root, "cache", "deep", "er")]; ok {
+		t.Fatalf("directories deeper than %d levels were ranked: %v", diskScanDepth, sizes)
+	}
+	if scan.SizeBytes < sizes[filepath.Join(root, "logs")]+sizes[filepath.Join(root, "cache")] {
+		t.Fatalf("root size = %d, want at least the sum of its children", scan.SizeBytes)
+	}
+}
+
+func TestScanDiskRootMissing(t *testing.T) {
+	t.Parallel()
+
+	scan := scanDiskRoot(context.Background(), filepath.Join(t.TempDir(), "missing"))
+	if scan.Error == "" || len(scan.Directories) != 0 {
+		t.Fatalf("scan = %+v, want error and no directories", scan)
+	}
+}
+
+func TestDiskScannerCachesResults(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 13, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	scanner := newDiskScanner([]string{"/data"})
+	scanner.nowFn = func() time.Time { return now }
+	scanner.scanFn = func(_ context.Context, root string) DiskScan {
+		calls++
+		return DiskScan{Root: root, SizeBytes: int64(calls)}
+	}
+
+	first := scanner.snapshot(false)
+	if !first.Scanning || len(first.Roots) != 0 {
+		t.Fatalf("first snapshot = %+v, want scan in progress without results", first)
+	}
+	scanner.wg.Wait()
+
+	second := scanner.snapshot(false)
+	if second.Scanning || len(second.Roots) != 1 || second.Roots[0].SizeBytes != 1 {
+		t.Fatalf("second snapshot = %+v, want cached first scan", second)
+	}
+	if second.ScannedAt != "2026-05-13T12:00:00Z" {
+		t.Fatalf("ScannedAt = %q", second.ScannedAt)
+	}
+
+	refreshed := scanner.snapshot(true)
+	if !refreshed.Scanning || refreshed.Roots[0].SizeBytes != 1 {
+		t.Fatalf("refresh snapshot = %+v, want previous results while scanning", refreshed)
+	}
+	scanner.wg.Wait()
+
+	now = now.Add(diskScanTTL)
+	_ = scanner.snapshot(false)
+	scanner.wg.Wait()
+	if calls != 3 {
+		t.Fatalf("scan calls = %d, want 3", calls)
+	}
+}
+
+func writeSizedFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
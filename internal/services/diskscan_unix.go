@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd
+
+package services
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileUsage returns the device and allocated size of a file. ok is false when
+// the platform stat data is unavailable and size falls back to the apparent
+// length.
+func fileUsage(info fs.FileInfo) (dev uint64, size int64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, info.Size(), false
+	}
+	return uint64(st.Dev), st.Blocks * 512, true //nolint:unconvert // Dev is int32 on darwin.
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	managerDocker     = "docker"
+	unitTypeContainer = "container"
+
+	dockerRestartNone   = "no"
+	dockerRestartEnable = "unless-stopped"
+
+	// dockerInspectFormat renders the fields used for status and summary as
+	// key=value lines, matching the systemd show output parser.
+	dockerInspectFormat = "Name={{.Name}}\n" +
+		"Image={{.Config.Image}}\n" +
+		"Status={{.State.Status}}\n" +
+		"ExitCode={{.State.ExitCode}}\n" +
+		"StartedAt={{.State.StartedAt}}\n" +
+		"RestartPolicy={{.HostConfig.RestartPolicy.Name}}\n" +
+		"RestartCount={{.RestartCount}}"
+)
+
+// dockerAvailable reports whether the docker CLI can be used. A nil
+// dockerLookup disables docker support.
+func (m *Manager) dockerAvailable() bool {
+	return m != nil && m.dockerLookup != nil && m.dockerLookup()
+}
+
+func (m *Manager) probeDockerContainer(ctx context.Context, svc *ServiceStatus) {
+	props, err := m.inspectDockerProps(ctx, svc.Unit)
+	if err != nil {
+		svc.Exists = false
+		svc.ActiveState = stateUnknown
+		svc.EnabledState = stateUnknown
+		return
+	}
+	svc.Exists = true
+	svc.ActiveState = dockerActiveState(props["Status"], props["ExitCode"])
+	svc.EnabledState = dockerEnabledState(props["RestartPolicy"])
+}
+
+func (m *Manager) inspectDockerProps(ctx context.Context, container string) (map[string]string, error) {
+	if !IsValidUnit(container) {
+		return nil, ErrInvalidUnit
+	}
+	out, err := m.commandRunner(ctx, "docker", "inspect", "--type", "container", "--format", dockerInspectFormat, container)
+	if err != nil {
+		return nil, err
+	}
+	return parseSystemdShow(out), nil
+}
+
+func (m *Manager) inspectDocker(ctx context.Context, container string) (map[string]string, string, error) {
+	props, err := m.inspectDockerProps(ctx, container)
+	if err != nil {
+		return nil, "", fmt.Errorf("docker inspect failed: %w", err)
+	}
+	out, err := m.commandRunner(ctx, "docker", "inspect", "--type", "container", container)
+	if err != nil {
+		return nil, "", fmt.Errorf("docker inspect failed: %w", err)
+	}
+	return props, out, nil
+}
+
+func (m *Manager) actDocker(ctx context.Context, container, action string) error {
+	if !IsValidUnit(container) {
+		return ErrInvalidUnit
+	}
+	var args []string
+	switch action {
+	case ActionStart, ActionStop, ActionRestart:
+		args = []string{action, container}
+	case ActionEnable:
+		args = []string{"update", "--restart", dockerRestartEnable, container}
+	case ActionDisable:
+		args = []string{"update", "--restart", dockerRestartNone, container}
+	default:
+		return ErrInvalidAction
+	}
+	if _, err := m.commandRunner(ctx, "docker", args...); err != nil {
+		return fmt.Errorf("docker %s failed: %w", action, err)
+	}
+	return nil
+}
+
+// logsDocker reads container logs. Docker has no severity or pattern
+// filter, so grep is applied to the returned lines and priority is
+// rejected.
+func (m *Manager) logsDocker(ctx context.Context, container string, query LogQuery) (string, error) {
+	if !IsValidUnit(container) {
+		return "", ErrInvalidUnit
+	}
+	if query.Priority != "" {
+		return "", ErrPriorityUnsupported
+	}
+	args := []string{"logs", "--timestamps", "--tail", fmt.Sprintf("%d", query.Lines)}
+	if !query.Since.IsZero() {
+		args = append(args, "--since", query.Since.UTC().Format(time.RFC3339))
+	}
+	if !query.Until.IsZero() {
+		args = append(args, "--until", query.Until.UTC().Format(time.RFC3339))
+	}
+	args = append(args, container)
+	out, err := m.commandRunner(ctx, "docker", args...)
+	if err != nil {
+		return "", fmt.Errorf("docker logs failed: %w", err)
+	}
+	return grepLogLines(out, query.Grep), nil
+}
+
+func (m *Manager) discoverDockerContainers(ctx context.Context) ([]AvailableService, error) {
+	raw, err := m.commandRunner(ctx, "docker", "ps", "--all", "--no-trunc",
+		"--format", "{{.Names}}\t{{.State}}\t{{.Image}}\t{{.Status}}")
+	if err != nil {
+		return nil, err
+	}
+
+	var units []AvailableService
+	for _, line := range strings.Split(raw, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 3 || !IsValidUnit(fields[0]) {
+			continue
+		}
+		desc := fields[2]
+		if len(fields) > 3 && strings.TrimSpace(fields[3]) != "" {
+			desc += " (" + strings.TrimSpace(fields[3]) + ")"
+		}
+		units = append(units, AvailableService{
+			Unit:         fields[0],
+			UnitType:     unitTypeContainer,
+			Description:  desc,
+			ActiveState:  dockerActiveState(fields[1], ""),
+			EnabledState: stateUnknown,
+			Manager:      managerDocker,
+			Scope:        scopeSystem,
+		})
+	}
+	return units, nil
+}
+
+// dockerUnits lists host containers when docker is available. Failures are
+// logged rather than returned so a stopped docker daemon does not break
+// service discovery for the native manager.
+func (m *Manager) dockerUnits(ctx context.Context) []AvailableService {
+	if !m.dockerAvailable() {
+		return nil
+	}
+	units, err := m.discoverDockerContainers(ctx)
+	if err != nil {
+		slog.Warn("service discovery failed", "manager", managerDocker, "err", err)
+		return nil
+	}
+	return units
+}
+
+func dockerActiveState(status, exitCode string) string {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case stateRunning, "restarting":
+		return stateRunning
+	case "created", "paused":
+		return stateInactive
+	case "exited":
+		if code := strings.TrimSpace(exitCode); code != "" && code != "0" {
+			return stateFailed
+		}
+		return stateInactive
+	case "dead":
+		return stateFailed
+	default:
+		return stateUnknown
+	}
+}
+
+func dockerEnabledState(restartPolicy string) string {
+	policy := strings.TrimSpace(restartPolicy)
+	if policy == "" || policy == dockerRestartNone {
+		return "disabled"
+	}
+	return "enabled"
+}
+
+func buildDockerSummary(props map[string]string) string {
+	parts := make([]string, 0, 3)
+	if status := strings.TrimSpace(props["Status"]); status != "" {
+		parts = append(parts, "status="+status)
+	}
+	if image := strings.TrimSpace(props["Image"]); image != "" {
+		parts = append(parts, "image="+image)
+	}
+	if policy := strings.TrimSpace(props["RestartPolicy"]); policy != "" {
+		parts = append(parts, "restart="+policy)
+	}
+	return strings.Join(parts, " ")
+}
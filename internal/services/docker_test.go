@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+type dockerServicesRepo struct{}
+
+func (dockerServicesRepo) ListCustomServices(context.Context) ([]store.CustomService, error) {
+	return []store.CustomService{{
+		Name:        "db",
+		DisplayName: "Postgres",
+		Manager:     managerDocker,
+		Unit:        "postgres",
+		Scope:       scopeSystem,
+	}}, nil
+}
+
+func newDockerTestManager(runner commandRunner) *Manager {
+	m := newTestManager("linux", runner)
+	m.customServices = dockerServicesRepo{}
+	m.dockerLookup = func() bool { return true }
+	return m
+}
+
+func TestDockerProbeAndInspect(t *testing.T) {
+	t.Parallel()
+
+	m := newDockerTestManager(func(_ context.Context, name string, args ...string) (string, error) {
+		if name != "docker" || args[0] != "inspect" {
+			return "", errors.New("unexpected command")
+		}
+		if slices.Contains(args, "--format") {
+			return "Name=/postgres\nImage=postgres:16\nStatus=exited\nExitCode=1\nRestartPolicy=always", nil
+		}
+		return `[{"Id":"abc"}]`, nil
+	})
+
+	services, err := m.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices() error = %v", err)
+	}
+	if len(services) != 1 || !services[0].Exists || services[0].ActiveState != stateFailed || services[0].EnabledState != "enabled" {
+		t.Fatalf("services = %+v", services)
+	}
+
+	inspect, err := m.Inspect(context.Background(), "db")
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if inspect.Summary != "status=exited image=postgres:16 restart=always" || inspect.Output != `[{"Id":"abc"}]` {
+		t.Fatalf("inspect = %+v", inspect)
+	}
+}
+
+func TestDockerActions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		action string
+		want   []string
+	}{
+		{ActionRestart, []string{"docker", "restart", "postgres"}},
+		{ActionEnable, []string{"docker", "update", "--restart", "unless-stopped", "postgres"}},
+		{ActionDisable, []string{"docker", "update", "--restart", "no", "postgres"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			t.Parallel()
+
+			var calls [][]string
+			m := newDockerTestManager(func(_ context.Context, name string, args ...string) (string, error) {
+				calls = append(calls, append([]string{name}, args...))
+				return "", nil
+			})
+			if err := m.ActByUnit(context.Background(), "postgres", scopeSystem, managerDocker, tt.action); err != nil {
+				t.Fatalf("ActByUnit() error = %v", err)
+			}
+			if len(calls) != 1 || !slices.Equal(calls[0], tt.want) {
+				t.Fatalf("calls = %#v, want %#v", calls, tt.want)
+			}
+		})
+	}
+
+	m := newDockerTestManager(nil)
+	if err := m.ActByUnit(context.Background(), "--rm", scopeSystem, managerDocker, ActionStart); !errors.Is(err, ErrInvalidUnit) {
+		t.Fatalf("unsafe container error = %v, want ErrInvalidUnit", err)
+	}
+}
+
+func TestDockerLogsByUnit(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	m := newDockerTestManager(func(_ context.Context, name string, args ...string) (string, error) {
+		got = append([]string{name}, args...)
+		return "log line", nil
+	})
+	out, err := m.LogsByUnit(context.Background(), "postgres", scopeSystem, managerDocker, LogQuery{Lines: 50})
+	if err != nil {
+		t.Fatalf("LogsByUnit() error = %v", err)
+	}
+	want := []string{"docker", "logs", "--timestamps", "--tail", "50", "postgres"}
+	if out != "log line" || !slices.Equal(got, want) {
+		t.Fatalf("LogsByUnit() = %q with %#v, want %#v", out, got, want)
+	}
+}
+
+func TestDockerLogsByUnitQueryFilters(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	m := newDockerTestManager(func(_ context.Context, name string, args ...string) (string, error) {
+		got = append([]string{name}, args...)
+		return "ready\nERROR disk full\nready", nil
+	})
+	since := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	query := LogQuery{Lines: 50, Since: since, Until: since.Add(time.Hour), Grep: "ERROR"}
+	out, err := m.LogsByUnit(context.Background(), "postgres", scopeSystem, managerDocker, query)
+	if err != nil {
+		t.Fatalf("LogsByUnit() error = %v", err)
+	}
+	want := []string{
+		"docker", "logs", "--timestamps", "--tail", "50",
+		"--since", "2026-10-15T09:00:00Z", "--until", "2026-10-15T10:00:00Z", "postgres",
+	}
+	if out != "ERROR disk full" || !slices.Equal(got, want) {
+		t.Fatalf("LogsByUnit() = %q with %#v, want %#v", out, got, want)
+	}
+
+	query = LogQuery{Priority: "err"}
+	if _, err := m.LogsByUnit(context.Background(), "postgres", scopeSystem, managerDocker, query); !errors.Is(err, ErrPriorityUnsupported) {
+		t.Fatalf("priority error = %v, want ErrPriorityUnsupported", err)
+	}
+}
+
+func TestBrowseServicesIncludesDockerContainers(t *testing.T) {
+	t.Parallel()
+
+	m := newDockerTestManager(func(_ context.Context, name string, args ...string) (string, error) {
+		if name != "docker" {
+			return "", nil
+		}
+		switch args[0] {
+		case "ps":
+			return "postgres\trunning\tpostgres:16\tUp 2 hours\nweb\texited\tnginx:1\tExited (0) 1 day ago\n", nil
+		case "inspect":
+			return "Status=running\nRestartPolicy=no", nil
+		}
+		return "", nil
+	})
+
+	browsed, err := m.BrowseServices(context.Background())
+	if err != nil {
+		t.Fatalf("BrowseServices() error = %v", err)
+	}
+	var containers []BrowsedService
+	for _, item := range browsed {
+		if item.Manager == managerDocker {
+			containers = append(containers, item)
+		}
+	}
+	if len(containers) != 2 {
+		t.Fatalf("docker entries = %+v, want 2", containers)
+	}
+	if !containers[0].Tracked || containers[0].TrackedName != "db" || containers[0].UnitType != unitTypeContainer {
+		t.Fatalf("postgres entry = %+v, want tracked container", containers[0])
+	}
+	if containers[1].Tracked || containers[1].ActiveState != stateInactive || containers[1].Description != "nginx:1 (Exited (0) 1 day ago)" {
+		t.Fatalf("web entry = %+v", containers[1])
+	}
+
+	m.dockerLookup = nil
+	browsed, err = m.BrowseServices(context.Background())
+	if err != nil {
+		t.Fatalf("BrowseServices() without docker error = %v", err)
+	}
+	for _, item := range browsed {
+		if item.Manager == managerDocker && !item.Tracked {
+			t.Fatalf("untracked container listed without docker CLI: %+v", item)
+		}
+	}
+}
+
+func TestDockerActiveState(t *testing.T) {
+	t.Parallel()
+
+	tests := map[[2]string]string{
+		{"running", ""}:    stateRunning,
+		{"restarting", ""}: stateRunning,
+		{"exited", "0"}:    stateInactive,
+		{"exited", "137"}:  stateFailed,
+		{"created", ""}:    stateInactive,
+		{"dead", ""}:       stateFailed,
+		{"weird", ""}:      stateUnknown,
+	}
+	for in, want := range tests {
+		if got := dockerActiveState(in[0], in[1]); got != want {
+			t.Errorf("dockerActiveState(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+const (
+	// HealthCheckInterval is how often custom service health checks run.
+	HealthCheckInterval = 30 * time.Second
+
+	healthCheckHTTP    = "http"
+	healthCheckTCP     = "tcp"
+	healthCheckCommand = "command"
+
+	maxHealthChecks           = 8
+	maxHealthCheckTimeout     = 60
+	defaultHealthCheckTimeout = 5 * time.Second
+	maxHealthCheckDetail      = 200
+	maxHealthCheckBody        = 1 << 20
+)
+
+// ErrInvalidHealthCheck is returned for a malformed health check.
+var ErrInvalidHealthCheck = errors.New("invalid health check")
+
+// ServiceHealth is the outcome of the latest run of a service's health
+// checks. Healthy requires every check to pass.
+type ServiceHealth struct {
+	Healthy   bool                `json:"healthy"`
+	CheckedAt string              `json:"checkedAt"`
+	Checks    []HealthCheckResult `json:"checks"`
+}
+
+// HealthCheckResult is the outcome of one health check.
+type HealthCheckResult struct {
+	Type       string `json:"type"`
+	Target     string `json:"target"`
+	OK         bool   `json:"ok"`
+	Detail     string `json:"detail,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// HealthChange reports a service whose health flipped, or that was checked
+// for the first time, in a CheckHealth run.
+type HealthChange struct {
+	Name   string
+	Health ServiceHealth
+}
+
+// healthCache keeps the latest health of each custom service with checks.
+type healthCache struct {
+	mu      sync.Mutex
+	results map[string]ServiceHealth
+}
+
+func (c *healthCache) get(name string) (ServiceHealth, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	health, ok := c.results[name]
+	return health, ok
+}
+
+// ValidateHealthChecks rejects health checks that cannot run.
+func ValidateHealthChecks(checks []store.HealthCheck) error {
+	if len(checks) > maxHealthChecks {
+		return fmt.Errorf("%w: at most %d checks per service", ErrInvalidHealthCheck, maxHealthChecks)
+	}
+	for i, check := range checks {
+		if err := validateHealthCheck(check); err != nil {
+			return fmt.Errorf("%w: check %d: %s", ErrInvalidHealthCheck, i+1, err.Error())
+		}
+	}
+	return nil
+}
+
+func validateHealthCheck(check store.HealthCheck) error {
+	if check.TimeoutSeconds < 0 || check.TimeoutSeconds > maxHealthCheckTimeout {
+		return fmt.Errorf("timeoutSeconds must be between 0 and %d", maxHealthCheckTimeout)
+	}
+	switch check.Type {
+	case healthCheckHTTP:
+		parsed, err := url.Parse(check.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("url must be an absolute http or https URL")
+		}
+		if check.ExpectStatus != 0 && (check.ExpectStatus < 100 || check.ExpectStatus > 599) {
+			return errors.New("expectStatus must be an HTTP status code")
+		}
+	case healthCheckTCP:
+		host, port, err := net.SplitHostPort(check.Address)
+		if err != nil || host == "" {
+			return errors.New("address must be host:port")
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return errors.New("address port must be between 1 and 65535")
+		}
+	case healthCheckCommand:
+		if strings.TrimSpace(check.Command) == "" || strings.ContainsRune(check.Command, 0) || len(check.Command) > 4096 {
+			return errors.New("command must be a non-empty shell command")
+		}
+		if check.ExpectExit < 0 || check.ExpectExit > 255 {
+			return errors.New("expectExit must be between 0 and 255")
+		}
+	default:
+		return errors.New("type must be http, tcp or command")
+	}
+	return nil
+}
+
+// CheckHealth runs the health checks of every custom service that has
+// some, keeps the results for ListServices and returns the services whose
+// health changed since the previous run.
+func (m *Manager) CheckHealth(ctx context.Context) ([]HealthChange, error) {
+	if m.customServices == nil {
+		return nil, nil
+	}
+	custom, err := m.customServices.ListCustomServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]ServiceHealth, len(custom))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, cs := range custom {
+		if len(cs.HealthChecks) == 0 {
+			continue
+		}
+		wg.Go(func() {
+			health := m.runHealthChecks(ctx, cs.HealthChecks)
+			mu.Lock()
+			results[cs.Name] = health
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	m.health.mu.Lock()
+	defer m.health.mu.Unlock()
+	var changes []HealthChange
+	for name, health := range results {
+		if previous, ok := m.health.results[name]; !ok || previous.Healthy != health.Healthy {
+			changes = append(changes, HealthChange{Name: name, Health: health})
+		}
+	}
+	m.health.results = results
+	return changes, nil
+}
+
+func (m *Manager) runHealthChecks(ctx context.Context, checks []store.HealthCheck) ServiceHealth {
+	health := ServiceHealth{Healthy: true, Checks: make([]HealthCheckResult, 0, len(checks))}
+	for _, check := range checks {
+		result := m.runHealthCheck(ctx, check)
+		health.Healthy = health.Healthy && result.OK
+		health.Checks = append(health.Checks, result)
+	}
+	health.CheckedAt = m.nowFn().UTC().Format(time.RFC3339)
+	return health
+}
+
+func (m *Manager) runHealthCheck(ctx context.Context, check store.HealthCheck) HealthCheckResult {
+	timeout := defaultHealthCheckTimeout
+	if check.TimeoutSeconds > 0 {
+		timeout = time.Duration(check.TimeoutSeconds) * time.Second
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := HealthCheckResult{Type: check.Type}
+	started := time.Now()
+	var err error
+	switch check.Type {
+	case healthCheckHTTP:
+		result.Target = check.URL
+		err = checkHTTP(checkCtx, check.URL, check.ExpectStatus, nil)
+	case healthCheckTCP:
+		result.Target = check.Address
+		err = checkTCP(checkCtx, check.Address)
+	case healthCheckCommand:
+		result.Target = check.Command
+		err = checkCommand(checkCtx, check.Command, check.ExpectExit)
+	default:
+		err = fmt.Errorf("unknown check type %q", check.Type)
+	}
+	result.DurationMs = time.Since(started).Milliseconds()
+	result.OK = err == nil
+	if err != nil {
+		result.Detail = truncateDetail(err.Error())
+	}
+	return result
+}
+
+// healthHTTPClient does not follow redirects, so a check can expect a 3xx.
+var healthHTTPClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// checkHTTP requests target and checks the status and, when body is set,
+// that the first MiB of the response body matches it.
+func checkHTTP(ctx context.Context, target string, expect int, body *regexp.Regexp) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := healthHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case expect == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299):
+		return fmt.Errorf("status %d, want 2xx", resp.StatusCode)
+	case expect != 0 && resp.StatusCode != expect:
+		return fmt.Errorf("status %d, want %d", resp.StatusCode, expect)
+	}
+	if body == nil {
+		return nil
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxHealthCheckBody))
+	if err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
+	if !body.Match(content) {
+		return fmt.Errorf("body does not match %q", body.String())
+	}
+	return nil
+}
+
+func checkTCP(ctx context.Context, address string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func checkCommand(ctx context.Context, command string, expect int) error {
+	out, err := exec.CommandContext(ctx, "sh", "-c", command).CombinedOutput()
+	code := 0
+	if err != nil {
+		if ctx.Err() != nil {
+			return errors.New("timed out")
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return err
+		}
+		code = exitErr.ExitCode()
+	}
+	if code != expect {
+		detail := fmt.Sprintf("exit %d, want %d", code, expect)
+		if trimmed := strings.TrimSpace(string(out)); trimmed != "" {
+			detail += ": " + trimmed
+		}
+		return errors.New(detail)
+	}
+	return nil
+}
+
+func truncateDetail(detail string) string {
+	if len(detail) <= maxHealthCheckDetail {
+		return detail
+	}
+	return detail[:maxHealthCheckDetail] + "…"
+}
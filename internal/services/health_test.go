@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func TestValidateHealthChecks(t *testing.T) {
+	t.Parallel()
+
+	valid := []store.HealthCheck{
+		{Type: "http", URL: "https://127.0.0.1/healthz", ExpectStatus: 204},
+		{Type: "tcp", Address: "localhost:5432"},
+		{Type: "command", Command: "pg_isready", ExpectExit: 0, TimeoutSeconds: 10},
+	}
+	if err := ValidateHealthChecks(valid); err != nil {
+		t.Fatalf("ValidateHealthChecks(valid) = %v", err)
+	}
+
+	for _, bad := range []store.HealthCheck{
+		{Type: "ping"},
+		{Type: "http", URL: "ftp://example.com"},
+		{Type: "http", URL: "/healthz"},
+		{Type: "http", URL: "http://x", ExpectStatus: 42},
+		{Type: "tcp", Address: "5432"},
+		{Type: "tcp", Address: "db:99999"},
+		{Type: "command", Command: "  "},
+		{Type: "command", Command: "true", ExpectExit: 300},
+		{Type: "tcp", Address: "db:1", TimeoutSeconds: 61},
+	} {
+		if err := ValidateHealthChecks([]store.HealthCheck{bad}); !errors.Is(err, ErrInvalidHealthCheck) {
+			t.Errorf("ValidateHealthChecks(%+v) = %v, want ErrInvalidHealthCheck", bad, err)
+		}
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Parallel()
+
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	m := newTestManager("linux", nil)
+	m.customServices = &stubCustomServicesRepo{services: []store.CustomService{
+		{Name: "sentinel", Unit: "sentinel"},
+		{Name: "api", Unit: "api.service", HealthChecks: []store.HealthCheck{
+			{Type: "http", URL: srv.URL},
+			{Type: "tcp", Address: listener.Addr().String()},
+		}},
+		{Name: "worker", Unit: "worker.service", HealthChecks: []store.HealthCheck{
+			{Type: "command", Command: "echo stuck; exit 3"},
+		}},
+	}}
+
+	changes, err := m.CheckHealth(context.Background())
+	if err != nil {
+		t.Fatalf("CheckHealth: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("first run changes = %+v, want api and worker", changes)
+	}
+	for _, change := range changes {
+		switch change.Name {
+		case "api":
+			if !change.Health.Healthy || len(change.Health.Checks) != 2 {
+				t.Fatalf("api health = %+v, want 2 passing checks", change.Health)
+			}
+		case "worker":
+			if change.Health.Healthy || change.Health.Checks[0].Detail != "exit 3, want 0: stuck" {
+				t.Fatalf("worker health = %+v, want failing with exit detail", change.Health)
+			}
+		default:
+			t.Fatalf("unexpected change for %s", change.Name)
+		}
+	}
+
+	// Unchanged health is not reported again; a flip is.
+	status.Store(http.StatusServiceUnavailable)
+	changes, err = m.CheckHealth(context.Background())
+	if err != nil {
+		t.Fatalf("CheckHealth: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Name != "api" || changes[0].Health.Healthy {
+		t.Fatalf("second run changes = %+v, want api turning unhealthy", changes)
+	}
+	if detail := changes[0].Health.Checks[0].Detail; !strings.Contains(detail, "status 503") {
+		t.Fatalf("http detail = %q", detail)
+	}
+
+	m.commandRunner = func(context.Context, string, ...string) (string, error) {
+		return "ActiveState=active\nUnitFileState=enabled\nLoadState=loaded\n", nil
+	}
+	services, err := m.ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListServices: %v", err)
+	}
+	for _, svc := range services {
+		if (svc.Health != nil) != (svc.Name != "sentinel") {
+			t.Fatalf("service %s health = %+v", svc.Name, svc.Health)
+		}
+	}
+}
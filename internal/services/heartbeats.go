@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+const (
+	// HeartbeatTickInterval is how often overdue heartbeats are looked for.
+	HeartbeatTickInterval = 30 * time.Second
+
+	minHeartbeatInterval = 60
+	maxHeartbeatInterval = 31 * 24 * 60 * 60
+	maxHeartbeatGrace    = 7 * 24 * 60 * 60
+)
+
+// ErrInvalidHeartbeat is returned for a malformed heartbeat.
+var ErrInvalidHeartbeat = errors.New("invalid heartbeat")
+
+// heartbeatRepo stores heartbeats and their missed transitions.
+type heartbeatRepo interface {
+	ListOpsHeartbeats(ctx context.Context) ([]store.OpsHeartbeat, error)
+	MarkOpsHeartbeatMissed(ctx context.Context, id string, lastPingAt, at time.Time) (bool, error)
+}
+
+// SetHeartbeats sets the store the heartbeats are read from. It is called
+// once at startup.
+func (m *Manager) SetHeartbeats(repo heartbeatRepo) {
+	m.heartbeats = repo
+}
+
+// ValidateHeartbeat rejects heartbeats that cannot be watched.
+func ValidateHeartbeat(hb store.OpsHeartbeatWrite) error {
+	switch {
+	case strings.TrimSpace(hb.Name) == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidHeartbeat)
+	case hb.IntervalSeconds < minHeartbeatInterval || hb.IntervalSeconds > maxHeartbeatInterval:
+		return fmt.Errorf("%w: intervalSeconds must be between %d and %d", ErrInvalidHeartbeat, minHeartbeatInterval, maxHeartbeatInterval)
+	case hb.GraceSeconds < 0 || hb.GraceSeconds > maxHeartbeatGrace:
+		return fmt.Errorf("%w: graceSeconds must be between 0 and %d", ErrInvalidHeartbeat, maxHeartbeatGrace)
+	}
+	return nil
+}
+
+// CheckHeartbeats marks the enabled heartbeats whose last ping is older
+// than their interval plus grace as missed and returns them. Heartbeats
+// that were never pinged wait for their first ping.
+func (m *Manager) CheckHeartbeats(ctx context.Context) ([]store.OpsHeartbeat, error) {
+	if m.heartbeats == nil {
+		return nil, nil
+	}
+	list, err := m.heartbeats.ListOpsHeartbeats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := m.nowFn().UTC()
+	var missed []store.OpsHeartbeat
+	for _, hb := range list {
+		if !hb.Enabled || hb.Status != store.HeartbeatStatusUp || now.Before(hb.DueAt()) {
+			continue
+		}
+		changed, err := m.heartbeats.MarkOpsHeartbeatMissed(ctx, hb.ID, hb.LastPingAt, now)
+		if err != nil {
+			return missed, err
+		}
+		if changed {
+			hb.Status = store.HeartbeatStatusMissed
+			hb.LastChangeAt = now
+			missed = append(missed, hb)
+		}
+	}
+	return missed, nil
+}
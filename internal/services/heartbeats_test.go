@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+// fakeHeartbeatRepo keeps heartbeats in memory.
+type fakeHeartbeatRepo struct {
+	heartbeats []store.OpsHeartbeat
+}
+
+func (r *fakeHeartbeatRepo) ListOpsHeartbeats(context.Context) ([]store.OpsHeartbeat, error) {
+	return append([]store.OpsHeartbeat(nil), r.heartbeats...), nil
+}
+
+func (r *fakeHeartbeatRepo) MarkOpsHeartbeatMissed(_ context.Context, id string, lastPingAt, at time.Time) (bool, error) {
+	for i := range r.heartbeats {
+		hb := &r.heartbeats[i]
+		if hb.ID == id && hb.Status == store.HeartbeatStatusUp && hb.LastPingAt.Equal(lastPingAt) {
+			hb.Status, hb.LastChangeAt = store.HeartbeatStatusMissed, at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestValidateHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateHeartbeat(store.OpsHeartbeatWrite{Name: "backup", IntervalSeconds: 86400, GraceSeconds: 3600}); err != nil {
+		t.Fatalf("ValidateHeartbeat(valid) = %v", err)
+	}
+	for _, bad := range []store.OpsHeartbeatWrite{
+		{Name: " ", IntervalSeconds: 3600},
+		{Name: "fast", IntervalSeconds: 30},
+		{Name: "slow", IntervalSeconds: 365 * 24 * 3600},
+		{Name: "grace", IntervalSeconds: 3600, GraceSeconds: -1},
+	} {
+		if err := ValidateHeartbeat(bad); !errors.Is(err, ErrInvalidHeartbeat) {
+			t.Errorf("ValidateHeartbeat(%+v) = %v, want ErrInvalidHeartbeat", bad, err)
+		}
+	}
+}
+
+func TestCheckHeartbeats(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	repo := &fakeHeartbeatRepo{heartbeats: []store.OpsHeartbeat{
+		{ID: "1", Name: "backup", IntervalSeconds: 3600, GraceSeconds: 600, Enabled: true, Status: store.HeartbeatStatusUp, LastPingAt: now.Add(-70 * time.Minute)},
+		{ID: "2", Name: "within-grace", IntervalSeconds: 3600, GraceSeconds: 600, Enabled: true, Status: store.HeartbeatStatusUp, LastPingAt: now.Add(-65 * time.Minute)},
+		{ID: "3", Name: "new", IntervalSeconds: 60, Enabled: true, Status: store.HeartbeatStatusNew},
+		{ID: "4", Name: "paused", IntervalSeconds: 60, Status: store.HeartbeatStatusUp, LastPingAt: now.Add(-time.Hour)},
+	}}
+	m := newTestManager("linux", nil)
+	m.nowFn = func() time.Time { return now }
+	m.SetHeartbeats(repo)
+
+	missed, err := m.CheckHeartbeats(context.Background())
+	if err != nil {
+		t.Fatalf("CheckHeartbeats: %v", err)
+	}
+	if len(missed) != 1 || missed[0].Name != "backup" || missed[0].Status != store.HeartbeatStatusMissed || !missed[0].LastChangeAt.Equal(now) {
+		t.Fatalf("missed = %+v", missed)
+	}
+	// A missed heartbeat alerts once.
+	if missed, err := m.CheckHeartbeats(context.Background()); err != nil || len(missed) != 0 {
+		t.Fatalf("second CheckHeartbeats = %+v, %v", missed, err)
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+const (
+	// HookPreStop names the hook run before a restart.
+	HookPreStop = "preStop"
+	// HookPostStart names the hook run after a restart.
+	HookPostStart = "postStart"
+
+	// MaxHookTimeout is the longest a single restart hook may run.
+	MaxHookTimeout = 120 * time.Second
+
+	defaultHookTimeout = 30 * time.Second
+	maxHookCommand     = 4096
+)
+
+var (
+	// ErrInvalidHook is returned for a malformed restart hook.
+	ErrInvalidHook = errors.New("invalid restart hook")
+	// ErrHookFailed is returned by Act when the pre-stop hook fails and the
+	// restart is aborted.
+	ErrHookFailed = errors.New("restart hook failed")
+)
+
+// HookResult is the outcome of one restart hook run.
+type HookResult struct {
+	Hook       string `json:"hook"`
+	Command    string `json:"command"`
+	OK         bool   `json:"ok"`
+	Detail     string `json:"detail,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// ValidateRestartHooks rejects restart hooks that cannot run. nil is valid.
+func ValidateRestartHooks(hooks *store.RestartHooks) error {
+	if hooks == nil {
+		return nil
+	}
+	for _, named := range []struct {
+		name string
+		hook *store.Hook
+	}{{HookPreStop, hooks.PreStop}, {HookPostStart, hooks.PostStart}} {
+		if named.hook == nil {
+			continue
+		}
+		command := named.hook.Command
+		if strings.TrimSpace(command) == "" || strings.ContainsRune(command, 0) || len(command) > maxHookCommand {
+			return fmt.Errorf("%w: %s: command must be a non-empty shell command", ErrInvalidHook, named.name)
+		}
+		if named.hook.TimeoutSeconds < 0 || time.Duration(named.hook.TimeoutSeconds)*time.Second > MaxHookTimeout {
+			return fmt.Errorf("%w: %s: timeoutSeconds must be between 0 and %d", ErrInvalidHook, named.name, int(MaxHookTimeout.Seconds()))
+		}
+	}
+	return nil
+}
+
+// restartHooks returns the restart hooks of the named custom service, if any.
+func (m *Manager) restartHooks(ctx context.Context, name string) (*store.RestartHooks, error) {
+	if m.customServices == nil {
+		return nil, nil
+	}
+	custom, err := m.customServices.ListCustomServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cs := range custom {
+		if cs.Name == name {
+			return cs.RestartHooks, nil
+		}
+	}
+	return nil, nil
+}
+
+// runHook runs one restart hook through sh -c. A non-zero exit fails it.
+func runHook(ctx context.Context, name string, hook *store.Hook) HookResult {
+	timeout := defaultHookTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	out, err := exec.CommandContext(hookCtx, "sh", "-c", hook.Command).CombinedOutput()
+	result := HookResult{
+		Hook:       name,
+		Command:    hook.Command,
+		OK:         err == nil,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case hookCtx.Err() != nil:
+		result.Detail = "timed out"
+	case errors.As(err, &exitErr):
+		result.Detail = fmt.Sprintf("exit %d", exitErr.ExitCode())
+	default:
+		result.Detail = err.Error()
+	}
+	if trimmed := strings.TrimSpace(string(out)); trimmed != "" {
+		if result.Detail != "" {
+			result.Detail += ": "
+		}
+		result.Detail += trimmed
+	}
+	result.Detail = truncateDetail(result.Detail)
+	return result
+}
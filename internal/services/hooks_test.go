@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+func TestValidateRestartHooks(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateRestartHooks(nil); err != nil {
+		t.Fatalf("ValidateRestartHooks(nil) = %v", err)
+	}
+	valid := &store.RestartHooks{
+		PreStop:   &store.Hook{Command: "curl -fsS -X POST http://lb/drain/web1", TimeoutSeconds: 60},
+		PostStart: &store.Hook{Command: "curl -fsS -X POST http://lb/enable/web1"},
+	}
+	if err := ValidateRestartHooks(valid); err != nil {
+		t.Fatalf("ValidateRestartHooks(valid) = %v", err)
+	}
+
+	for _, bad := range []*store.RestartHooks{
+		{PreStop: &store.Hook{Command: " "}},
+		{PostStart: &store.Hook{Command: "true", TimeoutSeconds: -1}},
+		{PostStart: &store.Hook{Command: "true", TimeoutSeconds: 121}},
+	} {
+		if err := ValidateRestartHooks(bad); !errors.Is(err, ErrInvalidHook) {
+			t.Errorf("ValidateRestartHooks(%+v) = %v, want ErrInvalidHook", bad, err)
+		}
+	}
+}
+
+func TestActRunsRestartHooks(t *testing.T) {
+	t.Parallel()
+
+	newManager := func(hooks *store.RestartHooks, calls *[][]string) *Manager {
+		return &Manager{
+			nowFn:    time.Now,
+			uidFn:    func() int { return 1000 },
+			goos:     "linux",
+			hostname: func() (string, error) { return testHostname, nil },
+			customServices: &stubCustomServicesRepo{services: []store.CustomService{
+				{Name: "nginx", Manager: "systemd", Unit: "nginx.service", Scope: "system", RestartHooks: hooks},
+			}},
+			commandRunner: func(_ context.Context, name string, args ...string) (string, error) {
+				*calls = append(*calls, append([]string{name}, args...))
+				return "", nil
+			},
+		}
+	}
+	restart := []string{"systemctl", "restart", "nginx.service"}
+
+	var calls [][]string
+	m := newManager(&store.RestartHooks{
+		PreStop:   &store.Hook{Command: "echo drained"},
+		PostStart: &store.Hook{Command: "echo not ready; exit 2"},
+	}, &calls)
+	status, err := m.Act(context.Background(), "nginx", ActionRestart)
+	if err != nil {
+		t.Fatalf("Act: %v", err)
+	}
+	if !slices.ContainsFunc(calls, func(c []string) bool { return slices.Equal(c, restart) }) {
+		t.Fatalf("expected %v among calls %v", restart, calls)
+	}
+	if len(status.Hooks) != 2 {
+		t.Fatalf("hooks = %+v, want preStop and postStart", status.Hooks)
+	}
+	if pre := status.Hooks[0]; pre.Hook != HookPreStop || !pre.OK || pre.Detail != "drained" {
+		t.Fatalf("preStop = %+v", pre)
+	}
+	if post := status.Hooks[1]; post.Hook != HookPostStart || post.OK || post.Detail != "exit 2: not ready" {
+		t.Fatalf("postStart = %+v", post)
+	}
+
+	// Hooks only wrap restarts.
+	calls = nil
+	status, err = m.Act(context.Background(), "nginx", ActionStop)
+	if err != nil || len(status.Hooks) != 0 {
+		t.Fatalf("Act(stop) = %+v, %v; want no hooks", status.Hooks, err)
+	}
+
+	// A failing pre-stop hook aborts the restart.
+	calls = nil
+	m = newManager(&store.RestartHooks{PreStop: &store.Hook{Command: "exit 1"}}, &calls)
+	status, err = m.Act(context.Background(), "nginx", ActionRestart)
+	if !errors.Is(err, ErrHookFailed) {
+		t.Fatalf("Act error = %v, want ErrHookFailed", err)
+	}
+	if len(status.Hooks) != 1 || status.Hooks[0].OK {
+		t.Fatalf("hooks = %+v, want the failed preStop", status.Hooks)
+	}
+	if slices.ContainsFunc(calls, func(c []string) bool { return slices.Equal(c, restart) }) {
+		t.Fatalf("restart ran after a failed preStop hook: %v", calls)
+	}
+}
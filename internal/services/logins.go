@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSH authentication results.
+const (
+	AuthResultAccepted    = "accepted"
+	AuthResultFailed      = "failed"
+	AuthResultInvalidUser = "invalid-user"
+)
+
+// authEventLimit caps the sshd journal lines read per query.
+const authEventLimit = 1000
+
+// ErrLoginsUnsupported is returned when logins cannot be listed on the host
+// platform.
+var ErrLoginsUnsupported = errors.New("login tracking is not supported on this platform")
+
+// LoginSession is a user logged in to the host, as recorded in utmp.
+type LoginSession struct {
+	User    string `json:"user"`
+	TTY     string `json:"tty"`
+	Host    string `json:"host,omitempty"`
+	LoginAt string `json:"loginAt,omitempty"`
+}
+
+// AuthEvent is an sshd authentication attempt read from the journal.
+type AuthEvent struct {
+	At     string `json:"at"`
+	User   string `json:"user"`
+	Host   string `json:"host"`
+	Port   int    `json:"port,omitempty"`
+	Method string `json:"method,omitempty"`
+	Result string `json:"result"`
+}
+
+// Logins lists who is logged in to the host and the recent SSH
+// authentication attempts.
+type Logins struct {
+	Sessions  []LoginSession `json:"sessions"`
+	Events    []AuthEvent    `json:"events"`
+	CheckedAt string         `json:"checkedAt"`
+}
+
+// Logins reads the current sessions through who(1) and the sshd
+// authentication attempts since the given time.
+func (m *Manager) Logins(ctx context.Context, since time.Time) (Logins, error) {
+	if m.goos == "windows" {
+		return Logins{}, ErrLoginsUnsupported
+	}
+	out, err := m.commandRunner(ctx, "who")
+	if err != nil {
+		return Logins{}, fmt.Errorf("who failed: %w", err)
+	}
+	now := m.nowFn()
+	events, err := m.AuthEvents(ctx, since)
+	if err != nil {
+		return Logins{}, err
+	}
+	return Logins{
+		Sessions:  parseWho(out, now),
+		Events:    events,
+		CheckedAt: now.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// AuthEvents reads sshd authentication attempts since the given time, oldest
+// first. They come from the systemd journal, so other platforms report none.
+func (m *Manager) AuthEvents(ctx context.Context, since time.Time) ([]AuthEvent, error) {
+	if m.goos != "linux" {
+		return []AuthEvent{}, nil
+	}
+	// OpenSSH 9.8 moved per-connection logging to sshd-session.
+	out, err := m.commandRunner(ctx, "journalctl",
+		"-t", "sshd", "-t", "sshd-session",
+		"--no-pager",
+		"-n", strconv.Itoa(authEventLimit),
+		"--output=short-iso",
+		fmt.Sprintf("--since=@%d", since.Unix()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("journalctl failed: %w", err)
+	}
+	events := []AuthEvent{}
+	for _, line := range strings.Split(out, "\n") {
+		if event, ok := parseSSHAuthLine(line); ok {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// parseWho reads who(1) lines: user, terminal, login time and an optional
+// parenthesized remote host. GNU who prints "2026-10-17 09:12"; the BSDs
+// print "Oct 17 09:12", whose year is taken from now.
+func parseWho(out string, now time.Time) []LoginSession {
+	sessions := []LoginSession{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		session := LoginSession{User: fields[0], TTY: fields[1]}
+		rest := fields[2:]
+		if n := len(rest); n > 0 && strings.HasPrefix(rest[n-1], "(") && strings.HasSuffix(rest[n-1], ")") {
+			session.Host = strings.TrimSuffix(strings.TrimPrefix(rest[n-1], "("), ")")
+			rest = rest[:n-1]
+		}
+		stamp := strings.Join(rest, " ")
+		if at, err := time.ParseInLocation("2006-01-02 15:04", stamp, now.Location()); err == nil {
+			session.LoginAt = at.UTC().Format(time.RFC3339)
+		} else if at, err := time.ParseInLocation("Jan 2 15:04", stamp, now.Location()); err == nil {
+			at = at.AddDate(now.Year(), 0, 0)
+			if at.After(now.Add(24 * time.Hour)) {
+				at = at.AddDate(-1, 0, 0)
+			}
+			session.LoginAt = at.UTC().Format(time.RFC3339)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// parseSSHAuthLine reads a short-iso journal line from sshd, e.g.
+// "2026-10-17T09:12:03+00:00 web-01 sshd[812]: Accepted publickey for alice
+// from 10.0.0.5 port 51234 ssh2". Lines other than accepted, failed and
+// invalid-user attempts are skipped.
+func parseSSHAuthLine(line string) (AuthEvent, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return AuthEvent{}, false
+	}
+	at, err := time.Parse(time.RFC3339, fields[0])
+	if err != nil {
+		// journalctl before systemd 250 omits the colon in the offset.
+		if at, err = time.Parse("2006-01-02T15:04:05-0700", fields[0]); err != nil {
+			return AuthEvent{}, false
+		}
+	}
+	words := fields[3:]
+	var event AuthEvent
+	switch {
+	case len(words) >= 3 && (words[0] == "Accepted" || words[0] == "Failed") && words[2] == "for":
+		event.Method = words[1]
+		event.Result = AuthResultAccepted
+		if words[0] == "Failed" {
+			event.Result = AuthResultFailed
+		}
+		words = words[3:]
+		if len(words) >= 2 && words[0] == "invalid" && words[1] == "user" {
+			event.Result = AuthResultInvalidUser
+			words = words[2:]
+		}
+	case len(words) >= 2 && words[0] == "Invalid" && words[1] == "user":
+		event.Result = AuthResultInvalidUser
+		words = words[2:]
+	default:
+		return AuthEvent{}, false
+	}
+	from := slices.Index(words, "from")
+	if from < 0 || from+1 >= len(words) {
+		return AuthEvent{}, false
+	}
+	event.User = strings.Join(words[:from], " ")
+	event.Host = words[from+1]
+	if from+3 < len(words) && words[from+2] == "port" {
+		event.Port, _ = strconv.Atoi(words[from+3])
+	}
+	event.At = at.UTC().Format(time.RFC3339)
+	return event, true
+}
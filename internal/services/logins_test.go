@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogins(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	m := newTestManager("linux", func(_ context.Context, name string, args ...string) (string, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		if name == "who" {
+			return "alice    pts/0        2026-02-15 09:12 (10.0.0.5)\nbob      tty1         2026-02-14 22:40", nil
+		}
+		return strings.Join([]string{
+			"2026-02-15T09:12:03+00:00 web-01 sshd[812]: Accepted publickey for alice from 10.0.0.5 port 51234 ssh2: ED25519 SHA256:abc",
+			"2026-02-15T09:20:11+0000 web-01 sshd-session[903]: Failed password for invalid user admin from 203.0.113.9 port 40022 ssh2",
+			"2026-02-15T09:20:12+00:00 web-01 sshd-session[903]: Connection closed by invalid user admin 203.0.113.9 port 40022 [preauth]",
+			"2026-02-15T09:31:40+00:00 web-01 sshd[990]: Failed password for root from 198.51.100.7 port 2201 ssh2",
+			"2026-02-15T09:32:00+00:00 web-01 sshd[991]: Invalid user test from 198.51.100.7 port 2202",
+		}, "\n"), nil
+	})
+
+	logins, err := m.Logins(context.Background(), time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Logins: %v", err)
+	}
+	wantSessions := []LoginSession{
+		{User: "alice", TTY: "pts/0", Host: "10.0.0.5", LoginAt: "2026-02-15T09:12:00Z"},
+		{User: "bob", TTY: "tty1", LoginAt: "2026-02-14T22:40:00Z"},
+	}
+	if !slices.Equal(logins.Sessions, wantSessions) {
+		t.Fatalf("sessions = %+v, want %+v", logins.Sessions, wantSessions)
+	}
+	wantEvents := []AuthEvent{
+		{At: "2026-02-15T09:12:03Z", User: "alice", Host: "10.0.0.5", Port: 51234, Method: "publickey", Result: AuthResultAccepted},
+		{At: "2026-02-15T09:20:11Z", User: "admin", Host: "203.0.113.9", Port: 40022, Method: "password", Result: AuthResultInvalidUser},
+		{At: "2026-02-15T09:31:40Z", User: "root", Host: "198.51.100.7", Port: 2201, Method: "password", Result: AuthResultFailed},
+		{At: "2026-02-15T09:32:00Z", User: "test", Host: "198.51.100.7", Port: 2202, Result: AuthResultInvalidUser},
+	}
+	if !slices.Equal(logins.Events, wantEvents) {
+		t.Fatalf("events = %+v, want %+v", logins.Events, wantEvents)
+	}
+	if want := "journalctl -t sshd -t sshd-session --no-pager -n 1000 --output=short-iso --since=@1771113600"; calls[1] != want {
+		t.Fatalf("journal call = %q, want %q", calls[1], want)
+	}
+
+	if _, err := newTestManager("windows", nil).Logins(context.Background(), time.Time{}); !errors.Is(err, ErrLoginsUnsupported) {
+		t.Fatalf("Logins on windows = %v, want ErrLoginsUnsupported", err)
+	}
+}
+
+func TestParseWhoBSD(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+	sessions := parseWho("alice    ttys000  Dec 31 23:50 \t(192.0.2.4)\nalice    console  Jan  2 07:58", now)
+	if len(sessions) != 2 || sessions[0].LoginAt != "2025-12-31T23:50:00Z" || sessions[0].Host != "192.0.2.4" ||
+		sessions[1].LoginAt != "2026-01-02T07:58:00Z" {
+		t.Fatalf("sessions = %+v", sessions)
+	}
+}
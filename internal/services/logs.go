@@ -3,26 +3,71 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 )
 
 const (
 	defaultLogLines = 100
 	maxLogLines     = 1000
+
+	// launchdLogTimeLayout is the local-time format accepted by log show
+	// --start and --end.
+	launchdLogTimeLayout = "2006-01-02 15:04:05"
 )
 
-// Logs retrieves recent log output for a managed service.
-func (m *Manager) Logs(ctx context.Context, name string, lines int) (string, error) {
+// ErrInvalidLogQuery is returned for an inconsistent log query.
+var ErrInvalidLogQuery = errors.New("invalid log query")
+
+// LogQuery selects which log entries to return. Lines keeps the newest
+// entries of the window bounded by Since and Until (zero means unbounded).
+// Priority keeps journald entries at that syslog level or more severe, and
+// Grep keeps lines matching a regular expression.
+type LogQuery struct {
+	Lines    int
+	Since    time.Time
+	Until    time.Time
+	Priority string
+	Grep     string
+}
+
+// normalize applies line defaults and validates the query.
+func (q LogQuery) normalize() (LogQuery, error) {
+	if q.Lines <= 0 {
+		q.Lines = defaultLogLines
+	}
+	if q.Lines > maxLogLines {
+		q.Lines = maxLogLines
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && q.Until.Before(q.Since) {
+		return q, fmt.Errorf("%w: since must not be after until", ErrInvalidLogQuery)
+	}
+	priority, err := normalizeLogPriority(q.Priority)
+	if err != nil {
+		return q, err
+	}
+	q.Priority = priority
+	q.Grep = strings.TrimSpace(q.Grep)
+	if q.Grep != "" {
+		if _, err := regexp.Compile(q.Grep); err != nil {
+			return q, fmt.Errorf("%w: grep: %v", ErrInvalidLogQuery, err)
+		}
+	}
+	return q, nil
+}
+
+// Logs retrieves log output for a managed service.
+func (m *Manager) Logs(ctx context.Context, name string, query LogQuery) (string, error) {
 	serviceName, ok := normalizeServiceName(name)
 	if !ok {
 		return "", ErrServiceNotFound
 	}
-	if lines <= 0 {
-		lines = defaultLogLines
-	}
-	if lines > maxLogLines {
-		lines = maxLogLines
+	query, err := query.normalize()
+	if err != nil {
+		return "", err
 	}
 
 	services, err := m.ListServices(ctx)
@@ -36,25 +81,43 @@ func (m *Manager) Logs(ctx context.Context, name string, lines int) (string, err
 
 	switch target.Manager {
 	case managerSystemd:
-		return m.logsSystemd(ctx, target, lines)
+		return m.logsSystemd(ctx, target, query)
 	case managerLaunchd:
-		return m.logsLaunchd(ctx, target, lines)
+		return m.logsLaunchd(ctx, target.Unit, query)
+	case managerDocker:
+		return m.logsDocker(ctx, target.Unit, query)
+	case managerWindows:
+		return m.logsWindows(ctx, target.Unit, query)
+	case managerRCD:
+		return m.logsRCD(ctx, target.Unit, query)
 	default:
 		return "", fmt.Errorf("unsupported service manager: %s", target.Manager)
 	}
 }
 
-func (m *Manager) logsSystemd(ctx context.Context, target ServiceStatus, lines int) (string, error) {
-	args := make([]string, 0, 8)
+func (m *Manager) logsSystemd(ctx context.Context, target ServiceStatus, query LogQuery) (string, error) {
+	args := make([]string, 0, 12)
 	if strings.EqualFold(target.Scope, scopeUser) {
 		args = append(args, "--user")
 	}
 	args = append(args,
 		"-u", target.Unit,
 		"--no-pager",
-		"-n", fmt.Sprintf("%d", lines),
+		"-n", fmt.Sprintf("%d", query.Lines),
 		"--output=short-iso",
 	)
+	if !query.Since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=@%d", query.Since.Unix()))
+	}
+	if !query.Until.IsZero() {
+		args = append(args, fmt.Sprintf("--until=@%d", query.Until.Unix()))
+	}
+	if query.Priority != "" {
+		args = append(args, "--priority="+query.Priority)
+	}
+	if query.Grep != "" {
+		args = append(args, "--grep="+query.Grep)
+	}
 	out, err := m.commandRunner(ctx, "journalctl", args...)
 	if err != nil {
 		return "", fmt.Errorf("journalctl failed: %w", err)
@@ -62,17 +125,15 @@ func (m *Manager) logsSystemd(ctx context.Context, target ServiceStatus, lines i
 	return out, nil
 }
 
-// LogsByUnit retrieves recent log output for a service identified by
+// LogsByUnit retrieves log output for a service identified by
 // unit/scope/manager directly, without requiring the service to be tracked.
-func (m *Manager) LogsByUnit(ctx context.Context, unit, scope, manager string, lines int) (string, error) {
+func (m *Manager) LogsByUnit(ctx context.Context, unit, scope, manager string, query LogQuery) (string, error) {
 	if !IsValidUnit(unit) {
 		return "", ErrInvalidUnit
 	}
-	if lines <= 0 {
-		lines = defaultLogLines
-	}
-	if lines > maxLogLines {
-		lines = maxLogLines
+	query, err := query.normalize()
+	if err != nil {
+		return "", err
 	}
 
 	target := ServiceStatus{
@@ -83,47 +144,74 @@ func (m *Manager) LogsByUnit(ctx context.Context, unit, scope, manager string, l
 
 	switch manager {
 	case managerSystemd:
-		return m.logsSystemd(ctx, target, lines)
+		return m.logsSystemd(ctx, target, query)
 	case managerLaunchd:
-		return m.logsLaunchdUnit(ctx, unit, lines)
+		return m.logsLaunchd(ctx, unit, query)
+	case managerDocker:
+		return m.logsDocker(ctx, unit, query)
+	case managerWindows:
+		return m.logsWindows(ctx, unit, query)
+	case managerRCD:
+		return m.logsRCD(ctx, unit, query)
 	default:
 		return "", fmt.Errorf("unsupported service manager: %s", manager)
 	}
 }
 
-func (m *Manager) logsLaunchdUnit(ctx context.Context, label string, lines int) (string, error) {
-	out, err := m.commandRunner(ctx, "log", "show",
-		"--predicate", fmt.Sprintf(`senderImagePath CONTAINS "%s" OR subsystem == "%s"`, label, label),
-		"--style", "compact",
-		"--last", fmt.Sprintf("%dm", max(lines/10, 5)),
-	)
-	if err != nil {
-		return "", fmt.Errorf("log show failed: %w", err)
-	}
-	outputLines := strings.Split(out, "\n")
-	if len(outputLines) > lines {
-		outputLines = outputLines[len(outputLines)-lines:]
-	}
-	return strings.Join(outputLines, "\n"), nil
-}
-
-func (m *Manager) logsLaunchd(ctx context.Context, target ServiceStatus, lines int) (string, error) {
-	label := target.Unit
+// logsLaunchd reads the unified log for a launchd label. Without a time
+// window it looks back a span proportional to the requested line count.
+func (m *Manager) logsLaunchd(ctx context.Context, label string, query LogQuery) (string, error) {
 	if !IsValidUnit(label) {
 		return "", ErrInvalidUnit
 	}
-	out, err := m.commandRunner(ctx, "log", "show",
+	if query.Priority != "" {
+		return "", ErrPriorityUnsupported
+	}
+	args := []string{
+		"show",
 		"--predicate", fmt.Sprintf(`senderImagePath CONTAINS "%s" OR subsystem == "%s"`, label, label),
 		"--style", "compact",
-		"--last", fmt.Sprintf("%dm", max(lines/10, 5)),
-	)
+	}
+	switch {
+	case !query.Since.IsZero():
+		args = append(args, "--start", query.Since.Local().Format(launchdLogTimeLayout))
+		if !query.Until.IsZero() {
+			args = append(args, "--end", query.Until.Local().Format(launchdLogTimeLayout))
+		}
+	case !query.Until.IsZero():
+		args = append(args, "--end", query.Until.Local().Format(launchdLogTimeLayout))
+	default:
+		args = append(args, "--last", fmt.Sprintf("%dm", max(query.Lines/10, 5)))
+	}
+	out, err := m.commandRunner(ctx, "log", args...)
 	if err != nil {
 		return "", fmt.Errorf("log show failed: %w", err)
 	}
-	// Trim to requested line count.
-	outputLines := strings.Split(out, "\n")
-	if len(outputLines) > lines {
-		outputLines = outputLines[len(outputLines)-lines:]
+	return tailLogLines(grepLogLines(out, query.Grep), query.Lines), nil
+}
+
+// grepLogLines keeps the lines matching pattern; an empty pattern keeps all.
+// The pattern was validated by LogQuery.normalize.
+func grepLogLines(out, pattern string) string {
+	if pattern == "" {
+		return out
+	}
+	re := regexp.MustCompile(pattern)
+	lines := strings.Split(out, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if re.MatchString(line) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// tailLogLines trims output to its last n lines.
+func tailLogLines(out string, n int) string {
+	lines := strings.Split(out, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
 	}
-	return strings.Join(outputLines, "\n"), nil
+	return strings.Join(lines, "\n")
 }
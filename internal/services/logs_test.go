@@ -136,7 +136,7 @@ func TestLogs(t *testing.T) {
 			t.Parallel()
 
 			m := newLogsTestManager(tc.goos, tc.runner)
-			out, err := m.Logs(context.Background(), tc.svcName, tc.lines)
+			out, err := m.Logs(context.Background(), tc.svcName, LogQuery{Lines: tc.lines})
 			if tc.wantErr != nil {
 				if err == nil {
 					t.Fatalf("expected error containing %q, got nil", tc.wantErr)
@@ -169,7 +169,7 @@ func TestLogsUnsupportedManager(t *testing.T) {
 	// We can't directly trigger "unsupported manager" for built-in services
 	// since detectManager always returns systemd or launchd based on goos.
 	// Instead, test via LogsByUnit with an unsupported manager.
-	_, err := m.LogsByUnit(context.Background(), "some.service", "user", "openrc", 50)
+	_, err := m.LogsByUnit(context.Background(), "some.service", "user", "openrc", LogQuery{Lines: 50})
 	if err == nil {
 		t.Fatal("expected error for unsupported manager")
 	}
@@ -293,7 +293,7 @@ func TestLogsByUnit(t *testing.T) {
 				commandRunner: tc.runner,
 			}
 
-			_, err := m.LogsByUnit(context.Background(), tc.unit, tc.scope, tc.manager, tc.lines)
+			_, err := m.LogsByUnit(context.Background(), tc.unit, tc.scope, tc.manager, LogQuery{Lines: tc.lines})
 			if tc.wantErr != "" {
 				if err == nil {
 					t.Fatalf("expected error containing %q, got nil", tc.wantErr)
@@ -327,7 +327,7 @@ func TestLogsLaunchdTruncatesOutput(t *testing.T) {
 		return "", nil
 	})
 
-	out, err := m.Logs(context.Background(), "sentinel", 10)
+	out, err := m.Logs(context.Background(), "sentinel", LogQuery{Lines: 10})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -336,3 +336,79 @@ func TestLogsLaunchdTruncatesOutput(t *testing.T) {
 		t.Fatalf("output has %d lines, want <= 10", len(outputLines))
 	}
 }
+
+func TestLogsQueryFilters(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	until := since.Add(30 * time.Minute)
+
+	var got []string
+	m := newLogsTestManager("linux", func(_ context.Context, name string, args ...string) (string, error) {
+		got = append([]string{name}, args...)
+		return "ok", nil
+	})
+	query := LogQuery{Lines: 20, Since: since, Until: until, Priority: "warn", Grep: "timeout|refused"}
+	if _, err := m.LogsByUnit(context.Background(), "nginx.service", scopeSystem, managerSystemd, query); err != nil {
+		t.Fatalf("LogsByUnit() error = %v", err)
+	}
+	want := []string{
+		cmdJournalctl, "-u", "nginx.service", "--no-pager", "-n", "20", "--output=short-iso",
+		"--since=@1792054800", "--until=@1792056600", "--priority=warning", "--grep=timeout|refused",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("journalctl args = %#v, want %#v", got, want)
+	}
+}
+
+func TestLogsQueryValidation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	m := newLogsTestManager("linux", func(context.Context, string, ...string) (string, error) {
+		t.Fatal("command should not run for an invalid query")
+		return "", nil
+	})
+
+	tests := []struct {
+		name    string
+		query   LogQuery
+		wantErr error
+	}{
+		{"since after until", LogQuery{Since: now, Until: now.Add(-time.Minute)}, ErrInvalidLogQuery},
+		{"bad grep", LogQuery{Grep: "("}, ErrInvalidLogQuery},
+		{"bad priority", LogQuery{Priority: "loud"}, ErrInvalidLogPriority},
+	}
+	for _, tc := range tests {
+		if _, err := m.LogsByUnit(context.Background(), "nginx.service", scopeSystem, managerSystemd, tc.query); !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: error = %v, want %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestLogsLaunchdTimeWindowAndGrep(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)
+	var got []string
+	m := newLogsTestManager("darwin", func(_ context.Context, name string, args ...string) (string, error) {
+		got = args
+		return "starting\nconnection refused\nready", nil
+	})
+	out, err := m.LogsByUnit(context.Background(), "com.example.app", scopeUser, managerLaunchd,
+		LogQuery{Since: since, Grep: "refused"})
+	if err != nil {
+		t.Fatalf("LogsByUnit() error = %v", err)
+	}
+	if out != "connection refused" {
+		t.Fatalf("output = %q, want grep match only", out)
+	}
+	if !slices.Contains(got, "--start") || !slices.Contains(got, "2026-10-15 09:00:00") || slices.Contains(got, "--last") {
+		t.Fatalf("log show args = %#v, want --start window", got)
+	}
+
+	if _, err := m.LogsByUnit(context.Background(), "com.example.app", scopeUser, managerLaunchd,
+		LogQuery{Priority: "err"}); !errors.Is(err, ErrPriorityUnsupported) {
+		t.Fatalf("priority error = %v, want ErrPriorityUnsupported", err)
+	}
+}
@@ -65,7 +65,8 @@ var (
 	ErrServiceNotFound = errors.New("ops service not found")
 	// ErrInvalidAction is returned when an ops service action is not supported.
 	ErrInvalidAction = errors.New("ops invalid action")
-	// ErrInvalidUnit is returned when a service unit or launchd label is unsafe.
+	// ErrInvalidUnit is returned when a service unit, launchd label, or
+	// container name is unsafe.
 	ErrInvalidUnit = errors.New("ops invalid unit")
 
 	systemdBrowseUnitTypes = []string{
@@ -96,6 +97,10 @@ type ServiceStatus struct {
 	ActiveState  string `json:"activeState"`
 	LastRunState string `json:"lastRunState,omitempty"`
 	UpdatedAt    string `json:"updatedAt"`
+	// Health is set for custom services with health checks once they ran.
+	Health *ServiceHealth `json:"health,omitempty"`
+	// Hooks lists the restart hooks run by Act, in order.
+	Hooks []HookResult `json:"hooks,omitempty"`
 }
 
 // ServiceInspect represents service inspect data.
@@ -131,10 +136,14 @@ type Summary struct {
 
 // Overview represents overview data.
 type Overview struct {
-	Host      HostOverview     `json:"host"`
-	Sentinel  SentinelOverview `json:"sentinel"`
-	Services  Summary          `json:"services"`
-	UpdatedAt string           `json:"updatedAt"`
+	Host     HostOverview     `json:"host"`
+	Sentinel SentinelOverview `json:"sentinel"`
+	Services Summary          `json:"services"`
+	// Packages is the last package update check, on Linux hosts only.
+	Packages *PackageUpdates `json:"packages,omitempty"`
+	// Uptime counts the enabled uptime checks by status.
+	Uptime    *UptimeSummary `json:"uptime,omitempty"`
+	UpdatedAt string         `json:"updatedAt"`
 }
 
 // Manager represents manager data.
@@ -147,8 +156,28 @@ type Manager struct {
 	customServices customServicesRepo
 	metricsMu      sync.Mutex
 	metrics        *metricsCollector
+	diskScan       *diskScanner
+	packages       *packageChecker
+	dockerLookup   func() bool
+	scm            serviceControlManager
+	health         healthCache
+	// upsSource and upsName are set once at startup by SetUPS.
+	upsSource string
+	upsName   string
+	// certPaths, certEndpoints and certWarnDays are set by
+	// SetCertificates and certs caches the last check, all under metricsMu.
+	certPaths     []string
+	certEndpoints []string
+	certWarnDays  int
+	certs         *Certificates
+	// uptimeChecks is set once at startup by SetUptimeChecks.
+	uptimeChecks uptimeRepo
+	// heartbeats is set once at startup by SetHeartbeats.
+	heartbeats heartbeatRepo
 
 	commandRunner commandRunner
+	// remote is set by NewRemoteManager.
+	remote bool
 }
 
 // NewManager creates manager.
@@ -165,12 +194,23 @@ func NewManager(startedAt time.Time, csRepo customServicesRepo) *Manager {
 		goos:           runtime.GOOS,
 		customServices: csRepo,
 		metrics:        newMetricsCollector(),
+		diskScan:       newDiskScanner(DefaultDiskScanRoots),
+		dockerLookup:   hasDockerCLI,
+		scm:            newServiceControlManager(),
 		commandRunner:  runCommand,
 	}
 }
 
+func hasDockerCLI() bool {
+	_, err := exec.LookPath("docker")
+	return err == nil
+}
+
 // Metrics returns value.
 func (m *Manager) Metrics(ctx context.Context) HostMetrics {
+	if m != nil && m.remote {
+		return HostMetrics{}
+	}
 	return m.metricsCollector().Collect(ctx, "/")
 }
 
@@ -200,6 +240,16 @@ func (m *Manager) Overview(ctx context.Context) (Overview, error) {
 		uptime = 0
 	}
 
+	if m.remote {
+		out := Overview{
+			Host:      HostOverview{Hostname: strings.TrimSpace(hostname), OS: m.goos},
+			UpdatedAt: now.Format(time.RFC3339),
+		}
+		out.Services = summarizeServices(services)
+		out.Packages = m.overviewPackages()
+		return out, nil
+	}
+
 	out := Overview{
 		Host: HostOverview{
 			Hostname:  strings.TrimSpace(hostname),
@@ -215,17 +265,33 @@ func (m *Manager) Overview(ctx context.Context) (Overview, error) {
 		UpdatedAt: now.Format(time.RFC3339),
 	}
 
-	out.Services.Total = len(services)
+	out.Services = summarizeServices(services)
+	out.Packages = m.overviewPackages()
+	out.Uptime = m.UptimeSummary(ctx)
+	return out, nil
+}
+
+// overviewPackages returns the cached package update check, or nil where
+// packages are not checked.
+func (m *Manager) overviewPackages() *PackageUpdates {
+	updates, err := m.PackageUpdates(false)
+	if err != nil {
+		return nil
+	}
+	return &updates
+}
+
+func summarizeServices(services []ServiceStatus) Summary {
+	summary := Summary{Total: len(services)}
 	for _, item := range services {
 		switch strings.ToLower(strings.TrimSpace(item.ActiveState)) {
 		case stateActive, stateRunning:
-			out.Services.Active++
+			summary.Active++
 		case stateFailed:
-			out.Services.Failed++
+			summary.Failed++
 		}
 	}
-
-	return out, nil
+	return summary
 }
 
 // ListServices lists services.
@@ -251,6 +317,9 @@ func (m *Manager) ListServices(ctx context.Context) ([]ServiceStatus, error) {
 				UpdatedAt:   now,
 			}
 			m.probeCustomService(ctx, &svc)
+			if health, ok := m.health.get(cs.Name); ok {
+				svc.Health = &health
+			}
 			services = append(services, svc)
 		}
 	}
@@ -297,6 +366,12 @@ func (m *Manager) probeCustomService(ctx context.Context, svc *ServiceStatus) {
 		svc.Exists = true
 		svc.ActiveState = launchdActiveState(out)
 		svc.EnabledState = "enabled"
+	case managerDocker:
+		m.probeDockerContainer(ctx, svc)
+	case managerWindows:
+		m.probeWindowsService(ctx, svc)
+	case managerRCD:
+		m.probeRCDService(ctx, svc)
 	default:
 		svc.Exists = false
 		svc.ActiveState = stateUnknown
@@ -304,7 +379,8 @@ func (m *Manager) probeCustomService(ctx context.Context, svc *ServiceStatus) {
 	}
 }
 
-// Act runs value.
+// Act runs value. Restarting a custom service runs its restart hooks
+// around the restart.
 func (m *Manager) Act(ctx context.Context, name, action string) (ServiceStatus, error) {
 	serviceName, ok := normalizeServiceName(name)
 	if !ok {
@@ -323,6 +399,21 @@ func (m *Manager) Act(ctx context.Context, name, action string) (ServiceStatus,
 	if !ok {
 		return ServiceStatus{}, ErrServiceNotFound
 	}
+
+	var hooks *store.RestartHooks
+	if action == ActionRestart {
+		if hooks, err = m.restartHooks(ctx, serviceName); err != nil {
+			return ServiceStatus{}, err
+		}
+	}
+	if hooks != nil && hooks.PreStop != nil {
+		result := runHook(ctx, HookPreStop, hooks.PreStop)
+		target.Hooks = append(target.Hooks, result)
+		if !result.OK {
+			return target, fmt.Errorf("%w: %s: %s", ErrHookFailed, HookPreStop, result.Detail)
+		}
+	}
+
 	switch target.Manager {
 	case managerSystemd:
 		if err := m.actSystemd(ctx, target.Scope, target.Unit, action); err != nil {
@@ -332,10 +423,28 @@ func (m *Manager) Act(ctx context.Context, name, action string) (ServiceStatus,
 		if err := m.actLaunchd(ctx, target.Scope, target.Unit, action); err != nil {
 			return ServiceStatus{}, err
 		}
+	case managerDocker:
+		if err := m.actDocker(ctx, target.Unit, action); err != nil {
+			return ServiceStatus{}, err
+		}
+	case managerWindows:
+		if err := m.actWindows(ctx, target.Unit, action); err != nil {
+			return ServiceStatus{}, err
+		}
+	case managerRCD:
+		if err := m.actRCD(ctx, target.Unit, action); err != nil {
+			return ServiceStatus{}, err
+		}
 	default:
 		return ServiceStatus{}, fmt.Errorf("unsupported service manager: %s", target.Manager)
 	}
 
+	// The restart went through, so a failing post-start hook is reported in
+	// Hooks rather than as an error.
+	if hooks != nil && hooks.PostStart != nil {
+		target.Hooks = append(target.Hooks, runHook(ctx, HookPostStart, hooks.PostStart))
+	}
+
 	m.probeCustomService(ctx, &target)
 	return target, nil
 }
@@ -379,6 +488,32 @@ func (m *Manager) Inspect(ctx context.Context, name string) (ServiceInspect, err
 			return ServiceInspect{}, inspectErr
 		}
 		inspect.Output = output
+	case managerDocker:
+		props, output, inspectErr := m.inspectDocker(ctx, target.Unit)
+		if inspectErr != nil {
+			return ServiceInspect{}, inspectErr
+		}
+		inspect.Properties = props
+		inspect.Output = output
+		if summary := buildDockerSummary(props); summary != "" {
+			inspect.Summary = summary
+		}
+	case managerWindows:
+		props, output, inspectErr := m.inspectWindows(ctx, target.Unit)
+		if inspectErr != nil {
+			return ServiceInspect{}, inspectErr
+		}
+		inspect.Properties = props
+		inspect.Output = output
+		inspect.Summary = buildWindowsSummary(props)
+	case managerRCD:
+		props, output, inspectErr := m.inspectRCD(ctx, target.Unit)
+		if inspectErr != nil {
+			return ServiceInspect{}, inspectErr
+		}
+		inspect.Properties = props
+		inspect.Output = output
+		inspect.Summary = buildRCDSummary(props)
 	default:
 		return ServiceInspect{}, fmt.Errorf("unsupported service manager: %s", target.Manager)
 	}
@@ -499,6 +634,12 @@ func detectManager(goos string) string {
 	if strings.EqualFold(goos, "darwin") {
 		return managerLaunchd
 	}
+	if strings.EqualFold(goos, "windows") {
+		return managerWindows
+	}
+	if strings.EqualFold(goos, "freebsd") {
+		return managerRCD
+	}
 	return managerSystemd
 }
 
@@ -736,6 +877,27 @@ func (m *Manager) DiscoverServices(ctx context.Context) ([]AvailableService, err
 			u.Scope = scope
 			out = append(out, u)
 		}
+	case managerWindows, managerRCD:
+		discover := m.discoverWindowsServices
+		if manager == managerRCD {
+			discover = m.discoverRCDServices
+		}
+		units, err := discover(ctx)
+		if err != nil {
+			slog.Warn("service discovery failed", "manager", manager, "err", err)
+		}
+		for _, u := range units {
+			if !trackedUnits[serviceKey(manager, u.Scope, u.Unit)] {
+				out = append(out, u)
+			}
+		}
+	}
+
+	for _, u := range m.dockerUnits(ctx) {
+		if trackedUnits[serviceKey(managerDocker, u.Scope, u.Unit)] {
+			continue
+		}
+		out = append(out, u)
 	}
 
 	return out, nil
@@ -891,6 +1053,12 @@ type BrowsedService struct {
 	Scope        string `json:"scope"`
 	Tracked      bool   `json:"tracked"`
 	TrackedName  string `json:"trackedName,omitempty"`
+
+	// Timer schedule (RFC3339) and socket listeners for systemd units.
+	NextElapse  string   `json:"nextElapse,omitempty"`
+	LastTrigger string   `json:"lastTrigger,omitempty"`
+	Listen      []string `json:"listen,omitempty"`
+	Activates   []string `json:"activates,omitempty"`
 }
 
 // BrowseServices returns all manageable units discovered on the host,
@@ -920,6 +1088,7 @@ func (m *Manager) BrowseServices(ctx context.Context) ([]BrowsedService, error)
 			if err != nil {
 				slog.Warn("service discovery failed", "manager", "systemd", "scope", scope, "err", err)
 			}
+			start := len(result)
 			for _, u := range units {
 				key := serviceKey(managerSystemd, scope, u.Unit)
 				if seen[key] {
@@ -941,6 +1110,7 @@ func (m *Manager) BrowseServices(ctx context.Context) ([]BrowsedService, error)
 				}
 				result = append(result, bs)
 			}
+			m.annotateSystemdUnits(ctx, scope, result[start:])
 		}
 	case managerLaunchd:
 		units, err := m.discoverLaunchdUnits(ctx)
@@ -972,6 +1142,58 @@ func (m *Manager) BrowseServices(ctx context.Context) ([]BrowsedService, error)
 			}
 			result = append(result, bs)
 		}
+	case managerWindows, managerRCD:
+		discover := m.discoverWindowsServices
+		if manager == managerRCD {
+			discover = m.discoverRCDServices
+		}
+		units, err := discover(ctx)
+		if err != nil {
+			slog.Warn("service discovery failed", "manager", manager, "err", err)
+		}
+		for _, u := range units {
+			key := serviceKey(manager, u.Scope, u.Unit)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			bs := BrowsedService{
+				Unit:         u.Unit,
+				UnitType:     u.UnitType,
+				Description:  u.Description,
+				ActiveState:  u.ActiveState,
+				EnabledState: u.EnabledState,
+				Manager:      manager,
+				Scope:        u.Scope,
+			}
+			if info, ok := trackedMap[key]; ok {
+				bs.Tracked = true
+				bs.TrackedName = info.Name
+			}
+			result = append(result, bs)
+		}
+	}
+
+	for _, u := range m.dockerUnits(ctx) {
+		key := serviceKey(managerDocker, u.Scope, u.Unit)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		bs := BrowsedService{
+			Unit:         u.Unit,
+			UnitType:     u.UnitType,
+			Description:  u.Description,
+			ActiveState:  u.ActiveState,
+			EnabledState: u.EnabledState,
+			Manager:      managerDocker,
+			Scope:        u.Scope,
+		}
+		if info, ok := trackedMap[key]; ok {
+			bs.Tracked = true
+			bs.TrackedName = info.Name
+		}
+		result = append(result, bs)
 	}
 
 	// Inject tracked services that were not returned by discover (e.g. built-ins).
@@ -1013,6 +1235,12 @@ func (m *Manager) ActByUnit(ctx context.Context, unit, scope, manager, action st
 		return m.actSystemdUnit(ctx, scope, unit, action)
 	case managerLaunchd:
 		return m.actLaunchdUnit(ctx, scope, unit, action)
+	case managerDocker:
+		return m.actDocker(ctx, unit, action)
+	case managerWindows:
+		return m.actWindows(ctx, unit, action)
+	case managerRCD:
+		return m.actRCD(ctx, unit, action)
 	default:
 		return fmt.Errorf("unsupported service manager: %s", manager)
 	}
@@ -1103,6 +1331,32 @@ func (m *Manager) InspectByUnit(ctx context.Context, unit, scope, manager string
 			return ServiceInspect{}, fmt.Errorf("launchd inspect failed: %w", err)
 		}
 		inspect.Output = out
+	case managerDocker:
+		props, output, err := m.inspectDocker(ctx, unit)
+		if err != nil {
+			return ServiceInspect{}, err
+		}
+		inspect.Properties = props
+		inspect.Output = output
+		if summary := buildDockerSummary(props); summary != "" {
+			inspect.Summary = summary
+		}
+	case managerWindows:
+		props, output, err := m.inspectWindows(ctx, unit)
+		if err != nil {
+			return ServiceInspect{}, err
+		}
+		inspect.Properties = props
+		inspect.Output = output
+		inspect.Summary = buildWindowsSummary(props)
+	case managerRCD:
+		props, output, err := m.inspectRCD(ctx, unit)
+		if err != nil {
+			return ServiceInspect{}, err
+		}
+		inspect.Properties = props
+		inspect.Output = output
+		inspect.Summary = buildRCDSummary(props)
 	default:
 		return ServiceInspect{}, fmt.Errorf("unsupported service manager: %s", manager)
 	}
@@ -1134,6 +1388,10 @@ func browseUnitType(manager, unit string) string {
 	switch {
 	case strings.EqualFold(manager, managerLaunchd):
 		return unitTypeJob
+	case strings.EqualFold(manager, managerDocker):
+		return unitTypeContainer
+	case strings.EqualFold(manager, managerWindows), strings.EqualFold(manager, managerRCD):
+		return unitTypeService
 	case !strings.EqualFold(manager, managerSystemd):
 		return unitTypeUnit
 	}
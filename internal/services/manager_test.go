@@ -1303,10 +1303,10 @@ func TestUnitValidationRejectsUnsafeValuesBeforeExec(t *testing.T) {
 		if _, err := m.InspectByUnit(ctx, unit, scopeUser, managerSystemd); !errors.Is(err, ErrInvalidUnit) {
 			t.Fatalf("InspectByUnit(%q) error = %v, want ErrInvalidUnit", unit, err)
 		}
-		if _, err := m.LogsByUnit(ctx, unit, scopeUser, managerSystemd, 10); !errors.Is(err, ErrInvalidUnit) {
+		if _, err := m.LogsByUnit(ctx, unit, scopeUser, managerSystemd, LogQuery{Lines: 10}); !errors.Is(err, ErrInvalidUnit) {
 			t.Fatalf("LogsByUnit(%q) error = %v, want ErrInvalidUnit", unit, err)
 		}
-		if _, err := m.StreamLogsByUnit(ctx, unit, scopeUser, managerSystemd); !errors.Is(err, ErrInvalidUnit) {
+		if _, err := m.StreamLogsByUnit(ctx, unit, scopeUser, managerSystemd, ""); !errors.Is(err, ErrInvalidUnit) {
 			t.Fatalf("StreamLogsByUnit(%q) error = %v, want ErrInvalidUnit", unit, err)
 		}
 	}
@@ -1470,3 +1470,47 @@ func TestInspectUnsupportedManager(t *testing.T) {
 		t.Fatalf("error = %v, want 'unsupported service manager'", err)
 	}
 }
+
+func TestStartUpdateQueuesUpdaterService(t *testing.T) {
+	t.Parallel()
+
+	var calls [][]string
+	m := &Manager{
+		nowFn:          time.Now,
+		uidFn:          func() int { return 1000 },
+		goos:           "linux",
+		hostname:       func() (string, error) { return testHostname, nil },
+		customServices: builtinServicesRepo("linux"),
+		commandRunner: func(_ context.Context, name string, args ...string) (string, error) {
+			calls = append(calls, append([]string{name}, args...))
+			return "", nil
+		},
+	}
+
+	if _, err := m.StartUpdate(context.Background()); err != nil {
+		t.Fatalf("StartUpdate: %v", err)
+	}
+	want := []string{"systemctl", "--user", "start", "--no-block", updaterSystemdService}
+	if !slices.ContainsFunc(calls, func(c []string) bool { return reflect.DeepEqual(c, want) }) {
+		t.Fatalf("expected %v among calls %v", want, calls)
+	}
+}
+
+func TestStartUpdateRequiresInstalledUpdater(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{
+		nowFn:          time.Now,
+		uidFn:          func() int { return 1000 },
+		goos:           "linux",
+		hostname:       func() (string, error) { return testHostname, nil },
+		customServices: builtinServicesRepo("linux"),
+		commandRunner: func(context.Context, string, ...string) (string, error) {
+			return "LoadState=not-found\n", nil
+		},
+	}
+
+	if _, err := m.StartUpdate(context.Background()); !errors.Is(err, ErrUpdaterNotInstalled) {
+		t.Fatalf("StartUpdate error = %v, want ErrUpdaterNotInstalled", err)
+	}
+}
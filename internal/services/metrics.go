@@ -1,8 +1,10 @@
 package services
 
 import (
+	"cmp"
 	"context"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 )
@@ -17,43 +19,50 @@ const (
 
 // HostMetrics holds a snapshot of host resource metrics.
 type HostMetrics struct {
-	CPUPercent        float64 `json:"cpuPercent"`
-	CPUCount          int     `json:"cpuCount"`
-	LoadAvg1          float64 `json:"loadAvg1"`
-	LoadAvg5          float64 `json:"loadAvg5"`
-	LoadAvg15         float64 `json:"loadAvg15"`
-	LoadPerCPU        float64 `json:"loadPerCPU"`
-	MemUsedBytes      int64   `json:"memUsedBytes"`
-	MemTotalBytes     int64   `json:"memTotalBytes"`
-	MemAvailableBytes int64   `json:"memAvailableBytes"`
-	MemPercent        float64 `json:"memPercent"`
-	SwapUsedBytes     int64   `json:"swapUsedBytes"`
-	SwapTotalBytes    int64   `json:"swapTotalBytes"`
-	SwapPercent       float64 `json:"swapPercent"`
-	DiskUsedBytes     int64   `json:"diskUsedBytes"`
-	DiskTotalBytes    int64   `json:"diskTotalBytes"`
-	DiskFreeBytes     int64   `json:"diskFreeBytes"`
-	DiskPercent       float64 `json:"diskPercent"`
-	DiskInodesUsed    int64   `json:"diskInodesUsed"`
-	DiskInodesTotal   int64   `json:"diskInodesTotal"`
-	DiskInodesPercent float64 `json:"diskInodesPercent"`
-	NetRxBytes        int64   `json:"netRxBytes"`
-	NetTxBytes        int64   `json:"netTxBytes"`
-	NetInterfaces     int     `json:"netInterfaces"`
-	ProcessCount      int     `json:"processCount"`
-	ThreadCount       int     `json:"threadCount"`
-	HostUptimeSec     int64   `json:"hostUptimeSec"`
-	BootTime          string  `json:"bootTime"`
-	CPUPressureAvg10  float64 `json:"cpuPressureAvg10"`
-	MemPressureAvg10  float64 `json:"memPressureAvg10"`
-	IOPressureAvg10   float64 `json:"ioPressureAvg10"`
-	NumGoroutines     int     `json:"numGoroutines"`
-	GoMemAllocMB      float64 `json:"goMemAllocMB"`
-	GoMemSysMB        float64 `json:"goMemSysMB"`
-	GoHeapObjects     uint64  `json:"goHeapObjects"`
-	GoNumGC           uint32  `json:"goNumGC"`
-	GoLastGCPauseMs   float64 `json:"goLastGcPauseMs"`
-	CollectedAt       string  `json:"collectedAt"`
+	CPUPercent           float64            `json:"cpuPercent"`
+	CPUCount             int                `json:"cpuCount"`
+	LoadAvg1             float64            `json:"loadAvg1"`
+	LoadAvg5             float64            `json:"loadAvg5"`
+	LoadAvg15            float64            `json:"loadAvg15"`
+	LoadPerCPU           float64            `json:"loadPerCPU"`
+	MemUsedBytes         int64              `json:"memUsedBytes"`
+	MemTotalBytes        int64              `json:"memTotalBytes"`
+	MemAvailableBytes    int64              `json:"memAvailableBytes"`
+	MemPercent           float64            `json:"memPercent"`
+	SwapUsedBytes        int64              `json:"swapUsedBytes"`
+	SwapTotalBytes       int64              `json:"swapTotalBytes"`
+	SwapPercent          float64            `json:"swapPercent"`
+	DiskUsedBytes        int64              `json:"diskUsedBytes"`
+	DiskTotalBytes       int64              `json:"diskTotalBytes"`
+	DiskFreeBytes        int64              `json:"diskFreeBytes"`
+	DiskPercent          float64            `json:"diskPercent"`
+	DiskInodesUsed       int64              `json:"diskInodesUsed"`
+	DiskInodesTotal      int64              `json:"diskInodesTotal"`
+	DiskInodesPercent    float64            `json:"diskInodesPercent"`
+	DiskMounts           []DiskMount        `json:"diskMounts,omitempty"`
+	NetRxBytes           int64              `json:"netRxBytes"`
+	NetTxBytes           int64              `json:"netTxBytes"`
+	NetInterfaces        int                `json:"netInterfaces"`
+	NetRxBytesPerSec     float64            `json:"netRxBytesPerSec"`
+	NetTxBytesPerSec     float64            `json:"netTxBytesPerSec"`
+	NetRxErrors          int64              `json:"netRxErrors"`
+	NetTxErrors          int64              `json:"netTxErrors"`
+	NetSaturationPercent float64            `json:"netSaturationPercent"`
+	NetworkInterfaces    []NetworkInterface `json:"networkInterfaces,omitempty"`
+	ProcessCount         int                `json:"processCount"`
+	ThreadCount          int                `json:"threadCount"`
+	HostUptimeSec        int64              `json:"hostUptimeSec"`
+	BootTime             string             `json:"bootTime"`
+	CPUPressureAvg10     float64            `json:"cpuPressureAvg10"`
+	MemPressureAvg10     float64            `json:"memPressureAvg10"`
+	IOPressureAvg10      float64            `json:"ioPressureAvg10"`
+	NumGoroutines        int                `json:"numGoroutines"`
+	GoMemAllocMB         float64            `json:"goMemAllocMB"`
+	GoMemSysMB           float64            `json:"goMemSysMB"`
+	GoHeapObjects        uint64             `json:"goHeapObjects"`
+	GoNumGC              uint32             `json:"goNumGC"`
+	GoLastGCPauseMs      float64            `json:"goLastGcPauseMs"`
+	CollectedAt          string             `json:"collectedAt"`
 }
 
 type memorySample struct {
@@ -72,10 +81,62 @@ type diskSample struct {
 	inodesTotal int64
 }
 
+// DiskMount holds usage for one mounted filesystem.
+type DiskMount struct {
+	Mountpoint    string  `json:"mountpoint"`
+	Device        string  `json:"device"`
+	FSType        string  `json:"fsType"`
+	UsedBytes     int64   `json:"usedBytes"`
+	TotalBytes    int64   `json:"totalBytes"`
+	FreeBytes     int64   `json:"freeBytes"`
+	Percent       float64 `json:"percent"`
+	InodesUsed    int64   `json:"inodesUsed"`
+	InodesTotal   int64   `json:"inodesTotal"`
+	InodesPercent float64 `json:"inodesPercent"`
+}
+
+// mountPoint is one entry of the host mount table.
+type mountPoint struct {
+	path   string
+	device string
+	fsType string
+}
+
+// NetworkInterface holds throughput and error counters for one
+// non-loopback interface. Rates are per second since the previous
+// collection; saturation compares the busier direction with the link
+// speed and stays 0 when the speed is unknown.
+type NetworkInterface struct {
+	Name              string  `json:"name"`
+	RxBytes           int64   `json:"rxBytes"`
+	TxBytes           int64   `json:"txBytes"`
+	RxBytesPerSec     float64 `json:"rxBytesPerSec"`
+	TxBytesPerSec     float64 `json:"txBytesPerSec"`
+	RxErrors          int64   `json:"rxErrors"`
+	TxErrors          int64   `json:"txErrors"`
+	RxDropped         int64   `json:"rxDropped"`
+	TxDropped         int64   `json:"txDropped"`
+	SpeedMbps         int64   `json:"speedMbps,omitempty"`
+	SaturationPercent float64 `json:"saturationPercent"`
+}
+
 type networkIOSample struct {
 	rxBytes    int64
 	txBytes    int64
 	interfaces int
+	ifaces     []interfaceSample
+}
+
+// interfaceSample holds the cumulative counters of one interface.
+type interfaceSample struct {
+	name      string
+	rxBytes   int64
+	txBytes   int64
+	rxErrors  int64
+	txErrors  int64
+	rxDropped int64
+	txDropped int64
+	speedMbps int64
 }
 
 type processSample struct {
@@ -108,6 +169,7 @@ type metricCollectors struct {
 	memInfo      func(context.Context) memorySample
 	loadAvg      func(context.Context) (float64, float64, float64)
 	diskUsage    func(string) diskSample
+	mounts       func() []mountPoint
 	networkIO    func() networkIOSample
 	processInfo  func(context.Context) processSample
 	hostUptime   func() uptimeSample
@@ -132,6 +194,10 @@ type metricsCollector struct {
 	diskAt   time.Time
 	diskPath string
 
+	hasMounts bool
+	mounts    []DiskMount
+	mountsAt  time.Time
+
 	hasProcess bool
 	process    processSample
 	processAt  time.Time
@@ -143,6 +209,9 @@ type metricsCollector struct {
 	hasPressure bool
 	pressure    pressureSample
 	pressureAt  time.Time
+
+	netPrev map[string]interfaceSample
+	netAt   time.Time
 }
 
 func newMetricsCollector() *metricsCollector {
@@ -198,7 +267,9 @@ func (c *metricsCollector) Collect(ctx context.Context, diskPath string) HostMet
 	mem := c.collectors.memInfo(ctx)
 	avg1, avg5, avg15 := c.collectors.loadAvg(ctx)
 	disk := c.diskLocked(diskPath, now)
+	mounts := c.mountsLocked(now)
 	net := c.collectors.networkIO()
+	ifaces := c.networkRatesLocked(net, now)
 	processes := c.processLocked(ctx, now)
 	uptime := c.uptimeLocked(now)
 	pressure := c.pressureLocked(now)
@@ -228,6 +299,16 @@ func (c *metricsCollector) Collect(ctx context.Context, diskPath string) HostMet
 		loadPerCPU = avg1 / float64(cpuCount)
 	}
 
+	var netRxRate, netTxRate, netSaturationPercent float64
+	var netRxErrors, netTxErrors int64
+	for _, iface := range ifaces {
+		netRxRate += iface.RxBytesPerSec
+		netTxRate += iface.TxBytesPerSec
+		netRxErrors += iface.RxErrors
+		netTxErrors += iface.TxErrors
+		netSaturationPercent = max(netSaturationPercent, iface.SaturationPercent)
+	}
+
 	var memStats runtime.MemStats
 	c.collectors.readMemStats(&memStats)
 	lastGCPauseMS := 0.0
@@ -236,43 +317,50 @@ func (c *metricsCollector) Collect(ctx context.Context, diskPath string) HostMet
 	}
 
 	metrics := HostMetrics{
-		CPUPercent:        cpuPct,
-		CPUCount:          cpuCount,
-		LoadAvg1:          avg1,
-		LoadAvg5:          avg5,
-		LoadAvg15:         avg15,
-		LoadPerCPU:        loadPerCPU,
-		MemUsedBytes:      mem.usedBytes,
-		MemTotalBytes:     mem.totalBytes,
-		MemAvailableBytes: mem.availableBytes,
-		MemPercent:        memPct,
-		SwapUsedBytes:     mem.swapUsedBytes,
-		SwapTotalBytes:    mem.swapTotalBytes,
-		SwapPercent:       swapPct,
-		DiskUsedBytes:     disk.usedBytes,
-		DiskTotalBytes:    disk.totalBytes,
-		DiskFreeBytes:     disk.freeBytes,
-		DiskPercent:       diskPct,
-		DiskInodesUsed:    disk.inodesUsed,
-		DiskInodesTotal:   disk.inodesTotal,
-		DiskInodesPercent: diskInodesPct,
-		NetRxBytes:        net.rxBytes,
-		NetTxBytes:        net.txBytes,
-		NetInterfaces:     net.interfaces,
-		ProcessCount:      processes.processes,
-		ThreadCount:       processes.threads,
-		HostUptimeSec:     uptime.uptimeSec,
-		BootTime:          uptime.bootTime,
-		CPUPressureAvg10:  pressure.cpuAvg10,
-		MemPressureAvg10:  pressure.memAvg10,
-		IOPressureAvg10:   pressure.ioAvg10,
-		NumGoroutines:     c.collectors.numGoroutine(),
-		GoMemAllocMB:      float64(memStats.Alloc) / (1024 * 1024),
-		GoMemSysMB:        float64(memStats.Sys) / (1024 * 1024),
-		GoHeapObjects:     memStats.HeapObjects,
-		GoNumGC:           memStats.NumGC,
-		GoLastGCPauseMs:   lastGCPauseMS,
-		CollectedAt:       now.Format(time.RFC3339),
+		CPUPercent:           cpuPct,
+		CPUCount:             cpuCount,
+		LoadAvg1:             avg1,
+		LoadAvg5:             avg5,
+		LoadAvg15:            avg15,
+		LoadPerCPU:           loadPerCPU,
+		MemUsedBytes:         mem.usedBytes,
+		MemTotalBytes:        mem.totalBytes,
+		MemAvailableBytes:    mem.availableBytes,
+		MemPercent:           memPct,
+		SwapUsedBytes:        mem.swapUsedBytes,
+		SwapTotalBytes:       mem.swapTotalBytes,
+		SwapPercent:          swapPct,
+		DiskUsedBytes:        disk.usedBytes,
+		DiskTotalBytes:       disk.totalBytes,
+		DiskFreeBytes:        disk.freeBytes,
+		DiskPercent:          diskPct,
+		DiskInodesUsed:       disk.inodesUsed,
+		DiskInodesTotal:      disk.inodesTotal,
+		DiskInodesPercent:    diskInodesPct,
+		DiskMounts:           mounts,
+		NetRxBytes:           net.rxBytes,
+		NetTxBytes:           net.txBytes,
+		NetInterfaces:        net.interfaces,
+		NetRxBytesPerSec:     netRxRate,
+		NetTxBytesPerSec:     netTxRate,
+		NetRxErrors:          netRxErrors,
+		NetTxErrors:          netTxErrors,
+		NetSaturationPercent: netSaturationPercent,
+		NetworkInterfaces:    ifaces,
+		ProcessCount:         processes.processes,
+		ThreadCount:          processes.threads,
+		HostUptimeSec:        uptime.uptimeSec,
+		BootTime:             uptime.bootTime,
+		CPUPressureAvg10:     pressure.cpuAvg10,
+		MemPressureAvg10:     pressure.memAvg10,
+		IOPressureAvg10:      pressure.ioAvg10,
+		NumGoroutines:        c.collectors.numGoroutine(),
+		GoMemAllocMB:         float64(memStats.Alloc) / (1024 * 1024),
+		GoMemSysMB:           float64(memStats.Sys) / (1024 * 1024),
+		GoHeapObjects:        memStats.HeapObjects,
+		GoNumGC:              memStats.NumGC,
+		GoLastGCPauseMs:      lastGCPauseMS,
+		CollectedAt:          now.Format(time.RFC3339),
 	}
 
 	c.snapshot = metrics
@@ -294,6 +382,87 @@ func (c *metricsCollector) diskLocked(path string, now time.Time) diskSample {
 	return c.disk
 }
 
+// mountsLocked refreshes per-mount usage on the disk interval. Mounts that
+// cannot be stat'ed or report no capacity are left out.
+func (c *metricsCollector) mountsLocked(now time.Time) []DiskMount {
+	if c.hasMounts && reusableAt(now, c.mountsAt, c.intervals.disk) {
+		return c.mounts
+	}
+
+	points := c.collectors.mounts()
+	mounts := make([]DiskMount, 0, len(points))
+	for _, point := range points {
+		sample := c.collectors.diskUsage(point.path)
+		if sample.totalBytes <= 0 {
+			continue
+		}
+		mount := DiskMount{
+			Mountpoint:  point.path,
+			Device:      point.device,
+			FSType:      point.fsType,
+			UsedBytes:   sample.usedBytes,
+			TotalBytes:  sample.totalBytes,
+			FreeBytes:   sample.freeBytes,
+			Percent:     float64(sample.usedBytes) / float64(sample.totalBytes) * 100,
+			InodesUsed:  sample.inodesUsed,
+			InodesTotal: sample.inodesTotal,
+		}
+		if sample.inodesTotal > 0 {
+			mount.InodesPercent = float64(sample.inodesUsed) / float64(sample.inodesTotal) * 100
+		}
+		mounts = append(mounts, mount)
+	}
+	c.mounts = mounts
+	c.mountsAt = now
+	c.hasMounts = true
+	return c.mounts
+}
+
+// networkRatesLocked turns interface counters into per-second rates
+// against the previous collection. A counter that went backwards, after a
+// reset or a re-created interface, reports no rate for that sample.
+func (c *metricsCollector) networkRatesLocked(sample networkIOSample, now time.Time) []NetworkInterface {
+	if len(sample.ifaces) == 0 {
+		return nil
+	}
+	elapsed := now.Sub(c.netAt).Seconds()
+	prev := c.netPrev
+	c.netPrev = make(map[string]interfaceSample, len(sample.ifaces))
+	out := make([]NetworkInterface, 0, len(sample.ifaces))
+	for _, iface := range sample.ifaces {
+		c.netPrev[iface.name] = iface
+		item := NetworkInterface{
+			Name:      iface.name,
+			RxBytes:   iface.rxBytes,
+			TxBytes:   iface.txBytes,
+			RxErrors:  iface.rxErrors,
+			TxErrors:  iface.txErrors,
+			RxDropped: iface.rxDropped,
+			TxDropped: iface.txDropped,
+			SpeedMbps: iface.speedMbps,
+		}
+		if last, ok := prev[iface.name]; ok && elapsed > 0 {
+			item.RxBytesPerSec = counterRate(last.rxBytes, iface.rxBytes, elapsed)
+			item.TxBytesPerSec = counterRate(last.txBytes, iface.txBytes, elapsed)
+		}
+		if iface.speedMbps > 0 {
+			capacity := float64(iface.speedMbps) * 1e6 / 8
+			item.SaturationPercent = max(item.RxBytesPerSec, item.TxBytesPerSec) / capacity * 100
+		}
+		out = append(out, item)
+	}
+	c.netAt = now
+	slices.SortFunc(out, func(a, b NetworkInterface) int { return cmp.Compare(a.Name, b.Name) })
+	return out
+}
+
+func counterRate(prev, cur int64, elapsed float64) float64 {
+	if cur < prev {
+		return 0
+	}
+	return float64(cur-prev) / elapsed
+}
+
 func (c *metricsCollector) processLocked(ctx context.Context, now time.Time) processSample {
 	if c.hasProcess && reusableAt(now, c.processAt, c.intervals.process) {
 		return c.process
@@ -375,6 +544,7 @@ func defaultMetricCollectors() metricCollectors {
 		memInfo:      collectMemInfo,
 		loadAvg:      collectLoadAvg,
 		diskUsage:    collectDiskUsage,
+		mounts:       collectMounts,
 		networkIO:    collectNetworkIO,
 		processInfo:  collectProcessInfo,
 		hostUptime:   collectHostUptime,
@@ -399,6 +569,9 @@ func (c metricCollectors) withDefaults() metricCollectors {
 	if c.diskUsage == nil {
 		c.diskUsage = defaults.diskUsage
 	}
+	if c.mounts == nil {
+		c.mounts = defaults.mounts
+	}
 	if c.networkIO == nil {
 		c.networkIO = defaults.networkIO
 	}
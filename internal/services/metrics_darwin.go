@@ -149,6 +149,46 @@ func collectDiskUsage(path string) diskSample {
 	}
 }
 
+func collectMounts() []mountPoint {
+	n, err := syscall.Getfsstat(nil, mntNoWait)
+	if err != nil || n <= 0 {
+		return nil
+	}
+	stats := make([]syscall.Statfs_t, n)
+	n, err = syscall.Getfsstat(stats, mntNoWait)
+	if err != nil {
+		return nil
+	}
+	mounts := make([]mountPoint, 0, n)
+	for _, stat := range stats[:n] {
+		fsType := int8String(stat.Fstypename[:])
+		if fsType == "devfs" || fsType == "autofs" {
+			continue
+		}
+		mounts = append(mounts, mountPoint{
+			path:   int8String(stat.Mntonname[:]),
+			device: int8String(stat.Mntfromname[:]),
+			fsType: fsType,
+		})
+	}
+	return mounts
+}
+
+// mntNoWait is MNT_NOWAIT: return cached statistics without blocking on
+// unresponsive network filesystems.
+const mntNoWait = 2
+
+func int8String(raw []int8) string {
+	b := make([]byte, 0, len(raw))
+	for _, c := range raw {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	return string(b)
+}
+
 func collectNetworkIO() networkIOSample {
 	return networkIOSample{}
 }
@@ -0,0 +1,220 @@
+//go:build freebsd
+
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// cpuStateIdle is the CP_IDLE index in kern.cp_time, whose counters are
+// user, nice, sys, intr and idle ticks.
+const cpuStateIdle = 4
+
+func collectCPUPercent(ctx context.Context) float64 {
+	idle1, total1, err := readCPUTime()
+	if err != nil {
+		return -1
+	}
+
+	select {
+	case <-ctx.Done():
+		return -1
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	idle2, total2, err := readCPUTime()
+	if err != nil {
+		return -1
+	}
+
+	totalDelta := total2 - total1
+	idleDelta := idle2 - idle1
+	if totalDelta <= 0 {
+		return 0
+	}
+	return float64(totalDelta-idleDelta) / float64(totalDelta) * 100
+}
+
+// readCPUTime reads kern.cp_time and returns (idle, total) CPU ticks.
+func readCPUTime() (idle, total uint64, err error) {
+	raw, err := unix.SysctlRaw("kern.cp_time")
+	if err != nil {
+		return 0, 0, err
+	}
+	ticks := decodeSysctlLongs(raw)
+	if len(ticks) <= cpuStateIdle {
+		return 0, 0, fmt.Errorf("unexpected kern.cp_time length %d", len(raw))
+	}
+	for _, v := range ticks {
+		total += v
+	}
+	return ticks[cpuStateIdle], total, nil
+}
+
+// decodeSysctlLongs decodes an array of C longs, which are 4 or 8 bytes
+// depending on the architecture.
+func decodeSysctlLongs(raw []byte) []uint64 {
+	size := 8
+	if len(raw)%8 != 0 {
+		size = 4
+	}
+	out := make([]uint64, 0, len(raw)/size)
+	for i := 0; i+size <= len(raw); i += size {
+		if size == 8 {
+			out = append(out, binary.NativeEndian.Uint64(raw[i:]))
+		} else {
+			out = append(out, uint64(binary.NativeEndian.Uint32(raw[i:])))
+		}
+	}
+	return out
+}
+
+// sysctlUint reads an unsigned integer sysctl of either width.
+func sysctlUint(name string) (uint64, error) {
+	raw, err := unix.SysctlRaw(name)
+	if err != nil {
+		return 0, err
+	}
+	switch len(raw) {
+	case 4:
+		return uint64(binary.NativeEndian.Uint32(raw)), nil
+	case 8:
+		return binary.NativeEndian.Uint64(raw), nil
+	default:
+		return 0, fmt.Errorf("unexpected %s length %d", name, len(raw))
+	}
+}
+
+func collectMemInfo(_ context.Context) memorySample {
+	physmem, err := sysctlUint("hw.physmem")
+	if err != nil {
+		return memorySample{}
+	}
+	total := int64(physmem)
+	pageSize, err := sysctlUint("hw.pagesize")
+	if err != nil {
+		return memorySample{totalBytes: total}
+	}
+
+	// Free, inactive and laundry pages can all be reclaimed without
+	// swapping, so they count as available like MemAvailable on Linux.
+	var reclaimable uint64
+	for _, name := range []string{
+		"vm.stats.vm.v_free_count",
+		"vm.stats.vm.v_inactive_count",
+		"vm.stats.vm.v_laundry_count",
+	} {
+		pages, err := sysctlUint(name)
+		if err != nil {
+			return memorySample{totalBytes: total}
+		}
+		reclaimable += pages
+	}
+	available := min(int64(reclaimable*pageSize), total)
+
+	sample := memorySample{
+		usedBytes:      total - available,
+		totalBytes:     total,
+		availableBytes: available,
+	}
+	if swapTotal, err := sysctlUint("vm.swap_total"); err == nil {
+		sample.swapTotalBytes = int64(swapTotal)
+	}
+	return sample
+}
+
+func collectLoadAvg(_ context.Context) (avg1, avg5, avg15 float64) {
+	// struct loadavg { fixpt_t ldavg[3]; long fscale; }, with fscale
+	// aligned to the size of a long.
+	raw, err := unix.SysctlRaw("vm.loadavg")
+	if err != nil || len(raw) < 16 {
+		return -1, -1, -1
+	}
+	var fscale uint64
+	if len(raw) >= 24 {
+		fscale = binary.NativeEndian.Uint64(raw[16:])
+	} else {
+		fscale = uint64(binary.NativeEndian.Uint32(raw[12:]))
+	}
+	if fscale == 0 {
+		return -1, -1, -1
+	}
+	load := func(i int) float64 {
+		return float64(binary.NativeEndian.Uint32(raw[i*4:])) / float64(fscale)
+	}
+	return load(0), load(1), load(2)
+}
+
+func collectDiskUsage(path string) diskSample {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return diskSample{}
+	}
+	total := int64(stat.Blocks * stat.Bsize)
+	// Bavail goes negative when the reserved blocks are in use.
+	free := max(stat.Bavail, 0) * int64(stat.Bsize)
+	used := max(total-free, 0)
+	inodesTotal := int64(stat.Files)
+	inodesUsed := max(inodesTotal-stat.Ffree, 0)
+	return diskSample{
+		usedBytes:   used,
+		totalBytes:  total,
+		freeBytes:   free,
+		inodesUsed:  inodesUsed,
+		inodesTotal: inodesTotal,
+	}
+}
+
+func collectMounts() []mountPoint {
+	n, err := unix.Getfsstat(nil, unix.MNT_NOWAIT)
+	if err != nil || n <= 0 {
+		return nil
+	}
+	stats := make([]unix.Statfs_t, n)
+	n, err = unix.Getfsstat(stats, unix.MNT_NOWAIT)
+	if err != nil {
+		return nil
+	}
+	mounts := make([]mountPoint, 0, n)
+	for _, stat := range stats[:n] {
+		fsType := unix.ByteSliceToString(stat.Fstypename[:])
+		if fsType == "devfs" || fsType == "fdescfs" || fsType == "procfs" || fsType == "autofs" {
+			continue
+		}
+		mounts = append(mounts, mountPoint{
+			path:   unix.ByteSliceToString(stat.Mntonname[:]),
+			device: unix.ByteSliceToString(stat.Mntfromname[:]),
+			fsType: fsType,
+		})
+	}
+	return mounts
+}
+
+func collectNetworkIO() networkIOSample {
+	return networkIOSample{}
+}
+
+func collectProcessInfo(_ context.Context) processSample {
+	return processSample{complete: true}
+}
+
+func collectHostUptime() uptimeSample {
+	boot, err := unix.SysctlTimeval("kern.boottime")
+	if err != nil {
+		return uptimeSample{}
+	}
+	booted := time.Unix(boot.Unix())
+	return uptimeSample{
+		uptimeSec: int64(time.Since(booted).Seconds()),
+		bootTime:  booted.UTC().Format(time.RFC3339),
+	}
+}
+
+func collectPressure() pressureSample {
+	return pressureSample{cpuAvg10: -1, memAvg10: -1, ioAvg10: -1}
+}
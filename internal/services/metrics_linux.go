@@ -176,6 +176,71 @@ func collectDiskUsage(path string) diskSample {
 	}
 }
 
+// pseudoFilesystems are kernel and in-memory filesystems excluded from the
+// per-mount disk breakdown. squashfs images (snaps) are always full.
+var pseudoFilesystems = map[string]bool{
+	"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true,
+	"cgroup2": true, "configfs": true, "debugfs": true, "devpts": true,
+	"devtmpfs": true, "efivarfs": true, "fusectl": true, "hugetlbfs": true,
+	"mqueue": true, "nsfs": true, "proc": true, "pstore": true,
+	"ramfs": true, "rpc_pipefs": true, "securityfs": true, "squashfs": true,
+	"sysfs": true, "tmpfs": true, "tracefs": true,
+}
+
+func collectMounts() []mountPoint {
+	data, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return nil
+	}
+	return parseMounts(string(data))
+}
+
+// parseMounts reads /proc/self/mounts, skipping pseudo filesystems and bind
+// mounts of a block device that is already listed.
+func parseMounts(raw string) []mountPoint {
+	var mounts []mountPoint
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(raw, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || pseudoFilesystems[fields[2]] {
+			continue
+		}
+		device := unescapeMountField(fields[0])
+		if strings.HasPrefix(device, "/") {
+			if seen[device] {
+				continue
+			}
+			seen[device] = true
+		}
+		mounts = append(mounts, mountPoint{
+			path:   unescapeMountField(fields[1]),
+			device: device,
+			fsType: fields[2],
+		})
+	}
+	return mounts
+}
+
+// unescapeMountField decodes the octal escapes (\040 for space) the kernel
+// uses in mount table fields.
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, "\\") {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if code, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
+
 func collectNetworkIO() networkIOSample {
 	data, err := os.ReadFile("/proc/net/dev")
 	if err != nil {
@@ -204,10 +269,39 @@ func collectNetworkIO() networkIOSample {
 		sample.rxBytes += rx
 		sample.txBytes += tx
 		sample.interfaces++
+		// Receive: bytes packets errs drop ...; transmit starts at field 8.
+		counter := func(i int) int64 {
+			v, _ := strconv.ParseInt(fields[i], 10, 64)
+			return v
+		}
+		sample.ifaces = append(sample.ifaces, interfaceSample{
+			name:      name,
+			rxBytes:   rx,
+			txBytes:   tx,
+			rxErrors:  counter(2),
+			rxDropped: counter(3),
+			txErrors:  counter(10),
+			txDropped: counter(11),
+			speedMbps: readLinkSpeed(name),
+		})
 	}
 	return sample
 }
 
+// readLinkSpeed returns the negotiated link speed in Mbit/s, or 0 for
+// virtual interfaces and links that are down, which report -1 or fail.
+func readLinkSpeed(iface string) int64 {
+	data, err := os.ReadFile("/sys/class/net/" + iface + "/speed")
+	if err != nil {
+		return 0
+	}
+	speed, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil || speed < 0 {
+		return 0
+	}
+	return speed
+}
+
 func collectProcessInfo(ctx context.Context) processSample {
 	procRoot, err := os.OpenRoot("/proc")
 	if err != nil {
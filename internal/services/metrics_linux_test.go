@@ -0,0 +1,36 @@
+//go:build linux
+
+package services
+
+import "testing"
+
+func TestParseMounts(t *testing.T) {
+	t.Parallel()
+
+	raw := `/dev/nvme0n1p2 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+tmpfs /run tmpfs rw,nosuid,nodev 0 0
+/dev/nvme0n1p1 /boot/efi vfat rw,relatime 0 0
+/dev/nvme0n1p2 /var/lib/docker/bind ext4 rw,relatime 0 0
+/dev/sdb1 /mnt/my\040disk xfs rw,relatime 0 0
+overlay /var/lib/docker/overlay2/a/merged overlay rw 0 0
+overlay /var/lib/docker/overlay2/b/merged overlay rw 0 0
+/dev/loop3 /snap/core/1 squashfs ro 0 0
+`
+	mounts := parseMounts(raw)
+	want := []mountPoint{
+		{path: "/", device: "/dev/nvme0n1p2", fsType: "ext4"},
+		{path: "/boot/efi", device: "/dev/nvme0n1p1", fsType: "vfat"},
+		{path: "/mnt/my disk", device: "/dev/sdb1", fsType: "xfs"},
+		{path: "/var/lib/docker/overlay2/a/merged", device: "overlay", fsType: "overlay"},
+		{path: "/var/lib/docker/overlay2/b/merged", device: "overlay", fsType: "overlay"},
+	}
+	if len(mounts) != len(want) {
+		t.Fatalf("mounts = %+v, want %+v", mounts, want)
+	}
+	for i := range want {
+		if mounts[i] != want[i] {
+			t.Errorf("mounts[%d] = %+v, want %+v", i, mounts[i], want[i])
+		}
+	}
+}
@@ -1,4 +1,4 @@
-//go:build !linux && !darwin
+//go:build !linux && !darwin && !freebsd
 
 package services
 
@@ -13,7 +13,7 @@ func collectCPUPercent(_ context.Context) float64 {
 	return -1
 }
 
-func collectMemInfo() memorySample {
+func collectMemInfo(_ context.Context) memorySample {
 	// Use Go runtime as a rough approximation on unsupported platforms.
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
@@ -24,7 +24,7 @@ func collectMemInfo() memorySample {
 	}
 }
 
-func collectLoadAvg() (avg1, avg5, avg15 float64) {
+func collectLoadAvg(_ context.Context) (avg1, avg5, avg15 float64) {
 	return -1, -1, -1
 }
 
@@ -32,6 +32,10 @@ func collectDiskUsage(_ string) diskSample {
 	return diskSample{}
 }
 
+func collectMounts() []mountPoint {
+	return nil
+}
+
 func collectNetworkIO() networkIOSample {
 	return networkIOSample{}
 }
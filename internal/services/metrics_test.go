@@ -207,6 +207,79 @@ func TestMetricsCollectorKeepsPreviousProcessSampleWhenRefreshIsIncomplete(t *te
 	}
 }
 
+func TestMetricsCollectorReportsMounts(t *testing.T) {
+	t.Parallel()
+
+	collector := newMetricsCollectorWith(
+		time.Now,
+		metricsCollectionIntervals{},
+		fakeMetricCollectors(func(context.Context) processSample {
+			return processSample{complete: true}
+		}, func() float64 { return 1 }),
+	)
+
+	m := collector.Collect(context.Background(), "/")
+	if len(m.DiskMounts) != 1 {
+		t.Fatalf("DiskMounts = %+v, want only the mount with capacity", m.DiskMounts)
+	}
+	mount := m.DiskMounts[0]
+	if mount.Mountpoint != "/" || mount.Device != "/dev/sda1" || mount.FSType != "ext4" {
+		t.Fatalf("mount = %+v, want / on /dev/sda1 (ext4)", mount)
+	}
+	if mount.Percent != 50 || mount.InodesPercent != 50 {
+		t.Fatalf("percents = %f, %f; want 50, 50", mount.Percent, mount.InodesPercent)
+	}
+}
+
+func TestMetricsCollectorNetworkRates(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 13, 12, 0, 0, 0, time.UTC)
+	samples := [][]interfaceSample{
+		{
+			{name: "eth0", rxBytes: 1_000, txBytes: 500, speedMbps: 1},
+			{name: "wg0", rxBytes: 9_000},
+		},
+		{
+			{name: "eth0", rxBytes: 251_000, txBytes: 2_500, rxErrors: 3, speedMbps: 1},
+			// A re-created interface restarts its counters.
+			{name: "wg0", rxBytes: 10, txErrors: 1},
+		},
+	}
+	calls := 0
+	collectors := fakeMetricCollectors(func(context.Context) processSample {
+		return processSample{complete: true}
+	}, func() float64 { return 1 })
+	collectors.networkIO = func() networkIOSample {
+		sample := networkIOSample{ifaces: samples[calls]}
+		calls++
+		return sample
+	}
+	collector := newMetricsCollectorWith(func() time.Time { return now }, metricsCollectionIntervals{}, collectors)
+
+	first := collector.Collect(context.Background(), "/")
+	if len(first.NetworkInterfaces) != 2 || first.NetRxBytesPerSec != 0 {
+		t.Fatalf("first = %+v, want two interfaces without rates", first.NetworkInterfaces)
+	}
+
+	now = now.Add(2 * time.Second)
+	m := collector.Collect(context.Background(), "/")
+	eth0, wg0 := m.NetworkInterfaces[0], m.NetworkInterfaces[1]
+	if eth0.Name != "eth0" || eth0.RxBytesPerSec != 125_000 || eth0.TxBytesPerSec != 1_000 {
+		t.Fatalf("eth0 = %+v, want 125000 rx and 1000 tx bytes/s", eth0)
+	}
+	// 125000 bytes/s fills a 1 Mbit/s link.
+	if eth0.SaturationPercent != 100 || m.NetSaturationPercent != 100 {
+		t.Fatalf("saturation = %f, host %f; want 100", eth0.SaturationPercent, m.NetSaturationPercent)
+	}
+	if wg0.RxBytesPerSec != 0 || wg0.SaturationPercent != 0 {
+		t.Fatalf("wg0 = %+v, want no rate after a counter reset", wg0)
+	}
+	if m.NetRxBytesPerSec != 125_000 || m.NetRxErrors != 3 || m.NetTxErrors != 1 {
+		t.Fatalf("host network = %f rx/s, %d rx errors, %d tx errors", m.NetRxBytesPerSec, m.NetRxErrors, m.NetTxErrors)
+	}
+}
+
 func fakeMetricCollectors(processInfo func(context.Context) processSample, cpuPercent func() float64) metricCollectors {
 	return metricCollectors{
 		cpuPercent: func(context.Context) float64 {
@@ -218,9 +291,18 @@ func fakeMetricCollectors(processInfo func(context.Context) processSample, cpuPe
 		loadAvg: func(context.Context) (float64, float64, float64) {
 			return 1, 2, 3
 		},
-		diskUsage: func(string) diskSample {
+		diskUsage: func(path string) diskSample {
+			if path == "/empty" {
+				return diskSample{}
+			}
 			return diskSample{usedBytes: 50, totalBytes: 100, freeBytes: 50, inodesUsed: 5, inodesTotal: 10}
 		},
+		mounts: func() []mountPoint {
+			return []mountPoint{
+				{path: "/", device: "/dev/sda1", fsType: "ext4"},
+				{path: "/empty", device: "none", fsType: "fuse"},
+			}
+		},
 		networkIO: func() networkIOSample {
 			return networkIOSample{rxBytes: 100, txBytes: 200, interfaces: 1}
 		},
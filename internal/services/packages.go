@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	packagesTTL     = time.Hour
+	packagesTimeout = 5 * time.Minute
+
+	packageManagerAPT = "apt"
+	packageManagerDNF = "dnf"
+	packageManagerYum = "yum"
+
+	// detectPackageManagerScript prints the path of the first package
+	// manager found, locally or on a remote host.
+	detectPackageManagerScript = "command -v apt || command -v dnf || command -v yum"
+	// aptRebootScript prints "reboot-required" and the packages asking for
+	// it when Debian's update-notifier flag file exists.
+	aptRebootScript = "if [ -e /var/run/reboot-required ]; then echo reboot-required; cat /var/run/reboot-required.pkgs 2>/dev/null; fi; true"
+	// dnfRebootScript prints the needs-restarting -r exit status: 1 when a
+	// reboot is required, 0 when not, anything else when dnf-utils is
+	// missing.
+	dnfRebootScript = "needs-restarting -r >/dev/null 2>&1; echo $?"
+)
+
+// ErrPackagesUnsupported is returned when pending package updates cannot
+// be checked on the host platform.
+var ErrPackagesUnsupported = errors.New("package update checks are not supported on this platform")
+
+// PendingPackage is an installed package with a newer version available.
+type PendingPackage struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Security bool   `json:"security"`
+}
+
+// PackageUpdates reports the pending package updates of a host and whether
+// it needs a reboot. Checks run in the background; Checking reports whether
+// one is in progress and the other fields hold the previous results until
+// it completes.
+type PackageUpdates struct {
+	Manager        string           `json:"manager"`
+	Pending        int              `json:"pending"`
+	Security       int              `json:"security"`
+	Packages       []PendingPackage `json:"packages"`
+	RebootRequired bool             `json:"rebootRequired"`
+	RebootPackages []string         `json:"rebootPackages,omitempty"`
+	CheckedAt      string           `json:"checkedAt,omitempty"`
+	Checking       bool             `json:"checking"`
+	Error          string           `json:"error,omitempty"`
+}
+
+// PackageUpdates returns the cached package update check, starting a new
+// check when the cache is stale or refresh is set. Only Linux hosts with
+// apt, dnf or yum are checked.
+func (m *Manager) PackageUpdates(refresh bool) (PackageUpdates, error) {
+	if m.goos != "linux" {
+		return PackageUpdates{}, ErrPackagesUnsupported
+	}
+	return m.packageChecker().snapshot(refresh), nil
+}
+
+func (m *Manager) packageChecker() *packageChecker {
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+	if m.packages == nil {
+		m.packages = &packageChecker{nowFn: m.nowFn, checkFn: m.checkPackageUpdates}
+	}
+	return m.packages
+}
+
+type packageChecker struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	nowFn   func() time.Time
+	checkFn func(context.Context) (PackageUpdates, error)
+
+	result    PackageUpdates
+	checkedAt time.Time
+	checking  bool
+}
+
+func (c *packageChecker) snapshot(refresh bool) PackageUpdates {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stale := c.checkedAt.IsZero() || c.nowFn().Sub(c.checkedAt) >= packagesTTL
+	if (stale || refresh) && !c.checking {
+		c.checking = true
+		c.wg.Add(1)
+		go c.run()
+	}
+
+	out := c.result
+	out.Packages = append([]PendingPackage{}, c.result.Packages...)
+	out.Checking = c.checking
+	if !c.checkedAt.IsZero() {
+		out.CheckedAt = c.checkedAt.UTC().Format(time.RFC3339)
+	}
+	return out
+}
+
+func (c *packageChecker) run() {
+	defer c.wg.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), packagesTimeout)
+	defer cancel()
+
+	result, err := c.checkFn(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		// Keep the last good results next to the error.
+		result = c.result
+		result.Error = err.Error()
+	}
+	c.result = result
+	c.checkedAt = c.nowFn()
+	c.checking = false
+}
+
+// checkPackageUpdates lists the pending updates through the host's package
+// manager. It reads the package lists as they are; refreshing them (apt
+// update, dnf makecache) is left to the distribution's timers.
+func (m *Manager) checkPackageUpdates(ctx context.Context) (PackageUpdates, error) {
+	found, err := m.commandRunner(ctx, "sh", "-c", detectPackageManagerScript)
+	if err != nil {
+		return PackageUpdates{}, errors.New("no supported package manager (apt, dnf or yum) found")
+	}
+	manager := path.Base(strings.TrimSpace(found))
+
+	var out PackageUpdates
+	switch manager {
+	case packageManagerAPT:
+		out, err = m.checkAPTUpdates(ctx)
+	case packageManagerDNF, packageManagerYum:
+		out, err = m.checkDNFUpdates(ctx, manager)
+	default:
+		return PackageUpdates{}, fmt.Errorf("unsupported package manager: %s", manager)
+	}
+	if err != nil {
+		return PackageUpdates{}, err
+	}
+	out.Manager = manager
+	out.Pending = len(out.Packages)
+	for _, pkg := range out.Packages {
+		if pkg.Security {
+			out.Security++
+		}
+	}
+	sort.Slice(out.Packages, func(i, j int) bool { return out.Packages[i].Name < out.Packages[j].Name })
+	return out, nil
+}
+
+func (m *Manager) checkAPTUpdates(ctx context.Context) (PackageUpdates, error) {
+	list, err := m.commandRunner(ctx, "apt", "list", "--upgradable")
+	if err != nil {
+		return PackageUpdates{}, fmt.Errorf("apt list failed: %w", err)
+	}
+	reboot, err := m.commandRunner(ctx, "sh", "-c", aptRebootScript)
+	if err != nil {
+		return PackageUpdates{}, fmt.Errorf("reboot check failed: %w", err)
+	}
+	out := PackageUpdates{Packages: parseAPTUpgradable(list)}
+	lines := strings.Split(reboot, "\n")
+	if strings.TrimSpace(lines[0]) == "reboot-required" {
+		out.RebootRequired = true
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); line != "" && !slices.Contains(out.RebootPackages, line) {
+				out.RebootPackages = append(out.RebootPackages, line)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *Manager) checkDNFUpdates(ctx context.Context, manager string) (PackageUpdates, error) {
+	// check-update exits 100 when updates are pending.
+	checkUpdate := func(extra string) (string, error) {
+		script := manager + " -q check-update" + extra + "; rc=$?; [ $rc -eq 0 ] || [ $rc -eq 100 ]"
+		return m.commandRunner(ctx, "sh", "-c", script)
+	}
+	all, err := checkUpdate("")
+	if err != nil {
+		return PackageUpdates{}, fmt.Errorf("%s check-update failed: %w", manager, err)
+	}
+	security, err := checkUpdate(" --security")
+	if err != nil {
+		return PackageUpdates{}, fmt.Errorf("%s check-update --security failed: %w", manager, err)
+	}
+	reboot, err := m.commandRunner(ctx, "sh", "-c", dnfRebootScript)
+	if err != nil {
+		return PackageUpdates{}, fmt.Errorf("reboot check failed: %w", err)
+	}
+
+	securityNames := make(map[string]bool)
+	for _, pkg := range parseDNFCheckUpdate(security) {
+		securityNames[pkg.Name] = true
+	}
+	out := PackageUpdates{Packages: parseDNFCheckUpdate(all)}
+	for i := range out.Packages {
+		out.Packages[i].Security = securityNames[out.Packages[i].Name]
+	}
+	out.RebootRequired = strings.TrimSpace(reboot) == "1"
+	return out, nil
+}
+
+// parseAPTUpgradable reads `apt list --upgradable` lines such as
+// "openssl/jammy-updates,jammy-security 3.0.2-0ubuntu1.15 amd64 [upgradable
+// from: 3.0.2-0ubuntu1.14]". Updates from a -security suite are security
+// updates.
+func parseAPTUpgradable(out string) []PendingPackage {
+	packages := []PendingPackage{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.Contains(line, "[upgradable from:") {
+			continue
+		}
+		name, suites, ok := strings.Cut(fields[0], "/")
+		if !ok {
+			continue
+		}
+		pkg := PendingPackage{Name: name, Version: fields[1]}
+		for _, suite := range strings.Split(suites, ",") {
+			if strings.HasSuffix(suite, "-security") {
+				pkg.Security = true
+			}
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// parseDNFCheckUpdate reads `dnf check-update` lines such as
+// "openssl.x86_64  1:3.0.9-2.fc39  updates". The obsoleted packages listed
+// after them are skipped.
+func parseDNFCheckUpdate(out string) []PendingPackage {
+	packages := []PendingPackage{}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "Obsoleting Packages") {
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasPrefix(line, " ") {
+			continue
+		}
+		name := fields[0]
+		if dot := strings.LastIndex(name, "."); dot > 0 {
+			name = name[:dot]
+		}
+		packages = append(packages, PendingPackage{Name: name, Version: fields[1]})
+	}
+	return packages
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckPackageUpdatesAPT(t *testing.T) {
+	t.Parallel()
+
+	m := newTestManager("linux", func(_ context.Context, name string, args ...string) (string, error) {
+		switch {
+		case name == "sh" && args[1] == detectPackageManagerScript:
+			return "/usr/bin/apt", nil
+		case name == "apt":
+			return strings.Join([]string{
+				"WARNING: apt does not have a stable CLI interface. Use with caution in scripts.",
+				"",
+				"Listing...",
+				"openssl/jammy-updates,jammy-security 3.0.2-0ubuntu1.15 amd64 [upgradable from: 3.0.2-0ubuntu1.14]",
+				"curl/jammy-updates 7.81.0-1ubuntu1.16 amd64 [upgradable from: 7.81.0-1ubuntu1.15]",
+			}, "\n"), nil
+		case name == "sh" && args[1] == aptRebootScript:
+			return "reboot-required\nlinux-image-5.15.0-91-generic\nlinux-base\nlinux-base", nil
+		}
+		t.Fatalf("unexpected command %s %v", name, args)
+		return "", nil
+	})
+
+	updates, err := m.checkPackageUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("checkPackageUpdates: %v", err)
+	}
+	want := []PendingPackage{
+		{Name: "curl", Version: "7.81.0-1ubuntu1.16"},
+		{Name: "openssl", Version: "3.0.2-0ubuntu1.15", Security: true},
+	}
+	if updates.Manager != packageManagerAPT || updates.Pending != 2 || updates.Security != 1 || !slices.Equal(updates.Packages, want) {
+		t.Fatalf("updates = %+v", updates)
+	}
+	if !updates.RebootRequired || !slices.Equal(updates.RebootPackages, []string{"linux-image-5.15.0-91-generic", "linux-base"}) {
+		t.Fatalf("reboot = %t %v", updates.RebootRequired, updates.RebootPackages)
+	}
+}
+
+func TestCheckPackageUpdatesDNF(t *testing.T) {
+	t.Parallel()
+
+	m := newTestManager("linux", func(_ context.Context, name string, args ...string) (string, error) {
+		if name != "sh" {
+			t.Fatalf("unexpected command %s %v", name, args)
+		}
+		switch script := args[1]; {
+		case script == detectPackageManagerScript:
+			return "/usr/bin/dnf", nil
+		case strings.Contains(script, "--security"):
+			return "openssl.x86_64    1:3.0.9-2.fc39    updates", nil
+		case strings.Contains(script, "check-update"):
+			return strings.Join([]string{
+				"",
+				"kernel.x86_64     6.7.4-200.fc39    updates",
+				"openssl.x86_64    1:3.0.9-2.fc39    updates",
+				"Obsoleting Packages",
+				"grub2-tools.x86_64  1:2.06-110.fc39  updates",
+				"    grub2-tools.x86_64  1:2.06-100.fc39  @updates",
+			}, "\n"), nil
+		case script == dnfRebootScript:
+			return "1", nil
+		}
+		t.Fatalf("unexpected script %q", args[1])
+		return "", nil
+	})
+
+	updates, err := m.checkPackageUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("checkPackageUpdates: %v", err)
+	}
+	want := []PendingPackage{
+		{Name: "kernel", Version: "6.7.4-200.fc39"},
+		{Name: "openssl", Version: "1:3.0.9-2.fc39", Security: true},
+	}
+	if updates.Manager != packageManagerDNF || updates.Security != 1 || !slices.Equal(updates.Packages, want) || !updates.RebootRequired {
+		t.Fatalf("updates = %+v", updates)
+	}
+}
+
+func TestPackageCheckerCachesResults(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	fail := false
+	c := &packageChecker{
+		nowFn: func() time.Time { return now },
+		checkFn: func(context.Context) (PackageUpdates, error) {
+			calls++
+			if fail {
+				return PackageUpdates{}, errors.New("apt list failed: E: Could not get lock")
+			}
+			return PackageUpdates{Manager: packageManagerAPT, Pending: 1, Packages: []PendingPackage{{Name: "curl"}}}, nil
+		},
+	}
+
+	if first := c.snapshot(false); !first.Checking || first.CheckedAt != "" {
+		t.Fatalf("first snapshot = %+v, want a check in progress", first)
+	}
+	c.wg.Wait()
+	if cached := c.snapshot(false); cached.Checking || cached.Pending != 1 || calls != 1 {
+		t.Fatalf("cached snapshot = %+v after %d checks", cached, calls)
+	}
+
+	fail = true
+	c.snapshot(true)
+	c.wg.Wait()
+	if failed := c.snapshot(false); failed.Error == "" || failed.Pending != 1 || calls != 2 {
+		t.Fatalf("snapshot after a failed check = %+v after %d checks, want the previous results and the error", failed, calls)
+	}
+
+	if _, err := newTestManager("darwin", nil).PackageUpdates(false); !errors.Is(err, ErrPackagesUnsupported) {
+		t.Fatalf("PackageUpdates on darwin = %v, want ErrPackagesUnsupported", err)
+	}
+}
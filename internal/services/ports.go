@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrPortsUnsupported is returned when listening sockets cannot be scanned
+// on the host platform.
+var ErrPortsUnsupported = errors.New("listening port scan is not supported on this platform")
+
+// ListeningPort describes a socket accepting connections or datagrams on the
+// host, with the process and tracked service that own it when known.
+type ListeningPort struct {
+	Protocol string `json:"protocol"`
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+	PID      int    `json:"pid,omitempty"`
+	Process  string `json:"process,omitempty"`
+	Service  string `json:"service,omitempty"`
+	Unit     string `json:"unit,omitempty"`
+	Session  string `json:"session,omitempty"`
+	PaneID   string `json:"paneId,omitempty"`
+}
+
+// ListeningPorts scans the host for listening TCP and bound UDP sockets and
+// associates them with tracked services through their owning process.
+func (m *Manager) ListeningPorts(ctx context.Context) ([]ListeningPort, error) {
+	if m.remote {
+		return nil, ErrRemoteUnsupported
+	}
+	ports, err := scanListeningPorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make(map[string]string)
+	if m.customServices != nil {
+		custom, err := m.customServices.ListCustomServices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, cs := range custom {
+			services[cs.Unit] = cs.Name
+		}
+	}
+	for i := range ports {
+		if ports[i].PID <= 0 {
+			continue
+		}
+		unit := processUnit(ports[i].PID)
+		if unit == "" {
+			continue
+		}
+		ports[i].Unit = unit
+		if name, ok := services[unit]; ok {
+			ports[i].Service = name
+		} else if name, ok := services[strings.TrimSuffix(unit, ".service")]; ok {
+			ports[i].Service = name
+		}
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		if ports[i].Protocol != ports[j].Protocol {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		return ports[i].Address < ports[j].Address
+	})
+	return ports, nil
+}
@@ -0,0 +1,180 @@
+//go:build linux
+
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const procDir = "/proc"
+
+// procNetTables lists the socket tables scanned for listeners.
+var procNetTables = []string{"tcp", "tcp6", "udp", "udp6"}
+
+const (
+	// tcpStateListen is the TCP_LISTEN state in /proc/net/tcp.
+	tcpStateListen = "0A"
+	// udpStateUnconnected is TCP_CLOSE, used by bound, unconnected UDP sockets.
+	udpStateUnconnected = "07"
+)
+
+// procSocket is one listening entry of a /proc/net socket table.
+type procSocket struct {
+	protocol string
+	address  string
+	port     int
+	inode    string
+}
+
+func scanListeningPorts(ctx context.Context) ([]ListeningPort, error) {
+	var sockets []procSocket
+	for _, table := range procNetTables {
+		raw, err := os.ReadFile(filepath.Join(procDir, "net", table))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read /proc/net/%s: %w", table, err)
+		}
+		sockets = append(sockets, parseProcNetSockets(table, string(raw))...)
+	}
+
+	owners := socketOwners(ctx, procDir)
+	ports := make([]ListeningPort, 0, len(sockets))
+	for _, sock := range sockets {
+		port := ListeningPort{Protocol: sock.protocol, Address: sock.address, Port: sock.port}
+		if pid, ok := owners[sock.inode]; ok {
+			port.PID = pid
+			port.Process = readProcComm(procDir, pid)
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// parseProcNetSockets returns the listening TCP and bound UDP sockets of a
+// /proc/net/{tcp,tcp6,udp,udp6} table.
+func parseProcNetSockets(protocol, raw string) []procSocket {
+	wantState := tcpStateListen
+	if strings.HasPrefix(protocol, "udp") {
+		wantState = udpStateUnconnected
+	}
+	lines := strings.Split(raw, "\n")
+	sockets := make([]procSocket, 0, len(lines))
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[3] != wantState {
+			continue
+		}
+		address, port, ok := parseProcNetAddr(fields[1])
+		if !ok {
+			continue
+		}
+		sockets = append(sockets, procSocket{protocol: protocol, address: address, port: port, inode: fields[9]})
+	}
+	return sockets
+}
+
+// parseProcNetAddr decodes "0100007F:1F90"-style addresses. The address is
+// stored as 32-bit words in host (little-endian) byte order.
+func parseProcNetAddr(raw string) (string, int, bool) {
+	hexAddr, hexPort, ok := strings.Cut(raw, ":")
+	if !ok {
+		return "", 0, false
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, false
+	}
+	addr, err := hex.DecodeString(hexAddr)
+	if err != nil || (len(addr) != net.IPv4len && len(addr) != net.IPv6len) {
+		return "", 0, false
+	}
+	for word := 0; word < len(addr); word += 4 {
+		addr[word], addr[word+3] = addr[word+3], addr[word]
+		addr[word+1], addr[word+2] = addr[word+2], addr[word+1]
+	}
+	return net.IP(addr).String(), int(port), true
+}
+
+// socketOwners maps socket inodes to the first process holding them. Processes
+// whose descriptors cannot be read (other users without privileges) are
+// skipped.
+func socketOwners(ctx context.Context, root string) map[string]int {
+	owners := make(map[string]int)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return owners
+	}
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return owners
+		}
+		if !entry.IsDir() || !isNumeric(entry.Name()) {
+			continue
+		}
+		pid, _ := strconv.Atoi(entry.Name())
+		fdDir := filepath.Join(root, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			inode, ok := strings.CutPrefix(target, "socket:[")
+			if !ok {
+				continue
+			}
+			inode = strings.TrimSuffix(inode, "]")
+			if _, seen := owners[inode]; !seen {
+				owners[inode] = pid
+			}
+		}
+	}
+	return owners
+}
+
+func readProcComm(root string, pid int) string {
+	raw, err := os.ReadFile(filepath.Join(root, strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+// processUnit returns the systemd unit owning pid from its cgroup path, or
+// "" when the process is not part of a unit.
+func processUnit(pid int) string {
+	raw, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	return cgroupUnit(string(raw))
+}
+
+// cgroupUnit extracts the innermost .service unit from /proc/<pid>/cgroup.
+func cgroupUnit(raw string) string {
+	for _, line := range strings.Split(strings.TrimSpace(raw), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		segments := strings.Split(parts[2], "/")
+		for i := len(segments) - 1; i >= 0; i-- {
+			if strings.HasSuffix(segments[i], ".service") {
+				return segments[i]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+//go:build linux
+
+package services
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseProcNetSockets(t *testing.T) {
+	t.Parallel()
+
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 4242 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:C350 01 00000000:00000000 00:00000000 00000000  1000        0 4343 1 0000000000000000 20 4 30 10 -1
+   2: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 17 1 0000000000000000 100 0 0 10 0
+`
+	sockets := parseProcNetSockets("tcp", tcp)
+	if len(sockets) != 2 {
+		t.Fatalf("sockets = %+v, want 2 listeners", sockets)
+	}
+	if sockets[0].address != "127.0.0.1" || sockets[0].port != 8080 || sockets[0].inode != "4242" {
+		t.Fatalf("first socket = %+v, want 127.0.0.1:8080 inode 4242", sockets[0])
+	}
+	if sockets[1].address != "0.0.0.0" || sockets[1].port != 22 {
+		t.Fatalf("second socket = %+v, want 0.0.0.0:22", sockets[1])
+	}
+
+	udp6 := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  10: 00000000000000000000000001000000:0035 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 555 2 0000000000000000 0
+`
+	sockets = parseProcNetSockets("udp6", udp6)
+	if len(sockets) != 1 || sockets[0].address != "::1" || sockets[0].port != 53 || sockets[0].protocol != "udp6" {
+		t.Fatalf("udp6 sockets = %+v, want [::1]:53", sockets)
+	}
+}
+
+func TestSocketOwners(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	fdDir := filepath.Join(root, "321", "fd")
+	if err := os.MkdirAll(fdDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("socket:[4242]", filepath.Join(fdDir, "3")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/dev/null", filepath.Join(fdDir, "0")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "321", "comm"), []byte("nginx\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "self"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	owners := socketOwners(context.Background(), root)
+	if len(owners) != 1 || owners["4242"] != 321 {
+		t.Fatalf("owners = %v, want 4242 -> 321", owners)
+	}
+	if got := readProcComm(root, 321); got != "nginx" {
+		t.Fatalf("readProcComm = %q, want nginx", got)
+	}
+}
+
+func TestCgroupUnit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"0::/system.slice/nginx.service\n", "nginx.service"},
+		{"0::/user.slice/user-1000.slice/user@1000.service/app.slice/api.service\n", "api.service"},
+		{"12:pids:/system.slice/docker-abc.scope\n", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := cgroupUnit(tt.raw); got != tt.want {
+			t.Errorf("cgroupUnit(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestScanListeningPortsFindsOwnListener(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	want := ln.Addr().(*net.TCPAddr).Port
+
+	ports, err := scanListeningPorts(context.Background())
+	if err != nil {
+		t.Fatalf("scanListeningPorts: %v", err)
+	}
+	for _, port := range ports {
+		if port.Protocol == "tcp" && port.Port == want {
+			if port.PID != os.Getpid() {
+				t.Fatalf("listener pid = %d, want %d", port.PID, os.Getpid())
+			}
+			return
+		}
+	}
+	t.Fatalf("listener on port %d not found in %d ports", want, len(ports))
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package services
+
+import "context"
+
+func scanListeningPorts(_ context.Context) ([]ListeningPort, error) {
+	return nil, ErrPortsUnsupported
+}
+
+func processUnit(_ int) string {
+	return ""
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	managerRCD = "rcd"
+
+	// rcdLogFile is where syslogd writes daemon messages on a stock FreeBSD
+	// install.
+	rcdLogFile = "/var/log/messages"
+	// rcdLogTimeLayout is the BSD syslog timestamp, which carries no year.
+	rcdLogTimeLayout = "Jan _2 15:04:05"
+)
+
+// rcdEnabledState reads the enabled state from `service NAME rcvar`, which
+// prints the script's rcvar assignment, e.g. nginx_enable="YES". Scripts
+// without an rcvar always run at boot.
+func rcdEnabledState(rcvar string) string {
+	for _, line := range strings.Split(rcvar, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		_, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(strings.Trim(value, `"'`)) {
+		case "YES", "TRUE", "ON", "1":
+			return "enabled"
+		default:
+			return "disabled"
+		}
+	}
+	return "static"
+}
+
+// rcdStatus runs the script's status command. The one- prefix checks the
+// process even when the script is not enabled in rc.conf; a non-zero exit
+// means it is not running.
+func (m *Manager) rcdStatus(ctx context.Context, name string) (string, string) {
+	out, err := m.commandRunner(ctx, "service", name, "onestatus")
+	if err != nil {
+		return stateInactive, strings.TrimPrefix(err.Error(), "service "+name+" onestatus failed: ")
+	}
+	return stateActive, out
+}
+
+func (m *Manager) probeRCDService(ctx context.Context, svc *ServiceStatus) {
+	if !IsValidUnit(svc.Unit) {
+		svc.Exists = false
+		svc.ActiveState = stateUnknown
+		svc.EnabledState = stateUnknown
+		return
+	}
+	// rcvar fails for names without an rc.d script.
+	rcvar, err := m.commandRunner(ctx, "service", svc.Unit, "rcvar")
+	if err != nil {
+		svc.Exists = false
+		svc.ActiveState = stateUnknown
+		svc.EnabledState = stateUnknown
+		return
+	}
+	svc.Exists = true
+	svc.EnabledState = rcdEnabledState(rcvar)
+	svc.ActiveState, _ = m.rcdStatus(ctx, svc.Unit)
+}
+
+// actRCD drives an rc.d script. Start, stop and restart use the one-
+// prefixed commands so they work whether or not the script is enabled,
+// like systemctl does; enable and disable edit rc.conf through service(8).
+func (m *Manager) actRCD(ctx context.Context, name, action string) error {
+	if !IsValidUnit(name) {
+		return ErrInvalidUnit
+	}
+	var command string
+	switch action {
+	case ActionStart, ActionStop, ActionRestart:
+		command = "one" + action
+	case ActionEnable, ActionDisable:
+		command = action
+	default:
+		return ErrInvalidAction
+	}
+	if _, err := m.commandRunner(ctx, "service", name, command); err != nil {
+		return fmt.Errorf("rc.d action failed: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) inspectRCD(ctx context.Context, name string) (map[string]string, string, error) {
+	if !IsValidUnit(name) {
+		return nil, "", ErrInvalidUnit
+	}
+	rcvar, err := m.commandRunner(ctx, "service", name, "rcvar")
+	if err != nil {
+		return nil, "", fmt.Errorf("rc.d inspect failed: %w", err)
+	}
+	active, status := m.rcdStatus(ctx, name)
+	props := map[string]string{
+		"Name":        name,
+		"ActiveState": active,
+		"Enabled":     rcdEnabledState(rcvar),
+		"Status":      status,
+	}
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s\n\n%s\n", strings.TrimSpace(rcvar), status)
+	return props, out.String(), nil
+}
+
+func buildRCDSummary(props map[string]string) string {
+	return fmt.Sprintf("enabled=%s active=%s", props["Enabled"], props["ActiveState"])
+}
+
+// discoverRCDServices lists the rc.d scripts of the base system and of
+// packages. Only enabled scripts have their status checked: most of the
+// rest are one-shot boot tasks with nothing to report.
+func (m *Manager) discoverRCDServices(ctx context.Context) ([]AvailableService, error) {
+	all, err := m.commandRunner(ctx, "service", "-l")
+	if err != nil {
+		return nil, err
+	}
+	enabledPaths, err := m.commandRunner(ctx, "service", "-e")
+	if err != nil {
+		return nil, err
+	}
+	enabled := make(map[string]bool)
+	for _, line := range strings.Split(enabledPaths, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			enabled[path.Base(line)] = true
+		}
+	}
+
+	var out []AvailableService
+	for _, line := range strings.Split(all, "\n") {
+		name := strings.TrimSpace(line)
+		if !IsValidUnit(name) {
+			continue
+		}
+		svc := AvailableService{
+			Unit:         name,
+			UnitType:     unitTypeService,
+			Description:  name,
+			ActiveState:  stateInactive,
+			EnabledState: "disabled",
+			Manager:      managerRCD,
+			Scope:        scopeSystem,
+		}
+		if enabled[name] {
+			svc.EnabledState = "enabled"
+			svc.ActiveState, _ = m.rcdStatus(ctx, name)
+		}
+		out = append(out, svc)
+	}
+	slices.SortFunc(out, func(a, b AvailableService) int { return cmp.Compare(a.Unit, b.Unit) })
+	return out, nil
+}
+
+// logsRCD reads the syslog lines a daemon wrote to /var/log/messages under
+// its own tag. Syslog lines carry no priority, so a priority filter is
+// rejected.
+func (m *Manager) logsRCD(ctx context.Context, name string, query LogQuery) (string, error) {
+	if !IsValidUnit(name) {
+		return "", ErrInvalidUnit
+	}
+	if query.Priority != "" {
+		return "", ErrPriorityUnsupported
+	}
+	out, err := m.commandRunner(ctx, "grep", "-F", "-h", "-e", " "+name+"[", "-e", " "+name+": ", rcdLogFile)
+	if err != nil {
+		// grep exits 1 when nothing matched.
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return "", fmt.Errorf("read %s failed: %w", rcdLogFile, err)
+		}
+		out = ""
+	}
+
+	now := m.nowFn()
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		stamp, ok := parseRCDLogLine(line, name, now)
+		if !ok {
+			continue
+		}
+		if !query.Since.IsZero() && stamp.Before(query.Since) {
+			continue
+		}
+		if !query.Until.IsZero() && stamp.After(query.Until) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return tailLogLines(grepLogLines(strings.Join(lines, "\n"), query.Grep), query.Lines), nil
+}
+
+// parseRCDLogLine checks that a syslog line ("Oct 17 10:00:05 host
+// nginx[812]: message") was written under the tag name and returns its
+// time. The year is taken from now, stepping back one for dates that would
+// otherwise lie in the future around New Year.
+func parseRCDLogLine(line, name string, now time.Time) (time.Time, bool) {
+	if len(line) <= len(rcdLogTimeLayout) {
+		return time.Time{}, false
+	}
+	stamp, err := time.ParseInLocation(rcdLogTimeLayout, line[:len(rcdLogTimeLayout)], now.Location())
+	if err != nil {
+		return time.Time{}, false
+	}
+	fields := strings.Fields(line[len(rcdLogTimeLayout):])
+	if len(fields) < 2 {
+		return time.Time{}, false
+	}
+	tag, _, _ := strings.Cut(strings.TrimSuffix(fields[1], ":"), "[")
+	if tag != name {
+		return time.Time{}, false
+	}
+	stamp = stamp.AddDate(now.Year(), 0, 0)
+	if stamp.After(now.Add(24 * time.Hour)) {
+		stamp = stamp.AddDate(-1, 0, 0)
+	}
+	return stamp, true
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+// fakeRCD answers service(8) calls for a set of rc.d scripts, keyed by
+// name to their rcvar value ("" for none) and whether they run.
+type fakeRCD struct {
+	scripts map[string]struct {
+		enable  string
+		running bool
+	}
+	calls []string
+}
+
+func (f *fakeRCD) run(_ context.Context, name string, args ...string) (string, error) {
+	f.calls = append(f.calls, name+" "+strings.Join(args, " "))
+	if name != "service" {
+		return "", fmt.Errorf("unexpected command %s", name)
+	}
+	switch args[0] {
+	case "-l":
+		names := make([]string, 0, len(f.scripts))
+		for script := range f.scripts {
+			names = append(names, script)
+		}
+		slices.Sort(names)
+		return strings.Join(names, "\n"), nil
+	case "-e":
+		var paths []string
+		for script, info := range f.scripts {
+			if info.enable == "YES" {
+				paths = append(paths, "/usr/local/etc/rc.d/"+script)
+			}
+		}
+		return strings.Join(paths, "\n"), nil
+	}
+	script, ok := f.scripts[args[0]]
+	if !ok {
+		return "", fmt.Errorf("service %s does not exist in /etc/rc.d or the local startup directories", args[0])
+	}
+	switch args[1] {
+	case "rcvar":
+		if script.enable == "" {
+			return "# " + args[0], nil
+		}
+		return fmt.Sprintf("# %s\n#\n%s_enable=%q\n#   (default: \"\")", args[0], args[0], script.enable), nil
+	case "onestatus":
+		if !script.running {
+			return "", errors.New(args[0] + " is not running.")
+		}
+		return args[0] + " is running as pid 812.", nil
+	}
+	return "", nil
+}
+
+func TestRCDServices(t *testing.T) {
+	t.Parallel()
+
+	rcd := &fakeRCD{scripts: map[string]struct {
+		enable  string
+		running bool
+	}{
+		"nginx":      {enable: "YES", running: true},
+		"postgresql": {enable: "NO"},
+		"cleanvar":   {},
+	}}
+	m := newTestManager("freebsd", rcd.run)
+	m.customServices = &stubCustomServicesRepo{services: []store.CustomService{
+		{Name: "web", Manager: managerRCD, Unit: "nginx", Scope: scopeSystem},
+		{Name: "gone", Manager: managerRCD, Unit: "apache24", Scope: scopeSystem},
+	}}
+	ctx := context.Background()
+
+	services, err := m.ListServices(ctx)
+	if err != nil {
+		t.Fatalf("ListServices: %v", err)
+	}
+	if len(services) != 2 || !services[0].Exists || services[0].ActiveState != stateActive || services[0].EnabledState != "enabled" {
+		t.Fatalf("web = %+v, want an active, enabled nginx", services)
+	}
+	if services[1].Exists {
+		t.Fatalf("gone = %+v, want missing", services[1])
+	}
+
+	rcd.calls = nil
+	if _, err := m.Act(ctx, "web", ActionRestart); err != nil {
+		t.Fatalf("Act(restart): %v", err)
+	}
+	if err := m.ActByUnit(ctx, "postgresql", scopeSystem, managerRCD, ActionEnable); err != nil {
+		t.Fatalf("ActByUnit(enable): %v", err)
+	}
+	for _, want := range []string{"service nginx onerestart", "service postgresql enable"} {
+		if !slices.Contains(rcd.calls, want) {
+			t.Fatalf("calls = %v, want %q", rcd.calls, want)
+		}
+	}
+
+	inspect, err := m.InspectByUnit(ctx, "postgresql", scopeSystem, managerRCD)
+	if err != nil {
+		t.Fatalf("InspectByUnit: %v", err)
+	}
+	if inspect.Summary != "enabled=disabled active=inactive" || inspect.Properties["Status"] != "postgresql is not running." {
+		t.Fatalf("inspect = %+v", inspect)
+	}
+
+	available, err := m.DiscoverServices(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverServices: %v", err)
+	}
+	if len(available) != 2 || available[0].Unit != "cleanvar" || available[1].Unit != "postgresql" || available[1].Scope != scopeSystem {
+		t.Fatalf("available = %+v, want cleanvar and postgresql", available)
+	}
+
+	browsed, err := m.BrowseServices(ctx)
+	if err != nil {
+		t.Fatalf("BrowseServices: %v", err)
+	}
+	if len(browsed) != 4 || !browsed[1].Tracked || browsed[1].TrackedName != "web" || browsed[1].ActiveState != stateActive {
+		t.Fatalf("browsed = %+v, want the tracked, running nginx", browsed)
+	}
+}
+
+func TestRCDEnabledState(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"# sshd\n#\nsshd_enable=\"YES\"\n#   (default: \"\")": "enabled",
+		"# ntpd\n#\nntpd_enable=\"NO\"":                       "disabled",
+		"# cleanvar":                                          "static",
+	}
+	for rcvar, want := range tests {
+		if got := rcdEnabledState(rcvar); got != want {
+			t.Errorf("rcdEnabledState(%q) = %q, want %q", rcvar, got, want)
+		}
+	}
+}
+
+func TestRCDLogs(t *testing.T) {
+	t.Parallel()
+
+	m := newTestManager("freebsd", func(_ context.Context, name string, args ...string) (string, error) {
+		if name != "grep" || args[len(args)-1] != rcdLogFile {
+			t.Fatalf("command = %s %v", name, args)
+		}
+		return strings.Join([]string{
+			"Dec 31 23:59:00 jailhost nginx[812]: last year",
+			"Feb 15 10:00:00 jailhost nginx[812]: too early",
+			"Feb 15 11:30:00 jailhost nginx-exporter[90]: other tag",
+			"Feb 15 11:31:00 jailhost nginx[812]: worker exited on signal 11",
+			"Feb 15 11:32:00 jailhost nginx: reloaded",
+		}, "\n"), nil
+	})
+	since := time.Date(2026, 2, 15, 11, 0, 0, 0, time.UTC)
+	out, err := m.LogsByUnit(context.Background(), "nginx", scopeSystem, managerRCD, LogQuery{Lines: 10, Since: since})
+	if err != nil {
+		t.Fatalf("LogsByUnit: %v", err)
+	}
+	want := "Feb 15 11:31:00 jailhost nginx[812]: worker exited on signal 11\nFeb 15 11:32:00 jailhost nginx: reloaded"
+	if out != want {
+		t.Fatalf("logs =\n%s\nwant\n%s", out, want)
+	}
+
+	if _, err := m.LogsByUnit(context.Background(), "nginx", scopeSystem, managerRCD, LogQuery{Priority: "err"}); !errors.Is(err, ErrPriorityUnsupported) {
+		t.Fatalf("priority error = %v, want ErrPriorityUnsupported", err)
+	}
+
+	// grep exits 1 when the daemon never logged.
+	m.commandRunner = func(context.Context, string, ...string) (string, error) {
+		return "", fmt.Errorf("grep failed: %w", exec.Command("sh", "-c", "exit 1").Run())
+	}
+	if out, err := m.LogsByUnit(context.Background(), "nginx", scopeSystem, managerRCD, LogQuery{}); err != nil || out != "" {
+		t.Fatalf("LogsByUnit without matches = %q, %v", out, err)
+	}
+}
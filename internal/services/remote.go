@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRemoteUnsupported is returned by operations that read the local
+// machine directly when the manager drives a remote host.
+var ErrRemoteUnsupported = errors.New("operation is not available on remote hosts")
+
+// NewRemoteManager returns a manager that runs systemctl and journalctl on a
+// remote Linux host through run, e.g. over SSH. It tracks no custom
+// services and does not use docker; operations that inspect the local
+// machine (metrics, disk usage, ports, log streaming) are unavailable.
+// user is the remote login user and decides whether user units are queried.
+func NewRemoteManager(hostname, user string, run func(ctx context.Context, name string, args ...string) (string, error)) *Manager {
+	m := &Manager{
+		startedAt:     time.Now().UTC(),
+		nowFn:         time.Now,
+		hostname:      func() (string, error) { return hostname, nil },
+		goos:          "linux",
+		commandRunner: run,
+		remote:        true,
+	}
+	if user == "root" {
+		// root has no user manager to query, as for a root daemon.
+		m.uidFn = func() int { return 0 }
+	}
+	return m
+}
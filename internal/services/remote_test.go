@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRemoteManagerRunsThroughRunner(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	m := NewRemoteManager("db-01", "deploy", func(_ context.Context, name string, args ...string) (string, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		return "", nil
+	})
+
+	if err := m.ActByUnit(context.Background(), "nginx.service", scopeSystem, managerSystemd, ActionRestart); err != nil {
+		t.Fatalf("ActByUnit() error = %v", err)
+	}
+	if !slices.Contains(calls, "systemctl restart nginx.service") {
+		t.Fatalf("calls = %q, want systemctl restart", calls)
+	}
+
+	overview, err := m.Overview(context.Background())
+	if err != nil {
+		t.Fatalf("Overview() error = %v", err)
+	}
+	if overview.Host.Hostname != "db-01" || overview.Host.OS != "linux" || overview.Sentinel.PID != 0 {
+		t.Fatalf("Overview() = %+v, want the remote host without local process details", overview)
+	}
+	if scopes := m.systemdScopes(); !slices.Equal(scopes, []string{scopeUser, scopeSystem}) {
+		t.Fatalf("systemdScopes() = %v", scopes)
+	}
+	if scopes := NewRemoteManager("db-01", "root", nil).systemdScopes(); !slices.Equal(scopes, []string{scopeSystem}) {
+		t.Fatalf("root systemdScopes() = %v, want system only", scopes)
+	}
+}
+
+func TestRemoteManagerRejectsLocalOnlyOperations(t *testing.T) {
+	t.Parallel()
+
+	m := NewRemoteManager("db-01", "deploy", func(context.Context, string, ...string) (string, error) {
+		t.Error("runner called for a local-only operation")
+		return "", nil
+	})
+	ctx := context.Background()
+	if _, err := m.ListeningPorts(ctx); !errors.Is(err, ErrRemoteUnsupported) {
+		t.Fatalf("ListeningPorts() error = %v", err)
+	}
+	if _, err := m.StreamLogsByUnit(ctx, "nginx.service", scopeSystem, managerSystemd, ""); !errors.Is(err, ErrRemoteUnsupported) {
+		t.Fatalf("StreamLogsByUnit() error = %v", err)
+	}
+	if usage := m.DiskUsage(ctx, true); len(usage.Mounts) != 0 || usage.Scanning {
+		t.Fatalf("DiskUsage() = %+v, want empty", usage)
+	}
+}
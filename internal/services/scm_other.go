@@ -0,0 +1,9 @@
+//go:build !windows
+
+package services
+
+// newServiceControlManager returns nil: only Windows has a service
+// control manager.
+func newServiceControlManager() serviceControlManager {
+	return nil
+}
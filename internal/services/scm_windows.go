@@ -0,0 +1,208 @@
+//go:build windows
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// scmPollInterval is how often a stop waits for the service to settle.
+const scmPollInterval = 250 * time.Millisecond
+
+// windowsSCM drives the local service control manager. Every call opens
+// its own handles with only the access rights it needs, so listing and
+// querying work without administrator rights.
+type windowsSCM struct{}
+
+func newServiceControlManager() serviceControlManager {
+	return windowsSCM{}
+}
+
+func connectSCM(access uint32) (*mgr.Mgr, error) {
+	h, err := windows.OpenSCManager(nil, nil, access)
+	if err != nil {
+		return nil, fmt.Errorf("open service control manager: %w", err)
+	}
+	return &mgr.Mgr{Handle: h}, nil
+}
+
+func openSCMService(name string, access uint32) (*mgr.Service, func(), error) {
+	m, err := connectSCM(windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return nil, nil, err
+	}
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		_ = m.Disconnect()
+		return nil, nil, err
+	}
+	h, err := windows.OpenService(m.Handle, namePtr, access)
+	if err != nil {
+		_ = m.Disconnect()
+		if errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+			return nil, nil, ErrServiceNotFound
+		}
+		return nil, nil, fmt.Errorf("open service %s: %w", name, err)
+	}
+	s := &mgr.Service{Name: name, Handle: h}
+	return s, func() {
+		_ = s.Close()
+		_ = m.Disconnect()
+	}, nil
+}
+
+func (windowsSCM) ListServices(ctx context.Context) ([]windowsService, error) {
+	m, err := connectSCM(windows.SC_MANAGER_CONNECT | windows.SC_MANAGER_ENUMERATE_SERVICE)
+	if err != nil {
+		return nil, err
+	}
+	names, err := m.ListServices()
+	_ = m.Disconnect()
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+	out := make([]windowsService, 0, len(names))
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		info, err := queryWindowsService(name)
+		if err != nil {
+			// Services can vanish or deny queries between list and open.
+			continue
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+func (windowsSCM) QueryService(_ context.Context, name string) (windowsService, error) {
+	return queryWindowsService(name)
+}
+
+func queryWindowsService(name string) (windowsService, error) {
+	s, release, err := openSCMService(name, windows.SERVICE_QUERY_STATUS|windows.SERVICE_QUERY_CONFIG)
+	if err != nil {
+		return windowsService{}, err
+	}
+	defer release()
+	status, err := s.Query()
+	if err != nil {
+		return windowsService{}, fmt.Errorf("query service %s: %w", name, err)
+	}
+	cfg, err := s.Config()
+	if err != nil {
+		return windowsService{}, fmt.Errorf("query service config %s: %w", name, err)
+	}
+	return windowsService{
+		Name:        name,
+		DisplayName: cfg.DisplayName,
+		Description: cfg.Description,
+		State:       scmStateName(status.State),
+		StartType:   scmStartTypeName(cfg.StartType, cfg.DelayedAutoStart),
+		PID:         status.ProcessId,
+		BinaryPath:  cfg.BinaryPathName,
+		Account:     cfg.ServiceStartName,
+	}, nil
+}
+
+func (windowsSCM) StartService(_ context.Context, name string) error {
+	s, release, err := openSCMService(name, windows.SERVICE_START)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if err := s.Start(); err != nil && !errors.Is(err, windows.ERROR_SERVICE_ALREADY_RUNNING) {
+		return fmt.Errorf("start service %s: %w", name, err)
+	}
+	return nil
+}
+
+// StopService asks the service to stop and waits until it has, so a
+// restart can start it again right after.
+func (windowsSCM) StopService(ctx context.Context, name string) error {
+	s, release, err := openSCMService(name, windows.SERVICE_STOP|windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return err
+	}
+	defer release()
+	status, err := s.Control(svc.Stop)
+	if err != nil && !errors.Is(err, windows.ERROR_SERVICE_NOT_ACTIVE) {
+		return fmt.Errorf("stop service %s: %w", name, err)
+	}
+	for err == nil && status.State != svc.Stopped {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stop service %s: %w", name, ctx.Err())
+		case <-time.After(scmPollInterval):
+		}
+		if status, err = s.Query(); err != nil {
+			return fmt.Errorf("query service %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (windowsSCM) SetStartType(_ context.Context, name, startType string) error {
+	s, release, err := openSCMService(name, windows.SERVICE_CHANGE_CONFIG)
+	if err != nil {
+		return err
+	}
+	defer release()
+	var value uint32
+	switch startType {
+	case scmStartAuto:
+		value = windows.SERVICE_AUTO_START
+	case scmStartDisabled:
+		value = windows.SERVICE_DISABLED
+	default:
+		return ErrInvalidAction
+	}
+	if err := windows.ChangeServiceConfig(s.Handle, windows.SERVICE_NO_CHANGE, value, windows.SERVICE_NO_CHANGE,
+		nil, nil, nil, nil, nil, nil, nil); err != nil {
+		return fmt.Errorf("configure service %s: %w", name, err)
+	}
+	return nil
+}
+
+func scmStateName(state svc.State) string {
+	switch state {
+	case svc.Running:
+		return scmStateRunning
+	case svc.Stopped:
+		return scmStateStopped
+	case svc.StartPending:
+		return scmStateStartPending
+	case svc.StopPending:
+		return scmStateStopPending
+	case svc.Paused, svc.PausePending, svc.ContinuePending:
+		return scmStatePaused
+	default:
+		return stateUnknown
+	}
+}
+
+func scmStartTypeName(startType uint32, delayed bool) string {
+	switch startType {
+	case windows.SERVICE_AUTO_START:
+		if delayed {
+			return scmStartDelayedAuto
+		}
+		return scmStartAuto
+	case windows.SERVICE_BOOT_START, windows.SERVICE_SYSTEM_START:
+		return scmStartAuto
+	case windows.SERVICE_DEMAND_START:
+		return scmStartManual
+	case windows.SERVICE_DISABLED:
+		return scmStartDisabled
+	default:
+		return stateUnknown
+	}
+}
@@ -5,15 +5,52 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 )
 
-// ErrStreamingUnsupported is returned when log streaming is not available
-// for the service manager in use (e.g. launchd).
-var ErrStreamingUnsupported = errors.New("log streaming is not supported for this service manager")
+var (
+	// ErrStreamingUnsupported is returned when log streaming is not available
+	// for the service manager in use (e.g. launchd).
+	ErrStreamingUnsupported = errors.New("log streaming is not supported for this service manager")
+	// ErrInvalidLogPriority is returned for an unknown syslog priority name.
+	ErrInvalidLogPriority = errors.New("invalid log priority")
+	// ErrPriorityUnsupported is returned when a priority filter is requested
+	// for logs without severity metadata (docker).
+	ErrPriorityUnsupported = errors.New("priority filtering is only supported for journald logs")
+)
+
+var (
+	journalctlCommandContext = exec.CommandContext // var enables test injection
+	dockerCommandContext     = exec.CommandContext // var enables test injection
+)
+
+// logPriorities maps accepted priority names to journalctl's names.
+var logPriorities = map[string]string{
+	"emerg": "emerg", "0": "emerg",
+	"alert": "alert", "1": "alert",
+	"crit": "crit", "2": "crit",
+	"err": "err", "error": "err", "3": "err",
+	"warning": "warning", "warn": "warning", "4": "warning",
+	"notice": "notice", "5": "notice",
+	"info": "info", "6": "info",
+	"debug": "debug", "7": "debug",
+}
 
-var journalctlCommandContext = exec.CommandContext // var enables test injection
+// normalizeLogPriority maps a syslog priority name or number to the name
+// journalctl expects. An empty priority disables filtering.
+func normalizeLogPriority(raw string) (string, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return "", nil
+	}
+	priority, ok := logPriorities[raw]
+	if !ok {
+		return "", ErrInvalidLogPriority
+	}
+	return priority, nil
+}
 
 // logStreamCloser wraps a journalctl process pipe so callers can read
 // streaming log output and cleanly tear down the child process.
@@ -36,8 +73,12 @@ func (lsc *logStreamCloser) Close() error {
 }
 
 // StreamLogs starts a streaming log tail for the named managed service.
-// Only systemd is supported; launchd returns ErrStreamingUnsupported.
-func (m *Manager) StreamLogs(ctx context.Context, name string) (io.ReadCloser, error) {
+// priority keeps journald entries at that syslog level or more severe.
+// systemd and docker are supported; launchd returns ErrStreamingUnsupported.
+func (m *Manager) StreamLogs(ctx context.Context, name, priority string) (io.ReadCloser, error) {
+	if m.remote {
+		return nil, ErrRemoteUnsupported
+	}
 	serviceName, ok := normalizeServiceName(name)
 	if !ok {
 		return nil, ErrServiceNotFound
@@ -52,34 +93,49 @@ func (m *Manager) StreamLogs(ctx context.Context, name string) (io.ReadCloser, e
 		return nil, ErrServiceNotFound
 	}
 
-	if target.Manager != managerSystemd {
-		return nil, ErrStreamingUnsupported
-	}
-	return streamLogsSystemd(ctx, target)
+	return streamLogs(ctx, target, priority)
 }
 
 // StreamLogsByUnit starts a streaming log tail for a service identified by
 // unit/scope/manager directly, without requiring the service to be tracked.
-// Only systemd is supported; launchd returns ErrStreamingUnsupported.
-func (m *Manager) StreamLogsByUnit(ctx context.Context, unit, scope, manager string) (io.ReadCloser, error) {
+// It supports the same managers and priority filter as StreamLogs.
+func (m *Manager) StreamLogsByUnit(ctx context.Context, unit, scope, manager, priority string) (io.ReadCloser, error) {
+	if m.remote {
+		return nil, ErrRemoteUnsupported
+	}
 	if !IsValidUnit(unit) {
 		return nil, ErrInvalidUnit
 	}
-	if manager != managerSystemd {
-		return nil, ErrStreamingUnsupported
-	}
 	target := ServiceStatus{
 		Unit:    unit,
 		Scope:   scope,
 		Manager: manager,
 	}
-	return streamLogsSystemd(ctx, target)
+	return streamLogs(ctx, target, priority)
+}
+
+func streamLogs(ctx context.Context, target ServiceStatus, priority string) (io.ReadCloser, error) {
+	priority, err := normalizeLogPriority(priority)
+	if err != nil {
+		return nil, err
+	}
+	switch target.Manager {
+	case managerSystemd:
+		return streamLogsSystemd(ctx, target, priority)
+	case managerDocker:
+		if priority != "" {
+			return nil, ErrPriorityUnsupported
+		}
+		return streamLogsDocker(ctx, target.Unit)
+	default:
+		return nil, ErrStreamingUnsupported
+	}
 }
 
 // streamLogsSystemd spawns journalctl --follow for the given service target
 // and returns an io.ReadCloser that streams its stdout.
-func streamLogsSystemd(ctx context.Context, target ServiceStatus) (io.ReadCloser, error) {
-	args := make([]string, 0, 10)
+func streamLogsSystemd(ctx context.Context, target ServiceStatus, priority string) (io.ReadCloser, error) {
+	args := make([]string, 0, 12)
 	if strings.EqualFold(target.Scope, scopeUser) {
 		args = append(args, "--user")
 	}
@@ -90,6 +146,9 @@ func streamLogsSystemd(ctx context.Context, target ServiceStatus) (io.ReadCloser
 		"--output=short-iso",
 		"--follow",
 	)
+	if priority != "" {
+		args = append(args, "--priority="+priority)
+	}
 
 	cmd := journalctlCommandContext(ctx, "journalctl", args...)
 	stdout, err := cmd.StdoutPipe()
@@ -101,3 +160,26 @@ func streamLogsSystemd(ctx context.Context, target ServiceStatus) (io.ReadCloser
 	}
 	return &logStreamCloser{pipe: stdout, cmd: cmd}, nil
 }
+
+// streamLogsDocker spawns docker logs --follow for a container. The
+// container's stdout and stderr are merged into one stream.
+func streamLogsDocker(ctx context.Context, container string) (io.ReadCloser, error) {
+	if !IsValidUnit(container) {
+		return nil, ErrInvalidUnit
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("docker logs pipe: %w", err)
+	}
+	cmd := dockerCommandContext(ctx, "docker", "logs", "--follow", "--timestamps", "--tail", "50", container)
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	if err := cmd.Start(); err != nil {
+		_ = reader.Close()
+		_ = writer.Close()
+		return nil, fmt.Errorf("docker logs start: %w", err)
+	}
+	// The child holds its own copy; closing ours lets reads end at EOF.
+	_ = writer.Close()
+	return &logStreamCloser{pipe: reader, cmd: cmd}, nil
+}
@@ -15,7 +15,7 @@ func TestStreamLogsByUnitRejectsUnsupportedManager(t *testing.T) {
 	t.Parallel()
 
 	m := NewManager(time.Time{}, nil)
-	_, err := m.StreamLogsByUnit(context.Background(), "sentinel.service", scopeUser, managerLaunchd)
+	_, err := m.StreamLogsByUnit(context.Background(), "sentinel.service", scopeUser, managerLaunchd, "")
 	if !errors.Is(err, ErrStreamingUnsupported) {
 		t.Fatalf("StreamLogsByUnit() error = %v, want ErrStreamingUnsupported", err)
 	}
@@ -27,7 +27,7 @@ func TestStreamLogsByUnitBuildsJournalctlCommand(t *testing.T) {
 	installJournalctlCommandRecorder(t)
 
 	m := NewManager(time.Time{}, nil)
-	reader, err := m.StreamLogsByUnit(context.Background(), "sentinel.service", scopeUser, managerSystemd)
+	reader, err := m.StreamLogsByUnit(context.Background(), "sentinel.service", scopeUser, managerSystemd, "warn")
 	if err != nil {
 		t.Fatalf("StreamLogsByUnit() error = %v", err)
 	}
@@ -48,18 +48,60 @@ func TestStreamLogsByUnitBuildsJournalctlCommand(t *testing.T) {
 		"50",
 		"--output=short-iso",
 		"--follow",
+		"--priority=warning",
 	}
 	if strings.Join(got, "\n") != strings.Join(want, "\n") {
 		t.Fatalf("journalctl command = %#v, want %#v", got, want)
 	}
 }
 
+func TestStreamLogsByUnitBuildsDockerCommand(t *testing.T) {
+	// Not parallel: mutates package-level dockerCommandContext.
+
+	installCommandRecorder(t, &dockerCommandContext)
+
+	m := NewManager(time.Time{}, nil)
+	reader, err := m.StreamLogsByUnit(context.Background(), "web", scopeSystem, managerDocker, "")
+	if err != nil {
+		t.Fatalf("StreamLogsByUnit() error = %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	out, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	want := "docker\nlogs\n--follow\n--timestamps\n--tail\n50\nweb"
+	if got := strings.TrimSpace(string(out)); got != want {
+		t.Fatalf("docker command = %q, want %q", got, want)
+	}
+}
+
+func TestStreamLogsByUnitRejectsPriority(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(time.Time{}, nil)
+	if _, err := m.StreamLogsByUnit(context.Background(), "web", scopeSystem, managerDocker, "err"); !errors.Is(err, ErrPriorityUnsupported) {
+		t.Fatalf("docker priority error = %v, want ErrPriorityUnsupported", err)
+	}
+	if _, err := m.StreamLogsByUnit(context.Background(), "nginx.service", scopeSystem, managerSystemd, "loud"); !errors.Is(err, ErrInvalidLogPriority) {
+		t.Fatalf("invalid priority error = %v, want ErrInvalidLogPriority", err)
+	}
+}
+
 func installJournalctlCommandRecorder(t *testing.T) {
 	t.Helper()
+	installCommandRecorder(t, &journalctlCommandContext)
+}
+
+// installCommandRecorder swaps a command constructor for one that prints its
+// command line instead of running it.
+func installCommandRecorder(t *testing.T, target *func(context.Context, string, ...string) *exec.Cmd) {
+	t.Helper()
 
-	original := journalctlCommandContext
-	t.Cleanup(func() { journalctlCommandContext = original })
-	journalctlCommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+	original := *target
+	t.Cleanup(func() { *target = original })
+	*target = func(ctx context.Context, name string, args ...string) *exec.Cmd {
 		helperArgs := []string{"-test.run=TestJournalctlCommandRecorder", "--", name}
 		helperArgs = append(helperArgs, args...)
 		cmd := exec.CommandContext(ctx, os.Args[0], helperArgs...)
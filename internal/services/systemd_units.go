@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	unitTypeTimer  = "timer"
+	unitTypeSocket = "socket"
+
+	// systemdTimestampLayout is how systemctl show prints realtime
+	// timestamps, e.g. "Thu 2026-10-15 00:00:00 UTC".
+	systemdTimestampLayout = "Mon 2006-01-02 15:04:05 MST"
+)
+
+// annotateSystemdUnits adds timer schedules and socket listeners to the
+// browsed units of one systemd scope. Lookups are best-effort: a failing
+// systemctl call leaves the units unannotated.
+func (m *Manager) annotateSystemdUnits(ctx context.Context, scope string, units []BrowsedService) {
+	var timers []string
+	hasSockets := false
+	for _, u := range units {
+		switch u.UnitType {
+		case unitTypeTimer:
+			if IsValidUnit(u.Unit) {
+				timers = append(timers, u.Unit)
+			}
+		case unitTypeSocket:
+			hasSockets = true
+		}
+	}
+
+	if len(timers) > 0 {
+		schedules, err := m.systemdTimerSchedules(ctx, scope, timers)
+		if err != nil {
+			slog.Warn("systemd timer lookup failed", "scope", scope, "err", err)
+		}
+		for i := range units {
+			if props, ok := schedules[units[i].Unit]; ok {
+				units[i].NextElapse = systemdTimestamp(props["NextElapseUSecRealtime"])
+				units[i].LastTrigger = systemdTimestamp(props["LastTriggerUSec"])
+				units[i].Activates = strings.Fields(props["Triggers"])
+			}
+		}
+	}
+
+	if hasSockets {
+		sockets, err := m.systemdSocketListeners(ctx, scope)
+		if err != nil {
+			slog.Warn("systemd socket lookup failed", "scope", scope, "err", err)
+		}
+		for i := range units {
+			if socket, ok := sockets[units[i].Unit]; ok {
+				units[i].Listen = socket.listen
+				units[i].Activates = socket.activates
+			}
+		}
+	}
+}
+
+// systemdTimerSchedules returns the show properties of each timer, keyed by
+// unit name.
+func (m *Manager) systemdTimerSchedules(ctx context.Context, scope string, timers []string) (map[string]map[string]string, error) {
+	args := make([]string, 0, len(timers)+5)
+	if scope == scopeUser {
+		args = append(args, "--user")
+	}
+	args = append(args,
+		"show",
+		"--no-pager",
+		"--property=Id,NextElapseUSecRealtime,LastTriggerUSec,Triggers",
+		"--",
+	)
+	args = append(args, timers...)
+	raw, err := m.commandRunner(ctx, "systemctl", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// systemctl show separates the property blocks of each unit with a
+	// blank line.
+	schedules := make(map[string]map[string]string, len(timers))
+	for _, block := range strings.Split(raw, "\n\n") {
+		props := parseSystemdShow(block)
+		if id := props["Id"]; id != "" {
+			schedules[id] = props
+		}
+	}
+	return schedules, nil
+}
+
+type socketListener struct {
+	listen    []string
+	activates []string
+}
+
+func (m *Manager) systemdSocketListeners(ctx context.Context, scope string) (map[string]socketListener, error) {
+	args := make([]string, 0, 5)
+	if scope == scopeUser {
+		args = append(args, "--user")
+	}
+	args = append(args, "list-sockets", "--all", "--no-pager", "--no-legend")
+	raw, err := m.commandRunner(ctx, "systemctl", args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseSystemdListSockets(raw), nil
+}
+
+// parseSystemdListSockets reads "LISTEN UNIT ACTIVATES" rows. A listen
+// address may contain spaces ("kobject-uevent 1"), so the unit column is
+// located by its .socket suffix. Sockets with several listeners span
+// several rows.
+func parseSystemdListSockets(raw string) map[string]socketListener {
+	sockets := make(map[string]socketListener)
+	for _, line := range strings.Split(raw, "\n") {
+		fields := strings.Fields(line)
+		for i, field := range fields {
+			if i == 0 || !strings.HasSuffix(field, "."+unitTypeSocket) {
+				continue
+			}
+			socket := sockets[field]
+			socket.listen = append(socket.listen, strings.Join(fields[:i], " "))
+			for _, unit := range fields[i+1:] {
+				unit = strings.TrimSuffix(unit, ",")
+				if unit != "" && !slices.Contains(socket.activates, unit) {
+					socket.activates = append(socket.activates, unit)
+				}
+			}
+			sockets[field] = socket
+			break
+		}
+	}
+	return sockets
+}
+
+// systemdTimestamp converts a systemctl show timestamp to RFC3339. Unset
+// values ("", "n/a", "0") yield ""; unparseable values are returned as-is.
+func systemdTimestamp(raw string) string {
+	raw = strings.TrimSpace(raw)
+	switch raw {
+	case "", "n/a", "0":
+		return ""
+	}
+	parsed, err := time.Parse(systemdTimestampLayout, raw)
+	if err != nil {
+		return raw
+	}
+	return parsed.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestBrowseServicesAnnotatesTimersAndSockets(t *testing.T) {
+	t.Parallel()
+
+	var showArgs []string
+	m := newTestManager("linux", func(_ context.Context, name string, args ...string) (string, error) {
+		if name != cmdSystemctl {
+			return "", nil
+		}
+		switch {
+		case slices.Contains(args, "list-units"):
+			return "logrotate.timer loaded active waiting Daily rotation\n" +
+				"ssh.socket loaded active listening OpenBSD Secure Shell\n" +
+				"nginx.service loaded active running Nginx", nil
+		case slices.Contains(args, "list-sockets"):
+			return "0.0.0.0:22 ssh.socket ssh.service\n[::]:22 ssh.socket ssh.service\n", nil
+		case slices.Contains(args, "show"):
+			showArgs = args
+			return "Id=logrotate.timer\nNextElapseUSecRealtime=Thu 2026-10-15 00:00:00 UTC\n" +
+				"LastTriggerUSec=n/a\nTriggers=logrotate.service\n", nil
+		default:
+			return "", nil
+		}
+	})
+	m.uidFn = func() int { return 0 }
+
+	result, err := m.BrowseServices(context.Background())
+	if err != nil {
+		t.Fatalf("BrowseServices: %v", err)
+	}
+	byUnit := make(map[string]BrowsedService, len(result))
+	for _, bs := range result {
+		byUnit[bs.Unit] = bs
+	}
+
+	timer := byUnit["logrotate.timer"]
+	if timer.UnitType != unitTypeTimer || timer.NextElapse != "2026-10-15T00:00:00Z" || timer.LastTrigger != "" {
+		t.Fatalf("timer = %+v, want timer with next elapse and no last trigger", timer)
+	}
+	if !slices.Equal(timer.Activates, []string{"logrotate.service"}) {
+		t.Fatalf("timer activates = %v", timer.Activates)
+	}
+	if !slices.Contains(showArgs, "logrotate.timer") || slices.Contains(showArgs, "nginx.service") {
+		t.Fatalf("show args = %v, want only timer units", showArgs)
+	}
+
+	socket := byUnit["ssh.socket"]
+	if !slices.Equal(socket.Listen, []string{"0.0.0.0:22", "[::]:22"}) || !slices.Equal(socket.Activates, []string{"ssh.service"}) {
+		t.Fatalf("socket = %+v, want two listeners activating ssh.service", socket)
+	}
+	if svc := byUnit["nginx.service"]; svc.NextElapse != "" || svc.Listen != nil {
+		t.Fatalf("service = %+v, want no timer or socket details", svc)
+	}
+}
+
+func TestParseSystemdListSockets(t *testing.T) {
+	t.Parallel()
+
+	sockets := parseSystemdListSockets("/run/dbus/system_bus_socket dbus.socket dbus.service\n" +
+		"kobject-uevent 1 systemd-udevd-kernel.socket systemd-udevd.service\n" +
+		"\n")
+	if got := sockets["dbus.socket"]; !slices.Equal(got.listen, []string{"/run/dbus/system_bus_socket"}) {
+		t.Fatalf("dbus.socket = %+v", got)
+	}
+	got := sockets["systemd-udevd-kernel.socket"]
+	if !slices.Equal(got.listen, []string{"kobject-uevent 1"}) || !slices.Equal(got.activates, []string{"systemd-udevd.service"}) {
+		t.Fatalf("udevd socket = %+v", got)
+	}
+}
+
+func TestSystemdTimestamp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct{ raw, want string }{
+		{"Thu 2026-10-15 00:00:00 UTC", "2026-10-15T00:00:00Z"},
+		{"n/a", ""},
+		{"", ""},
+		{"0", ""},
+		{"soon", "soon"},
+	}
+	for _, tt := range tests {
+		if got := systemdTimestamp(tt.raw); got != tt.want {
+			t.Errorf("systemdTimestamp(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// updaterSystemdService is the oneshot unit the autoupdate timer triggers.
+const updaterSystemdService = "sentinel-updater.service"
+
+// ErrUpdaterNotInstalled is returned when the autoupdate service is missing.
+var ErrUpdaterNotInstalled = errors.New("autoupdate service is not installed")
+
+// StartUpdate runs the autoupdate service once without waiting for it. The
+// update runs outside the daemon so it survives the restart it triggers and
+// can roll back a release that fails its health check.
+func (m *Manager) StartUpdate(ctx context.Context) (ServiceStatus, error) {
+	list, err := m.ListServices(ctx)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+	target, ok := findServiceStatus(list, ServiceNameUpdater)
+	if !ok || !target.Exists {
+		return ServiceStatus{}, ErrUpdaterNotInstalled
+	}
+	switch target.Manager {
+	case managerSystemd:
+		args := make([]string, 0, 4)
+		if strings.EqualFold(target.Scope, scopeUser) {
+			args = append(args, "--user")
+		}
+		// A oneshot start blocks until the update finishes; --no-block
+		// returns once the job is queued.
+		args = append(args, "start", "--no-block", updaterSystemdService)
+		if _, err := m.commandRunner(ctx, "systemctl", args...); err != nil {
+			return ServiceStatus{}, fmt.Errorf("systemd action failed: %w", err)
+		}
+	case managerLaunchd:
+		if err := m.actLaunchd(ctx, target.Scope, target.Unit, ActionStart); err != nil {
+			return ServiceStatus{}, err
+		}
+	default:
+		return ServiceStatus{}, fmt.Errorf("unsupported service manager: %s", target.Manager)
+	}
+	return target, nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UPS status sources.
+const (
+	UPSSourceNUT     = "nut"
+	UPSSourceApcupsd = "apcupsd"
+)
+
+// UPS power states.
+const (
+	UPSStateOnline     = "online"
+	UPSStateOnBattery  = "on-battery"
+	UPSStateLowBattery = "low-battery"
+)
+
+// ErrUPSNotConfigured is returned by UPS when no UPS source is set.
+var ErrUPSNotConfigured = errors.New("ups monitoring is not configured")
+
+// UPSStatus is the power state of the UPS feeding the host. Charge and
+// runtime are -1 when the UPS does not report them.
+type UPSStatus struct {
+	Source        string  `json:"source"`
+	Name          string  `json:"name,omitempty"`
+	Model         string  `json:"model,omitempty"`
+	State         string  `json:"state"`
+	Raw           string  `json:"raw"`
+	OnBattery     bool    `json:"onBattery"`
+	LowBattery    bool    `json:"lowBattery"`
+	ChargePercent float64 `json:"chargePercent"`
+	RuntimeSec    int64   `json:"runtimeSec"`
+	LoadPercent   float64 `json:"loadPercent"`
+	CheckedAt     string  `json:"checkedAt"`
+}
+
+// SetUPS sets where UPS status is read from. source is UPSSourceNUT, with
+// name the NUT UPS (e.g. ups@localhost), or UPSSourceApcupsd, with name the
+// apcupsd network address (host:port, empty for the local daemon). An
+// empty source disables UPS monitoring.
+func (m *Manager) SetUPS(source, name string) {
+	m.upsSource = strings.ToLower(strings.TrimSpace(source))
+	m.upsName = strings.TrimSpace(name)
+}
+
+// UPS reads the current UPS status through upsc or apcaccess.
+func (m *Manager) UPS(ctx context.Context) (UPSStatus, error) {
+	if m.upsName != "" && !IsValidUnit(m.upsName) {
+		return UPSStatus{}, fmt.Errorf("invalid ups name %q", m.upsName)
+	}
+	var status UPSStatus
+	switch m.upsSource {
+	case UPSSourceNUT:
+		out, err := m.commandRunner(ctx, "upsc", m.upsName)
+		if err != nil {
+			return UPSStatus{}, fmt.Errorf("upsc failed: %w", err)
+		}
+		status = parseNUTStatus(out)
+	case UPSSourceApcupsd:
+		args := []string{"status"}
+		if m.upsName != "" {
+			args = append(args, m.upsName)
+		}
+		out, err := m.commandRunner(ctx, "apcaccess", args...)
+		if err != nil {
+			return UPSStatus{}, fmt.Errorf("apcaccess failed: %w", err)
+		}
+		status = parseApcupsdStatus(out)
+	default:
+		return UPSStatus{}, ErrUPSNotConfigured
+	}
+	status.Source = m.upsSource
+	status.Name = m.upsName
+	status.CheckedAt = m.nowFn().UTC().Format(time.RFC3339)
+	return status, nil
+}
+
+// upsState derives the power state from the on-battery and low-battery
+// flags.
+func upsState(onBattery, lowBattery, known bool) string {
+	switch {
+	case lowBattery:
+		return UPSStateLowBattery
+	case onBattery:
+		return UPSStateOnBattery
+	case known:
+		return UPSStateOnline
+	default:
+		return stateUnknown
+	}
+}
+
+// parseNUTStatus reads `upsc` output: "key: value" lines where ups.status
+// holds flags such as OL (on line), OB (on battery) and LB (low battery).
+func parseNUTStatus(out string) UPSStatus {
+	vars := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok {
+			vars[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	status := UPSStatus{
+		Model:         vars["device.model"],
+		Raw:           vars["ups.status"],
+		ChargePercent: parseUPSNumber(vars["battery.charge"]),
+		RuntimeSec:    int64(parseUPSNumber(vars["battery.runtime"])),
+		LoadPercent:   max(parseUPSNumber(vars["ups.load"]), 0),
+	}
+	if status.Model == "" {
+		status.Model = vars["ups.model"]
+	}
+	flags := strings.Fields(status.Raw)
+	online := false
+	for _, flag := range flags {
+		switch flag {
+		case "OB":
+			status.OnBattery = true
+		case "LB":
+			status.LowBattery = true
+		case "OL":
+			online = true
+		}
+	}
+	status.State = upsState(status.OnBattery, status.LowBattery, online)
+	return status
+}
+
+// parseApcupsdStatus reads `apcaccess status` output: "KEY : value" lines,
+// with units after the number (e.g. "TIMELEFT :  35.0 Minutes").
+func parseApcupsdStatus(out string) UPSStatus {
+	vars := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok {
+			vars[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	status := UPSStatus{
+		Model:         vars["MODEL"],
+		Raw:           vars["STATUS"],
+		ChargePercent: parseUPSNumber(vars["BCHARGE"]),
+		RuntimeSec:    -1,
+		LoadPercent:   max(parseUPSNumber(vars["LOADPCT"]), 0),
+	}
+	if minutes := parseUPSNumber(vars["TIMELEFT"]); minutes >= 0 {
+		status.RuntimeSec = int64(minutes * 60)
+	}
+	flags := strings.Fields(status.Raw)
+	online := false
+	for _, flag := range flags {
+		switch flag {
+		case "ONBATT":
+			status.OnBattery = true
+		case "LOWBATT":
+			status.LowBattery = true
+		case "ONLINE":
+			online = true
+		}
+	}
+	status.State = upsState(status.OnBattery, status.LowBattery, online)
+	return status
+}
+
+// parseUPSNumber parses the leading number of a UPS variable, or -1.
+func parseUPSNumber(raw string) float64 {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return -1
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return -1
+	}
+	return v
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestUPSStatus(t *testing.T) {
+	t.Parallel()
+
+	var calls [][]string
+	m := newTestManager("linux", func(_ context.Context, name string, args ...string) (string, error) {
+		calls = append(calls, append([]string{name}, args...))
+		if name == "upsc" {
+			return "battery.charge: 87\nbattery.runtime: 1260\ndevice.model: Smart-UPS 1500\nups.load: 23\nups.status: OB DISCHRG", nil
+		}
+		return "APC      : 001,036,0870\nSTATUS   : ONBATT LOWBATT\nLOADPCT  :  18.0 Percent\n" +
+			"BCHARGE  : 9.0 Percent\nTIMELEFT :  2.5 Minutes\nMODEL    : Back-UPS XS 700U", nil
+	})
+	ctx := context.Background()
+
+	if _, err := m.UPS(ctx); !errors.Is(err, ErrUPSNotConfigured) {
+		t.Fatalf("UPS() without source = %v, want ErrUPSNotConfigured", err)
+	}
+
+	m.SetUPS(UPSSourceNUT, "ups@localhost")
+	nut, err := m.UPS(ctx)
+	if err != nil {
+		t.Fatalf("UPS(nut): %v", err)
+	}
+	if nut.State != UPSStateOnBattery || !nut.OnBattery || nut.LowBattery ||
+		nut.ChargePercent != 87 || nut.RuntimeSec != 1260 || nut.LoadPercent != 23 || nut.Model != "Smart-UPS 1500" {
+		t.Fatalf("nut = %+v", nut)
+	}
+
+	m.SetUPS(UPSSourceApcupsd, "")
+	apc, err := m.UPS(ctx)
+	if err != nil {
+		t.Fatalf("UPS(apcupsd): %v", err)
+	}
+	if apc.State != UPSStateLowBattery || !apc.OnBattery || !apc.LowBattery || apc.RuntimeSec != 150 || apc.ChargePercent != 9 {
+		t.Fatalf("apcupsd = %+v", apc)
+	}
+
+	want := [][]string{{"upsc", "ups@localhost"}, {"apcaccess", "status"}}
+	if !slices.EqualFunc(calls, want, slices.Equal) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+
+	m.SetUPS(UPSSourceNUT, "-h")
+	if _, err := m.UPS(ctx); err == nil {
+		t.Fatal("UPS() accepted a name that looks like a flag")
+	}
+}
+
+func TestParseNUTStatusOnline(t *testing.T) {
+	t.Parallel()
+
+	status := parseNUTStatus("ups.status: OL CHRG\nups.model: Eaton 5E")
+	if status.State != UPSStateOnline || status.OnBattery || status.ChargePercent != -1 || status.RuntimeSec != -1 || status.Model != "Eaton 5E" {
+		t.Fatalf("status = %+v", status)
+	}
+	if status := parseNUTStatus(""); status.State != stateUnknown {
+		t.Fatalf("empty status state = %q, want unknown", status.State)
+	}
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+// Uptime check types.
+const (
+	UptimeCheckHTTP = "http"
+	UptimeCheckTCP  = "tcp"
+	UptimeCheckICMP = "icmp"
+)
+
+const (
+	// UptimeTickInterval is how often due uptime checks are looked for.
+	UptimeTickInterval = 5 * time.Second
+
+	// DefaultUptimeInterval and DefaultUptimeTimeout apply when a check
+	// leaves them at 0.
+	DefaultUptimeInterval = 60
+	DefaultUptimeTimeout  = 10
+
+	minUptimeInterval   = 10
+	maxUptimeInterval   = 24 * 60 * 60
+	maxUptimeTimeout    = 60
+	maxUptimeExpectBody = 1024
+)
+
+// ErrInvalidUptimeCheck is returned for a malformed uptime check.
+var ErrInvalidUptimeCheck = errors.New("invalid uptime check")
+
+// pingTimePattern matches the round trip time ping prints, e.g. "time=12.3
+// ms" or, on Windows, "time<1ms".
+var pingTimePattern = regexp.MustCompile(`time[=<]([0-9.]+) ?ms`)
+
+// uptimeRepo stores uptime checks and their outcomes.
+type uptimeRepo interface {
+	ListOpsUptimeChecks(ctx context.Context) ([]store.OpsUptimeCheck, error)
+	RecordOpsUptimeResult(ctx context.Context, id string, r store.OpsUptimeResult) error
+}
+
+// UptimeSummary counts the enabled uptime checks by status.
+type UptimeSummary struct {
+	Total   int `json:"total"`
+	Up      int `json:"up"`
+	Down    int `json:"down"`
+	Pending int `json:"pending"`
+}
+
+// UptimeChange reports an uptime check whose status changed in a
+// CheckUptime run. Check holds the new outcome.
+type UptimeChange struct {
+	Check    store.OpsUptimeCheck
+	Previous string
+}
+
+// SetUptimeChecks sets the store the uptime checks are read from. It is
+// called once at startup.
+func (m *Manager) SetUptimeChecks(repo uptimeRepo) {
+	m.uptimeChecks = repo
+}
+
+// ValidateUptimeCheck rejects uptime checks that cannot run.
+func ValidateUptimeCheck(check store.OpsUptimeCheckWrite) error {
+	if err := validateUptimeCheck(check); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidUptimeCheck, err.Error())
+	}
+	return nil
+}
+
+func validateUptimeCheck(check store.OpsUptimeCheckWrite) error {
+	if strings.TrimSpace(check.Name) == "" {
+		return errors.New("name is required")
+	}
+	if check.IntervalSeconds < minUptimeInterval || check.IntervalSeconds > maxUptimeInterval {
+		return fmt.Errorf("intervalSeconds must be between %d and %d", minUptimeInterval, maxUptimeInterval)
+	}
+	if check.TimeoutSeconds < 1 || check.TimeoutSeconds > maxUptimeTimeout || check.TimeoutSeconds > check.IntervalSeconds {
+		return fmt.Errorf("timeoutSeconds must be between 1 and %d and at most intervalSeconds", maxUptimeTimeout)
+	}
+	if check.Type != UptimeCheckHTTP && (check.ExpectStatus != 0 || check.ExpectBody != "") {
+		return errors.New("expectStatus and expectBody apply to http checks")
+	}
+	switch check.Type {
+	case UptimeCheckHTTP:
+		parsed, err := url.Parse(check.Target)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("target must be an absolute http or https URL")
+		}
+		if check.ExpectStatus != 0 && (check.ExpectStatus < 100 || check.ExpectStatus > 599) {
+			return errors.New("expectStatus must be an HTTP status code")
+		}
+		if len(check.ExpectBody) > maxUptimeExpectBody {
+			return fmt.Errorf("expectBody must be at most %d bytes", maxUptimeExpectBody)
+		}
+		if _, err := regexp.Compile(check.ExpectBody); err != nil {
+			return errors.New("expectBody must be a valid regular expression")
+		}
+	case UptimeCheckTCP:
+		host, port, err := net.SplitHostPort(check.Target)
+		if err != nil || host == "" {
+			return errors.New("target must be host:port")
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return errors.New("target port must be between 1 and 65535")
+		}
+	case UptimeCheckICMP:
+		if !validPingHost(check.Target) {
+			return errors.New("target must be a host name or IP address")
+		}
+	default:
+		return errors.New("type must be http, tcp or icmp")
+	}
+	return nil
+}
+
+// validPingHost accepts IP addresses and host names, which keeps the target
+// from being read as a ping option.
+func validPingHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	if host == "" || len(host) > 253 || strings.HasPrefix(host, "-") {
+		return false
+	}
+	for _, r := range host {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '.' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckUptime runs the enabled uptime checks that are due, records their
+// outcomes and returns the checks whose status changed.
+func (m *Manager) CheckUptime(ctx context.Context) ([]UptimeChange, error) {
+	if m.uptimeChecks == nil {
+		return nil, nil
+	}
+	checks, err := m.uptimeChecks.ListOpsUptimeChecks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.nowFn()
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		changes []UptimeChange
+	)
+	for _, check := range checks {
+		due := check.LastCheckedAt.IsZero() || now.Sub(check.LastCheckedAt) >= time.Duration(check.IntervalSeconds)*time.Second
+		if !check.Enabled || !due {
+			continue
+		}
+		wg.Go(func() {
+			result := m.runUptimeCheck(ctx, check)
+			if ctx.Err() != nil {
+				return
+			}
+			if err := m.uptimeChecks.RecordOpsUptimeResult(ctx, check.ID, result); err != nil {
+				slog.Warn("uptime result not recorded", "check", check.Name, "err", err)
+				return
+			}
+			if result.Status == check.Status {
+				return
+			}
+			change := UptimeChange{Check: check, Previous: check.Status}
+			change.Check.Status = result.Status
+			change.Check.Detail = result.Detail
+			change.Check.LatencyMs = result.LatencyMs
+			change.Check.LastCheckedAt = result.CheckedAt
+			change.Check.LastChangeAt = result.CheckedAt
+			mu.Lock()
+			changes = append(changes, change)
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Check.Name < changes[j].Check.Name })
+	return changes, nil
+}
+
+// UptimeSummary counts the enabled uptime checks by their last status. It
+// returns nil when no store is set or no check is enabled.
+func (m *Manager) UptimeSummary(ctx context.Context) *UptimeSummary {
+	if m.uptimeChecks == nil {
+		return nil
+	}
+	checks, err := m.uptimeChecks.ListOpsUptimeChecks(ctx)
+	if err != nil {
+		return nil
+	}
+	var summary UptimeSummary
+	for _, check := range checks {
+		if !check.Enabled {
+			continue
+		}
+		summary.Total++
+		switch check.Status {
+		case store.UptimeStatusUp:
+			summary.Up++
+		case store.UptimeStatusDown:
+			summary.Down++
+		default:
+			summary.Pending++
+		}
+	}
+	if summary.Total == 0 {
+		return nil
+	}
+	return &summary
+}
+
+func (m *Manager) runUptimeCheck(ctx context.Context, check store.OpsUptimeCheck) store.OpsUptimeResult {
+	timeout := time.Duration(check.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultUptimeTimeout * time.Second
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	var (
+		latency time.Duration
+		err     error
+	)
+	switch check.Type {
+	case UptimeCheckHTTP:
+		var body *regexp.Regexp
+		if check.ExpectBody != "" {
+			body, err = regexp.Compile(check.ExpectBody)
+		}
+		if err == nil {
+			err = checkHTTP(checkCtx, check.Target, check.ExpectStatus, body)
+		}
+	case UptimeCheckTCP:
+		err = checkTCP(checkCtx, check.Target)
+	case UptimeCheckICMP:
+		latency, err = m.ping(checkCtx, check.Target, timeout)
+	default:
+		err = fmt.Errorf("unknown check type %q", check.Type)
+	}
+	if latency == 0 {
+		latency = time.Since(started)
+	}
+
+	result := store.OpsUptimeResult{
+		Status:    store.UptimeStatusUp,
+		LatencyMs: latency.Milliseconds(),
+		CheckedAt: m.nowFn().UTC(),
+	}
+	if err != nil {
+		result.Status = store.UptimeStatusDown
+		result.Detail = truncateDetail(err.Error())
+		if checkCtx.Err() != nil && ctx.Err() == nil {
+			result.Detail = fmt.Sprintf("timed out after %s", timeout)
+		}
+	}
+	return result
+}
+
+// ping sends one echo request through the system ping, which holds the raw
+// socket privileges Sentinel lacks, and returns the round trip time.
+func (m *Manager) ping(ctx context.Context, host string, timeout time.Duration) (time.Duration, error) {
+	if !validPingHost(host) {
+		return 0, fmt.Errorf("invalid host %q", host)
+	}
+	var args []string
+	switch m.goos {
+	case "windows":
+		args = []string{"-n", "1", "-w", strconv.FormatInt(timeout.Milliseconds(), 10), host}
+	case "linux":
+		args = []string{"-c", "1", "-W", strconv.Itoa(int(timeout / time.Second)), host}
+	default:
+		// The BSDs and macOS take the overall timeout in seconds.
+		args = []string{"-c", "1", "-t", strconv.Itoa(int(timeout / time.Second)), host}
+	}
+	out, err := m.commandRunner(ctx, "ping", args...)
+	// Windows ping exits 0 on "Destination host unreachable" replies.
+	if err != nil || (m.goos == "windows" && !strings.Contains(out, "TTL=")) {
+		return 0, fmt.Errorf("no reply from %s", host)
+	}
+	if match := pingTimePattern.FindStringSubmatch(out); match != nil {
+		if ms, err := strconv.ParseFloat(match[1], 64); err == nil {
+			return time.Duration(ms * float64(time.Millisecond)), nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+// fakeUptimeRepo keeps uptime checks in memory and applies recorded
+// results the way the store does.
+type fakeUptimeRepo struct {
+	mu     sync.Mutex
+	checks []store.OpsUptimeCheck
+}
+
+func (r *fakeUptimeRepo) ListOpsUptimeChecks(context.Context) ([]store.OpsUptimeCheck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]store.OpsUptimeCheck(nil), r.checks...), nil
+}
+
+func (r *fakeUptimeRepo) RecordOpsUptimeResult(_ context.Context, id string, result store.OpsUptimeResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.checks {
+		if r.checks[i].ID == id {
+			r.checks[i].Status = result.Status
+			r.checks[i].Detail = result.Detail
+			r.checks[i].LastCheckedAt = result.CheckedAt
+			return nil
+		}
+	}
+	return fmt.Errorf("uptime check %s not found", id)
+}
+
+func TestValidateUptimeCheck(t *testing.T) {
+	t.Parallel()
+
+	for _, valid := range []store.OpsUptimeCheckWrite{
+		{Name: "web", Type: "http", Target: "https://example.com/health", IntervalSeconds: 60, TimeoutSeconds: 10, ExpectStatus: 204, ExpectBody: `"status":\s*"ok"`},
+		{Name: "db", Type: "tcp", Target: "db.internal:5432", IntervalSeconds: 30, TimeoutSeconds: 5},
+		{Name: "router", Type: "icmp", Target: "192.168.1.1", IntervalSeconds: 10, TimeoutSeconds: 2},
+		{Name: "nas", Type: "icmp", Target: "nas.lan", IntervalSeconds: 60, TimeoutSeconds: 5},
+	} {
+		if err := ValidateUptimeCheck(valid); err != nil {
+			t.Errorf("ValidateUptimeCheck(%+v) = %v", valid, err)
+		}
+	}
+
+	base := store.OpsUptimeCheckWrite{Name: "x", IntervalSeconds: 60, TimeoutSeconds: 10}
+	for _, bad := range []func(*store.OpsUptimeCheckWrite){
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target = "udp", "dns:53" },
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target = "http", "ftp://example.com" },
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target, c.ExpectBody = "http", "http://x", "(" },
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target, c.ExpectStatus = "http", "http://x", 42 },
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target, c.ExpectStatus = "tcp", "db:5432", 200 },
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target = "tcp", "5432" },
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target = "icmp", "-f" },
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target = "icmp", "host; reboot" },
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target, c.IntervalSeconds = "icmp", "nas", 5 },
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target, c.TimeoutSeconds = "icmp", "nas", 90 },
+		func(c *store.OpsUptimeCheckWrite) { c.Type, c.Target, c.Name = "icmp", "nas", " " },
+	} {
+		check := base
+		bad(&check)
+		if err := ValidateUptimeCheck(check); !errors.Is(err, ErrInvalidUptimeCheck) {
+			t.Errorf("ValidateUptimeCheck(%+v) = %v, want ErrInvalidUptimeCheck", check, err)
+		}
+	}
+}
+
+func TestCheckUptime(t *testing.T) {
+	t.Parallel()
+
+	var body atomic.Value
+	body.Store(`{"status": "ok"}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body.Load().(string)))
+	}))
+	defer srv.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	repo := &fakeUptimeRepo{checks: []store.OpsUptimeCheck{
+		{ID: "1", Name: "api", Type: UptimeCheckHTTP, Target: srv.URL, ExpectBody: `"status":\s*"ok"`, IntervalSeconds: 60, TimeoutSeconds: 5, Enabled: true, Status: store.UptimeStatusPending},
+		{ID: "2", Name: "db", Type: UptimeCheckTCP, Target: listener.Addr().String(), IntervalSeconds: 60, TimeoutSeconds: 5, Enabled: true, Status: store.UptimeStatusPending},
+		{ID: "3", Name: "router", Type: UptimeCheckICMP, Target: "192.168.1.1", IntervalSeconds: 60, TimeoutSeconds: 2, Enabled: true, Status: store.UptimeStatusPending},
+		{ID: "4", Name: "idle", Type: UptimeCheckTCP, Target: "127.0.0.1:1", IntervalSeconds: 60, TimeoutSeconds: 5, Status: store.UptimeStatusPending},
+	}}
+	var pings []string
+	m := newTestManager("linux", func(_ context.Context, name string, args ...string) (string, error) {
+		pings = append(pings, name+" "+strings.Join(args, " "))
+		return "64 bytes from 192.168.1.1: icmp_seq=1 ttl=64 time=0.412 ms", nil
+	})
+	m.nowFn = func() time.Time { return now }
+	m.SetUptimeChecks(repo)
+
+	if summary := m.UptimeSummary(context.Background()); summary == nil || *summary != (UptimeSummary{Total: 3, Pending: 3}) {
+		t.Fatalf("summary before the first run = %+v", summary)
+	}
+	changes, err := m.CheckUptime(context.Background())
+	if err != nil {
+		t.Fatalf("CheckUptime: %v", err)
+	}
+	if len(changes) != 3 || changes[0].Check.Name != "api" || changes[0].Check.Status != store.UptimeStatusUp || changes[0].Previous != store.UptimeStatusPending {
+		t.Fatalf("first run changes = %+v", changes)
+	}
+	if changes[2].Check.Name != "router" || changes[2].Check.LatencyMs != 0 || changes[2].Check.Status != store.UptimeStatusUp {
+		t.Fatalf("router change = %+v", changes[2])
+	}
+	if len(pings) != 1 || pings[0] != "ping -c 1 -W 2 192.168.1.1" {
+		t.Fatalf("pings = %v", pings)
+	}
+
+	// Nothing is due before the interval elapses.
+	body.Store(`{"status": "degraded"}`)
+	if changes, err := m.CheckUptime(context.Background()); err != nil || len(changes) != 0 {
+		t.Fatalf("CheckUptime within the interval = %+v, %v", changes, err)
+	}
+
+	now = now.Add(time.Minute)
+	changes, err = m.CheckUptime(context.Background())
+	if err != nil {
+		t.Fatalf("CheckUptime: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Check.Name != "api" || changes[0].Check.Status != store.UptimeStatusDown ||
+		!strings.Contains(changes[0].Check.Detail, "body does not match") {
+		t.Fatalf("changes after the body changed = %+v", changes)
+	}
+	if summary := m.UptimeSummary(context.Background()); *summary != (UptimeSummary{Total: 3, Up: 2, Down: 1}) {
+		t.Fatalf("summary = %+v", summary)
+	}
+}
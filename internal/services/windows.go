@@ -0,0 +1,349 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	managerWindows = "windows"
+
+	// Service states as reported by the service control manager.
+	scmStateRunning      = "running"
+	scmStateStopped      = "stopped"
+	scmStateStartPending = "start-pending"
+	scmStateStopPending  = "stop-pending"
+	scmStatePaused       = "paused"
+
+	// Service start types.
+	scmStartAuto        = "auto"
+	scmStartDelayedAuto = "delayed-auto"
+	scmStartManual      = "manual"
+	scmStartDisabled    = "disabled"
+
+	// scmEventProvider writes service start, stop and crash events to the
+	// System log.
+	scmEventProvider = "Service Control Manager"
+)
+
+// errSCMUnavailable is returned for Windows services on other platforms.
+var errSCMUnavailable = errors.New("windows service control manager is not available")
+
+// windowsService is a service registered with the Windows service control
+// manager.
+type windowsService struct {
+	Name        string
+	DisplayName string
+	Description string
+	State       string
+	StartType   string
+	PID         uint32
+	BinaryPath  string
+	Account     string
+}
+
+// serviceControlManager is the part of the Windows service control manager
+// Sentinel drives. It is nil outside Windows.
+type serviceControlManager interface {
+	ListServices(ctx context.Context) ([]windowsService, error)
+	QueryService(ctx context.Context, name string) (windowsService, error)
+	StartService(ctx context.Context, name string) error
+	StopService(ctx context.Context, name string) error
+	SetStartType(ctx context.Context, name, startType string) error
+}
+
+// windowsActiveState maps a service control manager state to the systemd
+// vocabulary used across the ops plane.
+func windowsActiveState(state string) string {
+	switch state {
+	case scmStateRunning:
+		return stateActive
+	case scmStateStopped:
+		return stateInactive
+	case scmStateStartPending:
+		return "activating"
+	case scmStateStopPending:
+		return "deactivating"
+	case scmStatePaused:
+		return scmStatePaused
+	default:
+		return stateUnknown
+	}
+}
+
+// windowsEnabledState maps a start type to an enabled state: automatic
+// services start on boot, manual ones only on demand.
+func windowsEnabledState(startType string) string {
+	switch startType {
+	case scmStartAuto, scmStartDelayedAuto:
+		return "enabled"
+	case scmStartManual:
+		return scmStartManual
+	case scmStartDisabled:
+		return scmStartDisabled
+	default:
+		return stateUnknown
+	}
+}
+
+func (m *Manager) probeWindowsService(ctx context.Context, svc *ServiceStatus) {
+	if m.scm == nil || !IsValidUnit(svc.Unit) {
+		svc.Exists = false
+		svc.ActiveState = stateUnknown
+		svc.EnabledState = stateUnknown
+		return
+	}
+	info, err := m.scm.QueryService(ctx, svc.Unit)
+	if err != nil {
+		svc.Exists = false
+		svc.ActiveState = stateUnknown
+		svc.EnabledState = stateUnknown
+		return
+	}
+	svc.Exists = true
+	svc.ActiveState = windowsActiveState(info.State)
+	svc.EnabledState = windowsEnabledState(info.StartType)
+}
+
+func (m *Manager) actWindows(ctx context.Context, name, action string) error {
+	if !IsValidUnit(name) {
+		return ErrInvalidUnit
+	}
+	if m.scm == nil {
+		return errSCMUnavailable
+	}
+	switch action {
+	case ActionStart:
+		return m.scm.StartService(ctx, name)
+	case ActionStop:
+		return m.scm.StopService(ctx, name)
+	case ActionRestart:
+		if err := m.scm.StopService(ctx, name); err != nil {
+			return err
+		}
+		return m.scm.StartService(ctx, name)
+	case ActionEnable:
+		return m.scm.SetStartType(ctx, name, scmStartAuto)
+	case ActionDisable:
+		return m.scm.SetStartType(ctx, name, scmStartDisabled)
+	default:
+		return ErrInvalidAction
+	}
+}
+
+func (m *Manager) inspectWindows(ctx context.Context, name string) (map[string]string, string, error) {
+	if !IsValidUnit(name) {
+		return nil, "", ErrInvalidUnit
+	}
+	if m.scm == nil {
+		return nil, "", errSCMUnavailable
+	}
+	info, err := m.scm.QueryService(ctx, name)
+	if err != nil {
+		return nil, "", fmt.Errorf("service query failed: %w", err)
+	}
+	props := map[string]string{
+		"Name":        info.Name,
+		"DisplayName": info.DisplayName,
+		"Description": info.Description,
+		"State":       info.State,
+		"StartType":   info.StartType,
+		"PID":         strconv.FormatUint(uint64(info.PID), 10),
+		"BinaryPath":  info.BinaryPath,
+		"Account":     info.Account,
+	}
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	var out strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&out, "%s=%s\n", key, props[key])
+	}
+	return props, out.String(), nil
+}
+
+func buildWindowsSummary(props map[string]string) string {
+	return fmt.Sprintf("state=%s start=%s", props["State"], props["StartType"])
+}
+
+func (m *Manager) discoverWindowsServices(ctx context.Context) ([]AvailableService, error) {
+	if m.scm == nil {
+		return nil, errSCMUnavailable
+	}
+	services, err := m.scm.ListServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]AvailableService, 0, len(services))
+	for _, info := range services {
+		if !IsValidUnit(info.Name) {
+			continue
+		}
+		description := info.DisplayName
+		if description == "" {
+			description = info.Description
+		}
+		out = append(out, AvailableService{
+			Unit:         info.Name,
+			UnitType:     unitTypeService,
+			Description:  description,
+			ActiveState:  windowsActiveState(info.State),
+			EnabledState: windowsEnabledState(info.StartType),
+			Manager:      managerWindows,
+			Scope:        scopeSystem,
+		})
+	}
+	slices.SortFunc(out, func(a, b AvailableService) int { return cmp.Compare(a.Unit, b.Unit) })
+	return out, nil
+}
+
+// windowsEventLevels maps journald priorities to the most verbose Windows
+// event level they keep: 1 critical, 2 error, 3 warning, 4 information,
+// 5 verbose.
+var windowsEventLevels = map[string]int{
+	"emerg": 1, "alert": 1, "crit": 1,
+	"err":     2,
+	"warning": 3,
+	"notice":  4, "info": 4,
+	"debug": 5,
+}
+
+// logsWindows reads the Event Log entries of a service: those it wrote to
+// the Application log under its own name, and the start, stop and crash
+// events the service control manager wrote to the System log.
+func (m *Manager) logsWindows(ctx context.Context, name string, query LogQuery) (string, error) {
+	if !IsValidUnit(name) {
+		return "", ErrInvalidUnit
+	}
+	events, err := m.queryEventLog(ctx, "Application", "Provider[@Name='"+name+"']", query)
+	if err != nil {
+		return "", err
+	}
+	if m.scm != nil {
+		info, err := m.scm.QueryService(ctx, name)
+		if err == nil && info.DisplayName != "" && !strings.Contains(info.DisplayName, "'") {
+			filter := "Provider[@Name='" + scmEventProvider + "']"
+			scmEvents, err := m.queryEventLog(ctx, "System", filter, query,
+				"EventData[Data[@Name='param1']='"+info.DisplayName+"']")
+			if err != nil {
+				slog.WarnContext(ctx, "service control manager events failed", "service", name, "err", err)
+			}
+			events = append(events, scmEvents...)
+		}
+	}
+	slices.SortStableFunc(events, func(a, b windowsEvent) int { return a.time.Compare(b.time) })
+
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		lines = append(lines, event.String())
+	}
+	return tailLogLines(grepLogLines(strings.Join(lines, "\n"), query.Grep), query.Lines), nil
+}
+
+// queryEventLog runs wevtutil for the newest query.Lines events of a log
+// matching the System filter and optional extra conditions.
+func (m *Manager) queryEventLog(ctx context.Context, log, systemFilter string, query LogQuery, extra ...string) ([]windowsEvent, error) {
+	conditions := []string{systemFilter}
+	if !query.Since.IsZero() {
+		conditions = append(conditions, "TimeCreated[@SystemTime>='"+query.Since.UTC().Format(time.RFC3339)+"']")
+	}
+	if !query.Until.IsZero() {
+		conditions = append(conditions, "TimeCreated[@SystemTime<='"+query.Until.UTC().Format(time.RFC3339)+"']")
+	}
+	if query.Priority != "" {
+		level := windowsEventLevels[query.Priority]
+		// Level 0 is "log always", which Windows renders as information.
+		if level >= 4 {
+			conditions = append(conditions, fmt.Sprintf("Level<=%d", level))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("(Level>=1 and Level<=%d)", level))
+		}
+	}
+	xpath := "*[System[" + strings.Join(conditions, " and ") + "]"
+	for _, condition := range extra {
+		xpath += " and " + condition
+	}
+	xpath += "]"
+
+	out, err := m.commandRunner(ctx, "wevtutil", "qe", log, "/q:"+xpath,
+		fmt.Sprintf("/c:%d", query.Lines), "/rd:true", "/f:RenderedXml")
+	if err != nil {
+		return nil, fmt.Errorf("wevtutil failed: %w", err)
+	}
+	return parseWindowsEvents(out)
+}
+
+// windowsEvent is one Event Log entry.
+type windowsEvent struct {
+	time     time.Time
+	provider string
+	level    string
+	message  string
+}
+
+func (e windowsEvent) String() string {
+	return fmt.Sprintf("%s %s[%s]: %s", e.time.UTC().Format(time.RFC3339), e.provider, e.level, e.message)
+}
+
+// renderedEvent is the part of a wevtutil /f:RenderedXml event Sentinel
+// shows.
+type renderedEvent struct {
+	System struct {
+		Provider struct {
+			Name string `xml:"Name,attr"`
+		} `xml:"Provider"`
+		TimeCreated struct {
+			SystemTime string `xml:"SystemTime,attr"`
+		} `xml:"TimeCreated"`
+	} `xml:"System"`
+	RenderingInfo struct {
+		Level   string `xml:"Level"`
+		Message string `xml:"Message"`
+	} `xml:"RenderingInfo"`
+}
+
+// parseWindowsEvents decodes the sequence of <Event> elements wevtutil
+// prints. Messages are folded onto one line.
+func parseWindowsEvents(out string) ([]windowsEvent, error) {
+	dec := xml.NewDecoder(strings.NewReader(out))
+	var events []windowsEvent
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse event log: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Event" {
+			continue
+		}
+		var raw renderedEvent
+		if err := dec.DecodeElement(&raw, &start); err != nil {
+			return nil, fmt.Errorf("parse event log: %w", err)
+		}
+		created, _ := time.Parse(time.RFC3339Nano, raw.System.TimeCreated.SystemTime)
+		level := raw.RenderingInfo.Level
+		if level == "" {
+			level = "Information"
+		}
+		events = append(events, windowsEvent{
+			time:     created,
+			provider: raw.System.Provider.Name,
+			level:    level,
+			message:  strings.Join(strings.Fields(raw.RenderingInfo.Message), " "),
+		})
+	}
+}
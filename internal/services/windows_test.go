@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+type fakeSCM struct {
+	services []windowsService
+	calls    []string
+}
+
+func (f *fakeSCM) ListServices(context.Context) ([]windowsService, error) {
+	return f.services, nil
+}
+
+func (f *fakeSCM) QueryService(_ context.Context, name string) (windowsService, error) {
+	for _, svc := range f.services {
+		if svc.Name == name {
+			return svc, nil
+		}
+	}
+	return windowsService{}, ErrServiceNotFound
+}
+
+func (f *fakeSCM) StartService(_ context.Context, name string) error {
+	f.calls = append(f.calls, "start "+name)
+	return nil
+}
+
+func (f *fakeSCM) StopService(_ context.Context, name string) error {
+	f.calls = append(f.calls, "stop "+name)
+	return nil
+}
+
+func (f *fakeSCM) SetStartType(_ context.Context, name, startType string) error {
+	f.calls = append(f.calls, "start-type "+name+" "+startType)
+	return nil
+}
+
+func newWindowsTestManager(scm *fakeSCM, custom []store.CustomService) *Manager {
+	m := newTestManager("windows", nil)
+	m.scm = scm
+	m.customServices = &stubCustomServicesRepo{services: custom}
+	return m
+}
+
+func TestWindowsServices(t *testing.T) {
+	t.Parallel()
+
+	scm := &fakeSCM{services: []windowsService{
+		{Name: "W3SVC", DisplayName: "World Wide Web Publishing Service", State: scmStateRunning, StartType: scmStartAuto, PID: 4120},
+		{Name: "Spooler", DisplayName: "Print Spooler", State: scmStateStopped, StartType: scmStartManual},
+		{Name: "MSSQL$EXPRESS", DisplayName: "SQL Server (EXPRESS)", State: scmStateRunning, StartType: scmStartDelayedAuto},
+	}}
+	m := newWindowsTestManager(scm, []store.CustomService{
+		{Name: "iis", DisplayName: "IIS", Manager: managerWindows, Unit: "W3SVC", Scope: scopeSystem},
+	})
+	ctx := context.Background()
+
+	services, err := m.ListServices(ctx)
+	if err != nil {
+		t.Fatalf("ListServices: %v", err)
+	}
+	if len(services) != 1 || !services[0].Exists || services[0].ActiveState != stateActive || services[0].EnabledState != "enabled" {
+		t.Fatalf("services = %+v, want iis active and enabled", services)
+	}
+
+	if _, err := m.Act(ctx, "iis", ActionRestart); err != nil {
+		t.Fatalf("Act(restart): %v", err)
+	}
+	if err := m.ActByUnit(ctx, "Spooler", scopeSystem, managerWindows, ActionDisable); err != nil {
+		t.Fatalf("ActByUnit(disable): %v", err)
+	}
+	if want := []string{"stop W3SVC", "start W3SVC", "start-type Spooler disabled"}; !slices.Equal(scm.calls, want) {
+		t.Fatalf("scm calls = %v, want %v", scm.calls, want)
+	}
+
+	inspect, err := m.Inspect(ctx, "iis")
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if inspect.Summary != "state=running start=auto" || inspect.Properties["PID"] != "4120" {
+		t.Fatalf("inspect = %+v", inspect)
+	}
+
+	// Names the unit check rejects are left out rather than offered.
+	available, err := m.DiscoverServices(ctx)
+	if err != nil {
+		t.Fatalf("DiscoverServices: %v", err)
+	}
+	if len(available) != 1 || available[0].Unit != "Spooler" || available[0].EnabledState != scmStartManual || available[0].Scope != scopeSystem {
+		t.Fatalf("available = %+v, want only Spooler", available)
+	}
+
+	browsed, err := m.BrowseServices(ctx)
+	if err != nil {
+		t.Fatalf("BrowseServices: %v", err)
+	}
+	if len(browsed) != 2 || !browsed[1].Tracked || browsed[1].TrackedName != "iis" || browsed[1].UnitType != unitTypeService {
+		t.Fatalf("browsed = %+v, want Spooler and the tracked W3SVC", browsed)
+	}
+}
+
+func TestWindowsLogs(t *testing.T) {
+	t.Parallel()
+
+	scm := &fakeSCM{services: []windowsService{
+		{Name: "W3SVC", DisplayName: "World Wide Web Publishing Service", State: scmStateRunning, StartType: scmStartAuto},
+	}}
+	m := newWindowsTestManager(scm, nil)
+	var queries [][]string
+	m.commandRunner = func(_ context.Context, name string, args ...string) (string, error) {
+		if name != "wevtutil" {
+			t.Fatalf("command = %s, want wevtutil", name)
+		}
+		queries = append(queries, args)
+		if args[1] == "System" {
+			return `<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event"><System>` +
+				`<Provider Name="Service Control Manager"/><TimeCreated SystemTime="2026-10-17T10:00:05.1234567Z"/></System>` +
+				`<RenderingInfo Culture="en-US"><Message>The World Wide Web Publishing Service service
+entered the running state.</Message><Level>Information</Level></RenderingInfo></Event>`, nil
+		}
+		return `<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event"><System>` +
+			`<Provider Name="W3SVC"/><TimeCreated SystemTime="2026-10-17T10:00:09Z"/></System>` +
+			`<RenderingInfo Culture="en-US"><Message>Worker process failed.</Message><Level>Error</Level></RenderingInfo></Event>` +
+			`<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event"><System>` +
+			`<Provider Name="W3SVC"/><TimeCreated SystemTime="2026-10-17T09:59:00Z"/></System>` +
+			`<RenderingInfo Culture="en-US"><Message>Starting.</Message></RenderingInfo></Event>`, nil
+	}
+
+	since := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	out, err := m.LogsByUnit(context.Background(), "W3SVC", scopeSystem, managerWindows, LogQuery{Lines: 2, Since: since, Priority: "info"})
+	if err != nil {
+		t.Fatalf("LogsByUnit: %v", err)
+	}
+	want := "2026-10-17T10:00:05Z Service Control Manager[Information]: The World Wide Web Publishing Service service entered the running state.\n" +
+		"2026-10-17T10:00:09Z W3SVC[Error]: Worker process failed."
+	if out != want {
+		t.Fatalf("logs =\n%s\nwant\n%s", out, want)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("wevtutil queries = %v, want Application and System", queries)
+	}
+	app := strings.Join(queries[0], " ")
+	if !strings.Contains(app, "qe Application /q:*[System[Provider[@Name='W3SVC'] and TimeCreated[@SystemTime>='2026-10-17T09:00:00Z'] and Level<=4]]") ||
+		!strings.Contains(app, "/c:2 /rd:true /f:RenderedXml") {
+		t.Fatalf("application query = %s", app)
+	}
+	if system := strings.Join(queries[1], " "); !strings.Contains(system, "and EventData[Data[@Name='param1']='World Wide Web Publishing Service']]") {
+		t.Fatalf("system query = %s", system)
+	}
+}
@@ -0,0 +1,135 @@
+// Package sshhost runs commands on a remote host through the system ssh
+// client. Commands share one multiplexed connection per host (OpenSSH
+// ControlMaster), so tmux and service managers can drive a host that has no
+// Sentinel agent installed without paying a handshake per command.
+package sshhost
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config describes a host reached over SSH with key authentication.
+type Config struct {
+	Name    string
+	Address string
+	Port    int
+	User    string
+	// IdentityFile is the private key to offer; empty uses the ssh defaults.
+	IdentityFile string
+	// KnownHostsFile replaces ~/.ssh/known_hosts for this host. Host keys
+	// are always checked strictly.
+	KnownHostsFile string
+}
+
+// Client runs commands on one host.
+type Client struct {
+	cfg         Config
+	controlPath string
+	command     func(ctx context.Context, name string, args ...string) *exec.Cmd
+
+	// mu serializes starting the master connection.
+	mu sync.Mutex
+}
+
+// New returns a client for cfg that keeps its control socket in
+// controlDir, which must exist and be private to the daemon user.
+func New(cfg Config, controlDir string) *Client {
+	return &Client{
+		cfg: cfg,
+		// %C hashes the connection parameters, keeping the socket path short
+		// enough for the unix socket limit.
+		controlPath: filepath.Join(controlDir, "%C"),
+		command:     exec.CommandContext,
+	}
+}
+
+// Command returns an unstarted ssh command running name with args on the
+// host over the shared connection, starting that connection if needed.
+// Arguments are quoted for the remote shell.
+func (c *Client) Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	c.connect(ctx)
+	words := make([]string, 0, len(args)+1)
+	words = append(words, Quote(name))
+	for _, arg := range args {
+		words = append(words, Quote(arg))
+	}
+	sshArgs := append(c.options(), "-T", "--", c.cfg.Address, strings.Join(words, " "))
+	return c.command(ctx, "ssh", sshArgs...)
+}
+
+// Run runs name with args on the host and returns its trimmed combined
+// output. Failures include that output, like a local command would.
+func (c *Client) Run(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := c.Command(ctx, name, args...).CombinedOutput()
+	msg := strings.TrimSpace(string(out))
+	if err != nil {
+		if msg == "" {
+			return "", fmt.Errorf("%s: %s %s failed: %w", c.cfg.Name, name, strings.Join(args, " "), err)
+		}
+		return "", fmt.Errorf("%s: %s %s failed: %s", c.cfg.Name, name, strings.Join(args, " "), msg)
+	}
+	return msg, nil
+}
+
+// Close stops the shared connection. It is harmless when none is running.
+func (c *Client) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = c.command(ctx, "ssh", append(c.options(), "-O", "exit", "--", c.cfg.Address)...).Run()
+}
+
+// connect starts the shared connection unless one is running. A failure is
+// only logged: the command then connects on its own and reports the error.
+func (c *Client) connect(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.command(ctx, "ssh", append(c.options(), "-O", "check", "--", c.cfg.Address)...).Run() == nil {
+		return
+	}
+	// -f backgrounds the master once it has authenticated. Its stdio is
+	// /dev/null, so no caller ends up waiting on its pipes.
+	master := c.command(ctx, "ssh", append(c.options(), "-M", "-N", "-f", "--", c.cfg.Address)...)
+	if err := master.Run(); err != nil {
+		slog.Warn("ssh connection failed", "host", c.cfg.Name, "address", c.cfg.Address, "err", err)
+	}
+}
+
+func (c *Client) options() []string {
+	opts := []string{
+		"-o", "BatchMode=yes",
+		"-o", "StrictHostKeyChecking=yes",
+		"-o", "ConnectTimeout=10",
+		"-o", "ServerAliveInterval=15",
+		"-o", "ServerAliveCountMax=3",
+		"-o", "ControlPath=" + c.controlPath,
+	}
+	if c.cfg.KnownHostsFile != "" {
+		opts = append(opts, "-o", "UserKnownHostsFile="+c.cfg.KnownHostsFile)
+	}
+	if c.cfg.IdentityFile != "" {
+		opts = append(opts, "-o", "IdentitiesOnly=yes", "-i", c.cfg.IdentityFile)
+	}
+	if c.cfg.Port > 0 {
+		opts = append(opts, "-p", strconv.Itoa(c.cfg.Port))
+	}
+	if c.cfg.User != "" {
+		opts = append(opts, "-l", c.cfg.User)
+	}
+	return opts
+}
+
+// Quote single-quotes s for a POSIX shell.
+func Quote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:@%+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
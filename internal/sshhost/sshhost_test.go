@@ -0,0 +1,137 @@
+package sshhost
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeSSH stands in for ssh. Control commands (-O, -M) succeed once a master
+// is up; remote commands run with sh, as the remote login shell would. The
+// returned slice records each ssh invocation's control flag.
+func fakeSSH(t *testing.T, c *Client) *[]string {
+	t.Helper()
+	var calls []string
+	masterUp := false
+	c.command = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if name != "ssh" {
+			t.Errorf("command = %q, want ssh", name)
+		}
+		switch {
+		case slices.Contains(args, "-O"):
+			op := args[slices.Index(args, "-O")+1]
+			calls = append(calls, op)
+			if op == "check" && !masterUp {
+				return exec.CommandContext(ctx, "false")
+			}
+			return exec.CommandContext(ctx, "true")
+		case slices.Contains(args, "-M"):
+			calls = append(calls, "master")
+			masterUp = true
+			return exec.CommandContext(ctx, "true")
+		default:
+			calls = append(calls, "run")
+			return exec.CommandContext(ctx, "sh", "-c", args[len(args)-1])
+		}
+	}
+	return &calls
+}
+
+func TestCommandOptions(t *testing.T) {
+	t.Parallel()
+
+	c := New(Config{
+		Name:           "db-01",
+		Address:        "db-01.internal",
+		Port:           2222,
+		User:           "deploy",
+		IdentityFile:   "/etc/sentinel/id_ed25519",
+		KnownHostsFile: "/etc/sentinel/known_hosts",
+	}, "/var/lib/sentinel/ssh")
+	c.command = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "true", args...)
+	}
+	args := c.Command(context.Background(), "tmux", "list-sessions").Args[1:]
+
+	for _, want := range [][]string{
+		{"-o", "BatchMode=yes"},
+		{"-o", "StrictHostKeyChecking=yes"},
+		{"-o", "ControlPath=/var/lib/sentinel/ssh/%C"},
+		{"-o", "UserKnownHostsFile=/etc/sentinel/known_hosts"},
+		{"-i", "/etc/sentinel/id_ed25519"},
+		{"-p", "2222"},
+		{"-l", "deploy"},
+		{"--", "db-01.internal", "tmux list-sessions"},
+	} {
+		if !containsRun(args, want) {
+			t.Errorf("ssh args %q missing %q", args, want)
+		}
+	}
+}
+
+func TestRunSharesOneMasterConnection(t *testing.T) {
+	t.Parallel()
+
+	c := New(Config{Name: "db-01", Address: "db-01.internal"}, t.TempDir())
+	calls := fakeSSH(t, c)
+
+	for range 2 {
+		if _, err := c.Run(context.Background(), "true"); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+	c.Close()
+	if want := []string{"check", "master", "run", "check", "run", "exit"}; !slices.Equal(*calls, want) {
+		t.Fatalf("ssh calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestRunQuotesArguments(t *testing.T) {
+	t.Parallel()
+
+	c := New(Config{Name: "db-01", Address: "db-01.internal"}, t.TempDir())
+	fakeSSH(t, c)
+
+	out, err := c.Run(context.Background(), "printf", "%s|", "two words", "it's", "#{session_name}\t$HOME", "")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := "two words|it's|#{session_name}\t$HOME||"; out != want {
+		t.Fatalf("Run() = %q, want %q", out, want)
+	}
+
+	_, err = c.Run(context.Background(), "sh", "-c", "echo unit not found >&2; exit 4")
+	if err == nil || !strings.Contains(err.Error(), "db-01") || !strings.Contains(err.Error(), "unit not found") {
+		t.Fatalf("Run(failing) error = %v", err)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"list-sessions":  "list-sessions",
+		"%3":             "%3",
+		"":               "''",
+		"a b":            "'a b'",
+		"it's":           `'it'\''s'`,
+		"$(reboot)":      "'$(reboot)'",
+		"nginx.service;": "'nginx.service;'",
+	}
+	for in, want := range tests {
+		if got := Quote(in); got != want {
+			t.Errorf("Quote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func containsRun(args, run []string) bool {
+	for i := range args {
+		if i+len(run) <= len(args) && slices.Equal(args[i:i+len(run)], run) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,327 @@
+// Package statuspage renders the read-only status page: the enabled uptime
+// checks and the tracked services, each with its daily history.
+package statuspage
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+// Component and day states.
+const (
+	StateUp       = "up"
+	StateDown     = "down"
+	StatePending  = "pending"
+	StateDegraded = "degraded"
+	StateNone     = "none"
+)
+
+// Overall page states.
+const (
+	OverallOperational = "operational"
+	OverallDegraded    = "degraded"
+	OverallOutage      = "outage"
+)
+
+const (
+	// DefaultHistoryDays is how many days of history bars are shown.
+	DefaultHistoryDays = 30
+	defaultTitle       = "Status"
+
+	// cacheTTL bounds how often a page view probes the services, since the
+	// page may be served without authentication.
+	cacheTTL  = 15 * time.Second
+	dayLayout = "2006-01-02"
+)
+
+//go:embed status.html
+var pageTemplateSource string
+
+var pageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"percent": formatPercent,
+}).Parse(pageTemplateSource))
+
+// serviceLister lists the tracked services with their current state.
+type serviceLister interface {
+	ListServices(ctx context.Context) ([]services.ServiceStatus, error)
+}
+
+// historyRepo reads the uptime checks and the daily status history.
+type historyRepo interface {
+	ListOpsUptimeChecks(ctx context.Context) ([]store.OpsUptimeCheck, error)
+	ListOpsStatusHistory(ctx context.Context, since time.Time) ([]store.OpsStatusDay, error)
+}
+
+// Authenticator authorizes status page requests. A nil Authenticator
+// serves the page to anyone.
+type Authenticator interface {
+	RequireAuth(r *http.Request) error
+}
+
+// Options configure a Page.
+type Options struct {
+	Title       string
+	HistoryDays int
+}
+
+// Day is one history bar.
+type Day struct {
+	Day   string `json:"day"`
+	State string `json:"state"`
+	// UptimePercent is the share of up samples; nil when there are none.
+	UptimePercent *float64 `json:"uptimePercent,omitempty"`
+}
+
+// Component is an uptime check or a tracked service. Targets and failure
+// details are left out: the page only tells whether something is up.
+type Component struct {
+	Name          string   `json:"name"`
+	Kind          string   `json:"kind"`
+	State         string   `json:"state"`
+	UptimePercent *float64 `json:"uptimePercent,omitempty"`
+	Days          []Day    `json:"days"`
+}
+
+// Status is the content of the status page.
+type Status struct {
+	Title       string      `json:"title"`
+	State       string      `json:"state"`
+	Components  []Component `json:"components"`
+	HistoryDays int         `json:"historyDays"`
+	GeneratedAt time.Time   `json:"generatedAt"`
+}
+
+// Page builds and serves the status page.
+type Page struct {
+	services serviceLister
+	repo     historyRepo
+	title    string
+	days     int
+	nowFn    func() time.Time
+
+	mu       sync.Mutex
+	cached   *Status
+	cachedAt time.Time
+}
+
+// New returns a Page reading services from svc and checks and history from
+// repo.
+func New(svc serviceLister, repo historyRepo, opts Options) *Page {
+	if opts.Title == "" {
+		opts.Title = defaultTitle
+	}
+	if opts.HistoryDays <= 0 {
+		opts.HistoryDays = DefaultHistoryDays
+	}
+	return &Page{
+		services: svc,
+		repo:     repo,
+		title:    opts.Title,
+		days:     opts.HistoryDays,
+		nowFn:    time.Now,
+	}
+}
+
+// Register serves the page as HTML at /status and as JSON at
+// /status.json. Requests must pass auth unless it is nil.
+func (p *Page) Register(mux *http.ServeMux, auth Authenticator) {
+	mux.HandleFunc("GET /status", p.guard(auth, p.serveHTML))
+	mux.HandleFunc("GET /status.json", p.guard(auth, p.serveJSON))
+}
+
+func (p *Page) guard(auth Authenticator, next http.HandlerFunc) http.HandlerFunc {
+	if auth == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := auth.RequireAuth(r); err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (p *Page) serveHTML(w http.ResponseWriter, r *http.Request) {
+	status, err := p.Status(r.Context())
+	if err != nil {
+		slog.Warn("status page unavailable", "err", err)
+		http.Error(w, "status unavailable", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := pageTemplate.Execute(w, status); err != nil {
+		slog.Warn("status page render failed", "err", err)
+	}
+}
+
+func (p *Page) serveJSON(w http.ResponseWriter, r *http.Request) {
+	status, err := p.Status(r.Context())
+	if err != nil {
+		slog.Warn("status page unavailable", "err", err)
+		http.Error(w, "status unavailable", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// Status returns the page content, rebuilt at most every cacheTTL.
+func (p *Page) Status(ctx context.Context) (Status, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.nowFn()
+	if p.cached != nil && now.Sub(p.cachedAt) < cacheTTL {
+		return *p.cached, nil
+	}
+	status, err := p.build(ctx, now)
+	if err != nil {
+		return Status{}, err
+	}
+	p.cached, p.cachedAt = &status, now
+	return status, nil
+}
+
+func (p *Page) build(ctx context.Context, now time.Time) (Status, error) {
+	checks, err := p.repo.ListOpsUptimeChecks(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	svcs, err := p.services.ListServices(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	days := historyDays(now, p.days)
+	history, err := p.repo.ListOpsStatusHistory(ctx, now.UTC().AddDate(0, 0, 1-p.days))
+	if err != nil {
+		return Status{}, err
+	}
+	byKey := make(map[[2]string]map[string]store.OpsStatusDay)
+	for _, day := range history {
+		key := [2]string{day.Kind, day.Name}
+		if byKey[key] == nil {
+			byKey[key] = make(map[string]store.OpsStatusDay)
+		}
+		byKey[key][day.Day] = day
+	}
+
+	status := Status{
+		Title:       p.title,
+		Components:  make([]Component, 0, len(checks)+len(svcs)),
+		HistoryDays: p.days,
+		GeneratedAt: now.UTC(),
+	}
+	for _, check := range checks {
+		if !check.Enabled {
+			continue
+		}
+		state := StatePending
+		switch check.Status {
+		case store.UptimeStatusUp:
+			state = StateUp
+		case store.UptimeStatusDown:
+			state = StateDown
+		}
+		status.Components = append(status.Components,
+			component(check.Name, store.StatusHistoryUptime, state, days, byKey[[2]string{store.StatusHistoryUptime, check.ID}]))
+	}
+	for _, svc := range svcs {
+		name := svc.DisplayName
+		if name == "" {
+			name = svc.Name
+		}
+		state := StateDown
+		if ServiceUp(svc) {
+			state = StateUp
+		}
+		status.Components = append(status.Components,
+			component(name, store.StatusHistoryService, state, days, byKey[[2]string{store.StatusHistoryService, svc.Name}]))
+	}
+	status.State = overallState(status.Components)
+	return status, nil
+}
+
+// ServiceUp reports whether a tracked service counts as up: active and not
+// failing its health checks.
+func ServiceUp(svc services.ServiceStatus) bool {
+	return svc.ActiveState == "active" && (svc.Health == nil || svc.Health.Healthy)
+}
+
+func component(name, kind, state string, days []string, history map[string]store.OpsStatusDay) Component {
+	c := Component{Name: name, Kind: kind, State: state, Days: make([]Day, 0, len(days))}
+	var up, total int64
+	for _, day := range days {
+		sample := history[day]
+		bar := Day{Day: day, State: StateNone}
+		if n := sample.Up + sample.Down; n > 0 {
+			bar.UptimePercent = ratio(sample.Up, n)
+			switch {
+			case sample.Down == 0:
+				bar.State = StateUp
+			case sample.Up == 0:
+				bar.State = StateDown
+			default:
+				bar.State = StateDegraded
+			}
+			up += sample.Up
+			total += n
+		}
+		c.Days = append(c.Days, bar)
+	}
+	if total > 0 {
+		c.UptimePercent = ratio(up, total)
+	}
+	return c
+}
+
+func overallState(components []Component) string {
+	down := 0
+	for _, c := range components {
+		if c.State == StateDown {
+			down++
+		}
+	}
+	switch {
+	case down == 0:
+		return OverallOperational
+	case down == len(components):
+		return OverallOutage
+	default:
+		return OverallDegraded
+	}
+}
+
+// historyDays lists the UTC days of the history window, oldest first and
+// ending today.
+func historyDays(now time.Time, n int) []string {
+	today := now.UTC()
+	days := make([]string, n)
+	for i := range n {
+		days[i] = today.AddDate(0, 0, i-n+1).Format(dayLayout)
+	}
+	return days
+}
+
+func ratio(part, total int64) *float64 {
+	percent := float64(part) * 100 / float64(total)
+	return &percent
+}
+
+func formatPercent(percent *float64) string {
+	if percent == nil {
+		return "no data"
+	}
+	return strconv.FormatFloat(*percent, 'f', 2, 64) + "%"
+}
@@ -0,0 +1,120 @@
+package statuspage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/services"
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+type fakeServices struct {
+	services []services.ServiceStatus
+	calls    int
+}
+
+func (f *fakeServices) ListServices(context.Context) ([]services.ServiceStatus, error) {
+	f.calls++
+	return f.services, nil
+}
+
+type fakeRepo struct {
+	checks  []store.OpsUptimeCheck
+	history []store.OpsStatusDay
+}
+
+func (f *fakeRepo) ListOpsUptimeChecks(context.Context) ([]store.OpsUptimeCheck, error) {
+	return f.checks, nil
+}
+
+func (f *fakeRepo) ListOpsStatusHistory(_ context.Context, since time.Time) ([]store.OpsStatusDay, error) {
+	var out []store.OpsStatusDay
+	for _, day := range f.history {
+		if day.Day >= since.Format(dayLayout) {
+			out = append(out, day)
+		}
+	}
+	return out, nil
+}
+
+type denyAll struct{}
+
+func (denyAll) RequireAuth(*http.Request) error { return errors.New("unauthorized") }
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	svc := &fakeServices{services: []services.ServiceStatus{
+		{Name: "nginx", DisplayName: "Web", ActiveState: "active"},
+		{Name: "app", ActiveState: "active", Health: &services.ServiceHealth{Healthy: false}},
+	}}
+	repo := &fakeRepo{
+		checks: []store.OpsUptimeCheck{
+			{ID: "c1", Name: "api", Enabled: true, Status: store.UptimeStatusUp, Target: "https://internal.example/health"},
+			{ID: "c2", Name: "off", Status: store.UptimeStatusDown},
+		},
+		history: []store.OpsStatusDay{
+			{Kind: store.StatusHistoryUptime, Name: "c1", Day: "2026-02-10", Up: 10},
+			{Kind: store.StatusHistoryUptime, Name: "c1", Day: "2026-02-13", Up: 3, Down: 1},
+			{Kind: store.StatusHistoryUptime, Name: "c1", Day: "2026-02-15", Down: 2},
+			{Kind: store.StatusHistoryService, Name: "nginx", Day: "2026-02-15", Up: 5},
+		},
+	}
+	page := New(svc, repo, Options{Title: "Homelab", HistoryDays: 3})
+	page.nowFn = func() time.Time { return now }
+
+	got, err := page.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if got.Title != "Homelab" || got.State != OverallDegraded || len(got.Components) != 3 {
+		t.Fatalf("status = %+v", got)
+	}
+	api := got.Components[0]
+	if api.Name != "api" || api.State != StateUp || len(api.Days) != 3 || *api.UptimePercent != 50 {
+		t.Fatalf("api component = %+v", api)
+	}
+	if api.Days[0].Day != "2026-02-13" || api.Days[0].State != StateDegraded || api.Days[1].State != StateNone || api.Days[2].State != StateDown {
+		t.Fatalf("api days = %+v", api.Days)
+	}
+	if web := got.Components[1]; web.Name != "Web" || web.State != StateUp || web.Days[2].State != StateUp {
+		t.Fatalf("web component = %+v", web)
+	}
+	if app := got.Components[2]; app.State != StateDown || app.UptimePercent != nil {
+		t.Fatalf("app component = %+v", app)
+	}
+
+	// Views within the cache TTL reuse the last build.
+	if _, err := page.Status(context.Background()); err != nil || svc.calls != 1 {
+		t.Fatalf("cached Status: calls = %d, err = %v", svc.calls, err)
+	}
+
+	mux := http.NewServeMux()
+	page.Register(mux, nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Partial outage") || strings.Contains(w.Body.String(), "internal.example") {
+		t.Fatalf("GET /status = %d\n%s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status.json", nil))
+	var decoded Status
+	if err := json.Unmarshal(w.Body.Bytes(), &decoded); err != nil || decoded.State != OverallDegraded {
+		t.Fatalf("GET /status.json = %d %s (%v)", w.Code, w.Body.String(), err)
+	}
+
+	private := http.NewServeMux()
+	page.Register(private, denyAll{})
+	w = httptest.NewRecorder()
+	private.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("GET /status without auth = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyTokenPrefix  = "snk_"
+	apiKeyDisplayChars = 8
+	// apiKeyTouchInterval bounds how often last_used_at is rewritten so
+	// authenticated polling does not turn every read into a write.
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKey represents a named API key. The token itself is never stored.
+type APIKey struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Role        string    `json:"role"`
+	TokenPrefix string    `json:"tokenPrefix"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastUsedAt  time.Time `json:"lastUsedAt"`
+}
+
+// APIKeyWrite represents API key write data.
+type APIKeyWrite struct {
+	Name string
+	Role string
+}
+
+// ListAPIKeys lists API keys ordered by name.
+func (s *Store) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, name, role, token_prefix, created_at, last_used_at
+		   FROM api_keys
+		  ORDER BY name COLLATE NOCASE ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]APIKey, 0, 4)
+	for rows.Next() {
+		var (
+			row                     APIKey
+			createdAtRaw, usedAtRaw string
+		)
+		if err := rows.Scan(&row.ID, &row.Name, &row.Role, &row.TokenPrefix, &createdAtRaw, &usedAtRaw); err != nil {
+			return nil, err
+		}
+		row.CreatedAt = parseStoreTime(createdAtRaw)
+		row.LastUsedAt = parseStoreTime(usedAtRaw)
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// CreateAPIKey creates a named key and returns it together with the
+// plaintext token, which cannot be recovered later.
+func (s *Store) CreateAPIKey(ctx context.Context, w APIKeyWrite) (APIKey, string, error) {
+	name := strings.TrimSpace(w.Name)
+	if name == "" {
+		return APIKey{}, "", errors.New("api key name is required")
+	}
+	role := strings.ToLower(strings.TrimSpace(w.Role))
+	if role == "" {
+		return APIKey{}, "", errors.New("api key role is required")
+	}
+
+	token, err := newAPIKeyToken()
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	row := APIKey{
+		ID:          randomID(),
+		Name:        name,
+		Role:        role,
+		TokenPrefix: token[:len(apiKeyTokenPrefix)+apiKeyDisplayChars],
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO api_keys (id, name, role, token_hash, token_prefix, created_at, last_used_at)
+		 VALUES (?, ?, ?, ?, ?, ?, '')`,
+		row.ID, row.Name, row.Role, hashAPIKeyToken(token), row.TokenPrefix,
+		row.CreatedAt.Format(time.RFC3339),
+	); err != nil {
+		return APIKey{}, "", err
+	}
+	return row, token, nil
+}
+
+// DeleteAPIKey revokes an API key.
+func (s *Store) DeleteAPIKey(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return errors.New("api key id is required")
+	}
+	result, err := s.db.ExecContext(ctx, `DELETE FROM api_keys WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// AuthenticateAPIKey resolves a plaintext token to its key and records the
+// use. It returns sql.ErrNoRows when the token is unknown.
+func (s *Store) AuthenticateAPIKey(ctx context.Context, token string) (APIKey, error) {
+	token = strings.TrimSpace(token)
+	if !strings.HasPrefix(token, apiKeyTokenPrefix) {
+		return APIKey{}, sql.ErrNoRows
+	}
+	var (
+		row                     APIKey
+		createdAtRaw, usedAtRaw string
+	)
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, name, role, token_prefix, created_at, last_used_at
+		   FROM api_keys
+		  WHERE token_hash = ?`,
+		hashAPIKeyToken(token),
+	).Scan(&row.ID, &row.Name, &row.Role, &row.TokenPrefix, &createdAtRaw, &usedAtRaw)
+	if err != nil {
+		return APIKey{}, err
+	}
+	row.CreatedAt = parseStoreTime(createdAtRaw)
+	row.LastUsedAt = parseStoreTime(usedAtRaw)
+
+	now := time.Now().UTC()
+	if now.Sub(row.LastUsedAt) >= apiKeyTouchInterval {
+		if _, err := s.db.ExecContext(ctx,
+			`UPDATE api_keys SET last_used_at = ? WHERE id = ?`,
+			now.Format(time.RFC3339), row.ID,
+		); err != nil {
+			return APIKey{}, err
+		}
+		row.LastUsedAt = now.Truncate(time.Second)
+	}
+	return row, nil
+}
+
+func newAPIKeyToken() (string, error) {
+	var raw [24]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", fmt.Errorf("generate api key: %w", err)
+	}
+	return apiKeyTokenPrefix + base64.RawURLEncoding.EncodeToString(raw[:]), nil
+}
+
+func hashAPIKeyToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
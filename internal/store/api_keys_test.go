@@ -0,0 +1,91 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAPIKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("create authenticate list delete", func(t *testing.T) {
+		t.Parallel()
+
+		s := newTestStore(t)
+		ctx := context.Background()
+
+		created, token, err := s.CreateAPIKey(ctx, APIKeyWrite{Name: " dashboard ", Role: "Viewer"})
+		if err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+		if created.ID == "" || created.Name != "dashboard" || created.Role != "viewer" {
+			t.Fatalf("created key = %#v", created)
+		}
+		if !strings.HasPrefix(token, apiKeyTokenPrefix) || !strings.HasPrefix(token, created.TokenPrefix) {
+			t.Fatalf("token %q does not start with prefix %q", token, created.TokenPrefix)
+		}
+
+		var storedHash string
+		if err := s.db.QueryRowContext(ctx, "SELECT token_hash FROM api_keys WHERE id = ?", created.ID).Scan(&storedHash); err != nil {
+			t.Fatalf("query token_hash: %v", err)
+		}
+		if storedHash == token || strings.Contains(storedHash, token) {
+			t.Fatal("plaintext token must not be stored")
+		}
+
+		resolved, err := s.AuthenticateAPIKey(ctx, token)
+		if err != nil {
+			t.Fatalf("AuthenticateAPIKey() error = %v", err)
+		}
+		if resolved.ID != created.ID || resolved.LastUsedAt.IsZero() {
+			t.Fatalf("resolved key = %#v", resolved)
+		}
+
+		keys, err := s.ListAPIKeys(ctx)
+		if err != nil {
+			t.Fatalf("ListAPIKeys() error = %v", err)
+		}
+		if len(keys) != 1 || keys[0].LastUsedAt.IsZero() {
+			t.Fatalf("keys = %#v", keys)
+		}
+
+		if err := s.DeleteAPIKey(ctx, created.ID); err != nil {
+			t.Fatalf("DeleteAPIKey() error = %v", err)
+		}
+		if _, err := s.AuthenticateAPIKey(ctx, token); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("AuthenticateAPIKey() after delete error = %v, want sql.ErrNoRows", err)
+		}
+		if err := s.DeleteAPIKey(ctx, created.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("DeleteAPIKey() twice error = %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("validation and uniqueness", func(t *testing.T) {
+		t.Parallel()
+
+		s := newTestStore(t)
+		ctx := context.Background()
+
+		if _, _, err := s.CreateAPIKey(ctx, APIKeyWrite{Role: "viewer"}); err == nil {
+			t.Fatal("expected error for missing name")
+		}
+		if _, _, err := s.CreateAPIKey(ctx, APIKeyWrite{Name: "ci"}); err == nil {
+			t.Fatal("expected error for missing role")
+		}
+		if _, _, err := s.CreateAPIKey(ctx, APIKeyWrite{Name: "ci", Role: "operator"}); err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+		if _, _, err := s.CreateAPIKey(ctx, APIKeyWrite{Name: "CI", Role: "admin"}); err == nil {
+			t.Fatal("expected unique constraint error for duplicate name")
+		}
+		if _, err := s.AuthenticateAPIKey(ctx, "not-a-key"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("AuthenticateAPIKey(garbage) error = %v, want sql.ErrNoRows", err)
+		}
+		if err := s.DeleteAPIKey(ctx, " "); err == nil {
+			t.Fatal("expected error for empty id")
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupPrefix     = "sentinel-"
+	backupSuffix     = ".db"
+	backupTimeLayout = "20060102T150405Z"
+
+	// pendingRestoreFile names the backup to restore on the next startup.
+	pendingRestoreFile = "restore-pending"
+)
+
+var (
+	// ErrBackupNotFound is returned when a named backup does not exist.
+	ErrBackupNotFound = errors.New("backup not found")
+	// ErrInvalidBackupName is returned for names that are not backup files
+	// directly inside the backup directory.
+	ErrInvalidBackupName = errors.New("invalid backup name")
+)
+
+// BackupInfo describes a database snapshot in the backup directory.
+type BackupInfo struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	SizeBytes int64     `json:"sizeBytes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Backup writes a consistent snapshot of the database into dir with
+// VACUUM INTO, then deletes the oldest snapshots beyond keep. A keep of
+// zero or less disables rotation.
+func (s *Store) Backup(ctx context.Context, dir string, keep int, now time.Time) (BackupInfo, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return BackupInfo{}, fmt.Errorf("create backup dir: %w", err)
+	}
+	createdAt := now.UTC().Truncate(time.Second)
+	name := backupPrefix + createdAt.Format(backupTimeLayout) + backupSuffix
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return BackupInfo{}, fmt.Errorf("backup %s already exists", name)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return BackupInfo{}, fmt.Errorf("vacuum into %s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return BackupInfo{}, fmt.Errorf("stat backup: %w", err)
+	}
+	if keep > 0 {
+		if err := rotateBackups(dir, keep); err != nil {
+			return BackupInfo{}, err
+		}
+	}
+	return BackupInfo{Name: name, Path: path, SizeBytes: info.Size(), CreatedAt: createdAt}, nil
+}
+
+// ListBackups returns the snapshots in dir, newest first. A missing
+// directory has no backups.
+func ListBackups(dir string) ([]BackupInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read backup dir: %w", err)
+	}
+	backups := make([]BackupInfo, 0, len(entries))
+	for _, entry := range entries {
+		createdAt, ok := parseBackupName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupInfo{
+			Name:      entry.Name(),
+			Path:      filepath.Join(dir, entry.Name()),
+			SizeBytes: info.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// StageRestore marks a backup to replace the database the next time it is
+// opened through ApplyPendingRestore.
+func StageRestore(dir, name string) (BackupInfo, error) {
+	backup, err := findBackup(dir, name)
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, pendingRestoreFile), []byte(backup.Name+"\n"), 0o600); err != nil {
+		return BackupInfo{}, fmt.Errorf("stage restore: %w", err)
+	}
+	return backup, nil
+}
+
+// ApplyPendingRestore replaces the database at dbPath with the backup staged
+// by StageRestore, if any, and clears the marker. It must run before the
+// database is opened. The restored backup name is returned, or "" when no
+// restore was pending.
+func ApplyPendingRestore(dbPath, dir string) (string, error) {
+	marker := filepath.Join(dir, pendingRestoreFile)
+	raw, err := os.ReadFile(marker) //nolint:gosec // marker lives in the configured backup dir.
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read restore marker: %w", err)
+	}
+	name := strings.TrimSpace(string(raw))
+	if err := RestoreBackup(dbPath, dir, name); err != nil {
+		return "", err
+	}
+	if err := os.Remove(marker); err != nil {
+		return name, fmt.Errorf("clear restore marker: %w", err)
+	}
+	return name, nil
+}
+
+// RestoreBackup copies a named backup over the database at dbPath and drops
+// its WAL sidecars. The database must not be open.
+func RestoreBackup(dbPath, dir, name string) error {
+	backup, err := findBackup(dir, name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o700); err != nil {
+		return fmt.Errorf("create data dir: %w", err)
+	}
+
+	// Copy next to the target first so a failed copy leaves the live
+	// database untouched.
+	tmp := dbPath + ".restore"
+	if err := copyFile(backup.Path, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("copy backup: %w", err)
+	}
+	for _, sidecar := range []string{dbPath + "-wal", dbPath + "-shm", dbPath + "-journal"} {
+		if err := os.Remove(sidecar); err != nil && !errors.Is(err, os.ErrNotExist) {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("remove %s: %w", sidecar, err)
+		}
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("replace database: %w", err)
+	}
+	return nil
+}
+
+func findBackup(dir, name string) (BackupInfo, error) {
+	name = strings.TrimSpace(name)
+	createdAt, ok := parseBackupName(name)
+	if !ok || filepath.Base(name) != name {
+		return BackupInfo{}, ErrInvalidBackupName
+	}
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return BackupInfo{}, ErrBackupNotFound
+		}
+		return BackupInfo{}, err
+	}
+	return BackupInfo{Name: name, Path: path, SizeBytes: info.Size(), CreatedAt: createdAt}, nil
+}
+
+func parseBackupName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix)
+	createdAt, err := time.Parse(backupTimeLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
+
+func rotateBackups(dir string, keep int) error {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rotate backups: %w", err)
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) //nolint:gosec // src is a validated backup path.
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600) //nolint:gosec // dst is derived from the configured database path.
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
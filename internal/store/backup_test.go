@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupRotatesAndRestores(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "sentinel.db")
+	backupDir := filepath.Join(dir, "backups")
+	ctx := context.Background()
+
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := s.UpsertSession(ctx, "before", "hash", "content"); err != nil {
+		t.Fatalf("UpsertSession: %v", err)
+	}
+
+	base := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	var first BackupInfo
+	for i := range 3 {
+		info, err := s.Backup(ctx, backupDir, 2, base.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatalf("Backup #%d: %v", i+1, err)
+		}
+		if i == 0 {
+			first = info
+		}
+	}
+	if first.Name != "sentinel-20260401T120000Z.db" {
+		t.Fatalf("first backup name = %q", first.Name)
+	}
+
+	backups, err := ListBackups(backupDir)
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != 2 || backups[0].Name != "sentinel-20260401T140000Z.db" || backups[1].Name != "sentinel-20260401T130000Z.db" {
+		t.Fatalf("backups after rotation = %+v", backups)
+	}
+
+	if err := s.UpsertSession(ctx, "after", "hash", "content"); err != nil {
+		t.Fatalf("UpsertSession: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := StageRestore(backupDir, first.Name); !errors.Is(err, ErrBackupNotFound) {
+		t.Fatalf("StageRestore(rotated) error = %v, want ErrBackupNotFound", err)
+	}
+	if _, err := StageRestore(backupDir, "../sentinel.db"); !errors.Is(err, ErrInvalidBackupName) {
+		t.Fatalf("StageRestore(traversal) error = %v, want ErrInvalidBackupName", err)
+	}
+	if _, err := StageRestore(backupDir, backups[1].Name); err != nil {
+		t.Fatalf("StageRestore: %v", err)
+	}
+
+	restored, err := ApplyPendingRestore(dbPath, backupDir)
+	if err != nil {
+		t.Fatalf("ApplyPendingRestore: %v", err)
+	}
+	if restored != backups[1].Name {
+		t.Fatalf("restored = %q, want %q", restored, backups[1].Name)
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, pendingRestoreFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("restore marker still present: %v", err)
+	}
+	if again, err := ApplyPendingRestore(dbPath, backupDir); err != nil || again != "" {
+		t.Fatalf("second ApplyPendingRestore = %q, %v; want no-op", again, err)
+	}
+
+	s, err = New(dbPath)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	sessions, err := s.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if _, ok := sessions["before"]; !ok {
+		t.Fatalf("restored sessions missing %q: %v", "before", sessions)
+	}
+	if _, ok := sessions["after"]; ok {
+		t.Fatalf("restored sessions contain post-backup %q: %v", "after", sessions)
+	}
+}
+
+func TestListBackupsMissingDir(t *testing.T) {
+	t.Parallel()
+
+	backups, err := ListBackups(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(backups) != 0 {
+		t.Fatalf("ListBackups(missing) = %v, %v", backups, err)
+	}
+}
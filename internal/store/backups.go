@@ -0,0 +1,282 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Backup statuses.
+const (
+	BackupStatusNew       = "new"
+	BackupStatusRunning   = "running"
+	BackupStatusSucceeded = "succeeded"
+	BackupStatusFailed    = "failed"
+)
+
+// OpsBackup is a restic or borg backup of Sources into Repository, run on
+// Schedule through a managed runbook, with the outcome of its latest run.
+type OpsBackup struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Tool is "restic" or "borg". Command, when set, replaces the tool's
+	// default command template.
+	Tool       string   `json:"tool"`
+	Repository string   `json:"repository"`
+	Sources    []string `json:"sources"`
+	Command    string   `json:"command"`
+	// Schedule is a cron expression evaluated in Timezone.
+	Schedule       string    `json:"schedule"`
+	Timezone       string    `json:"timezone"`
+	MaxAgeHours    int       `json:"maxAgeHours"`
+	TimeoutMinutes int       `json:"timeoutMinutes"`
+	Enabled        bool      `json:"enabled"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+
+	Status string `json:"status"`
+	// Stale is set when no run succeeded within MaxAgeHours.
+	Stale          bool           `json:"stale"`
+	LastJobID      string         `json:"lastJobId"`
+	LastRunAt      time.Time      `json:"lastRunAt"`
+	LastFinishedAt time.Time      `json:"lastFinishedAt"`
+	LastSuccessAt  time.Time      `json:"lastSuccessAt"`
+	LastError      string         `json:"lastError,omitempty"`
+	Stats          OpsBackupStats `json:"stats"`
+}
+
+// OpsBackupStats are the figures reported by a successful backup run.
+// Fields the tool does not report stay zero.
+type OpsBackupStats struct {
+	SnapshotID      string  `json:"snapshotId,omitempty"`
+	Files           int64   `json:"files"`
+	FilesNew        int64   `json:"filesNew"`
+	FilesChanged    int64   `json:"filesChanged"`
+	BytesProcessed  int64   `json:"bytesProcessed"`
+	BytesAdded      int64   `json:"bytesAdded"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// OpsBackupWrite represents backup write data.
+type OpsBackupWrite struct {
+	ID             string
+	Name           string
+	Tool           string
+	Repository     string
+	Sources        []string
+	Command        string
+	Schedule       string
+	Timezone       string
+	MaxAgeHours    int
+	TimeoutMinutes int
+	Enabled        bool
+}
+
+// OpsBackupResult is the outcome of one backup run.
+type OpsBackupResult struct {
+	JobID      string
+	Status     string
+	Error      string
+	Stats      OpsBackupStats
+	FinishedAt time.Time
+}
+
+const opsBackupColumns = `id, name, tool, repository, sources, command, schedule, timezone,
+	max_age_hours, timeout_minutes, enabled, created_at, updated_at,
+	status, stale, last_job_id, last_run_at, last_finished_at, last_success_at, last_error, stats`
+
+// ListOpsBackups lists backups ordered by name.
+func (s *Store) ListOpsBackups(ctx context.Context) ([]OpsBackup, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+opsBackupColumns+`
+		   FROM ops_backups
+		  ORDER BY name COLLATE NOCASE ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]OpsBackup, 0, 8)
+	for rows.Next() {
+		row, err := scanOpsBackup(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// GetOpsBackup returns a backup.
+func (s *Store) GetOpsBackup(ctx context.Context, id string) (OpsBackup, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return OpsBackup{}, sql.ErrNoRows
+	}
+	return scanOpsBackup(s.db.QueryRowContext(ctx,
+		`SELECT `+opsBackupColumns+` FROM ops_backups WHERE id = ?`, id,
+	))
+}
+
+// CreateOpsBackup creates a backup that has not run yet.
+func (s *Store) CreateOpsBackup(ctx context.Context, w OpsBackupWrite) (OpsBackup, error) {
+	name := strings.TrimSpace(w.Name)
+	if name == "" {
+		return OpsBackup{}, errors.New("backup name is required")
+	}
+	sources, err := json.Marshal(nonNilStrings(w.Sources))
+	if err != nil {
+		return OpsBackup{}, err
+	}
+	id := strings.TrimSpace(w.ID)
+	if id == "" {
+		id = randomID()
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO ops_backups (id, name, tool, repository, sources, command, schedule, timezone,
+		 max_age_hours, timeout_minutes, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, name, w.Tool, w.Repository, string(sources), w.Command, w.Schedule, w.Timezone,
+		w.MaxAgeHours, w.TimeoutMinutes, boolToInt(w.Enabled), now, now,
+	); err != nil {
+		return OpsBackup{}, err
+	}
+	return s.GetOpsBackup(ctx, id)
+}
+
+// UpdateOpsBackup updates a backup's definition; its run history is kept.
+func (s *Store) UpdateOpsBackup(ctx context.Context, w OpsBackupWrite) (OpsBackup, error) {
+	name := strings.TrimSpace(w.Name)
+	if name == "" {
+		return OpsBackup{}, errors.New("backup name is required")
+	}
+	sources, err := json.Marshal(nonNilStrings(w.Sources))
+	if err != nil {
+		return OpsBackup{}, err
+	}
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE ops_backups SET name = ?, tool = ?, repository = ?, sources = ?, command = ?,
+		 schedule = ?, timezone = ?, max_age_hours = ?, timeout_minutes = ?, enabled = ?, updated_at = ?
+		 WHERE id = ?`,
+		name, w.Tool, w.Repository, string(sources), w.Command,
+		w.Schedule, w.Timezone, w.MaxAgeHours, w.TimeoutMinutes, boolToInt(w.Enabled),
+		time.Now().UTC().Format(time.RFC3339), strings.TrimSpace(w.ID),
+	)
+	if err != nil {
+		return OpsBackup{}, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return OpsBackup{}, sql.ErrNoRows
+	}
+	return s.GetOpsBackup(ctx, w.ID)
+}
+
+// DeleteOpsBackup removes a backup. The runs it started are kept.
+func (s *Store) DeleteOpsBackup(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM ops_backups WHERE id = ?`, strings.TrimSpace(id))
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkOpsBackupStarted records that the backup started the run jobID at
+// at. An empty jobID records an attempt that could not start.
+func (s *Store) MarkOpsBackupStarted(ctx context.Context, id, jobID string, at time.Time) error {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE ops_backups SET status = ?, last_job_id = ?, last_run_at = ? WHERE id = ?`,
+		BackupStatusRunning, jobID, at.UTC().Format(time.RFC3339), strings.TrimSpace(id),
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RecordOpsBackupResult stores the outcome of the backup's run r.JobID. A
+// success also records its stats and clears the stale flag. It reports
+// false when the backup has started another run since.
+func (s *Store) RecordOpsBackupResult(ctx context.Context, id string, r OpsBackupResult) (bool, error) {
+	stats, err := json.Marshal(r.Stats)
+	if err != nil {
+		return false, err
+	}
+	finished := r.FinishedAt.UTC().Format(time.RFC3339)
+	succeeded := boolToInt(r.Status == BackupStatusSucceeded)
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE ops_backups SET status = ?, last_finished_at = ?, last_error = ?,
+		 last_success_at = CASE WHEN ? THEN ? ELSE last_success_at END,
+		 stats = CASE WHEN ? THEN ? ELSE stats END,
+		 stale = CASE WHEN ? THEN 0 ELSE stale END
+		 WHERE id = ? AND status = ? AND last_job_id = ?`,
+		r.Status, finished, r.Error,
+		succeeded, finished,
+		succeeded, string(stats),
+		succeeded,
+		strings.TrimSpace(id), BackupStatusRunning, r.JobID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// SetOpsBackupStale sets the backup's stale flag and reports whether it
+// changed.
+func (s *Store) SetOpsBackupStale(ctx context.Context, id string, stale bool) (bool, error) {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE ops_backups SET stale = ? WHERE id = ? AND stale != ?`,
+		boolToInt(stale), strings.TrimSpace(id), boolToInt(stale),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func scanOpsBackup(row interface{ Scan(...any) error }) (OpsBackup, error) {
+	var (
+		b                                     OpsBackup
+		enabled, stale                        int
+		sourcesRaw, statsRaw                  string
+		createdAtRaw, updatedAtRaw            string
+		runAtRaw, finishedAtRaw, successAtRaw string
+	)
+	if err := row.Scan(&b.ID, &b.Name, &b.Tool, &b.Repository, &sourcesRaw, &b.Command, &b.Schedule, &b.Timezone,
+		&b.MaxAgeHours, &b.TimeoutMinutes, &enabled, &createdAtRaw, &updatedAtRaw,
+		&b.Status, &stale, &b.LastJobID, &runAtRaw, &finishedAtRaw, &successAtRaw, &b.LastError, &statsRaw); err != nil {
+		return OpsBackup{}, err
+	}
+	if err := json.Unmarshal([]byte(sourcesRaw), &b.Sources); err != nil || b.Sources == nil {
+		b.Sources = []string{}
+	}
+	_ = json.Unmarshal([]byte(statsRaw), &b.Stats)
+	b.Enabled = enabled == 1
+	b.Stale = stale == 1
+	b.CreatedAt = parseStoreTime(createdAtRaw)
+	b.UpdatedAt = parseStoreTime(updatedAtRaw)
+	b.LastRunAt = parseStoreTime(runAtRaw)
+	b.LastFinishedAt = parseStoreTime(finishedAtRaw)
+	b.LastSuccessAt = parseStoreTime(successAtRaw)
+	return b, nil
+}
+
+func nonNilStrings(in []string) []string {
+	if in == nil {
+		return []string{}
+	}
+	return in
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOpsBackups(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	write := OpsBackupWrite{
+		Name: " home ", Tool: "restic", Repository: "/srv/restic", Sources: []string{"/home", "/etc"},
+		Schedule: "0 3 * * *", Timezone: "UTC", MaxAgeHours: 48, TimeoutMinutes: 360, Enabled: true,
+	}
+	created, err := s.CreateOpsBackup(ctx, write)
+	if err != nil {
+		t.Fatalf("CreateOpsBackup() error = %v", err)
+	}
+	if len(created.ID) != 32 || created.Name != "home" || created.Status != BackupStatusNew ||
+		len(created.Sources) != 2 || created.Sources[1] != "/etc" || !created.LastRunAt.IsZero() {
+		t.Fatalf("created backup = %#v", created)
+	}
+	if _, err := s.CreateOpsBackup(ctx, write); err == nil {
+		t.Fatal("CreateOpsBackup() accepted a duplicate name")
+	}
+
+	started := time.Date(2026, 2, 15, 3, 0, 0, 0, time.UTC)
+	if err := s.MarkOpsBackupStarted(ctx, created.ID, "job-1", started); err != nil {
+		t.Fatalf("MarkOpsBackupStarted() error = %v", err)
+	}
+	if _, err := s.SetOpsBackupStale(ctx, created.ID, true); err != nil {
+		t.Fatalf("SetOpsBackupStale() error = %v", err)
+	}
+
+	// A result for a run the backup no longer tracks is ignored.
+	if ok, err := s.RecordOpsBackupResult(ctx, created.ID, OpsBackupResult{JobID: "job-0", Status: BackupStatusFailed}); err != nil || ok {
+		t.Fatalf("RecordOpsBackupResult(old job) = %v, %v; want false", ok, err)
+	}
+	finished := started.Add(10 * time.Minute)
+	ok, err := s.RecordOpsBackupResult(ctx, created.ID, OpsBackupResult{
+		JobID: "job-1", Status: BackupStatusSucceeded, FinishedAt: finished,
+		Stats: OpsBackupStats{SnapshotID: "abc123", Files: 10, BytesAdded: 2048},
+	})
+	if err != nil || !ok {
+		t.Fatalf("RecordOpsBackupResult() = %v, %v", ok, err)
+	}
+	got, err := s.GetOpsBackup(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetOpsBackup() error = %v", err)
+	}
+	if got.Status != BackupStatusSucceeded || got.Stale || got.LastJobID != "job-1" || !got.LastRunAt.Equal(started) ||
+		!got.LastSuccessAt.Equal(finished) || got.Stats.SnapshotID != "abc123" || got.Stats.BytesAdded != 2048 {
+		t.Fatalf("backup after success = %#v", got)
+	}
+
+	// A failure keeps the last success and its stats.
+	if err := s.MarkOpsBackupStarted(ctx, created.ID, "job-2", finished.Add(time.Hour)); err != nil {
+		t.Fatalf("MarkOpsBackupStarted() error = %v", err)
+	}
+	if _, err := s.RecordOpsBackupResult(ctx, created.ID, OpsBackupResult{
+		JobID: "job-2", Status: BackupStatusFailed, Error: "repository locked", FinishedAt: finished.Add(2 * time.Hour),
+	}); err != nil {
+		t.Fatalf("RecordOpsBackupResult() error = %v", err)
+	}
+
+	write.ID, write.Name, write.Enabled = created.ID, "home-dirs", false
+	updated, err := s.UpdateOpsBackup(ctx, write)
+	if err != nil {
+		t.Fatalf("UpdateOpsBackup() error = %v", err)
+	}
+	if updated.Name != "home-dirs" || updated.Enabled || updated.Status != BackupStatusFailed ||
+		updated.LastError != "repository locked" || !updated.LastSuccessAt.Equal(finished) || updated.Stats.Files != 10 {
+		t.Fatalf("updated backup = %#v", updated)
+	}
+
+	if changed, err := s.SetOpsBackupStale(ctx, created.ID, true); err != nil || !changed {
+		t.Fatalf("SetOpsBackupStale(true) = %v, %v; want a change", changed, err)
+	}
+	if changed, err := s.SetOpsBackupStale(ctx, created.ID, true); err != nil || changed {
+		t.Fatalf("second SetOpsBackupStale(true) = %v, %v; want no change", changed, err)
+	}
+
+	list, err := s.ListOpsBackups(ctx)
+	if err != nil || len(list) != 1 || !list[0].Stale {
+		t.Fatalf("ListOpsBackups() = %#v, %v", list, err)
+	}
+	if err := s.DeleteOpsBackup(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteOpsBackup() error = %v", err)
+	}
+	if err := s.DeleteOpsBackup(ctx, created.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("second DeleteOpsBackup() error = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -18,15 +19,52 @@ type CustomService struct {
 	Enabled     bool   `json:"enabled"`
 	CreatedAt   string `json:"createdAt"`
 	UpdatedAt   string `json:"updatedAt"`
+	// HealthChecks must all pass for the service to count as healthy.
+	HealthChecks []HealthCheck `json:"healthChecks,omitempty"`
+	// RestartHooks run around a restart of the service.
+	RestartHooks *RestartHooks `json:"restartHooks,omitempty"`
+}
+
+// HealthCheck is one probe of a custom service. Type is "http", "tcp" or
+// "command" and selects which of the other fields apply.
+type HealthCheck struct {
+	Type string `json:"type"`
+	// URL is requested with GET; ExpectStatus 0 accepts any 2xx.
+	URL          string `json:"url,omitempty"`
+	ExpectStatus int    `json:"expectStatus,omitempty"`
+	// Address is the host:port a tcp check connects to.
+	Address string `json:"address,omitempty"`
+	// Command runs through sh -c and must exit with ExpectExit.
+	Command    string `json:"command,omitempty"`
+	ExpectExit int    `json:"expectExit,omitempty"`
+	// TimeoutSeconds bounds one run; 0 uses the default.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
+
+// RestartHooks are the commands run around a custom service restart.
+// PreStop runs before the restart and aborts it when it fails, e.g. to
+// drain a load balancer; PostStart runs once the service is back.
+type RestartHooks struct {
+	PreStop   *Hook `json:"preStop,omitempty"`
+	PostStart *Hook `json:"postStart,omitempty"`
+}
+
+// Hook is a shell command run through sh -c.
+type Hook struct {
+	Command string `json:"command"`
+	// TimeoutSeconds bounds the run; 0 uses the default.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
 }
 
 // CustomServiceWrite contains the fields needed to register a custom service.
 type CustomServiceWrite struct {
-	Name        string
-	DisplayName string
-	Manager     string
-	Unit        string
-	Scope       string
+	Name         string
+	DisplayName  string
+	Manager      string
+	Unit         string
+	Scope        string
+	HealthChecks []HealthCheck
+	RestartHooks *RestartHooks
 }
 
 // InsertCustomService inserts custom service.
@@ -49,32 +87,58 @@ func (s *Store) InsertCustomService(ctx context.Context, w CustomServiceWrite) (
 	}
 	scope := strings.ToLower(strings.TrimSpace(w.Scope))
 	if scope == "" {
+		// Containers live in the docker daemon, Windows services in the
+		// service control manager and rc.d scripts in the base system, not
+		// a user session.
 		scope = "user"
+		if manager == "docker" || manager == "windows" || manager == "rcd" {
+			scope = "system"
+		}
+	}
+	checks := w.HealthChecks
+	if checks == nil {
+		checks = []HealthCheck{}
+	}
+	checksJSON, err := json.Marshal(checks)
+	if err != nil {
+		return CustomService{}, fmt.Errorf("encode health checks: %w", err)
+	}
+	hooks := w.RestartHooks
+	if hooks != nil && hooks.PreStop == nil && hooks.PostStart == nil {
+		hooks = nil
+	}
+	hooksJSON := []byte("{}")
+	if hooks != nil {
+		if hooksJSON, err = json.Marshal(hooks); err != nil {
+			return CustomService{}, fmt.Errorf("encode restart hooks: %w", err)
+		}
 	}
 	now := time.Now().UTC().Format(time.RFC3339)
 	if _, err := s.db.ExecContext(ctx, `INSERT INTO ops_custom_services (
-		name, display_name, manager, unit, scope, enabled, created_at, updated_at
-	) VALUES (?, ?, ?, ?, ?, 1, ?, ?)`,
-		name, displayName, manager, unit, scope, now, now,
+		name, display_name, manager, unit, scope, enabled, created_at, updated_at, health_checks, restart_hooks
+	) VALUES (?, ?, ?, ?, ?, 1, ?, ?, ?, ?)`,
+		name, displayName, manager, unit, scope, now, now, string(checksJSON), string(hooksJSON),
 	); err != nil {
 		return CustomService{}, err
 	}
 	return CustomService{
-		Name:        name,
-		DisplayName: displayName,
-		Manager:     manager,
-		Unit:        unit,
-		Scope:       scope,
-		Enabled:     true,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		Name:         name,
+		DisplayName:  displayName,
+		Manager:      manager,
+		Unit:         unit,
+		Scope:        scope,
+		Enabled:      true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		HealthChecks: w.HealthChecks,
+		RestartHooks: hooks,
 	}, nil
 }
 
 // ListCustomServices lists custom services.
 func (s *Store) ListCustomServices(ctx context.Context) ([]CustomService, error) {
 	rows, err := s.db.QueryContext(ctx, `SELECT
-		name, display_name, manager, unit, scope, enabled, created_at, updated_at
+		name, display_name, manager, unit, scope, enabled, created_at, updated_at, health_checks, restart_hooks
 	FROM ops_custom_services
 	WHERE enabled = 1
 	ORDER BY name ASC`)
@@ -87,14 +151,22 @@ func (s *Store) ListCustomServices(ctx context.Context) ([]CustomService, error)
 	for rows.Next() {
 		var item CustomService
 		var enabled int
+		var checksRaw, hooksRaw string
 		if err := rows.Scan(
 			&item.Name, &item.DisplayName, &item.Manager,
 			&item.Unit, &item.Scope, &enabled,
-			&item.CreatedAt, &item.UpdatedAt,
+			&item.CreatedAt, &item.UpdatedAt, &checksRaw, &hooksRaw,
 		); err != nil {
 			return nil, err
 		}
 		item.Enabled = enabled == 1
+		if err := json.Unmarshal([]byte(checksRaw), &item.HealthChecks); err != nil || len(item.HealthChecks) == 0 {
+			item.HealthChecks = nil
+		}
+		var hooks RestartHooks
+		if err := json.Unmarshal([]byte(hooksRaw), &hooks); err == nil && (hooks.PreStop != nil || hooks.PostStart != nil) {
+			item.RestartHooks = &hooks
+		}
 		out = append(out, item)
 	}
 	return out, rows.Err()
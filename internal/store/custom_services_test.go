@@ -68,6 +68,34 @@ func TestInsertCustomService(t *testing.T) {
 		}
 	})
 
+	t.Run("docker defaults to system scope", func(t *testing.T) {
+		svc, err := s.InsertCustomService(ctx, CustomServiceWrite{
+			Name:    "postgres",
+			Manager: "docker",
+			Unit:    "postgres",
+		})
+		if err != nil {
+			t.Fatalf("InsertCustomService: %v", err)
+		}
+		if svc.Scope != "system" {
+			t.Fatalf("docker scope should default to system, got %q", svc.Scope)
+		}
+	})
+
+	t.Run("windows defaults to system scope", func(t *testing.T) {
+		svc, err := s.InsertCustomService(ctx, CustomServiceWrite{
+			Name:    "spooler",
+			Manager: "windows",
+			Unit:    "Spooler",
+		})
+		if err != nil {
+			t.Fatalf("InsertCustomService: %v", err)
+		}
+		if svc.Scope != "system" {
+			t.Fatalf("windows scope should default to system, got %q", svc.Scope)
+		}
+	})
+
 	t.Run("empty name errors", func(t *testing.T) {
 		_, err := s.InsertCustomService(ctx, CustomServiceWrite{
 			Name: "",
@@ -117,7 +145,12 @@ func TestListCustomServices(t *testing.T) {
 	// Insert two services.
 	for _, w := range []CustomServiceWrite{
 		{Name: "beta", Unit: "beta.service"},
-		{Name: "alpha", Unit: "alpha.service"},
+		{Name: "alpha", Unit: "alpha.service", HealthChecks: []HealthCheck{
+			{Type: "http", URL: "http://127.0.0.1:8080/healthz", ExpectStatus: 200},
+			{Type: "tcp", Address: "127.0.0.1:5432", TimeoutSeconds: 2},
+		}, RestartHooks: &RestartHooks{
+			PreStop: &Hook{Command: "drain alpha", TimeoutSeconds: 20},
+		}},
 	} {
 		if _, err := s.InsertCustomService(ctx, w); err != nil {
 			t.Fatalf("InsertCustomService(%s): %v", w.Name, err)
@@ -135,6 +168,18 @@ func TestListCustomServices(t *testing.T) {
 	if list[0].Name != "alpha" || list[1].Name != "beta" {
 		t.Fatalf("services not sorted: [%s, %s]", list[0].Name, list[1].Name)
 	}
+	if checks := list[0].HealthChecks; len(checks) != 2 || checks[0].URL != "http://127.0.0.1:8080/healthz" || checks[1].Address != "127.0.0.1:5432" {
+		t.Fatalf("alpha health checks = %+v", checks)
+	}
+	if list[1].HealthChecks != nil {
+		t.Fatalf("beta health checks = %+v, want none", list[1].HealthChecks)
+	}
+	if hooks := list[0].RestartHooks; hooks == nil || hooks.PreStop == nil || hooks.PreStop.Command != "drain alpha" || hooks.PostStart != nil {
+		t.Fatalf("alpha restart hooks = %+v", hooks)
+	}
+	if list[1].RestartHooks != nil {
+		t.Fatalf("beta restart hooks = %+v, want none", list[1].RestartHooks)
+	}
 }
 
 func TestDeleteCustomService(t *testing.T) {
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Heartbeat statuses.
+const (
+	HeartbeatStatusNew    = "new"
+	HeartbeatStatusUp     = "up"
+	HeartbeatStatusMissed = "missed"
+)
+
+// OpsHeartbeat is a check-in that an external job pings after each run. It
+// is missed once no ping arrived within IntervalSeconds + GraceSeconds of
+// the last one. The ID is random and doubles as the ping credential.
+type OpsHeartbeat struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	IntervalSeconds int       `json:"intervalSeconds"`
+	GraceSeconds    int       `json:"graceSeconds"`
+	Enabled         bool      `json:"enabled"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+	Status          string    `json:"status"`
+	LastPingAt      time.Time `json:"lastPingAt"`
+	LastChangeAt    time.Time `json:"lastChangeAt"`
+}
+
+// DueAt returns when the heartbeat is missed without another ping, or the
+// zero time before its first ping.
+func (h OpsHeartbeat) DueAt() time.Time {
+	if h.LastPingAt.IsZero() {
+		return time.Time{}
+	}
+	return h.LastPingAt.Add(time.Duration(h.IntervalSeconds+h.GraceSeconds) * time.Second)
+}
+
+// OpsHeartbeatWrite represents heartbeat write data.
+type OpsHeartbeatWrite struct {
+	ID              string
+	Name            string
+	IntervalSeconds int
+	GraceSeconds    int
+	Enabled         bool
+}
+
+const opsHeartbeatColumns = `id, name, interval_seconds, grace_seconds, enabled,
+	created_at, updated_at, status, last_ping_at, last_change_at`
+
+// ListOpsHeartbeats lists heartbeats ordered by name.
+func (s *Store) ListOpsHeartbeats(ctx context.Context) ([]OpsHeartbeat, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+opsHeartbeatColumns+`
+		   FROM ops_heartbeats
+		  ORDER BY name COLLATE NOCASE ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]OpsHeartbeat, 0, 8)
+	for rows.Next() {
+		row, err := scanOpsHeartbeat(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// GetOpsHeartbeat returns a heartbeat.
+func (s *Store) GetOpsHeartbeat(ctx context.Context, id string) (OpsHeartbeat, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return OpsHeartbeat{}, sql.ErrNoRows
+	}
+	return scanOpsHeartbeat(s.db.QueryRowContext(ctx,
+		`SELECT `+opsHeartbeatColumns+` FROM ops_heartbeats WHERE id = ?`, id,
+	))
+}
+
+// CreateOpsHeartbeat creates a heartbeat waiting for its first ping.
+func (s *Store) CreateOpsHeartbeat(ctx context.Context, w OpsHeartbeatWrite) (OpsHeartbeat, error) {
+	name := strings.TrimSpace(w.Name)
+	if name == "" {
+		return OpsHeartbeat{}, errors.New("heartbeat name is required")
+	}
+	id := strings.TrimSpace(w.ID)
+	if id == "" {
+		id = randomID()
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO ops_heartbeats (id, name, interval_seconds, grace_seconds, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		id, name, w.IntervalSeconds, w.GraceSeconds, boolToInt(w.Enabled), now, now,
+	); err != nil {
+		return OpsHeartbeat{}, err
+	}
+	return s.GetOpsHeartbeat(ctx, id)
+}
+
+// UpdateOpsHeartbeat updates a heartbeat's name, schedule and enabled flag;
+// its pings and status are kept.
+func (s *Store) UpdateOpsHeartbeat(ctx context.Context, w OpsHeartbeatWrite) (OpsHeartbeat, error) {
+	name := strings.TrimSpace(w.Name)
+	if name == "" {
+		return OpsHeartbeat{}, errors.New("heartbeat name is required")
+	}
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE ops_heartbeats SET name = ?, interval_seconds = ?, grace_seconds = ?, enabled = ?, updated_at = ?
+		 WHERE id = ?`,
+		name, w.IntervalSeconds, w.GraceSeconds, boolToInt(w.Enabled), time.Now().UTC().Format(time.RFC3339),
+		strings.TrimSpace(w.ID),
+	)
+	if err != nil {
+		return OpsHeartbeat{}, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return OpsHeartbeat{}, sql.ErrNoRows
+	}
+	return s.GetOpsHeartbeat(ctx, w.ID)
+}
+
+// DeleteOpsHeartbeat removes a heartbeat.
+func (s *Store) DeleteOpsHeartbeat(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM ops_heartbeats WHERE id = ?`, strings.TrimSpace(id))
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PingOpsHeartbeat records a ping at at, marks the heartbeat up and returns
+// it together with its status before the ping.
+func (s *Store) PingOpsHeartbeat(ctx context.Context, id string, at time.Time) (OpsHeartbeat, string, error) {
+	id = strings.TrimSpace(id)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return OpsHeartbeat{}, "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var previous string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM ops_heartbeats WHERE id = ?`, id).Scan(&previous); err != nil {
+		return OpsHeartbeat{}, "", err
+	}
+	stamp := at.UTC().Format(time.RFC3339)
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE ops_heartbeats SET
+		 last_change_at = CASE WHEN status = ? THEN last_change_at ELSE ? END,
+		 status = ?, last_ping_at = ?
+		 WHERE id = ?`,
+		HeartbeatStatusUp, stamp,
+		HeartbeatStatusUp, stamp,
+		id,
+	); err != nil {
+		return OpsHeartbeat{}, "", err
+	}
+	hb, err := scanOpsHeartbeat(tx.QueryRowContext(ctx,
+		`SELECT `+opsHeartbeatColumns+` FROM ops_heartbeats WHERE id = ?`, id,
+	))
+	if err != nil {
+		return OpsHeartbeat{}, "", err
+	}
+	return hb, previous, tx.Commit()
+}
+
+// MarkOpsHeartbeatMissed marks an up heartbeat missed at at, unless a ping
+// arrived after lastPingAt in the meantime. It reports whether the status
+// changed.
+func (s *Store) MarkOpsHeartbeatMissed(ctx context.Context, id string, lastPingAt, at time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE ops_heartbeats SET status = ?, last_change_at = ?
+		 WHERE id = ? AND status = ? AND last_ping_at = ?`,
+		HeartbeatStatusMissed, at.UTC().Format(time.RFC3339),
+		strings.TrimSpace(id), HeartbeatStatusUp, lastPingAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func scanOpsHeartbeat(row interface{ Scan(...any) error }) (OpsHeartbeat, error) {
+	var (
+		hb                                                  OpsHeartbeat
+		enabled                                             int
+		createdAtRaw, updatedAtRaw, pingAtRaw, changedAtRaw string
+	)
+	if err := row.Scan(&hb.ID, &hb.Name, &hb.IntervalSeconds, &hb.GraceSeconds, &enabled,
+		&createdAtRaw, &updatedAtRaw, &hb.Status, &pingAtRaw, &changedAtRaw); err != nil {
+		return OpsHeartbeat{}, err
+	}
+	hb.Enabled = enabled == 1
+	hb.CreatedAt = parseStoreTime(createdAtRaw)
+	hb.UpdatedAt = parseStoreTime(updatedAtRaw)
+	hb.LastPingAt = parseStoreTime(pingAtRaw)
+	hb.LastChangeAt = parseStoreTime(changedAtRaw)
+	return hb, nil
+}
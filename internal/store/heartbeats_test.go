@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOpsHeartbeats(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	created, err := s.CreateOpsHeartbeat(ctx, OpsHeartbeatWrite{Name: " backup ", IntervalSeconds: 86400, GraceSeconds: 3600, Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateOpsHeartbeat() error = %v", err)
+	}
+	if len(created.ID) != 32 || created.Name != "backup" || created.Status != HeartbeatStatusNew || !created.DueAt().IsZero() {
+		t.Fatalf("created heartbeat = %#v", created)
+	}
+	if _, err := s.CreateOpsHeartbeat(ctx, OpsHeartbeatWrite{Name: "backup", IntervalSeconds: 60}); err == nil {
+		t.Fatal("CreateOpsHeartbeat() accepted a duplicate name")
+	}
+
+	ping := time.Date(2026, 2, 15, 3, 0, 0, 0, time.UTC)
+	hb, previous, err := s.PingOpsHeartbeat(ctx, created.ID, ping)
+	if err != nil {
+		t.Fatalf("PingOpsHeartbeat() error = %v", err)
+	}
+	if previous != HeartbeatStatusNew || hb.Status != HeartbeatStatusUp || !hb.LastPingAt.Equal(ping) || !hb.LastChangeAt.Equal(ping) {
+		t.Fatalf("pinged heartbeat = %#v, previous %q", hb, previous)
+	}
+	if want := ping.Add(25 * time.Hour); !hb.DueAt().Equal(want) {
+		t.Fatalf("DueAt() = %v, want %v", hb.DueAt(), want)
+	}
+
+	// A ping that lands before the miss is recorded wins.
+	if missed, err := s.MarkOpsHeartbeatMissed(ctx, created.ID, ping.Add(-time.Hour), ping.Add(26*time.Hour)); err != nil || missed {
+		t.Fatalf("MarkOpsHeartbeatMissed(stale ping) = %t, %v", missed, err)
+	}
+	missedAt := ping.Add(26 * time.Hour)
+	if missed, err := s.MarkOpsHeartbeatMissed(ctx, created.ID, ping, missedAt); err != nil || !missed {
+		t.Fatalf("MarkOpsHeartbeatMissed() = %t, %v", missed, err)
+	}
+	if missed, err := s.MarkOpsHeartbeatMissed(ctx, created.ID, ping, missedAt); err != nil || missed {
+		t.Fatalf("MarkOpsHeartbeatMissed(again) = %t, %v", missed, err)
+	}
+	hb, previous, err = s.PingOpsHeartbeat(ctx, created.ID, missedAt.Add(time.Minute))
+	if err != nil || previous != HeartbeatStatusMissed || hb.Status != HeartbeatStatusUp {
+		t.Fatalf("recovery ping = %#v, %q, %v", hb, previous, err)
+	}
+
+	updated, err := s.UpdateOpsHeartbeat(ctx, OpsHeartbeatWrite{ID: created.ID, Name: "nightly-backup", IntervalSeconds: 3600, Enabled: false})
+	if err != nil {
+		t.Fatalf("UpdateOpsHeartbeat() error = %v", err)
+	}
+	if updated.Name != "nightly-backup" || updated.Enabled || updated.Status != HeartbeatStatusUp || !updated.LastPingAt.Equal(missedAt.Add(time.Minute)) {
+		t.Fatalf("updated heartbeat = %#v", updated)
+	}
+
+	if _, _, err := s.PingOpsHeartbeat(ctx, "missing", ping); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("PingOpsHeartbeat(missing) error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.UpdateOpsHeartbeat(ctx, OpsHeartbeatWrite{ID: "missing", Name: "x"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("UpdateOpsHeartbeat(missing) error = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.DeleteOpsHeartbeat(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteOpsHeartbeat() error = %v", err)
+	}
+	if list, err := s.ListOpsHeartbeats(ctx); err != nil || len(list) != 0 {
+		t.Fatalf("ListOpsHeartbeats() = %v, %v; want none", list, err)
+	}
+	if err := s.DeleteOpsHeartbeat(ctx, created.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("DeleteOpsHeartbeat(deleted) error = %v, want sql.ErrNoRows", err)
+	}
+}
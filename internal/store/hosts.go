@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OpsHost holds the labels assigned to a federated host.
+type OpsHost struct {
+	Name      string            `json:"name"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt string            `json:"createdAt"`
+	UpdatedAt string            `json:"updatedAt"`
+}
+
+// ListOpsHosts returns every labelled host ordered by name.
+func (s *Store) ListOpsHosts(ctx context.Context) ([]OpsHost, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT name, labels, created_at, updated_at FROM ops_hosts ORDER BY name ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]OpsHost, 0, 8)
+	for rows.Next() {
+		var (
+			host      OpsHost
+			labelsRaw string
+		)
+		if err := rows.Scan(&host.Name, &labelsRaw, &host.CreatedAt, &host.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(labelsRaw), &host.Labels); err != nil || host.Labels == nil {
+			host.Labels = map[string]string{}
+		}
+		out = append(out, host)
+	}
+	return out, rows.Err()
+}
+
+// SetOpsHostLabels replaces the labels of a host, adding it when unknown.
+func (s *Store) SetOpsHostLabels(ctx context.Context, name string, labels map[string]string) (OpsHost, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return OpsHost{}, fmt.Errorf("host name is required")
+	}
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return OpsHost{}, fmt.Errorf("marshal labels: %w", err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO ops_hosts (name, labels, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET labels = excluded.labels, updated_at = excluded.updated_at`,
+		name, string(labelsJSON), now, now,
+	); err != nil {
+		return OpsHost{}, err
+	}
+
+	host := OpsHost{Name: name}
+	var labelsRaw string
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT labels, created_at, updated_at FROM ops_hosts WHERE name = ?`, name,
+	).Scan(&labelsRaw, &host.CreatedAt, &host.UpdatedAt); err != nil {
+		return OpsHost{}, err
+	}
+	if err := json.Unmarshal([]byte(labelsRaw), &host.Labels); err != nil || host.Labels == nil {
+		host.Labels = map[string]string{}
+	}
+	return host, nil
+}
+
+// DeleteOpsHost forgets the labels of a host.
+func (s *Store) DeleteOpsHost(ctx context.Context, name string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM ops_hosts WHERE name = ?", strings.TrimSpace(name))
+	if err != nil {
+		return err
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
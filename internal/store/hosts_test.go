@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOpsHostLabels(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	created, err := s.SetOpsHostLabels(ctx, "web-01", map[string]string{"env": "prod", "role": "web"})
+	if err != nil {
+		t.Fatalf("SetOpsHostLabels() error = %v", err)
+	}
+	if created.Labels["role"] != "web" || created.CreatedAt == "" {
+		t.Fatalf("created = %+v", created)
+	}
+
+	updated, err := s.SetOpsHostLabels(ctx, "web-01", map[string]string{"env": "staging"})
+	if err != nil {
+		t.Fatalf("SetOpsHostLabels(update) error = %v", err)
+	}
+	if len(updated.Labels) != 1 || updated.Labels["env"] != "staging" || updated.CreatedAt != created.CreatedAt {
+		t.Fatalf("updated = %+v, want labels replaced and createdAt kept", updated)
+	}
+	if _, err := s.SetOpsHostLabels(ctx, "db-01", nil); err != nil {
+		t.Fatalf("SetOpsHostLabels(nil) error = %v", err)
+	}
+
+	hosts, err := s.ListOpsHosts(ctx)
+	if err != nil {
+		t.Fatalf("ListOpsHosts() error = %v", err)
+	}
+	if len(hosts) != 2 || hosts[0].Name != "db-01" || hosts[0].Labels == nil || hosts[1].Name != "web-01" {
+		t.Fatalf("hosts = %+v", hosts)
+	}
+
+	if err := s.DeleteOpsHost(ctx, "db-01"); err != nil {
+		t.Fatalf("DeleteOpsHost() error = %v", err)
+	}
+	if err := s.DeleteOpsHost(ctx, "db-01"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("DeleteOpsHost(missing) error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.SetOpsHostLabels(ctx, " ", nil); err == nil {
+		t.Fatal("SetOpsHostLabels(empty name) succeeded")
+	}
+}
+
+func TestHostSelectorOnSchedulesAndRuns(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	sched, err := s.InsertOpsSchedule(ctx, OpsScheduleWrite{
+		RunbookID:    "ops.service.recover",
+		Name:         "Nightly web restart",
+		ScheduleType: "cron",
+		CronExpr:     "0 3 * * *",
+		Timezone:     "UTC",
+		Hosts:        "role=web",
+	})
+	if err != nil {
+		t.Fatalf("InsertOpsSchedule() error = %v", err)
+	}
+	if sched.Hosts != "role=web" {
+		t.Fatalf("schedule hosts = %q, want role=web", sched.Hosts)
+	}
+
+	run, err := s.CreateOpsRunbookRunForHosts(ctx, "ops.service.recover", time.Now(), nil, " role=web ")
+	if err != nil {
+		t.Fatalf("CreateOpsRunbookRunForHosts() error = %v", err)
+	}
+	if run.Hosts != "role=web" {
+		t.Fatalf("run hosts = %q, want role=web", run.Hosts)
+	}
+	run, err = s.CreateOpsRunbookRunWithParams(ctx, "ops.service.recover", time.Now(), nil)
+	if err != nil {
+		t.Fatalf("CreateOpsRunbookRunWithParams() error = %v", err)
+	}
+	if run.Hosts != "" {
+		t.Fatalf("untargeted run hosts = %q, want empty", run.Hosts)
+	}
+}
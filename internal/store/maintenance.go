@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Maintenance step names, in the order Maintain runs them.
+const (
+	MaintenanceStepIntegrity  = "integrity_check"
+	MaintenanceStepAnalyze    = "analyze"
+	MaintenanceStepVacuum     = "vacuum"
+	MaintenanceStepCheckpoint = "checkpoint"
+)
+
+// autoVacuumIncremental is the PRAGMA auto_vacuum value that lets
+// incremental_vacuum return free pages to the file system.
+const autoVacuumIncremental = 2
+
+// MaintenanceStep reports one finished step of a maintenance run.
+type MaintenanceStep struct {
+	Name       string `json:"name"`
+	DurationMs int64  `json:"durationMs"`
+	Detail     string `json:"detail,omitempty"`
+	Skipped    bool   `json:"skipped,omitempty"`
+}
+
+// MaintenanceReport is the outcome of Maintain. Sizes cover the database
+// file and its WAL.
+type MaintenanceReport struct {
+	Steps           []MaintenanceStep `json:"steps"`
+	IntegrityOK     bool              `json:"integrityOk"`
+	Problems        []string          `json:"problems,omitempty"`
+	BytesBefore     int64             `json:"bytesBefore"`
+	BytesAfter      int64             `json:"bytesAfter"`
+	FreePagesBefore int64             `json:"freePagesBefore"`
+	FreePagesAfter  int64             `json:"freePagesAfter"`
+	StartedAt       time.Time         `json:"startedAt"`
+	FinishedAt      time.Time         `json:"finishedAt"`
+}
+
+// Maintain checks the database and gives unused space back to the file
+// system: integrity_check, ANALYZE, an incremental VACUUM and a WAL
+// checkpoint. progress, when set, is called as each step finishes.
+//
+// Databases created before incremental auto-vacuum was enabled get one full
+// VACUUM to switch modes; later runs only release the free pages. The
+// vacuum is skipped when the integrity check reports problems, so a damaged
+// file is not rewritten.
+func (s *Store) Maintain(ctx context.Context, progress func(MaintenanceStep)) (MaintenanceReport, error) {
+	report := MaintenanceReport{StartedAt: time.Now().UTC()}
+	var err error
+	if report.BytesBefore, err = s.databaseBytes(); err != nil {
+		return MaintenanceReport{}, err
+	}
+	if report.FreePagesBefore, err = s.pragmaInt(ctx, "freelist_count"); err != nil {
+		return MaintenanceReport{}, err
+	}
+
+	steps := []struct {
+		name string
+		run  func() (string, bool, error)
+	}{
+		{MaintenanceStepIntegrity, func() (string, bool, error) {
+			problems, err := integrityProblems(ctx, s.db)
+			if err != nil {
+				return "", false, err
+			}
+			report.Problems = problems
+			report.IntegrityOK = len(problems) == 0
+			if !report.IntegrityOK {
+				return fmt.Sprintf("%d problem(s) found", len(problems)), false, nil
+			}
+			return "ok", false, nil
+		}},
+		{MaintenanceStepAnalyze, func() (string, bool, error) {
+			_, err := s.db.ExecContext(ctx, "ANALYZE")
+			return "", false, err
+		}},
+		{MaintenanceStepVacuum, func() (string, bool, error) {
+			if !report.IntegrityOK {
+				return "skipped: integrity check failed", true, nil
+			}
+			return s.incrementalVacuum(ctx)
+		}},
+		{MaintenanceStepCheckpoint, func() (string, bool, error) {
+			return "", false, s.walCheckpoint(ctx)
+		}},
+	}
+	for _, item := range steps {
+		started := time.Now()
+		detail, skipped, err := item.run()
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", item.name, err)
+		}
+		done := MaintenanceStep{Name: item.name, DurationMs: time.Since(started).Milliseconds(), Detail: detail, Skipped: skipped}
+		report.Steps = append(report.Steps, done)
+		if progress != nil {
+			progress(done)
+		}
+	}
+
+	if report.BytesAfter, err = s.databaseBytes(); err != nil {
+		return report, err
+	}
+	if report.FreePagesAfter, err = s.pragmaInt(ctx, "freelist_count"); err != nil {
+		return report, err
+	}
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
+
+// incrementalVacuum releases the free pages of the database, switching it
+// to incremental auto-vacuum with a full VACUUM first when needed.
+func (s *Store) incrementalVacuum(ctx context.Context) (string, bool, error) {
+	mode, err := s.pragmaInt(ctx, "auto_vacuum")
+	if err != nil {
+		return "", false, err
+	}
+	if mode != autoVacuumIncremental {
+		if _, err := s.db.ExecContext(ctx, "PRAGMA auto_vacuum = INCREMENTAL"); err != nil {
+			return "", false, err
+		}
+		if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+			return "", false, err
+		}
+		return "full vacuum: switched to incremental auto-vacuum", false, nil
+	}
+	free, err := s.pragmaInt(ctx, "freelist_count")
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := s.db.ExecContext(ctx, "PRAGMA incremental_vacuum"); err != nil {
+		return "", false, err
+	}
+	return fmt.Sprintf("released %d free page(s)", free), false, nil
+}
+
+func (s *Store) pragmaInt(ctx context.Context, name string) (int64, error) {
+	var value int64
+	if err := s.db.QueryRowContext(ctx, "PRAGMA "+name).Scan(&value); err != nil {
+		return 0, fmt.Errorf("read %s: %w", name, err)
+	}
+	return value, nil
+}
+
+func (s *Store) databaseBytes() (int64, error) {
+	dbBytes, err := fileSizeBestEffort(s.dbPath)
+	if err != nil {
+		return 0, err
+	}
+	walBytes, err := fileSizeBestEffort(s.dbPath + "-wal")
+	if err != nil {
+		return 0, err
+	}
+	return dbBytes + walBytes, nil
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestMaintainReleasesFreePages(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		"CREATE TABLE scratch (payload BLOB)",
+		"WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 512) INSERT INTO scratch SELECT randomblob(4096) FROM n",
+		"DROP TABLE scratch",
+		"PRAGMA wal_checkpoint(TRUNCATE)",
+	} {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	var seen []string
+	report, err := s.Maintain(ctx, func(step MaintenanceStep) { seen = append(seen, step.Name) })
+	if err != nil {
+		t.Fatalf("Maintain() error = %v", err)
+	}
+	want := []string{MaintenanceStepIntegrity, MaintenanceStepAnalyze, MaintenanceStepVacuum, MaintenanceStepCheckpoint}
+	if !slices.Equal(seen, want) || len(report.Steps) != len(want) {
+		t.Fatalf("progress = %v, steps = %+v, want %v", seen, report.Steps, want)
+	}
+	if !report.IntegrityOK || report.FreePagesBefore == 0 || report.FreePagesAfter != 0 {
+		t.Fatalf("report = %+v, want intact database with free pages released", report)
+	}
+	if report.BytesAfter >= report.BytesBefore {
+		t.Fatalf("bytes %d -> %d, want the file to shrink", report.BytesBefore, report.BytesAfter)
+	}
+	if mode, err := s.pragmaInt(ctx, "auto_vacuum"); err != nil || mode != autoVacuumIncremental {
+		t.Fatalf("auto_vacuum = %d (%v), want incremental", mode, err)
+	}
+
+	// Later runs release pages without a full VACUUM.
+	report, err = s.Maintain(ctx, nil)
+	if err != nil {
+		t.Fatalf("Maintain(second) error = %v", err)
+	}
+	if vacuum := report.Steps[2]; vacuum.Detail != "released 0 free page(s)" {
+		t.Fatalf("second vacuum = %+v", vacuum)
+	}
+}
@@ -0,0 +1,272 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Metrics history resolutions. Raw samples are rolled up into minute and
+// hour buckets so long ranges stay cheap to query.
+const (
+	MetricsResolutionRaw    = "raw"
+	MetricsResolutionMinute = "1m"
+	MetricsResolutionHour   = "1h"
+
+	// MaxMetricsHistoryPoints caps the number of buckets returned by a single
+	// history query; the step is widened to fit.
+	MaxMetricsHistoryPoints = 1000
+
+	// Rollups recompute a few trailing complete buckets each pass so a
+	// missed tick is caught up on the next one.
+	metricsMinuteRollupWindow = 5 * time.Minute
+	metricsHourRollupWindow   = 2 * time.Hour
+)
+
+// ErrInvalidMetricsRange is returned when a history query range is empty or
+// inverted.
+var ErrInvalidMetricsRange = errors.New("invalid metrics range")
+
+// MetricsSample is one host metrics observation persisted for history.
+type MetricsSample struct {
+	CPUPercent    float64 `json:"cpuPercent"`
+	LoadAvg1      float64 `json:"loadAvg1"`
+	MemPercent    float64 `json:"memPercent"`
+	MemUsedBytes  int64   `json:"memUsedBytes"`
+	SwapPercent   float64 `json:"swapPercent"`
+	DiskPercent   float64 `json:"diskPercent"`
+	DiskUsedBytes int64   `json:"diskUsedBytes"`
+	NetRxBytes    int64   `json:"netRxBytes"`
+	NetTxBytes    int64   `json:"netTxBytes"`
+	NetRxRate     float64 `json:"netRxBytesPerSec"`
+	NetTxRate     float64 `json:"netTxBytesPerSec"`
+	NetRxErrors   int64   `json:"netRxErrors"`
+	NetTxErrors   int64   `json:"netTxErrors"`
+	NetSaturation float64 `json:"netSaturationPercent"`
+}
+
+// MetricsPoint is an aggregated metrics bucket starting at At.
+type MetricsPoint struct {
+	At      time.Time `json:"at"`
+	Samples int64     `json:"samples"`
+	MetricsSample
+}
+
+// MetricsHistory is the result of a history query.
+type MetricsHistory struct {
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Step       int64          `json:"stepSeconds"`
+	Resolution string         `json:"resolution"`
+	Points     []MetricsPoint `json:"points"`
+}
+
+// MetricsRetention bounds how long each resolution is kept.
+type MetricsRetention struct {
+	Raw    time.Duration
+	Minute time.Duration
+	Hour   time.Duration
+}
+
+// metricsAggregateColumns aggregates a set of rows into one bucket: ratios
+// are weighted by sample count, byte gauges and network rates averaged the
+// same way, and cumulative network counters keep their maximum. Interface
+// saturation keeps its peak so a short burst is not averaged away.
+const metricsAggregateColumns = `SUM(samples),
+	SUM(cpu_percent * samples) / SUM(samples),
+	SUM(load_avg1 * samples) / SUM(samples),
+	SUM(mem_percent * samples) / SUM(samples),
+	CAST(SUM(mem_used_bytes * samples) / SUM(samples) AS INTEGER),
+	SUM(swap_percent * samples) / SUM(samples),
+	SUM(disk_percent * samples) / SUM(samples),
+	CAST(SUM(disk_used_bytes * samples) / SUM(samples) AS INTEGER),
+	MAX(net_rx_bytes),
+	MAX(net_tx_bytes),
+	SUM(net_rx_rate * samples) / SUM(samples),
+	SUM(net_tx_rate * samples) / SUM(samples),
+	MAX(net_rx_errors),
+	MAX(net_tx_errors),
+	MAX(net_saturation_percent)`
+
+// RecordMetricsSample stores a raw metrics sample taken at the given time.
+func (s *Store) RecordMetricsSample(ctx context.Context, at time.Time, sample MetricsSample) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO ops_metrics_history (
+			resolution, bucket_at, samples, cpu_percent, load_avg1, mem_percent,
+			mem_used_bytes, swap_percent, disk_percent, disk_used_bytes,
+			net_rx_bytes, net_tx_bytes, net_rx_rate, net_tx_rate,
+			net_rx_errors, net_tx_errors, net_saturation_percent
+		) VALUES (?, ?, 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		MetricsResolutionRaw, at.UTC().Unix(),
+		sample.CPUPercent, sample.LoadAvg1, sample.MemPercent, sample.MemUsedBytes,
+		sample.SwapPercent, sample.DiskPercent, sample.DiskUsedBytes,
+		sample.NetRxBytes, sample.NetTxBytes, sample.NetRxRate, sample.NetTxRate,
+		sample.NetRxErrors, sample.NetTxErrors, sample.NetSaturation,
+	)
+	return err
+}
+
+// RollupMetricsHistory aggregates recent complete raw minutes into 1m
+// buckets and recent complete 1m hours into 1h buckets. It is idempotent.
+func (s *Store) RollupMetricsHistory(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	minuteEnd := now.Truncate(time.Minute)
+	if err := s.rollupMetrics(ctx, MetricsResolutionRaw, MetricsResolutionMinute,
+		time.Minute, minuteEnd.Add(-metricsMinuteRollupWindow), minuteEnd); err != nil {
+		return err
+	}
+	hourEnd := now.Truncate(time.Hour)
+	return s.rollupMetrics(ctx, MetricsResolutionMinute, MetricsResolutionHour,
+		time.Hour, hourEnd.Add(-metricsHourRollupWindow), hourEnd)
+}
+
+func (s *Store) rollupMetrics(ctx context.Context, source, target string, bucket time.Duration, from, to time.Time) error {
+	size := int64(bucket / time.Second)
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO ops_metrics_history (
+			resolution, bucket_at, samples, cpu_percent, load_avg1, mem_percent,
+			mem_used_bytes, swap_percent, disk_percent, disk_used_bytes,
+			net_rx_bytes, net_tx_bytes, net_rx_rate, net_tx_rate,
+			net_rx_errors, net_tx_errors, net_saturation_percent
+		)
+		SELECT ?, (bucket_at / ?) * ?, `+metricsAggregateColumns+`
+		  FROM ops_metrics_history
+		 WHERE resolution = ? AND bucket_at >= ? AND bucket_at < ?
+		 GROUP BY bucket_at / ?`,
+		target, size, size, source, from.Unix(), to.Unix(), size,
+	)
+	return err
+}
+
+// PruneMetricsHistory removes buckets older than each resolution's
+// retention and returns the number of rows deleted.
+func (s *Store) PruneMetricsHistory(ctx context.Context, now time.Time, retention MetricsRetention) (int64, error) {
+	var removed int64
+	for _, item := range []struct {
+		resolution string
+		keep       time.Duration
+	}{
+		{MetricsResolutionRaw, retention.Raw},
+		{MetricsResolutionMinute, retention.Minute},
+		{MetricsResolutionHour, retention.Hour},
+	} {
+		if item.keep <= 0 {
+			continue
+		}
+		result, err := s.db.ExecContext(ctx,
+			`DELETE FROM ops_metrics_history WHERE resolution = ? AND bucket_at < ?`,
+			item.resolution, now.Add(-item.keep).Unix(),
+		)
+		if err != nil {
+			return removed, err
+		}
+		n, _ := result.RowsAffected()
+		removed += n
+	}
+	return removed, nil
+}
+
+// QueryMetricsHistory returns metrics aggregated into step-sized buckets
+// over [from, to). The finest stored resolution that fits within step and
+// still covers from is used; step is widened when the range would exceed
+// MaxMetricsHistoryPoints.
+func (s *Store) QueryMetricsHistory(ctx context.Context, from, to time.Time, step time.Duration) (MetricsHistory, error) {
+	from, to = from.UTC(), to.UTC()
+	if !to.After(from) {
+		return MetricsHistory{}, ErrInvalidMetricsRange
+	}
+	step = step.Truncate(time.Second)
+	if step < time.Second {
+		step = time.Second
+	}
+	if span := to.Sub(from); span/step > MaxMetricsHistoryPoints {
+		step = (span + MaxMetricsHistoryPoints - 1) / MaxMetricsHistoryPoints
+		if step%time.Second != 0 {
+			step = step.Truncate(time.Second) + time.Second
+		}
+	}
+
+	resolution, err := s.metricsResolutionFor(ctx, from, step)
+	if err != nil {
+		return MetricsHistory{}, err
+	}
+
+	size := int64(step / time.Second)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT (bucket_at / ?) * ? AS bucket, `+metricsAggregateColumns+`
+		   FROM ops_metrics_history
+		  WHERE resolution = ? AND bucket_at >= ? AND bucket_at < ?
+		  GROUP BY bucket
+		  ORDER BY bucket ASC`,
+		size, size, resolution, from.Unix(), to.Unix(),
+	)
+	if err != nil {
+		return MetricsHistory{}, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := MetricsHistory{
+		From:       from,
+		To:         to,
+		Step:       size,
+		Resolution: resolution,
+		Points:     make([]MetricsPoint, 0, 64),
+	}
+	for rows.Next() {
+		var (
+			bucket int64
+			point  MetricsPoint
+		)
+		if err := rows.Scan(&bucket, &point.Samples,
+			&point.CPUPercent, &point.LoadAvg1, &point.MemPercent, &point.MemUsedBytes,
+			&point.SwapPercent, &point.DiskPercent, &point.DiskUsedBytes,
+			&point.NetRxBytes, &point.NetTxBytes, &point.NetRxRate, &point.NetTxRate,
+			&point.NetRxErrors, &point.NetTxErrors, &point.NetSaturation,
+		); err != nil {
+			return MetricsHistory{}, err
+		}
+		point.At = time.Unix(bucket, 0).UTC()
+		out.Points = append(out.Points, point)
+	}
+	return out, rows.Err()
+}
+
+// metricsResolutionFor picks the finest resolution no coarser than step
+// whose oldest bucket reaches back to from. When none covers the range, the
+// resolution with the oldest data wins so long ranges degrade gracefully.
+func (s *Store) metricsResolutionFor(ctx context.Context, from time.Time, step time.Duration) (string, error) {
+	candidates := []struct {
+		resolution string
+		bucket     time.Duration
+	}{
+		{MetricsResolutionRaw, 0},
+		{MetricsResolutionMinute, time.Minute},
+		{MetricsResolutionHour, time.Hour},
+	}
+
+	best := MetricsResolutionRaw
+	var bestOldest int64
+	for _, c := range candidates {
+		if c.bucket > step {
+			break
+		}
+		var oldest sql.NullInt64
+		if err := s.db.QueryRowContext(ctx,
+			`SELECT MIN(bucket_at) FROM ops_metrics_history WHERE resolution = ?`,
+			c.resolution,
+		).Scan(&oldest); err != nil {
+			return "", err
+		}
+		if !oldest.Valid {
+			continue
+		}
+		if oldest.Int64 <= from.Unix() {
+			return c.resolution, nil
+		}
+		if bestOldest == 0 || oldest.Int64 < bestOldest {
+			best, bestOldest = c.resolution, oldest.Int64
+		}
+	}
+	return best, nil
+}
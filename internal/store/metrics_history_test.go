@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMetricsHistoryRollupAndQuery(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	// Two minutes of samples every 2s: CPU 10% in the first minute, 30% in
+	// the second; the rx counter keeps growing and saturation climbs within
+	// each minute.
+	for i := range 60 {
+		at := base.Add(time.Duration(i) * 2 * time.Second)
+		cpu := 10.0
+		if i >= 30 {
+			cpu = 30
+		}
+		sample := MetricsSample{CPUPercent: cpu, NetRxBytes: int64(i), NetRxRate: cpu * 100, NetSaturation: float64(i % 30)}
+		if err := s.RecordMetricsSample(ctx, at, sample); err != nil {
+			t.Fatalf("RecordMetricsSample: %v", err)
+		}
+	}
+
+	now := base.Add(2*time.Minute + 5*time.Second)
+	if err := s.RollupMetricsHistory(ctx, now); err != nil {
+		t.Fatalf("RollupMetricsHistory: %v", err)
+	}
+
+	raw, err := s.QueryMetricsHistory(ctx, base, base.Add(2*time.Minute), 2*time.Second)
+	if err != nil {
+		t.Fatalf("QueryMetricsHistory(raw): %v", err)
+	}
+	if raw.Resolution != MetricsResolutionRaw || len(raw.Points) != 60 {
+		t.Fatalf("raw resolution = %q, points = %d; want raw, 60", raw.Resolution, len(raw.Points))
+	}
+
+	// Once raw samples age out, the same range is served from 1m rollups.
+	if _, err := s.PruneMetricsHistory(ctx, now, MetricsRetention{Raw: time.Second}); err != nil {
+		t.Fatalf("PruneMetricsHistory: %v", err)
+	}
+
+	minutes, err := s.QueryMetricsHistory(ctx, base, base.Add(2*time.Minute), time.Minute)
+	if err != nil {
+		t.Fatalf("QueryMetricsHistory(1m): %v", err)
+	}
+	if minutes.Resolution != MetricsResolutionMinute || len(minutes.Points) != 2 {
+		t.Fatalf("minute resolution = %q, points = %d; want 1m, 2", minutes.Resolution, len(minutes.Points))
+	}
+	first, second := minutes.Points[0], minutes.Points[1]
+	if first.Samples != 30 || first.CPUPercent != 10 || second.CPUPercent != 30 {
+		t.Fatalf("minute points = %+v", minutes.Points)
+	}
+	if second.NetRxBytes != 59 {
+		t.Fatalf("second.NetRxBytes = %d, want max counter 59", second.NetRxBytes)
+	}
+	if second.NetRxRate != 3000 || second.NetSaturation != 29 {
+		t.Fatalf("second rate = %f, saturation = %f; want average 3000 and peak 29", second.NetRxRate, second.NetSaturation)
+	}
+
+	// A 2m step over minute rollups weights both minutes equally.
+	merged, err := s.QueryMetricsHistory(ctx, base, base.Add(2*time.Minute), 2*time.Minute)
+	if err != nil {
+		t.Fatalf("QueryMetricsHistory(2m): %v", err)
+	}
+	if len(merged.Points) != 1 || math.Abs(merged.Points[0].CPUPercent-20) > 1e-9 || merged.Points[0].Samples != 60 {
+		t.Fatalf("merged points = %+v", merged.Points)
+	}
+}
+
+func TestMetricsHistoryHourRollupAndPrune(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		at := base.Add(time.Duration(i) * 20 * time.Minute)
+		if err := s.RecordMetricsSample(ctx, at, MetricsSample{MemPercent: 50}); err != nil {
+			t.Fatalf("RecordMetricsSample: %v", err)
+		}
+		if err := s.RollupMetricsHistory(ctx, at.Add(time.Minute)); err != nil {
+			t.Fatalf("RollupMetricsHistory: %v", err)
+		}
+	}
+	now := base.Add(time.Hour + time.Minute)
+	if err := s.RollupMetricsHistory(ctx, now); err != nil {
+		t.Fatalf("RollupMetricsHistory: %v", err)
+	}
+
+	removed, err := s.PruneMetricsHistory(ctx, now, MetricsRetention{Raw: time.Minute, Minute: time.Minute, Hour: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("PruneMetricsHistory: %v", err)
+	}
+	if removed != 6 {
+		t.Fatalf("removed = %d, want 6 raw and minute rows", removed)
+	}
+
+	hours, err := s.QueryMetricsHistory(ctx, base, now, time.Hour)
+	if err != nil {
+		t.Fatalf("QueryMetricsHistory(1h): %v", err)
+	}
+	if hours.Resolution != MetricsResolutionHour || len(hours.Points) != 1 || hours.Points[0].Samples != 3 {
+		t.Fatalf("hour history = %+v", hours)
+	}
+}
+
+func TestQueryMetricsHistoryRangeAndStep(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := s.QueryMetricsHistory(ctx, now, now, time.Minute); !errors.Is(err, ErrInvalidMetricsRange) {
+		t.Fatalf("error = %v, want ErrInvalidMetricsRange", err)
+	}
+
+	history, err := s.QueryMetricsHistory(ctx, now.Add(-30*24*time.Hour), now, time.Second)
+	if err != nil {
+		t.Fatalf("QueryMetricsHistory: %v", err)
+	}
+	if want := int64((30 * 24 * time.Hour / MaxMetricsHistoryPoints) / time.Second); history.Step < want {
+		t.Fatalf("step = %d, want widened to >= %d", history.Step, want)
+	}
+	if len(history.Points) != 0 {
+		t.Fatalf("points = %d, want 0 on empty store", len(history.Points))
+	}
+}
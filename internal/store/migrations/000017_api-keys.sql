@@ -0,0 +1,13 @@
+-- 000017_api-keys.sql: Named API keys with per-key roles.
+-- Only a SHA-256 digest of each key is stored; the plaintext is shown once
+-- when the key is created.
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           TEXT PRIMARY KEY,
+    name         TEXT NOT NULL COLLATE NOCASE UNIQUE,
+    role         TEXT NOT NULL,
+    token_hash   TEXT NOT NULL UNIQUE,
+    token_prefix TEXT NOT NULL DEFAULT '',
+    created_at   TEXT NOT NULL DEFAULT (datetime('now')),
+    last_used_at TEXT NOT NULL DEFAULT ''
+);
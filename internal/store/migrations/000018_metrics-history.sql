@@ -0,0 +1,20 @@
+-- 000018_metrics-history.sql: Persisted host metrics for historical charts.
+-- Raw samples are rolled up into 1m and 1h buckets; each resolution has its
+-- own retention window. Ratios are sample-weighted averages, counters keep
+-- the bucket maximum.
+
+CREATE TABLE IF NOT EXISTS ops_metrics_history (
+    resolution      TEXT    NOT NULL,
+    bucket_at       INTEGER NOT NULL,
+    samples         INTEGER NOT NULL DEFAULT 1,
+    cpu_percent     REAL    NOT NULL DEFAULT 0,
+    load_avg1       REAL    NOT NULL DEFAULT 0,
+    mem_percent     REAL    NOT NULL DEFAULT 0,
+    mem_used_bytes  INTEGER NOT NULL DEFAULT 0,
+    swap_percent    REAL    NOT NULL DEFAULT 0,
+    disk_percent    REAL    NOT NULL DEFAULT 0,
+    disk_used_bytes INTEGER NOT NULL DEFAULT 0,
+    net_rx_bytes    INTEGER NOT NULL DEFAULT 0,
+    net_tx_bytes    INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (resolution, bucket_at)
+) WITHOUT ROWID;
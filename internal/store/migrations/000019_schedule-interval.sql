@@ -0,0 +1,6 @@
+-- 000019_schedule-interval.sql: Interval schedules ("every 15m").
+-- interval_expr and jitter hold Go duration strings and are only set when
+-- schedule_type = 'interval'.
+
+ALTER TABLE ops_schedules ADD COLUMN interval_expr TEXT NOT NULL DEFAULT '';
+ALTER TABLE ops_schedules ADD COLUMN jitter TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+-- 000020_schedule-concurrency.sql: Per-schedule overlap handling.
+-- concurrency_policy decides what a due schedule does while its previous run
+-- is still in flight: 'forbid' skips, 'allow' runs alongside, 'replace'
+-- cancels the previous run first.
+
+ALTER TABLE ops_schedules ADD COLUMN concurrency_policy TEXT NOT NULL DEFAULT 'forbid';
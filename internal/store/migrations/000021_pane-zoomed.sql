@@ -0,0 +1,4 @@
+-- 000021_pane-zoomed.sql: Project tmux pane zoom state.
+-- zoomed is 1 for the pane filling its window through resize-pane -Z.
+
+ALTER TABLE wt_panes ADD COLUMN zoomed INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- 000022_session-tags.sql: Free-form session tags and a group name.
+-- tags holds a JSON array of lowercase tag strings.
+
+ALTER TABLE sessions ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE sessions ADD COLUMN group_name TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- 000023_session-notes.sql: Free-form markdown notes per session.
+
+ALTER TABLE sessions ADD COLUMN notes TEXT NOT NULL DEFAULT '';
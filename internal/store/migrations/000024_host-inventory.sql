@@ -0,0 +1,15 @@
+-- 000024_host-inventory.sql: Federated host labels and label targeting.
+-- ops_hosts keeps the labels of each host by name, so a host that is offline
+-- still shows in the inventory. labels holds a JSON object of strings.
+-- hosts on schedules and runs is a label selector ("role=web") that targets
+-- the run's service steps at the matching hosts.
+
+CREATE TABLE IF NOT EXISTS ops_hosts (
+    name       TEXT PRIMARY KEY,
+    labels     TEXT NOT NULL DEFAULT '{}',
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+ALTER TABLE ops_schedules ADD COLUMN hosts TEXT NOT NULL DEFAULT '';
+ALTER TABLE ops_runbook_runs ADD COLUMN hosts TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,17 @@
+-- 000025_schedule-runs.sql: History of scheduler-triggered executions.
+-- One row per fire: outcome starts as 'running' (or 'skipped' when the
+-- concurrency policy dropped the run) and takes the job's final status when
+-- it finishes. duration_ms counts from the fire time to completion.
+
+CREATE TABLE IF NOT EXISTS ops_schedule_runs (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    schedule_id TEXT    NOT NULL,
+    job_id      TEXT    NOT NULL DEFAULT '',
+    fired_at    TEXT    NOT NULL,
+    finished_at TEXT    NOT NULL DEFAULT '',
+    outcome     TEXT    NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_schedule_runs_schedule
+    ON ops_schedule_runs (schedule_id, id DESC);
@@ -0,0 +1,19 @@
+-- 000026_webhooks.sql: Inbound webhooks that trigger a runbook.
+-- External systems sign each request with the per-hook secret (HMAC-SHA256
+-- of the body). parameters is a JSON object of runbook parameters applied
+-- to every triggered run.
+
+CREATE TABLE IF NOT EXISTS ops_webhooks (
+    id                TEXT    PRIMARY KEY,
+    name              TEXT    NOT NULL UNIQUE,
+    secret            TEXT    NOT NULL,
+    runbook_id        TEXT    NOT NULL,
+    parameters        TEXT    NOT NULL DEFAULT '{}',
+    enabled           INTEGER NOT NULL DEFAULT 1,
+    created_at        TEXT    NOT NULL,
+    updated_at        TEXT    NOT NULL,
+    last_triggered_at TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_webhooks_runbook
+    ON ops_webhooks (runbook_id);
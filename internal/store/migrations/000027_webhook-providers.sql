@@ -0,0 +1,10 @@
+-- 000027_webhook-providers.sql: GitHub and GitLab webhooks.
+-- provider selects how requests are verified and read: 'generic' (signed
+-- body with optional parameters), 'github' or 'gitlab'. For the git
+-- providers, repository and branch filter the events ('' matches any) and
+-- events lists the accepted kinds (push, tag, release), comma-separated.
+
+ALTER TABLE ops_webhooks ADD COLUMN provider   TEXT NOT NULL DEFAULT 'generic';
+ALTER TABLE ops_webhooks ADD COLUMN repository TEXT NOT NULL DEFAULT '';
+ALTER TABLE ops_webhooks ADD COLUMN branch     TEXT NOT NULL DEFAULT '';
+ALTER TABLE ops_webhooks ADD COLUMN events     TEXT NOT NULL DEFAULT '';
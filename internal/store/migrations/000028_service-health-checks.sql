@@ -0,0 +1,4 @@
+-- 000028_service-health-checks.sql: optional health checks per custom
+-- service, stored as a JSON array of HTTP, TCP and command checks.
+
+ALTER TABLE ops_custom_services ADD COLUMN health_checks TEXT NOT NULL DEFAULT '[]';
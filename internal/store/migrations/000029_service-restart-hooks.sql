@@ -0,0 +1,4 @@
+-- 000029_service-restart-hooks.sql: optional pre-stop and post-start hook
+-- commands run around a custom service restart, stored as a JSON object.
+
+ALTER TABLE ops_custom_services ADD COLUMN restart_hooks TEXT NOT NULL DEFAULT '{}';
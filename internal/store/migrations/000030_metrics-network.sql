@@ -0,0 +1,9 @@
+-- 000030_metrics-network.sql: network throughput, error counters and peak
+-- interface saturation in metrics history. Rates are sample-weighted
+-- averages; error counters and saturation keep the bucket maximum.
+
+ALTER TABLE ops_metrics_history ADD COLUMN net_rx_rate REAL NOT NULL DEFAULT 0;
+ALTER TABLE ops_metrics_history ADD COLUMN net_tx_rate REAL NOT NULL DEFAULT 0;
+ALTER TABLE ops_metrics_history ADD COLUMN net_rx_errors INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE ops_metrics_history ADD COLUMN net_tx_errors INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE ops_metrics_history ADD COLUMN net_saturation_percent REAL NOT NULL DEFAULT 0;
@@ -0,0 +1,15 @@
+-- 000031_package-updates-runbook.sql: built-in runbook that applies pending
+-- apt or dnf package updates after an approval and reports whether the host
+-- needs a reboot.
+
+INSERT OR IGNORE INTO ops_runbooks(
+    id, name, description, steps_json, enabled, created_at, updated_at
+) VALUES (
+    'ops.packages.upgrade',
+    'Apply Package Updates',
+    'List pending apt or dnf updates, upgrade all packages after approval, and check whether a reboot is required. Needs passwordless sudo.',
+    '[{"type":"run","title":"List pending updates","command":"if command -v apt >/dev/null 2>&1; then apt list --upgradable 2>/dev/null; else dnf -q check-update || [ $? -eq 100 ]; fi"},{"type":"approval","title":"Approve upgrade","description":"Review the pending updates. Services may restart while packages upgrade."},{"type":"run","title":"Upgrade packages","command":"if command -v apt-get >/dev/null 2>&1; then sudo -n apt-get update && sudo -n env DEBIAN_FRONTEND=noninteractive apt-get -y upgrade; else sudo -n dnf -y upgrade; fi","timeout":1800},{"type":"run","title":"Check reboot required","command":"if [ -e /var/run/reboot-required ]; then cat /var/run/reboot-required; elif command -v needs-restarting >/dev/null 2>&1; then needs-restarting -r || true; else echo No reboot required; fi"}]',
+    1,
+    datetime('now'),
+    datetime('now')
+);
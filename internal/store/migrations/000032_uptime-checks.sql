@@ -0,0 +1,22 @@
+-- 000032_uptime-checks.sql: HTTP, TCP and ICMP uptime checks of URLs and
+-- endpoints. The last_* columns hold the outcome of the latest run, written
+-- by the checker; last_change_at is when the status last flipped.
+
+CREATE TABLE IF NOT EXISTS ops_uptime_checks (
+    id               TEXT    PRIMARY KEY,
+    name             TEXT    NOT NULL UNIQUE,
+    type             TEXT    NOT NULL,
+    target           TEXT    NOT NULL,
+    interval_seconds INTEGER NOT NULL DEFAULT 60,
+    timeout_seconds  INTEGER NOT NULL DEFAULT 10,
+    expect_status    INTEGER NOT NULL DEFAULT 0,
+    expect_body      TEXT    NOT NULL DEFAULT '',
+    enabled          INTEGER NOT NULL DEFAULT 1,
+    created_at       TEXT    NOT NULL,
+    updated_at       TEXT    NOT NULL,
+    last_status      TEXT    NOT NULL DEFAULT 'pending',
+    last_detail      TEXT    NOT NULL DEFAULT '',
+    last_latency_ms  INTEGER NOT NULL DEFAULT 0,
+    last_checked_at  TEXT    NOT NULL DEFAULT '',
+    last_change_at   TEXT    NOT NULL DEFAULT ''
+);
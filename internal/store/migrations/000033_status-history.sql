@@ -0,0 +1,14 @@
+-- 000033_status-history.sql: daily up/down sample counts behind the status
+-- page history bars. kind is "uptime" (name holds the check id) or "service"
+-- (name holds the tracked service name); day is a UTC YYYY-MM-DD date.
+
+CREATE TABLE IF NOT EXISTS ops_status_history (
+    kind TEXT    NOT NULL,
+    name TEXT    NOT NULL,
+    day  TEXT    NOT NULL,
+    up   INTEGER NOT NULL DEFAULT 0,
+    down INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (kind, name, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_status_history_day ON ops_status_history(day);
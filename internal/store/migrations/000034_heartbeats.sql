@@ -0,0 +1,17 @@
+-- 000034_heartbeats.sql: heartbeat check-ins (dead man's switches). External
+-- jobs ping POST /api/hooks/heartbeat/{id}; the random id doubles as the
+-- credential. status is 'new' until the first ping, then 'up', or 'missed'
+-- once no ping arrived within interval_seconds + grace_seconds of the last.
+
+CREATE TABLE IF NOT EXISTS ops_heartbeats (
+    id               TEXT    PRIMARY KEY,
+    name             TEXT    NOT NULL UNIQUE,
+    interval_seconds INTEGER NOT NULL,
+    grace_seconds    INTEGER NOT NULL DEFAULT 0,
+    enabled          INTEGER NOT NULL DEFAULT 1,
+    created_at       TEXT    NOT NULL,
+    updated_at       TEXT    NOT NULL,
+    status           TEXT    NOT NULL DEFAULT 'new',
+    last_ping_at     TEXT    NOT NULL DEFAULT '',
+    last_change_at   TEXT    NOT NULL DEFAULT ''
+);
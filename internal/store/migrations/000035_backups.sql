@@ -0,0 +1,29 @@
+-- 000035_backups.sql: restic and borg backups. Each backup runs through a
+-- managed runbook on its cron schedule; status is 'new' until the first
+-- run, then 'running', 'succeeded' or 'failed'. stale is set once the last
+-- success (or creation) is older than max_age_hours and cleared by the next
+-- success. stats holds the figures parsed from the last successful run.
+
+CREATE TABLE IF NOT EXISTS ops_backups (
+    id               TEXT    PRIMARY KEY,
+    name             TEXT    NOT NULL UNIQUE,
+    tool             TEXT    NOT NULL,
+    repository       TEXT    NOT NULL,
+    sources          TEXT    NOT NULL DEFAULT '[]',
+    command          TEXT    NOT NULL DEFAULT '',
+    schedule         TEXT    NOT NULL,
+    timezone         TEXT    NOT NULL DEFAULT 'UTC',
+    max_age_hours    INTEGER NOT NULL,
+    timeout_minutes  INTEGER NOT NULL,
+    enabled          INTEGER NOT NULL DEFAULT 1,
+    created_at       TEXT    NOT NULL,
+    updated_at       TEXT    NOT NULL,
+    status           TEXT    NOT NULL DEFAULT 'new',
+    stale            INTEGER NOT NULL DEFAULT 0,
+    last_job_id      TEXT    NOT NULL DEFAULT '',
+    last_run_at      TEXT    NOT NULL DEFAULT '',
+    last_finished_at TEXT    NOT NULL DEFAULT '',
+    last_success_at  TEXT    NOT NULL DEFAULT '',
+    last_error       TEXT    NOT NULL DEFAULT '',
+    stats            TEXT    NOT NULL DEFAULT '{}'
+);
@@ -0,0 +1,10 @@
+-- 000036_secrets.sql: named credentials referenced from runbook steps and
+-- notification settings as {{secret "name"}}. value holds the AES-GCM
+-- nonce and ciphertext; the key never reaches the database.
+
+CREATE TABLE IF NOT EXISTS ops_secrets (
+    name       TEXT PRIMARY KEY,
+    value      BLOB NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
@@ -0,0 +1,7 @@
+-- 000037_library.sql: Runbooks and schedules loaded from the runbook
+-- library. managed_by holds the path of the YAML file, relative to the
+-- library directory, that owns the row; it is empty for rows managed in
+-- the UI or API, which cannot change a file-managed row.
+
+ALTER TABLE ops_runbooks ADD COLUMN managed_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE ops_schedules ADD COLUMN managed_by TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+-- 000038_watch-rules.sql: watchtower watch rules. Each rule is a regular
+-- expression matched against new lines of captured pane output, optionally
+-- limited to one session or pane. A match is published as
+-- tmux.watch.matched.
+
+CREATE TABLE IF NOT EXISTS tmux_watch_rules (
+    id           TEXT    PRIMARY KEY,
+    name         TEXT    NOT NULL UNIQUE,
+    pattern      TEXT    NOT NULL,
+    severity     TEXT    NOT NULL DEFAULT 'warning',
+    session_name TEXT    NOT NULL DEFAULT '',
+    pane_id      TEXT    NOT NULL DEFAULT '',
+    enabled      INTEGER NOT NULL DEFAULT 1,
+    created_at   TEXT    NOT NULL,
+    updated_at   TEXT    NOT NULL
+);
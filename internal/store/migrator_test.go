@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"path/filepath"
 	"testing"
 
@@ -27,8 +28,8 @@ func TestRunMigrationsFreshDB(t *testing.T) {
 	).Scan(&version, &name); err != nil {
 		t.Fatalf("query schema_migrations: %v", err)
 	}
-	if version != 16 || name != "update-apply-runbook" {
-		t.Fatalf("latest migration = (%d, %q), want (16, %q)", version, name, "update-apply-runbook")
+	if version != 38 || name != "watch-rules" {
+		t.Fatalf("latest migration = (%d, %q), want (38, %q)", version, name, "watch-rules")
 	}
 
 	// Spot-check that a few tables exist.
@@ -64,8 +65,8 @@ func TestRunMigrationsIdempotent(t *testing.T) {
 	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&count); err != nil {
 		t.Fatalf("count schema_migrations: %v", err)
 	}
-	if count != 13 {
-		t.Fatalf("schema_migrations rows = %d, want 13", count)
+	if count != 35 {
+		t.Fatalf("schema_migrations rows = %d, want 35", count)
 	}
 }
 
@@ -84,8 +85,16 @@ func TestRunMigrationsSeedData(t *testing.T) {
 	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ops_runbooks").Scan(&runbookCount); err != nil {
 		t.Fatalf("count ops_runbooks: %v", err)
 	}
-	if runbookCount != 3 {
-		t.Fatalf("ops_runbooks count = %d, want 3", runbookCount)
+	if runbookCount != 4 {
+		t.Fatalf("ops_runbooks count = %d, want 4", runbookCount)
+	}
+	var upgradeSteps []OpsRunbookStep
+	var stepsJSON string
+	if err := db.QueryRowContext(ctx, "SELECT steps_json FROM ops_runbooks WHERE id = 'ops.packages.upgrade'").Scan(&stepsJSON); err != nil {
+		t.Fatalf("select ops.packages.upgrade: %v", err)
+	}
+	if err := json.Unmarshal([]byte(stepsJSON), &upgradeSteps); err != nil || len(upgradeSteps) != 4 || upgradeSteps[1].Type != "approval" {
+		t.Fatalf("ops.packages.upgrade steps = %+v (%v), want 4 steps with an approval", upgradeSteps, err)
 	}
 
 	// Runbooks have webhook_url column.
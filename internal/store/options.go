@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Journal modes and synchronous levels accepted in Options.
+var (
+	JournalModes      = []string{"wal", "delete", "truncate", "persist"}
+	SynchronousLevels = []string{"off", "normal", "full", "extra"}
+)
+
+// Default connection settings: WAL lets readers run alongside a writer,
+// and NORMAL sync is durable in WAL mode short of a power loss.
+const (
+	DefaultJournalMode = "wal"
+	DefaultSynchronous = "normal"
+	DefaultBusyTimeout = 5 * time.Second
+)
+
+// Options tunes the SQLite connection. Empty fields take the defaults; a
+// zero CacheSize keeps SQLite's own.
+type Options struct {
+	JournalMode string
+	Synchronous string
+	BusyTimeout time.Duration
+	// CacheSize is PRAGMA cache_size: pages when positive, KiB when
+	// negative.
+	CacheSize int
+}
+
+func (o Options) withDefaults() Options {
+	o.JournalMode = strings.ToLower(strings.TrimSpace(o.JournalMode))
+	if o.JournalMode == "" {
+		o.JournalMode = DefaultJournalMode
+	}
+	o.Synchronous = strings.ToLower(strings.TrimSpace(o.Synchronous))
+	if o.Synchronous == "" {
+		o.Synchronous = DefaultSynchronous
+	}
+	if o.BusyTimeout <= 0 {
+		o.BusyTimeout = DefaultBusyTimeout
+	}
+	return o
+}
+
+// pragmas returns the statements that apply o to a connection.
+func (o Options) pragmas() ([]string, error) {
+	if !slices.Contains(JournalModes, o.JournalMode) {
+		return nil, fmt.Errorf("journal mode %q is not one of %s", o.JournalMode, strings.Join(JournalModes, ", "))
+	}
+	if !slices.Contains(SynchronousLevels, o.Synchronous) {
+		return nil, fmt.Errorf("synchronous %q is not one of %s", o.Synchronous, strings.Join(SynchronousLevels, ", "))
+	}
+	pragmas := []string{
+		"PRAGMA journal_mode=" + o.JournalMode,
+		fmt.Sprintf("PRAGMA busy_timeout=%d", o.BusyTimeout.Milliseconds()),
+		"PRAGMA synchronous=" + o.Synchronous,
+	}
+	if o.CacheSize != 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA cache_size=%d", o.CacheSize))
+	}
+	return pragmas, nil
+}
+
+// StorageDatabaseStats reports the connection settings in effect and how
+// much queries contend for the connection. Every query shares one
+// connection, so WaitCount and WaitDurationMs grow whenever a query had to
+// queue behind another, such as an API read behind a watchtower write.
+type StorageDatabaseStats struct {
+	JournalMode     string `json:"journalMode"`
+	Synchronous     string `json:"synchronous"`
+	BusyTimeoutMs   int64  `json:"busyTimeoutMs"`
+	CacheSize       int64  `json:"cacheSize"`
+	OpenConnections int    `json:"openConnections"`
+	InUse           int    `json:"inUse"`
+	WaitCount       int64  `json:"waitCount"`
+	WaitDurationMs  int64  `json:"waitDurationMs"`
+}
+
+func (s *Store) databaseStats(ctx context.Context) (StorageDatabaseStats, error) {
+	var (
+		stats       StorageDatabaseStats
+		synchronous int
+	)
+	// Read the pragmas on one connection, so the pool statistics below
+	// count this query only once.
+	err := withConn(ctx, s.db, func(conn *sql.Conn) error {
+		if err := conn.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&stats.JournalMode); err != nil {
+			return err
+		}
+		if err := conn.QueryRowContext(ctx, "PRAGMA synchronous").Scan(&synchronous); err != nil {
+			return err
+		}
+		if err := conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&stats.BusyTimeoutMs); err != nil {
+			return err
+		}
+		return conn.QueryRowContext(ctx, "PRAGMA cache_size").Scan(&stats.CacheSize)
+	})
+	if err != nil {
+		return StorageDatabaseStats{}, fmt.Errorf("read pragmas: %w", err)
+	}
+	if synchronous >= 0 && synchronous < len(SynchronousLevels) {
+		stats.Synchronous = SynchronousLevels[synchronous]
+	}
+
+	pool := s.db.Stats()
+	stats.OpenConnections = pool.OpenConnections
+	stats.InUse = pool.InUse
+	stats.WaitCount = pool.WaitCount
+	stats.WaitDurationMs = pool.WaitDuration.Milliseconds()
+	return stats, nil
+}
+
+func withConn(ctx context.Context, db *sql.DB, fn func(*sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	return fn(conn)
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenAppliesOptions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	stats, err := s.GetStorageStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStorageStats() error = %v", err)
+	}
+	db := stats.Database
+	if db.JournalMode != "wal" || db.Synchronous != "normal" || db.BusyTimeoutMs != 5000 || db.OpenConnections != 1 {
+		t.Fatalf("default database stats = %+v", db)
+	}
+
+	tuned, err := Open(filepath.Join(t.TempDir(), "sentinel.db"), Options{
+		JournalMode: "DELETE",
+		Synchronous: "full",
+		BusyTimeout: 2 * time.Second,
+		CacheSize:   -4096,
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer func() { _ = tuned.Close() }()
+	stats, err = tuned.GetStorageStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStorageStats(tuned) error = %v", err)
+	}
+	db = stats.Database
+	if db.JournalMode != "delete" || db.Synchronous != "full" || db.BusyTimeoutMs != 2000 || db.CacheSize != -4096 {
+		t.Fatalf("tuned database stats = %+v", db)
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "sentinel.db"), Options{JournalMode: "memory"}); err == nil {
+		t.Fatal("Open(journal_mode=memory) succeeded")
+	}
+	if _, err := Open(filepath.Join(t.TempDir(), "sentinel.db"), Options{Synchronous: "sometimes"}); err == nil {
+		t.Fatal("Open(synchronous=sometimes) succeeded")
+	}
+}
+
+func TestStorageStatsCountConnectionWaits(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+
+	// Hold the only connection so the next query has to queue for it.
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.ListOpsHosts(ctx)
+		done <- err
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for s.db.Stats().WaitCount == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	_ = conn.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("ListOpsHosts() error = %v", err)
+	}
+
+	stats, err := s.GetStorageStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStorageStats() error = %v", err)
+	}
+	if stats.Database.WaitCount < 1 || stats.Database.WaitDurationMs < 10 {
+		t.Fatalf("database stats = %+v, want the queued query counted", stats.Database)
+	}
+}
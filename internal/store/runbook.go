@@ -32,6 +32,49 @@ type OpsRunbookStep struct {
 	Timeout         int    `json:"timeout,omitempty"`
 	Retries         int    `json:"retries,omitempty"`
 	RetryDelay      int    `json:"retryDelay,omitempty"`
+
+	// Retry policy: each retry waits RetryBackoff times longer than the
+	// previous one, up to RetryMaxDelay seconds. With RetryOnExitCodes set,
+	// only a command failing with one of those exit codes is retried.
+	RetryBackoff     float64 `json:"retryBackoff,omitempty"`
+	RetryMaxDelay    int     `json:"retryMaxDelay,omitempty"`
+	RetryOnExitCodes []int   `json:"retryOnExitCodes,omitempty"`
+
+	// run, script and wait steps: run the command with Shell (default sh)
+	// in the Cwd directory, as RunAsUser when set.
+	Cwd       string `json:"cwd,omitempty"`
+	RunAsUser string `json:"runAsUser,omitempty"`
+	Shell     string `json:"shell,omitempty"`
+
+	// http steps.
+	URL          string `json:"url,omitempty"`
+	Method       string `json:"method,omitempty"`
+	Body         string `json:"body,omitempty"`
+	ExpectStatus int    `json:"expectStatus,omitempty"`
+
+	// tmux.send steps.
+	Target string `json:"target,omitempty"`
+	Keys   string `json:"keys,omitempty"`
+	Enter  bool   `json:"enter,omitempty"`
+
+	// tmux.exec steps: run Command in Window of Session, creating either
+	// when missing, and wait for Marker in the pane output when set.
+	Session string `json:"session,omitempty"`
+	Window  string `json:"window,omitempty"`
+	Marker  string `json:"marker,omitempty"`
+
+	// wait steps: sleep for Duration seconds, or poll Command every
+	// Interval seconds until it succeeds.
+	Duration int `json:"duration,omitempty"`
+	Interval int `json:"interval,omitempty"`
+
+	// service steps: run Action on Unit at the hosts matching the Hosts
+	// label selector.
+	Hosts   string `json:"hosts,omitempty"`
+	Unit    string `json:"unit,omitempty"`
+	Action  string `json:"action,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+	Manager string `json:"manager,omitempty"`
 }
 
 // RunbookParameter defines a single parameter that a runbook accepts.
@@ -54,8 +97,10 @@ type OpsRunbook struct {
 	WebhookURL  string             `json:"webhookURL"`
 	Steps       []OpsRunbookStep   `json:"steps"`
 	Parameters  []RunbookParameter `json:"parameters"`
-	CreatedAt   string             `json:"createdAt"`
-	UpdatedAt   string             `json:"updatedAt"`
+	// ManagedBy is the library file that owns the runbook, if any.
+	ManagedBy string `json:"managedBy,omitempty"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
 }
 
 // OpsRunbookStepResult represents ops runbook step result data.
@@ -66,6 +111,10 @@ type OpsRunbookStepResult struct {
 	Output     string `json:"output"`
 	Error      string `json:"error"`
 	DurationMs int64  `json:"durationMs"`
+	// Retries counts the attempts after the first; ExitCode is the exit
+	// status of a failed command.
+	Retries  int `json:"retries,omitempty"`
+	ExitCode int `json:"exitCode,omitempty"`
 }
 
 // OpsRunbookRun represents ops runbook run data.
@@ -80,6 +129,7 @@ type OpsRunbookRun struct {
 	Error          string                 `json:"error"`
 	StepResults    []OpsRunbookStepResult `json:"stepResults"`
 	ParametersUsed map[string]string      `json:"parametersUsed"`
+	Hosts          string                 `json:"hosts,omitempty"`
 	CreatedAt      string                 `json:"createdAt"`
 	StartedAt      string                 `json:"startedAt,omitempty"`
 	FinishedAt     string                 `json:"finishedAt,omitempty"`
@@ -94,6 +144,9 @@ type OpsRunbookWrite struct {
 	Parameters  []RunbookParameter
 	Enabled     bool
 	WebhookURL  string
+	// ManagedBy marks an inserted runbook as owned by a library file;
+	// updates keep it.
+	ManagedBy string
 }
 
 // OpsRunbookDeleteResult describes an atomic runbook deletion.
@@ -101,6 +154,7 @@ type OpsRunbookDeleteResult struct {
 	ID               string `json:"id"`
 	Name             string `json:"name"`
 	DeletedSchedules int64  `json:"deletedSchedules"`
+	DeletedWebhooks  int64  `json:"deletedWebhooks"`
 }
 
 // OpsRunbookRunUpdate represents ops runbook run update data.
@@ -134,7 +188,7 @@ var ErrOpsRunbookNameMismatch = errors.New("ops runbook confirmation name does n
 // ListOpsRunbooks lists ops runbooks.
 func (s *Store) ListOpsRunbooks(ctx context.Context) ([]OpsRunbook, error) {
 	rows, err := s.db.QueryContext(ctx, `SELECT
-		id, name, description, steps_json, enabled, webhook_url, parameters, created_at, updated_at
+		id, name, description, steps_json, enabled, webhook_url, parameters, managed_by, created_at, updated_at
 	FROM ops_runbooks
 	ORDER BY name ASC`)
 	if err != nil {
@@ -158,6 +212,7 @@ func (s *Store) ListOpsRunbooks(ctx context.Context) ([]OpsRunbook, error) {
 			&enabled,
 			&item.WebhookURL,
 			&paramsJSON,
+			&item.ManagedBy,
 			&item.CreatedAt,
 			&item.UpdatedAt,
 		); err != nil {
@@ -208,8 +263,8 @@ func (s *Store) StartOpsRunbook(ctx context.Context, runbookID string, at time.T
 	defer func() { _ = tx.Rollback() }()
 
 	if _, err := tx.ExecContext(ctx, `INSERT INTO ops_runbook_runs (
-		id, runbook_id, runbook_name, status, total_steps, completed_steps, current_step, error, step_results, parameters_used, created_at, started_at, finished_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, '', '[]', '{}', ?, '', '')`,
+		id, runbook_id, runbook_name, status, total_steps, completed_steps, current_step, error, step_results, parameters_used, hosts, created_at, started_at, finished_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, '', '[]', '{}', '', ?, '', '')`,
 		runID,
 		runbook.ID,
 		runbook.Name,
@@ -265,7 +320,7 @@ func (s *Store) ListOpsRunbookRuns(ctx context.Context, limit int) ([]OpsRunbook
 		limit = 500
 	}
 	rows, err := s.db.QueryContext(ctx, `SELECT
-		id, runbook_id, runbook_name, status, total_steps, completed_steps, current_step, error, step_results, parameters_used, created_at, started_at, finished_at
+		id, runbook_id, runbook_name, status, total_steps, completed_steps, current_step, error, step_results, parameters_used, hosts, created_at, started_at, finished_at
 	FROM ops_runbook_runs
 	ORDER BY created_at DESC, id DESC
 	LIMIT ?`, limit)
@@ -295,7 +350,7 @@ func (s *Store) GetOpsRunbookRun(ctx context.Context, runID string) (OpsRunbookR
 		return OpsRunbookRun{}, sql.ErrNoRows
 	}
 	rows, err := s.db.QueryContext(ctx, `SELECT
-		id, runbook_id, runbook_name, status, total_steps, completed_steps, current_step, error, step_results, parameters_used, created_at, started_at, finished_at
+		id, runbook_id, runbook_name, status, total_steps, completed_steps, current_step, error, step_results, parameters_used, hosts, created_at, started_at, finished_at
 	FROM ops_runbook_runs
 	WHERE id = ?
 	LIMIT 1`, runID)
@@ -334,7 +389,7 @@ func (s *Store) getOpsRunbookByID(ctx context.Context, runbookID string) (OpsRun
 		enabled   int
 	)
 	err := s.db.QueryRowContext(ctx, `SELECT
-		id, name, description, steps_json, enabled, webhook_url, parameters, created_at, updated_at
+		id, name, description, steps_json, enabled, webhook_url, parameters, managed_by, created_at, updated_at
 	FROM ops_runbooks
 	WHERE id = ?`, runbookID).Scan(
 		&out.ID,
@@ -344,6 +399,7 @@ func (s *Store) getOpsRunbookByID(ctx context.Context, runbookID string) (OpsRun
 		&enabled,
 		&out.WebhookURL,
 		&paramsRaw,
+		&out.ManagedBy,
 		&out.CreatedAt,
 		&out.UpdatedAt,
 	)
@@ -381,6 +437,7 @@ func scanOpsRunbookRun(scanner opsRunbookRunScanner) (OpsRunbookRun, error) {
 		&out.Error,
 		&stepResultsRaw,
 		&paramsUsedRaw,
+		&out.Hosts,
 		&out.CreatedAt,
 		&out.StartedAt,
 		&out.FinishedAt,
@@ -428,9 +485,9 @@ func (s *Store) InsertOpsRunbook(ctx context.Context, w OpsRunbookWrite) (OpsRun
 		enabled = 1
 	}
 	if _, err := s.db.ExecContext(ctx, `INSERT INTO ops_runbooks (
-		id, name, description, steps_json, enabled, webhook_url, parameters, created_at, updated_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		id, name, strings.TrimSpace(w.Description), string(stepsJSON), enabled, strings.TrimSpace(w.WebhookURL), string(paramsJSON), now, now,
+		id, name, description, steps_json, enabled, webhook_url, parameters, managed_by, created_at, updated_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, name, strings.TrimSpace(w.Description), string(stepsJSON), enabled, strings.TrimSpace(w.WebhookURL), string(paramsJSON), w.ManagedBy, now, now,
 	); err != nil {
 		return OpsRunbook{}, err
 	}
@@ -527,13 +584,21 @@ func (s *Store) DeleteOpsRunbook(ctx context.Context, id, expectedName string) (
 	if err != nil {
 		return OpsRunbookDeleteResult{}, err
 	}
+	webhooks, err := tx.ExecContext(ctx, "DELETE FROM ops_webhooks WHERE runbook_id = ?", id)
+	if err != nil {
+		return OpsRunbookDeleteResult{}, err
+	}
+	deletedWebhooks, err := webhooks.RowsAffected()
+	if err != nil {
+		return OpsRunbookDeleteResult{}, err
+	}
 	if _, err := tx.ExecContext(ctx, "DELETE FROM ops_runbooks WHERE id = ?", id); err != nil {
 		return OpsRunbookDeleteResult{}, err
 	}
 	if err := tx.Commit(); err != nil {
 		return OpsRunbookDeleteResult{}, err
 	}
-	return OpsRunbookDeleteResult{ID: id, Name: name, DeletedSchedules: deletedSchedules}, nil
+	return OpsRunbookDeleteResult{ID: id, Name: name, DeletedSchedules: deletedSchedules, DeletedWebhooks: deletedWebhooks}, nil
 }
 
 // UpdateOpsRunbookRun updates ops runbook run.
@@ -608,8 +673,8 @@ func (s *Store) CreateOpsRunbookRun(ctx context.Context, runbookID string, at ti
 		currentStep = runbook.Steps[0].Title
 	}
 	if _, err := s.db.ExecContext(ctx, `INSERT INTO ops_runbook_runs (
-		id, runbook_id, runbook_name, status, total_steps, completed_steps, current_step, error, step_results, parameters_used, created_at, started_at, finished_at
-	) VALUES (?, ?, ?, ?, ?, 0, ?, '', '[]', '{}', ?, '', '')`,
+		id, runbook_id, runbook_name, status, total_steps, completed_steps, current_step, error, step_results, parameters_used, hosts, created_at, started_at, finished_at
+	) VALUES (?, ?, ?, ?, ?, 0, ?, '', '[]', '{}', '', ?, '', '')`,
 		runID, runbook.ID, runbook.Name, opsRunbookStatusQueued, totalSteps, currentStep, now.Format(time.RFC3339),
 	); err != nil {
 		return OpsRunbookRun{}, err
@@ -620,6 +685,13 @@ func (s *Store) CreateOpsRunbookRun(ctx context.Context, runbookID string, at ti
 // CreateOpsRunbookRunWithParams creates a new run record and stores the
 // parameter values that were supplied by the caller.
 func (s *Store) CreateOpsRunbookRunWithParams(ctx context.Context, runbookID string, at time.Time, params map[string]string) (OpsRunbookRun, error) {
+	return s.CreateOpsRunbookRunForHosts(ctx, runbookID, at, params, "")
+}
+
+// CreateOpsRunbookRunForHosts creates a run like
+// CreateOpsRunbookRunWithParams whose service steps target the hosts
+// matching the hosts label selector instead of their own.
+func (s *Store) CreateOpsRunbookRunForHosts(ctx context.Context, runbookID string, at time.Time, params map[string]string, hosts string) (OpsRunbookRun, error) {
 	runbookID = strings.TrimSpace(runbookID)
 	if runbookID == "" {
 		return OpsRunbookRun{}, sql.ErrNoRows
@@ -646,9 +718,9 @@ func (s *Store) CreateOpsRunbookRunWithParams(ctx context.Context, runbookID str
 		return OpsRunbookRun{}, fmt.Errorf("marshal parameters: %w", err)
 	}
 	if _, err := s.db.ExecContext(ctx, `INSERT INTO ops_runbook_runs (
-		id, runbook_id, runbook_name, status, total_steps, completed_steps, current_step, error, step_results, parameters_used, created_at, started_at, finished_at
-	) VALUES (?, ?, ?, ?, ?, 0, ?, '', '[]', ?, ?, '', '')`,
-		runID, rb.ID, rb.Name, opsRunbookStatusQueued, totalSteps, currentStep, string(paramsJSON), now.Format(time.RFC3339),
+		id, runbook_id, runbook_name, status, total_steps, completed_steps, current_step, error, step_results, parameters_used, hosts, created_at, started_at, finished_at
+	) VALUES (?, ?, ?, ?, ?, 0, ?, '', '[]', ?, ?, ?, '', '')`,
+		runID, rb.ID, rb.Name, opsRunbookStatusQueued, totalSteps, currentStep, string(paramsJSON), strings.TrimSpace(hosts), now.Format(time.RFC3339),
 	); err != nil {
 		return OpsRunbookRun{}, err
 	}
@@ -6,19 +6,35 @@ import (
 	"time"
 )
 
+// Schedule concurrency policies decide what a due schedule does while its
+// previous run is still in flight.
+const (
+	// ScheduleConcurrencyForbid skips the new run (the default).
+	ScheduleConcurrencyForbid = "forbid"
+	// ScheduleConcurrencyAllow starts the new run alongside the old one.
+	ScheduleConcurrencyAllow = "allow"
+	// ScheduleConcurrencyReplace cancels the old run and starts the new one.
+	ScheduleConcurrencyReplace = "replace"
+)
+
 // OpsSchedule represents a schedule attached to a runbook.
 type OpsSchedule struct {
 	ID            string `json:"id"`
 	RunbookID     string `json:"runbookId"`
 	Name          string `json:"name"`
-	ScheduleType  string `json:"scheduleType"` // "cron" or "once"
-	CronExpr      string `json:"cronExpr"`     // 5-field cron expression
-	Timezone      string `json:"timezone"`     // IANA timezone
-	RunAt         string `json:"runAt"`        // ISO8601 for type="once"
+	ScheduleType  string `json:"scheduleType"`      // "cron", "once" or "interval"
+	CronExpr      string `json:"cronExpr"`          // 5-field cron expression
+	Timezone      string `json:"timezone"`          // IANA timezone
+	RunAt         string `json:"runAt"`             // ISO8601 for type="once"
+	Interval      string `json:"interval"`          // Go duration for type="interval"
+	Jitter        string `json:"jitter"`            // optional random delay added to each interval
+	Concurrency   string `json:"concurrencyPolicy"` // see ScheduleConcurrency*
+	Hosts         string `json:"hosts"`             // host label selector for service steps
 	Enabled       bool   `json:"enabled"`
 	LastRunAt     string `json:"lastRunAt"`
 	LastRunStatus string `json:"lastRunStatus"`
 	NextRunAt     string `json:"nextRunAt"`
+	ManagedBy     string `json:"managedBy,omitempty"` // library file that owns the schedule
 	CreatedAt     string `json:"createdAt"`
 	UpdatedAt     string `json:"updatedAt"`
 }
@@ -32,16 +48,23 @@ type OpsScheduleWrite struct {
 	CronExpr     string
 	Timezone     string
 	RunAt        string
+	Interval     string
+	Jitter       string
+	Concurrency  string
+	Hosts        string
 	Enabled      bool
 	NextRunAt    string
+	// ManagedBy marks an inserted schedule as owned by a library file;
+	// updates keep it.
+	ManagedBy string
 }
 
 // ListOpsSchedules returns all schedules ordered by name.
 func (s *Store) ListOpsSchedules(ctx context.Context) ([]OpsSchedule, error) {
 	rows, err := s.db.QueryContext(ctx,
 		`SELECT id, runbook_id, name, schedule_type, cron_expr, timezone,
-		        run_at, enabled, last_run_at, last_run_status, next_run_at,
-		        created_at, updated_at
+		        run_at, interval_expr, jitter, concurrency_policy, hosts, enabled, last_run_at, last_run_status,
+		        next_run_at, managed_by, created_at, updated_at
 		 FROM ops_schedules ORDER BY name ASC, created_at ASC`)
 	if err != nil {
 		return nil, err
@@ -55,8 +78,8 @@ func (s *Store) ListOpsSchedules(ctx context.Context) ([]OpsSchedule, error) {
 // Remaining due schedules are naturally picked up on the next tick.
 func (s *Store) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]OpsSchedule, error) {
 	query := `SELECT id, runbook_id, name, schedule_type, cron_expr, timezone,
-		        run_at, enabled, last_run_at, last_run_status, next_run_at,
-		        created_at, updated_at
+		        run_at, interval_expr, jitter, concurrency_policy, hosts, enabled, last_run_at, last_run_status,
+		        next_run_at, managed_by, created_at, updated_at
 		 FROM ops_schedules
 		 WHERE enabled = 1 AND next_run_at != '' AND next_run_at <= ?
 		 ORDER BY next_run_at ASC`
@@ -77,8 +100,8 @@ func (s *Store) ListDueSchedules(ctx context.Context, now time.Time, limit int)
 func (s *Store) ListSchedulesByRunbook(ctx context.Context, runbookID string) ([]OpsSchedule, error) {
 	rows, err := s.db.QueryContext(ctx,
 		`SELECT id, runbook_id, name, schedule_type, cron_expr, timezone,
-		        run_at, enabled, last_run_at, last_run_status, next_run_at,
-		        created_at, updated_at
+		        run_at, interval_expr, jitter, concurrency_policy, hosts, enabled, last_run_at, last_run_status,
+		        next_run_at, managed_by, created_at, updated_at
 		 FROM ops_schedules WHERE runbook_id = ?
 		 ORDER BY created_at ASC`, runbookID)
 	if err != nil {
@@ -88,6 +111,11 @@ func (s *Store) ListSchedulesByRunbook(ctx context.Context, runbookID string) ([
 	return scanOpsSchedules(rows)
 }
 
+// GetOpsSchedule returns one schedule by ID.
+func (s *Store) GetOpsSchedule(ctx context.Context, id string) (OpsSchedule, error) {
+	return s.getOpsScheduleByID(ctx, id)
+}
+
 // InsertOpsSchedule creates a new schedule.
 func (s *Store) InsertOpsSchedule(ctx context.Context, w OpsScheduleWrite) (OpsSchedule, error) {
 	id := w.ID
@@ -96,10 +124,12 @@ func (s *Store) InsertOpsSchedule(ctx context.Context, w OpsScheduleWrite) (OpsS
 	}
 	_, err := s.db.ExecContext(ctx,
 		`INSERT INTO ops_schedules
-		 (id, runbook_id, name, schedule_type, cron_expr, timezone, run_at, enabled, next_run_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		 (id, runbook_id, name, schedule_type, cron_expr, timezone, run_at,
+		  interval_expr, jitter, concurrency_policy, hosts, enabled, next_run_at, managed_by)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		id, w.RunbookID, w.Name, w.ScheduleType, w.CronExpr, w.Timezone,
-		w.RunAt, boolToInt(w.Enabled), w.NextRunAt)
+		w.RunAt, w.Interval, w.Jitter, concurrencyPolicyOrDefault(w.Concurrency),
+		w.Hosts, boolToInt(w.Enabled), w.NextRunAt, w.ManagedBy)
 	if err != nil {
 		return OpsSchedule{}, err
 	}
@@ -111,11 +141,12 @@ func (s *Store) UpdateOpsSchedule(ctx context.Context, w OpsScheduleWrite) (OpsS
 	result, err := s.db.ExecContext(ctx,
 		`UPDATE ops_schedules SET
 		 name = ?, schedule_type = ?, cron_expr = ?, timezone = ?,
-		 run_at = ?, enabled = ?, next_run_at = ?,
-		 updated_at = datetime('now')
+		 run_at = ?, interval_expr = ?, jitter = ?, concurrency_policy = ?,
+		 hosts = ?, enabled = ?, next_run_at = ?, updated_at = datetime('now')
 		 WHERE id = ?`,
 		w.Name, w.ScheduleType, w.CronExpr, w.Timezone,
-		w.RunAt, boolToInt(w.Enabled), w.NextRunAt, w.ID)
+		w.RunAt, w.Interval, w.Jitter, concurrencyPolicyOrDefault(w.Concurrency),
+		w.Hosts, boolToInt(w.Enabled), w.NextRunAt, w.ID)
 	if err != nil {
 		return OpsSchedule{}, err
 	}
@@ -126,7 +157,7 @@ func (s *Store) UpdateOpsSchedule(ctx context.Context, w OpsScheduleWrite) (OpsS
 	return s.getOpsScheduleByID(ctx, w.ID)
 }
 
-// DeleteOpsSchedule removes a schedule by ID.
+// DeleteOpsSchedule removes a schedule and its run history by ID.
 func (s *Store) DeleteOpsSchedule(ctx context.Context, id string) error {
 	result, err := s.db.ExecContext(ctx, "DELETE FROM ops_schedules WHERE id = ?", id)
 	if err != nil {
@@ -136,7 +167,8 @@ func (s *Store) DeleteOpsSchedule(ctx context.Context, id string) error {
 	if n == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	_, err = s.db.ExecContext(ctx, "DELETE FROM ops_schedule_runs WHERE schedule_id = ?", id)
+	return err
 }
 
 // UpdateScheduleAfterRun updates the schedule's last run info and next run time.
@@ -163,17 +195,118 @@ func (s *Store) UpdateScheduleLastRun(ctx context.Context, id, lastRunAt, lastRu
 	return err
 }
 
-// DeleteSchedulesByRunbook removes all schedules for a runbook.
+// DeleteSchedulesByRunbook removes all schedules for a runbook and their
+// run history.
 func (s *Store) DeleteSchedulesByRunbook(ctx context.Context, runbookID string) error {
+	if _, err := s.db.ExecContext(ctx,
+		"DELETE FROM ops_schedule_runs WHERE schedule_id IN (SELECT id FROM ops_schedules WHERE runbook_id = ?)",
+		runbookID); err != nil {
+		return err
+	}
 	_, err := s.db.ExecContext(ctx, "DELETE FROM ops_schedules WHERE runbook_id = ?", runbookID)
 	return err
 }
 
+// ScheduleRunRunning is the outcome of a schedule run until its job ends.
+const ScheduleRunRunning = "running"
+
+// scheduleRunHistoryLimit is how many runs are kept per schedule.
+const scheduleRunHistoryLimit = 500
+
+// OpsScheduleRun is one scheduler-triggered execution of a schedule.
+type OpsScheduleRun struct {
+	ID         int64  `json:"id"`
+	ScheduleID string `json:"scheduleId"`
+	JobID      string `json:"jobId"`
+	FiredAt    string `json:"firedAt"`
+	FinishedAt string `json:"finishedAt"`
+	Outcome    string `json:"outcome"` // "running", "skipped" or the job's final status
+	DurationMs int64  `json:"durationMs"`
+}
+
+// InsertScheduleRun records a fired schedule and returns the run ID. Runs
+// beyond the newest scheduleRunHistoryLimit of the schedule are dropped.
+func (s *Store) InsertScheduleRun(ctx context.Context, scheduleID, jobID string, firedAt time.Time, outcome string) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`INSERT INTO ops_schedule_runs (schedule_id, job_id, fired_at, outcome)
+		 VALUES (?, ?, ?, ?)`,
+		scheduleID, jobID, firedAt.UTC().Format(time.RFC3339), outcome)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`DELETE FROM ops_schedule_runs
+		 WHERE schedule_id = ? AND id <= (
+		   SELECT id FROM ops_schedule_runs WHERE schedule_id = ?
+		   ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+		scheduleID, scheduleID, scheduleRunHistoryLimit)
+	return id, err
+}
+
+// FinishScheduleRun records the outcome of a run and its duration since it
+// fired.
+func (s *Store) FinishScheduleRun(ctx context.Context, id int64, finishedAt time.Time, outcome string) error {
+	var firedAt string
+	if err := s.db.QueryRowContext(ctx,
+		"SELECT fired_at FROM ops_schedule_runs WHERE id = ?", id,
+	).Scan(&firedAt); err != nil {
+		return err
+	}
+	var durationMs int64
+	if fired, err := time.Parse(time.RFC3339, firedAt); err == nil {
+		durationMs = max(finishedAt.Sub(fired).Milliseconds(), 0)
+	}
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE ops_schedule_runs SET finished_at = ?, outcome = ?, duration_ms = ?
+		 WHERE id = ?`,
+		finishedAt.UTC().Format(time.RFC3339), outcome, durationMs, id)
+	return err
+}
+
+// ListScheduleRuns returns the newest runs of a schedule first. When
+// limit > 0, at most limit rows are returned.
+func (s *Store) ListScheduleRuns(ctx context.Context, scheduleID string, limit int) ([]OpsScheduleRun, error) {
+	query := `SELECT id, schedule_id, job_id, fired_at, finished_at, outcome, duration_ms
+		 FROM ops_schedule_runs WHERE schedule_id = ?
+		 ORDER BY id DESC`
+	args := []any{scheduleID}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	runs := make([]OpsScheduleRun, 0)
+	for rows.Next() {
+		var run OpsScheduleRun
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &run.JobID, &run.FiredAt, &run.FinishedAt, &run.Outcome, &run.DurationMs); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func concurrencyPolicyOrDefault(policy string) string {
+	if policy == "" {
+		return ScheduleConcurrencyForbid
+	}
+	return policy
+}
+
 func (s *Store) getOpsScheduleByID(ctx context.Context, id string) (OpsSchedule, error) {
 	row := s.db.QueryRowContext(ctx,
 		`SELECT id, runbook_id, name, schedule_type, cron_expr, timezone,
-		        run_at, enabled, last_run_at, last_run_status, next_run_at,
-		        created_at, updated_at
+		        run_at, interval_expr, jitter, concurrency_policy, hosts, enabled, last_run_at, last_run_status,
+		        next_run_at, managed_by, created_at, updated_at
 		 FROM ops_schedules WHERE id = ?`, id)
 	return scanOpsSchedule(row)
 }
@@ -186,8 +319,8 @@ func scanOpsSchedules(rows *sql.Rows) ([]OpsSchedule, error) {
 		if err := rows.Scan(
 			&sched.ID, &sched.RunbookID, &sched.Name,
 			&sched.ScheduleType, &sched.CronExpr, &sched.Timezone,
-			&sched.RunAt, &enabled, &sched.LastRunAt, &sched.LastRunStatus,
-			&sched.NextRunAt, &sched.CreatedAt, &sched.UpdatedAt,
+			&sched.RunAt, &sched.Interval, &sched.Jitter, &sched.Concurrency, &sched.Hosts, &enabled, &sched.LastRunAt, &sched.LastRunStatus,
+			&sched.NextRunAt, &sched.ManagedBy, &sched.CreatedAt, &sched.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -207,8 +340,8 @@ func scanOpsSchedule(row opsScheduleRowScanner) (OpsSchedule, error) {
 	if err := row.Scan(
 		&sched.ID, &sched.RunbookID, &sched.Name,
 		&sched.ScheduleType, &sched.CronExpr, &sched.Timezone,
-		&sched.RunAt, &enabled, &sched.LastRunAt, &sched.LastRunStatus,
-		&sched.NextRunAt, &sched.CreatedAt, &sched.UpdatedAt,
+		&sched.RunAt, &sched.Interval, &sched.Jitter, &sched.Concurrency, &sched.Hosts, &enabled, &sched.LastRunAt, &sched.LastRunStatus,
+		&sched.NextRunAt, &sched.ManagedBy, &sched.CreatedAt, &sched.UpdatedAt,
 	); err != nil {
 		return OpsSchedule{}, err
 	}
@@ -37,6 +37,9 @@ func TestSchedulerCRUD(t *testing.T) {
 	if !sched.Enabled {
 		t.Fatal("enabled = false, want true")
 	}
+	if sched.Concurrency != ScheduleConcurrencyForbid {
+		t.Fatalf("concurrency = %q, want default %q", sched.Concurrency, ScheduleConcurrencyForbid)
+	}
 
 	// List all schedules.
 	all, err := s.ListOpsSchedules(ctx)
@@ -52,9 +55,10 @@ func TestSchedulerCRUD(t *testing.T) {
 		ID:           sched.ID,
 		RunbookID:    "runbook-1",
 		Name:         "Weekly backup",
-		ScheduleType: "cron",
-		CronExpr:     "0 2 * * 0",
-		Timezone:     "UTC",
+		ScheduleType: "interval",
+		Interval:     "15m",
+		Jitter:       "30s",
+		Concurrency:  ScheduleConcurrencyReplace,
 		Enabled:      false,
 		NextRunAt:    "",
 	})
@@ -67,6 +71,12 @@ func TestSchedulerCRUD(t *testing.T) {
 	if updated.Enabled {
 		t.Fatal("enabled = true, want false")
 	}
+	if updated.ScheduleType != "interval" || updated.Interval != "15m" || updated.Jitter != "30s" {
+		t.Fatalf("updated interval fields = %q %q %q", updated.ScheduleType, updated.Interval, updated.Jitter)
+	}
+	if updated.Concurrency != ScheduleConcurrencyReplace {
+		t.Fatalf("updated concurrency = %q, want %q", updated.Concurrency, ScheduleConcurrencyReplace)
+	}
 
 	// Delete the schedule.
 	if err := s.DeleteOpsSchedule(ctx, sched.ID); err != nil {
@@ -330,3 +340,78 @@ func TestInsertOpsScheduleWithExplicitID(t *testing.T) {
 		t.Fatalf("id = %q, want %q", sched.ID, "custom-id-123")
 	}
 }
+
+func TestScheduleRunHistory(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	sched, err := s.InsertOpsSchedule(ctx, OpsScheduleWrite{
+		RunbookID: "runbook-1", Name: "nightly", ScheduleType: "cron",
+		CronExpr: "0 2 * * *", Timezone: "UTC", Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("InsertOpsSchedule: %v", err)
+	}
+
+	fired := time.Date(2026, 5, 1, 2, 0, 0, 0, time.UTC)
+	first, err := s.InsertScheduleRun(ctx, sched.ID, "job-1", fired, ScheduleRunRunning)
+	if err != nil {
+		t.Fatalf("InsertScheduleRun: %v", err)
+	}
+	if _, err := s.InsertScheduleRun(ctx, sched.ID, "", fired.Add(time.Hour), "skipped"); err != nil {
+		t.Fatalf("InsertScheduleRun(skipped): %v", err)
+	}
+	if err := s.FinishScheduleRun(ctx, first, fired.Add(90*time.Second), "failed"); err != nil {
+		t.Fatalf("FinishScheduleRun: %v", err)
+	}
+
+	runs, err := s.ListScheduleRuns(ctx, sched.ID, 0)
+	if err != nil {
+		t.Fatalf("ListScheduleRuns: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Outcome != "skipped" {
+		t.Fatalf("runs = %+v, want newest (skipped) first", runs)
+	}
+	if got := runs[1]; got.JobID != "job-1" || got.Outcome != "failed" || got.DurationMs != 90000 || got.FinishedAt == "" {
+		t.Fatalf("finished run = %+v", got)
+	}
+	if runs, _ := s.ListScheduleRuns(ctx, sched.ID, 1); len(runs) != 1 {
+		t.Fatalf("limited runs = %d, want 1", len(runs))
+	}
+
+	if err := s.DeleteOpsSchedule(ctx, sched.ID); err != nil {
+		t.Fatalf("DeleteOpsSchedule: %v", err)
+	}
+	if runs, _ := s.ListScheduleRuns(ctx, sched.ID, 0); len(runs) != 0 {
+		t.Fatalf("runs after delete = %d, want 0", len(runs))
+	}
+}
+
+func TestScheduleRunHistoryIsBounded(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	fired := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := range scheduleRunHistoryLimit + 5 {
+		if _, err := s.InsertScheduleRun(ctx, "sched-1", "", fired.Add(time.Duration(i)*time.Minute), "skipped"); err != nil {
+			t.Fatalf("InsertScheduleRun(%d): %v", i, err)
+		}
+	}
+	runs, err := s.ListScheduleRuns(ctx, "sched-1", 0)
+	if err != nil {
+		t.Fatalf("ListScheduleRuns: %v", err)
+	}
+	if len(runs) != scheduleRunHistoryLimit {
+		t.Fatalf("runs = %d, want %d", len(runs), scheduleRunHistoryLimit)
+	}
+	want := fired.Add(time.Duration(scheduleRunHistoryLimit+4) * time.Minute).Format(time.RFC3339)
+	if runs[0].FiredAt != want {
+		t.Fatalf("newest run = %s, want %s", runs[0].FiredAt, want)
+	}
+}
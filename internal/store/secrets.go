@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// OpsSecret is a named credential. Value holds it sealed by the secrets
+// vault and is never serialized.
+type OpsSecret struct {
+	Name      string    `json:"name"`
+	Value     []byte    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ListOpsSecrets lists secrets ordered by name, without their values.
+func (s *Store) ListOpsSecrets(ctx context.Context) ([]OpsSecret, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT name, created_at, updated_at
+		   FROM ops_secrets
+		  ORDER BY name ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]OpsSecret, 0, 8)
+	for rows.Next() {
+		var (
+			item                       OpsSecret
+			createdAtRaw, updatedAtRaw string
+		)
+		if err := rows.Scan(&item.Name, &createdAtRaw, &updatedAtRaw); err != nil {
+			return nil, err
+		}
+		item.CreatedAt = parseStoreTime(createdAtRaw)
+		item.UpdatedAt = parseStoreTime(updatedAtRaw)
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+// GetOpsSecret returns a secret with its sealed value.
+func (s *Store) GetOpsSecret(ctx context.Context, name string) (OpsSecret, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return OpsSecret{}, sql.ErrNoRows
+	}
+	var (
+		item                       OpsSecret
+		createdAtRaw, updatedAtRaw string
+	)
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT name, value, created_at, updated_at FROM ops_secrets WHERE name = ?`, name,
+	).Scan(&item.Name, &item.Value, &createdAtRaw, &updatedAtRaw); err != nil {
+		return OpsSecret{}, err
+	}
+	item.CreatedAt = parseStoreTime(createdAtRaw)
+	item.UpdatedAt = parseStoreTime(updatedAtRaw)
+	return item, nil
+}
+
+// CreateOpsSecret stores a new sealed secret.
+func (s *Store) CreateOpsSecret(ctx context.Context, name string, value []byte) (OpsSecret, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return OpsSecret{}, errors.New("secret name is required")
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO ops_secrets (name, value, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+		name, value, now, now,
+	); err != nil {
+		return OpsSecret{}, err
+	}
+	return s.GetOpsSecret(ctx, name)
+}
+
+// UpdateOpsSecret replaces the sealed value of a secret.
+func (s *Store) UpdateOpsSecret(ctx context.Context, name string, value []byte) (OpsSecret, error) {
+	name = strings.TrimSpace(name)
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE ops_secrets SET value = ?, updated_at = ? WHERE name = ?`,
+		value, time.Now().UTC().Format(time.RFC3339), name,
+	)
+	if err != nil {
+		return OpsSecret{}, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return OpsSecret{}, sql.ErrNoRows
+	}
+	return s.GetOpsSecret(ctx, name)
+}
+
+// DeleteOpsSecret removes a secret.
+func (s *Store) DeleteOpsSecret(ctx context.Context, name string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM ops_secrets WHERE name = ?`, strings.TrimSpace(name))
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
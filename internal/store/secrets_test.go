@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestOpsSecrets(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	created, err := s.CreateOpsSecret(ctx, " slack_webhook ", []byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("CreateOpsSecret() error = %v", err)
+	}
+	if created.Name != "slack_webhook" || string(created.Value) != "\x01\x02\x03" || created.CreatedAt.IsZero() {
+		t.Fatalf("created secret = %#v", created)
+	}
+	if _, err := s.CreateOpsSecret(ctx, "slack_webhook", []byte{4}); err == nil {
+		t.Fatal("CreateOpsSecret() accepted a duplicate name")
+	}
+
+	updated, err := s.UpdateOpsSecret(ctx, "slack_webhook", []byte{4, 5})
+	if err != nil || string(updated.Value) != "\x04\x05" {
+		t.Fatalf("UpdateOpsSecret() = %#v, %v", updated, err)
+	}
+	if _, err := s.UpdateOpsSecret(ctx, "missing", []byte{1}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("UpdateOpsSecret(missing) error = %v, want sql.ErrNoRows", err)
+	}
+
+	list, err := s.ListOpsSecrets(ctx)
+	if err != nil || len(list) != 1 || list[0].Name != "slack_webhook" || list[0].Value != nil {
+		t.Fatalf("ListOpsSecrets() = %#v, %v; want the name without its value", list, err)
+	}
+
+	if err := s.DeleteOpsSecret(ctx, "slack_webhook"); err != nil {
+		t.Fatalf("DeleteOpsSecret() error = %v", err)
+	}
+	if _, err := s.GetOpsSecret(ctx, "slack_webhook"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("GetOpsSecret() after delete error = %v, want sql.ErrNoRows", err)
+	}
+}
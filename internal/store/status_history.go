@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Status history kinds.
+const (
+	StatusHistoryUptime  = "uptime"
+	StatusHistoryService = "service"
+)
+
+const statusHistoryDayLayout = "2006-01-02"
+
+// OpsStatusDay counts the up and down samples of an uptime check or a
+// tracked service on one UTC day.
+type OpsStatusDay struct {
+	Kind string `json:"kind"`
+	// Name is the uptime check id or the service name.
+	Name string `json:"name"`
+	Day  string `json:"day"`
+	Up   int64  `json:"up"`
+	Down int64  `json:"down"`
+}
+
+// RecordOpsStatusSample counts one up or down sample for the day of at.
+func (s *Store) RecordOpsStatusSample(ctx context.Context, kind, name string, up bool, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, recordOpsStatusSampleSQL, statusSampleArgs(kind, name, up, at)...)
+	return err
+}
+
+const recordOpsStatusSampleSQL = `INSERT INTO ops_status_history (kind, name, day, up, down)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(kind, name, day) DO UPDATE SET
+	up = up + excluded.up, down = down + excluded.down`
+
+func statusSampleArgs(kind, name string, up bool, at time.Time) []any {
+	upCount, downCount := 0, 1
+	if up {
+		upCount, downCount = 1, 0
+	}
+	return []any{kind, strings.TrimSpace(name), at.UTC().Format(statusHistoryDayLayout), upCount, downCount}
+}
+
+// ListOpsStatusHistory lists the daily samples from the day of since on,
+// ordered by kind, name and day.
+func (s *Store) ListOpsStatusHistory(ctx context.Context, since time.Time) ([]OpsStatusDay, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT kind, name, day, up, down
+		   FROM ops_status_history
+		  WHERE day >= ?
+		  ORDER BY kind, name, day`,
+		since.UTC().Format(statusHistoryDayLayout),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []OpsStatusDay
+	for rows.Next() {
+		var day OpsStatusDay
+		if err := rows.Scan(&day.Kind, &day.Name, &day.Day, &day.Up, &day.Down); err != nil {
+			return nil, err
+		}
+		out = append(out, day)
+	}
+	return out, rows.Err()
+}
+
+// PruneOpsStatusHistory deletes the days before the day of before.
+func (s *Store) PruneOpsStatusHistory(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM ops_status_history WHERE day < ?`,
+		before.UTC().Format(statusHistoryDayLayout),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOpsStatusHistory(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	day1 := time.Date(2026, 2, 14, 23, 30, 0, 0, time.UTC)
+	day2 := day1.Add(time.Hour)
+	for _, sample := range []struct {
+		name string
+		up   bool
+		at   time.Time
+	}{
+		{"nginx", true, day1},
+		{"nginx", false, day1},
+		{"nginx", true, day2},
+		{"nginx", true, day2},
+		{"postgres", true, day2},
+	} {
+		if err := s.RecordOpsStatusSample(ctx, StatusHistoryService, sample.name, sample.up, sample.at); err != nil {
+			t.Fatalf("RecordOpsStatusSample() error = %v", err)
+		}
+	}
+
+	check, err := s.CreateOpsUptimeCheck(ctx, OpsUptimeCheckWrite{Name: "db", Type: "tcp", Target: "db:5432", Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateOpsUptimeCheck() error = %v", err)
+	}
+	if err := s.RecordOpsUptimeResult(ctx, check.ID, OpsUptimeResult{Status: UptimeStatusDown, CheckedAt: day2}); err != nil {
+		t.Fatalf("RecordOpsUptimeResult() error = %v", err)
+	}
+
+	got, err := s.ListOpsStatusHistory(ctx, day1)
+	if err != nil {
+		t.Fatalf("ListOpsStatusHistory() error = %v", err)
+	}
+	want := []OpsStatusDay{
+		{Kind: StatusHistoryService, Name: "nginx", Day: "2026-02-14", Up: 1, Down: 1},
+		{Kind: StatusHistoryService, Name: "nginx", Day: "2026-02-15", Up: 2},
+		{Kind: StatusHistoryService, Name: "postgres", Day: "2026-02-15", Up: 1},
+		{Kind: StatusHistoryUptime, Name: check.ID, Day: "2026-02-15", Down: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListOpsStatusHistory() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("day %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Deleting a check drops its history.
+	if err := s.DeleteOpsUptimeCheck(ctx, check.ID); err != nil {
+		t.Fatalf("DeleteOpsUptimeCheck() error = %v", err)
+	}
+	if n, err := s.PruneOpsStatusHistory(ctx, day2); err != nil || n != 1 {
+		t.Fatalf("PruneOpsStatusHistory() = %d, %v; want 1 day removed", n, err)
+	}
+	if got, err := s.ListOpsStatusHistory(ctx, day1); err != nil || len(got) != 2 {
+		t.Fatalf("history after delete and prune = %+v, %v", got, err)
+	}
+}
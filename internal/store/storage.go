@@ -15,12 +15,22 @@ const (
 	StorageResourceActivityLog = "activity-journal"
 	// StorageResourceOpsJobs identifies ops runbook job storage.
 	StorageResourceOpsJobs = "ops-jobs"
+	// StorageResourceMetrics identifies persisted host metrics history.
+	StorageResourceMetrics = "metrics-history"
 	// StorageResourceAll targets every flushable storage resource.
 	StorageResourceAll           = "all"
 	storageResourceActivityLabel = "Activity journal"
 	storageResourceOpsJobsLbl    = "Ops runbook jobs"
+	storageResourceMetricsLbl    = "Metrics history"
 )
 
+// storageResources lists the flushable resources in display order.
+var storageResources = []string{
+	StorageResourceActivityLog,
+	StorageResourceOpsJobs,
+	StorageResourceMetrics,
+}
+
 // ErrInvalidStorageResource is returned when invalid storage resource occurs.
 var ErrInvalidStorageResource = errors.New("invalid storage resource")
 
@@ -39,6 +49,7 @@ type StorageStats struct {
 	SHMBytes      int64                 `json:"shmBytes"`
 	TotalBytes    int64                 `json:"totalBytes"`
 	Resources     []StorageResourceStat `json:"resources"`
+	Database      StorageDatabaseStats  `json:"database"`
 	CollectedAt   time.Time             `json:"collectedAt"`
 }
 
@@ -58,6 +69,7 @@ func IsStorageResource(raw string) bool {
 	switch NormalizeStorageResource(raw) {
 	case StorageResourceActivityLog,
 		StorageResourceOpsJobs,
+		StorageResourceMetrics,
 		StorageResourceAll:
 		return true
 	default:
@@ -68,7 +80,7 @@ func IsStorageResource(raw string) bool {
 // GetStorageStats returns storage stats.
 func (s *Store) GetStorageStats(ctx context.Context) (StorageStats, error) {
 	stats := StorageStats{
-		Resources:   make([]StorageResourceStat, 0, 2),
+		Resources:   make([]StorageResourceStat, 0, len(storageResources)),
 		CollectedAt: time.Now().UTC(),
 	}
 
@@ -89,16 +101,16 @@ func (s *Store) GetStorageStats(ctx context.Context) (StorageStats, error) {
 	stats.SHMBytes = shmBytes
 	stats.TotalBytes = dbBytes + walBytes + shmBytes
 
-	for _, resource := range []string{
-		StorageResourceActivityLog,
-		StorageResourceOpsJobs,
-	} {
+	for _, resource := range storageResources {
 		item, err := s.resourceStorageStats(ctx, resource)
 		if err != nil {
 			return StorageStats{}, err
 		}
 		stats.Resources = append(stats.Resources, item)
 	}
+	if stats.Database, err = s.databaseStats(ctx); err != nil {
+		return StorageStats{}, err
+	}
 
 	return stats, nil
 }
@@ -107,11 +119,8 @@ func (s *Store) GetStorageStats(ctx context.Context) (StorageStats, error) {
 func (s *Store) FlushStorageResource(ctx context.Context, resource string) ([]StorageFlushResult, error) {
 	resource = NormalizeStorageResource(resource)
 	if resource == StorageResourceAll {
-		results := make([]StorageFlushResult, 0, 2)
-		for _, key := range []string{
-			StorageResourceActivityLog,
-			StorageResourceOpsJobs,
-		} {
+		results := make([]StorageFlushResult, 0, len(storageResources))
+		for _, key := range storageResources {
 			item, err := s.flushStorageResourceSingle(ctx, key)
 			if err != nil {
 				return nil, err
@@ -144,6 +153,12 @@ func (s *Store) flushStorageResourceSingle(ctx context.Context, resource string)
 			return StorageFlushResult{}, err
 		}
 		return StorageFlushResult{Resource: resource, RemovedRows: removed}, nil
+	case StorageResourceMetrics:
+		removed, err := deleteRows(ctx, s.db, "DELETE FROM ops_metrics_history")
+		if err != nil {
+			return StorageFlushResult{}, err
+		}
+		return StorageFlushResult{Resource: resource, RemovedRows: removed}, nil
 	default:
 		return StorageFlushResult{}, ErrInvalidStorageResource
 	}
@@ -186,6 +201,21 @@ func (s *Store) resourceStorageStats(ctx context.Context, resource string) (Stor
 			Rows:        rows,
 			ApproxBytes: approxBytes,
 		}, nil
+	case StorageResourceMetrics:
+		// Each row is a fixed set of numeric columns, roughly 8 bytes apiece.
+		rows, approxBytes, err := queryRowsAndBytes(ctx, s.db, `SELECT
+			COUNT(*),
+			COALESCE(SUM(length(resolution) + 11 * 8), 0)
+		FROM ops_metrics_history`)
+		if err != nil {
+			return StorageResourceStat{}, err
+		}
+		return StorageResourceStat{
+			Resource:    resource,
+			Label:       storageResourceMetricsLbl,
+			Rows:        rows,
+			ApproxBytes: approxBytes,
+		}, nil
 	default:
 		return StorageResourceStat{}, ErrInvalidStorageResource
 	}
@@ -213,6 +243,46 @@ func deleteRows(ctx context.Context, db *sql.DB, query string) (int64, error) {
 	return result.RowsAffected()
 }
 
+// CheckIntegrity runs PRAGMA integrity_check against the database at dbPath
+// through a read-only connection, without running migrations. It returns
+// the reported problems, or nil when the database is intact. A missing
+// database file yields an error wrapping os.ErrNotExist.
+func CheckIntegrity(ctx context.Context, dbPath string) ([]string, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	return integrityProblems(ctx, db)
+}
+
+// integrityProblems runs PRAGMA integrity_check on db and returns every
+// line other than "ok".
+func integrityProblems(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("integrity check: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("integrity check: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("integrity check: %w", err)
+	}
+	return problems, nil
+}
+
 func (s *Store) walCheckpoint(ctx context.Context) error {
 	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
 		return err
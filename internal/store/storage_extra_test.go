@@ -15,6 +15,7 @@ func TestIsStorageResource(t *testing.T) {
 	}{
 		{"activity_log", StorageResourceActivityLog, true},
 		{"ops_jobs", StorageResourceOpsJobs, true},
+		{"metrics_history", StorageResourceMetrics, true},
 		{"all", StorageResourceAll, true},
 		{"uppercase_activity_log", "ACTIVITY-JOURNAL", true},
 		{"mixed_case", "Activity-Journal", true},
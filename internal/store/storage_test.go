@@ -3,6 +3,8 @@ package store
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -21,8 +23,8 @@ func TestStorageStatsAndFlush(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetStorageStats: %v", err)
 	}
-	if len(stats.Resources) != 2 {
-		t.Fatalf("len(resources) = %d, want 2", len(stats.Resources))
+	if len(stats.Resources) != 3 {
+		t.Fatalf("len(resources) = %d, want 3", len(stats.Resources))
 	}
 
 	rowsByResource := make(map[string]int64, len(stats.Resources))
@@ -32,6 +34,7 @@ func TestStorageStatsAndFlush(t *testing.T) {
 	for _, resource := range []string{
 		StorageResourceActivityLog,
 		StorageResourceOpsJobs,
+		StorageResourceMetrics,
 	} {
 		if rowsByResource[resource] < 1 {
 			t.Fatalf("resource %q rows = %d, want >= 1", resource, rowsByResource[resource])
@@ -42,8 +45,8 @@ func TestStorageStatsAndFlush(t *testing.T) {
 	if err != nil {
 		t.Fatalf("FlushStorageResource(all): %v", err)
 	}
-	if len(results) != 2 {
-		t.Fatalf("len(results) = %d, want 2", len(results))
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
 	}
 
 	after, err := s.GetStorageStats(ctx)
@@ -80,6 +83,9 @@ func seedStorageStatsData(ctx context.Context, t *testing.T, s *Store, base time
 	if _, err := s.StartOpsRunbook(ctx, runbooks[0].ID, base); err != nil {
 		t.Fatalf("StartOpsRunbook: %v", err)
 	}
+	if err := s.RecordMetricsSample(ctx, base, MetricsSample{CPUPercent: 12}); err != nil {
+		t.Fatalf("RecordMetricsSample: %v", err)
+	}
 }
 
 func TestFlushStorageRejectsInvalidResource(t *testing.T) {
@@ -93,3 +99,26 @@ func TestFlushStorageRejectsInvalidResource(t *testing.T) {
 		t.Fatalf("error = %v, want ErrInvalidStorageResource", err)
 	}
 }
+
+func TestCheckIntegrity(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "sentinel.db")
+	if _, err := CheckIntegrity(context.Background(), dbPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("CheckIntegrity(missing) error = %v, want os.ErrNotExist", err)
+	}
+
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	problems, err := CheckIntegrity(context.Background(), dbPath)
+	if err != nil {
+		t.Fatalf("CheckIntegrity: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Fatalf("problems = %v, want none", problems)
+	}
+}
@@ -3,21 +3,35 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
-	_ "modernc.org/sqlite" // register the sqlite driver used by sql.Open
+	"modernc.org/sqlite"
+
+	"github.com/opus-domini/sentinel/internal/tracing"
 )
 
+// driverName is the sqlite driver wrapped so that queries join the trace
+// of the request or run that issued them.
+const driverName = "sentinel-sqlite"
+
+func init() {
+	sql.Register(driverName, tracing.WrapDriver(&sqlite.Driver{}, "sqlite"))
+}
+
 // SessionMeta represents session meta data.
 type SessionMeta struct {
 	Hash        string
 	LastContent string
 	Icon        string
 	SortOrder   int
+	Tags        []string
+	Group       string
+	Notes       string
 }
 
 // Store represents store data.
@@ -26,14 +40,23 @@ type Store struct {
 	dbPath string
 }
 
-// New creates a new service value.
+// New opens the database at dbPath with the default Options.
 func New(dbPath string) (*Store, error) {
+	return Open(dbPath, Options{})
+}
+
+// Open opens the database at dbPath, applies opts and runs migrations.
+func Open(dbPath string, opts Options) (*Store, error) {
+	pragmas, err := opts.withDefaults().pragmas()
+	if err != nil {
+		return nil, err
+	}
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return nil, fmt.Errorf("create data dir: %w", err)
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := sql.Open(driverName, dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
@@ -44,10 +67,7 @@ func New(dbPath string) (*Store, error) {
 	db.SetMaxOpenConns(1)
 
 	ctx := context.Background()
-	for _, pragma := range []string{
-		"PRAGMA journal_mode=WAL",
-		"PRAGMA busy_timeout=5000",
-	} {
+	for _, pragma := range pragmas {
 		if _, err := db.ExecContext(ctx, pragma); err != nil {
 			_ = db.Close()
 			return nil, fmt.Errorf("set %s: %w", pragma, err)
@@ -64,7 +84,7 @@ func New(dbPath string) (*Store, error) {
 
 // GetAll returns all.
 func (s *Store) GetAll(ctx context.Context) (map[string]SessionMeta, error) {
-	rows, err := s.db.QueryContext(ctx, "SELECT name, hash, last_content, icon, sort_order FROM sessions")
+	rows, err := s.db.QueryContext(ctx, "SELECT name, hash, last_content, icon, sort_order, tags, group_name, notes FROM sessions")
 	if err != nil {
 		return nil, err
 	}
@@ -73,10 +93,10 @@ func (s *Store) GetAll(ctx context.Context) (map[string]SessionMeta, error) {
 	result := make(map[string]SessionMeta)
 	for rows.Next() {
 		var (
-			name, hash, content, icon string
-			sortOrder                 int
+			name, hash, content, icon, tagsRaw, group, notes string
+			sortOrder                                        int
 		)
-		if err := rows.Scan(&name, &hash, &content, &icon, &sortOrder); err != nil {
+		if err := rows.Scan(&name, &hash, &content, &icon, &sortOrder, &tagsRaw, &group, &notes); err != nil {
 			return nil, err
 		}
 		result[name] = SessionMeta{
@@ -84,6 +104,9 @@ func (s *Store) GetAll(ctx context.Context) (map[string]SessionMeta, error) {
 			LastContent: content,
 			Icon:        icon,
 			SortOrder:   sortOrder,
+			Tags:        decodeSessionTags(tagsRaw),
+			Group:       group,
+			Notes:       notes,
 		}
 	}
 	return result, rows.Err()
@@ -163,6 +186,56 @@ func (s *Store) SetIcon(ctx context.Context, name, icon string) error {
 	return err
 }
 
+// SetSessionTags replaces the tags and group name of a session.
+func (s *Store) SetSessionTags(ctx context.Context, name, group string, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("marshal tags: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO sessions (name, hash, tags, group_name, sort_order, updated_at)
+		 VALUES (
+		   ?, '', ?, ?,
+		   COALESCE((SELECT MAX(sort_order) + 1 FROM sessions), 1),
+		   datetime('now')
+		 )
+		 ON CONFLICT(name) DO UPDATE SET
+		   tags = excluded.tags,
+		   group_name = excluded.group_name,
+		   updated_at = excluded.updated_at`,
+		name, string(tagsJSON), group,
+	)
+	return err
+}
+
+// SetSessionNotes replaces the notes of a session.
+func (s *Store) SetSessionNotes(ctx context.Context, name, notes string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sessions (name, hash, notes, sort_order, updated_at)
+		 VALUES (
+		   ?, '', ?,
+		   COALESCE((SELECT MAX(sort_order) + 1 FROM sessions), 1),
+		   datetime('now')
+		 )
+		 ON CONFLICT(name) DO UPDATE SET
+		   notes = excluded.notes,
+		   updated_at = excluded.updated_at`,
+		name, notes,
+	)
+	return err
+}
+
+func decodeSessionTags(raw string) []string {
+	var tags []string
+	if err := json.Unmarshal([]byte(raw), &tags); err != nil || len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
 // MoveSessionToFront moves session to front.
 func (s *Store) MoveSessionToFront(ctx context.Context, name string) error {
 	name = strings.TrimSpace(name)
@@ -289,6 +362,12 @@ func (s *Store) ListFrequentDirectories(ctx context.Context, limit int) ([]strin
 	return dirs, rows.Err()
 }
 
+// Ping checks that the database answers a query.
+func (s *Store) Ping(ctx context.Context) error {
+	var one int
+	return s.db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
 // Close closes value.
 func (s *Store) Close() error {
 	return s.db.Close()
@@ -254,6 +254,69 @@ func TestSetIcon(t *testing.T) {
 	})
 }
 
+func TestSetSessionTags(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+
+	if err := s.SetSessionTags(ctx, "dev", "clients", []string{"work", "acme"}); err != nil {
+		t.Fatalf("SetSessionTags(dev) error = %v", err)
+	}
+	// Upsert and rename must both preserve tags.
+	if err := s.UpsertSession(ctx, "dev", "h2", "c2"); err != nil {
+		t.Fatalf("UpsertSession(dev) error = %v", err)
+	}
+	if err := s.Rename(ctx, "dev", "acme"); err != nil {
+		t.Fatalf("Rename(dev, acme) error = %v", err)
+	}
+
+	got, err := s.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	meta := got["acme"]
+	if meta.Group != "clients" || len(meta.Tags) != 2 || meta.Tags[0] != "work" || meta.Tags[1] != "acme" {
+		t.Fatalf("acme meta = %+v, want group clients and tags [work acme]", meta)
+	}
+
+	if err := s.SetSessionTags(ctx, "acme", "", nil); err != nil {
+		t.Fatalf("SetSessionTags(clear) error = %v", err)
+	}
+	got, err = s.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if got["acme"].Group != "" || got["acme"].Tags != nil {
+		t.Fatalf("cleared meta = %+v, want no tags or group", got["acme"])
+	}
+}
+
+func TestSetSessionNotes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+
+	const notes = "Runs the data migration.\n\n**Do not kill.**"
+	if err := s.SetSessionNotes(ctx, "dev", notes); err != nil {
+		t.Fatalf("SetSessionNotes(dev) error = %v", err)
+	}
+	if err := s.UpsertSession(ctx, "dev", "h2", "c2"); err != nil {
+		t.Fatalf("UpsertSession(dev) error = %v", err)
+	}
+
+	got, err := s.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if got["dev"].Notes != notes {
+		t.Fatalf("dev.Notes = %q, want %q", got["dev"].Notes, notes)
+	}
+}
+
 func TestAllocateNextWindowSequence(t *testing.T) {
 	t.Parallel()
 
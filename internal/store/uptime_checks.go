@@ -0,0 +1,237 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Uptime check statuses.
+const (
+	UptimeStatusPending = "pending"
+	UptimeStatusUp      = "up"
+	UptimeStatusDown    = "down"
+)
+
+// OpsUptimeCheck is an HTTP, TCP or ICMP check of a URL or endpoint, with
+// the outcome of its latest run.
+type OpsUptimeCheck struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Type is "http", "tcp" or "icmp". Target is the URL, the host:port
+	// or the host to ping.
+	Type            string `json:"type"`
+	Target          string `json:"target"`
+	IntervalSeconds int    `json:"intervalSeconds"`
+	TimeoutSeconds  int    `json:"timeoutSeconds"`
+	// ExpectStatus 0 accepts any 2xx; ExpectBody is a regular expression
+	// the response body must match. Both apply to http checks only.
+	ExpectStatus int       `json:"expectStatus"`
+	ExpectBody   string    `json:"expectBody"`
+	Enabled      bool      `json:"enabled"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+
+	Status        string    `json:"status"`
+	Detail        string    `json:"detail,omitempty"`
+	LatencyMs     int64     `json:"latencyMs"`
+	LastCheckedAt time.Time `json:"lastCheckedAt"`
+	LastChangeAt  time.Time `json:"lastChangeAt"`
+}
+
+// OpsUptimeCheckWrite represents uptime check write data.
+type OpsUptimeCheckWrite struct {
+	ID              string
+	Name            string
+	Type            string
+	Target          string
+	IntervalSeconds int
+	TimeoutSeconds  int
+	ExpectStatus    int
+	ExpectBody      string
+	Enabled         bool
+}
+
+// OpsUptimeResult is the outcome of one run of an uptime check.
+type OpsUptimeResult struct {
+	Status    string
+	Detail    string
+	LatencyMs int64
+	CheckedAt time.Time
+}
+
+const opsUptimeCheckColumns = `id, name, type, target, interval_seconds, timeout_seconds,
+	expect_status, expect_body, enabled, created_at, updated_at,
+	last_status, last_detail, last_latency_ms, last_checked_at, last_change_at`
+
+// ListOpsUptimeChecks lists uptime checks ordered by name.
+func (s *Store) ListOpsUptimeChecks(ctx context.Context) ([]OpsUptimeCheck, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+opsUptimeCheckColumns+`
+		   FROM ops_uptime_checks
+		  ORDER BY name COLLATE NOCASE ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]OpsUptimeCheck, 0, 8)
+	for rows.Next() {
+		row, err := scanOpsUptimeCheck(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// GetOpsUptimeCheck returns an uptime check.
+func (s *Store) GetOpsUptimeCheck(ctx context.Context, id string) (OpsUptimeCheck, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return OpsUptimeCheck{}, sql.ErrNoRows
+	}
+	return scanOpsUptimeCheck(s.db.QueryRowContext(ctx,
+		`SELECT `+opsUptimeCheckColumns+` FROM ops_uptime_checks WHERE id = ?`, id,
+	))
+}
+
+// CreateOpsUptimeCheck creates a pending uptime check.
+func (s *Store) CreateOpsUptimeCheck(ctx context.Context, w OpsUptimeCheckWrite) (OpsUptimeCheck, error) {
+	name := strings.TrimSpace(w.Name)
+	if name == "" {
+		return OpsUptimeCheck{}, errors.New("uptime check name is required")
+	}
+	id := strings.TrimSpace(w.ID)
+	if id == "" {
+		id = randomID()
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO ops_uptime_checks (id, name, type, target, interval_seconds, timeout_seconds,
+		 expect_status, expect_body, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, name, strings.TrimSpace(w.Type), strings.TrimSpace(w.Target), w.IntervalSeconds, w.TimeoutSeconds,
+		w.ExpectStatus, w.ExpectBody, boolToInt(w.Enabled), now, now,
+	); err != nil {
+		return OpsUptimeCheck{}, err
+	}
+	return s.GetOpsUptimeCheck(ctx, id)
+}
+
+// UpdateOpsUptimeCheck updates an uptime check. A changed type or target
+// resets it to pending and due, since the previous outcome no longer
+// applies.
+func (s *Store) UpdateOpsUptimeCheck(ctx context.Context, w OpsUptimeCheckWrite) (OpsUptimeCheck, error) {
+	name := strings.TrimSpace(w.Name)
+	if name == "" {
+		return OpsUptimeCheck{}, errors.New("uptime check name is required")
+	}
+	kind, target := strings.TrimSpace(w.Type), strings.TrimSpace(w.Target)
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE ops_uptime_checks SET
+		 last_status = CASE WHEN type = ? AND target = ? THEN last_status ELSE ? END,
+		 last_detail = CASE WHEN type = ? AND target = ? THEN last_detail ELSE '' END,
+		 last_change_at = CASE WHEN type = ? AND target = ? THEN last_change_at ELSE '' END,
+		 last_checked_at = CASE WHEN type = ? AND target = ? THEN last_checked_at ELSE '' END,
+		 name = ?, type = ?, target = ?, interval_seconds = ?, timeout_seconds = ?,
+		 expect_status = ?, expect_body = ?, enabled = ?, updated_at = ?
+		 WHERE id = ?`,
+		kind, target, UptimeStatusPending,
+		kind, target,
+		kind, target,
+		kind, target,
+		name, kind, target, w.IntervalSeconds, w.TimeoutSeconds,
+		w.ExpectStatus, w.ExpectBody, boolToInt(w.Enabled), time.Now().UTC().Format(time.RFC3339),
+		strings.TrimSpace(w.ID),
+	)
+	if err != nil {
+		return OpsUptimeCheck{}, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return OpsUptimeCheck{}, sql.ErrNoRows
+	}
+	return s.GetOpsUptimeCheck(ctx, w.ID)
+}
+
+// DeleteOpsUptimeCheck removes an uptime check and its status history.
+func (s *Store) DeleteOpsUptimeCheck(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM ops_uptime_checks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM ops_status_history WHERE kind = ? AND name = ?`, StatusHistoryUptime, id,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RecordOpsUptimeResult stores the outcome of a run, moves last_change_at
+// when the status flipped and counts the run in the status history.
+func (s *Store) RecordOpsUptimeResult(ctx context.Context, id string, r OpsUptimeResult) error {
+	id = strings.TrimSpace(id)
+	at := r.CheckedAt.UTC().Format(time.RFC3339)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE ops_uptime_checks SET
+		 last_change_at = CASE WHEN last_status = ? THEN last_change_at ELSE ? END,
+		 last_status = ?, last_detail = ?, last_latency_ms = ?, last_checked_at = ?
+		 WHERE id = ?`,
+		r.Status, at,
+		r.Status, r.Detail, r.LatencyMs, at,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.ExecContext(ctx, recordOpsStatusSampleSQL,
+		statusSampleArgs(StatusHistoryUptime, id, r.Status == UptimeStatusUp, r.CheckedAt)...,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func scanOpsUptimeCheck(row interface{ Scan(...any) error }) (OpsUptimeCheck, error) {
+	var (
+		check                                                  OpsUptimeCheck
+		enabled                                                int
+		createdAtRaw, updatedAtRaw, checkedAtRaw, changedAtRaw string
+	)
+	if err := row.Scan(&check.ID, &check.Name, &check.Type, &check.Target,
+		&check.IntervalSeconds, &check.TimeoutSeconds, &check.ExpectStatus, &check.ExpectBody,
+		&enabled, &createdAtRaw, &updatedAtRaw,
+		&check.Status, &check.Detail, &check.LatencyMs, &checkedAtRaw, &changedAtRaw); err != nil {
+		return OpsUptimeCheck{}, err
+	}
+	check.Enabled = enabled == 1
+	check.CreatedAt = parseStoreTime(createdAtRaw)
+	check.UpdatedAt = parseStoreTime(updatedAtRaw)
+	check.LastCheckedAt = parseStoreTime(checkedAtRaw)
+	check.LastChangeAt = parseStoreTime(changedAtRaw)
+	return check, nil
+}
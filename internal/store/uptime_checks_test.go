@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOpsUptimeChecks(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	created, err := s.CreateOpsUptimeCheck(ctx, OpsUptimeCheckWrite{
+		Name:            " web ",
+		Type:            "http",
+		Target:          "https://example.com/health",
+		IntervalSeconds: 60,
+		TimeoutSeconds:  10,
+		ExpectBody:      "ok",
+		Enabled:         true,
+	})
+	if err != nil {
+		t.Fatalf("CreateOpsUptimeCheck() error = %v", err)
+	}
+	if created.ID == "" || created.Name != "web" || created.Status != UptimeStatusPending || !created.LastCheckedAt.IsZero() {
+		t.Fatalf("created check = %#v", created)
+	}
+	if _, err := s.CreateOpsUptimeCheck(ctx, OpsUptimeCheckWrite{Name: "web", Type: "tcp", Target: "db:5432"}); err == nil {
+		t.Fatal("CreateOpsUptimeCheck() accepted a duplicate name")
+	}
+
+	down := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	if err := s.RecordOpsUptimeResult(ctx, created.ID, OpsUptimeResult{Status: UptimeStatusDown, Detail: "status 502, want 2xx", LatencyMs: 40, CheckedAt: down}); err != nil {
+		t.Fatalf("RecordOpsUptimeResult() error = %v", err)
+	}
+	if err := s.RecordOpsUptimeResult(ctx, created.ID, OpsUptimeResult{Status: UptimeStatusDown, Detail: "status 502, want 2xx", LatencyMs: 35, CheckedAt: down.Add(time.Minute)}); err != nil {
+		t.Fatalf("RecordOpsUptimeResult() error = %v", err)
+	}
+	got, err := s.GetOpsUptimeCheck(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetOpsUptimeCheck() error = %v", err)
+	}
+	if got.Status != UptimeStatusDown || got.LatencyMs != 35 || !got.LastChangeAt.Equal(down) || !got.LastCheckedAt.Equal(down.Add(time.Minute)) {
+		t.Fatalf("check after two failures = %#v", got)
+	}
+
+	// Renaming keeps the outcome; a new target starts over.
+	renamed, err := s.UpdateOpsUptimeCheck(ctx, OpsUptimeCheckWrite{ID: created.ID, Name: "site", Type: "http", Target: "https://example.com/health", IntervalSeconds: 30, TimeoutSeconds: 5, Enabled: true})
+	if err != nil {
+		t.Fatalf("UpdateOpsUptimeCheck() error = %v", err)
+	}
+	if renamed.Name != "site" || renamed.IntervalSeconds != 30 || renamed.Status != UptimeStatusDown {
+		t.Fatalf("renamed check = %#v", renamed)
+	}
+	moved, err := s.UpdateOpsUptimeCheck(ctx, OpsUptimeCheckWrite{ID: created.ID, Name: "site", Type: "http", Target: "https://example.org/", IntervalSeconds: 30, TimeoutSeconds: 5, Enabled: true})
+	if err != nil {
+		t.Fatalf("UpdateOpsUptimeCheck() error = %v", err)
+	}
+	if moved.Status != UptimeStatusPending || moved.Detail != "" || !moved.LastCheckedAt.IsZero() {
+		t.Fatalf("moved check = %#v", moved)
+	}
+
+	if _, err := s.UpdateOpsUptimeCheck(ctx, OpsUptimeCheckWrite{ID: "missing", Name: "x"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("UpdateOpsUptimeCheck(missing) error = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.DeleteOpsUptimeCheck(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteOpsUptimeCheck() error = %v", err)
+	}
+	if checks, err := s.ListOpsUptimeChecks(ctx); err != nil || len(checks) != 0 {
+		t.Fatalf("ListOpsUptimeChecks() = %v, %v; want none", checks, err)
+	}
+	if err := s.DeleteOpsUptimeCheck(ctx, created.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("DeleteOpsUptimeCheck(deleted) error = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Watch rule severities.
+const (
+	WatchSeverityInfo     = "info"
+	WatchSeverityWarning  = "warning"
+	WatchSeverityCritical = "critical"
+)
+
+// WatchRule is a regular expression the watchtower matches against new
+// lines of pane output. Session and PaneID, when set, limit it to one
+// session or pane.
+type WatchRule struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Pattern   string    `json:"pattern"`
+	Severity  string    `json:"severity"`
+	Session   string    `json:"session,omitempty"`
+	PaneID    string    `json:"paneId,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// WatchRuleWrite represents watch rule write data.
+type WatchRuleWrite struct {
+	ID       string
+	Name     string
+	Pattern  string
+	Severity string
+	Session  string
+	PaneID   string
+	Enabled  bool
+}
+
+const watchRuleColumns = `id, name, pattern, severity, session_name, pane_id, enabled, created_at, updated_at`
+
+// ListWatchRules lists watch rules ordered by name.
+func (s *Store) ListWatchRules(ctx context.Context) ([]WatchRule, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+watchRuleColumns+`
+		   FROM tmux_watch_rules
+		  ORDER BY name COLLATE NOCASE ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]WatchRule, 0, 8)
+	for rows.Next() {
+		row, err := scanWatchRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// GetWatchRule returns a watch rule.
+func (s *Store) GetWatchRule(ctx context.Context, id string) (WatchRule, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return WatchRule{}, sql.ErrNoRows
+	}
+	return scanWatchRule(s.db.QueryRowContext(ctx,
+		`SELECT `+watchRuleColumns+` FROM tmux_watch_rules WHERE id = ?`, id,
+	))
+}
+
+// CreateWatchRule creates a watch rule.
+func (s *Store) CreateWatchRule(ctx context.Context, w WatchRuleWrite) (WatchRule, error) {
+	w, err := normalizeWatchRuleWrite(w)
+	if err != nil {
+		return WatchRule{}, err
+	}
+	id := strings.TrimSpace(w.ID)
+	if id == "" {
+		id = randomID()
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO tmux_watch_rules (id, name, pattern, severity, session_name, pane_id, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, w.Name, w.Pattern, w.Severity, w.Session, w.PaneID, boolToInt(w.Enabled), now, now,
+	); err != nil {
+		return WatchRule{}, err
+	}
+	return s.GetWatchRule(ctx, id)
+}
+
+// UpdateWatchRule replaces a watch rule.
+func (s *Store) UpdateWatchRule(ctx context.Context, w WatchRuleWrite) (WatchRule, error) {
+	w, err := normalizeWatchRuleWrite(w)
+	if err != nil {
+		return WatchRule{}, err
+	}
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE tmux_watch_rules SET name = ?, pattern = ?, severity = ?, session_name = ?, pane_id = ?, enabled = ?, updated_at = ?
+		 WHERE id = ?`,
+		w.Name, w.Pattern, w.Severity, w.Session, w.PaneID, boolToInt(w.Enabled), time.Now().UTC().Format(time.RFC3339),
+		strings.TrimSpace(w.ID),
+	)
+	if err != nil {
+		return WatchRule{}, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return WatchRule{}, sql.ErrNoRows
+	}
+	return s.GetWatchRule(ctx, w.ID)
+}
+
+// DeleteWatchRule removes a watch rule.
+func (s *Store) DeleteWatchRule(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM tmux_watch_rules WHERE id = ?`, strings.TrimSpace(id))
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func normalizeWatchRuleWrite(w WatchRuleWrite) (WatchRuleWrite, error) {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		return w, errors.New("watch rule name is required")
+	}
+	if strings.TrimSpace(w.Pattern) == "" {
+		return w, errors.New("watch rule pattern is required")
+	}
+	w.Severity = strings.TrimSpace(w.Severity)
+	if w.Severity == "" {
+		w.Severity = WatchSeverityWarning
+	}
+	w.Session = strings.TrimSpace(w.Session)
+	w.PaneID = strings.TrimSpace(w.PaneID)
+	return w, nil
+}
+
+func scanWatchRule(row interface{ Scan(...any) error }) (WatchRule, error) {
+	var (
+		rule                       WatchRule
+		enabled                    int
+		createdAtRaw, updatedAtRaw string
+	)
+	if err := row.Scan(&rule.ID, &rule.Name, &rule.Pattern, &rule.Severity, &rule.Session, &rule.PaneID, &enabled,
+		&createdAtRaw, &updatedAtRaw); err != nil {
+		return WatchRule{}, err
+	}
+	rule.Enabled = enabled == 1
+	rule.CreatedAt = parseStoreTime(createdAtRaw)
+	rule.UpdatedAt = parseStoreTime(updatedAtRaw)
+	return rule, nil
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestWatchRules(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	created, err := s.CreateWatchRule(ctx, WatchRuleWrite{Name: " panics ", Pattern: "panic:", Session: " api ", Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateWatchRule() error = %v", err)
+	}
+	if len(created.ID) != 32 || created.Name != "panics" || created.Severity != WatchSeverityWarning || created.Session != "api" || !created.Enabled {
+		t.Fatalf("created rule = %#v", created)
+	}
+	if _, err := s.CreateWatchRule(ctx, WatchRuleWrite{Name: "panics", Pattern: "x"}); err == nil {
+		t.Fatal("CreateWatchRule() accepted a duplicate name")
+	}
+	if _, err := s.CreateWatchRule(ctx, WatchRuleWrite{Name: "empty"}); err == nil {
+		t.Fatal("CreateWatchRule() accepted an empty pattern")
+	}
+
+	updated, err := s.UpdateWatchRule(ctx, WatchRuleWrite{ID: created.ID, Name: "panics", Pattern: "panic|fatal", Severity: WatchSeverityCritical, PaneID: "%3"})
+	if err != nil {
+		t.Fatalf("UpdateWatchRule() error = %v", err)
+	}
+	if updated.Pattern != "panic|fatal" || updated.Severity != WatchSeverityCritical || updated.Session != "" || updated.PaneID != "%3" || updated.Enabled {
+		t.Fatalf("updated rule = %#v", updated)
+	}
+	if _, err := s.UpdateWatchRule(ctx, WatchRuleWrite{ID: "missing", Name: "x", Pattern: "x"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("UpdateWatchRule(missing) error = %v, want sql.ErrNoRows", err)
+	}
+
+	rules, err := s.ListWatchRules(ctx)
+	if err != nil || len(rules) != 1 || rules[0].ID != created.ID {
+		t.Fatalf("ListWatchRules() = %#v, %v", rules, err)
+	}
+	if err := s.DeleteWatchRule(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteWatchRule() error = %v", err)
+	}
+	if err := s.DeleteWatchRule(ctx, created.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("DeleteWatchRule(again) error = %v, want sql.ErrNoRows", err)
+	}
+}
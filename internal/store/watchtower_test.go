@@ -292,6 +292,35 @@ func TestUpsertWatchtowerPaneClampsSeenRevision(t *testing.T) {
 	}
 }
 
+func TestUpsertWatchtowerPaneTracksZoom(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	for _, zoomed := range []bool{true, false} {
+		if err := s.UpsertWatchtowerPane(ctx, WatchtowerPaneWrite{
+			PaneID:      "%1",
+			SessionName: "dev",
+			Active:      true,
+			Zoomed:      zoomed,
+		}); err != nil {
+			t.Fatalf("UpsertWatchtowerPane(zoomed=%v): %v", zoomed, err)
+		}
+		panes, err := s.ListWatchtowerPanes(ctx, "dev")
+		if err != nil {
+			t.Fatalf("ListWatchtowerPanes: %v", err)
+		}
+		if len(panes) != 1 || panes[0].Zoomed != zoomed {
+			t.Fatalf("panes = %+v, want zoomed=%v", panes, zoomed)
+		}
+		if patch := BuildWatchtowerPanePatches(panes)[0]; patch["zoomed"] != zoomed {
+			t.Fatalf("patch zoomed = %v, want %v", patch["zoomed"], zoomed)
+		}
+	}
+}
+
 func TestGetWatchtowerInspectorPatchUsesManagedRuntimeIdentity(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const webhookSecretPrefix = "whsec_"
+
+// Webhook providers.
+const (
+	WebhookProviderGeneric = "generic"
+	WebhookProviderGitHub  = "github"
+	WebhookProviderGitLab  = "gitlab"
+)
+
+// OpsWebhook is an inbound webhook that triggers a runbook. The secret is
+// only returned when the webhook is created. Repository, Branch and Events
+// filter the events of the github and gitlab providers.
+type OpsWebhook struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Provider        string            `json:"provider"`
+	RunbookID       string            `json:"runbookId"`
+	Parameters      map[string]string `json:"parameters"`
+	Repository      string            `json:"repository"`
+	Branch          string            `json:"branch"`
+	Events          []string          `json:"events"`
+	Enabled         bool              `json:"enabled"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+	LastTriggeredAt time.Time         `json:"lastTriggeredAt"`
+	Secret          string            `json:"-"`
+}
+
+// OpsWebhookWrite represents webhook write data.
+type OpsWebhookWrite struct {
+	ID         string
+	Name       string
+	Provider   string
+	RunbookID  string
+	Parameters map[string]string
+	Repository string
+	Branch     string
+	Events     []string
+	Enabled    bool
+}
+
+const opsWebhookColumns = `id, name, provider, secret, runbook_id, parameters,
+	repository, branch, events, enabled, created_at, updated_at, last_triggered_at`
+
+// ListOpsWebhooks lists webhooks ordered by name.
+func (s *Store) ListOpsWebhooks(ctx context.Context) ([]OpsWebhook, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+opsWebhookColumns+`
+		   FROM ops_webhooks
+		  ORDER BY name COLLATE NOCASE ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]OpsWebhook, 0, 4)
+	for rows.Next() {
+		row, err := scanOpsWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// GetOpsWebhook returns a webhook, secret included.
+func (s *Store) GetOpsWebhook(ctx context.Context, id string) (OpsWebhook, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return OpsWebhook{}, sql.ErrNoRows
+	}
+	return scanOpsWebhook(s.db.QueryRowContext(ctx,
+		`SELECT `+opsWebhookColumns+` FROM ops_webhooks WHERE id = ?`, id,
+	))
+}
+
+// CreateOpsWebhook creates a webhook with a new secret and returns it
+// together with the secret.
+func (s *Store) CreateOpsWebhook(ctx context.Context, w OpsWebhookWrite) (OpsWebhook, string, error) {
+	name := strings.TrimSpace(w.Name)
+	if name == "" {
+		return OpsWebhook{}, "", errors.New("webhook name is required")
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return OpsWebhook{}, "", err
+	}
+	params, err := marshalWebhookParameters(w.Parameters)
+	if err != nil {
+		return OpsWebhook{}, "", err
+	}
+	id := strings.TrimSpace(w.ID)
+	if id == "" {
+		id = randomID()
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO ops_webhooks (id, name, provider, secret, runbook_id, parameters,
+		 repository, branch, events, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, name, webhookProviderOrDefault(w.Provider), secret, strings.TrimSpace(w.RunbookID), params,
+		strings.TrimSpace(w.Repository), strings.TrimSpace(w.Branch), strings.Join(w.Events, ","),
+		boolToInt(w.Enabled), now, now,
+	); err != nil {
+		return OpsWebhook{}, "", err
+	}
+	hook, err := s.GetOpsWebhook(ctx, id)
+	if err != nil {
+		return OpsWebhook{}, "", err
+	}
+	return hook, secret, nil
+}
+
+// UpdateOpsWebhook updates everything but the secret, which is kept.
+func (s *Store) UpdateOpsWebhook(ctx context.Context, w OpsWebhookWrite) (OpsWebhook, error) {
+	name := strings.TrimSpace(w.Name)
+	if name == "" {
+		return OpsWebhook{}, errors.New("webhook name is required")
+	}
+	params, err := marshalWebhookParameters(w.Parameters)
+	if err != nil {
+		return OpsWebhook{}, err
+	}
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE ops_webhooks SET
+		 name = ?, provider = ?, runbook_id = ?, parameters = ?,
+		 repository = ?, branch = ?, events = ?, enabled = ?, updated_at = ?
+		 WHERE id = ?`,
+		name, webhookProviderOrDefault(w.Provider), strings.TrimSpace(w.RunbookID), params,
+		strings.TrimSpace(w.Repository), strings.TrimSpace(w.Branch), strings.Join(w.Events, ","),
+		boolToInt(w.Enabled), time.Now().UTC().Format(time.RFC3339), strings.TrimSpace(w.ID),
+	)
+	if err != nil {
+		return OpsWebhook{}, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return OpsWebhook{}, sql.ErrNoRows
+	}
+	return s.GetOpsWebhook(ctx, w.ID)
+}
+
+// DeleteOpsWebhook removes a webhook.
+func (s *Store) DeleteOpsWebhook(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM ops_webhooks WHERE id = ?`, strings.TrimSpace(id))
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkOpsWebhookTriggered records when a webhook last started a run.
+func (s *Store) MarkOpsWebhookTriggered(ctx context.Context, id string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE ops_webhooks SET last_triggered_at = ? WHERE id = ?`,
+		at.UTC().Format(time.RFC3339), strings.TrimSpace(id),
+	)
+	return err
+}
+
+func scanOpsWebhook(row interface{ Scan(...any) error }) (OpsWebhook, error) {
+	var (
+		hook                                  OpsWebhook
+		paramsRaw, eventsRaw                  string
+		enabled                               int
+		createdAtRaw, updatedAtRaw, triggered string
+	)
+	if err := row.Scan(&hook.ID, &hook.Name, &hook.Provider, &hook.Secret, &hook.RunbookID, &paramsRaw,
+		&hook.Repository, &hook.Branch, &eventsRaw, &enabled,
+		&createdAtRaw, &updatedAtRaw, &triggered); err != nil {
+		return OpsWebhook{}, err
+	}
+	hook.Events = []string{}
+	if eventsRaw != "" {
+		hook.Events = strings.Split(eventsRaw, ",")
+	}
+	if err := json.Unmarshal([]byte(paramsRaw), &hook.Parameters); err != nil || hook.Parameters == nil {
+		hook.Parameters = map[string]string{}
+	}
+	hook.Enabled = enabled == 1
+	hook.CreatedAt = parseStoreTime(createdAtRaw)
+	hook.UpdatedAt = parseStoreTime(updatedAtRaw)
+	hook.LastTriggeredAt = parseStoreTime(triggered)
+	return hook, nil
+}
+
+func webhookProviderOrDefault(provider string) string {
+	if provider = strings.TrimSpace(provider); provider != "" {
+		return provider
+	}
+	return WebhookProviderGeneric
+}
+
+func marshalWebhookParameters(params map[string]string) (string, error) {
+	if len(params) == 0 {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+func newWebhookSecret() (string, error) {
+	var raw [24]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(raw[:]), nil
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpsWebhooks(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	rb, err := s.InsertOpsRunbook(ctx, OpsRunbookWrite{
+		Name:  "deploy",
+		Steps: []OpsRunbookStep{{Type: "run", Title: "deploy", Command: "true"}},
+	})
+	if err != nil {
+		t.Fatalf("InsertOpsRunbook() error = %v", err)
+	}
+
+	created, secret, err := s.CreateOpsWebhook(ctx, OpsWebhookWrite{
+		Name:       " ci ",
+		RunbookID:  rb.ID,
+		Parameters: map[string]string{"ENV": "staging"},
+		Enabled:    true,
+	})
+	if err != nil {
+		t.Fatalf("CreateOpsWebhook() error = %v", err)
+	}
+	if created.ID == "" || created.Name != "ci" || !created.Enabled || created.Parameters["ENV"] != "staging" {
+		t.Fatalf("created webhook = %#v", created)
+	}
+	if !strings.HasPrefix(secret, webhookSecretPrefix) || created.Secret != secret {
+		t.Fatalf("secret = %q, stored %q", secret, created.Secret)
+	}
+	if _, _, err := s.CreateOpsWebhook(ctx, OpsWebhookWrite{Name: "ci", RunbookID: rb.ID}); err == nil {
+		t.Fatal("CreateOpsWebhook() with a duplicate name succeeded")
+	}
+
+	updated, err := s.UpdateOpsWebhook(ctx, OpsWebhookWrite{ID: created.ID, Name: "ci-main", RunbookID: rb.ID})
+	if err != nil {
+		t.Fatalf("UpdateOpsWebhook() error = %v", err)
+	}
+	if updated.Name != "ci-main" || updated.Enabled || len(updated.Parameters) != 0 || updated.Secret != secret {
+		t.Fatalf("updated webhook = %#v", updated)
+	}
+	if _, err := s.UpdateOpsWebhook(ctx, OpsWebhookWrite{ID: "missing", Name: "x"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("UpdateOpsWebhook(missing) error = %v, want sql.ErrNoRows", err)
+	}
+
+	triggered := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := s.MarkOpsWebhookTriggered(ctx, created.ID, triggered); err != nil {
+		t.Fatalf("MarkOpsWebhookTriggered() error = %v", err)
+	}
+	hooks, err := s.ListOpsWebhooks(ctx)
+	if err != nil {
+		t.Fatalf("ListOpsWebhooks() error = %v", err)
+	}
+	if len(hooks) != 1 || !hooks[0].LastTriggeredAt.Equal(triggered) {
+		t.Fatalf("webhooks = %#v", hooks)
+	}
+
+	// Deleting the runbook removes the webhooks bound to it.
+	deleted, err := s.DeleteOpsRunbook(ctx, rb.ID, "")
+	if err != nil {
+		t.Fatalf("DeleteOpsRunbook() error = %v", err)
+	}
+	if deleted.DeletedWebhooks != 1 {
+		t.Fatalf("DeletedWebhooks = %d, want 1", deleted.DeletedWebhooks)
+	}
+	if _, err := s.GetOpsWebhook(ctx, created.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("GetOpsWebhook() after runbook delete error = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.DeleteOpsWebhook(ctx, created.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("DeleteOpsWebhook(missing) error = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// WatchtowerSessionDiff is what one collection tick changes for a session:
+// the rows that differ from what is stored, and the windows and panes that
+// are stored but no longer live.
+type WatchtowerSessionDiff struct {
+	SessionName          string
+	Session              *WatchtowerSessionWrite // nil when unchanged
+	Windows              []WatchtowerWindowWrite
+	Panes                []WatchtowerPaneWrite
+	RemovedWindowIndices []int
+	RemovedPaneIDs       []string
+}
+
+// Empty reports whether the diff writes nothing.
+func (d WatchtowerSessionDiff) Empty() bool {
+	return d.Session == nil && len(d.Windows) == 0 && len(d.Panes) == 0 &&
+		len(d.RemovedWindowIndices) == 0 && len(d.RemovedPaneIDs) == 0
+}
+
+// WatchtowerCollectBatch is everything a collection tick writes.
+type WatchtowerCollectBatch struct {
+	Sessions []WatchtowerSessionDiff
+	// ActiveSessions are the sessions still live; every other session is
+	// purged with its windows and panes.
+	ActiveSessions []string
+	Journal        []WatchtowerJournalWrite
+	// GlobalRev is stored as the global revision when Journal is not empty.
+	GlobalRev int64
+}
+
+// ApplyWatchtowerCollect writes a collection tick in one transaction, so a
+// host with many panes pays for a single commit instead of one per row.
+// Each kind of row goes through one prepared statement.
+func (s *Store) ApplyWatchtowerCollect(ctx context.Context, batch WatchtowerCollectBatch) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmts := txStatements{tx: tx}
+	defer stmts.close()
+
+	for _, diff := range batch.Sessions {
+		if err := applyWatchtowerSessionDiff(ctx, &stmts, diff); err != nil {
+			return err
+		}
+	}
+	if err := purgeWatchtowerSessionsTx(ctx, tx, batch.ActiveSessions); err != nil {
+		return err
+	}
+	for _, row := range batch.Journal {
+		args, err := watchtowerJournalArgs(row)
+		if err != nil {
+			return err
+		}
+		if err := stmts.exec(ctx, insertWatchtowerJournalSQL, args...); err != nil {
+			return err
+		}
+	}
+	if len(batch.Journal) > 0 {
+		if err := stmts.exec(ctx, upsertWatchtowerRuntimeSQL, "global_rev", strconv.FormatInt(batch.GlobalRev, 10)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func applyWatchtowerSessionDiff(ctx context.Context, stmts *txStatements, diff WatchtowerSessionDiff) error {
+	sessionName := strings.TrimSpace(diff.SessionName)
+	for _, paneID := range diff.RemovedPaneIDs {
+		if err := stmts.exec(ctx, "DELETE FROM wt_panes WHERE session_name = ? AND pane_id = ?", sessionName, paneID); err != nil {
+			return err
+		}
+	}
+	for _, row := range diff.Panes {
+		args, err := watchtowerPaneArgs(row)
+		if err != nil {
+			return err
+		}
+		if err := stmts.exec(ctx, upsertWatchtowerPaneSQL, args...); err != nil {
+			return err
+		}
+	}
+	for _, windowIndex := range diff.RemovedWindowIndices {
+		if err := stmts.exec(ctx, "DELETE FROM wt_windows WHERE session_name = ? AND window_index = ?", sessionName, windowIndex); err != nil {
+			return err
+		}
+	}
+	for _, row := range diff.Windows {
+		args, err := watchtowerWindowArgs(row)
+		if err != nil {
+			return err
+		}
+		if err := stmts.exec(ctx, upsertWatchtowerWindowSQL, args...); err != nil {
+			return err
+		}
+	}
+	if diff.Session == nil {
+		return nil
+	}
+	args, err := watchtowerSessionArgs(*diff.Session)
+	if err != nil {
+		return err
+	}
+	return stmts.exec(ctx, upsertWatchtowerSessionSQL, args...)
+}
+
+// txStatements prepares each query once per transaction, on first use.
+type txStatements struct {
+	tx    *sql.Tx
+	stmts map[string]*sql.Stmt
+}
+
+func (t *txStatements) exec(ctx context.Context, query string, args ...any) error {
+	stmt, ok := t.stmts[query]
+	if !ok {
+		var err error
+		stmt, err = t.tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		if t.stmts == nil {
+			t.stmts = make(map[string]*sql.Stmt)
+		}
+		t.stmts[query] = stmt
+	}
+	_, err := stmt.ExecContext(ctx, args...)
+	return err
+}
+
+func (t *txStatements) close() {
+	for _, stmt := range t.stmts {
+		_ = stmt.Close()
+	}
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestApplyWatchtowerCollect(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	seedWatchtowerProjectionSession(ctx, t, s, now, "a")
+	seedWatchtowerProjectionSession(ctx, t, s, now, "b")
+
+	err := s.ApplyWatchtowerCollect(ctx, WatchtowerCollectBatch{
+		Sessions: []WatchtowerSessionDiff{{
+			SessionName: "a",
+			Session:     &WatchtowerSessionWrite{SessionName: "a", Windows: 1, Panes: 1, Rev: 2},
+			Windows:     []WatchtowerWindowWrite{{SessionName: "a", WindowIndex: 1, Name: "w1", Rev: 1}},
+			Panes: []WatchtowerPaneWrite{{
+				PaneID:      "%a2",
+				SessionName: "a",
+				WindowIndex: 1,
+				TailHash:    "h2",
+				Revision:    1,
+				ChangedAt:   now,
+			}},
+			RemovedWindowIndices: []int{0},
+			RemovedPaneIDs:       []string{"%a"},
+		}},
+		ActiveSessions: []string{"a"},
+		Journal: []WatchtowerJournalWrite{{
+			GlobalRev:  3,
+			EntityType: "session",
+			Session:    "a",
+			WindowIdx:  -1,
+			ChangeKind: "activity",
+			ChangedAt:  now,
+		}},
+		GlobalRev: 3,
+	})
+	if err != nil {
+		t.Fatalf("ApplyWatchtowerCollect: %v", err)
+	}
+
+	assertWatchtowerSessionNames(ctx, t, s, []string{"a"})
+	session, err := s.GetWatchtowerSession(ctx, "a")
+	if err != nil || session.Rev != 2 {
+		t.Fatalf("session a = %+v, %v; want rev 2", session, err)
+	}
+	windows, err := s.ListWatchtowerWindows(ctx, "a")
+	if err != nil || len(windows) != 1 || windows[0].WindowIndex != 1 {
+		t.Fatalf("windows = %+v, %v; want only window 1", windows, err)
+	}
+	panes, err := s.ListWatchtowerPanes(ctx, "a")
+	if err != nil || len(panes) != 1 || panes[0].PaneID != "%a2" {
+		t.Fatalf("panes = %+v, %v; want only %%a2", panes, err)
+	}
+	if rev, err := s.WatchtowerGlobalRevision(ctx); err != nil || rev != 3 {
+		t.Fatalf("global revision = %d, %v; want 3", rev, err)
+	}
+	journal, err := s.ListWatchtowerJournalSince(ctx, 0, 10)
+	if err != nil || len(journal) != 1 || journal[0].Session != "a" {
+		t.Fatalf("journal = %+v, %v", journal, err)
+	}
+
+	// A failing row rolls back the whole tick.
+	err = s.ApplyWatchtowerCollect(ctx, WatchtowerCollectBatch{
+		Sessions: []WatchtowerSessionDiff{{
+			SessionName:    "a",
+			RemovedPaneIDs: []string{"%a2"},
+			Panes:          []WatchtowerPaneWrite{{PaneID: "%bad"}},
+		}},
+		ActiveSessions: []string{"a"},
+	})
+	if err == nil {
+		t.Fatal("ApplyWatchtowerCollect(invalid pane) succeeded")
+	}
+	assertWatchtowerPaneCount(ctx, t, s, "a", 1)
+}
@@ -9,21 +9,40 @@ import (
 	"time"
 )
 
+const insertWatchtowerJournalSQL = `INSERT INTO wt_journal (
+		global_rev, entity_type, session_name, window_index,
+		pane_id, change_kind, changed_at
+	 ) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+const upsertWatchtowerRuntimeSQL = `INSERT INTO wt_runtime (key, value, updated_at)
+	 VALUES (?, ?, datetime('now'))
+	 ON CONFLICT(key) DO UPDATE SET
+		value = excluded.value,
+		updated_at = excluded.updated_at`
+
 // InsertWatchtowerJournal inserts watchtower journal.
 func (s *Store) InsertWatchtowerJournal(ctx context.Context, row WatchtowerJournalWrite) (int64, error) {
+	args, err := watchtowerJournalArgs(row)
+	if err != nil {
+		return 0, err
+	}
+	result, err := s.db.ExecContext(ctx, insertWatchtowerJournalSQL, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func watchtowerJournalArgs(row WatchtowerJournalWrite) ([]any, error) {
 	entityType := strings.TrimSpace(row.EntityType)
 	if entityType == "" {
-		return 0, errors.New("entity type is required")
+		return nil, errors.New("entity type is required")
 	}
 	changedAt := row.ChangedAt.UTC()
 	if changedAt.IsZero() {
 		changedAt = time.Now().UTC()
 	}
-	result, err := s.db.ExecContext(ctx,
-		`INSERT INTO wt_journal (
-			global_rev, entity_type, session_name, window_index,
-			pane_id, change_kind, changed_at
-		 ) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+	return []any{
 		row.GlobalRev,
 		entityType,
 		strings.TrimSpace(row.Session),
@@ -31,11 +50,7 @@ func (s *Store) InsertWatchtowerJournal(ctx context.Context, row WatchtowerJourn
 		strings.TrimSpace(row.PaneID),
 		strings.TrimSpace(row.ChangeKind),
 		changedAt.Format(time.RFC3339),
-	)
-	if err != nil {
-		return 0, err
-	}
-	return result.LastInsertId()
+	}, nil
 }
 
 // ListWatchtowerJournalSince lists watchtower journal since.
@@ -82,6 +97,42 @@ func (s *Store) ListWatchtowerJournalSince(ctx context.Context, sinceRev int64,
 	return out, rows.Err()
 }
 
+// ListWatchtowerActivityBuckets counts the journal entries of each session
+// per UTC hour since the given time, ordered by session and hour. Entries
+// pruned by the journal row limit are not counted.
+func (s *Store) ListWatchtowerActivityBuckets(ctx context.Context, since time.Time) ([]WatchtowerActivityBucket, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT session_name, substr(changed_at, 1, 13) AS hour, COUNT(*)
+		   FROM wt_journal
+		  WHERE session_name != '' AND changed_at >= ?
+		  GROUP BY session_name, hour
+		  ORDER BY session_name ASC, hour ASC`,
+		since.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]WatchtowerActivityBucket, 0)
+	for rows.Next() {
+		var (
+			bucket  WatchtowerActivityBucket
+			hourRaw string
+		)
+		if err := rows.Scan(&bucket.Session, &hourRaw, &bucket.Changes); err != nil {
+			return nil, err
+		}
+		hour, err := time.Parse("2006-01-02T15", hourRaw)
+		if err != nil {
+			continue
+		}
+		bucket.Hour = hour.UTC()
+		out = append(out, bucket)
+	}
+	return out, rows.Err()
+}
+
 // PruneWatchtowerJournalRows prunes watchtower journal rows.
 func (s *Store) PruneWatchtowerJournalRows(ctx context.Context, maxRows int) (int64, error) {
 	if maxRows <= 0 {
@@ -106,15 +157,7 @@ func (s *Store) PruneWatchtowerJournalRows(ctx context.Context, maxRows int) (in
 
 // SetWatchtowerRuntimeValue sets watchtower runtime value.
 func (s *Store) SetWatchtowerRuntimeValue(ctx context.Context, key, value string) error {
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO wt_runtime (key, value, updated_at)
-		 VALUES (?, ?, datetime('now'))
-		 ON CONFLICT(key) DO UPDATE SET
-			value = excluded.value,
-			updated_at = excluded.updated_at`,
-		strings.TrimSpace(key),
-		value,
-	)
+	_, err := s.db.ExecContext(ctx, upsertWatchtowerRuntimeSQL, strings.TrimSpace(key), value)
 	return err
 }
 
@@ -131,12 +174,7 @@ func (s *Store) SetWatchtowerRuntimeValues(ctx context.Context, values map[strin
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO wt_runtime (key, value, updated_at)
-		 VALUES (?, ?, datetime('now'))
-		 ON CONFLICT(key) DO UPDATE SET
-			value = excluded.value,
-			updated_at = excluded.updated_at`)
+	stmt, err := tx.PrepareContext(ctx, upsertWatchtowerRuntimeSQL)
 	if err != nil {
 		return err
 	}
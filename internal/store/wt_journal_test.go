@@ -55,6 +55,42 @@ func TestWatchtowerJournalListFilteringLimitAndPrune(t *testing.T) {
 	}
 }
 
+func TestWatchtowerActivityBucketsGroupBySessionAndHour(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+	base := time.Date(2026, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	for i, row := range []WatchtowerJournalWrite{
+		{EntityType: "session", Session: "dev", ChangedAt: base.Add(-25 * time.Hour)},
+		{EntityType: "session", Session: "dev", ChangedAt: base.Add(5 * time.Minute)},
+		{EntityType: "session", Session: "dev", ChangedAt: base.Add(59 * time.Minute)},
+		{EntityType: "session", Session: "dev", ChangedAt: base.Add(time.Hour)},
+		{EntityType: "session", Session: "ops", ChangedAt: base.Add(10 * time.Minute)},
+		{EntityType: "session", Session: "", ChangedAt: base.Add(10 * time.Minute)},
+	} {
+		row.GlobalRev = int64(i + 1)
+		if _, err := s.InsertWatchtowerJournal(ctx, row); err != nil {
+			t.Fatalf("InsertWatchtowerJournal(%d): %v", i, err)
+		}
+	}
+
+	buckets, err := s.ListWatchtowerActivityBuckets(ctx, base.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("ListWatchtowerActivityBuckets: %v", err)
+	}
+	want := []WatchtowerActivityBucket{
+		{Session: "dev", Hour: base, Changes: 2},
+		{Session: "dev", Hour: base.Add(time.Hour), Changes: 1},
+		{Session: "ops", Hour: base, Changes: 1},
+	}
+	if !reflect.DeepEqual(buckets, want) {
+		t.Fatalf("buckets = %+v, want %+v", buckets, want)
+	}
+}
+
 func TestWatchtowerGlobalRevisionMissingValidAndInvalid(t *testing.T) {
 	t.Parallel()
 
@@ -24,6 +24,7 @@ func BuildWatchtowerPanePatches(panes []WatchtowerPane) []map[string]any {
 			"paneId":         row.PaneID,
 			"title":          row.Title,
 			"active":         row.Active,
+			"zoomed":         row.Zoomed,
 			"tty":            row.TTY,
 			"currentPath":    row.CurrentPath,
 			"startCommand":   row.StartCommand,
@@ -38,49 +39,59 @@ func BuildWatchtowerPanePatches(panes []WatchtowerPane) []map[string]any {
 	return patches
 }
 
+const upsertWatchtowerPaneSQL = `INSERT INTO wt_panes (
+		pane_id, session_name, window_index, pane_index, title,
+		active, tty, current_path, start_command, current_command,
+		tail_hash, tail_preview, tail_captured_at,
+		revision, seen_revision, changed_at, updated_at, zoomed
+	 ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	 ON CONFLICT(pane_id) DO UPDATE SET
+		session_name = excluded.session_name,
+		window_index = excluded.window_index,
+		pane_index = excluded.pane_index,
+		title = excluded.title,
+		active = excluded.active,
+		tty = excluded.tty,
+		current_path = excluded.current_path,
+		start_command = excluded.start_command,
+		current_command = excluded.current_command,
+		tail_hash = excluded.tail_hash,
+		tail_preview = excluded.tail_preview,
+		tail_captured_at = excluded.tail_captured_at,
+		revision = excluded.revision,
+		-- seen_revision only ever moves forward: a collection upsert carries
+		-- the value it read at the start of the tick, so without this clamp a
+		-- concurrent "mark seen" (which raises seen_revision) would be lost and
+		-- the pane would pop back as unread. max() keeps the highest seen.
+		seen_revision = max(seen_revision, excluded.seen_revision),
+		changed_at = excluded.changed_at,
+		updated_at = excluded.updated_at,
+		zoomed = excluded.zoomed`
+
 // UpsertWatchtowerPane upserts watchtower pane.
 func (s *Store) UpsertWatchtowerPane(ctx context.Context, row WatchtowerPaneWrite) error {
+	args, err := watchtowerPaneArgs(row)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, upsertWatchtowerPaneSQL, args...)
+	return err
+}
+
+func watchtowerPaneArgs(row WatchtowerPaneWrite) ([]any, error) {
 	paneID := strings.TrimSpace(row.PaneID)
 	if paneID == "" {
-		return errors.New("pane id is required")
+		return nil, errors.New("pane id is required")
 	}
 	name := strings.TrimSpace(row.SessionName)
 	if name == "" {
-		return errors.New("session name is required")
+		return nil, errors.New("session name is required")
 	}
 	updatedAt := row.UpdatedAt.UTC()
 	if updatedAt.IsZero() {
 		updatedAt = time.Now().UTC()
 	}
-
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO wt_panes (
-			pane_id, session_name, window_index, pane_index, title,
-			active, tty, current_path, start_command, current_command,
-			tail_hash, tail_preview, tail_captured_at,
-			revision, seen_revision, changed_at, updated_at
-		 ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		 ON CONFLICT(pane_id) DO UPDATE SET
-			session_name = excluded.session_name,
-			window_index = excluded.window_index,
-			pane_index = excluded.pane_index,
-			title = excluded.title,
-			active = excluded.active,
-			tty = excluded.tty,
-			current_path = excluded.current_path,
-			start_command = excluded.start_command,
-			current_command = excluded.current_command,
-			tail_hash = excluded.tail_hash,
-			tail_preview = excluded.tail_preview,
-			tail_captured_at = excluded.tail_captured_at,
-			revision = excluded.revision,
-			-- seen_revision only ever moves forward: a collection upsert carries
-			-- the value it read at the start of the tick, so without this clamp a
-			-- concurrent "mark seen" (which raises seen_revision) would be lost and
-			-- the pane would pop back as unread. max() keeps the highest seen.
-			seen_revision = max(seen_revision, excluded.seen_revision),
-			changed_at = excluded.changed_at,
-			updated_at = excluded.updated_at`,
+	return []any{
 		paneID,
 		name,
 		row.WindowIndex,
@@ -98,8 +109,8 @@ func (s *Store) UpsertWatchtowerPane(ctx context.Context, row WatchtowerPaneWrit
 		row.SeenRevision,
 		formatStoreValueTime(row.ChangedAt),
 		updatedAt.Format(time.RFC3339),
-	)
-	return err
+		boolToInt(row.Zoomed),
+	}, nil
 }
 
 // ListWatchtowerPanes lists watchtower panes.
@@ -108,7 +119,7 @@ func (s *Store) ListWatchtowerPanes(ctx context.Context, sessionName string) ([]
 		`SELECT pane_id, session_name, window_index, pane_index, title,
 		        active, tty, current_path, start_command, current_command,
 		        tail_hash, tail_preview, tail_captured_at,
-		        revision, seen_revision, changed_at, updated_at
+		        revision, seen_revision, changed_at, updated_at, zoomed
 		   FROM wt_panes
 		  WHERE session_name = ?
 		  ORDER BY window_index ASC, pane_index ASC`,
@@ -123,7 +134,7 @@ func (s *Store) ListWatchtowerPanes(ctx context.Context, sessionName string) ([]
 	for rows.Next() {
 		var (
 			row                                   WatchtowerPane
-			activeRaw                             int
+			activeRaw, zoomedRaw                  int
 			tailCapturedRaw, changedAt, updatedAt string
 		)
 		if err := rows.Scan(
@@ -144,10 +155,12 @@ func (s *Store) ListWatchtowerPanes(ctx context.Context, sessionName string) ([]
 			&row.SeenRevision,
 			&changedAt,
 			&updatedAt,
+			&zoomedRaw,
 		); err != nil {
 			return nil, err
 		}
 		row.Active = activeRaw == 1
+		row.Zoomed = zoomedRaw == 1
 		row.TailCapturedAt = parseStoreTime(tailCapturedRaw)
 		row.ChangedAt = parseStoreTime(changedAt)
 		row.UpdatedAt = parseStoreTime(updatedAt)
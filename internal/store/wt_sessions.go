@@ -56,35 +56,44 @@ func BuildWatchtowerInspectorPatchWithManaged(
 	}
 }
 
+const upsertWatchtowerSessionSQL = `INSERT INTO wt_sessions (
+		session_name, attached, windows, panes, activity_at,
+		last_preview, last_preview_at, last_preview_pane_id,
+		unread_windows, unread_panes, rev, updated_at
+	 ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	 ON CONFLICT(session_name) DO UPDATE SET
+		attached = excluded.attached,
+		windows = excluded.windows,
+		panes = excluded.panes,
+		activity_at = excluded.activity_at,
+		last_preview = excluded.last_preview,
+		last_preview_at = excluded.last_preview_at,
+		last_preview_pane_id = excluded.last_preview_pane_id,
+		unread_windows = excluded.unread_windows,
+		unread_panes = excluded.unread_panes,
+		rev = excluded.rev,
+		updated_at = excluded.updated_at`
+
 // UpsertWatchtowerSession upserts watchtower session.
 func (s *Store) UpsertWatchtowerSession(ctx context.Context, row WatchtowerSessionWrite) error {
+	args, err := watchtowerSessionArgs(row)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, upsertWatchtowerSessionSQL, args...)
+	return err
+}
+
+func watchtowerSessionArgs(row WatchtowerSessionWrite) ([]any, error) {
 	name := strings.TrimSpace(row.SessionName)
 	if name == "" {
-		return errors.New("session name is required")
+		return nil, errors.New("session name is required")
 	}
 	updatedAt := row.UpdatedAt.UTC()
 	if updatedAt.IsZero() {
 		updatedAt = time.Now().UTC()
 	}
-
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO wt_sessions (
-			session_name, attached, windows, panes, activity_at,
-			last_preview, last_preview_at, last_preview_pane_id,
-			unread_windows, unread_panes, rev, updated_at
-		 ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		 ON CONFLICT(session_name) DO UPDATE SET
-			attached = excluded.attached,
-			windows = excluded.windows,
-			panes = excluded.panes,
-			activity_at = excluded.activity_at,
-			last_preview = excluded.last_preview,
-			last_preview_at = excluded.last_preview_at,
-			last_preview_pane_id = excluded.last_preview_pane_id,
-			unread_windows = excluded.unread_windows,
-			unread_panes = excluded.unread_panes,
-			rev = excluded.rev,
-			updated_at = excluded.updated_at`,
+	return []any{
 		name,
 		row.Attached,
 		row.Windows,
@@ -97,8 +106,7 @@ func (s *Store) UpsertWatchtowerSession(ctx context.Context, row WatchtowerSessi
 		row.UnreadPanes,
 		row.Rev,
 		updatedAt.Format(time.RFC3339),
-	)
-	return err
+	}, nil
 }
 
 // GetWatchtowerSession returns watchtower session.
@@ -219,6 +227,13 @@ func (s *Store) PurgeWatchtowerSessions(ctx context.Context, activeSessions []st
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	if err := purgeWatchtowerSessionsTx(ctx, tx, activeSessions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func purgeWatchtowerSessionsTx(ctx context.Context, tx *sql.Tx, activeSessions []string) error {
 	if len(activeSessions) == 0 {
 		for _, stmt := range []string{
 			"DELETE FROM wt_panes",
@@ -229,7 +244,7 @@ func (s *Store) PurgeWatchtowerSessions(ctx context.Context, activeSessions []st
 				return err
 			}
 		}
-		return tx.Commit()
+		return nil
 	}
 
 	placeholders := sqlPlaceholders(len(activeSessions))
@@ -247,5 +262,5 @@ func (s *Store) PurgeWatchtowerSessions(ctx context.Context, activeSessions []st
 			return err
 		}
 	}
-	return tx.Commit()
+	return nil
 }
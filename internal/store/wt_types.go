@@ -72,6 +72,7 @@ type WatchtowerPane struct {
 	PaneIndex      int       `json:"paneIndex"`
 	Title          string    `json:"title"`
 	Active         bool      `json:"active"`
+	Zoomed         bool      `json:"zoomed"`
 	TTY            string    `json:"tty"`
 	CurrentPath    string    `json:"currentPath"`
 	StartCommand   string    `json:"startCommand"`
@@ -93,6 +94,7 @@ type WatchtowerPaneWrite struct {
 	PaneIndex      int
 	Title          string
 	Active         bool
+	Zoomed         bool
 	TTY            string
 	CurrentPath    string
 	StartCommand   string
@@ -142,6 +144,14 @@ type WatchtowerJournal struct {
 	ChangedAt  time.Time `json:"changedAt"`
 }
 
+// WatchtowerActivityBucket counts the journaled changes of one session
+// within one UTC hour.
+type WatchtowerActivityBucket struct {
+	Session string    `json:"session"`
+	Hour    time.Time `json:"hour"`
+	Changes int64     `json:"changes"`
+}
+
 // WatchtowerJournalWrite represents watchtower journal write data.
 type WatchtowerJournalWrite struct {
 	GlobalRev  int64
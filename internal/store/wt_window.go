@@ -57,32 +57,41 @@ func BuildWatchtowerWindowPatchesWithManaged(
 	return patches
 }
 
+const upsertWatchtowerWindowSQL = `INSERT INTO wt_windows (
+		session_name, tmux_window_id, window_index, name, active, layout,
+		window_activity_at, unread_panes, has_unread, rev, updated_at
+	 ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	 ON CONFLICT(session_name, window_index) DO UPDATE SET
+		tmux_window_id = excluded.tmux_window_id,
+		name = excluded.name,
+		active = excluded.active,
+		layout = excluded.layout,
+		window_activity_at = excluded.window_activity_at,
+		unread_panes = excluded.unread_panes,
+		has_unread = excluded.has_unread,
+		rev = excluded.rev,
+		updated_at = excluded.updated_at`
+
 // UpsertWatchtowerWindow upserts watchtower window.
 func (s *Store) UpsertWatchtowerWindow(ctx context.Context, row WatchtowerWindowWrite) error {
+	args, err := watchtowerWindowArgs(row)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, upsertWatchtowerWindowSQL, args...)
+	return err
+}
+
+func watchtowerWindowArgs(row WatchtowerWindowWrite) ([]any, error) {
 	name := strings.TrimSpace(row.SessionName)
 	if name == "" {
-		return errors.New("session name is required")
+		return nil, errors.New("session name is required")
 	}
 	updatedAt := row.UpdatedAt.UTC()
 	if updatedAt.IsZero() {
 		updatedAt = time.Now().UTC()
 	}
-
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO wt_windows (
-			session_name, tmux_window_id, window_index, name, active, layout,
-			window_activity_at, unread_panes, has_unread, rev, updated_at
-		 ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		 ON CONFLICT(session_name, window_index) DO UPDATE SET
-			tmux_window_id = excluded.tmux_window_id,
-			name = excluded.name,
-			active = excluded.active,
-			layout = excluded.layout,
-			window_activity_at = excluded.window_activity_at,
-			unread_panes = excluded.unread_panes,
-			has_unread = excluded.has_unread,
-			rev = excluded.rev,
-			updated_at = excluded.updated_at`,
+	return []any{
 		name,
 		strings.TrimSpace(row.TmuxWindowID),
 		row.WindowIndex,
@@ -94,8 +103,7 @@ func (s *Store) UpsertWatchtowerWindow(ctx context.Context, row WatchtowerWindow
 		boolToInt(row.HasUnread),
 		row.Rev,
 		updatedAt.Format(time.RFC3339),
-	)
-	return err
+	}, nil
 }
 
 // ListWatchtowerWindows lists watchtower windows.
@@ -0,0 +1,49 @@
+//go:build !linux && !darwin
+
+package term
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opus-domini/sentinel/internal/userswitch"
+)
+
+// ErrUnsupported is returned where the platform has no pseudo-terminals,
+// e.g. on Windows, which only runs the ops half of Sentinel.
+var ErrUnsupported = errors.New("terminals are not supported on this platform")
+
+// PTY represents PTY data.
+type PTY struct{}
+
+// UserSwitchMethod controls how multi-user tmux attach commands are launched.
+// Set from main.go after config.Load().
+var UserSwitchMethod = userswitch.MethodSystemdRun // set once at startup from config
+
+// StartTmuxAttach starts tmux attach.
+func StartTmuxAttach(context.Context, string, int, int) (*PTY, error) {
+	return nil, ErrUnsupported
+}
+
+// StartTmuxAttachAsUser starts tmux attach as user.
+func StartTmuxAttachAsUser(context.Context, string, string, int, int) (*PTY, error) {
+	return nil, ErrUnsupported
+}
+
+// StartShell starts shell.
+func StartShell(context.Context, string, int, int) (*PTY, error) {
+	return nil, ErrUnsupported
+}
+
+func (p *PTY) Read([]byte) (int, error) { return 0, ErrUnsupported }
+
+func (p *PTY) Write([]byte) (int, error) { return 0, ErrUnsupported }
+
+// Wait handles wait.
+func (p *PTY) Wait() error { return ErrUnsupported }
+
+// Resize handles resize.
+func (p *PTY) Resize(int, int) error { return ErrUnsupported }
+
+// Close closes value.
+func (p *PTY) Close() error { return nil }
@@ -0,0 +1,75 @@
+package tmux
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const errBufferNameRequired = "buffer name is required"
+
+// Buffer is a tmux paste buffer. Sample is tmux's escaped preview of the
+// start of its content.
+type Buffer struct {
+	Name      string    `json:"name"`
+	Size      int       `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+	Sample    string    `json:"sample"`
+}
+
+func listBuffersVia(ctx context.Context, runFn runnerFunc) ([]Buffer, error) {
+	out, err := runFn(ctx, "list-buffers", "-F", "#{buffer_name}\t#{buffer_size}\t#{buffer_created}\t#{buffer_sample}")
+	if err != nil {
+		if IsKind(err, ErrKindServerNotRunning) {
+			return []Buffer{}, nil
+		}
+		return nil, err
+	}
+	return parseBufferListOutput(out), nil
+}
+
+func parseBufferListOutput(out string) []Buffer {
+	buffers := []Buffer{}
+	for line := range strings.SplitSeq(strings.TrimRight(out, "\n"), "\n") {
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) != 4 || parts[0] == "" {
+			continue
+		}
+		buffer := Buffer{Name: parts[0], Sample: parts[3]}
+		buffer.Size, _ = strconv.Atoi(parts[1])
+		if created, err := strconv.ParseInt(parts[2], 10, 64); err == nil && created > 0 {
+			buffer.CreatedAt = time.Unix(created, 0).UTC()
+		}
+		buffers = append(buffers, buffer)
+	}
+	return buffers
+}
+
+func showBufferVia(ctx context.Context, runFn runnerFunc, name string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", &Error{Kind: ErrKindInvalidIdentifier, Msg: errBufferNameRequired}
+	}
+	return runFn(ctx, "show-buffer", "-b", name)
+}
+
+func setBufferVia(ctx context.Context, runFn runnerFunc, name, content string) error {
+	if strings.TrimSpace(name) == "" {
+		return &Error{Kind: ErrKindInvalidIdentifier, Msg: errBufferNameRequired}
+	}
+	_, err := runFn(ctx, "set-buffer", "-b", name, "--", content)
+	return err
+}
+
+// pasteBufferVia pastes with -p so applications that enabled bracketed
+// paste receive the content as one paste rather than typed keys.
+func pasteBufferVia(ctx context.Context, runFn runnerFunc, name, paneID string) error {
+	if strings.TrimSpace(name) == "" {
+		return &Error{Kind: ErrKindInvalidIdentifier, Msg: errBufferNameRequired}
+	}
+	if strings.TrimSpace(paneID) == "" {
+		return &Error{Kind: ErrKindInvalidIdentifier, Msg: errPaneIDRequired}
+	}
+	_, err := runFn(ctx, "paste-buffer", "-p", "-b", name, "-t", paneID)
+	return err
+}
@@ -0,0 +1,110 @@
+package tmux
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBufferListOutput(t *testing.T) {
+	t.Parallel()
+
+	out := "buffer0001\t12\t1700000000\thello\\tworld\nclip\t3\t0\ta\tb\n\nbad line\n"
+	got := parseBufferListOutput(out)
+	want := []Buffer{
+		{Name: "buffer0001", Size: 12, CreatedAt: time.Unix(1700000000, 0).UTC(), Sample: `hello\tworld`},
+		{Name: "clip", Size: 3, Sample: "a\tb"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseBufferListOutput() = %+v, want %+v", got, want)
+	}
+	if got := parseBufferListOutput(""); got == nil || len(got) != 0 {
+		t.Fatalf("parseBufferListOutput(empty) = %#v, want empty slice", got)
+	}
+}
+
+func TestBufferCommandsVia(t *testing.T) {
+	t.Parallel()
+
+	var calls [][]string
+	runFn := func(_ context.Context, args ...string) (string, error) {
+		calls = append(calls, slices.Clone(args))
+		return "content", nil
+	}
+	ctx := context.Background()
+	if out, err := showBufferVia(ctx, runFn, "clip"); err != nil || out != "content" {
+		t.Fatalf("showBufferVia() = %q, %v", out, err)
+	}
+	if err := setBufferVia(ctx, runFn, "clip", "-n\nnext"); err != nil {
+		t.Fatalf("setBufferVia() error = %v", err)
+	}
+	if err := pasteBufferVia(ctx, runFn, "clip", "%3"); err != nil {
+		t.Fatalf("pasteBufferVia() error = %v", err)
+	}
+	want := [][]string{
+		{"show-buffer", "-b", "clip"},
+		{"set-buffer", "-b", "clip", "--", "-n\nnext"},
+		{"paste-buffer", "-p", "-b", "clip", "-t", "%3"},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+
+	if err := pasteBufferVia(ctx, runFn, "clip", " "); !IsKind(err, ErrKindInvalidIdentifier) {
+		t.Fatalf("pasteBufferVia(empty pane) error = %v, want ErrKindInvalidIdentifier", err)
+	}
+	if err := setBufferVia(ctx, runFn, "", "x"); !IsKind(err, ErrKindInvalidIdentifier) {
+		t.Fatalf("setBufferVia(empty name) error = %v, want ErrKindInvalidIdentifier", err)
+	}
+}
+
+func TestListBuffersViaServerNotRunning(t *testing.T) {
+	t.Parallel()
+
+	runFn := func(context.Context, ...string) (string, error) {
+		return "", &Error{Kind: ErrKindServerNotRunning}
+	}
+	got, err := listBuffersVia(context.Background(), runFn)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("listBuffersVia() = %+v, %v, want no buffers", got, err)
+	}
+}
+
+func TestClassifyErrorBufferNotFound(t *testing.T) {
+	t.Parallel()
+
+	err := classifyError(errors.New("exit status 1"), "no buffer clip\n", []string{"show-buffer", "-b", "clip"})
+	if !IsKind(err, ErrKindBufferNotFound) {
+		t.Fatalf("classifyError() = %v, want ErrKindBufferNotFound", err)
+	}
+}
+
+func TestServiceShowBufferBypassesControl(t *testing.T) {
+	t.Parallel()
+
+	control := NewControlClient("", nil)
+	control.dial = func(context.Context) (*controlConn, error) {
+		t.Fatal("ShowBuffer dialed the control connection")
+		return nil, errors.New("unreachable")
+	}
+	var got []string
+	svc := Service{
+		Control: control,
+		Command: func(ctx context.Context, name string, args ...string) *exec.Cmd {
+			got = args
+			return exec.CommandContext(ctx, "printf", "%s", `a\b`)
+		},
+	}
+	out, err := svc.ShowBuffer(context.Background(), "clip")
+	if err != nil || out != `a\b` {
+		t.Fatalf("ShowBuffer() = %q, %v", out, err)
+	}
+	if strings.Join(got, " ") != "show-buffer -b clip" {
+		t.Fatalf("command args = %q", got)
+	}
+}
@@ -0,0 +1,129 @@
+package tmux
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// CaptureRangeOptions selects the lines returned by CapturePaneRange. Line
+// numbers follow capture-pane: 0 is the first visible line and negative
+// numbers reach back into the scrollback history.
+type CaptureRangeOptions struct {
+	Start int
+	End   int
+	// Escapes keeps ANSI color and attribute sequences in the output.
+	Escapes bool
+	// MaxLines caps the returned span; the newest lines are kept.
+	MaxLines int
+}
+
+// PaneCapture is a slice of pane scrollback together with the bounds needed
+// to page through the rest of it.
+type PaneCapture struct {
+	PaneID      string `json:"paneId"`
+	Content     string `json:"content"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	HistorySize int    `json:"historySize"`
+	Height      int    `json:"height"`
+}
+
+func capturePaneRangeVia(ctx context.Context, runFn runnerFunc, paneID string, opts CaptureRangeOptions) (PaneCapture, error) {
+	paneID = strings.TrimSpace(paneID)
+	if paneID == "" {
+		return PaneCapture{}, &Error{Kind: ErrKindInvalidIdentifier, Msg: errPaneIDRequired}
+	}
+	out, err := runFn(ctx, "display-message", "-p", "-t", paneID, "#{history_size}\t#{pane_height}")
+	if err != nil {
+		return PaneCapture{}, err
+	}
+	historySize, height, err := parsePaneExtent(out)
+	if err != nil {
+		return PaneCapture{}, err
+	}
+
+	capture := PaneCapture{PaneID: paneID, HistorySize: historySize, Height: height}
+	capture.Start, capture.End = clampCaptureRange(opts, historySize, height)
+
+	args := []string{"capture-pane", "-p", "-t", paneID, "-S", strconv.Itoa(capture.Start), "-E", strconv.Itoa(capture.End)}
+	if opts.Escapes {
+		args = append(args, "-e")
+	}
+	capture.Content, err = runFn(ctx, args...)
+	if err != nil {
+		return PaneCapture{}, err
+	}
+	return capture, nil
+}
+
+func parsePaneExtent(out string) (int, int, error) {
+	parts := strings.Split(strings.TrimSpace(out), "\t")
+	if len(parts) != 2 {
+		return 0, 0, &Error{Kind: ErrKindCommandFailed, Msg: "unexpected pane extent output"}
+	}
+	historySize, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, &Error{Kind: ErrKindCommandFailed, Msg: "invalid history size", Err: err}
+	}
+	height, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, &Error{Kind: ErrKindCommandFailed, Msg: "invalid pane height", Err: err}
+	}
+	return historySize, height, nil
+}
+
+func clampCaptureRange(opts CaptureRangeOptions, historySize, height int) (int, int) {
+	first, last := -historySize, height-1
+	if last < first {
+		last = first
+	}
+	end := min(max(opts.End, first), last)
+	start := min(max(opts.Start, first), end)
+	if opts.MaxLines > 0 && end-start+1 > opts.MaxLines {
+		start = end - opts.MaxLines + 1
+	}
+	return start, end
+}
+
+// PaneView is the visible screen of a pane, with color escapes, and where
+// its cursor sits.
+type PaneView struct {
+	Content string
+	CursorX int
+	CursorY int
+}
+
+func capturePaneViewVia(ctx context.Context, runFn runnerFunc, paneID string) (PaneView, error) {
+	paneID = strings.TrimSpace(paneID)
+	if paneID == "" {
+		return PaneView{}, &Error{Kind: ErrKindInvalidIdentifier, Msg: errPaneIDRequired}
+	}
+	out, err := runFn(ctx, "display-message", "-p", "-t", paneID, "#{cursor_x}\t#{cursor_y}")
+	if err != nil {
+		return PaneView{}, err
+	}
+	var view PaneView
+	view.CursorX, view.CursorY, err = parsePaneCursor(out)
+	if err != nil {
+		return PaneView{}, err
+	}
+	view.Content, err = runFn(ctx, "capture-pane", "-p", "-e", "-t", paneID)
+	if err != nil {
+		return PaneView{}, err
+	}
+	return view, nil
+}
+
+func parsePaneCursor(out string) (int, int, error) {
+	parts := strings.Split(strings.TrimSpace(out), "\t")
+	if len(parts) != 2 {
+		return 0, 0, &Error{Kind: ErrKindCommandFailed, Msg: "unexpected pane cursor output"}
+	}
+	x, errX := strconv.Atoi(parts[0])
+	y, errY := strconv.Atoi(parts[1])
+	if errX != nil || errY != nil {
+		return 0, 0, &Error{Kind: ErrKindCommandFailed, Msg: "invalid pane cursor"}
+	}
+	return x, y, nil
+}
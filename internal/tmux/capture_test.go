@@ -0,0 +1,113 @@
+package tmux
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestCapturePaneRangeVia(t *testing.T) {
+	t.Parallel()
+
+	if _, err := capturePaneRangeVia(context.Background(), nil, " ", CaptureRangeOptions{}); !IsKind(err, ErrKindInvalidIdentifier) {
+		t.Fatalf("empty pane error = %v, want ErrKindInvalidIdentifier", err)
+	}
+
+	tests := []struct {
+		name      string
+		opts      CaptureRangeOptions
+		wantArgs  []string
+		wantStart int
+		wantEnd   int
+	}{
+		{
+			name:      "clamped to history and screen",
+			opts:      CaptureRangeOptions{Start: -5000, End: 1 << 30},
+			wantArgs:  []string{"capture-pane", "-p", "-t", "%1", "-S", "-1000", "-E", "23"},
+			wantStart: -1000,
+			wantEnd:   23,
+		},
+		{
+			name:      "max lines keeps newest",
+			opts:      CaptureRangeOptions{Start: -1000, End: -1, MaxLines: 100},
+			wantArgs:  []string{"capture-pane", "-p", "-t", "%1", "-S", "-100", "-E", "-1"},
+			wantStart: -100,
+			wantEnd:   -1,
+		},
+		{
+			name:      "escapes",
+			opts:      CaptureRangeOptions{Start: 0, End: 5, Escapes: true},
+			wantArgs:  []string{"capture-pane", "-p", "-t", "%1", "-S", "0", "-E", "5", "-e"},
+			wantStart: 0,
+			wantEnd:   5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls [][]string
+			runFn := func(_ context.Context, args ...string) (string, error) {
+				calls = append(calls, slices.Clone(args))
+				if args[0] == "display-message" {
+					return "1000\t24\n", nil
+				}
+				return "line", nil
+			}
+			got, err := capturePaneRangeVia(context.Background(), runFn, "%1", tt.opts)
+			if err != nil {
+				t.Fatalf("capturePaneRangeVia() error = %v", err)
+			}
+			if len(calls) != 2 || !slices.Equal(calls[1], tt.wantArgs) {
+				t.Fatalf("calls = %#v, want capture %#v", calls, tt.wantArgs)
+			}
+			want := PaneCapture{PaneID: "%1", Content: "line", Start: tt.wantStart, End: tt.wantEnd, HistorySize: 1000, Height: 24}
+			if got != want {
+				t.Fatalf("capture = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParsePaneExtentRejectsGarbage(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{"", "10", "x\t24", "10\ty"} {
+		if _, _, err := parsePaneExtent(raw); !IsKind(err, ErrKindCommandFailed) {
+			t.Errorf("parsePaneExtent(%q) error = %v, want ErrKindCommandFailed", raw, err)
+		}
+	}
+}
+
+func TestCapturePaneViewVia(t *testing.T) {
+	t.Parallel()
+
+	if _, err := capturePaneViewVia(context.Background(), nil, " "); !IsKind(err, ErrKindInvalidIdentifier) {
+		t.Fatalf("empty pane error = %v, want ErrKindInvalidIdentifier", err)
+	}
+
+	var calls [][]string
+	runFn := func(_ context.Context, args ...string) (string, error) {
+		calls = append(calls, slices.Clone(args))
+		if args[0] == "display-message" {
+			return "4\t2\n", nil
+		}
+		return "\x1b[32mok\x1b[0m", nil
+	}
+	got, err := capturePaneViewVia(context.Background(), runFn, "%3")
+	if err != nil {
+		t.Fatalf("capturePaneViewVia() error = %v", err)
+	}
+	if want := []string{"capture-pane", "-p", "-e", "-t", "%3"}; len(calls) != 2 || !slices.Equal(calls[1], want) {
+		t.Fatalf("calls = %#v, want capture %#v", calls, want)
+	}
+	if want := (PaneView{Content: "\x1b[32mok\x1b[0m", CursorX: 4, CursorY: 2}); got != want {
+		t.Fatalf("view = %+v, want %+v", got, want)
+	}
+
+	for _, raw := range []string{"", "4", "x\t2"} {
+		if _, _, err := parsePaneCursor(raw); !IsKind(err, ErrKindCommandFailed) {
+			t.Errorf("parsePaneCursor(%q) error = %v, want ErrKindCommandFailed", raw, err)
+		}
+	}
+}
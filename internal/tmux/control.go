@@ -0,0 +1,375 @@
+package tmux
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/userswitch"
+)
+
+const (
+	maxControlLineBytes = 4 * 1024 * 1024
+	controlRetryDelay   = 5 * time.Second
+)
+
+// ErrControlUnavailable is returned by ControlClient.Run when no control
+// connection could be opened, e.g. because the server has no session to
+// attach to. Nothing was sent, so the command can be run another way.
+var ErrControlUnavailable = errors.New("tmux control connection unavailable")
+
+// controlNotifications are the control-mode notifications that report a
+// change in the session, window or pane layout.
+var controlNotifications = map[string]bool{
+	"sessions-changed":        true,
+	"session-renamed":         true,
+	"session-window-changed":  true,
+	"window-add":              true,
+	"window-close":            true,
+	"window-renamed":          true,
+	"window-pane-changed":     true,
+	"unlinked-window-add":     true,
+	"unlinked-window-close":   true,
+	"unlinked-window-renamed": true,
+	"layout-change":           true,
+}
+
+// ControlClient runs tmux commands over one control-mode (tmux -C)
+// connection instead of starting a process per command, and reports the
+// change notifications tmux sends on it. It connects on first use, attached
+// to an existing session with output disabled, and reconnects once that
+// session is gone.
+//
+// tmux counts the connection as a client attached to that session;
+// Service.ListSessions leaves it out of the attached count.
+type ControlClient struct {
+	user     string
+	onChange func(notification string)
+
+	// dial opens a connection; tests replace it.
+	dial func(ctx context.Context) (*controlConn, error)
+
+	mu      sync.Mutex
+	conn    *controlConn
+	retryAt time.Time
+	closed  bool
+}
+
+// NewControlClient returns a client for user's tmux server ("" for the
+// daemon user). onChange, when set, is called from the reader goroutine
+// with the name of each change notification and must not block.
+func NewControlClient(user string, onChange func(notification string)) *ControlClient {
+	c := &ControlClient{user: strings.TrimSpace(user), onChange: onChange}
+	c.dial = c.dialTmux
+	return c
+}
+
+// Run runs one tmux command and returns its output like the tmux binary
+// would print it. Commands are answered in the order they were sent.
+func (c *ControlClient) Run(ctx context.Context, args ...string) (string, error) {
+	conn, err := c.connection(ctx)
+	if err != nil {
+		return "", err
+	}
+	_, span := traceCommand(ctx, "control", args)
+	defer span.End()
+	call, err := conn.send(args)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+	select {
+	case <-call.done:
+		span.RecordError(call.err)
+		return call.out.String(), call.err
+	case <-ctx.Done():
+		span.RecordError(ctx.Err())
+		return "", ctx.Err()
+	}
+}
+
+// Close ends the connection. Run then reports ErrControlUnavailable.
+func (c *ControlClient) Close() {
+	c.mu.Lock()
+	c.closed = true
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+	if conn != nil {
+		conn.close()
+	}
+}
+
+// attachedSession returns the session the connection is attached to, or ""
+// when it is not connected.
+func (c *ControlClient) attachedSession() string {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return ""
+	}
+	return conn.attachedSession()
+}
+
+func (c *ControlClient) connection(ctx context.Context) (*controlConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, ErrControlUnavailable
+	}
+	if c.conn != nil && c.conn.alive() {
+		return c.conn, nil
+	}
+	c.conn = nil
+	if time.Now().Before(c.retryAt) {
+		return nil, ErrControlUnavailable
+	}
+	conn, err := c.dial(ctx)
+	if err != nil {
+		c.retryAt = time.Now().Add(controlRetryDelay)
+		return nil, fmt.Errorf("%w: %v", ErrControlUnavailable, err)
+	}
+	c.conn = conn
+	return conn, nil
+}
+
+// dialTmux attaches a control client to the first session of the server.
+func (c *ControlClient) dialTmux(ctx context.Context) (*controlConn, error) {
+	out, err := runAsUser(ctx, c.user, "list-sessions", "-F", "#{session_name}")
+	if err != nil {
+		return nil, err
+	}
+	session, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	if session == "" {
+		return nil, errors.New("no session to attach to")
+	}
+	args := []string{"-C", "attach-session", "-f", "no-output,ignore-size", "-t", "=" + session}
+	name, commandArgs, err := userswitch.BuildTmuxCommand(UserSwitchMethod, c.user, args, true)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(name, commandArgs...) //nolint:gosec // name and args come from the validated user switch builder
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	conn := newControlConn(stdin, stdout, c.onChange)
+	conn.session = session
+	go func() {
+		<-conn.done
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	return conn, nil
+}
+
+// controlConn is one control-mode connection. Replies come back in the
+// order commands were written, so pending is a queue.
+type controlConn struct {
+	stdin    io.WriteCloser
+	onChange func(string)
+	done     chan struct{}
+
+	mu        sync.Mutex
+	pending   []*controlCall
+	closed    bool
+	session   string
+	sessionID string
+}
+
+type controlCall struct {
+	args []string
+	out  strings.Builder
+	err  error
+	done chan struct{}
+}
+
+func newControlConn(stdin io.WriteCloser, stdout io.Reader, onChange func(string)) *controlConn {
+	conn := &controlConn{stdin: stdin, onChange: onChange, done: make(chan struct{})}
+	go conn.read(stdout)
+	return conn
+}
+
+func (c *controlConn) send(args []string) (*controlCall, error) {
+	call := &controlCall{args: args, done: make(chan struct{})}
+	line := quoteControlArgs(args) + "\n"
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, ErrControlUnavailable
+	}
+	c.pending = append(c.pending, call)
+	if _, err := io.WriteString(c.stdin, line); err != nil {
+		c.pending = c.pending[:len(c.pending)-1]
+		return nil, fmt.Errorf("%w: %v", ErrControlUnavailable, err)
+	}
+	return call, nil
+}
+
+// read parses the control-mode stream. A reply is framed by %begin and a
+// %end or %error line carrying the same command number; a flags field of 1
+// marks replies to this client's own commands. Lines outside a reply are
+// notifications.
+func (c *controlConn) read(stdout io.Reader) {
+	defer c.close()
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxControlLineBytes)
+	var (
+		call    *controlCall
+		inReply bool
+		number  string
+	)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if inReply {
+			kind, num, _, ok := parseControlGuard(line)
+			if !ok || num != number || (kind != "end" && kind != "error") {
+				if call != nil {
+					call.out.WriteString(line)
+					call.out.WriteByte('\n')
+				}
+				continue
+			}
+			if call != nil {
+				if kind == "error" {
+					call.err = classifyError(errors.New("tmux command failed"), call.out.String(), call.args)
+					call.out.Reset()
+				}
+				close(call.done)
+			}
+			inReply, call = false, nil
+			continue
+		}
+
+		if kind, num, flags, ok := parseControlGuard(line); ok && kind == "begin" {
+			inReply, number = true, num
+			if flags == "1" {
+				call = c.popPending()
+			}
+			continue
+		}
+		c.notify(line)
+	}
+}
+
+func (c *controlConn) popPending() *controlCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) == 0 {
+		return nil
+	}
+	call := c.pending[0]
+	c.pending = c.pending[1:]
+	return call
+}
+
+func (c *controlConn) notify(line string) {
+	name, rest, _ := strings.Cut(strings.TrimPrefix(line, "%"), " ")
+	switch name {
+	case "exit":
+		c.close()
+		return
+	case "session-changed":
+		id, session, _ := strings.Cut(rest, " ")
+		c.mu.Lock()
+		c.sessionID, c.session = id, session
+		c.mu.Unlock()
+	case "session-renamed":
+		id, session, _ := strings.Cut(rest, " ")
+		c.mu.Lock()
+		if id == c.sessionID {
+			c.session = session
+		}
+		c.mu.Unlock()
+	}
+	if controlNotifications[name] && c.onChange != nil {
+		c.onChange(name)
+	}
+}
+
+func (c *controlConn) alive() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.closed
+}
+
+func (c *controlConn) attachedSession() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ""
+	}
+	return c.session
+}
+
+// close fails the commands still waiting for a reply; they may or may not
+// have run.
+func (c *controlConn) close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	_ = c.stdin.Close()
+	for _, call := range pending {
+		call.err = &Error{Kind: ErrKindCommandFailed, Msg: "tmux control connection closed"}
+		close(call.done)
+	}
+	close(c.done)
+}
+
+// parseControlGuard parses a "%begin", "%end" or "%error" line into its
+// kind, command number and flags.
+func parseControlGuard(line string) (string, string, string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 4 {
+		return "", "", "", false
+	}
+	switch fields[0] {
+	case "%begin", "%end", "%error":
+		return fields[0][1:], fields[2], fields[3], true
+	}
+	return "", "", "", false
+}
+
+// controlEscaper escapes a double-quoted tmux argument. tmux expands $ and
+// backslash escapes inside double quotes only.
+var controlEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`)
+
+// quoteControlArgs renders args as one tmux command line. Each argument is
+// single-quoted so formats, spaces and separators reach tmux unchanged;
+// arguments with line breaks, which would end the command line, are
+// double-quoted with the breaks escaped.
+func quoteControlArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, "\r\n") {
+			quoted[i] = `"` + controlEscaper.Replace(arg) + `"`
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,203 @@
+package tmux
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeControlPeer plays the tmux side of a control-mode connection. reply
+// answers each command line with its output, or an error message.
+type fakeControlPeer struct {
+	stdout *io.PipeWriter
+	mu     sync.Mutex
+	lines  []string
+}
+
+func startFakeControlPeer(t *testing.T, reply func(line string) (string, string)) (*fakeControlPeer, *controlConn, chan string) {
+	t.Helper()
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	changes := make(chan string, 16)
+	peer := &fakeControlPeer{stdout: stdoutW}
+	conn := newControlConn(stdinW, stdoutR, func(name string) { changes <- name })
+	t.Cleanup(func() {
+		_ = stdoutW.Close()
+		conn.close()
+	})
+
+	peer.write("%begin 1 1 0\n%end 1 1 0\n%session-changed $0 dev\n")
+	go func() {
+		scanner := bufio.NewScanner(stdinR)
+		for n := 2; scanner.Scan(); n++ {
+			line := scanner.Text()
+			peer.mu.Lock()
+			peer.lines = append(peer.lines, line)
+			peer.mu.Unlock()
+			out, msg := reply(line)
+			end := "end"
+			if msg != "" {
+				out, end = msg+"\n", "error"
+			}
+			peer.write(fmt.Sprintf("%%begin 1 %d 1\n%s%%%s 1 %d 1\n", n, out, end, n))
+		}
+	}()
+	return peer, conn, changes
+}
+
+func (p *fakeControlPeer) write(s string) {
+	_, _ = io.WriteString(p.stdout, s)
+}
+
+func (p *fakeControlPeer) received() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.lines...)
+}
+
+func newFakeControlClient(conn *controlConn) *ControlClient {
+	c := NewControlClient("", nil)
+	c.dial = func(context.Context) (*controlConn, error) { return conn, nil }
+	return c
+}
+
+func TestControlClientRunsQuotedCommands(t *testing.T) {
+	t.Parallel()
+
+	peer, conn, _ := startFakeControlPeer(t, func(line string) (string, string) {
+		switch line {
+		case `'list-sessions' '-F' '#{session_name}'`:
+			return "dev\nops\n", ""
+		case `'has-session' '-t' 'it'\''s'`:
+			return "", "can't find session: it's"
+		}
+		return "", "unknown command: " + line
+	})
+	client := newFakeControlClient(conn)
+	ctx := context.Background()
+
+	out, err := client.Run(ctx, "list-sessions", "-F", "#{session_name}")
+	if err != nil || out != "dev\nops\n" {
+		t.Fatalf("Run(list-sessions) = %q, %v", out, err)
+	}
+	_, err = client.Run(ctx, "has-session", "-t", "it's")
+	if !IsKind(err, ErrKindSessionNotFound) {
+		t.Fatalf("Run(has-session) error = %v, want session not found", err)
+	}
+	if got := peer.received(); len(got) != 2 {
+		t.Fatalf("peer received %q", got)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := client.Run(ctx, "list-sessions", "-F", "#{session_name}")
+			if err == nil && out != "dev\nops\n" {
+				err = fmt.Errorf("out = %q", out)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent Run: %v", err)
+		}
+	}
+}
+
+func TestQuoteControlArgs(t *testing.T) {
+	t.Parallel()
+
+	got := quoteControlArgs([]string{"set-buffer", "it's", "a\\b \"q\" $HOME\nnext\r"})
+	want := `'set-buffer' 'it'\''s' "a\\b \"q\" \$HOME\nnext\r"`
+	if got != want {
+		t.Fatalf("quoteControlArgs() = %s, want %s", got, want)
+	}
+}
+
+func TestControlClientNotificationsAndExit(t *testing.T) {
+	t.Parallel()
+
+	peer, conn, changes := startFakeControlPeer(t, func(string) (string, string) { return "", "" })
+	client := newFakeControlClient(conn)
+	if _, err := client.Run(context.Background(), "refresh-client"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := client.attachedSession(); got != "dev" {
+		t.Fatalf("attachedSession = %q, want dev", got)
+	}
+
+	peer.write("%output %1 ignored\n%window-add @3\n%session-renamed $0 prod\n")
+	for _, want := range []string{"window-add", "session-renamed"} {
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Fatalf("change = %q, want %q", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s notification", want)
+		}
+	}
+	if got := client.attachedSession(); got != "prod" {
+		t.Fatalf("attachedSession after rename = %q, want prod", got)
+	}
+
+	peer.write("%exit\n")
+	select {
+	case <-conn.done:
+	case <-time.After(time.Second):
+		t.Fatal("connection not closed on exit notification")
+	}
+	if got := client.attachedSession(); got != "" {
+		t.Fatalf("attachedSession after exit = %q", got)
+	}
+	client.dial = func(context.Context) (*controlConn, error) { return nil, errors.New("no server") }
+	if _, err := client.Run(context.Background(), "list-sessions"); !errors.Is(err, ErrControlUnavailable) {
+		t.Fatalf("Run after exit error = %v, want ErrControlUnavailable", err)
+	}
+}
+
+func TestServiceControlFallbackAndAttachedCount(t *testing.T) {
+	t.Parallel()
+
+	_, conn, _ := startFakeControlPeer(t, func(string) (string, string) {
+		return "dev\t2\t1\t1700000000\t1700000001\nops\t1\t0\t1700000000\t1700000001\n", ""
+	})
+	client := newFakeControlClient(conn)
+	sessions, err := Service{Control: client}.ListSessions(context.Background())
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].Attached != 0 || sessions[1].Attached != 0 {
+		t.Fatalf("sessions = %+v, want control client left out of dev's attached count", sessions)
+	}
+
+	var fallback []string
+	unavailable := NewControlClient("", nil)
+	unavailable.dial = func(context.Context) (*controlConn, error) { return nil, errors.New("no session") }
+	svc := Service{
+		Control: unavailable,
+		Command: func(ctx context.Context, name string, args ...string) *exec.Cmd {
+			fallback = append(fallback, strings.Join(args, " "))
+			return exec.CommandContext(ctx, "true")
+		},
+	}
+	if err := svc.SelectPane(context.Background(), "%1"); err != nil {
+		t.Fatalf("SelectPane: %v", err)
+	}
+	if len(fallback) != 1 || fallback[0] != "select-pane -t %1" {
+		t.Fatalf("fallback commands = %q", fallback)
+	}
+}
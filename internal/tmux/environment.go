@@ -0,0 +1,61 @@
+package tmux
+
+import (
+	"context"
+	"strings"
+)
+
+// EnvVar is a variable of a session environment. Removed marks a variable
+// tmux strips from the environment of new processes in the session.
+type EnvVar struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Removed bool   `json:"removed"`
+}
+
+func showEnvironmentVia(ctx context.Context, runFn runnerFunc, session string) ([]EnvVar, error) {
+	if strings.TrimSpace(session) == "" {
+		return nil, &Error{Kind: ErrKindInvalidIdentifier, Msg: "tmux session is required"}
+	}
+	out, err := runFn(ctx, "show-environment", "-t", session)
+	if err != nil {
+		return nil, err
+	}
+	return parseEnvironmentOutput(out), nil
+}
+
+func parseEnvironmentOutput(out string) []EnvVar {
+	vars := []EnvVar{}
+	for line := range strings.SplitSeq(strings.TrimRight(out, "\n"), "\n") {
+		if name, ok := strings.CutPrefix(line, "-"); ok {
+			if name != "" {
+				vars = append(vars, EnvVar{Name: name, Removed: true})
+			}
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || name == "" {
+			continue
+		}
+		vars = append(vars, EnvVar{Name: name, Value: value})
+	}
+	return vars
+}
+
+func setEnvironmentVia(ctx context.Context, runFn runnerFunc, session, name, value string) error {
+	if strings.TrimSpace(session) == "" || strings.TrimSpace(name) == "" {
+		return &Error{Kind: ErrKindInvalidIdentifier, Msg: "tmux session and variable name are required"}
+	}
+	_, err := runFn(ctx, "set-environment", "-t", session, "--", name, value)
+	return err
+}
+
+// unsetEnvironmentVia drops the session's own value, so new processes get
+// the global one again.
+func unsetEnvironmentVia(ctx context.Context, runFn runnerFunc, session, name string) error {
+	if strings.TrimSpace(session) == "" || strings.TrimSpace(name) == "" {
+		return &Error{Kind: ErrKindInvalidIdentifier, Msg: "tmux session and variable name are required"}
+	}
+	_, err := runFn(ctx, "set-environment", "-t", session, "-u", "--", name)
+	return err
+}
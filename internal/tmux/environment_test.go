@@ -0,0 +1,54 @@
+package tmux
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestParseEnvironmentOutput(t *testing.T) {
+	t.Parallel()
+
+	got := parseEnvironmentOutput("-DISPLAY\nAPI_KEY=a=b c\nEMPTY=\n\nnot a var\n")
+	want := []EnvVar{
+		{Name: "DISPLAY", Removed: true},
+		{Name: "API_KEY", Value: "a=b c"},
+		{Name: "EMPTY"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseEnvironmentOutput() = %+v, want %+v", got, want)
+	}
+}
+
+func TestEnvironmentCommandsVia(t *testing.T) {
+	t.Parallel()
+
+	var calls [][]string
+	runFn := func(_ context.Context, args ...string) (string, error) {
+		calls = append(calls, slices.Clone(args))
+		return "FOO=bar\n", nil
+	}
+	ctx := context.Background()
+	vars, err := showEnvironmentVia(ctx, runFn, "dev")
+	if err != nil || len(vars) != 1 || vars[0].Value != "bar" {
+		t.Fatalf("showEnvironmentVia() = %+v, %v", vars, err)
+	}
+	if err := setEnvironmentVia(ctx, runFn, "dev", "API_KEY", "-secret"); err != nil {
+		t.Fatalf("setEnvironmentVia() error = %v", err)
+	}
+	if err := unsetEnvironmentVia(ctx, runFn, "dev", "API_KEY"); err != nil {
+		t.Fatalf("unsetEnvironmentVia() error = %v", err)
+	}
+	want := [][]string{
+		{"show-environment", "-t", "dev"},
+		{"set-environment", "-t", "dev", "--", "API_KEY", "-secret"},
+		{"set-environment", "-t", "dev", "-u", "--", "API_KEY"},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+	if err := setEnvironmentVia(ctx, runFn, "dev", " ", "x"); !IsKind(err, ErrKindInvalidIdentifier) {
+		t.Fatalf("setEnvironmentVia(empty name) error = %v, want ErrKindInvalidIdentifier", err)
+	}
+}
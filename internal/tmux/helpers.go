@@ -79,7 +79,7 @@ func listWindowsVia(ctx context.Context, runFn runnerFunc, session string) ([]Wi
 }
 
 func listPanesVia(ctx context.Context, runFn runnerFunc, session string) ([]Pane, error) {
-	out, err := runFn(ctx, "list-panes", "-a", "-F", "#{session_name}\t#{window_index}\t#{pane_index}\t#{pane_id}\t#{pane_title}\t#{pane_active}\t#{pane_tty}\t#{pane_current_path}\t#{pane_start_command}\t#{pane_current_command}\t#{pane_left}\t#{pane_top}\t#{pane_width}\t#{pane_height}")
+	out, err := runFn(ctx, "list-panes", "-a", "-F", paneListFormat)
 	if err != nil {
 		return nil, err
 	}
@@ -201,6 +201,43 @@ func reorderWindowsVia(ctx context.Context, runFn runnerFunc, session string, or
 	return nil
 }
 
+func moveWindowVia(ctx context.Context, runFn runnerFunc, session string, index int, targetSession string) error {
+	source := fmt.Sprintf("%s:%d", session, index)
+	_, err := runFn(ctx, "move-window", "-s", source, "-t", targetSession+":")
+	return err
+}
+
+func zoomPaneVia(ctx context.Context, runFn runnerFunc, paneID string) error {
+	_, err := runFn(ctx, "resize-pane", "-Z", "-t", paneID)
+	return err
+}
+
+func panePIDVia(ctx context.Context, runFn runnerFunc, paneID string) (int, error) {
+	out, err := runFn(ctx, "display-message", "-p", "-t", paneID, "#{pane_pid}")
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil || pid <= 0 {
+		return 0, &Error{Kind: ErrKindCommandFailed, Msg: "invalid pane pid", Err: err}
+	}
+	return pid, nil
+}
+
+func swapPaneVia(ctx context.Context, runFn runnerFunc, paneID, targetPaneID string) error {
+	_, err := runFn(ctx, "swap-pane", "-s", paneID, "-t", targetPaneID)
+	return err
+}
+
+func rotateWindowVia(ctx context.Context, runFn runnerFunc, session string, index int, direction string) error {
+	args, err := rotateWindowArgs(session, index, direction)
+	if err != nil {
+		return err
+	}
+	_, err = runFn(ctx, args...)
+	return err
+}
+
 func splitPaneVia(ctx context.Context, runFn runnerFunc, paneID, direction string) (string, error) {
 	args := []string{cmdSplitWindow, "-t", paneID}
 	switch direction {
@@ -349,6 +386,10 @@ func parseWindowListOutput(out string) []Window {
 	return windows
 }
 
+// paneListFormat is the list-panes format parsed by parsePaneListOutput.
+// Zoom is a window flag, so only the window's active pane reports it.
+const paneListFormat = "#{session_name}\t#{window_index}\t#{pane_index}\t#{pane_id}\t#{pane_title}\t#{pane_active}\t#{pane_tty}\t#{pane_current_path}\t#{pane_start_command}\t#{pane_current_command}\t#{pane_left}\t#{pane_top}\t#{pane_width}\t#{pane_height}\t#{?pane_active,#{window_zoomed_flag},0}\t#{pane_pid}\t#{pane_dead}\t#{pane_dead_status}\t#{pane_dead_signal}"
+
 // parsePaneListOutput parses list-panes output filtered by session.
 func parsePaneListOutput(out string, session string) []Pane {
 	if strings.TrimSpace(out) == "" {
@@ -370,6 +411,9 @@ func parsePaneListOutput(out string, session string) []Pane {
 		top, _ := strconv.Atoi(valueAt(parts, 11))
 		width, _ := strconv.Atoi(valueAt(parts, 12))
 		height, _ := strconv.Atoi(valueAt(parts, 13))
+		pid, _ := strconv.Atoi(valueAt(parts, 15))
+		deadStatus, _ := strconv.Atoi(valueAt(parts, 17))
+		deadSignal, _ := strconv.Atoi(valueAt(parts, 18))
 		panes = append(panes, Pane{
 			Session:        parts[0],
 			WindowIndex:    windowIndex,
@@ -377,6 +421,11 @@ func parsePaneListOutput(out string, session string) []Pane {
 			PaneID:         parts[3],
 			Title:          parts[4],
 			Active:         parts[5] == "1",
+			Zoomed:         valueAt(parts, 14) == "1",
+			PID:            pid,
+			Dead:           valueAt(parts, 16) == "1",
+			DeadStatus:     deadStatus,
+			DeadSignal:     deadSignal,
 			TTY:            parts[6],
 			CurrentPath:    valueAt(parts, 7),
 			StartCommand:   valueAt(parts, 8),
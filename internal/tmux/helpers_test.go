@@ -30,13 +30,18 @@ func TestParseWindowAndPaneListOutput(t *testing.T) {
 		t.Fatalf("window = %+v, want parsed @1 window", windows[0])
 	}
 
-	panes := parsePaneListOutput("dev\t0\t1\t%2\tlogs\t1\t/dev/pts/2\t/tmp\tbash\tvim\t10\t20\t80\t24\nother\t0\t0\t%9\tx\t0\t/dev/null\n", "dev")
-	if len(panes) != 1 {
-		t.Fatalf("panes len = %d, want 1", len(panes))
+	panes := parsePaneListOutput("dev\t0\t1\t%2\tlogs\t1\t/dev/pts/2\t/tmp\tbash\tvim\t10\t20\t80\t24\t1\t4242\t0\t\t\n"+
+		"dev\t0\t2\t%3\tjob\t0\t/dev/pts/3\t/tmp\tmake\tmake\t0\t0\t80\t24\t0\t4243\t1\t2\t\n"+
+		"other\t0\t0\t%9\tx\t0\t/dev/null\n", "dev")
+	if len(panes) != 2 {
+		t.Fatalf("panes len = %d, want 2", len(panes))
 	}
-	if panes[0].PaneID != "%2" || panes[0].CurrentPath != "/tmp" || panes[0].Left != 10 || panes[0].Height != 24 {
+	if panes[0].PaneID != "%2" || panes[0].CurrentPath != "/tmp" || panes[0].Left != 10 || panes[0].Height != 24 || !panes[0].Zoomed || panes[0].PID != 4242 || panes[0].Dead {
 		t.Fatalf("pane = %+v, want parsed pane", panes[0])
 	}
+	if !panes[1].Dead || panes[1].DeadStatus != 2 || panes[1].DeadSignal != 0 {
+		t.Fatalf("pane = %+v, want dead pane with status 2", panes[1])
+	}
 }
 
 func TestSendKeysVia(t *testing.T) {
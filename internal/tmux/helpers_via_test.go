@@ -450,3 +450,92 @@ func TestReorderWindowsVia(t *testing.T) {
 		}
 	})
 }
+
+func TestLayoutMoveVia(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		call func(runnerFunc) error
+		want []string
+	}{
+		{
+			name: "move window",
+			call: func(runFn runnerFunc) error { return moveWindowVia(context.Background(), runFn, "dev", 2, "ops") },
+			want: []string{"move-window", "-s", "dev:2", "-t", "ops:"},
+		},
+		{
+			name: "zoom pane",
+			call: func(runFn runnerFunc) error { return zoomPaneVia(context.Background(), runFn, "%3") },
+			want: []string{"resize-pane", "-Z", "-t", "%3"},
+		},
+		{
+			name: "swap pane",
+			call: func(runFn runnerFunc) error { return swapPaneVia(context.Background(), runFn, "%1", "%4") },
+			want: []string{"swap-pane", "-s", "%1", "-t", "%4"},
+		},
+		{
+			name: "rotate up",
+			call: func(runFn runnerFunc) error { return rotateWindowVia(context.Background(), runFn, "dev", 1, rotateUp) },
+			want: []string{"rotate-window", "-t", "dev:1", "-U"},
+		},
+		{
+			name: "rotate down",
+			call: func(runFn runnerFunc) error {
+				return rotateWindowVia(context.Background(), runFn, "dev", 1, rotateDown)
+			},
+			want: []string{"rotate-window", "-t", "dev:1", "-D"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotArgs []string
+			runFn := func(_ context.Context, args ...string) (string, error) {
+				gotArgs = slices.Clone(args)
+				return "", nil
+			}
+			if err := tt.call(runFn); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if !slices.Equal(gotArgs, tt.want) {
+				t.Fatalf("args = %#v, want %#v", gotArgs, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid rotate direction", func(t *testing.T) {
+		t.Parallel()
+
+		runFn := func(_ context.Context, _ ...string) (string, error) {
+			t.Fatal("run should not be called for invalid direction")
+			return "", nil
+		}
+		if err := rotateWindowVia(context.Background(), runFn, "dev", 1, "sideways"); !IsKind(err, ErrKindInvalidIdentifier) {
+			t.Fatalf("error = %v, want ErrKindInvalidIdentifier", err)
+		}
+	})
+}
+
+func TestPanePIDVia(t *testing.T) {
+	t.Parallel()
+
+	var gotArgs []string
+	runFn := func(_ context.Context, args ...string) (string, error) {
+		gotArgs = slices.Clone(args)
+		return "4242\n", nil
+	}
+	pid, err := panePIDVia(context.Background(), runFn, "%3")
+	if err != nil || pid != 4242 {
+		t.Fatalf("panePIDVia = %d, %v; want 4242", pid, err)
+	}
+	if want := []string{"display-message", "-p", "-t", "%3", "#{pane_pid}"}; !slices.Equal(gotArgs, want) {
+		t.Fatalf("args = %#v, want %#v", gotArgs, want)
+	}
+
+	badRun := func(context.Context, ...string) (string, error) { return "", nil }
+	if _, err := panePIDVia(context.Background(), badRun, "%3"); !IsKind(err, ErrKindCommandFailed) {
+		t.Fatalf("error = %v, want ErrKindCommandFailed", err)
+	}
+}
@@ -3,12 +3,14 @@ package tmux
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"regexp"
 	"runtime"
 	"strings"
 
+	"github.com/opus-domini/sentinel/internal/tracing"
 	"github.com/opus-domini/sentinel/internal/userswitch"
 )
 
@@ -16,9 +18,37 @@ import (
 // non-empty, commands are wrapped according to UserSwitchMethod.
 type Service struct {
 	User string
+
+	// Command, when set, builds the tmux command instead of the local
+	// binary, e.g. an ssh command for a remote host. User is then ignored.
+	Command func(ctx context.Context, name string, args ...string) *exec.Cmd
+
+	// Control, when set, runs commands over its control-mode connection
+	// and falls back to User's tmux binary while it cannot connect.
+	Control *ControlClient
+}
+
+// local reports whether the service runs the package-level functions
+// against the daemon user's own tmux server.
+func (s Service) local() bool {
+	return s.User == "" && s.Command == nil && s.Control == nil
 }
 
 func (s Service) run(ctx context.Context, args ...string) (string, error) {
+	if s.Control != nil {
+		out, err := s.Control.Run(ctx, args...)
+		if !errors.Is(err, ErrControlUnavailable) {
+			return out, err
+		}
+	}
+	return s.runProcess(ctx, args...)
+}
+
+// runProcess runs a command in its own tmux process, bypassing Control.
+func (s Service) runProcess(ctx context.Context, args ...string) (string, error) {
+	if s.Command != nil {
+		return runCommand(ctx, s.Command(ctx, "tmux", args...), args)
+	}
 	return runAsUser(ctx, s.User, args...)
 }
 
@@ -87,19 +117,39 @@ func runAsUser(ctx context.Context, user string, args ...string) (string, error)
 	if err != nil {
 		return "", &Error{Kind: ErrKindCommandFailed, Msg: err.Error()}
 	}
-	cmd := execCommandContext(ctx, name, commandArgs...)
+	return runCommand(ctx, execCommandContext(ctx, name, commandArgs...), args)
+}
+
+// runCommand runs cmd and classifies a failure by its stderr as if tmuxArgs
+// had been run directly.
+func runCommand(ctx context.Context, cmd *exec.Cmd, tmuxArgs []string) (string, error) {
+	_, span := traceCommand(ctx, "exec", tmuxArgs)
+	defer span.End()
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		return "", classifyError(err, stderr.String(), args)
+		err = classifyError(err, stderr.String(), tmuxArgs)
+		span.RecordError(err)
+		return "", err
 	}
 	return stdout.String(), nil
 }
 
+// traceCommand starts a span for one tmux command within the caller's
+// trace. It is named after the subcommand alone: arguments can hold typed
+// keys or pane content.
+func traceCommand(ctx context.Context, transport string, args []string) (context.Context, *tracing.Span) {
+	name := "tmux"
+	if len(args) > 0 {
+		name += " " + args[0]
+	}
+	return tracing.StartChild(ctx, name, tracing.KindClient, tracing.String("tmux.transport", transport))
+}
+
 // ListSessions lists sessions.
 func (s Service) ListSessions(ctx context.Context) ([]Session, error) {
-	if s.User == "" {
+	if s.local() {
 		return ListSessions(ctx)
 	}
 	out, err := s.run(ctx, "list-sessions", "-F", listSessionsFormatWithActivity)
@@ -118,12 +168,26 @@ func (s Service) ListSessions(ctx context.Context) ([]Session, error) {
 			return nil, err
 		}
 	}
-	return parseSessionListOutput(out), nil
+	sessions := parseSessionListOutput(out)
+	if s.Control != nil {
+		discountControlClient(sessions, s.Control.attachedSession())
+	}
+	return sessions, nil
+}
+
+// discountControlClient removes the control connection from the attached
+// count of the session it sits on.
+func discountControlClient(sessions []Session, attachedTo string) {
+	for i := range sessions {
+		if sessions[i].Name == attachedTo && sessions[i].Attached > 0 {
+			sessions[i].Attached--
+		}
+	}
 }
 
 // ListActivePaneCommands lists active pane commands.
 func (s Service) ListActivePaneCommands(ctx context.Context) (map[string]PaneSnapshot, error) {
-	if s.User == "" {
+	if s.local() {
 		return ListActivePaneCommands(ctx)
 	}
 	out, err := s.run(ctx, "list-panes", "-a", "-F", "#{session_name}\t#{window_active}\t#{pane_active}\t#{pane_start_command}\t#{pane_current_command}")
@@ -138,7 +202,7 @@ func (s Service) ListActivePaneCommands(ctx context.Context) (map[string]PaneSna
 
 // CapturePane captures pane.
 func (s Service) CapturePane(ctx context.Context, session string) (string, error) {
-	if s.User == "" {
+	if s.local() {
 		return CapturePane(ctx, session)
 	}
 	return capturePane(ctx, s.run, session)
@@ -146,7 +210,7 @@ func (s Service) CapturePane(ctx context.Context, session string) (string, error
 
 // HasSession reports whether session.
 func (s Service) HasSession(ctx context.Context, session string) bool {
-	if s.User == "" {
+	if s.local() {
 		return HasSession(ctx, session)
 	}
 	_, err := s.run(ctx, "has-session", "-t", session)
@@ -155,7 +219,7 @@ func (s Service) HasSession(ctx context.Context, session string) bool {
 
 // CreateSession creates session.
 func (s Service) CreateSession(ctx context.Context, name, cwd string) error {
-	if s.User == "" {
+	if s.local() {
 		return CreateSession(ctx, name, cwd)
 	}
 	args := []string{"new-session", "-d", "-s", name}
@@ -168,7 +232,7 @@ func (s Service) CreateSession(ctx context.Context, name, cwd string) error {
 
 // RenameSession renames session.
 func (s Service) RenameSession(ctx context.Context, session, newName string) error {
-	if s.User == "" {
+	if s.local() {
 		return RenameSession(ctx, session, newName)
 	}
 	_, err := s.run(ctx, "rename-session", "-t", session, newName)
@@ -177,7 +241,7 @@ func (s Service) RenameSession(ctx context.Context, session, newName string) err
 
 // RenameWindow renames window.
 func (s Service) RenameWindow(ctx context.Context, session string, index int, name string) error {
-	if s.User == "" {
+	if s.local() {
 		return RenameWindow(ctx, session, index, name)
 	}
 	return renameWindowVia(ctx, s.run, session, index, name)
@@ -185,7 +249,7 @@ func (s Service) RenameWindow(ctx context.Context, session string, index int, na
 
 // RenamePane renames pane.
 func (s Service) RenamePane(ctx context.Context, paneID, title string) error {
-	if s.User == "" {
+	if s.local() {
 		return RenamePane(ctx, paneID, title)
 	}
 	_, err := s.run(ctx, "select-pane", "-t", paneID, "-T", title)
@@ -194,7 +258,7 @@ func (s Service) RenamePane(ctx context.Context, paneID, title string) error {
 
 // KillSession handles kill session.
 func (s Service) KillSession(ctx context.Context, session string) error {
-	if s.User == "" {
+	if s.local() {
 		return KillSession(ctx, session)
 	}
 	_, err := s.run(ctx, "kill-session", "-t", session)
@@ -203,7 +267,7 @@ func (s Service) KillSession(ctx context.Context, session string) error {
 
 // ListWindows lists windows.
 func (s Service) ListWindows(ctx context.Context, session string) ([]Window, error) {
-	if s.User == "" {
+	if s.local() {
 		return ListWindows(ctx, session)
 	}
 	return listWindowsVia(ctx, s.run, session)
@@ -211,7 +275,7 @@ func (s Service) ListWindows(ctx context.Context, session string) ([]Window, err
 
 // ListPanes lists panes.
 func (s Service) ListPanes(ctx context.Context, session string) ([]Pane, error) {
-	if s.User == "" {
+	if s.local() {
 		return ListPanes(ctx, session)
 	}
 	return listPanesVia(ctx, s.run, session)
@@ -219,7 +283,7 @@ func (s Service) ListPanes(ctx context.Context, session string) ([]Pane, error)
 
 // ReorderWindows reorders windows.
 func (s Service) ReorderWindows(ctx context.Context, session string, orderedWindowIDs []string) error {
-	if s.User == "" {
+	if s.local() {
 		return ReorderWindows(ctx, session, orderedWindowIDs)
 	}
 	return reorderWindowsVia(ctx, s.run, session, orderedWindowIDs)
@@ -227,7 +291,7 @@ func (s Service) ReorderWindows(ctx context.Context, session string, orderedWind
 
 // SelectWindow selects window.
 func (s Service) SelectWindow(ctx context.Context, session string, index int) error {
-	if s.User == "" {
+	if s.local() {
 		return SelectWindow(ctx, session, index)
 	}
 	return selectWindowVia(ctx, s.run, session, index)
@@ -235,7 +299,7 @@ func (s Service) SelectWindow(ctx context.Context, session string, index int) er
 
 // SelectPane selects pane.
 func (s Service) SelectPane(ctx context.Context, paneID string) error {
-	if s.User == "" {
+	if s.local() {
 		return SelectPane(ctx, paneID)
 	}
 	_, err := s.run(ctx, "select-pane", "-t", paneID)
@@ -249,7 +313,7 @@ func (s Service) NewWindow(ctx context.Context, session string) (NewWindowResult
 
 // NewWindowWithOptions creates window with options.
 func (s Service) NewWindowWithOptions(ctx context.Context, session, name, cwd string) (NewWindowResult, error) {
-	if s.User == "" {
+	if s.local() {
 		return NewWindowWithOptions(ctx, session, name, cwd)
 	}
 	return newWindowWithOptionsVia(ctx, s.run, session, name, cwd)
@@ -257,7 +321,7 @@ func (s Service) NewWindowWithOptions(ctx context.Context, session, name, cwd st
 
 // NewWindowAt creates window at.
 func (s Service) NewWindowAt(ctx context.Context, session string, index int, name, cwd string) error {
-	if s.User == "" {
+	if s.local() {
 		return NewWindowAt(ctx, session, index, name, cwd)
 	}
 	return newWindowAtVia(ctx, s.run, session, index, name, cwd)
@@ -265,7 +329,7 @@ func (s Service) NewWindowAt(ctx context.Context, session string, index int, nam
 
 // KillWindow handles kill window.
 func (s Service) KillWindow(ctx context.Context, session string, index int) error {
-	if s.User == "" {
+	if s.local() {
 		return KillWindow(ctx, session, index)
 	}
 	return killWindowVia(ctx, s.run, session, index)
@@ -273,16 +337,53 @@ func (s Service) KillWindow(ctx context.Context, session string, index int) erro
 
 // KillPane handles kill pane.
 func (s Service) KillPane(ctx context.Context, paneID string) error {
-	if s.User == "" {
+	if s.local() {
 		return KillPane(ctx, paneID)
 	}
 	_, err := s.run(ctx, "kill-pane", "-t", paneID)
 	return err
 }
 
+// MoveWindow moves window.
+func (s Service) MoveWindow(ctx context.Context, session string, index int, targetSession string) error {
+	if s.local() {
+		return MoveWindow(ctx, session, index, targetSession)
+	}
+	return moveWindowVia(ctx, s.run, session, index, targetSession)
+}
+
+// ZoomPane toggles pane zoom.
+func (s Service) ZoomPane(ctx context.Context, paneID string) error {
+	if s.local() {
+		return ZoomPane(ctx, paneID)
+	}
+	return zoomPaneVia(ctx, s.run, paneID)
+}
+
+// PanePID returns the process ID of the program started in a pane.
+func (s Service) PanePID(ctx context.Context, paneID string) (int, error) {
+	return panePIDVia(ctx, s.run, paneID)
+}
+
+// SwapPane swaps pane.
+func (s Service) SwapPane(ctx context.Context, paneID, targetPaneID string) error {
+	if s.local() {
+		return SwapPane(ctx, paneID, targetPaneID)
+	}
+	return swapPaneVia(ctx, s.run, paneID, targetPaneID)
+}
+
+// RotateWindow rotates window.
+func (s Service) RotateWindow(ctx context.Context, session string, index int, direction string) error {
+	if s.local() {
+		return RotateWindow(ctx, session, index, direction)
+	}
+	return rotateWindowVia(ctx, s.run, session, index, direction)
+}
+
 // SplitPane splits pane.
 func (s Service) SplitPane(ctx context.Context, paneID, direction string) (string, error) {
-	if s.User == "" {
+	if s.local() {
 		return SplitPane(ctx, paneID, direction)
 	}
 	return splitPaneVia(ctx, s.run, paneID, direction)
@@ -290,7 +391,7 @@ func (s Service) SplitPane(ctx context.Context, paneID, direction string) (strin
 
 // SessionExists handles session exists.
 func (s Service) SessionExists(ctx context.Context, session string) (bool, error) {
-	if s.User == "" {
+	if s.local() {
 		return SessionExists(ctx, session)
 	}
 	_, err := s.run(ctx, "has-session", "-t", session)
@@ -305,7 +406,7 @@ func (s Service) SessionExists(ctx context.Context, session string) (bool, error
 
 // SplitPaneIn splits pane in.
 func (s Service) SplitPaneIn(ctx context.Context, paneID, direction, cwd string) (string, error) {
-	if s.User == "" {
+	if s.local() {
 		return SplitPaneIn(ctx, paneID, direction, cwd)
 	}
 	return splitPaneInVia(ctx, s.run, paneID, direction, cwd)
@@ -313,7 +414,7 @@ func (s Service) SplitPaneIn(ctx context.Context, paneID, direction, cwd string)
 
 // SelectLayout selects layout.
 func (s Service) SelectLayout(ctx context.Context, session string, index int, layout string) error {
-	if s.User == "" {
+	if s.local() {
 		return SelectLayout(ctx, session, index, layout)
 	}
 	return selectLayoutVia(ctx, s.run, session, index, layout)
@@ -321,7 +422,7 @@ func (s Service) SelectLayout(ctx context.Context, session string, index int, la
 
 // SendKeys sends keys.
 func (s Service) SendKeys(ctx context.Context, paneID, keys string, enter bool) error {
-	if s.User == "" {
+	if s.local() {
 		return SendKeys(ctx, paneID, keys, enter)
 	}
 	return sendKeysVia(ctx, s.run, paneID, keys, enter)
@@ -332,6 +433,12 @@ func (s Service) SendText(ctx context.Context, paneID, text string) error {
 	return sendTextVia(ctx, s.run, paneID, text)
 }
 
+// CapturePaneView captures the visible pane with color escapes and its
+// cursor position.
+func (s Service) CapturePaneView(ctx context.Context, paneID string) (PaneView, error) {
+	return capturePaneViewVia(ctx, s.run, paneID)
+}
+
 // SendKey sends one named tmux key such as Enter or C-c.
 func (s Service) SendKey(ctx context.Context, paneID, key string) error {
 	return sendKeyVia(ctx, s.run, paneID, key)
@@ -342,9 +449,50 @@ func (s Service) CapturePaneScreen(ctx context.Context, paneID string) (string,
 	return capturePaneScreenVia(ctx, s.run, paneID)
 }
 
+// CapturePaneRange captures a range of pane scrollback.
+func (s Service) CapturePaneRange(ctx context.Context, paneID string, opts CaptureRangeOptions) (PaneCapture, error) {
+	return capturePaneRangeVia(ctx, s.run, paneID, opts)
+}
+
+// ListBuffers lists the paste buffers of the tmux server.
+func (s Service) ListBuffers(ctx context.Context) ([]Buffer, error) {
+	return listBuffersVia(ctx, s.run)
+}
+
+// ShowBuffer returns the content of a paste buffer. tmux escapes buffer
+// content sent to control clients, so it never goes through Control.
+func (s Service) ShowBuffer(ctx context.Context, name string) (string, error) {
+	return showBufferVia(ctx, s.runProcess, name)
+}
+
+// SetBuffer creates or replaces a paste buffer.
+func (s Service) SetBuffer(ctx context.Context, name, content string) error {
+	return setBufferVia(ctx, s.run, name, content)
+}
+
+// PasteBuffer pastes a paste buffer into a pane.
+func (s Service) PasteBuffer(ctx context.Context, name, paneID string) error {
+	return pasteBufferVia(ctx, s.run, name, paneID)
+}
+
+// ShowEnvironment lists the session environment.
+func (s Service) ShowEnvironment(ctx context.Context, session string) ([]EnvVar, error) {
+	return showEnvironmentVia(ctx, s.run, session)
+}
+
+// SetEnvironment sets a session environment variable for new processes.
+func (s Service) SetEnvironment(ctx context.Context, session, name, value string) error {
+	return setEnvironmentVia(ctx, s.run, session, name, value)
+}
+
+// UnsetEnvironment removes a session environment variable.
+func (s Service) UnsetEnvironment(ctx context.Context, session, name string) error {
+	return unsetEnvironmentVia(ctx, s.run, session, name)
+}
+
 // CapturePaneLines captures pane lines.
 func (s Service) CapturePaneLines(ctx context.Context, target string, lines int) (string, error) {
-	if s.User == "" {
+	if s.local() {
 		return CapturePaneLines(ctx, target, lines)
 	}
 	return capturePaneLinesVia(ctx, s.run, target, lines)
@@ -352,7 +500,7 @@ func (s Service) CapturePaneLines(ctx context.Context, target string, lines int)
 
 // SetSessionMouse sets session mouse.
 func (s Service) SetSessionMouse(ctx context.Context, session string, enabled bool) error {
-	if s.User == "" {
+	if s.local() {
 		return SetSessionMouse(ctx, session, enabled)
 	}
 	return setSessionOptionVia(ctx, s.run, session, "mouse", enabled)
@@ -360,7 +508,7 @@ func (s Service) SetSessionMouse(ctx context.Context, session string, enabled bo
 
 // SetSessionStatus sets session status.
 func (s Service) SetSessionStatus(ctx context.Context, session string, enabled bool) error {
-	if s.User == "" {
+	if s.local() {
 		return SetSessionStatus(ctx, session, enabled)
 	}
 	return setSessionOptionVia(ctx, s.run, session, "status", enabled)
@@ -368,7 +516,7 @@ func (s Service) SetSessionStatus(ctx context.Context, session string, enabled b
 
 // EnsureWebMouseBindings ensures web mouse bindings.
 func (s Service) EnsureWebMouseBindings(ctx context.Context) error {
-	if s.User == "" {
+	if s.local() {
 		return EnsureWebMouseBindings(ctx)
 	}
 	// Best-effort for multi-user: apply global bindings via the user's server.
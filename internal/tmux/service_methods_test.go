@@ -36,6 +36,11 @@ func serviceMethodCalls() []serviceMethodCall {
 		{"NewWindowAt", func(ctx context.Context, s Service) error { return s.NewWindowAt(ctx, "dev", 2, "w", "/tmp") }},
 		{"KillWindow", func(ctx context.Context, s Service) error { return s.KillWindow(ctx, "dev", 1) }},
 		{"KillPane", func(ctx context.Context, s Service) error { return s.KillPane(ctx, "%1") }},
+		{"MoveWindow", func(ctx context.Context, s Service) error { return s.MoveWindow(ctx, "dev", 1, "ops") }},
+		{"ZoomPane", func(ctx context.Context, s Service) error { return s.ZoomPane(ctx, "%1") }},
+		{"PanePID", func(ctx context.Context, s Service) error { _, e := s.PanePID(ctx, "%1"); return e }},
+		{"SwapPane", func(ctx context.Context, s Service) error { return s.SwapPane(ctx, "%1", "%2") }},
+		{"RotateWindow", func(ctx context.Context, s Service) error { return s.RotateWindow(ctx, "dev", 1, rotateUp) }},
 		{"SplitPane", func(ctx context.Context, s Service) error { _, e := s.SplitPane(ctx, "%1", dirVertical); return e }},
 		{"SplitPaneIn", func(ctx context.Context, s Service) error {
 			_, e := s.SplitPaneIn(ctx, "%1", dirVertical, "/tmp")
@@ -33,6 +33,31 @@ func TestServiceDelegatesToPackageLevelRunWhenNoUser(t *testing.T) {
 	}
 }
 
+func TestServiceRunsThroughCommand(t *testing.T) {
+	t.Parallel()
+
+	var gotName string
+	var gotArgs []string
+	svc := Service{
+		User: "ignored",
+		Command: func(ctx context.Context, name string, args ...string) *exec.Cmd {
+			gotName, gotArgs = name, args
+			return exec.CommandContext(ctx, "sh", "-c", "echo \"can't find session: $1\" >&2; exit 1", "sh", args[len(args)-1])
+		},
+	}
+	if svc.local() {
+		t.Fatal("Service with Command reports local")
+	}
+
+	err := svc.KillSession(context.Background(), "dev")
+	if gotName != "tmux" || !slices.Equal(gotArgs, []string{"kill-session", "-t", "dev"}) {
+		t.Fatalf("command = %s %v", gotName, gotArgs)
+	}
+	if !IsKind(err, ErrKindSessionNotFound) {
+		t.Fatalf("KillSession() error = %v, want %s", err, ErrKindSessionNotFound)
+	}
+}
+
 func TestRunAsUserWrapsWithSudo(t *testing.T) {
 	// Not parallel: mutates package-level execCommandContext, UserSwitchMethod and SystemUsers.
 
@@ -29,6 +29,8 @@ const (
 	ErrKindCommandFailed ErrorKind = "TMUX_COMMAND_FAILED"
 	// ErrKindInvalidIdentifier reports that a tmux identifier is invalid.
 	ErrKindInvalidIdentifier ErrorKind = "INVALID_IDENTIFIER"
+	// ErrKindBufferNotFound reports that a tmux paste buffer does not exist.
+	ErrKindBufferNotFound ErrorKind = "BUFFER_NOT_FOUND"
 )
 
 // Error represents error data.
@@ -97,6 +99,8 @@ type Pane struct {
 	PaneID         string `json:"paneId"`
 	Title          string `json:"title"`
 	Active         bool   `json:"active"`
+	Zoomed         bool   `json:"zoomed,omitempty"`
+	PID            int    `json:"pid,omitempty"`
 	TTY            string `json:"tty"`
 	CurrentPath    string `json:"currentPath,omitempty"`
 	StartCommand   string `json:"startCommand,omitempty"`
@@ -105,6 +109,12 @@ type Pane struct {
 	Top            int    `json:"top,omitempty"`
 	Width          int    `json:"width,omitempty"`
 	Height         int    `json:"height,omitempty"`
+	// Dead is set when the pane's process exited and remain-on-exit kept
+	// the pane. DeadStatus is its exit status, DeadSignal the signal that
+	// killed it.
+	Dead       bool `json:"dead,omitempty"`
+	DeadStatus int  `json:"deadStatus,omitempty"`
+	DeadSignal int  `json:"deadSignal,omitempty"`
 }
 
 // NewWindowResult represents new window result data.
@@ -290,6 +300,9 @@ const (
 
 	dirVertical   = "vertical"
 	dirHorizontal = "horizontal"
+
+	rotateUp   = "up"
+	rotateDown = "down"
 )
 
 const (
@@ -301,6 +314,7 @@ const (
 const (
 	errWindowOrderMismatch = "tmux window order does not match live windows"
 	errInvalidSplitDir     = "invalid split direction"
+	errInvalidRotateDir    = "invalid rotate direction"
 	errPaneIDRequired      = "pane ID is required"
 )
 
@@ -611,6 +625,47 @@ func KillPane(ctx context.Context, paneID string) error {
 	return err
 }
 
+// MoveWindow moves a window to the next free index of another session.
+func MoveWindow(ctx context.Context, session string, index int, targetSession string) error {
+	source := fmt.Sprintf("%s:%d", session, index)
+	_, err := run(ctx, "move-window", "-s", source, "-t", targetSession+":")
+	return err
+}
+
+// ZoomPane toggles the zoom of a pane within its window.
+func ZoomPane(ctx context.Context, paneID string) error {
+	_, err := run(ctx, "resize-pane", "-Z", "-t", paneID)
+	return err
+}
+
+// SwapPane swaps the positions of two panes.
+func SwapPane(ctx context.Context, paneID, targetPaneID string) error {
+	_, err := run(ctx, "swap-pane", "-s", paneID, "-t", targetPaneID)
+	return err
+}
+
+// RotateWindow rotates the panes of a window up or down.
+func RotateWindow(ctx context.Context, session string, index int, direction string) error {
+	args, err := rotateWindowArgs(session, index, direction)
+	if err != nil {
+		return err
+	}
+	_, err = run(ctx, args...)
+	return err
+}
+
+func rotateWindowArgs(session string, index int, direction string) ([]string, error) {
+	args := []string{"rotate-window", "-t", fmt.Sprintf("%s:%d", session, index)}
+	switch direction {
+	case rotateUp:
+		return append(args, "-U"), nil
+	case rotateDown:
+		return append(args, "-D"), nil
+	default:
+		return nil, &Error{Kind: ErrKindInvalidIdentifier, Msg: errInvalidRotateDir}
+	}
+}
+
 // SplitPane splits pane.
 func SplitPane(ctx context.Context, paneID, direction string) (string, error) {
 	args := []string{cmdSplitWindow, "-t", paneID}
@@ -716,47 +771,11 @@ func ListWindows(ctx context.Context, session string) ([]Window, error) {
 
 // ListPanes lists panes.
 func ListPanes(ctx context.Context, session string) ([]Pane, error) {
-	out, err := run(ctx, "list-panes", "-a", "-F", "#{session_name}\t#{window_index}\t#{pane_index}\t#{pane_id}\t#{pane_title}\t#{pane_active}\t#{pane_tty}\t#{pane_current_path}\t#{pane_start_command}\t#{pane_current_command}\t#{pane_left}\t#{pane_top}\t#{pane_width}\t#{pane_height}")
+	out, err := run(ctx, "list-panes", "-a", "-F", paneListFormat)
 	if err != nil {
 		return nil, err
 	}
-	if strings.TrimSpace(out) == "" {
-		return []Pane{}, nil
-	}
-	lines := strings.Split(strings.TrimSpace(out), "\n")
-	panes := make([]Pane, 0, len(lines))
-	for _, line := range lines {
-		parts := strings.Split(line, "\t")
-		if len(parts) < 7 {
-			continue
-		}
-		if parts[0] != session {
-			continue
-		}
-		windowIndex, _ := strconv.Atoi(parts[1])
-		paneIndex, _ := strconv.Atoi(parts[2])
-		left, _ := strconv.Atoi(valueAt(parts, 10))
-		top, _ := strconv.Atoi(valueAt(parts, 11))
-		width, _ := strconv.Atoi(valueAt(parts, 12))
-		height, _ := strconv.Atoi(valueAt(parts, 13))
-		panes = append(panes, Pane{
-			Session:        parts[0],
-			WindowIndex:    windowIndex,
-			PaneIndex:      paneIndex,
-			PaneID:         parts[3],
-			Title:          parts[4],
-			Active:         parts[5] == "1",
-			TTY:            parts[6],
-			CurrentPath:    valueAt(parts, 7),
-			StartCommand:   valueAt(parts, 8),
-			CurrentCommand: valueAt(parts, 9),
-			Left:           left,
-			Top:            top,
-			Width:          width,
-			Height:         height,
-		})
-	}
-	return panes, nil
+	return parsePaneListOutput(out, session), nil
 }
 
 // CapturePaneLines captures pane lines.
@@ -869,6 +888,8 @@ var run = func(ctx context.Context, args ...string) (string, error) { // var ena
 }
 
 func executeTmuxCommand(ctx context.Context, name string, commandArgs, tmuxArgs []string) (string, error) {
+	ctx, span := traceCommand(ctx, "exec", tmuxArgs)
+	defer span.End()
 	cmd := exec.CommandContext(ctx, name, commandArgs...)
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -882,7 +903,9 @@ func executeTmuxCommand(ctx context.Context, name string, commandArgs, tmuxArgs
 				Err:  err,
 			}
 		}
-		return "", classifyError(err, stderr.String(), tmuxArgs)
+		err = classifyError(err, stderr.String(), tmuxArgs)
+		span.RecordError(err)
+		return "", err
 	}
 	return stdout.String(), nil
 }
@@ -909,6 +932,8 @@ func classifyError(err error, stderr string, args []string) error {
 		return &Error{Kind: ErrKindSessionExists, Msg: strings.TrimSpace(stderr), Err: err}
 	case isServerNotRunningMessage(msg):
 		return &Error{Kind: ErrKindServerNotRunning, Msg: strings.TrimSpace(stderr), Err: err}
+	case strings.HasPrefix(msg, "no buffer"):
+		return &Error{Kind: ErrKindBufferNotFound, Msg: strings.TrimSpace(stderr), Err: err}
 	default:
 		return &Error{
 			Kind: ErrKindCommandFailed,
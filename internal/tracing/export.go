@@ -0,0 +1,324 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	queueSize      = 2048
+	maxBatch       = 512
+	exportInterval = 5 * time.Second
+	exportTimeout  = 10 * time.Second
+	tracesPath     = "/v1/traces"
+)
+
+// ErrInvalidEndpoint is returned for an endpoint that is not an http:// or
+// https:// URL with a host.
+var ErrInvalidEndpoint = errors.New("tracing endpoint must be an http:// or https:// URL with a host")
+
+// Config configures a Tracer.
+type Config struct {
+	// Endpoint is the OTLP/HTTP collector, e.g. http://localhost:4318.
+	// Spans are posted to its /v1/traces path unless it already names one.
+	Endpoint    string
+	ServiceName string
+	Version     string
+	// SampleRatio is the share of new traces recorded, from 0 to 1.
+	// Traces continued from a traceparent header keep the caller's choice.
+	SampleRatio float64
+	// Headers are sent with every export, e.g. an authorization token.
+	Headers map[string]string
+}
+
+// TracesURL returns the URL spans are posted to for endpoint.
+func TracesURL(endpoint string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", ErrInvalidEndpoint
+	}
+	if parsed.Path = strings.TrimSuffix(parsed.Path, "/"); !strings.HasSuffix(parsed.Path, tracesPath) {
+		parsed.Path += tracesPath
+	}
+	return parsed.String(), nil
+}
+
+// Tracer batches ended spans and exports them in the background.
+type Tracer struct {
+	url       string
+	headers   map[string]string
+	resource  []Attr
+	threshold uint64
+	always    bool
+	client    *http.Client
+
+	queue    chan *Span
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+
+	mu      sync.Mutex
+	failing bool
+	dropped int
+}
+
+// NewTracer starts a tracer exporting to cfg.Endpoint. Call Shutdown to
+// flush the remaining spans.
+func NewTracer(cfg Config) (*Tracer, error) {
+	tracesURL, err := TracesURL(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	resource := []Attr{String("service.name", cfg.ServiceName)}
+	if cfg.Version != "" {
+		resource = append(resource, String("service.version", cfg.Version))
+	}
+	ratio := min(max(cfg.SampleRatio, 0), 1)
+	t := &Tracer{
+		url:       tracesURL,
+		headers:   cfg.Headers,
+		resource:  resource,
+		threshold: uint64(ratio * math.MaxUint64),
+		always:    ratio >= 1,
+		client:    &http.Client{Timeout: exportTimeout},
+		queue:     make(chan *Span, queueSize),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go t.loop()
+	return t, nil
+}
+
+// Shutdown exports the queued spans and stops the tracer, waiting until
+// ctx ends at most.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.stopOnce.Do(func() { close(t.stop) })
+	select {
+	case <-t.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sample decides on a new trace from the low half of its ID, so every
+// service sampling by ratio agrees on the same traces.
+func (t *Tracer) sample(traceID [16]byte) bool {
+	return t.always || binary.BigEndian.Uint64(traceID[8:]) < t.threshold
+}
+
+// enqueue hands an ended span to the exporter, dropping it when the queue
+// is full rather than slowing the caller down.
+func (t *Tracer) enqueue(s *Span) {
+	select {
+	case t.queue <- s:
+	default:
+		t.mu.Lock()
+		t.dropped++
+		t.mu.Unlock()
+	}
+}
+
+func (t *Tracer) loop() {
+	defer close(t.stopped)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, maxBatch)
+	flush := func() {
+		if len(batch) > 0 {
+			t.export(batch)
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case span := <-t.queue:
+			if batch = append(batch, span); len(batch) >= maxBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-t.stop:
+			for {
+				select {
+				case span := <-t.queue:
+					if batch = append(batch, span); len(batch) >= maxBatch {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// export posts a batch. Failures are logged once until an export succeeds
+// again, so an unreachable collector does not flood the log.
+func (t *Tracer) export(batch []*Span) {
+	err := t.post(batch)
+	t.mu.Lock()
+	dropped := t.dropped
+	t.dropped = 0
+	wasFailing := t.failing
+	t.failing = err != nil
+	t.mu.Unlock()
+
+	switch {
+	case err != nil && !wasFailing:
+		slog.Warn("tracing export failed", "endpoint", t.url, "spans", len(batch), "err", err)
+	case err == nil && wasFailing:
+		slog.Info("tracing export recovered", "endpoint", t.url)
+	}
+	if dropped > 0 {
+		slog.Warn("tracing queue full, spans dropped", "dropped", dropped)
+	}
+}
+
+func (t *Tracer) post(batch []*Span) error {
+	body, err := json.Marshal(t.request(batch))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector answered %s", resp.Status)
+	}
+	return nil
+}
+
+// OTLP/HTTP JSON encoding. IDs are hex and 64-bit integers are strings, as
+// the protocol's JSON mapping requires.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              Kind           `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// OTLP status codes.
+const (
+	statusOK    = 1
+	statusError = 2
+)
+
+func (t *Tracer) request(batch []*Span) otlpRequest {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, s := range batch {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.sc.traceID[:]),
+			SpanID:            hex.EncodeToString(s.sc.spanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        keyValues(s.attrs),
+			Status:            otlpStatus{Code: statusOK},
+		}
+		if s.errored {
+			span.Status = otlpStatus{Code: statusError, Message: s.statusMsg}
+		}
+		s.mu.Unlock()
+		if s.parent != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parent[:])
+		}
+		spans = append(spans, span)
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: keyValues(t.resource)},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "sentinel"}, Spans: spans}},
+	}}}
+}
+
+func keyValues(attrs []Attr) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		var value otlpAnyValue
+		switch v := attr.Value.(type) {
+		case string:
+			value.StringValue = &v
+		case int64:
+			s := strconv.FormatInt(v, 10)
+			value.IntValue = &s
+		case bool:
+			value.BoolValue = &v
+		case float64:
+			value.DoubleValue = &v
+		default:
+			s := fmt.Sprint(v)
+			value.StringValue = &s
+		}
+		out = append(out, otlpKeyValue{Key: attr.Key, Value: value})
+	}
+	return out
+}
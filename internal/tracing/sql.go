@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+)
+
+// maxStatementLength bounds the db.statement attribute. Statements are
+// parameterized, so they carry no values.
+const maxStatementLength = 1024
+
+// WrapDriver returns d with ExecContext and QueryContext calls recorded as
+// client spans of the request or run that issued them. Queries outside a
+// trace are not recorded. A query span ends when the rows are returned,
+// not when they are read.
+func WrapDriver(d driver.Driver, system string) driver.Driver {
+	return tracedDriver{Driver: d, system: system}
+}
+
+type tracedDriver struct {
+	driver.Driver
+	system string
+}
+
+func (d tracedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn, system: d.system}, nil
+}
+
+type tracedConn struct {
+	driver.Conn
+	system string
+}
+
+func (c *tracedConn) start(ctx context.Context, query string) (context.Context, *Span) {
+	operation, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	if len(query) > maxStatementLength {
+		query = query[:maxStatementLength]
+	}
+	return StartChild(ctx, c.system+" "+strings.ToUpper(operation), KindClient,
+		String("db.system", c.system),
+		String("db.statement", query),
+	)
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := c.start(ctx, query)
+	defer span.End()
+	result, err := execer.ExecContext(ctx, query, args)
+	span.RecordError(skipErr(err))
+	return result, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := c.start(ctx, query)
+	defer span.End()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	span.RecordError(skipErr(err))
+	return rows, err
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Prepare(query)
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Begin() //nolint:staticcheck // drivers without BeginTx only offer Begin.
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// skipErr hides driver.ErrSkip, which only tells database/sql to fall back
+// to a prepared statement.
+func skipErr(err error) error {
+	if err == driver.ErrSkip { //nolint:errorlint // sentinel compared by identity, as database/sql does.
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,225 @@
+// Package tracing records OpenTelemetry-compatible spans for API requests,
+// store queries, tmux commands and runbook runs, and exports them to an
+// OTLP/HTTP collector. It implements the subset Sentinel uses: W3C trace
+// context propagation, ratio sampling and batched JSON export.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kind is the OTLP span kind.
+type Kind int
+
+// Span kinds used by Sentinel.
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// Attr is a span attribute. Value is a string, int64, bool or float64.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute.
+func String(key, value string) Attr { return Attr{Key: key, Value: value} }
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attr { return Attr{Key: key, Value: int64(value)} }
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attr { return Attr{Key: key, Value: value} }
+
+var defaultTracer atomic.Pointer[Tracer]
+
+// SetDefault makes t the tracer used by Start. Nil disables tracing.
+func SetDefault(t *Tracer) {
+	defaultTracer.Store(t)
+}
+
+// Enabled reports whether a default tracer is set.
+func Enabled() bool {
+	return defaultTracer.Load() != nil
+}
+
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+	sampled bool
+}
+
+type (
+	spanKey   struct{}
+	remoteKey struct{}
+)
+
+// Span is an operation being timed. A nil or unsampled span ignores every
+// call, so callers need no checks.
+type Span struct {
+	tracer *Tracer
+	sc     spanContext
+	parent [8]byte
+	kind   Kind
+	start  time.Time
+
+	mu        sync.Mutex
+	name      string
+	attrs     []Attr
+	errored   bool
+	statusMsg string
+	end       time.Time
+	ended     bool
+}
+
+// Start begins a span as a child of the span in ctx, or of the remote
+// parent stored by Extract, or as a new trace sampled at the configured
+// ratio. It returns a context carrying the span.
+func Start(ctx context.Context, name string, kind Kind, attrs ...Attr) (context.Context, *Span) {
+	t := defaultTracer.Load()
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{tracer: t, kind: kind, start: time.Now(), name: name, attrs: attrs}
+	binary.BigEndian.PutUint64(span.sc.spanID[:], nonZeroUint64())
+	if parent, ok := parentContext(ctx); ok {
+		span.sc.traceID, span.sc.sampled, span.parent = parent.traceID, parent.sampled, parent.spanID
+	} else {
+		binary.BigEndian.PutUint64(span.sc.traceID[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(span.sc.traceID[8:], nonZeroUint64())
+		span.sc.sampled = t.sample(span.sc.traceID)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// StartChild is Start for operations that are only worth recording as part
+// of a larger trace, such as single queries: without a parent in ctx it
+// records nothing.
+func StartChild(ctx context.Context, name string, kind Kind, attrs ...Attr) (context.Context, *Span) {
+	if !Enabled() {
+		return ctx, nil
+	}
+	if _, ok := parentContext(ctx); !ok {
+		return ctx, nil
+	}
+	return Start(ctx, name, kind, attrs...)
+}
+
+// SpanFromContext returns the span started in ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Extract returns ctx continuing the trace in a W3C traceparent header.
+// Malformed headers are ignored.
+func Extract(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ctx
+	}
+	var (
+		sc    spanContext
+		flags [1]byte
+	)
+	if _, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil || sc.traceID == [16]byte{} {
+		return ctx
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil || sc.spanID == [8]byte{} {
+		return ctx
+	}
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return ctx
+	}
+	sc.sampled = flags[0]&0x01 != 0
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+func parentContext(ctx context.Context) (spanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.sc, true
+	}
+	sc, ok := ctx.Value(remoteKey{}).(spanContext)
+	return sc, ok
+}
+
+// TraceID returns the span's trace ID in hex, or "" for a nil span.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.sc.traceID[:])
+}
+
+// SetName renames the span, e.g. once the route of a request is known.
+func (s *Span) SetName(name string) {
+	if !s.recording() {
+		return
+	}
+	s.mu.Lock()
+	s.name = name
+	s.mu.Unlock()
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if !s.recording() {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// SetError marks the span as failed with msg.
+func (s *Span) SetError(msg string) {
+	if !s.recording() {
+		return
+	}
+	s.mu.Lock()
+	s.errored, s.statusMsg = true, msg
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed when err is not nil.
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.SetError(err.Error())
+	}
+}
+
+// End finishes the span and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if !s.recording() {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended, s.end = true, time.Now()
+	s.mu.Unlock()
+	s.tracer.enqueue(s)
+}
+
+func (s *Span) recording() bool {
+	return s != nil && s.sc.sampled
+}
+
+func nonZeroUint64() uint64 {
+	for {
+		if v := rand.Uint64(); v != 0 {
+			return v
+		}
+	}
+}
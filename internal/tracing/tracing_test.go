@@ -0,0 +1,240 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// collector records the spans posted to it, flattened across batches.
+type collector struct {
+	server  *httptest.Server
+	headers chan http.Header
+	spans   chan otlpSpan
+}
+
+func newCollector(t *testing.T) *collector {
+	t.Helper()
+	c := &collector{headers: make(chan http.Header, 16), spans: make(chan otlpSpan, 64)}
+	c.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req otlpRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.headers <- r.Header
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					c.spans <- span
+				}
+			}
+		}
+	}))
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+// startTracer installs a tracer for the test and returns a function that
+// flushes it.
+func startTracer(t *testing.T, cfg Config) func() {
+	t.Helper()
+	tracer, err := NewTracer(cfg)
+	if err != nil {
+		t.Fatalf("NewTracer: %v", err)
+	}
+	SetDefault(tracer)
+	t.Cleanup(func() { SetDefault(nil) })
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracer.Shutdown(ctx); err != nil {
+			t.Fatalf("Shutdown: %v", err)
+		}
+	}
+}
+
+func attribute(span otlpSpan, key string) string {
+	for _, kv := range span.Attributes {
+		if kv.Key != key {
+			continue
+		}
+		switch {
+		case kv.Value.StringValue != nil:
+			return *kv.Value.StringValue
+		case kv.Value.IntValue != nil:
+			return *kv.Value.IntValue
+		}
+	}
+	return ""
+}
+
+func TestExportsSpansAsOTLP(t *testing.T) {
+	c := newCollector(t)
+	flush := startTracer(t, Config{
+		Endpoint:    c.server.URL,
+		ServiceName: "sentinel",
+		SampleRatio: 1,
+		Headers:     map[string]string{"Authorization": "Bearer t"},
+	})
+
+	ctx := Extract(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, parent := Start(ctx, "GET /api/tmux/sessions", KindServer, String("url.path", "/api/tmux/sessions"))
+	_, child := StartChild(ctx, "tmux list-sessions", KindClient)
+	child.RecordError(errors.New("no server running"))
+	child.End()
+	parent.SetAttributes(Int("http.response.status_code", 200))
+	parent.End()
+	parent.End()
+	flush()
+
+	if got := (<-c.headers).Get("Authorization"); got != "Bearer t" {
+		t.Fatalf("Authorization = %q", got)
+	}
+	spans := map[string]otlpSpan{}
+	for range 2 {
+		select {
+		case span := <-c.spans:
+			spans[span.Name] = span
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d spans, want 2", len(spans))
+		}
+	}
+	select {
+	case extra := <-c.spans:
+		t.Fatalf("unexpected span %+v", extra)
+	default:
+	}
+
+	server, client := spans["GET /api/tmux/sessions"], spans["tmux list-sessions"]
+	if server.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentSpanID != "00f067aa0ba902b7" || server.Kind != KindServer {
+		t.Fatalf("server span = %+v", server)
+	}
+	if attribute(server, "http.response.status_code") != "200" || attribute(server, "url.path") != "/api/tmux/sessions" {
+		t.Fatalf("server attributes = %+v", server.Attributes)
+	}
+	if client.TraceID != server.TraceID || client.ParentSpanID != server.SpanID {
+		t.Fatalf("client span %+v is not a child of %+v", client, server)
+	}
+	if client.Status.Code != statusError || client.Status.Message != "no server running" {
+		t.Fatalf("client status = %+v", client.Status)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	c := newCollector(t)
+	flush := startTracer(t, Config{Endpoint: c.server.URL, SampleRatio: 0})
+
+	_, dropped := Start(context.Background(), "new trace", KindInternal)
+	dropped.End()
+	_, orphan := StartChild(context.Background(), "query", KindClient)
+	if orphan != nil {
+		t.Fatal("StartChild without a parent returned a span")
+	}
+	unsampled := Extract(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	_, skipped := Start(unsampled, "unsampled caller", KindServer)
+	skipped.End()
+	sampled := Extract(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, kept := Start(sampled, "sampled caller", KindServer)
+	kept.End()
+	flush()
+
+	if span := <-c.spans; span.Name != "sampled caller" {
+		t.Fatalf("exported %q, want only the sampled caller", span.Name)
+	}
+	if len(c.spans) != 0 {
+		t.Fatalf("%d extra spans exported", len(c.spans))
+	}
+}
+
+func TestExtractIgnoresMalformedHeaders(t *testing.T) {
+	for _, header := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		if _, ok := parentContext(Extract(context.Background(), header)); ok {
+			t.Errorf("Extract(%q) produced a parent", header)
+		}
+	}
+}
+
+func TestTracesURL(t *testing.T) {
+	cases := map[string]string{
+		"http://localhost:4318":            "http://localhost:4318/v1/traces",
+		"https://otlp.example/otlp/":       "https://otlp.example/otlp/v1/traces",
+		"https://otlp.example/v1/traces":   "https://otlp.example/v1/traces",
+		"grpc://collector:4317":            "",
+		"localhost:4318":                   "",
+		"http:///missing-host/v1/traces":   "",
+		"https://otlp.example/v1/traces/":  "https://otlp.example/v1/traces",
+		"https://otlp.example?tenant=home": "https://otlp.example/v1/traces?tenant=home",
+	}
+	for endpoint, want := range cases {
+		got, err := TracesURL(endpoint)
+		if (err != nil) != (want == "") || got != want {
+			t.Errorf("TracesURL(%q) = %q, %v; want %q", endpoint, got, err, want)
+		}
+	}
+}
+
+type fakeConn struct {
+	driver.Conn
+	err error
+}
+
+func (c fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), c.err
+}
+
+type fakeDriver struct{ err error }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{err: d.err}, nil }
+
+func TestWrapDriverTracesQueriesWithinATrace(t *testing.T) {
+	c := newCollector(t)
+	flush := startTracer(t, Config{Endpoint: c.server.URL, SampleRatio: 1})
+
+	conn, err := WrapDriver(fakeDriver{err: errors.New("disk I/O error")}, "sqlite").Open("test.db")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	execer := conn.(driver.ExecerContext)
+	if _, err := execer.ExecContext(context.Background(), "DELETE FROM outside", nil); err == nil {
+		t.Fatal("ExecContext error was swallowed")
+	}
+	ctx, parent := Start(context.Background(), "request", KindServer)
+	_, _ = execer.ExecContext(ctx, "update sessions SET icon = ? WHERE name = ?", nil)
+	parent.End()
+	flush()
+
+	spans := map[string]otlpSpan{}
+	for range 2 {
+		span := <-c.spans
+		spans[span.Name] = span
+	}
+	query, ok := spans["sqlite UPDATE"]
+	if !ok {
+		t.Fatalf("spans = %v, want sqlite UPDATE", spans)
+	}
+	if attribute(query, "db.statement") != "update sessions SET icon = ? WHERE name = ?" || query.Status.Code != statusError {
+		t.Fatalf("query span = %+v", query)
+	}
+	if len(c.spans) != 0 {
+		t.Fatalf("%d extra spans exported; queries outside a trace must not be", len(c.spans))
+	}
+}
@@ -1073,7 +1073,7 @@ func TestRegisterSetsUpRoutes(t *testing.T) {
 	hub := events.NewHub()
 
 	mux := http.NewServeMux()
-	if err := Register(mux, guard, st, hub, nil, nil); err != nil {
+	if err := Register(mux, guard, st, hub, nil, nil, nil); err != nil {
 		t.Fatalf("Register() error = %v", err)
 	}
 
@@ -1143,7 +1143,7 @@ func TestRegisterServesBrandedManifest(t *testing.T) {
 	hub := events.NewHub()
 
 	mux := http.NewServeMux()
-	if err := Register(mux, guard, st, hub, nil, nil); err != nil {
+	if err := Register(mux, guard, st, hub, nil, nil, nil); err != nil {
 		t.Fatalf("Register() error = %v", err)
 	}
 
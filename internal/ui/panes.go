@@ -0,0 +1,368 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/tmux"
+	"github.com/opus-domini/sentinel/internal/validate"
+	"github.com/opus-domini/sentinel/internal/ws"
+)
+
+const (
+	// paneStreamInterval is how often subscribed panes are re-captured.
+	paneStreamInterval = 250 * time.Millisecond
+	// maxPaneSubscriptions caps the panes one /ws/panes socket may watch.
+	maxPaneSubscriptions = 16
+	keyPaneID            = "paneId"
+)
+
+var (
+	tmuxCapturePaneViewFn = func(ctx context.Context, user, paneID string) (tmux.PaneView, error) {
+		return tmux.Service{User: user}.CapturePaneView(ctx, paneID)
+	}
+	tmuxSendPaneTextFn = func(ctx context.Context, user, paneID, text string) error {
+		return tmux.Service{User: user}.SendText(ctx, paneID, text)
+	}
+	tmuxListPanesFn = func(ctx context.Context, user, session string) ([]tmux.Pane, error) {
+		return tmux.Service{User: user}.ListPanes(ctx, session)
+	}
+)
+
+// paneStream is one pane subscribed on a /ws/panes socket.
+type paneStream struct {
+	paneID string
+	user   string
+	// last is the frame most recently sent, so unchanged screens are
+	// not sent again.
+	last string
+}
+
+// paneMux serves the panes subscribed on one /ws/panes socket. Unlike
+// /ws/tmux it does not attach a tmux client: each pane is captured every
+// paneStreamInterval and its screen is sent whenever it changed, and input
+// is typed into the pane with send-keys. Panes keep their tmux size.
+type paneMux struct {
+	conn              *ws.Conn
+	sessionUserLookup SessionUserLookup
+	wake              chan struct{}
+
+	mu    sync.Mutex
+	panes map[string]*paneStream
+}
+
+func (h *Handler) attachPanesWS(w http.ResponseWriter, r *http.Request) {
+	if !h.requireWSRole(w, r, security.RoleOperator) {
+		return
+	}
+
+	wsConn, _, err := ws.UpgradeWithSubprotocols(w, r, nil, []string{subprotocolSentinelV1})
+	if err != nil {
+		return
+	}
+	defer func() { _ = wsConn.Close() }()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	mux := &paneMux{
+		conn:              wsConn,
+		sessionUserLookup: h.sessionUserLookup,
+		wake:              make(chan struct{}, 1),
+		panes:             make(map[string]*paneStream),
+	}
+	if !writeAttachStatus(wsConn, map[string]any{keyMsgType: "status", "state": "ready"}, "panes") {
+		return
+	}
+
+	errCh, sendErr := newAttachErrChannel()
+	go func() {
+		defer recoverWSGoroutine("panesRead", sendErr)
+		sendErr(mux.readLoop(ctx))
+	}()
+	go func() {
+		defer recoverWSGoroutine("panesStream", sendErr)
+		sendErr(mux.streamLoop(ctx))
+	}()
+
+	pingTicker := time.NewTicker(30 * time.Second)
+	defer pingTicker.Stop()
+	go runPingLoop(ctx, wsConn, pingTicker.C, sendErr)
+
+	finalErr := <-errCh
+	if finalErr != nil && !errors.Is(finalErr, io.EOF) && !errors.Is(finalErr, ws.ErrClosed) {
+		slog.Warn("pane stream error", "err", finalErr)
+		_ = wsConn.WriteClose(ws.CloseInternal, "connection error")
+		return
+	}
+	_ = wsConn.WriteClose(ws.CloseNormal, "done")
+}
+
+func (m *paneMux) readLoop(ctx context.Context) error {
+	for {
+		opcode, payload, err := m.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case ws.OpBinary:
+			if err := m.handleInput(ctx, payload); err != nil {
+				return err
+			}
+		case ws.OpText:
+			if err := m.handleControl(ctx, payload); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (m *paneMux) handleControl(ctx context.Context, payload []byte) error {
+	if len(payload) > 8*1024 {
+		return errors.New("control payload too large")
+	}
+	var msg struct {
+		Type    string `json:"type"`
+		Session string `json:"session"`
+		PaneID  string `json:"paneId"`
+	}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil
+	}
+	paneID := strings.TrimSpace(msg.PaneID)
+	switch msg.Type {
+	case "subscribe":
+		return m.subscribe(ctx, strings.TrimSpace(msg.Session), paneID)
+	case "unsubscribe":
+		m.mu.Lock()
+		_, ok := m.panes[paneID]
+		delete(m.panes, paneID)
+		m.mu.Unlock()
+		if !ok {
+			return nil
+		}
+		return m.writeMessage(map[string]any{keyMsgType: "unsubscribed", keyPaneID: paneID, "reason": "client"})
+	}
+	return nil
+}
+
+func (m *paneMux) subscribe(ctx context.Context, session, paneID string) error {
+	if !validate.SessionName(session) || !validPaneID(paneID) {
+		return m.writePaneError(paneID, "INVALID_REQUEST", "valid session and paneId are required")
+	}
+	m.mu.Lock()
+	_, exists := m.panes[paneID]
+	full := len(m.panes) >= maxPaneSubscriptions
+	m.mu.Unlock()
+	if exists {
+		return nil
+	}
+	if full {
+		return m.writePaneError(paneID, "TOO_MANY_PANES", fmt.Sprintf("at most %d panes per connection", maxPaneSubscriptions))
+	}
+
+	// The pane's session picks the tmux server, with the same fallback to
+	// the default server as /ws/tmux. Pane IDs are global to a server, so
+	// the pane must also be in that session.
+	user := ""
+	if m.sessionUserLookup != nil {
+		user = m.sessionUserLookup(session)
+	}
+	inSession := sessionHasPane(ctx, user, session, paneID)
+	if !inSession && user != "" {
+		user = ""
+		inSession = sessionHasPane(ctx, user, session, paneID)
+	}
+	if !inSession {
+		return m.writePaneError(paneID, "PANE_NOT_FOUND", "pane not found")
+	}
+	view, err := capturePaneView(ctx, user, paneID)
+	if err != nil {
+		return m.writePaneError(paneID, "PANE_NOT_FOUND", "pane not found")
+	}
+
+	// Subscriptions only change on the read loop, so the pane is added
+	// after its first screen is out and the stream loop cannot overtake it.
+	stream := &paneStream{paneID: paneID, user: user, last: renderPaneView(view)}
+	if err := m.writeMessage(map[string]any{keyMsgType: "subscribed", keyPaneID: paneID, keySession: session}); err != nil {
+		return err
+	}
+	if err := m.conn.WriteBinary(tagPaneFrame(paneID, []byte(stream.last))); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.panes[paneID] = stream
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *paneMux) handleInput(ctx context.Context, frame []byte) error {
+	paneID, input, ok := splitPaneFrame(frame)
+	if !ok || len(input) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	stream, subscribed := m.panes[paneID]
+	m.mu.Unlock()
+	if !subscribed {
+		return nil
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := tmuxSendPaneTextFn(sendCtx, stream.user, paneID, string(input)); err != nil {
+		slog.Warn("pane input failed", keyPaneID, paneID, "err", err)
+	}
+	// Show the echo without waiting for the next tick.
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (m *paneMux) streamLoop(ctx context.Context) error {
+	ticker := time.NewTicker(paneStreamInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-m.wake:
+		}
+		if err := m.refresh(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// refresh captures every subscribed pane, sends the screens that changed
+// and drops the panes that no longer exist.
+func (m *paneMux) refresh(ctx context.Context) error {
+	m.mu.Lock()
+	streams := make([]*paneStream, 0, len(m.panes))
+	for _, stream := range m.panes {
+		streams = append(streams, stream)
+	}
+	m.mu.Unlock()
+
+	for _, stream := range streams {
+		view, err := capturePaneView(ctx, stream.user, stream.paneID)
+		if ctx.Err() != nil {
+			return nil
+		}
+		m.mu.Lock()
+		current := m.panes[stream.paneID] == stream
+		if current && err != nil {
+			delete(m.panes, stream.paneID)
+		}
+		m.mu.Unlock()
+		if !current {
+			continue // unsubscribed while capturing
+		}
+		if err != nil {
+			if werr := m.writeMessage(map[string]any{keyMsgType: "unsubscribed", keyPaneID: stream.paneID, "reason": "pane closed"}); werr != nil {
+				return werr
+			}
+			continue
+		}
+		frame := renderPaneView(view)
+		if frame == stream.last {
+			continue
+		}
+		stream.last = frame
+		if err := m.conn.WriteBinary(tagPaneFrame(stream.paneID, []byte(frame))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *paneMux) writeMessage(msg map[string]any) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return m.conn.WriteText(payload)
+}
+
+func (m *paneMux) writePaneError(paneID, code, message string) error {
+	return m.writeMessage(map[string]any{keyMsgType: "error", keyPaneID: paneID, "code": code, "message": message})
+}
+
+func capturePaneView(ctx context.Context, user, paneID string) (tmux.PaneView, error) {
+	captureCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	return tmuxCapturePaneViewFn(captureCtx, user, paneID)
+}
+
+// sessionHasPane reports whether paneID is one of session's panes on the
+// tmux server of user.
+func sessionHasPane(ctx context.Context, user, session, paneID string) bool {
+	listCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	panes, err := tmuxListPanesFn(listCtx, user, session)
+	if err != nil {
+		return false
+	}
+	for _, pane := range panes {
+		if pane.PaneID == paneID {
+			return true
+		}
+	}
+	return false
+}
+
+// renderPaneView turns a captured screen into bytes that repaint a
+// terminal of the pane's size: reset, clear, the screen lines, and the
+// cursor moved back to where the pane has it.
+func renderPaneView(view tmux.PaneView) string {
+	var b strings.Builder
+	b.WriteString("\x1b[0m\x1b[2J\x1b[H")
+	b.WriteString(strings.ReplaceAll(strings.TrimRight(view.Content, "\n"), "\n", "\x1b[0m\r\n"))
+	fmt.Fprintf(&b, "\x1b[0m\x1b[%d;%dH", view.CursorY+1, view.CursorX+1)
+	return b.String()
+}
+
+// validPaneID reports whether id is a tmux pane ID such as %3.
+func validPaneID(id string) bool {
+	if len(id) < 2 || len(id) > 16 || id[0] != '%' {
+		return false
+	}
+	for _, c := range id[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// tagPaneFrame prefixes a binary frame with the pane it belongs to: one
+// byte holding the pane ID's length, then the ID itself.
+func tagPaneFrame(paneID string, payload []byte) []byte {
+	frame := make([]byte, 0, 1+len(paneID)+len(payload))
+	frame = append(frame, byte(len(paneID)))
+	frame = append(frame, paneID...)
+	return append(frame, payload...)
+}
+
+// splitPaneFrame undoes tagPaneFrame.
+func splitPaneFrame(frame []byte) (string, []byte, bool) {
+	if len(frame) == 0 {
+		return "", nil, false
+	}
+	n := int(frame[0])
+	if n == 0 || len(frame) < 1+n {
+		return "", nil, false
+	}
+	return string(frame[1 : 1+n]), frame[1+n:], true
+}
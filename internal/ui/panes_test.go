@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/security"
+	"github.com/opus-domini/sentinel/internal/tmux"
+	"github.com/opus-domini/sentinel/internal/ws"
+)
+
+func TestPaneFrameTagging(t *testing.T) {
+	t.Parallel()
+
+	frame := tagPaneFrame("%12", []byte("ls\r"))
+	paneID, payload, ok := splitPaneFrame(frame)
+	if !ok || paneID != "%12" || string(payload) != "ls\r" {
+		t.Fatalf("splitPaneFrame = %q, %q, %v", paneID, payload, ok)
+	}
+	for _, bad := range [][]byte{nil, {0, 'x'}, {5, '%', '1'}} {
+		if _, _, ok := splitPaneFrame(bad); ok {
+			t.Errorf("splitPaneFrame(%v) ok, want rejected", bad)
+		}
+	}
+
+	for id, want := range map[string]bool{"%0": true, "%123": true, "": false, "%": false, "3": false, "%1a": false, "dev:0.1": false} {
+		if got := validPaneID(id); got != want {
+			t.Errorf("validPaneID(%q) = %v, want %v", id, got, want)
+		}
+	}
+
+	got := renderPaneView(tmux.PaneView{Content: "a\nb\n", CursorX: 1, CursorY: 1})
+	if want := "\x1b[0m\x1b[2J\x1b[Ha\x1b[0m\r\nb\x1b[0m\x1b[2;2H"; got != want {
+		t.Fatalf("renderPaneView = %q, want %q", got, want)
+	}
+}
+
+func TestAttachPanesWSMultiplexesPanes(t *testing.T) {
+	originalCapture := tmuxCapturePaneViewFn
+	originalSend := tmuxSendPaneTextFn
+	originalList := tmuxListPanesFn
+	t.Cleanup(func() {
+		tmuxCapturePaneViewFn = originalCapture
+		tmuxSendPaneTextFn = originalSend
+		tmuxListPanesFn = originalList
+	})
+
+	var mu sync.Mutex
+	screens := map[string]string{"%1": "one", "%2": "two", "%3": "three"}
+	tmuxCapturePaneViewFn = func(_ context.Context, _ string, paneID string) (tmux.PaneView, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		content, ok := screens[paneID]
+		if !ok {
+			return tmux.PaneView{}, errors.New("can't find pane")
+		}
+		return tmux.PaneView{Content: content}, nil
+	}
+	tmuxSendPaneTextFn = func(_ context.Context, _ string, paneID, text string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		screens[paneID] += text
+		return nil
+	}
+	// %3 exists but belongs to another session.
+	tmuxListPanesFn = func(_ context.Context, _ string, session string) ([]tmux.Pane, error) {
+		if session != testSessionName {
+			return []tmux.Pane{{Session: session, PaneID: "%3"}}, nil
+		}
+		return []tmux.Pane{{Session: session, PaneID: "%1"}, {Session: session, PaneID: "%2"}, {Session: session, PaneID: "%9"}}, nil
+	}
+
+	h := &Handler{guard: security.New("", nil, security.CookieSecureAuto)}
+	srv := httptest.NewServer(http.HandlerFunc(h.attachPanesWS))
+	defer srv.Close()
+
+	conn := dialWebSocketPath(t, srv.URL, "/ws/panes")
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+
+	if msg := readPaneMessage(t, conn); msg[keyMsgType] != testMessageStatus {
+		t.Fatalf("greeting = %v", msg)
+	}
+
+	for _, paneID := range []string{"%1", "%2"} {
+		sendPaneControl(t, conn, map[string]any{keyMsgType: "subscribe", keySession: testSessionName, keyPaneID: paneID})
+		if msg := readPaneMessage(t, conn); msg[keyMsgType] != "subscribed" || msg[keyPaneID] != paneID {
+			t.Fatalf("subscribe %s: reply = %v", paneID, msg)
+		}
+		if id, screen := readPaneScreen(t, conn); id != paneID || !strings.Contains(screen, screens[paneID]) {
+			t.Fatalf("subscribe %s: first screen %s %q", paneID, id, screen)
+		}
+	}
+
+	if err := writeClientFrame(conn, ws.OpBinary, tagPaneFrame("%1", []byte("!"))); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	if id, screen := readPaneScreen(t, conn); id != "%1" || !strings.Contains(screen, "one!") {
+		t.Fatalf("after input: screen %s %q, want %%1 with the echo", id, screen)
+	}
+
+	sendPaneControl(t, conn, map[string]any{keyMsgType: "unsubscribe", keyPaneID: "%2"})
+	if msg := readPaneMessage(t, conn); msg[keyMsgType] != "unsubscribed" || msg[keyPaneID] != "%2" || msg["reason"] != "client" {
+		t.Fatalf("unsubscribe reply = %v", msg)
+	}
+
+	sendPaneControl(t, conn, map[string]any{keyMsgType: "subscribe", keySession: testSessionName, keyPaneID: "%9"})
+	if msg := readPaneMessage(t, conn); msg[keyMsgType] != "error" || msg["code"] != "PANE_NOT_FOUND" {
+		t.Fatalf("missing pane reply = %v", msg)
+	}
+
+	sendPaneControl(t, conn, map[string]any{keyMsgType: "subscribe", keySession: testSessionName, keyPaneID: "%3"})
+	if msg := readPaneMessage(t, conn); msg[keyMsgType] != "error" || msg["code"] != "PANE_NOT_FOUND" {
+		t.Fatalf("pane of another session reply = %v", msg)
+	}
+
+	mu.Lock()
+	delete(screens, "%1")
+	mu.Unlock()
+	if msg := readPaneMessage(t, conn); msg[keyMsgType] != "unsubscribed" || msg[keyPaneID] != "%1" || msg["reason"] != "pane closed" {
+		t.Fatalf("closed pane message = %v", msg)
+	}
+}
+
+func sendPaneControl(t *testing.T, conn net.Conn, msg map[string]any) {
+	t.Helper()
+	payload, _ := json.Marshal(msg)
+	if err := writeClientFrame(conn, ws.OpText, payload); err != nil {
+		t.Fatalf("write control frame: %v", err)
+	}
+}
+
+// readPaneMessage returns the next text message, skipping screen frames.
+func readPaneMessage(t *testing.T, conn net.Conn) map[string]any {
+	t.Helper()
+	for {
+		opcode, payload, err := readServerFrame(conn)
+		if err != nil {
+			t.Fatalf("readServerFrame error = %v", err)
+		}
+		if opcode != ws.OpText {
+			continue
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			t.Fatalf("decode %q: %v", payload, err)
+		}
+		return msg
+	}
+}
+
+func readPaneScreen(t *testing.T, conn net.Conn) (string, string) {
+	t.Helper()
+	opcode, payload, err := readServerFrame(conn)
+	if err != nil {
+		t.Fatalf("readServerFrame error = %v", err)
+	}
+	if opcode != ws.OpBinary {
+		t.Fatalf("opcode = %d, want binary screen (payload %q)", opcode, payload)
+	}
+	paneID, screen, ok := splitPaneFrame(payload)
+	if !ok {
+		t.Fatalf("untagged screen frame %q", payload)
+	}
+	return paneID, string(screen)
+}
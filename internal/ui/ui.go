@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/recording"
 	"github.com/opus-domini/sentinel/internal/security"
 	"github.com/opus-domini/sentinel/internal/store"
 	"github.com/opus-domini/sentinel/internal/term"
@@ -55,8 +56,8 @@ var (
 
 // OpsLogStreamer provides streaming log access for managed services.
 type OpsLogStreamer interface {
-	StreamLogs(ctx context.Context, name string) (io.ReadCloser, error)
-	StreamLogsByUnit(ctx context.Context, unit, scope, manager string) (io.ReadCloser, error)
+	StreamLogs(ctx context.Context, name, priority string) (io.ReadCloser, error)
+	StreamLogsByUnit(ctx context.Context, unit, scope, manager, priority string) (io.ReadCloser, error)
 }
 
 // presenceRepo covers watchtower presence and global-revision queries.
@@ -95,22 +96,26 @@ type Handler struct {
 	ops               OpsLogStreamer
 	sessionUserLookup SessionUserLookup
 	spa               *spa
+	// recordings is nil unless terminal recording is enabled.
+	recordings *recording.Store
 }
 
 // Register wires the package routes into the HTTP mux. A missing frontend
 // bundle (only the committed .gitkeep is embedded) is not a registration
 // error: the routes are wired and serve a 503 not-built response until the
-// bundle is compiled in.
-func Register(mux *http.ServeMux, guard *security.Guard, st *store.Store, eventsHub *events.Hub, ops OpsLogStreamer, sessionUserLookup SessionUserLookup) error {
+// bundle is compiled in. With a non-nil recordings store, terminals
+// attached through /ws/tmux are recorded.
+func Register(mux *http.ServeMux, guard *security.Guard, st *store.Store, eventsHub *events.Hub, ops OpsLogStreamer, sessionUserLookup SessionUserLookup, recordings *recording.Store) error {
 	app, err := newSPA(DistFS)
 	if err != nil && !errors.Is(err, errBundleMissing) {
 		return err
 	}
 
-	h := &Handler{guard: guard, events: eventsHub, store: st, ops: ops, sessionUserLookup: sessionUserLookup, spa: app}
+	h := &Handler{guard: guard, events: eventsHub, store: st, ops: ops, sessionUserLookup: sessionUserLookup, spa: app, recordings: recordings}
 	app.registerAssets(mux)
 	mux.HandleFunc("GET /manifest.webmanifest", h.serveManifest)
 	mux.HandleFunc("GET /ws/tmux", h.attachWS)
+	mux.HandleFunc("GET /ws/panes", h.attachPanesWS)
 	mux.HandleFunc("GET /ws/events", h.attachEventsWS)
 	mux.HandleFunc("GET /ws/logs", h.attachLogsWS)
 	mux.HandleFunc("GET /{path...}", h.spaPage)
@@ -152,12 +157,22 @@ func (h *Handler) serveManifest(w http.ResponseWriter, r *http.Request) {
 // requests. Returns true if the request is authorized, false otherwise
 // (with the appropriate HTTP error already written to w).
 func (h *Handler) requireWSAuth(w http.ResponseWriter, r *http.Request) bool {
+	return h.requireWSRole(w, r, security.RoleViewer)
+}
+
+// requireWSRole is requireWSAuth for sockets that need more than read
+// access, such as interactive terminals.
+func (h *Handler) requireWSRole(w http.ResponseWriter, r *http.Request, required security.Role) bool {
 	if err := h.guard.CheckOrigin(r); err != nil {
 		h.guard.LogOriginDenial(r, err)
 		http.Error(w, "forbidden", http.StatusForbidden)
 		return false
 	}
-	if err := h.guard.RequireAuth(r); err != nil {
+	if _, err := h.guard.Authorize(r, required); err != nil {
+		if errors.Is(err, security.ErrForbidden) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return false
+		}
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return false
 	}
@@ -165,7 +180,7 @@ func (h *Handler) requireWSAuth(w http.ResponseWriter, r *http.Request) bool {
 }
 
 func (h *Handler) attachWS(w http.ResponseWriter, r *http.Request) {
-	if !h.requireWSAuth(w, r) {
+	if !h.requireWSRole(w, r, security.RoleOperator) {
 		return
 	}
 
@@ -215,7 +230,7 @@ func (h *Handler) attachWS(w http.ResponseWriter, r *http.Request) {
 	}
 	defer func() { _ = wsConn.Close() }()
 
-	h.attachPTY(wsConn, attachPTYOptions{
+	opts := attachPTYOptions{
 		parentCtx: r.Context(),
 		label:     session,
 		startPTY: func(ctx context.Context) (*term.PTY, error) {
@@ -226,7 +241,13 @@ func (h *Handler) attachWS(w http.ResponseWriter, r *http.Request) {
 			"state":    "attached",
 			keySession: session,
 		},
-	})
+	}
+	if h.recordings != nil {
+		opts.startRecording = func() (*recording.Recorder, error) {
+			return h.recordings.Start(session, cols, rows)
+		}
+	}
+	h.attachPTY(wsConn, opts)
 }
 
 func parseAttachDimensions(r *http.Request) (int, int) {
@@ -284,10 +305,20 @@ func (h *Handler) attachEventsWS(w http.ResponseWriter, r *http.Request) {
 	}
 	defer func() { _ = wsConn.Close() }()
 
-	eventsCh, unsubscribe := h.events.Subscribe(64)
+	since, _ := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("since")), 10, 64)
+	eventsCh, replay, complete, unsubscribe := h.events.SubscribeSince(64, since)
 	defer unsubscribe()
 
-	writeEventsReadyPayload(wsConn)
+	writeEventsReadyPayload(wsConn, h.events.LatestEventID(), len(replay), complete)
+	for _, evt := range replay {
+		payload, marshalErr := json.Marshal(evt)
+		if marshalErr != nil {
+			continue
+		}
+		if err := wsConn.WriteText(payload); err != nil {
+			return
+		}
+	}
 	readErrCh := startEventsWSReader(wsConn, h.handleEventsClientMessage)
 	runEventsWSLoop(wsConn, eventsCh, readErrCh)
 }
@@ -301,6 +332,7 @@ func (h *Handler) attachLogsWS(w http.ResponseWriter, r *http.Request) {
 	unit := strings.TrimSpace(r.URL.Query().Get("unit"))
 	scope := strings.TrimSpace(r.URL.Query().Get(keyScope))
 	manager := strings.TrimSpace(r.URL.Query().Get("manager"))
+	priority := strings.TrimSpace(r.URL.Query().Get("priority"))
 
 	if service == "" && unit == "" {
 		http.Error(w, "service or unit required", http.StatusBadRequest)
@@ -318,9 +350,9 @@ func (h *Handler) attachLogsWS(w http.ResponseWriter, r *http.Request) {
 
 	var stream io.ReadCloser
 	if service != "" {
-		stream, err = h.ops.StreamLogs(ctx, service)
+		stream, err = h.ops.StreamLogs(ctx, service, priority)
 	} else {
-		stream, err = h.ops.StreamLogsByUnit(ctx, unit, scope, manager)
+		stream, err = h.ops.StreamLogsByUnit(ctx, unit, scope, manager, priority)
 	}
 	if err != nil {
 		errMsg, _ := json.Marshal(map[string]string{keyMsgType: "error", "message": err.Error()})
@@ -389,9 +421,12 @@ func (h *Handler) authorizeEventsWS(w http.ResponseWriter, r *http.Request) bool
 	return true
 }
 
-func writeEventsReadyPayload(wsConn *ws.Conn) {
+func writeEventsReadyPayload(wsConn *ws.Conn, latestEventID int64, replayed int, complete bool) {
 	readyPayload, _ := json.Marshal(events.NewEvent(events.TypeReady, map[string]any{
-		"message": "subscribed",
+		"message":        "subscribed",
+		"latestEventId":  latestEventID,
+		"replayed":       replayed,
+		"replayComplete": complete,
 	}))
 	_ = wsConn.WriteText(readyPayload)
 }
@@ -689,6 +724,9 @@ type attachPTYOptions struct {
 	label     string
 	startPTY  func(ctx context.Context) (*term.PTY, error)
 	statusMsg map[string]any
+	// startRecording, when set, records the terminal. A recording that
+	// cannot start is logged and the terminal attaches unrecorded.
+	startRecording func() (*recording.Recorder, error)
 }
 
 type pingWriter interface {
@@ -712,13 +750,23 @@ func (h *Handler) attachPTY(wsConn *ws.Conn, opts attachPTYOptions) {
 	shutdown := attachShutdown(cancelAttach, pty, wsConn, opts.label)
 	defer shutdown("connection closed")
 
+	rec := startAttachRecording(opts)
+	defer func() {
+		if err := rec.Close(); err != nil {
+			slog.Warn("recording close failed", "label", opts.label, "err", err)
+		}
+	}()
+	if rec != nil {
+		opts.statusMsg["recording"] = rec.ID()
+	}
+
 	if !writeAttachStatus(wsConn, opts.statusMsg, opts.label) {
 		return
 	}
 
 	errCh, sendErr := newAttachErrChannel()
-	startPTYReadLoop(pty, wsConn, sendErr)
-	startWSReadLoop(wsConn, pty, sendErr)
+	startPTYReadLoop(pty, wsConn, rec, sendErr)
+	startWSReadLoop(wsConn, pty, rec, sendErr)
 	startPTYWaitLoop(pty, sendErr)
 
 	// Keepalive pings
@@ -746,6 +794,18 @@ func startAttachPTY(attachCtx context.Context, wsConn *ws.Conn, opts attachPTYOp
 	return pty, true
 }
 
+func startAttachRecording(opts attachPTYOptions) *recording.Recorder {
+	if opts.startRecording == nil {
+		return nil
+	}
+	rec, err := opts.startRecording()
+	if err != nil {
+		slog.Warn("recording start failed", "label", opts.label, "err", err)
+		return nil
+	}
+	return rec
+}
+
 func attachShutdown(cancelAttach context.CancelFunc, pty *term.PTY, wsConn *ws.Conn, label string) func(string) {
 	var shutdownOnce sync.Once
 	return func(reason string) {
@@ -793,13 +853,14 @@ func recoverWSGoroutine(label string, sendErr func(error)) {
 	}
 }
 
-func startPTYReadLoop(pty *term.PTY, wsConn *ws.Conn, sendErr func(error)) {
+func startPTYReadLoop(pty *term.PTY, wsConn *ws.Conn, rec *recording.Recorder, sendErr func(error)) {
 	go func() {
 		defer recoverWSGoroutine("ptyRead", sendErr)
 		buf := make([]byte, 32768)
 		for {
 			n, readErr := pty.Read(buf)
 			if n > 0 {
+				rec.Output(buf[:n])
 				if werr := wsConn.WriteBinary(buf[:n]); werr != nil {
 					sendErr(werr)
 					return
@@ -815,7 +876,7 @@ func startPTYReadLoop(pty *term.PTY, wsConn *ws.Conn, sendErr func(error)) {
 	}()
 }
 
-func startWSReadLoop(wsConn *ws.Conn, pty *term.PTY, sendErr func(error)) {
+func startWSReadLoop(wsConn *ws.Conn, pty *term.PTY, rec *recording.Recorder, sendErr func(error)) {
 	go func() {
 		defer recoverWSGoroutine("wsRead", sendErr)
 		for {
@@ -826,15 +887,20 @@ func startWSReadLoop(wsConn *ws.Conn, pty *term.PTY, sendErr func(error)) {
 			}
 			switch opcode {
 			case ws.OpBinary:
+				rec.Input(payload)
 				if _, writeErr := pty.Write(payload); writeErr != nil {
 					sendErr(writeErr)
 					return
 				}
 			case ws.OpText:
-				if _, _, ctrlErr := handleControlMessage(payload, pty); ctrlErr != nil {
+				cols, rows, ctrlErr := handleControlMessage(payload, pty)
+				if ctrlErr != nil {
 					sendErr(ctrlErr)
 					return
 				}
+				if cols > 0 {
+					rec.Resize(cols, rows)
+				}
 			}
 		}
 	}()
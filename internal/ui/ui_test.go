@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/recording"
 	"github.com/opus-domini/sentinel/internal/security"
 	"github.com/opus-domini/sentinel/internal/store"
 	"github.com/opus-domini/sentinel/internal/term"
@@ -605,6 +606,87 @@ func TestAttachWSAllowsConcurrentClientsForSameSession(t *testing.T) {
 	}
 }
 
+func TestAttachWSRecordsTerminal(t *testing.T) {
+	originalExists := tmuxSessionExistsFn
+	originalEnsureMouse := tmuxEnsureWebMouse
+	originalMouse := tmuxSetSessionMouse
+	originalStatus := tmuxSetSessionStatus
+	originalAttach := startTmuxAttachFn
+	t.Cleanup(func() {
+		tmuxSessionExistsFn = originalExists
+		tmuxEnsureWebMouse = originalEnsureMouse
+		tmuxSetSessionMouse = originalMouse
+		tmuxSetSessionStatus = originalStatus
+		startTmuxAttachFn = originalAttach
+	})
+	tmuxEnsureWebMouse = func(_ context.Context) error { return nil }
+	tmuxSessionExistsFn = func(_ context.Context, _ string) (bool, error) { return true, nil }
+	tmuxSetSessionMouse = func(_ context.Context, _ string, _ bool) error { return nil }
+	tmuxSetSessionStatus = func(_ context.Context, _ string, _ bool) error { return nil }
+	startTmuxAttachFn = func(ctx context.Context, _ string, cols, rows int) (*term.PTY, error) {
+		return term.StartShell(ctx, "/bin/sh", cols, rows)
+	}
+
+	recordings := recording.New(t.TempDir(), recording.Options{})
+	h := &Handler{guard: security.New("", nil, security.CookieSecureAuto), recordings: recordings}
+	srv := httptest.NewServer(http.HandlerFunc(h.attachWS))
+	defer srv.Close()
+
+	conn := dialWebSocketPath(t, srv.URL, "/ws/tmux?session="+testSessionName+"&cols=90&rows=30")
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	_, payload, err := readServerFrame(conn)
+	if err != nil {
+		t.Fatalf("read status frame error = %v", err)
+	}
+	var status map[string]any
+	if err := json.Unmarshal(payload, &status); err != nil {
+		t.Fatalf("status payload is not JSON: %v", err)
+	}
+	id, _ := status["recording"].(string)
+	if id == "" {
+		t.Fatalf("status = %s, want a recording ID", payload)
+	}
+
+	if err := writeClientFrame(conn, ws.OpBinary, []byte("echo recorded-$((40+2))\n")); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	for {
+		opcode, out, err := readServerFrame(conn)
+		if err != nil {
+			t.Fatalf("waiting for echo: %v", err)
+		}
+		if opcode == ws.OpBinary && strings.Contains(string(out), "recorded-42") {
+			break
+		}
+	}
+	_ = conn.Close()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		info, err := recordings.Stat(id)
+		if err != nil {
+			t.Fatalf("Stat(%s) error = %v", id, err)
+		}
+		file, err := recordings.Open(id)
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", id, err)
+		}
+		raw, _ := io.ReadAll(file)
+		_ = file.Close()
+		cast := string(raw)
+		if strings.Contains(cast, `"i","echo recorded-$((40+2))\n"`) && strings.Contains(cast, "recorded-42") {
+			if info.Session != testSessionName || info.Width != 90 || info.Height != 30 {
+				t.Fatalf("info = %+v", info)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("recording is missing the input or output:\n%s", cast)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
 func TestAttachEventsWSAllowsConcurrentSubscribers(t *testing.T) {
 	t.Parallel()
 
@@ -644,6 +726,48 @@ func TestAttachEventsWSAllowsConcurrentSubscribers(t *testing.T) {
 	}
 }
 
+func TestAttachEventsWSReplaysSinceCursor(t *testing.T) {
+	t.Parallel()
+
+	hub := events.NewHub()
+	for range 3 {
+		hub.Publish(events.NewEvent(events.TypeOpsServices, nil))
+	}
+	h := &Handler{
+		guard:  security.New("", nil, security.CookieSecureAuto),
+		events: hub,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(h.attachEventsWS))
+	defer srv.Close()
+
+	conn := dialWebSocketPath(t, srv.URL, "/ws/events?since=1")
+	defer func() { _ = conn.Close() }()
+
+	readEvent := func() events.Event {
+		t.Helper()
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, payload, err := readServerFrame(conn)
+		if err != nil {
+			t.Fatalf("readServerFrame error = %v", err)
+		}
+		var evt events.Event
+		if err := json.Unmarshal(payload, &evt); err != nil {
+			t.Fatalf("payload is not JSON: %v", err)
+		}
+		return evt
+	}
+
+	ready := readEvent()
+	if ready.Type != events.TypeReady || ready.Payload["replayed"] != float64(2) || ready.Payload["replayComplete"] != true {
+		t.Fatalf("unexpected ready payload: %+v", ready)
+	}
+	for _, want := range []int64{2, 3} {
+		if evt := readEvent(); evt.EventID != want {
+			t.Fatalf("replayed eventId = %d, want %d", evt.EventID, want)
+		}
+	}
+}
+
 func TestHandleEventsClientMessagePresence(t *testing.T) {
 	t.Parallel()
 
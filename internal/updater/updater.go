@@ -5,7 +5,9 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -20,7 +22,6 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 )
 
@@ -48,6 +49,47 @@ const (
 	maxChecksumSize     = int64(1 * 1024 * 1024)   // 1 MiB cap on the downloaded checksums file.
 )
 
+// Release channels.
+const (
+	// ChannelStable follows the latest published stable release.
+	ChannelStable = "stable"
+	// ChannelPrerelease follows the newest release, prereleases included.
+	ChannelPrerelease = "prerelease"
+)
+
+// prereleaseListSize is how many recent releases the prerelease channel
+// compares.
+const prereleaseListSize = 30
+
+// ErrInvalidChannel is returned for an unknown release channel.
+var ErrInvalidChannel = errors.New(`update channel must be "stable" or "prerelease"`)
+
+// ErrInvalidPublicKey is returned for a release signing key that is not a
+// base64-encoded ed25519 public key.
+var ErrInvalidPublicKey = errors.New("update public key must be a base64-encoded ed25519 public key")
+
+// ParsePublicKey decodes a base64-encoded ed25519 release signing key.
+func ParsePublicKey(raw string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// NormalizeChannel lowercases a release channel; empty selects stable.
+func NormalizeChannel(raw string) (string, error) {
+	channel := strings.ToLower(strings.TrimSpace(raw))
+	switch channel {
+	case "":
+		return ChannelStable, nil
+	case ChannelStable, ChannelPrerelease:
+		return channel, nil
+	default:
+		return "", ErrInvalidChannel
+	}
+}
+
 // healthCheckDelay is how long to wait after restarting the service before
 // confirming the updated binary stayed healthy.
 const healthCheckDelay = 5 * time.Second
@@ -63,22 +105,30 @@ type CheckOptions struct {
 	CurrentVersion string
 	Repo           string
 	APIBaseURL     string
+	Channel        string
 	OS             string
 	Arch           string
 	DataDir        string
-	HTTPClient     *http.Client
+	// PublicKey, when set, requires the release checksums file to carry a
+	// valid ed25519 signature by this base64-encoded key.
+	PublicKey  string
+	HTTPClient *http.Client
 }
 
 // CheckResult represents check result data.
 type CheckResult struct {
 	CurrentVersion string
 	LatestVersion  string
+	Channel        string
 	UpToDate       bool
 	ReleaseURL     string
 	AssetName      string
 	AssetURL       string
 	ExpectedSHA256 string
-	CheckedAt      time.Time
+	// SignatureVerified reports that ExpectedSHA256 comes from a checksums
+	// file signed by CheckOptions.PublicKey.
+	SignatureVerified bool
+	CheckedAt         time.Time
 }
 
 // ApplyOptions represents apply options data.
@@ -86,6 +136,7 @@ type ApplyOptions struct {
 	CurrentVersion  string
 	Repo            string
 	APIBaseURL      string
+	Channel         string
 	OS              string
 	Arch            string
 	DataDir         string
@@ -96,6 +147,7 @@ type ApplyOptions struct {
 	SkipRestart     bool
 	ServiceUnit     string
 	SystemdScope    string // user, system, launchd, none
+	PublicKey       string
 	HTTPClient      *http.Client
 }
 
@@ -127,6 +179,7 @@ type State struct {
 type release struct {
 	TagName string `json:"tag_name"`
 	HTMLURL string `json:"html_url"`
+	Draft   bool   `json:"draft"`
 	Assets  []asset
 }
 
@@ -146,6 +199,9 @@ var (
 func Check(ctx context.Context, opts CheckOptions) (CheckResult, error) {
 	now := time.Now().UTC()
 	cfg := normalizeCheckOptions(opts)
+	if _, err := NormalizeChannel(opts.Channel); err != nil {
+		return CheckResult{}, err
+	}
 
 	rel, err := fetchLatestRelease(ctx, cfg)
 	if err != nil {
@@ -168,21 +224,23 @@ func Check(ctx context.Context, opts CheckOptions) (CheckResult, error) {
 		return CheckResult{}, err
 	}
 
-	expectedSHA, err := resolveExpectedArchiveSHA256(ctx, cfg, rel.Assets, latestVersion, archiveName, archiveAsset)
+	expectedSHA, signed, err := resolveExpectedArchiveSHA256(ctx, cfg, rel.Assets, latestVersion, archiveName, archiveAsset)
 	if err != nil {
 		recordStateError(cfg.DataDir, now, cfg.CurrentVersion, err)
 		return CheckResult{}, err
 	}
 
 	result := CheckResult{
-		CurrentVersion: normalizeVersion(cfg.CurrentVersion),
-		LatestVersion:  latestVersion,
-		UpToDate:       isCurrentUpToDate(normalizeVersion(cfg.CurrentVersion), latestVersion),
-		ReleaseURL:     rel.HTMLURL,
-		AssetName:      archiveName,
-		AssetURL:       archiveAsset.BrowserDownloadURL,
-		ExpectedSHA256: expectedSHA,
-		CheckedAt:      now,
+		CurrentVersion:    normalizeVersion(cfg.CurrentVersion),
+		LatestVersion:     latestVersion,
+		Channel:           cfg.Channel,
+		UpToDate:          isCurrentUpToDate(normalizeVersion(cfg.CurrentVersion), latestVersion),
+		ReleaseURL:        rel.HTMLURL,
+		AssetName:         archiveName,
+		AssetURL:          archiveAsset.BrowserDownloadURL,
+		ExpectedSHA256:    expectedSHA,
+		SignatureVerified: signed,
+		CheckedAt:         now,
 	}
 	if result.CurrentVersion == "" {
 		result.CurrentVersion = normalizeVersion(cfg.CurrentVersion)
@@ -264,9 +322,11 @@ func applyCheck(ctx context.Context, cfg ApplyOptions) (CheckResult, error) {
 		CurrentVersion: cfg.CurrentVersion,
 		Repo:           cfg.Repo,
 		APIBaseURL:     cfg.APIBaseURL,
+		Channel:        cfg.Channel,
 		OS:             cfg.OS,
 		Arch:           cfg.Arch,
 		DataDir:        cfg.DataDir,
+		PublicKey:      cfg.PublicKey,
 		HTTPClient:     cfg.HTTPClient,
 	})
 }
@@ -395,6 +455,9 @@ func normalizeCheckOptions(opts CheckOptions) CheckOptions {
 	if cfg.APIBaseURL == "" {
 		cfg.APIBaseURL = defaultAPIBase
 	}
+	if channel, err := NormalizeChannel(cfg.Channel); err == nil {
+		cfg.Channel = channel
+	}
 	cfg.OS = strings.TrimSpace(cfg.OS)
 	if cfg.OS == "" {
 		cfg.OS = runtime.GOOS
@@ -417,6 +480,9 @@ func normalizeApplyOptions(opts ApplyOptions) ApplyOptions {
 	if cfg.APIBaseURL == "" {
 		cfg.APIBaseURL = defaultAPIBase
 	}
+	if channel, err := NormalizeChannel(cfg.Channel); err == nil {
+		cfg.Channel = channel
+	}
 	cfg.OS = strings.TrimSpace(cfg.OS)
 	if cfg.OS == "" {
 		cfg.OS = runtime.GOOS
@@ -600,7 +666,11 @@ func launchdJobTarget(scope, unit string) string {
 }
 
 func fetchLatestRelease(ctx context.Context, cfg CheckOptions) (release, error) {
-	url := fmt.Sprintf("%s/repos/%s/releases/latest", strings.TrimRight(cfg.APIBaseURL, "/"), cfg.Repo)
+	base := strings.TrimRight(cfg.APIBaseURL, "/")
+	if cfg.Channel == ChannelPrerelease {
+		return fetchNewestRelease(ctx, cfg.HTTPClient, fmt.Sprintf("%s/repos/%s/releases?per_page=%d", base, cfg.Repo, prereleaseListSize))
+	}
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", base, cfg.Repo)
 	var rel release
 	if err := fetchJSON(ctx, cfg.HTTPClient, url, &rel); err != nil {
 		return release{}, err
@@ -608,6 +678,33 @@ func fetchLatestRelease(ctx context.Context, cfg CheckOptions) (release, error)
 	return rel, nil
 }
 
+// fetchNewestRelease returns the highest-versioned published release in the
+// list at url, prereleases included. GitHub orders the list by creation
+// date, which need not match version order.
+func fetchNewestRelease(ctx context.Context, client *http.Client, url string) (release, error) {
+	var releases []release
+	if err := fetchJSON(ctx, client, url, &releases); err != nil {
+		return release{}, err
+	}
+	var newest release
+	for _, rel := range releases {
+		if rel.Draft || normalizeVersion(rel.TagName) == "" {
+			continue
+		}
+		if newest.TagName == "" || compareVersions(rel.TagName, newest.TagName) > 0 {
+			newest = rel
+		}
+	}
+	if newest.TagName == "" {
+		return release{}, errors.New("no published releases found")
+	}
+	return newest, nil
+}
+
+// resolveExpectedArchiveSHA256 returns the archive checksum and whether it
+// comes from a signed checksums file. Without a public key, the asset digest
+// GitHub reports is preferred. With one, only the signed checksums file is
+// trusted, and a missing or bad signature fails the check.
 func resolveExpectedArchiveSHA256(
 	ctx context.Context,
 	cfg CheckOptions,
@@ -615,25 +712,63 @@ func resolveExpectedArchiveSHA256(
 	version string,
 	archiveName string,
 	archiveAsset asset,
-) (string, error) {
-	if digest := parseSHA256Digest(archiveAsset.Digest); digest != "" {
-		return digest, nil
+) (string, bool, error) {
+	signing := strings.TrimSpace(cfg.PublicKey) != ""
+	if digest := parseSHA256Digest(archiveAsset.Digest); digest != "" && !signing {
+		return digest, false, nil
 	}
 
 	checksumAsset, ok := findChecksumAsset(assets, version)
 	if !ok {
-		return "", nil
+		if signing {
+			return "", false, errors.New("release does not provide a checksums file; refusing unsigned update")
+		}
+		return "", false, nil
 	}
 
 	checksumRaw, err := downloadToString(ctx, cfg.HTTPClient, checksumAsset.BrowserDownloadURL)
 	if err != nil {
-		return "", fmt.Errorf("download checksum file: %w", err)
+		return "", false, fmt.Errorf("download checksum file: %w", err)
+	}
+	if signing {
+		if err := verifyChecksumSignature(ctx, cfg, assets, checksumAsset, checksumRaw); err != nil {
+			return "", false, err
+		}
 	}
 	checksums := parseChecksums(checksumRaw)
 	if sum, ok := checksums[archiveName]; ok {
-		return strings.ToLower(sum), nil
+		return strings.ToLower(sum), signing, nil
+	}
+	return "", false, fmt.Errorf("checksum for %s not found in %s", archiveName, checksumAsset.Name)
+}
+
+// verifyChecksumSignature checks the detached ed25519 signature of the
+// checksums file, published next to it as <name>.sig in raw or base64 form.
+func verifyChecksumSignature(ctx context.Context, cfg CheckOptions, assets []asset, checksumAsset asset, checksums string) error {
+	key, err := ParsePublicKey(cfg.PublicKey)
+	if err != nil {
+		return err
 	}
-	return "", fmt.Errorf("checksum for %s not found in %s", archiveName, checksumAsset.Name)
+	sigName := checksumAsset.Name + ".sig"
+	sigAsset, ok := findAssetByName(assets, sigName)
+	if !ok {
+		return fmt.Errorf("release does not provide %s; refusing unsigned update", sigName)
+	}
+	raw, err := downloadToString(ctx, cfg.HTTPClient, sigAsset.BrowserDownloadURL)
+	if err != nil {
+		return fmt.Errorf("download checksum signature: %w", err)
+	}
+	sig := []byte(raw)
+	if len(sig) != ed25519.SignatureSize {
+		sig, err = base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+		if err != nil || len(sig) != ed25519.SignatureSize {
+			return fmt.Errorf("%s is not an ed25519 signature", sigName)
+		}
+	}
+	if !ed25519.Verify(key, []byte(checksums), sig) {
+		return fmt.Errorf("signature check failed for %s", checksumAsset.Name)
+	}
+	return nil
 }
 
 func findAssetByName(assets []asset, name string) (asset, bool) {
@@ -1139,11 +1274,11 @@ func withUpdateLock(dataDir string, fn func() error) error {
 	}
 	defer func() { _ = f.Close() }()
 
-	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+	if err := lockFile(f); err != nil {
 		return errors.New("another sentinel update is already running")
 	}
 	defer func() {
-		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = unlockFile(f)
 	}()
 
 	return fn()
@@ -2,8 +2,12 @@ package updater
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -184,3 +188,66 @@ func TestInstallBinaryRestoresOriginalOnChmodFailure(t *testing.T) {
 		t.Fatalf("after chmod failure exec path = %q, want original old binary restored", got)
 	}
 }
+
+// TestCheckVerifiesChecksumSignature confirms that with a public key the
+// checksum comes from the signed checksums file only, and that a bad or
+// missing signature fails the check.
+func TestCheckVerifiesChecksumSignature(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archiveName := "sentinel-1.3.0-linux-amd64.tar.gz"
+	checksums := fmt.Sprintf("%s  %s\n", strings.Repeat("c", 64), archiveName)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(checksums)))
+
+	newServer := func(served, sig string) *httptest.Server {
+		var serverURL string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case latestReleasePath:
+				assets := []map[string]any{
+					// The digest GitHub reports is not signed, so it is ignored.
+					{"name": archiveName, "browser_download_url": serverURL + "/assets/archive", "digest": "sha256:" + strings.Repeat("a", 64)},
+					{"name": "sentinel-1.3.0-checksums.txt", "browser_download_url": serverURL + checksumAssetPath},
+				}
+				if sig != "" {
+					assets = append(assets, map[string]any{"name": "sentinel-1.3.0-checksums.txt.sig", "browser_download_url": serverURL + "/assets/sig"})
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"tag_name": "v1.3.0", "assets": assets})
+			case checksumAssetPath:
+				_, _ = io.WriteString(w, served)
+			case "/assets/sig":
+				_, _ = io.WriteString(w, sig+"\n")
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		serverURL = ts.URL
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	check := func(ts *httptest.Server) (CheckResult, error) {
+		return Check(context.Background(), CheckOptions{
+			CurrentVersion: "1.2.0",
+			APIBaseURL:     ts.URL,
+			OS:             "linux",
+			Arch:           "amd64",
+			PublicKey:      base64.StdEncoding.EncodeToString(pub),
+		})
+	}
+
+	res, err := check(newServer(checksums, signature))
+	if err != nil || !res.SignatureVerified || res.ExpectedSHA256 != strings.Repeat("c", 64) {
+		t.Fatalf("Check() = %+v, %v; want the signed checksum", res, err)
+	}
+	tampered := strings.Replace(checksums, "c", "d", 1)
+	if _, err := check(newServer(tampered, signature)); err == nil || !strings.Contains(err.Error(), "signature check failed") {
+		t.Fatalf("Check() with tampered checksums error = %v", err)
+	}
+	if _, err := check(newServer(checksums, "")); err == nil || !strings.Contains(err.Error(), "refusing unsigned update") {
+		t.Fatalf("Check() without a signature error = %v", err)
+	}
+}
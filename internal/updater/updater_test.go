@@ -223,6 +223,62 @@ func TestCheckUsesDigestWhenAvailable(t *testing.T) {
 	}
 }
 
+func TestCheckPrereleaseChannelPicksNewestPublishedRelease(t *testing.T) {
+	t.Parallel()
+
+	digest := strings.Repeat("c", 64)
+	releaseJSON := func(tag string, draft bool) map[string]any {
+		version := strings.TrimPrefix(tag, "v")
+		return map[string]any{
+			"tag_name": tag,
+			"draft":    draft,
+			"assets": []map[string]any{{
+				"name":                 "sentinel-" + version + "-linux-amd64.tar.gz",
+				"browser_download_url": "https://example.invalid/" + version,
+				"digest":               "sha256:" + digest,
+			}},
+		}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/opus-domini/sentinel/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			releaseJSON("v1.4.0-rc.1", false),
+			releaseJSON("v1.5.0", true),
+			releaseJSON("v1.3.2", false),
+		})
+	}))
+	defer ts.Close()
+
+	res, err := Check(context.Background(), CheckOptions{
+		CurrentVersion: "1.3.2",
+		APIBaseURL:     ts.URL,
+		Channel:        "Prerelease",
+		OS:             "linux",
+		Arch:           "amd64",
+	})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if res.LatestVersion != "1.4.0-rc.1" || res.UpToDate {
+		t.Fatalf("Check() = %+v, want 1.4.0-rc.1 available", res)
+	}
+	if res.Channel != ChannelPrerelease {
+		t.Fatalf("Channel = %q, want %q", res.Channel, ChannelPrerelease)
+	}
+}
+
+func TestCheckRejectsUnknownChannel(t *testing.T) {
+	t.Parallel()
+
+	_, err := Check(context.Background(), CheckOptions{Channel: "nightly"})
+	if !errors.Is(err, ErrInvalidChannel) {
+		t.Fatalf("Check() error = %v, want ErrInvalidChannel", err)
+	}
+}
+
 func TestCheckUsesChecksumAssetWhenDigestMissing(t *testing.T) {
 	t.Parallel()
 
@@ -2,7 +2,9 @@
 package validate
 
 import (
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"regexp"
 	"strings"
 	"time"
@@ -55,6 +57,54 @@ func IconKey(key string) bool {
 	return iconKeyRE.MatchString(key)
 }
 
+var sessionTagRE = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,31}$`)
+
+// SessionTag reports whether tag is a valid session tag.
+func SessionTag(tag string) bool {
+	return sessionTagRE.MatchString(tag)
+}
+
+// BufferName reports whether name is a valid tmux paste buffer name. It
+// follows the session name rules, which admit tmux's own "bufferNNNN" names.
+func BufferName(name string) bool {
+	return sessionNameRE.MatchString(name)
+}
+
+var envNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+// EnvName reports whether name is a valid environment variable name.
+func EnvName(name string) bool {
+	return envNameRE.MatchString(name)
+}
+
+var hostNameRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$`)
+
+// HostName reports whether name is a valid federation host name.
+func HostName(name string) bool {
+	return hostNameRE.MatchString(name)
+}
+
+var sshAddressRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.:_-]{0,252}$`)
+
+// SSHAddress reports whether addr is a hostname or IP address safe to pass
+// to ssh: it cannot be read as an option or carry a user or port.
+func SSHAddress(addr string) bool {
+	return sshAddressRE.MatchString(addr)
+}
+
+var loginNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9._-]{0,31}$`)
+
+// LoginName reports whether name is a valid remote login user.
+func LoginName(name string) bool {
+	return loginNameRE.MatchString(name)
+}
+
+// SessionGroup reports whether group is a valid session group name. It
+// follows the window name rules.
+func SessionGroup(group string) bool {
+	return windowNameRE.MatchString(group)
+}
+
 var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
 
 // CronExpression validates a 5-field cron expression (or @descriptor).
@@ -77,6 +127,51 @@ func ParseCron(expr string) (cron.Schedule, error) {
 	return cronParser.Parse(expr)
 }
 
+// MinScheduleInterval is the shortest accepted interval schedule.
+const MinScheduleInterval = time.Minute
+
+// IntervalSchedule fires every Every, delayed by a random amount in
+// [0, Jitter) each time. It satisfies cron.Schedule.
+type IntervalSchedule struct {
+	Every  time.Duration
+	Jitter time.Duration
+}
+
+// Next returns the next activation time after t.
+func (s IntervalSchedule) Next(t time.Time) time.Time {
+	next := t.Add(s.Every)
+	if s.Jitter > 0 {
+		next = next.Add(rand.N(s.Jitter)) //nolint:gosec // jitter does not need a CSPRNG.
+	}
+	return next
+}
+
+// ParseInterval parses an interval schedule from Go duration strings
+// ("15m", "1h30m"). The interval must be at least MinScheduleInterval and
+// the optional jitter must be shorter than the interval.
+func ParseInterval(every, jitter string) (IntervalSchedule, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(every))
+	if err != nil {
+		return IntervalSchedule{}, fmt.Errorf("invalid interval: %w", err)
+	}
+	if d < MinScheduleInterval {
+		return IntervalSchedule{}, fmt.Errorf("invalid interval: must be at least %s", MinScheduleInterval)
+	}
+	sched := IntervalSchedule{Every: d}
+	if strings.TrimSpace(jitter) == "" {
+		return sched, nil
+	}
+	j, err := time.ParseDuration(strings.TrimSpace(jitter))
+	if err != nil {
+		return IntervalSchedule{}, fmt.Errorf("invalid jitter: %w", err)
+	}
+	if j < 0 || j >= d {
+		return IntervalSchedule{}, errors.New("invalid jitter: must be between 0 and the interval")
+	}
+	sched.Jitter = j
+	return sched, nil
+}
+
 // Timezone validates an IANA timezone string.
 func Timezone(tz string) error {
 	_, err := time.LoadLocation(tz)
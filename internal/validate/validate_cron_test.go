@@ -1,6 +1,9 @@
 package validate
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestCronExpression(t *testing.T) {
 	t.Parallel()
@@ -121,3 +124,54 @@ func TestTimezone(t *testing.T) {
 		})
 	}
 }
+
+func TestParseInterval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		every      string
+		jitter     string
+		wantEvery  time.Duration
+		wantJitter time.Duration
+		wantErr    bool
+	}{
+		{name: "minutes", every: "15m", wantEvery: 15 * time.Minute},
+		{name: "with_jitter", every: "1h", jitter: "5m", wantEvery: time.Hour, wantJitter: 5 * time.Minute},
+		{name: "padded", every: " 2h30m ", jitter: " ", wantEvery: 150 * time.Minute},
+		{name: "too_short", every: "30s", wantErr: true},
+		{name: "garbage", every: "often", wantErr: true},
+		{name: "empty", every: "", wantErr: true},
+		{name: "jitter_not_shorter", every: "10m", jitter: "10m", wantErr: true},
+		{name: "negative_jitter", every: "10m", jitter: "-1m", wantErr: true},
+		{name: "bad_jitter", every: "10m", jitter: "abit", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseInterval(tt.every, tt.jitter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseInterval(%q, %q) error = %v, wantErr %v", tt.every, tt.jitter, err, tt.wantErr)
+			}
+			if err == nil && (got.Every != tt.wantEvery || got.Jitter != tt.wantJitter) {
+				t.Fatalf("ParseInterval(%q, %q) = %+v", tt.every, tt.jitter, got)
+			}
+		})
+	}
+}
+
+func TestIntervalScheduleNextStaysWithinJitter(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	sched := IntervalSchedule{Every: 15 * time.Minute, Jitter: time.Minute}
+	for range 50 {
+		next := sched.Next(base)
+		if next.Before(base.Add(15*time.Minute)) || !next.Before(base.Add(16*time.Minute)) {
+			t.Fatalf("Next = %s, want within [08:15, 08:16)", next)
+		}
+	}
+	if got := (IntervalSchedule{Every: time.Hour}).Next(base); !got.Equal(base.Add(time.Hour)) {
+		t.Fatalf("Next without jitter = %s", got)
+	}
+}
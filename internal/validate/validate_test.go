@@ -88,6 +88,135 @@ func TestIconKey(t *testing.T) {
 	}
 }
 
+func TestHostName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"short hostname", "web-01", true},
+		{"fqdn", "db1.prod.example.com", true},
+		{"max length 63", strings.Repeat("a", 63), true},
+
+		{"empty", "", false},
+		{"too long 64", strings.Repeat("a", 64), false},
+		{"leading dot", ".web", false},
+		{"with slash", "web/01", false},
+		{"with space", "web 01", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := HostName(tt.input); got != tt.want {
+				t.Errorf("HostName(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSHAddressAndLoginName(t *testing.T) {
+	t.Parallel()
+
+	addresses := map[string]bool{
+		"db-01.internal": true,
+		"10.0.0.12":      true,
+		"fd00::12":       true,
+		"":               false,
+		"-oProxyCommand": false,
+		"deploy@db-01":   false,
+		"db 01":          false,
+	}
+	for addr, want := range addresses {
+		if got := SSHAddress(addr); got != want {
+			t.Errorf("SSHAddress(%q) = %v, want %v", addr, got, want)
+		}
+	}
+	names := map[string]bool{
+		"deploy":    true,
+		"_svc":      true,
+		"ci.bot":    true,
+		"":          false,
+		"-l":        false,
+		"root user": false,
+	}
+	for name, want := range names {
+		if got := LoginName(name); got != want {
+			t.Errorf("LoginName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestSessionTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"lowercase", "work", true},
+		{"with separators", "client.acme_v2-x", true},
+		{"max length 32", strings.Repeat("a", 32), true},
+
+		{"empty", "", false},
+		{"too long 33", strings.Repeat("a", 33), false},
+		{"uppercase", "Work", false},
+		{"leading hyphen", "-work", false},
+		{"with space", "my tag", false},
+		{"with comma", "a,b", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := SessionTag(tt.input); got != tt.want {
+				t.Errorf("SessionTag(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBufferName(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]bool{
+		"buffer0001": true,
+		"clip.web_1": true,
+		"":           false,
+		"-b":         false,
+		"has space":  false,
+		"semi;colon": false,
+		"new\nline":  false,
+	} {
+		if got := BufferName(input); got != want {
+			t.Errorf("BufferName(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]bool{
+		"API_KEY":                true,
+		"_private":               true,
+		"path2":                  true,
+		"":                       false,
+		"2FA":                    false,
+		"-u":                     false,
+		"A=B":                    false,
+		"WITH SPACE":             false,
+		strings.Repeat("A", 129): false,
+	} {
+		if got := EnvName(input); got != want {
+			t.Errorf("EnvName(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
 func TestWindowName(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,113 @@
+package watchtower
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultIdleTicks   = 10
+	defaultMaxInterval = 10 * time.Second
+)
+
+// idleBackoff decides which sessions an adaptive tick collects. A session
+// unchanged for idleTicks collections is collected at a doubling interval,
+// up to maxInterval; a change, new tmux activity or a client viewing the
+// session brings it back to every tick. A nil idleBackoff collects every
+// session on every tick.
+type idleBackoff struct {
+	base      time.Duration
+	max       time.Duration
+	idleTicks int
+
+	mu       sync.Mutex
+	sessions map[string]*sessionBackoff
+}
+
+type sessionBackoff struct {
+	unchanged  int
+	interval   time.Duration // 0 while the session is collected every tick
+	nextAt     time.Time
+	activityAt time.Time
+}
+
+func newIdleBackoff(options Options) *idleBackoff {
+	if !options.Adaptive {
+		return nil
+	}
+	return &idleBackoff{
+		base:      options.TickInterval,
+		max:       options.MaxInterval,
+		idleTicks: options.IdleTicks,
+		sessions:  make(map[string]*sessionBackoff),
+	}
+}
+
+// due reports whether the session is collected at now. tmux activity since
+// the last collection makes it due at once.
+func (b *idleBackoff) due(key string, activityAt, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.sessions[key]
+	if st == nil || st.interval == 0 || !activityAt.Equal(st.activityAt) {
+		return true
+	}
+	return !now.Before(st.nextAt)
+}
+
+// observe records a collection of the session at now.
+func (b *idleBackoff) observe(key string, changed bool, activityAt, now time.Time) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.sessions[key]
+	if st == nil {
+		st = &sessionBackoff{}
+		b.sessions[key] = st
+	}
+	st.activityAt = activityAt
+	if changed {
+		st.unchanged = 0
+		st.interval = 0
+		return
+	}
+	st.unchanged++
+	if st.unchanged < b.idleTicks {
+		return
+	}
+	st.interval = min(max(2*st.interval, 2*b.base), b.max)
+	st.nextAt = now.Add(st.interval)
+}
+
+// wake puts the session back on every tick.
+func (b *idleBackoff) wake(key string, activityAt time.Time) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sessions[key] = &sessionBackoff{activityAt: activityAt}
+}
+
+// retain forgets the sessions not in live.
+func (b *idleBackoff) retain(live map[string]bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key := range b.sessions {
+		if !live[key] {
+			delete(b.sessions, key)
+		}
+	}
+}
+
+func backoffKey(ts taggedSession) string {
+	return ts.user + "\x00" + ts.Name
+}
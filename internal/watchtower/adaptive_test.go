@@ -0,0 +1,140 @@
+package watchtower
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestIdleBackoffDoublesToMaxInterval(t *testing.T) {
+	t.Parallel()
+
+	b := newIdleBackoff(Options{Adaptive: true, TickInterval: time.Second, IdleTicks: 2, MaxInterval: 5 * time.Second})
+	now := time.Unix(1000, 0)
+	activity := now.Add(-time.Hour)
+
+	var intervals []time.Duration
+	for range 5 {
+		b.observe("dev", false, activity, now)
+		intervals = append(intervals, b.sessions["dev"].interval)
+	}
+	want := []time.Duration{0, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i := range want {
+		if intervals[i] != want[i] {
+			t.Fatalf("intervals = %v, want %v", intervals, want)
+		}
+	}
+	if b.due("dev", activity, now.Add(time.Second)) {
+		t.Fatal("idle session due before its interval")
+	}
+	if !b.due("dev", activity, now.Add(5*time.Second)) {
+		t.Fatal("idle session not due after its interval")
+	}
+	if !b.due("dev", activity.Add(time.Second), now) {
+		t.Fatal("session with new tmux activity not due")
+	}
+
+	b.observe("dev", true, activity, now)
+	if !b.due("dev", activity, now) {
+		t.Fatal("changed session not back on every tick")
+	}
+	b.retain(map[string]bool{})
+	if len(b.sessions) != 0 {
+		t.Fatalf("retain kept %v", b.sessions)
+	}
+	if !(*idleBackoff)(nil).due("dev", activity, now) {
+		t.Fatal("nil backoff skipped a session")
+	}
+}
+
+func TestAdaptiveCollectSkipsIdleSessions(t *testing.T) {
+	t.Parallel()
+
+	st := newWatchtowerTestStore(t)
+	defer func() { _ = st.Close() }()
+	ctx := context.Background()
+
+	var activity atomic.Int64
+	activity.Store(time.Now().UTC().Truncate(time.Second).Unix())
+	var listed atomic.Int32
+	fake := fakeTmux{
+		listSessionsFn: func(context.Context) ([]tmux.Session, error) {
+			at := time.Unix(activity.Load(), 0).UTC()
+			return []tmux.Session{{Name: "dev", Windows: 1, CreatedAt: at, ActivityAt: at}}, nil
+		},
+		listWindowsFn: func(context.Context, string) ([]tmux.Window, error) {
+			listed.Add(1)
+			return []tmux.Window{{Session: "dev", Index: 0, Name: "main", Active: true, Panes: 1}}, nil
+		},
+		listPanesFn: func(context.Context, string) ([]tmux.Pane, error) {
+			return []tmux.Pane{{Session: "dev", PaneID: "%1", Active: true}}, nil
+		},
+		capturePaneLinesFn: func(context.Context, string, int) (string, error) {
+			return "idle prompt", nil
+		},
+	}
+	svc := New(st, fake, Options{
+		TickInterval: time.Hour,
+		Adaptive:     true,
+		IdleTicks:    2,
+		MaxInterval:  4 * time.Hour,
+	})
+
+	// tick collects once and reports whether the session was collected.
+	tick := func() bool {
+		t.Helper()
+		before := listed.Load()
+		if err := svc.collect(ctx); err != nil {
+			t.Fatalf("collect: %v", err)
+		}
+		return listed.Load() > before
+	}
+
+	for i := range 3 {
+		if !tick() {
+			t.Fatalf("tick %d skipped before the session went idle", i+1)
+		}
+	}
+	if tick() {
+		t.Fatal("idle session collected again before max interval")
+	}
+	if skipped, _ := st.GetWatchtowerRuntimeValue(ctx, runtimeLastCollectSkipKey); skipped != "1" {
+		t.Fatalf("%s = %q, want 1", runtimeLastCollectSkipKey, skipped)
+	}
+	if _, err := st.GetWatchtowerSession(ctx, "dev"); err != nil {
+		t.Fatalf("skipped session was purged: %v", err)
+	}
+
+	presence := store.WatchtowerPresenceWrite{
+		TerminalID:  "term-1",
+		SessionName: "dev",
+		PaneID:      "%1",
+		Visible:     true,
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+	if err := st.UpsertWatchtowerPresence(ctx, presence); err != nil {
+		t.Fatalf("UpsertWatchtowerPresence: %v", err)
+	}
+	if !tick() {
+		t.Fatal("viewed session skipped")
+	}
+	presence.Visible = false
+	if err := st.UpsertWatchtowerPresence(ctx, presence); err != nil {
+		t.Fatalf("UpsertWatchtowerPresence(hidden): %v", err)
+	}
+	for range 2 {
+		tick()
+	}
+	if tick() {
+		t.Fatal("session collected again after the viewer left and it went idle")
+	}
+
+	activity.Add(1)
+	if !tick() {
+		t.Fatal("session with new tmux activity skipped")
+	}
+}
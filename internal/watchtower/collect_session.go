@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"log/slog"
+	"sort"
 	"strings"
 	"time"
 
@@ -45,9 +47,16 @@ type collectSessionState struct {
 	anyPaneChanged       bool
 	anyWindowChanged     bool
 	activeWindowSwitched bool
+
+	diff store.WatchtowerSessionDiff
+	// matches are the watch rules matching new pane output.
+	matches []watchMatch
+	// exits are the panes that died since the last collection.
+	exits []paneExit
 }
 
 type paneTailSnapshot struct {
+	raw        string // full capture, empty when the capture failed
 	preview    string
 	hash       string
 	capturedAt time.Time
@@ -145,6 +154,8 @@ func (s *Service) prepareCollectSessionState(ctx context.Context, ts taggedSessi
 		bestPreview:       strings.TrimSpace(existingSession.LastPreview),
 		bestPreviewAt:     existingSession.LastPreviewAt,
 		bestPreviewPaneID: strings.TrimSpace(existingSession.LastPreviewPaneID),
+
+		diff: store.WatchtowerSessionDiff{SessionName: name},
 	}
 	return state, true, nil
 }
@@ -206,32 +217,24 @@ func (s *Service) loadFocusedPanes(ctx context.Context, sessionName string, now
 	return focused, nil
 }
 
-func (c *collectSessionState) collect() (bool, error) {
-	if err := c.collectPanes(); err != nil {
-		return false, err
-	}
-	if err := c.purgePanes(); err != nil {
-		return false, err
-	}
-	if err := c.collectWindows(); err != nil {
-		return false, err
-	}
-	if err := c.purgeWindows(); err != nil {
-		return false, err
-	}
-	return c.persistSession()
+// collect computes the session diff against the stored projection. Nothing
+// is written here: the diffs of every session are applied together at the
+// end of the tick.
+func (c *collectSessionState) collect() bool {
+	c.collectPanes()
+	c.collectRemovedPanes()
+	c.collectWindows()
+	c.collectRemovedWindows()
+	return c.projectSession()
 }
 
-func (c *collectSessionState) collectPanes() error {
+func (c *collectSessionState) collectPanes() {
 	for _, pane := range c.panes {
-		if err := c.collectPane(pane); err != nil {
-			return err
-		}
+		c.collectPane(pane)
 	}
-	return nil
 }
 
-func (c *collectSessionState) collectPane(pane tmux.Pane) error {
+func (c *collectSessionState) collectPane(pane tmux.Pane) {
 	rawPaneID := pane.PaneID
 	qualifiedID := qualifyPaneID(c.user, rawPaneID)
 
@@ -245,16 +248,24 @@ func (c *collectSessionState) collectPane(pane tmux.Pane) error {
 	qualifiedPane := pane
 	qualifiedPane.PaneID = qualifiedID
 
+	c.matches = append(c.matches, c.service.watch.match(c.watchKey(), c.name, rawPaneID, qualifiedID, tail.raw)...)
+	if exit, ok := c.service.exits.observe(c.watchKey(), c.name, qualifiedID, pane); ok {
+		c.exits = append(c.exits, exit)
+	}
 	c.updateWindowAggregate(pane.WindowIndex, revision)
 	c.updateBestPreview(qualifiedID, tail.preview, revision.changedAt)
+	if revision.changed {
+		c.archivePaneOutput(qualifiedID, tail)
+	}
 
-	return c.service.store.UpsertWatchtowerPane(c.ctx, store.WatchtowerPaneWrite{
+	row := store.WatchtowerPaneWrite{
 		PaneID:         qualifiedID,
 		SessionName:    c.name,
 		WindowIndex:    pane.WindowIndex,
 		PaneIndex:      pane.PaneIndex,
 		Title:          pane.Title,
 		Active:         pane.Active,
+		Zoomed:         pane.Zoomed,
 		TTY:            pane.TTY,
 		CurrentPath:    pane.CurrentPath,
 		StartCommand:   pane.StartCommand,
@@ -266,7 +277,46 @@ func (c *collectSessionState) collectPane(pane tmux.Pane) error {
 		SeenRevision:   revision.seenRevision,
 		ChangedAt:      revision.changedAt,
 		UpdatedAt:      c.now,
-	})
+	}
+	if !hadPrev || paneRowChanged(prev, row) {
+		c.diff.Panes = append(c.diff.Panes, row)
+	}
+}
+
+// paneRowChanged reports whether row differs from the stored pane. The
+// capture and update times alone do not count, so an idle pane is not
+// rewritten every tick.
+func paneRowChanged(prev store.WatchtowerPane, row store.WatchtowerPaneWrite) bool {
+	return prev.SessionName != row.SessionName ||
+		prev.WindowIndex != row.WindowIndex ||
+		prev.PaneIndex != row.PaneIndex ||
+		prev.Title != strings.TrimSpace(row.Title) ||
+		prev.Active != row.Active ||
+		prev.Zoomed != row.Zoomed ||
+		prev.TTY != strings.TrimSpace(row.TTY) ||
+		prev.CurrentPath != strings.TrimSpace(row.CurrentPath) ||
+		prev.StartCommand != strings.TrimSpace(row.StartCommand) ||
+		prev.CurrentCommand != strings.TrimSpace(row.CurrentCommand) ||
+		prev.TailHash != strings.TrimSpace(row.TailHash) ||
+		prev.TailPreview != strings.TrimSpace(row.TailPreview) ||
+		prev.Revision != row.Revision ||
+		prev.SeenRevision != row.SeenRevision ||
+		!prev.ChangedAt.Equal(row.ChangedAt)
+}
+
+// watchKey identifies the session to the watch matcher, like backoffKey.
+func (c *collectSessionState) watchKey() string {
+	return c.user + "\x00" + c.name
+}
+
+func (c *collectSessionState) archivePaneOutput(paneID string, tail paneTailSnapshot) {
+	archive := c.service.options.PaneLog
+	if archive == nil || tail.raw == "" {
+		return
+	}
+	if err := archive.Append(c.name, paneID, c.now, tail.raw); err != nil {
+		slog.Warn("watchtower pane log append failed", "session", c.name, "pane", paneID, "err", err)
+	}
 }
 
 func (c *collectSessionState) capturePaneTail(paneID string, prev store.WatchtowerPane, hadPrev bool) paneTailSnapshot {
@@ -277,6 +327,7 @@ func (c *collectSessionState) capturePaneTail(paneID string, prev store.Watchtow
 	cancel()
 
 	if capErr == nil {
+		tail.raw = captured
 		tail.preview = normalizePaneTail(captured)
 		tail.hash = hashPaneTail(tail.preview)
 		tail.capturedAt = c.now
@@ -345,11 +396,22 @@ func (c *collectSessionState) updateBestPreview(paneID, tailPreview string, chan
 	c.bestPreviewPaneID = paneID
 }
 
-func (c *collectSessionState) purgePanes() error {
-	return c.service.store.PurgeWatchtowerPanes(c.ctx, c.name, c.paneIDs)
+func (c *collectSessionState) collectRemovedPanes() {
+	live := make(map[string]bool, len(c.paneIDs))
+	for _, paneID := range c.paneIDs {
+		live[paneID] = true
+	}
+	for paneID := range c.existingPaneByID {
+		if !live[paneID] {
+			c.diff.RemovedPaneIDs = append(c.diff.RemovedPaneIDs, paneID)
+			c.service.watch.forget(c.watchKey(), paneID)
+			c.service.exits.forget(c.watchKey(), paneID)
+		}
+	}
+	sort.Strings(c.diff.RemovedPaneIDs)
 }
 
-func (c *collectSessionState) collectWindows() error {
+func (c *collectSessionState) collectWindows() {
 	for _, win := range c.windows {
 		c.windowIndices = append(c.windowIndices, win.Index)
 
@@ -382,7 +444,10 @@ func (c *collectSessionState) collectWindows() error {
 			windowRev = 1
 		}
 
-		if err := c.service.store.UpsertWatchtowerWindow(c.ctx, store.WatchtowerWindowWrite{
+		if hadPrev && !windowChanged && prev.Rev == windowRev && prev.TmuxWindowID == strings.TrimSpace(win.ID) {
+			continue
+		}
+		c.diff.Windows = append(c.diff.Windows, store.WatchtowerWindowWrite{
 			SessionName:      c.name,
 			TmuxWindowID:     win.ID,
 			WindowIndex:      win.Index,
@@ -394,11 +459,8 @@ func (c *collectSessionState) collectWindows() error {
 			HasUnread:        hasUnread,
 			Rev:              windowRev,
 			UpdatedAt:        c.now,
-		}); err != nil {
-			return err
-		}
+		})
 	}
-	return nil
 }
 
 func (c *collectSessionState) windowProjection(windowIndex int) (int, time.Time, bool, store.WatchtowerWindow) {
@@ -420,11 +482,20 @@ func (c *collectSessionState) windowProjection(windowIndex int) (int, time.Time,
 	return unread, activityAt, hadPrev, prev
 }
 
-func (c *collectSessionState) purgeWindows() error {
-	return c.service.store.PurgeWatchtowerWindows(c.ctx, c.name, c.windowIndices)
+func (c *collectSessionState) collectRemovedWindows() {
+	live := make(map[int]bool, len(c.windowIndices))
+	for _, index := range c.windowIndices {
+		live[index] = true
+	}
+	for index := range c.existingWindowByID {
+		if !live[index] {
+			c.diff.RemovedWindowIndices = append(c.diff.RemovedWindowIndices, index)
+		}
+	}
+	sort.Ints(c.diff.RemovedWindowIndices)
 }
 
-func (c *collectSessionState) persistSession() (bool, error) {
+func (c *collectSessionState) projectSession() bool {
 	sessionRev := int64(0)
 	if c.hasExistingSession {
 		sessionRev = c.existingSession.Rev
@@ -437,7 +508,10 @@ func (c *collectSessionState) persistSession() (bool, error) {
 		sessionRev = 1
 	}
 
-	if err := c.service.store.UpsertWatchtowerSession(c.ctx, store.WatchtowerSessionWrite{
+	if c.hasExistingSession && !sessionChanged && c.existingSession.Rev == sessionRev {
+		return false
+	}
+	c.diff.Session = &store.WatchtowerSessionWrite{
 		SessionName:       c.name,
 		Attached:          c.sess.Attached,
 		Windows:           c.sess.Windows,
@@ -450,11 +524,8 @@ func (c *collectSessionState) persistSession() (bool, error) {
 		UnreadPanes:       c.unreadPanes,
 		Rev:               sessionRev,
 		UpdatedAt:         c.now,
-	}); err != nil {
-		return false, err
 	}
-
-	return sessionChanged, nil
+	return sessionChanged
 }
 
 func (c *collectSessionState) sessionProjectionChanged() bool {
@@ -0,0 +1,204 @@
+package watchtower
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/proc"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+// oomPollInterval is how often the kernel journal is read for OOM kills.
+const oomPollInterval = 15 * time.Second
+
+// Pane exit reasons.
+const (
+	paneExitExited    = "exited"
+	paneExitOOMKilled = "oom-killed"
+)
+
+// ProcessWatcher reports the processes the kernel OOM killer killed and the
+// process tree used to tie them to panes. proc.System satisfies it.
+type ProcessWatcher interface {
+	Table(ctx context.Context) (proc.Table, error)
+	OOMKills(ctx context.Context, since time.Time) ([]proc.OOMKill, error)
+}
+
+// paneExit is a pane whose process exited non-zero or was killed by a
+// signal, or a process of a pane the OOM killer killed.
+type paneExit struct {
+	reason  string
+	session string
+	paneID  string
+	command string
+	status  int
+	signal  int
+	pid     int
+	process string
+}
+
+// paneRef is a live pane as of its session's last collection.
+type paneRef struct {
+	session string
+	paneID  string
+	command string
+	pid     int
+}
+
+// exitWatch tracks dead panes so each exit is reported once, and the pane
+// processes OOM kills are matched against. Panes already dead at the first
+// collection are recorded without being reported.
+type exitWatch struct {
+	primed bool
+	// dead holds the dead panes by session key and pane key.
+	dead  map[string]map[string]bool
+	panes map[string]map[string]paneRef
+
+	// table is the process tree of the last OOM poll, lastKills the kills
+	// it read, which the next poll reads again because journalctl --since
+	// has second resolution.
+	table     proc.Table
+	oomSince  time.Time
+	oomPollAt time.Time
+	lastKills map[proc.OOMKill]bool
+	failing   bool
+}
+
+func newExitWatch() *exitWatch {
+	return &exitWatch{
+		dead:      make(map[string]map[string]bool),
+		panes:     make(map[string]map[string]paneRef),
+		lastKills: make(map[proc.OOMKill]bool),
+	}
+}
+
+// observe records a collected pane and returns its exit when it turned dead
+// with a non-zero status or by a signal since the last collection.
+func (w *exitWatch) observe(sessionKey, session, key string, pane tmux.Pane) (paneExit, bool) {
+	panes := w.panes[sessionKey]
+	if panes == nil {
+		panes = make(map[string]paneRef)
+		w.panes[sessionKey] = panes
+	}
+	dead := w.dead[sessionKey]
+	if !pane.Dead {
+		panes[key] = paneRef{session: session, paneID: pane.PaneID, command: pane.CurrentCommand, pid: pane.PID}
+		delete(dead, key)
+		return paneExit{}, false
+	}
+	delete(panes, key)
+	if dead == nil {
+		dead = make(map[string]bool)
+		w.dead[sessionKey] = dead
+	}
+	if dead[key] {
+		return paneExit{}, false
+	}
+	dead[key] = true
+	if !w.primed || (pane.DeadStatus == 0 && pane.DeadSignal == 0) {
+		return paneExit{}, false
+	}
+	command := pane.StartCommand
+	if command == "" {
+		command = pane.CurrentCommand
+	}
+	return paneExit{
+		reason:  paneExitExited,
+		session: session,
+		paneID:  pane.PaneID,
+		command: command,
+		status:  pane.DeadStatus,
+		signal:  pane.DeadSignal,
+	}, true
+}
+
+// forget drops a pane that went away.
+func (w *exitWatch) forget(sessionKey, key string) {
+	delete(w.dead[sessionKey], key)
+	delete(w.panes[sessionKey], key)
+}
+
+// retain drops the sessions whose keys are not in live and ends the first
+// collection.
+func (w *exitWatch) retain(live map[string]bool) {
+	for key := range w.dead {
+		if !live[key] {
+			delete(w.dead, key)
+		}
+	}
+	for key := range w.panes {
+		if !live[key] {
+			delete(w.panes, key)
+		}
+	}
+	w.primed = true
+}
+
+// pollOOM reads the OOM kills since the last poll, at most every
+// oomPollInterval, and returns the ones that killed a pane process or one
+// of its descendants. Kills are matched against the process tree of the
+// previous poll, so a process started and killed between two polls is not
+// tied to its pane. The first poll only snapshots the tree.
+func (w *exitWatch) pollOOM(ctx context.Context, procs ProcessWatcher, now time.Time) []paneExit {
+	if procs == nil || now.Sub(w.oomPollAt) < oomPollInterval {
+		return nil
+	}
+	w.oomPollAt = now
+
+	var exits []paneExit
+	if !w.oomSince.IsZero() {
+		readCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		kills, err := procs.OOMKills(readCtx, w.oomSince)
+		cancel()
+		if err != nil {
+			if !w.failing && ctx.Err() == nil {
+				slog.Warn("watchtower oom kills read failed", "err", err)
+			}
+			w.failing = true
+			return nil
+		}
+		w.failing = false
+		current := make(map[proc.OOMKill]bool, len(kills))
+		for _, kill := range kills {
+			current[kill] = true
+			if w.lastKills[kill] {
+				continue
+			}
+			if ref, ok := w.paneOf(kill.PID); ok {
+				exits = append(exits, paneExit{
+					reason:  paneExitOOMKilled,
+					session: ref.session,
+					paneID:  ref.paneID,
+					command: ref.command,
+					pid:     kill.PID,
+					process: kill.Process,
+				})
+			}
+		}
+		w.lastKills = current
+	}
+	w.oomSince = now
+
+	tableCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	table, err := procs.Table(tableCtx)
+	cancel()
+	if err != nil {
+		slog.Debug("watchtower process table read failed", "err", err)
+		return exits
+	}
+	w.table = table
+	return exits
+}
+
+// paneOf returns the pane whose process is pid or one of its ancestors.
+func (w *exitWatch) paneOf(pid int) (paneRef, bool) {
+	for _, panes := range w.panes {
+		for _, ref := range panes {
+			if ref.pid == pid || w.table.IsDescendant(ref.pid, pid) {
+				return ref, true
+			}
+		}
+	}
+	return paneRef{}, false
+}
@@ -0,0 +1,114 @@
+package watchtower
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/proc"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestCollectPublishesPaneExits(t *testing.T) {
+	t.Parallel()
+
+	st := newWatchtowerTestStore(t)
+	defer func() { _ = st.Close() }()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	panes := []tmux.Pane{
+		{Session: "dev", WindowIndex: 0, PaneID: "%1", StartCommand: "make build", PID: 100},
+		// Already dead at startup: recorded, not reported.
+		{Session: "dev", WindowIndex: 0, PaneIndex: 1, PaneID: "%2", Dead: true, DeadStatus: 1},
+	}
+	fake := fakeTmux{
+		listSessionsFn: func(context.Context) ([]tmux.Session, error) {
+			return []tmux.Session{{Name: "dev", Windows: 1, CreatedAt: now, ActivityAt: now}}, nil
+		},
+		listWindowsFn: func(context.Context, string) ([]tmux.Window, error) {
+			return []tmux.Window{{Session: "dev", Index: 0, Name: "main", Active: true, Panes: 2}}, nil
+		},
+		listPanesFn: func(context.Context, string) ([]tmux.Pane, error) {
+			return panes, nil
+		},
+		capturePaneLinesFn: func(context.Context, string, int) (string, error) {
+			return "output", nil
+		},
+	}
+
+	var exited []map[string]any
+	svc := New(st, fake, Options{
+		Publish: func(eventType string, payload map[string]any) {
+			if eventType == events.TypeTmuxPaneExit {
+				exited = append(exited, payload)
+			}
+		},
+	})
+
+	if err := svc.collect(context.Background()); err != nil {
+		t.Fatalf("collect #1: %v", err)
+	}
+	if len(exited) != 0 {
+		t.Fatalf("exits after first collect = %v, want none", exited)
+	}
+
+	panes[0].Dead, panes[0].DeadStatus = true, 2
+	for i := range 2 {
+		if err := svc.collect(context.Background()); err != nil {
+			t.Fatalf("collect #%d: %v", i+2, err)
+		}
+	}
+	if len(exited) != 1 {
+		t.Fatalf("exits = %v, want 1", exited)
+	}
+	got := exited[0]
+	if got["action"] != "exited" || got["session"] != "dev" || got["paneId"] != "%1" ||
+		got["command"] != "make build" || got["status"] != 2 || got["signal"] != 0 {
+		t.Fatalf("unexpected exit payload: %+v", got)
+	}
+}
+
+func TestExitWatchPollOOM(t *testing.T) {
+	t.Parallel()
+
+	w := newExitWatch()
+	w.observe("k", "dev", "%1", tmux.Pane{PaneID: "%1", CurrentCommand: "python3", PID: 100})
+	w.observe("k", "dev", "%2", tmux.Pane{PaneID: "%2", CurrentCommand: "zsh", PID: 200})
+
+	at := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	procs := &fakeProcesses{table: proc.Table{100: 1, 101: 100, 102: 101, 200: 1}}
+	start := time.Now()
+
+	// The first poll only snapshots the process tree.
+	if got := w.pollOOM(context.Background(), procs, start); got != nil {
+		t.Fatalf("first poll = %+v, want none", got)
+	}
+	procs.kills = []proc.OOMKill{{At: at, PID: 102, Process: "worker"}, {At: at, PID: 999, Process: "other"}}
+	if got := w.pollOOM(context.Background(), procs, start.Add(time.Second)); got != nil {
+		t.Fatalf("poll within the interval = %+v, want none", got)
+	}
+	got := w.pollOOM(context.Background(), procs, start.Add(oomPollInterval))
+	want := paneExit{reason: "oom-killed", session: "dev", paneID: "%1", command: "python3", pid: 102, process: "worker"}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("poll = %+v, want %+v", got, want)
+	}
+
+	// The journal reports the same kill again on the next poll.
+	if got := w.pollOOM(context.Background(), procs, start.Add(2*oomPollInterval)); got != nil {
+		t.Fatalf("repeated poll = %+v, want none", got)
+	}
+}
+
+type fakeProcesses struct {
+	table proc.Table
+	kills []proc.OOMKill
+}
+
+func (f *fakeProcesses) Table(context.Context) (proc.Table, error) {
+	return f.table, nil
+}
+
+func (f *fakeProcesses) OOMKills(context.Context, time.Time) ([]proc.OOMKill, error) {
+	return f.kills, nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/opus-domini/sentinel/internal/store"
 	"github.com/opus-domini/sentinel/internal/tmux"
 )
 
@@ -195,13 +196,7 @@ func TestCollectMultiUserPaneIDNamespacing(t *testing.T) {
 		user:   "alice",
 	}
 
-	keep, _, _, err := svc.collectSession(context.Background(), ts)
-	if err != nil {
-		t.Fatalf("collectSession: %v", err)
-	}
-	if !keep {
-		t.Fatal("collectSession returned keep=false")
-	}
+	collectTaggedSession(t, svc, ts)
 
 	// Verify pane was stored with qualified ID.
 	panes, err := st.ListWatchtowerPanes(context.Background(), "remote")
@@ -229,6 +224,22 @@ func TestCollectMultiUserPaneIDNamespacing(t *testing.T) {
 	}
 }
 
+// collectTaggedSession collects ts and applies its diff, as one tick would.
+func collectTaggedSession(t *testing.T, svc *Service, ts taggedSession) {
+	t.Helper()
+	result, err := svc.collectSession(context.Background(), ts)
+	if err != nil {
+		t.Fatalf("collectSession: %v", err)
+	}
+	if !result.keep {
+		t.Fatal("collectSession returned keep=false")
+	}
+	summary := collectSummary{activeSessions: []string{ts.Name}, diffs: []store.WatchtowerSessionDiff{result.diff}}
+	if _, err := svc.persistCollect(context.Background(), summary); err != nil {
+		t.Fatalf("persistCollect: %v", err)
+	}
+}
+
 func TestCollectMultiUserCapturePaneUsesRawID(t *testing.T) {
 	t.Parallel()
 
@@ -283,10 +294,7 @@ func TestCollectMultiUserCapturePaneUsesRawID(t *testing.T) {
 		user:   "bob",
 	}
 
-	_, _, _, err := svc.collectSession(context.Background(), ts)
-	if err != nil {
-		t.Fatalf("collectSession: %v", err)
-	}
+	collectTaggedSession(t, svc, ts)
 
 	// The raw pane ID (without user prefix) should be passed to tmux.
 	if capturedTarget != "%5" {
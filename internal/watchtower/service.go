@@ -28,6 +28,7 @@ const (
 	runtimeLastCollectMSKey      = "last_collect_duration_ms"
 	runtimeLastCollectSessKey    = "last_collect_sessions"
 	runtimeLastCollectChangedKey = "last_collect_changed_sessions"
+	runtimeLastCollectSkipKey    = "last_collect_skipped_sessions"
 	runtimeLastCollectErrorKey   = "last_collect_error"
 )
 
@@ -38,18 +39,13 @@ type tmuxClient interface {
 	CapturePaneLines(ctx context.Context, target string, lines int) (string, error)
 }
 
-// projectionRepo covers session/window upsert and purge operations.
+// projectionRepo covers the per-tick projection and journal write.
 type projectionRepo interface {
-	UpsertWatchtowerSession(ctx context.Context, row store.WatchtowerSessionWrite) error
-	UpsertWatchtowerWindow(ctx context.Context, row store.WatchtowerWindowWrite) error
-	PurgeWatchtowerSessions(ctx context.Context, activeSessions []string) error
-	PurgeWatchtowerWindows(ctx context.Context, sessionName string, activeWindowIndices []int) error
-	PurgeWatchtowerPanes(ctx context.Context, sessionName string, activePaneIDs []string) error
+	ApplyWatchtowerCollect(ctx context.Context, batch store.WatchtowerCollectBatch) error
 }
 
 // paneRepo covers pane state reads and presence lookups.
 type paneRepo interface {
-	UpsertWatchtowerPane(ctx context.Context, row store.WatchtowerPaneWrite) error
 	ListWatchtowerPanes(ctx context.Context, sessionName string) ([]store.WatchtowerPane, error)
 	GetWatchtowerSession(ctx context.Context, sessionName string) (store.WatchtowerSession, error)
 	ListWatchtowerWindows(ctx context.Context, sessionName string) ([]store.WatchtowerWindow, error)
@@ -59,9 +55,8 @@ type paneRepo interface {
 	DeleteManagedTmuxWindowsMissingRuntime(ctx context.Context, sessionName string, liveWindowIDs []string) error
 }
 
-// journalRepo covers journal insert and prune operations.
+// journalRepo covers journal and presence prune operations.
 type journalRepo interface {
-	InsertWatchtowerJournal(ctx context.Context, row store.WatchtowerJournalWrite) (int64, error)
 	PruneWatchtowerJournalRows(ctx context.Context, maxRows int) (int64, error)
 	PruneWatchtowerPresence(ctx context.Context, now time.Time) (int64, error)
 }
@@ -69,7 +64,6 @@ type journalRepo interface {
 // runtimeRepo covers key-value runtime state.
 type runtimeRepo interface {
 	GetWatchtowerRuntimeValue(ctx context.Context, key string) (string, error)
-	SetWatchtowerRuntimeValue(ctx context.Context, key, value string) error
 	SetWatchtowerRuntimeValues(ctx context.Context, values map[string]string) error
 }
 
@@ -79,6 +73,7 @@ type watchtowerStore interface {
 	paneRepo
 	journalRepo
 	runtimeRepo
+	watchRuleRepo
 }
 
 // Compile-time check: *store.Store satisfies watchtowerStore.
@@ -87,6 +82,11 @@ var _ watchtowerStore = (*store.Store)(nil)
 // CollectFunc represents collect func data.
 type CollectFunc func(ctx context.Context) error
 
+// PaneArchiver persists captured pane output.
+type PaneArchiver interface {
+	Append(session, paneID string, at time.Time, captured string) error
+}
+
 // Options represents options data.
 type Options struct {
 	TickInterval   time.Duration
@@ -96,10 +96,25 @@ type Options struct {
 	Collect        CollectFunc
 	Publish        func(eventType string, payload map[string]any)
 
+	// PaneLog, when set, receives the full capture of every pane whose
+	// output changed.
+	PaneLog PaneArchiver
+
+	// Adaptive collects a session unchanged for IdleTicks ticks at a
+	// doubling interval up to MaxInterval, until it changes, shows tmux
+	// activity or a client views it.
+	Adaptive    bool
+	IdleTicks   int
+	MaxInterval time.Duration
+
 	// UserProvider returns the list of OS users with active multi-user sessions.
 	// Called periodically to discover which additional tmux servers to scan.
 	// Returns nil or empty when no multi-user sessions exist.
 	UserProvider func(ctx context.Context) []string
+
+	// Processes, when set, is read for OOM kills, which are reported for
+	// the panes whose processes they killed.
+	Processes ProcessWatcher
 }
 
 // Service represents service data.
@@ -107,6 +122,9 @@ type Service struct {
 	store   watchtowerStore
 	tmux    tmuxClient
 	options Options
+	backoff *idleBackoff
+	watch   *watchMatcher
+	exits   *exitWatch
 
 	startOnce sync.Once
 	stopOnce  sync.Once
@@ -114,6 +132,9 @@ type Service struct {
 	stopFn context.CancelFunc
 	doneCh chan struct{}
 
+	// triggerCh holds at most one pending out-of-tick collection.
+	triggerCh chan struct{}
+
 	// userCache holds the last resolved multi-user list with a TTL.
 	userCache     []string
 	userCacheTime time.Time
@@ -164,10 +185,34 @@ func New(st watchtowerStore, tm tmuxClient, options Options) *Service {
 	if options.JournalRows <= 0 {
 		options.JournalRows = defaultJournalRows
 	}
+	if options.IdleTicks <= 0 {
+		options.IdleTicks = defaultIdleTicks
+	}
+	if options.MaxInterval <= 0 {
+		options.MaxInterval = defaultMaxInterval
+	}
+	options.MaxInterval = max(options.MaxInterval, options.TickInterval)
 	return &Service{
-		store:   st,
-		tmux:    tm,
-		options: options,
+		store:     st,
+		tmux:      tm,
+		options:   options,
+		backoff:   newIdleBackoff(options),
+		watch:     newWatchMatcher(),
+		exits:     newExitWatch(),
+		triggerCh: make(chan struct{}, 1),
+	}
+}
+
+// Trigger asks the running service to collect now instead of waiting for
+// the next tick. Calls made while a collection is pending are merged; it
+// never blocks, so it is safe from tmux notification callbacks.
+func (s *Service) Trigger() {
+	if s == nil {
+		return
+	}
+	select {
+	case s.triggerCh <- struct{}{}:
+	default:
 	}
 }
 
@@ -197,6 +242,10 @@ func (s *Service) Start(parent context.Context) {
 					if err := s.collect(ctx); err != nil {
 						slog.Warn("watchtower collect failed", "err", err)
 					}
+				case <-s.triggerCh:
+					if err := s.collect(ctx); err != nil {
+						slog.Warn("watchtower collect failed", "err", err)
+					}
 				}
 			}
 		}()
@@ -239,11 +288,13 @@ func (s *Service) collectOnce(ctx context.Context) (err error) {
 	startedAt := time.Now().UTC()
 	sessionsCount := 0
 	changedCount := 0
+	skippedCount := 0
 	defer func() {
-		s.recordCollectMetrics(ctx, startedAt, sessionsCount, changedCount, err)
+		s.recordCollectMetrics(ctx, startedAt, sessionsCount, changedCount, skippedCount, err)
 	}()
 
 	s.prunePresenceBestEffort(ctx)
+	s.watch.load(ctx, s.store)
 
 	tagged, proceed, err := s.listCollectSessions(ctx)
 	if err != nil {
@@ -255,12 +306,11 @@ func (s *Service) collectOnce(ctx context.Context) (err error) {
 	sessionsCount = len(tagged)
 
 	summary := s.collectSessionsProjection(ctx, tagged)
-	if err := s.store.PurgeWatchtowerSessions(ctx, summary.activeSessions); err != nil {
-		return err
-	}
+	summary.exits = append(summary.exits, s.exits.pollOOM(ctx, s.options.Processes, time.Now())...)
 	changedCount = len(summary.changedSessions)
+	skippedCount = summary.skippedSessions
 
-	globalRev, err := s.persistActivityJournal(ctx, summary.changedSessions)
+	globalRev, err := s.persistCollect(ctx, summary)
 	if err != nil {
 		return err
 	}
@@ -274,6 +324,11 @@ type collectSummary struct {
 	activeSessions              []string
 	changedSessions             []string
 	activeWindowChangedSessions []string
+	diffs                       []store.WatchtowerSessionDiff
+	matches                     []watchMatch
+	exits                       []paneExit
+	// skippedSessions counts the idle sessions an adaptive tick left out.
+	skippedSessions int
 }
 
 func (s *Service) prunePresenceBestEffort(ctx context.Context) {
@@ -327,52 +382,99 @@ func (s *Service) collectSessionsProjection(ctx context.Context, sessions []tagg
 		activeSessions:  make([]string, 0, len(sessions)),
 		changedSessions: make([]string, 0, len(sessions)),
 	}
+	now := time.Now()
+	live := make(map[string]bool, len(sessions))
 	for _, ts := range sessions {
-		keep, changed, activeWindowSwitched, collectErr := s.collectSession(ctx, ts)
+		key := backoffKey(ts)
+		live[key] = true
+		viewed := false
+		if !s.backoff.due(key, ts.ActivityAt, now) {
+			if viewed = s.sessionViewed(ctx, ts.Name, now); !viewed {
+				summary.activeSessions = append(summary.activeSessions, ts.Name)
+				summary.skippedSessions++
+				continue
+			}
+		}
+
+		result, collectErr := s.collectSession(ctx, ts)
 		if collectErr != nil {
 			slog.Warn("watchtower collect session failed", "session", ts.Name, "user", ts.user, "err", collectErr)
 		}
-		if !keep {
+		if !result.keep {
 			continue
 		}
+		if collectErr == nil {
+			if viewed {
+				s.backoff.wake(key, ts.ActivityAt)
+			} else {
+				s.backoff.observe(key, result.changed, ts.ActivityAt, now)
+			}
+		}
 		summary.activeSessions = append(summary.activeSessions, ts.Name)
-		if changed {
+		if result.changed {
 			summary.changedSessions = append(summary.changedSessions, ts.Name)
 		}
-		if activeWindowSwitched {
+		if result.activeWindowSwitched {
 			summary.activeWindowChangedSessions = append(summary.activeWindowChangedSessions, ts.Name)
 		}
+		if !result.diff.Empty() {
+			summary.diffs = append(summary.diffs, result.diff)
+		}
+		summary.matches = append(summary.matches, result.matches...)
+		summary.exits = append(summary.exits, result.exits...)
 	}
+	s.backoff.retain(live)
+	s.watch.retain(live)
+	s.exits.retain(live)
 	return summary
 }
 
-func (s *Service) persistActivityJournal(ctx context.Context, changedSessions []string) (int64, error) {
-	if len(changedSessions) == 0 {
-		return 0, nil
+// sessionViewed reports whether a client currently shows the session. A
+// failed lookup counts as viewed so the session is not left stale.
+func (s *Service) sessionViewed(ctx context.Context, sessionName string, now time.Time) bool {
+	rows, err := s.store.ListWatchtowerPresenceBySession(ctx, sessionName)
+	if err != nil {
+		return true
 	}
+	for _, row := range rows {
+		if row.Visible && (row.ExpiresAt.IsZero() || row.ExpiresAt.After(now)) {
+			return true
+		}
+	}
+	return false
+}
 
-	currentRev, err := s.currentGlobalRev(ctx)
-	if err != nil {
-		return 0, err
+// persistCollect applies the session diffs, the purge of gone sessions and
+// one journal entry per changed session in a single store transaction, and
+// returns the new global revision (0 when nothing changed).
+func (s *Service) persistCollect(ctx context.Context, summary collectSummary) (int64, error) {
+	batch := store.WatchtowerCollectBatch{
+		Sessions:       summary.diffs,
+		ActiveSessions: summary.activeSessions,
 	}
-	now := time.Now().UTC()
-	for _, sessionName := range changedSessions {
-		currentRev++
-		if _, err := s.store.InsertWatchtowerJournal(ctx, store.WatchtowerJournalWrite{
-			GlobalRev:  currentRev,
-			EntityType: "session",
-			Session:    sessionName,
-			WindowIdx:  -1,
-			ChangeKind: "activity",
-			ChangedAt:  now,
-		}); err != nil {
+	if len(summary.changedSessions) > 0 {
+		currentRev, err := s.currentGlobalRev(ctx)
+		if err != nil {
 			return 0, err
 		}
+		now := time.Now().UTC()
+		for _, sessionName := range summary.changedSessions {
+			currentRev++
+			batch.Journal = append(batch.Journal, store.WatchtowerJournalWrite{
+				GlobalRev:  currentRev,
+				EntityType: "session",
+				Session:    sessionName,
+				WindowIdx:  -1,
+				ChangeKind: "activity",
+				ChangedAt:  now,
+			})
+		}
+		batch.GlobalRev = currentRev
 	}
-	if err := s.store.SetWatchtowerRuntimeValue(ctx, runtimeGlobalRevKey, strconv.FormatInt(currentRev, 10)); err != nil {
+	if err := s.store.ApplyWatchtowerCollect(ctx, batch); err != nil {
 		return 0, err
 	}
-	return currentRev, nil
+	return batch.GlobalRev, nil
 }
 
 func (s *Service) pruneRetentionBestEffort(ctx context.Context) {
@@ -412,6 +514,40 @@ func (s *Service) publishCollectEvents(ctx context.Context, summary collectSumma
 			"action":  "active-window-changed",
 		})
 	}
+
+	for _, match := range summary.matches {
+		slog.Warn("watch rule matched", "rule", match.rule.Name, "severity", match.rule.Severity,
+			"session", match.session, "pane", match.paneID, "line", match.line)
+		s.options.Publish(events.TypeTmuxWatch, map[string]any{
+			"globalRev": globalRev,
+			"action":    "matched",
+			"rule":      match.rule,
+			"session":   match.session,
+			"paneId":    match.paneID,
+			"line":      match.line,
+			"count":     match.count,
+		})
+	}
+
+	for _, exit := range summary.exits {
+		slog.Warn("pane process died", "reason", exit.reason, "session", exit.session, "pane", exit.paneID,
+			"command", exit.command, "status", exit.status, "signal", exit.signal, "pid", exit.pid, "process", exit.process)
+		payload := map[string]any{
+			"globalRev": globalRev,
+			"action":    exit.reason,
+			"session":   exit.session,
+			"paneId":    exit.paneID,
+			"command":   exit.command,
+		}
+		if exit.reason == paneExitOOMKilled {
+			payload["pid"] = exit.pid
+			payload["process"] = exit.process
+		} else {
+			payload["status"] = exit.status
+			payload["signal"] = exit.signal
+		}
+		s.options.Publish(events.TypeTmuxPaneExit, payload)
+	}
 }
 
 func (s *Service) buildSessionActivityPatches(ctx context.Context, sessionNames []string) []map[string]any {
@@ -466,20 +602,31 @@ func (s *Service) buildInspectorActivityPatches(ctx context.Context, sessionName
 	return patches
 }
 
-// collectSession returns (keep, changed, activeWindowSwitched, err).
-func (s *Service) collectSession(ctx context.Context, ts taggedSession) (bool, bool, bool, error) {
+// sessionCollect is the outcome of collecting one session. A session that
+// is kept but failed to collect has an empty diff, so its stored rows stay.
+type sessionCollect struct {
+	keep                 bool
+	changed              bool
+	activeWindowSwitched bool
+	diff                 store.WatchtowerSessionDiff
+	matches              []watchMatch
+	exits                []paneExit
+}
+
+func (s *Service) collectSession(ctx context.Context, ts taggedSession) (sessionCollect, error) {
 	state, keep, err := s.prepareCollectSessionState(ctx, ts)
-	if err != nil {
-		return keep, false, false, err
-	}
-	if !keep {
-		return false, false, false, nil
-	}
-	sessionChanged, err := state.collect()
-	if err != nil {
-		return true, false, false, err
-	}
-	return true, sessionChanged, state.activeWindowSwitched, nil
+	if err != nil || !keep {
+		return sessionCollect{keep: keep}, err
+	}
+	changed := state.collect()
+	return sessionCollect{
+		keep:                 true,
+		changed:              changed,
+		activeWindowSwitched: state.activeWindowSwitched,
+		diff:                 state.diff,
+		matches:              state.matches,
+		exits:                state.exits,
+	}, nil
 }
 
 func (s *Service) currentGlobalRev(ctx context.Context) (int64, error) {
@@ -498,7 +645,7 @@ func (s *Service) currentGlobalRev(ctx context.Context) (int64, error) {
 	return value, nil
 }
 
-func (s *Service) recordCollectMetrics(ctx context.Context, startedAt time.Time, sessionsCount, changedCount int, collectErr error) {
+func (s *Service) recordCollectMetrics(ctx context.Context, startedAt time.Time, sessionsCount, changedCount, skippedCount int, collectErr error) {
 	if s == nil || s.store == nil {
 		return
 	}
@@ -516,6 +663,7 @@ func (s *Service) recordCollectMetrics(ctx context.Context, startedAt time.Time,
 		runtimeLastCollectMSKey:      strconv.FormatInt(durationMS, 10),
 		runtimeLastCollectSessKey:    strconv.Itoa(sessionsCount),
 		runtimeLastCollectChangedKey: strconv.Itoa(changedCount),
+		runtimeLastCollectSkipKey:    strconv.Itoa(skippedCount),
 		runtimeLastCollectErrorKey:   errStr,
 		runtimeCollectTotalKey:       strconv.FormatInt(s.readRuntimeCounter(ctx, runtimeCollectTotalKey)+1, 10),
 	}
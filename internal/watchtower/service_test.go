@@ -3,7 +3,9 @@ package watchtower
 import (
 	"context"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -85,6 +87,35 @@ func TestServiceStartStop(t *testing.T) {
 	svc.Stop(stopCtx)
 }
 
+func TestServiceTriggerCollectsBeforeTick(t *testing.T) {
+	t.Parallel()
+
+	collected := make(chan struct{}, 4)
+	svc := New(nil, fakeTmux{}, Options{
+		TickInterval: time.Hour,
+		Collect: func(context.Context) error {
+			collected <- struct{}{}
+			return nil
+		},
+	})
+	t.Cleanup(func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		svc.Stop(stopCtx)
+	})
+
+	svc.Start(context.Background())
+	<-collected // initial collect
+
+	svc.Trigger()
+	svc.Trigger()
+	select {
+	case <-collected:
+	case <-time.After(time.Second):
+		t.Fatal("Trigger did not collect")
+	}
+}
+
 func TestServiceStartStopIdempotent(t *testing.T) {
 	t.Parallel()
 
@@ -181,6 +212,18 @@ func TestCollectWritesProjections(t *testing.T) {
 	if panes[0].Revision != 1 || panes[0].SeenRevision != 0 {
 		t.Fatalf("unexpected pane revisions: %+v", panes[0])
 	}
+
+	// Nothing changed in tmux, so the next tick has nothing to write.
+	result, err := svc.collectSession(context.Background(), taggedSession{
+		Session: tmux.Session{Name: "dev", Windows: 1, Attached: 1, CreatedAt: now, ActivityAt: now},
+		client:  fake,
+	})
+	if err != nil {
+		t.Fatalf("collectSession: %v", err)
+	}
+	if result.changed || !result.diff.Empty() {
+		t.Fatalf("idle tick = changed %v, diff %+v; want no writes", result.changed, result.diff)
+	}
 }
 
 func TestCollectPublishesSessionsEventOnActivity(t *testing.T) {
@@ -594,6 +637,58 @@ func TestCollectIncrementsRevisionOnOutputChange(t *testing.T) {
 	}
 }
 
+type recordingPaneLog struct {
+	mu      sync.Mutex
+	appends []string
+}
+
+func (r *recordingPaneLog) Append(session, paneID string, _ time.Time, captured string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.appends = append(r.appends, session+"/"+paneID+":"+captured)
+	return nil
+}
+
+func TestCollectArchivesChangedPaneOutput(t *testing.T) {
+	t.Parallel()
+
+	st := newWatchtowerTestStore(t)
+	defer func() { _ = st.Close() }()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var captureCount atomic.Int32
+	fake := fakeTmux{
+		listSessionsFn: func(context.Context) ([]tmux.Session, error) {
+			return []tmux.Session{{Name: "dev", Windows: 1, CreatedAt: now, ActivityAt: now}}, nil
+		},
+		listWindowsFn: func(context.Context, string) ([]tmux.Window, error) {
+			return []tmux.Window{{Session: "dev", Index: 0, Name: "main", Active: true, Panes: 1}}, nil
+		},
+		listPanesFn: func(context.Context, string) ([]tmux.Pane, error) {
+			return []tmux.Pane{{Session: "dev", WindowIndex: 0, PaneID: "%1", Active: true}}, nil
+		},
+		capturePaneLinesFn: func(context.Context, string, int) (string, error) {
+			if captureCount.Add(1) == 1 {
+				return "first", nil
+			}
+			return "second", nil
+		},
+	}
+
+	paneLog := &recordingPaneLog{}
+	svc := New(st, fake, Options{PaneLog: paneLog})
+	for i := range 3 {
+		if err := svc.collect(context.Background()); err != nil {
+			t.Fatalf("collect #%d: %v", i+1, err)
+		}
+	}
+
+	want := []string{"dev/%1:first", "dev/%1:second"}
+	if !reflect.DeepEqual(paneLog.appends, want) {
+		t.Fatalf("appends = %v, want %v", paneLog.appends, want)
+	}
+}
+
 func TestCollectPreservesPreviousTailOnCaptureError(t *testing.T) {
 	t.Parallel()
 
@@ -688,7 +783,7 @@ func TestCollectPreservesPreviousTailOnCaptureError(t *testing.T) {
 	}
 }
 
-func TestPersistActivityJournalAdvancesGlobalRevision(t *testing.T) {
+func TestPersistCollectAdvancesGlobalRevision(t *testing.T) {
 	t.Parallel()
 
 	st := newWatchtowerTestStore(t)
@@ -696,9 +791,9 @@ func TestPersistActivityJournalAdvancesGlobalRevision(t *testing.T) {
 	ctx := context.Background()
 	svc := New(st, fakeTmux{}, Options{})
 
-	rev, err := svc.persistActivityJournal(ctx, nil)
+	rev, err := svc.persistCollect(ctx, collectSummary{})
 	if err != nil {
-		t.Fatalf("persistActivityJournal(empty): %v", err)
+		t.Fatalf("persistCollect(empty): %v", err)
 	}
 	if rev != 0 {
 		t.Fatalf("empty revision = %d, want 0", rev)
@@ -707,9 +802,12 @@ func TestPersistActivityJournalAdvancesGlobalRevision(t *testing.T) {
 	if err := st.SetWatchtowerRuntimeValue(ctx, runtimeGlobalRevKey, "4"); err != nil {
 		t.Fatalf("SetWatchtowerRuntimeValue: %v", err)
 	}
-	rev, err = svc.persistActivityJournal(ctx, []string{"dev", "prod"})
+	rev, err = svc.persistCollect(ctx, collectSummary{
+		activeSessions:  []string{"dev", "prod"},
+		changedSessions: []string{"dev", "prod"},
+	})
 	if err != nil {
-		t.Fatalf("persistActivityJournal: %v", err)
+		t.Fatalf("persistCollect: %v", err)
 	}
 	if rev != 6 {
 		t.Fatalf("revision = %d, want 6", rev)
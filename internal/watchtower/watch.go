@@ -0,0 +1,177 @@
+package watchtower
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/opus-domini/sentinel/internal/store"
+)
+
+// maxWatchLine bounds the matched line carried by an event.
+const maxWatchLine = 512
+
+// watchRuleRepo reads the watch rules.
+type watchRuleRepo interface {
+	ListWatchRules(ctx context.Context) ([]store.WatchRule, error)
+}
+
+// watchMatch is a watch rule matching new output of a pane. Line is the
+// first matching line of the capture and Count the number that matched.
+type watchMatch struct {
+	rule    store.WatchRule
+	session string
+	paneID  string
+	line    string
+	count   int
+}
+
+type compiledWatchRule struct {
+	store.WatchRule
+	re *regexp.Regexp
+}
+
+// watchMatcher matches the enabled watch rules against new pane output. It
+// keeps the lines each pane showed at its last capture, so a line matches
+// once when it appears rather than on every tick it stays on screen. A
+// pane seen for the first time only has its lines recorded, so output
+// already on screen at startup does not match.
+type watchMatcher struct {
+	rules []compiledWatchRule
+	// patterns caches compiled patterns by source; nil marks one that does
+	// not compile.
+	patterns map[string]*regexp.Regexp
+	// lines holds the last lines of each pane by session key and pane ID.
+	lines map[string]map[string]map[string]struct{}
+}
+
+func newWatchMatcher() *watchMatcher {
+	return &watchMatcher{
+		patterns: make(map[string]*regexp.Regexp),
+		lines:    make(map[string]map[string]map[string]struct{}),
+	}
+}
+
+// load reads the enabled rules for this tick. On a read error the rules of
+// the last tick are kept.
+func (m *watchMatcher) load(ctx context.Context, repo watchRuleRepo) {
+	rules, err := repo.ListWatchRules(ctx)
+	if err != nil {
+		slog.Warn("watchtower watch rules read failed", "err", err)
+		return
+	}
+	patterns := make(map[string]*regexp.Regexp, len(rules))
+	m.rules = m.rules[:0]
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		re, ok := m.patterns[rule.Pattern]
+		if !ok {
+			if re, err = regexp.Compile(rule.Pattern); err != nil {
+				slog.Warn("watchtower watch rule does not compile", "rule", rule.Name, "err", err)
+				re = nil
+			}
+		}
+		patterns[rule.Pattern] = re
+		if re != nil {
+			m.rules = append(m.rules, compiledWatchRule{WatchRule: rule, re: re})
+		}
+	}
+	m.patterns = patterns
+	if len(m.rules) == 0 {
+		clear(m.lines)
+	}
+}
+
+// match records the capture of a pane and returns the rules matching its
+// new lines. paneID is the tmux pane ID rules filter on; key identifies the
+// pane across tmux servers.
+func (m *watchMatcher) match(sessionKey, session, paneID, key, captured string) []watchMatch {
+	if len(m.rules) == 0 || captured == "" {
+		return nil
+	}
+	lines := captureLines(captured)
+	current := make(map[string]struct{}, len(lines))
+	for _, line := range lines {
+		current[line] = struct{}{}
+	}
+	panes := m.lines[sessionKey]
+	if panes == nil {
+		panes = make(map[string]map[string]struct{})
+		m.lines[sessionKey] = panes
+	}
+	previous, seen := panes[key]
+	panes[key] = current
+	if !seen {
+		return nil
+	}
+
+	var fresh []string
+	for _, line := range lines {
+		if _, old := previous[line]; !old {
+			fresh = append(fresh, line)
+		}
+	}
+	var matches []watchMatch
+	for _, rule := range m.rules {
+		if (rule.Session != "" && rule.Session != session) || (rule.PaneID != "" && rule.PaneID != paneID) {
+			continue
+		}
+		found := watchMatch{rule: rule.WatchRule, session: session, paneID: paneID}
+		for _, line := range fresh {
+			if !rule.re.MatchString(line) {
+				continue
+			}
+			if found.count == 0 {
+				found.line = truncateWatchLine(line)
+			}
+			found.count++
+		}
+		if found.count > 0 {
+			matches = append(matches, found)
+		}
+	}
+	return matches
+}
+
+// forget drops a pane that went away.
+func (m *watchMatcher) forget(sessionKey, key string) {
+	delete(m.lines[sessionKey], key)
+}
+
+// retain drops the sessions whose keys are not in live.
+func (m *watchMatcher) retain(live map[string]bool) {
+	for key := range m.lines {
+		if !live[key] {
+			delete(m.lines, key)
+		}
+	}
+}
+
+// captureLines returns the non-blank lines of a capture without trailing
+// whitespace.
+func captureLines(captured string) []string {
+	raw := strings.Split(captured, "\n")
+	lines := raw[:0]
+	for _, line := range raw {
+		line = strings.TrimRight(line, " \t\r")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func truncateWatchLine(line string) string {
+	if len(line) <= maxWatchLine {
+		return line
+	}
+	cut := maxWatchLine
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut]
+}
@@ -0,0 +1,119 @@
+package watchtower
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/events"
+	"github.com/opus-domini/sentinel/internal/store"
+	"github.com/opus-domini/sentinel/internal/tmux"
+)
+
+func TestCollectPublishesWatchRuleMatches(t *testing.T) {
+	t.Parallel()
+
+	st := newWatchtowerTestStore(t)
+	defer func() { _ = st.Close() }()
+
+	ctx := context.Background()
+	if _, err := st.CreateWatchRule(ctx, store.WatchRuleWrite{Name: "panic", Pattern: `^panic:`, Enabled: true}); err != nil {
+		t.Fatalf("CreateWatchRule(panic): %v", err)
+	}
+	if _, err := st.CreateWatchRule(ctx, store.WatchRuleWrite{Name: "other", Pattern: "panic", Session: "ops", Enabled: true}); err != nil {
+		t.Fatalf("CreateWatchRule(other): %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	capture := "panic: boot\n$ make"
+	fake := fakeTmux{
+		listSessionsFn: func(context.Context) ([]tmux.Session, error) {
+			return []tmux.Session{{Name: "dev", Windows: 1, CreatedAt: now, ActivityAt: now}}, nil
+		},
+		listWindowsFn: func(context.Context, string) ([]tmux.Window, error) {
+			return []tmux.Window{{Session: "dev", Index: 0, Name: "main", Active: true, Panes: 1}}, nil
+		},
+		listPanesFn: func(context.Context, string) ([]tmux.Pane, error) {
+			return []tmux.Pane{{Session: "dev", WindowIndex: 0, PaneID: "%1", Active: true}}, nil
+		},
+		capturePaneLinesFn: func(context.Context, string, int) (string, error) {
+			return capture, nil
+		},
+	}
+
+	var matched []map[string]any
+	svc := New(st, fake, Options{
+		Publish: func(eventType string, payload map[string]any) {
+			if eventType == events.TypeTmuxWatch {
+				matched = append(matched, payload)
+			}
+		},
+	})
+
+	// Output already on screen at the first capture does not match.
+	if err := svc.collect(ctx); err != nil {
+		t.Fatalf("collect #1: %v", err)
+	}
+	if len(matched) != 0 {
+		t.Fatalf("matches after first collect = %v, want none", matched)
+	}
+
+	capture = "panic: boot\n$ make\npanic: nil map\npanic: again"
+	if err := svc.collect(ctx); err != nil {
+		t.Fatalf("collect #2: %v", err)
+	}
+	if len(matched) != 1 {
+		t.Fatalf("matches after second collect = %v, want 1", matched)
+	}
+	got := matched[0]
+	rule, _ := got["rule"].(store.WatchRule)
+	if rule.Name != "panic" || got["session"] != "dev" || got["paneId"] != "%1" ||
+		got["line"] != "panic: nil map" || got["count"] != 2 || got["action"] != "matched" {
+		t.Fatalf("unexpected match payload: %+v", got)
+	}
+
+	// Lines still on screen do not match again.
+	if err := svc.collect(ctx); err != nil {
+		t.Fatalf("collect #3: %v", err)
+	}
+	if len(matched) != 1 {
+		t.Fatalf("matches after third collect = %d, want 1", len(matched))
+	}
+}
+
+func TestWatchMatcherIgnoresBadPatternsAndTruncates(t *testing.T) {
+	t.Parallel()
+
+	m := newWatchMatcher()
+	m.load(context.Background(), fakeWatchRules{
+		{Name: "bad", Pattern: "(", Enabled: true},
+		{Name: "off", Pattern: "x", Enabled: false},
+		{Name: "pane", Pattern: "x", PaneID: "%2", Enabled: true},
+	})
+	if len(m.rules) != 1 || m.rules[0].Name != "pane" {
+		t.Fatalf("rules = %+v, want only pane", m.rules)
+	}
+
+	m.match("k", "dev", "%2", "%2", "")
+	m.match("k", "dev", "%2", "%2", "start")
+	long := strings.Repeat("x", maxWatchLine+10)
+	if got := m.match("k", "dev", "%1", "%1", long); got != nil {
+		t.Fatalf("first sight of %%1 matched: %+v", got)
+	}
+	got := m.match("k", "dev", "%2", "%2", "start\n"+long)
+	if len(got) != 1 || len(got[0].line) != maxWatchLine {
+		t.Fatalf("match = %+v, want one truncated line", got)
+	}
+
+	m.retain(map[string]bool{})
+	if len(m.lines) != 0 {
+		t.Fatalf("lines after retain = %v, want none", m.lines)
+	}
+}
+
+type fakeWatchRules []store.WatchRule
+
+func (f fakeWatchRules) ListWatchRules(context.Context) ([]store.WatchRule, error) {
+	return f, nil
+}
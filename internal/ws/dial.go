@@ -0,0 +1,119 @@
+package ws
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HandshakeError reports a server that refused the WebSocket upgrade.
+type HandshakeError struct {
+	StatusCode int
+}
+
+func (e *HandshakeError) Error() string {
+	return fmt.Sprintf("websocket handshake failed: HTTP %d", e.StatusCode)
+}
+
+// Dial opens a client WebSocket connection to a ws:// or wss:// URL. The
+// extra header is sent with the upgrade request, e.g. for authorization.
+func Dial(ctx context.Context, rawURL string, header http.Header) (*Conn, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse websocket url: %w", err)
+	}
+	var useTLS bool
+	switch target.Scheme {
+	case "ws":
+	case "wss":
+		useTLS = true
+	default:
+		return nil, fmt.Errorf("unsupported websocket scheme %q", target.Scheme)
+	}
+	addr := target.Host
+	if target.Port() == "" {
+		port := "80"
+		if useTLS {
+			port = "443"
+		}
+		addr = net.JoinHostPort(target.Hostname(), port)
+	}
+
+	var rawConn net.Conn
+	if useTLS {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: target.Hostname(), MinVersion: tls.VersionTLS12}}
+		rawConn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		rawConn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	conn, err := clientHandshake(ctx, rawConn, target, header)
+	if err != nil {
+		_ = rawConn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func clientHandshake(ctx context.Context, rawConn net.Conn, target *url.URL, header http.Header) (*Conn, error) {
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, (&url.URL{
+		Scheme:   "http",
+		Host:     target.Host,
+		Path:     target.Path,
+		RawQuery: target.RawQuery,
+	}).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = rawConn.SetDeadline(deadline)
+		defer func() { _ = rawConn.SetDeadline(time.Time{}) }()
+	}
+	if err := req.Write(rawConn); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(rawConn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, &HandshakeError{StatusCode: resp.StatusCode}
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != computeAcceptKey(key) {
+		return nil, fmt.Errorf("websocket handshake failed: invalid upgrade response")
+	}
+	return &Conn{
+		conn:   rawConn,
+		reader: reader,
+		writer: bufio.NewWriter(rawConn),
+		client: true,
+	}, nil
+}
@@ -3,6 +3,7 @@ package ws
 
 import (
 	"bufio"
+	"crypto/rand"
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/binary"
@@ -69,6 +70,10 @@ type Conn struct {
 	writeMu   sync.Mutex
 	closeOnce sync.Once
 	closed    atomic.Bool
+	// client marks a connection opened by Dial: its frames are masked on
+	// write and must arrive unmasked.
+	client     bool
+	maxPayload int64
 }
 
 // Upgrade handles upgrade.
@@ -154,7 +159,11 @@ func UpgradeWithSubprotocols(
 // ReadMessage handles read message.
 func (c *Conn) ReadMessage() (byte, []byte, error) {
 	for {
-		opcode, payload, err := c.readFrame(defaultMaxFramePayload)
+		maxPayload := c.maxPayload
+		if maxPayload <= 0 {
+			maxPayload = defaultMaxFramePayload
+		}
+		opcode, payload, err := c.readFrame(maxPayload)
 		if err != nil {
 			var ferr *frameError
 			if errors.As(err, &ferr) {
@@ -189,6 +198,12 @@ func (c *Conn) ReadMessage() (byte, []byte, error) {
 	}
 }
 
+// SetReadLimit sets the largest data frame ReadMessage accepts. Values of
+// zero or less restore the 64 KiB default.
+func (c *Conn) SetReadLimit(limit int64) {
+	c.maxPayload = limit
+}
+
 // WriteText handles write text.
 func (c *Conn) WriteText(payload []byte) error {
 	return c.writeFrame(OpText, payload)
@@ -245,22 +260,38 @@ func (c *Conn) writeFrame(opcode byte, payload []byte) error {
 	if c.closed.Load() {
 		return ErrClosed
 	}
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
 	header := make([]byte, 0, 14)
 	header = append(header, 0x80|opcode)
 	switch {
 	case len(payload) < 126:
-		header = append(header, byte(len(payload)))
+		header = append(header, maskBit|byte(len(payload)))
 	case len(payload) <= 0xFFFF:
-		header = append(header, 126)
+		header = append(header, maskBit|126)
 		tmp := make([]byte, 2)
 		binary.BigEndian.PutUint16(tmp, uint16(len(payload)))
 		header = append(header, tmp...)
 	default:
-		header = append(header, 127)
+		header = append(header, maskBit|127)
 		tmp := make([]byte, 8)
 		binary.BigEndian.PutUint64(tmp, uint64(len(payload)))
 		header = append(header, tmp...)
 	}
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		header = append(header, mask[:]...)
+		masked := make([]byte, len(payload))
+		for i := range payload {
+			masked[i] = payload[i] ^ mask[i%4]
+		}
+		payload = masked
+	}
 
 	c.writeMu.Lock()
 	defer c.writeMu.Unlock()
@@ -317,7 +348,13 @@ func (c *Conn) readFrame(maxPayload int64) (byte, []byte, error) {
 			msg:       "rsv bits are not supported",
 		}
 	}
-	if !masked {
+	if c.client && masked {
+		return 0, nil, &frameError{
+			closeCode: CloseProtocol,
+			msg:       "server frame is masked",
+		}
+	}
+	if !c.client && !masked {
 		return 0, nil, &frameError{
 			closeCode: CloseProtocol,
 			msg:       "client frame is not masked",
@@ -342,8 +379,10 @@ func (c *Conn) readFrame(maxPayload int64) (byte, []byte, error) {
 	}
 
 	var mask [4]byte
-	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
-		return 0, nil, err
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+			return 0, nil, err
+		}
 	}
 
 	payload := make([]byte, payloadLen)
@@ -351,8 +390,10 @@ func (c *Conn) readFrame(maxPayload int64) (byte, []byte, error) {
 		if _, err := io.ReadFull(c.reader, payload); err != nil {
 			return 0, nil, err
 		}
-		for i := range payload {
-			payload[i] ^= mask[i%4]
+		if masked {
+			for i := range payload {
+				payload[i] ^= mask[i%4]
+			}
 		}
 	}
 
@@ -1274,3 +1274,75 @@ func TestReadMessageControlFrameTooLarge(t *testing.T) {
 		t.Errorf("error = %q, want to contain %q", err.Error(), "control frame too large")
 	}
 }
+
+func TestDialRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		opcode, payload, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if opcode == OpText {
+			_ = conn.WriteText(append([]byte("echo:"), payload...))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+	conn, err := Dial(ctx, wsURL, http.Header{"Authorization": {"Bearer secret"}})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Large enough to exercise the 16-bit length and masking paths.
+	payload := strings.Repeat("x", 300)
+	if err := conn.WriteText([]byte(payload)); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	opcode, got, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if opcode != OpText || string(got) != "echo:"+payload {
+		t.Fatalf("ReadMessage() = %d %q", opcode, got)
+	}
+
+	_, err = Dial(ctx, wsURL, nil)
+	var hsErr *HandshakeError
+	if !errors.As(err, &hsErr) || hsErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Dial() without auth error = %v, want HandshakeError 401", err)
+	}
+	if _, err := Dial(ctx, "ftp://example.com", nil); err == nil {
+		t.Fatal("Dial() with ftp scheme succeeded, want error")
+	}
+}
+
+func TestSetReadLimit(t *testing.T) {
+	t.Parallel()
+
+	conn, client := newTestServerConn(t)
+	conn.SetReadLimit(128 * 1024)
+	payload := make([]byte, 100*1024)
+	go func() { _ = writeMaskedFrame(client, OpBinary, payload) }()
+
+	_, got, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if len(got) != len(payload) {
+		t.Fatalf("len = %d, want %d", len(got), len(payload))
+	}
+}
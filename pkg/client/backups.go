@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Backup is a restic or borg backup of Sources into Repository, run on a
+// cron Schedule evaluated in Timezone, with the outcome of its latest run.
+// Stale is set when no run succeeded within MaxAgeHours.
+type Backup struct {
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	Tool           string      `json:"tool"`
+	Repository     string      `json:"repository"`
+	Sources        []string    `json:"sources"`
+	Command        string      `json:"command"`
+	Schedule       string      `json:"schedule"`
+	Timezone       string      `json:"timezone"`
+	MaxAgeHours    int         `json:"maxAgeHours"`
+	TimeoutMinutes int         `json:"timeoutMinutes"`
+	Enabled        bool        `json:"enabled"`
+	CreatedAt      string      `json:"createdAt"`
+	UpdatedAt      string      `json:"updatedAt"`
+	Status         string      `json:"status"`
+	Stale          bool        `json:"stale"`
+	LastJobID      string      `json:"lastJobId"`
+	LastRunAt      string      `json:"lastRunAt"`
+	LastFinishedAt string      `json:"lastFinishedAt"`
+	LastSuccessAt  string      `json:"lastSuccessAt"`
+	LastError      string      `json:"lastError,omitempty"`
+	Stats          BackupStats `json:"stats"`
+}
+
+// BackupStats are the figures reported by a successful backup run. Fields
+// the tool does not report stay zero.
+type BackupStats struct {
+	SnapshotID      string  `json:"snapshotId,omitempty"`
+	Files           int64   `json:"files"`
+	FilesNew        int64   `json:"filesNew"`
+	FilesChanged    int64   `json:"filesChanged"`
+	BytesProcessed  int64   `json:"bytesProcessed"`
+	BytesAdded      int64   `json:"bytesAdded"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+func backupPath(id, rest string) string {
+	return "/api/ops/backups/" + url.PathEscape(id) + rest
+}
+
+// ListBackups lists the backups with their latest runs.
+func (c *Client) ListBackups(ctx context.Context) ([]Backup, error) {
+	var data struct {
+		Backups []Backup `json:"backups"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/ops/backups", nil, &data)
+	return data.Backups, err
+}
+
+// CreateBackup stores a new backup. It needs the admin role.
+func (c *Client) CreateBackup(ctx context.Context, b Backup) (Backup, error) {
+	return c.backupAction(ctx, http.MethodPost, "/api/ops/backups", backupWrite(b))
+}
+
+// UpdateBackup replaces the backup with ID b.ID. It needs the admin role.
+func (c *Client) UpdateBackup(ctx context.Context, b Backup) (Backup, error) {
+	return c.backupAction(ctx, http.MethodPut, backupPath(b.ID, ""), backupWrite(b))
+}
+
+// DeleteBackup deletes a backup. It needs the admin role.
+func (c *Client) DeleteBackup(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, backupPath(id, ""), nil, nil)
+}
+
+// RunBackup queues a run of a backup now and returns the backup with
+// LastJobID set; see WaitJob.
+func (c *Client) RunBackup(ctx context.Context, id string) (Backup, error) {
+	return c.backupAction(ctx, http.MethodPost, backupPath(id, "/run"), nil)
+}
+
+func (c *Client) backupAction(ctx context.Context, method, path string, body any) (Backup, error) {
+	var data struct {
+		Backup Backup `json:"backup"`
+	}
+	err := c.do(ctx, method, path, body, &data)
+	return data.Backup, err
+}
+
+func backupWrite(b Backup) any {
+	return struct {
+		Name           string   `json:"name"`
+		Tool           string   `json:"tool"`
+		Repository     string   `json:"repository"`
+		Sources        []string `json:"sources"`
+		Command        string   `json:"command,omitempty"`
+		Schedule       string   `json:"schedule"`
+		Timezone       string   `json:"timezone,omitempty"`
+		MaxAgeHours    int      `json:"maxAgeHours,omitempty"`
+		TimeoutMinutes int      `json:"timeoutMinutes,omitempty"`
+		Enabled        bool     `json:"enabled"`
+	}{
+		b.Name, b.Tool, b.Repository, b.Sources, b.Command, b.Schedule, b.Timezone,
+		b.MaxAgeHours, b.TimeoutMinutes, b.Enabled,
+	}
+}
@@ -0,0 +1,233 @@
+// Package client is a Go client for the Sentinel HTTP API.
+//
+// A Client authenticates with a bearer token (WithToken) or, after Login,
+// with the sentinel_auth cookie the server sets, and decodes the
+// {"data": ...} / {"error": ...} envelope into typed results. API failures
+// are returned as *APIError. Subscribe streams realtime events from the
+// /ws/events channel.
+//
+//	c, err := client.New("http://127.0.0.1:4040", client.WithToken(token))
+//	if err != nil {
+//		return err
+//	}
+//	sessions, err := c.ListSessions(ctx, client.SessionFilter{})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds each request of a client built without
+// WithHTTPClient. It does not apply to event subscriptions.
+const DefaultTimeout = 30 * time.Second
+
+// APIError is an error envelope returned by the server.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    map[string]any
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("sentinel: %s: %s (HTTP %d)", e.Code, e.Message, e.StatusCode)
+}
+
+// IsCode reports whether err is an *APIError with the given code, such as
+// "OPS_JOB_NOT_FOUND" or "FORBIDDEN".
+func IsCode(err error, code string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// Client calls one Sentinel server. It is safe for concurrent use.
+type Client struct {
+	baseURL *url.URL
+	token   string
+	http    *http.Client
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithToken authenticates every request and event subscription with token,
+// either the server token or a named API key.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = strings.TrimSpace(token) }
+}
+
+// WithHTTPClient sends requests through hc, e.g. for custom TLS settings.
+// Without a cookie jar on hc, Login has no lasting effect.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// New returns a client for the server at baseURL, e.g.
+// http://127.0.0.1:4040 or https://sentinel.example.com/prefix.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("sentinel: base URL %q must be an http:// or https:// URL with a host", baseURL)
+	}
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+	parsed.RawQuery, parsed.Fragment = "", ""
+
+	c := &Client{baseURL: parsed}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.http == nil {
+		jar, _ := cookiejar.New(nil)
+		c.http = &http.Client{Timeout: DefaultTimeout, Jar: jar}
+	}
+	return c, nil
+}
+
+// Login exchanges token for the sentinel_auth session cookie, which the
+// client then sends instead of a bearer header.
+func (c *Client) Login(ctx context.Context, token string) error {
+	var data struct {
+		Authenticated bool `json:"authenticated"`
+	}
+	if err := c.do(ctx, http.MethodPut, "/api/auth/token", map[string]string{"token": token}, &data); err != nil {
+		return err
+	}
+	if !data.Authenticated {
+		return &APIError{StatusCode: http.StatusUnauthorized, Code: "UNAUTHORIZED", Message: "token was not accepted"}
+	}
+	c.token = ""
+	return nil
+}
+
+// Logout clears the session cookie set by Login.
+func (c *Client) Logout(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/api/auth/token", nil, nil)
+}
+
+// Do calls an endpoint without a typed method. body is sent as JSON when
+// not nil, and the response data is decoded into out when not nil.
+func (c *Client) Do(ctx context.Context, method, path string, body, out any) error {
+	return c.do(ctx, method, path, body, out)
+}
+
+// url resolves path, whose segments are already escaped, against the base
+// URL.
+func (c *Client) url(path string, query url.Values) string {
+	target := *c.baseURL
+	target.RawPath = c.baseURL.EscapedPath() + path
+	target.Path, _ = url.PathUnescape(target.RawPath)
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	return c.doQuery(ctx, method, path, nil, body, out)
+}
+
+// doQuery sends body as JSON and decodes the response "data" field into
+// out. Error envelopes are returned as *APIError.
+func (c *Client) doQuery(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader, contentType = bytes.NewReader(raw), "application/json"
+	}
+	return c.doBody(ctx, method, path, query, contentType, reader, out)
+}
+
+// doBody sends body as contentType and decodes the response "data" field
+// into out.
+func (c *Client) doBody(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader, out any) error {
+	resp, err := c.send(ctx, method, path, query, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return responseError(resp)
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("sentinel: decode %s %s response (HTTP %d): %w", method, path, resp.StatusCode, err)
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// doStream returns the body of a successful response, such as a file
+// download, which the caller must close.
+func (c *Client) doStream(ctx context.Context, method, path string, query url.Values) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, method, path, query, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer func() { _ = resp.Body.Close() }()
+		return nil, responseError(resp)
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path, query), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return c.http.Do(req)
+}
+
+// responseError decodes the error envelope of a failed response.
+func responseError(resp *http.Response) error {
+	var envelope struct {
+		Error *struct {
+			Code    string         `json:"code"`
+			Message string         `json:"message"`
+			Details map[string]any `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || envelope.Error == nil {
+		return statusError(resp.StatusCode)
+	}
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Code:       envelope.Error.Code,
+		Message:    envelope.Error.Message,
+		Details:    envelope.Error.Details,
+	}
+}
+
+// statusError describes a failed response without an error envelope, such
+// as one written by a proxy.
+func statusError(status int) *APIError {
+	return &APIError{StatusCode: status, Code: "HTTP_" + strconv.Itoa(status), Message: http.StatusText(status)}
+}
@@ -0,0 +1,326 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opus-domini/sentinel/internal/ws"
+)
+
+func writeEnvelope(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL+"/", opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func TestNewRejectsInvalidBaseURL(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{"", "localhost:4040", "ftp://host", "http://"} {
+		if _, err := New(raw); err == nil {
+			t.Errorf("New(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestListSessionsSendsTokenAndFilter(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tmux/sessions" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		if got := r.URL.Query()["tag"]; len(got) != 2 || got[0] != "work" || r.URL.Query().Get("group") != "clients" {
+			http.Error(w, "unexpected query "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+		writeEnvelope(w, http.StatusOK, map[string]any{"sessions": []map[string]any{
+			{"name": "dev", "windows": 2, "tags": []string{"work", "acme"}},
+		}})
+	}, WithToken("secret"))
+
+	sessions, err := c.ListSessions(context.Background(), SessionFilter{Tags: []string{"work", "acme"}, Group: "clients"})
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "dev" || sessions[0].Windows != 2 || len(sessions[0].Tags) != 2 {
+		t.Fatalf("sessions = %+v", sessions)
+	}
+}
+
+func TestErrorEnvelopeBecomesAPIError(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/tmux/sessions/dev/panes/%253/send-keys" {
+			http.Error(w, "unexpected path "+r.URL.EscapedPath(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"PANE_NOT_FOUND","message":"pane does not belong to session"}}`))
+	})
+
+	err := c.SendKeys(context.Background(), "dev", "%3", "ls", true)
+	if !IsCode(err, "PANE_NOT_FOUND") {
+		t.Fatalf("err = %v, want PANE_NOT_FOUND", err)
+	}
+	if apiErr := err.(*APIError); apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "pane does not belong to session" {
+		t.Fatalf("APIError = %+v", apiErr)
+	}
+}
+
+func TestLoginUsesSessionCookie(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/api/auth/token":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["token"] != "secret" {
+				writeEnvelope(w, http.StatusOK, map[string]any{"authenticated": false})
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "sentinel_auth", Value: "secret", Path: "/", HttpOnly: true})
+			writeEnvelope(w, http.StatusOK, map[string]any{"authenticated": true})
+		case r.URL.Path == "/api/ops/services":
+			if cookie, err := r.Cookie("sentinel_auth"); err != nil || cookie.Value != "secret" || r.Header.Get("Authorization") != "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":{"code":"UNAUTHORIZED","message":"authentication required"}}`))
+				return
+			}
+			writeEnvelope(w, http.StatusOK, map[string]any{"services": []map[string]any{{"name": "web", "activeState": "active"}}})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	ctx := context.Background()
+	if _, err := c.ListServices(ctx); !IsCode(err, "UNAUTHORIZED") {
+		t.Fatalf("ListServices before Login: err = %v", err)
+	}
+	if err := c.Login(ctx, "wrong"); !IsCode(err, "UNAUTHORIZED") {
+		t.Fatalf("Login with a wrong token: err = %v", err)
+	}
+	if err := c.Login(ctx, "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	services, err := c.ListServices(ctx)
+	if err != nil {
+		t.Fatalf("ListServices: %v", err)
+	}
+	if len(services) != 1 || services[0].ActiveState != "active" {
+		t.Fatalf("services = %+v", services)
+	}
+}
+
+func TestRunRunbookAndWaitJob(t *testing.T) {
+	t.Parallel()
+
+	var polls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/ops/runbooks/rb-1/run":
+			var body RunOptions
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Priority != PriorityHigh || body.Parameters["env"] != "prod" {
+				http.Error(w, "unexpected body", http.StatusBadRequest)
+				return
+			}
+			writeEnvelope(w, http.StatusAccepted, map[string]any{"job": map[string]any{"id": "job-1", "status": JobQueued}})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/ops/jobs/job-1":
+			status, position := JobQueued, 1
+			if polls.Add(1) > 2 {
+				status, position = JobSucceeded, 0
+			}
+			writeEnvelope(w, http.StatusOK, map[string]any{
+				"job":           map[string]any{"id": "job-1", "status": status, "stepResults": []map[string]any{{"title": "check", "output": "ok"}}},
+				"queuePosition": position,
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	ctx := context.Background()
+	job, err := c.RunRunbook(ctx, "rb-1", RunOptions{Parameters: map[string]string{"env": "prod"}, Priority: PriorityHigh})
+	if err != nil || job.ID != "job-1" || job.Finished() {
+		t.Fatalf("RunRunbook = %+v, %v", job, err)
+	}
+	queued, err := c.GetJob(ctx, job.ID)
+	if err != nil || queued.QueuePosition != 1 {
+		t.Fatalf("GetJob = %+v, %v", queued, err)
+	}
+	done, err := c.WaitJob(ctx, job.ID, time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitJob: %v", err)
+	}
+	if done.Status != JobSucceeded || len(done.StepResults) != 1 || done.StepResults[0].Output != "ok" {
+		t.Fatalf("WaitJob = %+v", done)
+	}
+}
+
+func TestUploadAndDownloadFile(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/fs/files/upload":
+			raw, _ := io.ReadAll(r.Body)
+			if r.Header.Get("Content-Type") != "application/octet-stream" || string(raw) != "hello" ||
+				query.Get("dir") != "/srv" || query.Get("name") != "a b.txt" || query.Get("overwrite") != "true" {
+				http.Error(w, "unexpected upload "+r.URL.RawQuery, http.StatusBadRequest)
+				return
+			}
+			writeEnvelope(w, http.StatusCreated, map[string]any{"file": map[string]any{"name": "a b.txt", "path": "/srv/a b.txt", "size": 5}})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/fs/files/download":
+			if query.Get("path") != "/srv/a b.txt" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":{"code":"FILE_NOT_FOUND","message":"file not found"}}`))
+				return
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte("hello"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	ctx := context.Background()
+	entry, err := c.UploadFile(ctx, "/srv", "a b.txt", strings.NewReader("hello"), true)
+	if err != nil || entry.Path != "/srv/a b.txt" || entry.Size != 5 {
+		t.Fatalf("UploadFile = %+v, %v", entry, err)
+	}
+	body, err := c.DownloadFile(ctx, entry.Path)
+	if err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	raw, err := io.ReadAll(body)
+	_ = body.Close()
+	if err != nil || string(raw) != "hello" {
+		t.Fatalf("downloaded %q, %v", raw, err)
+	}
+	if _, err := c.DownloadFile(ctx, "/srv/missing"); !IsCode(err, "FILE_NOT_FOUND") {
+		t.Fatalf("DownloadFile missing: err = %v, want FILE_NOT_FOUND", err)
+	}
+}
+
+func TestCreateWebhookReturnsSecret(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/ops/webhooks" {
+			http.NotFound(w, r)
+			return
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["runbookId"] != "rb-1" || body["enabled"] != true {
+			http.Error(w, "unexpected body", http.StatusBadRequest)
+			return
+		}
+		if _, ok := body["id"]; ok {
+			http.Error(w, "id must not be sent", http.StatusBadRequest)
+			return
+		}
+		writeEnvelope(w, http.StatusCreated, map[string]any{
+			"webhook": map[string]any{"id": "wh-1", "name": body["name"], "provider": body["provider"], "runbookId": "rb-1", "enabled": true},
+			"secret":  "s3cret",
+		})
+	})
+
+	hook, secret, err := c.CreateWebhook(context.Background(), Webhook{
+		ID: "ignored", Name: "deploy", Provider: WebhookGitHub, RunbookID: "rb-1", Enabled: true,
+	})
+	if err != nil || hook.ID != "wh-1" || hook.Provider != WebhookGitHub || secret != "s3cret" {
+		t.Fatalf("CreateWebhook = %+v, %q, %v", hook, secret, err)
+	}
+}
+
+func TestSubscribeStreamsEvents(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws/events" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, protocol, err := ws.UpgradeWithSubprotocols(w, r, nil, []string{"sentinel.v1"})
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		if protocol != "sentinel.v1" || r.URL.Query().Get("since") != "7" {
+			_ = conn.WriteClose(ws.CloseNormal, "unexpected request")
+			return
+		}
+		for _, msg := range []string{
+			`{"type":"events.ready","payload":{"message":"subscribed","latestEventId":9,"replayed":1,"replayComplete":true}}`,
+			`{"eventId":8,"type":"ops.job.updated","timestamp":"2026-10-16T12:00:00Z","payload":{"jobId":"job-1"}}`,
+			`{"type":"ops.job.log","payload":{"chunk":"hello"}}`,
+		} {
+			if err := conn.WriteText([]byte(msg)); err != nil {
+				return
+			}
+		}
+		_, _, _ = conn.ReadMessage()
+	}, WithToken("secret"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sub, err := c.Subscribe(ctx, SubscribeOptions{Since: 7})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer func() { _ = sub.Close() }()
+	if sub.LatestEventID != 9 || sub.Replayed != 1 || !sub.ReplayComplete {
+		t.Fatalf("greeting = %+v", sub)
+	}
+
+	evt, err := sub.Next()
+	if err != nil || evt.Type != EventOpsJob || evt.Payload["jobId"] != "job-1" {
+		t.Fatalf("Next = %+v, %v", evt, err)
+	}
+	if evt, err = sub.Next(); err != nil || evt.Type != EventOpsJobLog || evt.EventID != 0 {
+		t.Fatalf("Next = %+v, %v", evt, err)
+	}
+	if sub.LastEventID() != 8 {
+		t.Fatalf("LastEventID = %d, want 8", sub.LastEventID())
+	}
+
+	cancel()
+	if _, err := sub.Next(); err == nil {
+		t.Fatal("Next succeeded after the context ended")
+	}
+}
+
+func TestSubscribeReportsRefusedHandshake(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+	_, err := c.Subscribe(context.Background(), SubscribeOptions{})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Fatalf("err = %v, want a 401 handshake error", err)
+	}
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ConfigValidation is the outcome of ValidateConfig. Changes lists the
+// settings the content would change and is empty unless Valid.
+type ConfigValidation struct {
+	Valid   bool           `json:"valid"`
+	Issues  []string       `json:"issues"`
+	Changes []ConfigChange `json:"changes"`
+}
+
+// ConfigChange is one setting that differs from the current config file.
+type ConfigChange struct {
+	Key  string `json:"key"`
+	From any    `json:"from"`
+	To   any    `json:"to"`
+}
+
+// Config returns the path and TOML content of the server's config file,
+// with the server token redacted. It needs the admin role.
+func (c *Client) Config(ctx context.Context) (path, content string, err error) {
+	var data struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+	}
+	err = c.do(ctx, http.MethodGet, "/api/ops/config", nil, &data)
+	return data.Path, data.Content, err
+}
+
+// UpdateConfig replaces the server's config file with content, which takes
+// effect on the next restart. Invalid content fails with code
+// "INVALID_CONFIG" and the problems in the error's "issues" detail. It
+// needs the admin role.
+func (c *Client) UpdateConfig(ctx context.Context, content string) error {
+	return c.do(ctx, http.MethodPatch, "/api/ops/config", map[string]string{"content": content}, nil)
+}
+
+// ValidateConfig checks config content without writing it. It needs the
+// admin role.
+func (c *Client) ValidateConfig(ctx context.Context, content string) (ConfigValidation, error) {
+	var data ConfigValidation
+	err := c.do(ctx, http.MethodPost, "/api/ops/config/validate", map[string]string{"content": content}, &data)
+	return data, err
+}
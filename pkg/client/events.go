@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/opus-domini/sentinel/internal/ws"
+)
+
+// Event types published on the events stream.
+const (
+	EventReady           = "events.ready"
+	EventTmuxSessions    = "tmux.sessions.updated"
+	EventTmuxInspector   = "tmux.inspector.updated"
+	EventTmuxActivity    = "tmux.activity.updated"
+	EventTmuxWatch       = "tmux.watch.matched"
+	EventTmuxPaneExit    = "tmux.pane.exited"
+	EventOpsOverview     = "ops.overview.updated"
+	EventOpsServices     = "ops.services.updated"
+	EventOpsJob          = "ops.job.updated"
+	EventOpsJobLog       = "ops.job.log"
+	EventOpsMetrics      = "ops.metrics.updated"
+	EventScheduleUpdated = "ops.schedule.updated"
+	EventOpsHosts        = "ops.hosts.updated"
+	EventOpsUPS          = "ops.ups.updated"
+	EventOpsLogins       = "ops.logins.updated"
+	EventOpsCertificates = "ops.certificates.updated"
+	EventOpsUptime       = "ops.uptime.updated"
+	EventOpsHeartbeats   = "ops.heartbeats.updated"
+	EventOpsBackups      = "ops.backups.updated"
+	EventOpsLibrary      = "ops.library.updated"
+)
+
+// eventsReadLimit bounds one event message. Service and overview events
+// carry full listings, so the 64 KiB frame default is too small.
+const eventsReadLimit = 4 << 20
+
+// Event is a message from the events stream. Live-only events such as
+// ops.job.log have no EventID.
+type Event struct {
+	EventID   int64          `json:"eventId"`
+	Type      string         `json:"type"`
+	Timestamp string         `json:"timestamp"`
+	Payload   map[string]any `json:"payload,omitempty"`
+}
+
+// SubscribeOptions configures Subscribe.
+type SubscribeOptions struct {
+	// Since replays the retained events after this event ID before live
+	// ones, e.g. the LastEventID of a previous subscription.
+	Since int64
+}
+
+// Subscription is an open events stream. Next must not be called
+// concurrently; Close may be.
+type Subscription struct {
+	conn *ws.Conn
+
+	// LatestEventID, Replayed and ReplayComplete come from the
+	// events.ready greeting. ReplayComplete is false when events after
+	// Since were already evicted, so resources should be reloaded.
+	LatestEventID  int64
+	Replayed       int
+	ReplayComplete bool
+
+	lastEventID int64
+	closeOnce   sync.Once
+	stop        chan struct{}
+}
+
+// Subscribe opens the /ws/events stream with the client's credentials and
+// waits for the server's greeting. The stream is closed when ctx ends or
+// Close is called.
+func (c *Client) Subscribe(ctx context.Context, opts SubscribeOptions) (*Subscription, error) {
+	target := *c.baseURL
+	target.Scheme = "ws"
+	if c.baseURL.Scheme == "https" {
+		target.Scheme = "wss"
+	}
+	target.Path += "/ws/events"
+	if opts.Since > 0 {
+		target.RawQuery = url.Values{"since": {strconv.FormatInt(opts.Since, 10)}}.Encode()
+	}
+
+	header := http.Header{"Sec-Websocket-Protocol": {"sentinel.v1"}}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.http.Jar != nil {
+		for _, cookie := range c.http.Jar.Cookies(c.baseURL) {
+			header.Add("Cookie", cookie.String())
+		}
+	}
+	conn, err := ws.Dial(ctx, target.String(), header)
+	if err != nil {
+		return nil, fmt.Errorf("sentinel: subscribe to events: %w", err)
+	}
+	conn.SetReadLimit(eventsReadLimit)
+
+	sub := &Subscription{conn: conn, lastEventID: opts.Since, stop: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = sub.Close()
+		case <-sub.stop:
+		}
+	}()
+
+	ready, err := sub.read()
+	if err != nil {
+		_ = sub.Close()
+		return nil, fmt.Errorf("sentinel: subscribe to events: %w", err)
+	}
+	if ready.Type != EventReady {
+		_ = sub.Close()
+		return nil, fmt.Errorf("sentinel: subscribe to events: unexpected first event %q", ready.Type)
+	}
+	var greeting struct {
+		LatestEventID  int64 `json:"latestEventId"`
+		Replayed       int   `json:"replayed"`
+		ReplayComplete bool  `json:"replayComplete"`
+	}
+	if raw, err := json.Marshal(ready.Payload); err == nil {
+		_ = json.Unmarshal(raw, &greeting)
+	}
+	sub.LatestEventID, sub.Replayed, sub.ReplayComplete = greeting.LatestEventID, greeting.Replayed, greeting.ReplayComplete
+	return sub, nil
+}
+
+// Next blocks until the next event arrives. It returns an error once the
+// stream is closed.
+func (s *Subscription) Next() (Event, error) {
+	evt, err := s.read()
+	if err != nil {
+		return Event{}, err
+	}
+	if evt.EventID > s.lastEventID {
+		s.lastEventID = evt.EventID
+	}
+	return evt, nil
+}
+
+// LastEventID is the highest event ID returned by Next, to pass as Since
+// when reconnecting.
+func (s *Subscription) LastEventID() int64 {
+	return s.lastEventID
+}
+
+// Close ends the stream.
+func (s *Subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		_ = s.conn.WriteClose(ws.CloseNormal, "")
+		err = s.conn.Close()
+	})
+	return err
+}
+
+func (s *Subscription) read() (Event, error) {
+	for {
+		opcode, payload, err := s.conn.ReadMessage()
+		if err != nil {
+			return Event{}, err
+		}
+		if opcode != ws.OpText {
+			continue
+		}
+		var evt Event
+		if err := json.Unmarshal(payload, &evt); err != nil {
+			return Event{}, fmt.Errorf("sentinel: decode event: %w", err)
+		}
+		return evt, nil
+	}
+}
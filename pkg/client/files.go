@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// FileEntry is a file or directory under one of the server's file roots.
+type FileEntry struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Dir        bool   `json:"dir"`
+	Symlink    bool   `json:"symlink"`
+	Size       int64  `json:"size"`
+	Mode       string `json:"mode"`
+	ModifiedAt string `json:"modifiedAt"`
+}
+
+// FileRoots lists the directories the server exposes for browsing.
+func (c *Client) FileRoots(ctx context.Context) ([]string, error) {
+	var data struct {
+		Roots []string `json:"roots"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/fs/files", nil, &data)
+	return data.Roots, err
+}
+
+// ListFiles lists the entries of a directory under a file root.
+func (c *Client) ListFiles(ctx context.Context, dir string) ([]FileEntry, error) {
+	var data struct {
+		Entries []FileEntry `json:"entries"`
+	}
+	err := c.doQuery(ctx, http.MethodGet, "/api/fs/files", url.Values{"path": {dir}}, nil, &data)
+	return data.Entries, err
+}
+
+// DownloadFile returns the contents of a file, which the caller must
+// close.
+func (c *Client) DownloadFile(ctx context.Context, path string) (io.ReadCloser, error) {
+	return c.doStream(ctx, http.MethodGet, "/api/fs/files/download", url.Values{"path": {path}})
+}
+
+// UploadFile stores r as dir/name. Replacing an existing file needs
+// overwrite and the admin role.
+func (c *Client) UploadFile(ctx context.Context, dir, name string, r io.Reader, overwrite bool) (FileEntry, error) {
+	query := url.Values{"dir": {dir}, "name": {name}}
+	if overwrite {
+		query.Set("overwrite", "true")
+	}
+	var data struct {
+		File FileEntry `json:"file"`
+	}
+	err := c.doBody(ctx, http.MethodPost, "/api/fs/files/upload", query, "application/octet-stream", r, &data)
+	return data.File, err
+}
+
+// RenameFile renames a file or directory within its directory. It needs
+// the admin role.
+func (c *Client) RenameFile(ctx context.Context, path, name string) (FileEntry, error) {
+	var data struct {
+		File FileEntry `json:"file"`
+	}
+	err := c.do(ctx, http.MethodPost, "/api/fs/files/rename", map[string]string{"path": path, "name": name}, &data)
+	return data.File, err
+}
+
+// DeleteFile removes a file or an empty directory. It needs the admin role.
+func (c *Client) DeleteFile(ctx context.Context, path string) error {
+	return c.doQuery(ctx, http.MethodDelete, "/api/fs/files", url.Values{"path": {path}}, nil, nil)
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Host is a host of the inventory: a connected agent, a labelled host, or
+// both. Status is "online" or "offline"; Health rolls up the host's
+// services and is "healthy", "degraded", "unknown" or "offline".
+type Host struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels"`
+	Status      string            `json:"status"`
+	Transport   string            `json:"transport,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	ConnectedAt string            `json:"connectedAt,omitempty"`
+	Health      string            `json:"health,omitempty"`
+	Services    *ServiceSummary   `json:"services,omitempty"`
+	HealthError string            `json:"healthError,omitempty"`
+}
+
+// ServiceSummary counts the tracked services of a host.
+type ServiceSummary struct {
+	Total  int `json:"total"`
+	Active int `json:"active"`
+	Failed int `json:"failed"`
+}
+
+// HostSummary counts inventory hosts by status and health.
+type HostSummary struct {
+	Total    int `json:"total"`
+	Online   int `json:"online"`
+	Offline  int `json:"offline"`
+	Healthy  int `json:"healthy"`
+	Degraded int `json:"degraded"`
+	Unknown  int `json:"unknown"`
+}
+
+// HostLabels are the stored labels of a host.
+type HostLabels struct {
+	Name      string            `json:"name"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt string            `json:"createdAt"`
+	UpdatedAt string            `json:"updatedAt"`
+}
+
+// Agent is a remote sentinel connected to this server.
+type Agent struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Transport   string `json:"transport"`
+	RemoteAddr  string `json:"remoteAddr"`
+	ConnectedAt string `json:"connectedAt"`
+}
+
+// ListHosts lists the inventory hosts matching selector, a label selector
+// such as "env=prod,role!=db", or every host when it is empty.
+func (c *Client) ListHosts(ctx context.Context, selector string) ([]Host, HostSummary, error) {
+	query := url.Values{}
+	if selector != "" {
+		query.Set("selector", selector)
+	}
+	var data struct {
+		Hosts   []Host      `json:"hosts"`
+		Summary HostSummary `json:"summary"`
+	}
+	err := c.doQuery(ctx, http.MethodGet, "/api/ops/hosts", query, nil, &data)
+	return data.Hosts, data.Summary, err
+}
+
+// SetHostLabels replaces the labels of a host, which need not be connected.
+// It needs the admin role.
+func (c *Client) SetHostLabels(ctx context.Context, name string, labels map[string]string) (HostLabels, error) {
+	var data struct {
+		Host HostLabels `json:"host"`
+	}
+	err := c.do(ctx, http.MethodPost, "/api/ops/hosts", map[string]any{"name": name, "labels": labels}, &data)
+	return data.Host, err
+}
+
+// DeleteHostLabels forgets the labels of a host. It needs the admin role.
+func (c *Client) DeleteHostLabels(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/ops/hosts/"+url.PathEscape(name), nil, nil)
+}
+
+// ListAgents lists the remote sentinels connected to this server.
+func (c *Client) ListAgents(ctx context.Context) ([]Agent, error) {
+	var data struct {
+		Hosts []Agent `json:"hosts"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/hosts", nil, &data)
+	return data.Hosts, err
+}
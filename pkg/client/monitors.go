@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Heartbeat expects a ping every IntervalSeconds, plus GraceSeconds, and
+// is missed once one is late. Its ID doubles as the ping credential.
+type Heartbeat struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	IntervalSeconds int    `json:"intervalSeconds"`
+	GraceSeconds    int    `json:"graceSeconds"`
+	Enabled         bool   `json:"enabled"`
+	CreatedAt       string `json:"createdAt"`
+	UpdatedAt       string `json:"updatedAt"`
+	Status          string `json:"status"`
+	LastPingAt      string `json:"lastPingAt"`
+	LastChangeAt    string `json:"lastChangeAt"`
+}
+
+// UptimeCheck probes Target every IntervalSeconds. Type is "http", "tcp"
+// or "icmp"; ExpectStatus and ExpectBody apply to http checks only.
+type UptimeCheck struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Type            string `json:"type"`
+	Target          string `json:"target"`
+	IntervalSeconds int    `json:"intervalSeconds"`
+	TimeoutSeconds  int    `json:"timeoutSeconds"`
+	ExpectStatus    int    `json:"expectStatus"`
+	ExpectBody      string `json:"expectBody"`
+	Enabled         bool   `json:"enabled"`
+	CreatedAt       string `json:"createdAt"`
+	UpdatedAt       string `json:"updatedAt"`
+	Status          string `json:"status"`
+	Detail          string `json:"detail,omitempty"`
+	LatencyMs       int64  `json:"latencyMs"`
+	LastCheckedAt   string `json:"lastCheckedAt"`
+	LastChangeAt    string `json:"lastChangeAt"`
+}
+
+func heartbeatPath(id string) string {
+	return "/api/ops/heartbeats/" + url.PathEscape(id)
+}
+
+func uptimePath(id string) string {
+	return "/api/ops/uptime/" + url.PathEscape(id)
+}
+
+// ListHeartbeats lists the heartbeat monitors.
+func (c *Client) ListHeartbeats(ctx context.Context) ([]Heartbeat, error) {
+	var data struct {
+		Heartbeats []Heartbeat `json:"heartbeats"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/ops/heartbeats", nil, &data)
+	return data.Heartbeats, err
+}
+
+// CreateHeartbeat stores a new heartbeat monitor. It needs the admin role.
+func (c *Client) CreateHeartbeat(ctx context.Context, hb Heartbeat) (Heartbeat, error) {
+	return c.heartbeatWrite(ctx, http.MethodPost, "/api/ops/heartbeats", hb)
+}
+
+// UpdateHeartbeat replaces the heartbeat monitor with ID hb.ID. It needs
+// the admin role.
+func (c *Client) UpdateHeartbeat(ctx context.Context, hb Heartbeat) (Heartbeat, error) {
+	return c.heartbeatWrite(ctx, http.MethodPut, heartbeatPath(hb.ID), hb)
+}
+
+// DeleteHeartbeat deletes a heartbeat monitor. It needs the admin role.
+func (c *Client) DeleteHeartbeat(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, heartbeatPath(id), nil, nil)
+}
+
+// PingHeartbeat records a ping. The heartbeat ID authenticates it, so no
+// token is needed.
+func (c *Client) PingHeartbeat(ctx context.Context, id string) (Heartbeat, error) {
+	var data struct {
+		Heartbeat Heartbeat `json:"heartbeat"`
+	}
+	err := c.do(ctx, http.MethodPost, "/api/hooks/heartbeat/"+url.PathEscape(id), nil, &data)
+	return data.Heartbeat, err
+}
+
+func (c *Client) heartbeatWrite(ctx context.Context, method, path string, hb Heartbeat) (Heartbeat, error) {
+	body := struct {
+		Name            string `json:"name"`
+		IntervalSeconds int    `json:"intervalSeconds"`
+		GraceSeconds    int    `json:"graceSeconds"`
+		Enabled         bool   `json:"enabled"`
+	}{hb.Name, hb.IntervalSeconds, hb.GraceSeconds, hb.Enabled}
+	var data struct {
+		Heartbeat Heartbeat `json:"heartbeat"`
+	}
+	err := c.do(ctx, method, path, body, &data)
+	return data.Heartbeat, err
+}
+
+// ListUptimeChecks lists the uptime checks with their latest results.
+func (c *Client) ListUptimeChecks(ctx context.Context) ([]UptimeCheck, error) {
+	var data struct {
+		Checks []UptimeCheck `json:"checks"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/ops/uptime", nil, &data)
+	return data.Checks, err
+}
+
+// CreateUptimeCheck stores a new uptime check. It needs the admin role.
+func (c *Client) CreateUptimeCheck(ctx context.Context, check UptimeCheck) (UptimeCheck, error) {
+	return c.uptimeWrite(ctx, http.MethodPost, "/api/ops/uptime", check)
+}
+
+// UpdateUptimeCheck replaces the uptime check with ID check.ID. It needs
+// the admin role.
+func (c *Client) UpdateUptimeCheck(ctx context.Context, check UptimeCheck) (UptimeCheck, error) {
+	return c.uptimeWrite(ctx, http.MethodPut, uptimePath(check.ID), check)
+}
+
+// DeleteUptimeCheck deletes an uptime check. It needs the admin role.
+func (c *Client) DeleteUptimeCheck(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, uptimePath(id), nil, nil)
+}
+
+func (c *Client) uptimeWrite(ctx context.Context, method, path string, check UptimeCheck) (UptimeCheck, error) {
+	body := struct {
+		Name            string `json:"name"`
+		Type            string `json:"type"`
+		Target          string `json:"target"`
+		IntervalSeconds int    `json:"intervalSeconds"`
+		TimeoutSeconds  int    `json:"timeoutSeconds"`
+		ExpectStatus    int    `json:"expectStatus"`
+		ExpectBody      string `json:"expectBody"`
+		Enabled         bool   `json:"enabled"`
+	}{
+		check.Name, check.Type, check.Target, check.IntervalSeconds, check.TimeoutSeconds,
+		check.ExpectStatus, check.ExpectBody, check.Enabled,
+	}
+	var data struct {
+		Check UptimeCheck `json:"check"`
+	}
+	err := c.do(ctx, method, path, body, &data)
+	return data.Check, err
+}
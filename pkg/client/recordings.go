@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Recording is an asciicast recording of a terminal session. UpdatedAt is
+// when it ended once the terminal closed.
+type Recording struct {
+	ID        string `json:"id"`
+	Session   string `json:"session"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	StartedAt string `json:"startedAt"`
+	UpdatedAt string `json:"updatedAt"`
+	Size      int64  `json:"size"`
+}
+
+func recordingPath(id string) string {
+	return "/api/tmux/recordings/" + url.PathEscape(id)
+}
+
+// ListRecordings lists the terminal recordings. It needs the admin role.
+func (c *Client) ListRecordings(ctx context.Context) ([]Recording, error) {
+	var data struct {
+		Recordings []Recording `json:"recordings"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/tmux/recordings", nil, &data)
+	return data.Recordings, err
+}
+
+// DownloadRecording returns a recording as an asciicast v2 stream, which
+// the caller must close. It needs the admin role.
+func (c *Client) DownloadRecording(ctx context.Context, id string) (io.ReadCloser, error) {
+	return c.doStream(ctx, http.MethodGet, recordingPath(id), nil)
+}
+
+// DeleteRecording deletes a recording. It needs the admin role.
+func (c *Client) DeleteRecording(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, recordingPath(id), nil, nil)
+}
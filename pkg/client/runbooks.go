@@ -0,0 +1,312 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Job statuses. A job is finished once it is succeeded, failed or canceled.
+const (
+	JobQueued          = "queued"
+	JobRunning         = "running"
+	JobWaitingApproval = "waiting_approval"
+	JobSucceeded       = "succeeded"
+	JobFailed          = "failed"
+	JobCanceled        = "canceled"
+)
+
+// Run priorities accepted by RunOptions.
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
+// RunbookStep is one step of a runbook. Which fields apply depends on Type;
+// see the HTTP API reference.
+type RunbookStep struct {
+	Type             string  `json:"type"`
+	Title            string  `json:"title"`
+	Command          string  `json:"command,omitempty"`
+	Script           string  `json:"script,omitempty"`
+	Description      string  `json:"description,omitempty"`
+	ContinueOnError  bool    `json:"continueOnError,omitempty"`
+	Timeout          int     `json:"timeout,omitempty"`
+	Retries          int     `json:"retries,omitempty"`
+	RetryDelay       int     `json:"retryDelay,omitempty"`
+	RetryBackoff     float64 `json:"retryBackoff,omitempty"`
+	RetryMaxDelay    int     `json:"retryMaxDelay,omitempty"`
+	RetryOnExitCodes []int   `json:"retryOnExitCodes,omitempty"`
+	Cwd              string  `json:"cwd,omitempty"`
+	RunAsUser        string  `json:"runAsUser,omitempty"`
+	Shell            string  `json:"shell,omitempty"`
+	URL              string  `json:"url,omitempty"`
+	Method           string  `json:"method,omitempty"`
+	Body             string  `json:"body,omitempty"`
+	ExpectStatus     int     `json:"expectStatus,omitempty"`
+	Target           string  `json:"target,omitempty"`
+	Keys             string  `json:"keys,omitempty"`
+	Enter            bool    `json:"enter,omitempty"`
+	Session          string  `json:"session,omitempty"`
+	Window           string  `json:"window,omitempty"`
+	Marker           string  `json:"marker,omitempty"`
+	Duration         int     `json:"duration,omitempty"`
+	Interval         int     `json:"interval,omitempty"`
+	Hosts            string  `json:"hosts,omitempty"`
+	Unit             string  `json:"unit,omitempty"`
+	Action           string  `json:"action,omitempty"`
+	Scope            string  `json:"scope,omitempty"`
+	Manager          string  `json:"manager,omitempty"`
+}
+
+// RunbookParameter is a value a runbook run substitutes into its steps.
+type RunbookParameter struct {
+	Name     string   `json:"name"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Default  string   `json:"default"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// Runbook is a stored sequence of steps.
+type Runbook struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Enabled     bool               `json:"enabled"`
+	WebhookURL  string             `json:"webhookURL"`
+	Steps       []RunbookStep      `json:"steps"`
+	Parameters  []RunbookParameter `json:"parameters"`
+	CreatedAt   string             `json:"createdAt"`
+	UpdatedAt   string             `json:"updatedAt"`
+}
+
+// StepResult is the outcome of one executed step.
+type StepResult struct {
+	StepIndex  int    `json:"stepIndex"`
+	Title      string `json:"title"`
+	Type       string `json:"type"`
+	Output     string `json:"output"`
+	Error      string `json:"error"`
+	DurationMs int64  `json:"durationMs"`
+	Retries    int    `json:"retries,omitempty"`
+	ExitCode   int    `json:"exitCode,omitempty"`
+}
+
+// Job is one run of a runbook.
+type Job struct {
+	ID             string            `json:"id"`
+	RunbookID      string            `json:"runbookId"`
+	RunbookName    string            `json:"runbookName"`
+	Status         string            `json:"status"`
+	TotalSteps     int               `json:"totalSteps"`
+	CompletedSteps int               `json:"completedSteps"`
+	CurrentStep    string            `json:"currentStep"`
+	Error          string            `json:"error"`
+	StepResults    []StepResult      `json:"stepResults"`
+	ParametersUsed map[string]string `json:"parametersUsed"`
+	Hosts          string            `json:"hosts,omitempty"`
+	CreatedAt      string            `json:"createdAt"`
+	StartedAt      string            `json:"startedAt,omitempty"`
+	FinishedAt     string            `json:"finishedAt,omitempty"`
+	// QueuePosition is the 1-based place of a queued job. Only GetJob
+	// sets it.
+	QueuePosition int `json:"-"`
+}
+
+// Finished reports whether the job reached a final status.
+func (j Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+// Schedule runs a runbook on a cron expression, once or at an interval.
+type Schedule struct {
+	ID                string `json:"id"`
+	RunbookID         string `json:"runbookId"`
+	Name              string `json:"name"`
+	ScheduleType      string `json:"scheduleType"`
+	CronExpr          string `json:"cronExpr"`
+	Timezone          string `json:"timezone"`
+	RunAt             string `json:"runAt"`
+	Interval          string `json:"interval"`
+	Jitter            string `json:"jitter"`
+	ConcurrencyPolicy string `json:"concurrencyPolicy"`
+	Hosts             string `json:"hosts"`
+	Enabled           bool   `json:"enabled"`
+	LastRunAt         string `json:"lastRunAt"`
+	LastRunStatus     string `json:"lastRunStatus"`
+	NextRunAt         string `json:"nextRunAt"`
+	CreatedAt         string `json:"createdAt"`
+	UpdatedAt         string `json:"updatedAt"`
+}
+
+// Plan is a rendered runbook returned by DryRunRunbook. Ready is false when
+// any step reports a problem.
+type Plan struct {
+	RunbookID   string            `json:"runbookId"`
+	RunbookName string            `json:"runbookName"`
+	Parameters  map[string]string `json:"parameters"`
+	Steps       []PlannedStep     `json:"steps"`
+	Ready       bool              `json:"ready"`
+}
+
+// PlannedStep is one rendered step of a Plan.
+type PlannedStep struct {
+	Index          int      `json:"index"`
+	Type           string   `json:"type"`
+	Title          string   `json:"title"`
+	Command        string   `json:"command,omitempty"`
+	Script         string   `json:"script,omitempty"`
+	TargetHosts    []string `json:"targetHosts,omitempty"`
+	TimeoutSeconds int      `json:"timeoutSeconds"`
+	Problems       []string `json:"problems,omitempty"`
+}
+
+// RunOptions parameterizes a runbook run. Hosts is a label selector that
+// replaces the selector of every service step.
+type RunOptions struct {
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Hosts      string            `json:"hosts,omitempty"`
+	Priority   string            `json:"priority,omitempty"`
+}
+
+func runbookPath(id, rest string) string {
+	return "/api/ops/runbooks/" + url.PathEscape(id) + rest
+}
+
+func jobPath(id, rest string) string {
+	return "/api/ops/jobs/" + url.PathEscape(id) + rest
+}
+
+// ListRunbooks lists the runbooks and the 20 most recent jobs.
+func (c *Client) ListRunbooks(ctx context.Context) ([]Runbook, []Job, error) {
+	var data struct {
+		Runbooks []Runbook `json:"runbooks"`
+		Jobs     []Job     `json:"jobs"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/ops/runbooks", nil, &data)
+	return data.Runbooks, data.Jobs, err
+}
+
+// CreateRunbook stores a new runbook. ID, CreatedAt and UpdatedAt are
+// assigned by the server. It needs the admin role.
+func (c *Client) CreateRunbook(ctx context.Context, rb Runbook) (Runbook, error) {
+	var data struct {
+		Runbook Runbook `json:"runbook"`
+	}
+	err := c.do(ctx, http.MethodPost, "/api/ops/runbooks", runbookWrite(rb), &data)
+	return data.Runbook, err
+}
+
+// UpdateRunbook replaces the runbook with ID rb.ID. It needs the admin role.
+func (c *Client) UpdateRunbook(ctx context.Context, rb Runbook) (Runbook, error) {
+	var data struct {
+		Runbook Runbook `json:"runbook"`
+	}
+	err := c.do(ctx, http.MethodPut, runbookPath(rb.ID, ""), runbookWrite(rb), &data)
+	return data.Runbook, err
+}
+
+// DeleteRunbook deletes a runbook and its schedules. It needs the admin role.
+func (c *Client) DeleteRunbook(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, runbookPath(id, ""), nil, nil)
+}
+
+func runbookWrite(rb Runbook) any {
+	return struct {
+		Name        string             `json:"name"`
+		Description string             `json:"description"`
+		Enabled     bool               `json:"enabled"`
+		WebhookURL  string             `json:"webhookURL,omitempty"`
+		Steps       []RunbookStep      `json:"steps"`
+		Parameters  []RunbookParameter `json:"parameters,omitempty"`
+	}{rb.Name, rb.Description, rb.Enabled, rb.WebhookURL, rb.Steps, rb.Parameters}
+}
+
+// RunRunbook queues a run and returns its job without waiting for it; see
+// WaitJob.
+func (c *Client) RunRunbook(ctx context.Context, id string, opts RunOptions) (Job, error) {
+	return c.jobAction(ctx, http.MethodPost, runbookPath(id, "/run"), opts)
+}
+
+// DryRunRunbook renders the steps a run with opts would execute, without
+// running anything.
+func (c *Client) DryRunRunbook(ctx context.Context, id string, opts RunOptions) (Plan, error) {
+	var data struct {
+		Plan Plan `json:"plan"`
+	}
+	err := c.do(ctx, http.MethodPost, runbookPath(id, "/dry-run"), opts, &data)
+	return data.Plan, err
+}
+
+// GetJob returns a job, with QueuePosition set while it waits to start.
+func (c *Client) GetJob(ctx context.Context, id string) (Job, error) {
+	var data struct {
+		Job           Job `json:"job"`
+		QueuePosition int `json:"queuePosition"`
+	}
+	err := c.do(ctx, http.MethodGet, jobPath(id, ""), nil, &data)
+	data.Job.QueuePosition = data.QueuePosition
+	return data.Job, err
+}
+
+// WaitJob polls a job every interval until it finishes or needs approval,
+// and returns its last state.
+func (c *Client) WaitJob(ctx context.Context, id string, interval time.Duration) (Job, error) {
+	for {
+		job, err := c.GetJob(ctx, id)
+		if err != nil || job.Finished() || job.Status == JobWaitingApproval {
+			return job, err
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// CancelJob cancels a queued or running job.
+func (c *Client) CancelJob(ctx context.Context, id string) (Job, error) {
+	return c.jobAction(ctx, http.MethodPost, jobPath(id, "/cancel"), nil)
+}
+
+// DeleteJob deletes a finished job. It needs the admin role.
+func (c *Client) DeleteJob(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, jobPath(id, ""), nil, nil)
+}
+
+// ApproveRun resumes a run waiting at an approval step.
+func (c *Client) ApproveRun(ctx context.Context, runID string) (Job, error) {
+	return c.jobAction(ctx, http.MethodPost, "/api/ops/runs/"+url.PathEscape(runID)+"/approve", nil)
+}
+
+// RejectRun fails a run waiting at an approval step.
+func (c *Client) RejectRun(ctx context.Context, runID string) (Job, error) {
+	return c.jobAction(ctx, http.MethodPost, "/api/ops/runs/"+url.PathEscape(runID)+"/reject", nil)
+}
+
+func (c *Client) jobAction(ctx context.Context, method, path string, body any) (Job, error) {
+	var data struct {
+		Job Job `json:"job"`
+	}
+	err := c.do(ctx, method, path, body, &data)
+	return data.Job, err
+}
+
+// ListSchedules lists the runbook schedules.
+func (c *Client) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	var data struct {
+		Schedules []Schedule `json:"schedules"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/ops/schedules", nil, &data)
+	return data.Schedules, err
+}
+
+// TriggerSchedule runs a schedule's runbook now and returns the job.
+func (c *Client) TriggerSchedule(ctx context.Context, id string) (Job, error) {
+	return c.jobAction(ctx, http.MethodPost, "/api/ops/schedules/"+url.PathEscape(id)+"/trigger", nil)
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Secret is a stored secret. Its value is never returned.
+type Secret struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+func secretPath(name string) string {
+	return "/api/ops/secrets/" + url.PathEscape(name)
+}
+
+// ListSecrets lists the stored secrets. It needs the admin role.
+func (c *Client) ListSecrets(ctx context.Context) ([]Secret, error) {
+	var data struct {
+		Secrets []Secret `json:"secrets"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/ops/secrets", nil, &data)
+	return data.Secrets, err
+}
+
+// CreateSecret stores a new secret. It needs the admin role.
+func (c *Client) CreateSecret(ctx context.Context, name, value string) (Secret, error) {
+	return c.secretWrite(ctx, http.MethodPost, "/api/ops/secrets", map[string]string{"name": name, "value": value})
+}
+
+// UpdateSecret replaces the value of a secret. It needs the admin role.
+func (c *Client) UpdateSecret(ctx context.Context, name, value string) (Secret, error) {
+	return c.secretWrite(ctx, http.MethodPut, secretPath(name), map[string]string{"value": value})
+}
+
+// DeleteSecret deletes a secret. It needs the admin role.
+func (c *Client) DeleteSecret(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, secretPath(name), nil, nil)
+}
+
+func (c *Client) secretWrite(ctx context.Context, method, path string, body any) (Secret, error) {
+	var data struct {
+		Secret Secret `json:"secret"`
+	}
+	err := c.do(ctx, method, path, body, &data)
+	return data.Secret, err
+}
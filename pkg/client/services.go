@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Service actions accepted by ServiceAction.
+const (
+	ActionStart   = "start"
+	ActionStop    = "stop"
+	ActionRestart = "restart"
+	ActionEnable  = "enable"
+	ActionDisable = "disable"
+)
+
+// Service is a tracked service and its runtime state.
+type Service struct {
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	Manager      string `json:"manager"`
+	Scope        string `json:"scope"`
+	Unit         string `json:"unit"`
+	Exists       bool   `json:"exists"`
+	EnabledState string `json:"enabledState"`
+	ActiveState  string `json:"activeState"`
+	LastRunState string `json:"lastRunState,omitempty"`
+	UpdatedAt    string `json:"updatedAt"`
+}
+
+// ServiceInspection is the detailed manager status of one service.
+type ServiceInspection struct {
+	Service    Service           `json:"service"`
+	Summary    string            `json:"summary"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Output     string            `json:"output,omitempty"`
+	CheckedAt  string            `json:"checkedAt"`
+}
+
+// LogOptions filters ServiceLogs. Since and Until take RFC3339 times or
+// unix seconds; Priority applies to journald only and Grep is a regular
+// expression.
+type LogOptions struct {
+	Lines    int
+	Since    string
+	Until    string
+	Priority string
+	Grep     string
+}
+
+func servicePath(name, rest string) string {
+	return "/api/ops/services/" + url.PathEscape(name) + rest
+}
+
+// ListServices lists the tracked services.
+func (c *Client) ListServices(ctx context.Context) ([]Service, error) {
+	var data struct {
+		Services []Service `json:"services"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/ops/services", nil, &data)
+	return data.Services, err
+}
+
+// InspectService returns the detailed status of a tracked service.
+func (c *Client) InspectService(ctx context.Context, name string) (ServiceInspection, error) {
+	var data struct {
+		Status ServiceInspection `json:"status"`
+	}
+	err := c.do(ctx, http.MethodGet, servicePath(name, "/status"), nil, &data)
+	return data.Status, err
+}
+
+// ServiceAction runs action, one of the Action constants, on a tracked
+// service and returns its new state. It needs the admin role.
+func (c *Client) ServiceAction(ctx context.Context, name, action string) (Service, error) {
+	var data struct {
+		Service Service `json:"service"`
+	}
+	err := c.do(ctx, http.MethodPost, servicePath(name, "/action"), map[string]string{"action": action}, &data)
+	return data.Service, err
+}
+
+// ServiceLogs returns recent log output of a tracked service.
+func (c *Client) ServiceLogs(ctx context.Context, name string, opts LogOptions) (string, error) {
+	query := url.Values{}
+	if opts.Lines > 0 {
+		query.Set("lines", strconv.Itoa(opts.Lines))
+	}
+	for key, value := range map[string]string{
+		"since": opts.Since, "until": opts.Until, "priority": opts.Priority, "grep": opts.Grep,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	var data struct {
+		Output string `json:"output"`
+	}
+	err := c.doQuery(ctx, http.MethodGet, servicePath(name, "/logs"), query, nil, &data)
+	return data.Output, err
+}
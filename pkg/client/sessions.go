@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Session is a tmux session as listed by the server.
+type Session struct {
+	Name          string   `json:"name"`
+	Windows       int      `json:"windows"`
+	Panes         int      `json:"panes"`
+	Attached      int      `json:"attached"`
+	CreatedAt     string   `json:"createdAt"`
+	ActivityAt    string   `json:"activityAt"`
+	Command       string   `json:"command"`
+	Hash          string   `json:"hash"`
+	LastContent   string   `json:"lastContent"`
+	Icon          string   `json:"icon"`
+	Tags          []string `json:"tags,omitempty"`
+	Group         string   `json:"group,omitempty"`
+	Notes         string   `json:"notes,omitempty"`
+	User          string   `json:"user,omitempty"`
+	SortOrder     int      `json:"sortOrder"`
+	UnreadWindows int      `json:"unreadWindows"`
+	UnreadPanes   int      `json:"unreadPanes"`
+	Rev           int64    `json:"rev"`
+}
+
+// Window is a tmux window of a session.
+type Window struct {
+	Session      string `json:"session"`
+	Index        int    `json:"index"`
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	TmuxWindowID string `json:"tmuxWindowId,omitempty"`
+	Active       bool   `json:"active"`
+	Panes        int    `json:"panes"`
+	Layout       string `json:"layout,omitempty"`
+	UnreadPanes  int    `json:"unreadPanes"`
+	HasUnread    bool   `json:"hasUnread"`
+	Rev          int64  `json:"rev"`
+	ActivityAt   string `json:"activityAt,omitempty"`
+}
+
+// Pane is a tmux pane of a session.
+type Pane struct {
+	Session        string `json:"session"`
+	WindowIndex    int    `json:"windowIndex"`
+	PaneIndex      int    `json:"paneIndex"`
+	PaneID         string `json:"paneId"`
+	Title          string `json:"title"`
+	Active         bool   `json:"active"`
+	Zoomed         bool   `json:"zoomed"`
+	TTY            string `json:"tty"`
+	CurrentPath    string `json:"currentPath,omitempty"`
+	StartCommand   string `json:"startCommand,omitempty"`
+	CurrentCommand string `json:"currentCommand,omitempty"`
+	TailPreview    string `json:"tailPreview,omitempty"`
+	Revision       int64  `json:"revision"`
+	SeenRevision   int64  `json:"seenRevision"`
+	HasUnread      bool   `json:"hasUnread"`
+	ChangedAt      string `json:"changedAt,omitempty"`
+}
+
+// Capture is a range of pane scrollback. Request End = Start-1 to page
+// further back.
+type Capture struct {
+	PaneID      string `json:"paneId"`
+	Content     string `json:"content"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	HistorySize int    `json:"historySize"`
+	Height      int    `json:"height"`
+}
+
+// SessionFilter narrows ListSessions. A session must carry every tag.
+type SessionFilter struct {
+	Tags  []string
+	Group string
+}
+
+// CreateSessionRequest describes a new session. Icon and User are optional.
+type CreateSessionRequest struct {
+	Name string `json:"name"`
+	Cwd  string `json:"cwd"`
+	Icon string `json:"icon,omitempty"`
+	User string `json:"user,omitempty"`
+}
+
+// CaptureOptions selects the lines CapturePane returns. Nil bounds use the
+// server defaults: the last 200 lines through the bottom of the screen.
+type CaptureOptions struct {
+	Start   *int
+	End     *int
+	Escapes bool
+}
+
+func sessionPath(session string, rest ...string) string {
+	return "/api/tmux/sessions/" + url.PathEscape(session) + strings.Join(rest, "")
+}
+
+func panePath(session, paneID, action string) string {
+	return sessionPath(session, "/panes/", url.PathEscape(paneID), "/", action)
+}
+
+// ListSessions lists the tmux sessions matching filter.
+func (c *Client) ListSessions(ctx context.Context, filter SessionFilter) ([]Session, error) {
+	query := url.Values{}
+	for _, tag := range filter.Tags {
+		query.Add("tag", tag)
+	}
+	if filter.Group != "" {
+		query.Set("group", filter.Group)
+	}
+	var data struct {
+		Sessions []Session `json:"sessions"`
+	}
+	err := c.doQuery(ctx, http.MethodGet, "/api/tmux/sessions", query, nil, &data)
+	return data.Sessions, err
+}
+
+// CreateSession creates a session and returns its name, which carries a
+// numeric suffix when the requested name was taken.
+func (c *Client) CreateSession(ctx context.Context, req CreateSessionRequest) (string, error) {
+	var data struct {
+		Name string `json:"name"`
+	}
+	err := c.do(ctx, http.MethodPost, "/api/tmux/sessions", req, &data)
+	return data.Name, err
+}
+
+// RenameSession renames session to newName.
+func (c *Client) RenameSession(ctx context.Context, session, newName string) error {
+	return c.do(ctx, http.MethodPatch, sessionPath(session), map[string]string{"newName": newName}, nil)
+}
+
+// KillSession kills session. It needs the admin role.
+func (c *Client) KillSession(ctx context.Context, session string) error {
+	return c.do(ctx, http.MethodDelete, sessionPath(session), nil, nil)
+}
+
+// ListWindows lists the windows of session.
+func (c *Client) ListWindows(ctx context.Context, session string) ([]Window, error) {
+	var data struct {
+		Windows []Window `json:"windows"`
+	}
+	err := c.do(ctx, http.MethodGet, sessionPath(session, "/windows"), nil, &data)
+	return data.Windows, err
+}
+
+// ListPanes lists the panes of session.
+func (c *Client) ListPanes(ctx context.Context, session string) ([]Pane, error) {
+	var data struct {
+		Panes []Pane `json:"panes"`
+	}
+	err := c.do(ctx, http.MethodGet, sessionPath(session, "/panes"), nil, &data)
+	return data.Panes, err
+}
+
+// NewWindow opens a window in session.
+func (c *Client) NewWindow(ctx context.Context, session string) error {
+	return c.do(ctx, http.MethodPost, sessionPath(session, "/new-window"), struct{}{}, nil)
+}
+
+// KillWindow kills the window at index in session. It needs the admin role.
+func (c *Client) KillWindow(ctx context.Context, session string, index int) error {
+	return c.do(ctx, http.MethodPost, sessionPath(session, "/kill-window"), map[string]int{"index": index}, nil)
+}
+
+// SendKeys types keys literally into a pane, pressing Enter afterwards
+// when enter is set. paneID may omit the leading "%".
+func (c *Client) SendKeys(ctx context.Context, session, paneID, keys string, enter bool) error {
+	body := struct {
+		Keys  string `json:"keys"`
+		Enter bool   `json:"enter"`
+	}{keys, enter}
+	return c.do(ctx, http.MethodPost, panePath(session, paneID, "send-keys"), body, nil)
+}
+
+// CapturePane returns a range of a pane's scrollback.
+func (c *Client) CapturePane(ctx context.Context, session, paneID string, opts CaptureOptions) (Capture, error) {
+	query := url.Values{}
+	if opts.Start != nil {
+		query.Set("start", strconv.Itoa(*opts.Start))
+	}
+	if opts.End != nil {
+		query.Set("end", strconv.Itoa(*opts.End))
+	}
+	if opts.Escapes {
+		query.Set("escapes", "true")
+	}
+	var data struct {
+		Capture Capture `json:"capture"`
+	}
+	err := c.doQuery(ctx, http.MethodGet, panePath(session, paneID, "capture"), query, nil, &data)
+	return data.Capture, err
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Webhook providers accepted by Webhook.Provider.
+const (
+	WebhookGeneric = "generic"
+	WebhookGitHub  = "github"
+	WebhookGitLab  = "gitlab"
+)
+
+// Webhook runs a runbook when a signed request arrives. Repository, Branch
+// and Events filter github and gitlab deliveries.
+type Webhook struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Provider        string            `json:"provider"`
+	RunbookID       string            `json:"runbookId"`
+	Parameters      map[string]string `json:"parameters"`
+	Repository      string            `json:"repository"`
+	Branch          string            `json:"branch"`
+	Events          []string          `json:"events"`
+	Enabled         bool              `json:"enabled"`
+	CreatedAt       string            `json:"createdAt"`
+	UpdatedAt       string            `json:"updatedAt"`
+	LastTriggeredAt string            `json:"lastTriggeredAt"`
+}
+
+func webhookPath(id string) string {
+	return "/api/ops/webhooks/" + url.PathEscape(id)
+}
+
+// ListWebhooks lists the webhooks.
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var data struct {
+		Webhooks []Webhook `json:"webhooks"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/ops/webhooks", nil, &data)
+	return data.Webhooks, err
+}
+
+// CreateWebhook stores a new webhook and returns it with its signing
+// secret, which is not returned again. It needs the admin role.
+func (c *Client) CreateWebhook(ctx context.Context, hook Webhook) (Webhook, string, error) {
+	var data struct {
+		Webhook Webhook `json:"webhook"`
+		Secret  string  `json:"secret"`
+	}
+	err := c.do(ctx, http.MethodPost, "/api/ops/webhooks", webhookWrite(hook), &data)
+	return data.Webhook, data.Secret, err
+}
+
+// UpdateWebhook replaces the webhook with ID hook.ID. It needs the admin
+// role.
+func (c *Client) UpdateWebhook(ctx context.Context, hook Webhook) (Webhook, error) {
+	var data struct {
+		Webhook Webhook `json:"webhook"`
+	}
+	err := c.do(ctx, http.MethodPut, webhookPath(hook.ID), webhookWrite(hook), &data)
+	return data.Webhook, err
+}
+
+// DeleteWebhook deletes a webhook. It needs the admin role.
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, webhookPath(id), nil, nil)
+}
+
+func webhookWrite(hook Webhook) any {
+	return struct {
+		Name       string            `json:"name"`
+		Provider   string            `json:"provider"`
+		RunbookID  string            `json:"runbookId"`
+		Parameters map[string]string `json:"parameters,omitempty"`
+		Repository string            `json:"repository,omitempty"`
+		Branch     string            `json:"branch,omitempty"`
+		Events     []string          `json:"events,omitempty"`
+		Enabled    bool              `json:"enabled"`
+	}{hook.Name, hook.Provider, hook.RunbookID, hook.Parameters, hook.Repository, hook.Branch, hook.Events, hook.Enabled}
+}